@@ -0,0 +1,1337 @@
+// Protobuf definitions of the collective's gRPC API: agent listing and
+// invocation, and knowledge graph queries. The services mirror the HTTP
+// endpoints of the same names; see the README for their semantics.
+//
+// Generate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: collective/v1/collective.proto
+
+package collectivev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListAgentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsRequest) Reset() {
+	*x = ListAgentsRequest{}
+	mi := &file_collective_v1_collective_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsRequest) ProtoMessage() {}
+
+func (x *ListAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsRequest.ProtoReflect.Descriptor instead.
+func (*ListAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{0}
+}
+
+type ListAgentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*Agent               `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsResponse) Reset() {
+	*x = ListAgentsResponse{}
+	mi := &file_collective_v1_collective_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsResponse) ProtoMessage() {}
+
+func (x *ListAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsResponse.ProtoReflect.Descriptor instead.
+func (*ListAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAgentsResponse) GetAgents() []*Agent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type GetAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codename      string                 `protobuf:"bytes,1,opt,name=codename,proto3" json:"codename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentRequest) Reset() {
+	*x = GetAgentRequest{}
+	mi := &file_collective_v1_collective_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentRequest) ProtoMessage() {}
+
+func (x *GetAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentRequest) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAgentRequest) GetCodename() string {
+	if x != nil {
+		return x.Codename
+	}
+	return ""
+}
+
+type GetAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agent         *Agent                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentResponse) Reset() {
+	*x = GetAgentResponse{}
+	mi := &file_collective_v1_collective_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentResponse) ProtoMessage() {}
+
+func (x *GetAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentResponse) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAgentResponse) GetAgent() *Agent {
+	if x != nil {
+		return x.Agent
+	}
+	return nil
+}
+
+// Agent describes a registered agent.
+type Agent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Codename      string                 `protobuf:"bytes,2,opt,name=codename,proto3" json:"codename,omitempty"`
+	Tier          int32                  `protobuf:"varint,3,opt,name=tier,proto3" json:"tier,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Specialty     string                 `protobuf:"bytes,5,opt,name=specialty,proto3" json:"specialty,omitempty"`
+	Philosophy    string                 `protobuf:"bytes,6,opt,name=philosophy,proto3" json:"philosophy,omitempty"`
+	Directives    []string               `protobuf:"bytes,7,rep,name=directives,proto3" json:"directives,omitempty"`
+	Keywords      []string               `protobuf:"bytes,8,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	Examples      []string               `protobuf:"bytes,9,rep,name=examples,proto3" json:"examples,omitempty"`
+	Collaborators []string               `protobuf:"bytes,10,rep,name=collaborators,proto3" json:"collaborators,omitempty"`
+	Category      string                 `protobuf:"bytes,11,opt,name=category,proto3" json:"category,omitempty"`
+	// Lifecycle is set when the agent is deprecated or removed.
+	Lifecycle *AgentLifecycle `protobuf:"bytes,12,opt,name=lifecycle,proto3" json:"lifecycle,omitempty"`
+	// Aliases are former codenames that still route to the agent.
+	Aliases       []string `protobuf:"bytes,13,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Agent) Reset() {
+	*x = Agent{}
+	mi := &file_collective_v1_collective_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Agent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Agent) ProtoMessage() {}
+
+func (x *Agent) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
+func (*Agent) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Agent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Agent) GetCodename() string {
+	if x != nil {
+		return x.Codename
+	}
+	return ""
+}
+
+func (x *Agent) GetTier() int32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *Agent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Agent) GetSpecialty() string {
+	if x != nil {
+		return x.Specialty
+	}
+	return ""
+}
+
+func (x *Agent) GetPhilosophy() string {
+	if x != nil {
+		return x.Philosophy
+	}
+	return ""
+}
+
+func (x *Agent) GetDirectives() []string {
+	if x != nil {
+		return x.Directives
+	}
+	return nil
+}
+
+func (x *Agent) GetKeywords() []string {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+func (x *Agent) GetExamples() []string {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *Agent) GetCollaborators() []string {
+	if x != nil {
+		return x.Collaborators
+	}
+	return nil
+}
+
+func (x *Agent) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Agent) GetLifecycle() *AgentLifecycle {
+	if x != nil {
+		return x.Lifecycle
+	}
+	return nil
+}
+
+func (x *Agent) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+// AgentLifecycle describes where an agent is in its lifecycle. Dates are
+// in YYYY-MM-DD form.
+type AgentLifecycle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// State is active, deprecated or removed.
+	State         string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	DeprecatedOn  string `protobuf:"bytes,2,opt,name=deprecated_on,json=deprecatedOn,proto3" json:"deprecated_on,omitempty"`
+	RemovalDate   string `protobuf:"bytes,3,opt,name=removal_date,json=removalDate,proto3" json:"removal_date,omitempty"`
+	ReplacedBy    string `protobuf:"bytes,4,opt,name=replaced_by,json=replacedBy,proto3" json:"replaced_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentLifecycle) Reset() {
+	*x = AgentLifecycle{}
+	mi := &file_collective_v1_collective_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentLifecycle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentLifecycle) ProtoMessage() {}
+
+func (x *AgentLifecycle) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentLifecycle.ProtoReflect.Descriptor instead.
+func (*AgentLifecycle) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AgentLifecycle) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *AgentLifecycle) GetDeprecatedOn() string {
+	if x != nil {
+		return x.DeprecatedOn
+	}
+	return ""
+}
+
+func (x *AgentLifecycle) GetRemovalDate() string {
+	if x != nil {
+		return x.RemovalDate
+	}
+	return ""
+}
+
+func (x *AgentLifecycle) GetReplacedBy() string {
+	if x != nil {
+		return x.ReplacedBy
+	}
+	return ""
+}
+
+// Message is a message of a conversation.
+type Message struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Role is user, assistant or system.
+	Role          string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Content       string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_collective_v1_collective_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Message) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Message) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type InvokeAgentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codename      string                 `protobuf:"bytes,1,opt,name=codename,proto3" json:"codename,omitempty"`
+	Messages      []*Message             `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvokeAgentRequest) Reset() {
+	*x = InvokeAgentRequest{}
+	mi := &file_collective_v1_collective_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvokeAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvokeAgentRequest) ProtoMessage() {}
+
+func (x *InvokeAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvokeAgentRequest.ProtoReflect.Descriptor instead.
+func (*InvokeAgentRequest) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *InvokeAgentRequest) GetCodename() string {
+	if x != nil {
+		return x.Codename
+	}
+	return ""
+}
+
+func (x *InvokeAgentRequest) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *InvokeAgentRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+type InvokeAgentResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Agent is the codename of the agent that answered, after aliases are
+	// resolved.
+	Agent         string `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	Content       string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	FinishReason  string `protobuf:"bytes,3,opt,name=finish_reason,json=finishReason,proto3" json:"finish_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvokeAgentResponse) Reset() {
+	*x = InvokeAgentResponse{}
+	mi := &file_collective_v1_collective_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvokeAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvokeAgentResponse) ProtoMessage() {}
+
+func (x *InvokeAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvokeAgentResponse.ProtoReflect.Descriptor instead.
+func (*InvokeAgentResponse) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *InvokeAgentResponse) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *InvokeAgentResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *InvokeAgentResponse) GetFinishReason() string {
+	if x != nil {
+		return x.FinishReason
+	}
+	return ""
+}
+
+type AskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Question      string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskRequest) Reset() {
+	*x = AskRequest{}
+	mi := &file_collective_v1_collective_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskRequest) ProtoMessage() {}
+
+func (x *AskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskRequest.ProtoReflect.Descriptor instead.
+func (*AskRequest) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AskRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+type AskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Question      string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Answer        string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	Value         *structpb.Value        `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Entities      []string               `protobuf:"bytes,6,rep,name=entities,proto3" json:"entities,omitempty"`
+	Relation      string                 `protobuf:"bytes,7,opt,name=relation,proto3" json:"relation,omitempty"`
+	Inferred      bool                   `protobuf:"varint,8,opt,name=inferred,proto3" json:"inferred,omitempty"`
+	Reasoning     []string               `protobuf:"bytes,9,rep,name=reasoning,proto3" json:"reasoning,omitempty"`
+	Subgraph      *Subgraph              `protobuf:"bytes,10,opt,name=subgraph,proto3" json:"subgraph,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskResponse) Reset() {
+	*x = AskResponse{}
+	mi := &file_collective_v1_collective_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskResponse) ProtoMessage() {}
+
+func (x *AskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskResponse.ProtoReflect.Descriptor instead.
+func (*AskResponse) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AskResponse) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *AskResponse) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *AskResponse) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *AskResponse) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *AskResponse) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *AskResponse) GetEntities() []string {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *AskResponse) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+func (x *AskResponse) GetInferred() bool {
+	if x != nil {
+		return x.Inferred
+	}
+	return false
+}
+
+func (x *AskResponse) GetReasoning() []string {
+	if x != nil {
+		return x.Reasoning
+	}
+	return nil
+}
+
+func (x *AskResponse) GetSubgraph() *Subgraph {
+	if x != nil {
+		return x.Subgraph
+	}
+	return nil
+}
+
+// Subgraph is the part of the knowledge graph an answer came from.
+type Subgraph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Relations     []*Relation            `protobuf:"bytes,2,rep,name=relations,proto3" json:"relations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Subgraph) Reset() {
+	*x = Subgraph{}
+	mi := &file_collective_v1_collective_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Subgraph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subgraph) ProtoMessage() {}
+
+func (x *Subgraph) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subgraph.ProtoReflect.Descriptor instead.
+func (*Subgraph) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Subgraph) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Subgraph) GetRelations() []*Relation {
+	if x != nil {
+		return x.Relations
+	}
+	return nil
+}
+
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Properties    *structpb.Struct       `protobuf:"bytes,5,opt,name=properties,proto3" json:"properties,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_collective_v1_collective_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Node) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Node) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Node) GetProperties() *structpb.Struct {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+type Relation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Weight        float64                `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,6,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Relation) Reset() {
+	*x = Relation{}
+	mi := &file_collective_v1_collective_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Relation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Relation) ProtoMessage() {}
+
+func (x *Relation) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Relation.ProtoReflect.Descriptor instead.
+func (*Relation) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Relation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Relation) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Relation) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Relation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Relation) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Relation) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type QueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_collective_v1_collective_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *QueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type QueryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Vars  []string               `protobuf:"bytes,1,rep,name=vars,proto3" json:"vars,omitempty"`
+	// Rows bind each variable to a value.
+	Rows          []*structpb.Struct `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	Plan          []*PlanStep        `protobuf:"bytes,3,rep,name=plan,proto3" json:"plan,omitempty"`
+	Truncated     bool               `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_collective_v1_collective_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QueryResponse) GetVars() []string {
+	if x != nil {
+		return x.Vars
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetRows() []*structpb.Struct {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetPlan() []*PlanStep {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+// PlanStep is a step of the query plan.
+type PlanStep struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pattern       string                 `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Access        string                 `protobuf:"bytes,2,opt,name=access,proto3" json:"access,omitempty"`
+	Estimate      int32                  `protobuf:"varint,3,opt,name=estimate,proto3" json:"estimate,omitempty"`
+	Filters       []string               `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanStep) Reset() {
+	*x = PlanStep{}
+	mi := &file_collective_v1_collective_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanStep) ProtoMessage() {}
+
+func (x *PlanStep) ProtoReflect() protoreflect.Message {
+	mi := &file_collective_v1_collective_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanStep.ProtoReflect.Descriptor instead.
+func (*PlanStep) Descriptor() ([]byte, []int) {
+	return file_collective_v1_collective_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PlanStep) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PlanStep) GetAccess() string {
+	if x != nil {
+		return x.Access
+	}
+	return ""
+}
+
+func (x *PlanStep) GetEstimate() int32 {
+	if x != nil {
+		return x.Estimate
+	}
+	return 0
+}
+
+func (x *PlanStep) GetFilters() []string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+var File_collective_v1_collective_proto protoreflect.FileDescriptor
+
+const file_collective_v1_collective_proto_rawDesc = "" +
+	"\n" +
+	"\x1ecollective/v1/collective.proto\x12\rcollective.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x13\n" +
+	"\x11ListAgentsRequest\"B\n" +
+	"\x12ListAgentsResponse\x12,\n" +
+	"\x06agents\x18\x01 \x03(\v2\x14.collective.v1.AgentR\x06agents\"-\n" +
+	"\x0fGetAgentRequest\x12\x1a\n" +
+	"\bcodename\x18\x01 \x01(\tR\bcodename\">\n" +
+	"\x10GetAgentResponse\x12*\n" +
+	"\x05agent\x18\x01 \x01(\v2\x14.collective.v1.AgentR\x05agent\"\x8a\x03\n" +
+	"\x05Agent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bcodename\x18\x02 \x01(\tR\bcodename\x12\x12\n" +
+	"\x04tier\x18\x03 \x01(\x05R\x04tier\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1c\n" +
+	"\tspecialty\x18\x05 \x01(\tR\tspecialty\x12\x1e\n" +
+	"\n" +
+	"philosophy\x18\x06 \x01(\tR\n" +
+	"philosophy\x12\x1e\n" +
+	"\n" +
+	"directives\x18\a \x03(\tR\n" +
+	"directives\x12\x1a\n" +
+	"\bkeywords\x18\b \x03(\tR\bkeywords\x12\x1a\n" +
+	"\bexamples\x18\t \x03(\tR\bexamples\x12$\n" +
+	"\rcollaborators\x18\n" +
+	" \x03(\tR\rcollaborators\x12\x1a\n" +
+	"\bcategory\x18\v \x01(\tR\bcategory\x12;\n" +
+	"\tlifecycle\x18\f \x01(\v2\x1d.collective.v1.AgentLifecycleR\tlifecycle\x12\x18\n" +
+	"\aaliases\x18\r \x03(\tR\aaliases\"\x8f\x01\n" +
+	"\x0eAgentLifecycle\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12#\n" +
+	"\rdeprecated_on\x18\x02 \x01(\tR\fdeprecatedOn\x12!\n" +
+	"\fremoval_date\x18\x03 \x01(\tR\vremovalDate\x12\x1f\n" +
+	"\vreplaced_by\x18\x04 \x01(\tR\n" +
+	"replacedBy\"7\n" +
+	"\aMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"z\n" +
+	"\x12InvokeAgentRequest\x12\x1a\n" +
+	"\bcodename\x18\x01 \x01(\tR\bcodename\x122\n" +
+	"\bmessages\x18\x02 \x03(\v2\x16.collective.v1.MessageR\bmessages\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\"j\n" +
+	"\x13InvokeAgentResponse\x12\x14\n" +
+	"\x05agent\x18\x01 \x01(\tR\x05agent\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12#\n" +
+	"\rfinish_reason\x18\x03 \x01(\tR\ffinishReason\"(\n" +
+	"\n" +
+	"AskRequest\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\"\xca\x02\n" +
+	"\vAskResponse\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\x12,\n" +
+	"\x05value\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x05value\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\x01R\n" +
+	"confidence\x12\x1a\n" +
+	"\bentities\x18\x06 \x03(\tR\bentities\x12\x1a\n" +
+	"\brelation\x18\a \x01(\tR\brelation\x12\x1a\n" +
+	"\binferred\x18\b \x01(\bR\binferred\x12\x1c\n" +
+	"\treasoning\x18\t \x03(\tR\treasoning\x123\n" +
+	"\bsubgraph\x18\n" +
+	" \x01(\v2\x17.collective.v1.SubgraphR\bsubgraph\"l\n" +
+	"\bSubgraph\x12)\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x13.collective.v1.NodeR\x05nodes\x125\n" +
+	"\trelations\x18\x02 \x03(\v2\x17.collective.v1.RelationR\trelations\"\x99\x01\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x127\n" +
+	"\n" +
+	"properties\x18\x05 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"properties\"\x96\x01\n" +
+	"\bRelation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x16\n" +
+	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x06 \x01(\x01R\n" +
+	"confidence\"$\n" +
+	"\fQueryRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"\x9b\x01\n" +
+	"\rQueryResponse\x12\x12\n" +
+	"\x04vars\x18\x01 \x03(\tR\x04vars\x12+\n" +
+	"\x04rows\x18\x02 \x03(\v2\x17.google.protobuf.StructR\x04rows\x12+\n" +
+	"\x04plan\x18\x03 \x03(\v2\x17.collective.v1.PlanStepR\x04plan\x12\x1c\n" +
+	"\ttruncated\x18\x04 \x01(\bR\ttruncated\"r\n" +
+	"\bPlanStep\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\x12\x16\n" +
+	"\x06access\x18\x02 \x01(\tR\x06access\x12\x1a\n" +
+	"\bestimate\x18\x03 \x01(\x05R\bestimate\x12\x18\n" +
+	"\afilters\x18\x04 \x03(\tR\afilters2\x84\x02\n" +
+	"\fAgentService\x12Q\n" +
+	"\n" +
+	"ListAgents\x12 .collective.v1.ListAgentsRequest\x1a!.collective.v1.ListAgentsResponse\x12K\n" +
+	"\bGetAgent\x12\x1e.collective.v1.GetAgentRequest\x1a\x1f.collective.v1.GetAgentResponse\x12T\n" +
+	"\vInvokeAgent\x12!.collective.v1.InvokeAgentRequest\x1a\".collective.v1.InvokeAgentResponse2\x91\x01\n" +
+	"\rMemoryService\x12<\n" +
+	"\x03Ask\x12\x19.collective.v1.AskRequest\x1a\x1a.collective.v1.AskResponse\x12B\n" +
+	"\x05Query\x12\x1b.collective.v1.QueryRequest\x1a\x1c.collective.v1.QueryResponseB_Z]github.com/iamthegreatdestroyer/elite-agent-collective/backend/api/collective/v1;collectivev1b\x06proto3"
+
+var (
+	file_collective_v1_collective_proto_rawDescOnce sync.Once
+	file_collective_v1_collective_proto_rawDescData []byte
+)
+
+func file_collective_v1_collective_proto_rawDescGZIP() []byte {
+	file_collective_v1_collective_proto_rawDescOnce.Do(func() {
+		file_collective_v1_collective_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_collective_v1_collective_proto_rawDesc), len(file_collective_v1_collective_proto_rawDesc)))
+	})
+	return file_collective_v1_collective_proto_rawDescData
+}
+
+var file_collective_v1_collective_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_collective_v1_collective_proto_goTypes = []any{
+	(*ListAgentsRequest)(nil),   // 0: collective.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),  // 1: collective.v1.ListAgentsResponse
+	(*GetAgentRequest)(nil),     // 2: collective.v1.GetAgentRequest
+	(*GetAgentResponse)(nil),    // 3: collective.v1.GetAgentResponse
+	(*Agent)(nil),               // 4: collective.v1.Agent
+	(*AgentLifecycle)(nil),      // 5: collective.v1.AgentLifecycle
+	(*Message)(nil),             // 6: collective.v1.Message
+	(*InvokeAgentRequest)(nil),  // 7: collective.v1.InvokeAgentRequest
+	(*InvokeAgentResponse)(nil), // 8: collective.v1.InvokeAgentResponse
+	(*AskRequest)(nil),          // 9: collective.v1.AskRequest
+	(*AskResponse)(nil),         // 10: collective.v1.AskResponse
+	(*Subgraph)(nil),            // 11: collective.v1.Subgraph
+	(*Node)(nil),                // 12: collective.v1.Node
+	(*Relation)(nil),            // 13: collective.v1.Relation
+	(*QueryRequest)(nil),        // 14: collective.v1.QueryRequest
+	(*QueryResponse)(nil),       // 15: collective.v1.QueryResponse
+	(*PlanStep)(nil),            // 16: collective.v1.PlanStep
+	(*structpb.Value)(nil),      // 17: google.protobuf.Value
+	(*structpb.Struct)(nil),     // 18: google.protobuf.Struct
+}
+var file_collective_v1_collective_proto_depIdxs = []int32{
+	4,  // 0: collective.v1.ListAgentsResponse.agents:type_name -> collective.v1.Agent
+	4,  // 1: collective.v1.GetAgentResponse.agent:type_name -> collective.v1.Agent
+	5,  // 2: collective.v1.Agent.lifecycle:type_name -> collective.v1.AgentLifecycle
+	6,  // 3: collective.v1.InvokeAgentRequest.messages:type_name -> collective.v1.Message
+	17, // 4: collective.v1.AskResponse.value:type_name -> google.protobuf.Value
+	11, // 5: collective.v1.AskResponse.subgraph:type_name -> collective.v1.Subgraph
+	12, // 6: collective.v1.Subgraph.nodes:type_name -> collective.v1.Node
+	13, // 7: collective.v1.Subgraph.relations:type_name -> collective.v1.Relation
+	18, // 8: collective.v1.Node.properties:type_name -> google.protobuf.Struct
+	18, // 9: collective.v1.QueryResponse.rows:type_name -> google.protobuf.Struct
+	16, // 10: collective.v1.QueryResponse.plan:type_name -> collective.v1.PlanStep
+	0,  // 11: collective.v1.AgentService.ListAgents:input_type -> collective.v1.ListAgentsRequest
+	2,  // 12: collective.v1.AgentService.GetAgent:input_type -> collective.v1.GetAgentRequest
+	7,  // 13: collective.v1.AgentService.InvokeAgent:input_type -> collective.v1.InvokeAgentRequest
+	9,  // 14: collective.v1.MemoryService.Ask:input_type -> collective.v1.AskRequest
+	14, // 15: collective.v1.MemoryService.Query:input_type -> collective.v1.QueryRequest
+	1,  // 16: collective.v1.AgentService.ListAgents:output_type -> collective.v1.ListAgentsResponse
+	3,  // 17: collective.v1.AgentService.GetAgent:output_type -> collective.v1.GetAgentResponse
+	8,  // 18: collective.v1.AgentService.InvokeAgent:output_type -> collective.v1.InvokeAgentResponse
+	10, // 19: collective.v1.MemoryService.Ask:output_type -> collective.v1.AskResponse
+	15, // 20: collective.v1.MemoryService.Query:output_type -> collective.v1.QueryResponse
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_collective_v1_collective_proto_init() }
+func file_collective_v1_collective_proto_init() {
+	if File_collective_v1_collective_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_collective_v1_collective_proto_rawDesc), len(file_collective_v1_collective_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_collective_v1_collective_proto_goTypes,
+		DependencyIndexes: file_collective_v1_collective_proto_depIdxs,
+		MessageInfos:      file_collective_v1_collective_proto_msgTypes,
+	}.Build()
+	File_collective_v1_collective_proto = out.File
+	file_collective_v1_collective_proto_goTypes = nil
+	file_collective_v1_collective_proto_depIdxs = nil
+}
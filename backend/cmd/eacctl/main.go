@@ -0,0 +1,349 @@
+// Package main is the entry point for eacctl, the Elite Agent Collective
+// operations tool.
+//
+// Usage:
+//
+//	eacctl memory migrate -snapshot PATH [-to VERSION] [-dry-run] [-no-backup]
+//	eacctl memory reindex [-online] [-server URL] [-token TOKEN]
+//	eacctl routing replay (-model FILE | -version NAME=N ...) [-from TIME] [-to TIME] [-limit N] [-server URL] [-token TOKEN]
+//	eacctl apikey new -id ID -scopes SCOPE,... [-subject SUBJECT] [-expires DATE]
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command line and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) >= 2 && args[0] == "memory" {
+		switch args[1] {
+		case "migrate":
+			return memoryMigrate(args[2:], stdout, stderr)
+		case "reindex":
+			return memoryReindex(args[2:], stdout, stderr)
+		}
+	}
+	if len(args) >= 2 && args[0] == "routing" && args[1] == "replay" {
+		return routingReplay(args[2:], stdout, stderr)
+	}
+	if len(args) >= 2 && args[0] == "apikey" && args[1] == "new" {
+		return apiKeyNew(args[2:], stdout, stderr)
+	}
+	fmt.Fprintln(stderr, "usage: eacctl memory migrate -snapshot PATH [-to VERSION] [-dry-run] [-no-backup]")
+	fmt.Fprintln(stderr, "       eacctl memory reindex [-online] [-server URL] [-token TOKEN]")
+	fmt.Fprintln(stderr, "       eacctl routing replay (-model FILE | -version NAME=N ...) [-from TIME] [-to TIME] [-limit N] [-server URL] [-token TOKEN]")
+	fmt.Fprintln(stderr, "       eacctl apikey new -id ID -scopes SCOPE,... [-subject SUBJECT] [-expires DATE]")
+	return 2
+}
+
+// memoryMigrate migrates a persisted snapshot file between format versions.
+func memoryMigrate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("memory migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	snapshot := fs.String("snapshot", os.Getenv("MEMORY_SNAPSHOT_PATH"), "snapshot file to migrate")
+	to := fs.Int("to", memory.LatestSnapshotVersion(), "target format version (lower to downgrade)")
+	dryRun := fs.Bool("dry-run", false, "validate the migration without writing")
+	noBackup := fs.Bool("no-backup", false, "don't keep a copy of the original file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *snapshot == "" {
+		fmt.Fprintln(stderr, "eacctl: -snapshot or MEMORY_SNAPSHOT_PATH is required")
+		return 2
+	}
+
+	result, err := memory.MigrateSnapshotFile(*snapshot, *to, *dryRun, !*noBackup)
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 1
+	}
+
+	if len(result.Steps) == 0 {
+		fmt.Fprintf(stdout, "%s is already at version %d\n", result.Path, result.To)
+		return 0
+	}
+	for _, step := range result.Steps {
+		fmt.Fprintf(stdout, "  %-4s v%d -> v%d  %s\n", step.Direction, step.From, step.To, step.Description)
+	}
+	if result.DryRun {
+		fmt.Fprintf(stdout, "dry run: %s would be migrated from version %d to %d\n", result.Path, result.From, result.To)
+		return 0
+	}
+	fmt.Fprintf(stdout, "migrated %s from version %d to %d\n", result.Path, result.From, result.To)
+	if result.Backup != "" {
+		fmt.Fprintf(stdout, "original kept at %s\n", result.Backup)
+	}
+	return 0
+}
+
+// memoryReindex has a running server rebuild its derived indexes and prints
+// the progress it streams back.
+func memoryReindex(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("memory reindex", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", os.Getenv("EACCTL_SERVER"), "server base URL (default http://localhost:8080)")
+	token := fs.String("token", os.Getenv("EACCTL_TOKEN"), "bearer token of an admin subject")
+	online := fs.Bool("online", false, "build shadow indexes and swap them in, serving reads throughout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *server == "" {
+		*server = "http://localhost:8080"
+	}
+
+	url := strings.TrimRight(*server, "/") + "/admin/memory/reindex?online=" + strconv.FormatBool(*online)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 2
+	}
+	if *token != "" {
+		req.Header.Set("Authorization", "Bearer "+*token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) != nil || body.Error == "" {
+			body.Error = http.StatusText(resp.StatusCode)
+		}
+		fmt.Fprintf(stderr, "eacctl: reindex rejected with status %d: %s\n", resp.StatusCode, body.Error)
+		return 1
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event memory.ReindexEvent
+		if err := decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			fmt.Fprintf(stderr, "eacctl: reading progress: %v\n", err)
+			return 1
+		}
+
+		switch event.Type {
+		case memory.ReindexProgressEvent:
+			if event.ReindexProgress != nil && event.Total > 0 {
+				fmt.Fprintf(stdout, "  %-26s %3d%%  %d/%d\n", event.Index, event.Done*100/event.Total, event.Done, event.Total)
+			}
+		case memory.ReindexSummaryEvent, memory.ReindexErrorEvent:
+			report := event.Report
+			if report == nil {
+				report = &memory.ReindexReport{Online: *online}
+			}
+			for _, result := range report.Indexes {
+				if result.Error == "" {
+					fmt.Fprintf(stdout, "rebuilt %s from %d items in %s\n", result.Index, result.Items, result.Duration)
+				}
+			}
+			if event.Type == memory.ReindexErrorEvent {
+				fmt.Fprintf(stderr, "eacctl: %s\n", event.Error)
+				return 1
+			}
+			mode := "offline"
+			if report.Online {
+				mode = "online"
+			}
+			fmt.Fprintf(stdout, "reindexed %d indexes %s in %s\n", len(report.Indexes), mode, report.Elapsed)
+			return 0
+		}
+	}
+}
+
+// versionFlags collects repeated -version NAME=N flags.
+type versionFlags map[string]int
+
+func (v versionFlags) String() string {
+	return fmt.Sprint(map[string]int(v))
+}
+
+func (v versionFlags) Set(raw string) error {
+	name, version, ok := strings.Cut(raw, "=")
+	n, err := strconv.Atoi(version)
+	if !ok || name == "" || err != nil || n < 1 {
+		return fmt.Errorf("%q is not NAME=VERSION", raw)
+	}
+	v[name] = n
+	return nil
+}
+
+// routingReplay has a running server replay its recorded queries against
+// a candidate routing model and prints how the candidate compares with
+// the current routing.
+func routingReplay(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("routing replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", os.Getenv("EACCTL_SERVER"), "server base URL (default http://localhost:8080)")
+	token := fs.String("token", os.Getenv("EACCTL_TOKEN"), "bearer token of an admin subject")
+	model := fs.String("model", "", "routing model artifact (JSON) to evaluate")
+	versions := versionFlags{}
+	fs.Var(versions, "version", "model registry version to evaluate, as NAME=VERSION (repeatable)")
+	from := fs.String("from", "", "replay queries recorded from this RFC 3339 time")
+	to := fs.String("to", "", "replay queries recorded before this RFC 3339 time")
+	limit := fs.Int("limit", 0, "replay only the latest queries (default 1000)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if (*model == "") == (len(versions) == 0) {
+		fmt.Fprintln(stderr, "eacctl: exactly one of -model and -version is required")
+		return 2
+	}
+	if *server == "" {
+		*server = "http://localhost:8080"
+	}
+
+	request := memory.RoutingReplayRequest{Limit: *limit}
+	if *model != "" {
+		data, err := os.ReadFile(*model)
+		if err != nil {
+			fmt.Fprintf(stderr, "eacctl: %v\n", err)
+			return 1
+		}
+		request.Candidate = &memory.RoutingModel{}
+		if err := json.Unmarshal(data, request.Candidate); err != nil {
+			fmt.Fprintf(stderr, "eacctl: %s is not a routing model: %v\n", *model, err)
+			return 1
+		}
+	} else {
+		request.Versions = versions
+	}
+	for _, bound := range []struct {
+		name, raw string
+		t         *time.Time
+	}{{"from", *from, &request.From}, {"to", *to, &request.To}} {
+		if bound.raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.raw)
+		if err != nil {
+			fmt.Fprintf(stderr, "eacctl: -%s must be an RFC 3339 time\n", bound.name)
+			return 2
+		}
+		*bound.t = t
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 2
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(*server, "/")+"/admin/memory/routing/replay", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 2
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if *token != "" {
+		req.Header.Set("Authorization", "Bearer "+*token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) != nil || body.Error == "" {
+			body.Error = http.StatusText(resp.StatusCode)
+		}
+		fmt.Fprintf(stderr, "eacctl: replay rejected with status %d: %s\n", resp.StatusCode, body.Error)
+		return 1
+	}
+	var report memory.RoutingReplayReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		fmt.Fprintf(stderr, "eacctl: reading report: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "replayed %d queries, predicting success from %d outcomes\n", report.Queries, report.Outcomes)
+	fmt.Fprintf(stdout, "  %-10s blend %.2f/%.2f  predicted success %.3f\n", "current", report.Current.Weights.Keyword, report.Current.Weights.Similarity, report.Current.PredictedSuccess)
+	fmt.Fprintf(stdout, "  %-10s blend %.2f/%.2f  predicted success %.3f\n", "candidate", report.Candidate.Weights.Keyword, report.Candidate.Weights.Similarity, report.Candidate.PredictedSuccess)
+	fmt.Fprintf(stdout, "%d queries routed differently (%.1f%% agreement), %d predicted to do worse\n", report.Changed, report.Agreement*100, report.Regressions)
+	for _, change := range report.Changes {
+		fmt.Fprintf(stdout, "  %-10s -> %-10s %.3f -> %.3f  %s\n", change.Current, change.Candidate, change.CurrentSuccess, change.CandidateSuccess, change.Query)
+	}
+	fmt.Fprintf(stdout, "predicted improvement %+.3f\n", report.Improvement)
+	return 0
+}
+
+// apiKeyNew generates an API key, printing the key for its holder and the
+// entry to add to the server's API keys file, which holds only its hash.
+func apiKeyNew(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("apikey new", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	id := fs.String("id", "", "name of the key, e.g. ci-indexer")
+	scopes := fs.String("scopes", "", "comma-separated scopes: agents, memory, memory:write, sessions, tools, admin or *")
+	subject := fs.String("subject", "", "subject requests are made as (default apikey:ID)")
+	expires := fs.String("expires", "", "date (YYYY-MM-DD) or RFC 3339 time the key stops working")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 1
+	}
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "  - id: %s\n    sha256: %s\n", *id, hash)
+	if *subject != "" {
+		fmt.Fprintf(&entry, "    subject: %q\n", *subject)
+	}
+	entry.WriteString("    scopes: [")
+	for i, scope := range strings.Split(*scopes, ",") {
+		if i > 0 {
+			entry.WriteString(", ")
+		}
+		fmt.Fprintf(&entry, "%q", strings.TrimSpace(scope))
+	}
+	entry.WriteString("]\n")
+	if *expires != "" {
+		at, err := time.Parse(time.RFC3339, *expires)
+		if err != nil {
+			at, err = time.Parse(time.DateOnly, *expires)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "eacctl: -expires %q is not a date or RFC 3339 time\n", *expires)
+			return 2
+		}
+		fmt.Fprintf(&entry, "    expires: %s\n", at.UTC().Format(time.RFC3339))
+	}
+	// The entry is checked as the server will load it
+	if _, err := auth.ParseAPIKeys([]byte("keys:\n" + entry.String())); err != nil {
+		fmt.Fprintf(stderr, "eacctl: %v\n", err)
+		return 2
+	}
+
+	fmt.Fprintf(stdout, "key: %s\n\n", key)
+	fmt.Fprintf(stdout, "Give the key to its holder; it is not shown again. Add its entry under keys: in API_KEYS_CONFIG:\n\n%s", entry.String())
+	return 0
+}
@@ -0,0 +1,99 @@
+// Package main is the entry point for loadgen, which drives realistic
+// mixed traffic against an Elite Agent Collective server and reports
+// latency percentiles per operation.
+//
+// Usage:
+//
+//	loadgen [-target URL] [-concurrency N] [-duration D] [-rate R]
+//	        [-mix invoke=50,route=30,feedback=15,ingest=5] [-token TOKEN]
+//	        [-timeout D] [-seed N] [-json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command line and returns the process exit code. An
+// interrupt ends the run early and still prints the report.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", envOr("LOADGEN_TARGET", "http://localhost:8080"), "base URL of the server")
+	concurrency := fs.Int("concurrency", 16, "concurrent clients")
+	duration := fs.Duration("duration", 30*time.Second, "how long to generate traffic")
+	rate := fs.Float64("rate", 0, "total requests per second across clients; 0 is as fast as they go")
+	mix := fs.String("mix", defaultMix, "relative weights of the operations")
+	token := fs.String("token", os.Getenv("LOADGEN_TOKEN"), "bearer token for authenticated endpoints")
+	timeout := fs.Duration("timeout", 10*time.Second, "per-request timeout")
+	seed := fs.Int64("seed", 1, "seed for the generated traffic, so runs are repeatable")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	weights, err := parseMix(*mix)
+	if err != nil {
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 2
+	}
+	if *concurrency < 1 || *duration <= 0 || *rate < 0 {
+		fmt.Fprintln(stderr, "loadgen: -concurrency and -duration must be positive and -rate not negative")
+		return 2
+	}
+
+	gen := &generator{
+		target:  strings.TrimRight(*target, "/"),
+		token:   *token,
+		client:  &http.Client{Timeout: *timeout},
+		weights: weights,
+		seed:    *seed,
+	}
+	if err := gen.discoverAgents(ctx); err != nil {
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "loadgen: %d clients against %s for %s (%d agents)\n", *concurrency, gen.target, *duration, len(gen.agents))
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+	report := gen.run(runCtx, *concurrency, *rate)
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "loadgen: %v\n", err)
+			return 1
+		}
+	} else {
+		report.print(stdout)
+	}
+	if report.Total.Requests == 0 {
+		fmt.Fprintln(stderr, "loadgen: no requests completed")
+		return 1
+	}
+	return 0
+}
+
+// envOr returns an environment variable, or def when it is unset.
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
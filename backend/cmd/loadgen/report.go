@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// maxSampleErrors bounds the distinct error messages kept per operation.
+const maxSampleErrors = 5
+
+// Report summarizes a run.
+type Report struct {
+	DurationMs int64 `json:"duration_ms"`
+	// Total covers every operation
+	Total      OperationStats   `json:"total"`
+	Operations []OperationStats `json:"operations"`
+}
+
+// OperationStats summarizes the requests of one operation. Latencies are
+// in milliseconds and include failed requests.
+type OperationStats struct {
+	Operation string `json:"operation"`
+	Requests  int    `json:"requests"`
+	Errors    int    `json:"errors"`
+	// Throughput is requests per second over the run
+	Throughput float64 `json:"throughput"`
+	MeanMs     float64 `json:"mean_ms"`
+	P50Ms      float64 `json:"p50_ms"`
+	P90Ms      float64 `json:"p90_ms"`
+	P95Ms      float64 `json:"p95_ms"`
+	P99Ms      float64 `json:"p99_ms"`
+	MaxMs      float64 `json:"max_ms"`
+	// Statuses counts responses by HTTP status; 0 counts requests that got
+	// no response
+	Statuses map[string]int `json:"statuses"`
+	// SampleErrors holds the first distinct errors seen
+	SampleErrors []string `json:"sample_errors,omitempty"`
+}
+
+// samples are the results of one operation's requests.
+type samples struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+	messages  []string
+}
+
+// recorder collects results from concurrent clients.
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*samples
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: make(map[string]*samples)}
+}
+
+// record adds a request's result.
+func (r *recorder) record(op string, latency time.Duration, status int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.ops[op]
+	if !ok {
+		s = &samples{statuses: make(map[int]int)}
+		r.ops[op] = s
+	}
+	s.latencies = append(s.latencies, latency)
+	s.statuses[status]++
+	if err == nil {
+		return
+	}
+	s.errors++
+	if len(s.messages) < maxSampleErrors {
+		message := err.Error()
+		for _, seen := range s.messages {
+			if seen == message {
+				return
+			}
+		}
+		s.messages = append(s.messages, message)
+	}
+}
+
+// report computes the stats of everything recorded over elapsed.
+func (r *recorder) report(elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &Report{DurationMs: elapsed.Milliseconds()}
+	all := &samples{statuses: make(map[int]int)}
+	for _, op := range operations {
+		s, ok := r.ops[op]
+		if !ok {
+			continue
+		}
+		report.Operations = append(report.Operations, s.stats(op, elapsed))
+		all.latencies = append(all.latencies, s.latencies...)
+		all.errors += s.errors
+		for status, n := range s.statuses {
+			all.statuses[status] += n
+		}
+	}
+	report.Total = all.stats("total", elapsed)
+	return report
+}
+
+// stats computes an operation's stats. It sorts the latencies in place.
+func (s *samples) stats(op string, elapsed time.Duration) OperationStats {
+	stats := OperationStats{
+		Operation:    op,
+		Requests:     len(s.latencies),
+		Errors:       s.errors,
+		Statuses:     make(map[string]int, len(s.statuses)),
+		SampleErrors: s.messages,
+	}
+	for status, n := range s.statuses {
+		stats.Statuses[strconv.Itoa(status)] = n
+	}
+	if len(s.latencies) == 0 {
+		return stats
+	}
+	if elapsed > 0 {
+		stats.Throughput = float64(len(s.latencies)) / elapsed.Seconds()
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	var sum time.Duration
+	for _, latency := range s.latencies {
+		sum += latency
+	}
+	stats.MeanMs = milliseconds(sum / time.Duration(len(s.latencies)))
+	stats.P50Ms = milliseconds(percentile(s.latencies, 50))
+	stats.P90Ms = milliseconds(percentile(s.latencies, 90))
+	stats.P95Ms = milliseconds(percentile(s.latencies, 95))
+	stats.P99Ms = milliseconds(percentile(s.latencies, 99))
+	stats.MaxMs = milliseconds(s.latencies[len(s.latencies)-1])
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// print writes the report as a table, then any sample errors.
+func (r *Report) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operation\trequests\terrors\treq/s\tmean\tp50\tp90\tp95\tp99\tmax\t")
+	for _, stats := range append(r.Operations, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1fms\t%.1fms\t%.1fms\t%.1fms\t%.1fms\t%.1fms\t\n",
+			stats.Operation, stats.Requests, stats.Errors, stats.Throughput,
+			stats.MeanMs, stats.P50Ms, stats.P90Ms, stats.P95Ms, stats.P99Ms, stats.MaxMs)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d requests in %s\n", r.Total.Requests, time.Duration(r.DurationMs)*time.Millisecond)
+	for _, stats := range r.Operations {
+		for _, message := range stats.SampleErrors {
+			fmt.Fprintf(w, "%s error: %s\n", stats.Operation, message)
+		}
+	}
+}
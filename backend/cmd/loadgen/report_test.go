@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("p%v: expected %v, got %v", tt.p, tt.want, got)
+		}
+	}
+	if got := percentile([]time.Duration{7}, 99); got != 7 {
+		t.Errorf("expected the only sample, got %v", got)
+	}
+}
+
+func TestRecorderReport(t *testing.T) {
+	r := newRecorder()
+	for i := 1; i <= 10; i++ {
+		r.record(opInvoke, time.Duration(i)*time.Millisecond, 200, nil)
+	}
+	r.record(opIngest, 40*time.Millisecond, 503, errors.New("status 503"))
+	r.record(opIngest, 20*time.Millisecond, 503, errors.New("status 503"))
+	r.record(opIngest, time.Millisecond, 0, errors.New("connection refused"))
+
+	report := r.report(2 * time.Second)
+	if len(report.Operations) != 2 || report.Operations[0].Operation != opInvoke || report.Operations[1].Operation != opIngest {
+		t.Fatalf("expected invoke then ingest, got %+v", report.Operations)
+	}
+	invoke := report.Operations[0]
+	if invoke.Requests != 10 || invoke.Errors != 0 || invoke.Throughput != 5 {
+		t.Errorf("expected 10 requests at 5 req/s, got %+v", invoke)
+	}
+	if invoke.P50Ms != 5 || invoke.P90Ms != 9 || invoke.MaxMs != 10 || invoke.MeanMs != 5.5 {
+		t.Errorf("expected p50 5ms, p90 9ms, max 10ms and mean 5.5ms, got %+v", invoke)
+	}
+
+	ingest := report.Operations[1]
+	if ingest.Errors != 3 || ingest.Statuses["503"] != 2 || ingest.Statuses["0"] != 1 {
+		t.Errorf("expected 3 errors by status, got %+v", ingest)
+	}
+	if len(ingest.SampleErrors) != 2 {
+		t.Errorf("expected distinct sample errors, got %v", ingest.SampleErrors)
+	}
+	if report.Total.Requests != 13 || report.Total.Errors != 3 || report.Total.MaxMs != 40 {
+		t.Errorf("expected totals over both operations, got %+v", report.Total)
+	}
+
+	var out bytes.Buffer
+	report.print(&out)
+	if !strings.Contains(out.String(), "13 requests in 2s") || !strings.Contains(out.String(), "ingest error: connection refused") {
+		t.Errorf("expected summary and sample errors printed, got\n%s", out.String())
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// Operations loadgen generates.
+const (
+	// opInvoke calls one agent directly
+	opInvoke = "invoke"
+	// opRoute sends a Copilot request the server routes to an agent
+	opRoute = "route"
+	// opFeedback posts a batch of task outcomes
+	opFeedback = "feedback"
+	// opIngest streams nodes and relations into the knowledge graph
+	opIngest = "ingest"
+)
+
+// operations lists every operation, in report order.
+var operations = []string{opInvoke, opRoute, opFeedback, opIngest}
+
+// defaultMix weights the operations roughly as production traffic does:
+// mostly agent calls, with feedback and imports in the background.
+const defaultMix = "invoke=50,route=30,feedback=15,ingest=5"
+
+// fallbackAgents are used when the server's agent list can't be read.
+var fallbackAgents = []string{"APEX", "CIPHER", "ARCHITECT", "FORTRESS", "TENSOR", "FLUX", "VELOCITY", "ECLIPSE"}
+
+// topics and promptTemplates combine into requests like those users send.
+var (
+	topics = []string{
+		"a rate limiter for our public API",
+		"the JWT validation in the auth service",
+		"a Kafka consumer that keeps falling behind",
+		"the Postgres query behind the dashboard",
+		"our Kubernetes autoscaling settings",
+		"a retry policy for payment webhooks",
+		"the feature store for the ranking model",
+		"an LRU cache with per-entry TTLs",
+	}
+	promptTemplates = []string{
+		"Review %s and point out the riskiest parts.",
+		"How would you redesign %s to handle ten times the load?",
+		"Write tests for %s.",
+		"What are the security implications of %s?",
+		"Explain the trade-offs in %s to a new team member.",
+	}
+	taskTypes  = []string{"code_review", "design", "debugging", "security_audit", "optimization"}
+	strategies = []string{"", "", "divide_and_conquer", "pair_review", "spike"}
+)
+
+// weight is an operation's share of the mix.
+type weight struct {
+	op     string
+	weight int
+}
+
+// parseMix reads weights such as "invoke=50,route=30". Operations left
+// out get no traffic.
+func parseMix(mix string) ([]weight, error) {
+	var weights []weight
+	total := 0
+	for _, part := range strings.Split(mix, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, want op=weight", part)
+		}
+		known := false
+		for _, op := range operations {
+			known = known || op == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown operation %q in mix, want one of %s", name, strings.Join(operations, ", "))
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", value, name)
+		}
+		if n > 0 {
+			weights = append(weights, weight{op: name, weight: n})
+			total += n
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("mix has no traffic")
+	}
+	return weights, nil
+}
+
+// generator sends the traffic.
+type generator struct {
+	target  string
+	token   string
+	client  *http.Client
+	weights []weight
+	// seed makes runs repeatable; client i draws from seed+i
+	seed   int64
+	agents []string
+	// runID keeps imported node IDs apart from earlier runs'
+	runID string
+}
+
+// discoverAgents reads the codenames to call from GET /agents, falling back
+// to a fixed list if the server doesn't answer with any.
+func (g *generator) discoverAgents(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.target+"/agents", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	var agents []models.Agent
+	if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&agents) == nil {
+		for _, agent := range agents {
+			g.agents = append(g.agents, agent.Codename)
+		}
+	}
+	if len(g.agents) == 0 {
+		g.agents = fallbackAgents
+	}
+	return nil
+}
+
+// run drives traffic from concurrency clients until ctx is done. A
+// positive rate paces the clients to that many requests per second in
+// total.
+func (g *generator) run(ctx context.Context, concurrency int, rate float64) *Report {
+	var ticks <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	recorder := newRecorder()
+	started := time.Now()
+	g.runID = strconv.FormatInt(started.UnixNano(), 36)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(client int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(g.seed + int64(client)))
+			for sequence := 0; ; sequence++ {
+				if ticks != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticks:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+				op := g.pick(rng)
+				start := time.Now()
+				status, err := g.send(ctx, op, rng, fmt.Sprintf("%s-%d-%d", g.runID, client, sequence))
+				if ctx.Err() != nil {
+					// Cut off by the end of the run, not by the server
+					return
+				}
+				recorder.record(op, time.Since(start), status, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return recorder.report(time.Since(started))
+}
+
+// pick draws an operation by weight.
+func (g *generator) pick(rng *rand.Rand) string {
+	total := 0
+	for _, w := range g.weights {
+		total += w.weight
+	}
+	n := rng.Intn(total)
+	for _, w := range g.weights {
+		if n < w.weight {
+			return w.op
+		}
+		n -= w.weight
+	}
+	return g.weights[len(g.weights)-1].op
+}
+
+// send makes one request and returns its status. id makes the request's
+// generated records unique.
+func (g *generator) send(ctx context.Context, op string, rng *rand.Rand, id string) (int, error) {
+	var path, contentType string
+	var body []byte
+	var err error
+	switch op {
+	case opInvoke:
+		path = "/agents/" + g.agent(rng) + "/invoke"
+		body, err = json.Marshal(g.copilotRequest(rng, ""))
+	case opRoute:
+		// Half the requests name an agent; the rest are routed by content
+		mention := ""
+		if rng.Intn(2) == 0 {
+			mention = "@" + g.agent(rng) + " "
+		}
+		path = "/agent"
+		body, err = json.Marshal(g.copilotRequest(rng, mention))
+	case opFeedback:
+		path = "/feedback/batch"
+		body, err = json.Marshal(memory.FeedbackBatchRequest{Records: g.feedback(rng)})
+	case opIngest:
+		path, contentType = "/memory/ingest", "application/x-ndjson"
+		body, err = g.ingestStream(rng, id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.target+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if op == opIngest {
+		return resp.StatusCode, checkIngestSummary(resp.Body)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, err
+}
+
+// checkIngestSummary reads an ingestion response stream to its end and
+// reports a stream that failed or rejected records.
+func checkIngestSummary(body io.Reader) error {
+	var summary *memory.IngestEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var event memory.IngestEvent
+		if json.Unmarshal(scanner.Bytes(), &event) == nil && event.Type == memory.IngestSummaryEvent {
+			summary = &event
+		}
+	}
+	switch {
+	case scanner.Err() != nil:
+		return scanner.Err()
+	case summary == nil:
+		return errors.New("ingestion stream ended without a summary")
+	case summary.Error != "":
+		return errors.New(summary.Error)
+	case summary.IngestProgress != nil && summary.Rejected > 0:
+		return fmt.Errorf("%d records rejected", summary.Rejected)
+	}
+	return nil
+}
+
+// agent draws a codename.
+func (g *generator) agent(rng *rand.Rand) string {
+	return g.agents[rng.Intn(len(g.agents))]
+}
+
+// prompt draws a request a user might send.
+func prompt(rng *rand.Rand) string {
+	return fmt.Sprintf(promptTemplates[rng.Intn(len(promptTemplates))], topics[rng.Intn(len(topics))])
+}
+
+// copilotRequest builds a chat request, sometimes with earlier turns.
+func (g *generator) copilotRequest(rng *rand.Rand, mention string) models.CopilotRequest {
+	var messages []models.Message
+	if rng.Intn(4) == 0 {
+		messages = append(messages,
+			models.Message{Role: "user", Content: prompt(rng)},
+			models.Message{Role: "assistant", Content: "Here is a first pass at that."},
+		)
+	}
+	messages = append(messages, models.Message{Role: "user", Content: mention + prompt(rng)})
+	return models.CopilotRequest{Messages: messages}
+}
+
+// feedback builds a batch of 1 to 20 outcomes, mostly successes.
+func (g *generator) feedback(rng *rand.Rand) []memory.FeedbackRecord {
+	records := make([]memory.FeedbackRecord, 1+rng.Intn(20))
+	for i := range records {
+		record := memory.FeedbackRecord{
+			Query:    prompt(rng),
+			Agent:    g.agent(rng),
+			Success:  rng.Float64() < 0.8,
+			TaskType: taskTypes[rng.Intn(len(taskTypes))],
+			Strategy: strategies[rng.Intn(len(strategies))],
+		}
+		if rng.Intn(3) == 0 {
+			record.Collaborators = []string{g.agent(rng)}
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// ingestStream builds an NDJSON import of 10 to 100 concepts, each
+// related to the one before it. id keeps its node IDs apart from other
+// requests'.
+func (g *generator) ingestStream(rng *rand.Rand, id string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	count := 10 + rng.Intn(91)
+	for i := 0; i < count; i++ {
+		node := &memory.NodeView{
+			ID:         fmt.Sprintf("loadgen-%s-%d", id, i),
+			Label:      fmt.Sprintf("%s %d", topics[rng.Intn(len(topics))], i),
+			Type:       "concept",
+			Confidence: 0.5 + rng.Float64()/2,
+		}
+		if err := encoder.Encode(memory.IngestRecord{Node: node}); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			continue
+		}
+		relation := &memory.RelationView{
+			SourceID:   node.ID,
+			TargetID:   fmt.Sprintf("loadgen-%s-%d", id, i-1),
+			Type:       "related-to",
+			Weight:     rng.Float64(),
+			Confidence: 0.8,
+		}
+		if err := encoder.Encode(memory.IngestRecord{Relation: relation}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
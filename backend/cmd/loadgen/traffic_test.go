@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func TestParseMix(t *testing.T) {
+	weights, err := parseMix("invoke=3, route=1,ingest=0")
+	if err != nil {
+		t.Fatalf("expected valid mix, got %v", err)
+	}
+	if len(weights) != 2 || weights[0] != (weight{opInvoke, 3}) || weights[1] != (weight{opRoute, 1}) {
+		t.Errorf("expected invoke and route without ingest, got %v", weights)
+	}
+
+	for _, mix := range []string{"invoke", "search=1", "invoke=-1", "invoke=x", "invoke=0"} {
+		if _, err := parseMix(mix); err == nil {
+			t.Errorf("expected error for mix %q", mix)
+		}
+	}
+}
+
+// fakeServer answers like the server's endpoints and records what it got.
+type fakeServer struct {
+	mu       sync.Mutex
+	paths    map[string]int
+	tokens   map[string]bool
+	ingested int
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/agents/") && strings.HasSuffix(path, "/invoke") {
+		path = "/agents/{codename}/invoke"
+	}
+	f.paths[path]++
+	f.tokens[r.Header.Get("Authorization")] = true
+	f.mu.Unlock()
+
+	switch path {
+	case "/agents":
+		json.NewEncoder(w).Encode([]models.Agent{{Codename: "APEX"}, {Codename: "CIPHER"}})
+	case "/agents/{codename}/invoke", "/agent":
+		var req models.CopilotRequest
+		if json.NewDecoder(r.Body).Decode(&req) != nil || len(req.Messages) == 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"choices":[]}`))
+	case "/feedback/batch":
+		var req memory.FeedbackBatchRequest
+		if json.NewDecoder(r.Body).Decode(&req) != nil || len(req.Records) == 0 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{}`))
+	case "/memory/ingest":
+		received := 0
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			received++
+		}
+		f.mu.Lock()
+		f.ingested += received
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(memory.IngestEvent{Type: memory.IngestSummaryEvent, IngestProgress: &memory.IngestProgress{Received: received}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGeneratorRun(t *testing.T) {
+	fake := &fakeServer{paths: make(map[string]int), tokens: make(map[string]bool)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	weights, _ := parseMix(defaultMix)
+	gen := &generator{target: server.URL, token: "load-token", client: server.Client(), weights: weights, seed: 1}
+	if err := gen.discoverAgents(context.Background()); err != nil {
+		t.Fatalf("expected agents discovered, got %v", err)
+	}
+	if strings.Join(gen.agents, ",") != "APEX,CIPHER" {
+		t.Errorf("expected the server's agents, got %v", gen.agents)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	report := gen.run(ctx, 4, 0)
+
+	if report.Total.Requests == 0 || report.Total.Errors != 0 {
+		t.Fatalf("expected requests without errors, got %+v", report.Total)
+	}
+	if len(report.Operations) != 4 {
+		t.Errorf("expected all 4 operations in the default mix, got %+v", report.Operations)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for _, path := range []string{"/agents/{codename}/invoke", "/agent", "/feedback/batch", "/memory/ingest"} {
+		if fake.paths[path] == 0 {
+			t.Errorf("expected traffic to %s", path)
+		}
+	}
+	if !fake.tokens["Bearer load-token"] || len(fake.tokens) != 2 {
+		t.Errorf("expected the token on every request but the agent list, got %v", fake.tokens)
+	}
+	if fake.ingested == 0 {
+		t.Error("expected records ingested")
+	}
+}
+
+func TestGeneratorRunPaced(t *testing.T) {
+	fake := &fakeServer{paths: make(map[string]int), tokens: make(map[string]bool)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	weights, _ := parseMix("feedback=1")
+	gen := &generator{target: server.URL, client: server.Client(), weights: weights, agents: fallbackAgents}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	report := gen.run(ctx, 8, 20)
+
+	// 20 req/s for half a second, whatever the concurrency
+	if report.Total.Requests < 5 || report.Total.Requests > 12 {
+		t.Errorf("expected about 10 paced requests, got %d", report.Total.Requests)
+	}
+}
+
+func TestCheckIngestSummary(t *testing.T) {
+	tests := []struct {
+		body    string
+		wantErr bool
+	}{
+		{`{"type":"progress","received":5}` + "\n" + `{"type":"summary","received":10}`, false},
+		{`{"type":"summary","received":10,"rejected":2}`, true},
+		{`{"type":"summary","error":"stream idle"}`, true},
+		{`{"type":"progress","received":5}`, true},
+	}
+	for _, tt := range tests {
+		err := checkIngestSummary(io.NopCloser(bytes.NewReader([]byte(tt.body))))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.body, tt.wantErr, err)
+		}
+	}
+}
+
+func TestIngestStreamIsValid(t *testing.T) {
+	weights, _ := parseMix(defaultMix)
+	gen := &generator{weights: weights, agents: fallbackAgents}
+	sn := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	ingester := memory.NewStreamIngester(sn, nil, memory.DefaultStreamIngestConfig())
+
+	body, err := gen.ingestStream(newTestRand(), "run-0-0")
+	if err != nil {
+		t.Fatalf("expected a stream, got %v", err)
+	}
+	var summary memory.IngestEvent
+	ingester.Ingest(context.Background(), bytes.NewReader(body), func(event memory.IngestEvent) {
+		if event.Type == memory.IngestSummaryEvent {
+			summary = event
+		}
+	})
+	if summary.IngestProgress == nil || summary.Rejected != 0 || summary.Nodes == 0 || summary.Relations != summary.Nodes-1 {
+		t.Errorf("expected a chain of nodes accepted by the server's ingester, got %+v", summary.IngestProgress)
+	}
+}
+
+// newTestRand returns a fixed-seed source for payload tests.
+func newTestRand() *rand.Rand {
+	return rand.New(rand.NewSource(42))
+}
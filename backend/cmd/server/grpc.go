@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/grpcapi"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/ratelimit"
+	"google.golang.org/grpc"
+)
+
+// newGRPCServer creates the gRPC API server. It shares the HTTP server's
+// registry, knowledge graph and warmup, authenticates calls with the same
+// middleware, OIDC tokens and API keys alike, and takes calls from the
+// same client rate limits.
+func newGRPCServer(registry *agents.Registry, memoryHandler *memory.Handler, warmup *memory.Warmup, authMiddleware *auth.Middleware, limiter *ratelimit.Limiter) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcapi.Tenant,
+			authMiddleware.UnaryInterceptor(grpcapi.PublicMethods...),
+			authMiddleware.ScopeInterceptor(grpcapi.ServiceScopes),
+			limiter.UnaryInterceptor,
+		),
+		grpc.ChainStreamInterceptor(limiter.StreamInterceptor),
+	)
+	grpcapi.Register(server, grpcapi.NewAgentServer(registry), grpcapi.NewMemoryServer(memoryHandler, warmup))
+	return server
+}
+
+// stopGRPCServer stops the gRPC server gracefully, letting calls in
+// flight finish, and cuts them off if ctx is done first.
+func stopGRPCServer(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
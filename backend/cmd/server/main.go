@@ -4,19 +4,47 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/adminui"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents/handlers"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/analytics"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/compliance"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/embeddings"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/events"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/gitops"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/integrations"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/intent"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/llm"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/metrics"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/orchestrator"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/preferences"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/propagation"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/ratelimit"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/rungroup"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/runtimeinfo"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/selftest"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/sessions"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/tools"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 )
 
 // corsMiddleware creates CORS middleware with configurable allowed origins.
@@ -32,8 +60,8 @@ func corsMiddleware(allowedOrigins string) func(http.Handler) http.Handler {
 				origin = "*"
 			}
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-GitHub-Signature-256")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-GitHub-Signature-256, X-Tenant-ID")
 			w.Header().Set("Access-Control-Max-Age", "86400")
 
 			// Handle preflight requests
@@ -47,23 +75,800 @@ func corsMiddleware(allowedOrigins string) func(http.Handler) http.Handler {
 	}
 }
 
+// shutdownTimeout is how long the servers and workers have to stop once
+// the server is shutting down.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		config.WriteUsage(os.Stderr)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Could not load configuration: %v", err)
+	}
+	if cfg.PrintConfig {
+		cfg.WriteReport(os.Stdout)
+	}
+	if cfg.Profile != "" {
+		log.Printf("Using the %s configuration profile", cfg.Profile)
+	}
+	if cfg.Offline {
+		log.Printf("Offline mode: network-backed providers are replaced by deterministic stubs")
+	}
 
 	// Initialize agent registry
 	registry := agents.DefaultRegistry()
 	log.Printf("Registered %d agents", registry.Count())
 
+	if cfg.SelfTest {
+		ctx, cancel := context.WithTimeout(context.Background(), selftest.DefaultTimeout)
+		report := selftest.Run(ctx, selftest.DefaultChecks(cfg, registry))
+		cancel()
+		report.Write(os.Stdout)
+		if !report.Passed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Roll usage up into daily aggregates ORACLE reports trends from
+	usage := analytics.New(analytics.DefaultConfig())
+
+	// Error rates, latency and impasses are watched for the collective's
+	// own degradation, which is focused as an interrupt
+	focus := memory.NewAttentionController(memory.DefaultAttentionConfig())
+	// What the collective is doing is streamed live to /ws; memory
+	// subsystems publish to the hub as they fire, focus and detect
+	liveEvents := events.NewHub()
+	anomalies := memory.NewAnomalyDetector(memory.DefaultAnomalyConfig(), focus)
+	// Invocation latency and the memory subsystems' stats are scraped by
+	// Prometheus
+	serverMetrics := metrics.New()
+	registry.OnInvocation(func(inv agents.Invocation) {
+		usage.RecordInvocation(inv.Agent, inv.Route, string(inv.Intent), inv.Success, inv.Time)
+		anomalies.RecordInvocation(inv.Agent, inv.Success, inv.Duration)
+		serverMetrics.ObserveInvocation(inv.Agent, inv.Route, inv.Success, inv.Duration)
+	})
+	// Agents answer through a language model, prompted with their persona
+	// and sampling with parameters feedback tunes; ORACLE keeps reporting
+	// the usage digest
+	samplingConfig := memory.DefaultSamplingTunerConfig()
+	samplingConfig.Default = memory.SamplingParams{Temperature: cfg.LLM.Temperature, TopP: cfg.LLM.TopP}
+	samplingTuner := memory.NewSamplingTuner(samplingConfig)
+	llmProvider := cfg.LLM.Provider
+	if cfg.Offline && llmProvider != "" {
+		llmProvider = llm.ProviderStub
+	}
+	// Streamed requests asking for a draft get one from the draft model
+	// while the agent answers
+	var drafter agents.Drafter
+	if llmProvider != "" {
+		provider, err := llm.New(llm.Config{
+			Provider:       llmProvider,
+			APIKey:         cfg.LLM.APIKey,
+			BaseURL:        cfg.LLM.BaseURL,
+			Model:          cfg.LLM.Model,
+			EmbeddingModel: cfg.LLM.EmbeddingModel,
+			MaxTokens:      cfg.LLM.MaxTokens,
+			Timeout:        time.Duration(cfg.LLM.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			log.Fatalf("Could not create language model provider: %v", err)
+		}
+		// Completions the provider is slow with are hedged with the
+		// fallback provider
+		if cfg.LLM.Hedge.Provider != "" && !cfg.Offline {
+			fallback, err := llm.New(llm.Config{
+				Provider:  cfg.LLM.Hedge.Provider,
+				APIKey:    cfg.LLM.Hedge.APIKey,
+				BaseURL:   cfg.LLM.Hedge.BaseURL,
+				Model:     cfg.LLM.Hedge.Model,
+				MaxTokens: cfg.LLM.MaxTokens,
+				Timeout:   time.Duration(cfg.LLM.TimeoutSeconds) * time.Second,
+			})
+			if err != nil {
+				log.Fatalf("Could not create hedge language model provider: %v", err)
+			}
+			hedged := llm.NewHedged(provider, fallback, llm.HedgeConfig{
+				Percentile: cfg.LLM.Hedge.Percentile,
+				Delay:      time.Duration(cfg.LLM.Hedge.DelayMillis) * time.Millisecond,
+				MaxRate:    cfg.LLM.Hedge.MaxRate,
+			})
+			hedged.OnHedge(serverMetrics.ObserveHedge)
+			provider = hedged
+			log.Printf("Slow completions are hedged with the %s provider after the p%g latency, at most %g of them",
+				fallback.Name(), cfg.LLM.Hedge.Percentile, cfg.LLM.Hedge.MaxRate)
+		}
+		for _, agent := range registry.List() {
+			if agent.Codename == "ORACLE" {
+				continue
+			}
+			if handler, err := registry.Get(agent.Codename); err == nil {
+				registry.Register(handlers.NewModelAgent(handler.GetInfo(), provider, samplingTuner))
+			}
+		}
+		log.Printf("Agents answer through the %s provider (model %q)", provider.Name(), cfg.LLM.Model)
+		if cfg.LLM.DraftModel != "" {
+			drafter = handlers.NewDrafter(provider, cfg.LLM.DraftModel)
+			log.Printf("Drafts stream from model %q", cfg.LLM.DraftModel)
+		}
+	}
+	if oracle, err := registry.Get("ORACLE"); err == nil {
+		registry.Register(handlers.NewOracleAgent(oracle.GetInfo(), func(ctx context.Context) string {
+			return usage.Digest(analytics.DefaultDigestDays).Report
+		}))
+	}
+
+	// Initialize the knowledge graph
+	networkConfig := memory.DefaultSemanticNetworkConfig()
+	networkConfig.IndexedProperties = []string{"tier"}
+	network := memory.NewSemanticNetwork(networkConfig)
+
+	// Profiles put the log and snapshot in directories that may not exist yet
+	for _, path := range []string{cfg.Memory.WALPath, cfg.Memory.SnapshotPath} {
+		if path == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("Could not create memory directory: %v", err)
+		}
+	}
+
+	// Open the log; it is replayed and attached during warmup
+	var wal *memory.WriteAheadLog
+	if cfg.Memory.WALPath != "" {
+		var err error
+		wal, err = memory.OpenWAL(cfg.Memory.WALPath, memory.DefaultWALConfig())
+		if err != nil {
+			log.Fatalf("Could not open write-ahead log: %v", err)
+		}
+	}
+
+	// Embed text with a local model so vector search needs no external API,
+	// or with OpenAI's embedding model
+	embeddingProvider := cfg.Embeddings.Provider
+	if cfg.Offline {
+		embeddingProvider = "stub"
+	}
+	var embedder embeddings.Embedder
+	var onnxEmbedder *embeddings.ONNXEmbedder
+	switch embeddingProvider {
+	case "":
+	case "onnx":
+		var err error
+		onnxEmbedder, err = embeddings.NewONNXEmbedder(embeddings.ONNXConfig{
+			ModelPath:   cfg.Embeddings.ModelPath,
+			LibraryPath: cfg.Embeddings.LibraryPath,
+		})
+		if err != nil {
+			log.Fatalf("Could not load embedding model: %v", err)
+		}
+		embedder = onnxEmbedder
+	case "openai":
+		provider, err := llm.New(llm.Config{
+			Provider:       llm.ProviderOpenAI,
+			APIKey:         cfg.LLM.APIKey,
+			BaseURL:        cfg.LLM.BaseURL,
+			EmbeddingModel: cfg.LLM.EmbeddingModel,
+			Timeout:        time.Duration(cfg.LLM.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			log.Fatalf("Could not create embedding provider: %v", err)
+		}
+		embedder = llm.NewEmbedder(provider, cfg.LLM.EmbeddingModel)
+	case "stub":
+		embedder = embeddings.NewStubEmbedder(0)
+	default:
+		log.Fatalf("Unknown embeddings provider %q", cfg.Embeddings.Provider)
+	}
+	var embeddingCache *embeddings.Cache
+	if embedder != nil {
+		cacheConfig := embeddings.DefaultCacheConfig()
+		cacheConfig.Path = cfg.Embeddings.CachePath
+		var err error
+		embeddingCache, err = embeddings.NewCache(embedder, cacheConfig)
+		if err != nil {
+			log.Fatalf("Could not load embedding cache: %v", err)
+		}
+		log.Printf("Embedding with %s", embedder.Model())
+		// Nodes are embedded as they are created, so similarity and concept
+		// discovery have vectors to compare
+		network.SetEmbedder(embeddingCache)
+	}
+
+	// Label queries with their intent before they are handled; rules decide
+	// until the intent prototypes are embedded
+	classifier := intent.NewClassifier()
+	var intentConfig *intent.Config
+	if cfg.IntentTemplates != "" {
+		var err error
+		intentConfig, err = intent.LoadConfig(cfg.IntentTemplates)
+		if err != nil {
+			log.Fatalf("Could not load intent templates: %v", err)
+		}
+		log.Printf("Loaded %d intent templates from %s", len(intentConfig.Templates), cfg.IntentTemplates)
+	}
+	registry.SetIntents(classifier, intent.NewTemplates(intentConfig))
+
+	// Personas, intent templates and rule packs pushed to the prompt
+	// repository replace the loaded ones without a release
+	var promptSync *gitops.Syncer
+	switch {
+	case cfg.GitOps.Repo != "" && cfg.Offline:
+		log.Printf("Offline mode: prompt sync from %s is disabled", cfg.GitOps.Repo)
+	case cfg.GitOps.Repo != "":
+		promptSync = gitops.NewSyncer(gitops.Config{
+			Repo:           cfg.GitOps.Repo,
+			Branch:         cfg.GitOps.Branch,
+			Dir:            cfg.GitOps.Dir,
+			Path:           cfg.GitOps.Path,
+			AllowedSigners: cfg.GitOps.AllowedSigners,
+		}, func(bundle *gitops.Bundle) error {
+			if bundle.Templates != nil {
+				registry.SetTemplates(intent.NewTemplates(bundle.Templates))
+			}
+			updated, skipped := registry.UpdatePersonas(bundle.Personas)
+			log.Printf("Commit %s updated %d personas and skipped %v", bundle.Commit, len(updated), skipped)
+			return nil
+		})
+	}
+
+	// Route by keyword attention, blended with persona similarity once the
+	// agent personas are embedded
+	attention := memory.NewCollaborativeAttentionIndex()
+	router := memory.NewHybridRouter(attention, memory.DefaultHybridRouterConfig())
+
+	// Routing decisions and the outcomes fed back on them are kept for
+	// export as training data
+	trainingLog := memory.NewTrainingLog(memory.DefaultTrainingLogCapacity, nil)
+	router.OnRoute(trainingLog.RecordDecision)
+
+	// Warm the knowledge graph in the background; /ready flips once it is done
+	warmupConfig := memory.DefaultWarmupConfig()
+	warmupConfig.ServeDegraded = cfg.Memory.WarmupServeDegraded
+	warmup := memory.NewWarmup(warmupConfig)
+	warmup.AddSemanticNetworkSteps(network, memory.SemanticWarmupOptions{
+		SnapshotPath: cfg.Memory.SnapshotPath,
+		WAL:          wal,
+		Seed: func(sn *memory.SemanticNetwork) error {
+			// Without a snapshot, start from what the collective knows about itself
+			if err := memory.SeedAgentOntology(sn, registry.List()); err != nil {
+				log.Printf("Warning: seeding agent ontology: %v", err)
+			}
+			return nil
+		},
+	})
+	if onnxEmbedder != nil {
+		// The first inference allocates the model's buffers; pay for it here
+		// rather than on the first request
+		warmup.AddStep(memory.WarmupStep{
+			Name: "warm embedding model",
+			Run: func(ctx context.Context, progress memory.WarmupProgress) error {
+				return onnxEmbedder.Warmup(ctx)
+			},
+			Optional: true,
+		})
+	}
+	if embeddingCache != nil {
+		warmup.AddStep(memory.WarmupStep{
+			Name: "embed agent personas",
+			Run: func(ctx context.Context, progress memory.WarmupProgress) error {
+				personas, err := memory.NewPersonaIndex(ctx, embeddingCache, registry.List())
+				if err != nil {
+					return err
+				}
+				router.SetPersonas(personas)
+				return nil
+			},
+			Optional: true,
+		})
+		warmup.AddStep(memory.WarmupStep{
+			Name: "embed intent prototypes",
+			Run: func(ctx context.Context, progress memory.WarmupProgress) error {
+				model, err := intent.NewModel(ctx, embeddingCache, intent.DefaultMinSimilarity)
+				if err != nil {
+					return err
+				}
+				classifier.SetModel(model)
+				return nil
+			},
+			Optional: true,
+		})
+	}
+	// Agents are briefed on what the knowledge graph holds on their
+	// specialty from a pool rebuilt in the background, once the graph is
+	// loaded, so invocations don't wait on the retrieval
+	warmPool := agents.NewWarmPool(registry, time.Duration(cfg.AgentContextRefreshSeconds)*time.Second, memory.NewAgentBriefing(network, 0))
+	warmPool.OnAssemble(func(agent string, warm bool, duration time.Duration) {
+		serverMetrics.ObserveContext(warm, duration)
+	})
+	registry.SetWarmPool(warmPool)
+
+	// Long-lived workers run as one group, stopped by SIGINT or SIGTERM:
+	// the first to fail, such as a server that can't listen, stops the
+	// rest, and shutdown waits for every one before state is saved
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	workers := rungroup.New(signalCtx, shutdownTimeout)
+	workers.Go("warmup", func(ctx context.Context) error {
+		if err := warmup.Run(ctx); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			log.Printf("Knowledge graph has %d nodes and %d relations", network.NodeCount(), network.RelationCount())
+		}
+		warmPool.Run(ctx)
+		return nil
+	})
+
+	// Consolidation and periodic snapshots run on one replica only, the
+	// leader replicas elect through a shared lease
+	replicaID := cfg.Cluster.ReplicaID
+	if replicaID == "" {
+		replicaID, _ = os.Hostname()
+	}
+	var leaseLock memory.LeaseLock = memory.NewMemoryLeaseLock()
+	if cfg.Cluster.LeaderElection == "kubernetes" {
+		kubernetesLock, err := memory.NewInClusterLeaseLock(cfg.Cluster.LeaseNamespace, cfg.Cluster.LeaseName)
+		if err != nil {
+			log.Fatalf("Could not set up leader election: %v\n", err)
+		}
+		leaseLock = kubernetesLock
+		log.Printf("Electing a leader as %s with lease %s/%s", replicaID, cfg.Cluster.LeaseNamespace, cfg.Cluster.LeaseName)
+	}
+	leader := memory.NewLeaderElector(leaseLock, memory.DefaultLeaderElectionConfig(replicaID))
+	consolidatorConfig := memory.DefaultConsolidatorConfig()
+	consolidatorConfig.EnableAutoConsolidation = true
+	consolidatorConfig.ConsolidationInterval = time.Duration(cfg.Memory.ConsolidationIntervalSeconds) * time.Second
+	consolidator := memory.NewMemoryConsolidator(consolidatorConfig)
+	consolidator.SetLeaderElector(leader)
+	if cfg.Memory.SnapshotPath != "" && cfg.Memory.SnapshotIntervalSeconds > 0 {
+		leader.Register("snapshot", func(ctx context.Context) {
+			ticker := time.NewTicker(time.Duration(cfg.Memory.SnapshotIntervalSeconds) * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					saveSnapshot(network, warmup, wal, cfg.Memory.SnapshotPath)
+				}
+			}
+		})
+	}
+	workers.Go("leader", func(ctx context.Context) error {
+		if err := leader.Run(ctx); err != nil {
+			log.Printf("Error releasing the leader lease: %v", err)
+		}
+		consolidator.Stop()
+		return nil
+	})
+	// Feedback is kept as episodes, and those that age out are consolidated;
+	// only the leader consolidates, so other replicas let theirs go
+	episodes := memory.NewEpisodicMemory(memory.DefaultEpisodicConfig())
+	episodes.OnConsolidate(func(expired []*memory.ExperienceTuple) {
+		if !leader.IsLeader() {
+			return
+		}
+		for _, exp := range expired {
+			consolidator.AddToBuffer(exp)
+		}
+	})
+	workers.Go("episodes", func(ctx context.Context) error {
+		episodes.Run(ctx, time.Minute)
+		return nil
+	})
+
+	// Chat integrations post notifications and take commands
+	var integrationsConfig *integrations.Config
+	var notifier *integrations.Notifier
+	switch {
+	case cfg.IntegrationsConfig != "" && cfg.Offline:
+		// Notifications and command replies go to Slack and Teams
+		log.Printf("Offline mode: chat integrations in %s are disabled", cfg.IntegrationsConfig)
+	case cfg.IntegrationsConfig != "":
+		var err error
+		integrationsConfig, err = integrations.LoadConfig(cfg.IntegrationsConfig)
+		if err != nil {
+			log.Fatalf("Could not load integrations: %v", err)
+		}
+		notifier = integrations.NewNotifier(integrationsConfig)
+		log.Printf("Loaded %d chat workspaces from %s", len(integrationsConfig.Workspaces), cfg.IntegrationsConfig)
+	}
+
+	// Experimental features are gated per tenant and toggled at runtime
+	var featuresConfig *features.Config
+	if cfg.FeaturesConfig != "" {
+		var err error
+		featuresConfig, err = features.LoadConfig(cfg.FeaturesConfig)
+		if err != nil {
+			log.Fatalf("Could not load feature flags: %v", err)
+		}
+		log.Printf("Loaded feature flags from %s", cfg.FeaturesConfig)
+	}
+	flags := features.New(featuresConfig)
+
+	// Deployment tooling checks what is running against what it rolled out
+	runtimeInfo := runtimeinfo.New(cfg, flags)
+	runtimeInfo.SetSubsystem("semantic_snapshot", strconv.Itoa(memory.LatestSnapshotVersion()))
+	runtimeInfo.SetSubsystem("session_bundle", sessions.BundleFormat)
+	runtimeInfo.SetSubsystem("embedding_cache", strconv.Itoa(embeddings.CacheFileVersion))
+
+	// Answers are checked against the knowledge graph for tenants with
+	// grounded answers enabled
+	registry.SetGrounding(agents.GroundingConfig{
+		Grounder: memory.NewGroundingVerifier(network),
+		Enabled: func(ctx context.Context) bool {
+			return flags.Enabled(features.GroundedAnswers, features.TenantFromContext(ctx))
+		},
+		Revise: cfg.GroundingRevise,
+	})
+
+	// Answers suggest what to ask next about the concepts they touch
+	registry.SetFollowUps(memory.NewFollowUpSuggester(network))
+
+	// Each client and agent gets its share of requests, limited per
+	// server or, through Redis, across servers
+	limits := &ratelimit.Config{
+		Client: ratelimit.Limit{QPS: cfg.RateLimit.ClientQPS, Burst: cfg.RateLimit.ClientBurst},
+		Agent:  ratelimit.Limit{QPS: cfg.RateLimit.AgentQPS, Burst: cfg.RateLimit.AgentBurst},
+	}
+	if cfg.RateLimit.LimitsConfig != "" {
+		var err error
+		limits, err = ratelimit.LoadConfig(cfg.RateLimit.LimitsConfig, *limits)
+		if err != nil {
+			log.Fatalf("Could not load rate limits: %v", err)
+		}
+	}
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	switch {
+	case cfg.RateLimit.Store == "redis" && cfg.Offline:
+		log.Printf("Offline mode: rate limits are kept in memory instead of Redis")
+	case cfg.RateLimit.Store == "redis":
+		limitStore = ratelimit.NewRedisStore(cfg.RateLimit.RedisAddr, cfg.RateLimit.RedisPassword)
+	}
+	rateLimiter := ratelimit.New(limitStore, *limits)
+	registry.SetRateLimiter(rateLimiter)
+	if limits.Enabled() {
+		log.Printf("Rate limits are kept in %s", cfg.RateLimit.Store)
+	}
+
+	// Users' preferences are learned from feedback and added to prompts
+	userPreferences := preferences.NewStore()
+	if cfg.PreferencesDir != "" {
+		var err error
+		userPreferences, err = preferences.OpenStore(cfg.PreferencesDir)
+		if err != nil {
+			log.Fatalf("Could not open preferences: %v", err)
+		}
+	}
+
+	// Requests naming a session continue its conversation. A new session
+	// prefetches the user's recent turns, the subgraph they drew on and
+	// their preferences, which its first turn is answered from
+	sessionConfig := sessions.DefaultConfig()
+	sessionConfig.SigningKey = cfg.SessionSigningKey
+	sessionConfig.Retention = time.Duration(cfg.SessionTTLMinutes) * time.Minute
+	sessionStore := sessions.NewStore(sessionConfig, memory.NewQuestionAnswerer(network))
+	sessionStore.SetPrefetch(network, userPreferences)
+	registry.SetSessions(sessionStore)
+	registry.SetPreferences(sessionStore)
+
 	// Initialize handlers
 	agentHandler := agents.NewHandler(registry)
+	agentHandler.SetGitHubAPI(cfg.GitHub.APIURL)
+	if drafter != nil {
+		agentHandler.SetDrafter(drafter)
+	}
+	if cfg.WorkflowsDir != "" {
+		workflows := agents.NewWorkflowEngine(registry)
+		// Graph steps add the records in their agents' replies to the
+		// knowledge graph, as /memory/ingest does
+		workflows.SetGraph(memory.NewStreamIngester(network, nil, memory.DefaultStreamIngestConfig()))
+		if notifier != nil {
+			workflows.OnRunFinished(func(run *agents.WorkflowRun) {
+				notifier.Notify(integrations.RunEvent(run))
+			})
+		}
+		loaded, err := workflows.LoadDir(cfg.WorkflowsDir)
+		if err != nil {
+			log.Fatalf("Could not load workflows: %v", err)
+		}
+		if cfg.WorkflowStateDir != "" {
+			store, err := agents.OpenWorkflowStore(cfg.WorkflowStateDir)
+			if err != nil {
+				log.Fatalf("Could not open workflow state: %v", err)
+			}
+			workflows.SetStore(store)
+		}
+		agentHandler.SetWorkflows(workflows)
+		log.Printf("Loaded %d workflows from %s", loaded, cfg.WorkflowsDir)
+
+		// Runs interrupted by the last shutdown pick up where they left off
+		if resumed := workflows.Resume(); resumed > 0 {
+			log.Printf("Resumed %d workflow runs", resumed)
+		}
+	}
+	// Multi-agent answers are merged, with disagreements surfaced rather
+	// than left in the concatenation. Goals resolving impasses create are
+	// kept on a goal stack the admin UI shows; goals running past their
+	// deadline or budget raise impasses too. Each goal's journal is kept
+	// for post-mortems
+	goals := memory.NewGoalStack(memory.DefaultGoalStackConfig())
+	goalJournal := memory.NewGoalJournalStore()
+	if cfg.Memory.GoalJournalDir != "" {
+		var err error
+		goalJournal, err = memory.OpenGoalJournalStore(cfg.Memory.GoalJournalDir)
+		if err != nil {
+			log.Fatalf("Could not open goal journals: %v", err)
+		}
+	}
+	goals.SetJournal(goalJournal)
+	fusionImpasses := memory.NewImpasseDetector(nil, goals)
+	fusionImpasses.OnImpasseDetected(func(imp *memory.Impasse) {
+		anomalies.RecordImpasse()
+		if len(imp.Candidates) > 0 {
+			log.Printf("Agents disagree (%s): %v", imp.Description, imp.Candidates)
+		} else {
+			log.Printf("Goal %s impasse: %s", imp.GoalID, imp.Description)
+		}
+		if notifier != nil {
+			notifier.Notify(integrations.ImpasseEvent(imp))
+		}
+		liveEvents.PublishImpasse(imp)
+	})
+	serverMetrics.RegisterMemory(metrics.Sources{
+		Semantic:  network.GetStats,
+		Attention: focus.GetStats,
+		Impasse:   fusionImpasses.GetStats,
+	})
+	workers.Go("goals", func(ctx context.Context) error {
+		goals.WatchDeadlines(ctx, 5*time.Second)
+		return nil
+	})
+	anomalies.OnAnomaly(func(a *memory.Anomaly) {
+		log.Printf("Anomaly: %s", a.Description())
+		if notifier != nil {
+			notifier.Notify(integrations.AnomalyEvent(a))
+		}
+	})
+	workers.Go("anomalies", func(ctx context.Context) error {
+		anomalies.Run(ctx, time.Minute)
+		return nil
+	})
+	workers.Go("sessions", func(ctx context.Context) error {
+		sessionStore.WatchExpiry(ctx, time.Minute)
+		return nil
+	})
+	// WebSocket connections are hijacked, so server shutdown leaves them
+	// open; closing the hub ends their streams
+	workers.Go("events", func(ctx context.Context) error {
+		<-ctx.Done()
+		liveEvents.Close()
+		return nil
+	})
+	if promptSync != nil {
+		workers.Go("gitops", func(ctx context.Context) error {
+			promptSync.Run(ctx, time.Duration(cfg.GitOps.IntervalSeconds)*time.Second)
+			return nil
+		})
+	}
+	fuser := memory.NewAnswerFuser(memory.DefaultAnswerFusionConfig(), fusionImpasses)
+	agentHandler.SetFusion(func(answers []models.AgentAnswer) string {
+		return fuser.Fuse(answers).Content
+	})
+	memoryHandler := memory.NewHandler(network)
+	memoryAdmin := memory.NewAdminHandler(network, attention, goals, fusionImpasses)
+	trainingExporter := memory.NewTrainingExporter(trainingLog, router, attention)
+	// Learned parameters are versioned in the model registry; promoted
+	// versions saved there are applied again as each model is bound
+	models := memory.NewModelRegistry(nil)
+	if cfg.Memory.ModelRegistryPath != "" {
+		var err error
+		models, err = memory.OpenModelRegistry(cfg.Memory.ModelRegistryPath, nil)
+		if err != nil {
+			log.Fatalf("Could not open model registry: %v", err)
+		}
+	}
+	for _, bind := range []func() error{
+		func() error { return models.BindAttention("attention-weights", attention) },
+		func() error { return models.BindRouting("routing-priors", router) },
+		func() error { return models.BindCalibration("calibration", memoryHandler.Calibrator()) },
+		func() error { return models.BindSampling("sampling", samplingTuner) },
+	} {
+		if err := bind(); err != nil {
+			log.Fatalf("Could not bind model: %v", err)
+		}
+	}
+	// Candidate routing models are replayed against recorded queries
+	// before they are promoted
+	routingReplayer := memory.NewRoutingReplayer(trainingLog, router)
+	routingReplayer.SetRegistry(models)
+	streamIngester := memory.NewStreamIngester(network, nil, memory.DefaultStreamIngestConfig())
+	// A breakthrough in one tenant's traffic is shared with the others, in
+	// the usage digest and chat notifications, only once the insight policy
+	// or an administrator promotes it
+	insightPolicy := propagation.DefaultPolicy()
+	if cfg.InsightPolicy != "" {
+		insightPolicy, err = propagation.LoadPolicy(cfg.InsightPolicy)
+		if err != nil {
+			log.Fatalf("Could not load insight policy: %v", err)
+		}
+		log.Printf("Loaded insight policy from %s", cfg.InsightPolicy)
+	}
+	propagationEngine := propagation.NewEngine(insightPolicy)
+	propagationEngine.OnPromote(func(insight propagation.Insight) {
+		event := memory.SurpriseEvent{
+			Agents:        insight.Agents,
+			TaskType:      insight.Subject,
+			SurpriseScore: insight.Score,
+			Timestamp:     insight.DetectedAt,
+			Strategy:      insight.Detail,
+		}
+		usage.RecordBreakthrough(analytics.Breakthrough{
+			Agents:   event.Agents,
+			TaskType: event.TaskType,
+			Score:    event.SurpriseScore,
+			Time:     event.Timestamp,
+		})
+		if notifier != nil {
+			notifier.Notify(integrations.BreakthroughEvent(event))
+		}
+	})
+	insights := memory.NewEmergentInsightDetector()
+	liveEvents.Watch(events.Sources{Attention: focus, Insights: insights})
+	affinity := memory.NewAgentAffinityGraph()
+	feedbackIngester := memory.NewFeedbackIngester(attention, affinity, insights)
+	feedbackIngester.SetRouter(router)
+	privacy := memory.PrivacyConfig{
+		Epsilon:         cfg.Privacy.Epsilon,
+		MinTenants:      cfg.Privacy.MinTenants,
+		MaxContribution: cfg.Privacy.MaxContribution,
+	}
+	if privacy.Enabled() {
+		feedbackIngester.SetPrivacy(memory.NewPrivateAggregator(privacy))
+		log.Printf("Pooling tenant feedback privately (epsilon %g, at least %d tenants per signal)", privacy.Epsilon, privacy.MinTenants)
+	}
+	feedbackIngester.OnBreakthrough(func(ctx context.Context, event memory.SurpriseEvent) {
+		propagationEngine.Submit(propagation.Insight{
+			Tenant:     features.TenantFromContext(ctx),
+			Kind:       propagation.KindBreakthrough,
+			Subject:    event.TaskType,
+			Detail:     event.Strategy,
+			Agents:     event.Agents,
+			Score:      event.SurpriseScore,
+			DetectedAt: event.Timestamp,
+		})
+	})
+	feedbackIngester.OnIngested(func(summary *memory.FeedbackSummary) {
+		for agent, counts := range summary.Agents {
+			usage.RecordFeedback(agent, counts.Successes, counts.Failures)
+		}
+	})
+	feedbackIngester.OnApplied(func(ctx context.Context, records []memory.FeedbackRecord) {
+		trainingLog.RecordOutcomes(records)
+		samplingTuner.Observe(records)
+		feedback := make([]preferences.Feedback, 0, len(records))
+		for _, record := range records {
+			if err := episodes.Record(record.Experience()); err != nil {
+				log.Printf("Could not record feedback as an episode: %v", err)
+			}
+			feedback = append(feedback, preferences.Feedback{
+				User:      record.User,
+				Query:     record.Query,
+				Success:   record.Success,
+				Verbosity: preferences.Verbosity(record.Verbosity),
+			})
+		}
+		if err := userPreferences.Learn(features.TenantFromContext(ctx), feedback); err != nil {
+			log.Printf("Could not save learned preferences: %v", err)
+		}
+	})
+
+	// Tasks for a team are routed to a lead by attention, the team grown
+	// from the affinity the feedback above learns, and run as a workflow
+	teams := orchestrator.New(registry, attention, affinity, orchestrator.DefaultConfig())
+
+	// Derived indexes rebuilt on demand from their primary stores
+	reindexer := memory.NewReindexer()
+	reindexer.AddSemanticNetwork(network)
+	reindexer.AddAffinityGraph(affinity)
+
+	// Replicas gossip the affinity and attention they learn to each other,
+	// so each routes with what all of them have learned
+	var gossip *memory.AffinityGossip
+	if len(cfg.Cluster.GossipPeers) > 0 {
+		gossipConfig := memory.DefaultGossipConfig(replicaID)
+		gossipConfig.Interval = time.Duration(cfg.Cluster.GossipIntervalSeconds) * time.Second
+		gossip = memory.NewAffinityGossip(gossipConfig, affinity, attention)
+		for _, peer := range cfg.Cluster.GossipPeers {
+			gossip.AddPeer(memory.NewHTTPGossipPeer(peer, cfg.Cluster.GossipSecret))
+		}
+		workers.Go("gossip", func(ctx context.Context) error {
+			if err := gossip.Start(ctx); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			gossip.Stop()
+			return nil
+		})
+		log.Printf("Gossiping routing state as %s with %d peers", replicaID, len(cfg.Cluster.GossipPeers))
+	}
+
+	// Agent tools act with each tenant's credentials, only as the tenant's
+	// grants allow, and every call is audited
+	var issueTool *tools.IssueTool
+	var audit *tools.AuditLog
+	var toolsConfig *tools.Config
+	if cfg.ToolsConfig != "" {
+		var err error
+		toolsConfig, err = tools.LoadConfig(cfg.ToolsConfig)
+		if err != nil {
+			log.Fatalf("Could not load tools: %v", err)
+		}
+		audit = tools.NewAuditLog(log.Writer())
+		if cfg.AuditLogPath != "" {
+			audit, err = tools.OpenAuditLog(cfg.AuditLogPath)
+			if err != nil {
+				log.Fatalf("Could not open audit log: %v", err)
+			}
+		}
+		dispatcher := tools.NewDispatcher(tools.NewMatrix(toolsConfig), audit)
+		if cfg.Offline {
+			issueTool = tools.NewStubIssueTool(toolsConfig, dispatcher)
+		} else {
+			issueTool = tools.NewIssueTool(toolsConfig, dispatcher)
+		}
+		log.Printf("Loaded tool credentials for %d tenants from %s", len(toolsConfig.Tenants), cfg.ToolsConfig)
+	}
+
+	// Evidence packs for audits, compiled on demand from the audit trail,
+	// the access configuration, retention policies and insight moderation
+	complianceSources := compliance.Sources{
+		Insights: func() []propagation.Insight { return propagationEngine.List("") },
+		Retention: []compliance.RetentionPolicy{
+			{Data: "Sessions and their working memory", Period: sessionConfig.Retention, Disposal: "deleted once idle past retention, checked every minute"},
+			{Data: "Workflow runs", Period: agents.WorkflowRunRetention, Disposal: "deleted once finished past retention"},
+			{Data: "Usage analytics rollups", Period: time.Duration(analytics.DefaultConfig().Retention) * 24 * time.Hour, Disposal: "daily rollups dropped past retention"},
+			{Data: "Tool-call audit trail", Disposal: "appended only; rotation and deletion are left to the operator"},
+		},
+	}
+	if audit != nil {
+		complianceSources.Audit = audit.Entries
+		complianceSources.Tools = func() *tools.Config { return toolsConfig }
+	}
+	evidence := compliance.New(cfg, complianceSources)
 
 	// Initialize authentication middleware
 	authMiddleware := auth.NewMiddleware(&cfg.OIDC)
+	if cfg.APIKeysConfig != "" {
+		apiKeys, err := auth.LoadAPIKeys(cfg.APIKeysConfig)
+		if err != nil {
+			log.Fatalf("Could not load API keys: %v", err)
+		}
+		authMiddleware.SetAPIKeys(apiKeys)
+		log.Printf("Accepting %d API keys alongside OIDC tokens", apiKeys.Len())
+	}
+	// API keys are limited to the scopes they are granted; each route
+	// names the one it needs
+	agentsScope := authMiddleware.RequireScope(auth.ScopeAgents)
+	memoryScope := authMiddleware.RequireScope(auth.ScopeMemory)
+	sessionsScope := authMiddleware.RequireScope(auth.ScopeSessions)
+	toolsScope := authMiddleware.RequireScope(auth.ScopeTools)
+	adminScope := authMiddleware.RequireScope(auth.ScopeAdmin)
+	// Any caller may query memory; changing it takes the editor role, and
+	// rebuilding its stores or replacing what was learned the admin role
+	editorRole := authMiddleware.RequireRole(auth.RoleEditor)
+	adminRole := authMiddleware.RequireRole(auth.RoleAdmin)
 
 	// Initialize signature verification middleware for GitHub webhooks
 	signatureMiddleware := auth.NewSignatureMiddleware(cfg.GitHub.WebhookSecret)
 
+	// Initialize installation token verification for GitHub Actions
+	installationVerifier := auth.NewInstallationVerifier(&cfg.GitHub)
+
 	// Setup router
 	r := chi.NewRouter()
 
@@ -72,26 +877,176 @@ func main() {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 	r.Use(corsMiddleware(cfg.CORSAllowedOrigins))
+	r.Use(features.Tenant)
+	r.Use(sessions.Track)
+
+	// Every route but streaming ingestion is bounded by the request timeout
+	requestTimeout := middleware.Timeout(60 * time.Second)
+
+	r.Group(func(r chi.Router) {
+		r.Use(requestTimeout)
+
+		// Health check endpoint (no auth required)
+		r.Get("/health", healthCheckHandler)
+
+		// Readiness probe, false until memory warmup completes
+		r.Get("/ready", warmup.ServeReady)
+
+		// Prometheus metrics (no auth required)
+		r.Get("/metrics", serverMetrics.ServeHTTP)
+
+		// Peer replicas authenticate with the shared gossip secret
+		if gossip != nil {
+			r.Post(memory.GossipPath, gossip.Handler(cfg.Cluster.GossipSecret).ServeHTTP)
+		}
 
-	// Health check endpoint (no auth required)
-	r.Get("/health", healthCheckHandler)
+		// API routes
+		r.Route("/agents", func(r chi.Router) {
+			r.Get("/", agentHandler.ListAgents)
+			r.With(authMiddleware.Authenticate, agentsScope).Get("/quotas", agentHandler.QuotaStats)
+			r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients, sessionStore.Prefetch).Post("/route", router.ServeRoute)
+			r.Get("/{codename}", agentHandler.GetAgent)
+			r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients, sessionStore.Prefetch).Post("/{codename}/invoke", agentHandler.InvokeAgent)
+		})
+
+		// Declarative multi-agent workflows
+		r.Route("/workflows", func(r chi.Router) {
+			r.Get("/", agentHandler.ListWorkflows)
+			r.Get("/runs/{id}", agentHandler.GetWorkflowRun)
+			r.Get("/{name}", agentHandler.GetWorkflow)
+			r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients).Post("/{name}/run", agentHandler.RunWorkflow)
+			r.With(authMiddleware.Authenticate, agentsScope, adminRole, rateLimiter.LimitClients).Post("/reload", agentHandler.ReloadWorkflows)
+		})
+
+		// Teams of agents picked for a task, their answers combined by the lead
+		r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients).Post("/orchestrate", teams.ServeOrchestrate)
+		r.With(authMiddleware.Authenticate, agentsScope).Get("/orchestrate/runs/{id}", teams.ServeRun)
+
+		// The caller's own preference profile
+		r.Route("/preferences", func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate, sessionsScope)
+			r.Get("/", userPreferences.ServeGet)
+			r.Put("/", userPreferences.ServeSet)
+			r.Delete("/", userPreferences.ServeDelete)
+		})
+
+		// The caller's sessions, exported and imported as signed bundles
+		r.Route("/sessions", func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate, sessionsScope)
+			r.Get("/", sessionStore.ServeList)
+			r.Post("/import", sessionStore.ServeImport)
+			r.Get("/{id}", sessionStore.ServeGet)
+			r.Delete("/{id}", sessionStore.ServeDelete)
+			r.Get("/{id}/export", sessionStore.ServeExport)
+			r.Get("/{id}/state", sessionStore.ServeState)
+		})
+
+		// Project glossaries generated from the knowledge graph, for SCRIBE
+		r.With(authMiddleware.Authenticate, memoryScope, warmup.Gate).Get("/glossary", memoryHandler.Glossary)
+
+		// Batch outcome feedback for the learning structures
+		r.With(authMiddleware.Authenticate, memoryScope, editorRole, flags.Require(features.AutoLearning)).Post("/feedback/batch", feedbackIngester.ServeBatch)
+
+		// Admin API, open to the subjects in ADMIN_SUBJECTS; changing what
+		// it manages also takes the admin role
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate, adminScope, authMiddleware.Authorize(cfg.AdminSubjects))
+			r.Get("/runtime", runtimeInfo.ServeHTTP)
+			r.Get("/features", flags.ServeList)
+			r.With(adminRole).Put("/features/{name}", flags.ServeSet)
+			r.Get("/insights", propagationEngine.ServeList)
+			r.With(adminRole).Post("/insights/{id}/approve", propagationEngine.ServeApprove)
+			r.With(adminRole).Post("/insights/{id}/reject", propagationEngine.ServeReject)
+			r.Get("/analytics", usage.ServeRollups)
+			r.Get("/analytics/digest", usage.ServeDigest)
+			r.Get("/compliance/evidence", evidence.ServePack)
+			r.Get("/memory/attention", memoryAdmin.ServeAttention)
+			r.Get("/memory/goals", memoryAdmin.ServeGoals)
+			r.Get("/memory/goals/{id}/journal", memoryAdmin.ServeGoalJournal)
+			r.Get("/memory/journals", memoryAdmin.ServeJournals)
+			r.Get("/memory/impasses", memoryAdmin.ServeImpasses)
+			r.Get("/memory/anomalies", anomalies.ServeAnomalies)
+			r.Get("/memory/nodes", memoryAdmin.ServeNodes)
+			r.Get("/memory/nodes/{id}", memoryAdmin.ServeNode)
+			r.Get("/memory/export/schema", trainingExporter.ServeSchema)
+			r.Get("/memory/export/{table}", trainingExporter.ServeExport)
+			r.With(adminRole).Post("/memory/models/routing", trainingExporter.ServeImportModel)
+			r.Get("/memory/registry", models.ServeModels)
+			r.Get("/memory/registry/{name}", models.ServeVersions)
+			r.With(adminRole).Post("/memory/registry/{name}", models.ServeCreate)
+			r.Get("/memory/registry/{name}/versions/{version}", models.ServeVersion)
+			r.With(adminRole).Post("/memory/registry/{name}/versions/{version}/promote", models.ServePromote)
+			r.With(adminRole).Post("/memory/registry/{name}/rollback", models.ServeRollback)
+			r.Get("/memory/registry/{name}/diff", models.ServeDiff)
+			r.With(adminRole).Post("/memory/routing/replay", routingReplayer.ServeReplay)
+			r.Get("/memory/sampling", samplingTuner.ServeSampling)
+			r.With(adminRole).Put("/memory/sampling/{agent}", samplingTuner.ServeSet)
+			r.With(adminRole).Post("/memory/sampling/{agent}/revert", samplingTuner.ServeRevert)
+			if promptSync != nil {
+				r.Get("/gitops", promptSync.ServeStatus)
+				r.With(adminRole).Post("/gitops/sync", promptSync.ServeSync)
+			}
+		})
+
+		// Copilot webhook endpoint with signature verification
+		// Uses signature verification when GITHUB_WEBHOOK_SECRET is configured
+		// Falls back to OIDC auth otherwise
+		r.With(signatureMiddleware.VerifySignature, authMiddleware.OptionalAuth, agentsScope, rateLimiter.LimitClients, sessions.CopilotThread, sessionStore.Prefetch).Post("/copilot", agentHandler.CopilotWebhook)
 
-	// API routes
-	r.Route("/agents", func(r chi.Router) {
-		r.Get("/", agentHandler.ListAgents)
-		r.Get("/{codename}", agentHandler.GetAgent)
-		r.With(authMiddleware.Authenticate).Post("/{codename}/invoke", agentHandler.InvokeAgent)
+		// Alternative Copilot endpoint with only OIDC auth (for direct API calls)
+		r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients).Post("/agent", agentHandler.CopilotWebhook)
+
+		// CI jobs invoke agents with a GitHub App installation token
+		r.With(installationVerifier.Authenticate, rateLimiter.LimitClients).Post("/integrations/actions", agentHandler.ActionsIntegration)
+		r.With(installationVerifier.Authenticate, rateLimiter.LimitClients).Post("/review", agentHandler.ReviewDiff)
+
+		// ECLIPSE finds the untested branches of a package posted with its tests
+		r.With(authMiddleware.Authenticate, agentsScope, rateLimiter.LimitClients).Post("/test-gaps", agentHandler.TestGaps)
+
+		// Tools agents call to act outside the collective
+		if issueTool != nil {
+			r.With(authMiddleware.Authenticate, toolsScope).Post("/tools/issues", issueTool.ServeCreateIssue)
+		}
+
+		// Embeddings from the local model, or the stub offline
+		if embeddingCache != nil {
+			embeddingHandler := embeddings.NewHandler(embeddingCache)
+			r.With(authMiddleware.Authenticate, memoryScope).Post("/embeddings", embeddingHandler.ServeEmbed)
+			r.With(authMiddleware.Authenticate, memoryScope).Get("/embeddings/stats", embeddingHandler.ServeStats)
+		}
+
+		// Chat commands are verified with each workspace's signing secret
+		if integrationsConfig != nil {
+			bridge := integrations.NewBridge(integrationsConfig, registry, notifier)
+			r.With(rateLimiter.LimitClients).Post("/integrations/slack/commands", bridge.ServeSlackCommand)
+			r.With(rateLimiter.LimitClients).Post("/integrations/teams/messages", bridge.ServeTeamsCommand)
+		}
+	})
+
+	// Memory routes
+	r.Route("/memory", func(r chi.Router) {
+		// Questions, queries and explanations only read, so degraded
+		// warmup serves them; imports wait for the warmup to complete
+		r.With(warmup.GateReads, requestTimeout, authMiddleware.Authenticate, memoryScope).Post("/ask", memoryHandler.Ask)
+		r.With(warmup.GateReads, requestTimeout, authMiddleware.Authenticate, memoryScope).Post("/query", memoryHandler.Query)
+		r.With(warmup.GateReads, requestTimeout, authMiddleware.Authenticate, memoryScope).Get("/explain", memoryHandler.Explain)
+		r.With(warmup.Gate, authMiddleware.Authenticate, memoryScope).Get("/semantic/export", memoryHandler.ExportGraph)
+
+		// Imports run as long as they keep making progress; the handler
+		// extends the connection deadlines as records arrive
+		r.With(warmup.Gate, authMiddleware.Authenticate, memoryScope, editorRole).Post("/ingest", streamIngester.ServeIngest)
 	})
 
-	// Copilot webhook endpoint with signature verification
-	// Uses signature verification when GITHUB_WEBHOOK_SECRET is configured
-	// Falls back to OIDC auth otherwise
-	r.With(signatureMiddleware.VerifySignature, authMiddleware.OptionalAuth).Post("/copilot", agentHandler.CopilotWebhook)
+	// Live events stream for as long as the client listens. Browsers offer
+	// their token as a WebSocket subprotocol
+	r.With(events.BearerFromProtocol, authMiddleware.Authenticate, adminScope, authMiddleware.Authorize(cfg.AdminSubjects)).Get("/ws", liveEvents.ServeWS)
+
+	// The admin UI is static; the data it shows comes from the admin API
+	r.Mount("/admin/ui", adminui.Handler("/admin/ui"))
 
-	// Alternative Copilot endpoint with only OIDC auth (for direct API calls)
-	r.With(authMiddleware.Authenticate).Post("/agent", agentHandler.CopilotWebhook)
+	// Index rebuilds stream progress for as long as they run
+	r.With(authMiddleware.Authenticate, adminScope, authMiddleware.Authorize(cfg.AdminSubjects), adminRole, warmup.Gate).Post("/admin/memory/reindex", reindexer.ServeReindex)
 
 	// Start server
 	addr := fmt.Sprintf(":%d", cfg.Port)
@@ -103,45 +1058,103 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Graceful shutdown handling
-	done := make(chan bool)
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-quit
-		log.Println("Server is shutting down...")
-
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		server.SetKeepAlivesEnabled(false)
-		if err := server.Shutdown(ctx); err != nil {
-			log.Fatalf("Could not gracefully shutdown the server: %v\n", err)
+	// The gRPC API listens on its own port when one is configured
+	if cfg.GRPCPort != 0 {
+		grpcAddr := fmt.Sprintf(":%d", cfg.GRPCPort)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Could not listen on %s: %v\n", grpcAddr, err)
 		}
-		close(done)
-	}()
+		grpcServer := newGRPCServer(registry, memoryHandler, warmup, authMiddleware, rateLimiter)
+		workers.Serve("grpc", func() error { return grpcServer.Serve(listener) }, func(ctx context.Context) error {
+			return stopGRPCServer(ctx, grpcServer)
+		})
+		log.Printf("gRPC API on %s", grpcAddr)
+	}
 
 	log.Printf("Server is starting on %s", addr)
 	log.Printf("Health check available at http://localhost%s/health", addr)
 	log.Printf("Agent list available at http://localhost%s/agents", addr)
 	log.Printf("Copilot webhook at http://localhost%s/copilot", addr)
+	log.Printf("Admin UI at http://localhost%s/admin/ui/", addr)
 
 	if cfg.GitHub.WebhookSecret != "" {
 		log.Printf("GitHub webhook signature verification enabled")
 	}
+	if cfg.GitHub.AppID != "" {
+		log.Printf("GitHub Actions installation token verification enabled")
+	}
 	if cfg.OIDC.ClientID != "" {
 		log.Printf("OIDC authentication enabled")
 	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Could not listen on %s: %v\n", addr, err)
-	}
+	workers.Serve("http", server.ListenAndServe, func(ctx context.Context) error {
+		server.SetKeepAlivesEnabled(false)
+		return server.Shutdown(ctx)
+	})
 
-	<-done
+	// Graceful shutdown: once a signal arrives or a worker fails, every
+	// worker is stopped, then what they leave behind is closed and saved
+	<-workers.Context().Done()
+	log.Println("Server is shutting down...")
+	failed := false
+	if err := workers.Wait(); err != nil {
+		log.Printf("Error: %v", err)
+		failed = true
+	}
+	if notifier != nil {
+		notifier.Close()
+	}
+	if audit != nil {
+		if err := audit.Close(); err != nil {
+			log.Printf("Error closing audit log: %v", err)
+		}
+	}
+	if embeddingCache != nil {
+		if err := embeddingCache.Save(); err != nil {
+			log.Printf("Error saving embedding cache: %v", err)
+		}
+	}
+	if onnxEmbedder != nil {
+		if err := onnxEmbedder.Close(); err != nil {
+			log.Printf("Error closing embedding model: %v", err)
+		}
+	}
+	if cfg.Memory.SnapshotPath != "" {
+		saveSnapshot(network, warmup, wal, cfg.Memory.SnapshotPath)
+	}
+	if wal != nil {
+		if err := wal.Close(); err != nil {
+			log.Printf("Error closing write-ahead log: %v", err)
+		}
+	}
+	if failed {
+		log.Fatalln("Server stopped after a failure")
+	}
 	log.Println("Server stopped")
 }
 
+// saveSnapshot persists the knowledge graph and drops the log records it
+// covers. A graph that never finished warming is not saved, so a partial
+// load can't replace a good snapshot.
+func saveSnapshot(network *memory.SemanticNetwork, warmup *memory.Warmup, wal *memory.WriteAheadLog, path string) {
+	if warmup.Status().State != memory.WarmupComplete {
+		log.Printf("Skipping knowledge graph snapshot: warmup did not complete")
+		return
+	}
+	snapshot := network.Snapshot()
+	if err := memory.SaveSnapshotFile(path, snapshot); err != nil {
+		log.Printf("Error saving knowledge graph snapshot: %v", err)
+		return
+	}
+	log.Printf("Saved knowledge graph snapshot to %s at LSN %d", path, snapshot.LSN)
+	if wal != nil {
+		if err := wal.Checkpoint(snapshot.LSN); err != nil {
+			log.Printf("Error checkpointing write-ahead log: %v", err)
+		}
+	}
+}
+
 // healthCheckHandler handles the /health endpoint.
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,41 @@
+// Package adminui serves the admin web UI, a small single page built into
+// the binary. It shows the registered agents, live routing attention and
+// activation, goals and impasses, and explores the knowledge graph. The
+// page itself holds no data: it reads the admin API with a bearer token the
+// operator enters, so serving it needs no authentication.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the UI mounted at prefix, such as /admin/ui. Requests for
+// the prefix itself are redirected to it with a trailing slash, so the
+// page's relative asset paths resolve.
+func Handler(prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix(prefix, http.FileServerFS(assets))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix {
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		// Assets change with the binary; revalidate rather than serve a
+		// stale page after an upgrade
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; connect-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,44 @@
+package adminui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Mount("/admin/ui", Handler("/admin/ui"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ui", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/admin/ui/" {
+		t.Errorf("expected a redirect to /admin/ui/, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ui/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<script src="app.js">`) {
+		t.Fatalf("expected the page, got %d", w.Code)
+	}
+	if !strings.Contains(w.Header().Get("Content-Security-Policy"), "default-src 'self'") || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected security and caching headers, got %v", w.Header())
+	}
+
+	for path, contentType := range map[string]string{"/admin/ui/app.js": "javascript", "/admin/ui/style.css": "text/css"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Type"), contentType) {
+			t.Errorf("expected %s served as %s, got %d %q", path, contentType, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ui/missing.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing asset, got %d", w.Code)
+	}
+}
@@ -0,0 +1,261 @@
+// Admin UI for the Elite Agent Collective. Reads the admin API with the
+// operator's bearer token, kept for the browser session only. Everything
+// rendered from the API goes through textContent, never innerHTML.
+"use strict";
+
+const REFRESH_MS = 5000;
+const TOKEN_KEY = "eac-admin-token";
+
+let activePanel = "agents";
+let refreshTimer = null;
+let agents = [];
+
+const $ = (id) => document.getElementById(id);
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined && text !== null) node.textContent = String(text);
+  if (className) node.className = className;
+  return node;
+}
+
+function row(cells) {
+  const tr = el("tr");
+  for (const cell of cells) {
+    const td = el("td");
+    if (cell instanceof Node) td.appendChild(cell);
+    else td.textContent = cell === undefined || cell === null ? "" : String(cell);
+    tr.appendChild(td);
+  }
+  return tr;
+}
+
+function emptyRow(tbody, columns, message) {
+  const td = el("td", message, "empty");
+  td.colSpan = columns;
+  const tr = el("tr");
+  tr.appendChild(td);
+  tbody.appendChild(tr);
+}
+
+function setStatus(message, error) {
+  const status = $("status");
+  status.textContent = message;
+  status.className = error ? "status error" : "status";
+}
+
+async function api(path) {
+  const headers = { Accept: "application/json" };
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) headers.Authorization = "Bearer " + token;
+  const resp = await fetch(path, { headers });
+  if (!resp.ok) {
+    let message = resp.status + " " + resp.statusText;
+    try {
+      const body = await resp.json();
+      if (body.error) message = body.error;
+    } catch (e) {
+      // not JSON; keep the status line
+    }
+    throw new Error(path + ": " + message);
+  }
+  return resp.json();
+}
+
+// ---------------------------------------------------------------------------
+// Panels
+// ---------------------------------------------------------------------------
+
+function renderAgents() {
+  const tbody = $("agent-rows");
+  const filter = $("agent-filter").value.trim().toLowerCase();
+  tbody.replaceChildren();
+  const shown = agents.filter((a) =>
+    !filter || [a.codename, a.specialty, a.category].some((v) => (v || "").toLowerCase().includes(filter)));
+  if (shown.length === 0) {
+    emptyRow(tbody, 5, "No agents");
+    return;
+  }
+  for (const a of shown) {
+    const lifecycle = a.lifecycle ? a.lifecycle.state + (a.lifecycle.replaced_by ? " → " + a.lifecycle.replaced_by : "") : "active";
+    tbody.appendChild(row([a.id, a.codename, a.tier, a.specialty, lifecycle]));
+  }
+}
+
+async function loadAgents() {
+  agents = await api("/agents");
+  renderAgents();
+}
+
+async function loadAttention() {
+  const data = await api("/admin/memory/attention");
+  const cards = $("attention-categories");
+  cards.replaceChildren();
+  for (const category of Object.keys(data.categories).sort()) {
+    const card = el("div", null, "card");
+    card.appendChild(el("h3", category));
+    for (const weight of data.categories[category]) {
+      card.appendChild(el("div", weight.agent + "  " + (weight.weight * 100).toFixed(1) + "%"));
+      const bar = el("div", null, "bar");
+      bar.style.width = Math.min(100, weight.weight * 100) + "%";
+      card.appendChild(bar);
+    }
+    cards.appendChild(card);
+  }
+
+  const list = $("activated");
+  list.replaceChildren();
+  if (data.activated.length === 0) list.appendChild(el("li", "Nothing is active", "empty"));
+  for (const node of data.activated) {
+    const li = el("li");
+    li.appendChild(nodeLink(node));
+    li.appendChild(document.createTextNode("  " + node.activation.toFixed(3)));
+    list.appendChild(li);
+  }
+}
+
+async function loadGoals() {
+  const data = await api("/admin/memory/goals");
+  const tbody = $("goal-rows");
+  tbody.replaceChildren();
+  if (data.goals.length === 0) {
+    emptyRow(tbody, 5, "No goals");
+    return;
+  }
+  for (const g of data.goals) {
+    const name = "  ".repeat(g.depth) + g.name + (g.id === data.current ? "  (current)" : "");
+    let reason = g.blocked_by ? "waiting on " + g.blocked_by.join(", ") : g.reason;
+    if (g.exhausted) reason = "past its " + g.exhausted + (g.exhausted === "deadline" ? "" : " budget");
+    tbody.appendChild(row([name, g.status, g.priority, Math.round(g.progress * 100) + "%", reason]));
+  }
+}
+
+async function loadImpasses() {
+  const data = await api("/admin/memory/impasses");
+  $("impasse-counts").textContent = data.active + " active, " + data.resolved + " resolved";
+  const tbody = $("impasse-rows");
+  tbody.replaceChildren();
+  if (data.recent.length === 0) {
+    emptyRow(tbody, 5, "No impasses");
+    return;
+  }
+  for (const imp of data.recent) {
+    tbody.appendChild(row([
+      new Date(imp.detected_at).toLocaleString(),
+      imp.type,
+      imp.description,
+      (imp.candidates || []).join(", "),
+      imp.resolution || "unresolved",
+    ]));
+  }
+}
+
+// ---------------------------------------------------------------------------
+// Knowledge graph explorer
+// ---------------------------------------------------------------------------
+
+function nodeLink(node) {
+  const a = el("a", node.label);
+  a.title = node.id + " (" + node.type + ")";
+  a.addEventListener("click", () => {
+    showPanel("graph");
+    loadNode(node.id).catch(report);
+  });
+  return a;
+}
+
+async function searchNodes(query) {
+  const data = await api("/admin/memory/nodes?limit=100&q=" + encodeURIComponent(query));
+  const list = $("node-results");
+  list.replaceChildren();
+  if (data.nodes.length === 0) list.appendChild(el("li", "No matching concepts", "empty"));
+  for (const node of data.nodes) {
+    const li = el("li");
+    li.appendChild(nodeLink(node));
+    list.appendChild(li);
+  }
+  if (data.total > data.nodes.length) {
+    list.appendChild(el("li", (data.total - data.nodes.length) + " more; refine the search", "empty"));
+  }
+}
+
+async function loadNode(id) {
+  const data = await api("/admin/memory/nodes/" + encodeURIComponent(id));
+  const labels = {};
+  labels[data.node.id] = data.node;
+  for (const n of data.neighbors) labels[n.id] = n;
+
+  const detail = $("node-detail");
+  detail.replaceChildren();
+  detail.appendChild(el("h2", data.node.label + " (" + data.node.type + ")"));
+  detail.appendChild(el("p", "ID " + data.node.id + ", confidence " + data.node.confidence.toFixed(2) +
+    ", activation " + data.activation.toFixed(3)));
+
+  const table = el("table");
+  const head = el("tr");
+  for (const h of ["Source", "Relation", "Target", "Weight"]) head.appendChild(el("th", h));
+  table.appendChild(head);
+  const end = (nodeID) => labels[nodeID] ? nodeLink(labels[nodeID]) : el("span", nodeID);
+  for (const rel of data.relations) {
+    table.appendChild(row([end(rel.source), rel.type, end(rel.target), rel.weight.toFixed(2)]));
+  }
+  if (data.relations.length === 0) detail.appendChild(el("p", "No relations", "empty"));
+  else detail.appendChild(table);
+}
+
+// ---------------------------------------------------------------------------
+// Navigation and refresh
+// ---------------------------------------------------------------------------
+
+const loaders = {
+  agents: loadAgents,
+  attention: loadAttention,
+  goals: loadGoals,
+  impasses: loadImpasses,
+};
+
+function report(err) {
+  setStatus(err.message, true);
+}
+
+function refresh() {
+  const load = loaders[activePanel];
+  if (!load) return;
+  load().then(() => setStatus("Updated " + new Date().toLocaleTimeString())).catch(report);
+}
+
+function showPanel(name) {
+  activePanel = name;
+  for (const button of document.querySelectorAll("nav button")) {
+    button.classList.toggle("active", button.dataset.panel === name);
+  }
+  for (const panel of document.querySelectorAll(".panel")) {
+    panel.classList.toggle("active", panel.id === name);
+  }
+  clearInterval(refreshTimer);
+  refresh();
+  // Agents change rarely and the explorer is driven by the operator
+  if (name !== "agents" && loaders[name]) refreshTimer = setInterval(refresh, REFRESH_MS);
+}
+
+for (const button of document.querySelectorAll("nav button")) {
+  button.addEventListener("click", () => showPanel(button.dataset.panel));
+}
+
+$("token-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const token = $("token").value.trim();
+  if (token) sessionStorage.setItem(TOKEN_KEY, token);
+  else sessionStorage.removeItem(TOKEN_KEY);
+  $("token").value = "";
+  refresh();
+});
+
+$("agent-filter").addEventListener("input", renderAgents);
+
+$("node-search").addEventListener("submit", (event) => {
+  event.preventDefault();
+  searchNodes($("node-query").value.trim()).catch(report);
+});
+
+showPanel("agents");
@@ -0,0 +1,270 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+const (
+	// maxActionsBodyBytes bounds an Actions request, patches included
+	maxActionsBodyBytes = 1 << 20
+	// maxActionsPatchBytes bounds the patch text sent to the agent; later
+	// files are listed by path only
+	maxActionsPatchBytes = 64 << 10
+)
+
+// Finding levels, named as in GitHub Actions workflow commands.
+const (
+	FindingNotice  = "notice"
+	FindingWarning = "warning"
+	FindingError   = "error"
+)
+
+// Conclusions of an Actions invocation, named as in GitHub check runs.
+const (
+	ConclusionSuccess = "success"
+	ConclusionNeutral = "neutral"
+	ConclusionFailure = "failure"
+)
+
+// findingPattern matches a workflow command such as
+// "::warning file=app.go,line=3,title=Untested::message" in agent output.
+var findingPattern = regexp.MustCompile(`^\s*::(notice|warning|error)(?:\s+([^:]*))?::(.*)$`)
+
+// repositoryPattern matches "owner/name" repository names.
+var repositoryPattern = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// findingInstructions tells the agent how to report findings so they can
+// be parsed.
+const findingInstructions = `Report each finding on its own line as a GitHub Actions workflow command:
+::warning file=PATH,line=START,endLine=END,title=TITLE::MESSAGE
+Use notice, warning or error as the level. Omit file and line for findings about the change as a whole.`
+
+// ActionsRequest is the payload a GitHub Actions job sends to invoke an
+// agent on a repository.
+type ActionsRequest struct {
+	// Agent is the codename or alias of the agent to invoke, in any case
+	Agent  string `json:"agent"`
+	Prompt string `json:"prompt"`
+	// Repository is "owner/name"; the installation token must access it
+	Repository  string `json:"repository"`
+	SHA         string `json:"sha,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	// Files are the changed files, given to the agent as context
+	Files []ActionsFile `json:"files,omitempty"`
+}
+
+// ActionsFile is a changed file and its unified diff.
+type ActionsFile struct {
+	Path  string `json:"path"`
+	Patch string `json:"patch,omitempty"`
+}
+
+// ActionsFinding is one issue an agent reported, ready to become a job
+// annotation.
+type ActionsFinding struct {
+	// Level is notice, warning or error
+	Level     string `json:"level"`
+	Path      string `json:"path,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Message   string `json:"message"`
+}
+
+// ActionsResponse is the machine-readable result of an Actions invocation.
+type ActionsResponse struct {
+	Agent      string `json:"agent"`
+	Repository string `json:"repository"`
+	SHA        string `json:"sha,omitempty"`
+	// Conclusion is failure if any finding is an error, neutral if any is
+	// a warning, and success otherwise
+	Conclusion string `json:"conclusion"`
+	// Summary is the agent's reply without the finding lines
+	Summary  string           `json:"summary"`
+	Findings []ActionsFinding `json:"findings"`
+}
+
+// ActionsIntegration handles POST /integrations/actions - invokes an agent
+// for a CI job and returns its findings in a form the job can turn into
+// annotations. The caller's installation token must access the repository.
+func (h *Handler) ActionsIntegration(w http.ResponseWriter, r *http.Request) {
+	var req ActionsRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxActionsBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		errdefs.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if installation := auth.GetInstallation(r.Context()); installation != nil && !installation.CanAccess(req.Repository) {
+		errdefs.WriteError(w, "installation token can't access "+req.Repository, http.StatusForbidden)
+		return
+	}
+
+	agent, res, err := h.registry.Resolve(strings.ToUpper(req.Agent))
+	if err != nil {
+		errdefs.WriteError(w, err.Error(), resolveStatus(err))
+		return
+	}
+	writeDeprecationHeaders(w, res)
+
+	release, err := h.registry.Admit(r.Context(), agent)
+	if err != nil {
+		log.Printf("Request not admitted: %v", err)
+		status := errdefs.HTTPStatus(err)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", retryAfter(err))
+		}
+		if status == http.StatusInternalServerError {
+			errdefs.WriteError(w, "Error processing request", status)
+			return
+		}
+		errdefs.WriteError(w, err.Error(), status)
+		return
+	}
+	defer release()
+
+	log.Printf("Actions integration: invoking agent %s for %s", res.Codename, req.Repository)
+
+	resp, err := h.registry.Handle(r.Context(), agent, RouteActions, &models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: req.message()}},
+	})
+	if err != nil {
+		log.Printf("Error handling Actions request: %v", err)
+		errdefs.WriteError(w, "Error processing request", http.StatusBadGateway)
+		return
+	}
+
+	summary, findings := parseFindings(resp.Choices[0].Message.Content)
+	errdefs.WriteJSON(w, http.StatusOK, &ActionsResponse{
+		Agent:      res.Codename,
+		Repository: req.Repository,
+		SHA:        req.SHA,
+		Conclusion: conclude(findings),
+		Summary:    summary,
+		Findings:   findings,
+	})
+}
+
+// validate checks a request's required fields.
+func (req *ActionsRequest) validate() error {
+	switch {
+	case req.Agent == "":
+		return errors.New("agent is required")
+	case strings.TrimSpace(req.Prompt) == "":
+		return errors.New("prompt is required")
+	case !repositoryPattern.MatchString(req.Repository):
+		return errors.New(`repository must be "owner/name"`)
+	}
+	return nil
+}
+
+// message builds the prompt sent to the agent: the job's prompt, the
+// change it runs on, and how to report findings.
+func (req *ActionsRequest) message() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(req.Prompt))
+	b.WriteString("\n\nRepository: " + req.Repository)
+	if req.PullRequest > 0 {
+		fmt.Fprintf(&b, "\nPull request: #%d", req.PullRequest)
+	}
+	if req.Ref != "" {
+		b.WriteString("\nRef: " + req.Ref)
+	}
+	if req.SHA != "" {
+		b.WriteString("\nCommit: " + req.SHA)
+	}
+
+	if len(req.Files) > 0 {
+		b.WriteString("\n\nChanged files:")
+		budget := maxActionsPatchBytes
+		for _, file := range req.Files {
+			b.WriteString("\n--- " + file.Path)
+			if file.Patch == "" {
+				continue
+			}
+			if len(file.Patch) > budget {
+				b.WriteString("\n(patch omitted)")
+				continue
+			}
+			budget -= len(file.Patch)
+			b.WriteString("\n" + strings.TrimRight(file.Patch, "\n"))
+		}
+	}
+
+	b.WriteString("\n\n" + findingInstructions)
+	return b.String()
+}
+
+// parseFindings splits an agent's reply into its finding lines and the
+// rest, which is returned as the summary.
+func parseFindings(output string) (string, []ActionsFinding) {
+	findings := []ActionsFinding{}
+	var summary []string
+	for _, line := range strings.Split(output, "\n") {
+		match := findingPattern.FindStringSubmatch(line)
+		if match == nil {
+			summary = append(summary, line)
+			continue
+		}
+		finding := ActionsFinding{Level: match[1], Message: unescapeCommandData(strings.TrimSpace(match[3]))}
+		for _, property := range strings.Split(match[2], ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(property), "=")
+			value = unescapeCommandProperty(value)
+			switch key {
+			case "file":
+				finding.Path = value
+			case "line":
+				finding.StartLine, _ = strconv.Atoi(value)
+			case "endLine":
+				finding.EndLine, _ = strconv.Atoi(value)
+			case "title":
+				finding.Title = value
+			}
+		}
+		if finding.EndLine < finding.StartLine {
+			finding.EndLine = finding.StartLine
+		}
+		findings = append(findings, finding)
+	}
+	return strings.TrimSpace(strings.Join(summary, "\n")), findings
+}
+
+// conclude derives a job conclusion from the most severe finding.
+func conclude(findings []ActionsFinding) string {
+	conclusion := ConclusionSuccess
+	for _, finding := range findings {
+		switch finding.Level {
+		case FindingError:
+			return ConclusionFailure
+		case FindingWarning:
+			conclusion = ConclusionNeutral
+		}
+	}
+	return conclusion
+}
+
+// unescapeCommandData reverses workflow command message escaping.
+func unescapeCommandData(s string) string {
+	return strings.NewReplacer("%0D", "\r", "%0A", "\n", "%25", "%").Replace(s)
+}
+
+// unescapeCommandProperty reverses workflow command property escaping.
+func unescapeCommandProperty(s string) string {
+	return strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%").Replace(s)
+}
@@ -0,0 +1,140 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+const actionsReply = `Two paths in the parser have no tests.
+::error file=parser.go,line=42,endLine=48,title=Untested error path::parse returns early on EOF%0Awithout a test
+::warning file=lexer.go,line=7,title=Edge case%2C unicode::No test for multi-byte runes
+::notice::Coverage tooling is not configured
+Consider table-driven tests.`
+
+// setupActionsRouter routes the Actions endpoint to a registry holding
+// the given agent.
+func setupActionsRouter(agent *scriptedAgent) *chi.Mux {
+	registry := NewRegistry()
+	registry.Register(agent)
+	handler := NewHandler(registry)
+	r := chi.NewRouter()
+	r.Post("/integrations/actions", handler.ActionsIntegration)
+	return r
+}
+
+func TestParseFindings(t *testing.T) {
+	summary, findings := parseFindings(actionsReply)
+
+	if summary != "Two paths in the parser have no tests.\nConsider table-driven tests." {
+		t.Errorf("expected finding lines removed from summary, got %q", summary)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(findings))
+	}
+
+	first := findings[0]
+	if first.Level != FindingError || first.Path != "parser.go" || first.StartLine != 42 || first.EndLine != 48 {
+		t.Errorf("expected error on parser.go lines 42-48, got %+v", first)
+	}
+	if first.Title != "Untested error path" || first.Message != "parse returns early on EOF\nwithout a test" {
+		t.Errorf("expected unescaped title and message, got %+v", first)
+	}
+	if findings[1].Title != "Edge case, unicode" || findings[1].EndLine != 7 {
+		t.Errorf("expected unescaped title and end line defaulted to start, got %+v", findings[1])
+	}
+	if findings[2].Level != FindingNotice || findings[2].Path != "" || findings[2].Message != "Coverage tooling is not configured" {
+		t.Errorf("expected notice without a location, got %+v", findings[2])
+	}
+
+	if conclude(findings) != ConclusionFailure {
+		t.Error("expected failure with an error finding")
+	}
+	if conclude(findings[1:]) != ConclusionNeutral {
+		t.Error("expected neutral with a warning finding")
+	}
+	if conclude(nil) != ConclusionSuccess {
+		t.Error("expected success without findings")
+	}
+}
+
+func TestActionsIntegration(t *testing.T) {
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: actionsReply}
+	r := setupActionsRouter(eclipse)
+
+	body := `{
+		"agent": "eclipse",
+		"prompt": "Find test gaps in this change",
+		"repository": "octo-org/api",
+		"sha": "0a1b2c3",
+		"pull_request": 12,
+		"files": [{"path": "parser.go", "patch": "@@ -40,3 +40,9 @@\n+if err == io.EOF {"}]
+	}`
+	req := httptest.NewRequest("POST", "/integrations/actions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ActionsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Agent != "ECLIPSE" || resp.Repository != "octo-org/api" || resp.SHA != "0a1b2c3" {
+		t.Errorf("expected agent and repository echoed, got %+v", resp)
+	}
+	if resp.Conclusion != ConclusionFailure || len(resp.Findings) != 3 {
+		t.Errorf("expected failure with 3 findings, got %s with %d", resp.Conclusion, len(resp.Findings))
+	}
+
+	prompt, _ := eclipse.prompt.Load().(string)
+	for _, want := range []string{"Find test gaps in this change", "Repository: octo-org/api", "Pull request: #12", "--- parser.go\n@@ -40,3", "::warning file=PATH"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected prompt to contain %q, got %q", want, prompt)
+		}
+	}
+}
+
+func TestActionsIntegrationErrors(t *testing.T) {
+	r := setupActionsRouter(&scriptedAgent{codename: "ECLIPSE", reply: "ok"})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"no prompt", `{"agent": "ECLIPSE", "repository": "octo-org/api"}`, http.StatusBadRequest},
+		{"bad repository", `{"agent": "ECLIPSE", "prompt": "p", "repository": "api"}`, http.StatusBadRequest},
+		{"unknown agent", `{"agent": "NOBODY", "prompt": "p", "repository": "octo-org/api"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/integrations/actions", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"error"`) {
+			t.Errorf("%s: expected JSON error, got %s", tt.name, w.Body.String())
+		}
+	}
+
+	// The installation token must reach the repository
+	body := `{"agent": "ECLIPSE", "prompt": "p", "repository": "octo-org/secret"}`
+	installation := &auth.Installation{Repositories: []string{"octo-org/api"}}
+	ctx := context.WithValue(context.Background(), auth.InstallationContextKey, installation)
+	req := httptest.NewRequest("POST", "/integrations/actions", strings.NewReader(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for an inaccessible repository, got %d", w.Code)
+	}
+}
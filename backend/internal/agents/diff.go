@@ -0,0 +1,195 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrInvalidDiff is returned for text that is not a unified diff.
+var ErrInvalidDiff = errdefs.New(errdefs.ErrInvalidArgument, "invalid diff")
+
+// hunkHeaderPattern matches a hunk header such as "@@ -12,7 +12,9 @@ func main".
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// DiffFile is one file of a unified diff.
+type DiffFile struct {
+	// Path is the file's path after the change; for a deleted file, its
+	// path before
+	Path    string
+	OldPath string
+	Deleted bool
+	// Binary is set for files whose change the diff does not show
+	Binary bool
+	Hunks  []DiffHunk
+}
+
+// DiffHunk is one hunk of a file's diff.
+type DiffHunk struct {
+	// Header is the hunk's "@@ -12,7 +12,9 @@" line
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Lines are the hunk's lines with their " ", "+", "-" or "\" prefix
+	Lines []string
+}
+
+// ParseDiff parses a unified diff, as written by git diff or diff -u, into
+// its files.
+func ParseDiff(text string) ([]DiffFile, error) {
+	var files []DiffFile
+	var file *DiffFile
+	// oldLeft and newLeft count the lines the current hunk has yet to show
+	oldLeft, newLeft := 0, 0
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for n, line := range lines {
+		if file != nil && len(file.Hunks) > 0 && (oldLeft > 0 || newLeft > 0 || strings.HasPrefix(line, `\`)) {
+			hunk := &file.Hunks[len(file.Hunks)-1]
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				// Some tools strip the space of empty context lines
+				line = " " + line
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(line, `\`):
+			default:
+				return nil, fmt.Errorf("%w: line %d: hunk %s ends early", ErrInvalidDiff, n+1, hunk.Header)
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, fmt.Errorf("%w: line %d: hunk %s is longer than its header says", ErrInvalidDiff, n+1, hunk.Header)
+			}
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, DiffFile{})
+			file = &files[len(files)-1]
+			if a, b, ok := strings.Cut(strings.TrimPrefix(line, "diff --git "), " b/"); ok {
+				file.OldPath, file.Path = strings.TrimPrefix(a, "a/"), b
+			}
+		case strings.HasPrefix(line, "--- "):
+			// A diff without git headers starts each file here
+			if file == nil || len(file.Hunks) > 0 {
+				files = append(files, DiffFile{})
+				file = &files[len(files)-1]
+			}
+			if path := diffPath(line, "a/"); path != "" {
+				file.OldPath = path
+			}
+		case strings.HasPrefix(line, "+++ ") && file != nil:
+			if path := diffPath(line, "b/"); path != "" {
+				file.Path = path
+			} else {
+				file.Deleted = true
+			}
+		case strings.HasPrefix(line, "deleted file mode") && file != nil:
+			file.Deleted = true
+		case strings.HasPrefix(line, "rename to ") && file != nil:
+			file.Path = strings.TrimPrefix(line, "rename to ")
+		case (strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch") && file != nil:
+			file.Binary = true
+		case strings.HasPrefix(line, "@@"):
+			if file == nil {
+				return nil, fmt.Errorf("%w: line %d: hunk outside a file", ErrInvalidDiff, n+1)
+			}
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("%w: line %d: malformed hunk header %q", ErrInvalidDiff, n+1, line)
+			}
+			hunk := DiffHunk{Header: line, OldStart: atoi(match[1]), OldLines: 1, NewStart: atoi(match[3]), NewLines: 1}
+			if match[2] != "" {
+				hunk.OldLines = atoi(match[2])
+			}
+			if match[4] != "" {
+				hunk.NewLines = atoi(match[4])
+			}
+			file.Hunks = append(file.Hunks, hunk)
+			oldLeft, newLeft = hunk.OldLines, hunk.NewLines
+		}
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("%w: the last hunk is cut short", ErrInvalidDiff)
+	}
+
+	parsed := files[:0]
+	for _, file := range files {
+		if file.Path == "" {
+			file.Path = file.OldPath
+		}
+		if file.Path == "" || (len(file.Hunks) == 0 && !file.Binary && !file.Deleted) {
+			// Mode changes and pure renames have nothing to review
+			continue
+		}
+		parsed = append(parsed, file)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%w: no changed files", ErrInvalidDiff)
+	}
+	return parsed, nil
+}
+
+// diffPath returns the path of a "---" or "+++" line without its prefix,
+// or "" for /dev/null.
+func diffPath(line, prefix string) string {
+	path := strings.TrimSpace(line[4:])
+	// diff -u follows the path with a tab and a timestamp
+	path, _, _ = strings.Cut(path, "\t")
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// atoi parses a number the hunk header pattern has matched.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// hunkAt returns the index of the hunk that shows a line of the new file,
+// added or unchanged, or -1 if no hunk does. Only those lines can carry
+// review comments.
+func (f *DiffFile) hunkAt(line int) int {
+	for i, hunk := range f.Hunks {
+		n := hunk.NewStart
+		for _, l := range hunk.Lines {
+			if l[0] != '+' && l[0] != ' ' {
+				continue
+			}
+			if n == line {
+				return i
+			}
+			n++
+		}
+	}
+	return -1
+}
+
+// numbered returns a hunk's lines with the new file's line numbers in the
+// margin, so agents can anchor their comments.
+func (h *DiffHunk) numbered() string {
+	var b strings.Builder
+	b.WriteString(h.Header + "\n")
+	n := h.NewStart
+	for _, line := range h.Lines {
+		if line[0] == '+' || line[0] == ' ' {
+			fmt.Fprintf(&b, "%5d %s\n", n, line)
+			n++
+		} else {
+			fmt.Fprintf(&b, "      %s\n", line)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package agents
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const reviewDiff = `diff --git a/parser.go b/parser.go
+index 1111111..2222222 100644
+--- a/parser.go
++++ b/parser.go
+@@ -40,3 +40,6 @@ func parse(r io.Reader) error {
+ 	tok, err := next(r)
+-	if err != nil {
++	if err == io.EOF {
++		return nil
++	}
++	if err != nil {
+ 		return err
+@@ -90,2 +92,3 @@ func next(r io.Reader) (string, error) {
+ 	buf := make([]byte, 1)
++	// --- read one byte
+ 	return read(r, buf)
+diff --git a/parser_test.go b/parser_test.go
+new file mode 100644
+--- /dev/null
++++ b/parser_test.go
+@@ -0,0 +1,2 @@
++package parser
++
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package parser
+diff --git a/logo.png b/logo.png
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParseDiff(t *testing.T) {
+	files, err := ParseDiff(reviewDiff)
+	if err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("expected 4 files, got %+v", files)
+	}
+	parser := files[0]
+	if parser.Path != "parser.go" || len(parser.Hunks) != 2 || len(parser.Hunks[0].Lines) != 7 || parser.Hunks[1].NewStart != 92 {
+		t.Errorf("unexpected file %+v", parser)
+	}
+	if parser.Hunks[1].Lines[1] != "+\t// --- read one byte" {
+		t.Errorf("expected a line starting with --- kept in its hunk, got %q", parser.Hunks[1].Lines[1])
+	}
+	if files[1].Path != "parser_test.go" || files[1].Deleted {
+		t.Errorf("unexpected new file %+v", files[1])
+	}
+	if !files[2].Deleted || files[2].Path != "old.go" {
+		t.Errorf("unexpected deleted file %+v", files[2])
+	}
+	if !files[3].Binary {
+		t.Errorf("expected a binary file, got %+v", files[3])
+	}
+
+	// Added and unchanged lines can be commented on, removed ones not
+	for line, hunk := range map[int]int{40: 0, 42: 0, 45: 0, 46: -1, 93: 1, 91: -1} {
+		if got := parser.hunkAt(line); got != hunk {
+			t.Errorf("expected line %d in hunk %d, got %d", line, hunk, got)
+		}
+	}
+	if numbered := parser.Hunks[0].numbered(); !strings.Contains(numbered, "   41 +\tif err == io.EOF {") || !strings.Contains(numbered, "      -\tif err != nil {") {
+		t.Errorf("expected new line numbers in the margin, got\n%s", numbered)
+	}
+}
+
+func TestParseDiffPlain(t *testing.T) {
+	diff := "--- a.txt\t2026-01-01 10:00:00\n+++ a.txt\t2026-01-02 10:00:00\n@@ -1 +1 @@\n-old\n+new\n\\ No newline at end of file\n"
+	files, err := ParseDiff(diff)
+	if err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "a.txt" || len(files[0].Hunks[0].Lines) != 3 {
+		t.Errorf("unexpected files %+v", files)
+	}
+}
+
+func TestParseDiffInvalid(t *testing.T) {
+	for name, diff := range map[string]string{
+		"empty":       "",
+		"not a diff":  "hello\nworld\n",
+		"cut short":   "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n",
+		"bad header":  "--- a/x\n+++ b/x\n@@ -1,3 +1,3\n",
+		"orphan hunk": "@@ -1 +1 @@\n-a\n+b\n",
+		"ends early":  "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\nstray\n",
+	} {
+		if _, err := ParseDiff(diff); !errors.Is(err, ErrInvalidDiff) {
+			t.Errorf("%s: expected ErrInvalidDiff, got %v", name, err)
+		}
+	}
+}
@@ -2,15 +2,23 @@
 package agents
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 )
 
@@ -20,6 +28,19 @@ var agentMentionPattern = regexp.MustCompile(`@([A-Za-z]+)`)
 // Handler provides HTTP handlers for agent endpoints.
 type Handler struct {
 	registry *Registry
+
+	// workflows runs declarative workflows; nil disables the endpoints
+	workflows *WorkflowEngine
+
+	// fuse merges multi-agent answers; nil concatenates them
+	fuse func([]models.AgentAnswer) string
+
+	// githubAPI is the GitHub API pull requests are reviewed from
+	githubAPI string
+
+	// drafter drafts answers streamed ahead of the full answer; nil
+	// streams the full answer alone
+	drafter Drafter
 }
 
 // NewHandler creates a new agent handler.
@@ -30,29 +51,30 @@ func NewHandler(registry *Registry) *Handler {
 }
 
 // ListAgents handles GET /agents - returns all registered agents.
+// Responses carry an ETag, and requests naming it get 304 Not Modified.
 func (h *Handler) ListAgents(w http.ResponseWriter, r *http.Request) {
 	agents := h.registry.List()
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(agents); err != nil {
+	if err := writeCachedJSON(w, r, agents); err != nil {
 		log.Printf("Error encoding agents list: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 }
 
-// GetAgent handles GET /agents/{codename} - returns a specific agent's info.
+// GetAgent handles GET /agents/{codename} - returns a specific agent's
+// info and capabilities, cached like the agent list.
 func (h *Handler) GetAgent(w http.ResponseWriter, r *http.Request) {
 	codename := chi.URLParam(r, "codename")
 
-	agent, err := h.registry.Get(codename)
+	agent, res, err := h.registry.Resolve(codename)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), resolveStatus(err))
 		return
 	}
+	writeDeprecationHeaders(w, res)
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(agent.GetInfo()); err != nil {
+	if err := writeCachedJSON(w, r, h.registry.Info(agent)); err != nil {
 		log.Printf("Error encoding agent info: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -63,11 +85,12 @@ func (h *Handler) GetAgent(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) InvokeAgent(w http.ResponseWriter, r *http.Request) {
 	codename := chi.URLParam(r, "codename")
 
-	agent, err := h.registry.Get(codename)
+	agent, res, err := h.registry.Resolve(codename)
 	if err != nil {
-		copilot.WriteError(w, err.Error(), http.StatusNotFound)
+		copilot.WriteError(w, err.Error(), resolveStatus(err))
 		return
 	}
+	writeDeprecationHeaders(w, res)
 
 	req, err := copilot.ParseRequest(r)
 	if err != nil {
@@ -76,9 +99,21 @@ func (h *Handler) InvokeAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	release, err := h.registry.Admit(r.Context(), agent)
+	if err != nil {
+		writeAdmitError(w, err)
+		return
+	}
+	defer release()
+
 	log.Printf("Invoking agent %s with %d messages", codename, len(req.Messages))
 
-	resp, err := agent.Handle(r.Context(), req)
+	if h.wantsDraft(req) {
+		h.streamDrafted(w, r, agent, RouteDirect, req)
+		return
+	}
+
+	resp, err := h.registry.Handle(r.Context(), agent, RouteDirect, req)
 	if err != nil {
 		log.Printf("Error handling request: %v", err)
 		copilot.WriteError(w, "Error processing request", http.StatusInternalServerError)
@@ -87,7 +122,7 @@ func (h *Handler) InvokeAgent(w http.ResponseWriter, r *http.Request) {
 
 	// Support streaming responses if requested
 	if req.Stream {
-		if err := copilot.WriteStreamingResponse(w, resp.Choices[0].Message.Content); err != nil {
+		if err := copilot.StreamResponse(w, resp); err != nil {
 			log.Printf("Error writing streaming response: %v", err)
 		}
 		return
@@ -120,8 +155,10 @@ func (h *Handler) CopilotWebhook(w http.ResponseWriter, r *http.Request) {
 	codenames := extractAllAgentCodenames(userMessage)
 
 	// If no agents specified, default to APEX
+	route := RouteMention
 	if len(codenames) == 0 {
 		codenames = []string{"APEX"}
+		route = RouteDefault
 	}
 
 	// Handle multi-agent collaboration
@@ -132,16 +169,32 @@ func (h *Handler) CopilotWebhook(w http.ResponseWriter, r *http.Request) {
 
 	// Single agent invocation
 	codename := codenames[0]
-	agent, err := h.registry.Get(codename)
+	agent, res, err := h.registry.Resolve(codename)
 	if err != nil {
 		// Fall back to APEX if agent not found
 		agent, _ = h.registry.Get("APEX")
 		codename = "APEX"
+		route = RouteFallback
+	} else {
+		writeDeprecationHeaders(w, res)
+		codename = res.Codename
+	}
+
+	release, err := h.registry.Admit(r.Context(), agent)
+	if err != nil {
+		writeAdmitError(w, err)
+		return
 	}
+	defer release()
 
 	log.Printf("Copilot webhook: routing to agent %s", codename)
 
-	resp, err := agent.Handle(r.Context(), req)
+	if h.wantsDraft(req) {
+		h.streamDrafted(w, r, agent, route, req)
+		return
+	}
+
+	resp, err := h.registry.Handle(r.Context(), agent, route, req)
 	if err != nil {
 		log.Printf("Error handling Copilot request: %v", err)
 		copilot.WriteError(w, "Error processing request", http.StatusInternalServerError)
@@ -150,7 +203,7 @@ func (h *Handler) CopilotWebhook(w http.ResponseWriter, r *http.Request) {
 
 	// Support streaming responses if requested
 	if req.Stream {
-		if err := copilot.WriteStreamingResponse(w, resp.Choices[0].Message.Content); err != nil {
+		if err := copilot.StreamResponse(w, resp); err != nil {
 			log.Printf("Error writing streaming response: %v", err)
 		}
 		return
@@ -167,7 +220,7 @@ func (h *Handler) CopilotWebhook(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) handleMultiAgentRequest(w http.ResponseWriter, r *http.Request, req *models.CopilotRequest, codenames []string) {
 	log.Printf("Copilot webhook: multi-agent collaboration with agents: %v", codenames)
 
-	var responses []string
+	var answers []models.AgentAnswer
 	var validAgents []string
 	var skippedAgents []string
 
@@ -179,20 +232,25 @@ func (h *Handler) handleMultiAgentRequest(w http.ResponseWriter, r *http.Request
 			continue
 		}
 
-		resp, err := agent.Handle(r.Context(), req)
+		release, err := h.registry.Admit(r.Context(), agent)
+		if err != nil {
+			log.Printf("Agent %s over quota, skipping: %v", codename, err)
+			skippedAgents = append(skippedAgents, codename)
+			continue
+		}
+		resp, err := h.registry.Handle(r.Context(), agent, RouteMulti, req)
+		release()
 		if err != nil {
 			log.Printf("Error from agent %s: %v", codename, err)
 			skippedAgents = append(skippedAgents, codename)
 			continue
 		}
 
-		if len(resp.Choices) > 0 {
-			responses = append(responses, resp.Choices[0].Message.Content)
-			validAgents = append(validAgents, codename)
-		}
+		answers = append(answers, models.AgentAnswer{Agent: codename, Content: resp.Choices[0].Message.Content})
+		validAgents = append(validAgents, codename)
 	}
 
-	if len(responses) == 0 {
+	if len(answers) == 0 {
 		copilot.WriteError(w, "No valid agents could process the request", http.StatusInternalServerError)
 		return
 	}
@@ -206,11 +264,15 @@ func (h *Handler) handleMultiAgentRequest(w http.ResponseWriter, r *http.Request
 		combinedContent.WriteString(fmt.Sprintf("*Note: The following requested agents were unavailable: %s*\n\n", strings.Join(skippedAgents, ", ")))
 	}
 
-	for i, content := range responses {
-		if i > 0 {
-			combinedContent.WriteString("\n---\n\n")
+	if h.fuse != nil && len(answers) > 1 {
+		combinedContent.WriteString(h.fuse(answers))
+	} else {
+		for i, answer := range answers {
+			if i > 0 {
+				combinedContent.WriteString("\n---\n\n")
+			}
+			combinedContent.WriteString(answer.Content)
 		}
-		combinedContent.WriteString(content)
 	}
 
 	combinedResp := copilot.NewResponse(combinedContent.String())
@@ -228,6 +290,178 @@ func (h *Handler) handleMultiAgentRequest(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// QuotaStats handles GET /agents/quotas - returns per-tier quota metrics.
+func (h *Handler) QuotaStats(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, h.registry.QuotaStats())
+}
+
+// SetFusion sets how the answers of a multi-agent request are merged into
+// one response. Without it they are concatenated.
+func (h *Handler) SetFusion(fuse func([]models.AgentAnswer) string) {
+	h.fuse = fuse
+}
+
+// SetWorkflows sets the engine serving the workflow endpoints.
+func (h *Handler) SetWorkflows(workflows *WorkflowEngine) {
+	h.workflows = workflows
+}
+
+// ListWorkflows handles GET /workflows - returns all loaded workflows.
+func (h *Handler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
+	workflows := []*WorkflowDefinition{}
+	if h.workflows != nil {
+		workflows = h.workflows.List()
+	}
+	errdefs.WriteJSON(w, http.StatusOK, workflows)
+}
+
+// GetWorkflow handles GET /workflows/{name} - returns a workflow definition.
+func (h *Handler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	if h.workflows == nil {
+		http.Error(w, ErrWorkflowNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	def, err := h.workflows.Get(chi.URLParam(r, "name"))
+	if err != nil {
+		http.Error(w, err.Error(), errdefs.HTTPStatus(err))
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, def)
+}
+
+// RunWorkflow handles POST /workflows/{name}/run - runs a workflow with
+// the inputs in the request body and returns every step's result. A run
+// still going when the request times out is answered with 202 Accepted
+// and goes on in the background. Retries with the same Idempotency-Key
+// header get the run the first request started.
+func (h *Handler) RunWorkflow(w http.ResponseWriter, r *http.Request) {
+	if h.workflows == nil {
+		http.Error(w, ErrWorkflowNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	var req struct {
+		Inputs map[string]string `json:"inputs"`
+	}
+	// An empty body runs the workflow with its default inputs
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	run, err := h.workflows.RunWithKey(r.Context(), name, req.Inputs, r.Header.Get("Idempotency-Key"))
+	if err != nil {
+		http.Error(w, err.Error(), errdefs.HTTPStatus(err))
+		return
+	}
+	if run.Status == StepRunning {
+		w.Header().Set("Location", "/workflows/runs/"+run.ID)
+		errdefs.WriteJSON(w, http.StatusAccepted, run)
+		return
+	}
+	log.Printf("Workflow %s run %s %s in %dms", name, run.ID, run.Status, run.DurationMs)
+	errdefs.WriteJSON(w, http.StatusOK, run)
+}
+
+// GetWorkflowRun handles GET /workflows/runs/{id} - returns a run's state.
+func (h *Handler) GetWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	if h.workflows == nil {
+		http.Error(w, ErrRunNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	run, err := h.workflows.GetRun(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), errdefs.HTTPStatus(err))
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, run)
+}
+
+// ReloadWorkflows handles POST /workflows/reload - re-reads the workflow
+// directory. On a validation error the loaded workflows are kept.
+func (h *Handler) ReloadWorkflows(w http.ResponseWriter, r *http.Request) {
+	if h.workflows == nil {
+		http.Error(w, "workflows are not enabled", http.StatusNotFound)
+		return
+	}
+	loaded, err := h.workflows.Reload()
+	if err != nil {
+		log.Printf("Workflow reload failed: %v", err)
+		errdefs.WriteError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, map[string]int{"loaded": loaded})
+}
+
+// metadataCacheControl lets clients reuse agent metadata, which changes
+// only when agents are registered or deprecated, for a minute before
+// revalidating it by its ETag.
+const metadataCacheControl = "public, max-age=60, must-revalidate"
+
+// writeCachedJSON writes agent metadata with an ETag derived from its
+// content and caching headers, or just the headers and 304 Not Modified
+// when the request's If-None-Match names the ETag. It returns an error,
+// before writing anything, if v cannot be encoded.
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", metadataCacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+	return nil
+}
+
+// etagMatches reports whether an If-None-Match header names an ETag, by
+// the weak comparison RFC 9110 prescribes for it.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAdmitError reports a request the registry did not admit.
+func writeAdmitError(w http.ResponseWriter, err error) {
+	log.Printf("Request not admitted: %v", err)
+	status := errdefs.HTTPStatus(err)
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", retryAfter(err))
+	}
+	if status == http.StatusInternalServerError {
+		copilot.WriteError(w, "Error processing request", status)
+		return
+	}
+	copilot.WriteError(w, err.Error(), status)
+}
+
+// retryAfter returns the Retry-After seconds of a request not admitted:
+// when a rate limit has a token again, or one second for a tier at
+// capacity.
+func retryAfter(err error) string {
+	var limited interface{ RetryAfter() time.Duration }
+	if errors.As(err, &limited) {
+		if seconds := int(math.Ceil(limited.RetryAfter().Seconds())); seconds > 1 {
+			return strconv.Itoa(seconds)
+		}
+	}
+	return "1"
+}
+
 // extractAgentCodename extracts the first agent codename from a message.
 // It looks for @CODENAME patterns at the start of the message.
 func extractAgentCodename(message string) string {
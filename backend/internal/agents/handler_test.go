@@ -2,12 +2,15 @@ package agents
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 )
 
@@ -82,6 +85,45 @@ func TestGetAgentNotFound(t *testing.T) {
 	}
 }
 
+func TestAgentMetadataConditionalRequests(t *testing.T) {
+	_, r := setupTestHandler()
+
+	for _, path := range []string{"/agents", "/agents/APEX"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		etag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || etag == "" || w.Header().Get("Cache-Control") != metadataCacheControl {
+			t.Fatalf("expected %s with an ETag and Cache-Control, got %d %v", path, w.Code, w.Header())
+		}
+
+		for _, match := range []string{etag, "W/" + etag, `"stale", ` + etag, "*"} {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("If-None-Match", match)
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+				t.Errorf("expected 304 for %s with If-None-Match %s, got %d", path, match, w.Code)
+			}
+		}
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("expected the body of %s for a stale ETag, got %d", path, w.Code)
+		}
+	}
+
+	// The APEX and agent list ETags differ
+	list, apex := httptest.NewRecorder(), httptest.NewRecorder()
+	r.ServeHTTP(list, httptest.NewRequest("GET", "/agents", nil))
+	r.ServeHTTP(apex, httptest.NewRequest("GET", "/agents/APEX", nil))
+	if list.Header().Get("ETag") == apex.Header().Get("ETag") {
+		t.Error("expected ETags derived from content")
+	}
+}
+
 func TestInvokeAgent(t *testing.T) {
 	_, r := setupTestHandler()
 
@@ -160,6 +202,55 @@ func TestCopilotWebhook(t *testing.T) {
 	}
 }
 
+func TestCopilotWebhookRoutes(t *testing.T) {
+	handler, r := setupTestHandler()
+	var routes []string
+	handler.registry.OnInvocation(func(inv Invocation) {
+		routes = append(routes, inv.Agent+" "+inv.Route)
+	})
+
+	for _, message := range []string{"@CIPHER audit this", "help me with something", "@NOBODY help", "@APEX @CIPHER review"} {
+		body, _ := json.Marshal(models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: message}}})
+		req := httptest.NewRequest("POST", "/copilot", bytes.NewReader(body))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []string{"CIPHER mention", "APEX default", "APEX fallback", "APEX multi", "CIPHER multi"}
+	if len(routes) != len(want) {
+		t.Fatalf("expected routes %v, got %v", want, routes)
+	}
+	for i := range want {
+		if routes[i] != want[i] {
+			t.Errorf("expected route %q, got %q", want[i], routes[i])
+		}
+	}
+}
+
+func TestCopilotWebhookFusesMultiAgentAnswers(t *testing.T) {
+	handler, r := setupTestHandler()
+	var fused []models.AgentAnswer
+	handler.SetFusion(func(answers []models.AgentAnswer) string {
+		fused = answers
+		return "fused answer"
+	})
+
+	body, _ := json.Marshal(models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "@APEX @CIPHER review"}}})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/copilot", bytes.NewReader(body)))
+
+	if len(fused) != 2 || fused[0].Agent != "APEX" || fused[1].Agent != "CIPHER" || fused[0].Content == "" {
+		t.Fatalf("expected the answers of APEX and CIPHER fused, got %+v", fused)
+	}
+	var resp models.CopilotResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	content := resp.Choices[0].Message.Content
+	if !strings.HasPrefix(content, "## Multi-Agent Collaboration: APEX + CIPHER") || !strings.HasSuffix(content, "fused answer") {
+		t.Errorf("expected the fused answer under the collaboration heading, got %q", content)
+	}
+}
+
 func TestCopilotWebhookDefaultsToAPEX(t *testing.T) {
 	_, r := setupTestHandler()
 
@@ -206,3 +297,67 @@ func TestExtractAgentCodename(t *testing.T) {
 		}
 	}
 }
+
+// fakeDrafter drafts a fixed answer in two pieces.
+type fakeDrafter struct{}
+
+func (fakeDrafter) Draft(ctx context.Context, agent models.Agent, req *models.CopilotRequest, onDelta func(string) error) error {
+	for _, delta := range []string{"Quick ", "take from " + agent.Codename} {
+		if err := onDelta(delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInvokeAgentDrafted(t *testing.T) {
+	handler, r := setupTestHandler()
+	handler.SetDrafter(fakeDrafter{})
+
+	body, _ := json.Marshal(models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: "Help me with an algorithm"}},
+		Stream:   true,
+		Draft:    true,
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/agents/APEX/invoke", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var draft, refined strings.Builder
+	var stages []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		var chunk copilot.StreamChunk
+		if !strings.HasPrefix(line, "data: {") || json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk) != nil {
+			continue
+		}
+		if len(stages) == 0 || stages[len(stages)-1] != chunk.Stage {
+			stages = append(stages, chunk.Stage)
+		}
+		for _, choice := range chunk.Choices {
+			if chunk.Stage == copilot.StageDraft {
+				draft.WriteString(choice.Delta.Content)
+			} else {
+				refined.WriteString(choice.Delta.Content)
+			}
+		}
+	}
+	if len(stages) != 2 || stages[0] != copilot.StageDraft || stages[1] != copilot.StageRefined {
+		t.Fatalf("expected the draft stage and then the refined stage, got %q", stages)
+	}
+	if draft.String() != "Quick take from APEX" {
+		t.Errorf("expected the agent's draft, got %q", draft.String())
+	}
+	if refined.String() == "" || !strings.HasSuffix(w.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("expected the refined answer to end the stream, got %q", w.Body.String())
+	}
+
+	// Without streaming the draft is skipped
+	body, _ = json.Marshal(models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "Help"}}, Draft: true})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/agents/APEX/invoke", bytes.NewReader(body)))
+	if strings.Contains(w.Body.String(), copilot.StageDraft) {
+		t.Errorf("expected a plain answer, got %q", w.Body.String())
+	}
+}
@@ -39,18 +39,19 @@ func (a *ApexAgent) GetInfo() models.Agent {
 func (a *ApexAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
 	userMessage := copilot.GetLastUserMessage(req)
 
-	response := fmt.Sprintf(`As APEX, the Elite Computer Science Engineering Specialist, I'll help you with: %s
-
-My approach follows these principles:
+	return copilot.NewAgentResponse(&models.AgentResponse{
+		Summary: fmt.Sprintf("As APEX, the Elite Computer Science Engineering Specialist, I'll help you with: %s", userMessage),
+		Reasoning: `My approach follows these principles:
 1. DECOMPOSE → Break problem into atomic components
 2. CLASSIFY → Map to known patterns & paradigms
 3. THEORIZE → Generate multiple solution hypotheses
 4. ANALYZE → Evaluate time/space complexity, edge cases
 5. SYNTHESIZE → Construct optimal solution with patterns
 6. VALIDATE → Mental execution, trace through
-7. DOCUMENT → Clear explanation with trade-offs
-
-Let me analyze your request...`, userMessage)
-
-	return copilot.NewResponse(response), nil
+7. DOCUMENT → Clear explanation with trade-offs`,
+		FollowUps: []string{
+			"Analyze the time and space complexity",
+			"Walk through the edge cases",
+		},
+	}), nil
 }
@@ -54,6 +54,11 @@ func TestApexAgentHandle(t *testing.T) {
 	if !containsString(content, "APEX") {
 		t.Error("expected response to mention APEX")
 	}
+
+	answer := resp.Choices[0].Structured
+	if answer == nil || !containsString(answer.Reasoning, "DECOMPOSE") || len(answer.FollowUps) == 0 {
+		t.Errorf("expected a structured answer with APEX's methodology as reasoning, got %+v", answer)
+	}
 }
 
 func containsString(s, substr string) bool {
@@ -27,25 +27,28 @@ func (a *BaseAgent) GetInfo() models.Agent {
 	return a.info
 }
 
+// WithInfo returns a base agent with another persona.
+func (a *BaseAgent) WithInfo(info models.Agent) models.AgentHandler {
+	return NewBaseAgent(info)
+}
+
 // Handle processes a Copilot request using the base implementation.
 func (a *BaseAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
-	userMessage := copilot.GetLastUserMessage(req)
-
-	response := fmt.Sprintf(`As %s, the %s Specialist, I'll help you with: %s
-
-My philosophy: %s
-
-I'm ready to assist you with my expertise. Here are my core directives:
-%s
-
-How can I help you today?`,
-		a.info.Codename,
-		a.info.Specialty,
-		userMessage,
-		a.info.Philosophy,
-		formatDirectives(a.info.Directives))
+	return copilot.NewAgentResponse(a.answer(copilot.GetLastUserMessage(req))), nil
+}
 
-	return copilot.NewResponse(response), nil
+// answer introduces the agent's approach to a request, suggesting the
+// agent's examples as follow-ups.
+func (a *BaseAgent) answer(userMessage string) *models.AgentResponse {
+	return &models.AgentResponse{
+		Summary: fmt.Sprintf("As %s, the %s Specialist, I'll help you with: %s",
+			a.info.Codename, a.info.Specialty, userMessage),
+		Sections: []models.ResponseSection{
+			{Title: "Philosophy", Body: a.info.Philosophy},
+			{Title: "Core Directives", Body: formatDirectives(a.info.Directives)},
+		},
+		FollowUps: a.info.Examples,
+	}
 }
 
 // formatDirectives formats the directives as a numbered list.
@@ -0,0 +1,45 @@
+// Package handlers contains individual agent implementations.
+package handlers
+
+import (
+	"context"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/llm"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// draftMaxTokens bounds a draft, which only has to hold the reader until
+// the refined answer arrives.
+const draftMaxTokens = 256
+
+// draftInstruction follows the persona prompt of a draft.
+const draftInstruction = "\n\nGive a brief first answer at once; a fuller answer follows it, so leave out detail and caveats."
+
+// ModelDrafter drafts answers with a small, fast model, prompted with the
+// agent's persona.
+type ModelDrafter struct {
+	provider llm.Provider
+	model    string
+}
+
+// NewDrafter creates a drafter that streams drafts from model through
+// provider.
+func NewDrafter(provider llm.Provider, model string) *ModelDrafter {
+	return &ModelDrafter{provider: provider, model: model}
+}
+
+// Draft streams a brief answer to the conversation as agent, calling
+// onDelta with each piece of text as it arrives.
+func (d *ModelDrafter) Draft(ctx context.Context, agent models.Agent, req *models.CopilotRequest, onDelta func(string) error) error {
+	messages := make([]llm.Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, llm.Message{Role: m.Role, Content: m.Content})
+	}
+	_, err := d.provider.Stream(ctx, &llm.Request{
+		Model:     d.model,
+		System:    llm.PersonaPrompt(agent) + draftInstruction,
+		Messages:  messages,
+		MaxTokens: draftMaxTokens,
+	}, onDelta)
+	return err
+}
@@ -0,0 +1,65 @@
+// Package handlers contains individual agent implementations.
+package handlers
+
+import (
+	"context"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/llm"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// Sampling supplies the temperature and top-p each agent samples with.
+type Sampling interface {
+	Sampling(agent string) (temperature, topP float64)
+}
+
+// ModelAgent answers with a language model, prompted with the agent's
+// persona. The whole conversation is sent, so earlier turns and session
+// history reach the model.
+type ModelAgent struct {
+	info     models.Agent
+	provider llm.Provider
+	prompt   string
+	// sampling is read on every request, so tuned values apply at once;
+	// nil uses the model's defaults
+	sampling Sampling
+}
+
+// NewModelAgent creates an agent that answers through provider, sampling
+// as sampling says; sampling may be nil.
+func NewModelAgent(info models.Agent, provider llm.Provider, sampling Sampling) *ModelAgent {
+	return &ModelAgent{info: info, provider: provider, prompt: llm.PersonaPrompt(info), sampling: sampling}
+}
+
+// GetInfo returns the agent's metadata.
+func (a *ModelAgent) GetInfo() models.Agent {
+	return a.info
+}
+
+// WithInfo returns an agent answering through the same provider and
+// sampling with another persona.
+func (a *ModelAgent) WithInfo(info models.Agent) models.AgentHandler {
+	return NewModelAgent(info, a.provider, a.sampling)
+}
+
+// Handle completes the conversation with the agent's persona as the
+// system prompt.
+func (a *ModelAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	messages := make([]llm.Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, llm.Message{Role: m.Role, Content: m.Content})
+	}
+	request := &llm.Request{System: a.prompt, Messages: messages}
+	if a.sampling != nil {
+		temperature, topP := a.sampling.Sampling(a.info.Codename)
+		request.Temperature, request.TopP = &temperature, &topP
+	}
+	completion, err := a.provider.Complete(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	resp := copilot.NewResponse(completion.Content)
+	resp.Choices[0].FinishReason = completion.FinishReason
+	return resp, nil
+}
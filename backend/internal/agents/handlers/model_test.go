@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/llm"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// recordingProvider records the requests it completes.
+type recordingProvider struct {
+	*llm.Stub
+	requests []*llm.Request
+	err      error
+}
+
+func (p *recordingProvider) Complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	p.requests = append(p.requests, req)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &llm.Response{Content: "Use a B-tree.", FinishReason: llm.FinishLength}, nil
+}
+
+func (p *recordingProvider) Stream(ctx context.Context, req *llm.Request, onDelta func(string) error) (*llm.Response, error) {
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, onDelta(resp.Content)
+}
+
+// fixedSampling samples every agent alike.
+type fixedSampling struct {
+	temperature, topP float64
+}
+
+func (s fixedSampling) Sampling(string) (float64, float64) {
+	return s.temperature, s.topP
+}
+
+func TestModelAgentHandle(t *testing.T) {
+	info := models.Agent{Codename: "APEX", Specialty: "Elite Computer Science Engineering", Directives: []string{"Anticipate edge cases"}}
+	provider := &recordingProvider{Stub: llm.NewStub()}
+	agent := NewModelAgent(info, provider, nil)
+
+	resp, err := agent.Handle(context.Background(), &models.CopilotRequest{Messages: []models.Message{
+		{Role: "user", Content: "Which index?"},
+		{Role: "assistant", Content: "For what workload?"},
+		{Role: "user", Content: "Range scans"},
+	}})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Use a B-tree." || resp.Choices[0].FinishReason != llm.FinishLength {
+		t.Errorf("expected the model's answer, got %+v", resp.Choices[0])
+	}
+	req := provider.requests[0]
+	if !strings.Contains(req.System, "You are APEX") || !strings.Contains(req.System, "1. Anticipate edge cases") {
+		t.Errorf("expected the persona prompt, got %q", req.System)
+	}
+	if req.Temperature != nil || req.TopP != nil {
+		t.Errorf("expected the model's default sampling, got %v and %v", req.Temperature, req.TopP)
+	}
+	if len(req.Messages) != 3 || req.Messages[2].Content != "Range scans" {
+		t.Errorf("expected the whole conversation, got %+v", req.Messages)
+	}
+
+	provider.err = llm.ErrProvider
+	if _, err := agent.Handle(context.Background(), &models.CopilotRequest{}); !errors.Is(err, llm.ErrProvider) {
+		t.Errorf("expected the provider's error, got %v", err)
+	}
+}
+
+func TestModelAgentWithInfo(t *testing.T) {
+	provider := &recordingProvider{Stub: llm.NewStub()}
+	agent := NewModelAgent(models.Agent{Codename: "APEX"}, provider, fixedSampling{0.3, 0.9}).WithInfo(models.Agent{Codename: "APEX", Directives: []string{"Prefer stdlib"}})
+
+	if _, err := agent.Handle(context.Background(), &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "Parse JSON"}}}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if len(provider.requests) != 1 || !strings.Contains(provider.requests[0].System, "1. Prefer stdlib") {
+		t.Fatalf("expected the new persona through the same provider, got %+v", provider.requests)
+	}
+	if req := provider.requests[0]; *req.Temperature != 0.3 || *req.TopP != 0.9 {
+		t.Errorf("expected the same sampling, got %v and %v", *req.Temperature, *req.TopP)
+	}
+}
+
+func TestModelDrafterDraft(t *testing.T) {
+	provider := &recordingProvider{Stub: llm.NewStub()}
+	drafter := NewDrafter(provider, "small-model")
+
+	var draft strings.Builder
+	err := drafter.Draft(context.Background(), models.Agent{Codename: "APEX"}, &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "Which index?"}}}, func(delta string) error {
+		draft.WriteString(delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Draft failed: %v", err)
+	}
+	if draft.String() != "Use a B-tree." {
+		t.Errorf("expected the model's draft streamed, got %q", draft.String())
+	}
+	req := provider.requests[0]
+	if req.Model != "small-model" || req.MaxTokens != draftMaxTokens || !strings.Contains(req.System, "You are APEX") || !strings.Contains(req.System, "brief first answer") {
+		t.Errorf("expected a short draft from the small model, got %+v", req)
+	}
+
+	provider.err = llm.ErrProvider
+	if err := drafter.Draft(context.Background(), models.Agent{Codename: "APEX"}, &models.CopilotRequest{}, func(string) error { return nil }); !errors.Is(err, llm.ErrProvider) {
+		t.Errorf("expected the provider's error, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package handlers contains individual agent implementations.
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// trendKeywords mark requests ORACLE answers with the usage digest.
+var trendKeywords = []string{"trend", "usage", "analytics", "digest"}
+
+// OracleAgent is the Predictive Analytics & Forecasting Specialist. Asked
+// about trends or usage, it reports on the collective's own usage digest.
+type OracleAgent struct {
+	*BaseAgent
+	digest func(ctx context.Context) string
+}
+
+// NewOracleAgent creates an ORACLE agent that reports the usage digest
+// returned by digest.
+func NewOracleAgent(info models.Agent, digest func(ctx context.Context) string) *OracleAgent {
+	return &OracleAgent{BaseAgent: NewBaseAgent(info), digest: digest}
+}
+
+// WithInfo returns an ORACLE agent reporting the same digest with another
+// persona.
+func (a *OracleAgent) WithInfo(info models.Agent) models.AgentHandler {
+	return NewOracleAgent(info, a.digest)
+}
+
+// Handle processes a Copilot request, adding the usage digest to answers
+// about trends.
+func (a *OracleAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	userMessage := copilot.GetLastUserMessage(req)
+	answer := a.answer(userMessage)
+	if asksForTrends(userMessage) {
+		answer.Sections = append(answer.Sections, models.ResponseSection{Title: "Collective Usage Trends", Body: a.digest(ctx)})
+		answer.Citations = append(answer.Citations, models.Citation{Title: "Collective usage analytics"})
+	}
+	return copilot.NewAgentResponse(answer), nil
+}
+
+// asksForTrends reports whether a message asks about trends or usage.
+func asksForTrends(message string) bool {
+	message = strings.ToLower(message)
+	for _, keyword := range trendKeywords {
+		if strings.Contains(message, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func TestOracleAgentHandle(t *testing.T) {
+	info := models.Agent{ID: "40", Codename: "ORACLE", Tier: 8, Specialty: "Predictive Analytics & Forecasting Systems"}
+	agent := NewOracleAgent(info, func(ctx context.Context) string {
+		return "- 12 invocations, 100.0% succeeded\n"
+	})
+
+	if agent.GetInfo().Codename != "ORACLE" {
+		t.Errorf("expected codename 'ORACLE', got %s", agent.GetInfo().Codename)
+	}
+
+	ask := func(message string) *models.Choice {
+		resp, err := agent.Handle(context.Background(), &models.CopilotRequest{
+			Messages: []models.Message{{Role: "user", Content: message}},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return &resp.Choices[0]
+	}
+
+	report := ask("What are the usage trends this week?")
+	if !strings.Contains(report.Message.Content, "## Collective Usage Trends\n\n- 12 invocations") {
+		t.Errorf("expected the digest in a trend report, got %q", report.Message.Content)
+	}
+	if len(report.Structured.Citations) != 1 {
+		t.Errorf("expected the usage analytics cited, got %+v", report.Structured.Citations)
+	}
+	if reply := ask("Forecast next quarter's revenue"); strings.Contains(reply.Message.Content, "12 invocations") || len(reply.Structured.Citations) != 0 {
+		t.Errorf("expected no digest in an unrelated answer, got %q", reply.Message.Content)
+	}
+}
@@ -0,0 +1,203 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+var (
+	// ErrAgentNotFound is returned when no agent or alias has a codename
+	ErrAgentNotFound = errdefs.New(errdefs.ErrNotFound, "agent not found")
+	// ErrAgentRemoved is returned for agents and aliases past removal
+	ErrAgentRemoved = errdefs.New(errdefs.ErrNotFound, "agent removed")
+)
+
+// lifecycleDateLayout is the form of lifecycle dates.
+const lifecycleDateLayout = "2006-01-02"
+
+// aliasEntry is a former codename routed to a registered agent.
+type aliasEntry struct {
+	codename  string
+	lifecycle models.AgentLifecycle
+}
+
+// Resolution describes how a requested codename was resolved.
+type Resolution struct {
+	// Codename is the agent's current codename
+	Codename string
+	// Requested is the codename as requested
+	Requested string
+	// Alias is set when Requested is a former codename
+	Alias bool
+	// Lifecycle applies to the request: the alias's when resolved through
+	// one, otherwise the agent's
+	Lifecycle models.AgentLifecycle
+}
+
+// Deprecated reports whether the request should carry deprecation headers.
+func (res *Resolution) Deprecated() bool {
+	return res.Lifecycle.State == models.LifecycleDeprecated
+}
+
+// validateLifecycle checks a lifecycle's state and dates.
+func validateLifecycle(lc models.AgentLifecycle) error {
+	switch lc.State {
+	case models.LifecycleActive, models.LifecycleDeprecated, models.LifecycleRemoved:
+	default:
+		return fmt.Errorf("unknown lifecycle state %q", lc.State)
+	}
+	for _, date := range []string{lc.DeprecatedOn, lc.RemovalDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(lifecycleDateLayout, date); err != nil {
+			return fmt.Errorf("invalid lifecycle date %q: want YYYY-MM-DD", date)
+		}
+	}
+	return nil
+}
+
+// lifecycleAt returns the lifecycle in effect at now: a deprecated agent
+// whose removal date has passed counts as removed.
+func lifecycleAt(lc models.AgentLifecycle, now time.Time) models.AgentLifecycle {
+	if lc.State == "" {
+		lc.State = models.LifecycleActive
+	}
+	if lc.State == models.LifecycleDeprecated && lc.RemovalDate != "" {
+		if removal, err := time.Parse(lifecycleDateLayout, lc.RemovalDate); err == nil && !now.Before(removal) {
+			lc.State = models.LifecycleRemoved
+		}
+	}
+	return lc
+}
+
+// SetLifecycle sets the lifecycle of a registered agent.
+func (r *Registry) SetLifecycle(codename string, lc models.AgentLifecycle) error {
+	if err := validateLifecycle(lc); err != nil {
+		return fmt.Errorf("agent %s: %w", codename, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.agents[codename]; !ok {
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, codename)
+	}
+	r.lifecycle[codename] = lc
+	return nil
+}
+
+// AddAlias routes a former codename to a registered agent. The alias's
+// lifecycle is usually deprecated, with a removal date after which the
+// old codename stops working; ReplacedBy defaults to the agent.
+func (r *Registry) AddAlias(alias, codename string, lc models.AgentLifecycle) error {
+	if lc.ReplacedBy == "" {
+		lc.ReplacedBy = codename
+	}
+	if err := validateLifecycle(lc); err != nil {
+		return fmt.Errorf("alias %s: %w", alias, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.agents[codename]; !ok {
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, codename)
+	}
+	if _, ok := r.agents[alias]; ok {
+		return fmt.Errorf("alias %s is a registered codename", alias)
+	}
+	if existing, ok := r.aliases[alias]; ok && existing.codename != codename {
+		return fmt.Errorf("alias %s already routes to %s", alias, existing.codename)
+	}
+	r.aliases[alias] = aliasEntry{codename: codename, lifecycle: lc}
+	return nil
+}
+
+// Resolve retrieves an agent by current or former codename. The error
+// wraps ErrAgentNotFound or, past an agent's or alias's removal,
+// ErrAgentRemoved.
+func (r *Registry) Resolve(codename string) (models.AgentHandler, *Resolution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := time.Now()
+
+	res := &Resolution{Codename: codename, Requested: codename}
+	alias, isAlias := r.aliases[codename]
+	if isAlias {
+		res.Codename, res.Alias = alias.codename, true
+	}
+	handler, ok := r.agents[res.Codename]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrAgentNotFound, codename)
+	}
+
+	res.Lifecycle = lifecycleAt(r.lifecycle[res.Codename], now)
+	if isAlias && res.Lifecycle.State != models.LifecycleRemoved {
+		res.Lifecycle = lifecycleAt(alias.lifecycle, now)
+	}
+	if res.Lifecycle.State == models.LifecycleRemoved {
+		err := fmt.Errorf("%w: %s", ErrAgentRemoved, codename)
+		if res.Lifecycle.ReplacedBy != "" {
+			err = fmt.Errorf("%w: %s, use %s", ErrAgentRemoved, codename, res.Lifecycle.ReplacedBy)
+		}
+		return nil, res, err
+	}
+	return handler, res, nil
+}
+
+// Info returns an agent's info with its lifecycle and aliases.
+func (r *Registry) Info(handler models.AgentHandler) models.Agent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.describe(handler.GetInfo(), time.Now())
+}
+
+// describe adds an agent's lifecycle and aliases to its info. Callers hold mu.
+func (r *Registry) describe(info models.Agent, now time.Time) models.Agent {
+	if lc, ok := r.lifecycle[info.Codename]; ok {
+		lc = lifecycleAt(lc, now)
+		info.Lifecycle = &lc
+	}
+	for alias, entry := range r.aliases {
+		if entry.codename == info.Codename && lifecycleAt(entry.lifecycle, now).State != models.LifecycleRemoved {
+			info.Aliases = append(info.Aliases, alias)
+		}
+	}
+	sort.Strings(info.Aliases)
+	return info
+}
+
+// resolveStatus maps a Resolve error to an HTTP status. A removed agent is
+// not found, but answered with 410 Gone so clients stop asking for it.
+func resolveStatus(err error) int {
+	if errors.Is(err, ErrAgentRemoved) {
+		return http.StatusGone
+	}
+	return errdefs.HTTPStatus(err)
+}
+
+// writeDeprecationHeaders advertises a deprecated agent or alias: the
+// Deprecation and Sunset headers carry its dates (RFC 9745, RFC 8594) and
+// a Link points at its replacement.
+func writeDeprecationHeaders(w http.ResponseWriter, res *Resolution) {
+	if !res.Deprecated() {
+		return
+	}
+	lc := res.Lifecycle
+	deprecation := "@" + fmt.Sprint(time.Now().Unix())
+	if on, err := time.Parse(lifecycleDateLayout, lc.DeprecatedOn); err == nil {
+		deprecation = "@" + fmt.Sprint(on.Unix())
+	}
+	w.Header().Set("Deprecation", deprecation)
+	if removal, err := time.Parse(lifecycleDateLayout, lc.RemovalDate); err == nil {
+		w.Header().Set("Sunset", removal.UTC().Format(http.TimeFormat))
+	}
+	if lc.ReplacedBy != "" {
+		w.Header().Set("Link", fmt.Sprintf(`</agents/%s>; rel="successor-version"`, strings.ToUpper(lc.ReplacedBy)))
+	}
+}
@@ -0,0 +1,169 @@
+package agents
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// futureDate returns a lifecycle date days from now.
+func futureDate(days int) string {
+	return time.Now().AddDate(0, 0, days).Format(lifecycleDateLayout)
+}
+
+func TestRegistryResolveAlias(t *testing.T) {
+	registry := DefaultRegistry()
+	removal := futureDate(30)
+	lc := models.AgentLifecycle{State: models.LifecycleDeprecated, DeprecatedOn: "2026-01-01", RemovalDate: removal}
+	if err := registry.AddAlias("PINNACLE", "APEX", lc); err != nil {
+		t.Fatalf("failed to add alias: %v", err)
+	}
+
+	agent, res, err := registry.Resolve("PINNACLE")
+	if err != nil {
+		t.Fatalf("expected alias to resolve, got %v", err)
+	}
+	if agent.GetInfo().Codename != "APEX" || res.Codename != "APEX" || !res.Alias {
+		t.Errorf("expected alias routed to APEX, got %+v", res)
+	}
+	if !res.Deprecated() || res.Lifecycle.ReplacedBy != "APEX" {
+		t.Errorf("expected deprecated alias replaced by APEX, got %+v", res.Lifecycle)
+	}
+
+	// The agent itself is unaffected
+	if _, res, err := registry.Resolve("APEX"); err != nil || res.Deprecated() {
+		t.Errorf("expected APEX active, got %+v (%v)", res, err)
+	}
+
+	if err := registry.AddAlias("CIPHER", "APEX", lc); err == nil {
+		t.Error("expected error for alias colliding with a codename")
+	}
+	if err := registry.AddAlias("PINNACLE", "CIPHER", lc); err == nil {
+		t.Error("expected error for alias routed to another agent")
+	}
+	if err := registry.AddAlias("ZENITH", "NONEXISTENT", lc); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+	if err := registry.AddAlias("ZENITH", "APEX", models.AgentLifecycle{State: models.LifecycleDeprecated, RemovalDate: "soon"}); err == nil {
+		t.Error("expected error for invalid removal date")
+	}
+}
+
+func TestRegistryResolveRemoved(t *testing.T) {
+	registry := DefaultRegistry()
+	expired := models.AgentLifecycle{State: models.LifecycleDeprecated, RemovalDate: futureDate(-1)}
+	if err := registry.AddAlias("PINNACLE", "APEX", expired); err != nil {
+		t.Fatalf("failed to add alias: %v", err)
+	}
+	if err := registry.SetLifecycle("CIPHER", models.AgentLifecycle{State: models.LifecycleRemoved, ReplacedBy: "FORTRESS"}); err != nil {
+		t.Fatalf("failed to set lifecycle: %v", err)
+	}
+
+	// An alias past its removal date counts as removed
+	if _, _, err := registry.Resolve("PINNACLE"); !errors.Is(err, ErrAgentRemoved) {
+		t.Errorf("expected ErrAgentRemoved for expired alias, got %v", err)
+	}
+	if _, err := registry.Get("CIPHER"); !errors.Is(err, ErrAgentRemoved) {
+		t.Errorf("expected ErrAgentRemoved for removed agent, got %v", err)
+	}
+	if _, _, err := registry.Resolve("NONEXISTENT"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+
+	agents := registry.List()
+	if len(agents) != 39 {
+		t.Errorf("expected 39 listed agents, got %d", len(agents))
+	}
+	for _, agent := range agents {
+		if agent.Codename == "CIPHER" {
+			t.Error("expected removed agent not listed")
+		}
+		if agent.Codename == "APEX" && len(agent.Aliases) != 0 {
+			t.Errorf("expected expired alias not listed, got %v", agent.Aliases)
+		}
+	}
+}
+
+func TestRegistryListLifecycle(t *testing.T) {
+	registry := DefaultRegistry()
+	removal := futureDate(30)
+	if err := registry.SetLifecycle("APEX", models.AgentLifecycle{State: models.LifecycleDeprecated, RemovalDate: removal, ReplacedBy: "NEXUS"}); err != nil {
+		t.Fatalf("failed to set lifecycle: %v", err)
+	}
+	for _, alias := range []string{"ZENITH", "PINNACLE"} {
+		if err := registry.AddAlias(alias, "APEX", models.AgentLifecycle{State: models.LifecycleDeprecated}); err != nil {
+			t.Fatalf("failed to add alias: %v", err)
+		}
+	}
+
+	for _, agent := range registry.List() {
+		if agent.Codename != "APEX" {
+			if agent.Lifecycle != nil || agent.Aliases != nil {
+				t.Errorf("expected %s without lifecycle or aliases", agent.Codename)
+			}
+			continue
+		}
+		if agent.Lifecycle == nil || agent.Lifecycle.State != models.LifecycleDeprecated || agent.Lifecycle.RemovalDate != removal {
+			t.Errorf("expected APEX deprecated until %s, got %+v", removal, agent.Lifecycle)
+		}
+		if len(agent.Aliases) != 2 || agent.Aliases[0] != "PINNACLE" || agent.Aliases[1] != "ZENITH" {
+			t.Errorf("expected sorted aliases, got %v", agent.Aliases)
+		}
+	}
+
+	if err := registry.SetLifecycle("APEX", models.AgentLifecycle{State: "retired"}); err == nil {
+		t.Error("expected error for unknown lifecycle state")
+	}
+}
+
+func TestGetAgentDeprecationHeaders(t *testing.T) {
+	handler, r := setupTestHandler()
+	lc := models.AgentLifecycle{State: models.LifecycleDeprecated, DeprecatedOn: "2026-01-01", RemovalDate: "2099-01-01"}
+	if err := handler.registry.AddAlias("PINNACLE", "APEX", lc); err != nil {
+		t.Fatalf("failed to add alias: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/agents/PINNACLE", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("expected Deprecation @1767225600, got %s", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Thu, 01 Jan 2099 00:00:00 GMT" {
+		t.Errorf("expected Sunset on 2099-01-01, got %s", got)
+	}
+	if got := w.Header().Get("Link"); got != `</agents/APEX>; rel="successor-version"` {
+		t.Errorf("expected successor link to APEX, got %s", got)
+	}
+
+	// Current codenames carry no deprecation headers
+	req = httptest.NewRequest("GET", "/agents/APEX", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("expected no Deprecation header, got %s", w.Header().Get("Deprecation"))
+	}
+}
+
+func TestGetAgentRemoved(t *testing.T) {
+	handler, r := setupTestHandler()
+	if err := handler.registry.SetLifecycle("CIPHER", models.AgentLifecycle{State: models.LifecycleRemoved}); err != nil {
+		t.Fatalf("failed to set lifecycle: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/agents/CIPHER", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusGone {
+		t.Errorf("expected status 410, got %d", w.Code)
+	}
+}
@@ -0,0 +1,288 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrQuotaExceeded is returned when a tier has no capacity for a request.
+var ErrQuotaExceeded = errdefs.New(errdefs.ErrCapacityExceeded, "tier quota exceeded")
+
+// QuotaPolicy decides what happens to a request over its tier's quota.
+type QuotaPolicy string
+
+const (
+	// QuotaReject fails requests over quota immediately
+	QuotaReject QuotaPolicy = "reject"
+	// QuotaQueue holds requests over quota until capacity frees up or
+	// MaxWait passes
+	QuotaQueue QuotaPolicy = "queue"
+)
+
+// TierQuota limits the requests routed to one tier's agents. Zero limits
+// are unlimited.
+type TierQuota struct {
+	// MaxConcurrent bounds the requests in flight across the tier
+	MaxConcurrent int `yaml:"max_concurrent" json:"max_concurrent"`
+	// QPS bounds the sustained request rate across the tier
+	QPS float64 `yaml:"qps" json:"qps"`
+	// Burst is how many requests may start at once within QPS; defaults
+	// to one second's worth
+	Burst int `yaml:"burst" json:"burst"`
+	// Policy is reject (the default) or queue
+	Policy QuotaPolicy `yaml:"policy" json:"policy"`
+	// MaxWait bounds how long a queued request waits; zero waits until
+	// the request's context is done
+	MaxWait time.Duration `yaml:"max_wait" json:"max_wait"`
+}
+
+// DefaultTierQuotas returns the default quotas. Only Tier 4 (meta agents,
+// which fan out to other agents) is limited, to protect cost.
+func DefaultTierQuotas() map[int]TierQuota {
+	return map[int]TierQuota{
+		4: {MaxConcurrent: 4, QPS: 2, Burst: 4, Policy: QuotaQueue, MaxWait: 5 * time.Second},
+	}
+}
+
+// Validate checks a quota's limits and policy.
+func (q TierQuota) Validate() error {
+	if q.MaxConcurrent < 0 || q.QPS < 0 || q.Burst < 0 || q.MaxWait < 0 {
+		return errors.New("quota limits must not be negative")
+	}
+	if q.Policy != "" && q.Policy != QuotaReject && q.Policy != QuotaQueue {
+		return fmt.Errorf("unknown quota policy %q", q.Policy)
+	}
+	return nil
+}
+
+// TierQuotaStats reports a tier's quota activity.
+type TierQuotaStats struct {
+	Tier  int       `json:"tier"`
+	Quota TierQuota `json:"quota"`
+	// InFlight and Queued are current counts
+	InFlight int   `json:"in_flight"`
+	Queued   int   `json:"queued"`
+	Admitted int64 `json:"admitted"`
+	// Rejected counts requests refused under the reject policy
+	Rejected int64 `json:"rejected"`
+	// TimedOut counts queued requests that gave up waiting
+	TimedOut int64 `json:"timed_out"`
+	// AvgWaitMs is the mean queueing delay of admitted requests
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+}
+
+// tierLimiter enforces one tier's quota.
+type tierLimiter struct {
+	quota TierQuota
+	// slots holds a token per request in flight; nil is unlimited
+	slots chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	stats  TierQuotaStats
+	waited time.Duration
+}
+
+func newTierLimiter(tier int, quota TierQuota) *tierLimiter {
+	if quota.Policy == "" {
+		quota.Policy = QuotaReject
+	}
+	if quota.QPS > 0 && quota.Burst == 0 {
+		quota.Burst = int(math.Ceil(quota.QPS))
+	}
+	l := &tierLimiter{
+		quota:  quota,
+		tokens: float64(quota.Burst),
+		last:   time.Now(),
+		stats:  TierQuotaStats{Tier: tier, Quota: quota},
+	}
+	if quota.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, quota.MaxConcurrent)
+	}
+	return l
+}
+
+// reserve takes a rate token, returning how long until it may be used.
+// Tokens may go negative so queued requests are served in order.
+func (l *tierLimiter) reserve(now time.Time) time.Duration {
+	if l.quota.QPS <= 0 {
+		return 0
+	}
+	l.tokens = math.Min(float64(l.quota.Burst), l.tokens+now.Sub(l.last).Seconds()*l.quota.QPS)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.quota.QPS * float64(time.Second))
+}
+
+// unreserve returns a token taken by reserve.
+func (l *tierLimiter) unreserve() {
+	if l.quota.QPS > 0 {
+		l.tokens = math.Min(float64(l.quota.Burst), l.tokens+1)
+	}
+}
+
+// acquire admits a request, returning a function that ends it.
+func (l *tierLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	if l.quota.Policy == QuotaReject {
+		return l.tryAcquire(start)
+	}
+
+	if l.quota.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.quota.MaxWait)
+		defer cancel()
+	}
+
+	l.mu.Lock()
+	delay := l.reserve(start)
+	if deadline, ok := ctx.Deadline(); ok && start.Add(delay).After(deadline) {
+		l.unreserve()
+		l.stats.TimedOut++
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: tier %d rate limit, queue wait exceeds %v", ErrQuotaExceeded, l.stats.Tier, time.Until(deadline).Round(time.Millisecond))
+	}
+	l.stats.Queued++
+	l.mu.Unlock()
+
+	err := l.wait(ctx, delay)
+	l.mu.Lock()
+	l.stats.Queued--
+	if err != nil {
+		l.stats.TimedOut++
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: tier %d queue wait: %v", ErrQuotaExceeded, l.stats.Tier, err)
+	}
+	l.admit(time.Since(start))
+	l.mu.Unlock()
+	return l.release, nil
+}
+
+// wait sleeps for delay, then waits for a concurrency slot.
+func (l *tierLimiter) wait(ctx context.Context, delay time.Duration) error {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if l.slots == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryAcquire admits a request only if the tier has capacity now.
+func (l *tierLimiter) tryAcquire(now time.Time) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.reserve(now) > 0 {
+		l.unreserve()
+		l.stats.Rejected++
+		return nil, fmt.Errorf("%w: tier %d is limited to %g requests per second", ErrQuotaExceeded, l.stats.Tier, l.quota.QPS)
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			l.unreserve()
+			l.stats.Rejected++
+			return nil, fmt.Errorf("%w: tier %d is limited to %d concurrent requests", ErrQuotaExceeded, l.stats.Tier, l.quota.MaxConcurrent)
+		}
+	}
+	l.admit(0)
+	return l.release, nil
+}
+
+// admit records an admitted request. Callers hold mu.
+func (l *tierLimiter) admit(waited time.Duration) {
+	l.stats.Admitted++
+	l.stats.InFlight++
+	l.waited += waited
+}
+
+// release ends an admitted request.
+func (l *tierLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+	l.mu.Lock()
+	l.stats.InFlight--
+	l.mu.Unlock()
+}
+
+// snapshot returns a copy of the tier's statistics.
+func (l *tierLimiter) snapshot() TierQuotaStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := l.stats
+	if stats.Admitted > 0 {
+		stats.AvgWaitMs = float64(l.waited.Milliseconds()) / float64(stats.Admitted)
+	}
+	return stats
+}
+
+// ============================================================================
+// Quota Manager
+// ============================================================================
+
+// QuotaManager enforces per-tier quotas. Tiers without a quota are
+// unlimited.
+type QuotaManager struct {
+	tiers map[int]*tierLimiter
+}
+
+// NewQuotaManager creates a manager enforcing the given quotas by tier.
+func NewQuotaManager(quotas map[int]TierQuota) (*QuotaManager, error) {
+	m := &QuotaManager{tiers: make(map[int]*tierLimiter, len(quotas))}
+	for tier, quota := range quotas {
+		if err := quota.Validate(); err != nil {
+			return nil, fmt.Errorf("tier %d: %w", tier, err)
+		}
+		m.tiers[tier] = newTierLimiter(tier, quota)
+	}
+	return m, nil
+}
+
+// Acquire admits a request to an agent of the given tier, queueing or
+// rejecting it per the tier's policy. The returned function must be called
+// when the request ends. The error wraps ErrQuotaExceeded when the tier
+// has no capacity.
+func (m *QuotaManager) Acquire(ctx context.Context, tier int) (func(), error) {
+	limiter, ok := m.tiers[tier]
+	if !ok {
+		return func() {}, nil
+	}
+	return limiter.acquire(ctx)
+}
+
+// Stats returns the quota statistics of every limited tier, by tier.
+func (m *QuotaManager) Stats() []TierQuotaStats {
+	stats := make([]TierQuotaStats, 0, len(m.tiers))
+	for _, limiter := range m.tiers {
+		stats = append(stats, limiter.snapshot())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tier < stats[j].Tier })
+	return stats
+}
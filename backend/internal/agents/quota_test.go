@@ -0,0 +1,243 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents/handlers"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func TestQuotaManagerRejectsOverConcurrency(t *testing.T) {
+	quotas, err := NewQuotaManager(map[int]TierQuota{4: {MaxConcurrent: 2}})
+	if err != nil {
+		t.Fatalf("failed to create quota manager: %v", err)
+	}
+
+	first, err := quotas.Acquire(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("expected first request admitted, got %v", err)
+	}
+	second, _ := quotas.Acquire(context.Background(), 4)
+	if _, err := quotas.Acquire(context.Background(), 4); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Other tiers are unlimited
+	if _, err := quotas.Acquire(context.Background(), 1); err != nil {
+		t.Errorf("expected unlimited tier 1, got %v", err)
+	}
+
+	first()
+	if release, err := quotas.Acquire(context.Background(), 4); err != nil {
+		t.Errorf("expected request admitted after release, got %v", err)
+	} else {
+		release()
+	}
+	second()
+
+	stats := quotas.Stats()
+	if len(stats) != 1 || stats[0].Admitted != 3 || stats[0].Rejected != 1 || stats[0].InFlight != 0 {
+		t.Errorf("expected 3 admitted and 1 rejected, got %+v", stats)
+	}
+}
+
+func TestQuotaManagerRejectsOverRate(t *testing.T) {
+	quotas, _ := NewQuotaManager(map[int]TierQuota{4: {QPS: 10, Burst: 1}})
+
+	release, err := quotas.Acquire(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("expected burst request admitted, got %v", err)
+	}
+	release()
+	if _, err := quotas.Acquire(context.Background(), 4); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded within the same 100ms, got %v", err)
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	if _, err := quotas.Acquire(context.Background(), 4); err != nil {
+		t.Errorf("expected request admitted once a token refilled, got %v", err)
+	}
+}
+
+func TestQuotaManagerQueues(t *testing.T) {
+	quotas, _ := NewQuotaManager(map[int]TierQuota{4: {MaxConcurrent: 1, Policy: QuotaQueue, MaxWait: time.Second}})
+
+	release, _ := quotas.Acquire(context.Background(), 4)
+	admitted := make(chan time.Time)
+	go func() {
+		next, err := quotas.Acquire(context.Background(), 4)
+		if err != nil {
+			t.Errorf("expected queued request admitted, got %v", err)
+			close(admitted)
+			return
+		}
+		admitted <- time.Now()
+		next()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if stats := quotas.Stats(); stats[0].Queued != 1 {
+		t.Errorf("expected 1 queued request, got %+v", stats[0])
+	}
+	released := time.Now()
+	release()
+	if at := <-admitted; at.Before(released) {
+		t.Error("expected queued request admitted only after release")
+	}
+}
+
+func TestQuotaManagerQueueTimeout(t *testing.T) {
+	quotas, _ := NewQuotaManager(map[int]TierQuota{
+		3: {MaxConcurrent: 1, Policy: QuotaQueue, MaxWait: 30 * time.Millisecond},
+		4: {QPS: 1, Burst: 1, Policy: QuotaQueue, MaxWait: 100 * time.Millisecond},
+	})
+
+	quotas.Acquire(context.Background(), 3)
+	if _, err := quotas.Acquire(context.Background(), 3); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded after MaxWait, got %v", err)
+	}
+
+	// A rate wait longer than MaxWait fails without waiting
+	quotas.Acquire(context.Background(), 4)
+	start := time.Now()
+	if _, err := quotas.Acquire(context.Background(), 4); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected immediate failure, took %v", elapsed)
+	}
+
+	for _, s := range quotas.Stats() {
+		if s.TimedOut != 1 {
+			t.Errorf("expected tier %d to time out once, got %+v", s.Tier, s)
+		}
+	}
+}
+
+func TestQuotaManagerConcurrentLimit(t *testing.T) {
+	quotas, _ := NewQuotaManager(map[int]TierQuota{4: {MaxConcurrent: 3, Policy: QuotaQueue}})
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := quotas.Acquire(context.Background(), 4)
+			if err != nil {
+				t.Errorf("expected request admitted, got %v", err)
+				return
+			}
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+			time.Sleep(2 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", peak)
+	}
+}
+
+func TestNewQuotaManagerValidates(t *testing.T) {
+	if _, err := NewQuotaManager(map[int]TierQuota{4: {Policy: "drop"}}); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+	if _, err := NewQuotaManager(map[int]TierQuota{4: {QPS: -1}}); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
+
+func TestInvokeAgentOverQuota(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(handlers.NewBaseAgent(models.Agent{ID: "24", Codename: "OMNISCIENT", Tier: 4}))
+	quotas, _ := NewQuotaManager(map[int]TierQuota{4: {QPS: 1, Burst: 1}})
+	registry.SetQuotas(quotas)
+	handler := NewHandler(registry)
+
+	r := chi.NewRouter()
+	r.Get("/agents/quotas", handler.QuotaStats)
+	r.Post("/agents/{codename}/invoke", handler.InvokeAgent)
+
+	body, _ := json.Marshal(models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "coordinate a review"}}})
+	codes := make([]int, 2)
+	for i := range codes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/agents/OMNISCIENT/invoke", bytes.NewReader(body)))
+		codes[i] = w.Code
+		if i == 1 && w.Header().Get("Retry-After") == "" {
+			t.Error("expected Retry-After header on rejection")
+		}
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected 200 then 429, got %v", codes)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/agents/quotas", nil))
+	var stats []TierQuotaStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode quota stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Admitted != 1 || stats[0].Rejected != 1 {
+		t.Errorf("expected 1 admitted and 1 rejected, got %+v", stats)
+	}
+}
+
+// limitedAgents is a rate limiter with no capacity for the agents it
+// lists.
+type limitedAgents map[string]time.Duration
+
+// rateLimited is a request over a rate limit.
+type rateLimited time.Duration
+
+func (e rateLimited) Error() string             { return "rate limit exceeded" }
+func (e rateLimited) Unwrap() error             { return errdefs.ErrCapacityExceeded }
+func (e rateLimited) RetryAfter() time.Duration { return time.Duration(e) }
+
+func (l limitedAgents) AllowAgent(ctx context.Context, codename string) error {
+	if wait, ok := l[codename]; ok {
+		return rateLimited(wait)
+	}
+	return nil
+}
+
+func TestInvokeAgentRateLimited(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(handlers.NewBaseAgent(models.Agent{ID: "24", Codename: "OMNISCIENT", Tier: 4}))
+	registry.Register(handlers.NewBaseAgent(models.Agent{ID: "1", Codename: "APEX", Tier: 1}))
+	registry.SetRateLimiter(limitedAgents{"OMNISCIENT": 2500 * time.Millisecond})
+	r := chi.NewRouter()
+	r.Post("/agents/{codename}/invoke", NewHandler(registry).InvokeAgent)
+
+	body, _ := json.Marshal(models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "coordinate a review"}}})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/agents/OMNISCIENT/invoke", bytes.NewReader(body)))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3" {
+		t.Errorf("expected 429 with Retry-After 3, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/agents/APEX/invoke", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected APEX admitted, got %d", w.Code)
+	}
+}
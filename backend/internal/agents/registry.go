@@ -2,11 +2,20 @@
 package agents
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents/handlers"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/intent"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 	"gopkg.in/yaml.v3"
 )
@@ -25,6 +34,8 @@ type TierConfig struct {
 	ID          int    `yaml:"id"`
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	// Quota limits requests to the tier's agents; nil is unlimited
+	Quota *TierQuota `yaml:"quota,omitempty"`
 }
 
 // AgentConfig represents an agent definition in the manifest.
@@ -39,18 +50,111 @@ type AgentConfig struct {
 	Directives    []string `yaml:"directives"`
 	Examples      []string `yaml:"examples"`
 	Collaborators []string `yaml:"collaborators"`
+	// Lifecycle marks the agent deprecated or removed; nil is active
+	Lifecycle *models.AgentLifecycle `yaml:"lifecycle,omitempty"`
+	// Aliases are former codenames still routed to the agent
+	Aliases []AliasConfig `yaml:"aliases,omitempty"`
+}
+
+// AliasConfig represents a former codename in the manifest. Requests
+// through it are answered with deprecation headers until its removal date.
+type AliasConfig struct {
+	Name         string `yaml:"name"`
+	DeprecatedOn string `yaml:"deprecated_on"`
+	RemovalDate  string `yaml:"removal_date"`
 }
 
 // Registry maintains a registry of all available agents.
 type Registry struct {
 	agents map[string]models.AgentHandler
 	mu     sync.RWMutex
+
+	// lifecycle holds the agents that are not plainly active
+	lifecycle map[string]models.AgentLifecycle
+	// aliases routes former codenames to current ones
+	aliases map[string]aliasEntry
+
+	// quotas limits requests per tier; nil is unlimited
+	quotas *QuotaManager
+
+	// limiter limits requests per agent; nil is unlimited
+	limiter RateLimiter
+
+	// intents labels queries before they are handled; nil skips
+	// classification
+	intents *intent.Classifier
+	// templates rewrites queries by intent; nil leaves them as sent. It
+	// is replaced when templates are reloaded
+	templates atomic.Pointer[intent.Templates]
+
+	// preferences supplies the preferences of the user a request is made
+	// for; nil leaves prompts as sent
+	preferences Preferences
+
+	// sessions keeps the conversations requests continue; nil handles
+	// every request on its own
+	sessions Sessions
+
+	// grounding verifies answers against memory; nil leaves them as
+	// answered
+	grounding *GroundingConfig
+
+	// followUps suggests what to ask next; nil leaves the agents' own
+	// suggestions
+	followUps FollowUps
+
+	// warm pools the context agents are invoked with; nil adds none
+	warm *WarmPool
+
+	// onInvocation is called with each finished invocation
+	onInvocation func(Invocation)
+}
+
+// Invocation routes: how a request came to be answered by its agent.
+const (
+	// RouteDirect is a request to /agents/{codename}/invoke
+	RouteDirect = "direct"
+	// RouteMention is a Copilot message mentioning one agent
+	RouteMention = "mention"
+	// RouteDefault is a Copilot message mentioning no agent, answered by APEX
+	RouteDefault = "default"
+	// RouteFallback is a Copilot message mentioning an unknown agent,
+	// answered by APEX
+	RouteFallback = "fallback"
+	// RouteMulti is a Copilot message mentioning several agents
+	RouteMulti = "multi"
+	// RouteActions is a GitHub Actions job
+	RouteActions = "actions"
+	// RouteWorkflow is a workflow step
+	RouteWorkflow = "workflow"
+	// RouteChat is a Slack or Teams command
+	RouteChat = "chat"
+	// RouteReview is a chunk of a diff sent to /review
+	RouteReview = "review"
+	// RouteTestGaps is a package's source sent to /test-gaps
+	RouteTestGaps = "test-gaps"
+	// RouteGRPC is an InvokeAgent call to the gRPC API
+	RouteGRPC = "grpc"
+)
+
+// Invocation describes one finished agent invocation.
+type Invocation struct {
+	Agent string
+	Route string
+	// Intent is the query's intent; empty without a classifier
+	Intent   intent.Intent
+	Success  bool
+	Duration time.Duration
+	// Time is when the invocation started
+	Time time.Time
 }
 
 // NewRegistry creates a new agent registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		agents: make(map[string]models.AgentHandler),
+		agents:    make(map[string]models.AgentHandler),
+		lifecycle: make(map[string]models.AgentLifecycle),
+		aliases:   make(map[string]aliasEntry),
 	}
 }
 
@@ -62,28 +166,443 @@ func (r *Registry) Register(handler models.AgentHandler) {
 	r.agents[info.Codename] = handler
 }
 
-// Get retrieves an agent by codename.
-func (r *Registry) Get(codename string) (models.AgentHandler, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	handler, ok := r.agents[codename]
-	if !ok {
-		return nil, fmt.Errorf("agent not found: %s", codename)
+// PersonaHandler is a handler whose persona can be replaced, as when
+// persona prompts are reloaded.
+type PersonaHandler interface {
+	models.AgentHandler
+	// WithInfo returns a handler that answers as this one does, as the
+	// persona described by info
+	WithInfo(info models.Agent) models.AgentHandler
+}
+
+// UpdatePersonas replaces the personas of registered agents, matched by
+// codename. Personas of agents that are not registered, or whose handlers
+// cannot change persona, are skipped. Requests already being handled keep
+// the persona they started with.
+func (r *Registry) UpdatePersonas(personas []models.Agent) (updated, skipped []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, info := range personas {
+		handler, ok := r.agents[info.Codename].(PersonaHandler)
+		if !ok {
+			skipped = append(skipped, info.Codename)
+			continue
+		}
+		r.agents[info.Codename] = handler.WithInfo(info)
+		updated = append(updated, info.Codename)
 	}
-	return handler, nil
+	return updated, skipped
+}
+
+// Get retrieves an agent by current or former codename. Removed agents
+// and aliases are not found.
+func (r *Registry) Get(codename string) (models.AgentHandler, error) {
+	handler, _, err := r.Resolve(codename)
+	return handler, err
 }
 
-// List returns all registered agents.
+// List returns all registered agents that are not removed, with their
+// lifecycle and aliases, ordered by ID so the listing is stable.
 func (r *Registry) List() []models.Agent {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	now := time.Now()
 	agents := make([]models.Agent, 0, len(r.agents))
 	for _, handler := range r.agents {
-		agents = append(agents, handler.GetInfo())
+		info := r.describe(handler.GetInfo(), now)
+		if info.Lifecycle != nil && info.Lifecycle.State == models.LifecycleRemoved {
+			continue
+		}
+		agents = append(agents, info)
 	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
 	return agents
 }
 
+// SetQuotas sets the per-tier quotas enforced by Admit. Set before the
+// registry is shared between goroutines.
+func (r *Registry) SetQuotas(quotas *QuotaManager) {
+	r.quotas = quotas
+}
+
+// RateLimiter limits the requests routed to each agent.
+type RateLimiter interface {
+	// AllowAgent returns an error when an agent has been sent all the
+	// requests it may be for now
+	AllowAgent(ctx context.Context, codename string) error
+}
+
+// SetRateLimiter sets the per-agent rate limits enforced by Admit. Set
+// before the registry is shared between goroutines.
+func (r *Registry) SetRateLimiter(limiter RateLimiter) {
+	r.limiter = limiter
+}
+
+// Admit admits a request to an agent under its rate limit and its tier's
+// quota. The returned function must be called when the request ends. The
+// error wraps ErrQuotaExceeded when the tier has no capacity, and the
+// limiter's error when the agent is over its rate limit.
+func (r *Registry) Admit(ctx context.Context, handler models.AgentHandler) (func(), error) {
+	info := handler.GetInfo()
+	if r.limiter != nil {
+		if err := r.limiter.AllowAgent(ctx, info.Codename); err != nil {
+			return nil, fmt.Errorf("agent %s: %w", info.Codename, err)
+		}
+	}
+	if r.quotas == nil {
+		return func() {}, nil
+	}
+	release, err := r.quotas.Acquire(ctx, info.Tier)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", info.Codename, err)
+	}
+	return release, nil
+}
+
+// SetIntents sets the classifier that labels queries before they are
+// handled, and the templates that rewrite them by intent; templates may be
+// nil. Set before the registry is shared between goroutines.
+func (r *Registry) SetIntents(classifier *intent.Classifier, templates *intent.Templates) {
+	r.intents = classifier
+	r.templates.Store(templates)
+}
+
+// SetTemplates replaces the templates that rewrite queries by intent; nil
+// sends queries unchanged. Unlike SetIntents it is safe to call while the
+// registry is in use.
+func (r *Registry) SetTemplates(templates *intent.Templates) {
+	r.templates.Store(templates)
+}
+
+// Preferences supplies the preferences of the user a request is made for,
+// as an instruction to agents, or "" when none are known.
+type Preferences interface {
+	Prompt(ctx context.Context) string
+}
+
+// SetPreferences sets where user preferences are added to prompts from.
+// Set before the registry is shared between goroutines.
+func (r *Registry) SetPreferences(preferences Preferences) {
+	r.preferences = preferences
+}
+
+// Sessions keeps the conversations requests continue.
+type Sessions interface {
+	// History returns the earlier messages of the session a request
+	// continues, or nil outside a session
+	History(ctx context.Context) []models.Message
+	// Record adds an exchange to the session a request continues
+	Record(ctx context.Context, agent, query, answer string)
+}
+
+// SetSessions sets where the conversations requests continue are kept.
+// Set before the registry is shared between goroutines.
+func (r *Registry) SetSessions(sessions Sessions) {
+	r.sessions = sessions
+}
+
+// maxFollowUps bounds the follow-up suggestions on an answer.
+const maxFollowUps = 3
+
+// FollowUps suggests prompts a user might send after an exchange.
+type FollowUps interface {
+	// Suggest returns up to n follow-up prompts for a query and its answer
+	Suggest(ctx context.Context, query, answer string, n int) []string
+}
+
+// SetFollowUps sets where follow-up suggestions for structured answers
+// come from. Set before the registry is shared between goroutines.
+func (r *Registry) SetFollowUps(followUps FollowUps) {
+	r.followUps = followUps
+}
+
+// Grounder verifies an answer against memory. It returns the answer with
+// unsupported claims marked and the claims memory does not support.
+type Grounder interface {
+	Ground(ctx context.Context, answer string) (string, []string)
+}
+
+// GroundingConfig configures grounded answers.
+type GroundingConfig struct {
+	Grounder Grounder
+	// Enabled reports whether a request's answer is grounded; nil grounds
+	// every answer
+	Enabled func(ctx context.Context) bool
+	// Revise has the agent revise an answer with unsupported claims once
+	// before it is marked
+	Revise bool
+}
+
+// SetGrounding sets the verification of answers against memory. Set
+// before the registry is shared between goroutines.
+func (r *Registry) SetGrounding(cfg GroundingConfig) {
+	r.grounding = &cfg
+}
+
+// OnInvocation sets a callback for finished invocations, called on the
+// invoking goroutine, so it must not block.
+func (r *Registry) OnInvocation(fn func(Invocation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onInvocation = fn
+}
+
+// Handle has an admitted agent handle a request and reports the
+// invocation to the OnInvocation callback. The query is first labeled with
+// its intent and rewritten by the intent's template, if any, and the
+// agent's pooled context, the user's preferences, the repository code
+// Copilot sent and the earlier turns of the request's session are added
+// to it; the answer is then grounded if
+// grounding is enabled for the request, given citations of the repository
+// lines it relies on, recorded in the session, and given follow-up
+// suggestions.
+func (r *Registry) Handle(ctx context.Context, agent models.AgentHandler, route string, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	start := time.Now()
+	query := copilot.GetLastUserMessage(req)
+	var queryIntent intent.Intent
+	if r.intents != nil {
+		queryIntent, req = r.classify(ctx, agent.GetInfo().Codename, req)
+	}
+	if r.warm != nil {
+		req = withContext(req, r.warm.Context(ctx, agent.GetInfo())...)
+	}
+	if r.preferences != nil {
+		req = r.personalize(ctx, req)
+	}
+	repo := AssembleRepoContext(req)
+	if repo != nil {
+		req = withContext(req, models.Message{Role: "system", Content: repo.Prompt()})
+	}
+	if r.sessions != nil {
+		req = withContext(req, r.sessions.History(ctx)...)
+	}
+	resp, err := agent.Handle(ctx, req)
+	if err == nil && len(resp.Choices) == 0 {
+		err = errors.New("agent returned no response")
+	}
+	if err == nil {
+		resp = r.ground(ctx, agent, req, resp)
+		resp = cite(repo, resp)
+		if r.sessions != nil {
+			r.sessions.Record(ctx, agent.GetInfo().Codename, query, resp.Choices[0].Message.Content)
+		}
+		resp = r.suggestFollowUps(ctx, query, resp)
+	}
+
+	r.mu.RLock()
+	onInvocation := r.onInvocation
+	r.mu.RUnlock()
+	if onInvocation != nil {
+		onInvocation(Invocation{
+			Agent:    agent.GetInfo().Codename,
+			Route:    route,
+			Intent:   queryIntent,
+			Success:  err == nil,
+			Duration: time.Since(start),
+			Time:     start,
+		})
+	}
+	return resp, err
+}
+
+// classify labels the last user message of a request with its intent and
+// returns the request with that message rewritten by the intent's
+// template. The caller's request is not modified.
+func (r *Registry) classify(ctx context.Context, codename string, req *models.CopilotRequest) (intent.Intent, *models.CopilotRequest) {
+	last := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return intent.General, req
+	}
+
+	query := req.Messages[last].Content
+	result := r.intents.Classify(ctx, query)
+	templates := r.templates.Load()
+	if templates == nil {
+		return result.Intent, req
+	}
+	rewritten, ok, err := templates.Apply(intent.TemplateData{Query: query, Agent: codename, Intent: result.Intent})
+	if err != nil {
+		log.Printf("Sending query unchanged: %v", err)
+	}
+	if !ok {
+		return result.Intent, req
+	}
+
+	templated := *req
+	templated.Messages = append([]models.Message(nil), req.Messages...)
+	templated.Messages[last].Content = rewritten
+	return result.Intent, &templated
+}
+
+// personalize returns the request with the user's preferences added as a
+// system message. The caller's request is not modified.
+func (r *Registry) personalize(ctx context.Context, req *models.CopilotRequest) *models.CopilotRequest {
+	prompt := r.preferences.Prompt(ctx)
+	if prompt == "" {
+		return req
+	}
+	return withContext(req, models.Message{Role: "system", Content: prompt})
+}
+
+// withContext returns the request with messages inserted after any leading
+// system messages, or the request itself if there are none to insert. The
+// caller's request is not modified.
+func withContext(req *models.CopilotRequest, messages ...models.Message) *models.CopilotRequest {
+	if len(messages) == 0 {
+		return req
+	}
+	at := 0
+	for at < len(req.Messages) && req.Messages[at].Role == "system" {
+		at++
+	}
+	extended := *req
+	extended.Messages = make([]models.Message, 0, len(req.Messages)+len(messages))
+	extended.Messages = append(extended.Messages, req.Messages[:at]...)
+	extended.Messages = append(extended.Messages, messages...)
+	extended.Messages = append(extended.Messages, req.Messages[at:]...)
+	return &extended
+}
+
+// ground verifies an answer against memory and returns the response with
+// unsupported claims marked. With Revise set, an answer with unsupported
+// claims is sent back to the agent once and the revision is marked
+// instead. A marked answer loses its structured form. The agent's response
+// is not modified.
+func (r *Registry) ground(ctx context.Context, agent models.AgentHandler, req *models.CopilotRequest, resp *models.CopilotResponse) *models.CopilotResponse {
+	g := r.grounding
+	if g == nil || (g.Enabled != nil && !g.Enabled(ctx)) {
+		return resp
+	}
+
+	answer := resp.Choices[0].Message.Content
+	annotated, ungrounded := g.Grounder.Ground(ctx, answer)
+	if len(ungrounded) > 0 && g.Revise {
+		revision := *req
+		revision.Messages = append(append([]models.Message(nil), req.Messages...),
+			models.Message{Role: "assistant", Content: answer},
+			models.Message{Role: "user", Content: revisionPrompt(ungrounded)})
+		revised, err := agent.Handle(ctx, &revision)
+		switch {
+		case err != nil:
+			log.Printf("Keeping unrevised answer of %s: %v", agent.GetInfo().Codename, err)
+		case len(revised.Choices) > 0:
+			annotated, _ = g.Grounder.Ground(ctx, revised.Choices[0].Message.Content)
+		}
+	}
+
+	if annotated == answer {
+		return resp
+	}
+	// The marked answer no longer matches the agent's structured answer,
+	// so clients are sent the text alone
+	grounded := *resp
+	grounded.Choices = append([]models.Choice(nil), resp.Choices...)
+	grounded.Choices[0].Message.Content = annotated
+	grounded.Choices[0].Structured = nil
+	return &grounded
+}
+
+// cite returns the response with the repository lines its answer cites,
+// as references for Copilot to link to and, for a structured answer, as
+// citations, re-rendered. Without repository context the response is
+// returned as it is. The agent's response is not modified.
+func cite(repo *RepoContext, resp *models.CopilotResponse) *models.CopilotResponse {
+	if repo == nil {
+		return resp
+	}
+	citations := repo.Citations(resp.Choices[0].Message.Content)
+	cited := *resp
+	cited.CopilotReferences = append(append([]models.Reference(nil), resp.CopilotReferences...), repo.citationReferences(citations)...)
+	if answer := resp.Choices[0].Structured; answer != nil {
+		withCitations := *answer
+		withCitations.Citations = append(append([]models.Citation(nil), answer.Citations...), citations...)
+		cited.Choices = append([]models.Choice(nil), resp.Choices...)
+		cited.Choices[0].Message.Content = copilot.Render(&withCitations)
+		cited.Choices[0].Structured = &withCitations
+	}
+	return &cited
+}
+
+// suggestFollowUps returns a structured answer with follow-up suggestions
+// for the exchange ahead of the agent's own, up to maxFollowUps, and
+// re-rendered. Text answers are returned as they are, as is the agent's
+// response.
+func (r *Registry) suggestFollowUps(ctx context.Context, query string, resp *models.CopilotResponse) *models.CopilotResponse {
+	answer := resp.Choices[0].Structured
+	if r.followUps == nil || answer == nil {
+		return resp
+	}
+	suggestions := r.followUps.Suggest(ctx, query, resp.Choices[0].Message.Content, maxFollowUps)
+	seen := make(map[string]bool, maxFollowUps)
+	for _, suggestion := range suggestions {
+		seen[suggestion] = true
+	}
+	for _, own := range answer.FollowUps {
+		if len(suggestions) == maxFollowUps {
+			break
+		}
+		if !seen[own] {
+			seen[own] = true
+			suggestions = append(suggestions, own)
+		}
+	}
+
+	suggested := *answer
+	suggested.FollowUps = suggestions
+	rendered := *resp
+	rendered.Choices = append([]models.Choice(nil), resp.Choices...)
+	rendered.Choices[0].Message.Content = copilot.Render(&suggested)
+	rendered.Choices[0].Structured = &suggested
+	return &rendered
+}
+
+// revisionPrompt asks an agent to correct its unsupported claims.
+func revisionPrompt(claims []string) string {
+	var b strings.Builder
+	b.WriteString("These claims in your answer are not supported by the knowledge graph. Revise the answer to correct or remove them:\n")
+	for _, claim := range claims {
+		fmt.Fprintf(&b, "- %s\n", claim)
+	}
+	return b.String()
+}
+
+// Invoke sends a prompt from a chat command to an agent under its tier's
+// quota. It returns the agent's current codename, which differs from the
+// one given for an alias, and the agent's reply.
+func (r *Registry) Invoke(ctx context.Context, codename, prompt string) (string, string, error) {
+	agent, res, err := r.Resolve(codename)
+	if err != nil {
+		return "", "", err
+	}
+	release, err := r.Admit(ctx, agent)
+	if err != nil {
+		return "", "", err
+	}
+	defer release()
+
+	resp, err := r.Handle(ctx, agent, RouteChat, &models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return res.Codename, resp.Choices[0].Message.Content, nil
+}
+
+// QuotaStats returns the statistics of every limited tier.
+func (r *Registry) QuotaStats() []TierQuotaStats {
+	if r.quotas == nil {
+		return []TierQuotaStats{}
+	}
+	return r.quotas.Stats()
+}
+
 // Count returns the number of registered agents.
 func (r *Registry) Count() int {
 	r.mu.RLock()
@@ -91,14 +610,17 @@ func (r *Registry) Count() int {
 	return len(r.agents)
 }
 
-// DefaultRegistry creates a registry with all 40 agents registered.
-// It attempts to load from .github/agents/ first, falling back to hardcoded definitions.
+// DefaultRegistry creates a registry with all 40 agents registered and the
+// default tier quotas. It attempts to load from .github/agents/ first,
+// falling back to hardcoded definitions.
 func DefaultRegistry() *Registry {
 	registry := NewRegistry()
 	if err := RegisterAllAgents(registry); err != nil {
 		// Log error but don't panic - we may have loaded agents via fallback
 		fmt.Fprintf(os.Stderr, "Warning: RegisterAllAgents returned error: %v\n", err)
 	}
+	quotas, _ := NewQuotaManager(DefaultTierQuotas())
+	registry.SetQuotas(quotas)
 	return registry
 }
 
@@ -127,6 +649,18 @@ func RegistryFromManifest(manifestPath string) (*Registry, error) {
 
 	registry := NewRegistry()
 
+	quotas := make(map[int]TierQuota)
+	for _, tier := range manifest.Tiers {
+		if tier.Quota != nil {
+			quotas[tier.ID] = *tier.Quota
+		}
+	}
+	quotaManager, err := NewQuotaManager(quotas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest quota: %w", err)
+	}
+	registry.SetQuotas(quotaManager)
+
 	for _, agentConfig := range manifest.Agents {
 		agent := models.Agent{
 			ID:         agentConfig.ID,
@@ -143,6 +677,22 @@ func RegistryFromManifest(manifestPath string) (*Registry, error) {
 		} else {
 			registry.Register(handlers.NewBaseAgent(agent))
 		}
+
+		if agentConfig.Lifecycle != nil {
+			if err := registry.SetLifecycle(agentConfig.Codename, *agentConfig.Lifecycle); err != nil {
+				return nil, fmt.Errorf("invalid manifest lifecycle: %w", err)
+			}
+		}
+		for _, alias := range agentConfig.Aliases {
+			lc := models.AgentLifecycle{
+				State:        models.LifecycleDeprecated,
+				DeprecatedOn: alias.DeprecatedOn,
+				RemovalDate:  alias.RemovalDate,
+			}
+			if err := registry.AddAlias(alias.Name, agentConfig.Codename, lc); err != nil {
+				return nil, fmt.Errorf("invalid manifest alias: %w", err)
+			}
+		}
 	}
 
 	return registry, nil
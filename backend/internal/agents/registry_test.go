@@ -1,9 +1,19 @@
 package agents
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents/handlers"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/intent"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 )
 
 func TestNewRegistry(t *testing.T) {
@@ -50,6 +60,357 @@ func TestRegistryGet(t *testing.T) {
 	}
 }
 
+func TestRegistryInvoke(t *testing.T) {
+	registry := NewRegistry()
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: "add a fuzz test"}
+	registry.Register(eclipse)
+	if err := registry.AddAlias("TESTER", "ECLIPSE", models.AgentLifecycle{State: models.LifecycleActive}); err != nil {
+		t.Fatalf("failed to add alias: %v", err)
+	}
+
+	codename, reply, err := registry.Invoke(context.Background(), "TESTER", "cover the parser")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if codename != "ECLIPSE" || reply != "add a fuzz test" {
+		t.Errorf("expected ECLIPSE's reply through the alias, got %s: %q", codename, reply)
+	}
+	if got := eclipse.prompt.Load(); got != "cover the parser" {
+		t.Errorf("expected prompt passed to the agent, got %q", got)
+	}
+
+	if _, _, err := registry.Invoke(context.Background(), "NONEXISTENT", "p"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("expected ErrAgentNotFound, got %v", err)
+	}
+	registry.Register(&scriptedAgent{codename: "CIPHER", fail: true})
+	if _, _, err := registry.Invoke(context.Background(), "CIPHER", "p"); err == nil {
+		t.Error("expected agent error")
+	}
+}
+
+func TestRegistryOnInvocation(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(&scriptedAgent{codename: "ECLIPSE", reply: "add a fuzz test"})
+	registry.Register(&scriptedAgent{codename: "CIPHER", fail: true})
+	var invocations []Invocation
+	registry.OnInvocation(func(inv Invocation) {
+		invocations = append(invocations, inv)
+	})
+
+	registry.Invoke(context.Background(), "ECLIPSE", "cover the parser")
+	registry.Invoke(context.Background(), "CIPHER", "p")
+	registry.Invoke(context.Background(), "NONEXISTENT", "p")
+
+	if len(invocations) != 2 {
+		t.Fatalf("expected 2 invocations recorded, got %d", len(invocations))
+	}
+	if inv := invocations[0]; inv.Agent != "ECLIPSE" || inv.Route != RouteChat || !inv.Success || inv.Time.IsZero() {
+		t.Errorf("expected a successful chat invocation of ECLIPSE, got %+v", inv)
+	}
+	if inv := invocations[1]; inv.Agent != "CIPHER" || inv.Success {
+		t.Errorf("expected a failed invocation of CIPHER, got %+v", inv)
+	}
+}
+
+func TestRegistryIntents(t *testing.T) {
+	registry := NewRegistry()
+	agent := &scriptedAgent{codename: "ECLIPSE", reply: "done"}
+	registry.Register(agent)
+	cfg, err := intent.ParseConfig([]byte("templates:\n  review: \"{{.Agent}}, review strictly: {{.Query}}\"\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	registry.SetIntents(intent.NewClassifier(), intent.NewTemplates(cfg))
+	var invocations []Invocation
+	registry.OnInvocation(func(inv Invocation) {
+		invocations = append(invocations, inv)
+	})
+
+	registry.Invoke(context.Background(), "ECLIPSE", "review the retry loop")
+	if got := agent.prompt.Load(); got != "ECLIPSE, review strictly: review the retry loop" {
+		t.Errorf("expected the review template applied, got %q", got)
+	}
+	registry.Invoke(context.Background(), "ECLIPSE", "explain the retry loop")
+	if got := agent.prompt.Load(); got != "explain the retry loop" {
+		t.Errorf("expected a query without a template sent unchanged, got %q", got)
+	}
+
+	if len(invocations) != 2 || invocations[0].Intent != intent.Review || invocations[1].Intent != intent.Explain {
+		t.Errorf("expected review and explain intents recorded, got %+v", invocations)
+	}
+
+	registry.SetTemplates(nil)
+	registry.Invoke(context.Background(), "ECLIPSE", "review the retry loop")
+	if got := agent.prompt.Load(); got != "review the retry loop" {
+		t.Errorf("expected the query unchanged once templates are cleared, got %q", got)
+	}
+}
+
+func TestRegistryUpdatePersonas(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(handlers.NewBaseAgent(models.Agent{Codename: "CIPHER", Philosophy: "Trust nothing."}))
+	registry.Register(handlers.NewApexAgent())
+
+	updated, skipped := registry.UpdatePersonas([]models.Agent{
+		{Codename: "CIPHER", Philosophy: "Verify everything."},
+		{Codename: "APEX", Philosophy: "Ship it."},
+		{Codename: "NOBODY"},
+	})
+	if !reflect.DeepEqual(updated, []string{"CIPHER"}) || !reflect.DeepEqual(skipped, []string{"APEX", "NOBODY"}) {
+		t.Errorf("expected only CIPHER updated, got %v and skipped %v", updated, skipped)
+	}
+	if handler, _ := registry.Get("CIPHER"); handler.GetInfo().Philosophy != "Verify everything." {
+		t.Errorf("expected the new persona, got %+v", handler.GetInfo())
+	}
+	if handler, _ := registry.Get("APEX"); handler.GetInfo().Philosophy == "Ship it." {
+		t.Error("expected APEX's built-in persona kept")
+	}
+}
+
+// stubGrounder reports claims mentioning the moon as unsupported.
+type stubGrounder struct{}
+
+func (stubGrounder) Ground(ctx context.Context, answer string) (string, []string) {
+	if strings.Contains(answer, "moon") {
+		return answer + " [unverified]", []string{answer}
+	}
+	return answer + " [grounded]", nil
+}
+
+func TestRegistryGrounding(t *testing.T) {
+	type groundedKey struct{}
+	registry := NewRegistry()
+	agent := &scriptedAgent{codename: "ECLIPSE", reply: "the moon is cheese"}
+	registry.Register(agent)
+	registry.SetGrounding(GroundingConfig{
+		Grounder: stubGrounder{},
+		Enabled: func(ctx context.Context) bool {
+			return ctx.Value(groundedKey{}) != nil
+		},
+		Revise: true,
+	})
+
+	_, reply, err := registry.Invoke(context.Background(), "ECLIPSE", "what is the moon")
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if reply != "the moon is cheese" || agent.calls.Load() != 1 {
+		t.Errorf("expected an ungrounded answer without grounding enabled, got %q after %d calls", reply, agent.calls.Load())
+	}
+
+	ctx := context.WithValue(context.Background(), groundedKey{}, true)
+	_, reply, err = registry.Invoke(ctx, "ECLIPSE", "what is the moon")
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if reply != "the moon is cheese [unverified]" {
+		t.Errorf("expected the revised answer marked, got %q", reply)
+	}
+	if agent.calls.Load() != 3 {
+		t.Errorf("expected one revision pass, got %d calls", agent.calls.Load())
+	}
+	if prompt, _ := agent.prompt.Load().(string); !strings.Contains(prompt, "- the moon is cheese") {
+		t.Errorf("expected the revision prompt to list the unsupported claim, got %q", prompt)
+	}
+
+	agent.reply = "water is wet"
+	_, reply, _ = registry.Invoke(ctx, "ECLIPSE", "what is water")
+	if reply != "water is wet [grounded]" || agent.calls.Load() != 4 {
+		t.Errorf("expected a grounded answer without revision, got %q after %d calls", reply, agent.calls.Load())
+	}
+}
+
+// identityGrounder finds nothing to mark.
+type identityGrounder struct{}
+
+func (identityGrounder) Ground(ctx context.Context, answer string) (string, []string) {
+	return answer, nil
+}
+
+// structuredAgent answers with a structured answer.
+type structuredAgent struct {
+	scriptedAgent
+}
+
+func (a *structuredAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	return copilot.NewAgentResponse(&models.AgentResponse{Summary: a.reply}), nil
+}
+
+func TestRegistryGroundingStructuredAnswers(t *testing.T) {
+	registry := NewRegistry()
+	agent := &structuredAgent{scriptedAgent: scriptedAgent{codename: "ECLIPSE", reply: "water is wet"}}
+	registry.Register(agent)
+	req := &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "what is water"}}}
+
+	registry.SetGrounding(GroundingConfig{Grounder: identityGrounder{}})
+	resp, err := registry.Handle(context.Background(), agent, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if resp.Choices[0].Structured == nil {
+		t.Error("expected an answer with nothing marked to keep its structure")
+	}
+
+	registry.SetGrounding(GroundingConfig{Grounder: stubGrounder{}})
+	resp, err = registry.Handle(context.Background(), agent, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if resp.Choices[0].Structured != nil || resp.Choices[0].Message.Content != "water is wet\n [grounded]" {
+		t.Errorf("expected a marked answer as text alone, got %q with %+v", resp.Choices[0].Message.Content, resp.Choices[0].Structured)
+	}
+}
+
+// stubFollowUps suggests the same prompts for every exchange.
+type stubFollowUps []string
+
+func (f stubFollowUps) Suggest(ctx context.Context, query, answer string, n int) []string {
+	return f[:min(n, len(f))]
+}
+
+func TestRegistryFollowUps(t *testing.T) {
+	registry := NewRegistry()
+	structured := &structuredAgent{scriptedAgent: scriptedAgent{codename: "ECLIPSE", reply: "water is wet"}}
+	plain := &scriptedAgent{codename: "NEXUS", reply: "done"}
+	registry.Register(structured)
+	registry.Register(plain)
+	registry.SetFollowUps(stubFollowUps{"Why is water wet?", "What is ice?"})
+	req := &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "what is water"}}}
+
+	resp, err := registry.Handle(context.Background(), structured, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	answer := resp.Choices[0].Structured
+	if answer == nil || !reflect.DeepEqual(answer.FollowUps, []string{"Why is water wet?", "What is ice?"}) {
+		t.Fatalf("expected the suggestions on the structured answer, got %+v", answer)
+	}
+	if !strings.Contains(resp.Choices[0].Message.Content, "## Follow-up Suggestions\n\n- Why is water wet?\n- What is ice?\n") {
+		t.Errorf("expected the suggestions rendered, got %q", resp.Choices[0].Message.Content)
+	}
+
+	apex := handlers.NewApexAgent()
+	registry.Register(apex)
+	registry.SetFollowUps(stubFollowUps{"Why is water wet?", "Analyze the time and space complexity"})
+	resp, err = registry.Handle(context.Background(), apex, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	want := []string{"Why is water wet?", "Analyze the time and space complexity", "Walk through the edge cases"}
+	if got := resp.Choices[0].Structured.FollowUps; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the agent's own suggestions after the generated ones, got %q", got)
+	}
+
+	resp, err = registry.Handle(context.Background(), plain, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "done" || resp.Choices[0].Structured != nil {
+		t.Errorf("expected a text answer left alone, got %q", resp.Choices[0].Message.Content)
+	}
+}
+
+// recordingAgent keeps the last request it handled.
+type recordingAgent struct {
+	scriptedAgent
+	last *models.CopilotRequest
+}
+
+func (a *recordingAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	a.last = req
+	return a.scriptedAgent.Handle(ctx, req)
+}
+
+// stubPreferences returns the same preferences for every request.
+type stubPreferences string
+
+func (p stubPreferences) Prompt(ctx context.Context) string {
+	return string(p)
+}
+
+func TestRegistryPreferences(t *testing.T) {
+	registry := NewRegistry()
+	agent := &recordingAgent{scriptedAgent: scriptedAgent{codename: "ECLIPSE", reply: "done"}}
+	registry.Register(agent)
+	registry.SetPreferences(stubPreferences("The user works in Go."))
+
+	req := &models.CopilotRequest{Messages: []models.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "write a parser"},
+	}}
+	if _, err := registry.Handle(context.Background(), agent, RouteDirect, req); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	want := []models.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "system", Content: "The user works in Go."},
+		{Role: "user", Content: "write a parser"},
+	}
+	if len(agent.last.Messages) != len(want) {
+		t.Fatalf("expected %d messages, got %+v", len(want), agent.last.Messages)
+	}
+	for i, message := range want {
+		if !reflect.DeepEqual(agent.last.Messages[i], message) {
+			t.Errorf("expected message %d to be %+v, got %+v", i, message, agent.last.Messages[i])
+		}
+	}
+	if len(req.Messages) != 2 {
+		t.Errorf("expected the caller's request unchanged, got %+v", req.Messages)
+	}
+
+	registry.SetPreferences(stubPreferences(""))
+	registry.Handle(context.Background(), agent, RouteDirect, req)
+	if agent.last != req {
+		t.Error("expected the request sent unchanged without preferences")
+	}
+}
+
+// stubSessions replays a fixed history and keeps what is recorded.
+type stubSessions struct {
+	history  []models.Message
+	recorded []string
+}
+
+func (s *stubSessions) History(ctx context.Context) []models.Message {
+	return s.history
+}
+
+func (s *stubSessions) Record(ctx context.Context, agent, query, answer string) {
+	s.recorded = append(s.recorded, agent+": "+query+" -> "+answer)
+}
+
+func TestRegistrySessions(t *testing.T) {
+	registry := NewRegistry()
+	agent := &recordingAgent{scriptedAgent: scriptedAgent{codename: "ECLIPSE", reply: "done"}}
+	registry.Register(agent)
+	registry.SetPreferences(stubPreferences("The user works in Go."))
+	sessions := &stubSessions{history: []models.Message{
+		{Role: "user", Content: "write a parser"},
+		{Role: "assistant", Content: "here it is"},
+	}}
+	registry.SetSessions(sessions)
+
+	if _, _, err := registry.Invoke(context.Background(), "ECLIPSE", "now add tests"); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	roles := make([]string, len(agent.last.Messages))
+	for i, message := range agent.last.Messages {
+		roles[i] = message.Role
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,user" || agent.last.Messages[3].Content != "now add tests" {
+		t.Errorf("expected preferences, then history, then the query, got %+v", agent.last.Messages)
+	}
+	if len(sessions.recorded) != 1 || sessions.recorded[0] != "ECLIPSE: now add tests -> done" {
+		t.Errorf("expected the exchange recorded, got %v", sessions.recorded)
+	}
+
+	agent.fail = true
+	registry.Invoke(context.Background(), "ECLIPSE", "and docs")
+	if len(sessions.recorded) != 1 {
+		t.Errorf("expected failed exchanges not recorded, got %v", sessions.recorded)
+	}
+}
+
 func TestRegistryList(t *testing.T) {
 	registry := DefaultRegistry()
 	agents := registry.List()
@@ -170,6 +531,12 @@ func TestRegistryFromManifest(t *testing.T) {
 	if apex == nil {
 		t.Error("APEX handler is nil")
 	}
+
+	// Tier 4 carries a quota in the manifest
+	stats := registry.QuotaStats()
+	if len(stats) != 1 || stats[0].Tier != 4 || stats[0].Quota.Policy != QuotaQueue || stats[0].Quota.MaxWait != 5*time.Second {
+		t.Errorf("expected tier 4 queue quota from manifest, got %+v", stats)
+	}
 }
 
 // findManifestPath looks for the agents-manifest.yaml file.
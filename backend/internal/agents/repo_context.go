@@ -0,0 +1,187 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// Bounds on the repository context added to a prompt.
+const (
+	// maxContextFiles is the most files and selections added
+	maxContextFiles = 10
+	// maxContextLines is the most lines added of one file
+	maxContextLines = 400
+)
+
+// citationPattern matches the citations agents are asked to write, such as
+// [internal/api/server.go:L10-L24] or [main.go:L7].
+var citationPattern = regexp.MustCompile(`\[([^\[\]\s]+):L(\d+)(?:-L?(\d+))?\]`)
+
+// RepoContext is the repository code Copilot sent with a request: the
+// repository and commit the user is working at, and the files and
+// selections open in their editor.
+type RepoContext struct {
+	// Owner and Name name the repository; empty if Copilot sent none
+	Owner string
+	Name  string
+	// Commit is the SHA the repository is checked out at
+	Commit string
+	Files  []ContextFile
+}
+
+// ContextFile is a file, or lines of one, in a request's context.
+type ContextFile struct {
+	Path     string
+	Language string
+	// StartLine and EndLine are the lines Content holds, counting from 1
+	StartLine int
+	EndLine   int
+	Content   string
+	// Truncated is set when lines past EndLine were left out
+	Truncated bool
+}
+
+// AssembleRepoContext collects the repository context of a request from the
+// references on its last user message. It returns nil when there are no
+// files or selections to add.
+func AssembleRepoContext(req *models.CopilotRequest) *RepoContext {
+	var references []models.Reference
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			references = req.Messages[i].CopilotReferences
+			break
+		}
+	}
+
+	repo := &RepoContext{}
+	for _, ref := range references {
+		switch ref.Type {
+		case models.ReferenceRepository:
+			repo.Owner, repo.Name, repo.Commit = ref.Data.OwnerLogin, ref.Data.Name, ref.Data.CommitOID
+		case models.ReferenceFile, models.ReferenceSelection:
+			if ref.ID == "" || strings.TrimSpace(ref.Data.Content) == "" || len(repo.Files) == maxContextFiles {
+				continue
+			}
+			file := ContextFile{Path: ref.ID, Language: ref.Data.Language, StartLine: 1}
+			if ref.Type == models.ReferenceSelection && ref.Data.Start != nil {
+				// Editors count lines from 0
+				file.StartLine = ref.Data.Start.Line + 1
+			}
+			lines := strings.Split(strings.TrimRight(ref.Data.Content, "\n"), "\n")
+			if len(lines) > maxContextLines {
+				lines, file.Truncated = lines[:maxContextLines], true
+			}
+			file.Content = strings.Join(lines, "\n")
+			file.EndLine = file.StartLine + len(lines) - 1
+			repo.Files = append(repo.Files, file)
+		}
+	}
+	if len(repo.Files) == 0 {
+		return nil
+	}
+	return repo
+}
+
+// Prompt returns the context as a system message: each file with its
+// lines numbered, and how to cite them.
+func (c *RepoContext) Prompt() string {
+	var b strings.Builder
+	b.WriteString("The user's repository context follows")
+	if c.Owner != "" && c.Name != "" {
+		fmt.Fprintf(&b, ", from %s/%s", c.Owner, c.Name)
+		if c.Commit != "" {
+			fmt.Fprintf(&b, " at commit %s", c.Commit)
+		}
+	}
+	b.WriteString(". When the answer relies on these lines, cite them inline as [path:Lstart-Lend], for example [")
+	fmt.Fprintf(&b, "%s:L%d-L%d].\n", c.Files[0].Path, c.Files[0].StartLine, c.Files[0].EndLine)
+	for _, file := range c.Files {
+		fmt.Fprintf(&b, "\n%s (lines %d-%d", file.Path, file.StartLine, file.EndLine)
+		if file.Truncated {
+			b.WriteString(", truncated")
+		}
+		fmt.Fprintf(&b, "):\n```%s\n", file.Language)
+		for i, line := range strings.Split(file.Content, "\n") {
+			fmt.Fprintf(&b, "%d| %s\n", file.StartLine+i, line)
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
+
+// Citations returns the citations of an answer: the lines it cites inline
+// that are in the context, in the order first cited. Citations of files or
+// lines outside the context are dropped. An answer that cites nothing
+// cites every file in the context, since it was written from them.
+func (c *RepoContext) Citations(answer string) []models.Citation {
+	var citations []models.Citation
+	seen := make(map[string]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		start, _ := strconv.Atoi(match[2])
+		end := start
+		if match[3] != "" {
+			end, _ = strconv.Atoi(match[3])
+		}
+		if end < start || seen[match[0]] {
+			continue
+		}
+		for _, file := range c.Files {
+			if file.Path == match[1] && start >= file.StartLine && end <= file.EndLine {
+				seen[match[0]] = true
+				citations = append(citations, c.citation(file.Path, start, end))
+				break
+			}
+		}
+	}
+	if len(citations) > 0 {
+		return citations
+	}
+	for _, file := range c.Files {
+		citations = append(citations, c.citation(file.Path, file.StartLine, file.EndLine))
+	}
+	return citations
+}
+
+// citation returns the citation of lines of a file, linked to them on
+// GitHub when the repository and commit are known.
+func (c *RepoContext) citation(path string, start, end int) models.Citation {
+	lines := fmt.Sprintf("L%d", start)
+	if end != start {
+		lines += fmt.Sprintf("-L%d", end)
+	}
+	citation := models.Citation{Title: path + ":" + lines, Path: path, StartLine: start, EndLine: end, Commit: c.Commit}
+	if c.Owner != "" && c.Name != "" && c.Commit != "" {
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		citation.URL = fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s#%s", c.Owner, c.Name, c.Commit, strings.Join(segments, "/"), lines)
+	}
+	return citation
+}
+
+// citationReferences returns citations as references Copilot links to.
+func (c *RepoContext) citationReferences(citations []models.Citation) []models.Reference {
+	references := make([]models.Reference, 0, len(citations))
+	for _, citation := range citations {
+		references = append(references, models.Reference{
+			Type: models.ReferenceCitation,
+			ID:   citation.Path,
+			Data: models.ReferenceData{
+				Start:      &models.ReferencePosition{Line: citation.StartLine},
+				End:        &models.ReferencePosition{Line: citation.EndLine},
+				Name:       c.Name,
+				OwnerLogin: c.Owner,
+				CommitOID:  citation.Commit,
+			},
+			Metadata: &models.ReferenceMetadata{DisplayName: citation.Title, DisplayIcon: "file", DisplayURL: citation.URL},
+		})
+	}
+	return references
+}
@@ -0,0 +1,129 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// contextRequest returns a request whose last user message carries a
+// repository, a file and a selection.
+func contextRequest() *models.CopilotRequest {
+	return &models.CopilotRequest{Messages: []models.Message{
+		{Role: "user", Content: "what does the server do"},
+		{Role: "assistant", Content: "it serves"},
+		{Role: "user", Content: "explain the handler", CopilotReferences: []models.Reference{
+			{Type: models.ReferenceRepository, ID: "octo/app", Data: models.ReferenceData{Name: "app", OwnerLogin: "octo", CommitOID: "abc123"}},
+			{Type: models.ReferenceFile, ID: "main.go", Data: models.ReferenceData{Language: "go", Content: "package main\n\nfunc main() {}\n"}},
+			{Type: models.ReferenceSelection, ID: "internal/api/server.go", Data: models.ReferenceData{
+				Language: "go",
+				Content:  "func (s *Server) Handle() {\n\ts.serve()\n}",
+				Start:    &models.ReferencePosition{Line: 9},
+				End:      &models.ReferencePosition{Line: 11},
+			}},
+			{Type: models.ReferenceFile, ID: "empty.go", Data: models.ReferenceData{Content: "  \n"}},
+		}},
+	}}
+}
+
+func TestAssembleRepoContext(t *testing.T) {
+	repo := AssembleRepoContext(contextRequest())
+	if repo == nil || repo.Owner != "octo" || repo.Name != "app" || repo.Commit != "abc123" || len(repo.Files) != 2 {
+		t.Fatalf("unexpected context %+v", repo)
+	}
+	if file := repo.Files[0]; file.StartLine != 1 || file.EndLine != 3 {
+		t.Errorf("expected the file's three lines, got %+v", file)
+	}
+	if file := repo.Files[1]; file.StartLine != 10 || file.EndLine != 12 {
+		t.Errorf("expected the selection counted from 1, got %+v", file)
+	}
+
+	long := strings.Repeat("x\n", maxContextLines+5)
+	repo = AssembleRepoContext(&models.CopilotRequest{Messages: []models.Message{{Role: "user", CopilotReferences: []models.Reference{
+		{Type: models.ReferenceFile, ID: "big.go", Data: models.ReferenceData{Content: long}},
+	}}}})
+	if file := repo.Files[0]; !file.Truncated || file.EndLine != maxContextLines {
+		t.Errorf("expected the file truncated, got lines %d-%d", file.StartLine, file.EndLine)
+	}
+
+	if AssembleRepoContext(&models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "hi"}}}) != nil {
+		t.Error("expected no context without references")
+	}
+}
+
+func TestRepoContextPrompt(t *testing.T) {
+	prompt := AssembleRepoContext(contextRequest()).Prompt()
+	for _, want := range []string{"from octo/app at commit abc123", "[main.go:L1-L3]", "internal/api/server.go (lines 10-12)", "11| \ts.serve()"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in\n%s", want, prompt)
+		}
+	}
+}
+
+func TestRepoContextCitations(t *testing.T) {
+	repo := AssembleRepoContext(contextRequest())
+	citations := repo.Citations("Handle serves [internal/api/server.go:L10-L12], see [internal/api/server.go:L11] " +
+		"and [main.go:L3]; not [main.go:L4], [other.go:L1] or [main.go:L3-L1]. Again [main.go:L3].")
+	want := []string{"internal/api/server.go:L10-L12", "internal/api/server.go:L11", "main.go:L3"}
+	if len(citations) != len(want) {
+		t.Fatalf("expected %d citations, got %+v", len(want), citations)
+	}
+	for i, title := range want {
+		if citations[i].Title != title {
+			t.Errorf("expected citation %d to be %s, got %s", i, title, citations[i].Title)
+		}
+	}
+	if got := citations[0]; got.StartLine != 10 || got.EndLine != 12 || got.Commit != "abc123" ||
+		got.URL != "https://github.com/octo/app/blob/abc123/internal/api/server.go#L10-L12" {
+		t.Errorf("unexpected citation %+v", got)
+	}
+
+	if citations := repo.Citations("It serves."); len(citations) != 2 || citations[1].Title != "internal/api/server.go:L10-L12" {
+		t.Errorf("expected every file cited without inline citations, got %+v", citations)
+	}
+
+	repo.Commit = ""
+	if citations := repo.Citations("[main.go:L1]"); citations[0].URL != "" {
+		t.Errorf("expected no link without a commit, got %s", citations[0].URL)
+	}
+}
+
+func TestRegistryRepoContext(t *testing.T) {
+	registry := NewRegistry()
+	agent := &recordingAgent{scriptedAgent: scriptedAgent{codename: "APEX", reply: "It calls serve [internal/api/server.go:L11]."}}
+	registry.Register(agent)
+
+	req := contextRequest()
+	resp, err := registry.Handle(context.Background(), agent, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if len(agent.last.Messages) != 4 || agent.last.Messages[0].Role != "system" || !strings.Contains(agent.last.Messages[0].Content, "10| func") {
+		t.Errorf("expected the context added as a system message, got %+v", agent.last.Messages)
+	}
+	if len(req.Messages) != 3 {
+		t.Error("expected the caller's request unchanged")
+	}
+	refs := resp.CopilotReferences
+	if len(refs) != 1 || refs[0].Type != models.ReferenceCitation || refs[0].ID != "internal/api/server.go" ||
+		refs[0].Data.Start.Line != 11 || refs[0].Metadata.DisplayURL != "https://github.com/octo/app/blob/abc123/internal/api/server.go#L11" {
+		t.Errorf("unexpected references %+v", refs)
+	}
+
+	structured := &structuredAgent{scriptedAgent: scriptedAgent{codename: "ECLIPSE", reply: "Serves requests [main.go:L1-L3]."}}
+	registry.Register(structured)
+	resp, err = registry.Handle(context.Background(), structured, RouteDirect, req)
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	answer := resp.Choices[0].Structured
+	if answer == nil || len(answer.Citations) != 1 || answer.Citations[0].Path != "main.go" {
+		t.Fatalf("expected the citation on the structured answer, got %+v", answer)
+	}
+	if link := fmt.Sprintf("[main.go:L1-L3](%s)", answer.Citations[0].URL); !strings.Contains(resp.Choices[0].Message.Content, link) {
+		t.Errorf("expected %s rendered, got %q", link, resp.Choices[0].Message.Content)
+	}
+}
@@ -0,0 +1,525 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+const (
+	// maxReviewDiffBytes bounds a diff, given or fetched
+	maxReviewDiffBytes = 1 << 20
+	// maxReviewChunkBytes bounds the diff text one agent reviews at once;
+	// a larger hunk is a chunk of its own
+	maxReviewChunkBytes = 24 << 10
+	// maxReviewChunks bounds the chunks of one review; files past them
+	// are skipped
+	maxReviewChunks = 16
+	// reviewConcurrency is how many chunks are reviewed at once
+	reviewConcurrency = 4
+	// defaultReviewer reviews files no other agent is routed to
+	defaultReviewer = "MENTOR"
+)
+
+// Review events, named as in GitHub's pull request review API.
+const (
+	ReviewEventComment        = "COMMENT"
+	ReviewEventRequestChanges = "REQUEST_CHANGES"
+)
+
+// Errors fetching a pull request's diff.
+var (
+	// ErrPullRequestNotFound is returned for a pull request GitHub does
+	// not show the caller
+	ErrPullRequestNotFound = errdefs.New(errdefs.ErrNotFound, "pull request not found")
+	// ErrPullRequestFetch is returned when GitHub fails to return a diff
+	ErrPullRequestFetch = errdefs.New(errdefs.ErrProviderFailure, "could not fetch the pull request diff")
+)
+
+// reviewInstructions tells the agent what to review and how to anchor its
+// findings.
+const reviewInstructions = `Review the change below. Comment on bugs, risks and missing tests, not on style a formatter would fix.
+Anchor each finding to the line numbers in the left margin, which are lines of the new file; lines without a number were removed.`
+
+// reviewRoute sends files a rule matches to an agent.
+type reviewRoute struct {
+	agent string
+	match func(file *DiffFile) bool
+}
+
+// reviewRoutes are tried in order; files none matches go to MENTOR.
+var reviewRoutes = []reviewRoute{
+	{"ARBITER", hasConflictMarkers},
+	{"ECLIPSE", pathMatches("_test.", ".test.", ".spec.", "test/", "tests/")},
+	{"CIPHER", pathMatches("auth", "crypto", "secret", "token", "password", "security", "tls", ".pem")},
+	{"FLUX", pathMatches("Dockerfile", ".tf", ".github/", "k8s/", "helm/", "deploy/", "Makefile", "docker-compose")},
+	{"SCRIBE", pathMatches(".md", ".rst", ".adoc", "docs/")},
+}
+
+// ReviewRequest is the payload of POST /review: a unified diff, or a pull
+// request whose diff is fetched from GitHub.
+type ReviewRequest struct {
+	Diff string `json:"diff,omitempty"`
+	// Repository is "owner/name"; with PullRequest it names the pull
+	// request to review when Diff is empty
+	Repository  string `json:"repository,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	// SHA is the commit reviewed, returned as the review's commit_id
+	SHA string `json:"sha,omitempty"`
+	// Agent reviews every chunk instead of the agents files are routed to
+	Agent string `json:"agent,omitempty"`
+	// Prompt adds to the review instructions
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// ReviewComment is a comment on lines of the new version of a file, as
+// GitHub's pull request review API takes it.
+type ReviewComment struct {
+	Path string `json:"path"`
+	// Line is the last line commented on; StartLine the first, for a
+	// comment on several lines
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Body      string `json:"body"`
+}
+
+// ReviewChunk reports the review of one chunk of the diff.
+type ReviewChunk struct {
+	Agent   string   `json:"agent"`
+	Files   []string `json:"files"`
+	Summary string   `json:"summary,omitempty"`
+	// Findings is the number of findings the agent reported
+	Findings int `json:"findings"`
+	// Error is set if the chunk could not be reviewed
+	Error string `json:"error,omitempty"`
+}
+
+// ReviewResponse is the result of a review. CommitID, Body, Event and
+// Comments can be posted to GitHub as they are, to
+// POST /repos/{owner}/{repo}/pulls/{pull_number}/reviews.
+type ReviewResponse struct {
+	CommitID string `json:"commit_id,omitempty"`
+	// Body holds each chunk's summary and the findings that could not be
+	// anchored to a line of the diff
+	Body string `json:"body"`
+	// Event is REQUEST_CHANGES if any finding is an error, and COMMENT
+	// otherwise
+	Event    string          `json:"event"`
+	Comments []ReviewComment `json:"comments"`
+	// Conclusion is derived from the findings as for GitHub Actions
+	Conclusion string        `json:"conclusion"`
+	Chunks     []ReviewChunk `json:"chunks"`
+	// Skipped are the files not reviewed, with why
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// reviewChunk is a part of a diff one agent reviews.
+type reviewChunk struct {
+	agent string
+	files []*DiffFile
+	size  int
+}
+
+// reviewResult is what an agent found in a chunk.
+type reviewResult struct {
+	agent    string
+	summary  string
+	findings []ActionsFinding
+	err      error
+}
+
+// SetGitHubAPI sets the GitHub API base URL pull request diffs are fetched
+// from; without it they are fetched from api.github.com.
+func (h *Handler) SetGitHubAPI(apiURL string) {
+	h.githubAPI = strings.TrimSuffix(apiURL, "/")
+}
+
+// ReviewDiff handles POST /review - splits a diff into chunks, has the
+// agents suited to each file review them, and returns their findings as
+// review comments anchored to the diff's lines. A pull request's diff is
+// fetched with the caller's token, which must access the repository.
+func (h *Handler) ReviewDiff(w http.ResponseWriter, r *http.Request) {
+	var req ReviewRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxReviewDiffBytes+maxActionsBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		errdefs.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Repository != "" {
+		if installation := auth.GetInstallation(r.Context()); installation != nil && !installation.CanAccess(req.Repository) {
+			errdefs.WriteError(w, "installation token can't access "+req.Repository, http.StatusForbidden)
+			return
+		}
+	}
+
+	if req.Diff == "" {
+		diff, err := h.fetchPullRequestDiff(r.Context(), req.Repository, req.PullRequest, r.Header.Get("Authorization"))
+		if err != nil {
+			log.Printf("Error fetching %s#%d: %v", req.Repository, req.PullRequest, err)
+			errdefs.WriteHTTP(w, err)
+			return
+		}
+		req.Diff = diff
+	}
+	files, err := ParseDiff(req.Diff)
+	if err != nil {
+		errdefs.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Agent != "" {
+		if _, _, err := h.registry.Resolve(strings.ToUpper(req.Agent)); err != nil {
+			errdefs.WriteError(w, err.Error(), resolveStatus(err))
+			return
+		}
+	}
+
+	chunks, skipped := chunkDiff(files, strings.ToUpper(req.Agent))
+	log.Printf("Review: %d files in %d chunks", len(files), len(chunks))
+
+	results := make([]reviewResult, len(chunks))
+	sem := make(chan struct{}, reviewConcurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = h.reviewChunk(r.Context(), chunk, &req)
+		}()
+	}
+	wg.Wait()
+
+	resp := assembleReview(chunks, results, &req)
+	resp.Skipped = skipped
+	status := http.StatusOK
+	if len(chunks) > 0 && failedChunks(results) == len(chunks) {
+		status = http.StatusBadGateway
+		if errdefs.HTTPStatus(results[0].err) == http.StatusTooManyRequests {
+			status = http.StatusTooManyRequests
+			w.Header().Set("Retry-After", retryAfter(results[0].err))
+		}
+	}
+	errdefs.WriteJSON(w, status, resp)
+}
+
+// validate checks that a request has a diff or names a pull request.
+func (req *ReviewRequest) validate() error {
+	switch {
+	case req.Repository != "" && !repositoryPattern.MatchString(req.Repository):
+		return errors.New(`repository must be "owner/name"`)
+	case strings.TrimSpace(req.Diff) != "":
+		return nil
+	case req.Repository == "" || req.PullRequest <= 0:
+		return errors.New("diff, or repository and pull_request, is required")
+	}
+	return nil
+}
+
+// fetchPullRequestDiff fetches the diff of a pull request from the GitHub
+// API, authorized as the caller.
+func (h *Handler) fetchPullRequestDiff(ctx context.Context, repository string, number int, authorization string) (string, error) {
+	apiURL := h.githubAPI
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/pulls/%d", apiURL, repository, number), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.diff")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPullRequestFetch, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s#%d", ErrPullRequestNotFound, repository, number)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%w: GitHub returned %s", ErrPullRequestFetch, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReviewDiffBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPullRequestFetch, err)
+	}
+	if len(body) > maxReviewDiffBytes {
+		return "", fmt.Errorf("%w: the diff of %s#%d is larger than 1 MB", ErrInvalidDiff, repository, number)
+	}
+	return string(body), nil
+}
+
+// chunkDiff routes each reviewable file to an agent, or to agent for all
+// if it is set, and packs each agent's files into chunks. Files past the
+// chunk limit, and those with no lines to comment on, are skipped.
+func chunkDiff(files []DiffFile, agent string) ([]*reviewChunk, []string) {
+	var chunks []*reviewChunk
+	var skipped []string
+	open := make(map[string]*reviewChunk)
+	for i := range files {
+		file := &files[i]
+		switch {
+		case file.Binary:
+			skipped = append(skipped, file.Path+": binary")
+			continue
+		case file.Deleted:
+			skipped = append(skipped, file.Path+": deleted")
+			continue
+		}
+		reviewer := agent
+		if reviewer == "" {
+			reviewer = routeReview(file)
+		}
+		for _, part := range splitFile(file) {
+			size := part.size()
+			chunk := open[reviewer]
+			if chunk == nil || chunk.size+size > maxReviewChunkBytes {
+				if len(chunks) == maxReviewChunks {
+					skipped = append(skipped, file.Path+": too many chunks")
+					break
+				}
+				chunk = &reviewChunk{agent: reviewer}
+				chunks = append(chunks, chunk)
+				open[reviewer] = chunk
+			}
+			chunk.files = append(chunk.files, part)
+			chunk.size += size
+		}
+	}
+	return chunks, skipped
+}
+
+// splitFile splits a file whose hunks are larger than a chunk into parts
+// of whole hunks.
+func splitFile(file *DiffFile) []*DiffFile {
+	var parts []*DiffFile
+	part := &DiffFile{Path: file.Path, OldPath: file.OldPath}
+	for _, hunk := range file.Hunks {
+		if len(part.Hunks) > 0 && part.size()+len(hunk.numbered()) > maxReviewChunkBytes {
+			parts = append(parts, part)
+			part = &DiffFile{Path: file.Path, OldPath: file.OldPath}
+		}
+		part.Hunks = append(part.Hunks, hunk)
+	}
+	return append(parts, part)
+}
+
+// size is the length of a file's diff as an agent is shown it.
+func (f *DiffFile) size() int {
+	n := len(f.Path)
+	for i := range f.Hunks {
+		n += len(f.Hunks[i].numbered())
+	}
+	return n
+}
+
+// routeReview returns the agent suited to review a file.
+func routeReview(file *DiffFile) string {
+	for _, route := range reviewRoutes {
+		if route.match(file) {
+			return route.agent
+		}
+	}
+	return defaultReviewer
+}
+
+// pathMatches returns a rule matching files whose path, ignoring case,
+// contains one of the fragments.
+func pathMatches(fragments ...string) func(*DiffFile) bool {
+	return func(file *DiffFile) bool {
+		p := strings.ToLower(file.Path)
+		for _, fragment := range fragments {
+			if strings.Contains(p, strings.ToLower(fragment)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// hasConflictMarkers reports whether a change adds merge conflict markers.
+func hasConflictMarkers(file *DiffFile) bool {
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if strings.HasPrefix(line, "+<<<<<<< ") || strings.HasPrefix(line, "+>>>>>>> ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reviewChunk has a chunk's agent review it. An agent the registry does
+// not know, as in a trimmed manifest, is replaced by MENTOR.
+func (h *Handler) reviewChunk(ctx context.Context, chunk *reviewChunk, req *ReviewRequest) reviewResult {
+	agent, res, err := h.registry.Resolve(chunk.agent)
+	if err != nil && chunk.agent != defaultReviewer && req.Agent == "" {
+		agent, res, err = h.registry.Resolve(defaultReviewer)
+	}
+	if err != nil {
+		return reviewResult{agent: chunk.agent, err: err}
+	}
+	result := reviewResult{agent: res.Codename}
+
+	release, err := h.registry.Admit(ctx, agent)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer release()
+
+	resp, err := h.registry.Handle(ctx, agent, RouteReview, &models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: chunk.message(req)}},
+	})
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.summary, result.findings = parseFindings(resp.Choices[0].Message.Content)
+	return result
+}
+
+// message builds the prompt for a chunk: the review instructions, the
+// chunk's files with numbered lines, and how to report findings.
+func (c *reviewChunk) message(req *ReviewRequest) string {
+	var b strings.Builder
+	b.WriteString(reviewInstructions)
+	if prompt := strings.TrimSpace(req.Prompt); prompt != "" {
+		b.WriteString("\n" + prompt)
+	}
+	if req.Repository != "" {
+		b.WriteString("\n\nRepository: " + req.Repository)
+		if req.PullRequest > 0 {
+			fmt.Fprintf(&b, "\nPull request: #%d", req.PullRequest)
+		}
+	}
+	b.WriteString("\n\nChanged files:")
+	for _, file := range c.files {
+		b.WriteString("\n--- " + file.Path + "\n")
+		for i := range file.Hunks {
+			b.WriteString(file.Hunks[i].numbered())
+		}
+	}
+	b.WriteString("\n" + findingInstructions)
+	return b.String()
+}
+
+// assembleReview turns the chunks' findings into review comments, moving
+// those that do not fall on lines of the diff into the review body.
+func assembleReview(chunks []*reviewChunk, results []reviewResult, req *ReviewRequest) *ReviewResponse {
+	resp := &ReviewResponse{CommitID: req.SHA, Comments: []ReviewComment{}, Chunks: []ReviewChunk{}}
+	var all []ActionsFinding
+	var body, notes strings.Builder
+	for i, chunk := range chunks {
+		result := results[i]
+		report := ReviewChunk{Agent: result.agent, Summary: result.summary, Findings: len(result.findings)}
+		for _, file := range chunk.files {
+			if len(report.Files) == 0 || report.Files[len(report.Files)-1] != file.Path {
+				report.Files = append(report.Files, file.Path)
+			}
+		}
+		if result.err != nil {
+			log.Printf("Review: %s could not review %v: %v", result.agent, report.Files, result.err)
+			report.Error = result.err.Error()
+		}
+		resp.Chunks = append(resp.Chunks, report)
+		if result.summary != "" {
+			fmt.Fprintf(&body, "**%s** on %s: %s\n\n", result.agent, strings.Join(report.Files, ", "), result.summary)
+		}
+
+		for _, finding := range result.findings {
+			all = append(all, finding)
+			if comment, ok := chunk.anchor(finding, result.agent); ok {
+				resp.Comments = append(resp.Comments, comment)
+				continue
+			}
+			fmt.Fprintf(&notes, "- **%s** · %s%s: %s\n", result.agent, finding.Level, findingLocation(finding), finding.Message)
+		}
+	}
+	if notes.Len() > 0 {
+		body.WriteString("Findings not on a changed line:\n\n" + notes.String())
+	}
+	resp.Body = strings.TrimSpace(body.String())
+	resp.Conclusion = conclude(all)
+	resp.Event = ReviewEventComment
+	if resp.Conclusion == ConclusionFailure {
+		resp.Event = ReviewEventRequestChanges
+	}
+	return resp
+}
+
+// anchor returns a finding as a comment on the lines of the chunk it
+// names. A finding whose first line is not in the diff is not anchored; one
+// whose range leaves the hunk is anchored to its first line.
+func (c *reviewChunk) anchor(finding ActionsFinding, agent string) (ReviewComment, bool) {
+	if finding.Path == "" || finding.StartLine <= 0 {
+		return ReviewComment{}, false
+	}
+	for _, file := range c.files {
+		if file.Path != finding.Path {
+			continue
+		}
+		hunk := file.hunkAt(finding.StartLine)
+		if hunk < 0 {
+			continue
+		}
+		comment := ReviewComment{Path: file.Path, Line: finding.StartLine, Side: "RIGHT", Body: findingBody(finding, agent)}
+		if finding.EndLine > finding.StartLine && file.hunkAt(finding.EndLine) == hunk {
+			comment.StartLine, comment.StartSide, comment.Line = finding.StartLine, "RIGHT", finding.EndLine
+		}
+		return comment, true
+	}
+	return ReviewComment{}, false
+}
+
+// findingBody renders a finding as a comment, led by the agent and level.
+func findingBody(finding ActionsFinding, agent string) string {
+	lead := fmt.Sprintf("**%s** · %s", agent, finding.Level)
+	if finding.Title != "" {
+		lead += " · " + finding.Title
+	}
+	return lead + "\n\n" + finding.Message
+}
+
+// findingLocation returns where a finding that could not be anchored
+// points, if anywhere.
+func findingLocation(finding ActionsFinding) string {
+	switch {
+	case finding.Path == "":
+		return ""
+	case finding.StartLine <= 0:
+		return " in " + finding.Path
+	}
+	return fmt.Sprintf(" at %s:%d", finding.Path, finding.StartLine)
+}
+
+// failedChunks counts the chunks that could not be reviewed.
+func failedChunks(results []reviewResult) int {
+	n := 0
+	for _, result := range results {
+		if result.err != nil {
+			n++
+		}
+	}
+	return n
+}
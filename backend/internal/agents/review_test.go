@@ -0,0 +1,193 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+const mentorReview = `The EOF handling swallows a partial token.
+::error file=parser.go,line=41,endLine=43,title=Lost token::tok is dropped when EOF follows it
+::warning file=parser.go,line=46::err is shadowed below
+::notice::Consider a parser fuzz test`
+
+// setupReviewRouter routes the review endpoint to a handler whose registry
+// holds the given agents.
+func setupReviewRouter(agents ...*scriptedAgent) (*Handler, *chi.Mux) {
+	registry := NewRegistry()
+	for _, agent := range agents {
+		registry.Register(agent)
+	}
+	handler := NewHandler(registry)
+	r := chi.NewRouter()
+	r.Post("/review", handler.ReviewDiff)
+	return handler, r
+}
+
+// postReview posts a review request and decodes the response.
+func postReview(t *testing.T, r http.Handler, body interface{}, ctx context.Context) (int, *ReviewResponse, string) {
+	t.Helper()
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/review", strings.NewReader(string(payload))).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer ghs_token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp ReviewResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, &resp, w.Body.String()
+}
+
+func TestReviewDiff(t *testing.T) {
+	mentor := &scriptedAgent{codename: "MENTOR", reply: mentorReview}
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: "The new test file is empty."}
+	_, r := setupReviewRouter(mentor, eclipse)
+
+	status, resp, raw := postReview(t, r, ReviewRequest{Diff: reviewDiff, SHA: "2222222", Prompt: "Focus on error handling."}, context.Background())
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, raw)
+	}
+	if len(resp.Chunks) != 2 || resp.Chunks[0].Agent != "MENTOR" || resp.Chunks[1].Agent != "ECLIPSE" || resp.Chunks[1].Files[0] != "parser_test.go" {
+		t.Fatalf("expected the test file routed to ECLIPSE, got %+v", resp.Chunks)
+	}
+	if len(resp.Skipped) != 2 || !strings.Contains(resp.Skipped[0], "old.go") || !strings.Contains(resp.Skipped[1], "binary") {
+		t.Errorf("expected the deleted and binary files skipped, got %v", resp.Skipped)
+	}
+
+	want := ReviewComment{Path: "parser.go", StartLine: 41, StartSide: "RIGHT", Line: 43, Side: "RIGHT",
+		Body: "**MENTOR** · error · Lost token\n\ntok is dropped when EOF follows it"}
+	if len(resp.Comments) != 1 || resp.Comments[0] != want {
+		t.Errorf("expected one comment on lines 41-43, got %+v", resp.Comments)
+	}
+	for _, part := range []string{"**MENTOR** on parser.go: The EOF handling", "**ECLIPSE** on parser_test.go", "- **MENTOR** · warning at parser.go:46: err is shadowed", "- **MENTOR** · notice: Consider"} {
+		if !strings.Contains(resp.Body, part) {
+			t.Errorf("expected %q in the body, got %q", part, resp.Body)
+		}
+	}
+	if resp.Event != ReviewEventRequestChanges || resp.Conclusion != ConclusionFailure || resp.CommitID != "2222222" {
+		t.Errorf("expected changes requested on the commit, got %s, %s, %s", resp.Event, resp.Conclusion, resp.CommitID)
+	}
+
+	prompt, _ := mentor.prompt.Load().(string)
+	for _, want := range []string{"Focus on error handling.", "--- parser.go\n@@ -40,3", "   41 +\tif err == io.EOF {", "::warning file=PATH"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in the prompt, got %q", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "parser_test.go") {
+		t.Error("expected the test file left to ECLIPSE")
+	}
+}
+
+func TestReviewDiffAgent(t *testing.T) {
+	arbiter := &scriptedAgent{codename: "ARBITER", reply: "Nothing to add."}
+	_, r := setupReviewRouter(arbiter)
+
+	// One agent reviews everything when named
+	status, resp, raw := postReview(t, r, ReviewRequest{Diff: reviewDiff, Agent: "arbiter"}, context.Background())
+	if status != http.StatusOK || len(resp.Chunks) != 1 || len(resp.Chunks[0].Files) != 2 || arbiter.calls.Load() != 1 {
+		t.Fatalf("expected both files in one ARBITER chunk, got %d: %s", status, raw)
+	}
+	if resp.Event != ReviewEventComment || resp.Conclusion != ConclusionSuccess {
+		t.Errorf("expected a plain comment without findings, got %s", resp.Event)
+	}
+
+	// Routed agents missing from the registry leave their chunk failed
+	status, resp, _ = postReview(t, r, ReviewRequest{Diff: reviewDiff}, context.Background())
+	if status != http.StatusBadGateway || resp.Chunks[0].Error == "" {
+		t.Errorf("expected 502 when no chunk could be reviewed, got %d %+v", status, resp.Chunks)
+	}
+}
+
+func TestReviewDiffPullRequest(t *testing.T) {
+	var accept, authorization string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept, authorization = r.Header.Get("Accept"), r.Header.Get("Authorization")
+		if r.URL.Path != "/repos/octo-org/api/pulls/12" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, reviewDiff)
+	}))
+	defer github.Close()
+
+	mentor := &scriptedAgent{codename: "MENTOR", reply: "Fine."}
+	handler, r := setupReviewRouter(mentor, &scriptedAgent{codename: "ECLIPSE", reply: "Fine."})
+	handler.SetGitHubAPI(github.URL + "/")
+
+	status, resp, raw := postReview(t, r, ReviewRequest{Repository: "octo-org/api", PullRequest: 12}, context.Background())
+	if status != http.StatusOK || len(resp.Chunks) != 2 {
+		t.Fatalf("expected the pull request reviewed, got %d: %s", status, raw)
+	}
+	if accept != "application/vnd.github.diff" || authorization != "Bearer ghs_token" {
+		t.Errorf("expected the diff fetched with the caller's token, got %q %q", accept, authorization)
+	}
+	if prompt, _ := mentor.prompt.Load().(string); !strings.Contains(prompt, "Pull request: #12") {
+		t.Errorf("expected the pull request in the prompt, got %q", prompt)
+	}
+
+	if status, _, _ := postReview(t, r, ReviewRequest{Repository: "octo-org/api", PullRequest: 13}, context.Background()); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing pull request, got %d", status)
+	}
+}
+
+func TestReviewDiffErrors(t *testing.T) {
+	_, r := setupReviewRouter(&scriptedAgent{codename: "MENTOR", reply: "ok"})
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+	}{
+		{"nothing to review", ReviewRequest{Repository: "octo-org/api"}, http.StatusBadRequest},
+		{"bad repository", ReviewRequest{Repository: "api", PullRequest: 1}, http.StatusBadRequest},
+		{"invalid diff", ReviewRequest{Diff: "not a diff"}, http.StatusBadRequest},
+		{"unknown agent", ReviewRequest{Diff: reviewDiff, Agent: "NOBODY"}, http.StatusNotFound},
+		{"invalid json", "{", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		status, _, raw := postReview(t, r, tt.body, context.Background())
+		if status != tt.status || !strings.Contains(raw, `"error"`) {
+			t.Errorf("%s: expected JSON error with status %d, got %d: %s", tt.name, tt.status, status, raw)
+		}
+	}
+
+	installation := &auth.Installation{Repositories: []string{"octo-org/api"}}
+	ctx := context.WithValue(context.Background(), auth.InstallationContextKey, installation)
+	if status, _, _ := postReview(t, r, ReviewRequest{Repository: "octo-org/secret", PullRequest: 1}, ctx); status != http.StatusForbidden {
+		t.Errorf("expected 403 for an inaccessible repository, got %d", status)
+	}
+}
+
+func TestChunkDiff(t *testing.T) {
+	hunk := DiffHunk{Header: "@@ -1,1 +1,1 @@", NewStart: 1, Lines: []string{"+" + strings.Repeat("x", maxReviewChunkBytes/2)}}
+	files := []DiffFile{
+		{Path: "internal/auth/token.go", Hunks: []DiffHunk{hunk, hunk, hunk}},
+		{Path: ".github/workflows/ci.yml", Hunks: []DiffHunk{{Lines: []string{"+on: push"}}}},
+		{Path: "docs/guide.md", Hunks: []DiffHunk{{Lines: []string{"+<<<<<<< HEAD"}}}},
+		{Path: "main.go", Hunks: []DiffHunk{{Lines: []string{"+package main"}}}},
+	}
+	chunks, skipped := chunkDiff(files, "")
+	var got []string
+	for _, chunk := range chunks {
+		got = append(got, fmt.Sprintf("%s:%d", chunk.agent, len(chunk.files)))
+	}
+	if want := "CIPHER:1 CIPHER:1 CIPHER:1 FLUX:1 ARBITER:1 MENTOR:1"; strings.Join(got, " ") != want || len(skipped) != 0 {
+		t.Errorf("expected %s, got %v (skipped %v)", want, got, skipped)
+	}
+
+	var many []DiffFile
+	for i := 0; i < maxReviewChunks+2; i++ {
+		many = append(many, DiffFile{Path: fmt.Sprintf("f%d.go", i), Hunks: []DiffHunk{hunk, hunk}})
+	}
+	chunks, skipped = chunkDiff(many, "MENTOR")
+	if len(chunks) != maxReviewChunks || len(skipped) == 0 {
+		t.Errorf("expected at most %d chunks with the rest skipped, got %d and %v", maxReviewChunks, len(chunks), skipped)
+	}
+}
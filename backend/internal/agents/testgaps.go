@@ -0,0 +1,220 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/testgap"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+const (
+	// maxTestGapBodyBytes bounds a test-gap request, sources included
+	maxTestGapBodyBytes = 1 << 20
+	// maxTestGapSourceBytes bounds the source text sent to ECLIPSE; files
+	// after it are analyzed but not shown
+	maxTestGapSourceBytes = 64 << 10
+	// testGapAgent judges the branches of functions the tests reach
+	testGapAgent = "ECLIPSE"
+)
+
+// Where a test gap was found.
+const (
+	// GapStatic is a branch of a function no test reaches
+	GapStatic = "static"
+	// GapAgent is a branch ECLIPSE judged no test takes
+	GapAgent = "agent"
+)
+
+// testGapInstructions tells ECLIPSE how to report the gaps it finds.
+const testGapInstructions = `Report each branch of a reached function that no test takes on its own line as a GitHub Actions workflow command:
+::warning file=PATH,line=LINE,title=FUNCTION::the input that would take the branch
+Use the line the branch starts on.`
+
+// TestGapRequest is the payload of POST /test-gaps: the Go files of one
+// package, its tests among them.
+type TestGapRequest struct {
+	// Files are the package's source files; those named *_test.go are its
+	// tests
+	Files []testgap.File `json:"files"`
+	// Prompt adds to the instructions ECLIPSE is given
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// TestGap is a branch no test takes.
+type TestGap struct {
+	testgap.Branch
+	// Source is static or agent
+	Source string `json:"source"`
+	// Reason is how ECLIPSE would reach a branch it found
+	Reason string `json:"reason,omitempty"`
+}
+
+// TestGapResponse is the result of a test-gap analysis.
+type TestGapResponse struct {
+	Package string `json:"package"`
+	// Functions counts the package's functions, Reached those tests reach
+	Functions int       `json:"functions"`
+	Reached   int       `json:"reached"`
+	Gaps      []TestGap `json:"gaps"`
+	// Summary is ECLIPSE's reply without its gap lines
+	Summary string `json:"summary"`
+	// Artifacts hold the test skeletons for the gaps, and any code
+	// ECLIPSE wrote
+	Artifacts []models.Artifact `json:"artifacts"`
+	// Skipped are the generic functions left out of the skeletons
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// TestGaps handles POST /test-gaps - finds the branches of a Go package its
+// tests do not take and returns table-driven test skeletons for them.
+// Static analysis finds the functions no test reaches; ECLIPSE judges the
+// branches of the rest from the tests.
+func (h *Handler) TestGaps(w http.ResponseWriter, r *http.Request) {
+	var req TestGapRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxTestGapBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if len(req.Files) == 0 {
+		errdefs.WriteError(w, "files is required", http.StatusBadRequest)
+		return
+	}
+	analysis, err := testgap.Analyze(req.Files)
+	if err != nil {
+		errdefs.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	agent, res, err := h.registry.Resolve(testGapAgent)
+	if err != nil {
+		errdefs.WriteError(w, err.Error(), resolveStatus(err))
+		return
+	}
+	release, err := h.registry.Admit(r.Context(), agent)
+	if err != nil {
+		log.Printf("Request not admitted: %v", err)
+		status := errdefs.HTTPStatus(err)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", retryAfter(err))
+		}
+		if status == http.StatusInternalServerError {
+			errdefs.WriteError(w, "Error processing request", status)
+			return
+		}
+		errdefs.WriteError(w, err.Error(), status)
+		return
+	}
+	defer release()
+
+	resp, err := h.registry.Handle(r.Context(), agent, RouteTestGaps, &models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: testGapMessage(analysis, &req)}},
+	})
+	if err != nil {
+		log.Printf("Error handling test-gap request: %v", err)
+		errdefs.WriteError(w, "Error processing request", http.StatusBadGateway)
+		return
+	}
+
+	summary, findings := parseFindings(resp.Choices[0].Message.Content)
+	result := &TestGapResponse{
+		Package:   analysis.Package,
+		Functions: len(analysis.Functions),
+		Reached:   len(analysis.Reached()),
+		Gaps:      testGaps(analysis, findings),
+		Summary:   summary,
+		Artifacts: []models.Artifact{},
+	}
+	branches := make([]testgap.Branch, len(result.Gaps))
+	for i, gap := range result.Gaps {
+		branches[i] = gap.Branch
+	}
+	skeleton, skipped, err := analysis.Skeleton(branches)
+	if err != nil {
+		log.Printf("Error writing test skeletons for %s: %v", analysis.Package, err)
+	}
+	if skeleton != "" {
+		result.Artifacts = append(result.Artifacts, models.Artifact{Title: analysis.Package + "_gaps_test.go", Language: "go", Content: skeleton})
+	}
+	if answer := resp.Choices[0].Structured; answer != nil {
+		result.Artifacts = append(result.Artifacts, answer.Artifacts...)
+	}
+	result.Skipped = skipped
+	log.Printf("Test gaps: %s has %d untested branches (%s)", analysis.Package, len(result.Gaps), res.Codename)
+	errdefs.WriteJSON(w, http.StatusOK, result)
+}
+
+// testGaps returns the branches of unreached functions and those ECLIPSE
+// found in reached ones, in source order per kind. Findings that name no
+// line of a reached function are dropped.
+func testGaps(analysis *testgap.Analysis, findings []ActionsFinding) []TestGap {
+	gaps := []TestGap{}
+	seen := make(map[string]bool)
+	for _, branch := range analysis.Unreached() {
+		gaps = append(gaps, TestGap{Branch: branch, Source: GapStatic})
+		seen[fmt.Sprintf("%s:%d", branch.File, branch.Line)] = true
+	}
+	reached := make(map[string]bool)
+	for _, fn := range analysis.Reached() {
+		reached[fn.Name] = true
+	}
+	for _, finding := range findings {
+		key := fmt.Sprintf("%s:%d", finding.Path, finding.StartLine)
+		if finding.Path == "" || seen[key] {
+			continue
+		}
+		branch, ok := analysis.BranchAt(finding.Path, finding.StartLine)
+		if !ok || !reached[branch.Function] {
+			continue
+		}
+		seen[key] = true
+		gaps = append(gaps, TestGap{Branch: branch, Source: GapAgent, Reason: finding.Message})
+	}
+	return gaps
+}
+
+// testGapMessage builds ECLIPSE's prompt: what static analysis found, the
+// package's files with numbered lines, and how to report gaps.
+func testGapMessage(analysis *testgap.Analysis, req *TestGapRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Find the branches of package %s that its tests do not take. Static analysis found which functions the tests reach; judge the branches of those from the tests below.", analysis.Package)
+	if prompt := strings.TrimSpace(req.Prompt); prompt != "" {
+		b.WriteString("\n" + prompt)
+	}
+
+	b.WriteString("\n\nFunctions no test reaches, whose branches are all untested:")
+	for _, fn := range analysis.Functions {
+		if !fn.Reached {
+			fmt.Fprintf(&b, "\n- %s (%s:%d)", fn.Name, fn.File, fn.Line)
+		}
+	}
+	b.WriteString("\n\nBranches of functions the tests reach:")
+	for _, fn := range analysis.Reached() {
+		for _, branch := range fn.Branches {
+			fmt.Fprintf(&b, "\n- %s: %s:%d %s %s", fn.Name, branch.File, branch.Line, branch.Kind, branch.Condition)
+		}
+	}
+
+	b.WriteString("\n\nFiles:")
+	budget := maxTestGapSourceBytes
+	for _, file := range req.Files {
+		b.WriteString("\n--- " + file.Name)
+		if len(file.Content) > budget {
+			b.WriteString("\n(omitted)")
+			continue
+		}
+		budget -= len(file.Content)
+		for i, line := range strings.Split(strings.TrimRight(file.Content, "\n"), "\n") {
+			fmt.Fprintf(&b, "\n%d| %s", i+1, line)
+		}
+	}
+
+	b.WriteString("\n\n" + testGapInstructions)
+	return b.String()
+}
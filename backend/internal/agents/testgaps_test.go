@@ -0,0 +1,130 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/testgap"
+)
+
+const calcSource = `package calc
+
+import "errors"
+
+func Div(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("divide by zero")
+	}
+	return a / b, nil
+}
+
+func Abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+`
+
+const calcTest = `package calc
+
+import "testing"
+
+func TestDiv(t *testing.T) {
+	if got, _ := Div(4, 2); got != 2 {
+		t.Fatal(got)
+	}
+}
+`
+
+const eclipseGaps = `TestDiv never divides by zero.
+::warning file=calc.go,line=6,title=Div::Div(1, 0)
+::warning file=calc.go,line=13,title=Abs::Abs(-1)
+::warning file=calc.go,line=99::nowhere`
+
+// postTestGaps posts a test-gap request to a handler whose registry holds
+// the given agent and decodes the response.
+func postTestGaps(t *testing.T, agent *scriptedAgent, body interface{}) (int, *TestGapResponse, string) {
+	t.Helper()
+	registry := NewRegistry()
+	if agent != nil {
+		registry.Register(agent)
+	}
+	r := chi.NewRouter()
+	r.Post("/test-gaps", NewHandler(registry).TestGaps)
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/test-gaps", strings.NewReader(string(payload)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp TestGapResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, &resp, w.Body.String()
+}
+
+func TestTestGaps(t *testing.T) {
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: eclipseGaps}
+	status, resp, raw := postTestGaps(t, eclipse, TestGapRequest{
+		Files:  []testgap.File{{Name: "calc.go", Content: calcSource}, {Name: "calc_test.go", Content: calcTest}},
+		Prompt: "Mind the zero divisor.",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, raw)
+	}
+	if resp.Package != "calc" || resp.Functions != 2 || resp.Reached != 1 {
+		t.Errorf("expected Div of 2 functions reached, got %+v", resp)
+	}
+	if len(resp.Gaps) != 2 {
+		t.Fatalf("expected 2 gaps, got %+v", resp.Gaps)
+	}
+	if gap := resp.Gaps[0]; gap.Function != "Abs" || gap.Line != 13 || gap.Source != GapStatic {
+		t.Errorf("expected Abs's branch found statically, got %+v", gap)
+	}
+	if gap := resp.Gaps[1]; gap.Function != "Div" || gap.Line != 6 || gap.Condition != "b == 0" || gap.Source != GapAgent || gap.Reason != "Div(1, 0)" {
+		t.Errorf("expected Div's branch found by ECLIPSE, got %+v", gap)
+	}
+	if resp.Summary != "TestDiv never divides by zero." {
+		t.Errorf("expected the gap lines left out of the summary, got %q", resp.Summary)
+	}
+
+	if len(resp.Artifacts) != 1 || resp.Artifacts[0].Title != "calc_gaps_test.go" {
+		t.Fatalf("expected one skeleton artifact, got %+v", resp.Artifacts)
+	}
+	for _, want := range []string{"func TestDiv_gaps(t *testing.T)", "func TestAbs(t *testing.T)", "// calc.go:6", `{name: "if b == 0"}`} {
+		if !strings.Contains(resp.Artifacts[0].Content, want) {
+			t.Errorf("expected %q in the skeleton, got %s", want, resp.Artifacts[0].Content)
+		}
+	}
+
+	prompt, _ := eclipse.prompt.Load().(string)
+	for _, want := range []string{"Mind the zero divisor.", "- Abs (calc.go:12)", "- Div: calc.go:6 if b == 0", "--- calc_test.go", "6| \tif b == 0 {", "::warning file=PATH"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in the prompt, got %q", want, prompt)
+		}
+	}
+}
+
+func TestTestGapsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		agent  *scriptedAgent
+		body   interface{}
+		status int
+	}{
+		{"no files", &scriptedAgent{codename: "ECLIPSE"}, TestGapRequest{}, http.StatusBadRequest},
+		{"invalid source", &scriptedAgent{codename: "ECLIPSE"}, TestGapRequest{Files: []testgap.File{{Name: "calc.go", Content: "package calc\nfunc {"}}}, http.StatusBadRequest},
+		{"only tests", &scriptedAgent{codename: "ECLIPSE"}, TestGapRequest{Files: []testgap.File{{Name: "calc_test.go", Content: calcTest}}}, http.StatusBadRequest},
+		{"no ECLIPSE", nil, TestGapRequest{Files: []testgap.File{{Name: "calc.go", Content: calcSource}}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _, raw := postTestGaps(t, tt.agent, tt.body); status != tt.status {
+				t.Errorf("expected %d, got %d: %s", tt.status, status, raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// Drafter drafts answers fast, to stream while an agent works on its full
+// answer.
+type Drafter interface {
+	// Draft streams a brief answer to the conversation as agent, calling
+	// onDelta with each piece of text as it arrives. An error from onDelta
+	// stops the draft and is returned
+	Draft(ctx context.Context, agent models.Agent, req *models.CopilotRequest, onDelta func(string) error) error
+}
+
+// SetDrafter sets what drafts answers for streamed requests that ask for
+// a draft. Without it they get the full answer alone.
+func (h *Handler) SetDrafter(drafter Drafter) {
+	h.drafter = drafter
+}
+
+// wantsDraft reports whether a request is answered with a draft first.
+func (h *Handler) wantsDraft(req *models.CopilotRequest) bool {
+	return req.Stream && req.Draft && h.drafter != nil
+}
+
+// handled is the outcome of the full pipeline.
+type handled struct {
+	resp *models.CopilotResponse
+	err  error
+}
+
+// streamDrafted streams an answer in two stages. The draft stage streams
+// the drafter's answer as it arrives while the agent runs the full
+// pipeline; the refined stage, which replaces the draft, is the agent's
+// answer. The draft is cut short when the answer is ready first. A client
+// that leaves gets nothing more, and a failed pipeline leaves the draft as
+// the answer.
+func (h *Handler) streamDrafted(w http.ResponseWriter, r *http.Request, agent models.AgentHandler, route string, req *models.CopilotRequest) {
+	ctx := r.Context()
+	refined := make(chan handled, 1)
+	go func() {
+		resp, err := h.registry.Handle(ctx, agent, route, req)
+		refined <- handled{resp, err}
+	}()
+
+	sse := copilot.NewSSEWriter(w)
+	if sse == nil {
+		log.Printf("SSE streaming not supported, answering without a draft")
+		result := <-refined
+		if result.err != nil {
+			log.Printf("Error handling request: %v", result.err)
+			copilot.WriteError(w, "Error processing request", http.StatusInternalServerError)
+			return
+		}
+		if err := copilot.WriteResponse(w, result.resp); err != nil {
+			log.Printf("Error writing response: %v", err)
+		}
+		return
+	}
+	sse.Init()
+	sse.SetStage(copilot.StageDraft)
+
+	draftCtx, cancelDraft := context.WithCancel(ctx)
+	defer cancelDraft()
+	drafted := make(chan error, 1)
+	go func() {
+		if err := sse.WriteRole("assistant"); err != nil {
+			drafted <- err
+			return
+		}
+		drafted <- h.drafter.Draft(draftCtx, h.registry.Info(agent), req, sse.WriteChunk)
+	}()
+
+	var result handled
+	select {
+	case result = <-refined:
+		cancelDraft()
+		<-drafted
+	case err := <-drafted:
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error drafting answer: %v", err)
+		}
+		result = <-refined
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if result.err != nil {
+		log.Printf("Error handling request after its draft: %v", result.err)
+		if err := sse.WriteEnd(); err != nil {
+			log.Printf("Error writing streaming response: %v", err)
+		}
+		return
+	}
+
+	sse.SetStage(copilot.StageRefined)
+	if err := sse.WriteResponse(result.resp); err != nil {
+		log.Printf("Error writing streaming response: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ContextSource prepares context for an agent that does not depend on the
+// request it answers, such as what memory holds on its specialty.
+type ContextSource interface {
+	// Prepare returns system messages for the agent; nil when it has
+	// nothing to add
+	Prepare(ctx context.Context, agent models.Agent) ([]models.Message, error)
+}
+
+// AgentContext is the context an agent is invoked with ahead of the
+// request's own.
+type AgentContext struct {
+	// Agent is the persona the context was prepared for
+	Agent    models.Agent
+	Messages []models.Message
+	Built    time.Time
+}
+
+// WarmPool keeps the contexts agents are invoked with assembled ahead of
+// their invocations, so an invocation does not wait on its sources. Every
+// registered agent's context is rebuilt in the background each refresh
+// interval, and a context whose agent's persona has changed since is
+// rebuilt on its next invocation.
+type WarmPool struct {
+	registry *Registry
+	sources  []ContextSource
+	// refresh is how often contexts are rebuilt; zero keeps none, and
+	// assembles them on every invocation
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	contexts map[string]*AgentContext
+
+	// onAssemble is called with each context an invocation gets
+	onAssemble func(agent string, warm bool, duration time.Duration)
+}
+
+// NewWarmPool creates a pool of the contexts of a registry's agents,
+// prepared from sources and rebuilt every refresh interval once Run is
+// called. With a zero interval contexts are assembled on every invocation.
+func NewWarmPool(registry *Registry, refresh time.Duration, sources ...ContextSource) *WarmPool {
+	return &WarmPool{
+		registry: registry,
+		sources:  sources,
+		refresh:  refresh,
+		contexts: make(map[string]*AgentContext),
+	}
+}
+
+// OnAssemble sets a callback for each context an invocation gets,
+// reporting whether it came from the pool and how long getting it took.
+// Set before the pool is shared between goroutines.
+func (p *WarmPool) OnAssemble(fn func(agent string, warm bool, duration time.Duration)) {
+	p.onAssemble = fn
+}
+
+// SetWarmPool sets the pool of contexts added to requests ahead of the
+// user's preferences. Set before the registry is shared between
+// goroutines.
+func (r *Registry) SetWarmPool(pool *WarmPool) {
+	r.warm = pool
+}
+
+// Context returns the context messages of an agent: the pooled ones when
+// they were prepared for the agent's persona as it is, or ones assembled
+// now and pooled for the agent's next invocations.
+func (p *WarmPool) Context(ctx context.Context, agent models.Agent) []models.Message {
+	start := time.Now()
+	p.mu.RLock()
+	pooled, ok := p.contexts[agent.Codename]
+	p.mu.RUnlock()
+	warm := ok && reflect.DeepEqual(pooled.Agent, agent)
+	if !warm {
+		pooled = p.build(ctx, agent)
+		if p.refresh > 0 {
+			p.mu.Lock()
+			p.contexts[agent.Codename] = pooled
+			p.mu.Unlock()
+		}
+	}
+	if p.onAssemble != nil {
+		p.onAssemble(agent.Codename, warm, time.Since(start))
+	}
+	return pooled.Messages
+}
+
+// Refresh rebuilds the context of every registered agent, replacing the
+// pool once all are built so invocations meanwhile get the old ones.
+// Without a refresh interval nothing is pooled.
+func (p *WarmPool) Refresh(ctx context.Context) error {
+	if p.refresh <= 0 {
+		return nil
+	}
+	p.registry.mu.RLock()
+	agents := make([]models.Agent, 0, len(p.registry.agents))
+	for _, handler := range p.registry.agents {
+		agents = append(agents, handler.GetInfo())
+	}
+	p.registry.mu.RUnlock()
+
+	contexts := make(map[string]*AgentContext, len(agents))
+	for _, agent := range agents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		contexts[agent.Codename] = p.build(ctx, agent)
+	}
+	p.mu.Lock()
+	p.contexts = contexts
+	p.mu.Unlock()
+	return nil
+}
+
+// Run fills the pool and refreshes it every refresh interval until ctx is
+// done. Without an interval it returns at once.
+func (p *WarmPool) Run(ctx context.Context) {
+	if p.refresh <= 0 {
+		return
+	}
+	ticker := time.NewTicker(p.refresh)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if err := p.Refresh(ctx); err != nil {
+			return
+		}
+		log.Printf("Agent contexts rebuilt in %s", time.Since(start).Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// build prepares an agent's context from every source. A source that
+// fails is left out of the context and logged.
+func (p *WarmPool) build(ctx context.Context, agent models.Agent) *AgentContext {
+	built := &AgentContext{Agent: agent, Built: time.Now()}
+	for _, source := range p.sources {
+		messages, err := source.Prepare(ctx, agent)
+		if err != nil {
+			log.Printf("Preparing context of %s without a source: %v", agent.Codename, err)
+			continue
+		}
+		built.Messages = append(built.Messages, messages...)
+	}
+	return built
+}
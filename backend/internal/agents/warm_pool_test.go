@@ -0,0 +1,125 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// countingSource prepares a message naming the agent's specialty after a
+// delay, counting the agents it prepared for.
+type countingSource struct {
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (s *countingSource) Prepare(ctx context.Context, agent models.Agent) ([]models.Message, error) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+	return []models.Message{{Role: "system", Content: agent.Codename + " knows " + agent.Specialty}}, nil
+}
+
+// failingSource fails to prepare every context.
+type failingSource struct{}
+
+func (failingSource) Prepare(ctx context.Context, agent models.Agent) ([]models.Message, error) {
+	return nil, errors.New("graph unavailable")
+}
+
+// personaAgent is a recording agent whose persona can be changed.
+type personaAgent struct {
+	recordingAgent
+	info models.Agent
+}
+
+func (a *personaAgent) GetInfo() models.Agent {
+	return a.info
+}
+
+func TestWarmPool(t *testing.T) {
+	registry := NewRegistry()
+	agent := &personaAgent{
+		recordingAgent: recordingAgent{scriptedAgent: scriptedAgent{codename: "CIPHER", reply: "done"}},
+		info:           models.Agent{Codename: "CIPHER", Specialty: "cryptography"},
+	}
+	registry.Register(agent)
+	source := &countingSource{}
+	pool := NewWarmPool(registry, time.Minute, source, failingSource{})
+	var assembled []bool
+	pool.OnAssemble(func(codename string, warm bool, duration time.Duration) {
+		assembled = append(assembled, warm)
+	})
+	registry.SetWarmPool(pool)
+	if err := pool.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	req := &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "review this cipher"}}}
+	if _, err := registry.Handle(context.Background(), agent, RouteDirect, req); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if got := agent.last.Messages; len(got) != 2 || got[0].Content != "CIPHER knows cryptography" {
+		t.Errorf("expected the pooled context ahead of the query, got %+v", got)
+	}
+	if source.calls.Load() != 1 || len(assembled) != 1 || !assembled[0] {
+		t.Errorf("expected the pooled context used, got %d builds and %v", source.calls.Load(), assembled)
+	}
+
+	// A changed persona is prepared again, and pooled for the next request
+	agent.info.Specialty = "post-quantum cryptography"
+	registry.Handle(context.Background(), agent, RouteDirect, req)
+	registry.Handle(context.Background(), agent, RouteDirect, req)
+	if got := agent.last.Messages[0].Content; got != "CIPHER knows post-quantum cryptography" {
+		t.Errorf("expected the context of the new persona, got %q", got)
+	}
+	if source.calls.Load() != 2 || len(assembled) != 3 || assembled[1] || !assembled[2] {
+		t.Errorf("expected one cold build for the new persona, got %d builds and %v", source.calls.Load(), assembled)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.Refresh(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled refresh to fail, got %v", err)
+	}
+}
+
+func TestWarmPoolDisabled(t *testing.T) {
+	registry := NewRegistry()
+	source := &countingSource{}
+	pool := NewWarmPool(registry, 0, source)
+	agent := models.Agent{Codename: "APEX"}
+	pool.Refresh(context.Background())
+	for i := 0; i < 3; i++ {
+		if got := pool.Context(context.Background(), agent); len(got) != 1 {
+			t.Fatalf("expected the context assembled, got %+v", got)
+		}
+	}
+	if source.calls.Load() != 3 {
+		t.Errorf("expected a context assembled on every invocation without a refresh interval, got %d", source.calls.Load())
+	}
+}
+
+// BenchmarkWarmPool compares getting an agent's context from the pool with
+// assembling it from a source that takes a millisecond.
+func BenchmarkWarmPool(b *testing.B) {
+	registry := NewRegistry()
+	registry.Register(&scriptedAgent{codename: "APEX"})
+	agent := models.Agent{Codename: "APEX", Tier: 1}
+	for _, bench := range []struct {
+		name    string
+		refresh time.Duration
+	}{{"cold", 0}, {"warm", time.Minute}} {
+		b.Run(bench.name, func(b *testing.B) {
+			pool := NewWarmPool(registry, bench.refresh, &countingSource{delay: time.Millisecond})
+			pool.Refresh(context.Background())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pool.Context(context.Background(), agent)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrWorkflowNotFound is returned when no workflow has a name
+	ErrWorkflowNotFound = errdefs.New(errdefs.ErrNotFound, "workflow not found")
+	// ErrInvalidWorkflow is returned for definitions that fail validation
+	ErrInvalidWorkflow = errdefs.New(errdefs.ErrInvalidArgument, "invalid workflow")
+)
+
+// workflowRefPattern matches {{inputs.NAME}} and {{steps.ID.output}}
+// references in step prompts.
+var workflowRefPattern = regexp.MustCompile(`\{\{\s*(?:inputs\.([\w-]+)|steps\.([\w-]+)\.output)\s*\}\}`)
+
+// workflowIDPattern matches workflow names and step IDs.
+var workflowIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// WorkflowDefinition is a multi-agent flow defined in YAML. Steps run in
+// order; a step with a parallel group runs its members concurrently.
+type WorkflowDefinition struct {
+	Name        string          `yaml:"name" json:"name"`
+	Description string          `yaml:"description" json:"description"`
+	Inputs      []WorkflowInput `yaml:"inputs" json:"inputs"`
+	Steps       []WorkflowStep  `yaml:"steps" json:"steps"`
+}
+
+// WorkflowInput is a value supplied when a workflow is run.
+type WorkflowInput struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	// Required inputs must be supplied unless they have a default
+	Required bool   `yaml:"required" json:"required"`
+	Default  string `yaml:"default" json:"default,omitempty"`
+}
+
+// WorkflowStep invokes one agent, or runs a parallel group of steps.
+type WorkflowStep struct {
+	ID string `yaml:"id" json:"id,omitempty"`
+	// Agent is the codename of the agent to invoke
+	Agent string `yaml:"agent" json:"agent,omitempty"`
+	// Prompt is sent to the agent after {{inputs.NAME}} and
+	// {{steps.ID.output}} references are filled in
+	Prompt string `yaml:"prompt" json:"prompt,omitempty"`
+	// When skips the step unless the condition on a prior step holds
+	When *StepCondition `yaml:"when" json:"when,omitempty"`
+	// ContinueOnError lets the workflow go on when the step fails
+	ContinueOnError bool `yaml:"continue_on_error" json:"continue_on_error,omitempty"`
+	// Parallel makes the step a group whose members run concurrently. A
+	// group's ID is optional; its output joins its members' outputs
+	Parallel []WorkflowStep `yaml:"parallel" json:"parallel,omitempty"`
+	// Compensate undoes the step's effect if it succeeded but the run
+	// later fails
+	Compensate *Compensation `yaml:"compensate" json:"compensate,omitempty"`
+	// Graph adds the knowledge graph records in the agent's reply to the
+	// knowledge graph; the step's output is then the part of the graph
+	// they name
+	Graph bool `yaml:"graph" json:"graph,omitempty"`
+}
+
+// Compensation is the action that undoes a step, such as reverting a
+// draft comment. Its prompt can refer to the step's own output.
+type Compensation struct {
+	// Agent defaults to the step's agent
+	Agent  string `yaml:"agent" json:"agent,omitempty"`
+	Prompt string `yaml:"prompt" json:"prompt"`
+}
+
+// StepCondition tests the result of a prior step. Every test that is set
+// must hold.
+type StepCondition struct {
+	Step string `yaml:"step" json:"step"`
+	// Status the step must have ended with; defaults to succeeded
+	Status StepStatus `yaml:"status" json:"status,omitempty"`
+	// Contains and NotContains test the step's output, ignoring case
+	Contains    string `yaml:"contains" json:"contains,omitempty"`
+	NotContains string `yaml:"not_contains" json:"not_contains,omitempty"`
+}
+
+// ParseWorkflow decodes a workflow definition. Unknown fields are errors,
+// so misspelled keys are caught at load time.
+func ParseWorkflow(data []byte) (*WorkflowDefinition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var def WorkflowDefinition
+	if err := decoder.Decode(&def); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
+	}
+	return &def, nil
+}
+
+// Validate checks a definition's structure and references, and that its
+// agents are in the registry. It reports every problem found.
+func (d *WorkflowDefinition) Validate(registry *Registry) error {
+	v := &workflowValidator{
+		registry: registry,
+		inputs:   make(map[string]bool),
+		steps:    make(map[string]bool),
+	}
+	if !workflowIDPattern.MatchString(d.Name) {
+		v.fail("name %q must be lowercase letters, digits, '-' or '_'", d.Name)
+	}
+	for _, input := range d.Inputs {
+		if input.Name == "" || v.inputs[input.Name] {
+			v.fail("input %q is empty or duplicated", input.Name)
+		}
+		v.inputs[input.Name] = true
+	}
+	if len(d.Steps) == 0 {
+		v.fail("no steps")
+	}
+	for i := range d.Steps {
+		v.step(&d.Steps[i], false)
+	}
+	if len(v.problems) > 0 {
+		return fmt.Errorf("%w %s: %w", ErrInvalidWorkflow, d.Name, errors.Join(v.problems...))
+	}
+	return nil
+}
+
+// workflowValidator accumulates a definition's problems.
+type workflowValidator struct {
+	registry *Registry
+	inputs   map[string]bool
+	// steps holds the step IDs declared so far
+	steps    map[string]bool
+	problems []error
+}
+
+func (v *workflowValidator) fail(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Errorf(format, args...))
+}
+
+// step checks a step against the steps declared before it. Members of a
+// parallel group can't refer to each other, so they are declared only
+// once the whole group is checked.
+func (v *workflowValidator) step(s *WorkflowStep, inGroup bool) {
+	if s.When != nil {
+		v.condition(s)
+	}
+
+	if len(s.Parallel) > 0 {
+		if inGroup {
+			v.fail("step %q: parallel groups can't be nested", s.ID)
+		}
+		if s.Agent != "" || s.Prompt != "" {
+			v.fail("step %q: a parallel group has no agent or prompt", s.ID)
+		}
+		if s.Compensate != nil {
+			v.fail("step %q: a parallel group can't be compensated; compensate its members", s.ID)
+		}
+		if s.Graph {
+			v.fail("step %q: a parallel group has no reply to add to the graph; set graph on its members", s.ID)
+		}
+		for i := range s.Parallel {
+			v.step(&s.Parallel[i], true)
+		}
+		for _, member := range s.Parallel {
+			v.declare(member.ID)
+		}
+		if s.ID != "" {
+			v.declare(s.ID)
+		}
+		return
+	}
+
+	if !workflowIDPattern.MatchString(s.ID) {
+		v.fail("step id %q must be lowercase letters, digits, '-' or '_'", s.ID)
+	}
+	if s.Agent == "" || s.Prompt == "" {
+		v.fail("step %q: agent and prompt are required", s.ID)
+	} else {
+		s.Agent = strings.ToUpper(s.Agent)
+		if _, _, err := v.registry.Resolve(s.Agent); err != nil {
+			v.fail("step %q: %v", s.ID, err)
+		}
+	}
+	v.refs(s.ID, s.Prompt, "")
+	if s.Compensate != nil {
+		v.compensation(s)
+	}
+	if !inGroup {
+		v.declare(s.ID)
+	}
+}
+
+// refs checks a prompt's references against the inputs and the steps
+// declared so far, plus self if set.
+func (v *workflowValidator) refs(id, prompt, self string) {
+	for _, ref := range workflowRefPattern.FindAllStringSubmatch(prompt, -1) {
+		if input := ref[1]; input != "" && !v.inputs[input] {
+			v.fail("step %q: prompt refers to undeclared input %q", id, input)
+		}
+		if step := ref[2]; step != "" && step != self && !v.steps[step] {
+			v.fail("step %q: prompt refers to step %q, which does not run before it", id, step)
+		}
+	}
+}
+
+// compensation checks a step's compensation. It runs after the step, so
+// it can refer to the step's own output.
+func (v *workflowValidator) compensation(s *WorkflowStep) {
+	c := s.Compensate
+	if c.Prompt == "" {
+		v.fail("step %q: compensation prompt is required", s.ID)
+	}
+	if c.Agent == "" {
+		c.Agent = s.Agent
+	} else {
+		c.Agent = strings.ToUpper(c.Agent)
+		if _, _, err := v.registry.Resolve(c.Agent); err != nil {
+			v.fail("step %q: compensation: %v", s.ID, err)
+		}
+	}
+	v.refs(s.ID, c.Prompt, s.ID)
+}
+
+// condition checks a step's condition.
+func (v *workflowValidator) condition(s *WorkflowStep) {
+	if !v.steps[s.When.Step] {
+		v.fail("step %q: condition refers to step %q, which does not run before it", s.ID, s.When.Step)
+	}
+	switch s.When.Status {
+	case "", StepSucceeded, StepFailed, StepSkipped:
+	default:
+		v.fail("step %q: unknown condition status %q", s.ID, s.When.Status)
+	}
+}
+
+// declare records a step ID, rejecting duplicates.
+func (v *workflowValidator) declare(id string) {
+	if v.steps[id] {
+		v.fail("step id %q is duplicated", id)
+	}
+	v.steps[id] = true
+}
+
+// ============================================================================
+// Workflow Engine
+// ============================================================================
+
+// WorkflowGraph adds the knowledge graph records in a text to the
+// knowledge graph, and returns the part of the graph they name.
+type WorkflowGraph interface {
+	IngestText(ctx context.Context, text string) (string, error)
+}
+
+// WorkflowEngine holds the loaded workflow definitions and runs them
+// against a registry's agents.
+type WorkflowEngine struct {
+	registry *Registry
+	// store keeps run state; set before the engine is shared
+	store *WorkflowStore
+	// graph takes the replies of graph steps; nil fails them
+	graph WorkflowGraph
+
+	mu        sync.RWMutex
+	workflows map[string]*WorkflowDefinition
+	// dir is the directory Reload reads definitions from
+	dir string
+	// done holds a channel per run in progress, closed when it finishes
+	done map[string]chan struct{}
+	// onRunFinished is called with each run that finishes
+	onRunFinished func(*WorkflowRun)
+}
+
+// NewWorkflowEngine creates an engine with no workflows, keeping run
+// state in memory.
+func NewWorkflowEngine(registry *Registry) *WorkflowEngine {
+	return &WorkflowEngine{
+		registry:  registry,
+		store:     NewWorkflowStore(),
+		workflows: make(map[string]*WorkflowDefinition),
+		done:      make(map[string]chan struct{}),
+	}
+}
+
+// SetStore sets where run state is kept. Set before the engine is shared,
+// then call Resume to continue the store's unfinished runs.
+func (e *WorkflowEngine) SetStore(store *WorkflowStore) {
+	e.store = store
+}
+
+// SetGraph sets the knowledge graph graph steps add to. Set before the
+// engine is shared.
+func (e *WorkflowEngine) SetGraph(graph WorkflowGraph) {
+	e.graph = graph
+}
+
+// OnRunFinished sets a callback for runs that finish, called from the
+// run's goroutine with a copy of the run.
+func (e *WorkflowEngine) OnRunFinished(fn func(*WorkflowRun)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onRunFinished = fn
+}
+
+// Register validates a definition and adds it, replacing any workflow of
+// the same name.
+func (e *WorkflowEngine) Register(def *WorkflowDefinition) error {
+	if err := def.Validate(e.registry); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.workflows[def.Name] = def
+	return nil
+}
+
+// LoadDir replaces the engine's workflows with the *.yaml and *.yml files
+// in dir, returning how many were loaded. If any file is invalid nothing
+// is replaced, so a bad edit can't take down working flows.
+func (e *WorkflowEngine) LoadDir(dir string) (int, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return 0, fmt.Errorf("failed to read workflow directory: %w", err)
+	}
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return 0, err
+		}
+		paths = append(paths, matches...)
+	}
+
+	workflows := make(map[string]*WorkflowDefinition, len(paths))
+	var problems []error
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		def, err := ParseWorkflow(data)
+		if err == nil {
+			err = def.Validate(e.registry)
+		}
+		if err == nil && workflows[def.Name] != nil {
+			err = fmt.Errorf("%w %s: name is used by another file", ErrInvalidWorkflow, def.Name)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		workflows[def.Name] = def
+	}
+	if len(problems) > 0 {
+		return 0, errors.Join(problems...)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.workflows = workflows
+	e.dir = dir
+	return len(workflows), nil
+}
+
+// Reload re-reads the directory last passed to LoadDir.
+func (e *WorkflowEngine) Reload() (int, error) {
+	e.mu.RLock()
+	dir := e.dir
+	e.mu.RUnlock()
+	if dir == "" {
+		return 0, errors.New("no workflow directory is configured")
+	}
+	return e.LoadDir(dir)
+}
+
+// Get returns a workflow by name.
+func (e *WorkflowEngine) Get(name string) (*WorkflowDefinition, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	def, ok := e.workflows[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, name)
+	}
+	return def, nil
+}
+
+// List returns all workflows, by name.
+func (e *WorkflowEngine) List() []*WorkflowDefinition {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	workflows := make([]*WorkflowDefinition, 0, len(e.workflows))
+	for _, def := range e.workflows {
+		workflows = append(workflows, def)
+	}
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].Name < workflows[j].Name })
+	return workflows
+}
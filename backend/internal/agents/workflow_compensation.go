@@ -0,0 +1,69 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import "context"
+
+// compensate undoes the succeeded steps that have a compensation, last
+// step first, so each compensation runs while the steps before it still
+// stand. Members of a parallel group are undone in reverse definition
+// order. Every compensation is attempted even if one fails, and those
+// that finished before a restart are not run again.
+func (e *WorkflowEngine) compensate(ctx context.Context, state *workflowState) {
+	steps := compensable(state.record.Definition.Steps)
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if result, ok := state.result(step.ID); !ok || result.Status != StepSucceeded {
+			continue
+		}
+		previous, ok := state.compensation(step.ID)
+		if ok && previous.Status != StepRunning {
+			continue
+		}
+		undo := WorkflowStep{ID: step.ID, Agent: step.Compensate.Agent, Prompt: step.Compensate.Prompt}
+		key := state.record.Run.ID + "/" + step.ID + "/compensate"
+		e.attempt(ctx, undo, key, previous, state, state.putCompensation)
+	}
+}
+
+// compensable returns the agent steps with a compensation, in definition
+// order.
+func compensable(steps []WorkflowStep) []WorkflowStep {
+	var found []WorkflowStep
+	for _, step := range steps {
+		if len(step.Parallel) > 0 {
+			found = append(found, compensable(step.Parallel)...)
+		} else if step.Compensate != nil {
+			found = append(found, step)
+		}
+	}
+	return found
+}
+
+// compensation returns the latest compensation result of a step.
+func (s *workflowState) compensation(id string) (StepResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range s.record.Run.Compensations {
+		if result.ID == id {
+			return result, true
+		}
+	}
+	return StepResult{}, false
+}
+
+// putCompensation saves a compensation result, replacing any earlier one
+// for the step. New results are appended, keeping the order they ran in.
+func (s *workflowState) putCompensation(result StepResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.record.Run
+	for i := range run.Compensations {
+		if run.Compensations[i].ID == result.ID {
+			run.Compensations[i] = result
+			s.save()
+			return
+		}
+	}
+	run.Compensations = append(run.Compensations, result)
+	s.save()
+}
@@ -0,0 +1,223 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const compensatedWorkflowYAML = `
+name: "publish-review"
+steps:
+  - id: "draft"
+    agent: "SCRIBE"
+    prompt: "Draft a review comment"
+    compensate:
+      prompt: "Delete comment {{steps.draft.output}}"
+  - parallel:
+      - id: "label"
+        agent: "HELIX"
+        prompt: "Label the PR"
+        compensate:
+          agent: "ROLLBACK"
+          prompt: "Remove label {{steps.label.output}}"
+      - id: "notify"
+        agent: "NEXUS"
+        prompt: "Notify reviewers"
+  - id: "publish"
+    agent: "APEX"
+    prompt: "Publish {{steps.draft.output}}"
+`
+
+// setupCompensatedEngine registers scripted agents and the compensated
+// test workflow.
+func setupCompensatedEngine(t *testing.T, agents ...*scriptedAgent) *WorkflowEngine {
+	t.Helper()
+	registry := NewRegistry()
+	for _, agent := range agents {
+		registry.Register(agent)
+	}
+	engine := NewWorkflowEngine(registry)
+	def, err := ParseWorkflow([]byte(compensatedWorkflowYAML))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+	if err := engine.Register(def); err != nil {
+		t.Fatalf("failed to register workflow: %v", err)
+	}
+	return engine
+}
+
+func TestWorkflowCompensation(t *testing.T) {
+	scribe := &scriptedAgent{codename: "SCRIBE", reply: "comment-7"}
+	rollback := &scriptedAgent{codename: "ROLLBACK", reply: "removed"}
+	engine := setupCompensatedEngine(t,
+		scribe,
+		&scriptedAgent{codename: "HELIX", reply: "needs-review"},
+		&scriptedAgent{codename: "NEXUS", reply: "notified"},
+		&scriptedAgent{codename: "APEX", fail: true},
+		rollback,
+	)
+
+	run, err := engine.Run(context.Background(), "publish-review", nil)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepFailed || run.Compensation != StepSucceeded {
+		t.Fatalf("expected failed run with succeeded compensation, got %s and %q", run.Status, run.Compensation)
+	}
+
+	// Later steps are undone first; steps without a compensation are left
+	if len(run.Compensations) != 2 || run.Compensations[0].ID != "label" || run.Compensations[1].ID != "draft" {
+		t.Fatalf("expected label then draft compensated, got %+v", run.Compensations)
+	}
+	if run.Compensations[0].Agent != "ROLLBACK" || run.Compensations[1].Agent != "SCRIBE" {
+		t.Errorf("expected compensation agents ROLLBACK and SCRIBE, got %+v", run.Compensations)
+	}
+	if got := scribe.prompt.Load(); got != "Delete comment comment-7" {
+		t.Errorf("expected step output in compensation prompt, got %q", got)
+	}
+	if got := rollback.prompt.Load(); got != "Remove label needs-review" {
+		t.Errorf("expected step output in compensation prompt, got %q", got)
+	}
+	if key := run.Compensations[1].IdempotencyKey; key != run.ID+"/draft/compensate" {
+		t.Errorf("expected compensation idempotency key, got %q", key)
+	}
+}
+
+func TestWorkflowCompensationFailure(t *testing.T) {
+	scribe := &scriptedAgent{codename: "SCRIBE", reply: "comment-7"}
+	engine := setupCompensatedEngine(t,
+		scribe,
+		&scriptedAgent{codename: "HELIX", reply: "needs-review"},
+		&scriptedAgent{codename: "NEXUS", reply: "notified"},
+		&scriptedAgent{codename: "APEX", fail: true},
+		&scriptedAgent{codename: "ROLLBACK", fail: true},
+	)
+
+	run, err := engine.Run(context.Background(), "publish-review", nil)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Compensation != StepFailed {
+		t.Errorf("expected compensation failed, got %q", run.Compensation)
+	}
+	// A failed compensation doesn't stop the ones after it
+	if len(run.Compensations) != 2 || run.Compensations[0].Status != StepFailed || run.Compensations[1].Status != StepSucceeded {
+		t.Errorf("expected label compensation failed and draft compensated, got %+v", run.Compensations)
+	}
+	if scribe.calls.Load() != 2 {
+		t.Errorf("expected draft and its compensation, got %d calls", scribe.calls.Load())
+	}
+}
+
+func TestWorkflowCompensationResume(t *testing.T) {
+	scribe := &scriptedAgent{codename: "SCRIBE", reply: "comment-7"}
+	rollback := &scriptedAgent{codename: "ROLLBACK", reply: "removed"}
+	apex := &scriptedAgent{codename: "APEX", fail: true}
+	engine := setupCompensatedEngine(t,
+		scribe,
+		&scriptedAgent{codename: "HELIX", reply: "needs-review"},
+		&scriptedAgent{codename: "NEXUS", reply: "notified"},
+		apex,
+		rollback,
+	)
+	def, _ := engine.Get("publish-review")
+
+	// The server went down while the label was being removed
+	now := time.Now()
+	record := &workflowRecord{
+		Run: &WorkflowRun{
+			ID:        newRunID(),
+			Workflow:  "publish-review",
+			Status:    StepRunning,
+			StartedAt: now,
+			UpdatedAt: now,
+			Steps: []StepResult{
+				{ID: "draft", Agent: "SCRIBE", Status: StepSucceeded, Output: "comment-7", Attempts: 1},
+				{ID: "label", Agent: "HELIX", Status: StepSucceeded, Output: "needs-review", Attempts: 1},
+				{ID: "notify", Agent: "NEXUS", Status: StepSucceeded, Output: "notified", Attempts: 1},
+				{ID: "publish", Agent: "APEX", Status: StepFailed, Error: "APEX is unavailable", Attempts: 1},
+			},
+			Compensations: []StepResult{
+				{ID: "label", Agent: "ROLLBACK", Status: StepRunning, Attempts: 1},
+			},
+		},
+		Definition: def,
+	}
+	if err := engine.store.save(record); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	if resumed := engine.Resume(); resumed != 1 {
+		t.Fatalf("expected 1 run resumed, got %d", resumed)
+	}
+	run := waitForRun(t, engine, record.Run.ID)
+
+	if run.Status != StepFailed || run.Compensation != StepSucceeded || len(run.Compensations) != 2 {
+		t.Fatalf("expected compensation finished after resume, got %+v", run)
+	}
+	if run.Compensations[0].Attempts != 2 || run.Compensations[1].ID != "draft" {
+		t.Errorf("expected label compensation retried, then draft, got %+v", run.Compensations)
+	}
+	if apex.calls.Load() != 0 || scribe.calls.Load() != 1 || rollback.calls.Load() != 1 {
+		t.Errorf("expected only the compensations to run, got %d, %d and %d calls", apex.calls.Load(), scribe.calls.Load(), rollback.calls.Load())
+	}
+}
+
+func TestWorkflowCompensationNotRunOnSuccess(t *testing.T) {
+	rollback := &scriptedAgent{codename: "ROLLBACK", reply: "removed"}
+	engine := setupCompensatedEngine(t,
+		&scriptedAgent{codename: "SCRIBE", reply: "comment-7"},
+		&scriptedAgent{codename: "HELIX", reply: "needs-review"},
+		&scriptedAgent{codename: "NEXUS", reply: "notified"},
+		&scriptedAgent{codename: "APEX", reply: "published"},
+		rollback,
+	)
+
+	run, err := engine.Run(context.Background(), "publish-review", nil)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepSucceeded || len(run.Compensations) != 0 || run.Compensation != "" || rollback.calls.Load() != 0 {
+		t.Errorf("expected no compensation for a succeeded run, got %+v", run)
+	}
+}
+
+func TestWorkflowCompensationValidate(t *testing.T) {
+	registry := DefaultRegistry()
+	tests := []struct {
+		name    string
+		yaml    string
+		problem string
+	}{
+		{"group", "name: w\nsteps: [{id: g, compensate: {prompt: p}, parallel: [{id: a, agent: APEX, prompt: p}]}]", "can't be compensated"},
+		{"no prompt", "name: w\nsteps: [{id: a, agent: APEX, prompt: p, compensate: {agent: APEX}}]", "compensation prompt is required"},
+		{"unknown agent", "name: w\nsteps: [{id: a, agent: APEX, prompt: p, compensate: {agent: NOBODY, prompt: p}}]", "compensation: agent not found"},
+		{"later step", "name: w\nsteps: [{id: a, agent: APEX, prompt: p, compensate: {prompt: '{{steps.b.output}}'}}, {id: b, agent: APEX, prompt: p}]", `step "b", which does not run before it`},
+	}
+
+	for _, tt := range tests {
+		def, err := ParseWorkflow([]byte(tt.yaml))
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tt.name, err)
+		}
+		err = def.Validate(registry)
+		if !errors.Is(err, ErrInvalidWorkflow) || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+
+	def, err := ParseWorkflow([]byte("name: w\nsteps: [{id: a, agent: apex, prompt: p, compensate: {prompt: 'Undo {{steps.a.output}}'}}]"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if err := def.Validate(registry); err != nil {
+		t.Fatalf("expected valid workflow, got %v", err)
+	}
+	if def.Steps[0].Compensate.Agent != "APEX" {
+		t.Errorf("expected compensation agent to default to the step's, got %q", def.Steps[0].Compensate.Agent)
+	}
+}
@@ -0,0 +1,547 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ErrWorkflowInput is returned when a run is missing a required input or
+// given an undeclared one.
+var ErrWorkflowInput = errdefs.New(errdefs.ErrInvalidArgument, "invalid workflow input")
+
+// StepStatus is the state of a workflow step or run.
+type StepStatus string
+
+const (
+	// StepRunning steps and runs have not finished
+	StepRunning StepStatus = "running"
+	// StepSucceeded steps returned a response
+	StepSucceeded StepStatus = "succeeded"
+	// StepFailed steps could not be routed or returned an error
+	StepFailed StepStatus = "failed"
+	// StepSkipped steps had a condition that did not hold, or came after
+	// a failure
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepResult is the outcome of one step of a run.
+type StepResult struct {
+	ID     string     `json:"id"`
+	Agent  string     `json:"agent,omitempty"`
+	Status StepStatus `json:"status"`
+	Output string     `json:"output,omitempty"`
+	Error  string     `json:"error,omitempty"`
+	// DurationMs is how long the agent took, including quota waits
+	DurationMs int64 `json:"duration_ms"`
+	// IdempotencyKey is passed to the agent, the same on every attempt
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Attempts counts invocations; more than one means the step was
+	// interrupted and retried after a restart
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// WorkflowRun is the state of a workflow run.
+type WorkflowRun struct {
+	ID       string `json:"id"`
+	Workflow string `json:"workflow"`
+	// Status is running until the run finishes, then failed if a step
+	// failed without continue_on_error
+	Status StepStatus   `json:"status"`
+	Steps  []StepResult `json:"steps"`
+	// Output is the output of the last step that succeeded
+	Output     string `json:"output"`
+	DurationMs int64  `json:"duration_ms"`
+	// IdempotencyKey is the client's key; retries with it get this run
+	IdempotencyKey string    `json:"idempotency_key,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Resumes counts restarts the run survived
+	Resumes int `json:"resumes,omitempty"`
+	// Compensations are the results of undoing succeeded steps after the
+	// run failed, in the order they ran; each has the ID of its step
+	Compensations []StepResult `json:"compensations,omitempty"`
+	// Compensation is succeeded if every compensation succeeded and
+	// failed otherwise; empty if none ran
+	Compensation StepStatus `json:"compensation,omitempty"`
+}
+
+// Run runs a workflow with the given inputs and waits for it to finish.
+// See RunWithKey.
+func (e *WorkflowEngine) Run(ctx context.Context, name string, inputs map[string]string) (*WorkflowRun, error) {
+	return e.RunWithKey(ctx, name, inputs, "")
+}
+
+// RunWithKey runs a workflow and waits until it finishes or ctx is done.
+// The run goes on in the background after ctx is done, with its state
+// saved after every step; the returned run then has status running. A
+// non-empty key makes the call idempotent: retrying with the key returns
+// the run it started instead of starting another. Input errors wrap
+// ErrWorkflowInput; step failures are reported in the run, not as errors.
+func (e *WorkflowEngine) RunWithKey(ctx context.Context, name string, inputs map[string]string, key string) (*WorkflowRun, error) {
+	def, err := e.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.run(ctx, def, inputs, key)
+}
+
+// RunDefinition validates a definition that is not registered, such as
+// one planned for a single request, and runs it as RunWithKey does. Its
+// runs are kept and resumed like those of registered workflows.
+func (e *WorkflowEngine) RunDefinition(ctx context.Context, def *WorkflowDefinition, inputs map[string]string, key string) (*WorkflowRun, error) {
+	if err := def.Validate(e.registry); err != nil {
+		return nil, err
+	}
+	return e.run(ctx, def, inputs, key)
+}
+
+// run starts a run of a definition and waits for it as RunWithKey
+// describes.
+func (e *WorkflowEngine) run(ctx context.Context, def *WorkflowDefinition, inputs map[string]string, key string) (*WorkflowRun, error) {
+	values, err := def.resolveInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &workflowRecord{
+		Run: &WorkflowRun{
+			ID:             newRunID(),
+			Workflow:       def.Name,
+			Status:         StepRunning,
+			IdempotencyKey: key,
+			StartedAt:      now,
+			UpdatedAt:      now,
+		},
+		Definition: def,
+		Inputs:     values,
+		Groups:     make(map[string]StepResult),
+	}
+
+	e.mu.Lock()
+	existing, err := e.store.create(record)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+	id := record.Run.ID
+	if existing != nil {
+		if existing.Run.Workflow != def.Name {
+			e.mu.Unlock()
+			return nil, fmt.Errorf("%w: idempotency key was used for workflow %s", ErrWorkflowInput, existing.Run.Workflow)
+		}
+		id = existing.Run.ID
+	} else {
+		e.start(record)
+	}
+	done := e.done[id]
+	e.mu.Unlock()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+	return e.store.Get(id)
+}
+
+// GetRun returns the state of a run.
+func (e *WorkflowEngine) GetRun(id string) (*WorkflowRun, error) {
+	return e.store.Get(id)
+}
+
+// Resume continues the store's unfinished runs in the background,
+// returning how many. Steps that finished are not run again; a step
+// interrupted mid-call is retried with the same idempotency key.
+func (e *WorkflowEngine) Resume() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	resumed := 0
+	for _, record := range e.store.unfinished() {
+		if e.done[record.Run.ID] != nil {
+			continue
+		}
+		record.Run.Resumes++
+		log.Printf("Resuming workflow %s run %s", record.Run.Workflow, record.Run.ID)
+		e.start(record)
+		resumed++
+	}
+	return resumed
+}
+
+// start runs a record in the background. Callers hold mu.
+func (e *WorkflowEngine) start(record *workflowRecord) {
+	done := make(chan struct{})
+	e.done[record.Run.ID] = done
+	go func() {
+		e.execute(record)
+		e.mu.Lock()
+		delete(e.done, record.Run.ID)
+		onRunFinished := e.onRunFinished
+		e.mu.Unlock()
+		close(done)
+
+		if onRunFinished != nil {
+			if run, err := e.store.Get(record.Run.ID); err == nil {
+				onRunFinished(run)
+			}
+		}
+	}()
+}
+
+// execute runs a record's remaining steps. Runs are detached from the
+// request that started them, so a client going away doesn't abandon them.
+func (e *WorkflowEngine) execute(record *workflowRecord) {
+	state := newWorkflowState(e.store, record)
+	ctx := context.Background()
+
+	failed := false
+	for _, step := range record.Definition.Steps {
+		if failed {
+			state.skip(step)
+			continue
+		}
+		if !e.runStep(ctx, step, state) {
+			failed = true
+		}
+	}
+	if failed {
+		e.compensate(ctx, state)
+	}
+
+	state.finish(failed)
+}
+
+// resolveInputs fills in defaults and checks a run's inputs.
+func (d *WorkflowDefinition) resolveInputs(inputs map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(d.Inputs))
+	declared := make(map[string]bool, len(d.Inputs))
+	for _, input := range d.Inputs {
+		declared[input.Name] = true
+		value, ok := inputs[input.Name]
+		if !ok || value == "" {
+			value = input.Default
+		}
+		if value == "" && input.Required {
+			return nil, fmt.Errorf("%w: %s is required", ErrWorkflowInput, input.Name)
+		}
+		values[input.Name] = value
+	}
+	for name := range inputs {
+		if !declared[name] {
+			return nil, fmt.Errorf("%w: %s is not an input of %s", ErrWorkflowInput, name, d.Name)
+		}
+	}
+	return values, nil
+}
+
+// ============================================================================
+// Run State
+// ============================================================================
+
+// workflowState is a run in progress. Every change is saved to the store.
+type workflowState struct {
+	store *WorkflowStore
+	// order is each agent step's position in the definition
+	order map[string]int
+
+	mu     sync.Mutex
+	record *workflowRecord
+}
+
+func newWorkflowState(store *WorkflowStore, record *workflowRecord) *workflowState {
+	s := &workflowState{store: store, record: record, order: make(map[string]int)}
+	var index func(steps []WorkflowStep)
+	index = func(steps []WorkflowStep) {
+		for _, step := range steps {
+			if len(step.Parallel) > 0 {
+				index(step.Parallel)
+			} else {
+				s.order[step.ID] = len(s.order)
+			}
+		}
+	}
+	index(record.Definition.Steps)
+	if record.Groups == nil {
+		record.Groups = make(map[string]StepResult)
+	}
+	return s
+}
+
+// result returns the latest result of a step or group.
+func (s *workflowState) result(id string) (StepResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(id)
+}
+
+// lookup returns the latest result of a step or group. Callers hold mu.
+func (s *workflowState) lookup(id string) (StepResult, bool) {
+	if result, ok := s.record.Groups[id]; ok {
+		return result, true
+	}
+	for _, result := range s.record.Run.Steps {
+		if result.ID == id {
+			return result, true
+		}
+	}
+	return StepResult{}, false
+}
+
+// put saves a step's result, replacing any earlier one. Group results
+// are kept apart from the step list.
+func (s *workflowState) put(result StepResult, group bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.record.Run
+	if group {
+		s.record.Groups[result.ID] = result
+	} else {
+		replaced := false
+		for i := range run.Steps {
+			if run.Steps[i].ID == result.ID {
+				run.Steps[i], replaced = result, true
+			}
+		}
+		if !replaced {
+			run.Steps = append(run.Steps, result)
+		}
+		sort.SliceStable(run.Steps, func(i, j int) bool { return s.order[run.Steps[i].ID] < s.order[run.Steps[j].ID] })
+	}
+	s.save()
+}
+
+// finish marks the run finished and saves it.
+func (s *workflowState) finish(failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.record.Run
+	run.Status = StepSucceeded
+	if failed {
+		run.Status = StepFailed
+	}
+	for _, result := range run.Steps {
+		if result.Status == StepSucceeded {
+			run.Output = result.Output
+		}
+	}
+	if len(run.Compensations) > 0 {
+		run.Compensation = StepSucceeded
+	}
+	for _, result := range run.Compensations {
+		if result.Status != StepSucceeded {
+			run.Compensation = StepFailed
+		}
+	}
+	s.save()
+}
+
+// save writes the run to the store. A failed write costs resumability,
+// not the run, so it is logged. Callers hold mu.
+func (s *workflowState) save() {
+	s.record.Run.UpdatedAt = time.Now()
+	s.record.Run.DurationMs = s.record.Run.UpdatedAt.Sub(s.record.Run.StartedAt).Milliseconds()
+	if err := s.store.save(s.record); err != nil {
+		log.Printf("Warning: workflow run %s: %v", s.record.Run.ID, err)
+	}
+}
+
+// finished reports whether a step or group ended in an earlier attempt
+// of the run, and if so whether it failed.
+func (s *workflowState) finished(id string) (done, failed bool) {
+	result, ok := s.result(id)
+	if !ok || result.Status == StepRunning {
+		return false, false
+	}
+	return true, result.Status == StepFailed
+}
+
+// skip records a step, and a group's members, as skipped.
+func (s *workflowState) skip(step WorkflowStep) {
+	for _, member := range step.Parallel {
+		s.skip(member)
+	}
+	if step.ID != "" {
+		s.put(StepResult{ID: step.ID, Agent: step.Agent, Status: StepSkipped}, len(step.Parallel) > 0)
+	}
+}
+
+// holds reports whether a step's condition holds.
+func (s *workflowState) holds(cond *StepCondition) bool {
+	if cond == nil {
+		return true
+	}
+	result, ok := s.result(cond.Step)
+
+	status := cond.Status
+	if status == "" {
+		status = StepSucceeded
+	}
+	if !ok || result.Status != status {
+		return false
+	}
+	output := strings.ToLower(result.Output)
+	if cond.Contains != "" && !strings.Contains(output, strings.ToLower(cond.Contains)) {
+		return false
+	}
+	if cond.NotContains != "" && strings.Contains(output, strings.ToLower(cond.NotContains)) {
+		return false
+	}
+	return true
+}
+
+// render fills in a prompt's input and step output references.
+func (s *workflowState) render(prompt string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return workflowRefPattern.ReplaceAllStringFunc(prompt, func(ref string) string {
+		match := workflowRefPattern.FindStringSubmatch(ref)
+		if match[1] != "" {
+			return s.record.Inputs[match[1]]
+		}
+		if result, ok := s.lookup(match[2]); ok {
+			return result.Output
+		}
+		return ""
+	})
+}
+
+// ============================================================================
+// Step Execution
+// ============================================================================
+
+// runStep runs a step or group, returning false if the run must stop.
+// Steps that finished before a restart are not run again.
+func (e *WorkflowEngine) runStep(ctx context.Context, step WorkflowStep, state *workflowState) bool {
+	if step.ID != "" {
+		if done, failed := state.finished(step.ID); done {
+			return !failed || step.ContinueOnError
+		}
+	}
+	if !state.holds(step.When) {
+		state.skip(step)
+		return true
+	}
+	if len(step.Parallel) == 0 {
+		result := e.invoke(ctx, step, state)
+		return result.Status == StepSucceeded || step.ContinueOnError
+	}
+
+	// Members run concurrently. The group fails if any member does, and
+	// is skipped if none ran
+	var wg sync.WaitGroup
+	for _, member := range step.Parallel {
+		if done, _ := state.finished(member.ID); done {
+			continue
+		}
+		if !state.holds(member.When) {
+			state.skip(member)
+			continue
+		}
+		wg.Add(1)
+		go func(member WorkflowStep) {
+			defer wg.Done()
+			e.invoke(ctx, member, state)
+		}(member)
+	}
+	wg.Wait()
+
+	ok := true
+	outputs := make([]string, 0, len(step.Parallel))
+	group := StepResult{ID: step.ID, Status: StepSkipped}
+	for _, member := range step.Parallel {
+		result, _ := state.result(member.ID)
+		group.DurationMs = max(group.DurationMs, result.DurationMs)
+		switch result.Status {
+		case StepSucceeded:
+			outputs = append(outputs, result.Output)
+			if group.Status == StepSkipped {
+				group.Status = StepSucceeded
+			}
+		case StepFailed:
+			group.Status = StepFailed
+			if !member.ContinueOnError {
+				ok = false
+			}
+		}
+	}
+	if step.ID != "" {
+		group.Output = strings.Join(outputs, "\n\n---\n\n")
+		state.put(group, true)
+	}
+	return ok || step.ContinueOnError
+}
+
+// invoke sends a step's prompt to its agent, recording the result.
+func (e *WorkflowEngine) invoke(ctx context.Context, step WorkflowStep, state *workflowState) StepResult {
+	previous, _ := state.result(step.ID)
+	key := state.record.Run.ID + "/" + step.ID
+	return e.attempt(ctx, step, key, previous, state, func(result StepResult) { state.put(result, false) })
+}
+
+// attempt sends a prompt to an agent under the agent's tier quota,
+// passing each result to save: the attempt before the call and the
+// outcome after it. The agent gets an idempotency key that is the same on
+// every attempt.
+func (e *WorkflowEngine) attempt(ctx context.Context, step WorkflowStep, key string, previous StepResult, state *workflowState, save func(StepResult)) StepResult {
+	result := StepResult{
+		ID:             step.ID,
+		Agent:          step.Agent,
+		Status:         StepRunning,
+		IdempotencyKey: key,
+		Attempts:       previous.Attempts + 1,
+	}
+	save(result)
+
+	start := time.Now()
+	output, err := e.call(models.WithIdempotencyKey(ctx, key), step, state, &result)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status, result.Error = StepFailed, err.Error()
+	} else {
+		result.Status, result.Output = StepSucceeded, output
+	}
+	save(result)
+	return result
+}
+
+// call routes a step's prompt to its agent and returns the reply.
+func (e *WorkflowEngine) call(ctx context.Context, step WorkflowStep, state *workflowState, result *StepResult) (string, error) {
+	agent, res, err := e.registry.Resolve(step.Agent)
+	if err != nil {
+		return "", err
+	}
+	result.Agent = res.Codename
+	release, err := e.registry.Admit(ctx, agent)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	req := &models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: state.render(step.Prompt)}},
+	}
+	resp, err := e.registry.Handle(ctx, agent, RouteWorkflow, req)
+	if err != nil {
+		return "", err
+	}
+	reply := resp.Choices[0].Message.Content
+	if !step.Graph {
+		return reply, nil
+	}
+	if e.graph == nil {
+		return "", errors.New("no knowledge graph is configured")
+	}
+	return e.graph.IngestText(ctx, reply)
+}
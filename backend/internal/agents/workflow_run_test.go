@@ -0,0 +1,329 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// scriptedAgent answers with a fixed reply, or fails.
+type scriptedAgent struct {
+	codename string
+	reply    string
+	fail     bool
+	delay    time.Duration
+	// prompt and key are the last prompt and idempotency key received
+	prompt atomic.Value
+	key    atomic.Value
+	calls  atomic.Int32
+}
+
+func (a *scriptedAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	a.calls.Add(1)
+	a.prompt.Store(copilot.GetLastUserMessage(req))
+	if key, ok := models.IdempotencyKeyFromContext(ctx); ok {
+		a.key.Store(key)
+	}
+	time.Sleep(a.delay)
+	if a.fail {
+		return nil, errors.New(a.codename + " is unavailable")
+	}
+	return copilot.NewResponse(a.reply), nil
+}
+
+func (a *scriptedAgent) GetInfo() models.Agent {
+	return models.Agent{Codename: a.codename, Tier: 1}
+}
+
+// setupWorkflowEngine registers scripted agents and the test workflow.
+func setupWorkflowEngine(t *testing.T, agents ...*scriptedAgent) *WorkflowEngine {
+	t.Helper()
+	registry := NewRegistry()
+	for _, agent := range agents {
+		registry.Register(agent)
+	}
+	engine := NewWorkflowEngine(registry)
+	def, err := ParseWorkflow([]byte(testWorkflowYAML))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+	if err := engine.Register(def); err != nil {
+		t.Fatalf("failed to register workflow: %v", err)
+	}
+	return engine
+}
+
+func TestWorkflowRun(t *testing.T) {
+	architect := &scriptedAgent{codename: "ARCHITECT", reply: "layered design"}
+	cipher := &scriptedAgent{codename: "CIPHER", reply: "Injection RISK in the API", delay: 40 * time.Millisecond}
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: "unit tests", delay: 40 * time.Millisecond}
+	fortress := &scriptedAgent{codename: "FORTRESS", reply: "threat model"}
+	engine := setupWorkflowEngine(t, architect, cipher, eclipse, fortress)
+
+	start := time.Now()
+	run, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "a billing API"})
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepSucceeded || len(run.Steps) != 4 || run.Output != "threat model" {
+		t.Fatalf("expected 4 succeeded steps ending in the threat model, got %+v", run)
+	}
+	// The parallel reviews overlap
+	if elapsed := time.Since(start); elapsed > 70*time.Millisecond {
+		t.Errorf("expected parallel steps to run concurrently, took %v", elapsed)
+	}
+
+	if got := architect.prompt.Load(); got != "Review a billing API" {
+		t.Errorf("expected input filled in, got %q", got)
+	}
+	if got := cipher.prompt.Load(); got != "Secure layered design" {
+		t.Errorf("expected prior output filled in, got %q", got)
+	}
+	expected := []string{"architecture", "security", "testability", "threat-model"}
+	for i, id := range expected {
+		if run.Steps[i].ID != id {
+			t.Errorf("expected step %d to be %s, got %s", i, id, run.Steps[i].ID)
+		}
+	}
+}
+
+func TestWorkflowRunDefinition(t *testing.T) {
+	architect := &scriptedAgent{codename: "ARCHITECT", reply: "layered design"}
+	registry := NewRegistry()
+	registry.Register(architect)
+	engine := NewWorkflowEngine(registry)
+
+	def := &WorkflowDefinition{
+		Name:   "adhoc",
+		Inputs: []WorkflowInput{{Name: "task", Required: true}},
+		Steps:  []WorkflowStep{{ID: "design", Agent: "ARCHITECT", Prompt: "Design {{inputs.task}}"}},
+	}
+	run, err := engine.RunDefinition(context.Background(), def, map[string]string{"task": "a queue"}, "")
+	if err != nil {
+		t.Fatalf("failed to run definition: %v", err)
+	}
+	if run.Status != StepSucceeded || run.Output != "layered design" || architect.prompt.Load() != "Design a queue" {
+		t.Errorf("expected the definition run, got %+v", run)
+	}
+	if _, err := engine.Get("adhoc"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("expected the definition left unregistered, got %v", err)
+	}
+	if stored, err := engine.GetRun(run.ID); err != nil || stored.Workflow != "adhoc" {
+		t.Errorf("expected the run kept, got %+v, %v", stored, err)
+	}
+
+	def.Steps[0].Agent = "NOBODY"
+	if _, err := engine.RunDefinition(context.Background(), def, map[string]string{"task": "a queue"}, ""); !errors.Is(err, ErrInvalidWorkflow) {
+		t.Errorf("expected an invalid definition rejected, got %v", err)
+	}
+}
+
+func TestWorkflowRunCondition(t *testing.T) {
+	cipher := &scriptedAgent{codename: "CIPHER", reply: "no findings"}
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design"},
+		cipher,
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+
+	run, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "a billing API"})
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepSucceeded || run.Steps[3].Status != StepSkipped {
+		t.Errorf("expected threat model skipped, got %+v", run.Steps[3])
+	}
+	if run.Output != "unit tests" {
+		t.Errorf("expected output of the last succeeded step, got %q", run.Output)
+	}
+}
+
+func TestWorkflowRunFailure(t *testing.T) {
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design"},
+		&scriptedAgent{codename: "CIPHER", fail: true},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+
+	run, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "a billing API"})
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepFailed {
+		t.Errorf("expected run failed, got %s", run.Status)
+	}
+	if run.Steps[1].Status != StepFailed || run.Steps[1].Error != "CIPHER is unavailable" {
+		t.Errorf("expected security step failed, got %+v", run.Steps[1])
+	}
+	// The sibling still finishes; later steps are skipped
+	if run.Steps[2].Status != StepSucceeded || run.Steps[3].Status != StepSkipped {
+		t.Errorf("expected testability succeeded and threat model skipped, got %+v", run.Steps[2:])
+	}
+}
+
+func TestWorkflowRunFinishedCallback(t *testing.T) {
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design"},
+		&scriptedAgent{codename: "CIPHER", reply: "no findings"},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+	finished := make(chan *WorkflowRun, 1)
+	engine.OnRunFinished(func(run *WorkflowRun) {
+		finished <- run
+	})
+
+	run, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "a billing API"})
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	select {
+	case got := <-finished:
+		if got.ID != run.ID || got.Status != StepSucceeded {
+			t.Errorf("expected the finished run, got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected callback for the finished run")
+	}
+}
+
+// recordingGraph describes the records it is given.
+type recordingGraph struct {
+	text string
+}
+
+func (g *recordingGraph) IngestText(ctx context.Context, text string) (string, error) {
+	g.text = text
+	return "Nodes:\n- API Gateway", nil
+}
+
+func TestWorkflowRunGraph(t *testing.T) {
+	architect := &scriptedAgent{codename: "ARCHITECT", reply: `{"node": {"id": "api-gateway", "label": "API Gateway", "type": "instance"}}`}
+	fortress := &scriptedAgent{codename: "FORTRESS", reply: "threat model"}
+	registry := NewRegistry()
+	registry.Register(architect)
+	registry.Register(fortress)
+	def, err := ParseWorkflow([]byte(`
+name: "threats"
+steps:
+  - id: "components"
+    agent: "ARCHITECT"
+    graph: true
+    prompt: "Components"
+  - id: "threats"
+    agent: "FORTRESS"
+    prompt: "Threats to {{steps.components.output}}"
+`))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+
+	engine := NewWorkflowEngine(registry)
+	if err := engine.Register(def); err != nil {
+		t.Fatalf("failed to register workflow: %v", err)
+	}
+	run, err := engine.Run(context.Background(), "threats", nil)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepFailed || run.Steps[0].Error != "no knowledge graph is configured" {
+		t.Errorf("expected the graph step failed without a graph, got %+v", run.Steps[0])
+	}
+
+	graph := &recordingGraph{}
+	engine.SetGraph(graph)
+	run, err = engine.Run(context.Background(), "threats", nil)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	if run.Status != StepSucceeded || graph.text != architect.reply {
+		t.Fatalf("expected the architect's reply added to the graph, got %+v (%q)", run, graph.text)
+	}
+	if got := fortress.prompt.Load(); got != "Threats to Nodes:\n- API Gateway" {
+		t.Errorf("expected the graph's description passed on, got %q", got)
+	}
+}
+
+func TestWorkflowRunInputs(t *testing.T) {
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT"},
+		&scriptedAgent{codename: "CIPHER"},
+		&scriptedAgent{codename: "ECLIPSE"},
+		&scriptedAgent{codename: "FORTRESS"},
+	)
+
+	if _, err := engine.Run(context.Background(), "design-review", nil); !errors.Is(err, ErrWorkflowInput) {
+		t.Errorf("expected ErrWorkflowInput for missing input, got %v", err)
+	}
+	if _, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "x", "extra": "y"}); !errors.Is(err, ErrWorkflowInput) {
+		t.Errorf("expected ErrWorkflowInput for undeclared input, got %v", err)
+	}
+	if _, err := engine.Run(context.Background(), "missing", nil); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("expected ErrWorkflowNotFound, got %v", err)
+	}
+}
+
+func TestRunWorkflowHandler(t *testing.T) {
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design"},
+		&scriptedAgent{codename: "CIPHER", reply: "no findings"},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+	handler := NewHandler(engine.registry)
+	handler.SetWorkflows(engine)
+	r := chi.NewRouter()
+	r.Get("/workflows", handler.ListWorkflows)
+	r.Post("/workflows/{name}/run", handler.RunWorkflow)
+
+	body := []byte(`{"inputs": {"proposal": "a billing API"}}`)
+	req := httptest.NewRequest("POST", "/workflows/design-review/run", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var run WorkflowRun
+	if err := json.NewDecoder(w.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if run.Status != StepSucceeded || len(run.Steps) != 4 {
+		t.Errorf("expected succeeded run with 4 steps, got %+v", run)
+	}
+
+	req = httptest.NewRequest("POST", "/workflows/design-review/run", strings.NewReader(`{"inputs": {}}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for missing input, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/workflows/missing/run", strings.NewReader(`{}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/workflows", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var workflows []WorkflowDefinition
+	if err := json.NewDecoder(w.Body).Decode(&workflows); err != nil || len(workflows) != 1 {
+		t.Errorf("expected 1 listed workflow, got %d (%v)", len(workflows), err)
+	}
+}
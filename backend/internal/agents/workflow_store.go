@@ -0,0 +1,216 @@
+// Package agents provides the agent registry and HTTP handlers.
+package agents
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrRunNotFound is returned when no workflow run has an ID.
+var ErrRunNotFound = errdefs.New(errdefs.ErrNotFound, "workflow run not found")
+
+// WorkflowRunRetention is how long finished runs are kept for lookups and
+// idempotent retries.
+const WorkflowRunRetention = 24 * time.Hour
+
+// workflowRecord is the persisted state of a run: the run as reported,
+// plus what is needed to resume it.
+type workflowRecord struct {
+	Run *WorkflowRun `json:"run"`
+	// Definition is the workflow as it was when the run started, so a
+	// resumed run is not affected by later edits
+	Definition *WorkflowDefinition `json:"definition"`
+	Inputs     map[string]string   `json:"inputs"`
+	// Groups holds the results of parallel groups with IDs
+	Groups map[string]StepResult `json:"groups,omitempty"`
+}
+
+// clone copies the record's mutable parts; the definition and inputs are
+// never modified once a run starts.
+func (r *workflowRecord) clone() *workflowRecord {
+	run := *r.Run
+	run.Steps = append([]StepResult(nil), r.Run.Steps...)
+	run.Compensations = append([]StepResult(nil), r.Run.Compensations...)
+	groups := make(map[string]StepResult, len(r.Groups))
+	for id, result := range r.Groups {
+		groups[id] = result
+	}
+	return &workflowRecord{Run: &run, Definition: r.Definition, Inputs: r.Inputs, Groups: groups}
+}
+
+// WorkflowStore keeps the state of workflow runs. A store opened on a
+// directory writes each run's state to a file after every step, so runs
+// interrupted by a crash or redeploy can be resumed; otherwise state is
+// kept in memory only.
+type WorkflowStore struct {
+	// dir holds one file per run; empty keeps runs in memory
+	dir string
+
+	mu      sync.Mutex
+	records map[string]*workflowRecord
+	// keys maps client idempotency keys to run IDs
+	keys map[string]string
+}
+
+// NewWorkflowStore creates a store that keeps runs in memory.
+func NewWorkflowStore() *WorkflowStore {
+	return &WorkflowStore{
+		records: make(map[string]*workflowRecord),
+		keys:    make(map[string]string),
+	}
+}
+
+// OpenWorkflowStore opens a store persisting runs in dir, creating it if
+// needed, and loads the runs saved there. Finished runs past retention
+// are deleted.
+func OpenWorkflowStore(dir string) (*WorkflowStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create workflow state directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewWorkflowStore()
+	s.dir = dir
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflow run: %w", err)
+		}
+		var record workflowRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.Run == nil || record.Definition == nil {
+			return nil, fmt.Errorf("corrupt workflow run %s", filepath.Base(path))
+		}
+		s.records[record.Run.ID] = &record
+		if record.Run.IdempotencyKey != "" {
+			s.keys[record.Run.IdempotencyKey] = record.Run.ID
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	return s, nil
+}
+
+// create adds a new run. If the run's idempotency key was already used,
+// nothing is added and the existing run is returned.
+func (s *WorkflowStore) create(record *workflowRecord) (*workflowRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	if key := record.Run.IdempotencyKey; key != "" {
+		if id, ok := s.keys[key]; ok {
+			return s.records[id].clone(), nil
+		}
+	}
+	if err := s.write(record); err != nil {
+		return nil, err
+	}
+	s.records[record.Run.ID] = record.clone()
+	if key := record.Run.IdempotencyKey; key != "" {
+		s.keys[key] = record.Run.ID
+	}
+	return nil, nil
+}
+
+// save stores a run's current state.
+func (s *WorkflowStore) save(record *workflowRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Run.ID] = record.clone()
+	return s.write(record)
+}
+
+// Get returns a copy of a run.
+func (s *WorkflowStore) Get(id string) (*WorkflowRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return record.clone().Run, nil
+}
+
+// unfinished returns copies of the runs still in progress, oldest first.
+func (s *WorkflowStore) unfinished() []*workflowRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []*workflowRecord
+	for _, record := range s.records {
+		if record.Run.Status == StepRunning {
+			records = append(records, record.clone())
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Run.StartedAt.Before(records[j].Run.StartedAt) })
+	return records
+}
+
+// prune deletes finished runs past retention. Callers hold mu.
+func (s *WorkflowStore) prune(now time.Time) {
+	for id, record := range s.records {
+		if record.Run.Status == StepRunning || now.Sub(record.Run.UpdatedAt) < WorkflowRunRetention {
+			continue
+		}
+		delete(s.records, id)
+		delete(s.keys, record.Run.IdempotencyKey)
+		if s.dir != "" {
+			os.Remove(s.path(id))
+		}
+	}
+}
+
+// write saves a record to its file atomically. Callers hold mu.
+func (s *WorkflowStore) write(record *workflowRecord) error {
+	if s.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode workflow run: %w", err)
+	}
+	path := s.path(record.Run.ID)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save workflow run: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save workflow run: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save workflow run: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save workflow run: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save workflow run: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of a run.
+func (s *WorkflowStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// newRunID returns a random run ID.
+func newRunID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,206 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// waitForRun polls a run until it finishes.
+func waitForRun(t *testing.T, engine *WorkflowEngine, id string) *WorkflowRun {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		run, err := engine.GetRun(id)
+		if err != nil {
+			t.Fatalf("failed to get run: %v", err)
+		}
+		if run.Status != StepRunning {
+			return run
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("run %s did not finish", id)
+	return nil
+}
+
+func TestWorkflowStorePersistsRuns(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenWorkflowStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design"},
+		&scriptedAgent{codename: "CIPHER", reply: "no findings"},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+	engine.SetStore(store)
+
+	run, err := engine.Run(context.Background(), "design-review", map[string]string{"proposal": "a billing API"})
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	reopened, err := OpenWorkflowStore(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	saved, err := reopened.Get(run.ID)
+	if err != nil {
+		t.Fatalf("expected run persisted, got %v", err)
+	}
+	if saved.Status != StepSucceeded || len(saved.Steps) != 4 || saved.Output != run.Output {
+		t.Errorf("expected finished run persisted, got %+v", saved)
+	}
+	if len(reopened.unfinished()) != 0 {
+		t.Error("expected no unfinished runs")
+	}
+	if _, err := reopened.Get("missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestWorkflowResume(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenWorkflowStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	def, err := ParseWorkflow([]byte(testWorkflowYAML))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+
+	// The server went down while the security review was in flight
+	now := time.Now()
+	record := &workflowRecord{
+		Run: &WorkflowRun{
+			ID:        newRunID(),
+			Workflow:  "design-review",
+			Status:    StepRunning,
+			StartedAt: now,
+			UpdatedAt: now,
+			Steps: []StepResult{
+				{ID: "architecture", Agent: "ARCHITECT", Status: StepSucceeded, Output: "layered design", Attempts: 1},
+				{ID: "security", Agent: "CIPHER", Status: StepRunning, Attempts: 1},
+				{ID: "testability", Agent: "ECLIPSE", Status: StepSucceeded, Output: "unit tests", Attempts: 1},
+			},
+		},
+		Definition: def,
+		Inputs:     map[string]string{"proposal": "a billing API"},
+	}
+	if err := store.save(record); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	architect := &scriptedAgent{codename: "ARCHITECT", reply: "layered design"}
+	cipher := &scriptedAgent{codename: "CIPHER", reply: "RISK found"}
+	eclipse := &scriptedAgent{codename: "ECLIPSE", reply: "unit tests"}
+	fortress := &scriptedAgent{codename: "FORTRESS", reply: "threat model"}
+	engine := setupWorkflowEngine(t, architect, cipher, eclipse, fortress)
+	reopened, err := OpenWorkflowStore(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	engine.SetStore(reopened)
+
+	if resumed := engine.Resume(); resumed != 1 {
+		t.Fatalf("expected 1 run resumed, got %d", resumed)
+	}
+	run := waitForRun(t, engine, record.Run.ID)
+
+	if run.Status != StepSucceeded || run.Resumes != 1 || run.Output != "threat model" {
+		t.Fatalf("expected resumed run to succeed, got %+v", run)
+	}
+	// Finished steps are not run again; the interrupted one is retried once
+	if architect.calls.Load() != 0 || eclipse.calls.Load() != 0 {
+		t.Errorf("expected finished steps not rerun, got %d and %d calls", architect.calls.Load(), eclipse.calls.Load())
+	}
+	if cipher.calls.Load() != 1 || cipher.prompt.Load() != "Secure layered design" {
+		t.Errorf("expected security retried once with the saved output, got %d calls", cipher.calls.Load())
+	}
+	security := run.Steps[1]
+	if security.Attempts != 2 || security.IdempotencyKey != record.Run.ID+"/security" || cipher.key.Load() != security.IdempotencyKey {
+		t.Errorf("expected second attempt with a stable idempotency key, got %+v", security)
+	}
+
+	if engine.Resume() != 0 {
+		t.Error("expected nothing left to resume")
+	}
+}
+
+func TestWorkflowRunIdempotencyKey(t *testing.T) {
+	architect := &scriptedAgent{codename: "ARCHITECT", reply: "layered design"}
+	engine := setupWorkflowEngine(t,
+		architect,
+		&scriptedAgent{codename: "CIPHER", reply: "no findings"},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+	inputs := map[string]string{"proposal": "a billing API"}
+
+	first, err := engine.RunWithKey(context.Background(), "design-review", inputs, "review-42")
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+	retry, err := engine.RunWithKey(context.Background(), "design-review", inputs, "review-42")
+	if err != nil {
+		t.Fatalf("failed to retry workflow: %v", err)
+	}
+	if retry.ID != first.ID || architect.calls.Load() != 1 {
+		t.Errorf("expected retry to return the first run without rerunning it, got %s and %d calls", retry.ID, architect.calls.Load())
+	}
+
+	other, _ := engine.RunWithKey(context.Background(), "design-review", inputs, "review-43")
+	if other.ID == first.ID || architect.calls.Load() != 2 {
+		t.Errorf("expected a new key to start a new run")
+	}
+}
+
+func TestRunWorkflowAccepted(t *testing.T) {
+	engine := setupWorkflowEngine(t,
+		&scriptedAgent{codename: "ARCHITECT", reply: "layered design", delay: 100 * time.Millisecond},
+		&scriptedAgent{codename: "CIPHER", reply: "no findings"},
+		&scriptedAgent{codename: "ECLIPSE", reply: "unit tests"},
+		&scriptedAgent{codename: "FORTRESS", reply: "threat model"},
+	)
+	handler := NewHandler(engine.registry)
+	handler.SetWorkflows(engine)
+	r := chi.NewRouter()
+	r.Post("/workflows/{name}/run", handler.RunWorkflow)
+	r.Get("/workflows/runs/{id}", handler.GetWorkflowRun)
+
+	// The request gives up before the run finishes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("POST", "/workflows/design-review/run", strings.NewReader(`{"inputs": {"proposal": "a billing API"}}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", w.Code)
+	}
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, "/workflows/runs/") {
+		t.Fatalf("expected run location, got %q", location)
+	}
+
+	run := waitForRun(t, engine, strings.TrimPrefix(location, "/workflows/runs/"))
+	if run.Status != StepSucceeded {
+		t.Errorf("expected run to finish in the background, got %s", run.Status)
+	}
+	req = httptest.NewRequest("GET", location, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"succeeded"`) {
+		t.Errorf("expected finished run from lookup, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,140 @@
+package agents
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testWorkflowYAML = `
+name: "design-review"
+inputs:
+  - name: "proposal"
+    required: true
+steps:
+  - id: "architecture"
+    agent: "architect"
+    prompt: "Review {{inputs.proposal}}"
+  - parallel:
+      - id: "security"
+        agent: "CIPHER"
+        prompt: "Secure {{ steps.architecture.output }}"
+      - id: "testability"
+        agent: "ECLIPSE"
+        prompt: "Test {{inputs.proposal}}"
+  - id: "threat-model"
+    agent: "FORTRESS"
+    when:
+      step: "security"
+      contains: "risk"
+    prompt: "Model {{steps.security.output}}"
+`
+
+func TestParseWorkflow(t *testing.T) {
+	def, err := ParseWorkflow([]byte(testWorkflowYAML))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+	if def.Name != "design-review" || len(def.Steps) != 3 || len(def.Steps[1].Parallel) != 2 {
+		t.Errorf("expected 3 steps with a parallel group of 2, got %+v", def)
+	}
+	if def.Steps[2].When == nil || def.Steps[2].When.Contains != "risk" {
+		t.Errorf("expected condition on security, got %+v", def.Steps[2].When)
+	}
+
+	if err := def.Validate(DefaultRegistry()); err != nil {
+		t.Fatalf("expected valid workflow, got %v", err)
+	}
+	if def.Steps[0].Agent != "ARCHITECT" {
+		t.Errorf("expected agent codename normalized, got %s", def.Steps[0].Agent)
+	}
+
+	// Misspelled keys are rejected
+	if _, err := ParseWorkflow([]byte("name: x\nstep: []\n")); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	registry := DefaultRegistry()
+	tests := []struct {
+		name    string
+		yaml    string
+		problem string
+	}{
+		{"no steps", `name: "empty"`, "no steps"},
+		{"bad name", "name: \"Design Review\"\nsteps: [{id: a, agent: APEX, prompt: p}]", "must be lowercase"},
+		{"unknown agent", "name: w\nsteps: [{id: a, agent: NOBODY, prompt: p}]", "agent not found"},
+		{"missing prompt", "name: w\nsteps: [{id: a, agent: APEX}]", "agent and prompt are required"},
+		{"undeclared input", "name: w\nsteps: [{id: a, agent: APEX, prompt: '{{inputs.x}}'}]", "undeclared input"},
+		{"forward reference", "name: w\nsteps: [{id: a, agent: APEX, prompt: '{{steps.b.output}}'}, {id: b, agent: APEX, prompt: p}]", `step "b", which does not run before it`},
+		{"duplicate id", "name: w\nsteps: [{id: a, agent: APEX, prompt: p}, {id: a, agent: APEX, prompt: p}]", "duplicated"},
+		{"bad condition", "name: w\nsteps: [{id: a, agent: APEX, prompt: p, when: {step: a}}]", "condition refers"},
+		{"bad status", "name: w\nsteps: [{id: a, agent: APEX, prompt: p}, {id: b, agent: APEX, prompt: p, when: {step: a, status: done}}]", "unknown condition status"},
+		{"sibling reference", "name: w\nsteps: [{parallel: [{id: a, agent: APEX, prompt: p}, {id: b, agent: APEX, prompt: '{{steps.a.output}}'}]}]", "does not run before it"},
+		{"nested group", "name: w\nsteps: [{parallel: [{id: a, parallel: [{id: b, agent: APEX, prompt: p}]}]}]", "can't be nested"},
+		{"graph group", "name: w\nsteps: [{id: g, graph: true, parallel: [{id: a, agent: APEX, prompt: p}]}]", "no reply to add to the graph"},
+	}
+
+	for _, tt := range tests {
+		def, err := ParseWorkflow([]byte(tt.yaml))
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tt.name, err)
+		}
+		err = def.Validate(registry)
+		if !errors.Is(err, ErrInvalidWorkflow) || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.problem, err)
+		}
+	}
+}
+
+func TestWorkflowEngineLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	engine := NewWorkflowEngine(DefaultRegistry())
+	if err := os.WriteFile(filepath.Join(dir, "design-review.yaml"), []byte(testWorkflowYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := engine.LoadDir(dir)
+	if err != nil || loaded != 1 {
+		t.Fatalf("expected 1 workflow loaded, got %d (%v)", loaded, err)
+	}
+	if _, err := engine.Get("design-review"); err != nil {
+		t.Errorf("expected design-review loaded, got %v", err)
+	}
+	if _, err := engine.Get("missing"); !errors.Is(err, ErrWorkflowNotFound) {
+		t.Errorf("expected ErrWorkflowNotFound, got %v", err)
+	}
+
+	// An invalid file fails the reload and keeps what was loaded
+	if err := os.WriteFile(filepath.Join(dir, "broken.yml"), []byte("name: broken\nsteps: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Reload(); err == nil || !strings.Contains(err.Error(), "broken.yml") {
+		t.Errorf("expected reload error naming broken.yml, got %v", err)
+	}
+	if len(engine.List()) != 1 {
+		t.Errorf("expected previous workflows kept, got %d", len(engine.List()))
+	}
+
+	if _, err := NewWorkflowEngine(DefaultRegistry()).Reload(); err == nil {
+		t.Error("expected error reloading without a directory")
+	}
+}
+
+func TestShippedWorkflowsAreValid(t *testing.T) {
+	manifestPath := findManifestPath(t)
+	if manifestPath == "" {
+		t.Skip("config directory not found, skipping test")
+	}
+	engine := NewWorkflowEngine(DefaultRegistry())
+	loaded, err := engine.LoadDir(filepath.Join(filepath.Dir(manifestPath), "workflows"))
+	if err != nil {
+		t.Fatalf("shipped workflows are invalid: %v", err)
+	}
+	if loaded == 0 {
+		t.Error("expected at least one shipped workflow")
+	}
+}
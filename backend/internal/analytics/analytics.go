@@ -0,0 +1,248 @@
+// Package analytics rolls up usage of the collective into daily
+// aggregates: invocations and how they were routed, success rates, and
+// the intents queries were labeled with, and breakthrough events. A digest of the rollups is what the ORACLE agent
+// reports trends from.
+package analytics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// dateLayout names a rollup's day.
+const dateLayout = "2006-01-02"
+
+// maxBreakthroughsPerDay bounds the breakthrough events kept for one day;
+// further events are counted but not kept.
+const maxBreakthroughsPerDay = 50
+
+// Config configures the rollups.
+type Config struct {
+	// Retention is the number of days kept, counting today
+	Retention int
+}
+
+// DefaultConfig returns the default rollup configuration.
+func DefaultConfig() Config {
+	return Config{Retention: 90}
+}
+
+// AgentUsage is one agent's activity in a day.
+type AgentUsage struct {
+	Invocations int `json:"invocations"`
+	Failures    int `json:"failures"`
+	// FeedbackSuccesses and FeedbackFailures count reported outcomes
+	FeedbackSuccesses int `json:"feedback_successes"`
+	FeedbackFailures  int `json:"feedback_failures"`
+	Breakthroughs     int `json:"breakthroughs"`
+}
+
+// Breakthrough is a success the insight detector found surprising.
+type Breakthrough struct {
+	Agents   []string  `json:"agents"`
+	TaskType string    `json:"task_type"`
+	Score    float64   `json:"score"`
+	Time     time.Time `json:"time"`
+}
+
+// DailyRollup aggregates one UTC day.
+type DailyRollup struct {
+	Date        string `json:"date"`
+	Invocations int    `json:"invocations"`
+	Failures    int    `json:"failures"`
+	// Routes counts invocations by how they were routed
+	Routes map[string]int `json:"routes"`
+	// Intents counts invocations by the intent of their query
+	Intents           map[string]int         `json:"intents"`
+	Agents            map[string]*AgentUsage `json:"agents"`
+	FeedbackSuccesses int                    `json:"feedback_successes"`
+	FeedbackFailures  int                    `json:"feedback_failures"`
+	// BreakthroughCount counts every breakthrough; Breakthroughs keeps the
+	// first of them
+	BreakthroughCount int            `json:"breakthrough_count"`
+	Breakthroughs     []Breakthrough `json:"breakthroughs"`
+}
+
+// newDailyRollup creates an empty rollup for a day.
+func newDailyRollup(date string) *DailyRollup {
+	return &DailyRollup{
+		Date:          date,
+		Routes:        make(map[string]int),
+		Intents:       make(map[string]int),
+		Agents:        make(map[string]*AgentUsage),
+		Breakthroughs: make([]Breakthrough, 0),
+	}
+}
+
+// agent returns an agent's usage, creating it if needed.
+func (d *DailyRollup) agent(codename string) *AgentUsage {
+	usage := d.Agents[codename]
+	if usage == nil {
+		usage = &AgentUsage{}
+		d.Agents[codename] = usage
+	}
+	return usage
+}
+
+// clone returns a deep copy of the rollup.
+func (d *DailyRollup) clone() *DailyRollup {
+	c := *d
+	c.Routes = make(map[string]int, len(d.Routes))
+	for route, count := range d.Routes {
+		c.Routes[route] = count
+	}
+	c.Intents = make(map[string]int, len(d.Intents))
+	for intent, count := range d.Intents {
+		c.Intents[intent] = count
+	}
+	c.Agents = make(map[string]*AgentUsage, len(d.Agents))
+	for codename, usage := range d.Agents {
+		u := *usage
+		c.Agents[codename] = &u
+	}
+	c.Breakthroughs = append(make([]Breakthrough, 0, len(d.Breakthroughs)), d.Breakthroughs...)
+	return &c
+}
+
+// Analytics collects the daily rollups. Rollups are kept in memory, for
+// the configured number of days. It is safe for concurrent use.
+type Analytics struct {
+	mu     sync.Mutex
+	days   map[string]*DailyRollup
+	config Config
+	now    func() time.Time
+}
+
+// New creates empty rollups.
+func New(config Config) *Analytics {
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig().Retention
+	}
+	return &Analytics{
+		days:   make(map[string]*DailyRollup),
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// day returns the rollup for t's day, or nil if the day is past
+// retention. The caller must hold the lock.
+func (a *Analytics) day(t time.Time) *DailyRollup {
+	date := t.UTC().Format(dateLayout)
+	if date < a.oldestDate() {
+		return nil
+	}
+	rollup := a.days[date]
+	if rollup == nil {
+		rollup = newDailyRollup(date)
+		a.days[date] = rollup
+		a.prune()
+	}
+	return rollup
+}
+
+// oldestDate returns the first day within retention.
+func (a *Analytics) oldestDate() string {
+	return a.now().UTC().AddDate(0, 0, 1-a.config.Retention).Format(dateLayout)
+}
+
+// prune drops days past retention. The caller must hold the lock.
+func (a *Analytics) prune() {
+	oldest := a.oldestDate()
+	for date := range a.days {
+		if date < oldest {
+			delete(a.days, date)
+		}
+	}
+}
+
+// RecordInvocation counts an agent invocation started at t. An empty
+// intent is not counted by intent, for queries that were not classified.
+func (a *Analytics) RecordInvocation(agent, route, intent string, success bool, t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rollup := a.day(t)
+	if rollup == nil {
+		return
+	}
+	usage := rollup.agent(agent)
+	rollup.Invocations++
+	usage.Invocations++
+	if !success {
+		rollup.Failures++
+		usage.Failures++
+	}
+	rollup.Routes[route]++
+	if intent != "" {
+		rollup.Intents[intent]++
+	}
+}
+
+// RecordFeedback counts outcomes reported for an agent today.
+func (a *Analytics) RecordFeedback(agent string, successes, failures int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rollup := a.day(a.now())
+	usage := rollup.agent(agent)
+	rollup.FeedbackSuccesses += successes
+	rollup.FeedbackFailures += failures
+	usage.FeedbackSuccesses += successes
+	usage.FeedbackFailures += failures
+}
+
+// RecordBreakthrough counts a breakthrough event on the day it happened.
+func (a *Analytics) RecordBreakthrough(b Breakthrough) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rollup := a.day(b.Time)
+	if rollup == nil {
+		return
+	}
+	rollup.BreakthroughCount++
+	for _, agent := range b.Agents {
+		rollup.agent(agent).Breakthroughs++
+	}
+	if len(rollup.Breakthroughs) < maxBreakthroughsPerDay {
+		b.Agents = append([]string(nil), b.Agents...)
+		rollup.Breakthroughs = append(rollup.Breakthroughs, b)
+	}
+}
+
+// Rollups returns the last n days, oldest first and ending today. Days
+// without activity are included empty, so the series has no gaps. n is
+// capped at the retention.
+func (a *Analytics) Rollups(n int) []*DailyRollup {
+	if n <= 0 || n > a.config.Retention {
+		n = a.config.Retention
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	today := a.now().UTC()
+	rollups := make([]*DailyRollup, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		date := today.AddDate(0, 0, -i).Format(dateLayout)
+		if rollup := a.days[date]; rollup != nil {
+			rollups = append(rollups, rollup.clone())
+		} else {
+			rollups = append(rollups, newDailyRollup(date))
+		}
+	}
+	return rollups
+}
+
+// sortedAgents returns the codenames of usage, busiest first.
+func sortedAgents(usage map[string]*AgentUsage) []string {
+	codenames := make([]string, 0, len(usage))
+	for codename := range usage {
+		codenames = append(codenames, codename)
+	}
+	sort.Slice(codenames, func(i, j int) bool {
+		a, b := usage[codenames[i]], usage[codenames[j]]
+		if a.Invocations != b.Invocations {
+			return a.Invocations > b.Invocations
+		}
+		return codenames[i] < codenames[j]
+	})
+	return codenames
+}
@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+// testNow is the current time of the test rollups.
+var testNow = time.Date(2026, 3, 14, 15, 0, 0, 0, time.UTC)
+
+// newTestAnalytics creates rollups whose clock reads testNow.
+func newTestAnalytics(retention int) *Analytics {
+	a := New(Config{Retention: retention})
+	a.now = func() time.Time { return testNow }
+	return a
+}
+
+func TestRecordInvocation(t *testing.T) {
+	a := newTestAnalytics(30)
+	a.RecordInvocation("APEX", "mention", "code-gen", true, testNow)
+	a.RecordInvocation("APEX", "direct", "", false, testNow)
+	a.RecordInvocation("CIPHER", "mention", "review", true, testNow.AddDate(0, 0, -1))
+
+	rollups := a.Rollups(2)
+	if len(rollups) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(rollups))
+	}
+	yesterday, today := rollups[0], rollups[1]
+	if today.Date != "2026-03-14" || yesterday.Date != "2026-03-13" {
+		t.Errorf("expected oldest day first, got %s and %s", yesterday.Date, today.Date)
+	}
+	if today.Invocations != 2 || today.Failures != 1 {
+		t.Errorf("expected 2 invocations and 1 failure today, got %d and %d", today.Invocations, today.Failures)
+	}
+	if today.Routes["mention"] != 1 || today.Routes["direct"] != 1 {
+		t.Errorf("expected one mention and one direct route, got %v", today.Routes)
+	}
+	if len(today.Intents) != 1 || today.Intents["code-gen"] != 1 {
+		t.Errorf("expected only the classified invocation counted by intent, got %v", today.Intents)
+	}
+	if apex := today.Agents["APEX"]; apex == nil || apex.Invocations != 2 || apex.Failures != 1 {
+		t.Errorf("expected APEX usage counted, got %+v", apex)
+	}
+	if yesterday.Agents["CIPHER"] == nil {
+		t.Error("expected CIPHER counted yesterday")
+	}
+}
+
+func TestRollups_FillsGaps(t *testing.T) {
+	a := newTestAnalytics(30)
+	a.RecordInvocation("APEX", "mention", "", true, testNow.AddDate(0, 0, -3))
+
+	rollups := a.Rollups(5)
+	if len(rollups) != 5 {
+		t.Fatalf("expected 5 days, got %d", len(rollups))
+	}
+	for i, rollup := range rollups {
+		want := 0
+		if i == 1 {
+			want = 1
+		}
+		if rollup.Invocations != want {
+			t.Errorf("day %s: expected %d invocations, got %d", rollup.Date, want, rollup.Invocations)
+		}
+	}
+
+	rollups[1].Agents["APEX"].Invocations = 99
+	if a.Rollups(5)[1].Agents["APEX"].Invocations != 1 {
+		t.Error("expected rollups to be copies")
+	}
+	if len(a.Rollups(0)) != 30 || len(a.Rollups(365)) != 30 {
+		t.Error("expected the series capped at the retention")
+	}
+}
+
+func TestRetention(t *testing.T) {
+	a := newTestAnalytics(7)
+	a.RecordInvocation("APEX", "mention", "", true, testNow.AddDate(0, 0, -7))
+	if len(a.days) != 0 {
+		t.Errorf("expected an invocation past retention dropped, got %d days", len(a.days))
+	}
+
+	a.RecordInvocation("APEX", "mention", "", true, testNow.AddDate(0, 0, -6))
+	a.now = func() time.Time { return testNow.AddDate(0, 0, 1) }
+	a.RecordInvocation("APEX", "mention", "", true, testNow.AddDate(0, 0, 1))
+	if _, ok := a.days["2026-03-08"]; ok || len(a.days) != 1 {
+		t.Errorf("expected the oldest day pruned, got %d days", len(a.days))
+	}
+}
+
+func TestRecordFeedbackAndBreakthroughs(t *testing.T) {
+	a := newTestAnalytics(30)
+	a.RecordFeedback("APEX", 3, 1)
+	for i := 0; i < maxBreakthroughsPerDay+5; i++ {
+		a.RecordBreakthrough(Breakthrough{Agents: []string{"APEX", "CIPHER"}, TaskType: "security", Score: 0.9, Time: testNow})
+	}
+
+	today := a.Rollups(1)[0]
+	if today.FeedbackSuccesses != 3 || today.FeedbackFailures != 1 {
+		t.Errorf("expected 3 successes and 1 failure reported, got %d and %d", today.FeedbackSuccesses, today.FeedbackFailures)
+	}
+	if today.BreakthroughCount != maxBreakthroughsPerDay+5 || len(today.Breakthroughs) != maxBreakthroughsPerDay {
+		t.Errorf("expected all breakthroughs counted and %d kept, got %d and %d", maxBreakthroughsPerDay, today.BreakthroughCount, len(today.Breakthroughs))
+	}
+	if today.Agents["CIPHER"].Breakthroughs != maxBreakthroughsPerDay+5 {
+		t.Errorf("expected breakthroughs counted per agent, got %d", today.Agents["CIPHER"].Breakthroughs)
+	}
+}
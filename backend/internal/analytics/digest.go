@@ -0,0 +1,201 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxDigestAgents bounds the agents listed in a digest.
+const maxDigestAgents = 10
+
+// AgentTrend summarizes an agent's activity over a digest's window.
+type AgentTrend struct {
+	Agent       string  `json:"agent"`
+	Invocations int     `json:"invocations"`
+	SuccessRate float64 `json:"success_rate"`
+	// Previous and Recent count invocations in the earlier and later half
+	// of the window; the middle day of an odd window is in neither
+	Previous int `json:"previous"`
+	Recent   int `json:"recent"`
+	// Change is the relative change from Previous to Recent, counting a
+	// rise from zero as 1
+	Change        float64 `json:"change"`
+	Breakthroughs int     `json:"breakthroughs"`
+}
+
+// Digest summarizes the rollups of a window of days.
+type Digest struct {
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Days        int            `json:"days"`
+	Invocations int            `json:"invocations"`
+	SuccessRate float64        `json:"success_rate"`
+	Routes      map[string]int `json:"routes"`
+	Intents     map[string]int `json:"intents"`
+	// FeedbackOutcomes counts reported outcomes, FeedbackSuccessRate the
+	// share of them that succeeded
+	FeedbackOutcomes    int     `json:"feedback_outcomes"`
+	FeedbackSuccessRate float64 `json:"feedback_success_rate"`
+	Breakthroughs       int     `json:"breakthroughs"`
+	// Agents are the busiest agents, busiest first
+	Agents []AgentTrend `json:"agents"`
+	// Report is the digest as text, for the ORACLE agent
+	Report string `json:"report"`
+}
+
+// Digest summarizes the last n days, ending today.
+func (a *Analytics) Digest(n int) *Digest {
+	return newDigest(a.Rollups(n))
+}
+
+// newDigest summarizes a series of rollups, oldest first.
+func newDigest(rollups []*DailyRollup) *Digest {
+	d := &Digest{
+		From:    rollups[0].Date,
+		To:      rollups[len(rollups)-1].Date,
+		Days:    len(rollups),
+		Routes:  make(map[string]int),
+		Intents: make(map[string]int),
+		Agents:  make([]AgentTrend, 0),
+	}
+
+	half := len(rollups) / 2
+	totals := make(map[string]*AgentUsage)
+	trends := make(map[string]*AgentTrend)
+	failures, feedbackSuccesses := 0, 0
+	for i, rollup := range rollups {
+		d.Invocations += rollup.Invocations
+		failures += rollup.Failures
+		feedbackSuccesses += rollup.FeedbackSuccesses
+		d.FeedbackOutcomes += rollup.FeedbackSuccesses + rollup.FeedbackFailures
+		d.Breakthroughs += rollup.BreakthroughCount
+		for route, count := range rollup.Routes {
+			d.Routes[route] += count
+		}
+		for intent, count := range rollup.Intents {
+			d.Intents[intent] += count
+		}
+		for codename, usage := range rollup.Agents {
+			total := totals[codename]
+			if total == nil {
+				total = &AgentUsage{}
+				totals[codename] = total
+				trends[codename] = &AgentTrend{Agent: codename}
+			}
+			total.Invocations += usage.Invocations
+			total.Failures += usage.Failures
+			total.Breakthroughs += usage.Breakthroughs
+			switch {
+			case i < half:
+				trends[codename].Previous += usage.Invocations
+			case i >= len(rollups)-half:
+				trends[codename].Recent += usage.Invocations
+			}
+		}
+	}
+	d.SuccessRate = rate(d.Invocations-failures, d.Invocations)
+	d.FeedbackSuccessRate = rate(feedbackSuccesses, d.FeedbackOutcomes)
+
+	for _, codename := range sortedAgents(totals) {
+		total := totals[codename]
+		if total.Invocations == 0 && total.Breakthroughs == 0 {
+			continue
+		}
+		trend := trends[codename]
+		trend.Invocations = total.Invocations
+		trend.SuccessRate = rate(total.Invocations-total.Failures, total.Invocations)
+		trend.Breakthroughs = total.Breakthroughs
+		trend.Change = change(trend.Previous, trend.Recent)
+		d.Agents = append(d.Agents, *trend)
+		if len(d.Agents) == maxDigestAgents {
+			break
+		}
+	}
+
+	d.Report = d.report()
+	return d
+}
+
+// report renders the digest as text.
+func (d *Digest) report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Collective usage from %s to %s (%d days):\n", d.From, d.To, d.Days)
+	if d.Invocations == 0 {
+		b.WriteString("- No invocations\n")
+	} else {
+		fmt.Fprintf(&b, "- %d invocations, %.1f%% succeeded\n", d.Invocations, 100*d.SuccessRate)
+		fmt.Fprintf(&b, "- Routing: %s\n", describeCounts(d.Routes))
+		if len(d.Intents) > 0 {
+			fmt.Fprintf(&b, "- Intents: %s\n", describeCounts(d.Intents))
+		}
+	}
+	if d.FeedbackOutcomes > 0 {
+		fmt.Fprintf(&b, "- Feedback: %d outcomes, %.1f%% successful\n", d.FeedbackOutcomes, 100*d.FeedbackSuccessRate)
+	}
+	fmt.Fprintf(&b, "- Breakthroughs: %d\n", d.Breakthroughs)
+
+	if len(d.Agents) > 0 {
+		b.WriteString("Busiest agents:\n")
+		for _, trend := range d.Agents {
+			fmt.Fprintf(&b, "- %s: %d invocations, %.1f%% succeeded", trend.Agent, trend.Invocations, 100*trend.SuccessRate)
+			if d.Days >= 2 {
+				fmt.Fprintf(&b, ", %s", describeChange(trend.Change))
+			}
+			if trend.Breakthroughs > 0 {
+				fmt.Fprintf(&b, ", %d breakthroughs", trend.Breakthroughs)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// describeCounts renders counts for the report, largest first.
+func describeCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for i, key := range keys {
+		keys[i] = fmt.Sprintf("%s %d", key, counts[key])
+	}
+	return strings.Join(keys, ", ")
+}
+
+// rate returns part/whole, or 0 for an empty whole.
+func rate(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// change returns the relative change from previous to recent.
+func change(previous, recent int) float64 {
+	if previous == 0 {
+		if recent == 0 {
+			return 0
+		}
+		return 1
+	}
+	return float64(recent-previous) / float64(previous)
+}
+
+// describeChange renders a relative change for the report.
+func describeChange(c float64) string {
+	switch {
+	case c > 0:
+		return fmt.Sprintf("up %.0f%%", 100*c)
+	case c < 0:
+		return fmt.Sprintf("down %.0f%%", -100*c)
+	default:
+		return "steady"
+	}
+}
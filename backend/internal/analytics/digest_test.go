@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	a := newTestAnalytics(30)
+	// APEX doubles from the earlier half of the week to the later one;
+	// CIPHER appears only on the middle day
+	for i := 0; i < 2; i++ {
+		a.RecordInvocation("APEX", "mention", "", true, testNow.AddDate(0, 0, -6))
+	}
+	for i := 0; i < 4; i++ {
+		a.RecordInvocation("APEX", "direct", "code-gen", i > 0, testNow)
+	}
+	a.RecordInvocation("CIPHER", "mention", "review", true, testNow.AddDate(0, 0, -3))
+	a.RecordFeedback("APEX", 4, 1)
+	a.RecordBreakthrough(Breakthrough{Agents: []string{"APEX"}, Time: testNow})
+
+	d := a.Digest(7)
+	if d.From != "2026-03-08" || d.To != "2026-03-14" || d.Days != 7 {
+		t.Errorf("expected the week to 2026-03-14, got %s to %s (%d days)", d.From, d.To, d.Days)
+	}
+	if d.Invocations != 7 || d.SuccessRate != 6.0/7 {
+		t.Errorf("expected 7 invocations at 6/7 success, got %d at %v", d.Invocations, d.SuccessRate)
+	}
+	if d.FeedbackOutcomes != 5 || d.FeedbackSuccessRate != 0.8 || d.Breakthroughs != 1 {
+		t.Errorf("expected 5 outcomes at 0.8 and 1 breakthrough, got %d at %v and %d", d.FeedbackOutcomes, d.FeedbackSuccessRate, d.Breakthroughs)
+	}
+	if d.Intents["code-gen"] != 4 || d.Intents["review"] != 1 {
+		t.Errorf("expected 4 code-gen and 1 review queries, got %v", d.Intents)
+	}
+	if len(d.Agents) != 2 || d.Agents[0].Agent != "APEX" {
+		t.Fatalf("expected APEX first of 2 agents, got %+v", d.Agents)
+	}
+	if apex := d.Agents[0]; apex.Previous != 2 || apex.Recent != 4 || apex.Change != 1 {
+		t.Errorf("expected APEX up from 2 to 4, got %+v", apex)
+	}
+	if cipher := d.Agents[1]; cipher.Previous != 0 || cipher.Recent != 0 || cipher.Change != 0 {
+		t.Errorf("expected the middle day in neither half, got %+v", cipher)
+	}
+
+	for _, want := range []string{"7 invocations, 85.7% succeeded", "Routing: direct 4, mention 3", "Intents: code-gen 4, review 1", "APEX: 6 invocations", "up 100%", "1 breakthroughs"} {
+		if !strings.Contains(d.Report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, d.Report)
+		}
+	}
+}
+
+func TestDigest_Empty(t *testing.T) {
+	d := newTestAnalytics(30).Digest(7)
+	if d.Invocations != 0 || d.SuccessRate != 0 || len(d.Agents) != 0 {
+		t.Errorf("expected an empty digest, got %+v", d)
+	}
+	if !strings.Contains(d.Report, "No invocations") {
+		t.Errorf("expected report to say there were no invocations, got:\n%s", d.Report)
+	}
+}
+
+func TestChange(t *testing.T) {
+	tests := []struct {
+		previous, recent int
+		want             float64
+		describe         string
+	}{
+		{4, 2, -0.5, "down 50%"},
+		{2, 2, 0, "steady"},
+		{0, 3, 1, "up 100%"},
+		{0, 0, 0, "steady"},
+	}
+	for _, tt := range tests {
+		got := change(tt.previous, tt.recent)
+		if got != tt.want || describeChange(got) != tt.describe {
+			t.Errorf("change(%d, %d): expected %v (%s), got %v (%s)", tt.previous, tt.recent, tt.want, tt.describe, got, describeChange(got))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package analytics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// DefaultDigestDays is the window of a digest when none is requested.
+const DefaultDigestDays = 7
+
+// RollupsResponse is the body of GET /admin/analytics.
+type RollupsResponse struct {
+	Rollups []*DailyRollup `json:"rollups"`
+}
+
+// ServeRollups handles GET /admin/analytics - returns the daily rollups of
+// the last ?days= days, all retained days by default.
+func (a *Analytics) ServeRollups(w http.ResponseWriter, r *http.Request) {
+	days, ok := parseDays(w, r, a.config.Retention)
+	if !ok {
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, &RollupsResponse{Rollups: a.Rollups(days)})
+}
+
+// ServeDigest handles GET /admin/analytics/digest - returns a summary of
+// the last ?days= days, a week by default.
+func (a *Analytics) ServeDigest(w http.ResponseWriter, r *http.Request) {
+	days, ok := parseDays(w, r, DefaultDigestDays)
+	if !ok {
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, a.Digest(days))
+}
+
+// parseDays reads the days query parameter, writing a 400 response if it
+// is not a positive number.
+func parseDays(w http.ResponseWriter, r *http.Request, defaultDays int) (int, bool) {
+	value := r.URL.Query().Get("days")
+	if value == "" {
+		return defaultDays, true
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		errdefs.WriteError(w, "days must be a positive number", http.StatusBadRequest)
+		return 0, false
+	}
+	return days, true
+}
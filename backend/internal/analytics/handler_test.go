@@ -0,0 +1,50 @@
+package analytics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeRollups(t *testing.T) {
+	a := newTestAnalytics(30)
+	a.RecordInvocation("APEX", "mention", "", true, testNow)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/analytics?days=3", nil)
+	w := httptest.NewRecorder()
+	a.ServeRollups(w, req)
+
+	var resp RollupsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Rollups) != 3 || resp.Rollups[2].Invocations != 1 {
+		t.Errorf("expected 3 days ending with today's invocation, got %+v", resp.Rollups)
+	}
+}
+
+func TestServeDigest(t *testing.T) {
+	a := newTestAnalytics(30)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/analytics/digest", nil)
+	w := httptest.NewRecorder()
+	a.ServeDigest(w, req)
+
+	var digest Digest
+	if err := json.Unmarshal(w.Body.Bytes(), &digest); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if digest.Days != DefaultDigestDays || digest.Report == "" {
+		t.Errorf("expected a %d day digest with a report, got %+v", DefaultDigestDays, digest)
+	}
+
+	for _, days := range []string{"0", "-1", "week"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics/digest?days="+days, nil)
+		w := httptest.NewRecorder()
+		a.ServeDigest(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("days=%s: expected status 400, got %d", days, w.Code)
+		}
+	}
+}
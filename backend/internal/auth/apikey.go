@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// APIKeyPrefix starts every API key, telling keys from OIDC tokens.
+const APIKeyPrefix = "eac_"
+
+// APIKeyIssuer is the issuer of the claims an API key authenticates with.
+const APIKeyIssuer = "apikey"
+
+// Scopes an API key can be granted. OIDC tokens have every scope.
+const (
+	// ScopeAgents invokes agents, workflows and teams
+	ScopeAgents = "agents"
+	// ScopeMemory queries the knowledge graph and embeddings
+	ScopeMemory = "memory"
+	// ScopeMemoryWrite also changes memory, with the editor role
+	ScopeMemoryWrite = "memory:write"
+	// ScopeSessions manages the caller's sessions and preferences
+	ScopeSessions = "sessions"
+	// ScopeTools calls agent tools directly
+	ScopeTools = "tools"
+	// ScopeAdmin uses the admin API, for subjects also in ADMIN_SUBJECTS,
+	// with the admin role
+	ScopeAdmin = "admin"
+	// ScopeAll grants every scope
+	ScopeAll = "*"
+)
+
+// scopes are the scopes a key can be granted.
+var scopes = []string{ScopeAgents, ScopeMemory, ScopeMemoryWrite, ScopeSessions, ScopeTools, ScopeAdmin, ScopeAll}
+
+// impliedScopes are the scopes granted along with others.
+var impliedScopes = map[string]string{ScopeMemoryWrite: ScopeMemory}
+
+// ErrInvalidAPIKey is returned for API keys that are unknown or expired.
+var ErrInvalidAPIKey = errdefs.New(errdefs.ErrUnauthorized, "invalid API key")
+
+// keyIDPattern matches the IDs keys are listed under.
+var keyIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// APIKey is a key as the server stores it: only the SHA-256 of the key is
+// kept, so the file holding it need not be secret.
+type APIKey struct {
+	// ID names the key, e.g. for logs and rotation
+	ID string `yaml:"id"`
+	// SHA256 is the hex SHA-256 of the whole key
+	SHA256 string `yaml:"sha256"`
+	// Subject is who requests with the key are made as; empty is
+	// apikey:<id>
+	Subject string `yaml:"subject"`
+	// Scopes are the parts of the API the key may be used for
+	Scopes []string `yaml:"scopes"`
+	// Expires is when the key stops working; zero never
+	Expires time.Time `yaml:"expires"`
+}
+
+// APIKeys are the keys requests may authenticate with.
+type APIKeys struct {
+	byHash map[[sha256.Size]byte]APIKey
+	now    func() time.Time
+}
+
+// apiKeysFile is the YAML file of API keys.
+type apiKeysFile struct {
+	Keys []APIKey `yaml:"keys"`
+}
+
+// LoadAPIKeys reads and validates a YAML file of API keys.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	return ParseAPIKeys(data)
+}
+
+// ParseAPIKeys decodes and validates YAML API keys. Unknown fields are
+// errors, so misspelled keys are caught at load time.
+func ParseAPIKeys(data []byte) (*APIKeys, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var file apiKeysFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys YAML: %w", err)
+	}
+
+	var problems []error
+	keys := &APIKeys{byHash: make(map[[sha256.Size]byte]APIKey), now: time.Now}
+	seen := make(map[string]bool)
+	for i, key := range file.Keys {
+		if !keyIDPattern.MatchString(key.ID) {
+			problems = append(problems, fmt.Errorf("key %d: id %q must be lowercase letters, digits and dashes", i, key.ID))
+		} else if seen[key.ID] {
+			problems = append(problems, fmt.Errorf("key %d: %s is duplicated", i, key.ID))
+		}
+		seen[key.ID] = true
+
+		var hash [sha256.Size]byte
+		if decoded, err := hex.DecodeString(key.SHA256); err != nil || len(decoded) != sha256.Size {
+			problems = append(problems, fmt.Errorf("key %s: sha256 must be 64 hex digits", key.ID))
+		} else {
+			copy(hash[:], decoded)
+		}
+		if len(key.Scopes) == 0 {
+			problems = append(problems, fmt.Errorf("key %s: at least one scope is required", key.ID))
+		}
+		for _, scope := range key.Scopes {
+			if !containsScope(scopes, scope) {
+				problems = append(problems, fmt.Errorf("key %s: unknown scope %q", key.ID, scope))
+			}
+		}
+		if key.Subject == "" {
+			key.Subject = APIKeyIssuer + ":" + key.ID
+		}
+		if other, ok := keys.byHash[hash]; ok && len(key.SHA256) == 2*sha256.Size {
+			problems = append(problems, fmt.Errorf("key %s: sha256 is also %s's", key.ID, other.ID))
+		}
+		keys.byHash[hash] = key
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return keys, nil
+}
+
+// Len returns how many keys there are.
+func (k *APIKeys) Len() int {
+	return len(k.byHash)
+}
+
+// Validate returns the claims of a key: its subject, its scopes and the
+// roles they grant.
+func (k *APIKeys) Validate(key string) (*Claims, error) {
+	stored, ok := k.byHash[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	claims := &Claims{Subject: stored.Subject, Issuer: APIKeyIssuer, Scopes: stored.Scopes, Roles: rolesForScopes(stored.Scopes)}
+	if !stored.Expires.IsZero() {
+		if !k.now().Before(stored.Expires) {
+			return nil, fmt.Errorf("%w: key %s expired", ErrInvalidAPIKey, stored.ID)
+		}
+		claims.ExpiresAt = stored.Expires.Unix()
+	}
+	return claims, nil
+}
+
+// NewAPIKey generates a key and its SHA-256, the hash to list it under.
+func NewAPIKey() (key, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + hex.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(key))
+	return key, hex.EncodeToString(sum[:]), nil
+}
+
+// HasScope reports whether claims grant a scope, directly or along with
+// another. Claims without scopes, from OIDC tokens, grant every scope.
+func (c *Claims) HasScope(scope string) bool {
+	if c.Scopes == nil || containsScope(c.Scopes, scope) || containsScope(c.Scopes, ScopeAll) {
+		return true
+	}
+	for _, granted := range c.Scopes {
+		if impliedScopes[granted] == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// containsScope reports whether scopes holds scope.
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	keys, err := ParseAPIKeys([]byte(`
+keys:
+  - id: ci-indexer
+    sha256: ` + hash + `
+    scopes: [memory]
+  - id: ops
+    sha256: ` + strings.Repeat("cd", 32) + `
+    subject: ops@example.com
+    scopes: ["*"]
+`))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	if keys.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", keys.Len())
+	}
+
+	for _, tc := range []struct {
+		name, yaml, problem string
+	}{
+		{"bad id", "keys:\n  - {id: CI, sha256: " + hash + ", scopes: [agents]}", "lowercase"},
+		{"duplicate id", "keys:\n  - {id: ci, sha256: " + hash + ", scopes: [agents]}\n  - {id: ci, sha256: " + strings.Repeat("cd", 32) + ", scopes: [agents]}", "duplicated"},
+		{"bad hash", "keys:\n  - {id: ci, sha256: abc, scopes: [agents]}", "64 hex digits"},
+		{"duplicate hash", "keys:\n  - {id: ci, sha256: " + hash + ", scopes: [agents]}\n  - {id: cd, sha256: " + hash + ", scopes: [agents]}", "also ci's"},
+		{"no scopes", "keys:\n  - {id: ci, sha256: " + hash + "}", "at least one scope"},
+		{"unknown scope", "keys:\n  - {id: ci, sha256: " + hash + ", scopes: [everything]}", `unknown scope "everything"`},
+		{"unknown field", "keys:\n  - {id: ci, sha256: " + hash + ", scopes: [agents], scope: admin}", "field scope not found"},
+	} {
+		if _, err := ParseAPIKeys([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.problem) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.problem, err)
+		}
+	}
+}
+
+func TestAPIKeysValidate(t *testing.T) {
+	key, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		t.Errorf("expected a key starting with %s, got %s", APIKeyPrefix, key)
+	}
+	keys, err := ParseAPIKeys([]byte("keys:\n  - {id: ci, sha256: " + hash + ", scopes: [agents, memory], expires: 2030-01-01T00:00:00Z}"))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	keys.now = func() time.Time { return time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	claims, err := keys.Validate(key)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if claims.Subject != "apikey:ci" || claims.Issuer != APIKeyIssuer {
+		t.Errorf("expected apikey:ci issued by %s, got %s by %s", APIKeyIssuer, claims.Subject, claims.Issuer)
+	}
+	if !claims.HasScope(ScopeMemory) || claims.HasScope(ScopeAdmin) {
+		t.Errorf("expected the memory scope and not admin, got %v", claims.Scopes)
+	}
+
+	if _, err := keys.Validate(key + "0"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey for an unknown key, got %v", err)
+	}
+	keys.now = func() time.Time { return time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC) }
+	if _, err := keys.Validate(key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey for an expired key, got %v", err)
+	}
+}
+
+func TestClaimsHasScope(t *testing.T) {
+	if !(&Claims{}).HasScope(ScopeAdmin) {
+		t.Error("expected claims without scopes, from OIDC, to have every scope")
+	}
+	if !(&Claims{Scopes: []string{ScopeAll}}).HasScope(ScopeAdmin) {
+		t.Error("expected the * scope to grant every scope")
+	}
+	if (&Claims{Scopes: []string{}}).HasScope(ScopeAgents) {
+		t.Error("expected empty scopes to grant none")
+	}
+}
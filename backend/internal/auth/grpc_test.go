@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	cfg, tokenString, cleanup := setupMockOIDC(t)
+	defer cleanup()
+	interceptor := NewMiddleware(cfg).UnaryInterceptor("/test.Service/Public")
+
+	var claims *Claims
+	handler := func(ctx context.Context, req any) (any, error) {
+		claims = GetClaims(ctx)
+		return "ok", nil
+	}
+	call := func(method, authorization string) error {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		claims = nil
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	if err := call("/test.Service/Private", "Bearer "+tokenString); err != nil || claims == nil || claims.Subject != "test-user" {
+		t.Errorf("expected the valid token's claims, got %v (%v)", claims, err)
+	}
+	for _, tc := range []struct {
+		method, authorization, message string
+	}{
+		{"/test.Service/Private", "", "Authorization header required"},
+		{"/test.Service/Private", "Basic abc", "Invalid authorization header format"},
+		{"/test.Service/Private", "Bearer invalid-token", "Invalid token"},
+		{"/test.Service/Public", "Bearer invalid-token", "Invalid token"},
+	} {
+		err := call(tc.method, tc.authorization)
+		if s, _ := status.FromError(err); s.Code() != codes.Unauthenticated || s.Message() != tc.message {
+			t.Errorf("%s with %q: expected Unauthenticated %q, got %v", tc.method, tc.authorization, tc.message, err)
+		}
+	}
+	if err := call("/test.Service/Public", ""); err != nil || claims != nil {
+		t.Errorf("expected a public method served without a token, got %v", err)
+	}
+
+	disabled := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com"}).UnaryInterceptor()
+	if _, err := disabled(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Private"}, handler); err != nil {
+		t.Errorf("expected every call served with authentication disabled, got %v", err)
+	}
+}
+
+func TestScopeInterceptor(t *testing.T) {
+	interceptor := NewMiddleware(&config.OIDCConfig{}).ScopeInterceptor(map[string]string{"test.Memory": ScopeMemory})
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method string, claims *Claims) error {
+		ctx := context.Background()
+		if claims != nil {
+			ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	key := &Claims{Subject: "apikey:ci", Scopes: []string{ScopeAgents}}
+	if err := call("/test.Memory/Query", key); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a key without the memory scope, got %v", err)
+	}
+	if err := call("/test.Agents/Invoke", key); err != nil {
+		t.Errorf("expected a service without a scope served, got %v", err)
+	}
+	if err := call("/test.Memory/Query", &Claims{Subject: "oidc-user"}); err != nil {
+		t.Errorf("expected an OIDC token served, got %v", err)
+	}
+	if err := call("/test.Memory/Query", nil); err != nil {
+		t.Errorf("expected a call without credentials served, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package auth provides authentication middleware and GitHub App
+// installation token verification.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+)
+
+// InstallationContextKey is the context key for storing a verified
+// installation.
+const InstallationContextKey contextKey = "installation"
+
+// maxInstallationPages bounds how many pages of repositories are read for
+// one token.
+const maxInstallationPages = 10
+
+// Installation is what a verified GitHub App installation token can
+// access.
+type Installation struct {
+	// Repositories are the "owner/name" repositories the token can access
+	Repositories []string
+}
+
+// CanAccess reports whether the installation can access a repository,
+// ignoring case.
+func (i *Installation) CanAccess(repository string) bool {
+	for _, repo := range i.Repositories {
+		if strings.EqualFold(repo, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedInstallation holds a verified installation with expiration.
+type cachedInstallation struct {
+	installation *Installation
+	expiresAt    time.Time
+}
+
+// InstallationVerifier verifies the GitHub App installation tokens CI jobs
+// send, by asking the GitHub API which repositories a token can access.
+// Only installation tokens can list their repositories, so personal
+// tokens are rejected.
+type InstallationVerifier struct {
+	apiURL     string
+	httpClient *http.Client
+	// owners restricts installations to repositories of these owners;
+	// empty accepts any owner
+	owners  []string
+	enabled bool
+
+	cache    map[string]cachedInstallation
+	cacheMu  sync.Mutex
+	cacheTTL time.Duration
+}
+
+// NewInstallationVerifier creates a verifier. Verification is enabled
+// when a GitHub App ID is configured.
+func NewInstallationVerifier(cfg *config.GitHubConfig) *InstallationVerifier {
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &InstallationVerifier{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		owners:   cfg.ActionsAllowedOwners,
+		enabled:  cfg.AppID != "",
+		cache:    make(map[string]cachedInstallation),
+		cacheTTL: 5 * time.Minute,
+	}
+}
+
+// Authenticate is HTTP middleware that verifies an installation token in
+// the Authorization header ("Bearer" or "token" scheme) and adds the
+// installation to the request context. It returns 401 for missing or
+// invalid tokens and 403 for installations outside the allowed owners.
+// If verification is not enabled, requests pass through.
+func (v *InstallationVerifier) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !v.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			return
+		}
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || (strings.ToLower(parts[0]) != "bearer" && strings.ToLower(parts[0]) != "token") {
+			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+			return
+		}
+
+		installation, err := v.Verify(r.Context(), parts[1])
+		if err != nil {
+			log.Printf("Installation token verification failed: %v", err)
+			http.Error(w, "Invalid installation token", http.StatusUnauthorized)
+			return
+		}
+		if len(installation.Repositories) == 0 {
+			http.Error(w, "Installation has no allowed repositories", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), InstallationContextKey, installation)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Verify checks an installation token with the GitHub API and returns the
+// repositories it can access, limited to the allowed owners. Verified
+// tokens are cached for a few minutes; tokens are cached by hash, never
+// kept in memory as given.
+func (v *InstallationVerifier) Verify(ctx context.Context, token string) (*Installation, error) {
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	v.cacheMu.Lock()
+	if cached, ok := v.cache[key]; ok && time.Now().Before(cached.expiresAt) {
+		v.cacheMu.Unlock()
+		return cached.installation, nil
+	}
+	v.cacheMu.Unlock()
+
+	repositories, err := v.fetchRepositories(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	installation := &Installation{}
+	for _, repo := range repositories {
+		if v.allowed(repo) {
+			installation.Repositories = append(installation.Repositories, repo)
+		}
+	}
+
+	now := time.Now()
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+	for k, cached := range v.cache {
+		if now.After(cached.expiresAt) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = cachedInstallation{installation: installation, expiresAt: now.Add(v.cacheTTL)}
+	return installation, nil
+}
+
+// allowed reports whether a repository's owner is allowed.
+func (v *InstallationVerifier) allowed(repository string) bool {
+	if len(v.owners) == 0 {
+		return true
+	}
+	owner, _, _ := strings.Cut(repository, "/")
+	for _, allowed := range v.owners {
+		if strings.EqualFold(owner, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRepositories lists the repositories an installation token can
+// access from GET /installation/repositories.
+func (v *InstallationVerifier) fetchRepositories(ctx context.Context, token string) ([]string, error) {
+	var repositories []string
+	for page := 1; page <= maxInstallationPages; page++ {
+		url := fmt.Sprintf("%s/installation/repositories?per_page=100&page=%d", v.apiURL, page)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := v.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var body struct {
+			TotalCount   int `json:"total_count"`
+			Repositories []struct {
+				FullName string `json:"full_name"`
+			} `json:"repositories"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("installation repositories endpoint returned status %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode installation repositories: %w", err)
+		}
+
+		for _, repo := range body.Repositories {
+			repositories = append(repositories, repo.FullName)
+		}
+		if len(body.Repositories) == 0 || len(repositories) >= body.TotalCount {
+			break
+		}
+	}
+	return repositories, nil
+}
+
+// GetInstallation retrieves the verified installation from the request
+// context. Returns nil if verification is not enabled.
+func GetInstallation(ctx context.Context) *Installation {
+	installation, ok := ctx.Value(InstallationContextKey).(*Installation)
+	if !ok {
+		return nil
+	}
+	return installation
+}
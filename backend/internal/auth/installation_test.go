@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+)
+
+// newInstallationServer mocks GET /installation/repositories, accepting
+// only the given token and serving its repositories one per page.
+func newInstallationServer(t *testing.T, token string, repositories []string, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if r.URL.Path != "/installation/repositories" || r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var page int
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		body := map[string]interface{}{"total_count": len(repositories), "repositories": []interface{}{}}
+		if page >= 1 && page <= len(repositories) {
+			body["repositories"] = []map[string]string{{"full_name": repositories[page-1]}}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInstallationVerifierVerify(t *testing.T) {
+	var calls int32
+	server := newInstallationServer(t, "ghs_valid", []string{"octo-org/api", "octo-org/web", "other/lib"}, &calls)
+	verifier := NewInstallationVerifier(&config.GitHubConfig{AppID: "1", APIURL: server.URL})
+
+	installation, err := verifier.Verify(context.Background(), "ghs_valid")
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	if len(installation.Repositories) != 3 {
+		t.Fatalf("expected 3 repositories across pages, got %v", installation.Repositories)
+	}
+	if !installation.CanAccess("Octo-Org/API") || installation.CanAccess("octo-org/secret") {
+		t.Error("expected access checks to match repositories ignoring case")
+	}
+
+	// Verified tokens are cached
+	before := atomic.LoadInt32(&calls)
+	if _, err := verifier.Verify(context.Background(), "ghs_valid"); err != nil {
+		t.Fatalf("expected cached token, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != before {
+		t.Error("expected cached verification not to call GitHub")
+	}
+
+	if _, err := verifier.Verify(context.Background(), "ghp_personal"); err == nil {
+		t.Error("expected error for a token GitHub rejects")
+	}
+	if _, err := verifier.Verify(context.Background(), ""); err == nil {
+		t.Error("expected error for empty token")
+	}
+}
+
+func TestInstallationVerifierAllowedOwners(t *testing.T) {
+	var calls int32
+	server := newInstallationServer(t, "ghs_valid", []string{"octo-org/api", "other/lib"}, &calls)
+	verifier := NewInstallationVerifier(&config.GitHubConfig{
+		AppID:                "1",
+		APIURL:               server.URL,
+		ActionsAllowedOwners: []string{"OCTO-ORG"},
+	})
+
+	installation, err := verifier.Verify(context.Background(), "ghs_valid")
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	if len(installation.Repositories) != 1 || installation.Repositories[0] != "octo-org/api" {
+		t.Errorf("expected only octo-org repositories, got %v", installation.Repositories)
+	}
+}
+
+func TestInstallationVerifierAuthenticate(t *testing.T) {
+	var calls int32
+	server := newInstallationServer(t, "ghs_valid", []string{"octo-org/api"}, &calls)
+	verifier := NewInstallationVerifier(&config.GitHubConfig{AppID: "1", APIURL: server.URL})
+
+	var got *Installation
+	handler := verifier.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetInstallation(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"no header", "", http.StatusUnauthorized},
+		{"bad scheme", "Basic abc", http.StatusUnauthorized},
+		{"invalid token", "Bearer ghp_personal", http.StatusUnauthorized},
+		{"bearer", "Bearer ghs_valid", http.StatusOK},
+		{"token scheme", "token ghs_valid", http.StatusOK},
+	}
+	for _, tt := range tests {
+		got = nil
+		req := httptest.NewRequest("POST", "/integrations/actions", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if tt.status == http.StatusOK && (got == nil || !got.CanAccess("octo-org/api")) {
+			t.Errorf("%s: expected installation in context, got %+v", tt.name, got)
+		}
+	}
+
+	// A token whose repositories are all outside the allowed owners
+	restricted := NewInstallationVerifier(&config.GitHubConfig{AppID: "1", APIURL: server.URL, ActionsAllowedOwners: []string{"elite-labs"}})
+	req := httptest.NewRequest("POST", "/integrations/actions", nil)
+	req.Header.Set("Authorization", "Bearer ghs_valid")
+	w := httptest.NewRecorder()
+	restricted.Authenticate(handler).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for a disallowed owner, got %d", w.Code)
+	}
+}
+
+func TestInstallationVerifierDisabled(t *testing.T) {
+	verifier := NewInstallationVerifier(&config.GitHubConfig{})
+
+	called := false
+	handler := verifier.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if GetInstallation(r.Context()) != nil {
+			t.Error("expected no installation when verification is disabled")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/integrations/actions", nil))
+	if !called {
+		t.Error("expected request to pass through when verification is disabled")
+	}
+}
@@ -3,11 +3,13 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
 // contextKey is a type for context keys to avoid collisions.
@@ -16,10 +18,20 @@ type contextKey string
 // ClaimsContextKey is the context key for storing claims.
 const ClaimsContextKey contextKey = "claims"
 
+var (
+	// errMissingToken is returned for requests without a bearer token
+	errMissingToken = errdefs.New(errdefs.ErrUnauthorized, "Authorization header required")
+	// errTokenFormat is returned for credentials that are not a bearer token
+	errTokenFormat = errdefs.New(errdefs.ErrUnauthorized, "Invalid authorization header format")
+)
+
 // Middleware creates authentication middleware for protecting routes.
 type Middleware struct {
 	validator *OIDCValidator
 	enabled   bool
+	// keys are the API keys accepted alongside OIDC tokens; nil accepts
+	// none
+	keys *APIKeys
 }
 
 // NewMiddleware creates a new authentication middleware.
@@ -33,6 +45,16 @@ func NewMiddleware(cfg *config.OIDCConfig) *Middleware {
 	}
 }
 
+// SetAPIKeys sets the API keys accepted alongside OIDC tokens, as bearer
+// tokens. Keys enable authentication even without an OIDC client ID, and
+// OIDC tokens are then rejected. Set before the middleware serves requests.
+func (m *Middleware) SetAPIKeys(keys *APIKeys) {
+	m.keys = keys
+	if keys != nil && keys.Len() > 0 {
+		m.enabled = true
+	}
+}
+
 // Authenticate is HTTP middleware that validates authentication tokens.
 // It returns 401 for missing or invalid tokens when authentication is enabled.
 func (m *Middleware) Authenticate(next http.Handler) http.Handler {
@@ -43,31 +65,12 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Expect "Bearer <token>" format
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-		claims, err := m.validator.ValidateToken(token)
+		claims, err := m.authenticate(r.Header.Get("Authorization"))
 		if err != nil {
-			log.Printf("Token validation failed: %v", err)
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			http.Error(w, authErrorMessage(err), errdefs.HTTPStatus(err))
 			return
 		}
 
-		// Log successful authentication
-		log.Printf("Authenticated user: %s", claims.Subject)
-
 		// Add claims to request context
 		ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -80,44 +83,115 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 // If an invalid token is provided, the request is rejected with 401.
 func (m *Middleware) OptionalAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// If auth is not enabled, just proceed
-		if !m.enabled {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Extract token from Authorization header
+		// If auth is not enabled, or no token was provided, proceed
+		// without claims
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			// No token provided, allow request to proceed without claims
+		if !m.enabled || authHeader == "" {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Expect "Bearer <token>" format
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-		claims, err := m.validator.ValidateToken(token)
+		claims, err := m.authenticate(authHeader)
 		if err != nil {
-			log.Printf("Token validation failed: %v", err)
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			http.Error(w, authErrorMessage(err), errdefs.HTTPStatus(err))
 			return
 		}
 
-		// Log successful authentication
-		log.Printf("Authenticated user: %s", claims.Subject)
-
 		// Add claims to request context
 		ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// authenticate validates the bearer token of an Authorization header
+// value, for HTTP requests and gRPC calls alike.
+func (m *Middleware) authenticate(authHeader string) (*Claims, error) {
+	if authHeader == "" {
+		return nil, errMissingToken
+	}
+
+	// Expect "Bearer <token>" format
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, errTokenFormat
+	}
+
+	var claims *Claims
+	var err error
+	if strings.HasPrefix(parts[1], APIKeyPrefix) {
+		claims, err = m.validateAPIKey(parts[1])
+	} else {
+		claims, err = m.validator.ValidateToken(parts[1])
+	}
+	if err != nil {
+		log.Printf("Token validation failed: %v", err)
+		return nil, err
+	}
+
+	// Log successful authentication
+	log.Printf("Authenticated user: %s", claims.Subject)
+	return claims, nil
+}
+
+// validateAPIKey returns the claims of an API key.
+func (m *Middleware) validateAPIKey(key string) (*Claims, error) {
+	if m.keys == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return m.keys.Validate(key)
+}
+
+// authErrorMessage returns the message a failed authentication is
+// answered with. Validation failures are not detailed to the caller.
+func authErrorMessage(err error) string {
+	if errors.Is(err, errMissingToken) || errors.Is(err, errTokenFormat) {
+		return err.Error()
+	}
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return "Invalid API key"
+	}
+	return "Invalid token"
+}
+
+// Authorize is HTTP middleware that admits only the listed subjects,
+// rejecting others with 403. It must run after Authenticate. When
+// authentication is disabled every request is admitted.
+func (m *Middleware) Authorize(subjects []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		allowed[subject] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !m.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims := GetClaims(r.Context())
+			if claims == nil || !allowed[claims.Subject] {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope is HTTP middleware that rejects requests made with an API
+// key not granted a scope with 403. It must run after Authenticate or
+// OptionalAuth; requests with OIDC tokens or without credentials pass.
+func (m *Middleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims := GetClaims(r.Context()); claims != nil && !claims.HasScope(scope) {
+				http.Error(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // GetClaims retrieves claims from the request context.
 // Returns nil if no claims are present (unauthenticated request with optional auth).
 func GetClaims(ctx context.Context) *Claims {
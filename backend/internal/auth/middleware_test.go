@@ -9,6 +9,7 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -355,6 +356,49 @@ func TestOptionalAuthDisabled(t *testing.T) {
 	}
 }
 
+func TestAuthorize(t *testing.T) {
+	middleware := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com", ClientID: "test-client"})
+	handler := middleware.Authorize([]string{"admin"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		claims *Claims
+		want   int
+	}{
+		{"listed subject", &Claims{Subject: "admin"}, http.StatusOK},
+		{"other subject", &Claims{Subject: "test-user"}, http.StatusForbidden},
+		{"no claims", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/admin/features", nil)
+		if tt.claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, tt.claims))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestAuthorizeDisabled(t *testing.T) {
+	middleware := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com"})
+	handler := middleware.Authorize(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/admin/features", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 with authentication disabled, got %d", w.Code)
+	}
+}
+
 func TestGetClaimsNoClaims(t *testing.T) {
 	ctx := context.Background()
 	claims := GetClaims(ctx)
@@ -383,3 +427,45 @@ func TestGetClaimsWithClaims(t *testing.T) {
 		t.Errorf("expected subject '%s', got '%s'", expectedClaims.Subject, claims.Subject)
 	}
 }
+
+func TestMiddlewareAPIKeys(t *testing.T) {
+	key, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	keys, err := ParseAPIKeys([]byte("keys:\n  - {id: ci, sha256: " + hash + ", scopes: [memory]}"))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	// No OIDC client ID: the keys alone enable authentication
+	middleware := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com"})
+	middleware.SetAPIKeys(keys)
+
+	var claims *Claims
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaims(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(scope, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		middleware.Authenticate(middleware.RequireScope(scope)(handler)).ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(ScopeMemory, "Bearer "+key); w.Code != http.StatusOK || claims == nil || claims.Subject != "apikey:ci" {
+		t.Errorf("expected the key's claims, got %d with %v", w.Code, claims)
+	}
+	if w := serve(ScopeMemory, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a key, got %d", w.Code)
+	}
+	if w := serve(ScopeMemory, "Bearer "+APIKeyPrefix+"unknown"); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Invalid API key") {
+		t.Errorf("expected 401 Invalid API key, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(ScopeAdmin, "Bearer "+key); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "admin scope") {
+		t.Errorf("expected 403 for a scope the key lacks, got %d %q", w.Code, w.Body.String())
+	}
+}
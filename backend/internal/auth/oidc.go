@@ -15,14 +15,24 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
-// Claims represents the claims from a validated OIDC token.
+// ErrInvalidToken is returned for bearer tokens that fail validation.
+var ErrInvalidToken = errdefs.New(errdefs.ErrUnauthorized, "invalid token")
+
+// Claims represents the claims from a validated OIDC token or API key.
 type Claims struct {
 	Subject   string
 	Issuer    string
 	Audience  string
 	ExpiresAt int64
+	// Scopes limit an API key to parts of the API; nil, for OIDC tokens,
+	// is unlimited
+	Scopes []string
+	// Roles limit what the caller may change; nil, for OIDC tokens when
+	// no roles claim is configured, is unlimited
+	Roles []string
 }
 
 // JWKS represents a JSON Web Key Set.
@@ -80,10 +90,12 @@ func NewOIDCValidator(cfg *config.OIDCConfig) *OIDCValidator {
 //   - Validates the audience matches the configured OIDC_CLIENT_ID
 //   - Validates the token has not expired
 //
+// When a roles claim is configured, the token's roles are read from it.
+//
 // Returns an error if any validation step fails.
 func (v *OIDCValidator) ValidateToken(tokenString string) (*Claims, error) {
 	if tokenString == "" {
-		return nil, errors.New("token is required")
+		return nil, fmt.Errorf("%w: token is required", ErrInvalidToken)
 	}
 
 	// Parse and validate the token
@@ -111,17 +123,17 @@ func (v *OIDCValidator) ValidateToken(tokenString string) (*Claims, error) {
 		jwt.WithAudience(v.config.ClientID))
 
 	if err != nil {
-		return nil, fmt.Errorf("token validation failed: %w", err)
+		return nil, fmt.Errorf("%w: validation failed: %w", ErrInvalidToken, err)
 	}
 
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, ErrInvalidToken
 	}
 
 	// Extract claims
 	mapClaims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, errors.New("failed to parse claims")
+		return nil, fmt.Errorf("%w: failed to parse claims", ErrInvalidToken)
 	}
 
 	claims := &Claims{}
@@ -150,6 +162,10 @@ func (v *OIDCValidator) ValidateToken(tokenString string) (*Claims, error) {
 		claims.ExpiresAt = int64(exp)
 	}
 
+	if v.config.RolesClaim != "" {
+		claims.Roles = rolesFromClaim(mapClaims, v.config.RolesClaim)
+	}
+
 	return claims, nil
 }
 
@@ -188,6 +204,18 @@ func (v *OIDCValidator) getPublicKey(kid string) (*rsa.PublicKey, error) {
 	return key, nil
 }
 
+// CheckProvider fetches the provider's discovery document and signing keys,
+// reporting whether tokens can be verified. The keys are cached as on a
+// token's first validation.
+func (v *OIDCValidator) CheckProvider() (int, error) {
+	if err := v.refreshJWKS(); err != nil {
+		return 0, err
+	}
+	v.cacheMu.RLock()
+	defer v.cacheMu.RUnlock()
+	return len(v.cache.keys), nil
+}
+
 // refreshJWKS fetches and caches the JWKS from the OIDC provider.
 // It first retrieves the OIDC discovery document to obtain the JWKS URI,
 // then fetches the JWKS and parses all RSA public keys.
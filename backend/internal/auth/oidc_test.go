@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
@@ -46,8 +47,8 @@ func TestValidateTokenInvalidFormat(t *testing.T) {
 
 	// Test with non-JWT format
 	_, err := validator.ValidateToken("not-a-valid-jwt")
-	if err == nil {
-		t.Error("expected error for invalid token format")
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for invalid token format, got %v", err)
 	}
 }
 
@@ -164,6 +165,99 @@ func TestValidateTokenWithMockedJWKS(t *testing.T) {
 	}
 }
 
+func TestValidateTokenRoles(t *testing.T) {
+	// Generate RSA key pair
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	kid := "test-key-id"
+
+	// Create mock JWKS endpoint
+	jwksHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwks := JWKS{
+			Keys: []JWK{
+				{
+					Kty: "RSA",
+					Kid: kid,
+					Use: "sig",
+					Alg: "RS256",
+					N:   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+					E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jwks)
+	})
+
+	jwksServer := httptest.NewServer(jwksHandler)
+	defer jwksServer.Close()
+
+	// Create mock discovery endpoint
+	discoveryHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		discovery := OIDCDiscovery{
+			Issuer:  jwksServer.URL,
+			JWKSURI: jwksServer.URL + "/jwks",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(discovery)
+	})
+
+	discoveryServer := httptest.NewServer(discoveryHandler)
+	defer discoveryServer.Close()
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["sub"] = "test-user"
+		claims["iss"] = discoveryServer.URL
+		claims["aud"] = "test-client"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		tokenString, err := token.SignedString(privateKey)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return tokenString
+	}
+
+	cfg := &config.OIDCConfig{
+		Issuer:     discoveryServer.URL,
+		ClientID:   "test-client",
+		RolesClaim: "realm_access.roles",
+	}
+	validator := NewOIDCValidator(cfg)
+
+	claims, err := validator.ValidateToken(sign(jwt.MapClaims{
+		"realm_access": map[string]interface{}{"roles": []string{"editor"}},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !claims.HasRole(RoleEditor) || claims.HasRole(RoleAdmin) {
+		t.Errorf("expected the editor role from the roles claim, got %v", claims.Roles)
+	}
+
+	claims, err = validator.ValidateToken(sign(jwt.MapClaims{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.HasRole(RoleEditor) {
+		t.Errorf("expected no roles without the roles claim, got %v", claims.Roles)
+	}
+
+	// Without a roles claim configured, tokens hold every role
+	cfg.RolesClaim = ""
+	claims, err = NewOIDCValidator(cfg).ValidateToken(sign(jwt.MapClaims{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !claims.HasRole(RoleAdmin) {
+		t.Errorf("expected every role without a roles claim configured, got %v", claims.Roles)
+	}
+}
+
 func TestValidateTokenExpired(t *testing.T) {
 	// Generate RSA key pair
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Roles a caller can hold, each granting what the ones before it do.
+const (
+	// RoleReader queries agents and memory
+	RoleReader = "reader"
+	// RoleEditor also changes memory: imports knowledge and experiences,
+	// and records feedback
+	RoleEditor = "editor"
+	// RoleAdmin also rebuilds memory stores and replaces the models and
+	// parameters learned from them
+	RoleAdmin = "admin"
+)
+
+// roleRanks orders the roles by what they grant. Unknown roles rank zero
+// and grant nothing.
+var roleRanks = map[string]int{RoleReader: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// scopeRoles are the roles API key scopes grant; keys hold RoleReader
+// whatever their scopes.
+var scopeRoles = map[string]string{
+	ScopeMemoryWrite: RoleEditor,
+	ScopeAdmin:       RoleAdmin,
+	ScopeAll:         RoleAdmin,
+}
+
+// HasRole reports whether claims grant a role, held directly or through a
+// role above it. Claims without roles, from OIDC tokens when no roles
+// claim is configured, grant every role.
+func (c *Claims) HasRole(role string) bool {
+	if c.Roles == nil {
+		return true
+	}
+	for _, held := range c.Roles {
+		if roleRanks[held] >= roleRanks[role] && roleRanks[held] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rolesForScopes returns the roles an API key's scopes grant.
+func rolesForScopes(scopes []string) []string {
+	roles := []string{RoleReader}
+	for _, scope := range scopes {
+		if role, ok := scopeRoles[scope]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// rolesFromClaim returns the roles in a token's claim at path, a claim
+// name or a dotted path into nested claims such as realm_access.roles. The
+// claim may be a list of roles or a string of them separated by spaces or
+// commas. Tokens without it hold no roles, an empty rather than nil slice,
+// so they grant none.
+func rolesFromClaim(claims map[string]interface{}, path string) []string {
+	roles := []string{}
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return roles
+		}
+		value = nested[name]
+	}
+	switch value := value.(type) {
+	case string:
+		roles = append(roles, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })...)
+	case []interface{}:
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// RequireRole is HTTP middleware that rejects requests whose claims do not
+// grant a role with 403. It must run after Authenticate or OptionalAuth;
+// requests without credentials pass, as they do when authentication is
+// disabled.
+func (m *Middleware) RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims := GetClaims(r.Context()); claims != nil && !claims.HasRole(role) {
+				http.Error(w, "Requires the "+role+" role", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
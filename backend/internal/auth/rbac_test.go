@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+)
+
+func TestClaimsHasRole(t *testing.T) {
+	if !(&Claims{}).HasRole(RoleAdmin) {
+		t.Error("expected claims without roles, from OIDC without a roles claim, to have every role")
+	}
+	if (&Claims{Roles: []string{}}).HasRole(RoleReader) {
+		t.Error("expected empty roles to grant none")
+	}
+	editor := &Claims{Roles: []string{"unknown", RoleEditor}}
+	if !editor.HasRole(RoleReader) || !editor.HasRole(RoleEditor) || editor.HasRole(RoleAdmin) {
+		t.Errorf("expected the editor role to grant reader and editor only, got %v", editor.Roles)
+	}
+	if (&Claims{Roles: []string{"unknown"}}).HasRole("other") {
+		t.Error("expected unknown roles to grant nothing")
+	}
+}
+
+func TestAPIKeyRoles(t *testing.T) {
+	for _, tc := range []struct {
+		scopes []string
+		role   string
+	}{
+		{[]string{ScopeAgents, ScopeMemory}, RoleReader},
+		{[]string{ScopeMemoryWrite}, RoleEditor},
+		{[]string{ScopeAgents, ScopeAdmin}, RoleAdmin},
+		{[]string{ScopeAll}, RoleAdmin},
+	} {
+		key, hash, err := NewAPIKey()
+		if err != nil {
+			t.Fatalf("NewAPIKey: %v", err)
+		}
+		keys, err := ParseAPIKeys([]byte("keys:\n  - id: ci\n    sha256: " + hash + "\n    scopes: [\"" + strings.Join(tc.scopes, `", "`) + "\"]"))
+		if err != nil {
+			t.Fatalf("ParseAPIKeys: %v", err)
+		}
+		claims, err := keys.Validate(key)
+		if err != nil {
+			t.Fatalf("Validate: %v", err)
+		}
+		above := map[string]string{RoleReader: RoleEditor, RoleEditor: RoleAdmin}[tc.role]
+		if !claims.HasRole(tc.role) || (above != "" && claims.HasRole(above)) {
+			t.Errorf("scopes %v: expected the %s role, got %v", tc.scopes, tc.role, claims.Roles)
+		}
+	}
+	if !(&Claims{Scopes: []string{ScopeMemoryWrite}}).HasScope(ScopeMemory) {
+		t.Error("expected memory:write to grant the memory scope")
+	}
+}
+
+func TestRolesFromClaim(t *testing.T) {
+	claims := map[string]interface{}{
+		"roles":        []interface{}{"editor", 7, "admin"},
+		"scope":        "reader, editor",
+		"realm_access": map[string]interface{}{"roles": []interface{}{"admin"}},
+	}
+	for _, tc := range []struct {
+		path string
+		want []string
+	}{
+		{"roles", []string{"editor", "admin"}},
+		{"scope", []string{"reader", "editor"}},
+		{"realm_access.roles", []string{"admin"}},
+		{"groups", []string{}},
+		{"roles.nested", []string{}},
+	} {
+		got := rolesFromClaim(claims, tc.path)
+		if got == nil || len(got) != len(tc.want) {
+			t.Errorf("%s: expected %v, got %#v", tc.path, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: expected %v, got %v", tc.path, tc.want, got)
+			}
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	middleware := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com", ClientID: "test-client"})
+	handler := middleware.RequireRole(RoleEditor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		claims *Claims
+		want   int
+	}{
+		{"editor", &Claims{Subject: "a", Roles: []string{RoleEditor}}, http.StatusOK},
+		{"admin", &Claims{Subject: "a", Roles: []string{RoleAdmin}}, http.StatusOK},
+		{"reader", &Claims{Subject: "a", Roles: []string{RoleReader}}, http.StatusForbidden},
+		{"unlimited", &Claims{Subject: "a"}, http.StatusOK},
+		{"no claims", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/memory/ingest", nil)
+		if tt.claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, tt.claims))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestRequireRoleAdminRoutes(t *testing.T) {
+	// The admin API as the server mounts it: admin subjects may read it,
+	// but changing what it manages takes the admin role
+	middleware := NewMiddleware(&config.OIDCConfig{Issuer: "https://example.com", ClientID: "test-client"})
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	adminRole := middleware.RequireRole(RoleAdmin)
+	r := chi.NewRouter()
+	r.Use(middleware.RequireScope(ScopeAdmin), middleware.Authorize([]string{"ops"}))
+	r.Get("/admin/insights", ok)
+	r.With(adminRole).Put("/admin/features/{name}", ok)
+	r.With(adminRole).Post("/admin/insights/{id}/approve", ok)
+	r.With(adminRole).Post("/admin/insights/{id}/reject", ok)
+	r.With(adminRole).Post("/admin/memory/routing/replay", ok)
+	r.With(adminRole).Post("/admin/gitops/sync", ok)
+
+	writes := []string{
+		"PUT /admin/features/grounding",
+		"POST /admin/insights/i1/approve",
+		"POST /admin/insights/i1/reject",
+		"POST /admin/memory/routing/replay",
+		"POST /admin/gitops/sync",
+	}
+	serve := func(route string, claims *Claims) int {
+		method, path, _ := strings.Cut(route, " ")
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), ClaimsContextKey, claims))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	editor := &Claims{Subject: "ops", Scopes: []string{ScopeAdmin}, Roles: []string{RoleEditor}}
+	admin := &Claims{Subject: "ops", Scopes: []string{ScopeAdmin}, Roles: []string{RoleAdmin}}
+	if code := serve("GET /admin/insights", editor); code != http.StatusOK {
+		t.Errorf("expected an editor to read the admin API, got %d", code)
+	}
+	for _, route := range writes {
+		if code := serve(route, editor); code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for an editor, got %d", route, code)
+		}
+		if code := serve(route, admin); code != http.StatusOK {
+			t.Errorf("%s: expected 200 for an admin, got %d", route, code)
+		}
+	}
+}
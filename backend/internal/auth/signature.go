@@ -6,13 +6,19 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
+// ErrInvalidSignature is returned for webhook payloads whose signature
+// doesn't verify.
+var ErrInvalidSignature = errdefs.New(errdefs.ErrUnauthorized, "invalid signature")
+
 // SignatureMiddleware provides GitHub webhook signature verification.
 type SignatureMiddleware struct {
 	secret  string
@@ -61,7 +67,7 @@ func (m *SignatureMiddleware) VerifySignature(next http.Handler) http.Handler {
 		// Validate the signature
 		if err := m.validateSignature(signature, body); err != nil {
 			log.Printf("Signature validation failed: %v", err)
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			http.Error(w, "Invalid signature", errdefs.HTTPStatus(err))
 			return
 		}
 
@@ -75,14 +81,14 @@ func (m *SignatureMiddleware) VerifySignature(next http.Handler) http.Handler {
 func (m *SignatureMiddleware) validateSignature(signature string, body []byte) error {
 	// The signature should be in format "sha256=<hex>"
 	if !strings.HasPrefix(signature, "sha256=") {
-		return errors.New("signature must have sha256= prefix")
+		return fmt.Errorf("%w: must have sha256= prefix", ErrInvalidSignature)
 	}
 
 	// Extract the hex-encoded signature
 	signatureHex := strings.TrimPrefix(signature, "sha256=")
 	expectedSignature, err := hex.DecodeString(signatureHex)
 	if err != nil {
-		return errors.New("invalid signature format")
+		return fmt.Errorf("%w: not hex encoded", ErrInvalidSignature)
 	}
 
 	// Calculate the expected signature
@@ -92,7 +98,7 @@ func (m *SignatureMiddleware) validateSignature(signature string, body []byte) e
 
 	// Compare using constant-time comparison to prevent timing attacks
 	if !hmac.Equal(expectedSignature, actualSignature) {
-		return errors.New("signature mismatch")
+		return fmt.Errorf("%w: mismatch", ErrInvalidSignature)
 	}
 
 	return nil
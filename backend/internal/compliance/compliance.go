@@ -0,0 +1,440 @@
+// Package compliance compiles evidence packs for audits: the tool-call
+// audit trail, access control, retention policies and moderation decisions
+// of a period, each section mapped to the SOC 2 and ISO/IEC 27001 controls
+// it evidences. Packs are laid out as titled sections of facts and tables
+// so AEGIS, or a document renderer, can turn them into a report as is.
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/propagation"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/tools"
+)
+
+// maxTableRows bounds the rows of a pack's per-event tables; the counts
+// beside them cover every event.
+const maxTableRows = 1000
+
+// Frameworks controls are taken from.
+const (
+	FrameworkSOC2     = "SOC2"
+	FrameworkISO27001 = "ISO27001"
+)
+
+// Control statuses.
+const (
+	// StatusEvidenced controls have evidence in every section mapped to
+	// them
+	StatusEvidenced = "evidenced"
+	// StatusGap controls have a section whose evidence shows a gap
+	StatusGap = "gap"
+)
+
+// Pack section IDs.
+const (
+	SectionAuditLog      = "audit-log"
+	SectionAccessControl = "access-control"
+	SectionRetention     = "retention"
+	SectionModeration    = "moderation"
+)
+
+// Control is a control of a framework and the sections evidencing it.
+type Control struct {
+	Framework string `json:"framework"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	// Sections are the IDs of the pack sections evidencing the control
+	Sections []string `json:"sections"`
+	Status   string   `json:"status"`
+}
+
+// controls maps the SOC 2 Trust Services Criteria (2017) and ISO/IEC
+// 27001:2022 Annex A controls to the sections evidencing them.
+var controls = []Control{
+	{Framework: FrameworkSOC2, ID: "CC6.1", Title: "Logical access security", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkSOC2, ID: "CC6.2", Title: "Registration and authorization of users", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkSOC2, ID: "CC6.3", Title: "Role-based access and least privilege", Sections: []string{SectionAccessControl, SectionModeration}},
+	{Framework: FrameworkSOC2, ID: "CC7.2", Title: "Monitoring of system components", Sections: []string{SectionAuditLog}},
+	{Framework: FrameworkSOC2, ID: "CC7.3", Title: "Evaluation of security events", Sections: []string{SectionAuditLog, SectionModeration}},
+	{Framework: FrameworkSOC2, ID: "CC8.1", Title: "Authorization of changes", Sections: []string{SectionModeration}},
+	{Framework: FrameworkSOC2, ID: "C1.1", Title: "Retention of confidential information", Sections: []string{SectionRetention}},
+	{Framework: FrameworkSOC2, ID: "C1.2", Title: "Disposal of confidential information", Sections: []string{SectionRetention}},
+	{Framework: FrameworkISO27001, ID: "A.5.15", Title: "Access control", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkISO27001, ID: "A.5.18", Title: "Access rights", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkISO27001, ID: "A.5.25", Title: "Assessment and decision on information security events", Sections: []string{SectionModeration}},
+	{Framework: FrameworkISO27001, ID: "A.5.33", Title: "Protection of records", Sections: []string{SectionAuditLog, SectionRetention}},
+	{Framework: FrameworkISO27001, ID: "A.8.2", Title: "Privileged access rights", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkISO27001, ID: "A.8.5", Title: "Secure authentication", Sections: []string{SectionAccessControl}},
+	{Framework: FrameworkISO27001, ID: "A.8.10", Title: "Information deletion", Sections: []string{SectionRetention}},
+	{Framework: FrameworkISO27001, ID: "A.8.15", Title: "Logging", Sections: []string{SectionAuditLog}},
+	{Framework: FrameworkISO27001, ID: "A.8.16", Title: "Monitoring activities", Sections: []string{SectionAuditLog}},
+}
+
+// Pack is the evidence of a period, in sections.
+type Pack struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// From and To bound the period the audit trail and moderation
+	// decisions are taken from
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Tenant narrows the pack to one tenant; empty covers them all
+	Tenant   string    `json:"tenant,omitempty"`
+	Controls []Control `json:"controls"`
+	Sections []Section `json:"sections"`
+}
+
+// Section is the evidence for some controls.
+type Section struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Summary is a sentence or two on what the section shows
+	Summary string `json:"summary"`
+	// Controls are the controls the section evidences, as FRAMEWORK ID
+	Controls []string `json:"controls"`
+	Facts    []Fact   `json:"facts"`
+	Tables   []Table  `json:"tables"`
+	// Gaps are what an auditor would find missing
+	Gaps []string `json:"gaps"`
+}
+
+// Fact is a labeled value.
+type Fact struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Table is a table of text cells, one per column in each row.
+type Table struct {
+	Title   string     `json:"title"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// RetentionPolicy is how long a kind of data is kept and how it is
+// disposed of.
+type RetentionPolicy struct {
+	Data string
+	// Period is how long the data is kept; zero means the server does not
+	// expire it
+	Period   time.Duration
+	Disposal string
+}
+
+// Sources supply the evidence. Nil audit, tool or insight sources report
+// the subsystem as disabled.
+type Sources struct {
+	// Audit returns the tool calls recorded since a time, oldest first
+	Audit func(since time.Time) []tools.AuditEntry
+	// Tools returns the tenants' tool grants
+	Tools func() *tools.Config
+	// Insights returns the retained insights
+	Insights  func() []propagation.Insight
+	Retention []RetentionPolicy
+}
+
+// Reporter compiles evidence packs for a server.
+type Reporter struct {
+	cfg     *config.Config
+	sources Sources
+	now     func() time.Time
+}
+
+// New creates a reporter for a server configured with cfg.
+func New(cfg *config.Config, sources Sources) *Reporter {
+	return &Reporter{cfg: cfg, sources: sources, now: time.Now}
+}
+
+// Pack compiles the evidence of the last days, for one tenant or, if
+// tenant is empty, all of them.
+func (r *Reporter) Pack(tenant string, days int) *Pack {
+	now := r.now().UTC()
+	pack := &Pack{
+		GeneratedAt: now,
+		From:        now.AddDate(0, 0, -days),
+		To:          now,
+		Tenant:      tenant,
+	}
+	var entries []tools.AuditEntry
+	if r.sources.Audit != nil {
+		for _, entry := range r.sources.Audit(pack.From) {
+			if tenant == "" || entry.Tenant == tenant {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	pack.Sections = []Section{
+		r.auditLog(entries),
+		r.accessControl(tenant),
+		r.retention(),
+		r.moderation(pack, entries),
+	}
+	pack.Controls = mapControls(pack.Sections)
+	return pack
+}
+
+// auditLog is the evidence that tool calls are recorded.
+func (r *Reporter) auditLog(entries []tools.AuditEntry) Section {
+	section := Section{ID: SectionAuditLog, Title: "Audit log"}
+	if r.sources.Audit == nil {
+		section.Summary = "Agent tools are disabled, so agents make no calls outside the collective to audit."
+		section.Facts = []Fact{{"Agent tools", "disabled"}}
+		return section
+	}
+	section.Summary = fmt.Sprintf("Every tool call an agent makes is recorded in an append-only audit trail with its tenant, agent, caller and outcome. %d calls were recorded in the period.", len(entries))
+	destination := "server log"
+	if r.cfg.AuditLogPath != "" {
+		destination = r.cfg.AuditLogPath
+	} else {
+		section.Gaps = append(section.Gaps, "Tool calls are written to the server log rather than a dedicated audit file; set AUDIT_LOG_PATH.")
+	}
+	section.Facts = []Fact{
+		{"Audit trail", destination},
+		{"Format", "one JSON object per line, appended"},
+		{"Calls recorded", strconv.Itoa(len(entries))},
+	}
+
+	outcomes := make(map[string]int)
+	for _, entry := range entries {
+		outcomes[entry.Outcome]++
+	}
+	section.Tables = append(section.Tables, countTable("Calls by outcome", "Outcome", outcomes))
+
+	calls := Table{Title: "Tool calls", Columns: []string{"Time", "Tenant", "Agent", "Subject", "Tool", "Action", "Target", "Outcome", "Reference"}, Rows: make([][]string, 0)}
+	for _, entry := range latest(entries) {
+		calls.Rows = append(calls.Rows, []string{entry.Time.UTC().Format(time.RFC3339), entry.Tenant, entry.Agent, entry.Subject, entry.Tool, entry.Action, entry.Target, entry.Outcome, entry.Reference})
+	}
+	if len(entries) > maxTableRows {
+		calls.Title = fmt.Sprintf("Tool calls (latest %d of %d)", maxTableRows, len(entries))
+	}
+	section.Tables = append(section.Tables, calls)
+	return section
+}
+
+// accessControl is the evidence of who may call the API and what agents
+// may do.
+func (r *Reporter) accessControl(tenant string) Section {
+	section := Section{
+		ID:      SectionAccessControl,
+		Title:   "Access control",
+		Summary: "API callers authenticate with OIDC tokens or scoped API keys, webhooks are verified by signature, changing memory takes a role, the admin API is limited to named subjects, and agents act with a tenant's tools only as its grants allow.",
+	}
+	var methods []string
+	if r.cfg.OIDC.ClientID != "" {
+		methods = append(methods, "OIDC, issuer "+r.cfg.OIDC.Issuer)
+	}
+	if r.cfg.APIKeysConfig != "" {
+		methods = append(methods, "scoped API keys, stored hashed")
+	}
+	authentication := strings.Join(methods, "; ")
+	if len(methods) == 0 {
+		authentication = "disabled"
+		section.Gaps = append(section.Gaps, "API authentication is disabled, which also opens the admin API; set OIDC_CLIENT_ID.")
+	}
+	webhooks := "disabled"
+	if r.cfg.GitHub.WebhookSecret != "" {
+		webhooks = "HMAC-SHA256 signature"
+	} else {
+		section.Gaps = append(section.Gaps, "Copilot webhooks are not verified by signature; set GITHUB_WEBHOOK_SECRET.")
+	}
+	admins := "none"
+	if len(r.cfg.AdminSubjects) > 0 {
+		admins = strings.Join(r.cfg.AdminSubjects, ", ")
+	} else if len(methods) > 0 {
+		section.Gaps = append(section.Gaps, "No subjects may use the admin API; set ADMIN_SUBJECTS.")
+	}
+	memoryRoles := "any caller; API keys with the memory:write or admin scope"
+	if r.cfg.OIDC.RolesClaim != "" {
+		memoryRoles = "editor and admin roles, from the " + r.cfg.OIDC.RolesClaim + " token claim and API key scopes"
+	} else if r.cfg.OIDC.ClientID != "" {
+		section.Gaps = append(section.Gaps, "Every OIDC caller may change memory; set OIDC_ROLES_CLAIM.")
+	}
+	section.Facts = []Fact{
+		{"API authentication", authentication},
+		{"Webhook verification", webhooks},
+		{"Admin subjects", admins},
+		{"Memory changes", memoryRoles},
+	}
+
+	grants := Table{Title: "Tool grants", Columns: []string{"Tenant", "Agents", "Tools", "Actions"}, Rows: make([][]string, 0)}
+	trackers := Table{Title: "Issue trackers", Columns: []string{"Tenant", "Kind", "Projects"}, Rows: make([][]string, 0)}
+	if r.sources.Tools == nil {
+		section.Facts = append(section.Facts, Fact{"Agent tools", "disabled"})
+	} else if cfg := r.sources.Tools(); cfg != nil {
+		for _, t := range cfg.Tenants {
+			if tenant != "" && t.ID != tenant {
+				continue
+			}
+			for _, grant := range t.Grants {
+				actions := "all"
+				if len(grant.Actions) > 0 {
+					actions = strings.Join(grant.Actions, ", ")
+				}
+				grants.Rows = append(grants.Rows, []string{t.ID, strings.Join(grant.Agents, ", "), strings.Join(grant.Tools, ", "), actions})
+			}
+			if t.IssueTracker != nil {
+				trackers.Rows = append(trackers.Rows, []string{t.ID, t.IssueTracker.Kind, strings.Join(t.IssueTracker.Projects, ", ")})
+			}
+		}
+	}
+	section.Tables = []Table{grants, trackers}
+	return section
+}
+
+// retention is the evidence of how long data is kept.
+func (r *Reporter) retention() Section {
+	section := Section{
+		ID:      SectionRetention,
+		Title:   "Retention",
+		Summary: "How long each kind of data the server holds is kept, and how it is disposed of once it expires.",
+		Facts:   []Fact{},
+	}
+	policies := Table{Title: "Retention policies", Columns: []string{"Data", "Retention", "Disposal"}, Rows: make([][]string, 0)}
+	for _, policy := range r.sources.Retention {
+		period := "not expired by the server"
+		if policy.Period > 0 {
+			period = formatPeriod(policy.Period)
+		}
+		policies.Rows = append(policies.Rows, []string{policy.Data, period, policy.Disposal})
+	}
+	section.Tables = []Table{policies}
+	return section
+}
+
+// moderation is the evidence that what is shared across tenants, and what
+// agents do outside the collective, is reviewed.
+func (r *Reporter) moderation(pack *Pack, entries []tools.AuditEntry) Section {
+	section := Section{
+		ID:      SectionModeration,
+		Title:   "Moderation",
+		Summary: "Insights learned from one tenant are shared with others only by policy or an administrator's approval, and tool calls no grant allows are denied.",
+	}
+
+	denied := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Outcome == tools.OutcomeDenied {
+			denied[entry.Agent+" / "+entry.Tool]++
+		}
+	}
+	section.Facts = []Fact{{"Tool calls denied", strconv.Itoa(sum(denied))}}
+
+	statuses := make(map[string]int)
+	decisions := Table{Title: "Insight decisions", Columns: []string{"Detected", "Tenant", "Kind", "Subject", "Status", "Decided by", "Reason"}, Rows: make([][]string, 0)}
+	if r.sources.Insights == nil {
+		section.Facts = append(section.Facts, Fact{"Insight sharing", "disabled"})
+	} else {
+		var insights []propagation.Insight
+		for _, insight := range r.sources.Insights() {
+			if insight.DetectedAt.Before(pack.From) || (pack.Tenant != "" && insight.Tenant != pack.Tenant) {
+				continue
+			}
+			insights = append(insights, insight)
+			statuses[string(insight.Status)]++
+		}
+		sort.Slice(insights, func(i, j int) bool {
+			return insights[i].DetectedAt.Before(insights[j].DetectedAt)
+		})
+		for _, insight := range latest(insights) {
+			decisions.Rows = append(decisions.Rows, []string{insight.DetectedAt.UTC().Format(time.RFC3339), insight.Tenant, insight.Kind, insight.Subject, string(insight.Status), insight.DecidedBy, insight.Reason})
+		}
+		section.Facts = append(section.Facts,
+			Fact{"Insights detected", strconv.Itoa(len(insights))},
+			Fact{"Insights awaiting review", strconv.Itoa(statuses[string(propagation.StatusPending)])},
+		)
+	}
+	section.Tables = []Table{
+		countTable("Insights by status", "Status", statuses),
+		countTable("Denied tool calls by agent and tool", "Agent / tool", denied),
+		decisions,
+	}
+	return section
+}
+
+// mapControls returns the controls with the sections of a pack, each a gap
+// if one of its sections has gaps.
+func mapControls(sections []Section) []Control {
+	gaps := make(map[string]bool)
+	for _, section := range sections {
+		gaps[section.ID] = len(section.Gaps) > 0
+	}
+	mapped := make([]Control, len(controls))
+	for i, control := range controls {
+		control.Status = StatusEvidenced
+		for _, id := range control.Sections {
+			if gaps[id] {
+				control.Status = StatusGap
+			}
+		}
+		mapped[i] = control
+	}
+	for i := range sections {
+		sections[i].Controls = make([]string, 0)
+		if sections[i].Gaps == nil {
+			sections[i].Gaps = make([]string, 0)
+		}
+		for _, control := range controls {
+			for _, id := range control.Sections {
+				if id == sections[i].ID {
+					sections[i].Controls = append(sections[i].Controls, control.Framework+" "+control.ID)
+				}
+			}
+		}
+	}
+	return mapped
+}
+
+// countTable tabulates counts by key, largest first.
+func countTable(title, column string, counts map[string]int) Table {
+	table := Table{Title: title, Columns: []string{column, "Count"}, Rows: make([][]string, 0, len(counts))}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		table.Rows = append(table.Rows, []string{key, strconv.Itoa(counts[key])})
+	}
+	return table
+}
+
+// latest returns the last maxTableRows of a series.
+func latest[T any](series []T) []T {
+	if len(series) > maxTableRows {
+		return series[len(series)-maxTableRows:]
+	}
+	return series
+}
+
+// sum adds up counts.
+func sum(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// formatPeriod describes a retention period in days or hours.
+func formatPeriod(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,168 @@
+package compliance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/propagation"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/tools"
+)
+
+var testNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+// newTestReporter creates a reporter over a small audit trail, tool config
+// and insight queue, as of testNow.
+func newTestReporter(cfg *config.Config) *Reporter {
+	entries := []tools.AuditEntry{
+		{Time: testNow.AddDate(0, 0, -40), Tenant: "acme", Agent: "FORTRESS", Tool: "issue_tracker", Outcome: tools.OutcomeSucceeded},
+		{Time: testNow.AddDate(0, 0, -2), Tenant: "acme", Agent: "FORTRESS", Tool: "issue_tracker", Outcome: tools.OutcomeSucceeded, Reference: "SEC-1"},
+		{Time: testNow.AddDate(0, 0, -1), Tenant: "acme", Agent: "APEX", Tool: "issue_tracker", Outcome: tools.OutcomeDenied},
+		{Time: testNow.AddDate(0, 0, -1), Tenant: "globex", Agent: "APEX", Tool: "issue_tracker", Outcome: tools.OutcomeDenied},
+	}
+	decided := testNow.Add(-time.Hour)
+	reporter := New(cfg, Sources{
+		Audit: func(since time.Time) []tools.AuditEntry {
+			var recent []tools.AuditEntry
+			for _, entry := range entries {
+				if !entry.Time.Before(since) {
+					recent = append(recent, entry)
+				}
+			}
+			return recent
+		},
+		Tools: func() *tools.Config {
+			return &tools.Config{Tenants: []tools.Tenant{
+				{ID: "acme", IssueTracker: &tools.IssueTrackerConfig{Kind: tools.TrackerJira, Token: "secret", Projects: []string{"SEC"}}, Grants: []tools.Grant{{Agents: []string{"FORTRESS"}, Tools: []string{"issue_tracker"}}}},
+				{ID: "globex", Grants: []tools.Grant{{Agents: []string{"*"}, Tools: []string{"*"}, Actions: []string{"create"}}}},
+			}}
+		},
+		Insights: func() []propagation.Insight {
+			return []propagation.Insight{
+				{ID: "i1", Tenant: "acme", Kind: "breakthrough", Subject: "caching", DetectedAt: testNow.AddDate(0, 0, -3), Status: propagation.StatusPromoted, DecidedAt: &decided, DecidedBy: "admin"},
+				{ID: "i2", Tenant: "globex", Kind: "breakthrough", Subject: "sharding", DetectedAt: testNow.AddDate(0, 0, -1), Status: propagation.StatusPending},
+			}
+		},
+		Retention: []RetentionPolicy{
+			{Data: "Sessions", Period: 7 * 24 * time.Hour, Disposal: "deleted"},
+			{Data: "Workflow runs", Period: 24 * time.Hour, Disposal: "deleted"},
+			{Data: "Audit log", Disposal: "operator-managed"},
+		},
+	})
+	reporter.now = func() time.Time { return testNow }
+	return reporter
+}
+
+// section returns a pack's section by ID.
+func section(t *testing.T, pack *Pack, id string) Section {
+	t.Helper()
+	for _, s := range pack.Sections {
+		if s.ID == id {
+			return s
+		}
+	}
+	t.Fatalf("expected a %s section", id)
+	return Section{}
+}
+
+// fact returns the value of a section's fact.
+func fact(s Section, label string) string {
+	for _, f := range s.Facts {
+		if f.Label == label {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestReporterPack(t *testing.T) {
+	cfg := &config.Config{AuditLogPath: "/var/log/eac/audit.jsonl", AdminSubjects: []string{"repo:acme/ops"}}
+	cfg.OIDC.ClientID = "eac"
+	cfg.OIDC.Issuer = "https://token.actions.githubusercontent.com"
+	cfg.OIDC.RolesClaim = "roles"
+	cfg.GitHub.WebhookSecret = "secret"
+	pack := newTestReporter(cfg).Pack("", 30)
+
+	if !pack.From.Equal(testNow.AddDate(0, 0, -30)) || !pack.To.Equal(testNow) {
+		t.Errorf("expected the last 30 days, got %s to %s", pack.From, pack.To)
+	}
+	audit := section(t, pack, SectionAuditLog)
+	if fact(audit, "Calls recorded") != "3" || len(audit.Tables[1].Rows) != 3 {
+		t.Errorf("expected the 3 calls in the period, got %+v", audit)
+	}
+	access := section(t, pack, SectionAccessControl)
+	if !strings.Contains(fact(access, "Memory changes"), "roles token claim") {
+		t.Errorf("expected memory changes limited by the roles claim, got %+v", access.Facts)
+	}
+	if len(access.Tables[0].Rows) != 2 || access.Tables[0].Rows[1][3] != "create" {
+		t.Errorf("expected both tenants' grants, got %+v", access.Tables[0])
+	}
+	for _, row := range access.Tables[1].Rows {
+		for _, cell := range row {
+			if cell == "secret" {
+				t.Errorf("expected no credentials in the pack, got %v", row)
+			}
+		}
+	}
+	retention := section(t, pack, SectionRetention)
+	if rows := retention.Tables[0].Rows; len(rows) != 3 || rows[0][1] != "7 days" || rows[1][1] != "1 day" || rows[2][1] != "not expired by the server" {
+		t.Errorf("expected formatted retention periods, got %v", rows)
+	}
+	moderation := section(t, pack, SectionModeration)
+	if fact(moderation, "Tool calls denied") != "2" || fact(moderation, "Insights awaiting review") != "1" {
+		t.Errorf("expected denials and pending insights counted, got %+v", moderation.Facts)
+	}
+
+	for _, control := range pack.Controls {
+		if control.Status != StatusEvidenced {
+			t.Errorf("expected %s %s evidenced, got %s", control.Framework, control.ID, control.Status)
+		}
+	}
+	if len(access.Controls) == 0 || access.Controls[0] != "SOC2 CC6.1" {
+		t.Errorf("expected the section to name its controls, got %v", access.Controls)
+	}
+}
+
+func TestReporterPackTenant(t *testing.T) {
+	pack := newTestReporter(&config.Config{}).Pack("globex", 30)
+
+	if audit := section(t, pack, SectionAuditLog); fact(audit, "Calls recorded") != "1" {
+		t.Errorf("expected only globex's calls, got %+v", audit.Facts)
+	}
+	if access := section(t, pack, SectionAccessControl); len(access.Tables[0].Rows) != 1 || len(access.Tables[1].Rows) != 0 {
+		t.Errorf("expected only globex's grants, got %+v", access.Tables)
+	}
+	if moderation := section(t, pack, SectionModeration); fact(moderation, "Insights detected") != "1" {
+		t.Errorf("expected only globex's insights, got %+v", moderation.Facts)
+	}
+}
+
+func TestReporterPackGaps(t *testing.T) {
+	pack := newTestReporter(&config.Config{}).Pack("", 30)
+
+	if audit := section(t, pack, SectionAuditLog); len(audit.Gaps) != 1 {
+		t.Errorf("expected a gap for an audit trail in the server log, got %v", audit.Gaps)
+	}
+	if access := section(t, pack, SectionAccessControl); len(access.Gaps) != 2 {
+		t.Errorf("expected gaps for disabled authentication and webhook verification, got %v", access.Gaps)
+	}
+	status := make(map[string]string)
+	for _, control := range pack.Controls {
+		status[control.ID] = control.Status
+	}
+	if status["CC6.1"] != StatusGap || status["A.8.15"] != StatusGap || status["C1.2"] != StatusEvidenced {
+		t.Errorf("expected controls of sections with gaps marked, got %v", status)
+	}
+}
+
+func TestReporterPackDisabled(t *testing.T) {
+	pack := New(&config.Config{}, Sources{}).Pack("", 30)
+
+	if audit := section(t, pack, SectionAuditLog); fact(audit, "Agent tools") != "disabled" || len(audit.Gaps) != 0 {
+		t.Errorf("expected disabled tools reported without a gap, got %+v", audit)
+	}
+	if moderation := section(t, pack, SectionModeration); fact(moderation, "Insight sharing") != "disabled" {
+		t.Errorf("expected disabled insight sharing reported, got %+v", moderation.Facts)
+	}
+}
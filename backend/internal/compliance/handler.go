@@ -0,0 +1,45 @@
+package compliance
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+const (
+	// DefaultPackDays is the period of a pack when none is requested
+	DefaultPackDays = 30
+	// maxPackDays bounds the period of a pack
+	maxPackDays = 366
+)
+
+// ServePack handles GET /admin/compliance/evidence - compiles the evidence
+// pack of the last ?days= days, 30 by default, optionally only for the
+// ?tenant= tenant. ?download=true serves it as an attachment.
+func (r *Reporter) ServePack(w http.ResponseWriter, req *http.Request) {
+	days := DefaultPackDays
+	if value := req.URL.Query().Get("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxPackDays {
+			errdefs.WriteError(w, fmt.Sprintf("days must be a number from 1 to %d", maxPackDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	pack := r.Pack(req.URL.Query().Get("tenant"), days)
+	gaps := 0
+	for _, control := range pack.Controls {
+		if control.Status == StatusGap {
+			gaps++
+		}
+	}
+	log.Printf("Compiled evidence pack for %d days: %d of %d controls with gaps", days, gaps, len(pack.Controls))
+
+	if download, _ := strconv.ParseBool(req.URL.Query().Get("download")); download {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="evidence-pack-%s.json"`, pack.GeneratedAt.Format("2006-01-02")))
+	}
+	errdefs.WriteJSON(w, http.StatusOK, pack)
+}
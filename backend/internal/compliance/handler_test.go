@@ -0,0 +1,39 @@
+package compliance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+)
+
+func TestServePack(t *testing.T) {
+	reporter := newTestReporter(&config.Config{})
+
+	w := httptest.NewRecorder()
+	reporter.ServePack(w, httptest.NewRequest(http.MethodGet, "/admin/compliance/evidence?days=7&download=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, "evidence-pack-2026-10-16.json") {
+		t.Errorf("expected an attachment, got %q", disposition)
+	}
+	var pack Pack
+	if err := json.Unmarshal(w.Body.Bytes(), &pack); err != nil {
+		t.Fatalf("failed to decode pack: %v", err)
+	}
+	if !pack.From.Equal(testNow.AddDate(0, 0, -7)) || len(pack.Sections) != 4 || len(pack.Controls) != len(controls) {
+		t.Errorf("expected a 7-day pack, got %+v", pack)
+	}
+
+	for _, days := range []string{"0", "x", "367"} {
+		w := httptest.NewRecorder()
+		reporter.ServePack(w, httptest.NewRequest(http.MethodGet, "/admin/compliance/evidence?days="+days, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for days=%s, got %d", days, w.Code)
+		}
+	}
+}
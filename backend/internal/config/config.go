@@ -1,80 +1,972 @@
 // Package config provides configuration management for the backend server.
+//
+// Every setting is a field of Config whose tags name its key in the YAML
+// config file, its environment variable and its default. Load starts from
+// the defaults and applies the config file, then the environment, then
+// command-line flags, so later sources win. A profile, selected by ENV,
+// replaces the defaults with ones suited to an environment and adds the
+// checks that environment needs. Each value is parsed as the
+// field's type and the whole configuration is validated, and every problem
+// is reported at once, naming the source of the offending value. The
+// effective configuration can be printed with secrets masked.
 package config
 
 import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
+// ErrInvalidConfig is returned for configuration that fails to parse or
+// validate
+var ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid configuration")
+
+// FileEnv names the config file when the -config flag is not given.
+const FileEnv = "CONFIG_FILE"
+
 // Config holds all configuration for the server.
+//
+// Tags: config is the setting's key (nested structs prefix their fields'
+// keys, and flags are the keys with dashes), env its environment variable,
+// default its value when no source sets it, and secret marks values that
+// are masked when printed.
 type Config struct {
+	// Profile selects the defaults and checks of an environment; empty
+	// uses the plain defaults. It is loaded first, since it supplies the
+	// other settings' defaults
+	Profile string `config:"profile" env:"ENV" help:"configuration profile: dev, staging or prod"`
+
 	// Server configuration
-	Port     int
-	LogLevel string
+	Port     int    `config:"port" env:"PORT" default:"8080" help:"server port"`
+	GRPCPort int    `config:"grpc_port" env:"GRPC_PORT" default:"0" help:"gRPC server port; 0 disables the gRPC API"`
+	LogLevel string `config:"log_level" env:"LOG_LEVEL" default:"info" help:"logging level: debug, info, warn or error"`
+
+	// Offline replaces network-backed providers with deterministic stubs,
+	// for integration tests and local demos without network access
+	Offline bool `config:"offline" env:"OFFLINE_MODE" default:"false" help:"replace network-backed providers with deterministic stubs"`
 
 	// CORS configuration
-	CORSAllowedOrigins string
+	CORSAllowedOrigins string `config:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS" help:"origin allowed by CORS (any when empty)"`
 
 	// OIDC configuration
-	OIDC OIDCConfig
+	OIDC OIDCConfig `config:"oidc"`
+	// APIKeysConfig is the YAML file of hashed API keys accepted alongside
+	// OIDC tokens; empty accepts OIDC tokens only
+	APIKeysConfig string `config:"api_keys_config" env:"API_KEYS_CONFIG" help:"YAML file of hashed API keys and their scopes"`
 
 	// GitHub App configuration for Copilot Extensions
-	GitHub GitHubConfig
+	GitHub GitHubConfig `config:"github"`
+
+	// Memory persistence configuration
+	Memory MemoryConfig `config:"memory"`
+
+	// WorkflowsDir holds the YAML workflow definitions loaded at startup;
+	// empty disables workflows
+	WorkflowsDir string `config:"workflows_dir" env:"WORKFLOWS_DIR" help:"directory of YAML workflow definitions"`
+	// WorkflowStateDir persists workflow runs so they resume after a
+	// restart; empty keeps them in memory
+	WorkflowStateDir string `config:"workflows_state_dir" env:"WORKFLOWS_STATE_DIR" help:"directory workflow runs are persisted in"`
+
+	// IntegrationsConfig is the YAML file of Slack and Teams workspaces;
+	// empty disables chat integrations
+	IntegrationsConfig string `config:"integrations_config" env:"INTEGRATIONS_CONFIG" help:"YAML file of Slack and Teams workspaces"`
+
+	// ToolsConfig is the YAML file of tenant tool credentials; empty
+	// disables agent tools
+	ToolsConfig string `config:"tools_config" env:"TOOLS_CONFIG" help:"YAML file of tenant tool credentials"`
+	// AuditLogPath is the file tool calls are recorded in; empty writes
+	// them to the server log
+	AuditLogPath string `config:"audit_log_path" env:"AUDIT_LOG_PATH" help:"file tool calls are recorded in"`
+
+	// Embeddings configuration
+	Embeddings EmbeddingsConfig `config:"embeddings"`
+
+	// LLM configuration
+	LLM LLMConfig `config:"llm"`
+
+	// RateLimit limits the requests each client and agent is sent
+	RateLimit RateLimitConfig `config:"rate_limit"`
+
+	// GitOps syncs agent personas and prompts from a Git repository
+	GitOps GitOpsConfig `config:"gitops"`
+
+	// Privacy of the learning signals tenants share
+	Privacy PrivacyConfig `config:"privacy"`
+
+	// Cluster shares routing state between the replicas of a deployment
+	Cluster ClusterConfig `config:"cluster"`
+
+	// FeaturesConfig is the YAML file of feature flags; empty uses the
+	// built-in defaults
+	FeaturesConfig string `config:"features_config" env:"FEATURES_CONFIG" help:"YAML file of feature flags"`
+	// InsightPolicy is the YAML file deciding which tenants' insights are
+	// promoted to every tenant; empty queues them all for review
+	InsightPolicy string `config:"insight_policy" env:"INSIGHT_POLICY" help:"YAML file deciding which tenants' insights are shared"`
+	// AdminSubjects are the token subjects allowed to use the admin API
+	AdminSubjects []string `config:"admin_subjects" env:"ADMIN_SUBJECTS" help:"comma-separated token subjects allowed to use the admin API"`
+
+	// IntentTemplates is the YAML file of per-intent prompt templates;
+	// empty sends queries unchanged
+	IntentTemplates string `config:"intent_templates" env:"INTENT_TEMPLATES" help:"YAML file of per-intent prompt templates"`
+
+	// PreferencesDir persists user preference profiles, one file per
+	// tenant; empty keeps them in memory
+	PreferencesDir string `config:"preferences_dir" env:"PREFERENCES_DIR" help:"directory user preference profiles are saved in"`
+
+	// SessionSigningKey signs exported sessions and verifies imported ones;
+	// deployments that exchange sessions share it. Empty disables export
+	// and import
+	SessionSigningKey string `config:"session_signing_key" env:"SESSION_SIGNING_KEY" secret:"true" help:"key session bundles are signed with"`
+	// SessionTTLMinutes is how long a session is kept after its last turn
+	SessionTTLMinutes int `config:"session_ttl_minutes" env:"SESSION_TTL" default:"10080" help:"minutes an idle session is kept"`
+
+	// GroundingRevise has agents revise answers with unsupported claims
+	// once before they are marked, when grounded answers are enabled
+	GroundingRevise bool `config:"grounding_revise" env:"GROUNDING_REVISE" default:"false" help:"revise grounded answers with unsupported claims once"`
+
+	// AgentContextRefreshSeconds is how often the warm pool rebuilds the
+	// context agents are invoked with; 0 assembles it on every invocation
+	AgentContextRefreshSeconds int `config:"agent_context_refresh_seconds" env:"AGENT_CONTEXT_REFRESH" default:"300" help:"seconds between rebuilds of pooled agent contexts; 0 disables the pool"`
+
+	// File is the config file settings were read from; empty if none
+	File string `config:"-"`
+	// PrintConfig asks for the effective configuration to be printed at
+	// startup
+	PrintConfig bool `config:"-"`
+	// SelfTest asks for the self-test to be run instead of serving
+	SelfTest bool `config:"-"`
+
+	// sources records where each setting's value came from, by key
+	sources map[string]string
 }
 
 // OIDCConfig holds OIDC authentication configuration.
 type OIDCConfig struct {
-	Issuer       string
-	ClientID     string
-	ClientSecret string
+	Issuer       string `config:"issuer" env:"OIDC_ISSUER" default:"https://token.actions.githubusercontent.com" help:"OIDC issuer URL"`
+	ClientID     string `config:"client_id" env:"OIDC_CLIENT_ID" help:"OIDC client ID (enables authentication)"`
+	ClientSecret string `config:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true" help:"OIDC client secret"`
+	RolesClaim   string `config:"roles_claim" env:"OIDC_ROLES_CLAIM" help:"token claim holding the caller's roles, e.g. roles or realm_access.roles (every role when unset)"`
 }
 
 // GitHubConfig holds GitHub App configuration for Copilot Extensions.
 type GitHubConfig struct {
 	// AppID is the GitHub App ID
-	AppID string
+	AppID string `config:"app_id" env:"GITHUB_APP_ID" help:"GitHub App ID"`
 	// PrivateKey is the GitHub App private key (PEM format)
-	PrivateKey string
+	PrivateKey string `config:"private_key" env:"GITHUB_APP_PRIVATE_KEY" secret:"true" help:"GitHub App private key (PEM)"`
 	// WebhookSecret is the secret used to verify webhook payloads
-	WebhookSecret string
+	WebhookSecret string `config:"webhook_secret" env:"GITHUB_WEBHOOK_SECRET" secret:"true" help:"secret webhook payloads are verified with"`
+	// APIURL is the GitHub API base URL, for GitHub Enterprise Server
+	APIURL string `config:"api_url" env:"GITHUB_API_URL" default:"https://api.github.com" help:"GitHub API base URL"`
+	// ActionsAllowedOwners restricts the installation tokens accepted from
+	// GitHub Actions to repositories of these owners; empty accepts any
+	ActionsAllowedOwners []string `config:"actions_allowed_owners" env:"ACTIONS_ALLOWED_OWNERS" help:"comma-separated repository owners whose installation tokens are accepted"`
 }
 
-// Load reads configuration from environment variables with sensible defaults.
-func Load() *Config {
-	return &Config{
-		Port:               getEnvAsInt("PORT", 8080),
-		LogLevel:           getEnv("LOG_LEVEL", "info"),
-		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", ""),
-		OIDC: OIDCConfig{
-			Issuer:       getEnv("OIDC_ISSUER", "https://token.actions.githubusercontent.com"),
-			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
-			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
-		},
-		GitHub: GitHubConfig{
-			AppID:         getEnv("GITHUB_APP_ID", ""),
-			PrivateKey:    getEnv("GITHUB_APP_PRIVATE_KEY", ""),
-			WebhookSecret: getEnv("GITHUB_WEBHOOK_SECRET", ""),
-		},
-	}
+// MemoryConfig holds memory persistence configuration.
+type MemoryConfig struct {
+	// WALPath is the knowledge graph write-ahead log file; empty disables it
+	WALPath string `config:"wal_path" env:"MEMORY_WAL_PATH" help:"knowledge graph write-ahead log file"`
+	// SnapshotPath is the knowledge graph snapshot loaded on boot and saved
+	// on shutdown; empty disables it
+	SnapshotPath string `config:"snapshot_path" env:"MEMORY_SNAPSHOT_PATH" help:"knowledge graph snapshot file"`
+	// GoalJournalDir persists each goal's journal, for post-mortems after
+	// a restart; empty keeps journals in memory
+	GoalJournalDir string `config:"goal_journal_dir" env:"MEMORY_GOAL_JOURNAL_DIR" help:"directory goal journals are saved in"`
+	// ModelRegistryPath persists the versions of learned parameters and
+	// which are promoted; empty keeps them in memory
+	ModelRegistryPath string `config:"model_registry_path" env:"MEMORY_MODEL_REGISTRY_PATH" help:"file the model registry is saved in"`
+	// SnapshotIntervalSeconds is how often the leader saves the knowledge
+	// graph snapshot while running; 0 saves it only on shutdown
+	SnapshotIntervalSeconds int `config:"snapshot_interval_seconds" env:"MEMORY_SNAPSHOT_INTERVAL" default:"0" help:"seconds between knowledge graph snapshots the leader saves; 0 saves on shutdown only"`
+	// ConsolidationIntervalSeconds is how often the leader consolidates
+	// experiences into schemas
+	ConsolidationIntervalSeconds int `config:"consolidation_interval_seconds" env:"MEMORY_CONSOLIDATION_INTERVAL" default:"3600" help:"seconds between experience consolidations the leader runs"`
+	// WarmupServeDegraded serves reads before warmup has finished; writes
+	// still wait for it
+	WarmupServeDegraded bool `config:"warmup_degraded" env:"MEMORY_WARMUP_DEGRADED" default:"false" help:"serve memory reads before memory warmup has finished"`
+}
+
+// EmbeddingsConfig holds embedding backend configuration.
+type EmbeddingsConfig struct {
+	// Provider selects the embedding backend: onnx runs a local model in
+	// process, openai calls llm.embedding_model with the llm settings and
+	// stub hashes text without a model; empty disables embeddings
+	Provider string `config:"provider" env:"EMBEDDINGS_PROVIDER" help:"embedding backend: onnx, openai or stub"`
+	// ModelPath is the ONNX model file, with its vocab.txt beside it
+	ModelPath string `config:"model_path" env:"EMBEDDINGS_MODEL_PATH" help:"ONNX model file"`
+	// LibraryPath is the ONNX Runtime shared library; empty uses the
+	// platform default
+	LibraryPath string `config:"library_path" env:"ONNXRUNTIME_LIB" help:"ONNX Runtime shared library"`
+	// CachePath persists computed embeddings across restarts; empty keeps
+	// them in memory
+	CachePath string `config:"cache_path" env:"EMBEDDINGS_CACHE_PATH" help:"file computed embeddings are saved in"`
+}
+
+// LLMConfig holds the language model agents answer with.
+type LLMConfig struct {
+	// Provider selects the vendor: openai, anthropic or stub; empty keeps
+	// the agents' built-in answers
+	Provider string `config:"provider" env:"LLM_PROVIDER" help:"language model provider: openai, anthropic or stub"`
+	APIKey   string `config:"api_key" env:"LLM_API_KEY" secret:"true" help:"language model provider API key"`
+	// Model answers agent invocations, e.g. gpt-4o
+	Model string `config:"model" env:"LLM_MODEL" help:"model agents answer with"`
+	// EmbeddingModel embeds text through the provider; empty disables it
+	EmbeddingModel string `config:"embedding_model" env:"LLM_EMBEDDING_MODEL" help:"model text is embedded with"`
+	// DraftModel is a small, fast model streaming drafts ahead of the
+	// answer to requests that ask for one; empty disables drafts
+	DraftModel string `config:"draft_model" env:"LLM_DRAFT_MODEL" help:"small model drafts are streamed from"`
+	// BaseURL replaces the vendor's API URL, for proxies and compatible
+	// servers
+	BaseURL        string `config:"base_url" env:"LLM_BASE_URL" help:"provider API URL, for proxies and compatible servers"`
+	MaxTokens      int    `config:"max_tokens" env:"LLM_MAX_TOKENS" default:"1024" help:"most tokens in one answer"`
+	TimeoutSeconds int    `config:"timeout_seconds" env:"LLM_TIMEOUT" default:"60" help:"seconds one provider call may take"`
+	// Temperature and TopP are what agents sample with until feedback
+	// tunes them
+	Temperature float64 `config:"temperature" env:"LLM_TEMPERATURE" default:"1" help:"sampling temperature agents start with, 0.2 to 1.2"`
+	TopP        float64 `config:"top_p" env:"LLM_TOP_P" default:"1" help:"top-p agents start with, 0.7 to 1"`
+
+	// Hedge races a fallback provider against the provider's slow calls
+	Hedge HedgeConfig `config:"hedge"`
+}
+
+// HedgeConfig holds the fallback provider agent completions are hedged
+// with: a completion the provider is slow to return is also requested
+// from the fallback, and the first returned is used.
+type HedgeConfig struct {
+	// Provider selects the fallback's vendor: openai, anthropic or stub;
+	// empty disables hedging
+	Provider string `config:"provider" env:"LLM_HEDGE_PROVIDER" help:"fallback language model provider: openai, anthropic or stub"`
+	APIKey   string `config:"api_key" env:"LLM_HEDGE_API_KEY" secret:"true" help:"fallback provider API key"`
+	Model    string `config:"model" env:"LLM_HEDGE_MODEL" help:"model the fallback answers with"`
+	BaseURL  string `config:"base_url" env:"LLM_HEDGE_BASE_URL" help:"fallback provider API URL"`
+	// Percentile of the provider's recent latencies after which the
+	// fallback is called
+	Percentile float64 `config:"percentile" env:"LLM_HEDGE_PERCENTILE" default:"95" help:"percentile of the provider's latency after which the fallback is called"`
+	// DelayMillis is how long calls wait before hedging until enough
+	// latencies are known to take the percentile of
+	DelayMillis int `config:"delay_ms" env:"LLM_HEDGE_DELAY_MS" default:"2000" help:"milliseconds calls wait before hedging until the provider's latency is known"`
+	// MaxRate caps the share of calls that are hedged
+	MaxRate float64 `config:"max_rate" env:"LLM_HEDGE_MAX_RATE" default:"0.1" help:"largest share of calls hedged, 0 to 1"`
+}
+
+// RateLimitConfig holds the token buckets requests to agents are taken
+// from. Zero rates are unlimited.
+type RateLimitConfig struct {
+	// Store keeps the buckets: memory, per server, or redis, shared by
+	// every server using the same Redis
+	Store string `config:"store" env:"RATE_LIMIT_STORE" default:"memory" help:"where rate limit buckets are kept: memory or redis"`
+	// RedisAddr is the host:port of the Redis the redis store uses
+	RedisAddr     string `config:"redis_addr" env:"RATE_LIMIT_REDIS_ADDR" help:"Redis host:port rate limit buckets are kept in"`
+	RedisPassword string `config:"redis_password" env:"RATE_LIMIT_REDIS_PASSWORD" secret:"true" help:"Redis password"`
+	// ClientQPS and ClientBurst limit each OIDC subject, or each address
+	// for requests without a token
+	ClientQPS   float64 `config:"client_qps" env:"RATE_LIMIT_CLIENT_QPS" default:"0" help:"requests per second each client may send (unlimited when 0)"`
+	ClientBurst int     `config:"client_burst" env:"RATE_LIMIT_CLIENT_BURST" default:"0" help:"requests a client may send at once (one second's worth when 0)"`
+	// AgentQPS and AgentBurst limit the requests routed to each agent
+	AgentQPS   float64 `config:"agent_qps" env:"RATE_LIMIT_AGENT_QPS" default:"0" help:"requests per second each agent may be sent (unlimited when 0)"`
+	AgentBurst int     `config:"agent_burst" env:"RATE_LIMIT_AGENT_BURST" default:"0" help:"requests an agent may be sent at once (one second's worth when 0)"`
+	// LimitsConfig is the YAML file of limits for particular subjects and
+	// agents; empty limits them all alike
+	LimitsConfig string `config:"limits_config" env:"RATE_LIMITS_CONFIG" help:"YAML file of per-subject and per-agent rate limits"`
+}
+
+// GitOpsConfig holds the Git repository agent personas, intent templates
+// and rule packs are synced from.
+type GitOpsConfig struct {
+	// Repo is the repository's URL or path; empty disables syncing
+	Repo   string `config:"repo" env:"GITOPS_REPO" help:"Git repository agent prompts are synced from"`
+	Branch string `config:"branch" env:"GITOPS_BRANCH" default:"main" help:"branch agent prompts are synced from"`
+	// Path is the directory within the repository the files are in; empty
+	// is its root
+	Path string `config:"path" env:"GITOPS_PATH" help:"directory of the repository agent prompts are in"`
+	// Dir is the local checkout
+	Dir string `config:"dir" env:"GITOPS_DIR" default:"data/gitops" help:"directory the prompt repository is checked out in"`
+	// AllowedSigners is the SSH allowed signers file commits must be
+	// signed by; empty verifies GPG signatures against the server's keyring
+	AllowedSigners  string `config:"allowed_signers" env:"GITOPS_ALLOWED_SIGNERS" help:"SSH allowed signers file prompt commits are verified against"`
+	IntervalSeconds int    `config:"interval_seconds" env:"GITOPS_INTERVAL" default:"60" help:"seconds between prompt repository syncs"`
+}
+
+// PrivacyConfig holds the differential privacy applied to feedback before
+// it updates the routing and affinity state every tenant shares.
+type PrivacyConfig struct {
+	// Epsilon is the privacy budget spent on each released signal; smaller
+	// adds more noise, and zero adds none
+	Epsilon float64 `config:"epsilon" env:"PRIVACY_EPSILON" default:"0" help:"privacy budget per released learning signal (no noise when 0)"`
+	// MinTenants is the fewest distinct tenants a learning signal is
+	// released with; below two, signals are not held back
+	MinTenants int `config:"min_tenants" env:"PRIVACY_MIN_TENANTS" default:"0" help:"fewest tenants a learning signal is released with"`
+	// MaxContribution caps the outcomes one tenant adds to a released
+	// signal
+	MaxContribution int `config:"max_contribution" env:"PRIVACY_MAX_CONTRIBUTION" default:"10" help:"most outcomes one tenant adds to a released learning signal"`
+}
+
+// ClusterConfig holds how replicas of a horizontally scaled deployment
+// share what they learn.
+type ClusterConfig struct {
+	// ReplicaID names this replica among its peers; empty uses the host name
+	ReplicaID string `config:"replica_id" env:"REPLICA_ID" help:"name of this replica among its peers (default: host name)"`
+	// GossipPeers are the base URLs of the other replicas routing affinity
+	// and attention are gossiped to; empty disables gossip
+	GossipPeers []string `config:"gossip_peers" env:"GOSSIP_PEERS" help:"comma-separated base URLs of the replicas routing state is gossiped to"`
+	// GossipSecret authenticates replicas to each other
+	GossipSecret string `config:"gossip_secret" env:"GOSSIP_SECRET" secret:"true" help:"secret replicas gossip with"`
+	// GossipIntervalSeconds is the time between gossip rounds
+	GossipIntervalSeconds int `config:"gossip_interval_seconds" env:"GOSSIP_INTERVAL" default:"5" help:"seconds between gossip rounds"`
+	// LeaderElection selects the lease replicas elect the one running
+	// singleton jobs with: memory leads alone, for a single replica, and
+	// kubernetes shares a Lease object through the API server
+	LeaderElection string `config:"leader_election" env:"LEADER_ELECTION" default:"memory" help:"lease singleton jobs are elected with: memory or kubernetes"`
+	// LeaseName and LeaseNamespace name the Kubernetes Lease object
+	LeaseName      string `config:"lease_name" env:"LEADER_LEASE_NAME" default:"elite-agent-collective" help:"name of the Kubernetes Lease replicas elect a leader with"`
+	LeaseNamespace string `config:"lease_namespace" env:"POD_NAMESPACE" default:"default" help:"namespace of the Kubernetes Lease"`
 }
 
-// getEnv gets an environment variable or returns a default value.
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// ============================================================================
+// Profiles
+// ============================================================================
+
+// Profile names.
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// profiles are the defaults each profile sets, by key. dev runs without
+// authentication or network-backed providers; staging and prod persist
+// memory and are checked for authentication and persistence by Validate.
+var profiles = map[string]map[string]string{
+	ProfileDev: {
+		"log_level":              "debug",
+		"offline":                "true",
+		"embeddings.provider":    "stub",
+		"memory.warmup_degraded": "true",
+	},
+	ProfileStaging: {
+		"log_level":               "debug",
+		"memory.wal_path":         "data/memory/semantic.wal",
+		"memory.snapshot_path":    "data/memory/semantic.snapshot",
+		"memory.goal_journal_dir": "data/memory/goal-journals",
+		"preferences_dir":         "data/preferences",
+	},
+	ProfileProd: {
+		"log_level":               "info",
+		"memory.wal_path":         "data/memory/semantic.wal",
+		"memory.snapshot_path":    "data/memory/semantic.snapshot",
+		"memory.goal_journal_dir": "data/memory/goal-journals",
+		"preferences_dir":         "data/preferences",
+	},
+}
+
+// profileNames are the profiles in the order they are listed.
+var profileNames = []string{ProfileDev, ProfileStaging, ProfileProd}
+
+// strict reports whether the profile requires authentication and
+// persistence.
+func (c *Config) strict() bool {
+	return c.Profile == ProfileStaging || c.Profile == ProfileProd
+}
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+// setting is one leaf field of Config.
+type setting struct {
+	key    string
+	env    string
+	def    string
+	help   string
+	secret bool
+	value  reflect.Value
+}
+
+// Load builds the configuration from the defaults, the config file, the
+// environment and the command-line arguments, in that order of precedence,
+// and validates it. The config file is named by -config or CONFIG_FILE.
+func Load(args []string) (*Config, error) {
+	cfg := &Config{sources: make(map[string]string)}
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&cfg.File, "config", os.Getenv(FileEnv), "YAML config file")
+	fs.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration at startup")
+	fs.BoolVar(&cfg.SelfTest, "selftest", false, "run the self-test and exit")
+	flagged := make(map[string]string)
+	for _, s := range settings {
+		fs.Var(&settingFlag{key: s.key, values: flagged, bool: s.value.Kind() == reflect.Bool}, flagName(s.key), s.help)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("%w: unexpected argument %q", ErrInvalidConfig, fs.Arg(0))
+	}
+
+	var fileValues map[string]interface{}
+	var problems []error
+	if cfg.File != "" {
+		var err error
+		if fileValues, err = readFile(cfg.File); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		known := make(map[string]bool, len(settings))
+		for _, s := range settings {
+			known[s.key] = true
+		}
+		for _, key := range sortedKeys(fileValues) {
+			if !known[key] {
+				problems = append(problems, fmt.Errorf("%s: unknown setting in %s", key, cfg.File))
+			}
+		}
+	}
+
+	unparsed := make(map[string]bool)
+	for _, s := range settings {
+		// Settings are in declaration order, so the profile is already set
+		source, raw, list := "default", s.def, []string(nil)
+		if value, ok := profiles[cfg.Profile][s.key]; ok {
+			source, raw = "profile "+cfg.Profile, value
+		}
+		if value, ok := fileValues[s.key]; ok {
+			source = "file " + cfg.File
+			raw, list = fileValue(value)
+		}
+		if value, ok := os.LookupEnv(s.env); ok && (value != "" || s.value.Kind() == reflect.String || s.value.Kind() == reflect.Slice) {
+			source, raw, list = "env "+s.env, value, nil
+		}
+		if value, ok := flagged[s.key]; ok {
+			source, raw, list = "flag -"+flagName(s.key), value, nil
+		}
+
+		cfg.sources[s.key] = source
+		if err := setValue(s.value, raw, list); err != nil {
+			unparsed[s.key] = true
+			problems = append(problems, fmt.Errorf("%s: %w (from %s)", s.key, err, source))
+		}
+	}
+	// Settings that failed to parse are left at their zero value, which
+	// validation would report a second time
+	for _, problem := range cfg.validate() {
+		if !unparsed[problem.key] {
+			problems = append(problems, problem.err)
+		}
 	}
-	return defaultValue
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return cfg, nil
 }
 
-// getEnvAsInt gets an environment variable as an integer or returns a default value.
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := getEnv(key, "")
-	if valueStr == "" {
-		return defaultValue
+// settings lists the leaf fields of the configuration in declaration
+// order.
+func (c *Config) settings() []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := field.Tag.Get("config")
+			if key == "" || key == "-" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key)
+				continue
+			}
+			settings = append(settings, setting{
+				key:    key,
+				env:    field.Tag.Get("env"),
+				def:    field.Tag.Get("default"),
+				help:   field.Tag.Get("help"),
+				secret: field.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
 	}
-	value, err := strconv.Atoi(valueStr)
+	walk(reflect.ValueOf(c).Elem(), "")
+	return settings
+}
+
+// flagName is the command-line flag of a setting.
+func flagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// settingFlag collects a setting given on the command line; it is parsed
+// with the other sources.
+type settingFlag struct {
+	key    string
+	values map[string]string
+	bool   bool
+}
+
+func (f *settingFlag) String() string { return "" }
+
+func (f *settingFlag) Set(value string) error {
+	f.values[f.key] = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given without a value.
+func (f *settingFlag) IsBoolFlag() bool { return f.bool }
+
+// readFile reads a YAML config file into values by dotted key.
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return defaultValue
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]interface{})
+	var flatten func(m map[string]interface{}, prefix string)
+	flatten = func(m map[string]interface{}, prefix string) {
+		for key, value := range m {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if nested, ok := value.(map[string]interface{}); ok {
+				flatten(nested, key)
+				continue
+			}
+			values[key] = value
+		}
+	}
+	flatten(doc, "")
+	return values, nil
+}
+
+// fileValue converts a YAML value to the raw form of the other sources,
+// or to a list for YAML sequences.
+func fileValue(value interface{}) (string, []string) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return "", list
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// setValue parses a raw value into a field. list, if set, is a list value
+// from the config file.
+func setValue(v reflect.Value, raw string, list []string) error {
+	if list != nil && v.Kind() != reflect.Slice {
+		return fmt.Errorf("expected a single value, got a list")
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean, use true or false", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if list == nil {
+			list = strings.Split(raw, ",")
+		}
+		values := make([]string, 0, len(list))
+		for _, value := range list {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			values = nil
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+// logLevels are the accepted logging levels.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// embeddingProviders are the accepted embedding backends.
+var embeddingProviders = []string{"", "onnx", "openai", "stub"}
+
+// llmProviders are the accepted language model providers.
+var llmProviders = []string{"", "openai", "anthropic", "stub"}
+
+// Validate checks the configuration, reporting every problem at once.
+func (c *Config) Validate() error {
+	problems := c.validate()
+	if len(problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(problems))
+	for i, problem := range problems {
+		errs[i] = problem.err
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+}
+
+// settingProblem is a validation failure and the setting it concerns.
+type settingProblem struct {
+	key string
+	err error
+}
+
+// validate lists the problems with the configuration.
+func (c *Config) validate() []settingProblem {
+	var problems []settingProblem
+	problem := func(key, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		if source := c.sources[key]; source != "" {
+			msg += " (from " + source + ")"
+		}
+		problems = append(problems, settingProblem{key: key, err: fmt.Errorf("%s: %s", key, msg)})
+	}
+
+	if c.Profile != "" && profiles[c.Profile] == nil {
+		problem("profile", "%q is not one of %s", c.Profile, strings.Join(profileNames, ", "))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		problem("port", "%d is not between 1 and 65535", c.Port)
+	}
+	if c.GRPCPort < 0 || c.GRPCPort > 65535 {
+		problem("grpc_port", "%d is not between 0 and 65535", c.GRPCPort)
+	} else if c.GRPCPort != 0 && c.GRPCPort == c.Port {
+		problem("grpc_port", "%d is also the HTTP port", c.GRPCPort)
+	}
+	if !contains(logLevels, c.LogLevel) {
+		problem("log_level", "%q is not one of %s", c.LogLevel, strings.Join(logLevels, ", "))
+	}
+	if err := checkURL(c.OIDC.Issuer); err != nil {
+		problem("oidc.issuer", "%v", err)
+	}
+	if err := checkURL(c.GitHub.APIURL); err != nil {
+		problem("github.api_url", "%v", err)
+	}
+	if c.GitHub.PrivateKey != "" && c.GitHub.AppID == "" {
+		problem("github.private_key", "is set without github.app_id")
+	}
+	if !contains(embeddingProviders, c.Embeddings.Provider) {
+		problem("embeddings.provider", "%q is not onnx, openai or stub", c.Embeddings.Provider)
+	}
+	if c.Embeddings.Provider == "onnx" && c.Embeddings.ModelPath == "" && !c.Offline {
+		problem("embeddings.model_path", "is required with the onnx provider")
+	}
+	if c.Embeddings.Provider == "openai" && !c.Offline {
+		if c.LLM.APIKey == "" {
+			problem("llm.api_key", "is required with the openai embeddings provider")
+		}
+		if c.LLM.EmbeddingModel == "" {
+			problem("llm.embedding_model", "is required with the openai embeddings provider")
+		}
+	}
+	if !contains(llmProviders, c.LLM.Provider) {
+		problem("llm.provider", "%q is not openai, anthropic or stub", c.LLM.Provider)
+	}
+	if (c.LLM.Provider == "openai" || c.LLM.Provider == "anthropic") && !c.Offline {
+		if c.LLM.APIKey == "" {
+			problem("llm.api_key", "is required with the %s provider", c.LLM.Provider)
+		}
+		if c.LLM.Model == "" {
+			problem("llm.model", "is required with the %s provider", c.LLM.Provider)
+		}
+	}
+	if c.LLM.BaseURL != "" {
+		if err := checkURL(c.LLM.BaseURL); err != nil {
+			problem("llm.base_url", "%v", err)
+		}
+	}
+	if c.LLM.Hedge.Provider != "" {
+		if !contains(llmProviders, c.LLM.Hedge.Provider) {
+			problem("llm.hedge.provider", "%q is not openai, anthropic or stub", c.LLM.Hedge.Provider)
+		}
+		if c.LLM.Provider == "" {
+			problem("llm.hedge.provider", "requires llm.provider")
+		}
+		if (c.LLM.Hedge.Provider == "openai" || c.LLM.Hedge.Provider == "anthropic") && !c.Offline {
+			if c.LLM.Hedge.APIKey == "" {
+				problem("llm.hedge.api_key", "is required with the %s provider", c.LLM.Hedge.Provider)
+			}
+			if c.LLM.Hedge.Model == "" {
+				problem("llm.hedge.model", "is required with the %s provider", c.LLM.Hedge.Provider)
+			}
+		}
+		if c.LLM.Hedge.BaseURL != "" {
+			if err := checkURL(c.LLM.Hedge.BaseURL); err != nil {
+				problem("llm.hedge.base_url", "%v", err)
+			}
+		}
+		if c.LLM.Hedge.Percentile <= 0 || c.LLM.Hedge.Percentile >= 100 {
+			problem("llm.hedge.percentile", "%g is not between 0 and 100", c.LLM.Hedge.Percentile)
+		}
+		if c.LLM.Hedge.DelayMillis < 1 {
+			problem("llm.hedge.delay_ms", "%d is not at least 1", c.LLM.Hedge.DelayMillis)
+		}
+		if c.LLM.Hedge.MaxRate < 0 || c.LLM.Hedge.MaxRate > 1 {
+			problem("llm.hedge.max_rate", "%g is not between 0 and 1", c.LLM.Hedge.MaxRate)
+		}
+	}
+	if c.LLM.MaxTokens < 1 {
+		problem("llm.max_tokens", "%d is not at least 1", c.LLM.MaxTokens)
+	}
+	if c.LLM.TimeoutSeconds < 1 {
+		problem("llm.timeout_seconds", "%d is not at least 1", c.LLM.TimeoutSeconds)
+	}
+	if c.LLM.Temperature < 0.2 || c.LLM.Temperature > 1.2 {
+		problem("llm.temperature", "%g is not between 0.2 and 1.2", c.LLM.Temperature)
+	}
+	if c.LLM.TopP < 0.7 || c.LLM.TopP > 1 {
+		problem("llm.top_p", "%g is not between 0.7 and 1", c.LLM.TopP)
+	}
+	if c.SessionTTLMinutes < 1 {
+		problem("session_ttl_minutes", "%d is not at least 1", c.SessionTTLMinutes)
+	}
+	if c.AgentContextRefreshSeconds < 0 {
+		problem("agent_context_refresh_seconds", "%d is negative", c.AgentContextRefreshSeconds)
+	}
+	if c.GitOps.Repo != "" {
+		if c.GitOps.Dir == "" {
+			problem("gitops.dir", "is required with gitops.repo")
+		}
+		if c.GitOps.Branch == "" {
+			problem("gitops.branch", "is required with gitops.repo")
+		}
+		if c.GitOps.IntervalSeconds < 1 {
+			problem("gitops.interval_seconds", "%d is not at least 1", c.GitOps.IntervalSeconds)
+		}
+	}
+	if c.Privacy.Epsilon < 0 || math.IsNaN(c.Privacy.Epsilon) || math.IsInf(c.Privacy.Epsilon, 0) {
+		problem("privacy.epsilon", "%v is not a non-negative number", c.Privacy.Epsilon)
+	}
+	if c.Privacy.MinTenants < 0 {
+		problem("privacy.min_tenants", "%d is negative", c.Privacy.MinTenants)
+	}
+	if c.Privacy.MaxContribution < 1 {
+		problem("privacy.max_contribution", "%d is not at least 1", c.Privacy.MaxContribution)
+	}
+	for _, peer := range c.Cluster.GossipPeers {
+		if err := checkURL(peer); err != nil {
+			problem("cluster.gossip_peers", "%v", err)
+		}
+	}
+	if len(c.Cluster.GossipPeers) > 0 && c.Cluster.GossipSecret == "" {
+		problem("cluster.gossip_secret", "is required with cluster.gossip_peers")
+	}
+	if c.Cluster.LeaderElection != "memory" && c.Cluster.LeaderElection != "kubernetes" {
+		problem("cluster.leader_election", "%q is not memory or kubernetes", c.Cluster.LeaderElection)
+	}
+	if c.Memory.SnapshotIntervalSeconds < 0 {
+		problem("memory.snapshot_interval_seconds", "%d is negative", c.Memory.SnapshotIntervalSeconds)
+	}
+	if c.Memory.ConsolidationIntervalSeconds < 1 {
+		problem("memory.consolidation_interval_seconds", "%d is not at least 1", c.Memory.ConsolidationIntervalSeconds)
+	}
+	if c.Cluster.GossipIntervalSeconds < 1 {
+		problem("cluster.gossip_interval_seconds", "%d is not at least 1", c.Cluster.GossipIntervalSeconds)
+	}
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "redis" {
+		problem("rate_limit.store", "%q is not memory or redis", c.RateLimit.Store)
+	}
+	if c.RateLimit.Store == "redis" && c.RateLimit.RedisAddr == "" && !c.Offline {
+		problem("rate_limit.redis_addr", "is required with the redis store")
+	}
+	if c.RateLimit.ClientQPS < 0 || math.IsNaN(c.RateLimit.ClientQPS) || math.IsInf(c.RateLimit.ClientQPS, 0) {
+		problem("rate_limit.client_qps", "%v is not a non-negative number", c.RateLimit.ClientQPS)
+	}
+	if c.RateLimit.ClientBurst < 0 {
+		problem("rate_limit.client_burst", "%d is negative", c.RateLimit.ClientBurst)
+	}
+	if c.RateLimit.AgentQPS < 0 || math.IsNaN(c.RateLimit.AgentQPS) || math.IsInf(c.RateLimit.AgentQPS, 0) {
+		problem("rate_limit.agent_qps", "%v is not a non-negative number", c.RateLimit.AgentQPS)
+	}
+	if c.RateLimit.AgentBurst < 0 {
+		problem("rate_limit.agent_burst", "%d is negative", c.RateLimit.AgentBurst)
+	}
+	if c.WorkflowStateDir != "" && c.WorkflowsDir == "" {
+		problem("workflows_state_dir", "is set without workflows_dir")
+	}
+
+	if c.strict() {
+		if c.OIDC.ClientID == "" {
+			problem("oidc.client_id", "is required in the %s profile", c.Profile)
+		}
+		if c.Offline {
+			problem("offline", "is not allowed in the %s profile", c.Profile)
+		}
+		if c.Embeddings.Provider == "stub" {
+			problem("embeddings.provider", "stub is not allowed in the %s profile", c.Profile)
+		}
+		if c.LLM.Provider == "stub" {
+			problem("llm.provider", "stub is not allowed in the %s profile", c.Profile)
+		}
+		if c.Memory.WALPath == "" {
+			problem("memory.wal_path", "is required in the %s profile", c.Profile)
+		}
+		if c.Memory.SnapshotPath == "" {
+			problem("memory.snapshot_path", "is required in the %s profile", c.Profile)
+		}
+	}
+	return problems
+}
+
+// checkURL requires an absolute http or https URL.
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", raw)
+	}
+	return nil
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ============================================================================
+// Reporting
+// ============================================================================
+
+// maskedValue replaces secret values in reports.
+const maskedValue = "********"
+
+// Setting is one effective configuration value and where it came from.
+type Setting struct {
+	Key string
+	// Value is the value as text, masked for secrets
+	Value string
+	// Source is "default", "file PATH", "env NAME" or "flag -NAME"
+	Source string
+}
+
+// Settings returns every setting's effective value in declaration order.
+func (c *Config) Settings() []Setting {
+	settings := c.settings()
+	report := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		value := fmt.Sprint(s.value.Interface())
+		if s.value.Kind() == reflect.Slice {
+			value = strings.Join(s.value.Interface().([]string), ",")
+		}
+		if s.secret && value != "" {
+			value = maskedValue
+		}
+		source := c.sources[s.key]
+		if source == "" {
+			source = "default"
+		}
+		report = append(report, Setting{Key: s.key, Value: value, Source: source})
+	}
+	return report
+}
+
+// WriteReport prints the effective configuration, one setting per line
+// with its source; secrets are masked.
+func (c *Config) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, s := range c.Settings() {
+		value := s.Value
+		if value == "" {
+			value = `""`
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, value, s.Source)
+	}
+	return tw.Flush()
+}
+
+// WriteUsage prints the command line and every setting with its flag,
+// environment variable and default.
+func WriteUsage(w io.Writer) error {
+	fmt.Fprintln(w, "usage: server [-config FILE] [-print-config] [-selftest] [-SETTING VALUE]...")
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV\tDEFAULT\tDESCRIPTION")
+	for _, s := range (&Config{}).settings() {
+		def := s.def
+		if def == "" {
+			def = `""`
+		}
+		fmt.Fprintf(tw, "-%s\t%s\t%s\t%s\n", flagName(s.key), s.env, def, s.help)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "profiles (-profile or ENV) change these defaults:")
+	for _, name := range profileNames {
+		defaults := profiles[name]
+		keys := make([]string, 0, len(defaults))
+		for key := range defaults {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			keys[i] = key + "=" + defaults[key]
+		}
+		fmt.Fprintf(w, "  %-8s %s\n", name, strings.Join(keys, " "))
 	}
-	return value
+	return nil
 }
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -9,11 +14,33 @@ func TestLoadWithDefaults(t *testing.T) {
 	// Clear environment variables
 	os.Unsetenv("PORT")
 	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("OFFLINE_MODE")
 	os.Unsetenv("OIDC_ISSUER")
 	os.Unsetenv("OIDC_CLIENT_ID")
 	os.Unsetenv("OIDC_CLIENT_SECRET")
+	os.Unsetenv("MEMORY_WAL_PATH")
+	os.Unsetenv("MEMORY_SNAPSHOT_PATH")
+	os.Unsetenv("MEMORY_GOAL_JOURNAL_DIR")
+	os.Unsetenv("MEMORY_WARMUP_DEGRADED")
+	os.Unsetenv("WORKFLOWS_DIR")
+	os.Unsetenv("WORKFLOWS_STATE_DIR")
+	os.Unsetenv("GITHUB_API_URL")
+	os.Unsetenv("ACTIONS_ALLOWED_OWNERS")
+	os.Unsetenv("INTEGRATIONS_CONFIG")
+	os.Unsetenv("TOOLS_CONFIG")
+	os.Unsetenv("AUDIT_LOG_PATH")
+	os.Unsetenv("EMBEDDINGS_PROVIDER")
+	os.Unsetenv("EMBEDDINGS_MODEL_PATH")
+	os.Unsetenv("ONNXRUNTIME_LIB")
+	os.Unsetenv("EMBEDDINGS_CACHE_PATH")
+	os.Unsetenv("FEATURES_CONFIG")
+	os.Unsetenv("ADMIN_SUBJECTS")
+	os.Unsetenv("INTENT_TEMPLATES")
 
-	cfg := Load()
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("expected defaults to load, got %v", err)
+	}
 
 	if cfg.Port != 8080 {
 		t.Errorf("expected default port 8080, got %d", cfg.Port)
@@ -23,6 +50,10 @@ func TestLoadWithDefaults(t *testing.T) {
 		t.Errorf("expected default log level 'info', got %s", cfg.LogLevel)
 	}
 
+	if cfg.Offline {
+		t.Error("expected offline mode disabled by default")
+	}
+
 	if cfg.OIDC.Issuer != "https://token.actions.githubusercontent.com" {
 		t.Errorf("expected default OIDC issuer, got %s", cfg.OIDC.Issuer)
 	}
@@ -30,23 +61,124 @@ func TestLoadWithDefaults(t *testing.T) {
 	if cfg.OIDC.ClientID != "" {
 		t.Errorf("expected empty OIDC client ID, got %s", cfg.OIDC.ClientID)
 	}
+
+	if cfg.Memory.WALPath != "" {
+		t.Errorf("expected write-ahead log disabled by default, got %s", cfg.Memory.WALPath)
+	}
+
+	if cfg.Memory.SnapshotPath != "" {
+		t.Errorf("expected snapshots disabled by default, got %s", cfg.Memory.SnapshotPath)
+	}
+
+	if cfg.Memory.GoalJournalDir != "" {
+		t.Errorf("expected goal journals kept in memory by default, got %s", cfg.Memory.GoalJournalDir)
+	}
+
+	if cfg.Memory.WarmupServeDegraded {
+		t.Error("expected degraded warmup serving disabled by default")
+	}
+
+	if cfg.WorkflowsDir != "" {
+		t.Errorf("expected workflows disabled by default, got %s", cfg.WorkflowsDir)
+	}
+
+	if cfg.WorkflowStateDir != "" {
+		t.Errorf("expected workflow runs kept in memory by default, got %s", cfg.WorkflowStateDir)
+	}
+
+	if cfg.IntegrationsConfig != "" {
+		t.Errorf("expected chat integrations disabled by default, got %s", cfg.IntegrationsConfig)
+	}
+
+	if cfg.ToolsConfig != "" || cfg.AuditLogPath != "" {
+		t.Errorf("expected agent tools disabled by default, got %s and %s", cfg.ToolsConfig, cfg.AuditLogPath)
+	}
+
+	if cfg.Embeddings.Provider != "" || cfg.Embeddings.CachePath != "" {
+		t.Errorf("expected embeddings disabled by default, got %+v", cfg.Embeddings)
+	}
+
+	if cfg.GitHub.APIURL != "https://api.github.com" {
+		t.Errorf("expected default GitHub API URL, got %s", cfg.GitHub.APIURL)
+	}
+
+	if len(cfg.GitHub.ActionsAllowedOwners) != 0 {
+		t.Errorf("expected any Actions owner allowed by default, got %v", cfg.GitHub.ActionsAllowedOwners)
+	}
+
+	if cfg.FeaturesConfig != "" || len(cfg.AdminSubjects) != 0 {
+		t.Errorf("expected default feature flags and no admins, got %s and %v", cfg.FeaturesConfig, cfg.AdminSubjects)
+	}
+
+	if cfg.IntentTemplates != "" {
+		t.Errorf("expected no intent templates by default, got %s", cfg.IntentTemplates)
+	}
+
+	if cfg.Privacy != (PrivacyConfig{MaxContribution: 10}) {
+		t.Errorf("expected feedback privacy disabled by default, got %+v", cfg.Privacy)
+	}
+
+	if cfg.RateLimit != (RateLimitConfig{Store: "memory"}) {
+		t.Errorf("expected no rate limits by default, got %+v", cfg.RateLimit)
+	}
 }
 
 func TestLoadWithEnvironmentVariables(t *testing.T) {
 	os.Setenv("PORT", "3000")
 	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("OFFLINE_MODE", "true")
 	os.Setenv("OIDC_ISSUER", "https://example.com")
 	os.Setenv("OIDC_CLIENT_ID", "test-client")
 	os.Setenv("OIDC_CLIENT_SECRET", "test-secret")
+	os.Setenv("MEMORY_WAL_PATH", "/var/lib/mnemonic/semantic.wal")
+	os.Setenv("MEMORY_SNAPSHOT_PATH", "/var/lib/mnemonic/semantic.snapshot")
+	os.Setenv("MEMORY_GOAL_JOURNAL_DIR", "/var/lib/mnemonic/goal-journals")
+	os.Setenv("MEMORY_WARMUP_DEGRADED", "true")
+	os.Setenv("WORKFLOWS_DIR", "/etc/elite/workflows")
+	os.Setenv("WORKFLOWS_STATE_DIR", "/var/lib/elite/workflow-runs")
+	os.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3")
+	os.Setenv("ACTIONS_ALLOWED_OWNERS", "octo-org, ,elite-labs")
+	os.Setenv("INTEGRATIONS_CONFIG", "/etc/elite/integrations.yaml")
+	os.Setenv("TOOLS_CONFIG", "/etc/elite/tools.yaml")
+	os.Setenv("AUDIT_LOG_PATH", "/var/log/elite/audit.jsonl")
+	os.Setenv("EMBEDDINGS_PROVIDER", "onnx")
+	os.Setenv("EMBEDDINGS_MODEL_PATH", "/models/all-MiniLM-L6-v2/model.onnx")
+	os.Setenv("ONNXRUNTIME_LIB", "/usr/lib/libonnxruntime.so")
+	os.Setenv("EMBEDDINGS_CACHE_PATH", "/var/lib/elite/embeddings.json")
+	os.Setenv("FEATURES_CONFIG", "/etc/elite/features.yaml")
+	os.Setenv("ADMIN_SUBJECTS", "repo:elite-labs/ops:ref:refs/heads/main")
+	os.Setenv("INTENT_TEMPLATES", "/etc/elite/intents.yaml")
 	defer func() {
 		os.Unsetenv("PORT")
 		os.Unsetenv("LOG_LEVEL")
+		os.Unsetenv("OFFLINE_MODE")
 		os.Unsetenv("OIDC_ISSUER")
 		os.Unsetenv("OIDC_CLIENT_ID")
 		os.Unsetenv("OIDC_CLIENT_SECRET")
+		os.Unsetenv("MEMORY_WAL_PATH")
+		os.Unsetenv("MEMORY_SNAPSHOT_PATH")
+		os.Unsetenv("MEMORY_GOAL_JOURNAL_DIR")
+		os.Unsetenv("MEMORY_WARMUP_DEGRADED")
+		os.Unsetenv("WORKFLOWS_DIR")
+		os.Unsetenv("WORKFLOWS_STATE_DIR")
+		os.Unsetenv("GITHUB_API_URL")
+		os.Unsetenv("ACTIONS_ALLOWED_OWNERS")
+		os.Unsetenv("INTEGRATIONS_CONFIG")
+		os.Unsetenv("TOOLS_CONFIG")
+		os.Unsetenv("AUDIT_LOG_PATH")
+		os.Unsetenv("EMBEDDINGS_PROVIDER")
+		os.Unsetenv("EMBEDDINGS_MODEL_PATH")
+		os.Unsetenv("ONNXRUNTIME_LIB")
+		os.Unsetenv("EMBEDDINGS_CACHE_PATH")
+		os.Unsetenv("FEATURES_CONFIG")
+		os.Unsetenv("ADMIN_SUBJECTS")
+		os.Unsetenv("INTENT_TEMPLATES")
 	}()
 
-	cfg := Load()
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("expected defaults to load, got %v", err)
+	}
 
 	if cfg.Port != 3000 {
 		t.Errorf("expected port 3000, got %d", cfg.Port)
@@ -56,6 +188,10 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 		t.Errorf("expected log level 'debug', got %s", cfg.LogLevel)
 	}
 
+	if !cfg.Offline {
+		t.Error("expected offline mode from environment")
+	}
+
 	if cfg.OIDC.Issuer != "https://example.com" {
 		t.Errorf("expected OIDC issuer 'https://example.com', got %s", cfg.OIDC.Issuer)
 	}
@@ -67,16 +203,294 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 	if cfg.OIDC.ClientSecret != "test-secret" {
 		t.Errorf("expected OIDC client secret 'test-secret', got %s", cfg.OIDC.ClientSecret)
 	}
+
+	if cfg.Memory.WALPath != "/var/lib/mnemonic/semantic.wal" {
+		t.Errorf("expected write-ahead log path from environment, got %s", cfg.Memory.WALPath)
+	}
+
+	if cfg.Memory.SnapshotPath != "/var/lib/mnemonic/semantic.snapshot" {
+		t.Errorf("expected snapshot path from environment, got %s", cfg.Memory.SnapshotPath)
+	}
+
+	if cfg.Memory.GoalJournalDir != "/var/lib/mnemonic/goal-journals" {
+		t.Errorf("expected goal journal directory from environment, got %s", cfg.Memory.GoalJournalDir)
+	}
+
+	if !cfg.Memory.WarmupServeDegraded {
+		t.Error("expected degraded warmup serving from environment")
+	}
+
+	if cfg.WorkflowsDir != "/etc/elite/workflows" {
+		t.Errorf("expected workflows directory from environment, got %s", cfg.WorkflowsDir)
+	}
+
+	if cfg.WorkflowStateDir != "/var/lib/elite/workflow-runs" {
+		t.Errorf("expected workflow state directory from environment, got %s", cfg.WorkflowStateDir)
+	}
+
+	if cfg.IntegrationsConfig != "/etc/elite/integrations.yaml" {
+		t.Errorf("expected integrations config from environment, got %s", cfg.IntegrationsConfig)
+	}
+
+	if cfg.ToolsConfig != "/etc/elite/tools.yaml" {
+		t.Errorf("expected tools config from environment, got %s", cfg.ToolsConfig)
+	}
+
+	if cfg.AuditLogPath != "/var/log/elite/audit.jsonl" {
+		t.Errorf("expected audit log path from environment, got %s", cfg.AuditLogPath)
+	}
+
+	want := EmbeddingsConfig{
+		Provider:    "onnx",
+		ModelPath:   "/models/all-MiniLM-L6-v2/model.onnx",
+		LibraryPath: "/usr/lib/libonnxruntime.so",
+		CachePath:   "/var/lib/elite/embeddings.json",
+	}
+	if cfg.Embeddings != want {
+		t.Errorf("expected embeddings config from environment, got %+v", cfg.Embeddings)
+	}
+
+	if cfg.GitHub.APIURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected GitHub API URL from environment, got %s", cfg.GitHub.APIURL)
+	}
+
+	owners := cfg.GitHub.ActionsAllowedOwners
+	if len(owners) != 2 || owners[0] != "octo-org" || owners[1] != "elite-labs" {
+		t.Errorf("expected 2 Actions owners from environment, got %v", owners)
+	}
+
+	if cfg.FeaturesConfig != "/etc/elite/features.yaml" {
+		t.Errorf("expected features config from environment, got %s", cfg.FeaturesConfig)
+	}
+
+	if admins := cfg.AdminSubjects; len(admins) != 1 || admins[0] != "repo:elite-labs/ops:ref:refs/heads/main" {
+		t.Errorf("expected 1 admin subject from environment, got %v", admins)
+	}
+
+	if cfg.IntentTemplates != "/etc/elite/intents.yaml" {
+		t.Errorf("expected intent templates from environment, got %s", cfg.IntentTemplates)
+	}
 }
 
 func TestLoadWithInvalidPort(t *testing.T) {
 	os.Setenv("PORT", "invalid")
 	defer os.Unsetenv("PORT")
 
-	cfg := Load()
+	_, err := Load(nil)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `port: "invalid" is not an integer (from env PORT)`) {
+		t.Errorf("expected the error to name the setting and its source, got %v", err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	file := `
+port: 9000
+log_level: warn
+memory:
+  wal_path: /data/semantic.wal
+github:
+  actions_allowed_owners: [octo-org, elite-labs]
+privacy:
+  epsilon: 0.5
+  min_tenants: 3
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("LOG_LEVEL", "error")
+	defer os.Unsetenv("LOG_LEVEL")
 
-	// Should fall back to default
-	if cfg.Port != 8080 {
-		t.Errorf("expected default port 8080 for invalid value, got %d", cfg.Port)
+	cfg, err := Load([]string{"-config", path, "-port", "9100", "-offline", "-print-config"})
+	if err != nil {
+		t.Fatalf("expected configuration to load, got %v", err)
+	}
+
+	if cfg.Port != 9100 {
+		t.Errorf("expected the flag to override the file, got port %d", cfg.Port)
+	}
+	if cfg.LogLevel != "error" {
+		t.Errorf("expected the environment to override the file, got log level %s", cfg.LogLevel)
+	}
+	if cfg.Memory.WALPath != "/data/semantic.wal" {
+		t.Errorf("expected nested settings from the file, got %s", cfg.Memory.WALPath)
+	}
+	if owners := cfg.GitHub.ActionsAllowedOwners; len(owners) != 2 || owners[1] != "elite-labs" {
+		t.Errorf("expected a list from the file, got %v", owners)
+	}
+	if cfg.Privacy.Epsilon != 0.5 || cfg.Privacy.MinTenants != 3 {
+		t.Errorf("expected privacy settings from the file, got %+v", cfg.Privacy)
+	}
+	if !cfg.Offline || !cfg.PrintConfig || cfg.File != path {
+		t.Errorf("expected boolean flags without values, got offline=%v print=%v", cfg.Offline, cfg.PrintConfig)
+	}
+
+	sources := make(map[string]string)
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	want := map[string]string{
+		"port":            "flag -port",
+		"log_level":       "env LOG_LEVEL",
+		"memory.wal_path": "file " + path,
+		"oidc.issuer":     "default",
+	}
+	for key, source := range want {
+		if sources[key] != source {
+			t.Errorf("expected %s from %s, got %s", key, source, sources[key])
+		}
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	if err := os.WriteFile(path, []byte("prot: 9000\nembeddings:\n  provider: cohere\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load([]string{"-config", path, "-port", "70000", "-github.api-url", "github.example.com", "-privacy.epsilon", "-1", "-privacy.max-contribution", "0"})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	for _, want := range []string{
+		"prot: unknown setting in " + path,
+		"port: 70000 is not between 1 and 65535 (from flag -port)",
+		`embeddings.provider: "cohere" is not onnx, openai or stub (from file ` + path + ")",
+		`github.api_url: "github.example.com" is not an http or https URL`,
+		"privacy.epsilon: -1 is not a non-negative number (from flag -privacy.epsilon)",
+		"privacy.max_contribution: 0 is not at least 1 (from flag -privacy.max-contribution)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got %v", want, err)
+		}
+	}
+
+	if _, err := Load([]string{"-rate-limit.store", "redis", "-rate-limit.client-qps", "-2"}); err == nil ||
+		!strings.Contains(err.Error(), "rate_limit.redis_addr: is required with the redis store") ||
+		!strings.Contains(err.Error(), "rate_limit.client_qps: -2 is not a non-negative number") {
+		t.Errorf("expected the rate limit problems reported, got %v", err)
+	}
+	if _, err := Load([]string{"-port", "9000", "-grpc-port", "9000"}); err == nil || !strings.Contains(err.Error(), "grpc_port: 9000 is also the HTTP port") {
+		t.Errorf("expected the shared port reported, got %v", err)
+	}
+	if _, err := Load([]string{"-llm.provider", "anthropic", "-llm.max-tokens", "0"}); err == nil ||
+		!strings.Contains(err.Error(), "llm.api_key: is required with the anthropic provider") ||
+		!strings.Contains(err.Error(), "llm.model: is required with the anthropic provider") ||
+		!strings.Contains(err.Error(), "llm.max_tokens: 0 is not at least 1") {
+		t.Errorf("expected the missing provider settings reported, got %v", err)
+	}
+	if _, err := Load([]string{"-llm.temperature", "2", "-llm.top-p", "0.5"}); err == nil ||
+		!strings.Contains(err.Error(), "llm.temperature: 2 is not between 0.2 and 1.2") ||
+		!strings.Contains(err.Error(), "llm.top_p: 0.5 is not between 0.7 and 1") {
+		t.Errorf("expected the sampling bounds reported, got %v", err)
+	}
+	if _, err := Load([]string{"-embeddings.provider", "openai", "-llm.api-key", "sk-test"}); err == nil ||
+		!strings.Contains(err.Error(), "llm.embedding_model: is required with the openai embeddings provider") {
+		t.Errorf("expected the missing embedding model reported, got %v", err)
+	}
+	if _, err := Load([]string{"-gitops.repo", "https://github.com/example/prompts.git", "-gitops.interval-seconds", "0"}); err == nil ||
+		!strings.Contains(err.Error(), "gitops.interval_seconds: 0 is not at least 1") {
+		t.Errorf("expected the sync interval reported, got %v", err)
+	}
+	if _, err := Load([]string{"-cluster.gossip-peers", "https://replica-2:8080,replica-3"}); err == nil ||
+		!strings.Contains(err.Error(), `cluster.gossip_peers: "replica-3" is not an http or https URL`) ||
+		!strings.Contains(err.Error(), "cluster.gossip_secret: is required with cluster.gossip_peers") {
+		t.Errorf("expected the gossip peer problems reported, got %v", err)
+	}
+	if _, err := Load([]string{"-cluster.leader-election", "redis", "-memory.snapshot-interval-seconds", "-1"}); err == nil ||
+		!strings.Contains(err.Error(), `cluster.leader_election: "redis" is not memory or kubernetes`) ||
+		!strings.Contains(err.Error(), "memory.snapshot_interval_seconds: -1 is negative") {
+		t.Errorf("expected the leader election problems reported, got %v", err)
+	}
+	if _, err := Load([]string{"-no-such-setting"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for an unknown flag, got %v", err)
+	}
+	if _, err := Load([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp for -h, got %v", err)
+	}
+}
+
+func TestWriteReportMasksSecrets(t *testing.T) {
+	os.Setenv("OIDC_CLIENT_SECRET", "hunter2")
+	defer os.Unsetenv("OIDC_CLIENT_SECRET")
+
+	cfg, err := Load([]string{"-session-signing-key", "s3cret"})
+	if err != nil {
+		t.Fatalf("expected configuration to load, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.WriteReport(&out); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+	if strings.Contains(report, "hunter2") || strings.Contains(report, "s3cret") {
+		t.Errorf("expected secrets masked, got:\n%s", report)
+	}
+	for _, want := range []string{"oidc.client_secret", "********", "env OIDC_CLIENT_SECRET", "flag -session-signing-key", "port"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in report, got:\n%s", want, report)
+		}
+	}
+
+	out.Reset()
+	WriteUsage(&out)
+	if !strings.Contains(out.String(), "-memory.wal-path") || !strings.Contains(out.String(), "MEMORY_WAL_PATH") {
+		t.Errorf("expected every setting in the usage, got:\n%s", out.String())
+	}
+}
+
+func TestLoadDevProfile(t *testing.T) {
+	os.Setenv("ENV", "dev")
+	defer os.Unsetenv("ENV")
+
+	cfg, err := Load([]string{"-log-level", "warn"})
+	if err != nil {
+		t.Fatalf("expected the dev profile to load, got %v", err)
+	}
+	if !cfg.Offline || cfg.Embeddings.Provider != "stub" || !cfg.Memory.WarmupServeDegraded {
+		t.Errorf("expected stub providers in dev, got offline=%v provider=%q", cfg.Offline, cfg.Embeddings.Provider)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("expected a flag to override the profile, got log level %s", cfg.LogLevel)
+	}
+
+	sources := make(map[string]string)
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	if sources["offline"] != "profile dev" || sources["profile"] != "env ENV" {
+		t.Errorf("expected profile sources, got offline from %s and profile from %s", sources["offline"], sources["profile"])
+	}
+}
+
+func TestLoadProdProfile(t *testing.T) {
+	_, err := Load([]string{"-profile", "prod", "-offline", "-memory.snapshot-path", ""})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	for _, want := range []string{
+		"oidc.client_id: is required in the prod profile",
+		"offline: is not allowed in the prod profile (from flag -offline)",
+		"memory.snapshot_path: is required in the prod profile (from flag -memory.snapshot-path)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got %v", want, err)
+		}
+	}
+
+	cfg, err := Load([]string{"-profile", "prod", "-oidc.client-id", "eac"})
+	if err != nil {
+		t.Fatalf("expected the prod profile to load, got %v", err)
+	}
+	if cfg.Memory.WALPath == "" || cfg.Memory.SnapshotPath == "" || cfg.PreferencesDir == "" {
+		t.Errorf("expected persistence in prod, got %+v", cfg.Memory)
+	}
+
+	if _, err := Load([]string{"-profile", "qa"}); err == nil || !strings.Contains(err.Error(), `profile: "qa" is not one of dev, staging, prod`) {
+		t.Errorf("expected an unknown profile rejected, got %v", err)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
 )
@@ -52,10 +53,92 @@ func NewResponse(content string) *models.CopilotResponse {
 	}
 }
 
+// NewAgentResponse creates a Copilot response from an agent's structured
+// answer. The message is the answer rendered as Markdown, and the answer
+// itself is kept on the choice for clients that render it themselves.
+func NewAgentResponse(answer *models.AgentResponse) *models.CopilotResponse {
+	resp := NewResponse(Render(answer))
+	resp.Choices[0].Structured = answer
+	return resp
+}
+
+// Render renders a structured answer as Markdown: the summary, the
+// reasoning and sections under headings, artifacts as fenced code blocks
+// tagged with their language, then the sources and follow-up suggestions.
+func Render(answer *models.AgentResponse) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(answer.Summary))
+	b.WriteString("\n")
+
+	if reasoning := strings.TrimSpace(answer.Reasoning); reasoning != "" {
+		fmt.Fprintf(&b, "\n## Reasoning\n\n%s\n", reasoning)
+	}
+	for _, section := range answer.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", section.Title, strings.TrimSpace(section.Body))
+	}
+	for _, artifact := range answer.Artifacts {
+		b.WriteString("\n")
+		if artifact.Title != "" {
+			fmt.Fprintf(&b, "**%s**\n\n", artifact.Title)
+		}
+		fence := codeFence(artifact.Content)
+		content := strings.TrimRight(artifact.Content, "\n")
+		fmt.Fprintf(&b, "%s%s\n%s\n%s\n", fence, artifact.Language, content, fence)
+	}
+	if len(answer.Citations) > 0 {
+		b.WriteString("\n## Sources\n\n")
+		for i, citation := range answer.Citations {
+			if citation.URL != "" {
+				fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, citation.Title, citation.URL)
+			} else {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, citation.Title)
+			}
+		}
+	}
+	if len(answer.FollowUps) > 0 {
+		b.WriteString("\n## Follow-up Suggestions\n\n")
+		for _, followUp := range answer.FollowUps {
+			fmt.Fprintf(&b, "- %s\n", followUp)
+		}
+	}
+	return b.String()
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// content, so the content cannot close the block early.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, c := range content {
+		if c != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// Stages of a drafted stream.
+const (
+	// StageDraft chunks are a fast draft from a small model
+	StageDraft = "draft"
+	// StageRefined chunks are the answer from the full pipeline, which
+	// replaces the draft
+	StageRefined = "refined"
+)
+
 // SSEWriter provides Server-Sent Events streaming support for Copilot responses.
 type SSEWriter struct {
 	w       http.ResponseWriter
 	flusher http.Flusher
+	// stage marks the chunks written, if set
+	stage string
 }
 
 // NewSSEWriter creates a new SSE writer for streaming responses.
@@ -76,9 +159,20 @@ func (s *SSEWriter) Init() {
 	s.w.Header().Set("X-Accel-Buffering", "no")
 }
 
+// SetStage marks the chunks written from now on as belonging to a stage
+// of a drafted stream.
+func (s *SSEWriter) SetStage(stage string) {
+	s.stage = stage
+}
+
 // StreamChunk represents a single chunk in a streaming response.
 type StreamChunk struct {
 	Choices []StreamChoice `json:"choices"`
+	// CopilotReferences are sent in a chunk of their own, before the
+	// content
+	CopilotReferences []models.Reference `json:"copilot_references,omitempty"`
+	// Stage is the stage of a drafted stream the chunk belongs to
+	Stage string `json:"stage,omitempty"`
 }
 
 // StreamChoice represents a choice in a streaming response.
@@ -124,6 +218,11 @@ func (s *SSEWriter) WriteRole(role string) error {
 	return s.writeData(chunk)
 }
 
+// WriteReferences writes the references an answer cites.
+func (s *SSEWriter) WriteReferences(references []models.Reference) error {
+	return s.writeData(StreamChunk{Choices: []StreamChoice{}, CopilotReferences: references})
+}
+
 // WriteEnd writes the final chunk to close the stream.
 func (s *SSEWriter) WriteEnd() error {
 	chunk := StreamChunk{
@@ -147,9 +246,10 @@ func (s *SSEWriter) WriteEnd() error {
 	return nil
 }
 
-// writeData marshals and writes data to the SSE stream.
-func (s *SSEWriter) writeData(data interface{}) error {
-	jsonData, err := json.Marshal(data)
+// writeData marshals and writes a chunk to the SSE stream.
+func (s *SSEWriter) writeData(chunk StreamChunk) error {
+	chunk.Stage = s.stage
+	jsonData, err := json.Marshal(chunk)
 	if err != nil {
 		return err
 	}
@@ -167,26 +267,43 @@ func (s *SSEWriter) writeData(data interface{}) error {
 // If the ResponseWriter doesn't support streaming (no Flusher interface),
 // it falls back to a regular JSON response.
 func WriteStreamingResponse(w http.ResponseWriter, content string) error {
+	return StreamResponse(w, NewResponse(content))
+}
+
+// StreamResponse writes a complete response as a stream: its references,
+// if any, then the content of its first choice. Like
+// WriteStreamingResponse, it falls back to a regular JSON response.
+func StreamResponse(w http.ResponseWriter, resp *models.CopilotResponse) error {
 	sse := NewSSEWriter(w)
 	if sse == nil {
 		// Fall back to regular response if streaming not supported
 		// Log this fallback so it's visible in debugging
 		log.Printf("SSE streaming not supported, falling back to JSON response")
-		return WriteResponse(w, NewResponse(content))
+		return WriteResponse(w, resp)
 	}
 
 	sse.Init()
+	return sse.WriteResponse(resp)
+}
 
+// WriteResponse writes a complete response to the stream and ends it.
+func (s *SSEWriter) WriteResponse(resp *models.CopilotResponse) error {
 	// Write role
-	if err := sse.WriteRole("assistant"); err != nil {
+	if err := s.WriteRole("assistant"); err != nil {
 		return err
 	}
 
+	if len(resp.CopilotReferences) > 0 {
+		if err := s.WriteReferences(resp.CopilotReferences); err != nil {
+			return err
+		}
+	}
+
 	// Write content as a single chunk
-	if err := sse.WriteChunk(content); err != nil {
+	if err := s.WriteChunk(resp.Choices[0].Message.Content); err != nil {
 		return err
 	}
 
 	// Write end
-	return sse.WriteEnd()
+	return s.WriteEnd()
 }
@@ -3,6 +3,7 @@ package copilot
 import (
 	"encoding/json"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
@@ -90,3 +91,104 @@ func TestNewResponse(t *testing.T) {
 		t.Errorf("expected finish reason 'stop', got %s", resp.Choices[0].FinishReason)
 	}
 }
+
+func TestNewAgentResponse(t *testing.T) {
+	answer := &models.AgentResponse{
+		Summary:   "Use a min-heap.",
+		Reasoning: "Each pop is O(log n).",
+		Sections:  []models.ResponseSection{{Title: "Trade-offs", Body: "More memory than a sorted slice."}},
+		Artifacts: []models.Artifact{
+			{Title: "heap.go", Language: "go", Content: "package heap\n"},
+			{Language: "markdown", Content: "```go\nx := 1\n```"},
+		},
+		Citations: []models.Citation{{Title: "container/heap", URL: "https://pkg.go.dev/container/heap"}, {Title: "Knowledge graph"}},
+		FollowUps: []string{"Benchmark it"},
+	}
+	resp := NewAgentResponse(answer)
+	if resp.Choices[0].Structured != answer {
+		t.Fatal("expected the structured answer on the choice")
+	}
+
+	content := resp.Choices[0].Message.Content
+	for _, want := range []string{
+		"Use a min-heap.\n",
+		"## Reasoning\n\nEach pop is O(log n).\n",
+		"## Trade-offs\n\nMore memory than a sorted slice.\n",
+		"**heap.go**\n\n```go\npackage heap\n```\n",
+		"````markdown\n```go\nx := 1\n```\n````\n",
+		"## Sources\n\n1. [container/heap](https://pkg.go.dev/container/heap)\n2. Knowledge graph\n",
+		"## Follow-up Suggestions\n\n- Benchmark it\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in the rendered answer, got:\n%s", want, content)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := WriteResponse(w, resp); err != nil {
+		t.Fatal(err)
+	}
+	var decoded models.CopilotResponse
+	if err := json.NewDecoder(w.Body).Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if s := decoded.Choices[0].Structured; s == nil || s.Artifacts[0].Language != "go" || s.FollowUps[0] != "Benchmark it" {
+		t.Errorf("expected the structured answer in the JSON, got %+v", s)
+	}
+}
+
+func TestRenderSummaryOnly(t *testing.T) {
+	if got := Render(&models.AgentResponse{Summary: "Done. "}); got != "Done.\n" {
+		t.Errorf("expected just the summary, got %q", got)
+	}
+}
+
+func TestStreamResponseReferences(t *testing.T) {
+	w := httptest.NewRecorder()
+	resp := NewResponse("It serves [main.go:L1].")
+	resp.CopilotReferences = []models.Reference{{Type: models.ReferenceCitation, ID: "main.go", Metadata: &models.ReferenceMetadata{DisplayName: "main.go:L1"}}}
+	if err := StreamResponse(w, resp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if len(events) < 3 {
+		t.Fatalf("expected role, references and content events, got %q", w.Body.String())
+	}
+	var chunk StreamChunk
+	if err := json.Unmarshal([]byte(events[1]), &chunk); err != nil || len(chunk.CopilotReferences) != 1 || chunk.CopilotReferences[0].ID != "main.go" {
+		t.Errorf("expected the references before the content, got %s", events[1])
+	}
+	if !strings.Contains(events[2], "It serves") {
+		t.Errorf("expected the content after the references, got %s", events[2])
+	}
+}
+
+func TestSSEWriterStage(t *testing.T) {
+	w := httptest.NewRecorder()
+	sse := NewSSEWriter(w)
+	sse.SetStage(StageDraft)
+	if err := sse.WriteChunk("Use a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sse.SetStage(StageRefined)
+	if err := sse.WriteEnd(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var stages []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		var chunk StreamChunk
+		if strings.HasPrefix(line, "data: {") && json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk) == nil {
+			stages = append(stages, chunk.Stage)
+		}
+	}
+	if len(stages) != 2 || stages[0] != StageDraft || stages[1] != StageRefined {
+		t.Errorf("expected a draft chunk and a refined end, got %q", stages)
+	}
+}
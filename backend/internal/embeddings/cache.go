@@ -0,0 +1,360 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// CacheFileVersion is the format of saved cache files.
+const CacheFileVersion = 1
+
+// ErrCacheCorrupt is returned for cache files that can't be read.
+var ErrCacheCorrupt = errdefs.New(errdefs.ErrInternal, "embedding cache file is corrupt")
+
+// CacheConfig configures an embedding cache.
+type CacheConfig struct {
+	// Path is the file the cache is loaded from and saved to; empty keeps
+	// it in memory only
+	Path string
+	// TTL is how long an embedding is reused after it was computed; zero
+	// keeps embeddings until they are evicted
+	TTL time.Duration
+	// MaxEntries bounds the cache; the least recently used embeddings are
+	// evicted first
+	MaxEntries int
+}
+
+// DefaultCacheConfig returns a cache config suited to a single server.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		TTL:        30 * 24 * time.Hour,
+		MaxEntries: 50000,
+	}
+}
+
+// CacheStats reports how well the cache is working.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// HitRate is hits over lookups, 0 before the first lookup
+	HitRate float64 `json:"hit_rate"`
+	// Evictions counts entries dropped to stay within MaxEntries
+	Evictions int64 `json:"evictions"`
+	// Expirations counts entries dropped for outliving the TTL
+	Expirations int64 `json:"expirations"`
+	Entries     int   `json:"entries"`
+}
+
+// cacheEntry is one cached embedding.
+type cacheEntry struct {
+	key       string
+	vector    []float32
+	createdAt time.Time
+}
+
+// Cache is an Embedder that reuses the embeddings of texts it has seen,
+// keyed by model and a hash of the text, and computes the rest with the
+// embedder it wraps. It is safe for concurrent use.
+type Cache struct {
+	next   Embedder
+	config CacheConfig
+	// now is the clock entries are stamped and expired with
+	now func() time.Time
+
+	mu sync.Mutex
+	// entries index order, which runs from most to least recently used
+	entries map[string]*list.Element
+	order   *list.List
+	stats   CacheStats
+	dirty   bool
+}
+
+// NewCache wraps an embedder in a cache, loading the cache file if one
+// exists.
+func NewCache(next Embedder, config CacheConfig) (*Cache, error) {
+	c := &Cache{
+		next:    next,
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+	if config.Path != "" {
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CacheKey returns the key of a text's embedding by a model.
+func CacheKey(model, text string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Model returns the wrapped embedder's model.
+func (c *Cache) Model() string {
+	return c.next.Model()
+}
+
+// Embed returns cached embeddings where it can and computes the rest in
+// one call to the wrapped embedder. Repeated texts within a call are
+// embedded once. The vectors are shared with the cache and must not be
+// modified.
+func (c *Cache) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := c.next.Model()
+	vectors := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	// missing maps each uncached key to the positions it fills
+	missing := make(map[string][]int)
+	var misses []string
+
+	c.mu.Lock()
+	for i, text := range texts {
+		keys[i] = CacheKey(model, text)
+		if vector, ok := c.lookup(keys[i]); ok {
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := missing[keys[i]]; !ok {
+			misses = append(misses, text)
+		}
+		missing[keys[i]] = append(missing[keys[i]], i)
+	}
+	c.mu.Unlock()
+
+	if len(misses) == 0 {
+		return vectors, nil
+	}
+	computed, err := c.next.Embed(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	if len(computed) != len(misses) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(computed), len(misses))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, text := range misses {
+		key := CacheKey(model, text)
+		c.store(key, computed[i], c.now())
+		for _, position := range missing[key] {
+			vectors[position] = computed[i]
+		}
+	}
+	return vectors, nil
+}
+
+// lookup returns a live cached vector and counts the hit or miss.
+// Callers hold mu.
+func (c *Cache) lookup(key string) ([]float32, bool) {
+	element, ok := c.entries[key]
+	if ok && c.expired(element.Value.(*cacheEntry)) {
+		c.remove(element)
+		c.stats.Expirations++
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	c.stats.Hits++
+	return element.Value.(*cacheEntry).vector, true
+}
+
+// store adds or replaces an entry and evicts down to MaxEntries. Callers
+// hold mu.
+func (c *Cache) store(key string, vector []float32, createdAt time.Time) {
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector, createdAt: createdAt})
+	c.dirty = true
+	for c.config.MaxEntries > 0 && c.order.Len() > c.config.MaxEntries {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove drops an entry. Callers hold mu.
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+	c.dirty = true
+}
+
+// expired reports whether an entry has outlived the TTL.
+func (c *Cache) expired(entry *cacheEntry) bool {
+	return c.config.TTL > 0 && c.now().Sub(entry.createdAt) > c.config.TTL
+}
+
+// Stats returns the cache's hit rate and size.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// ============================================================================
+// Persistence
+// ============================================================================
+
+// cacheFile is the saved form of a cache.
+type cacheFile struct {
+	Version int `json:"version"`
+	// Entries run from least to most recently used, so loading them in
+	// order restores the eviction order
+	Entries []cacheFileEntry `json:"entries"`
+}
+
+// cacheFileEntry is a saved embedding; the vector is little-endian
+// float32s, base64 encoded by encoding/json.
+type cacheFileEntry struct {
+	Key       string    `json:"key"`
+	Vector    []byte    `json:"vector"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Save writes the cache to its file atomically, leaving out expired
+// entries. It does nothing for in-memory caches or when nothing changed.
+func (c *Cache) Save() error {
+	if c.config.Path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	file := cacheFile{Version: CacheFileVersion, Entries: make([]cacheFileEntry, 0, c.order.Len())}
+	for element := c.order.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*cacheEntry)
+		if c.expired(entry) {
+			continue
+		}
+		file.Entries = append(file.Entries, cacheFileEntry{
+			Key:       entry.key,
+			Vector:    encodeVector(entry.vector),
+			CreatedAt: entry.createdAt,
+		})
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err := writeCacheFile(c.config.Path, &file); err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// load reads the cache file, if it exists, skipping expired entries.
+func (c *Cache) load() error {
+	data, err := os.ReadFile(c.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
+	}
+	if file.Version != CacheFileVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrCacheCorrupt, file.Version)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, saved := range file.Entries {
+		vector, err := decodeVector(saved.Vector)
+		if err != nil {
+			return fmt.Errorf("%w: entry %s: %v", ErrCacheCorrupt, saved.Key, err)
+		}
+		entry := &cacheEntry{key: saved.Key, vector: vector, createdAt: saved.CreatedAt}
+		if c.expired(entry) {
+			continue
+		}
+		c.store(entry.key, entry.vector, entry.createdAt)
+	}
+	c.stats.Evictions = 0
+	c.dirty = false
+	return nil
+}
+
+// writeCacheFile writes a cache file to path atomically.
+func writeCacheFile(path string, file *cacheFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode embedding cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save embedding cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save embedding cache: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save embedding cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save embedding cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save embedding cache: %w", err)
+	}
+	return nil
+}
+
+// encodeVector packs a vector as little-endian float32s.
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// decodeVector unpacks a vector packed by encodeVector.
+func decodeVector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("vector is %d bytes, not a multiple of 4", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector, nil
+}
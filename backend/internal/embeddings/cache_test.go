@@ -0,0 +1,206 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingEmbedder embeds a text as its length and records every text it
+// was asked to embed.
+type countingEmbedder struct {
+	model string
+	err   error
+
+	mu       sync.Mutex
+	embedded []string
+}
+
+func (e *countingEmbedder) Model() string { return e.model }
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.mu.Lock()
+	e.embedded = append(e.embedded, texts...)
+	e.mu.Unlock()
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), 0.5}
+	}
+	return vectors, nil
+}
+
+// calls returns the texts embedded so far.
+func (e *countingEmbedder) calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.embedded...)
+}
+
+func TestCacheEmbed(t *testing.T) {
+	inner := &countingEmbedder{model: "mini"}
+	cache, err := NewCache(inner, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	vectors, err := cache.Embed(context.Background(), []string{"alpha", "be", "alpha"})
+	if err != nil {
+		t.Fatalf("expected embeddings, got %v", err)
+	}
+	if len(vectors) != 3 || vectors[0][0] != 5 || vectors[1][0] != 2 || vectors[2][0] != 5 {
+		t.Errorf("expected vectors in input order, got %v", vectors)
+	}
+	if got := inner.calls(); strings.Join(got, ",") != "alpha,be" {
+		t.Errorf("expected repeated text embedded once, got %v", got)
+	}
+
+	if _, err := cache.Embed(context.Background(), []string{"be", "gamma"}); err != nil {
+		t.Fatalf("expected embeddings, got %v", err)
+	}
+	if got := inner.calls(); strings.Join(got, ",") != "alpha,be,gamma" {
+		t.Errorf("expected only the new text embedded, got %v", got)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Entries != 3 {
+		t.Errorf("expected 1 hit, 4 misses and 3 entries, got %+v", stats)
+	}
+	if stats.HitRate != 0.2 {
+		t.Errorf("expected hit rate 0.2, got %v", stats.HitRate)
+	}
+	if cache.Model() != "mini" {
+		t.Errorf("expected wrapped model, got %s", cache.Model())
+	}
+}
+
+func TestCacheEmbedError(t *testing.T) {
+	inner := &countingEmbedder{model: "mini", err: errors.New("provider down")}
+	cache, _ := NewCache(inner, DefaultCacheConfig())
+	if _, err := cache.Embed(context.Background(), []string{"alpha"}); err == nil {
+		t.Error("expected the embedder's error")
+	}
+	if cache.Stats().Entries != 0 {
+		t.Error("expected nothing cached after an error")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	if CacheKey("mini", "text") == CacheKey("large", "text") {
+		t.Error("expected keys to differ by model")
+	}
+	if CacheKey("mini", "text") != CacheKey("mini", "text") {
+		t.Error("expected keys to be stable")
+	}
+	if CacheKey("a", "bc") == CacheKey("ab", "c") {
+		t.Error("expected model and text to be separated in the key")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	inner := &countingEmbedder{model: "mini"}
+	cache, _ := NewCache(inner, CacheConfig{TTL: time.Hour})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.Embed(context.Background(), []string{"alpha"})
+	now = now.Add(30 * time.Minute)
+	cache.Embed(context.Background(), []string{"alpha"})
+	now = now.Add(time.Hour)
+	cache.Embed(context.Background(), []string{"alpha"})
+
+	if got := len(inner.calls()); got != 2 {
+		t.Errorf("expected alpha embedded again once expired, got %d calls", got)
+	}
+	if stats := cache.Stats(); stats.Expirations != 1 || stats.Hits != 1 {
+		t.Errorf("expected 1 expiration and 1 hit, got %+v", stats)
+	}
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	inner := &countingEmbedder{model: "mini"}
+	cache, _ := NewCache(inner, CacheConfig{MaxEntries: 2})
+
+	cache.Embed(context.Background(), []string{"a", "bb"})
+	cache.Embed(context.Background(), []string{"a"})   // a is now most recent
+	cache.Embed(context.Background(), []string{"ccc"}) // evicts bb
+	cache.Embed(context.Background(), []string{"a", "bb"})
+
+	if got := strings.Join(inner.calls(), ","); got != "a,bb,ccc,bb" {
+		t.Errorf("expected least recently used entry evicted, got %s", got)
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 2 {
+		t.Errorf("expected 2 entries after 2 evictions, got %+v", stats)
+	}
+}
+
+func TestCacheSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embeddings.cache")
+	config := CacheConfig{Path: path, TTL: time.Hour, MaxEntries: 10}
+
+	inner := &countingEmbedder{model: "mini"}
+	cache, err := NewCache(inner, config)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	cache.Embed(context.Background(), []string{"alpha", "be"})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	restarted := &countingEmbedder{model: "mini"}
+	reloaded, err := NewCache(restarted, config)
+	if err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	vectors, err := reloaded.Embed(context.Background(), []string{"alpha", "be"})
+	if err != nil {
+		t.Fatalf("expected embeddings, got %v", err)
+	}
+	if len(restarted.calls()) != 0 {
+		t.Errorf("expected embeddings served from the saved cache, got calls %v", restarted.calls())
+	}
+	if vectors[0][0] != 5 || vectors[0][1] != 0.5 {
+		t.Errorf("expected vectors to round-trip, got %v", vectors[0])
+	}
+
+	// Another model misses the same texts
+	other, _ := NewCache(&countingEmbedder{model: "large"}, config)
+	other.Embed(context.Background(), []string{"alpha"})
+	if other.Stats().Hits != 0 {
+		t.Error("expected no hits for a different model")
+	}
+
+	// Expired entries are not loaded
+	expired, _ := NewCache(&countingEmbedder{model: "mini"}, config)
+	expired.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := expired.load(); err != nil {
+		t.Fatalf("failed to reload cache: %v", err)
+	}
+	expired.Embed(context.Background(), []string{"alpha"})
+	if expired.Stats().Hits != 0 {
+		t.Error("expected expired entries dropped")
+	}
+}
+
+func TestCacheCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embeddings.cache")
+	if err := writeCacheFile(path, &cacheFile{Version: CacheFileVersion, Entries: []cacheFileEntry{{Key: "k", Vector: []byte{1, 2, 3}}}}); err != nil {
+		t.Fatalf("failed to write cache file: %v", err)
+	}
+	if _, err := NewCache(&countingEmbedder{model: "mini"}, CacheConfig{Path: path}); !errors.Is(err, ErrCacheCorrupt) {
+		t.Errorf("expected ErrCacheCorrupt for a truncated vector, got %v", err)
+	}
+	if err := writeCacheFile(path, &cacheFile{Version: 99}); err != nil {
+		t.Fatalf("failed to write cache file: %v", err)
+	}
+	if _, err := NewCache(&countingEmbedder{model: "mini"}, CacheConfig{Path: path}); !errors.Is(err, ErrCacheCorrupt) {
+		t.Errorf("expected ErrCacheCorrupt for an unknown version, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import "context"
+
+// Embedder computes embeddings with one model. Every embedding call site
+// goes through an Embedder, so wrapping one in a Cache caches them all.
+type Embedder interface {
+	// Model names the model; vectors from different models are not
+	// comparable
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
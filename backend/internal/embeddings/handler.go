@@ -0,0 +1,70 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+const (
+	// maxEmbedBodyBytes bounds an embedding request
+	maxEmbedBodyBytes = 1 << 20
+	// maxEmbedTexts bounds the texts in one embedding request
+	maxEmbedTexts = 256
+)
+
+// EmbedRequest is the payload of POST /embeddings.
+type EmbedRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// EmbedResponse holds one embedding per requested text, in order.
+type EmbedResponse struct {
+	Model      string      `json:"model"`
+	Dimensions int         `json:"dimensions"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Handler serves embeddings over HTTP from a cached embedder.
+type Handler struct {
+	cache *Cache
+}
+
+// NewHandler creates a handler for a cached embedder.
+func NewHandler(cache *Cache) *Handler {
+	return &Handler{cache: cache}
+}
+
+// ServeEmbed handles POST /embeddings - embeds up to 256 texts.
+func (h *Handler) ServeEmbed(w http.ResponseWriter, r *http.Request) {
+	var req EmbedRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxEmbedBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if len(req.Texts) == 0 || len(req.Texts) > maxEmbedTexts {
+		errdefs.WriteError(w, "texts must hold between 1 and 256 texts", http.StatusBadRequest)
+		return
+	}
+
+	vectors, err := h.cache.Embed(r.Context(), req.Texts)
+	if err != nil {
+		log.Printf("Embedding %d texts failed: %v", len(req.Texts), err)
+		errdefs.WriteError(w, "Embedding failed", http.StatusInternalServerError)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, EmbedResponse{
+		Model:      h.cache.Model(),
+		Dimensions: len(vectors[0]),
+		Embeddings: vectors,
+	})
+}
+
+// ServeStats handles GET /embeddings/stats - returns the cache's stats.
+func (h *Handler) ServeStats(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, h.cache.Stats())
+}
@@ -0,0 +1,72 @@
+package embeddings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHandler(t *testing.T, inner *countingEmbedder) *Handler {
+	t.Helper()
+	cache, err := NewCache(inner, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	return NewHandler(cache)
+}
+
+func TestHandlerServeEmbed(t *testing.T) {
+	handler := newTestHandler(t, &countingEmbedder{model: "mini"})
+
+	rec := httptest.NewRecorder()
+	handler.ServeEmbed(rec, httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader(`{"texts":["alpha","be"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp EmbedResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Model != "mini" || resp.Dimensions != 2 || len(resp.Embeddings) != 2 || resp.Embeddings[1][0] != 2 {
+		t.Errorf("expected 2 embeddings from mini, got %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeStats(rec, httptest.NewRequest(http.MethodGet, "/embeddings/stats", nil))
+	var stats CacheStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	if stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("expected 2 misses and 2 entries, got %+v", stats)
+	}
+}
+
+func TestHandlerServeEmbedRejectsBadRequests(t *testing.T) {
+	handler := newTestHandler(t, &countingEmbedder{model: "mini"})
+	tooMany := `{"texts":[` + strings.Repeat(`"a",`, maxEmbedTexts) + `"a"]}`
+
+	for _, body := range []string{`not json`, `{"texts":[]}`, tooMany} {
+		rec := httptest.NewRecorder()
+		handler.ServeEmbed(rec, httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %.20q, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestHandlerServeEmbedFailure(t *testing.T) {
+	handler := newTestHandler(t, &countingEmbedder{model: "mini", err: errors.New("model crashed")})
+
+	rec := httptest.NewRecorder()
+	handler.ServeEmbed(rec, httptest.NewRequest(http.MethodPost, "/embeddings", strings.NewReader(`{"texts":["alpha"]}`)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "model crashed") {
+		t.Error("expected embedder errors kept out of the response")
+	}
+}
@@ -0,0 +1,127 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrONNXUnavailable is returned when the server was built without ONNX
+// Runtime support.
+var ErrONNXUnavailable = errdefs.New(errdefs.ErrUnavailable, "built without ONNX Runtime support; rebuild with -tags onnx")
+
+// ONNXConfig configures an in-process embedding model run by ONNX
+// Runtime, such as all-MiniLM-L6-v2 exported to ONNX.
+type ONNXConfig struct {
+	// ModelPath is the .onnx file
+	ModelPath string
+	// VocabPath is the WordPiece vocab.txt; defaults to vocab.txt beside
+	// the model
+	VocabPath string
+	// LibraryPath is the ONNX Runtime shared library; empty uses the
+	// platform's default library name
+	LibraryPath string
+	// Model names the model in cache keys; defaults to the name of the
+	// model's directory
+	Model string
+	// CaseSensitive keeps case and accents, for cased models
+	CaseSensitive bool
+	// MaxSequenceLength truncates long texts, in tokens
+	MaxSequenceLength int
+	// BatchSize is how many texts are run through the model at once
+	BatchSize int
+	// Threads bounds the threads one inference uses; zero lets ONNX
+	// Runtime decide
+	Threads int
+}
+
+// withDefaults fills in unset fields.
+func (c ONNXConfig) withDefaults() ONNXConfig {
+	if c.VocabPath == "" {
+		c.VocabPath = filepath.Join(filepath.Dir(c.ModelPath), "vocab.txt")
+	}
+	if c.Model == "" {
+		c.Model = filepath.Base(filepath.Dir(c.ModelPath))
+	}
+	if c.MaxSequenceLength <= 0 {
+		c.MaxSequenceLength = 256
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 32
+	}
+	return c
+}
+
+// batch is a padded batch of encoded texts, laid out row by row as the
+// model's input tensors.
+type batch struct {
+	size, length int
+	ids          []int64
+	mask         []int64
+	types        []int64
+}
+
+// newBatch encodes texts and pads them to the longest.
+func newBatch(tokenizer *Tokenizer, texts []string, maxLength int) *batch {
+	encoded := make([][]int64, len(texts))
+	b := &batch{size: len(texts)}
+	for i, text := range texts {
+		encoded[i] = tokenizer.Encode(text, maxLength)
+		b.length = max(b.length, len(encoded[i]))
+	}
+	b.ids = make([]int64, b.size*b.length)
+	b.mask = make([]int64, b.size*b.length)
+	b.types = make([]int64, b.size*b.length)
+	for i, ids := range encoded {
+		copy(b.ids[i*b.length:], ids)
+		for j := range ids {
+			b.mask[i*b.length+j] = 1
+		}
+	}
+	return b
+}
+
+// meanPool averages each row's token states over its unmasked tokens and
+// normalizes the result, turning [size, length, dims] model output into
+// one unit vector per text.
+func (b *batch) meanPool(states []float32, dims int) [][]float32 {
+	vectors := make([][]float32, b.size)
+	for i := range vectors {
+		vector := make([]float32, dims)
+		var count float32
+		for j := 0; j < b.length; j++ {
+			if b.mask[i*b.length+j] == 0 {
+				continue
+			}
+			count++
+			token := states[(i*b.length+j)*dims:]
+			for k := range vector {
+				vector[k] += token[k]
+			}
+		}
+		for k := range vector {
+			vector[k] /= count
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors
+}
+
+// normalize scales a vector to unit length in place, so cosine similarity
+// is a dot product.
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
@@ -0,0 +1,188 @@
+//go:build onnx
+
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ortInit initializes the process-wide ONNX Runtime environment once.
+var ortInit struct {
+	sync.Mutex
+	done bool
+}
+
+// ONNXEmbedder runs an embedding model in process with ONNX Runtime, so
+// no text leaves the server. Texts are embedded in batches and the token
+// states mean-pooled into unit vectors, as sentence-transformers does.
+type ONNXEmbedder struct {
+	config    ONNXConfig
+	tokenizer *Tokenizer
+	// tokenTypes is set for models that take a token_type_ids input
+	tokenTypes bool
+	// pooled is set for models whose output is already one vector per text
+	pooled bool
+
+	// mu serializes inference; each run already uses Threads threads
+	mu      sync.Mutex
+	session *ort.DynamicAdvancedSession
+}
+
+// NewONNXEmbedder loads a model and its vocabulary. The model must take
+// input_ids and attention_mask and output token states or pooled vectors
+// as its first output.
+func NewONNXEmbedder(config ONNXConfig) (*ONNXEmbedder, error) {
+	config = config.withDefaults()
+	tokenizer, err := LoadTokenizer(config.VocabPath, !config.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+	if err := initializeRuntime(config.LibraryPath); err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, err := ort.GetInputOutputInfo(config.ModelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect embedding model: %w", err)
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("embedding model %s has no outputs", config.ModelPath)
+	}
+	e := &ONNXEmbedder{
+		config:    config,
+		tokenizer: tokenizer,
+		pooled:    len(outputs[0].Dimensions) == 2,
+	}
+	inputNames := []string{"input_ids", "attention_mask"}
+	for _, input := range inputs {
+		if input.Name == "token_type_ids" {
+			e.tokenTypes = true
+			inputNames = append(inputNames, input.Name)
+		}
+	}
+
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session options: %w", err)
+	}
+	defer options.Destroy()
+	if config.Threads > 0 {
+		if err := options.SetIntraOpNumThreads(config.Threads); err != nil {
+			return nil, fmt.Errorf("failed to set inference threads: %w", err)
+		}
+	}
+	e.session, err = ort.NewDynamicAdvancedSession(config.ModelPath, inputNames, []string{outputs[0].Name}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedding model: %w", err)
+	}
+	return e, nil
+}
+
+// initializeRuntime loads the ONNX Runtime library the first time a model
+// is loaded.
+func initializeRuntime(libraryPath string) error {
+	ortInit.Lock()
+	defer ortInit.Unlock()
+	if ortInit.done {
+		return nil
+	}
+	if libraryPath != "" {
+		ort.SetSharedLibraryPath(libraryPath)
+	}
+	if err := ort.InitializeEnvironment(); err != nil {
+		return fmt.Errorf("failed to initialize ONNX Runtime: %w", err)
+	}
+	ortInit.done = true
+	return nil
+}
+
+// Model returns the configured model name.
+func (e *ONNXEmbedder) Model() string {
+	return e.config.Model
+}
+
+// Embed embeds texts BatchSize at a time, stopping between batches if ctx
+// is done.
+func (e *ONNXEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.config.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+e.config.BatchSize, len(texts))
+		batchVectors, err := e.run(newBatch(e.tokenizer, texts[start:end], e.config.MaxSequenceLength))
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+	return vectors, nil
+}
+
+// run runs one batch through the model.
+func (e *ONNXEmbedder) run(b *batch) ([][]float32, error) {
+	shape := ort.NewShape(int64(b.size), int64(b.length))
+	values := []*[]int64{&b.ids, &b.mask}
+	if e.tokenTypes {
+		values = append(values, &b.types)
+	}
+	inputs := make([]ort.Value, 0, len(values))
+	defer func() {
+		for _, input := range inputs {
+			input.Destroy()
+		}
+	}()
+	for _, data := range values {
+		tensor, err := ort.NewTensor(shape, *data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create input tensor: %w", err)
+		}
+		inputs = append(inputs, tensor)
+	}
+
+	outputs := []ort.Value{nil}
+	e.mu.Lock()
+	err := e.session.Run(inputs, outputs)
+	e.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("embedding inference failed: %w", err)
+	}
+	defer outputs[0].Destroy()
+
+	output, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, fmt.Errorf("embedding model output is not float32")
+	}
+	dims := output.GetShape()
+	if e.pooled {
+		data := output.GetData()
+		width := int(dims[1])
+		vectors := make([][]float32, b.size)
+		for i := range vectors {
+			vectors[i] = normalize(append([]float32(nil), data[i*width:(i+1)*width]...))
+		}
+		return vectors, nil
+	}
+	return b.meanPool(output.GetData(), int(dims[2])), nil
+}
+
+// Warmup runs a small batch so the first real request doesn't pay for
+// lazy allocation in the runtime.
+func (e *ONNXEmbedder) Warmup(ctx context.Context) error {
+	texts := make([]string, min(e.config.BatchSize, 4))
+	for i := range texts {
+		texts[i] = "warm up the embedding model"
+	}
+	_, err := e.Embed(ctx, texts)
+	return err
+}
+
+// Close releases the model's session.
+func (e *ONNXEmbedder) Close() error {
+	return e.session.Destroy()
+}
@@ -0,0 +1,54 @@
+//go:build onnx
+
+package embeddings
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+)
+
+// TestONNXEmbedder runs a real model. It needs ONNX Runtime and a
+// sentence-transformers model exported to ONNX, such as all-MiniLM-L6-v2:
+//
+//	ONNXRUNTIME_LIB=/usr/lib/libonnxruntime.so \
+//	EMBEDDINGS_MODEL_PATH=/models/all-MiniLM-L6-v2/model.onnx \
+//	go test -tags onnx ./internal/embeddings/
+func TestONNXEmbedder(t *testing.T) {
+	modelPath := os.Getenv("EMBEDDINGS_MODEL_PATH")
+	if modelPath == "" {
+		t.Skip("EMBEDDINGS_MODEL_PATH not set")
+	}
+	embedder, err := NewONNXEmbedder(ONNXConfig{
+		ModelPath:   modelPath,
+		LibraryPath: os.Getenv("ONNXRUNTIME_LIB"),
+		BatchSize:   2,
+	})
+	if err != nil {
+		t.Fatalf("failed to load model: %v", err)
+	}
+	defer embedder.Close()
+
+	if err := embedder.Warmup(context.Background()); err != nil {
+		t.Fatalf("warmup failed: %v", err)
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"How do I rotate a leaked API key?",
+		"Steps to revoke and reissue a compromised credential",
+		"The best sourdough needs a long cold proof",
+	})
+	if err != nil {
+		t.Fatalf("embedding failed: %v", err)
+	}
+	if len(vectors) != 3 {
+		t.Fatalf("expected 3 vectors across batches, got %d", len(vectors))
+	}
+
+	if norm := dot(vectors[0], vectors[0]); math.Abs(norm-1) > 1e-4 {
+		t.Errorf("expected unit vectors, got squared norm %v", norm)
+	}
+	if dot(vectors[0], vectors[1]) <= dot(vectors[0], vectors[2]) {
+		t.Error("expected related texts to be closer than unrelated ones")
+	}
+}
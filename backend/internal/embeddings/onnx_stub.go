@@ -0,0 +1,30 @@
+//go:build !onnx
+
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import "context"
+
+// ONNXEmbedder runs an embedding model in process. This build has no ONNX
+// Runtime support; see onnx_runtime.go.
+type ONNXEmbedder struct{}
+
+// NewONNXEmbedder returns ErrONNXUnavailable in builds without the onnx
+// tag.
+func NewONNXEmbedder(config ONNXConfig) (*ONNXEmbedder, error) {
+	return nil, ErrONNXUnavailable
+}
+
+// Model returns the model name.
+func (e *ONNXEmbedder) Model() string { return "" }
+
+// Embed returns ErrONNXUnavailable.
+func (e *ONNXEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrONNXUnavailable
+}
+
+// Warmup returns ErrONNXUnavailable.
+func (e *ONNXEmbedder) Warmup(ctx context.Context) error { return ErrONNXUnavailable }
+
+// Close does nothing.
+func (e *ONNXEmbedder) Close() error { return nil }
@@ -0,0 +1,19 @@
+//go:build !onnx
+
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewONNXEmbedderUnavailable(t *testing.T) {
+	if _, err := NewONNXEmbedder(ONNXConfig{ModelPath: "model.onnx"}); !errors.Is(err, ErrONNXUnavailable) {
+		t.Errorf("expected ErrONNXUnavailable without the onnx tag, got %v", err)
+	}
+	var e ONNXEmbedder
+	if _, err := e.Embed(context.Background(), []string{"text"}); !errors.Is(err, ErrONNXUnavailable) {
+		t.Errorf("expected ErrONNXUnavailable from Embed, got %v", err)
+	}
+}
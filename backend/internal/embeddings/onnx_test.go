@@ -0,0 +1,59 @@
+package embeddings
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestONNXConfigDefaults(t *testing.T) {
+	config := ONNXConfig{ModelPath: "/models/all-MiniLM-L6-v2/model.onnx"}.withDefaults()
+	if config.VocabPath != "/models/all-MiniLM-L6-v2/vocab.txt" {
+		t.Errorf("expected vocabulary beside the model, got %s", config.VocabPath)
+	}
+	if config.Model != "all-MiniLM-L6-v2" {
+		t.Errorf("expected model named after its directory, got %s", config.Model)
+	}
+	if config.MaxSequenceLength != 256 || config.BatchSize != 32 {
+		t.Errorf("expected default sequence length and batch size, got %d and %d", config.MaxSequenceLength, config.BatchSize)
+	}
+}
+
+func TestNewBatch(t *testing.T) {
+	tokenizer := newTestTokenizer(t, true)
+	b := newBatch(tokenizer, []string{"the unaffable", "the"}, 256)
+
+	if b.size != 2 || b.length != 6 {
+		t.Fatalf("expected 2 rows padded to 6 tokens, got %d rows of %d", b.size, b.length)
+	}
+	if want := []int64{2, 4, 6, 7, 8, 3, 2, 4, 3, 0, 0, 0}; !reflect.DeepEqual(b.ids, want) {
+		t.Errorf("expected padded IDs %v, got %v", want, b.ids)
+	}
+	if want := []int64{1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0}; !reflect.DeepEqual(b.mask, want) {
+		t.Errorf("expected attention mask %v, got %v", want, b.mask)
+	}
+	if len(b.types) != 12 {
+		t.Errorf("expected zero token types for every position, got %d", len(b.types))
+	}
+}
+
+func TestMeanPool(t *testing.T) {
+	b := &batch{size: 2, length: 2, mask: []int64{1, 1, 1, 0}}
+	// Two dims per token; the padded token's states must be ignored
+	states := []float32{
+		3, 0, 1, 4, // row 0: mean (2, 2)
+		0, 5, 9, 9, // row 1: only (0, 5) counts
+	}
+	vectors := b.meanPool(states, 2)
+
+	s := float32(1 / math.Sqrt(2))
+	if math.Abs(float64(vectors[0][0]-s)) > 1e-6 || math.Abs(float64(vectors[0][1]-s)) > 1e-6 {
+		t.Errorf("expected normalized mean of row 0, got %v", vectors[0])
+	}
+	if vectors[1][0] != 0 || vectors[1][1] != 1 {
+		t.Errorf("expected padding ignored in row 1, got %v", vectors[1])
+	}
+	if zero := normalize([]float32{0, 0}); zero[0] != 0 || zero[1] != 0 {
+		t.Errorf("expected zero vector left alone, got %v", zero)
+	}
+}
@@ -0,0 +1,79 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// DefaultStubDimensions matches all-MiniLM, so stub vectors fit wherever
+// the local model's would.
+const DefaultStubDimensions = 384
+
+// StubEmbedder embeds text without a model, for offline mode and tests.
+// Each lowercased word and each of its character trigrams is hashed to a
+// dimension and a sign, so the same text always gets the same vector and
+// texts that share words get similar ones.
+type StubEmbedder struct {
+	dims int
+}
+
+// NewStubEmbedder creates a stub embedder with vectors of dims dimensions;
+// zero or less uses DefaultStubDimensions.
+func NewStubEmbedder(dims int) *StubEmbedder {
+	if dims <= 0 {
+		dims = DefaultStubDimensions
+	}
+	return &StubEmbedder{dims: dims}
+}
+
+// Model names the stub by its dimensions, so cached stub vectors are never
+// mistaken for a real model's.
+func (e *StubEmbedder) Model() string {
+	return fmt.Sprintf("stub-hash-%d", e.dims)
+}
+
+// Embed returns a unit vector per text; texts without words get the zero
+// vector.
+func (e *StubEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed hashes one text's features into a vector.
+func (e *StubEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		// Whole words carry more weight than their trigrams
+		e.add(vector, word, 2)
+		padded := []rune("^" + word + "$")
+		for j := 0; j+3 <= len(padded); j++ {
+			e.add(vector, string(padded[j:j+3]), 1)
+		}
+	}
+	return normalize(vector)
+}
+
+// add hashes a feature to a dimension and adds its weight there, with the
+// hash's top bit as the sign.
+func (e *StubEmbedder) add(vector []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(e.dims)] += weight
+}
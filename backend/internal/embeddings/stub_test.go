@@ -0,0 +1,65 @@
+package embeddings
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// dot returns the dot product of two vectors, their cosine similarity
+// when both are unit vectors.
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func TestStubEmbedderIsDeterministic(t *testing.T) {
+	first, err := NewStubEmbedder(0).Embed(context.Background(), []string{"Rotate the leaked API key"})
+	if err != nil {
+		t.Fatalf("expected embeddings, got %v", err)
+	}
+	second, _ := NewStubEmbedder(0).Embed(context.Background(), []string{"Rotate the leaked API key"})
+	if !reflect.DeepEqual(first, second) {
+		t.Error("expected the same vector from separate embedders")
+	}
+	if len(first[0]) != DefaultStubDimensions {
+		t.Errorf("expected %d dimensions, got %d", DefaultStubDimensions, len(first[0]))
+	}
+	if norm := dot(first[0], first[0]); math.Abs(norm-1) > 1e-5 {
+		t.Errorf("expected a unit vector, got squared norm %v", norm)
+	}
+}
+
+func TestStubEmbedderSimilarity(t *testing.T) {
+	e := NewStubEmbedder(64)
+	if e.Model() != "stub-hash-64" {
+		t.Errorf("expected model stub-hash-64, got %s", e.Model())
+	}
+	vectors, err := e.Embed(context.Background(), []string{
+		"rotate the leaked api key",
+		"Rotating a leaked API key!",
+		"sourdough needs a long cold proof",
+		"  ... ",
+	})
+	if err != nil {
+		t.Fatalf("expected embeddings, got %v", err)
+	}
+	if dot(vectors[0], vectors[1]) <= dot(vectors[0], vectors[2]) {
+		t.Error("expected texts sharing words to be closer than unrelated ones")
+	}
+	if dot(vectors[3], vectors[3]) != 0 {
+		t.Errorf("expected the zero vector for a text without words, got %v", vectors[3])
+	}
+}
+
+func TestStubEmbedderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewStubEmbedder(0).Embed(ctx, []string{"text"}); err == nil {
+		t.Error("expected an error for a canceled context")
+	}
+}
@@ -0,0 +1,171 @@
+// Package embeddings turns text into vectors for semantic search.
+package embeddings
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxWordRunes is the longest word split into pieces; longer words are
+// unknown, as in BERT.
+const maxWordRunes = 100
+
+// Special tokens of BERT-style vocabularies.
+const (
+	tokenClassify = "[CLS]"
+	tokenSeparate = "[SEP]"
+	tokenUnknown  = "[UNK]"
+)
+
+// Tokenizer splits text into the WordPiece token IDs BERT-style models
+// such as all-MiniLM take, matching the reference BERT tokenizer.
+type Tokenizer struct {
+	vocab map[string]int64
+	// lowercase also strips accents, for uncased models
+	lowercase bool
+	cls       int64
+	sep       int64
+	unk       int64
+}
+
+// LoadTokenizer reads a vocab.txt with one token per line, the line
+// number being the token's ID.
+func LoadTokenizer(path string, lowercase bool) (*Tokenizer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vocabulary: %w", err)
+	}
+	defer file.Close()
+
+	vocab := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	for id := int64(0); scanner.Scan(); id++ {
+		vocab[strings.TrimRight(scanner.Text(), "\r")] = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	return NewTokenizer(vocab, lowercase)
+}
+
+// NewTokenizer creates a tokenizer for a vocabulary, which must contain
+// the [CLS], [SEP] and [UNK] tokens.
+func NewTokenizer(vocab map[string]int64, lowercase bool) (*Tokenizer, error) {
+	t := &Tokenizer{vocab: vocab, lowercase: lowercase}
+	for token, id := range map[string]*int64{tokenClassify: &t.cls, tokenSeparate: &t.sep, tokenUnknown: &t.unk} {
+		value, ok := vocab[token]
+		if !ok {
+			return nil, fmt.Errorf("vocabulary has no %s token", token)
+		}
+		*id = value
+	}
+	return t, nil
+}
+
+// Encode returns the token IDs of a text between [CLS] and [SEP],
+// truncated to maxLength IDs in all.
+func (t *Tokenizer) Encode(text string, maxLength int) []int64 {
+	ids := []int64{t.cls}
+	for _, word := range t.words(text) {
+		ids = append(ids, t.pieces(word)...)
+	}
+	if maxLength > 1 && len(ids) > maxLength-1 {
+		ids = ids[:maxLength-1]
+	}
+	return append(ids, t.sep)
+}
+
+// words splits text as BERT's basic tokenizer does: on whitespace, around
+// punctuation and around CJK characters, dropping control characters.
+func (t *Tokenizer) words(text string) []string {
+	if t.lowercase {
+		// Decompose, then drop the combining marks, which strips accents
+		var stripped strings.Builder
+		for _, r := range norm.NFD.String(strings.ToLower(text)) {
+			if !unicode.Is(unicode.Mn, r) {
+				stripped.WriteRune(r)
+			}
+		}
+		text = stripped.String()
+	}
+
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r == 0 || r == unicode.ReplacementChar || (unicode.IsControl(r) && !unicode.IsSpace(r)):
+		case unicode.IsSpace(r):
+			flush()
+		case isPunctuation(r) || isCJK(r):
+			flush()
+			words = append(words, string(r))
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// pieces splits a word into the longest vocabulary pieces from the left,
+// marking continuations with ##. Words that can't be split are unknown.
+func (t *Tokenizer) pieces(word string) []int64 {
+	runes := []rune(word)
+	if len(runes) > maxWordRunes {
+		return []int64{t.unk}
+	}
+	var ids []int64
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		var id int64
+		found := false
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = "##" + piece
+			}
+			if id, found = t.vocab[piece]; found {
+				break
+			}
+		}
+		if !found {
+			return []int64{t.unk}
+		}
+		ids = append(ids, id)
+		start = end
+	}
+	return ids
+}
+
+// isPunctuation reports whether BERT splits on a rune: all ASCII symbols
+// and Unicode punctuation.
+func isPunctuation(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
+
+// isCJK reports whether a rune is in the CJK ideograph blocks, which BERT
+// treats as words of their own.
+func isCJK(r rune) bool {
+	return (r >= 0x4E00 && r <= 0x9FFF) ||
+		(r >= 0x3400 && r <= 0x4DBF) ||
+		(r >= 0x20000 && r <= 0x2A6DF) ||
+		(r >= 0x2A700 && r <= 0x2B73F) ||
+		(r >= 0x2B740 && r <= 0x2B81F) ||
+		(r >= 0x2B820 && r <= 0x2CEAF) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0x2F800 && r <= 0x2FA1F)
+}
@@ -0,0 +1,72 @@
+package embeddings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testVocab is a tiny BERT vocabulary; IDs are line numbers.
+var testVocab = []string{"[PAD]", "[UNK]", "[CLS]", "[SEP]", "the", "cafe", "un", "##aff", "##able", ",", "!", "中", "go", "##pher", "Go"}
+
+// newTestTokenizer writes testVocab to a vocab.txt and loads it.
+func newTestTokenizer(t *testing.T, lowercase bool) *Tokenizer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vocab.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(testVocab, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write vocabulary: %v", err)
+	}
+	tokenizer, err := LoadTokenizer(path, lowercase)
+	if err != nil {
+		t.Fatalf("failed to load tokenizer: %v", err)
+	}
+	return tokenizer
+}
+
+func TestTokenizerEncode(t *testing.T) {
+	tokenizer := newTestTokenizer(t, true)
+
+	tests := []struct {
+		text string
+		want []int64
+	}{
+		{"the unaffable gopher", []int64{2, 4, 6, 7, 8, 12, 13, 3}},
+		{"The  Café,\tthe!", []int64{2, 4, 5, 9, 4, 10, 3}},
+		{"unknownword 中", []int64{2, 1, 11, 3}},
+		{"", []int64{2, 3}},
+		{"\x00�", []int64{2, 3}},
+	}
+	for _, tt := range tests {
+		if got := tokenizer.Encode(tt.text, 512); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.text, tt.want, got)
+		}
+	}
+
+	if got := tokenizer.Encode("the the the the", 4); !reflect.DeepEqual(got, []int64{2, 4, 4, 3}) {
+		t.Errorf("expected truncation to 4 IDs ending in [SEP], got %v", got)
+	}
+	if got := tokenizer.Encode(strings.Repeat("a", maxWordRunes+1), 512); !reflect.DeepEqual(got, []int64{2, 1, 3}) {
+		t.Errorf("expected overlong word to be unknown, got %v", got)
+	}
+}
+
+func TestTokenizerCaseSensitive(t *testing.T) {
+	tokenizer := newTestTokenizer(t, false)
+	if got := tokenizer.Encode("Go go", 512); !reflect.DeepEqual(got, []int64{2, 14, 12, 3}) {
+		t.Errorf("expected case kept, got %v", got)
+	}
+	if got := tokenizer.Encode("café", 512); !reflect.DeepEqual(got, []int64{2, 1, 3}) {
+		t.Errorf("expected accents kept, got %v", got)
+	}
+}
+
+func TestNewTokenizerMissingSpecialTokens(t *testing.T) {
+	if _, err := NewTokenizer(map[string]int64{"[CLS]": 0, "[SEP]": 1}, true); err == nil {
+		t.Error("expected error for a vocabulary without [UNK]")
+	}
+	if _, err := LoadTokenizer(filepath.Join(t.TempDir(), "missing.txt"), true); err == nil {
+		t.Error("expected error for a missing vocabulary")
+	}
+}
@@ -0,0 +1,162 @@
+// Package errdefs defines the kinds of error the server tells apart, so
+// handlers map any package's errors to HTTP statuses and gRPC codes in one
+// place.
+//
+// Packages declare their sentinel errors with New, giving each a kind:
+//
+//	var ErrGoalNotFound = errdefs.New(errdefs.ErrNotFound, "goal not found")
+//
+// errors.Is then matches both the sentinel and its kind, through any
+// fmt.Errorf("%w") wrapping.
+package errdefs
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Error kinds.
+var (
+	// ErrNotFound is for lookups of things that don't exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidArgument is for requests that can never succeed as given
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrConflict is for requests at odds with the current state, such as
+	// creating something that exists
+	ErrConflict = errors.New("conflict")
+	// ErrCapacityExceeded is for quotas and capacity limits; retrying later
+	// may succeed
+	ErrCapacityExceeded = errors.New("capacity exceeded")
+	// ErrUnauthorized is for callers without valid credentials
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is for authenticated callers not allowed the request
+	ErrForbidden = errors.New("forbidden")
+	// ErrUnavailable is for features that are disabled or not ready yet
+	ErrUnavailable = errors.New("unavailable")
+	// ErrProviderFailure is for failures of upstream services such as
+	// issue trackers, model providers and the Kubernetes API
+	ErrProviderFailure = errors.New("provider failure")
+	// ErrInternal is for corrupt data and failed persistence
+	ErrInternal = errors.New("internal error")
+)
+
+// kinds lists the error kinds in the order Kind checks them.
+var kinds = []error{
+	ErrNotFound, ErrInvalidArgument, ErrConflict, ErrCapacityExceeded,
+	ErrUnauthorized, ErrForbidden, ErrUnavailable, ErrProviderFailure, ErrInternal,
+}
+
+// kindError is a sentinel error of a kind.
+type kindError struct {
+	message string
+	kind    error
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// New returns a sentinel error with message that errors.Is matches to
+// kind. The kind isn't part of the message.
+func New(kind error, message string) error {
+	return &kindError{message: message, kind: kind}
+}
+
+// Kind returns the kind of err, or nil if it has none.
+func Kind(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// HTTPStatus returns the HTTP status answering err. Errors without a kind
+// are 500 Internal Server Error.
+func HTTPStatus(err error) int {
+	switch Kind(err) {
+	case ErrNotFound:
+		return http.StatusNotFound
+	case ErrInvalidArgument:
+		return http.StatusBadRequest
+	case ErrConflict:
+		return http.StatusConflict
+	case ErrCapacityExceeded:
+		return http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return http.StatusUnauthorized
+	case ErrForbidden:
+		return http.StatusForbidden
+	case ErrUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrProviderFailure:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteJSON writes v as a JSON response with status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// WriteError writes a JSON error response, {"error": message}.
+func WriteError(w http.ResponseWriter, message string, status int) {
+	WriteJSON(w, status, map[string]string{"error": message})
+}
+
+// WriteHTTP writes err as a JSON error response with the status its kind
+// maps to.
+func WriteHTTP(w http.ResponseWriter, err error) {
+	WriteError(w, err.Error(), HTTPStatus(err))
+}
+
+// Code is a gRPC status code. The values are those of
+// google.golang.org/grpc/codes, so a gRPC server converts with codes.Code(c).
+type Code uint32
+
+// gRPC status codes errors map to.
+const (
+	CodeUnknown           Code = 2
+	CodeInvalidArgument   Code = 3
+	CodeNotFound          Code = 5
+	CodeAlreadyExists     Code = 6
+	CodePermissionDenied  Code = 7
+	CodeResourceExhausted Code = 8
+	CodeInternal          Code = 13
+	CodeUnavailable       Code = 14
+	CodeUnauthenticated   Code = 16
+)
+
+// GRPCCode returns the gRPC status code answering err. Errors without a
+// kind are Unknown.
+func GRPCCode(err error) Code {
+	switch Kind(err) {
+	case ErrNotFound:
+		return CodeNotFound
+	case ErrInvalidArgument:
+		return CodeInvalidArgument
+	case ErrConflict:
+		return CodeAlreadyExists
+	case ErrCapacityExceeded:
+		return CodeResourceExhausted
+	case ErrUnauthorized:
+		return CodeUnauthenticated
+	case ErrForbidden:
+		return CodePermissionDenied
+	case ErrUnavailable, ErrProviderFailure:
+		return CodeUnavailable
+	case ErrInternal:
+		return CodeInternal
+	default:
+		return CodeUnknown
+	}
+}
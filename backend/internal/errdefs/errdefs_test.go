@@ -0,0 +1,82 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	errGoalNotFound := New(ErrNotFound, "goal not found")
+	err := fmt.Errorf("%w: g-1", errGoalNotFound)
+
+	if err.Error() != "goal not found: g-1" {
+		t.Errorf("Expected the kind kept out of the message, got %q", err.Error())
+	}
+	if !errors.Is(err, errGoalNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Error("Expected errors.Is to match the sentinel and its kind")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("Expected errors.Is not to match another kind")
+	}
+	if errors.Is(New(ErrNotFound, "goal not found"), errGoalNotFound) {
+		t.Error("Expected sentinels with the same message to stay distinct")
+	}
+}
+
+func TestKind(t *testing.T) {
+	if kind := Kind(fmt.Errorf("wrapped: %w", New(ErrProviderFailure, "tracker down"))); kind != ErrProviderFailure {
+		t.Errorf("Expected ErrProviderFailure, got %v", kind)
+	}
+	if kind := Kind(errors.New("plain")); kind != nil {
+		t.Errorf("Expected no kind for a plain error, got %v", kind)
+	}
+	if kind := Kind(nil); kind != nil {
+		t.Errorf("Expected no kind for nil, got %v", kind)
+	}
+}
+
+func TestHTTPStatusAndGRPCCode(t *testing.T) {
+	tests := []struct {
+		kind   error
+		status int
+		code   Code
+	}{
+		{ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{ErrInvalidArgument, http.StatusBadRequest, CodeInvalidArgument},
+		{ErrConflict, http.StatusConflict, CodeAlreadyExists},
+		{ErrCapacityExceeded, http.StatusTooManyRequests, CodeResourceExhausted},
+		{ErrUnauthorized, http.StatusUnauthorized, CodeUnauthenticated},
+		{ErrForbidden, http.StatusForbidden, CodePermissionDenied},
+		{ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable},
+		{ErrProviderFailure, http.StatusBadGateway, CodeUnavailable},
+		{ErrInternal, http.StatusInternalServerError, CodeInternal},
+		{errors.New("plain"), http.StatusInternalServerError, CodeUnknown},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("context: %w", New(tt.kind, "sentinel"))
+		if status := HTTPStatus(err); status != tt.status {
+			t.Errorf("Expected status %d for %v, got %d", tt.status, tt.kind, status)
+		}
+		if code := GRPCCode(err); code != tt.code {
+			t.Errorf("Expected code %d for %v, got %d", tt.code, tt.kind, code)
+		}
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteHTTP(rec, fmt.Errorf("%w: g-1", New(ErrNotFound, "goal not found")))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON response, got %q", ct)
+	}
+	if body := rec.Body.String(); body != `{"error":"goal not found: g-1"}`+"\n" {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
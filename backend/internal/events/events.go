@@ -0,0 +1,263 @@
+// Package events streams what the collective is doing as it happens -
+// impasses detected, items gaining and losing focus, breakthroughs - to
+// WebSocket clients such as a live dashboard.
+//
+// Memory subsystems report to a Hub from their callbacks, which run under
+// the subsystems' locks, so publishing never blocks: each subscriber has a
+// buffer, and events it has fallen too far behind for are dropped and
+// counted rather than held.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by
+// before further ones are dropped for it.
+const subscriberBuffer = 256
+
+// Type identifies an event.
+type Type string
+
+const (
+	// ImpasseDetected is an impasse: agents disagreeing, failing or stuck
+	ImpasseDetected Type = "impasse_detected"
+	// FocusGained is an item entering the focus of attention
+	FocusGained Type = "focus_gained"
+	// FocusLost is an item leaving the focus of attention
+	FocusLost Type = "focus_lost"
+	// Breakthrough is a combination of agents succeeding surprisingly
+	Breakthrough Type = "breakthrough"
+	// Heartbeat is sent to every stream periodically, so dead connections
+	// are noticed
+	Heartbeat Type = "heartbeat"
+)
+
+// Types are the event types clients can subscribe to.
+var Types = []Type{ImpasseDetected, FocusGained, FocusLost, Breakthrough}
+
+// Event is one thing that happened in the collective.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// Data describes the event, shaped by its type
+	Data interface{} `json:"data,omitempty"`
+	// Dropped is how many events were dropped for the subscriber, which
+	// had fallen behind, since the last one it was sent
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// ImpasseData describes an impasse.
+type ImpasseData struct {
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"`
+	GoalID      string   `json:"goal_id,omitempty"`
+	Description string   `json:"description"`
+	Candidates  []string `json:"candidates,omitempty"`
+	FailedAgent string   `json:"failed_agent,omitempty"`
+}
+
+// FocusData describes an item gaining or losing focus.
+type FocusData struct {
+	ID       string  `json:"id"`
+	Kind     string  `json:"kind"`
+	Label    string  `json:"label"`
+	Salience float64 `json:"salience"`
+	Priority float64 `json:"priority"`
+	// Reason is why the item lost focus, e.g. evicted or decayed
+	Reason string `json:"reason,omitempty"`
+}
+
+// BreakthroughData describes a breakthrough.
+type BreakthroughData struct {
+	Agents   []string `json:"agents"`
+	TaskType string   `json:"task_type"`
+	Score    float64  `json:"score"`
+	Strategy string   `json:"strategy,omitempty"`
+}
+
+// Sources are the memory subsystems whose events a hub streams; nil ones
+// are skipped.
+type Sources struct {
+	Attention *memory.AttentionController
+	Insights  *memory.EmergentInsightDetector
+}
+
+// Hub fans events out to subscribers. It is safe for concurrent use.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	now         func() time.Time
+	// heartbeat is how often streams are sent a heartbeat
+	heartbeat time.Duration
+}
+
+// NewHub creates a hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{}), now: time.Now, heartbeat: 30 * time.Second}
+}
+
+// Watch sets the callbacks of sources to publish their events. Callbacks
+// the sources already had are replaced. Impasse detectors usually have a
+// callback of their own, which should call PublishImpasse. Set before the
+// sources are shared between goroutines.
+func (h *Hub) Watch(sources Sources) {
+	if sources.Attention != nil {
+		sources.Attention.OnFocusGained(h.PublishFocusGained)
+		sources.Attention.OnFocusLost(h.PublishFocusLost)
+	}
+	if sources.Insights != nil {
+		sources.Insights.OnBreakthrough(h.PublishBreakthrough)
+	}
+}
+
+// Publish sends an event to every subscriber to its type. It never
+// blocks: a subscriber whose buffer is full misses the event.
+func (h *Hub) Publish(eventType Type, data interface{}) {
+	event := Event{Type: eventType, Time: h.now(), Data: data}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.wants(eventType) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// PublishImpasse publishes a detected impasse.
+func (h *Hub) PublishImpasse(imp *memory.Impasse) {
+	h.Publish(ImpasseDetected, ImpasseData{
+		ID:          imp.ID,
+		Kind:        imp.Type.String(),
+		GoalID:      imp.GoalID,
+		Description: imp.Description,
+		Candidates:  append([]string(nil), imp.Candidates...),
+		FailedAgent: imp.FailedAgent,
+	})
+}
+
+// PublishFocusGained publishes an item entering focus.
+func (h *Hub) PublishFocusGained(item *memory.FocusItem) {
+	h.Publish(FocusGained, focusData(item, ""))
+}
+
+// PublishFocusLost publishes an item leaving focus.
+func (h *Hub) PublishFocusLost(item *memory.FocusItem, reason string) {
+	h.Publish(FocusLost, focusData(item, reason))
+}
+
+// PublishBreakthrough publishes a breakthrough.
+func (h *Hub) PublishBreakthrough(event memory.SurpriseEvent) {
+	h.Publish(Breakthrough, BreakthroughData{
+		Agents:   append([]string(nil), event.Agents...),
+		TaskType: event.TaskType,
+		Score:    event.SurpriseScore,
+		Strategy: event.Strategy,
+	})
+}
+
+// focusData describes a focus item, copied since it changes under its
+// controller's lock.
+func focusData(item *memory.FocusItem, reason string) FocusData {
+	return FocusData{
+		ID:       item.ID,
+		Kind:     item.Type.String(),
+		Label:    item.Label,
+		Salience: item.Salience,
+		Priority: item.Priority,
+		Reason:   reason,
+	}
+}
+
+// Subscription receives the events of the types it subscribed to.
+type Subscription struct {
+	hub    *Hub
+	events chan Event
+	types  map[Type]bool
+	// dropped counts the events missed since the last one taken; guarded
+	// by the hub's lock
+	dropped int
+}
+
+// Subscribe subscribes to events of the given types, or of every type
+// when none are given. The subscription's channel is closed when it or
+// the hub is closed.
+func (h *Hub) Subscribe(types ...Type) *Subscription {
+	sub := &Subscription{hub: h, events: make(chan Event, subscriberBuffer)}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(sub.events)
+		return sub
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Events returns the channel events are received on.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes and closes the channel. Closing twice is harmless.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subscribers[s]; ok {
+		delete(s.hub.subscribers, s)
+		close(s.events)
+	}
+}
+
+// takeDropped returns and resets the count of events dropped for the
+// subscription.
+func (s *Subscription) takeDropped() int {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	dropped := s.dropped
+	s.dropped = 0
+	return dropped
+}
+
+// wants reports whether the subscription is to events of a type.
+func (s *Subscription) wants(eventType Type) bool {
+	return s.types == nil || s.types[eventType]
+}
+
+// Subscribers returns how many subscriptions are open.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// Close closes every subscription, ending the streams served from them,
+// and refuses further ones. The server closes the hub as it shuts down,
+// since WebSocket connections outlive http.Server.Shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for sub := range h.subscribers {
+		close(sub.events)
+	}
+	h.subscribers = make(map[*Subscription]struct{})
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// receive returns the next event on a subscription, failing the test if
+// none arrives.
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatal("expected an event, got a closed subscription")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("expected an event, got none")
+	}
+	return Event{}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe()
+	defer all.Close()
+	impasses := hub.Subscribe(ImpasseDetected)
+	defer impasses.Close()
+
+	hub.PublishFocusGained(&memory.FocusItem{ID: "f1", Type: memory.FocusGoal, Label: "ship"})
+	hub.PublishImpasse(&memory.Impasse{ID: "i1", Type: memory.ImpasseTie, Candidates: []string{"APEX", "CIPHER"}})
+
+	if event := receive(t, all); event.Type != FocusGained || event.Data.(FocusData).Label != "ship" {
+		t.Errorf("expected the focus event first, got %+v", event)
+	}
+	if event := receive(t, all); event.Type != ImpasseDetected {
+		t.Errorf("expected the impasse event, got %+v", event)
+	}
+	event := receive(t, impasses)
+	if data := event.Data.(ImpasseData); event.Type != ImpasseDetected || data.Kind != memory.ImpasseTie.String() || len(data.Candidates) != 2 {
+		t.Errorf("expected only the impasse for its subscriber, got %+v", event)
+	}
+
+	// A subscriber that falls behind misses events rather than blocking
+	for i := 0; i < subscriberBuffer+3; i++ {
+		hub.Publish(ImpasseDetected, nil)
+	}
+	for i := 0; i < subscriberBuffer; i++ {
+		receive(t, impasses)
+	}
+	if dropped := impasses.takeDropped(); dropped != 3 {
+		t.Errorf("expected 3 events dropped, got %d", dropped)
+	}
+
+	impasses.Close()
+	impasses.Close()
+	if n := hub.Subscribers(); n != 1 {
+		t.Errorf("expected 1 subscriber after closing one, got %d", n)
+	}
+	hub.Close()
+	for range all.Events() {
+	}
+	if _, ok := <-hub.Subscribe().Events(); ok {
+		t.Error("expected subscriptions to a closed hub to be closed")
+	}
+}
+
+func TestHubWatch(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(FocusGained, FocusLost)
+	defer sub.Close()
+	attention := memory.NewAttentionController(nil)
+	hub.Watch(Sources{Attention: attention})
+
+	item := memory.NewFocusItem(memory.FocusTask, nil, "review", 0.8)
+	if _, err := attention.Focus(item); err != nil {
+		t.Fatalf("Focus: %v", err)
+	}
+	if err := attention.Unfocus(item.ID); err != nil {
+		t.Fatalf("Unfocus: %v", err)
+	}
+	if event := receive(t, sub); event.Type != FocusGained || event.Data.(FocusData).ID != item.ID {
+		t.Errorf("expected the item to gain focus, got %+v", event)
+	}
+	if event := receive(t, sub); event.Type != FocusLost || event.Data.(FocusData).Reason == "" {
+		t.Errorf("expected the item to lose focus with a reason, got %+v", event)
+	}
+}
+
+func TestServeWS(t *testing.T) {
+	hub := NewHub()
+	var authorization string
+	server := httptest.NewServer(BearerFromProtocol(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		hub.ServeWS(w, r)
+	})))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?types=breakthrough"
+
+	resp, err := http.Get(server.URL + "/ws?types=thoughts")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected unknown types rejected with 400, got %d", resp.StatusCode)
+	}
+
+	config, err := websocket.NewConfig(url, server.URL)
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	config.Protocol = []string{BearerProtocol, "secret-token"}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("DialConfig: %v", err)
+	}
+	if authorization != "Bearer secret-token" {
+		t.Errorf("expected the offered token as a bearer token, got %q", authorization)
+	}
+	if len(config.Protocol) != 1 || config.Protocol[0] != BearerProtocol {
+		t.Errorf("expected the server to answer with %s only, got %v", BearerProtocol, config.Protocol)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	hub.PublishFocusGained(&memory.FocusItem{ID: "skipped"})
+	hub.PublishBreakthrough(memory.SurpriseEvent{Agents: []string{"APEX", "AXIOM"}, TaskType: "proof", SurpriseScore: 2.5})
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	var message []byte
+	if err := websocket.Message.Receive(ws, &message); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	var event struct {
+		Type Type             `json:"type"`
+		Data BreakthroughData `json:"data"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if event.Type != Breakthrough || event.Data.TaskType != "proof" || event.Data.Score != 2.5 {
+		t.Errorf("expected the breakthrough only, got %s", message)
+	}
+
+	// Closing the hub ends the stream
+	hub.Close()
+	if err := websocket.Message.Receive(ws, &message); err == nil {
+		t.Errorf("expected the stream closed with the hub, got %s", message)
+	}
+	ws.Close()
+}
+
+func TestServeWSClientClose(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(http.HandlerFunc(hub.ServeWS))
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ws.Close()
+	for hub.Subscribers() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := hub.Subscribers(); n != 0 {
+		t.Errorf("expected the subscription closed with the client, got %d", n)
+	}
+}
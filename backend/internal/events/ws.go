@@ -0,0 +1,148 @@
+package events
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// BearerProtocol is the WebSocket subprotocol browsers offer, followed by
+// their token as a second subprotocol, since they can't set headers on
+// WebSocket requests.
+const BearerProtocol = "eac.bearer"
+
+// writeTimeout bounds each write to a client, so a stalled one is dropped
+// rather than holding its stream open.
+const writeTimeout = 10 * time.Second
+
+// BearerFromProtocol is HTTP middleware that moves a token offered through
+// BearerProtocol into the Authorization header, for the authentication
+// middleware after it. Tokens aren't accepted in the query string, which
+// request logs record.
+func BearerFromProtocol(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			protocols := offeredProtocols(r)
+			for i, protocol := range protocols {
+				if protocol == BearerProtocol && i+1 < len(protocols) {
+					r.Header.Set("Authorization", "Bearer "+protocols[i+1])
+					break
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// offeredProtocols returns the subprotocols a WebSocket request offers.
+func offeredProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// parseTypes parses a comma-separated list of event types; an empty list
+// is every type.
+func parseTypes(list string) ([]Type, bool) {
+	var types []Type
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		known := false
+		for _, t := range Types {
+			known = known || Type(name) == t
+		}
+		if !known {
+			return nil, false
+		}
+		types = append(types, Type(name))
+	}
+	return types, true
+}
+
+// ServeWS upgrades a request to a WebSocket and streams events to it as
+// JSON messages until the client or the hub closes it. The types query
+// parameter limits the stream to a comma-separated list of event types.
+// Streams are sent heartbeats; messages from the client are ignored.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	types, ok := parseTypes(r.URL.Query().Get("types"))
+	if !ok {
+		http.Error(w, "Unknown event type; expected some of "+typeList(), http.StatusBadRequest)
+		return
+	}
+	websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			// Answer with BearerProtocol when offered, never echoing the token
+			protocol := config.Protocol
+			config.Protocol = nil
+			for _, offered := range protocol {
+				if offered == BearerProtocol {
+					config.Protocol = []string{BearerProtocol}
+				}
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			h.stream(ws, types)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// stream sends a subscription's events to a connection until either ends.
+func (h *Hub) stream(ws *websocket.Conn, types []Type) {
+	// The connection is hijacked with the server's deadlines still set
+	ws.SetDeadline(time.Time{})
+	sub := h.Subscribe(types...)
+	defer sub.Close()
+
+	// Reading notices the client closing; the server closes the connection
+	// when stream returns, which ends the reader too
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var message []byte
+		for websocket.Message.Receive(ws, &message) == nil {
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var event Event
+		select {
+		case next, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			event = next
+			event.Dropped = sub.takeDropped()
+		case <-heartbeat.C:
+			event = Event{Type: Heartbeat, Time: h.now()}
+		case <-closed:
+			return
+		}
+		ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := websocket.JSON.Send(ws, event); err != nil {
+			return
+		}
+	}
+}
+
+// typeList returns the event types clients can subscribe to, joined by
+// commas.
+func typeList() string {
+	names := make([]string, len(Types))
+	for i, t := range Types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,249 @@
+// Package features provides the feature flags that gate experimental
+// cognitive features, so they can be enabled progressively: first for
+// chosen tenants, then for everyone.
+package features
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidConfig is returned for flag files that fail validation
+	ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid features config")
+	// ErrUnknownFlag is returned for flags that are not defined
+	ErrUnknownFlag = errdefs.New(errdefs.ErrNotFound, "unknown feature flag")
+	// ErrFeatureDisabled is returned for requests to a feature that is off
+	// for the tenant
+	ErrFeatureDisabled = errdefs.New(errdefs.ErrForbidden, "feature disabled")
+)
+
+// Flag names.
+const (
+	// MCTSPlanning plans with Monte Carlo tree search
+	MCTSPlanning = "mcts_planning"
+	// DebateMode has agents argue a question before answering it
+	DebateMode = "debate_mode"
+	// AutoLearning applies outcome feedback to the learning structures
+	AutoLearning = "auto_learning"
+	// GroundedAnswers verifies agent answers against the knowledge graph
+	GroundedAnswers = "grounded_answers"
+)
+
+// Definition describes a flag and its built-in default.
+type Definition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Default applies when neither the config nor an override sets the flag
+	Default bool `json:"default"`
+}
+
+// definitions are the flags the server knows. Auto-learning defaults on
+// because feedback ingestion predates the flag.
+var definitions = []Definition{
+	{Name: AutoLearning, Description: "Apply outcome feedback to the learning structures", Default: true},
+	{Name: DebateMode, Description: "Agents debate a question before answering it"},
+	{Name: GroundedAnswers, Description: "Verify agent answers against the knowledge graph and mark unsupported claims"},
+	{Name: MCTSPlanning, Description: "Plan with Monte Carlo tree search"},
+}
+
+// Definitions returns the known flags, sorted by name.
+func Definitions() []Definition {
+	return append([]Definition(nil), definitions...)
+}
+
+// lookup returns the definition of a flag.
+func lookup(name string) (Definition, bool) {
+	for _, def := range definitions {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+// Config sets flags at startup.
+type Config struct {
+	Flags map[string]FlagConfig `yaml:"flags"`
+}
+
+// FlagConfig sets one flag.
+type FlagConfig struct {
+	// Enabled is the flag's value for every tenant; nil keeps the default
+	Enabled *bool `yaml:"enabled"`
+	// Tenants override Enabled for the listed tenants
+	Tenants map[string]bool `yaml:"tenants"`
+}
+
+// LoadConfig reads and validates a features file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read features config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes and validates a features config. Unknown fields and
+// flags are errors, so misspelled names are caught at load time.
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse features YAML: %w", err)
+	}
+
+	var problems []error
+	for _, name := range sortedKeys(cfg.Flags) {
+		if _, ok := lookup(name); !ok {
+			problems = append(problems, fmt.Errorf("flag %s is not defined", name))
+		}
+		for tenant := range cfg.Flags[name].Tenants {
+			if tenant == "" {
+				problems = append(problems, fmt.Errorf("flag %s: tenant id is required", name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return &cfg, nil
+}
+
+// ============================================================================
+// Flags
+// ============================================================================
+
+// flagState is the current value of one flag.
+type flagState struct {
+	enabled bool
+	tenants map[string]bool
+}
+
+// FlagStatus describes a flag's current value.
+type FlagStatus struct {
+	Definition
+	Enabled bool `json:"enabled"`
+	// Tenants are the per-tenant overrides
+	Tenants map[string]bool `json:"tenants"`
+}
+
+// Flags answers whether a feature is enabled for a tenant. Values start
+// from the config and may be changed at runtime; runtime changes are not
+// persisted. It is safe for concurrent use.
+type Flags struct {
+	mu    sync.RWMutex
+	flags map[string]*flagState
+}
+
+// New creates the flags set by cfg; a nil cfg uses the defaults.
+func New(cfg *Config) *Flags {
+	f := &Flags{flags: make(map[string]*flagState, len(definitions))}
+	for _, def := range definitions {
+		state := &flagState{enabled: def.Default, tenants: make(map[string]bool)}
+		if cfg != nil {
+			flag := cfg.Flags[def.Name]
+			if flag.Enabled != nil {
+				state.enabled = *flag.Enabled
+			}
+			for tenant, enabled := range flag.Tenants {
+				state.tenants[tenant] = enabled
+			}
+		}
+		f.flags[def.Name] = state
+	}
+	return f
+}
+
+// Enabled reports whether a flag is on for a tenant; an empty tenant gets
+// the flag's global value. Unknown flags are off.
+func (f *Flags) Enabled(name, tenant string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	state, ok := f.flags[name]
+	if !ok {
+		return false
+	}
+	if enabled, ok := state.tenants[tenant]; ok && tenant != "" {
+		return enabled
+	}
+	return state.enabled
+}
+
+// Set turns a flag on or off for a tenant, or for everyone when tenant is
+// empty. Tenant overrides survive a global change.
+func (f *Flags) Set(name, tenant string, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.flags[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	if tenant == "" {
+		state.enabled = enabled
+	} else {
+		state.tenants[tenant] = enabled
+	}
+	return nil
+}
+
+// Clear removes a tenant's override, so the tenant gets the global value.
+func (f *Flags) Clear(name, tenant string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.flags[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	delete(state.tenants, tenant)
+	return nil
+}
+
+// Status returns a flag's current value.
+func (f *Flags) Status(name string) (*FlagStatus, error) {
+	def, ok := lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	state := f.flags[name]
+	status := &FlagStatus{Definition: def, Enabled: state.enabled, Tenants: make(map[string]bool, len(state.tenants))}
+	for tenant, enabled := range state.tenants {
+		status.Tenants[tenant] = enabled
+	}
+	return status, nil
+}
+
+// List returns every flag's current value, sorted by name.
+func (f *Flags) List() []*FlagStatus {
+	statuses := make([]*FlagStatus, 0, len(definitions))
+	for _, def := range definitions {
+		status, _ := f.Status(def.Name)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys(m map[string]FlagConfig) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
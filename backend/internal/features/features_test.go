@@ -0,0 +1,138 @@
+package features
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testFeaturesYAML = `
+flags:
+  mcts_planning:
+    tenants:
+      acme: true
+  auto_learning:
+    enabled: false
+    tenants:
+      globex: true
+`
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(testFeaturesYAML))
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	if len(cfg.Flags) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(cfg.Flags))
+	}
+	if learning := cfg.Flags[AutoLearning]; learning.Enabled == nil || *learning.Enabled || !learning.Tenants["globex"] {
+		t.Errorf("expected auto-learning off but on for globex, got %+v", learning)
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown flag", "flags: {time_travel: {enabled: true}}", "time_travel is not defined"},
+		{"empty tenant", `flags: {debate_mode: {tenants: {"": true}}}`, "tenant id is required"},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.yaml))
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error to mention %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := ParseConfig([]byte("flags: {debate_mode: {on: true}}")); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+func TestFlagsDefaults(t *testing.T) {
+	flags := New(nil)
+	for _, def := range Definitions() {
+		if got := flags.Enabled(def.Name, "acme"); got != def.Default {
+			t.Errorf("expected %s to default to %v, got %v", def.Name, def.Default, got)
+		}
+	}
+	if flags.Enabled("time_travel", "") {
+		t.Error("expected unknown flag to be off")
+	}
+}
+
+func TestFlagsTenantOverrides(t *testing.T) {
+	cfg, err := ParseConfig([]byte(testFeaturesYAML))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	flags := New(cfg)
+
+	if !flags.Enabled(MCTSPlanning, "acme") || flags.Enabled(MCTSPlanning, "globex") || flags.Enabled(MCTSPlanning, "") {
+		t.Error("expected MCTS planning on only for acme")
+	}
+	if flags.Enabled(AutoLearning, "acme") || !flags.Enabled(AutoLearning, "globex") {
+		t.Error("expected auto-learning off except for globex")
+	}
+}
+
+func TestFlagsSetAndClear(t *testing.T) {
+	flags := New(nil)
+
+	if err := flags.Set(DebateMode, "acme", true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := flags.Set(DebateMode, "", true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := flags.Set(DebateMode, "acme", false); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if flags.Enabled(DebateMode, "acme") || !flags.Enabled(DebateMode, "globex") {
+		t.Error("expected debate mode on globally but off for acme")
+	}
+
+	if err := flags.Clear(DebateMode, "acme"); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if !flags.Enabled(DebateMode, "acme") {
+		t.Error("expected acme to get the global value after its override is cleared")
+	}
+
+	if err := flags.Set("time_travel", "", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("expected ErrUnknownFlag, got %v", err)
+	}
+	if err := flags.Clear("time_travel", "acme"); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("expected ErrUnknownFlag, got %v", err)
+	}
+}
+
+func TestFlagsList(t *testing.T) {
+	flags := New(nil)
+	flags.Set(MCTSPlanning, "acme", true)
+
+	statuses := flags.List()
+	if len(statuses) != len(Definitions()) {
+		t.Fatalf("expected %d flags, got %d", len(Definitions()), len(statuses))
+	}
+	for i := 1; i < len(statuses); i++ {
+		if statuses[i-1].Name >= statuses[i].Name {
+			t.Errorf("expected flags sorted by name, got %s before %s", statuses[i-1].Name, statuses[i].Name)
+		}
+	}
+
+	status, err := flags.Status(MCTSPlanning)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	status.Tenants["globex"] = true
+	if flags.Enabled(MCTSPlanning, "globex") {
+		t.Error("expected status to be a copy")
+	}
+}
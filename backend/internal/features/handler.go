@@ -0,0 +1,114 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// TenantHeader names the tenant a request is made for; flags are
+// evaluated for that tenant.
+const TenantHeader = "X-Tenant-ID"
+
+// tenantKey is the context key of the request's tenant.
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant a request is made for.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant or the Tenant
+// middleware, or "" if there is none.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Tenant is HTTP middleware that records the request's tenant in its
+// context, so flags can be evaluated below the HTTP layer.
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant := r.Header.Get(TenantHeader); tenant != "" {
+			r = r.WithContext(WithTenant(r.Context(), tenant))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require is HTTP middleware that rejects requests with 403 unless a flag
+// is on for the request's tenant.
+func (f *Flags) Require(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !f.Enabled(name, r.Header.Get(TenantHeader)) {
+				errdefs.WriteError(w, ErrFeatureDisabled.Error()+": "+name, errdefs.HTTPStatus(ErrFeatureDisabled))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ServeList handles GET /admin/features - lists every flag's current value.
+func (f *Flags) ServeList(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, f.List())
+}
+
+// SetRequest is the body of PUT /admin/features/{name}.
+type SetRequest struct {
+	// Tenant is the tenant to override; empty sets the global value
+	Tenant string `json:"tenant,omitempty"`
+	// Enabled turns the flag on or off; null clears the tenant's override
+	Enabled *bool `json:"enabled"`
+}
+
+// ServeSet handles PUT /admin/features/{name} - toggles a flag at runtime
+// and returns its new value.
+func (f *Flags) ServeSet(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	var req SetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch {
+	case req.Enabled != nil:
+		err = f.Set(name, req.Tenant, *req.Enabled)
+	case req.Tenant != "":
+		err = f.Clear(name, req.Tenant)
+	default:
+		errdefs.WriteError(w, "enabled is required without a tenant", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	log.Printf("Feature %s for tenant %q set to %v", name, req.Tenant, describe(req.Enabled))
+
+	status, err := f.Status(name)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, status)
+}
+
+// describe renders a requested value for the log.
+func describe(enabled *bool) string {
+	switch {
+	case enabled == nil:
+		return "default"
+	case *enabled:
+		return "on"
+	default:
+		return "off"
+	}
+}
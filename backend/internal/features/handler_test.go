@@ -0,0 +1,127 @@
+package features
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestRouter(flags *Flags) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/admin/features", flags.ServeList)
+	r.Put("/admin/features/{name}", flags.ServeSet)
+	r.With(flags.Require(DebateMode)).Post("/debate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
+func TestTenant(t *testing.T) {
+	var got string
+	handler := Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = TenantFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(TenantHeader, "acme")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "acme" {
+		t.Errorf("expected tenant acme in context, got %q", got)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got != "" {
+		t.Errorf("expected no tenant without the header, got %q", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	flags := New(nil)
+	flags.Set(DebateMode, "acme", true)
+	router := newTestRouter(flags)
+
+	tests := []struct {
+		tenant string
+		want   int
+	}{
+		{"acme", http.StatusOK},
+		{"globex", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/debate", nil)
+		req.Header.Set(TenantHeader, tt.tenant)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("tenant %q: expected status %d, got %d", tt.tenant, tt.want, w.Code)
+		}
+	}
+}
+
+func TestServeSet(t *testing.T) {
+	flags := New(nil)
+	router := newTestRouter(flags)
+
+	put := func(name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/features/"+name, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put(MCTSPlanning, `{"tenant": "acme", "enabled": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var status FlagStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if status.Name != MCTSPlanning || status.Enabled || !status.Tenants["acme"] {
+		t.Errorf("expected MCTS planning on for acme only, got %+v", status)
+	}
+	if !flags.Enabled(MCTSPlanning, "acme") {
+		t.Error("expected the toggle to take effect")
+	}
+
+	if w := put(MCTSPlanning, `{"tenant": "acme", "enabled": null}`); w.Code != http.StatusOK || flags.Enabled(MCTSPlanning, "acme") {
+		t.Errorf("expected the override cleared, got status %d", w.Code)
+	}
+
+	tests := []struct {
+		name string
+		flag string
+		body string
+		want int
+	}{
+		{"unknown flag", "time_travel", `{"enabled": true}`, http.StatusNotFound},
+		{"global without value", MCTSPlanning, `{}`, http.StatusBadRequest},
+		{"malformed", MCTSPlanning, `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := put(tt.flag, tt.body); w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestServeList(t *testing.T) {
+	router := newTestRouter(New(nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/features", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var statuses []FlagStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(statuses) != len(Definitions()) {
+		t.Errorf("expected %d flags, got %d", len(Definitions()), len(statuses))
+	}
+}
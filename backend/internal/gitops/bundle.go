@@ -0,0 +1,205 @@
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/intent"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// Repository layout, relative to the configured path.
+const (
+	// PersonasDir holds one .agent.md persona per agent
+	PersonasDir = "agents"
+	// TemplatesFile holds the per-intent prompt templates
+	TemplatesFile = "intent-templates.yaml"
+	// RulesDir holds the rule packs, one YAML file each
+	RulesDir = "rules"
+)
+
+// Bundle is what one commit of the repository configures.
+type Bundle struct {
+	Commit string
+	// Personas are the agents' personas with the rule packs' directives
+	// added
+	Personas []models.Agent
+	// Templates are the intent templates; nil if the repository has none
+	Templates *intent.Config
+	// Rules are the rule packs, by file name
+	Rules []*RulePack
+}
+
+// RulePack adds directives to the personas of some agents.
+type RulePack struct {
+	Name string `yaml:"-"`
+	// Agents are the codenames the directives are added to; empty adds
+	// them to every persona in the repository
+	Agents     []string `yaml:"agents"`
+	Directives []string `yaml:"directives"`
+}
+
+// ParseRulePack decodes and validates a rule pack.
+func ParseRulePack(name string, data []byte) (*RulePack, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	pack := &RulePack{Name: name}
+	if err := decoder.Decode(pack); err != nil {
+		return nil, fmt.Errorf("rule pack %s: %w", name, err)
+	}
+	if len(pack.Directives) == 0 {
+		return nil, fmt.Errorf("rule pack %s: no directives", name)
+	}
+	for i, directive := range pack.Directives {
+		if strings.TrimSpace(directive) == "" {
+			return nil, fmt.Errorf("rule pack %s: directive %d is empty", name, i+1)
+		}
+	}
+	return pack, nil
+}
+
+// appliesTo reports whether the pack's directives are added to an agent.
+func (p *RulePack) appliesTo(codename string) bool {
+	if len(p.Agents) == 0 {
+		return true
+	}
+	for _, agent := range p.Agents {
+		if agent == codename {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBundle reads the personas, templates and rule packs under dir. Any
+// file that fails to load fails the whole bundle, so a broken commit is
+// never partly applied.
+func LoadBundle(dir string) (*Bundle, error) {
+	bundle := &Bundle{}
+	var problems []error
+
+	personas, err := loadPersonas(filepath.Join(dir, PersonasDir))
+	if err != nil {
+		problems = append(problems, err)
+	}
+	bundle.Personas = personas
+
+	path := filepath.Join(dir, TemplatesFile)
+	if _, err := os.Stat(path); err == nil {
+		if bundle.Templates, err = intent.LoadConfig(path); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", TemplatesFile, err))
+		}
+	}
+
+	bundle.Rules, err = loadRulePacks(filepath.Join(dir, RulesDir))
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	known := make(map[string]bool, len(bundle.Personas))
+	for _, persona := range bundle.Personas {
+		known[persona.Codename] = true
+	}
+	for _, pack := range bundle.Rules {
+		for _, codename := range pack.Agents {
+			if !known[codename] {
+				problems = append(problems, fmt.Errorf("rule pack %s: %s has no persona in the repository", pack.Name, codename))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, errors.Join(problems...))
+	}
+
+	for i := range bundle.Personas {
+		persona := &bundle.Personas[i]
+		for _, pack := range bundle.Rules {
+			if pack.appliesTo(persona.Codename) {
+				persona.Directives = append(persona.Directives, pack.Directives...)
+			}
+		}
+	}
+	return bundle, nil
+}
+
+// loadPersonas loads the .agent.md files of a directory, which may be
+// missing.
+func loadPersonas(dir string) ([]models.Agent, error) {
+	names, err := filesWithSuffix(dir, ".agent.md")
+	if err != nil {
+		return nil, err
+	}
+	personas := make([]models.Agent, 0, len(names))
+	seen := make(map[string]string, len(names))
+	var problems []error
+	for _, name := range names {
+		persona, err := agents.LoadAgentFromFile(filepath.Join(dir, name))
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if persona.Codename == "" {
+			problems = append(problems, fmt.Errorf("%s: codename is required", name))
+			continue
+		}
+		if other, ok := seen[persona.Codename]; ok {
+			problems = append(problems, fmt.Errorf("%s: %s is also defined in %s", name, persona.Codename, other))
+			continue
+		}
+		seen[persona.Codename] = name
+		personas = append(personas, *persona)
+	}
+	return personas, errors.Join(problems...)
+}
+
+// loadRulePacks loads the rule packs of a directory, which may be missing.
+func loadRulePacks(dir string) ([]*RulePack, error) {
+	names, err := filesWithSuffix(dir, ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	packs := make([]*RulePack, 0, len(names))
+	var problems []error
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		pack, err := ParseRulePack(strings.TrimSuffix(name, ".yaml"), data)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		packs = append(packs, pack)
+	}
+	return packs, errors.Join(problems...)
+}
+
+// filesWithSuffix returns the names of the files in dir ending in suffix,
+// sorted; a missing directory has none.
+func filesWithSuffix(dir, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
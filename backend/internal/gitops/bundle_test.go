@@ -0,0 +1,83 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, by path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// persona returns an .agent.md file for a codename.
+func persona(codename, philosophy string) string {
+	return "---\nname: " + codename + "\ndescription: Specialist\ncodename: " + codename + "\ntier: 1\nid: \"01\"\n---\n\n" +
+		"**Philosophy:** _\"" + philosophy + "\"_\n\n## Core Capabilities\n\n- Be precise\n"
+}
+
+func TestLoadBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"agents/APEX.agent.md":   persona("APEX", "Elegance first."),
+		"agents/CIPHER.agent.md": persona("CIPHER", "Trust nothing."),
+		"agents/README.md":       "not a persona",
+		"intent-templates.yaml":  "templates:\n  review: \"Review strictly: {{.Query}}\"\n",
+		"rules/security.yaml":    "agents: [CIPHER]\ndirectives:\n  - Never suggest disabling TLS verification\n",
+		"rules/style.yaml":       "directives:\n  - Answer in British English\n",
+	})
+
+	bundle, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatalf("LoadBundle failed: %v", err)
+	}
+	if len(bundle.Personas) != 2 || bundle.Templates == nil || len(bundle.Rules) != 2 {
+		t.Fatalf("unexpected bundle %+v", bundle)
+	}
+	apex, cipher := bundle.Personas[0], bundle.Personas[1]
+	if apex.Codename != "APEX" || apex.Philosophy != "Elegance first." {
+		t.Errorf("unexpected persona %+v", apex)
+	}
+	if got := strings.Join(apex.Directives, "; "); got != "Be precise; Answer in British English" {
+		t.Errorf("expected only the pack for every agent added to APEX, got %q", got)
+	}
+	if got := strings.Join(cipher.Directives, "; "); got != "Be precise; Never suggest disabling TLS verification; Answer in British English" {
+		t.Errorf("expected both packs added to CIPHER, got %q", got)
+	}
+}
+
+func TestLoadBundle_Empty(t *testing.T) {
+	bundle, err := LoadBundle(t.TempDir())
+	if err != nil || len(bundle.Personas) != 0 || bundle.Templates != nil || len(bundle.Rules) != 0 {
+		t.Errorf("expected an empty bundle, got %+v (%v)", bundle, err)
+	}
+}
+
+func TestLoadBundle_Invalid(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"unknown intent":    {"intent-templates.yaml": "templates:\n  gossip: \"{{.Query}}\"\n"},
+		"empty rule pack":   {"rules/empty.yaml": "agents: [APEX]\n"},
+		"unknown field":     {"rules/typo.yaml": "directive:\n  - Be brief\n"},
+		"unknown agent":     {"agents/APEX.agent.md": persona("APEX", "x"), "rules/r.yaml": "agents: [NOBODY]\ndirectives: [Be brief]\n"},
+		"no codename":       {"agents/X.agent.md": "---\nname: X\n---\n"},
+		"duplicate persona": {"agents/A.agent.md": persona("APEX", "x"), "agents/B.agent.md": persona("APEX", "y")},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		if _, err := LoadBundle(dir); !errors.Is(err, ErrInvalidBundle) {
+			t.Errorf("%s: expected ErrInvalidBundle, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package gitops keeps agent personas, intent prompt templates and rule
+// packs in sync with a Git repository. A syncer fetches a branch on an
+// interval, verifies the signature of its latest commit, loads what the
+// commit configures and hands it to a callback that applies it, so prompt
+// changes are deployed by pushing a signed commit instead of releasing the
+// server. Unsigned or badly signed commits, and commits with files that
+// fail to load, are never applied; the last good commit stays in effect.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidBundle is returned for commits whose files fail to load
+	ErrInvalidBundle = errdefs.New(errdefs.ErrInvalidArgument, "invalid prompt repository")
+	// ErrUnverified is returned for commits without a trusted signature
+	ErrUnverified = errdefs.New(errdefs.ErrForbidden, "commit signature not verified")
+	// ErrGit is returned when the repository cannot be fetched or checked
+	// out
+	ErrGit = errdefs.New(errdefs.ErrUnavailable, "git failed")
+)
+
+// DefaultBranch is the branch synced when none is configured.
+const DefaultBranch = "main"
+
+// Config sets the repository a syncer follows.
+type Config struct {
+	// Repo is the repository's URL or path
+	Repo string
+	// Branch is synced; empty is main
+	Branch string
+	// Dir is the local checkout; it is created on the first sync
+	Dir string
+	// Path is the directory within the repository the files are in; empty
+	// is its root
+	Path string
+	// AllowedSigners is the SSH allowed signers file commits are verified
+	// against; empty verifies GPG signatures against the server's keyring
+	AllowedSigners string
+}
+
+// Status is the state of a syncer.
+type Status struct {
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+	// Commit is the commit in effect; empty until one is applied
+	Commit    string    `json:"commit,omitempty"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+	Personas  int       `json:"personas"`
+	Templates int       `json:"templates"`
+	Rules     int       `json:"rules"`
+	// CheckedAt is when the branch was last fetched
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	// Error is why the last sync failed; empty if it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// Syncer applies the commits of a branch as they are pushed.
+type Syncer struct {
+	config Config
+	apply  func(*Bundle) error
+
+	// syncing serializes syncs
+	syncing sync.Mutex
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewSyncer creates a syncer that calls apply with each verified commit's
+// bundle. An error from apply leaves the commit unapplied, to be tried
+// again on the next sync.
+func NewSyncer(cfg Config, apply func(*Bundle) error) *Syncer {
+	if cfg.Branch == "" {
+		cfg.Branch = DefaultBranch
+	}
+	return &Syncer{config: cfg, apply: apply, status: Status{Repo: cfg.Repo, Branch: cfg.Branch}}
+}
+
+// Status returns the syncer's state.
+func (s *Syncer) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Sync fetches the branch and applies its latest commit if it is new and
+// verified. It returns whether a commit was applied.
+func (s *Syncer) Sync(ctx context.Context) (bool, error) {
+	s.syncing.Lock()
+	defer s.syncing.Unlock()
+
+	applied, err := s.sync(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.CheckedAt = time.Now()
+	s.status.Error = ""
+	if err != nil {
+		s.status.Error = err.Error()
+	}
+	return applied, err
+}
+
+// sync does the work of Sync.
+func (s *Syncer) sync(ctx context.Context) (bool, error) {
+	if _, err := os.Stat(filepath.Join(s.config.Dir, ".git")); err != nil {
+		if err := os.MkdirAll(s.config.Dir, 0o755); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrGit, err)
+		}
+		if _, err := s.git(ctx, "init", "--quiet"); err != nil {
+			return false, err
+		}
+	}
+	if _, err := s.git(ctx, "fetch", "--quiet", "--no-tags", s.config.Repo, s.config.Branch); err != nil {
+		return false, err
+	}
+	commit, err := s.git(ctx, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return false, err
+	}
+	if commit == s.Status().Commit {
+		return false, nil
+	}
+
+	verify := []string{"verify-commit", commit}
+	if s.config.AllowedSigners != "" {
+		signers, err := filepath.Abs(s.config.AllowedSigners)
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrGit, err)
+		}
+		verify = append([]string{"-c", "gpg.ssh.allowedSignersFile=" + signers}, verify...)
+	}
+	if _, err := s.run(ctx, verify...); err != nil {
+		return false, fmt.Errorf("%w: commit %s: %v", ErrUnverified, commit, err)
+	}
+	if _, err := s.git(ctx, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+		return false, err
+	}
+
+	bundle, err := LoadBundle(filepath.Join(s.config.Dir, s.config.Path))
+	if err != nil {
+		return false, fmt.Errorf("commit %s: %w", commit, err)
+	}
+	bundle.Commit = commit
+	if err := s.apply(bundle); err != nil {
+		return false, fmt.Errorf("applying commit %s: %w", commit, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Commit = commit
+	s.status.AppliedAt = time.Now()
+	s.status.Personas = len(bundle.Personas)
+	s.status.Templates = 0
+	if bundle.Templates != nil {
+		s.status.Templates = len(bundle.Templates.Templates)
+	}
+	s.status.Rules = len(bundle.Rules)
+	return true, nil
+}
+
+// git runs a git command in the checkout and returns its trimmed output.
+func (s *Syncer) git(ctx context.Context, args ...string) (string, error) {
+	out, err := s.run(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrGit, err)
+	}
+	return out, nil
+}
+
+// run runs a git command, failing with what it wrote to stderr.
+func (s *Syncer) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.config.Dir
+	// Credentials come from the environment's helpers, never a prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), message)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Run syncs now and then every interval until ctx is done. Failures are
+// logged and retried on the next tick.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if applied, err := s.Sync(ctx); err != nil {
+			log.Printf("Prompt repository sync failed: %v", err)
+		} else if applied {
+			log.Printf("Applied prompt repository commit %s", s.Status().Commit)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// ServeStatus handles GET /admin/gitops - reports the commit in effect and
+// the last sync.
+func (s *Syncer) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, s.Status())
+}
+
+// ServeSync handles POST /admin/gitops/sync - syncs now instead of waiting
+// for the interval.
+func (s *Syncer) ServeSync(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.Sync(r.Context()); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, s.Status())
+}
@@ -0,0 +1,168 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a repository commits are pushed to, signed with an SSH key.
+type testRepo struct {
+	t       *testing.T
+	dir     string
+	key     string
+	signers string
+}
+
+// newTestRepo creates a repository and an allowed signers file trusting
+// its key.
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	for _, tool := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+	root := t.TempDir()
+	r := &testRepo{t: t, dir: filepath.Join(root, "prompts"), key: filepath.Join(root, "key"), signers: filepath.Join(root, "allowed_signers")}
+	r.run(root, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "prompts@example.com", "-f", r.key)
+	public, err := os.ReadFile(r.key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(r.signers, append([]byte("prompts@example.com "), public...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r.run(root, "git", "init", "--quiet", "--initial-branch=main", r.dir)
+	return r
+}
+
+// run runs a command in dir.
+func (r *testRepo) run(dir string, name string, args ...string) {
+	r.t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		r.t.Fatalf("%s %s failed: %v: %s", name, strings.Join(args, " "), err, out)
+	}
+}
+
+// commit commits files, signed with the repository's key if signed is set.
+func (r *testRepo) commit(files map[string]string, signed bool) {
+	r.t.Helper()
+	writeFiles(r.t, r.dir, files)
+	r.run(r.dir, "git", "add", "-A")
+	args := []string{"-c", "user.name=Prompts", "-c", "user.email=prompts@example.com", "-c", "gpg.format=ssh", "-c", "user.signingkey=" + r.key,
+		"commit", "--quiet", "--allow-empty", "-m", "Update prompts"}
+	if signed {
+		args = append(args, "-S")
+	} else {
+		args = append(args, "--no-gpg-sign")
+	}
+	r.run(r.dir, "git", args...)
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	repo := newTestRepo(t)
+	var applied []*Bundle
+	syncer := NewSyncer(Config{Repo: repo.dir, Dir: filepath.Join(t.TempDir(), "checkout"), AllowedSigners: repo.signers}, func(b *Bundle) error {
+		applied = append(applied, b)
+		return nil
+	})
+	ctx := context.Background()
+
+	repo.commit(map[string]string{"agents/APEX.agent.md": persona("APEX", "Elegance first.")}, true)
+	if ok, err := syncer.Sync(ctx); !ok || err != nil {
+		t.Fatalf("expected the signed commit applied, got %v (%v)", ok, err)
+	}
+	status := syncer.Status()
+	if len(applied) != 1 || applied[0].Commit != status.Commit || status.Personas != 1 || status.Branch != "main" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	// Nothing new is not applied again
+	if ok, err := syncer.Sync(ctx); ok || err != nil || len(applied) != 1 {
+		t.Errorf("expected nothing applied, got %v (%v)", ok, err)
+	}
+
+	// Unsigned and broken commits leave the last good one in effect
+	repo.commit(map[string]string{"agents/APEX.agent.md": persona("APEX", "Unsigned.")}, false)
+	if _, err := syncer.Sync(ctx); !errors.Is(err, ErrUnverified) {
+		t.Errorf("expected ErrUnverified, got %v", err)
+	}
+	repo.commit(map[string]string{"rules/empty.yaml": "agents: [APEX]\n"}, true)
+	if _, err := syncer.Sync(ctx); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected ErrInvalidBundle, got %v", err)
+	}
+	if got := syncer.Status(); got.Commit != status.Commit || got.Error == "" || len(applied) != 1 {
+		t.Errorf("expected the first commit still in effect with the error reported, got %+v", got)
+	}
+
+	repo.commit(map[string]string{"rules/empty.yaml": "directives: [Be brief]\n"}, true)
+	if ok, err := syncer.Sync(ctx); !ok || err != nil {
+		t.Fatalf("expected the fixed commit applied, got %v (%v)", ok, err)
+	}
+	if got := applied[1].Personas[0]; got.Philosophy != "Unsigned." || len(got.Directives) != 2 {
+		t.Errorf("expected the latest personas with the rule pack, got %+v", got)
+	}
+	if got := syncer.Status(); got.Error != "" || got.Rules != 1 {
+		t.Errorf("expected a clean status, got %+v", got)
+	}
+}
+
+func TestSyncer_UntrustedSigner(t *testing.T) {
+	repo := newTestRepo(t)
+	other := newTestRepo(t)
+	repo.commit(map[string]string{"agents/APEX.agent.md": persona("APEX", "x")}, true)
+	syncer := NewSyncer(Config{Repo: repo.dir, Dir: t.TempDir(), AllowedSigners: other.signers}, func(*Bundle) error {
+		t.Error("expected nothing applied")
+		return nil
+	})
+	if _, err := syncer.Sync(context.Background()); !errors.Is(err, ErrUnverified) {
+		t.Errorf("expected ErrUnverified, got %v", err)
+	}
+}
+
+func TestSyncer_ApplyFailure(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(nil, true)
+	failures := 1
+	syncer := NewSyncer(Config{Repo: repo.dir, Dir: t.TempDir(), AllowedSigners: repo.signers}, func(*Bundle) error {
+		if failures > 0 {
+			failures--
+			return errors.New("registry unavailable")
+		}
+		return nil
+	})
+	if _, err := syncer.Sync(context.Background()); err == nil {
+		t.Fatal("expected the apply error")
+	}
+	if ok, err := syncer.Sync(context.Background()); !ok || err != nil {
+		t.Errorf("expected the commit applied on the next sync, got %v (%v)", ok, err)
+	}
+}
+
+func TestSyncer_Serve(t *testing.T) {
+	repo := newTestRepo(t)
+	syncer := NewSyncer(Config{Repo: repo.dir, Branch: "missing", Dir: t.TempDir()}, func(*Bundle) error { return nil })
+
+	w := httptest.NewRecorder()
+	syncer.ServeSync(w, httptest.NewRequest(http.MethodPost, "/admin/gitops/sync", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 for a missing branch, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	syncer.ServeStatus(w, httptest.NewRequest(http.MethodGet, "/admin/gitops", nil))
+	var status Status
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || status.Branch != "missing" || status.Error == "" {
+		t.Errorf("expected the failure reported, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,324 @@
+// Package grpcapi serves the collective's gRPC API: the agent and memory
+// services defined in api/collective/v1. They answer from the same agent
+// registry and knowledge graph as the HTTP API, and map errors to status
+// codes through errdefs.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	collectivev1 "github.com/iamthegreatdestroyer/elite-agent-collective/backend/api/collective/v1"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// PublicMethods are the methods served without authentication, like their
+// HTTP counterparts; pass them to auth.Middleware.UnaryInterceptor.
+var PublicMethods = []string{
+	collectivev1.AgentService_ListAgents_FullMethodName,
+	collectivev1.AgentService_GetAgent_FullMethodName,
+}
+
+// ServiceScopes are the API key scopes each service's calls need; pass
+// them to auth.Middleware.ScopeInterceptor.
+var ServiceScopes = map[string]string{
+	collectivev1.AgentService_ServiceDesc.ServiceName:  auth.ScopeAgents,
+	collectivev1.MemoryService_ServiceDesc.ServiceName: auth.ScopeMemory,
+}
+
+// tenantMetadata is the metadata key naming the tenant a call is made
+// for, the counterpart of the X-Tenant-ID header.
+var tenantMetadata = strings.ToLower(features.TenantHeader)
+
+// Tenant is a unary interceptor that adds the tenant named in a call's
+// metadata to its context, as features.Tenant does for HTTP requests.
+func Tenant(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(tenantMetadata); len(values) > 0 && values[0] != "" {
+			ctx = features.WithTenant(ctx, values[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
+// Register registers the agent and memory services on a server.
+func Register(server *grpc.Server, agentServer *AgentServer, memoryServer *MemoryServer) {
+	collectivev1.RegisterAgentServiceServer(server, agentServer)
+	collectivev1.RegisterMemoryServiceServer(server, memoryServer)
+}
+
+// statusError converts an error to a gRPC status error. Errors without a
+// kind, and internal ones, are logged and not detailed to the caller.
+func statusError(err error, action string) error {
+	code := codes.Code(errdefs.GRPCCode(err))
+	if code == codes.Unknown || code == codes.Internal {
+		log.Printf("Error %s: %v", action, err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+	return status.Error(code, err.Error())
+}
+
+// ============================================================================
+// Agent Service
+// ============================================================================
+
+// AgentServer implements collective.v1.AgentService over the registry.
+type AgentServer struct {
+	collectivev1.UnimplementedAgentServiceServer
+	registry *agents.Registry
+}
+
+// NewAgentServer creates the agent service.
+func NewAgentServer(registry *agents.Registry) *AgentServer {
+	return &AgentServer{registry: registry}
+}
+
+// ListAgents returns every registered agent.
+func (s *AgentServer) ListAgents(ctx context.Context, req *collectivev1.ListAgentsRequest) (*collectivev1.ListAgentsResponse, error) {
+	list := s.registry.List()
+	resp := &collectivev1.ListAgentsResponse{Agents: make([]*collectivev1.Agent, 0, len(list))}
+	for _, agent := range list {
+		resp.Agents = append(resp.Agents, agentMessage(agent))
+	}
+	return resp, nil
+}
+
+// GetAgent returns an agent by codename or alias.
+func (s *AgentServer) GetAgent(ctx context.Context, req *collectivev1.GetAgentRequest) (*collectivev1.GetAgentResponse, error) {
+	agent, _, err := s.registry.Resolve(req.GetCodename())
+	if err != nil {
+		return nil, statusError(err, "resolving agent")
+	}
+	return &collectivev1.GetAgentResponse{Agent: agentMessage(s.registry.Info(agent))}, nil
+}
+
+// InvokeAgent has an agent answer a conversation, admitted by the tier
+// quotas like POST /agents/{codename}/invoke.
+func (s *AgentServer) InvokeAgent(ctx context.Context, req *collectivev1.InvokeAgentRequest) (*collectivev1.InvokeAgentResponse, error) {
+	agent, res, err := s.registry.Resolve(req.GetCodename())
+	if err != nil {
+		return nil, statusError(err, "resolving agent")
+	}
+	if len(req.GetMessages()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "messages are required")
+	}
+
+	release, err := s.registry.Admit(ctx, agent)
+	if err != nil {
+		return nil, statusError(err, "admitting request")
+	}
+	defer release()
+
+	request := &models.CopilotRequest{Model: req.GetModel()}
+	for _, message := range req.GetMessages() {
+		request.Messages = append(request.Messages, models.Message{Role: message.GetRole(), Content: message.GetContent()})
+	}
+	resp, err := s.registry.Handle(ctx, agent, agents.RouteGRPC, request)
+	if err != nil {
+		return nil, statusError(err, "handling request")
+	}
+
+	out := &collectivev1.InvokeAgentResponse{Agent: res.Codename}
+	if len(resp.Choices) > 0 {
+		out.Content = resp.Choices[0].Message.Content
+		out.FinishReason = resp.Choices[0].FinishReason
+	}
+	return out, nil
+}
+
+// agentMessage converts an agent to its protobuf form.
+func agentMessage(agent models.Agent) *collectivev1.Agent {
+	msg := &collectivev1.Agent{
+		Id:            agent.ID,
+		Codename:      agent.Codename,
+		Tier:          int32(agent.Tier),
+		Name:          agent.Name,
+		Specialty:     agent.Specialty,
+		Philosophy:    agent.Philosophy,
+		Directives:    agent.Directives,
+		Keywords:      agent.Keywords,
+		Examples:      agent.Examples,
+		Collaborators: agent.Collaborators,
+		Category:      agent.Category,
+		Aliases:       agent.Aliases,
+	}
+	if lc := agent.Lifecycle; lc != nil {
+		msg.Lifecycle = &collectivev1.AgentLifecycle{
+			State:        string(lc.State),
+			DeprecatedOn: lc.DeprecatedOn,
+			RemovalDate:  lc.RemovalDate,
+			ReplacedBy:   lc.ReplacedBy,
+		}
+	}
+	return msg
+}
+
+// ============================================================================
+// Memory Service
+// ============================================================================
+
+// MemoryServer implements collective.v1.MemoryService over the knowledge
+// graph's handler.
+type MemoryServer struct {
+	collectivev1.UnimplementedMemoryServiceServer
+	handler *memory.Handler
+	warmup  *memory.Warmup
+}
+
+// NewMemoryServer creates the memory service. Calls fail with Unavailable
+// until the warmup makes the server ready; a nil warmup never gates them.
+func NewMemoryServer(handler *memory.Handler, warmup *memory.Warmup) *MemoryServer {
+	return &MemoryServer{handler: handler, warmup: warmup}
+}
+
+// ready fails calls while the knowledge graph warms up.
+func (s *MemoryServer) ready() error {
+	if s.warmup != nil && !s.warmup.Ready() {
+		return status.Error(codes.Code(errdefs.GRPCCode(memory.ErrWarmupIncomplete)), memory.ErrWarmupIncomplete.Error())
+	}
+	return nil
+}
+
+// Ask answers a natural-language question.
+func (s *MemoryServer) Ask(ctx context.Context, req *collectivev1.AskRequest) (*collectivev1.AskResponse, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetQuestion()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "question is required")
+	}
+
+	answer, err := s.handler.Answer(req.GetQuestion())
+	if err != nil {
+		return nil, statusError(err, "answering question")
+	}
+	value, err := jsonValue(answer.Value)
+	if err != nil {
+		return nil, statusError(err, "encoding answer")
+	}
+	subgraph, err := subgraphMessage(answer.Subgraph)
+	if err != nil {
+		return nil, statusError(err, "encoding answer")
+	}
+	return &collectivev1.AskResponse{
+		Question:   answer.Question,
+		Kind:       answer.Kind,
+		Answer:     answer.Answer,
+		Value:      value,
+		Confidence: answer.Confidence,
+		Entities:   answer.Entities,
+		Relation:   answer.Relation,
+		Inferred:   answer.Inferred,
+		Reasoning:  answer.Reasoning,
+		Subgraph:   subgraph,
+	}, nil
+}
+
+// Query runs a SPARQL-lite query.
+func (s *MemoryServer) Query(ctx context.Context, req *collectivev1.QueryRequest) (*collectivev1.QueryResponse, error) {
+	if err := s.ready(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetQuery()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	result, err := s.handler.RunQuery(req.GetQuery())
+	if err != nil {
+		return nil, statusError(err, "running query")
+	}
+	resp := &collectivev1.QueryResponse{
+		Vars:      result.Vars,
+		Rows:      make([]*structpb.Struct, 0, len(result.Rows)),
+		Plan:      make([]*collectivev1.PlanStep, 0, len(result.Plan)),
+		Truncated: result.Truncated,
+	}
+	for _, row := range result.Rows {
+		msg, err := jsonStruct(row)
+		if err != nil {
+			return nil, statusError(err, "encoding query result")
+		}
+		resp.Rows = append(resp.Rows, msg)
+	}
+	for _, step := range result.Plan {
+		resp.Plan = append(resp.Plan, &collectivev1.PlanStep{
+			Pattern:  step.Pattern,
+			Access:   step.Access,
+			Estimate: int32(step.Estimate),
+			Filters:  step.Filters,
+		})
+	}
+	return resp, nil
+}
+
+// subgraphMessage converts a subgraph to its protobuf form.
+func subgraphMessage(view memory.SubgraphView) (*collectivev1.Subgraph, error) {
+	msg := &collectivev1.Subgraph{
+		Nodes:     make([]*collectivev1.Node, 0, len(view.Nodes)),
+		Relations: make([]*collectivev1.Relation, 0, len(view.Relations)),
+	}
+	for _, node := range view.Nodes {
+		properties, err := jsonStruct(node.Properties)
+		if err != nil {
+			return nil, err
+		}
+		msg.Nodes = append(msg.Nodes, &collectivev1.Node{
+			Id:         node.ID,
+			Label:      node.Label,
+			Type:       node.Type,
+			Confidence: node.Confidence,
+			Properties: properties,
+		})
+	}
+	for _, rel := range view.Relations {
+		msg.Relations = append(msg.Relations, &collectivev1.Relation{
+			Id:         rel.ID,
+			Source:     rel.SourceID,
+			Target:     rel.TargetID,
+			Type:       rel.Type,
+			Weight:     rel.Weight,
+			Confidence: rel.Confidence,
+		})
+	}
+	return msg, nil
+}
+
+// jsonStruct converts a map to a Struct through its JSON form, which
+// typed property values define.
+func jsonStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	msg := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// jsonValue converts a value to a Value through its JSON form.
+func jsonValue(v interface{}) (*structpb.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	msg := &structpb.Value{}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,155 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	collectivev1 "github.com/iamthegreatdestroyer/elite-agent-collective/backend/api/collective/v1"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the agent and memory services over an in-memory listener
+// and returns a connection to them.
+func dial(t *testing.T, registry *agents.Registry, handler *memory.Handler, warmup *memory.Warmup, interceptors ...grpc.UnaryServerInterceptor) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	Register(server, NewAgentServer(registry), NewMemoryServer(handler, warmup))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// newMemoryHandler returns a handler over a two-agent ontology.
+func newMemoryHandler(t *testing.T) *memory.Handler {
+	t.Helper()
+	sn := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	err := memory.SeedAgentOntology(sn, []models.Agent{
+		{Codename: "APEX", Tier: 1, Category: "Foundational", Keywords: []string{"algorithms", "system design"}},
+		{Codename: "CIPHER", Tier: 1, Category: "Foundational", Keywords: []string{"encryption"}},
+	})
+	if err != nil {
+		t.Fatalf("SeedAgentOntology failed: %v", err)
+	}
+	return memory.NewHandler(sn)
+}
+
+func TestAgentServer(t *testing.T) {
+	registry := agents.DefaultRegistry()
+	if err := registry.SetLifecycle("ORACLE", models.AgentLifecycle{State: models.LifecycleDeprecated, DeprecatedOn: "2026-01-01", ReplacedBy: "PRISM"}); err != nil {
+		t.Fatalf("SetLifecycle failed: %v", err)
+	}
+	client := collectivev1.NewAgentServiceClient(dial(t, registry, newMemoryHandler(t), nil))
+	ctx := context.Background()
+
+	list, err := client.ListAgents(ctx, &collectivev1.ListAgentsRequest{})
+	if err != nil || len(list.GetAgents()) != len(registry.List()) {
+		t.Fatalf("expected every agent listed, got %d (%v)", len(list.GetAgents()), err)
+	}
+
+	got, err := client.GetAgent(ctx, &collectivev1.GetAgentRequest{Codename: "ORACLE"})
+	if err != nil {
+		t.Fatalf("GetAgent failed: %v", err)
+	}
+	if lc := got.GetAgent().GetLifecycle(); lc.GetState() != string(models.LifecycleDeprecated) || lc.GetReplacedBy() != "PRISM" {
+		t.Errorf("expected ORACLE's deprecation, got %v", lc)
+	}
+	if _, err := client.GetAgent(ctx, &collectivev1.GetAgentRequest{Codename: "NOBODY"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown agent, got %v", err)
+	}
+
+	resp, err := client.InvokeAgent(ctx, &collectivev1.InvokeAgentRequest{
+		Codename: "APEX",
+		Messages: []*collectivev1.Message{{Role: "user", Content: "How should I structure a Go service?"}},
+	})
+	if err != nil {
+		t.Fatalf("InvokeAgent failed: %v", err)
+	}
+	if resp.GetAgent() != "APEX" || resp.GetContent() == "" || resp.GetFinishReason() != "stop" {
+		t.Errorf("expected APEX's answer, got %v", resp)
+	}
+	if _, err := client.InvokeAgent(ctx, &collectivev1.InvokeAgentRequest{Codename: "APEX"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without messages, got %v", err)
+	}
+}
+
+func TestMemoryServer(t *testing.T) {
+	client := collectivev1.NewMemoryServiceClient(dial(t, agents.NewRegistry(), newMemoryHandler(t), nil))
+	ctx := context.Background()
+
+	answer, err := client.Ask(ctx, &collectivev1.AskRequest{Question: "What can APEX do?"})
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.GetKind() != "relation" || answer.GetRelation() != "can-do" || len(answer.GetSubgraph().GetNodes()) == 0 {
+		t.Errorf("expected APEX's can-do relations, got %v", answer)
+	}
+	if _, err := client.Ask(ctx, &collectivev1.AskRequest{Question: " "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an empty question, got %v", err)
+	}
+	if _, err := client.Ask(ctx, &collectivev1.AskRequest{Question: "What is NOBODY?"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown entity, got %v", err)
+	}
+
+	result, err := client.Query(ctx, &collectivev1.QueryRequest{
+		Query: `SELECT ?agent ?tier WHERE { ?agent type agent . ?agent @tier ?tier . FILTER(?tier = 1) }`,
+	})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.GetRows()) != 2 || result.GetRows()[0].GetFields()["tier"].GetNumberValue() != 1 || len(result.GetPlan()) != 2 {
+		t.Errorf("expected tier 1 agents and a two-step plan, got %v", result)
+	}
+	if _, err := client.Query(ctx, &collectivev1.QueryRequest{Query: "SELECT ?x WHERE { ?x frobnicates ?y }"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a syntax error, got %v", err)
+	}
+}
+
+func TestMemoryServer_Warmup(t *testing.T) {
+	warmup := memory.NewWarmup(memory.DefaultWarmupConfig())
+	client := collectivev1.NewMemoryServiceClient(dial(t, agents.NewRegistry(), newMemoryHandler(t), warmup))
+
+	request := &collectivev1.AskRequest{Question: "What can APEX do?"}
+	if _, err := client.Ask(context.Background(), request); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable while warming, got %v", err)
+	}
+	if err := warmup.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := client.Ask(context.Background(), request); err != nil {
+		t.Errorf("expected the answer once warm, got %v", err)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	var tenant string
+	handler := func(ctx context.Context, req any) (any, error) {
+		tenant = features.TenantFromContext(ctx)
+		return nil, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "acme"))
+	if _, err := Tenant(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil || tenant != "acme" {
+		t.Errorf("expected tenant acme, got %q (%v)", tenant, err)
+	}
+	if Tenant(context.Background(), nil, &grpc.UnaryServerInfo{}, handler); tenant != "" {
+		t.Errorf("expected no tenant without metadata, got %q", tenant)
+	}
+}
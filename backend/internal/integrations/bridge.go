@@ -0,0 +1,109 @@
+// Package integrations connects the collective to Slack and Microsoft Teams.
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+const (
+	// maxCommandBodyBytes bounds a command request
+	maxCommandBodyBytes = 64 << 10
+	// commandTimeout bounds an agent's answer to a command
+	commandTimeout = 2 * time.Minute
+)
+
+// commandHelp is the reply to an empty command.
+const commandHelp = "Ask an agent: `AGENT your question`, for example `ECLIPSE find test gaps in the parser`. Without an agent, %s answers."
+
+// AgentInvoker runs agents for commands; *agents.Registry implements it.
+type AgentInvoker interface {
+	Get(codename string) (models.AgentHandler, error)
+	Invoke(ctx context.Context, codename, prompt string) (string, string, error)
+}
+
+// Bridge turns Slack slash commands and Teams mentions into agent
+// invocations. Each request is verified with its workspace's signing
+// secret.
+type Bridge struct {
+	config   *Config
+	agents   AgentInvoker
+	notifier *Notifier
+	client   *http.Client
+	// now is the clock signature timestamps are checked against
+	now func() time.Time
+	// teamsReplyTimeout is how long a Teams reply waits for the agent
+	// before answering that it is still working
+	teamsReplyTimeout time.Duration
+}
+
+// NewBridge creates a bridge. The notifier posts answers that are not
+// ready in time for a Teams reply; it may be nil.
+func NewBridge(cfg *Config, invoker AgentInvoker, notifier *Notifier) *Bridge {
+	return &Bridge{
+		config:   cfg,
+		agents:   invoker,
+		notifier: notifier,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		now:               time.Now,
+		teamsReplyTimeout: 4 * time.Second,
+	}
+}
+
+// command is a parsed chat command.
+type command struct {
+	agent  string
+	prompt string
+}
+
+// parseCommand reads "AGENT prompt" from a command's text. The first word
+// names the agent if it starts with @ or is a registered codename;
+// otherwise the workspace's default agent gets the whole text. Errors are
+// meant to be shown to the user.
+func (b *Bridge) parseCommand(ws *Workspace, text string) (command, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return command{}, fmt.Errorf(commandHelp, ws.defaultAgent())
+	}
+
+	cmd := command{agent: ws.defaultAgent(), prompt: text}
+	first, rest, _ := strings.Cut(text, " ")
+	name := strings.ToUpper(strings.TrimPrefix(first, "@"))
+	if _, err := b.agents.Get(name); err == nil || strings.HasPrefix(first, "@") {
+		cmd = command{agent: name, prompt: strings.TrimSpace(rest)}
+	}
+
+	if cmd.prompt == "" {
+		return command{}, fmt.Errorf("What should %s do? Try `%s your question`.", cmd.agent, cmd.agent)
+	}
+	if !ws.allows(cmd.agent) {
+		return command{}, fmt.Errorf("%s is not enabled in this workspace.", cmd.agent)
+	}
+	return cmd, nil
+}
+
+// answer invokes a command's agent and formats its reply, or the error,
+// as chat text. bold wraps the agent's codename in the platform's markup.
+func (b *Bridge) answer(cmd command, bold string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	codename, reply, err := b.agents.Invoke(ctx, cmd.agent, cmd.prompt)
+	if err != nil {
+		log.Printf("Error answering chat command for %s: %v", cmd.agent, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s did not answer in time.", cmd.agent)
+		}
+		return "", fmt.Errorf("%s could not answer: %v", cmd.agent, err)
+	}
+	return bold + codename + bold + ": " + reply, nil
+}
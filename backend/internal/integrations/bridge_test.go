@@ -0,0 +1,103 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// fakeInvoker answers for a fixed set of agents, echoing their prompts.
+type fakeInvoker struct {
+	codenames []string
+	delay     time.Duration
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (f *fakeInvoker) Get(codename string) (models.AgentHandler, error) {
+	for _, c := range f.codenames {
+		if c == codename {
+			return nil, nil
+		}
+	}
+	return nil, errors.New("agent not found: " + codename)
+}
+
+func (f *fakeInvoker) Invoke(ctx context.Context, codename, prompt string) (string, string, error) {
+	if _, err := f.Get(codename); err != nil {
+		return "", "", err
+	}
+	f.mu.Lock()
+	f.prompts = append(f.prompts, codename+": "+prompt)
+	f.mu.Unlock()
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+	return codename, "answered " + prompt, nil
+}
+
+// invoked returns the "CODENAME: prompt" of every invocation so far.
+func (f *fakeInvoker) invoked() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
+
+func TestParseCommand(t *testing.T) {
+	bridge := NewBridge(&Config{}, &fakeInvoker{codenames: []string{"APEX", "ECLIPSE", "CIPHER"}}, nil)
+	ws := &Workspace{Platform: PlatformSlack, ID: "T1", Agents: []string{"APEX", "ECLIPSE"}}
+
+	tests := []struct {
+		text   string
+		agent  string
+		prompt string
+		err    string
+	}{
+		{"eclipse find test gaps", "ECLIPSE", "find test gaps", ""},
+		{"  @Eclipse   find gaps ", "ECLIPSE", "find gaps", ""},
+		{"why is the build slow?", "APEX", "why is the build slow?", ""},
+		{"", "", "", "Ask an agent"},
+		{"ECLIPSE", "", "", "What should ECLIPSE do?"},
+		{"cipher audit the login flow", "", "", "CIPHER is not enabled"},
+		{"@nobody hello", "", "", "NOBODY is not enabled"},
+	}
+	for _, tt := range tests {
+		cmd, err := bridge.parseCommand(ws, tt.text)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: expected error containing %q, got %v", tt.text, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected command, got %v", tt.text, err)
+			continue
+		}
+		if cmd.agent != tt.agent || cmd.prompt != tt.prompt {
+			t.Errorf("%q: expected %s %q, got %s %q", tt.text, tt.agent, tt.prompt, cmd.agent, cmd.prompt)
+		}
+	}
+}
+
+func TestBridgeAnswer(t *testing.T) {
+	bridge := NewBridge(&Config{}, &fakeInvoker{codenames: []string{"APEX"}}, nil)
+
+	text, err := bridge.answer(command{agent: "APEX", prompt: "hello"}, "*")
+	if err != nil {
+		t.Fatalf("expected answer, got %v", err)
+	}
+	if text != "*APEX*: answered hello" {
+		t.Errorf("expected bold codename and reply, got %q", text)
+	}
+	if _, err := bridge.answer(command{agent: "NOBODY", prompt: "hello"}, "*"); err == nil || !strings.Contains(err.Error(), "NOBODY could not answer") {
+		t.Errorf("expected error for an unknown agent, got %v", err)
+	}
+}
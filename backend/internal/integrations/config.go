@@ -0,0 +1,202 @@
+// Package integrations connects the collective to Slack and Microsoft Teams.
+package integrations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrInvalidConfig is returned for integration files that fail validation.
+var ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid integrations config")
+
+// Platform is a chat platform.
+type Platform string
+
+const (
+	// PlatformSlack workspaces are identified by team ID
+	PlatformSlack Platform = "slack"
+	// PlatformTeams workspaces are identified by tenant ID
+	PlatformTeams Platform = "teams"
+)
+
+// EventKind names a kind of notification.
+type EventKind string
+
+const (
+	// EventBreakthrough is a surprising success found in outcome feedback
+	EventBreakthrough EventKind = "breakthrough"
+	// EventImpasse is a detected impasse
+	EventImpasse EventKind = "impasse"
+	// EventJobCompleted is a finished workflow run
+	EventJobCompleted EventKind = "job_completed"
+	// EventAnomaly is a collective metric far above its baseline
+	EventAnomaly EventKind = "anomaly"
+)
+
+// defaultCommandAgent answers commands that don't name an agent when the
+// workspace sets no default.
+const defaultCommandAgent = "APEX"
+
+// Config lists the chat workspaces the collective posts to and takes
+// commands from.
+type Config struct {
+	Workspaces []Workspace `yaml:"workspaces"`
+}
+
+// Workspace is one Slack workspace or Teams tenant.
+type Workspace struct {
+	Platform Platform `yaml:"platform"`
+	// ID is the Slack team ID or the Teams tenant ID
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+	// SigningSecret verifies commands: the Slack app's signing secret, or
+	// the Teams outgoing webhook's base64 security token. Empty disables
+	// commands
+	SigningSecret string `yaml:"signing_secret"`
+	// WebhookURL is the incoming webhook notifications are posted to.
+	// Empty disables notifications
+	WebhookURL string `yaml:"webhook_url"`
+	// Notify lists the event kinds posted; empty posts every kind
+	Notify []EventKind `yaml:"notify"`
+	// Agents restricts the agents commands can invoke; empty allows all
+	Agents []string `yaml:"agents"`
+	// DefaultAgent answers commands that don't name an agent
+	DefaultAgent string `yaml:"default_agent"`
+}
+
+// LoadConfig reads and validates an integrations file. Secrets and
+// webhook URLs may refer to environment variables as $NAME or ${NAME}, so
+// they need not be stored in the file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integrations config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes and validates an integrations config. Unknown
+// fields are errors, so misspelled keys are caught at load time.
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse integrations YAML: %w", err)
+	}
+
+	var problems []error
+	seen := make(map[string]bool)
+	for i := range cfg.Workspaces {
+		ws := &cfg.Workspaces[i]
+		ws.SigningSecret = os.ExpandEnv(ws.SigningSecret)
+		ws.WebhookURL = os.ExpandEnv(ws.WebhookURL)
+		ws.DefaultAgent = strings.ToUpper(ws.DefaultAgent)
+		for j, agent := range ws.Agents {
+			ws.Agents[j] = strings.ToUpper(agent)
+		}
+
+		for _, err := range ws.validate() {
+			problems = append(problems, fmt.Errorf("workspace %d (%s %s): %w", i, ws.Platform, ws.ID, err))
+		}
+		key := string(ws.Platform) + "/" + ws.ID
+		if seen[key] {
+			problems = append(problems, fmt.Errorf("workspace %d: %s %s is duplicated", i, ws.Platform, ws.ID))
+		}
+		seen[key] = true
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return &cfg, nil
+}
+
+// validate returns a workspace's problems.
+func (ws *Workspace) validate() []error {
+	var problems []error
+	switch ws.Platform {
+	case PlatformSlack, PlatformTeams:
+	default:
+		problems = append(problems, fmt.Errorf("unknown platform %q", ws.Platform))
+	}
+	if ws.ID == "" {
+		problems = append(problems, errors.New("id is required"))
+	}
+	if ws.SigningSecret == "" && ws.WebhookURL == "" {
+		problems = append(problems, errors.New("a signing secret or webhook URL is required"))
+	}
+	if ws.Platform == PlatformTeams && ws.SigningSecret != "" {
+		if _, err := base64.StdEncoding.DecodeString(ws.SigningSecret); err != nil {
+			problems = append(problems, errors.New("Teams signing secret must be base64"))
+		}
+	}
+	if ws.WebhookURL != "" {
+		if u, err := url.Parse(ws.WebhookURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			problems = append(problems, fmt.Errorf("webhook URL %q is not an HTTP URL", ws.WebhookURL))
+		}
+	}
+	for _, kind := range ws.Notify {
+		switch kind {
+		case EventBreakthrough, EventImpasse, EventJobCompleted, EventAnomaly:
+		default:
+			problems = append(problems, fmt.Errorf("unknown event kind %q", kind))
+		}
+	}
+	return problems
+}
+
+// workspace returns the workspace with a platform and ID, or nil.
+func (c *Config) workspace(platform Platform, id string) *Workspace {
+	for i := range c.Workspaces {
+		if c.Workspaces[i].Platform == platform && c.Workspaces[i].ID == id {
+			return &c.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// notifies reports whether events of a kind are posted to the workspace.
+func (ws *Workspace) notifies(kind EventKind) bool {
+	if ws.WebhookURL == "" {
+		return false
+	}
+	if len(ws.Notify) == 0 {
+		return true
+	}
+	for _, k := range ws.Notify {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether commands in the workspace can invoke an agent.
+func (ws *Workspace) allows(codename string) bool {
+	if len(ws.Agents) == 0 {
+		return true
+	}
+	for _, agent := range ws.Agents {
+		if agent == codename {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultAgent returns the agent answering commands that name none.
+func (ws *Workspace) defaultAgent() string {
+	if ws.DefaultAgent != "" {
+		return ws.DefaultAgent
+	}
+	return defaultCommandAgent
+}
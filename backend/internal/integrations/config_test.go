@@ -0,0 +1,110 @@
+package integrations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfigYAML = `
+workspaces:
+  - platform: slack
+    id: T0001
+    name: Elite Labs
+    signing_secret: ${TEST_SLACK_SECRET}
+    webhook_url: https://hooks.slack.example/services/T0001
+    notify: [breakthrough, job_completed]
+    agents: [eclipse, apex]
+    default_agent: eclipse
+  - platform: teams
+    id: tenant-1
+    signing_secret: c2VjcmV0LXRva2Vu
+`
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv("TEST_SLACK_SECRET", "slack-secret")
+	cfg, err := ParseConfig([]byte(testConfigYAML))
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	if len(cfg.Workspaces) != 2 {
+		t.Fatalf("expected 2 workspaces, got %d", len(cfg.Workspaces))
+	}
+
+	slack := cfg.workspace(PlatformSlack, "T0001")
+	if slack == nil {
+		t.Fatal("expected Slack workspace T0001")
+	}
+	if slack.SigningSecret != "slack-secret" {
+		t.Errorf("expected signing secret from the environment, got %q", slack.SigningSecret)
+	}
+	if slack.defaultAgent() != "ECLIPSE" || !slack.allows("APEX") || slack.allows("CIPHER") {
+		t.Errorf("expected upper-cased agents, got default %s and %v", slack.defaultAgent(), slack.Agents)
+	}
+	if !slack.notifies(EventBreakthrough) || slack.notifies(EventImpasse) {
+		t.Error("expected only subscribed events to be posted")
+	}
+
+	teams := cfg.workspace(PlatformTeams, "tenant-1")
+	if teams == nil {
+		t.Fatal("expected Teams workspace tenant-1")
+	}
+	if teams.notifies(EventBreakthrough) {
+		t.Error("expected no notifications without a webhook URL")
+	}
+	if teams.defaultAgent() != defaultCommandAgent || !teams.allows("CIPHER") {
+		t.Error("expected default agent and every agent allowed")
+	}
+	if cfg.workspace(PlatformSlack, "tenant-1") != nil {
+		t.Error("expected workspaces to be looked up by platform and ID")
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown platform", "workspaces: [{platform: discord, id: a, webhook_url: https://x.example}]", "unknown platform"},
+		{"no id", "workspaces: [{platform: slack, webhook_url: https://x.example}]", "id is required"},
+		{"no secret or webhook", "workspaces: [{platform: slack, id: T1}]", "signing secret or webhook URL"},
+		{"bad webhook", "workspaces: [{platform: slack, id: T1, webhook_url: 'ftp://x'}]", "not an HTTP URL"},
+		{"bad teams secret", "workspaces: [{platform: teams, id: t, signing_secret: 'not base64!'}]", "must be base64"},
+		{"unknown event", "workspaces: [{platform: slack, id: T1, webhook_url: https://x.example, notify: [deploy]}]", "unknown event kind"},
+		{"duplicate", "workspaces: [{platform: slack, id: T1, signing_secret: s}, {platform: slack, id: T1, signing_secret: s}]", "duplicated"},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.yaml))
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error to mention %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := ParseConfig([]byte("workspaces: [{platform: slack, id: T1, secret: s}]")); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "integrations.yaml")
+	if err := os.WriteFile(path, []byte("workspaces: [{platform: slack, id: T1, signing_secret: s}]"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected config to load, got %v", err)
+	}
+	if len(cfg.Workspaces) != 1 {
+		t.Errorf("expected 1 workspace, got %d", len(cfg.Workspaces))
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -0,0 +1,303 @@
+// Package integrations connects the collective to Slack and Microsoft Teams.
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+const (
+	// notifyQueueSize bounds the deliveries waiting to be posted; events
+	// beyond it are dropped rather than blocking their source
+	notifyQueueSize = 256
+	// maxSlackFields is the most fields Slack shows in a section block
+	maxSlackFields = 10
+)
+
+// Event is a notification posted to chat.
+type Event struct {
+	Kind  EventKind
+	Title string
+	Text  string
+	// Fields are shown as label and value pairs
+	Fields []Field
+}
+
+// Field is a labelled value shown with an event.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// delivery is a payload waiting to be posted to a workspace's webhook.
+type delivery struct {
+	workspace *Workspace
+	payload   []byte
+}
+
+// Notifier posts events to the incoming webhooks of the workspaces that
+// subscribe to them. Posting happens in the background, so Notify can be
+// called from callbacks that must not block.
+type Notifier struct {
+	config *Config
+	client *http.Client
+
+	queue  chan delivery
+	done   chan struct{}
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewNotifier creates a notifier and starts its delivery worker.
+func NewNotifier(cfg *Config) *Notifier {
+	n := &Notifier{
+		config: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		queue: make(chan delivery, notifyQueueSize),
+		done:  make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues an event for every workspace subscribed to its kind.
+func (n *Notifier) Notify(event Event) {
+	for i := range n.config.Workspaces {
+		ws := &n.config.Workspaces[i]
+		if !ws.notifies(event.Kind) {
+			continue
+		}
+		payload, err := json.Marshal(formatEvent(ws.Platform, event))
+		if err != nil {
+			log.Printf("Error encoding %s notification: %v", event.Kind, err)
+			continue
+		}
+		n.enqueue(delivery{workspace: ws, payload: payload})
+	}
+}
+
+// post queues a message for a workspace's webhook, whatever events it
+// subscribes to.
+func (n *Notifier) post(ws *Workspace, message interface{}) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error encoding message for %s %s: %v", ws.Platform, ws.ID, err)
+		return
+	}
+	n.enqueue(delivery{workspace: ws, payload: payload})
+}
+
+// enqueue queues a delivery, dropping it if the queue is full or the
+// notifier is closed.
+func (n *Notifier) enqueue(d delivery) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.queue <- d:
+	default:
+		log.Printf("Notification queue full, dropping message for %s %s", d.workspace.Platform, d.workspace.ID)
+	}
+}
+
+// Close stops accepting events and waits for queued ones to be posted.
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	<-n.done
+}
+
+// run posts queued deliveries until the queue is closed.
+func (n *Notifier) run() {
+	defer close(n.done)
+	for d := range n.queue {
+		if err := n.deliver(d); err != nil {
+			log.Printf("Error posting to %s %s: %v", d.workspace.Platform, d.workspace.ID, err)
+		}
+	}
+}
+
+// deliver posts one payload to its workspace's webhook.
+func (n *Notifier) deliver(d delivery) error {
+	resp, err := n.client.Post(d.workspace.WebhookURL, "application/json", bytes.NewReader(d.payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ============================================================================
+// Events
+// ============================================================================
+
+// BreakthroughEvent describes a surprising success.
+func BreakthroughEvent(e memory.SurpriseEvent) Event {
+	fields := []Field{
+		{Name: "Agents", Value: strings.Join(e.Agents, ", ")},
+		{Name: "Surprise", Value: fmt.Sprintf("%.1fσ", e.SurpriseScore)},
+	}
+	if e.Strategy != "" {
+		fields = append(fields, Field{Name: "Strategy", Value: e.Strategy})
+	}
+	return Event{
+		Kind:   EventBreakthrough,
+		Title:  "Breakthrough on " + e.TaskType,
+		Text:   fmt.Sprintf("%s did far better than expected on a %s task.", strings.Join(e.Agents, " + "), e.TaskType),
+		Fields: fields,
+	}
+}
+
+// ImpasseEvent describes a detected impasse.
+func ImpasseEvent(imp *memory.Impasse) Event {
+	fields := []Field{
+		{Name: "Type", Value: imp.Type.String()},
+		{Name: "Severity", Value: fmt.Sprintf("%.2f", imp.Severity)},
+	}
+	if imp.GoalID != "" {
+		fields = append(fields, Field{Name: "Goal", Value: imp.GoalID})
+	}
+	if imp.FailedAgent != "" {
+		fields = append(fields, Field{Name: "Agent", Value: imp.FailedAgent})
+	}
+	if len(imp.Candidates) > 0 {
+		fields = append(fields, Field{Name: "Candidates", Value: strings.Join(imp.Candidates, ", ")})
+	}
+	return Event{
+		Kind:   EventImpasse,
+		Title:  "Impasse: " + imp.Type.String(),
+		Text:   imp.Description,
+		Fields: fields,
+	}
+}
+
+// AnomalyEvent describes a collective metric far above its baseline.
+func AnomalyEvent(a *memory.Anomaly) Event {
+	fields := []Field{
+		{Name: "Metric", Value: a.Metric},
+		{Name: "Value", Value: fmt.Sprintf("%.3g", a.Value)},
+		{Name: "Expected", Value: fmt.Sprintf("%.3g", a.Expected)},
+		{Name: "Score", Value: fmt.Sprintf("%.1fσ", a.Score)},
+	}
+	title := "Anomaly: " + a.Metric
+	if a.Agent != "" {
+		fields = append(fields, Field{Name: "Agent", Value: a.Agent})
+		title += " of " + a.Agent
+	}
+	return Event{
+		Kind:   EventAnomaly,
+		Title:  title,
+		Text:   a.Description(),
+		Fields: fields,
+	}
+}
+
+// RunEvent describes a finished workflow run.
+func RunEvent(run *agents.WorkflowRun) Event {
+	text := run.Output
+	for _, step := range run.Steps {
+		if step.Status == agents.StepFailed {
+			text = fmt.Sprintf("Step %s failed: %s", step.ID, step.Error)
+			break
+		}
+	}
+	fields := []Field{
+		{Name: "Run", Value: run.ID},
+		{Name: "Duration", Value: (time.Duration(run.DurationMs) * time.Millisecond).String()},
+	}
+	if run.Compensation != "" {
+		fields = append(fields, Field{Name: "Compensation", Value: string(run.Compensation)})
+	}
+	return Event{
+		Kind:   EventJobCompleted,
+		Title:  fmt.Sprintf("Workflow %s %s", run.Workflow, run.Status),
+		Text:   text,
+		Fields: fields,
+	}
+}
+
+// ============================================================================
+// Payloads
+// ============================================================================
+
+// formatEvent builds the webhook payload for an event on a platform.
+func formatEvent(platform Platform, event Event) interface{} {
+	if platform == PlatformTeams {
+		return teamsCard(event)
+	}
+	return slackMessage(event)
+}
+
+// slackMessage formats an event as Slack blocks, with plain text for
+// notifications.
+func slackMessage(event Event) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": map[string]string{"type": "plain_text", "text": event.Title}},
+	}
+	if event.Text != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": event.Text},
+		})
+	}
+	if len(event.Fields) > 0 {
+		var fields []map[string]string
+		for i, field := range event.Fields {
+			if i == maxSlackFields {
+				break
+			}
+			fields = append(fields, map[string]string{"type": "mrkdwn", "text": "*" + field.Name + "*\n" + field.Value})
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+	return map[string]interface{}{"text": event.Title, "blocks": blocks}
+}
+
+// teamsCard formats an event as an Adaptive Card message.
+func teamsCard(event Event) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": event.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+	}
+	if event.Text != "" {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": event.Text, "wrap": true})
+	}
+	if len(event.Fields) > 0 {
+		var facts []map[string]string
+		for _, field := range event.Fields {
+			facts = append(facts, map[string]string{"title": field.Name, "value": field.Value})
+		}
+		body = append(body, map[string]interface{}{"type": "FactSet", "facts": facts})
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
@@ -0,0 +1,147 @@
+package integrations
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// webhookRecorder is an incoming webhook that records the payloads posted
+// to it.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+// newWebhookRecorder starts a recording webhook server.
+func newWebhookRecorder(t *testing.T) (*webhookRecorder, *httptest.Server) {
+	t.Helper()
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("expected JSON payload, got %s", body)
+		}
+		recorder.mu.Lock()
+		recorder.payloads = append(recorder.payloads, payload)
+		recorder.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return recorder, server
+}
+
+// received returns the payloads posted so far.
+func (wr *webhookRecorder) received() []map[string]interface{} {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return append([]map[string]interface{}(nil), wr.payloads...)
+}
+
+// waitFor waits until at least n payloads have been posted.
+func (wr *webhookRecorder) waitFor(t *testing.T, n int) []map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if payloads := wr.received(); len(payloads) >= n {
+			return payloads
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d webhook posts, got %d", n, len(wr.received()))
+	return nil
+}
+
+func TestNotifierNotify(t *testing.T) {
+	slackHook, slackServer := newWebhookRecorder(t)
+	teamsHook, teamsServer := newWebhookRecorder(t)
+	notifier := NewNotifier(&Config{Workspaces: []Workspace{
+		{Platform: PlatformSlack, ID: "T1", WebhookURL: slackServer.URL, Notify: []EventKind{EventBreakthrough}},
+		{Platform: PlatformTeams, ID: "tenant", WebhookURL: teamsServer.URL},
+	}})
+
+	notifier.Notify(BreakthroughEvent(memory.SurpriseEvent{Agents: []string{"APEX", "ECLIPSE"}, TaskType: "testing", SurpriseScore: 2.5}))
+	notifier.Notify(Event{Kind: EventImpasse, Title: "Impasse: tie"})
+	notifier.Close()
+
+	slack := slackHook.received()
+	if len(slack) != 1 {
+		t.Fatalf("expected only the subscribed event posted to Slack, got %d posts", len(slack))
+	}
+	if slack[0]["text"] != "Breakthrough on testing" {
+		t.Errorf("expected Slack fallback text, got %v", slack[0]["text"])
+	}
+	blocks, _ := slack[0]["blocks"].([]interface{})
+	if len(blocks) != 3 {
+		t.Errorf("expected header, text and fields blocks, got %v", slack[0]["blocks"])
+	}
+
+	teams := teamsHook.received()
+	if len(teams) != 2 {
+		t.Fatalf("expected both events posted to Teams, got %d posts", len(teams))
+	}
+	encoded, _ := json.Marshal(teams[0])
+	for _, want := range []string{`"type":"message"`, "application/vnd.microsoft.card.adaptive", `"AdaptiveCard"`, "APEX + ECLIPSE"} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("expected Teams card to contain %s, got %s", want, encoded)
+		}
+	}
+
+	// Events after Close are dropped
+	notifier.Notify(Event{Kind: EventImpasse, Title: "late"})
+	if len(teamsHook.received()) != 2 {
+		t.Error("expected no posts after Close")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	impasse := ImpasseEvent(&memory.Impasse{
+		Type:        memory.ImpasseTie,
+		Description: "APEX and ARCHITECT scored equally",
+		Candidates:  []string{"APEX", "ARCHITECT"},
+		Severity:    0.5,
+	})
+	if impasse.Kind != EventImpasse || impasse.Text != "APEX and ARCHITECT scored equally" || len(impasse.Fields) != 3 {
+		t.Errorf("expected impasse with description and candidates, got %+v", impasse)
+	}
+
+	anomaly := AnomalyEvent(&memory.Anomaly{
+		Metric:   memory.MetricErrorRate,
+		Agent:    "APEX",
+		Value:    0.4,
+		Expected: 0.02,
+		Score:    7.6,
+	})
+	if anomaly.Kind != EventAnomaly || anomaly.Title != "Anomaly: error_rate of APEX" || len(anomaly.Fields) != 5 {
+		t.Errorf("expected agent anomaly with its baseline, got %+v", anomaly)
+	}
+
+	run := RunEvent(&agents.WorkflowRun{
+		ID:         "run-1",
+		Workflow:   "review",
+		Status:     agents.StepFailed,
+		DurationMs: 1500,
+		Steps: []agents.StepResult{
+			{ID: "analyze", Status: agents.StepSucceeded},
+			{ID: "review", Status: agents.StepFailed, Error: "agent timed out"},
+		},
+		Compensation: agents.StepSucceeded,
+	})
+	if run.Kind != EventJobCompleted || run.Title != "Workflow review failed" {
+		t.Errorf("expected failed run title, got %q", run.Title)
+	}
+	if run.Text != "Step review failed: agent timed out" {
+		t.Errorf("expected failed step in text, got %q", run.Text)
+	}
+	if len(run.Fields) != 3 || run.Fields[1].Value != "1.5s" {
+		t.Errorf("expected run, duration and compensation fields, got %+v", run.Fields)
+	}
+}
@@ -0,0 +1,131 @@
+// Package integrations connects the collective to Slack and Microsoft Teams.
+package integrations
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// maxSlackRequestAge is how old a signed Slack request may be, which
+// limits replays.
+const maxSlackRequestAge = 5 * time.Minute
+
+// slackReply is a slash command response.
+type slackReply struct {
+	// ResponseType is ephemeral (only the user sees it) or in_channel
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// ServeSlackCommand handles POST /integrations/slack/commands - a slash
+// command such as "/elite ECLIPSE find test gaps". Slack wants a reply
+// within three seconds, so the command is acknowledged at once and the
+// agent's answer is posted to the command's response_url.
+func (b *Bridge) ServeSlackCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ws := b.config.workspace(PlatformSlack, form.Get("team_id"))
+	if ws == nil || ws.SigningSecret == "" {
+		http.Error(w, "Unknown workspace", http.StatusUnauthorized)
+		return
+	}
+	err = verifySlackSignature(ws.SigningSecret, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, b.now())
+	if err != nil {
+		log.Printf("Slack signature verification failed for %s: %v", ws.ID, err)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	cmd, err := b.parseCommand(ws, form.Get("text"))
+	if err != nil {
+		errdefs.WriteJSON(w, http.StatusOK, slackReply{ResponseType: "ephemeral", Text: err.Error()})
+		return
+	}
+	responseURL := form.Get("response_url")
+	if responseURL == "" {
+		http.Error(w, "response_url is required", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Slack command from %s: invoking agent %s", ws.ID, cmd.agent)
+	go b.answerSlack(cmd, responseURL, form.Get("user_id"))
+	errdefs.WriteJSON(w, http.StatusOK, slackReply{ResponseType: "ephemeral", Text: "Asking " + cmd.agent + "…"})
+}
+
+// answerSlack invokes a command's agent and posts the answer to the
+// command's response_url: in the channel on success, to the user alone
+// on error.
+func (b *Bridge) answerSlack(cmd command, responseURL, userID string) {
+	reply := slackReply{ResponseType: "ephemeral"}
+	text, err := b.answer(cmd, "*")
+	if err != nil {
+		reply.Text = err.Error()
+	} else {
+		reply.ResponseType = "in_channel"
+		reply.Text = text
+		if userID != "" {
+			reply.Text = "<@" + userID + "> asked: " + cmd.prompt + "\n" + text
+		}
+	}
+
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("Error encoding Slack answer: %v", err)
+		return
+	}
+	resp, err := b.client.Post(responseURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("Error posting Slack answer: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Slack response_url returned status %d", resp.StatusCode)
+	}
+}
+
+// verifySlackSignature checks a request's X-Slack-Signature, the
+// HMAC-SHA256 of "v0:timestamp:body" keyed with the signing secret, and
+// that the timestamp is recent.
+func verifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return errors.New("signature headers are required")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxSlackRequestAge || age < -maxSlackRequestAge {
+		return errors.New("timestamp is too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
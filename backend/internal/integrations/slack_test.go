@@ -0,0 +1,131 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSlackSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signSlack signs a Slack request body as Slack does.
+func signSlack(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newSlackCommand builds a signed slash command request.
+func newSlackCommand(teamID, text, responseURL string, now time.Time) *http.Request {
+	body := url.Values{
+		"team_id":      {teamID},
+		"user_id":      {"U42"},
+		"command":      {"/elite"},
+		"text":         {text},
+		"response_url": {responseURL},
+	}.Encode()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req := httptest.NewRequest("POST", "/integrations/slack/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", signSlack(testSlackSecret, timestamp, body))
+	return req
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("team_id=T1&text=hello")
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := signSlack(testSlackSecret, timestamp, string(body))
+
+	if err := verifySlackSignature(testSlackSecret, timestamp, signature, body, now); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+	if err := verifySlackSignature("other-secret", timestamp, signature, body, now); err == nil {
+		t.Error("expected error for a different secret")
+	}
+	if err := verifySlackSignature(testSlackSecret, timestamp, signature, []byte("team_id=T1&text=tampered"), now); err == nil {
+		t.Error("expected error for a tampered body")
+	}
+	if err := verifySlackSignature(testSlackSecret, timestamp, signature, body, now.Add(10*time.Minute)); err == nil {
+		t.Error("expected error for a replayed request")
+	}
+	if err := verifySlackSignature(testSlackSecret, "", "", body, now); err == nil {
+		t.Error("expected error without signature headers")
+	}
+}
+
+func TestServeSlackCommand(t *testing.T) {
+	responses, responseServer := newWebhookRecorder(t)
+	invoker := &fakeInvoker{codenames: []string{"APEX", "ECLIPSE"}}
+	bridge := NewBridge(&Config{Workspaces: []Workspace{
+		{Platform: PlatformSlack, ID: "T1", SigningSecret: testSlackSecret},
+	}}, invoker, nil)
+	now := time.Now()
+
+	w := httptest.NewRecorder()
+	bridge.ServeSlackCommand(w, newSlackCommand("T1", "eclipse find test gaps", responseServer.URL, now))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ack slackReply
+	if err := json.NewDecoder(w.Body).Decode(&ack); err != nil {
+		t.Fatalf("failed to decode acknowledgement: %v", err)
+	}
+	if ack.ResponseType != "ephemeral" || !strings.Contains(ack.Text, "ECLIPSE") {
+		t.Errorf("expected ephemeral acknowledgement naming the agent, got %+v", ack)
+	}
+
+	answer := responses.waitFor(t, 1)[0]
+	if answer["response_type"] != "in_channel" {
+		t.Errorf("expected answer in channel, got %v", answer["response_type"])
+	}
+	if text, _ := answer["text"].(string); !strings.Contains(text, "<@U42> asked: find test gaps") || !strings.Contains(text, "*ECLIPSE*: answered find test gaps") {
+		t.Errorf("expected attributed answer, got %q", text)
+	}
+
+	// Commands that can't run are answered at once
+	w = httptest.NewRecorder()
+	bridge.ServeSlackCommand(w, newSlackCommand("T1", "", responseServer.URL, now))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Ask an agent") {
+		t.Errorf("expected help for an empty command, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(invoker.invoked()) != 1 {
+		t.Errorf("expected one invocation, got %v", invoker.invoked())
+	}
+}
+
+func TestServeSlackCommandRejected(t *testing.T) {
+	bridge := NewBridge(&Config{Workspaces: []Workspace{
+		{Platform: PlatformSlack, ID: "T1", SigningSecret: testSlackSecret},
+	}}, &fakeInvoker{codenames: []string{"APEX"}}, nil)
+	now := time.Now()
+
+	w := httptest.NewRecorder()
+	bridge.ServeSlackCommand(w, newSlackCommand("T2", "apex hello", "http://localhost", now))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an unknown workspace, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	bridge.ServeSlackCommand(w, newSlackCommand("T1", "apex hello", "http://localhost", now.Add(-time.Hour)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a stale request, got %d", w.Code)
+	}
+
+	req := newSlackCommand("T1", "apex hello", "http://localhost", now)
+	req.Header.Set("X-Slack-Signature", "v0=00")
+	w = httptest.NewRecorder()
+	bridge.ServeSlackCommand(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a bad signature, got %d", w.Code)
+	}
+}
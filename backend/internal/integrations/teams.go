@@ -0,0 +1,132 @@
+// Package integrations connects the collective to Slack and Microsoft Teams.
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// mentionPattern matches the mention of the outgoing webhook that
+	// starts every Teams message it receives
+	mentionPattern = regexp.MustCompile(`(?s)<at>.*?</at>`)
+	// tagPattern matches the remaining HTML in a Teams message
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// teamsActivity is the part of a Teams outgoing webhook message the
+// bridge reads.
+type teamsActivity struct {
+	Text        string `json:"text"`
+	ChannelData struct {
+		Tenant struct {
+			ID string `json:"id"`
+		} `json:"tenant"`
+	} `json:"channelData"`
+}
+
+// teamsReply is an outgoing webhook response.
+type teamsReply struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ServeTeamsCommand handles POST /integrations/teams/messages - a message
+// mentioning the workspace's outgoing webhook, such as "@Elite ECLIPSE find
+// test gaps". Teams wants a reply within five seconds; answers that take
+// longer are posted to the workspace's incoming webhook when it has one.
+func (b *Bridge) ServeTeamsCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var activity teamsActivity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ws := b.config.workspace(PlatformTeams, activity.ChannelData.Tenant.ID)
+	if ws == nil || ws.SigningSecret == "" {
+		http.Error(w, "Unknown workspace", http.StatusUnauthorized)
+		return
+	}
+	if err := verifyTeamsSignature(ws.SigningSecret, r.Header.Get("Authorization"), body); err != nil {
+		log.Printf("Teams signature verification failed for %s: %v", ws.ID, err)
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	cmd, err := b.parseCommand(ws, teamsText(activity.Text))
+	if err != nil {
+		errdefs.WriteJSON(w, http.StatusOK, teamsReply{Type: "message", Text: err.Error()})
+		return
+	}
+
+	log.Printf("Teams command from %s: invoking agent %s", ws.ID, cmd.agent)
+	answers := make(chan string, 1)
+	go func() {
+		text, err := b.answer(cmd, "**")
+		if err != nil {
+			text = err.Error()
+		}
+		answers <- text
+	}()
+
+	select {
+	case text := <-answers:
+		errdefs.WriteJSON(w, http.StatusOK, teamsReply{Type: "message", Text: text})
+	case <-time.After(b.teamsReplyTimeout):
+		if ws.WebhookURL == "" || b.notifier == nil {
+			errdefs.WriteJSON(w, http.StatusOK, teamsReply{Type: "message", Text: cmd.agent + " is still working and can't post its answer later in this channel."})
+			return
+		}
+		go func() {
+			b.notifier.post(ws, teamsReply{Type: "message", Text: <-answers})
+		}()
+		errdefs.WriteJSON(w, http.StatusOK, teamsReply{Type: "message", Text: cmd.agent + " is working on it; the answer will be posted here."})
+	}
+}
+
+// teamsText returns the command in a Teams message: its text without the
+// webhook mention and HTML.
+func teamsText(text string) string {
+	text = mentionPattern.ReplaceAllString(text, "")
+	text = tagPattern.ReplaceAllString(text, " ")
+	// Fields also splits on the non-breaking spaces Teams sends
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// verifyTeamsSignature checks a request's "Authorization: HMAC signature"
+// header, the base64 HMAC-SHA256 of the body keyed with the outgoing
+// webhook's decoded security token.
+func verifyTeamsSignature(secret, authorization string, body []byte) error {
+	scheme, signature, ok := strings.Cut(authorization, " ")
+	if !ok || scheme != "HMAC" {
+		return errors.New("HMAC authorization is required")
+	}
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return errors.New("invalid security token")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
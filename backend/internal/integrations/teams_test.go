@@ -0,0 +1,99 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTeamsToken is an outgoing webhook security token, base64 encoded
+var testTeamsToken = base64.StdEncoding.EncodeToString([]byte("teams-security-token"))
+
+// newTeamsMessage builds a signed outgoing webhook message.
+func newTeamsMessage(tenant, text, token string) *http.Request {
+	encoded, _ := json.Marshal(text)
+	body := fmt.Sprintf(`{"type":"message","text":%s,"channelData":{"tenant":{"id":%q}}}`, encoded, tenant)
+	key, _ := base64.StdEncoding.DecodeString(token)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	req := httptest.NewRequest("POST", "/integrations/teams/messages", strings.NewReader(body))
+	req.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// decodeTeamsReply decodes an outgoing webhook response.
+func decodeTeamsReply(t *testing.T, w *httptest.ResponseRecorder) teamsReply {
+	t.Helper()
+	var reply teamsReply
+	if err := json.NewDecoder(w.Body).Decode(&reply); err != nil {
+		t.Fatalf("failed to decode reply: %v", err)
+	}
+	return reply
+}
+
+func TestTeamsText(t *testing.T) {
+	got := teamsText("<at>Elite</at>&nbsp;ECLIPSE <p>find  gaps in <b>parser</b></p>\n")
+	if got != "ECLIPSE find gaps in parser" {
+		t.Errorf("expected mention and markup removed, got %q", got)
+	}
+}
+
+func TestServeTeamsCommand(t *testing.T) {
+	invoker := &fakeInvoker{codenames: []string{"APEX", "ECLIPSE"}}
+	bridge := NewBridge(&Config{Workspaces: []Workspace{
+		{Platform: PlatformTeams, ID: "tenant-1", SigningSecret: testTeamsToken},
+	}}, invoker, nil)
+
+	w := httptest.NewRecorder()
+	bridge.ServeTeamsCommand(w, newTeamsMessage("tenant-1", "<at>Elite</at> eclipse find test gaps", testTeamsToken))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	reply := decodeTeamsReply(t, w)
+	if reply.Type != "message" || reply.Text != "**ECLIPSE**: answered find test gaps" {
+		t.Errorf("expected the agent's answer, got %+v", reply)
+	}
+
+	w = httptest.NewRecorder()
+	bridge.ServeTeamsCommand(w, newTeamsMessage("tenant-1", "hello", "b3RoZXItdG9rZW4="))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a bad signature, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	bridge.ServeTeamsCommand(w, newTeamsMessage("tenant-2", "hello", testTeamsToken))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an unknown tenant, got %d", w.Code)
+	}
+	if len(invoker.invoked()) != 1 {
+		t.Errorf("expected one invocation, got %v", invoker.invoked())
+	}
+}
+
+func TestServeTeamsCommandSlowAnswer(t *testing.T) {
+	webhook, server := newWebhookRecorder(t)
+	cfg := &Config{Workspaces: []Workspace{
+		{Platform: PlatformTeams, ID: "tenant-1", SigningSecret: testTeamsToken, WebhookURL: server.URL},
+	}}
+	notifier := NewNotifier(cfg)
+	defer notifier.Close()
+	bridge := NewBridge(cfg, &fakeInvoker{codenames: []string{"APEX"}, delay: 200 * time.Millisecond}, notifier)
+	bridge.teamsReplyTimeout = 20 * time.Millisecond
+
+	w := httptest.NewRecorder()
+	bridge.ServeTeamsCommand(w, newTeamsMessage("tenant-1", "<at>Elite</at> summarize the incident", testTeamsToken))
+	if reply := decodeTeamsReply(t, w); !strings.Contains(reply.Text, "APEX is working on it") {
+		t.Errorf("expected a holding reply, got %q", reply.Text)
+	}
+
+	posted := webhook.waitFor(t, 1)[0]
+	if posted["text"] != "**APEX**: answered summarize the incident" {
+		t.Errorf("expected late answer posted to the webhook, got %v", posted)
+	}
+}
@@ -0,0 +1,141 @@
+// Package intent labels incoming queries with what the user wants done:
+// generate code, review it, explain something, plan work, or research a
+// topic. Keyword rules decide clear cases; an optional embedding model
+// decides the rest.
+package intent
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Intent is what a query asks for.
+type Intent string
+
+// Intents.
+const (
+	CodeGen  Intent = "code-gen"
+	Review   Intent = "review"
+	Explain  Intent = "explain"
+	Plan     Intent = "plan"
+	Research Intent = "research"
+	// General is a query no intent was recognized in
+	General Intent = "general"
+)
+
+// Intents returns the recognized intents, excluding General.
+func Intents() []Intent {
+	return []Intent{CodeGen, Review, Explain, Plan, Research}
+}
+
+// Classification sources.
+const (
+	SourceRules = "rules"
+	SourceModel = "model"
+	SourceNone  = "none"
+)
+
+// Result is a query's intent and how it was decided.
+type Result struct {
+	Intent Intent `json:"intent"`
+	// Confidence is in [0, 1]
+	Confidence float64 `json:"confidence"`
+	// Source is rules, model, or none for General
+	Source string `json:"source"`
+}
+
+// rule adds weight to an intent when its pattern matches a query.
+type rule struct {
+	intent  Intent
+	pattern *regexp.Regexp
+	weight  float64
+}
+
+// rules are matched against the lower-cased query. Verbs that open the
+// query weigh most, since they usually state the request.
+var rules = []rule{
+	{CodeGen, regexp.MustCompile(`^(write|implement|create|generate|build|add|code)\b`), 2},
+	{CodeGen, regexp.MustCompile(`\b(implement|generate|scaffold|boilerplate|write (a|an|the|some) (function|class|script|test|program|method))\b`), 1},
+	{Review, regexp.MustCompile(`^(review|audit|critique|check)\b`), 2},
+	{Review, regexp.MustCompile(`\b(review|code review|audit|critique|what'?s wrong with|any (bugs|issues)|feedback on|pull request|pr)\b`), 1},
+	{Explain, regexp.MustCompile(`^(explain|describe|what is|what are|what does|how does|how do|why does|why is|why do)\b`), 2},
+	{Explain, regexp.MustCompile(`\b(explain|walk me through|help me understand|meaning of|difference between)\b`), 1},
+	{Plan, regexp.MustCompile(`^(plan|design|outline|roadmap|break down|architect)\b`), 2},
+	{Plan, regexp.MustCompile(`\b(plan|roadmap|milestones?|migration strategy|step[- ]by[- ]step|break (it|this) down|phases?)\b`), 1},
+	{Research, regexp.MustCompile(`^(research|survey|investigate|compare|find)\b`), 2},
+	{Research, regexp.MustCompile(`\b(research|state of the art|literature|papers?|survey|alternatives to|compare|comparison|trade-?offs)\b`), 1},
+}
+
+// minRuleScore is the least score rules must reach to decide alone.
+const minRuleScore = 2
+
+// scoreRules returns each intent's rule score for a query.
+func scoreRules(query string) map[Intent]float64 {
+	query = strings.ToLower(strings.TrimSpace(query))
+	// Agent mentions are routing, not intent
+	query = strings.TrimSpace(mentionPattern.ReplaceAllString(query, ""))
+	scores := make(map[Intent]float64)
+	for _, r := range rules {
+		if r.pattern.MatchString(query) {
+			scores[r.intent] += r.weight
+		}
+	}
+	return scores
+}
+
+// mentionPattern matches @AGENT mentions.
+var mentionPattern = regexp.MustCompile(`@[a-z0-9_-]+`)
+
+// Classifier labels queries. It is safe for concurrent use.
+type Classifier struct {
+	model atomic.Pointer[Model]
+}
+
+// NewClassifier creates a classifier that uses rules alone until a model
+// is set.
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
+
+// SetModel sets the model consulted when rules are inconclusive.
+func (c *Classifier) SetModel(model *Model) {
+	c.model.Store(model)
+}
+
+// Classify returns a query's intent. Rules decide when one intent clearly
+// leads; otherwise the model, if set, decides; otherwise the best rule
+// match wins, or General if none matched.
+func (c *Classifier) Classify(ctx context.Context, query string) Result {
+	scores := scoreRules(query)
+	ranked := make([]Intent, 0, len(scores))
+	total := 0.0
+	for intent, score := range scores {
+		ranked = append(ranked, intent)
+		total += score
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	decided := len(ranked) > 0 && scores[ranked[0]] >= minRuleScore &&
+		(len(ranked) == 1 || scores[ranked[0]] > scores[ranked[1]])
+	if decided {
+		return Result{Intent: ranked[0], Confidence: scores[ranked[0]] / total, Source: SourceRules}
+	}
+
+	if model := c.model.Load(); model != nil {
+		if result, ok := model.classify(ctx, query); ok {
+			return result
+		}
+	}
+	if len(ranked) > 0 {
+		return Result{Intent: ranked[0], Confidence: scores[ranked[0]] / total, Source: SourceRules}
+	}
+	return Result{Intent: General, Source: SourceNone}
+}
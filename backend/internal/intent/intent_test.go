@@ -0,0 +1,65 @@
+package intent
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClassify_Rules(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Intent
+	}{
+		{"Write a function that reverses a linked list", CodeGen},
+		{"@APEX implement retry with backoff for this client", CodeGen},
+		{"Review this pull request for race conditions", Review},
+		{"what's wrong with this handler? any bugs?", Review},
+		{"Explain how the Raft leader election works", Explain},
+		{"How does TLS certificate pinning work?", Explain},
+		{"Plan the migration to Postgres in phases", Plan},
+		{"Compare gRPC and REST trade-offs", Research},
+		{"Survey the literature on CRDTs", Research},
+		{"hello there", General},
+	}
+	c := NewClassifier()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := c.Classify(context.Background(), tt.query)
+			if got.Intent != tt.want {
+				t.Errorf("expected %s, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClassify_Sources(t *testing.T) {
+	c := NewClassifier()
+	if got := c.Classify(context.Background(), "write a test for the parser"); got.Source != SourceRules || got.Confidence != 1 {
+		t.Errorf("expected a confident rules decision, got %+v", got)
+	}
+	if got := c.Classify(context.Background(), "hello"); got.Source != SourceNone || got.Confidence != 0 {
+		t.Errorf("expected no decision, got %+v", got)
+	}
+	// A single weak match is not decisive, but wins without a model
+	if got := c.Classify(context.Background(), "the roadmap for next quarter"); got.Intent != Plan || got.Source != SourceRules {
+		t.Errorf("expected the weak plan match to win, got %+v", got)
+	}
+}
+
+func TestClassify_ModelDecidesInconclusiveQueries(t *testing.T) {
+	c := NewClassifier()
+	model, err := NewModel(context.Background(), testEmbedder(), DefaultMinSimilarity)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	c.SetModel(model)
+
+	// No rule matches; the model recognizes the vocabulary of research
+	if got := c.Classify(context.Background(), "kafka versus pulsar for streaming"); got.Intent != Research || got.Source != SourceModel {
+		t.Errorf("expected research from the model, got %+v", got)
+	}
+	// Clear rule winners never reach the model
+	if got := c.Classify(context.Background(), "explain kafka streaming"); got.Intent != Explain || got.Source != SourceRules {
+		t.Errorf("expected explain from the rules, got %+v", got)
+	}
+}
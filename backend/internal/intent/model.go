@@ -0,0 +1,149 @@
+package intent
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Embedder embeds texts in batches; embeddings.Embedder satisfies it.
+type Embedder interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// examples are sample queries of each intent. Their embeddings, averaged,
+// are the intent's prototype.
+var examples = map[Intent][]string{
+	CodeGen: {
+		"write a function that parses ISO dates",
+		"implement a thread-safe LRU cache in Go",
+		"generate a REST handler for creating users",
+		"create a script that renames files in bulk",
+	},
+	Review: {
+		"review this pull request for bugs",
+		"is there anything wrong with this code",
+		"audit this handler for security issues",
+		"give me feedback on my implementation",
+	},
+	Explain: {
+		"explain how garbage collection works",
+		"what does this regular expression match",
+		"why is my query slow",
+		"help me understand the difference between TCP and UDP",
+	},
+	Plan: {
+		"plan the migration from MySQL to Postgres",
+		"outline the steps to launch the new API",
+		"design a rollout for the feature across teams",
+		"break this epic down into milestones",
+	},
+	Research: {
+		"survey the state of the art in vector databases",
+		"compare Kafka and Pulsar for event streaming",
+		"find papers on retrieval augmented generation",
+		"what are the alternatives to Redis for caching",
+	},
+}
+
+// DefaultMinSimilarity is the least similarity to a prototype the model
+// accepts.
+const DefaultMinSimilarity = 0.3
+
+// Model classifies queries by the nearest intent prototype in embedding
+// space. It is immutable once built and safe for concurrent use.
+type Model struct {
+	embedder      Embedder
+	prototypes    map[Intent][]float64
+	minSimilarity float64
+}
+
+// NewModel embeds the example queries of every intent and averages them
+// into prototypes. Queries less similar than minSimilarity to every
+// prototype are left to the rules.
+func NewModel(ctx context.Context, embedder Embedder, minSimilarity float64) (*Model, error) {
+	var texts []string
+	var labels []Intent
+	for _, intent := range Intents() {
+		for _, example := range examples[intent] {
+			texts = append(texts, example)
+			labels = append(labels, intent)
+		}
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding intent examples: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding intent examples: got %d vectors for %d examples", len(vectors), len(texts))
+	}
+
+	m := &Model{embedder: embedder, prototypes: make(map[Intent][]float64), minSimilarity: minSimilarity}
+	for i, vector := range vectors {
+		prototype := m.prototypes[labels[i]]
+		if prototype == nil {
+			prototype = make([]float64, len(vector))
+			m.prototypes[labels[i]] = prototype
+		}
+		for j, v := range normalize(vector) {
+			prototype[j] += v
+		}
+	}
+	return m, nil
+}
+
+// classify returns the intent of the nearest prototype, or false if none
+// is similar enough or the query cannot be embedded.
+func (m *Model) classify(ctx context.Context, query string) (Result, bool) {
+	vectors, err := m.embedder.Embed(ctx, []string{query})
+	if err != nil || len(vectors) != 1 {
+		return Result{}, false
+	}
+	embedded := normalize(vectors[0])
+
+	best, bestSimilarity := General, math.Inf(-1)
+	for _, intent := range Intents() {
+		if similarity := cosine(embedded, m.prototypes[intent]); similarity > bestSimilarity {
+			best, bestSimilarity = intent, similarity
+		}
+	}
+	if bestSimilarity < m.minSimilarity {
+		return Result{}, false
+	}
+	return Result{Intent: best, Confidence: bestSimilarity, Source: SourceModel}, true
+}
+
+// normalize returns a vector scaled to unit length.
+func normalize(v []float32) []float64 {
+	norm := 0.0
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	norm = math.Sqrt(norm)
+	out := make([]float64, len(v))
+	if norm == 0 {
+		return out
+	}
+	for i, x := range v {
+		out[i] = float64(x) / norm
+	}
+	return out
+}
+
+// cosine returns the cosine similarity of two vectors.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
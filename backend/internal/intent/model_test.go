@@ -0,0 +1,69 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// wordEmbedder embeds text as counts over a fixed vocabulary, so texts
+// sharing words are similar.
+type wordEmbedder struct {
+	vocabulary []string
+	err        error
+}
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(e.vocabulary))
+		for j, word := range e.vocabulary {
+			vectors[i][j] = float32(strings.Count(strings.ToLower(text), word))
+		}
+	}
+	return vectors, nil
+}
+
+// testEmbedder returns an embedder whose vocabulary tells the example
+// queries of each intent apart.
+func testEmbedder() *wordEmbedder {
+	return &wordEmbedder{vocabulary: []string{"function", "implement", "review", "wrong", "explain", "why", "plan", "steps", "kafka", "pulsar", "papers"}}
+}
+
+func TestModel_Classify(t *testing.T) {
+	model, err := NewModel(context.Background(), testEmbedder(), DefaultMinSimilarity)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+
+	got, ok := model.classify(context.Background(), "what is wrong here, please review")
+	if !ok || got.Intent != Review || got.Source != SourceModel || got.Confidence <= DefaultMinSimilarity {
+		t.Errorf("expected review from the model, got %+v (%v)", got, ok)
+	}
+
+	// Nothing in the vocabulary: similar to no prototype
+	if got, ok := model.classify(context.Background(), "good morning"); ok {
+		t.Errorf("expected no decision, got %+v", got)
+	}
+}
+
+func TestModel_EmbedderErrors(t *testing.T) {
+	failing := &wordEmbedder{err: errors.New("model unavailable")}
+	if _, err := NewModel(context.Background(), failing, DefaultMinSimilarity); err == nil {
+		t.Error("expected error when examples cannot be embedded")
+	}
+
+	embedder := testEmbedder()
+	model, err := NewModel(context.Background(), embedder, DefaultMinSimilarity)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	embedder.err = errors.New("model unavailable")
+	if got, ok := model.classify(context.Background(), "review this"); ok {
+		t.Errorf("expected no decision when the query cannot be embedded, got %+v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package intent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrInvalidConfig is returned for template files that fail validation.
+var ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid intent templates config")
+
+// Config sets the prompt template of each intent.
+type Config struct {
+	// Templates maps an intent to a text/template that rewrites the query
+	// of that intent before it reaches the agent
+	Templates map[string]string `yaml:"templates"`
+}
+
+// TemplateData is what a prompt template is executed with.
+type TemplateData struct {
+	// Query is the user's message as sent
+	Query string
+	// Agent is the codename of the agent answering
+	Agent  string
+	Intent Intent
+}
+
+// LoadConfig reads and validates an intent templates file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read intent templates config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes and validates an intent templates config. Every
+// template is parsed and executed once, so unknown intents, syntax errors
+// and unknown fields are caught at load time.
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse intent templates YAML: %w", err)
+	}
+
+	var problems []error
+	for _, name := range sortedKeys(cfg.Templates) {
+		if !known(Intent(name)) {
+			problems = append(problems, fmt.Errorf("intent %s is not defined", name))
+			continue
+		}
+		tmpl, err := template.New(name).Parse(cfg.Templates[name])
+		if err != nil {
+			problems = append(problems, fmt.Errorf("intent %s: %w", name, err))
+			continue
+		}
+		if err := tmpl.Execute(&strings.Builder{}, TemplateData{}); err != nil {
+			problems = append(problems, fmt.Errorf("intent %s: %w", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return &cfg, nil
+}
+
+// known reports whether an intent may have a template; General may, for
+// queries no intent was recognized in.
+func known(intent Intent) bool {
+	if intent == General {
+		return true
+	}
+	for _, i := range Intents() {
+		if i == intent {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order, so problems are reported
+// deterministically.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Templates rewrites queries by their intent. It is immutable and safe for
+// concurrent use.
+type Templates struct {
+	templates map[Intent]*template.Template
+}
+
+// NewTemplates creates the templates of a validated config; a nil cfg has
+// none.
+func NewTemplates(cfg *Config) *Templates {
+	t := &Templates{templates: make(map[Intent]*template.Template)}
+	if cfg == nil {
+		return t
+	}
+	for name, text := range cfg.Templates {
+		t.templates[Intent(name)] = template.Must(template.New(name).Parse(text))
+	}
+	return t
+}
+
+// Apply rewrites a query with its intent's template. It returns false if
+// the intent has no template.
+func (t *Templates) Apply(data TemplateData) (string, bool, error) {
+	tmpl, ok := t.templates[data.Intent]
+	if !ok {
+		return "", false, nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", false, fmt.Errorf("applying %s template: %w", data.Intent, err)
+	}
+	return b.String(), true, nil
+}
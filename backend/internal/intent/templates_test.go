@@ -0,0 +1,67 @@
+package intent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+templates:
+  review: "Review as {{.Agent}}, listing issues by severity:\n{{.Query}}"
+  general: "{{.Query}}"
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if len(cfg.Templates) != 2 {
+		t.Errorf("expected 2 templates, got %d", len(cfg.Templates))
+	}
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown intent", "templates:\n  chat: \"{{.Query}}\"\n", "intent chat is not defined"},
+		{"syntax error", "templates:\n  plan: \"{{.Query\"\n", "intent plan"},
+		{"unknown field", "templates:\n  plan: \"{{.Tenant}}\"\n", "intent plan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.yaml))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("expected ErrInvalidConfig, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error to contain %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := ParseConfig([]byte("prompts: {}\n")); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+func TestTemplates_Apply(t *testing.T) {
+	cfg, err := ParseConfig([]byte("templates:\n  review: \"[{{.Intent}} by {{.Agent}}] {{.Query}}\"\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	templates := NewTemplates(cfg)
+
+	got, ok, err := templates.Apply(TemplateData{Query: "check this diff", Agent: "APEX", Intent: Review})
+	if err != nil || !ok || got != "[review by APEX] check this diff" {
+		t.Errorf("expected the review template applied, got %q (%v, %v)", got, ok, err)
+	}
+	if _, ok, err := templates.Apply(TemplateData{Query: "hi", Intent: General}); ok || err != nil {
+		t.Errorf("expected no template for general, got %v and %v", ok, err)
+	}
+	if _, ok, _ := NewTemplates(nil).Apply(TemplateData{Intent: Review}); ok {
+		t.Error("expected no templates without a config")
+	}
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// anthropicBaseURL is the Anthropic API's default URL
+	anthropicBaseURL = "https://api.anthropic.com"
+	// anthropicVersion is the Messages API version requests are made with
+	anthropicVersion = "2023-06-01"
+)
+
+// anthropic calls the Anthropic Messages API. Anthropic offers no
+// embedding model, so Embed always fails.
+type anthropic struct {
+	config Config
+	client *http.Client
+}
+
+func newAnthropic(cfg Config, client *http.Client) *anthropic {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = anthropicBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &anthropic{config: cfg, client: client}
+}
+
+// Name returns anthropic.
+func (a *anthropic) Name() string {
+	return ProviderAnthropic
+}
+
+// anthropicRequest is the body of POST /v1/messages.
+type anthropicRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+// anthropicUsage is the token usage of a message.
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse is a message.
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+// anthropicEvent is a streamed event; which fields are set depends on its
+// type.
+type anthropicEvent struct {
+	Type    string             `json:"type"`
+	Message *anthropicResponse `json:"message"`
+	Delta   struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// messagesRequest builds the body of a message request.
+func (a *anthropic) messagesRequest(req *Request, stream bool) *anthropicRequest {
+	system, messages := split(req)
+	body := &anthropicRequest{
+		Model:       req.Model,
+		System:      system,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+	if body.Model == "" {
+		body.Model = a.config.Model
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = a.config.MaxTokens
+	}
+	// Some models refuse both temperature and top-p; a top-p of one
+	// samples from every token anyway
+	if req.TopP != nil && *req.TopP < 1 {
+		body.TopP = req.TopP
+	}
+	return body
+}
+
+// Complete calls POST /v1/messages.
+func (a *anthropic) Complete(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := a.post(ctx, a.messagesRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var message anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode Anthropic response: %v", ErrProvider, err)
+	}
+	var content strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	return &Response{
+		Model:        message.Model,
+		Content:      content.String(),
+		FinishReason: anthropicFinishReason(message.StopReason),
+		Usage:        Usage(message.Usage),
+	}, nil
+}
+
+// Stream calls POST /v1/messages with stream set, reading text deltas
+// until the message_stop event.
+func (a *anthropic) Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error) {
+	resp, err := a.post(ctx, a.messagesRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	out := &Response{FinishReason: FinishStop}
+	var content strings.Builder
+	err = readEvents(resp.Body, func(_, data string) (bool, error) {
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("%w: failed to decode Anthropic event: %v", ErrProvider, err)
+		}
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				out.Model = event.Message.Model
+				out.Usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				return false, nil
+			}
+			content.WriteString(event.Delta.Text)
+			if err := onDelta(event.Delta.Text); err != nil {
+				return false, err
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				out.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+			}
+			if event.Usage != nil {
+				out.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			return true, nil
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("%w: Anthropic stream failed: %s: %s", ErrProvider, event.Error.Type, event.Error.Message)
+			}
+			return false, fmt.Errorf("%w: Anthropic stream failed", ErrProvider)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Content = content.String()
+	return out, nil
+}
+
+// Embed fails: Anthropic has no embedding model.
+func (a *anthropic) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrEmbeddingsUnsupported
+}
+
+// post sends a message request, returning the response if it succeeded.
+func (a *anthropic) post(ctx context.Context, body *anthropicRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.BaseURL+"/v1/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", a.config.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	if err := checkStatus("Anthropic", resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// anthropicFinishReason maps Anthropic's stop reasons onto our finish
+// reasons.
+func anthropicFinishReason(reason string) string {
+	if reason == "max_tokens" {
+		return FinishLength
+	}
+	return FinishStop
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAnthropicTest serves handler as the Anthropic API.
+func newAnthropicTest(t *testing.T, handler http.HandlerFunc) Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	provider, err := New(Config{Provider: ProviderAnthropic, APIKey: "key-test", BaseURL: server.URL, Model: "claude-test"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return provider
+}
+
+func TestAnthropic_Complete(t *testing.T) {
+	var got anthropicRequest
+	provider := newAnthropicTest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key-test" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("unexpected request %s with headers %v", r.URL.Path, r.Header)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"model":"claude-test","content":[{"type":"text","text":"Hel"},{"type":"text","text":"lo"}],"stop_reason":"max_tokens","usage":{"input_tokens":9,"output_tokens":2}}`)
+	})
+
+	temperature, topP := 0.4, 1.0
+	resp, err := provider.Complete(context.Background(), &Request{
+		System:      "You are CIPHER.",
+		Messages:    []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi"}},
+		Temperature: &temperature,
+		TopP:        &topP,
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != FinishLength || resp.Usage != (Usage{9, 2}) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if got.System != "You are CIPHER.\n\nBe brief." || len(got.Messages) != 1 || got.MaxTokens != DefaultMaxTokens || got.Model != "claude-test" {
+		t.Errorf("expected the system prompt apart and the default max tokens, got %+v", got)
+	}
+	if got.Temperature == nil || *got.Temperature != 0.4 || got.TopP != nil {
+		t.Errorf("expected the temperature sent and a top-p of one left out, got %+v", got)
+	}
+	topP = 0.9
+	if body := provider.(*anthropic).messagesRequest(&Request{TopP: &topP}, false); body.TopP == nil || *body.TopP != 0.9 {
+		t.Errorf("expected a top-p below one sent, got %+v", body)
+	}
+}
+
+func TestAnthropic_Stream(t *testing.T) {
+	provider := newAnthropicTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []struct{ name, data string }{
+			{"message_start", `{"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":7}}}`},
+			{"ping", `{"type":"ping"}`},
+			{"content_block_delta", `{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hel"}}`},
+			{"content_block_delta", `{"type":"content_block_delta","delta":{"type":"text_delta","text":"lo"}}`},
+			{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`},
+			{"message_stop", `{"type":"message_stop"}`},
+		} {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+		}
+	})
+
+	var deltas []string
+	resp, err := provider.Stream(context.Background(), &Request{Messages: []Message{{Role: "user", Content: "Hi"}}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(deltas) != 2 || resp.Content != "Hello" || resp.FinishReason != FinishStop || resp.Usage != (Usage{7, 2}) || resp.Model != "claude-test" {
+		t.Errorf("unexpected stream %v -> %+v", deltas, resp)
+	}
+}
+
+func TestAnthropic_StreamError(t *testing.T) {
+	provider := newAnthropicTest(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+	_, err := provider.Stream(context.Background(), &Request{}, func(string) error { return nil })
+	if !errors.Is(err, ErrProvider) || !contains(err.Error(), "overloaded_error") {
+		t.Errorf("expected the stream's error, got %v", err)
+	}
+	if _, err := provider.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrEmbeddingsUnsupported) {
+		t.Errorf("expected ErrEmbeddingsUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"context"
+)
+
+// Embedder embeds text through a provider. It satisfies
+// embeddings.Embedder, so provider embeddings are cached, and used, wherever
+// local ones are.
+type Embedder struct {
+	provider Provider
+	model    string
+}
+
+// NewEmbedder creates an embedder for a provider configured with
+// embedding model model.
+func NewEmbedder(provider Provider, model string) *Embedder {
+	return &Embedder{provider: provider, model: model}
+}
+
+// Model names the provider and its model, e.g. openai/text-embedding-3-small.
+func (e *Embedder) Model() string {
+	return e.provider.Name() + "/" + e.model
+}
+
+// Embed returns the provider's vectors.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.provider.Embed(ctx, texts)
+}
@@ -0,0 +1,234 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outcomes of completions that were due a hedge.
+const (
+	// HedgePrimary is a hedged completion the primary returned first
+	HedgePrimary = "primary"
+	// HedgeFallback is a hedged completion the fallback returned first
+	HedgeFallback = "fallback"
+	// HedgeFailed is a hedged completion both providers failed
+	HedgeFailed = "failed"
+	// HedgeCapped is a completion left unhedged because the hedging rate
+	// was at its cap
+	HedgeCapped = "capped"
+)
+
+const (
+	// hedgeWindow is how many of the primary's latest latencies the
+	// percentile is taken of
+	hedgeWindow = 200
+	// hedgeMinSamples is how many latencies are needed before their
+	// percentile replaces the configured delay
+	hedgeMinSamples = 20
+	// hedgeBurst is how many hedges may be sent back to back, however low
+	// the rate cap
+	hedgeBurst = 10
+)
+
+// HedgeConfig configures hedged completions.
+type HedgeConfig struct {
+	// Percentile of the primary's recent latencies after which the
+	// fallback is called, e.g. 95
+	Percentile float64
+	// Delay is how long completions wait before hedging until enough
+	// latencies are known to take the percentile of
+	Delay time.Duration
+	// MaxRate caps the share of completions that are hedged, from 0 to 1
+	MaxRate float64
+}
+
+// Hedged completes conversations with a primary provider, hedged with a
+// fallback: when the primary has not answered within the configured
+// percentile of its recent latencies, the fallback is called too, the
+// first completion returned is used and the other call is canceled. The
+// share of completions hedged is capped, so a slow primary does not double
+// the load on both. Streams and embeddings go to the primary alone. It is
+// safe for concurrent use.
+type Hedged struct {
+	primary  Provider
+	fallback Provider
+	config   HedgeConfig
+
+	mu sync.Mutex
+	// latencies are the primary's latest latencies, a ring of up to
+	// hedgeWindow; next is where the next one goes
+	latencies []time.Duration
+	next      int
+	// budget is how many hedges may be sent now: each completion adds
+	// MaxRate, up to hedgeBurst, and each hedge takes one. It starts full
+	// unless hedging is capped at zero
+	budget float64
+
+	// onHedge is called with the outcome of each completion due a hedge
+	onHedge func(outcome string)
+}
+
+// NewHedged creates a provider hedging primary's completions with
+// fallback's.
+func NewHedged(primary, fallback Provider, config HedgeConfig) *Hedged {
+	h := &Hedged{primary: primary, fallback: fallback, config: config}
+	if config.MaxRate > 0 {
+		h.budget = hedgeBurst
+	}
+	return h
+}
+
+// OnHedge sets a callback for each completion that was due a hedge, called
+// with its outcome: HedgePrimary, HedgeFallback, HedgeFailed or
+// HedgeCapped. Set before the provider is shared between goroutines.
+func (h *Hedged) OnHedge(fn func(outcome string)) {
+	h.onHedge = fn
+}
+
+// Name returns the primary's name.
+func (h *Hedged) Name() string {
+	return h.primary.Name()
+}
+
+// completion is the outcome of one provider's call.
+type completion struct {
+	resp     *Response
+	err      error
+	fallback bool
+}
+
+// Complete returns the first completion of the conversation returned by
+// the primary or, once the primary is slow, by the fallback. When both
+// fail the primary's error is returned.
+func (h *Hedged) Complete(ctx context.Context, req *Request) (*Response, error) {
+	h.earn()
+	start := time.Now()
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	results := make(chan completion, 2)
+	go func() {
+		resp, err := h.primary.Complete(primaryCtx, req)
+		results <- completion{resp: resp, err: err}
+	}()
+
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+	select {
+	case result := <-results:
+		h.observe(time.Since(start), result.err)
+		return result.resp, result.err
+	case <-timer.C:
+	}
+	if !h.spend() {
+		h.report(HedgeCapped)
+		result := <-results
+		h.observe(time.Since(start), result.err)
+		return result.resp, result.err
+	}
+
+	// The primary's model is not the fallback's to answer with
+	fallbackReq := *req
+	fallbackReq.Model = ""
+	fallbackCtx, cancelFallback := context.WithCancel(ctx)
+	defer cancelFallback()
+	go func() {
+		resp, err := h.fallback.Complete(fallbackCtx, &fallbackReq)
+		results <- completion{resp: resp, err: err, fallback: true}
+	}()
+
+	var primaryErr error
+	for pending := 2; pending > 0; pending-- {
+		result := <-results
+		if !result.fallback {
+			h.observe(time.Since(start), result.err)
+			primaryErr = result.err
+		}
+		if result.err != nil {
+			continue
+		}
+		if result.fallback {
+			// The primary, canceled while still running, is known only
+			// to take at least this long
+			if pending == 2 {
+				h.observe(time.Since(start), nil)
+			}
+			h.report(HedgeFallback)
+		} else {
+			h.report(HedgePrimary)
+		}
+		return result.resp, nil
+	}
+	h.report(HedgeFailed)
+	return nil, primaryErr
+}
+
+// Stream streams the primary's completion; deltas already sent can't be
+// taken back, so streams are not hedged.
+func (h *Hedged) Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error) {
+	return h.primary.Stream(ctx, req, onDelta)
+}
+
+// Embed embeds with the primary.
+func (h *Hedged) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return h.primary.Embed(ctx, texts)
+}
+
+// delay returns how long a completion waits on the primary before
+// hedging: the configured percentile of its latest latencies, or the
+// configured delay while too few are known.
+func (h *Hedged) delay() time.Duration {
+	h.mu.Lock()
+	if len(h.latencies) < hedgeMinSamples {
+		h.mu.Unlock()
+		return h.config.Delay
+	}
+	sorted := append([]time.Duration(nil), h.latencies...)
+	h.mu.Unlock()
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(h.config.Percentile/100*float64(len(sorted)))) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+// observe records a latency of the primary's. Failures are not, so a
+// primary failing fast does not make hedging eager.
+func (h *Hedged) observe(latency time.Duration, err error) {
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < hedgeWindow {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % hedgeWindow
+}
+
+// earn adds a completion's share of hedges to the budget.
+func (h *Hedged) earn() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.budget = math.Min(hedgeBurst, h.budget+h.config.MaxRate)
+}
+
+// spend takes a hedge from the budget, reporting whether there was one.
+func (h *Hedged) spend() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.budget < 1 {
+		return false
+	}
+	h.budget--
+	return true
+}
+
+// report calls the OnHedge callback.
+func (h *Hedged) report(outcome string) {
+	if h.onHedge != nil {
+		h.onHedge(outcome)
+	}
+}
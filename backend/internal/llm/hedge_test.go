@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowProvider answers with its name after a delay, or fails, recording
+// whether its calls were canceled and the models they asked for.
+type slowProvider struct {
+	name  string
+	delay time.Duration
+	fail  bool
+
+	mu       sync.Mutex
+	calls    int
+	canceled int
+	models   []string
+}
+
+func (p *slowProvider) Name() string { return p.name }
+
+func (p *slowProvider) Complete(ctx context.Context, req *Request) (*Response, error) {
+	p.mu.Lock()
+	p.calls++
+	p.models = append(p.models, req.Model)
+	p.mu.Unlock()
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.canceled++
+		p.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	if p.fail {
+		return nil, errors.New(p.name + " failed")
+	}
+	return &Response{Content: p.name, FinishReason: FinishStop}, nil
+}
+
+func (p *slowProvider) Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error) {
+	return p.Complete(ctx, req)
+}
+
+func (p *slowProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrEmbeddingsUnsupported
+}
+
+// stats returns the provider's calls and canceled calls.
+func (p *slowProvider) stats() (calls, canceled int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls, p.canceled
+}
+
+// recordHedges returns a callback recording outcomes, and the outcomes.
+func recordHedges() (func(string), func() []string) {
+	var mu sync.Mutex
+	var outcomes []string
+	return func(outcome string) {
+			mu.Lock()
+			defer mu.Unlock()
+			outcomes = append(outcomes, outcome)
+		}, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), outcomes...)
+		}
+}
+
+func TestHedged(t *testing.T) {
+	req := &Request{Model: "primary-model", Messages: []Message{{Role: "user", Content: "hi"}}}
+	config := HedgeConfig{Percentile: 95, Delay: 20 * time.Millisecond, MaxRate: 1}
+
+	// A fast primary is never hedged
+	primary := &slowProvider{name: "primary"}
+	fallback := &slowProvider{name: "fallback"}
+	hedged := NewHedged(primary, fallback, config)
+	record, outcomes := recordHedges()
+	hedged.OnHedge(record)
+	if resp, err := hedged.Complete(context.Background(), req); err != nil || resp.Content != "primary" {
+		t.Fatalf("expected the primary's answer, got %+v (%v)", resp, err)
+	}
+	if calls, _ := fallback.stats(); calls != 0 || len(outcomes()) != 0 {
+		t.Errorf("expected no hedge, got %d fallback calls and %v", calls, outcomes())
+	}
+
+	// A slow primary is raced and canceled when the fallback wins
+	primary.delay = time.Second
+	if resp, err := hedged.Complete(context.Background(), req); err != nil || resp.Content != "fallback" {
+		t.Fatalf("expected the fallback's answer, got %+v (%v)", resp, err)
+	}
+	if fallback.models[0] != "" {
+		t.Errorf("expected the fallback asked for its own model, got %q", fallback.models[0])
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, canceled := primary.stats(); canceled != 1 {
+		t.Errorf("expected the losing primary canceled, got %d", canceled)
+	}
+
+	// A fallback that fails leaves the primary's answer
+	fallback.fail = true
+	primary.delay = 50 * time.Millisecond
+	if resp, err := hedged.Complete(context.Background(), req); err != nil || resp.Content != "primary" {
+		t.Fatalf("expected the primary's answer after the fallback failed, got %+v (%v)", resp, err)
+	}
+
+	// Both failing returns the primary's error
+	primary.fail = true
+	if _, err := hedged.Complete(context.Background(), req); err == nil || err.Error() != "primary failed" {
+		t.Errorf("expected the primary's error, got %v", err)
+	}
+	want := []string{HedgeFallback, HedgePrimary, HedgeFailed}
+	if got := outcomes(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("expected outcomes %v, got %v", want, got)
+	}
+}
+
+func TestHedgedRateCap(t *testing.T) {
+	primary := &slowProvider{name: "primary", delay: 30 * time.Millisecond}
+	fallback := &slowProvider{name: "fallback", delay: time.Second}
+	hedged := NewHedged(primary, fallback, HedgeConfig{Percentile: 95, Delay: time.Millisecond, MaxRate: 0.05})
+	record, outcomes := recordHedges()
+	hedged.OnHedge(record)
+
+	for i := 0; i < hedgeBurst+2; i++ {
+		if resp, err := hedged.Complete(context.Background(), &Request{}); err != nil || resp.Content != "primary" {
+			t.Fatalf("expected the primary's answer, got %+v (%v)", resp, err)
+		}
+	}
+	capped := 0
+	for _, outcome := range outcomes() {
+		if outcome == HedgeCapped {
+			capped++
+		}
+	}
+	if calls, _ := fallback.stats(); calls != hedgeBurst || capped != 2 {
+		t.Errorf("expected %d hedges then 2 capped, got %d hedges and %v", hedgeBurst, calls, outcomes())
+	}
+
+	off := NewHedged(primary, fallback, HedgeConfig{Percentile: 95, Delay: time.Millisecond})
+	off.Complete(context.Background(), &Request{})
+	if calls, _ := fallback.stats(); calls != hedgeBurst {
+		t.Errorf("expected no hedge with a zero rate, got %d", calls-hedgeBurst)
+	}
+}
+
+func TestHedgedDelay(t *testing.T) {
+	hedged := NewHedged(&slowProvider{}, &slowProvider{}, HedgeConfig{Percentile: 90, Delay: time.Second, MaxRate: 1})
+	for i := 1; i < hedgeMinSamples; i++ {
+		hedged.observe(time.Duration(i)*time.Millisecond, nil)
+	}
+	if got := hedged.delay(); got != time.Second {
+		t.Errorf("expected the configured delay with too few latencies, got %s", got)
+	}
+	hedged.observe(time.Duration(hedgeMinSamples)*time.Millisecond, nil)
+	hedged.observe(time.Hour, errors.New("failed"))
+	if got := hedged.delay(); got != 18*time.Millisecond {
+		t.Errorf("expected the 90th percentile of 1-20ms, got %s", got)
+	}
+	for i := 0; i < hedgeWindow; i++ {
+		hedged.observe(time.Minute, nil)
+	}
+	if got := hedged.delay(); got != time.Minute {
+		t.Errorf("expected older latencies to leave the window, got %s", got)
+	}
+}
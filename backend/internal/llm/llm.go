@@ -0,0 +1,191 @@
+// Package llm calls large language models. A Provider completes
+// conversations, streams completions and embeds text with one vendor's
+// API; New selects the provider a configuration names, and agents answer
+// through it with their persona as the system prompt.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+var (
+	// ErrProvider is returned when a provider's API fails or rejects a
+	// request
+	ErrProvider = errdefs.New(errdefs.ErrProviderFailure, "language model provider error")
+	// ErrEmbeddingsUnsupported is returned by providers, or configurations,
+	// without an embedding model
+	ErrEmbeddingsUnsupported = errdefs.New(errdefs.ErrUnavailable, "provider has no embedding model")
+	// ErrInvalidConfig is returned for configurations New can't build a
+	// provider from
+	ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid language model config")
+)
+
+// Provider names accepted by New.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderStub      = "stub"
+)
+
+// Finish reasons, in the OpenAI vocabulary Copilot responses use.
+const (
+	FinishStop   = "stop"
+	FinishLength = "length"
+)
+
+const (
+	// DefaultMaxTokens bounds a completion when the request does not
+	DefaultMaxTokens = 1024
+	// DefaultTimeout bounds a call to a provider's API
+	DefaultTimeout = 60 * time.Second
+)
+
+// Message is one turn of a conversation.
+type Message struct {
+	// Role is system, user or assistant
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a conversation to complete.
+type Request struct {
+	// Model overrides the provider's configured model
+	Model string
+	// System is the system prompt; system messages in Messages are
+	// appended to it
+	System   string
+	Messages []Message
+	// MaxTokens bounds the completion; zero uses the provider's default
+	MaxTokens int
+	// Temperature sets sampling randomness; nil uses the model's default
+	Temperature *float64
+	// TopP samples from the smallest set of tokens whose probabilities add
+	// up to it; nil uses the model's default
+	TopP *float64
+}
+
+// Usage counts the tokens a completion consumed.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Response is a completion.
+type Response struct {
+	Model   string
+	Content string
+	// FinishReason is FinishStop or FinishLength
+	FinishReason string
+	Usage        Usage
+}
+
+// Provider is one vendor's language model API.
+type Provider interface {
+	// Name names the provider, e.g. openai
+	Name() string
+	// Complete returns the model's completion of a conversation
+	Complete(ctx context.Context, req *Request) (*Response, error)
+	// Stream completes a conversation, calling onDelta with each piece of
+	// text as it arrives, and returns the whole completion. An error from
+	// onDelta stops the stream and is returned
+	Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error)
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Config configures a provider.
+type Config struct {
+	// Provider is openai, anthropic or stub
+	Provider string
+	APIKey   string
+	// BaseURL replaces the vendor's API URL, for proxies and compatible
+	// servers
+	BaseURL string
+	// Model answers requests that don't name one
+	Model string
+	// EmbeddingModel embeds text; empty disables Embed
+	EmbeddingModel string
+	// MaxTokens bounds completions that don't; zero uses DefaultMaxTokens
+	MaxTokens int
+	// Timeout bounds each API call; zero uses DefaultTimeout
+	Timeout time.Duration
+}
+
+// New creates the provider a configuration names.
+func New(cfg Config) (Provider, error) {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = DefaultMaxTokens
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	switch cfg.Provider {
+	case ProviderStub:
+		return NewStub(), nil
+	case ProviderOpenAI, ProviderAnthropic:
+	default:
+		return nil, fmt.Errorf("%w: unknown provider %q", ErrInvalidConfig, cfg.Provider)
+	}
+	// An OpenAI provider may only embed
+	if cfg.APIKey == "" || (cfg.Model == "" && (cfg.Provider != ProviderOpenAI || cfg.EmbeddingModel == "")) {
+		return nil, fmt.Errorf("%w: %s needs an API key and a model", ErrInvalidConfig, cfg.Provider)
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	if cfg.Provider == ProviderOpenAI {
+		return newOpenAI(cfg, client), nil
+	}
+	return newAnthropic(cfg, client), nil
+}
+
+// PersonaPrompt is the system prompt an agent answers with: who it is,
+// its philosophy and its directives.
+func PersonaPrompt(agent models.Agent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are %s", agent.Codename)
+	if agent.Name != "" && agent.Name != agent.Codename {
+		fmt.Fprintf(&b, " (%s)", agent.Name)
+	}
+	if agent.Specialty != "" {
+		fmt.Fprintf(&b, ", the %s specialist", agent.Specialty)
+	}
+	b.WriteString(" of the Elite Agent Collective.")
+	if agent.Philosophy != "" {
+		fmt.Fprintf(&b, "\n\nPhilosophy: %s", agent.Philosophy)
+	}
+	if len(agent.Directives) > 0 {
+		b.WriteString("\n\nCore directives:")
+		for i, directive := range agent.Directives {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, directive)
+		}
+	}
+	if len(agent.Collaborators) > 0 {
+		fmt.Fprintf(&b, "\n\nWhen a request is outside your specialty, suggest the collaborator suited to it: %s.", strings.Join(agent.Collaborators, ", "))
+	}
+	return b.String()
+}
+
+// split separates a request's system prompt from its conversation,
+// appending system messages to the prompt.
+func split(req *Request) (system string, messages []Message) {
+	parts := make([]string, 0, 1)
+	if req.System != "" {
+		parts = append(parts, req.System)
+	}
+	messages = make([]Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		if m.Role == "system" {
+			parts = append(parts, m.Content)
+			continue
+		}
+		messages = append(messages, m)
+	}
+	return strings.Join(parts, "\n\n"), messages
+}
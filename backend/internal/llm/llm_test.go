@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func contains(s, substr string) bool {
+	return strings.Contains(s, substr)
+}
+
+func TestNew(t *testing.T) {
+	for _, cfg := range []Config{
+		{Provider: "cohere", APIKey: "k", Model: "m"},
+		{Provider: ProviderOpenAI, Model: "m"},
+		{Provider: ProviderAnthropic, APIKey: "k"},
+		{Provider: ProviderAnthropic, APIKey: "k", EmbeddingModel: "e"},
+	} {
+		if _, err := New(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%+v: expected ErrInvalidConfig, got %v", cfg, err)
+		}
+	}
+	for name, cfg := range map[string]Config{
+		ProviderOpenAI:    {Provider: ProviderOpenAI, APIKey: "k", Model: "m"},
+		ProviderAnthropic: {Provider: ProviderAnthropic, APIKey: "k", Model: "m"},
+		ProviderStub:      {Provider: ProviderStub},
+	} {
+		provider, err := New(cfg)
+		if err != nil || provider.Name() != name {
+			t.Errorf("expected the %s provider, got %v (%v)", name, provider, err)
+		}
+	}
+}
+
+func TestPersonaPrompt(t *testing.T) {
+	prompt := PersonaPrompt(models.Agent{
+		Codename:      "CIPHER",
+		Name:          "Cipher",
+		Specialty:     "Cryptography",
+		Philosophy:    "Security is not a feature.",
+		Directives:    []string{"Never roll your own crypto", "Prefer audited libraries"},
+		Collaborators: []string{"FORTRESS", "AXIOM"},
+	})
+	for _, want := range []string{
+		"You are CIPHER (Cipher), the Cryptography specialist of the Elite Agent Collective.",
+		"Philosophy: Security is not a feature.",
+		"1. Never roll your own crypto\n2. Prefer audited libraries",
+		"FORTRESS, AXIOM",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+	if got := PersonaPrompt(models.Agent{Codename: "APEX"}); got != "You are APEX of the Elite Agent Collective." {
+		t.Errorf("expected a one-line prompt for a bare agent, got %q", got)
+	}
+}
+
+func TestStub(t *testing.T) {
+	stub := NewStub()
+	req := &Request{System: "You are APEX.\n\nPhilosophy: elegance.", Messages: []Message{{Role: "user", Content: "Sort this list"}}}
+	resp, err := stub.Complete(context.Background(), req)
+	if err != nil || resp.Content != "You are APEX.\n\nSort this list" {
+		t.Fatalf("unexpected stub answer %+v (%v)", resp, err)
+	}
+
+	var streamed strings.Builder
+	if _, err := stub.Stream(context.Background(), req, func(delta string) error {
+		streamed.WriteString(delta)
+		return nil
+	}); err != nil || streamed.String() != resp.Content {
+		t.Errorf("expected the streamed deltas to make up the answer, got %q (%v)", streamed.String(), err)
+	}
+
+	vectors, err := stub.Embed(context.Background(), []string{"sort", "sort"})
+	if err != nil || len(vectors) != 2 || vectors[0][0] != vectors[1][0] {
+		t.Errorf("expected deterministic vectors, got %v", err)
+	}
+}
+
+func TestEmbedder(t *testing.T) {
+	provider, err := New(Config{Provider: ProviderOpenAI, APIKey: "k", EmbeddingModel: "text-embedding-3-small"})
+	if err != nil {
+		t.Fatalf("expected an embedding-only OpenAI provider, got %v", err)
+	}
+	if got := NewEmbedder(provider, "text-embedding-3-small").Model(); got != "openai/text-embedding-3-small" {
+		t.Errorf("unexpected model %q", got)
+	}
+
+	vectors, err := NewEmbedder(NewStub(), "stub").Embed(context.Background(), []string{"sort"})
+	if err != nil || len(vectors) != 1 {
+		t.Errorf("expected the provider's vectors, got %v (%v)", vectors, err)
+	}
+}
@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// openAIBaseURL is the OpenAI API's default URL.
+const openAIBaseURL = "https://api.openai.com/v1"
+
+// openAI calls the OpenAI chat completions and embeddings APIs, or a
+// server compatible with them.
+type openAI struct {
+	config Config
+	client *http.Client
+}
+
+func newOpenAI(cfg Config, client *http.Client) *openAI {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = openAIBaseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &openAI{config: cfg, client: client}
+}
+
+// Name returns openai.
+func (o *openAI) Name() string {
+	return ProviderOpenAI
+}
+
+// openAIChatRequest is the body of POST /chat/completions.
+type openAIChatRequest struct {
+	Model         string               `json:"model"`
+	Messages      []Message            `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+// openAIStreamOptions asks for usage in the last chunk of a stream.
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIUsage is the token usage of a completion.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// openAIChatResponse is a completion, or a chunk of a streamed one, whose
+// choices carry a delta instead of a message.
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      Message `json:"message"`
+		Delta        Message `json:"delta"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+// chatRequest builds the body of a completion request. System prompts go
+// first, as a system message.
+func (o *openAI) chatRequest(req *Request, stream bool) *openAIChatRequest {
+	system, messages := split(req)
+	body := &openAIChatRequest{
+		Model:       req.Model,
+		Messages:    make([]Message, 0, len(messages)+1),
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stream:      stream,
+	}
+	if body.Model == "" {
+		body.Model = o.config.Model
+	}
+	if body.MaxTokens <= 0 {
+		body.MaxTokens = o.config.MaxTokens
+	}
+	if system != "" {
+		body.Messages = append(body.Messages, Message{Role: "system", Content: system})
+	}
+	body.Messages = append(body.Messages, messages...)
+	if stream {
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	return body
+}
+
+// Complete calls POST /chat/completions.
+func (o *openAI) Complete(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := o.post(ctx, "/chat/completions", o.chatRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var completion openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode OpenAI response: %v", ErrProvider, err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("%w: OpenAI returned no choices", ErrProvider)
+	}
+	out := &Response{
+		Model:        completion.Model,
+		Content:      completion.Choices[0].Message.Content,
+		FinishReason: openAIFinishReason(completion.Choices[0].FinishReason),
+	}
+	if completion.Usage != nil {
+		out.Usage = Usage{InputTokens: completion.Usage.PromptTokens, OutputTokens: completion.Usage.CompletionTokens}
+	}
+	return out, nil
+}
+
+// Stream calls POST /chat/completions with stream set, reading the
+// completion's chunks as server-sent events until [DONE].
+func (o *openAI) Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error) {
+	resp, err := o.post(ctx, "/chat/completions", o.chatRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	out := &Response{FinishReason: FinishStop}
+	var content strings.Builder
+	err = readEvents(resp.Body, func(_, data string) (bool, error) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("%w: failed to decode OpenAI chunk: %v", ErrProvider, err)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.Usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				out.FinishReason = openAIFinishReason(choice.FinishReason)
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Content = content.String()
+	return out, nil
+}
+
+// Embed calls POST /embeddings with the configured embedding model.
+func (o *openAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if o.config.EmbeddingModel == "" {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	resp, err := o.post(ctx, "/embeddings", map[string]interface{}{"model": o.config.EmbeddingModel, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode OpenAI embeddings: %v", ErrProvider, err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("%w: OpenAI returned embedding %d of %d", ErrProvider, d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("%w: OpenAI returned no embedding for text %d", ErrProvider, i)
+		}
+	}
+	return vectors, nil
+}
+
+// post sends a JSON request, returning the response if it succeeded.
+func (o *openAI) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.config.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+o.config.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	if err := checkStatus("OpenAI", resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// openAIFinishReason maps OpenAI's finish reasons onto ours; reasons other
+// than running out of tokens count as stopping.
+func openAIFinishReason(reason string) string {
+	if reason == "length" {
+		return FinishLength
+	}
+	return FinishStop
+}
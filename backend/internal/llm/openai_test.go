@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newOpenAITest serves handler as the OpenAI API.
+func newOpenAITest(t *testing.T, cfg Config, handler http.HandlerFunc) Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	cfg.Provider, cfg.APIKey, cfg.BaseURL = ProviderOpenAI, "sk-test", server.URL+"/"
+	if cfg.Model == "" {
+		cfg.Model = "gpt-test"
+	}
+	provider, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return provider
+}
+
+func TestOpenAI_Complete(t *testing.T) {
+	var got openAIChatRequest
+	provider := newOpenAITest(t, Config{MaxTokens: 200}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"model":"gpt-test-0601","choices":[{"message":{"role":"assistant","content":"Hello"},"finish_reason":"length"}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`)
+	})
+
+	temperature, topP := 0.4, 0.9
+	resp, err := provider.Complete(context.Background(), &Request{
+		System:      "You are APEX.",
+		Messages:    []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hi"}},
+		Temperature: &temperature,
+		TopP:        &topP,
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != FinishLength || resp.Usage != (Usage{12, 3}) || resp.Model != "gpt-test-0601" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if got.Model != "gpt-test" || got.MaxTokens != 200 || got.Stream || *got.Temperature != 0.4 || *got.TopP != 0.9 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0] != (Message{"system", "You are APEX.\n\nBe brief."}) || got.Messages[1].Content != "Hi" {
+		t.Errorf("expected one system message first, got %+v", got.Messages)
+	}
+}
+
+func TestOpenAI_Stream(t *testing.T) {
+	provider := newOpenAITest(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("expected a streaming request with usage, got %+v", req)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"model":"gpt-test","choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+			`{"choices":[{"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	})
+
+	var deltas []string
+	resp, err := provider.Stream(context.Background(), &Request{Messages: []Message{{Role: "user", Content: "Hi"}}}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(deltas) != 2 || resp.Content != "Hello" || resp.FinishReason != FinishStop || resp.Usage != (Usage{5, 2}) {
+		t.Errorf("unexpected stream %v -> %+v", deltas, resp)
+	}
+
+	stop := errors.New("client went away")
+	if _, err := provider.Stream(context.Background(), &Request{}, func(string) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("expected the callback's error, got %v", err)
+	}
+}
+
+func TestOpenAI_Embed(t *testing.T) {
+	provider := newOpenAITest(t, Config{EmbeddingModel: "embed-test"}, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/embeddings" || req.Model != "embed-test" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, req)
+		}
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`)
+	})
+
+	vectors, err := provider.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("expected vectors in input order, got %v", vectors)
+	}
+
+	noModel := newOpenAITest(t, Config{}, func(w http.ResponseWriter, r *http.Request) {})
+	if _, err := noModel.Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrEmbeddingsUnsupported) {
+		t.Errorf("expected ErrEmbeddingsUnsupported without an embedding model, got %v", err)
+	}
+}
+
+func TestOpenAI_Error(t *testing.T) {
+	provider := newOpenAITest(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests)
+	})
+	_, err := provider.Complete(context.Background(), &Request{Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if !errors.Is(err, ErrProvider) || !contains(err.Error(), "status 429") || !contains(err.Error(), "Rate limit reached") {
+		t.Errorf("expected a provider error with the status and detail, got %v", err)
+	}
+}
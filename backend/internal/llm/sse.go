@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxEventBytes bounds one server-sent event line.
+const maxEventBytes = 1 << 20
+
+// readEvents calls fn with the event name and data of each server-sent
+// event in r, until r ends or fn returns an error or done.
+func readEvents(r io.Reader, fn func(event, data string) (done bool, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventBytes)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				done, err := fn(event, strings.Join(data, "\n"))
+				if err != nil || done {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// A comment, sent to keep the connection alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: reading stream: %v", ErrProvider, err)
+	}
+	if len(data) > 0 {
+		_, err := fn(event, strings.Join(data, "\n"))
+		return err
+	}
+	return nil
+}
+
+// checkStatus turns a non-200 response into an error carrying the start
+// of its body, which names the problem in both vendors' APIs.
+func checkStatus(vendor string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%w: %s returned status %d: %s", ErrProvider, vendor, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
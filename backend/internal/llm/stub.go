@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/embeddings"
+)
+
+// stubModel names the stub's model.
+const stubModel = "stub"
+
+// Stub answers without a model, for offline mode and tests. Its answer
+// restates the first line of the system prompt and the last user message,
+// so the same request always gets the same answer; it embeds text like
+// embeddings.StubEmbedder.
+type Stub struct {
+	embedder *embeddings.StubEmbedder
+}
+
+// NewStub creates a stub provider.
+func NewStub() *Stub {
+	return &Stub{embedder: embeddings.NewStubEmbedder(0)}
+}
+
+// Name returns stub.
+func (s *Stub) Name() string {
+	return ProviderStub
+}
+
+// Complete returns the stub's answer.
+func (s *Stub) Complete(ctx context.Context, req *Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	system, messages := split(req)
+	var question string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			question = messages[i].Content
+			break
+		}
+	}
+	persona, _, _ := strings.Cut(system, "\n")
+	content := fmt.Sprintf("%s\n\n%s", strings.TrimSpace(persona), question)
+	if persona == "" {
+		content = question
+	}
+	return &Response{
+		Model:        stubModel,
+		Content:      content,
+		FinishReason: FinishStop,
+		Usage:        Usage{InputTokens: len(strings.Fields(system)) + len(strings.Fields(question)), OutputTokens: len(strings.Fields(content))},
+	}, nil
+}
+
+// Stream returns the stub's answer a word at a time.
+func (s *Stub) Stream(ctx context.Context, req *Request, onDelta func(string) error) (*Response, error) {
+	resp, err := s.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rest := resp.Content
+	for rest != "" {
+		i := strings.IndexAny(rest[1:], " \n") + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		if err := onDelta(rest[:i]); err != nil {
+			return nil, err
+		}
+		rest = rest[i:]
+	}
+	return resp, nil
+}
+
+// Embed returns the stub embedder's vectors.
+func (s *Stub) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return s.embedder.Embed(ctx, texts)
+}
@@ -0,0 +1,253 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements adaptive spreading activation, which keeps each
+// query within a time budget.
+//
+// MaxSpreadingDepth fixes how far activation spreads, but what a cycle
+// costs depends on how dense the graph around the sources is, and grows as
+// the graph does. The controller measures every query and steers the depth
+// so that smoothed latency stays under a share of the budget: a level
+// shallower when it runs over, a level deeper when it runs well under.
+// When even the shallowest depth is over, it raises the activation
+// threshold instead, pruning weak spread, and lowers it back first once
+// there is room. A query that still runs past the budget stops after the
+// cycle it is in.
+
+package memory
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveSpreadingConfig holds configuration for adaptive spreading.
+type AdaptiveSpreadingConfig struct {
+	// Budget is the time a query may spend spreading
+	Budget time.Duration
+	// MinDepth and MaxDepth bound the spreading depth
+	MinDepth int
+	MaxDepth int
+	// MaxThreshold bounds how far the activation threshold is raised; the
+	// network's ActivationThreshold is the floor
+	MaxThreshold float64
+	// ThresholdStep multiplies or divides the threshold per adjustment
+	ThresholdStep float64
+	// Headroom is the share of the budget smoothed latency is kept under
+	Headroom float64
+	// Smoothing is the weight of the newest latency in the moving average
+	Smoothing float64
+	// Cooldown is the number of queries measured after an adjustment
+	// before the next; queries cut short by the budget adjust at once
+	Cooldown int
+}
+
+// DefaultAdaptiveSpreadingConfig returns sensible defaults.
+func DefaultAdaptiveSpreadingConfig() AdaptiveSpreadingConfig {
+	return AdaptiveSpreadingConfig{
+		Budget:        5 * time.Millisecond,
+		MinDepth:      1,
+		MaxDepth:      6,
+		MaxThreshold:  0.5,
+		ThresholdStep: 1.5,
+		Headroom:      0.8,
+		Smoothing:     0.3,
+		Cooldown:      3,
+	}
+}
+
+// SpreadingParams are the parameters a query spreads with.
+type SpreadingParams struct {
+	Depth     int     `json:"depth"`
+	Threshold float64 `json:"threshold"`
+}
+
+// AdaptiveSpreadingStats tracks the controller.
+type AdaptiveSpreadingStats struct {
+	Params SpreadingParams `json:"params"`
+	// Latency is the smoothed query latency at the current parameters
+	Latency   time.Duration `json:"latency"`
+	Queries   int64         `json:"queries"`
+	Truncated int64         `json:"truncated"`
+	// Deepened and Shallowed count depth adjustments; Raised and Lowered
+	// count threshold adjustments
+	Deepened  int64 `json:"deepened"`
+	Shallowed int64 `json:"shallowed"`
+	Raised    int64 `json:"raised"`
+	Lowered   int64 `json:"lowered"`
+}
+
+// AdaptiveSpreading runs spreading activation on a network with depth and
+// threshold adjusted to a time budget. It is safe for concurrent use.
+type AdaptiveSpreading struct {
+	network *SemanticNetwork
+	config  AdaptiveSpreadingConfig
+	// floor is the network's activation threshold
+	floor float64
+
+	mu     sync.Mutex
+	params SpreadingParams
+	// latency is the moving average in nanoseconds; zero until the first
+	// query at the current parameters
+	latency float64
+	// cooldown counts down the queries left before the next adjustment
+	cooldown int
+	stats    AdaptiveSpreadingStats
+}
+
+// NewAdaptiveSpreading creates a controller for a network, starting from
+// the network's MaxSpreadingDepth and ActivationThreshold, which are
+// measured for a cooldown before the first adjustment.
+func NewAdaptiveSpreading(network *SemanticNetwork, config AdaptiveSpreadingConfig) *AdaptiveSpreading {
+	if config.MinDepth < 1 {
+		config.MinDepth = 1
+	}
+	if config.MaxDepth < config.MinDepth {
+		config.MaxDepth = config.MinDepth
+	}
+	if config.ThresholdStep <= 1 {
+		config.ThresholdStep = DefaultAdaptiveSpreadingConfig().ThresholdStep
+	}
+
+	floor := network.config.ActivationThreshold
+	if config.MaxThreshold < floor {
+		config.MaxThreshold = floor
+	}
+	depth := network.config.MaxSpreadingDepth
+	if depth < config.MinDepth {
+		depth = config.MinDepth
+	}
+	if depth > config.MaxDepth {
+		depth = config.MaxDepth
+	}
+	return &AdaptiveSpreading{
+		network:  network,
+		config:   config,
+		floor:    floor,
+		params:   SpreadingParams{Depth: depth, Threshold: floor},
+		cooldown: config.Cooldown,
+	}
+}
+
+// SpreadActivation performs spreading activation from source nodes with
+// the current parameters, which the result records, and adjusts them by
+// how long it took.
+func (a *AdaptiveSpreading) SpreadActivation(sourceIDs []string, initialActivation float64) *ActivationResult {
+	return a.SpreadActivationWith(sourceIDs, initialActivation, SpreadOptions{})
+}
+
+// SpreadActivationWith performs adaptive spreading activation with
+// options. Recording contributions slows a query, and the controller
+// adjusts for that like any other cost.
+func (a *AdaptiveSpreading) SpreadActivationWith(sourceIDs []string, initialActivation float64, opts SpreadOptions) *ActivationResult {
+	params := a.Params()
+
+	a.network.mu.Lock()
+	result := a.network.spreadActivation(sourceIDs, initialActivation, params.Depth, params.Threshold, a.config.Budget, opts)
+	a.network.mu.Unlock()
+
+	a.observe(params, result)
+	return result
+}
+
+// Params returns the parameters the next query will spread with.
+func (a *AdaptiveSpreading) Params() SpreadingParams {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.params
+}
+
+// Stats returns the controller's statistics.
+func (a *AdaptiveSpreading) Stats() AdaptiveSpreadingStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.stats
+	stats.Params = a.params
+	stats.Latency = time.Duration(a.latency)
+	return stats
+}
+
+// observe folds a query's latency into the average and adjusts the
+// parameters. Queries run with parameters since replaced are ignored.
+func (a *AdaptiveSpreading) observe(params SpreadingParams, result *ActivationResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stats.Queries++
+	if result.Truncated {
+		a.stats.Truncated++
+	}
+	if params != a.params {
+		return
+	}
+
+	sample := float64(result.Duration)
+	if a.latency == 0 {
+		a.latency = sample
+	} else {
+		a.latency += a.config.Smoothing * (sample - a.latency)
+	}
+	if a.cooldown > 0 && !result.Truncated {
+		a.cooldown--
+		return
+	}
+
+	target := a.config.Headroom * float64(a.config.Budget)
+	switch {
+	case result.Truncated || a.latency > target:
+		a.shrink()
+	// A deeper query at least doubles the work while the graph keeps
+	// branching, so there must be room for that; and going deeper is
+	// pointless if spreading died out before the current depth
+	case a.latency < target/2 && (params.Threshold > a.floor || result.Iterations == params.Depth):
+		a.grow()
+	}
+}
+
+// shrink makes queries cheaper: a level shallower, or once at the minimum
+// depth, a higher threshold.
+func (a *AdaptiveSpreading) shrink() {
+	switch {
+	case a.params.Depth > a.config.MinDepth:
+		a.params.Depth--
+		a.stats.Shallowed++
+	case a.params.Threshold < a.config.MaxThreshold:
+		next := math.Max(a.params.Threshold*a.config.ThresholdStep, a.minRaised())
+		a.params.Threshold = math.Min(next, a.config.MaxThreshold)
+		a.stats.Raised++
+	default:
+		return
+	}
+	a.adjusted()
+}
+
+// grow spends spare budget: first lowering a raised threshold, then a
+// level deeper.
+func (a *AdaptiveSpreading) grow() {
+	switch {
+	case a.params.Threshold > a.floor:
+		a.params.Threshold /= a.config.ThresholdStep
+		if a.params.Threshold < a.minRaised() {
+			a.params.Threshold = a.floor
+		}
+		a.stats.Lowered++
+	case a.params.Depth < a.config.MaxDepth:
+		a.params.Depth++
+		a.stats.Deepened++
+	default:
+		return
+	}
+	a.adjusted()
+}
+
+// minRaised is the lowest raised threshold, a tenth of the way from the
+// floor to MaxThreshold, so that a zero floor can be raised and lowered
+// back to.
+func (a *AdaptiveSpreading) minRaised() float64 {
+	return a.floor + (a.config.MaxThreshold-a.floor)/10
+}
+
+// adjusted starts measuring the new parameters afresh.
+func (a *AdaptiveSpreading) adjusted() {
+	a.latency = 0
+	a.cooldown = a.config.Cooldown
+}
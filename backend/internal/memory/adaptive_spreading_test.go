@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// chainNetwork links n concept nodes in a line, n0 -> n1 -> ... with full
+// weight.
+func chainNetwork(n int) *SemanticNetwork {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for i := 0; i < n; i++ {
+		sn.AddNode(NewSemanticNode(fmt.Sprintf("n%d", i), fmt.Sprintf("Node %d", i), ConceptNode))
+		if i > 0 {
+			sn.AddRelation(NewSemanticRelation(fmt.Sprintf("n%d", i-1), fmt.Sprintf("n%d", i), RelatedTo))
+		}
+	}
+	return sn
+}
+
+func TestAdaptiveSpreading_RecordsParams(t *testing.T) {
+	config := DefaultAdaptiveSpreadingConfig()
+	config.Budget = time.Second
+	adaptive := NewAdaptiveSpreading(chainNetwork(5), config)
+
+	result := adaptive.SpreadActivation([]string{"n0"}, 1.0)
+	if result.Depth != 3 || result.Threshold != 0.1 {
+		t.Errorf("Expected the network's depth 3 and threshold 0.1, got %d and %v", result.Depth, result.Threshold)
+	}
+	if result.Truncated || result.Duration <= 0 {
+		t.Errorf("Expected an untruncated, timed query, got truncated=%v duration=%v", result.Truncated, result.Duration)
+	}
+	if stats := adaptive.Stats(); stats.Queries != 1 || stats.Latency != result.Duration {
+		t.Errorf("Expected the query measured, got %+v", stats)
+	}
+
+	result = adaptive.SpreadActivationWith([]string{"n0"}, 1.0, SpreadOptions{Contributions: true})
+	if got := result.ContributionsTo("n3"); len(got) != 1 || got[0].SourceID != "n2" || got[0].Cycle != 3 {
+		t.Errorf("Expected n3 reached from n2 in the third cycle, got %+v", got)
+	}
+}
+
+func TestAdaptiveSpreading_Truncated(t *testing.T) {
+	config := DefaultAdaptiveSpreadingConfig()
+	config.Budget = time.Nanosecond
+	adaptive := NewAdaptiveSpreading(chainNetwork(5), config)
+
+	result := adaptive.SpreadActivation([]string{"n0"}, 1.0)
+	if !result.Truncated || result.Iterations != 1 {
+		t.Errorf("Expected spreading cut after one cycle, got %d iterations, truncated=%v", result.Iterations, result.Truncated)
+	}
+	if params := adaptive.Params(); params.Depth != 2 {
+		t.Errorf("Expected a truncated query to shrink the depth at once, got %d", params.Depth)
+	}
+}
+
+func TestAdaptiveSpreading_Adjusts(t *testing.T) {
+	config := DefaultAdaptiveSpreadingConfig()
+	config.Budget = 10 * time.Millisecond
+	config.MaxDepth = 4
+	config.Cooldown = 1
+	adaptive := NewAdaptiveSpreading(chainNetwork(2), config)
+
+	// feed reports queries at the current parameters until they settle
+	feed := func(duration time.Duration, queries int) SpreadingParams {
+		for i := 0; i < queries; i++ {
+			params := adaptive.Params()
+			adaptive.observe(params, &ActivationResult{Depth: params.Depth, Threshold: params.Threshold, Iterations: params.Depth, Duration: duration})
+		}
+		return adaptive.Params()
+	}
+
+	// Over budget: shallower first, then a higher threshold up to the cap
+	if params := feed(20*time.Millisecond, 3); params.Depth != 2 || params.Threshold != 0.1 {
+		t.Errorf("Expected depth 2 after one adjustment and a cooldown, got %+v", params)
+	}
+	params := feed(20*time.Millisecond, 40)
+	if params.Depth != config.MinDepth || params.Threshold != config.MaxThreshold {
+		t.Errorf("Expected minimum depth and maximum threshold, got %+v", params)
+	}
+
+	// Well under budget: the threshold comes back down before depth grows
+	lowered := false
+	for i := 0; i < 40 && !lowered; i++ {
+		params = feed(time.Millisecond, 1)
+		if params.Depth > config.MinDepth && params.Threshold > 0.1 {
+			t.Fatalf("Expected the threshold lowered before deepening, got %+v", params)
+		}
+		lowered = params.Threshold == 0.1
+	}
+	if params := feed(time.Millisecond, 40); params.Depth != config.MaxDepth || params.Threshold != 0.1 {
+		t.Errorf("Expected maximum depth at the network's threshold, got %+v", params)
+	}
+
+	// Latency between half and all of the target holds the parameters
+	before := adaptive.Stats()
+	feed(6*time.Millisecond, 10)
+	after := adaptive.Stats()
+	if after.Deepened != before.Deepened || after.Shallowed != before.Shallowed {
+		t.Errorf("Expected no adjustment within the target band, got %+v", after)
+	}
+
+	// Spreading that dies out early gains nothing from more depth
+	adaptive = NewAdaptiveSpreading(chainNetwork(2), config)
+	for i := 0; i < 10; i++ {
+		params := adaptive.Params()
+		adaptive.observe(params, &ActivationResult{Iterations: 1, Duration: time.Millisecond})
+	}
+	if params := adaptive.Params(); params.Depth != 3 {
+		t.Errorf("Expected depth kept when spreading dies out, got %d", params.Depth)
+	}
+}
+
+func TestAdaptiveSpreading_ZeroFloor(t *testing.T) {
+	networkConfig := DefaultSemanticNetworkConfig()
+	networkConfig.ActivationThreshold = 0
+	config := DefaultAdaptiveSpreadingConfig()
+	config.MinDepth, config.Cooldown = 3, 0
+	adaptive := NewAdaptiveSpreading(NewSemanticNetwork(networkConfig), config)
+
+	adaptive.observe(adaptive.Params(), &ActivationResult{Truncated: true, Duration: time.Second})
+	if params := adaptive.Params(); params.Threshold <= 0 {
+		t.Fatalf("Expected a zero threshold raised, got %v", params.Threshold)
+	}
+	for i := 0; i < 20; i++ {
+		params := adaptive.Params()
+		adaptive.observe(params, &ActivationResult{Iterations: params.Depth, Duration: time.Microsecond})
+	}
+	if params := adaptive.Params(); params.Threshold != 0 {
+		t.Errorf("Expected the threshold lowered back to zero, got %v", params.Threshold)
+	}
+}
@@ -0,0 +1,397 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the admin API over the collective's cognitive state:
+// routing attention and the knowledge graph's activation, goals and their
+// journals, impasses, and browsing the knowledge graph. The admin UI is built on it.
+
+package memory
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// Admin API limits.
+const (
+	// adminAttentionAgents bounds the agents listed per attention category
+	adminAttentionAgents = 5
+	// adminActivatedNodes bounds the most activated nodes listed
+	adminActivatedNodes = 10
+	// adminRecentImpasses bounds the impasses listed
+	adminRecentImpasses = 50
+	// adminDefaultNodes and adminMaxNodes bound node searches
+	adminDefaultNodes = 25
+	adminMaxNodes     = 200
+	// adminDefaultJournals and adminMaxJournals bound journal listings
+	adminDefaultJournals = 50
+	adminMaxJournals     = 500
+)
+
+// AdminHandler serves the admin API over the cognitive state.
+type AdminHandler struct {
+	network *SemanticNetwork
+	// attention, goals and impasses may be nil; their endpoints then
+	// report empty state
+	attention *CollaborativeAttentionIndex
+	goals     *GoalStack
+	impasses  *ImpasseDetector
+}
+
+// NewAdminHandler creates an admin handler over a semantic network and the
+// cognitive components beside it, any of which may be nil.
+func NewAdminHandler(network *SemanticNetwork, attention *CollaborativeAttentionIndex, goals *GoalStack, impasses *ImpasseDetector) *AdminHandler {
+	return &AdminHandler{
+		network:   network,
+		attention: attention,
+		goals:     goals,
+		impasses:  impasses,
+	}
+}
+
+// ============================================================================
+// Response Types
+// ============================================================================
+
+// AgentWeightView is an agent's attention weight in a category.
+type AgentWeightView struct {
+	Agent  string  `json:"agent"`
+	Weight float64 `json:"weight"`
+}
+
+// ActivatedNodeView is a node and its current activation.
+type ActivatedNodeView struct {
+	NodeView
+	Activation float64 `json:"activation"`
+}
+
+// AttentionResponse is the body of GET /admin/memory/attention.
+type AttentionResponse struct {
+	// Categories holds each query category's most attended agents,
+	// strongest first
+	Categories map[string][]AgentWeightView `json:"categories"`
+	// Activated are the knowledge graph's most activated nodes
+	Activated []ActivatedNodeView `json:"activated"`
+}
+
+// GoalView is the JSON form of a goal.
+type GoalView struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	Depth       int        `json:"depth"`
+	Progress    float64    `json:"progress"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	// Reason is why the goal was suspended or failed
+	Reason string `json:"reason,omitempty"`
+	// Dependencies are the goals that must complete first
+	Dependencies []string `json:"dependencies,omitempty"`
+	// BlockedBy are the dependencies that have not completed yet
+	BlockedBy []string `json:"blocked_by,omitempty"`
+	// MaxTokens and MaxCost are the goal's budget; zero is unlimited
+	MaxTokens   int64   `json:"max_tokens,omitempty"`
+	MaxCost     float64 `json:"max_cost,omitempty"`
+	TokensSpent int64   `json:"tokens_spent"`
+	CostSpent   float64 `json:"cost_spent"`
+	// Exhausted is the limit the goal ran past: deadline, tokens or cost
+	Exhausted string `json:"exhausted,omitempty"`
+}
+
+// GoalsResponse is the body of GET /admin/memory/goals.
+type GoalsResponse struct {
+	// Current is the ID of the goal in focus, if any
+	Current string     `json:"current,omitempty"`
+	Goals   []GoalView `json:"goals"`
+	// Ready are the goals that can be worked on in parallel now, highest
+	// priority first
+	Ready []string `json:"ready"`
+}
+
+// JournalsResponse is the body of GET /admin/memory/journals.
+type JournalsResponse struct {
+	// Journals are the goals' journals without their entries, most
+	// recently updated first
+	Journals []*GoalJournal `json:"journals"`
+}
+
+// ImpasseView is the JSON form of an impasse.
+type ImpasseView struct {
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	GoalID      string     `json:"goal_id,omitempty"`
+	Description string     `json:"description"`
+	Candidates  []string   `json:"candidates,omitempty"`
+	Severity    float64    `json:"severity"`
+	DetectedAt  time.Time  `json:"detected_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	Resolution  string     `json:"resolution,omitempty"`
+}
+
+// ImpassesResponse is the body of GET /admin/memory/impasses.
+type ImpassesResponse struct {
+	Active   int `json:"active"`
+	Resolved int `json:"resolved"`
+	// Recent are the latest impasses, active or resolved, newest first
+	Recent []ImpasseView `json:"recent"`
+}
+
+// NodesResponse is the body of GET /admin/memory/nodes.
+type NodesResponse struct {
+	Nodes []NodeView `json:"nodes"`
+	// Total is the number of matching nodes before the limit
+	Total int `json:"total"`
+}
+
+// NodeDetailResponse is the body of GET /admin/memory/nodes/{id}: a node
+// and its neighborhood.
+type NodeDetailResponse struct {
+	Node       NodeView       `json:"node"`
+	Activation float64        `json:"activation"`
+	Relations  []RelationView `json:"relations"`
+	// Neighbors are the nodes at the other end of the relations
+	Neighbors []NodeView `json:"neighbors"`
+}
+
+// newGoalView converts a goal to its JSON form.
+func newGoalView(goal *Goal) GoalView {
+	reason := goal.FailureReason
+	if reason == "" {
+		reason = goal.SuspensionReason
+	}
+	return GoalView{
+		ID:           goal.ID,
+		Name:         goal.Name,
+		Description:  goal.Description,
+		Status:       goal.Status.String(),
+		Priority:     int(goal.Priority),
+		ParentID:     goal.ParentID,
+		Depth:        goal.Depth,
+		Progress:     goal.Progress,
+		CreatedAt:    goal.CreatedAt,
+		Deadline:     goal.Deadline,
+		Reason:       reason,
+		Dependencies: goal.Dependencies,
+		MaxTokens:    goal.Budget.MaxTokens,
+		MaxCost:      goal.Budget.MaxCost,
+		TokensSpent:  goal.Spent.Tokens,
+		CostSpent:    goal.Spent.Cost,
+		Exhausted:    string(goal.Exhausted),
+	}
+}
+
+// newImpasseView converts an impasse to its JSON form.
+func newImpasseView(imp *Impasse) ImpasseView {
+	view := ImpasseView{
+		ID:          imp.ID,
+		Type:        imp.Type.String(),
+		GoalID:      imp.GoalID,
+		Description: imp.Description,
+		Candidates:  imp.Candidates,
+		Severity:    imp.Severity,
+		DetectedAt:  imp.DetectedAt,
+		ResolvedAt:  imp.ResolvedAt,
+	}
+	if imp.IsResolved() {
+		view.Resolution = imp.Resolution.String()
+	}
+	return view
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+// ServeAttention handles GET /admin/memory/attention - returns the agents
+// each query category attends to most and the most activated nodes.
+func (h *AdminHandler) ServeAttention(w http.ResponseWriter, r *http.Request) {
+	resp := AttentionResponse{
+		Categories: make(map[string][]AgentWeightView),
+		Activated:  make([]ActivatedNodeView, 0),
+	}
+	if h.attention != nil {
+		for category, weights := range h.attention.Weights() {
+			agents := make([]AgentWeightView, 0, len(weights))
+			for agent, weight := range weights {
+				agents = append(agents, AgentWeightView{Agent: agent, Weight: weight})
+			}
+			sort.Slice(agents, func(i, j int) bool {
+				if agents[i].Weight != agents[j].Weight {
+					return agents[i].Weight > agents[j].Weight
+				}
+				return agents[i].Agent < agents[j].Agent
+			})
+			if len(agents) > adminAttentionAgents {
+				agents = agents[:adminAttentionAgents]
+			}
+			resp.Categories[category] = agents
+		}
+	}
+	for _, node := range h.network.GetMostActivated(adminActivatedNodes) {
+		if node.Activation <= node.BaseActivation {
+			continue
+		}
+		resp.Activated = append(resp.Activated, ActivatedNodeView{NodeView: newNodeView(node), Activation: node.Activation})
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ServeGoals handles GET /admin/memory/goals - returns the unfinished goals
+// on the goal stack by priority, what each waits on, and which are ready.
+func (h *AdminHandler) ServeGoals(w http.ResponseWriter, r *http.Request) {
+	resp := GoalsResponse{Goals: make([]GoalView, 0), Ready: make([]string, 0)}
+	if h.goals != nil {
+		snapshot := h.goals.Snapshot()
+		resp.Current = snapshot.CurrentGoalID
+		resp.Ready = snapshot.ReadyGoalIDs
+		goals := snapshot.Goals
+		sort.Slice(goals, func(i, j int) bool {
+			a, b := goals[i], goals[j]
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		})
+		for _, goal := range goals {
+			view := newGoalView(goal)
+			view.BlockedBy = snapshot.BlockedBy[goal.ID]
+			resp.Goals = append(resp.Goals, view)
+		}
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ServeJournals handles GET /admin/memory/journals?status=&limit= - lists
+// the goals' journals, most recently updated first, optionally only those
+// of goals in a status such as FAILED.
+func (h *AdminHandler) ServeJournals(w http.ResponseWriter, r *http.Request) {
+	limit := adminDefaultJournals
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > adminMaxJournals {
+			errdefs.WriteError(w, "limit must be between 1 and "+strconv.Itoa(adminMaxJournals), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	resp := JournalsResponse{Journals: make([]*GoalJournal, 0)}
+	if store := h.journal(); store != nil {
+		resp.Journals = store.List(r.URL.Query().Get("status"), limit)
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ServeGoalJournal handles GET /admin/memory/goals/{id}/journal - returns
+// a goal's journal, finished or not, for post-mortems.
+func (h *AdminHandler) ServeGoalJournal(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	store := h.journal()
+	if store == nil {
+		errdefs.WriteError(w, fmt.Errorf("%w: %s", ErrJournalNotFound, id).Error(), http.StatusNotFound)
+		return
+	}
+	journal, err := store.Get(id)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, journal)
+}
+
+// journal returns the goal stack's journal store, or nil if there is none.
+func (h *AdminHandler) journal() *GoalJournalStore {
+	if h.goals == nil {
+		return nil
+	}
+	return h.goals.Journal()
+}
+
+// ServeImpasses handles GET /admin/memory/impasses - returns the impasse
+// counts and the latest impasses.
+func (h *AdminHandler) ServeImpasses(w http.ResponseWriter, r *http.Request) {
+	resp := ImpassesResponse{Recent: make([]ImpasseView, 0)}
+	if h.impasses != nil {
+		snapshot := h.impasses.Snapshot()
+		resp.Active = snapshot.ActiveCount
+		resp.Resolved = snapshot.ResolvedCount
+		for _, imp := range h.impasses.Recent(adminRecentImpasses) {
+			resp.Recent = append(resp.Recent, newImpasseView(imp))
+		}
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ServeNodes handles GET /admin/memory/nodes?q=&limit= - returns the nodes
+// whose labels contain q, by label, or every node without q.
+func (h *AdminHandler) ServeNodes(w http.ResponseWriter, r *http.Request) {
+	limit := adminDefaultNodes
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > adminMaxNodes {
+			errdefs.WriteError(w, "limit must be between 1 and "+strconv.Itoa(adminMaxNodes), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	var nodes []*SemanticNode
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		nodes = h.network.FindNodesByLabel(q)
+	} else {
+		nodes = h.network.GetAllNodes()
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Label != nodes[j].Label {
+			return nodes[i].Label < nodes[j].Label
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	resp := NodesResponse{Nodes: make([]NodeView, 0), Total: len(nodes)}
+	for i := 0; i < len(nodes) && i < limit; i++ {
+		resp.Nodes = append(resp.Nodes, newNodeView(nodes[i]))
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ServeNode handles GET /admin/memory/nodes/{id} - returns a node with its
+// relations and the nodes they lead to.
+func (h *AdminHandler) ServeNode(w http.ResponseWriter, r *http.Request) {
+	node, err := h.network.GetNode(r.PathValue("id"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+
+	resp := NodeDetailResponse{
+		Node:       newNodeView(node),
+		Activation: node.Activation,
+		Relations:  make([]RelationView, 0),
+		Neighbors:  make([]NodeView, 0),
+	}
+	seen := map[string]bool{node.ID: true}
+	relations := append(h.network.GetOutgoingRelations(node.ID), h.network.GetIncomingRelations(node.ID)...)
+	for _, rel := range relations {
+		resp.Relations = append(resp.Relations, newRelationView(rel))
+		other := rel.TargetID
+		if other == node.ID {
+			other = rel.SourceID
+		}
+		if seen[other] {
+			continue
+		}
+		seen[other] = true
+		if neighbor, err := h.network.GetNode(other); err == nil {
+			resp.Neighbors = append(resp.Neighbors, newNodeView(neighbor))
+		}
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serveAdmin runs an admin endpoint and decodes its response.
+func serveAdmin(t *testing.T, handler http.HandlerFunc, req *http.Request, resp interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAdminHandler_AttentionGoalsImpasses(t *testing.T) {
+	sn, _ := activatedChain(t)
+	goals := NewGoalStack(DefaultGoalStackConfig())
+	impasses := NewImpasseDetector(nil, goals)
+	handler := NewAdminHandler(sn, NewCollaborativeAttentionIndex(), goals, impasses)
+
+	var attention AttentionResponse
+	serveAdmin(t, handler.ServeAttention, httptest.NewRequest(http.MethodGet, "/admin/memory/attention", nil), &attention)
+	if top := attention.Categories["security"]; len(top) != adminAttentionAgents || top[0].Agent != "CIPHER" && top[0].Agent != "FORTRESS" {
+		t.Errorf("Expected the security specialists attended most, got %+v", top)
+	}
+	// The source ties with the nodes it saturated, in no particular order
+	sourceActivation := -1.0
+	for _, node := range attention.Activated {
+		if node.ID == "dog" {
+			sourceActivation = node.Activation
+		}
+	}
+	if len(attention.Activated) == 0 || sourceActivation != attention.Activated[0].Activation {
+		t.Errorf("Expected the spreading source most activated, got %+v", attention.Activated)
+	}
+
+	goals.Push(&Goal{ID: "done", Name: "Done", Priority: PriorityHigh})
+	goals.Complete("done")
+	goals.Push(&Goal{ID: "low", Name: "Low", Priority: PriorityLow})
+	goals.Push(&Goal{ID: "high", Name: "High", Priority: PriorityHigh})
+	var goalsResp GoalsResponse
+	serveAdmin(t, handler.ServeGoals, httptest.NewRequest(http.MethodGet, "/admin/memory/goals", nil), &goalsResp)
+	if len(goalsResp.Goals) != 2 || goalsResp.Goals[0].ID != "high" || goalsResp.Goals[1].ID != "low" {
+		t.Errorf("Expected the unfinished goals by priority, got %+v", goalsResp.Goals)
+	}
+
+	if len(goalsResp.Ready) != 2 || goalsResp.Ready[0] != "high" {
+		t.Errorf("Expected both goals ready, high first, got %v", goalsResp.Ready)
+	}
+
+	goals.Push(&Goal{ID: "after", Name: "After", Priority: PriorityCritical, Dependencies: []string{"low"}})
+	serveAdmin(t, handler.ServeGoals, httptest.NewRequest(http.MethodGet, "/admin/memory/goals", nil), &goalsResp)
+	if after := goalsResp.Goals[0]; after.ID != "after" || len(after.BlockedBy) != 1 || after.BlockedBy[0] != "low" {
+		t.Errorf("Expected the waiting goal blocked by low, got %+v", after)
+	}
+	if len(goalsResp.Ready) != 2 {
+		t.Errorf("Expected the waiting goal not ready, got %v", goalsResp.Ready)
+	}
+
+	impasses.DetectTie("high", []string{"APEX", "AXIOM"}, []float64{0.5, 0.5})
+	var impassesResp ImpassesResponse
+	serveAdmin(t, handler.ServeImpasses, httptest.NewRequest(http.MethodGet, "/admin/memory/impasses", nil), &impassesResp)
+	if impassesResp.Active != 1 || len(impassesResp.Recent) != 1 || impassesResp.Recent[0].Type != "TIE" {
+		t.Errorf("Expected the tie impasse listed, got %+v", impassesResp)
+	}
+
+	// Without the optional components the endpoints report empty state
+	empty := NewAdminHandler(sn, nil, nil, nil)
+	serveAdmin(t, empty.ServeGoals, httptest.NewRequest(http.MethodGet, "/admin/memory/goals", nil), &goalsResp)
+	if goalsResp.Goals == nil || len(goalsResp.Goals) != 0 {
+		t.Errorf("Expected an empty goal list, got %+v", goalsResp.Goals)
+	}
+}
+
+func TestAdminHandler_Nodes(t *testing.T) {
+	sn, _ := activatedChain(t)
+	handler := NewAdminHandler(sn, nil, nil, nil)
+
+	var nodes NodesResponse
+	serveAdmin(t, handler.ServeNodes, httptest.NewRequest(http.MethodGet, "/admin/memory/nodes?limit=2", nil), &nodes)
+	if nodes.Total != 4 || len(nodes.Nodes) != 2 || nodes.Nodes[0].Label != "animal" {
+		t.Errorf("Expected the first 2 of 4 nodes by label, got %+v", nodes)
+	}
+	serveAdmin(t, handler.ServeNodes, httptest.NewRequest(http.MethodGet, "/admin/memory/nodes?q=LIV", nil), &nodes)
+	if nodes.Total != 1 || nodes.Nodes[0].ID != "living" {
+		t.Errorf("Expected a case-insensitive label search, got %+v", nodes)
+	}
+	if code := serveAdmin(t, handler.ServeNodes, httptest.NewRequest(http.MethodGet, "/admin/memory/nodes?limit=0", nil), &nodes); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad limit, got %d", code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/memory/nodes/animal", nil)
+	req.SetPathValue("id", "animal")
+	var detail NodeDetailResponse
+	serveAdmin(t, handler.ServeNode, req, &detail)
+	if detail.Node.ID != "animal" || len(detail.Relations) != 2 || len(detail.Neighbors) != 2 {
+		t.Errorf("Expected animal with its 2 relations and neighbors, got %+v", detail)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/memory/nodes/missing", nil)
+	req.SetPathValue("id", "missing")
+	if code := serveAdmin(t, handler.ServeNode, req, &detail); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing node, got %d", code)
+	}
+}
+
+func TestAdminHandler_Journals(t *testing.T) {
+	sn, _ := activatedChain(t)
+	goals := NewGoalStack(DefaultGoalStackConfig())
+	goals.SetJournal(NewGoalJournalStore())
+	handler := NewAdminHandler(sn, nil, goals, nil)
+
+	goals.Push(&Goal{ID: "answered", Name: "Answered"})
+	goals.Complete("answered")
+	goals.Push(&Goal{ID: "broken", Name: "Broken"})
+	goals.Fail("broken", "no agent could answer")
+
+	var list JournalsResponse
+	serveAdmin(t, handler.ServeJournals, httptest.NewRequest(http.MethodGet, "/admin/memory/journals?status=FAILED", nil), &list)
+	if len(list.Journals) != 1 || list.Journals[0].GoalID != "broken" {
+		t.Errorf("Expected only the failed goal's journal, got %+v", list.Journals)
+	}
+	if code := serveAdmin(t, handler.ServeJournals, httptest.NewRequest(http.MethodGet, "/admin/memory/journals?limit=0", nil), &list); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad limit, got %d", code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/memory/goals/broken/journal", nil)
+	req.SetPathValue("id", "broken")
+	var journal GoalJournal
+	serveAdmin(t, handler.ServeGoalJournal, req, &journal)
+	if journal.Status != "FAILED" || journal.FailureReason != "no agent could answer" || len(journal.Entries) == 0 {
+		t.Errorf("Expected the failed goal's journal, got %+v", journal)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/memory/goals/missing/journal", nil)
+	req.SetPathValue("id", "missing")
+	if code := serveAdmin(t, handler.ServeGoalJournal, req, &journal); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a goal without a journal, got %d", code)
+	}
+	empty := NewAdminHandler(sn, nil, nil, nil)
+	if code := serveAdmin(t, empty.ServeGoalJournal, req, &journal); code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a goal stack, got %d", code)
+	}
+}
@@ -0,0 +1,439 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements gossip-based sharing of routing state between
+// replicas of a horizontally scaled deployment.
+//
+// Each replica learns AgentAffinityGraph scores and CollaborativeAttentionIndex
+// weights from the requests it serves. Every gossip round a replica packages
+// its local changes as a delta, then pushes its recent messages to a few
+// random peers. Peers apply messages they have not seen and forward them on
+// later rounds, so every delta reaches every replica in O(log n) rounds
+// without a coordinator. Messages are deduplicated by origin, incarnation
+// and sequence, so duplicate paths don't matter, and a replica restarted
+// with the same ID starts a new incarnation rather than reusing sequences
+// its peers have seen. Collaboration counts are additive and converge
+// whatever the delivery order; affinity scores are clamped and attention
+// weights renormalized as each delta is applied, so replicas that apply
+// the same deltas in different orders can differ slightly near the bounds.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrGossipRunning is returned when starting a gossip loop twice
+var ErrGossipRunning = errdefs.New(errdefs.ErrConflict, "gossip already running")
+
+// ============================================================================
+// Gossip Messages
+// ============================================================================
+
+// GossipMessage carries the routing changes one replica made in one round.
+// Messages are immutable once created and may be shared between peers.
+type GossipMessage struct {
+	// Origin is the replica that made the changes
+	Origin string `json:"origin"`
+	// Incarnation identifies the origin's process, chosen at random when
+	// it starts
+	Incarnation uint64 `json:"incarnation"`
+	// Seq orders the incarnation's messages, starting at 1
+	Seq       uint64          `json:"seq"`
+	Affinity  *AffinityDelta  `json:"affinity,omitempty"`
+	Attention *AttentionDelta `json:"attention,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// GossipPeer is a remote replica that accepts gossip. Implementations wrap
+// the transport between replicas; an AffinityGossip is itself a peer.
+type GossipPeer interface {
+	// ID returns the peer's replica ID
+	ID() string
+	// Receive delivers a batch of messages to the peer
+	Receive(ctx context.Context, messages []GossipMessage) error
+}
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+// GossipConfig configures an AffinityGossip.
+type GossipConfig struct {
+	// ReplicaID uniquely identifies this replica
+	ReplicaID string
+	// Interval is the time between gossip rounds
+	Interval time.Duration
+	// Fanout is the number of peers contacted per round
+	Fanout int
+	// ForwardRounds is how many rounds a message is pushed before it is dropped
+	ForwardRounds int
+	// MaxBuffered bounds the messages held for forwarding; oldest are dropped
+	MaxBuffered int
+	// GapTimeout is how long a missing message is waited for, once later
+	// ones from its origin have arrived, before it is given up as lost;
+	// zero waits for twice the time a message is forwarded
+	GapTimeout time.Duration
+	// Clock supplies the time (default: SystemClock)
+	Clock Clock
+}
+
+// DefaultGossipConfig returns sensible defaults for a replica.
+func DefaultGossipConfig(replicaID string) GossipConfig {
+	return GossipConfig{
+		ReplicaID:     replicaID,
+		Interval:      5 * time.Second,
+		Fanout:        3,
+		ForwardRounds: 4,
+		MaxBuffered:   256,
+	}
+}
+
+// GossipStats contains gossip statistics.
+type GossipStats struct {
+	Rounds            int64
+	MessagesCreated   int64
+	MessagesSent      int64
+	MessagesApplied   int64
+	DuplicatesIgnored int64
+	MessagesDropped   int64
+	SendFailures      int64
+	// MessagesSkipped counts messages given up as lost
+	MessagesSkipped int64
+}
+
+// ============================================================================
+// Affinity Gossip
+// ============================================================================
+
+// bufferedMessage is a message awaiting forwarding.
+type bufferedMessage struct {
+	message GossipMessage
+	// roundsLeft counts the rounds the message will still be pushed
+	roundsLeft int
+}
+
+// originKey identifies one incarnation of a replica.
+type originKey struct {
+	origin      string
+	incarnation uint64
+}
+
+// originIdleGapTimeouts is how many gap timeouts an origin may be silent
+// before its progress is forgotten; its messages have long stopped being
+// forwarded by then.
+const originIdleGapTimeouts = 10
+
+// maxAhead bounds the sequences tracked above an origin's watermark when
+// MaxBuffered doesn't; past it, the watermark skips the oldest gap.
+const maxAhead = 1024
+
+// originProgress tracks which of an origin's messages have been applied.
+type originProgress struct {
+	// watermark is the highest sequence below which all were applied or
+	// given up as lost
+	watermark uint64
+	// ahead holds applied sequences above the watermark, with when each
+	// was applied
+	ahead map[uint64]time.Time
+	// updated is when a message from the origin was last applied
+	updated time.Time
+}
+
+// AffinityGossip shares affinity and attention changes with peer replicas.
+type AffinityGossip struct {
+	config    GossipConfig
+	affinity  *AgentAffinityGraph
+	attention *CollaborativeAttentionIndex
+
+	peers       []GossipPeer
+	incarnation uint64
+	seq         uint64
+	seen        map[originKey]*originProgress
+	buffer      []bufferedMessage
+	rng         *rand.Rand
+	stats       GossipStats
+
+	running bool
+	stopCh  chan struct{}
+	// done is closed once the gossip loop has returned
+	done chan struct{}
+	mu   sync.Mutex
+}
+
+// NewAffinityGossip creates a gossip node for a replica's routing state.
+// Either structure may be nil if the replica doesn't maintain it.
+func NewAffinityGossip(config GossipConfig, affinity *AgentAffinityGraph, attention *CollaborativeAttentionIndex) *AffinityGossip {
+	if config.Fanout <= 0 {
+		config.Fanout = 1
+	}
+	if config.ForwardRounds <= 0 {
+		config.ForwardRounds = 1
+	}
+	if config.GapTimeout <= 0 {
+		config.GapTimeout = 2 * time.Duration(config.ForwardRounds) * config.Interval
+	}
+	config.Clock = clockOrSystem(config.Clock)
+	return &AffinityGossip{
+		config:      config,
+		affinity:    affinity,
+		attention:   attention,
+		peers:       make([]GossipPeer, 0),
+		incarnation: rand.Uint64(),
+		seen:        make(map[originKey]*originProgress),
+		buffer:      make([]bufferedMessage, 0),
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// ID returns the replica ID.
+func (g *AffinityGossip) ID() string {
+	return g.config.ReplicaID
+}
+
+// AddPeer registers a peer to gossip with.
+func (g *AffinityGossip) AddPeer(peer GossipPeer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, p := range g.peers {
+		if p.ID() == peer.ID() {
+			return
+		}
+	}
+	g.peers = append(g.peers, peer)
+}
+
+// Receive applies messages not seen before and buffers them for forwarding.
+func (g *AffinityGossip) Receive(ctx context.Context, messages []GossipMessage) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, msg := range messages {
+		if msg.Origin == g.config.ReplicaID || !g.markSeen(originKey{msg.Origin, msg.Incarnation}, msg.Seq) {
+			g.stats.DuplicatesIgnored++
+			continue
+		}
+		if g.affinity != nil {
+			g.affinity.ApplyDelta(msg.Affinity)
+		}
+		if g.attention != nil {
+			g.attention.ApplyDelta(msg.Attention)
+		}
+		g.stats.MessagesApplied++
+		g.bufferMessage(msg)
+	}
+	return nil
+}
+
+// markSeen records a message as applied, returning false if it already was.
+func (g *AffinityGossip) markSeen(key originKey, seq uint64) bool {
+	now := g.config.Clock.Now()
+	progress, ok := g.seen[key]
+	if !ok {
+		progress = &originProgress{ahead: make(map[uint64]time.Time)}
+		g.seen[key] = progress
+	}
+	if _, applied := progress.ahead[seq]; seq <= progress.watermark || applied {
+		return false
+	}
+	progress.ahead[seq] = now
+	progress.updated = now
+	g.advance(progress, now)
+	return true
+}
+
+// advance moves an origin's watermark over the sequences applied above
+// it, and past gaps given up as lost: those waited on longer than
+// GapTimeout, or the oldest once too many sequences are tracked ahead.
+// Caller must hold g.mu.
+func (g *AffinityGossip) advance(progress *originProgress, now time.Time) {
+	limit := g.config.MaxBuffered
+	if limit <= 0 {
+		limit = maxAhead
+	}
+	for len(progress.ahead) > 0 {
+		if _, ok := progress.ahead[progress.watermark+1]; ok {
+			progress.watermark++
+			delete(progress.ahead, progress.watermark)
+			continue
+		}
+		next, appliedAt := uint64(0), time.Time{}
+		for seq, at := range progress.ahead {
+			if next == 0 || seq < next {
+				next, appliedAt = seq, at
+			}
+		}
+		if now.Sub(appliedAt) < g.config.GapTimeout && len(progress.ahead) <= limit {
+			return
+		}
+		g.stats.MessagesSkipped += int64(next - progress.watermark - 1)
+		progress.watermark = next - 1
+	}
+}
+
+// expireProgress gives up on the gaps that have timed out and forgets the
+// origins nothing has been heard from for many gap timeouts, such as the
+// incarnations of replicas since restarted. Caller must hold g.mu.
+func (g *AffinityGossip) expireProgress(now time.Time) {
+	for key, progress := range g.seen {
+		g.advance(progress, now)
+		if len(progress.ahead) == 0 && now.Sub(progress.updated) > originIdleGapTimeouts*g.config.GapTimeout {
+			delete(g.seen, key)
+		}
+	}
+}
+
+// bufferMessage queues a message for forwarding, dropping the oldest if full.
+func (g *AffinityGossip) bufferMessage(msg GossipMessage) {
+	g.buffer = append(g.buffer, bufferedMessage{message: msg, roundsLeft: g.config.ForwardRounds})
+	if g.config.MaxBuffered > 0 && len(g.buffer) > g.config.MaxBuffered {
+		dropped := len(g.buffer) - g.config.MaxBuffered
+		g.buffer = g.buffer[dropped:]
+		g.stats.MessagesDropped += int64(dropped)
+	}
+}
+
+// Round runs one gossip round: it packages local changes and pushes
+// buffered messages to Fanout random peers. Failed sends are counted and
+// retried on later rounds while the messages remain buffered.
+func (g *AffinityGossip) Round(ctx context.Context) error {
+	g.mu.Lock()
+	g.stats.Rounds++
+	g.expireProgress(g.config.Clock.Now())
+
+	var affinity *AffinityDelta
+	if g.affinity != nil {
+		affinity = g.affinity.TakeDelta()
+	}
+	var attention *AttentionDelta
+	if g.attention != nil {
+		attention = g.attention.TakeDelta()
+	}
+	if affinity != nil || attention != nil {
+		g.seq++
+		g.bufferMessage(GossipMessage{
+			Origin:      g.config.ReplicaID,
+			Incarnation: g.incarnation,
+			Seq:         g.seq,
+			Affinity:    affinity,
+			Attention:   attention,
+			CreatedAt:   g.config.Clock.Now(),
+		})
+		g.stats.MessagesCreated++
+	}
+
+	if len(g.buffer) == 0 || len(g.peers) == 0 {
+		g.mu.Unlock()
+		return nil
+	}
+
+	messages := make([]GossipMessage, len(g.buffer))
+	kept := g.buffer[:0]
+	for i, bm := range g.buffer {
+		messages[i] = bm.message
+		bm.roundsLeft--
+		if bm.roundsLeft > 0 {
+			kept = append(kept, bm)
+		}
+	}
+	g.buffer = kept
+	targets := g.choosePeers()
+	g.mu.Unlock()
+
+	// Peers are contacted without holding the lock, since an in-process
+	// peer may gossip back to us
+	var errs []error
+	sent := 0
+	for _, peer := range targets {
+		if err := peer.Receive(ctx, messages); err != nil {
+			errs = append(errs, fmt.Errorf("gossip to %s: %w", peer.ID(), err))
+			continue
+		}
+		sent += len(messages)
+	}
+
+	g.mu.Lock()
+	g.stats.MessagesSent += int64(sent)
+	g.stats.SendFailures += int64(len(errs))
+	g.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// choosePeers picks up to Fanout distinct random peers. Caller must hold g.mu.
+func (g *AffinityGossip) choosePeers() []GossipPeer {
+	order := g.rng.Perm(len(g.peers))
+	n := min(g.config.Fanout, len(order))
+	targets := make([]GossipPeer, n)
+	for i := 0; i < n; i++ {
+		targets[i] = g.peers[order[i]]
+	}
+	return targets
+}
+
+// GetStats returns gossip statistics.
+func (g *AffinityGossip) GetStats() GossipStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
+
+// ============================================================================
+// Background Loop
+// ============================================================================
+
+// Start runs gossip rounds every Interval until Stop or ctx is done.
+func (g *AffinityGossip) Start(ctx context.Context) error {
+	g.mu.Lock()
+	if g.running {
+		g.mu.Unlock()
+		return ErrGossipRunning
+	}
+	g.running = true
+	g.stopCh = make(chan struct{})
+	g.done = make(chan struct{})
+	go g.gossipLoop(ctx, g.stopCh, g.done)
+	g.mu.Unlock()
+	return nil
+}
+
+// Stop halts the gossip loop, returning once a round in progress has
+// finished.
+func (g *AffinityGossip) Stop() {
+	g.mu.Lock()
+	if !g.running {
+		g.mu.Unlock()
+		return
+	}
+	close(g.stopCh)
+	g.running = false
+	done := g.done
+	g.mu.Unlock()
+	<-done
+}
+
+// gossipLoop runs gossip rounds on a ticker until stopCh is closed or ctx
+// is done, then closes done.
+func (g *AffinityGossip) gossipLoop(ctx context.Context, stopCh, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(g.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			// Failures are counted in stats; unreachable peers are retried
+			g.Round(ctx)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the HTTP transport gossip travels between replicas on.
+//
+// Each replica serves its AffinityGossip at GossipPath and reaches its peers
+// there. Replicas share a secret, sent as a bearer token, so only replicas
+// of the deployment can change each other's routing state.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// GossipPath is where replicas serve gossip to their peers.
+const GossipPath = "/cluster/gossip"
+
+// maxGossipBody bounds the gossip a replica accepts in one request.
+const maxGossipBody = 8 << 20
+
+// ErrGossipPeer is returned when a peer rejects gossip or can't be reached
+var ErrGossipPeer = errdefs.New(errdefs.ErrProviderFailure, "gossip peer error")
+
+// HTTPGossipPeer is a remote replica reached over HTTP.
+type HTTPGossipPeer struct {
+	// URL is the replica's base URL; gossip is posted to its GossipPath
+	URL string
+	// Secret is the bearer token replicas share
+	Secret string
+	// Client sends the requests
+	Client *http.Client
+}
+
+// NewHTTPGossipPeer creates a peer for the replica at baseURL.
+func NewHTTPGossipPeer(baseURL, secret string) *HTTPGossipPeer {
+	return &HTTPGossipPeer{
+		URL:    strings.TrimSuffix(baseURL, "/"),
+		Secret: secret,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// ID returns the peer's URL, which identifies it among the configured peers.
+func (p *HTTPGossipPeer) ID() string {
+	return p.URL
+}
+
+// Receive posts messages to the peer.
+func (p *HTTPGossipPeer) Receive(ctx context.Context, messages []GossipMessage) error {
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("encoding gossip: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+GossipPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Secret)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrGossipPeer, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s: %s", ErrGossipPeer, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler peers post gossip to. Requests must
+// carry secret as a bearer token; an empty secret accepts none, so a
+// replica without one can gossip out but not be changed by others.
+func (g *AffinityGossip) Handler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			errdefs.WriteError(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if secret == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			errdefs.WriteError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var messages []GossipMessage
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGossipBody)).Decode(&messages); err != nil {
+			errdefs.WriteError(w, "invalid gossip: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := g.Receive(r.Context(), messages); err != nil {
+			errdefs.WriteHTTP(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPGossipPeer(t *testing.T) {
+	nodes := newTestGossipCluster(2, 1)
+	a, b := NewAffinityGossip(nodes[0].config, NewAgentAffinityGraph(), nil), nodes[1]
+	mux := http.NewServeMux()
+	mux.Handle(GossipPath, b.Handler("shared"))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	a.AddPeer(NewHTTPGossipPeer(server.URL+"/", "shared"))
+	a.affinity.RecordCollaboration("APEX", "CIPHER", true)
+	if err := a.Round(context.Background()); err != nil {
+		t.Fatalf("Round failed: %v", err)
+	}
+	if b.affinity.totalCount["APEX"]["CIPHER"] != 1 {
+		t.Errorf("Expected the delta applied over HTTP, got %d collaborations", b.affinity.totalCount["APEX"]["CIPHER"])
+	}
+
+	// Replicas without the secret can't change routing state
+	intruder := NewAffinityGossip(DefaultGossipConfig("intruder"), NewAgentAffinityGraph(), nil)
+	intruder.AddPeer(NewHTTPGossipPeer(server.URL, "guess"))
+	intruder.affinity.RecordCollaboration("APEX", "AXIOM", true)
+	if err := intruder.Round(context.Background()); !errors.Is(err, ErrGossipPeer) {
+		t.Errorf("Expected the peer to reject a wrong secret, got %v", err)
+	}
+	if stats := intruder.GetStats(); stats.SendFailures != 1 {
+		t.Errorf("Expected 1 send failure, got %d", stats.SendFailures)
+	}
+	if b.affinity.totalCount["APEX"]["AXIOM"] != 0 {
+		t.Error("Expected gossip with a wrong secret ignored")
+	}
+
+	resp, err := http.Get(server.URL + GossipPath)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for a GET, got %d", resp.StatusCode)
+	}
+}
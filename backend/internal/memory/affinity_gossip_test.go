@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Affinity Gossip Tests
+// ============================================================================
+
+// newTestGossipCluster creates fully connected replicas with fresh routing state.
+func newTestGossipCluster(n, fanout int) []*AffinityGossip {
+	nodes := make([]*AffinityGossip, n)
+	for i := range nodes {
+		config := DefaultGossipConfig(string(rune('a' + i)))
+		config.Fanout = fanout
+		nodes[i] = NewAffinityGossip(config, NewAgentAffinityGraph(), NewCollaborativeAttentionIndex())
+		// Fixed seeds keep peer selection reproducible
+		nodes[i].rng = rand.New(rand.NewSource(int64(i)))
+	}
+	for _, node := range nodes {
+		for _, peer := range nodes {
+			if peer != node {
+				node.AddPeer(peer)
+			}
+		}
+	}
+	return nodes
+}
+
+func TestAffinityGossip_Round(t *testing.T) {
+	nodes := newTestGossipCluster(2, 1)
+	a, b := nodes[0], nodes[1]
+
+	a.affinity.RecordCollaboration("APEX", "CIPHER", true)
+	a.affinity.RecordCollaboration("APEX", "CIPHER", true)
+	if err := a.Round(context.Background()); err != nil {
+		t.Fatalf("Round failed: %v", err)
+	}
+
+	want := a.affinity.GetAffinityScore("APEX", "CIPHER")
+	got := b.affinity.GetAffinityScore("APEX", "CIPHER")
+	if math.Abs(want-got) > 1e-9 {
+		t.Errorf("Expected affinity %f, got %f", want, got)
+	}
+	if got := b.affinity.GetAffinityScore("CIPHER", "APEX"); math.Abs(want-got) > 1e-9 {
+		t.Errorf("Expected symmetric affinity %f, got %f", want, got)
+	}
+	if b.affinity.totalCount["APEX"]["CIPHER"] != 2 {
+		t.Errorf("Expected 2 collaborations, got %d", b.affinity.totalCount["APEX"]["CIPHER"])
+	}
+
+	// Applied deltas are not gossiped back
+	if b.affinity.TakeDelta() != nil {
+		t.Error("Expected applied delta not to be recorded as local change")
+	}
+
+	stats := a.GetStats()
+	if stats.MessagesCreated != 1 || stats.MessagesSent != 1 {
+		t.Errorf("Expected 1 message created and sent, got %+v", stats)
+	}
+}
+
+func TestAffinityGossip_AttentionPropagation(t *testing.T) {
+	nodes := newTestGossipCluster(2, 1)
+	a, b := nodes[0], nodes[1]
+
+	query := "optimize cache performance"
+	for i := 0; i < 5; i++ {
+		a.attention.UpdateAttention(query, "FLUX", true)
+	}
+	a.Round(context.Background())
+
+	routesA := a.attention.RouteQuery(query, 1)
+	routesB := b.attention.RouteQuery(query, 1)
+	if routesA[0].AgentID != "FLUX" || routesB[0].AgentID != "FLUX" {
+		t.Errorf("Expected FLUX routed first on both replicas, got %s and %s",
+			routesA[0].AgentID, routesB[0].AgentID)
+	}
+	if routesB[0].Attention <= b.attention.RouteQuery(query, 2)[1].Attention {
+		t.Error("Expected FLUX attention to rise above the next agent on peer")
+	}
+}
+
+func TestAffinityGossip_Dissemination(t *testing.T) {
+	nodes := newTestGossipCluster(6, 2)
+
+	// Every replica learns something different
+	agents := []string{"CIPHER", "ARCHITECT", "AXIOM", "VELOCITY", "TENSOR", "QUANTUM"}
+	for i, node := range nodes {
+		node.affinity.RecordCollaboration("APEX", agents[i], true)
+	}
+
+	for round := 0; round < 4; round++ {
+		for _, node := range nodes {
+			node.Round(context.Background())
+		}
+	}
+
+	for _, agent := range agents {
+		want := nodes[0].affinity.GetAffinityScore("APEX", agent)
+		for _, node := range nodes[1:] {
+			if got := node.affinity.GetAffinityScore("APEX", agent); math.Abs(want-got) > 1e-9 {
+				t.Errorf("Expected APEX-%s affinity %f on %s, got %f", agent, want, node.ID(), got)
+			}
+		}
+	}
+}
+
+func TestAffinityGossip_DuplicateAndReordered(t *testing.T) {
+	g := NewAffinityGossip(DefaultGossipConfig("local"), NewAgentAffinityGraph(), nil)
+	before := g.affinity.GetAffinityScore("APEX", "CIPHER")
+
+	delta := newAffinityDelta()
+	delta.record("APEX", "CIPHER", 0.1, true)
+	msg1 := GossipMessage{Origin: "remote", Seq: 1, Affinity: delta}
+	msg2 := GossipMessage{Origin: "remote", Seq: 2, Affinity: delta}
+
+	// Out of order, then duplicated along another path
+	g.Receive(context.Background(), []GossipMessage{msg2})
+	g.Receive(context.Background(), []GossipMessage{msg1, msg2})
+	g.Receive(context.Background(), []GossipMessage{msg1})
+
+	got := g.affinity.GetAffinityScore("APEX", "CIPHER")
+	if math.Abs(got-(before+0.2)) > 1e-9 {
+		t.Errorf("Expected affinity %f, got %f", before+0.2, got)
+	}
+	stats := g.GetStats()
+	if stats.MessagesApplied != 2 || stats.DuplicatesIgnored != 2 {
+		t.Errorf("Expected 2 applied and 2 duplicates, got %+v", stats)
+	}
+	if progress := g.seen[originKey{origin: "remote"}]; progress.watermark != 2 || len(progress.ahead) != 0 {
+		t.Errorf("Expected watermark 2 with none ahead, got %d and %v", progress.watermark, progress.ahead)
+	}
+}
+
+func TestAffinityGossip_RestartedOrigin(t *testing.T) {
+	nodes := newTestGossipCluster(2, 1)
+	a, b := nodes[0], nodes[1]
+	a.affinity.RecordCollaboration("APEX", "CIPHER", true)
+	a.Round(context.Background())
+
+	// The replica restarts with the same ID, counting sequences from 1 again
+	restarted := NewAffinityGossip(a.config, NewAgentAffinityGraph(), nil)
+	restarted.AddPeer(b)
+	restarted.affinity.RecordCollaboration("APEX", "AXIOM", true)
+	restarted.Round(context.Background())
+
+	if restarted.incarnation == a.incarnation {
+		t.Fatal("Expected a new incarnation after a restart")
+	}
+	if b.affinity.totalCount["APEX"]["AXIOM"] != 1 {
+		t.Error("Expected the restarted replica's first delta applied, not dropped as a duplicate")
+	}
+	if stats := b.GetStats(); stats.MessagesApplied != 2 || stats.DuplicatesIgnored != 0 {
+		t.Errorf("Expected 2 messages applied, got %+v", stats)
+	}
+}
+
+func TestAffinityGossip_LostMessage(t *testing.T) {
+	clock := NewManualClock(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	config := DefaultGossipConfig("local")
+	config.Clock = clock
+	config.MaxBuffered = 8
+	g := NewAffinityGossip(config, NewAgentAffinityGraph(), nil)
+	key := originKey{origin: "remote", incarnation: 7}
+	message := func(seq uint64) GossipMessage {
+		delta := newAffinityDelta()
+		delta.record("APEX", "CIPHER", 0.01, true)
+		return GossipMessage{Origin: "remote", Incarnation: 7, Seq: seq, Affinity: delta}
+	}
+
+	// Sequence 1 is lost: the watermark waits for it, then gives it up
+	g.Receive(context.Background(), []GossipMessage{message(2), message(3)})
+	if progress := g.seen[key]; progress.watermark != 0 || len(progress.ahead) != 2 {
+		t.Fatalf("Expected the watermark held at the gap, got %d with %d ahead", progress.watermark, len(progress.ahead))
+	}
+	clock.Advance(g.config.GapTimeout)
+	g.Round(context.Background())
+	if progress := g.seen[key]; progress.watermark != 3 || len(progress.ahead) != 0 {
+		t.Errorf("Expected the watermark past the timed out gap, got %d with %d ahead", progress.watermark, len(progress.ahead))
+	}
+
+	// Too many sequences ahead of a gap skip it without waiting
+	var messages []GossipMessage
+	for seq := uint64(5); seq <= 5+uint64(config.MaxBuffered); seq++ {
+		messages = append(messages, message(seq))
+	}
+	g.Receive(context.Background(), messages)
+	if progress := g.seen[key]; progress.watermark != 5+uint64(config.MaxBuffered) || len(progress.ahead) != 0 {
+		t.Errorf("Expected the tracked sequences capped, got watermark %d with %d ahead", progress.watermark, len(progress.ahead))
+	}
+	if stats := g.GetStats(); stats.MessagesSkipped != 2 {
+		t.Errorf("Expected 2 messages given up as lost, got %d", stats.MessagesSkipped)
+	}
+	if g.Receive(context.Background(), []GossipMessage{message(1)}); g.GetStats().MessagesApplied != 11 {
+		t.Errorf("Expected a lost message arriving late ignored, got %d applied", g.GetStats().MessagesApplied)
+	}
+
+	// Origins silent for long are forgotten
+	clock.Advance(originIdleGapTimeouts*g.config.GapTimeout + time.Second)
+	g.Round(context.Background())
+	if _, ok := g.seen[key]; ok {
+		t.Error("Expected the idle origin forgotten")
+	}
+}
+
+// failingPeer is a peer whose transport is down.
+type failingPeer struct{}
+
+func (failingPeer) ID() string { return "down" }
+
+func (failingPeer) Receive(ctx context.Context, messages []GossipMessage) error {
+	return errors.New("connection refused")
+}
+
+func TestAffinityGossip_SendFailure(t *testing.T) {
+	config := DefaultGossipConfig("local")
+	config.ForwardRounds = 2
+	g := NewAffinityGossip(config, NewAgentAffinityGraph(), nil)
+	g.AddPeer(failingPeer{})
+
+	g.affinity.RecordCollaboration("APEX", "CIPHER", true)
+	if err := g.Round(context.Background()); err == nil {
+		t.Error("Expected error from failing peer")
+	}
+	if len(g.buffer) != 1 {
+		t.Errorf("Expected message kept for retry, got %d buffered", len(g.buffer))
+	}
+	g.Round(context.Background())
+	if len(g.buffer) != 0 {
+		t.Errorf("Expected message dropped after ForwardRounds, got %d buffered", len(g.buffer))
+	}
+	if g.GetStats().SendFailures != 2 {
+		t.Errorf("Expected 2 send failures, got %d", g.GetStats().SendFailures)
+	}
+}
+
+func TestAffinityGossip_StartStop(t *testing.T) {
+	nodes := newTestGossipCluster(2, 1)
+	a, b := nodes[0], nodes[1]
+	a.config.Interval = 5 * time.Millisecond
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := a.Start(context.Background()); !errors.Is(err, ErrGossipRunning) {
+		t.Errorf("Expected ErrGossipRunning, got %v", err)
+	}
+
+	a.affinity.RecordCollaboration("APEX", "CIPHER", false)
+	want := a.affinity.GetAffinityScore("APEX", "CIPHER")
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && math.Abs(b.affinity.GetAffinityScore("APEX", "CIPHER")-want) > 1e-9 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	done := a.done
+	a.Stop()
+	select {
+	case <-done:
+	default:
+		t.Error("Expected the gossip loop to have returned")
+	}
+
+	if got := b.affinity.GetAffinityScore("APEX", "CIPHER"); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected affinity %f after background gossip, got %f", want, got)
+	}
+}
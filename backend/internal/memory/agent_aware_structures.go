@@ -45,6 +45,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -76,9 +77,52 @@ type AgentAffinityGraph struct {
 	// Decay factor for temporal relevance
 	decayFactor float64
 
+	// Local changes not yet taken for gossip to other replicas
+	pending *AffinityDelta
+
 	mu sync.RWMutex
 }
 
+// AffinityDelta is an additive change to affinity scores and collaboration
+// counts, keyed by agent pair in sorted order. Deltas commute, so replicas
+// can apply them in any order.
+type AffinityDelta struct {
+	Affinity  map[string]map[string]float64 `json:"affinity"`
+	Successes map[string]map[string]int     `json:"successes"`
+	Totals    map[string]map[string]int     `json:"totals"`
+}
+
+// newAffinityDelta creates an empty delta.
+func newAffinityDelta() *AffinityDelta {
+	return &AffinityDelta{
+		Affinity:  make(map[string]map[string]float64),
+		Successes: make(map[string]map[string]int),
+		Totals:    make(map[string]map[string]int),
+	}
+}
+
+// record adds one collaboration outcome to the delta.
+func (d *AffinityDelta) record(agent1, agent2 string, change float64, success bool) {
+	if agent2 < agent1 {
+		agent1, agent2 = agent2, agent1
+	}
+	if d.Affinity[agent1] == nil {
+		d.Affinity[agent1] = make(map[string]float64)
+		d.Successes[agent1] = make(map[string]int)
+		d.Totals[agent1] = make(map[string]int)
+	}
+	d.Affinity[agent1][agent2] += change
+	d.Totals[agent1][agent2]++
+	if success {
+		d.Successes[agent1][agent2]++
+	}
+}
+
+// Empty returns true if the delta records no collaborations.
+func (d *AffinityDelta) Empty() bool {
+	return d == nil || len(d.Totals) == 0
+}
+
 // NewAgentAffinityGraph creates a new affinity graph for all 40 agents.
 func NewAgentAffinityGraph() *AgentAffinityGraph {
 	g := &AgentAffinityGraph{
@@ -88,6 +132,7 @@ func NewAgentAffinityGraph() *AgentAffinityGraph {
 		successCount: make(map[string]map[string]int),
 		totalCount:   make(map[string]map[string]int),
 		decayFactor:  0.95, // 5% decay per update cycle
+		pending:      newAffinityDelta(),
 	}
 
 	// Initialize with the 40 Elite Agents and their tiers
@@ -140,6 +185,38 @@ func (g *AgentAffinityGraph) RecordCollaboration(agent1, agent2 string, success
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	// Lazy routing table rebuild (every 100 updates or on demand)
+	if g.recordLocked(agent1, agent2, success) {
+		g.rebuildRoutingTables()
+	}
+}
+
+// CollaborationOutcome is one collaboration result for RecordCollaborations.
+type CollaborationOutcome struct {
+	Agent1  string
+	Agent2  string
+	Success bool
+}
+
+// RecordCollaborations records many outcomes under one lock and rebuilds
+// the routing tables once at the end.
+func (g *AgentAffinityGraph) RecordCollaborations(outcomes []CollaborationOutcome) {
+	if len(outcomes) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, outcome := range outcomes {
+		g.recordLocked(outcome.Agent1, outcome.Agent2, outcome.Success)
+	}
+	g.rebuildRoutingTables()
+}
+
+// recordLocked records one outcome and reports whether the routing tables
+// are due for a rebuild. The caller must hold g.mu.
+func (g *AgentAffinityGraph) recordLocked(agent1, agent2 string, success bool) bool {
 	// Update counts
 	if g.totalCount[agent1] == nil {
 		g.totalCount[agent1] = make(map[string]int)
@@ -155,6 +232,11 @@ func (g *AgentAffinityGraph) RecordCollaboration(agent1, agent2 string, success
 		g.totalCount[agent2] = make(map[string]int)
 		g.successCount[agent2] = make(map[string]int)
 	}
+	for _, agent := range []string{agent1, agent2} {
+		if g.affinity[agent] == nil {
+			g.affinity[agent] = make(map[string]float64)
+		}
+	}
 	g.totalCount[agent2][agent1]++
 	if success {
 		g.successCount[agent2][agent1]++
@@ -176,14 +258,12 @@ func (g *AgentAffinityGraph) RecordCollaboration(agent1, agent2 string, success
 		g.affinity[agent1][agent2] = math.Max(0.1, baseAffinity*0.95)
 	}
 	g.affinity[agent2][agent1] = g.affinity[agent1][agent2]
+	g.pending.record(agent1, agent2, g.affinity[agent1][agent2]-baseAffinity, success)
 
 	// Also maintain long-term success rate as a separate metric
 	_ = successRate // Stored in successCount/totalCount for advanced analytics
 
-	// Lazy routing table rebuild (every 100 updates or on demand)
-	if (g.totalCount[agent1][agent2]+g.totalCount[agent2][agent1])%100 == 0 {
-		g.rebuildRoutingTables()
-	}
+	return (g.totalCount[agent1][agent2]+g.totalCount[agent2][agent1])%100 == 0
 }
 
 // GetTopCollaborators returns the top k collaborators for an agent in O(1).
@@ -213,19 +293,26 @@ func (g *AgentAffinityGraph) GetAffinityScore(agent1, agent2 string) float64 {
 	return 0
 }
 
+// maxTeamWalkSteps bounds the random walk of SuggestCollaborationTeam, so
+// a seed with too few collaborators yields a smaller team instead of
+// walking forever.
+const maxTeamWalkSteps = 10000
+
 // SuggestCollaborationTeam suggests a team of agents for a task.
-// Uses random walk with restart to find a cohesive team.
+// Uses random walk with restart to find a cohesive team. The seed comes
+// first, then the agents in the order the walk reached them.
 func (g *AgentAffinityGraph) SuggestCollaborationTeam(seedAgent string, teamSize int) []string {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
 	team := make(map[string]bool)
 	team[seedAgent] = true
+	result := []string{seedAgent}
 
 	current := seedAgent
 	restartProb := 0.15 // Probability to restart at seed
 
-	for len(team) < teamSize {
+	for steps := 0; len(team) < teamSize && steps < maxTeamWalkSteps; steps++ {
 		if rand.Float64() < restartProb {
 			current = seedAgent
 		} else {
@@ -250,6 +337,7 @@ func (g *AgentAffinityGraph) SuggestCollaborationTeam(seedAgent string, teamSize
 					cumulative += aff
 					if cumulative >= r {
 						team[other] = true
+						result = append(result, other)
 						current = other
 						break
 					}
@@ -258,13 +346,57 @@ func (g *AgentAffinityGraph) SuggestCollaborationTeam(seedAgent string, teamSize
 		}
 	}
 
-	result := make([]string, 0, len(team))
-	for agent := range team {
-		result = append(result, agent)
-	}
 	return result
 }
 
+// TakeDelta returns the changes recorded since the last call and resets
+// them, or nil if nothing changed.
+func (g *AgentAffinityGraph) TakeDelta() *AffinityDelta {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.pending.Empty() {
+		return nil
+	}
+	delta := g.pending
+	g.pending = newAffinityDelta()
+	return delta
+}
+
+// ApplyDelta merges changes recorded by another replica. Applied deltas are
+// not recorded again, so they are never gossiped back.
+func (g *AgentAffinityGraph) ApplyDelta(delta *AffinityDelta) {
+	if delta.Empty() {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for agent1, totals := range delta.Totals {
+		for agent2, total := range totals {
+			successes := delta.Successes[agent1][agent2]
+			for _, pair := range [][2]string{{agent1, agent2}, {agent2, agent1}} {
+				if g.totalCount[pair[0]] == nil {
+					g.totalCount[pair[0]] = make(map[string]int)
+					g.successCount[pair[0]] = make(map[string]int)
+				}
+				if g.affinity[pair[0]] == nil {
+					g.affinity[pair[0]] = make(map[string]float64)
+				}
+				g.totalCount[pair[0]][pair[1]] += total
+				g.successCount[pair[0]][pair[1]] += successes
+			}
+
+			score := clamp(g.affinity[agent1][agent2]+delta.Affinity[agent1][agent2], 0.1, 2.0)
+			g.affinity[agent1][agent2] = score
+			g.affinity[agent2][agent1] = score
+		}
+	}
+
+	g.rebuildRoutingTables()
+}
+
 // rebuildRoutingTables rebuilds the pre-computed routing tables.
 func (g *AgentAffinityGraph) rebuildRoutingTables() {
 	for agent, affinities := range g.affinity {
@@ -448,14 +580,39 @@ func tokenizeForResonance(content string) []string {
 // Query with required skills cascades through filters, eliminating non-matches.
 
 // SkillBloomCascade enables fast multi-skill agent matching.
+//
+// Lookups read an immutable snapshot through an atomic pointer and never
+// lock; AddAgent copies the snapshot, changes the copy, and swaps it in.
 type SkillBloomCascade struct {
+	state atomic.Pointer[skillCascadeState]
+
+	// mu serializes writers; readers never take it
+	mu sync.Mutex
+}
+
+// skillCascadeState is an immutable snapshot of the cascade.
+type skillCascadeState struct {
 	// Per-agent skill filters
 	agentFilters map[string]*SkillFilter
 
 	// Inverted index: skill -> agents (for cascade optimization)
 	skillIndex map[string][]string
+}
 
-	mu sync.RWMutex
+// clone copies the snapshot's maps; filters and index slices are shared
+// and must not be modified in place.
+func (s *skillCascadeState) clone() *skillCascadeState {
+	next := &skillCascadeState{
+		agentFilters: make(map[string]*SkillFilter, len(s.agentFilters)+1),
+		skillIndex:   make(map[string][]string, len(s.skillIndex)),
+	}
+	for agentID, filter := range s.agentFilters {
+		next.agentFilters[agentID] = filter
+	}
+	for skill, agents := range s.skillIndex {
+		next.skillIndex[skill] = agents
+	}
+	return next
 }
 
 // SkillFilter is a Bloom filter for an agent's skills.
@@ -469,7 +626,8 @@ type SkillFilter struct {
 
 // NewSkillBloomCascade creates a new skill cascade.
 func NewSkillBloomCascade() *SkillBloomCascade {
-	c := &SkillBloomCascade{
+	c := &SkillBloomCascade{}
+	state := &skillCascadeState{
 		agentFilters: make(map[string]*SkillFilter),
 		skillIndex:   make(map[string][]string),
 	}
@@ -519,8 +677,9 @@ func NewSkillBloomCascade() *SkillBloomCascade {
 	}
 
 	for agent, skills := range agentSkills {
-		c.AddAgent(agent, skills)
+		state.addAgent(agent, skills)
 	}
+	c.state.Store(state)
 
 	return c
 }
@@ -530,6 +689,13 @@ func (c *SkillBloomCascade) AddAgent(agentID string, skills []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	next := c.state.Load().clone()
+	next.addAgent(agentID, skills)
+	c.state.Store(next)
+}
+
+// addAgent adds an agent to a snapshot not yet published.
+func (s *skillCascadeState) addAgent(agentID string, skills []string) {
 	filter := &SkillFilter{
 		size:    512,
 		numHash: 4,
@@ -542,14 +708,13 @@ func (c *SkillBloomCascade) AddAgent(agentID string, skills []string) {
 		normalizedSkill := strings.ToLower(skill)
 		filter.addSkill(normalizedSkill)
 
-		// Update inverted index
-		if c.skillIndex[normalizedSkill] == nil {
-			c.skillIndex[normalizedSkill] = []string{}
-		}
-		c.skillIndex[normalizedSkill] = append(c.skillIndex[normalizedSkill], agentID)
+		// Update inverted index; capping capacity makes append copy, so
+		// slices shared with earlier snapshots are never written
+		agents := s.skillIndex[normalizedSkill]
+		s.skillIndex[normalizedSkill] = append(agents[:len(agents):len(agents)], agentID)
 	}
 
-	c.agentFilters[agentID] = filter
+	s.agentFilters[agentID] = filter
 }
 
 // addSkill adds a skill to the filter.
@@ -580,8 +745,7 @@ func (f *SkillFilter) hasSkill(skill string) bool {
 // FindAgentsWithSkills finds agents matching required skills using cascade.
 // Returns agents in order of skill match count. O(k) where k = skills checked.
 func (c *SkillBloomCascade) FindAgentsWithSkills(requiredSkills []string) []string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	state := c.state.Load()
 
 	// Start with agents matching first skill (using inverted index)
 	if len(requiredSkills) == 0 {
@@ -601,7 +765,7 @@ func (c *SkillBloomCascade) FindAgentsWithSkills(requiredSkills []string) []stri
 	}
 	scores := []agentScore{}
 
-	for agentID, filter := range c.agentFilters {
+	for agentID, filter := range state.agentFilters {
 		matchCount := 0
 		for _, skill := range normalizedSkills {
 			if filter.hasSkill(skill) {
@@ -644,11 +808,20 @@ type TemporalDecaySketch struct {
 	depth    int
 	halfLife time.Duration // Time for count to decay to half
 
+	// clock stamps and decays counts
+	clock Clock
+
 	mu sync.RWMutex
 }
 
 // NewTemporalDecaySketch creates a new temporal decay sketch.
 func NewTemporalDecaySketch(halfLife time.Duration) *TemporalDecaySketch {
+	return NewTemporalDecaySketchWithClock(halfLife, SystemClock)
+}
+
+// NewTemporalDecaySketchWithClock creates a temporal decay sketch that reads
+// time from clock; a nil clock uses SystemClock.
+func NewTemporalDecaySketchWithClock(halfLife time.Duration, clock Clock) *TemporalDecaySketch {
 	width := 1024
 	depth := 4
 
@@ -658,9 +831,10 @@ func NewTemporalDecaySketch(halfLife time.Duration) *TemporalDecaySketch {
 		width:      width,
 		depth:      depth,
 		halfLife:   halfLife,
+		clock:      clockOrSystem(clock),
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	for i := 0; i < depth; i++ {
 		s.counts[i] = make([]float64, width)
 		s.timestamps[i] = make([]time.Time, width)
@@ -687,7 +861,11 @@ func (s *TemporalDecaySketch) hash(key string, seed int) int {
 
 // decay computes the decay factor based on time elapsed.
 func (s *TemporalDecaySketch) decay(lastUpdate time.Time) float64 {
-	elapsed := time.Since(lastUpdate)
+	elapsed := s.clock.Now().Sub(lastUpdate)
+	if elapsed < 0 {
+		// The clock stepped back; counts never grow by waiting
+		return 1
+	}
 	// Exponential decay: count * 2^(-elapsed/halfLife)
 	return math.Pow(2, -float64(elapsed)/float64(s.halfLife))
 }
@@ -702,7 +880,7 @@ func (s *TemporalDecaySketch) AddWithWeight(key string, weight float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now()
+	now := s.clock.Now()
 	for i := 0; i < s.depth; i++ {
 		idx := s.hash(key, i)
 
@@ -740,7 +918,7 @@ func (s *TemporalDecaySketch) EstimateRecent(key string, window time.Duration) f
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	cutoff := time.Now().Add(-window)
+	cutoff := s.clock.Now().Add(-window)
 	minCount := math.MaxFloat64
 
 	for i := 0; i < s.depth; i++ {
@@ -769,12 +947,12 @@ func (s *TemporalDecaySketch) EstimateRecent(key string, window time.Duration) f
 // Queries are routed to agents with highest attention scores.
 
 // CollaborativeAttentionIndex routes queries to agents using attention.
+//
+// Routing reads an immutable snapshot of the weights through an atomic
+// pointer and never waits on learning: feedback copies the categories it
+// changes into a new snapshot and swaps it in.
 type CollaborativeAttentionIndex struct {
-	// Learned attention weights: pattern category -> agent -> weight
-	attentionWeights map[string]map[string]float64
-
-	// Pattern embeddings (simplified as keyword sets)
-	patternCategories map[string][]string
+	state atomic.Pointer[attentionState]
 
 	// Agent capability vectors
 	agentCapabilities map[string][]float64
@@ -782,20 +960,82 @@ type CollaborativeAttentionIndex struct {
 	// Learning rate for weight updates
 	learningRate float64
 
-	mu sync.RWMutex
+	// Local changes not yet taken for gossip to other replicas
+	pending *AttentionDelta
+
+	// mu serializes writers; readers never take it
+	mu sync.Mutex
+}
+
+// attentionState is an immutable snapshot of the routing weights.
+type attentionState struct {
+	// Learned attention weights: pattern category -> agent -> weight
+	weights map[string]map[string]float64
+
+	// Pattern embeddings (simplified as keyword sets)
+	categories map[string][]string
+}
+
+// attentionUpdate builds the next snapshot from the current one, copying
+// each category's weights the first time it is changed. Unchanged
+// categories are shared between snapshots.
+type attentionUpdate struct {
+	next   *attentionState
+	copied map[string]bool
+}
+
+// newAttentionUpdate starts an update of a snapshot.
+func newAttentionUpdate(base *attentionState) *attentionUpdate {
+	next := &attentionState{
+		weights:    make(map[string]map[string]float64, len(base.weights)),
+		categories: base.categories,
+	}
+	for category, weights := range base.weights {
+		next.weights[category] = weights
+	}
+	return &attentionUpdate{next: next, copied: make(map[string]bool)}
+}
+
+// category returns a category's weights, safe to modify.
+func (u *attentionUpdate) category(category string) map[string]float64 {
+	if !u.copied[category] {
+		copied := make(map[string]float64, len(u.next.weights[category]))
+		for agent, w := range u.next.weights[category] {
+			copied[agent] = w
+		}
+		u.next.weights[category] = copied
+		u.copied[category] = true
+	}
+	return u.next.weights[category]
+}
+
+// AttentionDelta holds raw attention adjustments, keyed by category and
+// agent, made before normalization.
+type AttentionDelta struct {
+	Weights map[string]map[string]float64 `json:"weights"`
+}
+
+// newAttentionDelta creates an empty delta.
+func newAttentionDelta() *AttentionDelta {
+	return &AttentionDelta{Weights: make(map[string]map[string]float64)}
+}
+
+// Empty returns true if the delta holds no adjustments.
+func (d *AttentionDelta) Empty() bool {
+	return d == nil || len(d.Weights) == 0
 }
 
 // NewCollaborativeAttentionIndex creates a new attention index.
 func NewCollaborativeAttentionIndex() *CollaborativeAttentionIndex {
 	idx := &CollaborativeAttentionIndex{
-		attentionWeights:  make(map[string]map[string]float64),
-		patternCategories: make(map[string][]string),
 		agentCapabilities: make(map[string][]float64),
 		learningRate:      0.1,
+		pending:           newAttentionDelta(),
 	}
+	state := &attentionState{weights: make(map[string]map[string]float64)}
 
 	// Initialize pattern categories
-	idx.patternCategories = map[string][]string{
+	state.categories = map[string][]string{
 		"coding":        {"implement", "code", "function", "class", "algorithm", "fix", "debug"},
 		"architecture":  {"design", "system", "architecture", "scale", "microservice", "pattern"},
 		"security":      {"secure", "encrypt", "vulnerability", "authentication", "audit"},
@@ -820,21 +1060,22 @@ func NewCollaborativeAttentionIndex() *CollaborativeAttentionIndex {
 		"AEGIS", "LEDGER", "PULSE", "ARBITER", "ORACLE",
 	}
 
-	for category := range idx.patternCategories {
-		idx.attentionWeights[category] = make(map[string]float64)
+	for category := range state.categories {
+		state.weights[category] = make(map[string]float64)
 		for _, agent := range allAgents {
-			idx.attentionWeights[category][agent] = 1.0 / float64(len(allAgents))
+			state.weights[category][agent] = 1.0 / float64(len(allAgents))
 		}
 	}
 
 	// Set prior attention based on agent specializations
-	idx.setPriorAttention()
+	setPriorAttention(state.weights)
+	idx.state.Store(state)
 
 	return idx
 }
 
 // setPriorAttention sets initial attention based on agent specializations.
-func (idx *CollaborativeAttentionIndex) setPriorAttention() {
+func setPriorAttention(attentionWeights map[string]map[string]float64) {
 	priors := map[string]map[string]float64{
 		"coding":        {"APEX": 0.3, "CORE": 0.2, "ECLIPSE": 0.15},
 		"architecture":  {"ARCHITECT": 0.4, "APEX": 0.2, "ATLAS": 0.15},
@@ -850,34 +1091,26 @@ func (idx *CollaborativeAttentionIndex) setPriorAttention() {
 
 	for category, weights := range priors {
 		for agent, weight := range weights {
-			idx.attentionWeights[category][agent] = weight
+			attentionWeights[category][agent] = weight
 		}
 	}
 
 	// Normalize weights
-	for category := range idx.attentionWeights {
-		total := 0.0
-		for _, w := range idx.attentionWeights[category] {
-			total += w
-		}
-		for agent := range idx.attentionWeights[category] {
-			idx.attentionWeights[category][agent] /= total
-		}
+	for _, weights := range attentionWeights {
+		normalizeWeights(weights)
 	}
 }
 
 // RouteQuery routes a query to the most relevant agents using attention.
 // Returns top k agents with their attention scores. O(categories * agents) = O(1)
 func (idx *CollaborativeAttentionIndex) RouteQuery(query string, topK int) []AgentAttention {
-	idx.mu.RLock()
-	defer idx.mu.RUnlock()
-
+	state := idx.state.Load()
 	queryLower := strings.ToLower(query)
 
 	// Compute attention scores for each agent
 	agentScores := make(map[string]float64)
 
-	for category, keywords := range idx.patternCategories {
+	for category, keywords := range state.categories {
 		// Check if query matches this category
 		categoryMatch := 0.0
 		for _, kw := range keywords {
@@ -889,7 +1122,7 @@ func (idx *CollaborativeAttentionIndex) RouteQuery(query string, topK int) []Age
 		if categoryMatch > 0 {
 			// Add weighted attention from this category
 			categoryWeight := categoryMatch / float64(len(keywords))
-			for agent, attention := range idx.attentionWeights[category] {
+			for agent, attention := range state.weights[category] {
 				agentScores[agent] += categoryWeight * attention
 			}
 		}
@@ -934,13 +1167,49 @@ func (idx *CollaborativeAttentionIndex) UpdateAttention(query string, selectedAg
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
 
+	update := newAttentionUpdate(idx.state.Load())
+	idx.applyFeedback(update, query, selectedAgent, success)
+	idx.state.Store(update.next)
+}
+
+// AttentionFeedback is one routing outcome for UpdateAttentionBatch.
+type AttentionFeedback struct {
+	Query   string
+	Agent   string
+	Success bool
+}
+
+// UpdateAttentionBatch applies many outcomes in order and publishes the
+// result as one snapshot. Returns the number of category weights adjusted.
+func (idx *CollaborativeAttentionIndex) UpdateAttentionBatch(feedback []AttentionFeedback) int {
+	if len(feedback) == 0 {
+		return 0
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	update := newAttentionUpdate(idx.state.Load())
+	updated := 0
+	for _, f := range feedback {
+		updated += idx.applyFeedback(update, f.Query, f.Agent, f.Success)
+	}
+	idx.state.Store(update.next)
+	return updated
+}
+
+// applyFeedback adjusts the selected agent's weight in every category the
+// query matches and returns how many categories changed. The caller must
+// hold idx.mu.
+func (idx *CollaborativeAttentionIndex) applyFeedback(update *attentionUpdate, query, selectedAgent string, success bool) int {
 	queryLower := strings.ToLower(query)
 	reward := -0.5
 	if success {
 		reward = 1.0
 	}
 
-	for category, keywords := range idx.patternCategories {
+	updated := 0
+	for category, keywords := range update.next.categories {
 		categoryMatch := 0.0
 		for _, kw := range keywords {
 			if strings.Contains(queryLower, kw) {
@@ -949,24 +1218,188 @@ func (idx *CollaborativeAttentionIndex) UpdateAttention(query string, selectedAg
 		}
 
 		if categoryMatch > 0 {
-			// Update attention for selected agent
-			currentWeight := idx.attentionWeights[category][selectedAgent]
-			newWeight := currentWeight + idx.learningRate*reward*(1-currentWeight)
-			if newWeight < 0.01 {
-				newWeight = 0.01
+			idx.applyOutcome(update, category, selectedAgent, reward)
+			updated++
+		}
+	}
+	return updated
+}
+
+// applyOutcome moves an agent's weight in a category toward one by reward
+// and records the adjustment. The caller must hold idx.mu.
+func (idx *CollaborativeAttentionIndex) applyOutcome(update *attentionUpdate, category, agent string, reward float64) {
+	weights := update.category(category)
+	currentWeight := weights[agent]
+	newWeight := currentWeight + idx.learningRate*reward*(1-currentWeight)
+	if newWeight < 0.01 {
+		newWeight = 0.01
+	}
+	weights[agent] = newWeight
+	if idx.pending.Weights[category] == nil {
+		idx.pending.Weights[category] = make(map[string]float64)
+	}
+	idx.pending.Weights[category][agent] += newWeight - currentWeight
+
+	normalizeWeights(weights)
+}
+
+// MatchCategories returns the categories whose keywords a query mentions,
+// the ones feedback on it would adjust.
+func (idx *CollaborativeAttentionIndex) MatchCategories(query string) []string {
+	queryLower := strings.ToLower(query)
+	var matched []string
+	for category, keywords := range idx.state.Load().categories {
+		for _, kw := range keywords {
+			if strings.Contains(queryLower, kw) {
+				matched = append(matched, category)
+				break
 			}
-			idx.attentionWeights[category][selectedAgent] = newWeight
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// AttentionCount is an agent's outcomes in one category, for
+// UpdateAttentionCounts.
+type AttentionCount struct {
+	Category  string
+	Agent     string
+	Successes int
+	Failures  int
+}
+
+// UpdateAttentionCounts applies outcomes counted per category rather than
+// per query, as released by a PrivateAggregator, and publishes the result
+// as one snapshot. Each count's successes and failures are interleaved so
+// that neither is applied all at once. Unknown categories are skipped.
+// Returns the number of category weights adjusted.
+func (idx *CollaborativeAttentionIndex) UpdateAttentionCounts(counts []AttentionCount) int {
+	if len(counts) == 0 {
+		return 0
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
 
-			// Normalize
-			total := 0.0
-			for _, w := range idx.attentionWeights[category] {
-				total += w
+	update := newAttentionUpdate(idx.state.Load())
+	updated := 0
+	for _, c := range counts {
+		if _, ok := update.next.categories[c.Category]; !ok {
+			continue
+		}
+		successes, failures := c.Successes, c.Failures
+		for successes > 0 || failures > 0 {
+			if successes > 0 {
+				idx.applyOutcome(update, c.Category, c.Agent, 1.0)
+				successes--
+				updated++
 			}
-			for agent := range idx.attentionWeights[category] {
-				idx.attentionWeights[category][agent] /= total
+			if failures > 0 {
+				idx.applyOutcome(update, c.Category, c.Agent, -0.5)
+				failures--
+				updated++
 			}
 		}
 	}
+	idx.state.Store(update.next)
+	return updated
+}
+
+// normalizeWeights rescales a category's weights to sum to one.
+func normalizeWeights(weights map[string]float64) {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	for agent := range weights {
+		weights[agent] /= total
+	}
+}
+
+// TakeDelta returns the adjustments made since the last call and resets
+// them, or nil if nothing changed.
+func (idx *CollaborativeAttentionIndex) TakeDelta() *AttentionDelta {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.pending.Empty() {
+		return nil
+	}
+	delta := idx.pending
+	idx.pending = newAttentionDelta()
+	return delta
+}
+
+// ApplyDelta merges adjustments made by another replica, renormalizing
+// each affected category. Several local updates are summed into one
+// adjustment, so peers approximate rather than reproduce the local weights.
+// Unknown categories are ignored.
+func (idx *CollaborativeAttentionIndex) ApplyDelta(delta *AttentionDelta) {
+	if delta.Empty() {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	update := newAttentionUpdate(idx.state.Load())
+	for category, adjustments := range delta.Weights {
+		if _, ok := update.next.weights[category]; !ok {
+			continue
+		}
+		weights := update.category(category)
+		for agent, adjustment := range adjustments {
+			weights[agent] = math.Max(0.01, weights[agent]+adjustment)
+		}
+		normalizeWeights(weights)
+	}
+	idx.state.Store(update.next)
+}
+
+// SetWeights replaces the weights of categories, e.g. with ones trained
+// offline, normalizing each category's weights to sum to one. It returns
+// the categories replaced and those ignored because the index does not
+// route by them, each sorted.
+func (idx *CollaborativeAttentionIndex) SetWeights(weights map[string]map[string]float64) (replaced, ignored []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	replaced = make([]string, 0, len(weights))
+	update := newAttentionUpdate(idx.state.Load())
+	for category, agents := range weights {
+		if _, ok := update.next.weights[category]; !ok {
+			ignored = append(ignored, category)
+			continue
+		}
+		next := make(map[string]float64, len(agents))
+		for agent, w := range agents {
+			next[agent] = w
+		}
+		normalizeWeights(next)
+		update.next.weights[category] = next
+		update.copied[category] = true
+		replaced = append(replaced, category)
+	}
+	idx.state.Store(update.next)
+	sort.Strings(replaced)
+	sort.Strings(ignored)
+	return replaced, ignored
+}
+
+// Weights returns a copy of the current attention weights, by category
+// and agent.
+func (idx *CollaborativeAttentionIndex) Weights() map[string]map[string]float64 {
+	state := idx.state.Load()
+	weights := make(map[string]map[string]float64, len(state.weights))
+	for category, agents := range state.weights {
+		copied := make(map[string]float64, len(agents))
+		for agent, w := range agents {
+			copied[agent] = w
+		}
+		weights[category] = copied
+	}
+	return weights
 }
 
 // ============================================================================
@@ -990,6 +1423,9 @@ type EmergentInsightDetector struct {
 	// Threshold for detecting breakthrough
 	surpriseThreshold float64
 
+	// onBreakthrough is called with each surprising success
+	onBreakthrough func(SurpriseEvent)
+
 	mu sync.RWMutex
 }
 
@@ -1081,11 +1517,23 @@ func (d *EmergentInsightDetector) RecordOutcome(agents []string, taskType string
 			d.surpriseBuffer = d.surpriseBuffer[1:]
 		}
 		d.surpriseBuffer = append(d.surpriseBuffer, event)
+
+		if d.onBreakthrough != nil {
+			d.onBreakthrough(event)
+		}
 	}
 
 	return surprise
 }
 
+// OnBreakthrough sets callback for surprising successes. It is called
+// with the detector locked, so it must not block or use the detector.
+func (d *EmergentInsightDetector) OnBreakthrough(fn func(SurpriseEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onBreakthrough = fn
+}
+
 // GetRecentBreakthroughs returns recent surprising successes.
 func (d *EmergentInsightDetector) GetRecentBreakthroughs(limit int) []SurpriseEvent {
 	d.mu.RLock()
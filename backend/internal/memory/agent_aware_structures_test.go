@@ -5,6 +5,8 @@ import (
 	"math"
 	"testing"
 	"time"
+
+	"pgregory.net/rapid"
 )
 
 // ============================================================================
@@ -133,6 +135,21 @@ func TestAgentAffinityGraph_SuggestCollaborationTeam(t *testing.T) {
 	}
 }
 
+func TestAgentAffinityGraph_SuggestCollaborationTeamUnknownSeed(t *testing.T) {
+	g := NewAgentAffinityGraph()
+
+	// An agent with no collaborators can't grow a team; the walk gives up
+	team := g.SuggestCollaborationTeam("UNKNOWN", 3)
+	if len(team) != 1 || team[0] != "UNKNOWN" {
+		t.Errorf("Expected the seed alone, got %v", team)
+	}
+
+	team = g.SuggestCollaborationTeam("APEX", 3)
+	if len(team) != 3 || team[0] != "APEX" {
+		t.Errorf("Expected the seed first in a team of 3, got %v", team)
+	}
+}
+
 // ============================================================================
 // TIER RESONANCE FILTER TESTS
 // ============================================================================
@@ -302,6 +319,24 @@ func TestSkillBloomCascade_AddAgent(t *testing.T) {
 	}
 }
 
+func TestSkillBloomCascade_CopyOnUpdate(t *testing.T) {
+	c := NewSkillBloomCascade()
+	before := c.state.Load()
+	sharedBefore := len(before.skillIndex["kafka"])
+
+	c.AddAgent("CUSTOM_AGENT", []string{"kafka"})
+
+	if _, ok := before.agentFilters["CUSTOM_AGENT"]; ok {
+		t.Error("Expected earlier snapshot unchanged by AddAgent")
+	}
+	if len(before.skillIndex["kafka"]) != sharedBefore {
+		t.Errorf("Expected %d kafka agents in earlier snapshot, got %d", sharedBefore, len(before.skillIndex["kafka"]))
+	}
+	if after := c.state.Load().skillIndex["kafka"]; len(after) != sharedBefore+1 {
+		t.Errorf("Expected %d kafka agents after AddAgent, got %d", sharedBefore+1, len(after))
+	}
+}
+
 // ============================================================================
 // TEMPORAL DECAY SKETCH TESTS
 // ============================================================================
@@ -329,21 +364,21 @@ func TestTemporalDecaySketch_BasicCounting(t *testing.T) {
 }
 
 func TestTemporalDecaySketch_Decay(t *testing.T) {
-	// Create sketch with very short half-life for testing
-	s := NewTemporalDecaySketch(100 * time.Millisecond)
+	clock := NewManualClock(time.Now())
+	s := NewTemporalDecaySketchWithClock(time.Hour, clock)
 
 	// Add items
 	s.Add("decaying_item")
 	initialCount := s.Estimate("decaying_item")
 
-	// Wait for one half-life
-	time.Sleep(150 * time.Millisecond)
+	// Advance by one half-life
+	clock.Advance(time.Hour)
 
 	decayedCount := s.Estimate("decaying_item")
 
-	// Count should have decayed
-	if decayedCount >= initialCount*0.8 {
-		t.Errorf("Count should have decayed: initial=%.4f, after=%.4f",
+	// Count should have halved
+	if math.Abs(decayedCount-initialCount/2) > 1e-9 {
+		t.Errorf("Expected count to halve from %.4f, got %.4f",
 			initialCount, decayedCount)
 	}
 }
@@ -469,6 +504,45 @@ func TestCollaborativeAttentionIndex_UpdateAttention(t *testing.T) {
 	}
 }
 
+func TestCollaborativeAttentionIndex_CopyOnUpdate(t *testing.T) {
+	idx := NewCollaborativeAttentionIndex()
+	before := idx.state.Load()
+	coding := before.weights["coding"]["CORE"]
+	research := before.weights["research"]
+
+	idx.UpdateAttention("implement a function", "CORE", true)
+
+	after := idx.state.Load()
+	if before.weights["coding"]["CORE"] != coding {
+		t.Error("Expected earlier snapshot unchanged by UpdateAttention")
+	}
+	if after.weights["coding"]["CORE"] <= coding {
+		t.Errorf("Expected CORE coding weight above %f, got %f", coding, after.weights["coding"]["CORE"])
+	}
+	if fmt.Sprintf("%p", after.weights["research"]) != fmt.Sprintf("%p", research) {
+		t.Error("Expected unchanged categories shared between snapshots")
+	}
+}
+
+func TestCollaborativeAttentionIndex_ConcurrentRouting(t *testing.T) {
+	idx := NewCollaborativeAttentionIndex()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			idx.UpdateAttention("optimize the cache", "VELOCITY", i%3 != 0)
+		}
+	}()
+
+	// Routing proceeds while learning updates are applied
+	for i := 0; i < 200; i++ {
+		if routing := idx.RouteQuery("optimize the cache", 3); len(routing) != 3 {
+			t.Fatalf("Expected 3 agents, got %d", len(routing))
+		}
+	}
+	<-done
+}
+
 // ============================================================================
 // EMERGENT INSIGHT DETECTOR TESTS
 // ============================================================================
@@ -524,6 +598,29 @@ func TestEmergentInsightDetector_GetRecentBreakthroughs(t *testing.T) {
 	}
 }
 
+func TestEmergentInsightDetector_OnBreakthrough(t *testing.T) {
+	d := NewEmergentInsightDetector()
+	var events []SurpriseEvent
+	d.OnBreakthrough(func(event SurpriseEvent) {
+		events = append(events, event)
+	})
+
+	for i := 0; i < 30; i++ {
+		d.RecordOutcome([]string{"APEX", "VELOCITY"}, "optimization", false, "baseline")
+	}
+	if len(events) != 0 {
+		t.Fatalf("Expected no breakthroughs from failures, got %d", len(events))
+	}
+
+	d.RecordOutcome([]string{"APEX", "VELOCITY"}, "optimization", true, "cache-oblivious layout")
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 breakthrough, got %d", len(events))
+	}
+	if events[0].Strategy != "cache-oblivious layout" || events[0].TaskType != "optimization" {
+		t.Errorf("Expected breakthrough details, got %+v", events[0])
+	}
+}
+
 func TestEmergentInsightDetector_GetUnexpectedPairs(t *testing.T) {
 	d := NewEmergentInsightDetector()
 
@@ -571,13 +668,6 @@ func containsAll(slice []string, items []string) bool {
 	return true
 }
 
-func minInt(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 // ============================================================================
 // BENCHMARKS
 // ============================================================================
@@ -803,13 +893,14 @@ func TestProperty_AffinitySymmetry(t *testing.T) {
 }
 
 func TestProperty_DecayMonotonicity(t *testing.T) {
-	s := NewTemporalDecaySketch(1 * time.Second)
+	clock := NewManualClock(time.Now())
+	s := NewTemporalDecaySketchWithClock(1*time.Second, clock)
 
 	s.Add("test_item")
 
 	prev := s.Estimate("test_item")
 	for i := 0; i < 5; i++ {
-		time.Sleep(200 * time.Millisecond)
+		clock.Advance(200 * time.Millisecond)
 		curr := s.Estimate("test_item")
 		if curr > prev {
 			t.Errorf("Decay should be monotonic: prev=%.4f, curr=%.4f", prev, curr)
@@ -818,11 +909,111 @@ func TestProperty_DecayMonotonicity(t *testing.T) {
 	}
 }
 
+func TestProperty_AffinitySymmetryUnderRandomCollaborations(t *testing.T) {
+	// The 40 agents plus names the graph has never seen
+	agents := append(NewAgentAffinityGraph().GetTopCollaborators("APEX", 39), "APEX", "ROGUE", "NEWCOMER")
+
+	rapid.Check(t, func(t *rapid.T) {
+		g := NewAgentAffinityGraph()
+		agent := rapid.SampledFrom(agents)
+		outcomes := rapid.SliceOf(rapid.Custom(func(t *rapid.T) CollaborationOutcome {
+			return CollaborationOutcome{
+				Agent1:  agent.Draw(t, "agent1"),
+				Agent2:  agent.Draw(t, "agent2"),
+				Success: rapid.Bool().Draw(t, "success"),
+			}
+		})).Draw(t, "outcomes")
+
+		if rapid.Bool().Draw(t, "batched") {
+			g.RecordCollaborations(outcomes)
+		} else {
+			for _, o := range outcomes {
+				g.RecordCollaboration(o.Agent1, o.Agent2, o.Success)
+			}
+		}
+		// A replica built from the gossiped delta must agree too
+		replica := NewAgentAffinityGraph()
+		replica.ApplyDelta(g.TakeDelta())
+
+		for _, graph := range []*AgentAffinityGraph{g, replica} {
+			for _, a := range agents {
+				for _, b := range agents {
+					ab, ba := graph.GetAffinityScore(a, b), graph.GetAffinityScore(b, a)
+					if math.Abs(ab-ba) > 1e-9 {
+						t.Fatalf("Expected symmetric affinity, got %s->%s=%.6f and %s->%s=%.6f", a, b, ab, b, a, ba)
+					}
+				}
+			}
+		}
+		for _, o := range outcomes {
+			score := g.GetAffinityScore(o.Agent1, o.Agent2)
+			if score < 0.1 || score > 2.0 {
+				t.Fatalf("Expected affinity in [0.1, 2.0], got %.4f for %s and %s", score, o.Agent1, o.Agent2)
+			}
+			if replicated := replica.GetAffinityScore(o.Agent1, o.Agent2); math.Abs(replicated-score) > 1e-9 {
+				t.Fatalf("Expected replica affinity %.6f, got %.6f for %s and %s", score, replicated, o.Agent1, o.Agent2)
+			}
+		}
+	})
+}
+
+func TestProperty_DecaySketchMonotonicDecay(t *testing.T) {
+	halfLife := time.Hour
+
+	rapid.Check(t, func(t *rapid.T) {
+		clock := NewManualClock(time.Now())
+		s := NewTemporalDecaySketchWithClock(halfLife, clock)
+		keys := rapid.SampledFrom([]string{"a", "b", "c", "d", "e"})
+		// exact tracks each key's true decayed count; the sketch may only
+		// overestimate it, through collisions
+		exact := make(map[string]float64)
+
+		steps := rapid.IntRange(1, 50).Draw(t, "steps")
+		for i := 0; i < steps; i++ {
+			if rapid.Bool().Draw(t, "add") {
+				key := keys.Draw(t, "key")
+				weight := rapid.Float64Range(0.01, 100).Draw(t, "weight")
+				before := s.Estimate(key)
+				s.AddWithWeight(key, weight)
+				exact[key] += weight
+				if after := s.Estimate(key); after < before+weight*(1-1e-9) {
+					t.Fatalf("Expected adding %.4f to raise %q from %.6f, got %.6f", weight, key, before, after)
+				}
+				continue
+			}
+
+			elapsed := time.Duration(rapid.Int64Range(1, int64(3*halfLife)).Draw(t, "elapsed"))
+			before := make(map[string]float64, len(exact))
+			for key := range exact {
+				before[key] = s.Estimate(key)
+			}
+			clock.Advance(elapsed)
+			factor := math.Pow(2, -float64(elapsed)/float64(halfLife))
+			for key, previous := range before {
+				exact[key] *= factor
+				current := s.Estimate(key)
+				if current > previous {
+					t.Fatalf("Expected %q to decay, got %.6f after %.6f", key, current, previous)
+				}
+				if math.Abs(current-previous*factor) > 1e-9*math.Max(1, previous) {
+					t.Fatalf("Expected %q to decay by %.6f to %.6f, got %.6f", key, factor, previous*factor, current)
+				}
+			}
+		}
+
+		for key, want := range exact {
+			if got := s.Estimate(key); got < want*(1-1e-9) {
+				t.Fatalf("Expected estimate of %q to be at least %.6f, got %.6f", key, want, got)
+			}
+		}
+	})
+}
+
 func TestProperty_AttentionNormalization(t *testing.T) {
 	idx := NewCollaborativeAttentionIndex()
 
 	// Verify attention weights sum to ~1 for each category
-	for category, weights := range idx.attentionWeights {
+	for category, weights := range idx.state.Load().weights {
 		total := 0.0
 		for _, w := range weights {
 			total += w
@@ -833,3 +1024,49 @@ func TestProperty_AttentionNormalization(t *testing.T) {
 		}
 	}
 }
+
+func TestAgentAffinityGraph_ApplyDelta(t *testing.T) {
+	source := NewAgentAffinityGraph()
+	target := NewAgentAffinityGraph()
+
+	source.RecordCollaboration("APEX", "CIPHER", true)
+	source.RecordCollaboration("CIPHER", "APEX", false)
+	delta := source.TakeDelta()
+	if delta == nil {
+		t.Fatal("Expected delta after collaborations")
+	}
+	if source.TakeDelta() != nil {
+		t.Error("Expected TakeDelta to reset pending changes")
+	}
+
+	target.ApplyDelta(delta)
+	want := source.GetAffinityScore("APEX", "CIPHER")
+	if got := target.GetAffinityScore("APEX", "CIPHER"); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected affinity %f, got %f", want, got)
+	}
+	if target.totalCount["CIPHER"]["APEX"] != 2 || target.successCount["CIPHER"]["APEX"] != 1 {
+		t.Errorf("Expected 1 of 2 successes, got %d of %d",
+			target.successCount["CIPHER"]["APEX"], target.totalCount["CIPHER"]["APEX"])
+	}
+}
+
+func TestCollaborativeAttentionIndex_ApplyDelta(t *testing.T) {
+	source := NewCollaborativeAttentionIndex()
+	target := NewCollaborativeAttentionIndex()
+
+	source.UpdateAttention("write unit test coverage", "APEX", true)
+	target.ApplyDelta(source.TakeDelta())
+
+	for category, weights := range source.state.Load().weights {
+		total := 0.0
+		for agent, w := range weights {
+			total += target.state.Load().weights[category][agent]
+			if math.Abs(target.state.Load().weights[category][agent]-w) > 1e-9 {
+				t.Errorf("Expected %s/%s weight %f, got %f", category, agent, w, target.state.Load().weights[category][agent])
+			}
+		}
+		if math.Abs(total-1.0) > 1e-9 {
+			t.Errorf("Expected %s weights to sum to 1, got %f", category, total)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements agent briefings: what the Semantic Network holds on
+// an agent's specialty, prepared once per agent for the warm pool of agent
+// contexts rather than retrieved on every invocation.
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// DefaultBriefingConcepts bounds the concepts a briefing names.
+const DefaultBriefingConcepts = 12
+
+// AgentBriefing briefs agents on the concepts of their specialty. It is
+// safe for concurrent use.
+type AgentBriefing struct {
+	network *SemanticNetwork
+	qa      *QuestionAnswerer
+	limit   int
+}
+
+// NewAgentBriefing creates briefings over a network naming up to limit
+// concepts, or DefaultBriefingConcepts when limit is not positive.
+func NewAgentBriefing(network *SemanticNetwork, limit int) *AgentBriefing {
+	if limit <= 0 {
+		limit = DefaultBriefingConcepts
+	}
+	return &AgentBriefing{network: network, qa: NewQuestionAnswerer(network), limit: limit}
+}
+
+// Prepare returns a system message naming the concepts an agent's
+// specialty and directives mention, then the concepts most strongly
+// related to them. It returns nil when the network knows none of them.
+func (b *AgentBriefing) Prepare(ctx context.Context, agent models.Agent) ([]models.Message, error) {
+	text := agent.Specialty + "\n" + strings.Join(agent.Directives, "\n")
+	mentioned := b.qa.LinkEntities(text)
+	if len(mentioned) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var labels []string
+	add := func(node *SemanticNode) {
+		if !seen[node.ID] && len(labels) < b.limit {
+			seen[node.ID] = true
+			labels = append(labels, node.Label)
+		}
+	}
+	for _, node := range mentioned {
+		add(node)
+	}
+	for _, node := range mentioned {
+		if len(labels) == b.limit {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		relations := append(b.network.GetOutgoingRelations(node.ID), b.network.GetIncomingRelations(node.ID)...)
+		sort.SliceStable(relations, func(i, j int) bool {
+			return relations[i].Weight > relations[j].Weight
+		})
+		for _, rel := range relations {
+			other := rel.TargetID
+			if other == node.ID {
+				other = rel.SourceID
+			}
+			if related, err := b.network.GetNode(other); err == nil {
+				add(related)
+			}
+		}
+	}
+	return []models.Message{{
+		Role:    "system",
+		Content: "Concepts the knowledge graph holds on your specialty: " + strings.Join(labels, ", ") + ". Draw on them where they apply.",
+	}}, nil
+}
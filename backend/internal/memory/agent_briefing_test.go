@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func TestAgentBriefing_Prepare(t *testing.T) {
+	briefing := NewAgentBriefing(buildQANetwork(), 0)
+	ctx := context.Background()
+
+	agent := models.Agent{
+		Codename:   "VELOCITY",
+		Specialty:  "Sorting Algorithm Performance",
+		Directives: []string{"Prefer MergeSort for stable output"},
+	}
+	messages, err := briefing.Prepare(ctx, agent)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	want := "Concepts the knowledge graph holds on your specialty: Sorting Algorithm, MergeSort, Algorithm, QuickSort, Recursion, Extra Memory. Draw on them where they apply."
+	if len(messages) != 1 || messages[0].Role != "system" || messages[0].Content != want {
+		t.Errorf("Expected the mentioned concepts then their relations, got %+v", messages)
+	}
+
+	limited, _ := NewAgentBriefing(buildQANetwork(), 2).Prepare(ctx, agent)
+	if len(limited) != 1 || limited[0].Content != "Concepts the knowledge graph holds on your specialty: Sorting Algorithm, MergeSort. Draw on them where they apply." {
+		t.Errorf("Expected the briefing limited to 2 concepts, got %+v", limited)
+	}
+
+	if messages, _ := briefing.Prepare(ctx, models.Agent{Codename: "HELIX", Specialty: "Bioinformatics"}); messages != nil {
+		t.Errorf("Expected no briefing without known concepts, got %+v", messages)
+	}
+}
@@ -0,0 +1,410 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements anomaly detection on the collective's own metrics.
+//
+// The detector keeps an exponentially weighted baseline - a mean and a
+// variance - of each metric: each agent's error rate and latency, and the
+// rate of impasses. With a season configured, it also keeps a baseline per
+// slot of the season, such as each hour of the day, and judges a value
+// against its slot's baseline once the slot has seen enough values, so
+// daily traffic patterns are not mistaken for degradation. A value that
+// rises further above its baseline than the threshold allows is an
+// anomaly: it is focused as an interrupt on the attention controller and
+// reported to the OnAnomaly callback, so the collective notices its own
+// degradation and operators are told.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// Metrics the detector computes from recorded invocations and impasses.
+const (
+	// MetricErrorRate is the fraction of an agent's invocations that failed
+	MetricErrorRate = "error_rate"
+	// MetricLatency is an agent's mean invocation latency in milliseconds
+	MetricLatency = "latency_ms"
+	// MetricImpasseRate is the number of impasses per window
+	MetricImpasseRate = "impasse_rate"
+)
+
+const (
+	// maxRecentAnomalies bounds the anomalies kept for the admin API
+	maxRecentAnomalies = 100
+	// anomalySalienceFloor is the salience of an anomaly just past the
+	// threshold; it matches the default interrupt threshold
+	anomalySalienceFloor = 0.7
+	// adminDefaultAnomalies and adminMaxAnomalies bound anomaly listings
+	adminDefaultAnomalies = 20
+	adminMaxAnomalies     = maxRecentAnomalies
+)
+
+// AnomalyConfig configures an anomaly detector.
+type AnomalyConfig struct {
+	// Alpha is the weight of each new value in the baselines (0 to 1)
+	Alpha float64
+	// Threshold is how many standard deviations above its baseline a
+	// value must be to be an anomaly
+	Threshold float64
+	// MinObservations is how many values a baseline needs before values
+	// are judged against it
+	MinObservations int
+	// MinDeviation floors the standard deviation, so a metric that has
+	// been constant is not flagged for the smallest change
+	MinDeviation float64
+	// MinInvocations is how many invocations of an agent a window needs
+	// before its error rate and latency are observed
+	MinInvocations int
+	// SeasonPeriod is the length of the season, such as a day; zero keeps
+	// only one baseline per metric
+	SeasonPeriod time.Duration
+	// SeasonSlots is how many slots the season is divided into
+	SeasonSlots int
+	// Cooldown is how long after an anomaly the same metric is not
+	// reported again
+	Cooldown time.Duration
+	// Clock supplies the time (default: SystemClock)
+	Clock Clock
+}
+
+// DefaultAnomalyConfig returns defaults with an hourly seasonal baseline
+// over the day.
+func DefaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{
+		Alpha:           0.1,
+		Threshold:       3.0,
+		MinObservations: 10,
+		MinDeviation:    0.01,
+		MinInvocations:  5,
+		SeasonPeriod:    24 * time.Hour,
+		SeasonSlots:     24,
+		Cooldown:        15 * time.Minute,
+	}
+}
+
+// Anomaly is a metric value far above its baseline.
+type Anomaly struct {
+	ID     string `json:"id"`
+	Metric string `json:"metric"`
+	// Agent is the agent the metric is about; empty for collective metrics
+	Agent string  `json:"agent,omitempty"`
+	Value float64 `json:"value"`
+	// Expected and StdDev are the baseline the value was judged against
+	Expected float64 `json:"expected"`
+	StdDev   float64 `json:"std_dev"`
+	// Score is how many standard deviations above the baseline the value is
+	Score float64 `json:"score"`
+	// Seasonal is true when the value was judged against its season slot
+	Seasonal   bool      `json:"seasonal"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Description describes the anomaly in a sentence.
+func (a *Anomaly) Description() string {
+	subject := a.Metric
+	if a.Agent != "" {
+		subject += " of " + a.Agent
+	}
+	return fmt.Sprintf("%s is %.3g, %.1fσ above the expected %.3g", subject, a.Value, a.Score, a.Expected)
+}
+
+// ewma is an exponentially weighted mean and variance.
+type ewma struct {
+	mean     float64
+	variance float64
+	count    int
+}
+
+// observe adds a value with weight alpha.
+func (e *ewma) observe(value, alpha float64) {
+	if e.count == 0 {
+		e.mean = value
+		e.count = 1
+		return
+	}
+	diff := value - e.mean
+	incr := alpha * diff
+	e.mean += incr
+	e.variance = (1 - alpha) * (e.variance + diff*incr)
+	e.count++
+}
+
+// metricBaseline is a metric's overall baseline and its season slots'.
+type metricBaseline struct {
+	overall ewma
+	slots   []ewma
+}
+
+// invocationWindow accumulates an agent's invocations until the next flush.
+type invocationWindow struct {
+	total    int
+	failures int
+	latency  time.Duration
+}
+
+// AnomalyDetector watches the collective's metrics for degradation. It is
+// safe for concurrent use.
+type AnomalyDetector struct {
+	config    AnomalyConfig
+	clock     Clock
+	attention *AttentionController
+
+	mu          sync.Mutex
+	baselines   map[string]*metricBaseline
+	invocations map[string]*invocationWindow
+	impasses    int
+	lastAlert   map[string]time.Time
+	recent      []*Anomaly
+
+	onAnomaly func(*Anomaly)
+}
+
+// NewAnomalyDetector creates a detector that focuses anomalies as
+// interrupts on attention, which may be nil.
+func NewAnomalyDetector(config AnomalyConfig, attention *AttentionController) *AnomalyDetector {
+	defaults := DefaultAnomalyConfig()
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = defaults.Alpha
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = defaults.Threshold
+	}
+	if config.MinObservations < 1 {
+		config.MinObservations = 1
+	}
+	if config.SeasonPeriod <= 0 || config.SeasonSlots < 1 {
+		config.SeasonPeriod = 0
+		config.SeasonSlots = 0
+	}
+	return &AnomalyDetector{
+		config:      config,
+		clock:       clockOrSystem(config.Clock),
+		attention:   attention,
+		baselines:   make(map[string]*metricBaseline),
+		invocations: make(map[string]*invocationWindow),
+		lastAlert:   make(map[string]time.Time),
+	}
+}
+
+// OnAnomaly sets the callback anomalies are reported to, after they are
+// focused. It is called without the detector's lock held.
+func (d *AnomalyDetector) OnAnomaly(fn func(*Anomaly)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onAnomaly = fn
+}
+
+// RecordInvocation counts an agent invocation towards the agent's error
+// rate and latency for the current window.
+func (d *AnomalyDetector) RecordInvocation(agent string, success bool, latency time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	window, ok := d.invocations[agent]
+	if !ok {
+		window = &invocationWindow{}
+		d.invocations[agent] = window
+	}
+	window.total++
+	if !success {
+		window.failures++
+	}
+	window.latency += latency
+}
+
+// RecordImpasse counts an impasse towards the current window's impasse rate.
+func (d *AnomalyDetector) RecordImpasse() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.impasses++
+}
+
+// Flush ends the current window: it observes each agent's error rate and
+// latency, for agents invoked often enough, and the impasse rate, and
+// returns the anomalies found.
+func (d *AnomalyDetector) Flush() []*Anomaly {
+	d.mu.Lock()
+	now := d.clock.Now()
+	agents := make([]string, 0, len(d.invocations))
+	for agent := range d.invocations {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	var found []*Anomaly
+	for _, agent := range agents {
+		window := d.invocations[agent]
+		if window.total < d.config.MinInvocations {
+			continue
+		}
+		errorRate := float64(window.failures) / float64(window.total)
+		latency := float64(window.latency.Milliseconds()) / float64(window.total)
+		if a := d.observeLocked(MetricErrorRate, agent, errorRate, now); a != nil {
+			found = append(found, a)
+		}
+		if a := d.observeLocked(MetricLatency, agent, latency, now); a != nil {
+			found = append(found, a)
+		}
+	}
+	if a := d.observeLocked(MetricImpasseRate, "", float64(d.impasses), now); a != nil {
+		found = append(found, a)
+	}
+	d.invocations = make(map[string]*invocationWindow)
+	d.impasses = 0
+	onAnomaly := d.onAnomaly
+	d.mu.Unlock()
+
+	d.report(found, onAnomaly)
+	return found
+}
+
+// Observe judges a value of any metric against its baseline and adds it
+// to the baseline. It returns the anomaly if the value is one.
+func (d *AnomalyDetector) Observe(metric, agent string, value float64) *Anomaly {
+	d.mu.Lock()
+	a := d.observeLocked(metric, agent, value, d.clock.Now())
+	onAnomaly := d.onAnomaly
+	d.mu.Unlock()
+
+	if a == nil {
+		return nil
+	}
+	d.report([]*Anomaly{a}, onAnomaly)
+	return a
+}
+
+// Run flushes a window every interval until ctx is done.
+func (d *AnomalyDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Flush()
+		}
+	}
+}
+
+// Recent returns up to n of the latest anomalies, newest first.
+func (d *AnomalyDetector) Recent(n int) []*Anomaly {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n <= 0 || n > len(d.recent) {
+		n = len(d.recent)
+	}
+	recent := make([]*Anomaly, 0, n)
+	for i := len(d.recent) - 1; i >= 0 && len(recent) < n; i-- {
+		a := *d.recent[i]
+		recent = append(recent, &a)
+	}
+	return recent
+}
+
+// observeLocked judges a value against its baseline, then adds it to the
+// baseline. Anomalies are recorded but not yet reported. Must hold lock.
+func (d *AnomalyDetector) observeLocked(metric, agent string, value float64, now time.Time) *Anomaly {
+	key := metric + "/" + agent
+	baseline, ok := d.baselines[key]
+	if !ok {
+		baseline = &metricBaseline{slots: make([]ewma, d.config.SeasonSlots)}
+		d.baselines[key] = baseline
+	}
+
+	var slot *ewma
+	if d.config.SeasonSlots > 0 {
+		slot = &baseline.slots[d.slot(now)]
+	}
+	reference, seasonal := &baseline.overall, false
+	if slot != nil && slot.count >= d.config.MinObservations {
+		reference, seasonal = slot, true
+	}
+
+	var anomaly *Anomaly
+	if reference.count >= d.config.MinObservations {
+		stdDev := math.Max(math.Sqrt(reference.variance), math.Max(d.config.MinDeviation, 0.05*math.Abs(reference.mean)))
+		score := (value - reference.mean) / stdDev
+		if score >= d.config.Threshold && now.Sub(d.lastAlert[key]) >= d.config.Cooldown {
+			anomaly = &Anomaly{
+				ID:         NewID("anomaly"),
+				Metric:     metric,
+				Agent:      agent,
+				Value:      value,
+				Expected:   reference.mean,
+				StdDev:     stdDev,
+				Score:      score,
+				Seasonal:   seasonal,
+				DetectedAt: now,
+			}
+			d.lastAlert[key] = now
+			d.recent = append(d.recent, anomaly)
+			if len(d.recent) > maxRecentAnomalies {
+				d.recent = d.recent[len(d.recent)-maxRecentAnomalies:]
+			}
+		}
+	}
+
+	baseline.overall.observe(value, d.config.Alpha)
+	if slot != nil {
+		slot.observe(value, d.config.Alpha)
+	}
+	return anomaly
+}
+
+// slot returns the season slot a time falls in.
+func (d *AnomalyDetector) slot(t time.Time) int {
+	offset := t.Sub(t.Truncate(d.config.SeasonPeriod))
+	return int(offset * time.Duration(d.config.SeasonSlots) / d.config.SeasonPeriod)
+}
+
+// report focuses anomalies as interrupts and passes them to the callback.
+func (d *AnomalyDetector) report(anomalies []*Anomaly, onAnomaly func(*Anomaly)) {
+	for _, a := range anomalies {
+		if d.attention != nil {
+			// Salience rises from the floor with how far past the
+			// threshold the value is
+			salience := math.Min(1, anomalySalienceFloor+0.05*(a.Score-d.config.Threshold))
+			item := d.attention.NewFocusItem(FocusInterrupt, a, "Anomaly: "+a.Description(), salience)
+			item.SourceID = a.ID
+			item.Metadata["metric"] = a.Metric
+			if a.Agent != "" {
+				item.Metadata["agent"] = a.Agent
+			}
+			d.attention.FocusInterrupt(item)
+		}
+		if onAnomaly != nil {
+			onAnomaly(a)
+		}
+	}
+}
+
+// AnomaliesResponse is the body of GET /admin/memory/anomalies.
+type AnomaliesResponse struct {
+	// Anomalies are the latest anomalies, newest first
+	Anomalies []*Anomaly `json:"anomalies"`
+}
+
+// ServeAnomalies handles GET /admin/memory/anomalies?limit= - returns the
+// latest anomalies.
+func (d *AnomalyDetector) ServeAnomalies(w http.ResponseWriter, r *http.Request) {
+	limit := adminDefaultAnomalies
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > adminMaxAnomalies {
+			errdefs.WriteError(w, "limit must be between 1 and "+strconv.Itoa(adminMaxAnomalies), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	errdefs.WriteJSON(w, http.StatusOK, AnomaliesResponse{Anomalies: d.Recent(limit)})
+}
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetector_ErrorRateRaisesInterrupt(t *testing.T) {
+	clock := NewManualClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	config := DefaultAnomalyConfig()
+	config.SeasonPeriod = 0
+	config.Clock = clock
+	attention := NewAttentionController(&AttentionConfig{
+		Capacity: 7, MinSalience: 0.05, MaxFocusItems: 15, InterruptThreshold: 0.7, Clock: clock,
+	})
+	detector := NewAnomalyDetector(config, attention)
+
+	var reported []*Anomaly
+	detector.OnAnomaly(func(a *Anomaly) { reported = append(reported, a) })
+
+	// A steady baseline: one failure in twenty, 200ms per invocation
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 20; j++ {
+			detector.RecordInvocation("APEX", j != 0, 200*time.Millisecond)
+		}
+		if found := detector.Flush(); len(found) != 0 {
+			t.Fatalf("Expected no anomalies while the baseline builds, got %s", found[0].Description())
+		}
+		clock.Advance(time.Minute)
+	}
+
+	// Half the invocations fail
+	for j := 0; j < 20; j++ {
+		detector.RecordInvocation("APEX", j%2 == 0, 200*time.Millisecond)
+	}
+	found := detector.Flush()
+	if len(found) != 1 || found[0].Metric != MetricErrorRate || found[0].Agent != "APEX" {
+		t.Fatalf("Expected an APEX error rate anomaly, got %v", found)
+	}
+	if a := found[0]; a.Value != 0.5 || a.Expected < 0.04 || a.Expected > 0.06 || a.Score < config.Threshold {
+		t.Errorf("Expected 0.5 against about 0.05, got %+v", a)
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected the anomaly reported once, got %d", len(reported))
+	}
+
+	interrupts := attention.GetFocusedByType(FocusInterrupt)
+	if len(interrupts) != 1 || interrupts[0].SourceID != found[0].ID || interrupts[0].Metadata["agent"] != "APEX" {
+		t.Errorf("Expected the anomaly focused as an interrupt, got %+v", interrupts)
+	}
+
+	// Within the cooldown the same metric is not reported again
+	clock.Advance(time.Minute)
+	for j := 0; j < 20; j++ {
+		detector.RecordInvocation("APEX", j%2 == 0, 200*time.Millisecond)
+	}
+	if found := detector.Flush(); len(found) != 0 {
+		t.Errorf("Expected no anomaly within the cooldown, got %v", found)
+	}
+
+	// Windows with too few invocations are not judged
+	clock.Advance(config.Cooldown)
+	detector.RecordInvocation("APEX", false, time.Second)
+	if found := detector.Flush(); len(found) != 0 {
+		t.Errorf("Expected a sparse window skipped, got %v", found)
+	}
+}
+
+func TestAnomalyDetector_ImpasseRateAndDrops(t *testing.T) {
+	config := DefaultAnomalyConfig()
+	config.SeasonPeriod = 0
+	detector := NewAnomalyDetector(config, nil)
+
+	for i := 0; i < 15; i++ {
+		for j := 0; j < i%3; j++ {
+			detector.RecordImpasse()
+		}
+		detector.Flush()
+	}
+	// Fewer impasses than usual is not degradation
+	if found := detector.Flush(); len(found) != 0 {
+		t.Errorf("Expected a drop not flagged, got %v", found)
+	}
+	for j := 0; j < 12; j++ {
+		detector.RecordImpasse()
+	}
+	found := detector.Flush()
+	if len(found) != 1 || found[0].Metric != MetricImpasseRate || found[0].Agent != "" {
+		t.Errorf("Expected an impasse rate anomaly, got %v", found)
+	}
+}
+
+func TestAnomalyDetector_SeasonalBaseline(t *testing.T) {
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	config := DefaultAnomalyConfig()
+	config.Clock = clock
+	config.MinObservations = 5
+	detector := NewAnomalyDetector(config, nil)
+
+	// Latency is 100ms overnight and 400ms at 9:00, every day
+	for day := 0; day < 10; day++ {
+		for hour := 0; hour < 24; hour++ {
+			clock.Advance(start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour).Sub(clock.Now()))
+			value := 100.0
+			if hour == 9 {
+				value = 400
+			}
+			if a := detector.Observe(MetricLatency, "APEX", value); a != nil && day >= 5 {
+				t.Fatalf("Expected the daily peak learned, got %s on day %d", a.Description(), day)
+			}
+		}
+	}
+
+	// At 3:00 the peak's latency is anomalous
+	clock.Advance(start.AddDate(0, 0, 10).Add(3 * time.Hour).Sub(clock.Now()))
+	a := detector.Observe(MetricLatency, "APEX", 400)
+	if a == nil || !a.Seasonal || a.Expected > 101 {
+		t.Errorf("Expected 400ms at 3:00 judged against the 3:00 baseline, got %+v", a)
+	}
+}
+
+func TestAnomalyDetector_ServeAnomalies(t *testing.T) {
+	config := DefaultAnomalyConfig()
+	config.SeasonPeriod = 0
+	config.MinObservations = 3
+	config.Cooldown = 0
+	detector := NewAnomalyDetector(config, nil)
+	for i := 0; i < 3; i++ {
+		detector.Observe(MetricErrorRate, "APEX", 0)
+	}
+	detector.Observe(MetricErrorRate, "APEX", 0.5)
+	detector.Observe(MetricErrorRate, "APEX", 0.9)
+
+	var resp AnomaliesResponse
+	code := serveAdmin(t, detector.ServeAnomalies, httptest.NewRequest(http.MethodGet, "/admin/memory/anomalies?limit=1", nil), &resp)
+	if code != http.StatusOK || len(resp.Anomalies) != 1 || resp.Anomalies[0].Value != 0.9 {
+		t.Errorf("Expected the newest anomaly, got %d %+v", code, resp.Anomalies)
+	}
+	if code := serveAdmin(t, detector.ServeAnomalies, httptest.NewRequest(http.MethodGet, "/admin/memory/anomalies?limit=x", nil), &resp); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad limit, got %d", code)
+	}
+}
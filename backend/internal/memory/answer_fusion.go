@@ -0,0 +1,489 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements fusion of several agents' answers into one response.
+//
+// Each answer is split into claims: list items, and the sentences of
+// paragraphs. Code blocks, headings and tables are kept but never compared.
+// Claims that say the same thing are merged, and those several agents made
+// are stated once, as points of agreement. Claims about the same thing
+// that differ in polarity ("cache results in Redis" / "don't cache results
+// in Redis") or in their numbers are points of disagreement, shown side by
+// side rather than left for the reader to spot. Disagreements severe
+// enough raise an ImpasseConflict, which is resolved by asking, since the
+// user is shown every position.
+
+package memory
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+// AnswerFusionConfig configures answer fusion.
+type AnswerFusionConfig struct {
+	// SameClaimThreshold is the word overlap (Jaccard) at which two claims
+	// of the same polarity and numbers say the same thing
+	SameClaimThreshold float64
+
+	// SameTopicThreshold is the content word overlap at which two claims
+	// are about the same thing
+	SameTopicThreshold float64
+
+	// ImpasseSeverity is the severity at which a disagreement raises an
+	// ImpasseConflict
+	ImpasseSeverity float64
+}
+
+// DefaultAnswerFusionConfig returns the default fusion configuration.
+func DefaultAnswerFusionConfig() AnswerFusionConfig {
+	return AnswerFusionConfig{
+		SameClaimThreshold: 0.7,
+		SameTopicThreshold: 0.5,
+		ImpasseSeverity:    0.6,
+	}
+}
+
+// minTopicWords is the least content words a claim needs to be compared;
+// shorter ones ("Hope this helps!") say too little to agree or disagree.
+const minTopicWords = 3
+
+// ============================================================================
+// Fused Answer
+// ============================================================================
+
+// FusedClaim is a claim several agents made.
+type FusedClaim struct {
+	Claim  string   `json:"claim"`
+	Agents []string `json:"agents"`
+}
+
+// ConflictPosition is one side of a disagreement.
+type ConflictPosition struct {
+	Agents []string `json:"agents"`
+	Claim  string   `json:"claim"`
+}
+
+// AnswerConflict is a point agents disagree on.
+type AnswerConflict struct {
+	Positions []ConflictPosition `json:"positions"`
+	// Severity is in [0, 1]: how closely the claims are about the same
+	// thing, scaled for differing numbers by how far apart they are
+	Severity float64 `json:"severity"`
+	// ImpasseID is the impasse raised for a significant disagreement
+	ImpasseID string `json:"impasse_id,omitempty"`
+}
+
+// FusedAnswer is several agents' answers merged into one.
+type FusedAnswer struct {
+	Agents     []string         `json:"agents"`
+	Agreements []FusedClaim     `json:"agreements"`
+	Conflicts  []AnswerConflict `json:"conflicts"`
+	// Content is the merged response as markdown: agreements, then
+	// disagreements, then what each agent said besides
+	Content string `json:"content"`
+}
+
+// ============================================================================
+// Claims
+// ============================================================================
+
+// answerClaim is a statement in an agent's answer.
+type answerClaim struct {
+	agent string
+	text  string
+	// words are the claim's words without negations; topic are its
+	// content words, without stopwords or numbers
+	words   map[string]bool
+	topic   map[string]bool
+	numbers []float64
+	negated bool
+	// lifted claims are stated in the agreement or disagreement sections
+	// rather than the agent's own
+	lifted bool
+}
+
+// answerLine is a line of an answer: text kept verbatim, or claims.
+type answerLine struct {
+	// prefix is the list marker the line starts with, if any
+	prefix   string
+	verbatim string
+	claims   []*answerClaim
+}
+
+var (
+	listItemPattern    = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)(.*)$`)
+	sentenceEndPattern = regexp.MustCompile(`[.!?]+(\s+|$)`)
+	numberPattern      = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// negationWords flip a claim's polarity.
+var negationWords = map[string]bool{
+	"not": true, "no": true, "never": true, "cannot": true, "avoid": true,
+	"without": true, "nor": true, "neither": true,
+}
+
+// fusionStopwords carry no topic.
+var fusionStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "this": true, "that": true,
+	"with": true, "you": true, "your": true, "should": true, "would": true,
+	"could": true, "will": true, "are": true, "was": true, "were": true,
+	"from": true, "than": true, "then": true, "its": true, "can": true,
+	"have": true, "has": true, "does": true, "also": true,
+	"into": true, "our": true, "their": true, "they": true, "there": true,
+}
+
+// splitAnswer splits an answer into lines of claims and verbatim text.
+func splitAnswer(agent, content string) []answerLine {
+	var lines []answerLine
+	inCode := false
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		fence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+		if fence {
+			inCode = !inCode
+		}
+		if fence || inCode || trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "|") {
+			lines = append(lines, answerLine{verbatim: line})
+			continue
+		}
+
+		var l answerLine
+		body := line
+		if m := listItemPattern.FindStringSubmatch(line); m != nil {
+			l.prefix, body = m[1], m[2]
+		}
+		for _, sentence := range splitSentences(body) {
+			l.claims = append(l.claims, newAnswerClaim(agent, sentence))
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// splitSentences splits text after sentence-ending punctuation.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, end := range sentenceEndPattern.FindAllStringIndex(text, -1) {
+		if s := strings.TrimSpace(text[start:end[1]]); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end[1]
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// newAnswerClaim analyzes a statement.
+func newAnswerClaim(agent, text string) *answerClaim {
+	c := &answerClaim{
+		agent: agent,
+		text:  text,
+		words: make(map[string]bool),
+		topic: make(map[string]bool),
+	}
+	for _, match := range numberPattern.FindAllString(text, -1) {
+		if n, err := strconv.ParseFloat(match, 64); err == nil {
+			c.numbers = append(c.numbers, n)
+		}
+	}
+	for _, token := range tokenizeQuestion(text) {
+		if stem, ok := strings.CutSuffix(token, "n't"); ok {
+			c.negated = !c.negated
+			token = stem
+		} else if negationWords[token] {
+			c.negated = !c.negated
+			continue
+		}
+		c.words[token] = true
+		if len(token) > 2 && !fusionStopwords[token] && !numberPattern.MatchString(token) {
+			c.topic[token] = true
+		}
+	}
+	return c
+}
+
+// substantive reports whether a claim says enough to be compared.
+func (c *answerClaim) substantive() bool {
+	return len(c.topic) >= minTopicWords
+}
+
+// jaccardWords returns the Jaccard similarity of two word sets.
+func jaccardWords(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// sameNumbers reports whether two claims state the same numbers.
+func sameNumbers(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// numberGap returns how far apart the numbers of two claims are, relative
+// to their size, in [0, 1].
+func numberGap(a, b []float64) float64 {
+	gap := 0.0
+	for i := range a {
+		if scale := math.Max(math.Abs(a[i]), math.Abs(b[i])); scale > 0 {
+			gap = math.Max(gap, math.Abs(a[i]-b[i])/scale)
+		}
+	}
+	return gap
+}
+
+// claimCluster is claims that say the same thing.
+type claimCluster struct {
+	claims []*answerClaim
+	agents []string
+}
+
+// add adds a claim to the cluster.
+func (c *claimCluster) add(claim *answerClaim) {
+	c.claims = append(c.claims, claim)
+	for _, agent := range c.agents {
+		if agent == claim.agent {
+			return
+		}
+	}
+	c.agents = append(c.agents, claim.agent)
+}
+
+// lift marks the cluster's claims as stated outside their agents' sections.
+func (c *claimCluster) lift() {
+	for _, claim := range c.claims {
+		claim.lifted = true
+	}
+}
+
+// sameAgents reports whether two clusters were made by the same agents.
+func sameAgents(a, b *claimCluster) bool {
+	if len(a.agents) != len(b.agents) {
+		return false
+	}
+	for _, agent := range a.agents {
+		found := false
+		for _, other := range b.agents {
+			found = found || agent == other
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// Answer Fuser
+// ============================================================================
+
+// AnswerFuser merges several agents' answers into one response. It is safe
+// for concurrent use if its impasse detector is.
+type AnswerFuser struct {
+	config AnswerFusionConfig
+	// impasses is where significant disagreements are raised; nil only
+	// surfaces them in the response
+	impasses *ImpasseDetector
+}
+
+// NewAnswerFuser creates a fuser raising significant disagreements with
+// an impasse detector, which may be nil.
+func NewAnswerFuser(config AnswerFusionConfig, impasses *ImpasseDetector) *AnswerFuser {
+	return &AnswerFuser{config: config, impasses: impasses}
+}
+
+// Fuse merges answers, given in the order their agents are presented.
+func (f *AnswerFuser) Fuse(answers []models.AgentAnswer) *FusedAnswer {
+	fused := &FusedAnswer{
+		Agents:     make([]string, 0, len(answers)),
+		Agreements: make([]FusedClaim, 0),
+		Conflicts:  make([]AnswerConflict, 0),
+	}
+	split := make([][]answerLine, len(answers))
+	var clusters []*claimCluster
+	for i, answer := range answers {
+		fused.Agents = append(fused.Agents, answer.Agent)
+		split[i] = splitAnswer(answer.Agent, answer.Content)
+		for _, line := range split[i] {
+			for _, claim := range line.claims {
+				if claim.substantive() {
+					clusters = f.cluster(clusters, claim)
+				}
+			}
+		}
+	}
+
+	for _, cluster := range clusters {
+		if len(cluster.agents) > 1 {
+			fused.Agreements = append(fused.Agreements, FusedClaim{Claim: cluster.claims[0].text, Agents: cluster.agents})
+			cluster.lift()
+		}
+	}
+	fused.Conflicts = f.conflicts(clusters)
+
+	fused.Content = fused.render(split)
+	return fused
+}
+
+// cluster adds a claim to the cluster of claims saying the same thing,
+// starting a new one if there is none.
+func (f *AnswerFuser) cluster(clusters []*claimCluster, claim *answerClaim) []*claimCluster {
+	for _, cluster := range clusters {
+		first := cluster.claims[0]
+		if first.negated == claim.negated && sameNumbers(first.numbers, claim.numbers) &&
+			jaccardWords(first.words, claim.words) >= f.config.SameClaimThreshold {
+			cluster.add(claim)
+			return clusters
+		}
+	}
+	cluster := &claimCluster{}
+	cluster.add(claim)
+	return append(clusters, cluster)
+}
+
+// conflicts finds the disagreements between clusters, most severe first,
+// raising an impasse for each significant one.
+func (f *AnswerFuser) conflicts(clusters []*claimCluster) []AnswerConflict {
+	conflicts := make([]AnswerConflict, 0)
+	for i, a := range clusters {
+		for _, b := range clusters[i+1:] {
+			if sameAgents(a, b) {
+				continue
+			}
+			x, y := a.claims[0], b.claims[0]
+			topic := jaccardWords(x.topic, y.topic)
+			if topic < f.config.SameTopicThreshold {
+				continue
+			}
+			severity := 0.0
+			switch {
+			case x.negated != y.negated:
+				severity = topic
+			case len(x.numbers) > 0 && len(x.numbers) == len(y.numbers):
+				severity = topic * numberGap(x.numbers, y.numbers)
+			}
+			if severity == 0 {
+				continue
+			}
+			a.lift()
+			b.lift()
+			conflicts = append(conflicts, AnswerConflict{
+				Positions: []ConflictPosition{{Agents: a.agents, Claim: x.text}, {Agents: b.agents, Claim: y.text}},
+				Severity:  severity,
+			})
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Severity > conflicts[j].Severity
+	})
+
+	if f.impasses == nil {
+		return conflicts
+	}
+	goalID := NewID("fusion")
+	for i := range conflicts {
+		if conflicts[i].Severity < f.config.ImpasseSeverity {
+			continue
+		}
+		results := make(map[string]interface{})
+		for _, position := range conflicts[i].Positions {
+			for _, agent := range position.Agents {
+				results[agent] = position.Claim
+			}
+		}
+		imp := f.impasses.DetectConflict(goalID, results)
+		if imp == nil {
+			continue
+		}
+		conflicts[i].ImpasseID = imp.ID
+		// The user is shown every position, so the impasse awaits their input
+		f.impasses.ResolveWith(imp.ID, StrategyAsk)
+	}
+	return conflicts
+}
+
+// render writes the fused answer as markdown.
+func (f *FusedAnswer) render(split [][]answerLine) string {
+	var b strings.Builder
+	if len(f.Agreements) > 0 {
+		b.WriteString("### Points of Agreement\n\n")
+		for _, agreement := range f.Agreements {
+			fmt.Fprintf(&b, "- %s *(%s)*\n", agreement.Claim, strings.Join(agreement.Agents, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(f.Conflicts) > 0 {
+		b.WriteString("### Points of Disagreement\n\n")
+		for i, conflict := range f.Conflicts {
+			label := "Agents disagree"
+			if conflict.ImpasseID != "" {
+				label = "**Significant disagreement**"
+			}
+			fmt.Fprintf(&b, "%d. %s:\n", i+1, label)
+			for _, position := range conflict.Positions {
+				fmt.Fprintf(&b, "   - %s: %s\n", strings.Join(position.Agents, ", "), position.Claim)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for i, lines := range split {
+		section := renderLines(lines)
+		if section == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", f.Agents[i], section)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// renderLines writes an answer's lines without its lifted claims,
+// collapsing the blank lines left behind.
+func renderLines(lines []answerLine) string {
+	var out []string
+	for _, line := range lines {
+		if line.claims == nil {
+			if strings.TrimSpace(line.verbatim) == "" && (len(out) == 0 || out[len(out)-1] == "") {
+				continue
+			}
+			out = append(out, line.verbatim)
+			continue
+		}
+		var kept []string
+		for _, claim := range line.claims {
+			if !claim.lifted {
+				kept = append(kept, claim.text)
+			}
+		}
+		if len(kept) > 0 {
+			out = append(out, line.prefix+strings.Join(kept, " "))
+		}
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ============================================================================
+// Answer Fusion Tests
+// ============================================================================
+
+func TestAnswerFuser_Agreement(t *testing.T) {
+	fuser := NewAnswerFuser(DefaultAnswerFusionConfig(), nil)
+	fused := fuser.Fuse([]models.AgentAnswer{
+		{Agent: "APEX", Content: "Validate every input at the service boundary. Prefer table-driven tests."},
+		{Agent: "CIPHER", Content: "- Validate every input at the service boundary!\n- Rotate signing keys quarterly."},
+	})
+
+	if len(fused.Agreements) != 1 {
+		t.Fatalf("Expected 1 agreement, got %+v", fused.Agreements)
+	}
+	if a := fused.Agreements[0]; a.Claim != "Validate every input at the service boundary." || strings.Join(a.Agents, ",") != "APEX,CIPHER" {
+		t.Errorf("Expected the validation claim shared by APEX and CIPHER, got %+v", a)
+	}
+	if len(fused.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %+v", fused.Conflicts)
+	}
+
+	// The shared claim is stated once; the rest stays with its agent
+	if strings.Count(fused.Content, "service boundary") != 1 {
+		t.Errorf("Expected the agreed claim stated once, got:\n%s", fused.Content)
+	}
+	for _, want := range []string{"### Points of Agreement", "### APEX\n\nPrefer table-driven tests.", "### CIPHER\n\n- Rotate signing keys quarterly."} {
+		if !strings.Contains(fused.Content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, fused.Content)
+		}
+	}
+}
+
+func TestAnswerFuser_PolarityConflictRaisesImpasse(t *testing.T) {
+	impasses := NewImpasseDetector(nil, nil)
+	var detected []*Impasse
+	impasses.OnImpasseDetected(func(imp *Impasse) {
+		detected = append(detected, imp)
+	})
+	fuser := NewAnswerFuser(DefaultAnswerFusionConfig(), impasses)
+
+	fused := fuser.Fuse([]models.AgentAnswer{
+		{Agent: "APEX", Content: "You should cache session results in Redis."},
+		{Agent: "VELOCITY", Content: "You shouldn't cache session results in Redis."},
+	})
+
+	if len(fused.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v", fused.Conflicts)
+	}
+	conflict := fused.Conflicts[0]
+	if conflict.Severity != 1 || conflict.ImpasseID == "" {
+		t.Errorf("Expected a significant conflict with an impasse, got %+v", conflict)
+	}
+	if len(detected) != 1 || detected[0].Type != ImpasseConflict || detected[0].ConflictingResults["VELOCITY"] != "You shouldn't cache session results in Redis." {
+		t.Fatalf("Expected an ImpasseConflict with both positions, got %+v", detected)
+	}
+	if imp, _ := impasses.Get(conflict.ImpasseID); !imp.IsResolved() || imp.Resolution != StrategyAsk {
+		t.Error("Expected the impasse resolved by asking the user")
+	}
+
+	for _, want := range []string{"### Points of Disagreement", "**Significant disagreement**", "- APEX: You should cache", "- VELOCITY: You shouldn't cache"} {
+		if !strings.Contains(fused.Content, want) {
+			t.Errorf("Expected content to contain %q, got:\n%s", want, fused.Content)
+		}
+	}
+	if strings.Contains(fused.Content, "### APEX") {
+		t.Errorf("Expected no APEX section once its only claim is in a conflict, got:\n%s", fused.Content)
+	}
+}
+
+func TestAnswerFuser_NumericConflict(t *testing.T) {
+	impasses := NewImpasseDetector(nil, nil)
+	fuser := NewAnswerFuser(DefaultAnswerFusionConfig(), impasses)
+
+	fused := fuser.Fuse([]models.AgentAnswer{
+		{Agent: "FLUX", Content: "Set the connection pool size to 20 per replica."},
+		{Agent: "VELOCITY", Content: "Set the connection pool size to 25 per replica."},
+	})
+
+	if len(fused.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v", fused.Conflicts)
+	}
+	// The numbers are 20% apart: a minor disagreement, surfaced only
+	if c := fused.Conflicts[0]; c.Severity < 0.19 || c.Severity > 0.21 || c.ImpasseID != "" {
+		t.Errorf("Expected a minor conflict without an impasse, got %+v", c)
+	}
+	if impasses.GetStats().TotalDetected != 0 {
+		t.Error("Expected no impasse for a minor disagreement")
+	}
+	if !strings.Contains(fused.Content, "1. Agents disagree:") {
+		t.Errorf("Expected the disagreement surfaced, got:\n%s", fused.Content)
+	}
+}
+
+func TestAnswerFuser_KeepsCodeAndShortClaims(t *testing.T) {
+	fuser := NewAnswerFuser(DefaultAnswerFusionConfig(), nil)
+	code := "```go\nif err != nil {\n\treturn err\n}\n```"
+	fused := fuser.Fuse([]models.AgentAnswer{
+		{Agent: "APEX", Content: "## Fix\n\n" + code + "\n\nHope this helps."},
+		{Agent: "ECLIPSE", Content: "Hope this helps."},
+	})
+
+	if len(fused.Agreements) != 0 || len(fused.Conflicts) != 0 {
+		t.Errorf("Expected code and short claims never compared, got %+v and %+v", fused.Agreements, fused.Conflicts)
+	}
+	if !strings.Contains(fused.Content, "## Fix\n\n"+code) {
+		t.Errorf("Expected the code block kept verbatim, got:\n%s", fused.Content)
+	}
+	if !strings.Contains(fused.Content, "### ECLIPSE\n\nHope this helps.") {
+		t.Errorf("Expected each agent's section, got:\n%s", fused.Content)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := splitSentences("First point. Second point! Third? trailing")
+	want := []string{"First point.", "Second point!", "Third?", "trailing"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
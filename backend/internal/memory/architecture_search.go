@@ -208,16 +208,7 @@ func (s *TeamArchitectureSearch) generateRandomArchitecture(generation int) *Tea
 }
 
 func generateArchitectureID() string {
-	return time.Now().Format("20060102150405") + "-" + archRandomString(6)
-}
-
-func archRandomString(n int) string {
-	letters := []rune("abcdefghijklmnopqrstuvwxyz0123456789")
-	result := make([]rune, n)
-	for i := range result {
-		result[i] = letters[rand.Intn(len(letters))]
-	}
-	return string(result)
+	return NewID("arch")
 }
 
 // removeForbiddenPairs removes agents that form forbidden pairs
@@ -14,14 +14,14 @@
 package memory
 
 import (
-	"container/heap"
 	"errors"
-	"fmt"
 	"math"
 	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
 )
 
 // ============================================================================
@@ -29,10 +29,10 @@ import (
 // ============================================================================
 
 var (
-	ErrAttentionCapacityExceeded = errors.New("attention capacity exceeded")
-	ErrFocusItemNotFound         = errors.New("focus item not found")
-	ErrInvalidSalience           = errors.New("invalid salience value")
-	ErrAttentionBlocked          = errors.New("attention blocked by higher priority item")
+	ErrAttentionCapacityExceeded = errdefs.New(errdefs.ErrCapacityExceeded, "attention capacity exceeded")
+	ErrFocusItemNotFound         = errdefs.New(errdefs.ErrNotFound, "focus item not found")
+	ErrInvalidSalience           = errdefs.New(errdefs.ErrInvalidArgument, "invalid salience value")
+	ErrAttentionBlocked          = errdefs.New(errdefs.ErrConflict, "attention blocked by higher priority item")
 )
 
 // ============================================================================
@@ -113,9 +113,6 @@ func (t FocusItemType) BasePriority() float64 {
 // Focus Item
 // ============================================================================
 
-// focusItemIDCounter provides unique IDs
-var focusItemIDCounter uint64
-
 // FocusItem represents something being attended to.
 type FocusItem struct {
 	// ID uniquely identifies this focus item
@@ -162,27 +159,43 @@ type FocusItem struct {
 
 	// index is used by the priority queue
 	index int
+
+	// clock stamps accesses and ages recency; nil uses SystemClock
+	clock Clock
 }
 
 // NewFocusItem creates a new focus item.
 func NewFocusItem(itemType FocusItemType, content interface{}, label string, salience float64) *FocusItem {
-	now := time.Now()
+	return NewFocusItemWithClock(nil, itemType, content, label, salience)
+}
+
+// NewFocusItemWithClock creates a new focus item that reads time from clock;
+// a nil clock uses SystemClock until the item is focused by a controller,
+// which then lends it its own.
+func NewFocusItemWithClock(clock Clock, itemType FocusItemType, content interface{}, label string, salience float64) *FocusItem {
+	now := clockOrSystem(clock).Now()
 	item := &FocusItem{
-		ID:             fmt.Sprintf("focus-%d", atomic.AddUint64(&focusItemIDCounter, 1)),
+		ID:             NewID("focus"),
 		Type:           itemType,
 		Content:        content,
 		Label:          label,
-		Salience:       clampFloat(salience, 0.0, 1.0),
+		Salience:       clamp(salience, 0.0, 1.0),
 		CognitiveLoad:  computeDefaultLoad(itemType, salience),
 		EntryTime:      now,
 		LastAccessTime: now,
 		DecayRate:      0.01, // Default: 1% per second
 		Metadata:       make(map[string]interface{}),
+		clock:          clock,
 	}
 	item.Priority = item.computePriority()
 	return item
 }
 
+// now reads the item's clock.
+func (f *FocusItem) now() time.Time {
+	return clockOrSystem(f.clock).Now()
+}
+
 // computePriority calculates the current priority.
 func (f *FocusItem) computePriority() float64 {
 	// Priority = salience * type_weight * recency_bonus
@@ -197,7 +210,7 @@ func (f *FocusItem) computePriority() float64 {
 
 // recencyBonus returns a bonus based on how recently the item was accessed.
 func (f *FocusItem) recencyBonus() float64 {
-	elapsed := time.Since(f.LastAccessTime).Seconds()
+	elapsed := f.now().Sub(f.LastAccessTime).Seconds()
 	// Exponential decay of recency bonus
 	return math.Exp(-elapsed / 60.0) // 1-minute half-life
 }
@@ -205,13 +218,13 @@ func (f *FocusItem) recencyBonus() float64 {
 // DecaySalience applies time-based decay to the salience.
 func (f *FocusItem) DecaySalience(elapsed time.Duration) {
 	decayFactor := math.Exp(-f.DecayRate * elapsed.Seconds())
-	f.Salience = clampFloat(f.Salience*decayFactor, 0.0, 1.0)
+	f.Salience = clamp(f.Salience*decayFactor, 0.0, 1.0)
 	f.Priority = f.computePriority()
 }
 
 // Touch updates access time and count.
 func (f *FocusItem) Touch() {
-	f.LastAccessTime = time.Now()
+	f.LastAccessTime = f.now()
 	f.AccessCount++
 	f.Priority = f.computePriority()
 }
@@ -233,6 +246,7 @@ func (f *FocusItem) Clone() *FocusItem {
 		Sticky:         f.Sticky,
 		SourceID:       f.SourceID,
 		Metadata:       make(map[string]interface{}),
+		clock:          f.clock,
 	}
 	for k, v := range f.Metadata {
 		clone.Metadata[k] = v
@@ -268,37 +282,12 @@ func computeDefaultLoad(itemType FocusItemType, salience float64) float64 {
 // Focus Priority Queue (Max-Heap)
 // ============================================================================
 
-// FocusPriorityQueue implements heap.Interface for priority-based focus management.
-type FocusPriorityQueue []*FocusItem
-
-func (pq FocusPriorityQueue) Len() int { return len(pq) }
-
-func (pq FocusPriorityQueue) Less(i, j int) bool {
-	// Max-heap: higher priority first
-	return pq[i].Priority > pq[j].Priority
-}
-
-func (pq FocusPriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *FocusPriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*FocusItem)
-	item.index = n
-	*pq = append(*pq, item)
-}
-
-func (pq *FocusPriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil  // avoid memory leak
-	item.index = -1 // for safety
-	*pq = old[0 : n-1]
-	return item
+// newFocusQueue creates the queue of focus items, highest priority first.
+func newFocusQueue() *pqueue.Queue[*FocusItem] {
+	return pqueue.NewIndexed(
+		func(a, b *FocusItem) bool { return a.Priority > b.Priority },
+		func(item *FocusItem, index int) { item.index = index },
+	)
 }
 
 // ============================================================================
@@ -378,7 +367,7 @@ func (sc *SalienceComputer) ComputeSalience(factors *SalienceFactors) float64 {
 		sc.emotionalWeight*factors.Emotional +
 		sc.trustWeight*factors.SourceTrust
 
-	return clampFloat(salience, 0.0, 1.0)
+	return clamp(salience, 0.0, 1.0)
 }
 
 // ComputeNovelty estimates novelty based on how different this is from baseline.
@@ -398,7 +387,7 @@ func (sc *SalienceComputer) ComputeNovelty(key string, value float64) float64 {
 	if baseline == 0 {
 		return deviation
 	}
-	return clampFloat(deviation/baseline, 0.0, 1.0)
+	return clamp(deviation/baseline, 0.0, 1.0)
 }
 
 // ============================================================================
@@ -416,7 +405,7 @@ type AttentionController struct {
 	currentLoad float64
 
 	// focusHeap is the priority queue of focus items
-	focusHeap FocusPriorityQueue
+	focusHeap *pqueue.Queue[*FocusItem]
 
 	// focusMap provides O(1) lookup by ID
 	focusMap map[string]*FocusItem
@@ -437,6 +426,9 @@ type AttentionController struct {
 
 	// workingMemory reference (optional integration)
 	workingMemory *CognitiveWorkingMemory
+
+	// clock is lent to focused items that have none
+	clock Clock
 }
 
 // AttentionConfig configures the attention controller.
@@ -458,6 +450,9 @@ type AttentionConfig struct {
 
 	// StickyDecayMultiplier slows decay for sticky items
 	StickyDecayMultiplier float64
+
+	// Clock supplies the time recency is measured against (default: SystemClock)
+	Clock Clock
 }
 
 // DefaultAttentionConfig returns sensible defaults based on cognitive science.
@@ -492,14 +487,13 @@ func NewAttentionController(config *AttentionConfig) *AttentionController {
 
 	ac := &AttentionController{
 		capacity:         config.Capacity,
-		focusHeap:        make(FocusPriorityQueue, 0),
+		focusHeap:        newFocusQueue(),
 		focusMap:         make(map[string]*FocusItem),
 		salienceComputer: NewSalienceComputer(),
 		config:           config,
 		stats:            &AttentionStats{},
+		clock:            clockOrSystem(config.Clock),
 	}
-
-	heap.Init(&ac.focusHeap)
 	return ac
 }
 
@@ -524,8 +518,10 @@ func (ac *AttentionController) Focus(item *FocusItem) (bool, error) {
 		return true, nil
 	}
 
+	ac.adoptClock(item)
+
 	// Check item limit
-	if len(ac.focusHeap) >= ac.config.MaxFocusItems {
+	if ac.focusHeap.Len() >= ac.config.MaxFocusItems {
 		// Try to evict lowest priority item
 		if !ac.evictLowest(item.Priority) {
 			return false, ErrAttentionCapacityExceeded
@@ -560,13 +556,14 @@ func (ac *AttentionController) FocusInterrupt(item *FocusItem) error {
 	}
 
 	item.Type = FocusInterrupt
+	ac.adoptClock(item)
 	item.Priority = item.computePriority()
 
 	ac.mu.Lock()
 	defer ac.mu.Unlock()
 
 	// Force room for interrupt by evicting if necessary
-	for ac.currentLoad+item.CognitiveLoad > ac.capacity && len(ac.focusHeap) > 0 {
+	for ac.currentLoad+item.CognitiveLoad > ac.capacity && ac.focusHeap.Len() > 0 {
 		ac.evictLowestUnlocked()
 	}
 
@@ -605,13 +602,26 @@ func (ac *AttentionController) touchItem(itemID string) error {
 	}
 
 	item.Touch()
-	heap.Fix(&ac.focusHeap, item.index)
+	ac.focusHeap.Fix(item.index)
 	return nil
 }
 
+// adoptClock lends the controller's clock to an item that has none.
+func (ac *AttentionController) adoptClock(item *FocusItem) {
+	if item.clock == nil {
+		item.clock = ac.clock
+		item.Priority = item.computePriority()
+	}
+}
+
+// NewFocusItem creates a focus item that reads time from the controller's clock.
+func (ac *AttentionController) NewFocusItem(itemType FocusItemType, content interface{}, label string, salience float64) *FocusItem {
+	return NewFocusItemWithClock(ac.clock, itemType, content, label, salience)
+}
+
 // addItem adds an item to focus (must be called with lock held).
 func (ac *AttentionController) addItem(item *FocusItem) {
-	heap.Push(&ac.focusHeap, item)
+	ac.focusHeap.Push(item)
 	ac.focusMap[item.ID] = item
 	ac.currentLoad += item.CognitiveLoad
 	ac.stats.TotalItemsFocused++
@@ -634,8 +644,8 @@ func (ac *AttentionController) addItem(item *FocusItem) {
 
 // removeItem removes an item from focus (must be called with lock held).
 func (ac *AttentionController) removeItem(item *FocusItem, reason string) {
-	if item.index >= 0 && item.index < len(ac.focusHeap) {
-		heap.Remove(&ac.focusHeap, item.index)
+	if item.index >= 0 && item.index < ac.focusHeap.Len() {
+		ac.focusHeap.Remove(item.index)
 	}
 	delete(ac.focusMap, item.ID)
 	ac.currentLoad -= item.CognitiveLoad
@@ -656,14 +666,14 @@ func (ac *AttentionController) removeItem(item *FocusItem, reason string) {
 
 // evictLowest removes the lowest priority item if its priority is below threshold.
 func (ac *AttentionController) evictLowest(threshold float64) bool {
-	if len(ac.focusHeap) == 0 {
+	if ac.focusHeap.Len() == 0 {
 		return false
 	}
 
 	// Find lowest priority item
 	lowestIdx := -1
 	lowestPriority := math.MaxFloat64
-	for i, item := range ac.focusHeap {
+	for i, item := range ac.focusHeap.Items() {
 		if !item.Sticky && item.Priority < lowestPriority {
 			lowestPriority = item.Priority
 			lowestIdx = i
@@ -674,7 +684,7 @@ func (ac *AttentionController) evictLowest(threshold float64) bool {
 		return false
 	}
 
-	item := ac.focusHeap[lowestIdx]
+	item := ac.focusHeap.Items()[lowestIdx]
 	ac.removeItem(item, "evicted_by_priority")
 	ac.stats.TotalItemsEvicted++
 	return true
@@ -682,14 +692,14 @@ func (ac *AttentionController) evictLowest(threshold float64) bool {
 
 // evictLowestUnlocked removes the lowest priority non-sticky item.
 func (ac *AttentionController) evictLowestUnlocked() bool {
-	if len(ac.focusHeap) == 0 {
+	if ac.focusHeap.Len() == 0 {
 		return false
 	}
 
 	// Find lowest priority non-sticky item
 	lowestIdx := -1
 	lowestPriority := math.MaxFloat64
-	for i, item := range ac.focusHeap {
+	for i, item := range ac.focusHeap.Items() {
 		if !item.Sticky && item.Priority < lowestPriority {
 			lowestPriority = item.Priority
 			lowestIdx = i
@@ -700,7 +710,7 @@ func (ac *AttentionController) evictLowestUnlocked() bool {
 		return false
 	}
 
-	item := ac.focusHeap[lowestIdx]
+	item := ac.focusHeap.Items()[lowestIdx]
 	ac.removeItem(item, "evicted_for_interrupt")
 	ac.stats.TotalItemsEvicted++
 	return true
@@ -712,7 +722,7 @@ func (ac *AttentionController) makeRoom(newLoad, newPriority float64) bool {
 
 	// Collect evictable items sorted by priority
 	evictable := make([]*FocusItem, 0)
-	for _, item := range ac.focusHeap {
+	for _, item := range ac.focusHeap.Items() {
 		if !item.Sticky && item.Priority < newPriority {
 			evictable = append(evictable, item)
 		}
@@ -749,7 +759,7 @@ func (ac *AttentionController) DecayAll(elapsed time.Duration) int {
 	evicted := 0
 	itemsToEvict := make([]*FocusItem, 0)
 
-	for _, item := range ac.focusHeap {
+	for _, item := range ac.focusHeap.Items() {
 		// Apply decay (slower for sticky items)
 		decayDuration := elapsed
 		if item.Sticky {
@@ -771,7 +781,7 @@ func (ac *AttentionController) DecayAll(elapsed time.Duration) int {
 	}
 
 	// Rebuild heap after priority changes
-	heap.Init(&ac.focusHeap)
+	ac.focusHeap.Reorder()
 
 	return evicted
 }
@@ -813,8 +823,8 @@ func (ac *AttentionController) GetAllFocused() []*FocusItem {
 	ac.mu.RLock()
 	defer ac.mu.RUnlock()
 
-	result := make([]*FocusItem, len(ac.focusHeap))
-	for i, item := range ac.focusHeap {
+	result := make([]*FocusItem, ac.focusHeap.Len())
+	for i, item := range ac.focusHeap.Items() {
 		result[i] = item.Clone()
 	}
 	return result
@@ -826,8 +836,8 @@ func (ac *AttentionController) GetTopFocused(n int) []*FocusItem {
 	defer ac.mu.RUnlock()
 
 	// Copy and sort by priority
-	items := make([]*FocusItem, len(ac.focusHeap))
-	for i, item := range ac.focusHeap {
+	items := make([]*FocusItem, ac.focusHeap.Len())
+	for i, item := range ac.focusHeap.Items() {
 		items[i] = item.Clone()
 	}
 	sort.Slice(items, func(i, j int) bool {
@@ -846,7 +856,7 @@ func (ac *AttentionController) GetFocusedByType(itemType FocusItemType) []*Focus
 	defer ac.mu.RUnlock()
 
 	result := make([]*FocusItem, 0)
-	for _, item := range ac.focusHeap {
+	for _, item := range ac.focusHeap.Items() {
 		if item.Type == itemType {
 			result = append(result, item.Clone())
 		}
@@ -858,7 +868,7 @@ func (ac *AttentionController) GetFocusedByType(itemType FocusItemType) []*Focus
 func (ac *AttentionController) FocusCount() int {
 	ac.mu.RLock()
 	defer ac.mu.RUnlock()
-	return len(ac.focusHeap)
+	return ac.focusHeap.Len()
 }
 
 // CurrentLoad returns the current cognitive load.
@@ -885,7 +895,7 @@ func (ac *AttentionController) CanFocus(load float64) bool {
 	ac.mu.RLock()
 	defer ac.mu.RUnlock()
 	// Must have capacity AND item count below limit
-	return ac.currentLoad+load <= ac.capacity && len(ac.focusHeap) < ac.config.MaxFocusItems
+	return ac.currentLoad+load <= ac.capacity && ac.focusHeap.Len() < ac.config.MaxFocusItems
 }
 
 // ============================================================================
@@ -997,8 +1007,8 @@ func (ac *AttentionController) Snapshot() *AttentionSnapshot {
 	ac.mu.RLock()
 	defer ac.mu.RUnlock()
 
-	items := make([]*FocusItem, len(ac.focusHeap))
-	for i, item := range ac.focusHeap {
+	items := make([]*FocusItem, ac.focusHeap.Len())
+	for i, item := range ac.focusHeap.Items() {
 		items[i] = item.Clone()
 	}
 
@@ -1006,7 +1016,7 @@ func (ac *AttentionController) Snapshot() *AttentionSnapshot {
 		Items:       items,
 		CurrentLoad: ac.currentLoad,
 		Stats:       *ac.stats,
-		Timestamp:   time.Now(),
+		Timestamp:   ac.clock.Now(),
 	}
 }
 
@@ -1019,17 +1029,16 @@ func (ac *AttentionController) Restore(snapshot *AttentionSnapshot) error {
 	ac.mu.Lock()
 	defer ac.mu.Unlock()
 
-	ac.focusHeap = make(FocusPriorityQueue, len(snapshot.Items))
+	items := make([]*FocusItem, len(snapshot.Items))
 	ac.focusMap = make(map[string]*FocusItem)
 
 	for i, item := range snapshot.Items {
 		clone := item.Clone()
-		clone.index = i
-		ac.focusHeap[i] = clone
+		items[i] = clone
 		ac.focusMap[clone.ID] = clone
 	}
 
-	heap.Init(&ac.focusHeap)
+	ac.focusHeap.Reset(items...)
 	ac.currentLoad = snapshot.CurrentLoad
 	ac.stats = &AttentionStats{}
 	*ac.stats = snapshot.Stats
@@ -1042,10 +1051,9 @@ func (ac *AttentionController) Clear() {
 	ac.mu.Lock()
 	defer ac.mu.Unlock()
 
-	ac.focusHeap = make(FocusPriorityQueue, 0)
+	ac.focusHeap.Reset()
 	ac.focusMap = make(map[string]*FocusItem)
 	ac.currentLoad = 0
-	heap.Init(&ac.focusHeap)
 }
 
 // ============================================================================
@@ -1098,18 +1106,3 @@ func (ac *AttentionController) FilterByAttention(items []interface{}, salienceFu
 
 	return result
 }
-
-// ============================================================================
-// Helper Functions
-// ============================================================================
-
-// clampFloat clamps a float64 value to the given range.
-func clampFloat(val, min, max float64) float64 {
-	if val < min {
-		return min
-	}
-	if val > max {
-		return max
-	}
-	return val
-}
@@ -1,7 +1,9 @@
 package memory
 
 import (
+	"math"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -99,11 +101,12 @@ func TestFocusItem_SalienceClamp(t *testing.T) {
 }
 
 func TestFocusItem_Touch(t *testing.T) {
-	item := NewFocusItem(FocusTask, nil, "Test", 0.5)
+	clock := NewManualClock(time.Now())
+	item := NewFocusItemWithClock(clock, FocusTask, nil, "Test", 0.5)
 	initialAccess := item.LastAccessTime
 	initialCount := item.AccessCount
 
-	time.Sleep(10 * time.Millisecond)
+	clock.Advance(10 * time.Millisecond)
 	item.Touch()
 
 	if !item.LastAccessTime.After(initialAccess) {
@@ -114,6 +117,23 @@ func TestFocusItem_Touch(t *testing.T) {
 	}
 }
 
+func TestFocusItem_RecencyBonus(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	item := NewFocusItemWithClock(clock, FocusTask, nil, "Test", 0.5)
+	fresh := item.computePriority()
+
+	clock.Advance(time.Minute)
+	aged := item.computePriority()
+	if want := fresh * math.Exp(-1); math.Abs(aged-want) > 1e-9 {
+		t.Errorf("Expected priority %.6f after a minute, got %.6f", want, aged)
+	}
+
+	item.Touch()
+	if item.Priority != fresh {
+		t.Errorf("Expected Touch to restore priority %.6f, got %.6f", fresh, item.Priority)
+	}
+}
+
 func TestFocusItem_DecaySalience(t *testing.T) {
 	item := NewFocusItem(FocusTask, nil, "Test", 0.8)
 	item.DecayRate = 0.1 // 10% per second
@@ -317,11 +337,14 @@ func TestAttentionController_UnfocusNotFound(t *testing.T) {
 }
 
 func TestAttentionController_Touch(t *testing.T) {
-	ac := NewAttentionController(nil)
+	clock := NewManualClock(time.Now())
+	config := DefaultAttentionConfig()
+	config.Clock = clock
+	ac := NewAttentionController(config)
 
-	item := NewFocusItem(FocusTask, "test", "Test", 0.5)
+	item := ac.NewFocusItem(FocusTask, "test", "Test", 0.5)
 	ac.Focus(item)
-	time.Sleep(10 * time.Millisecond)
+	clock.Advance(10 * time.Millisecond)
 
 	err := ac.Touch(item.ID)
 	if err != nil {
@@ -334,6 +357,32 @@ func TestAttentionController_Touch(t *testing.T) {
 	}
 }
 
+func TestAttentionController_Clock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	config := DefaultAttentionConfig()
+	config.Clock = clock
+	ac := NewAttentionController(config)
+
+	// Items without a clock adopt the controller's when focused
+	item := NewFocusItem(FocusTask, "test", "Test", 0.5)
+	if _, err := ac.Focus(item); err != nil {
+		t.Fatalf("Focus failed: %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if err := ac.Touch(item.ID); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	retrieved, _ := ac.GetFocused(item.ID)
+	if want := start.Add(time.Hour); !retrieved.LastAccessTime.Equal(want) {
+		t.Errorf("Expected LastAccessTime %v, got %v", want, retrieved.LastAccessTime)
+	}
+	if snapshot := ac.Snapshot(); !snapshot.Timestamp.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected snapshot at %v, got %v", start.Add(time.Hour), snapshot.Timestamp)
+	}
+}
+
 func TestAttentionController_CapacityLimit(t *testing.T) {
 	config := &AttentionConfig{
 		Capacity:      1.0, // Very limited capacity
@@ -831,6 +880,65 @@ func TestAttentionController_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
+func TestAttentionController_ConcurrentFocusDecay(t *testing.T) {
+	ac := NewAttentionController(nil)
+	ac.SetWorkingMemory(NewCognitiveWorkingMemory(DefaultWorkingMemoryConfig()))
+	var gained, lost int64
+	ac.OnFocusGained(func(*FocusItem) { atomic.AddInt64(&gained, 1) })
+	ac.OnFocusLost(func(*FocusItem, string) { atomic.AddInt64(&lost, 1) })
+
+	const workers = 8
+	const iterations = 300
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				switch w % 4 {
+				case 0:
+					item := NewFocusItem(FocusTask, i, "Task", 0.2+float64(i%8)/10)
+					ac.Focus(item)
+					ac.Touch(item.ID)
+					if i%3 == 0 {
+						ac.Unfocus(item.ID)
+					}
+				case 1:
+					ac.FocusInterrupt(NewFocusItem(FocusInterrupt, i, "Interrupt", 0.9))
+				case 2:
+					ac.DecayAll(10 * time.Second)
+					ac.Tick()
+				default:
+					for _, item := range ac.GetTopFocused(3) {
+						ac.GetFocused(item.ID)
+					}
+					ac.GetAllFocused()
+					ac.GetFocusedByType(FocusTask)
+					ac.Snapshot()
+					ac.GetStats()
+					ac.CanFocus(0.5)
+					ac.FilterByAttention([]interface{}{1, 2, 3}, func(interface{}) float64 { return 0.5 })
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// The load is exactly what the focused items carry
+	load := 0.0
+	for _, item := range ac.GetAllFocused() {
+		load += item.CognitiveLoad
+	}
+	if math.Abs(load-ac.CurrentLoad()) > 1e-6 {
+		t.Errorf("Expected current load %.4f to match focused items, got %.4f", load, ac.CurrentLoad())
+	}
+	stats := ac.GetStats()
+	if stats.FocusGainedCount != atomic.LoadInt64(&gained) || stats.FocusLostCount != atomic.LoadInt64(&lost) {
+		t.Errorf("Expected callbacks to match stats, got %d/%d gained and %d/%d lost",
+			atomic.LoadInt64(&gained), stats.FocusGainedCount, atomic.LoadInt64(&lost), stats.FocusLostCount)
+	}
+}
+
 // ============================================================================
 // Benchmark Tests
 // ============================================================================
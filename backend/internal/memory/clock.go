@@ -0,0 +1,69 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the Clock abstraction temporal components read time from.
+//
+// Decay, backoff, half-life and recency logic all depend on the current time.
+// Reading it through a Clock lets tests substitute a ManualClock and advance
+// time instantly instead of sleeping.
+
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells temporal components the current time.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// systemClock reads the wall clock.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the wall clock, used when no Clock is configured.
+var SystemClock Clock = systemClock{}
+
+// clockOrSystem returns clock, or SystemClock if clock is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// ManualClock is a Clock that only moves when told to. It is safe for
+// concurrent use.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a manual clock reading start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current reading.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d; a negative d moves it back.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
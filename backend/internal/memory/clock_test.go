@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Clock Tests
+// ============================================================================
+
+func TestManualClock_Advance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("Expected %v, got %v", start, clock.Now())
+	}
+
+	clock.Advance(90 * time.Minute)
+	if want := start.Add(90 * time.Minute); !clock.Now().Equal(want) {
+		t.Errorf("Expected %v, got %v", want, clock.Now())
+	}
+
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("Expected %v after Set, got %v", start, clock.Now())
+	}
+}
+
+func TestClockOrSystem(t *testing.T) {
+	if clockOrSystem(nil) != SystemClock {
+		t.Error("Expected nil clock to fall back to SystemClock")
+	}
+
+	clock := NewManualClock(time.Time{})
+	if clockOrSystem(clock) != Clock(clock) {
+		t.Error("Expected configured clock to be kept")
+	}
+}
@@ -401,6 +401,25 @@ func (cgsc *CognitiveGoalStackComponent) GetActiveGoalStack() []*Goal {
 	return stack
 }
 
+// GetReadyGoals returns the goals within their limits whose dependencies
+// have completed, highest priority first; they can be dispatched in parallel
+func (cgsc *CognitiveGoalStackComponent) GetReadyGoals() []*Goal {
+	cgsc.mu.RLock()
+	defer cgsc.mu.RUnlock()
+
+	return cgsc.goalStack.Ready()
+}
+
+// SpendOnGoal records tokens and cost spent working on a goal. It returns
+// ErrGoalExhausted once the goal is past its deadline or budget, and the
+// caller should stop working on it
+func (cgsc *CognitiveGoalStackComponent) SpendOnGoal(goalID string, tokens int64, cost float64) error {
+	cgsc.mu.RLock()
+	defer cgsc.mu.RUnlock()
+
+	return cgsc.goalStack.Spend(goalID, tokens, cost)
+}
+
 // GetCompletedGoals returns all completed goals
 func (cgsc *CognitiveGoalStackComponent) GetCompletedGoals() []*Goal {
 	cgsc.mu.RLock()
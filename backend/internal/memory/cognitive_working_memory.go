@@ -10,10 +10,11 @@
 package memory
 
 import (
-	"container/heap"
 	"math"
 	"sync"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
 )
 
 // ============================================================================
@@ -96,6 +97,20 @@ type WorkingMemoryItem struct {
 	index int
 }
 
+// Clone copies an item, including its associations and metadata map.
+// Content and metadata values are shared.
+func (item *WorkingMemoryItem) Clone() *WorkingMemoryItem {
+	clone := *item
+	clone.Associations = append([]string(nil), item.Associations...)
+	if item.Metadata != nil {
+		clone.Metadata = make(map[string]interface{}, len(item.Metadata))
+		for k, v := range item.Metadata {
+			clone.Metadata[k] = v
+		}
+	}
+	return &clone
+}
+
 // WorkingMemoryContentType categorizes content.
 type WorkingMemoryContentType string
 
@@ -155,32 +170,13 @@ type Chunk struct {
 // Activation Priority Queue (for capacity management)
 // ============================================================================
 
-// activationHeap implements heap.Interface for activation-based eviction.
-type activationHeap []*WorkingMemoryItem
-
-func (h activationHeap) Len() int           { return len(h) }
-func (h activationHeap) Less(i, j int) bool { return h[i].Activation < h[j].Activation }
-func (h activationHeap) Swap(i, j int) {
-	h[i], h[j] = h[j], h[i]
-	h[i].index = i
-	h[j].index = j
-}
-
-func (h *activationHeap) Push(x interface{}) {
-	n := len(*h)
-	item := x.(*WorkingMemoryItem)
-	item.index = n
-	*h = append(*h, item)
-}
-
-func (h *activationHeap) Pop() interface{} {
-	old := *h
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	*h = old[0 : n-1]
-	return item
+// newActivationQueue creates the queue of items, lowest activation first,
+// so the next evicted is at the front.
+func newActivationQueue() *pqueue.Queue[*WorkingMemoryItem] {
+	return pqueue.NewIndexed(
+		func(a, b *WorkingMemoryItem) bool { return a.Activation < b.Activation },
+		func(item *WorkingMemoryItem, index int) { item.index = index },
+	)
 }
 
 // ============================================================================
@@ -199,7 +195,7 @@ type CognitiveWorkingMemory struct {
 	items map[string]*WorkingMemoryItem
 
 	// activationQueue for capacity management
-	activationQueue activationHeap
+	activationQueue *pqueue.Queue[*WorkingMemoryItem]
 
 	// chunks stores bound item groups
 	chunks map[string]*Chunk
@@ -281,7 +277,7 @@ func NewCognitiveWorkingMemory(config WorkingMemoryConfig) *CognitiveWorkingMemo
 	wm := &CognitiveWorkingMemory{
 		capacity:            capacity,
 		items:               make(map[string]*WorkingMemoryItem),
-		activationQueue:     make(activationHeap, 0, capacity),
+		activationQueue:     newActivationQueue(),
 		chunks:              make(map[string]*Chunk),
 		itemToChunk:         make(map[string]string),
 		decayRate:           config.DecayRate,
@@ -292,8 +288,6 @@ func NewCognitiveWorkingMemory(config WorkingMemoryConfig) *CognitiveWorkingMemo
 		lastDecayTime:       time.Now(),
 		stats:               &WorkingMemoryStats{},
 	}
-
-	heap.Init(&wm.activationQueue)
 	return wm
 }
 
@@ -316,7 +310,7 @@ func (wm *CognitiveWorkingMemory) Add(item *WorkingMemoryItem) *WorkingMemoryIte
 		existing.Activation += wm.rehearsalBoost
 		existing.LastAccess = time.Now()
 		existing.AccessCount++
-		heap.Fix(&wm.activationQueue, existing.index)
+		wm.activationQueue.Fix(existing.index)
 		wm.stats.TotalAccesses++
 		return existing
 	}
@@ -343,7 +337,7 @@ func (wm *CognitiveWorkingMemory) Add(item *WorkingMemoryItem) *WorkingMemoryIte
 
 	// Add item
 	wm.items[item.ID] = item
-	heap.Push(&wm.activationQueue, item)
+	wm.activationQueue.Push(item)
 	wm.stats.TotalItemsAdded++
 
 	// Update focus
@@ -369,7 +363,7 @@ func (wm *CognitiveWorkingMemory) Get(id string) (*WorkingMemoryItem, bool) {
 	item.Activation += wm.rehearsalBoost
 	item.LastAccess = time.Now()
 	item.AccessCount++
-	heap.Fix(&wm.activationQueue, item.index)
+	wm.activationQueue.Fix(item.index)
 	wm.stats.TotalAccesses++
 
 	// Update focus
@@ -406,7 +400,7 @@ func (wm *CognitiveWorkingMemory) Remove(id string) bool {
 	}
 
 	// Remove from heap
-	heap.Remove(&wm.activationQueue, item.index)
+	wm.activationQueue.Remove(item.index)
 	delete(wm.items, id)
 
 	// Update focus
@@ -448,11 +442,10 @@ func (wm *CognitiveWorkingMemory) Clear() {
 	defer wm.mu.Unlock()
 
 	wm.items = make(map[string]*WorkingMemoryItem)
-	wm.activationQueue = make(activationHeap, 0, wm.capacity)
+	wm.activationQueue.Reset()
 	wm.chunks = make(map[string]*Chunk)
 	wm.itemToChunk = make(map[string]string)
 	wm.focusedItem = ""
-	heap.Init(&wm.activationQueue)
 }
 
 // ============================================================================
@@ -494,14 +487,14 @@ func (wm *CognitiveWorkingMemory) applyDecayLocked() {
 		if item.ChunkID != "" {
 			wm.unbindFromChunkLocked(item.ID, item.ChunkID)
 		}
-		heap.Remove(&wm.activationQueue, item.index)
+		wm.activationQueue.Remove(item.index)
 		delete(wm.items, id)
 		wm.stats.TotalItemsEvicted++
 	}
 
 	// Rebuild heap after modifications
 	if len(toRemove) > 0 {
-		heap.Init(&wm.activationQueue)
+		wm.activationQueue.Reorder()
 	}
 }
 
@@ -517,7 +510,7 @@ func (wm *CognitiveWorkingMemory) spreadActivationLocked(source *WorkingMemoryIt
 	for _, assocID := range source.Associations {
 		if assoc, ok := wm.items[assocID]; ok {
 			assoc.Activation += spreadAmount
-			heap.Fix(&wm.activationQueue, assoc.index)
+			wm.activationQueue.Fix(assoc.index)
 		}
 	}
 }
@@ -525,11 +518,11 @@ func (wm *CognitiveWorkingMemory) spreadActivationLocked(source *WorkingMemoryIt
 // evictLowestActivationLocked removes the lowest-activation item.
 // Must be called with lock held.
 func (wm *CognitiveWorkingMemory) evictLowestActivationLocked() {
-	if len(wm.activationQueue) == 0 {
+	if wm.activationQueue.Len() == 0 {
 		return
 	}
 
-	item := heap.Pop(&wm.activationQueue).(*WorkingMemoryItem)
+	item, _ := wm.activationQueue.Pop()
 
 	// Callback before removal
 	if wm.evictionCallback != nil {
@@ -578,7 +571,7 @@ func (wm *CognitiveWorkingMemory) BoostActivation(id string, amount float64) boo
 
 	item.Activation += amount
 	item.LastAccess = time.Now()
-	heap.Fix(&wm.activationQueue, item.index)
+	wm.activationQueue.Fix(item.index)
 	wm.updateFocusLocked()
 
 	return true
@@ -595,12 +588,29 @@ func (wm *CognitiveWorkingMemory) SetActivation(id string, activation float64) b
 	}
 
 	item.Activation = activation
-	heap.Fix(&wm.activationQueue, item.index)
+	wm.activationQueue.Fix(item.index)
 	wm.updateFocusLocked()
 
 	return true
 }
 
+// SetMetadata sets one metadata value of an item.
+func (wm *CognitiveWorkingMemory) SetMetadata(id, key string, value interface{}) bool {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	item, ok := wm.items[id]
+	if !ok {
+		return false
+	}
+
+	if item.Metadata == nil {
+		item.Metadata = make(map[string]interface{})
+	}
+	item.Metadata[key] = value
+	return true
+}
+
 // ============================================================================
 // Chunking Operations
 // ============================================================================
@@ -750,7 +760,7 @@ func (wm *CognitiveWorkingMemory) Focus(id string) bool {
 	// Boost activation significantly
 	item.Activation += wm.rehearsalBoost * 2
 	item.LastAccess = time.Now()
-	heap.Fix(&wm.activationQueue, item.index)
+	wm.activationQueue.Fix(item.index)
 
 	wm.focusedItem = id
 
@@ -834,6 +844,19 @@ func (wm *CognitiveWorkingMemory) GetAll() []*WorkingMemoryItem {
 	return items
 }
 
+// SnapshotItems returns copies of all items in working memory, which stay
+// safe to read while decay and rehearsal change the originals.
+func (wm *CognitiveWorkingMemory) SnapshotItems() []*WorkingMemoryItem {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	items := make([]*WorkingMemoryItem, 0, len(wm.items))
+	for _, item := range wm.items {
+		items = append(items, item.Clone())
+	}
+	return items
+}
+
 // ============================================================================
 // Association Management
 // ============================================================================
@@ -484,6 +484,47 @@ func TestWorkingMemory_Snapshot(t *testing.T) {
 		snapshot.ItemCount, snapshot.ChunkCount, snapshot.AverageActivation)
 }
 
+func TestWorkingMemory_SnapshotItems(t *testing.T) {
+	wm := NewCognitiveWorkingMemory(DefaultWorkingMemoryConfig())
+	wm.Add(&WorkingMemoryItem{ID: "a", Content: "data a", Metadata: map[string]interface{}{"step": 1}})
+
+	items := wm.SnapshotItems()
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+
+	// Copies don't follow later changes, nor change the original
+	wm.SetMetadata("a", "step", 2)
+	items[0].Metadata["step"] = 3
+	items[0].Activation = 42
+	if items[0].Metadata["step"] != 3 {
+		t.Errorf("Expected copy to keep its own metadata, got %v", items[0].Metadata["step"])
+	}
+	original, _ := wm.Peek("a")
+	if original.Metadata["step"] != 2 {
+		t.Errorf("Expected step 2, got %v", original.Metadata["step"])
+	}
+	if original.Activation == 42 {
+		t.Error("Expected original activation to be unchanged")
+	}
+}
+
+func TestWorkingMemory_SetMetadata(t *testing.T) {
+	wm := NewCognitiveWorkingMemory(DefaultWorkingMemoryConfig())
+	wm.Add(&WorkingMemoryItem{ID: "a", Content: "data a"})
+
+	if !wm.SetMetadata("a", "priority", "high") {
+		t.Fatal("Expected metadata to be set")
+	}
+	item, _ := wm.Peek("a")
+	if item.Metadata["priority"] != "high" {
+		t.Errorf("Expected priority high, got %v", item.Metadata["priority"])
+	}
+	if wm.SetMetadata("missing", "priority", "high") {
+		t.Error("Expected missing item to be reported")
+	}
+}
+
 func TestWorkingMemory_IsFull(t *testing.T) {
 	config := DefaultWorkingMemoryConfig()
 	config.Capacity = 5
@@ -0,0 +1,368 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the confidence calculus used by semantic inference.
+//
+// Confidence values used to be multiplied ad hoc wherever two facts met.
+// The calculus makes the rules explicit:
+// - Chain: a conclusion that needs every link (A→B→C) gets the product
+// - Combine: independent supporting evidence is pooled by noisy-OR or by a
+//   Bayesian log-odds update against a prior
+// - Calibration: predicted confidence is compared with observed correctness
+//   from feedback, and can be remapped to the observed frequency
+
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ============================================================================
+// Evidence Combination
+// ============================================================================
+
+// EvidenceCombination selects how independent evidence is pooled.
+type EvidenceCombination int
+
+const (
+	// CombineNoisyOR treats each source as an independent chance of being
+	// right: 1 - Π(1 - cᵢ). Never decreases confidence.
+	CombineNoisyOR EvidenceCombination = iota
+	// CombineBayesian adds each source's log-odds relative to the prior,
+	// so evidence below the prior lowers confidence.
+	CombineBayesian
+	// CombineMax keeps only the strongest source.
+	CombineMax
+)
+
+// String returns the string representation of an EvidenceCombination.
+func (c EvidenceCombination) String() string {
+	switch c {
+	case CombineNoisyOR:
+		return "noisy-or"
+	case CombineBayesian:
+		return "bayesian"
+	case CombineMax:
+		return "max"
+	default:
+		return "unknown"
+	}
+}
+
+// confidenceEpsilon keeps log-odds finite at 0 and 1.
+const confidenceEpsilon = 1e-6
+
+// ConfidenceCalculus defines how confidences propagate. The zero value
+// chains by product and pools by noisy-OR.
+type ConfidenceCalculus struct {
+	// Evidence selects how independent evidence is pooled
+	Evidence EvidenceCombination
+	// Prior is the base rate for Bayesian pooling (0 = 0.5)
+	Prior float64
+}
+
+// DefaultConfidenceCalculus returns sensible defaults.
+func DefaultConfidenceCalculus() ConfidenceCalculus {
+	return ConfidenceCalculus{
+		Evidence: CombineNoisyOR,
+		Prior:    0.5,
+	}
+}
+
+// Chain returns the confidence of a conclusion that depends on every input.
+func (c ConfidenceCalculus) Chain(confidences ...float64) float64 {
+	result := 1.0
+	for _, conf := range confidences {
+		result *= clampConfidence(conf)
+	}
+	return result
+}
+
+// Combine pools independent evidence for the same conclusion.
+// Returns 0 when there is no evidence.
+func (c ConfidenceCalculus) Combine(confidences ...float64) float64 {
+	if len(confidences) == 0 {
+		return 0
+	}
+
+	switch c.Evidence {
+	case CombineBayesian:
+		prior := c.prior()
+		logOdds := logit(prior)
+		for _, conf := range confidences {
+			logOdds += logit(conf) - logit(prior)
+		}
+		return sigmoid(logOdds)
+	case CombineMax:
+		best := 0.0
+		for _, conf := range confidences {
+			best = math.Max(best, clampConfidence(conf))
+		}
+		return best
+	default:
+		disbelief := 1.0
+		for _, conf := range confidences {
+			disbelief *= 1 - clampConfidence(conf)
+		}
+		return 1 - disbelief
+	}
+}
+
+// Update applies Bayes' rule to a prior confidence given how likely the
+// observed evidence is when the fact is true and when it is false.
+func (c ConfidenceCalculus) Update(prior, likelihoodTrue, likelihoodFalse float64) float64 {
+	prior = clampConfidence(prior)
+	numerator := prior * likelihoodTrue
+	denominator := numerator + (1-prior)*likelihoodFalse
+	if denominator <= 0 {
+		return prior
+	}
+	return clampConfidence(numerator / denominator)
+}
+
+// prior returns the configured prior, defaulting to 0.5.
+func (c ConfidenceCalculus) prior() float64 {
+	if c.Prior <= 0 || c.Prior >= 1 {
+		return 0.5
+	}
+	return c.Prior
+}
+
+// clampConfidence bounds a confidence to [0, 1].
+func clampConfidence(conf float64) float64 {
+	if math.IsNaN(conf) || conf < 0 {
+		return 0
+	}
+	if conf > 1 {
+		return 1
+	}
+	return conf
+}
+
+// logit returns the log-odds of a confidence.
+func logit(p float64) float64 {
+	p = math.Min(math.Max(p, confidenceEpsilon), 1-confidenceEpsilon)
+	return math.Log(p / (1 - p))
+}
+
+// sigmoid is the inverse of logit.
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// ============================================================================
+// Calibration
+// ============================================================================
+
+// CalibrationBin is one bucket of a reliability diagram.
+type CalibrationBin struct {
+	// Lower and Upper bound the predicted confidences in this bin
+	Lower float64
+	Upper float64
+	// Count is the number of predictions in the bin
+	Count int
+	// MeanPredicted is the average predicted confidence
+	MeanPredicted float64
+	// ObservedAccuracy is the fraction of predictions that were correct
+	ObservedAccuracy float64
+}
+
+// CalibrationReport summarizes predicted vs observed correctness.
+type CalibrationReport struct {
+	Bins []CalibrationBin
+	// Samples is the total number of recorded outcomes
+	Samples int
+	// ExpectedCalibrationError is the count-weighted mean |predicted - observed|
+	ExpectedCalibrationError float64
+	// BrierScore is the mean squared error of the predictions
+	BrierScore float64
+}
+
+// ConfidenceCalibrator records predicted confidences against feedback and
+// remaps raw confidences to the frequency actually observed.
+type ConfidenceCalibrator struct {
+	mu sync.RWMutex
+
+	numBins      int
+	minBinCount  int
+	counts       []int
+	correct      []int
+	predictedSum []float64
+	brierSum     float64
+	samples      int
+}
+
+// NewConfidenceCalibrator creates a calibrator with equal-width bins.
+// Bins with fewer than minBinCount samples are not used for remapping.
+func NewConfidenceCalibrator(numBins, minBinCount int) *ConfidenceCalibrator {
+	if numBins <= 0 {
+		numBins = 10
+	}
+	return &ConfidenceCalibrator{
+		numBins:      numBins,
+		minBinCount:  minBinCount,
+		counts:       make([]int, numBins),
+		correct:      make([]int, numBins),
+		predictedSum: make([]float64, numBins),
+	}
+}
+
+// binFor returns the bin index for a confidence.
+func (c *ConfidenceCalibrator) binFor(conf float64) int {
+	bin := int(clampConfidence(conf) * float64(c.numBins))
+	if bin >= c.numBins {
+		bin = c.numBins - 1
+	}
+	return bin
+}
+
+// Record adds an observed outcome for a predicted confidence.
+func (c *ConfidenceCalibrator) Record(predicted float64, correct bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	predicted = clampConfidence(predicted)
+	bin := c.binFor(predicted)
+	c.counts[bin]++
+	c.predictedSum[bin] += predicted
+	outcome := 0.0
+	if correct {
+		c.correct[bin]++
+		outcome = 1.0
+	}
+	c.brierSum += (predicted - outcome) * (predicted - outcome)
+	c.samples++
+}
+
+// Calibrate maps a raw confidence to the observed accuracy of its bin,
+// with add-one smoothing towards the raw value. Sparse bins return the
+// raw confidence unchanged.
+func (c *ConfidenceCalibrator) Calibrate(raw float64) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	raw = clampConfidence(raw)
+	bin := c.binFor(raw)
+	if c.counts[bin] == 0 || c.counts[bin] < c.minBinCount {
+		return raw
+	}
+	return (float64(c.correct[bin]) + raw) / float64(c.counts[bin]+1)
+}
+
+// Report returns the reliability diagram and summary errors.
+func (c *ConfidenceCalibrator) Report() *CalibrationReport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := &CalibrationReport{
+		Bins:    make([]CalibrationBin, 0, c.numBins),
+		Samples: c.samples,
+	}
+	width := 1.0 / float64(c.numBins)
+	for i := 0; i < c.numBins; i++ {
+		bin := CalibrationBin{
+			Lower: float64(i) * width,
+			Upper: float64(i+1) * width,
+			Count: c.counts[i],
+		}
+		if bin.Count > 0 {
+			bin.MeanPredicted = c.predictedSum[i] / float64(bin.Count)
+			bin.ObservedAccuracy = float64(c.correct[i]) / float64(bin.Count)
+			if c.samples > 0 {
+				report.ExpectedCalibrationError += float64(bin.Count) / float64(c.samples) *
+					math.Abs(bin.MeanPredicted-bin.ObservedAccuracy)
+			}
+		}
+		report.Bins = append(report.Bins, bin)
+	}
+	if c.samples > 0 {
+		report.BrierScore = c.brierSum / float64(c.samples)
+	}
+	return report
+}
+
+// CalibrationTable is the state of a calibrator: its recorded outcomes by
+// bin, from which it remaps confidences.
+type CalibrationTable struct {
+	// MinBinCount is the fewest samples a bin is used for remapping with
+	MinBinCount int                   `json:"min_bin_count"`
+	Bins        []CalibrationTableBin `json:"bins"`
+	// BrierSum is the summed squared error of the recorded predictions
+	BrierSum float64 `json:"brier_sum"`
+}
+
+// CalibrationTableBin holds the outcomes recorded in one bin.
+type CalibrationTableBin struct {
+	Count        int     `json:"count"`
+	Correct      int     `json:"correct"`
+	PredictedSum float64 `json:"predicted_sum"`
+}
+
+// validate checks a table can be loaded into a calibrator.
+func (t *CalibrationTable) validate() error {
+	if len(t.Bins) == 0 {
+		return fmt.Errorf("calibration table has no bins")
+	}
+	if t.MinBinCount < 0 || t.BrierSum < 0 {
+		return fmt.Errorf("calibration table has a negative minimum or Brier sum")
+	}
+	for i, bin := range t.Bins {
+		if bin.Count < 0 || bin.Correct < 0 || bin.Correct > bin.Count || bin.PredictedSum < 0 {
+			return fmt.Errorf("calibration bin %d is inconsistent", i)
+		}
+	}
+	return nil
+}
+
+// Table returns a copy of the calibrator's state.
+func (c *ConfidenceCalibrator) Table() CalibrationTable {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	table := CalibrationTable{
+		MinBinCount: c.minBinCount,
+		Bins:        make([]CalibrationTableBin, c.numBins),
+		BrierSum:    c.brierSum,
+	}
+	for i := range table.Bins {
+		table.Bins[i] = CalibrationTableBin{Count: c.counts[i], Correct: c.correct[i], PredictedSum: c.predictedSum[i]}
+	}
+	return table
+}
+
+// SetTable replaces the calibrator's state, e.g. with a table saved
+// earlier. The table's bins replace the calibrator's, however many.
+func (c *ConfidenceCalibrator) SetTable(table CalibrationTable) error {
+	if err := table.validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.numBins = len(table.Bins)
+	c.minBinCount = table.MinBinCount
+	c.counts = make([]int, c.numBins)
+	c.correct = make([]int, c.numBins)
+	c.predictedSum = make([]float64, c.numBins)
+	c.samples = 0
+	for i, bin := range table.Bins {
+		c.counts[i] = bin.Count
+		c.correct[i] = bin.Correct
+		c.predictedSum[i] = bin.PredictedSum
+		c.samples += bin.Count
+	}
+	c.brierSum = table.BrierSum
+	return nil
+}
+
+// Reset discards all recorded outcomes.
+func (c *ConfidenceCalibrator) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts = make([]int, c.numBins)
+	c.correct = make([]int, c.numBins)
+	c.predictedSum = make([]float64, c.numBins)
+	c.brierSum = 0
+	c.samples = 0
+}
@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// ============================================================================
+// Confidence Calculus Tests
+// ============================================================================
+
+func TestConfidenceCalculus_Chain(t *testing.T) {
+	calc := DefaultConfidenceCalculus()
+
+	if got := calc.Chain(0.9, 0.8); math.Abs(got-0.72) > 1e-9 {
+		t.Errorf("Expected 0.72, got %f", got)
+	}
+	if got := calc.Chain(); got != 1.0 {
+		t.Errorf("Expected empty chain to be 1.0, got %f", got)
+	}
+	if got := calc.Chain(1.5, -0.2); got != 0 {
+		t.Errorf("Expected out-of-range inputs to be clamped, got %f", got)
+	}
+}
+
+func TestConfidenceCalculus_CombineNoisyOR(t *testing.T) {
+	calc := ConfidenceCalculus{Evidence: CombineNoisyOR}
+
+	// 1 - (0.5 * 0.4) = 0.8
+	if got := calc.Combine(0.5, 0.6); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("Expected 0.8, got %f", got)
+	}
+	if got := calc.Combine(0.3); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("Expected single source unchanged, got %f", got)
+	}
+	if got := calc.Combine(); got != 0 {
+		t.Errorf("Expected no evidence to give 0, got %f", got)
+	}
+}
+
+func TestConfidenceCalculus_CombineBayesian(t *testing.T) {
+	calc := ConfidenceCalculus{Evidence: CombineBayesian, Prior: 0.5}
+
+	// Two independent 0.8 sources: odds 4 * 4 = 16 → 16/17
+	if got := calc.Combine(0.8, 0.8); math.Abs(got-16.0/17.0) > 1e-6 {
+		t.Errorf("Expected %f, got %f", 16.0/17.0, got)
+	}
+	// Opposing evidence cancels out
+	if got := calc.Combine(0.8, 0.2); math.Abs(got-0.5) > 1e-6 {
+		t.Errorf("Expected opposing evidence to cancel, got %f", got)
+	}
+	// Evidence below the prior lowers confidence, unlike noisy-OR
+	if got := calc.Combine(0.3, 0.3); got >= 0.3 {
+		t.Errorf("Expected weak evidence to lower confidence, got %f", got)
+	}
+}
+
+func TestConfidenceCalculus_CombineMax(t *testing.T) {
+	calc := ConfidenceCalculus{Evidence: CombineMax}
+	if got := calc.Combine(0.2, 0.7, 0.4); got != 0.7 {
+		t.Errorf("Expected 0.7, got %f", got)
+	}
+}
+
+func TestConfidenceCalculus_Update(t *testing.T) {
+	calc := DefaultConfidenceCalculus()
+
+	// Prior 0.5, evidence 3x more likely when true → 0.75
+	if got := calc.Update(0.5, 0.6, 0.2); math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("Expected 0.75, got %f", got)
+	}
+	if got := calc.Update(0.4, 0, 0); got != 0.4 {
+		t.Errorf("Expected uninformative evidence to keep the prior, got %f", got)
+	}
+}
+
+func TestEvidenceCombination_String(t *testing.T) {
+	tests := map[EvidenceCombination]string{
+		CombineNoisyOR:         "noisy-or",
+		CombineBayesian:        "bayesian",
+		CombineMax:             "max",
+		EvidenceCombination(9): "unknown",
+	}
+	for combination, expected := range tests {
+		if combination.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, combination.String())
+		}
+	}
+}
+
+// ============================================================================
+// Calibration Tests
+// ============================================================================
+
+// simulateFeedback records predictions whose true probability of being
+// correct is truth(predicted).
+func simulateFeedback(calibrator *ConfidenceCalibrator, n int, truth func(float64) float64) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < n; i++ {
+		predicted := rng.Float64()
+		calibrator.Record(predicted, rng.Float64() < truth(predicted))
+	}
+}
+
+func TestConfidenceCalibrator_WellCalibrated(t *testing.T) {
+	calibrator := NewConfidenceCalibrator(10, 20)
+	simulateFeedback(calibrator, 5000, func(p float64) float64 { return p })
+
+	report := calibrator.Report()
+	if report.Samples != 5000 {
+		t.Errorf("Expected 5000 samples, got %d", report.Samples)
+	}
+	if report.ExpectedCalibrationError > 0.03 {
+		t.Errorf("Expected low ECE for calibrated predictions, got %f", report.ExpectedCalibrationError)
+	}
+	for _, bin := range report.Bins {
+		if bin.Count > 0 && (bin.MeanPredicted < bin.Lower || bin.MeanPredicted > bin.Upper) {
+			t.Errorf("Mean predicted %f outside bin [%f, %f]", bin.MeanPredicted, bin.Lower, bin.Upper)
+		}
+	}
+}
+
+func TestConfidenceCalibrator_Overconfident(t *testing.T) {
+	calibrator := NewConfidenceCalibrator(10, 20)
+	// Predictions are right only p² of the time
+	simulateFeedback(calibrator, 5000, func(p float64) float64 { return p * p })
+
+	report := calibrator.Report()
+	if report.ExpectedCalibrationError < 0.1 {
+		t.Errorf("Expected high ECE for overconfident predictions, got %f", report.ExpectedCalibrationError)
+	}
+
+	// Calibrated confidence should move towards the observed p² = 0.36
+	raw := 0.6
+	calibrated := calibrator.Calibrate(raw)
+	if math.Abs(calibrated-0.36) >= math.Abs(raw-0.36) {
+		t.Errorf("Expected calibrated %f to be closer to 0.36 than raw %f", calibrated, raw)
+	}
+
+	calibrator.Reset()
+	if calibrator.Calibrate(raw) != raw {
+		t.Error("Expected raw confidence after reset")
+	}
+	if calibrator.Report().Samples != 0 {
+		t.Error("Expected no samples after reset")
+	}
+}
+
+func TestConfidenceCalibrator_SparseBinsUnchanged(t *testing.T) {
+	calibrator := NewConfidenceCalibrator(10, 5)
+	calibrator.Record(0.9, false)
+	calibrator.Record(0.9, false)
+
+	if got := calibrator.Calibrate(0.95); got != 0.95 {
+		t.Errorf("Expected sparse bin to return raw confidence, got %f", got)
+	}
+}
+
+// ============================================================================
+// Propagation Tests
+// ============================================================================
+
+func TestSemanticNetwork_InheritedConfidenceChains(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	animal := NewSemanticNode("animal", "Animal", ConceptNode)
+	animal.Confidence = 0.9
+	animal.SetProperty("alive", true)
+	sn.AddNode(animal)
+	sn.AddNode(NewSemanticNode("dog", "Dog", ConceptNode))
+	sn.AddNode(NewSemanticNode("rex", "Rex", InstanceNode))
+
+	dogAnimal := NewSemanticRelation("dog", "animal", IsA)
+	dogAnimal.Confidence = 0.8
+	sn.AddRelation(dogAnimal)
+	rexDog := NewSemanticRelation("rex", "dog", IsA)
+	rexDog.Confidence = 0.5
+	sn.AddRelation(rexDog)
+
+	props, err := sn.GetInheritedProperties("rex")
+	if err != nil {
+		t.Fatalf("GetInheritedProperties failed: %v", err)
+	}
+	prop, ok := props["alive"]
+	if !ok {
+		t.Fatal("Expected 'alive' to be inherited")
+	}
+	// Every link and the source node must hold: 0.5 * 0.8 * 0.9
+	if math.Abs(prop.Confidence-0.36) > 1e-9 {
+		t.Errorf("Expected chained confidence 0.36, got %f", prop.Confidence)
+	}
+}
+
+func TestSemanticInferenceEngine_MembershipConfidence(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"rex", "dog", "animal"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	rexDog := NewSemanticRelation("rex", "dog", IsA)
+	rexDog.Confidence = 0.9
+	sn.AddRelation(rexDog)
+	dogAnimal := NewSemanticRelation("dog", "animal", IsA)
+	dogAnimal.Confidence = 0.5
+	sn.AddRelation(dogAnimal)
+
+	engine := NewSemanticInferenceEngine(sn)
+	result, err := engine.InferMembership("rex", "animal")
+	if err != nil {
+		t.Fatalf("InferMembership failed: %v", err)
+	}
+	if math.Abs(result.Confidence-0.45) > 1e-9 {
+		t.Errorf("Expected chained confidence 0.45, got %f", result.Confidence)
+	}
+	if result.CalibratedConfidence != result.Confidence {
+		t.Errorf("Expected uncalibrated engine to pass confidence through, got %f", result.CalibratedConfidence)
+	}
+}
+
+func TestSemanticInferenceEngine_RecordFeedback(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"rex", "dog"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	rel := NewSemanticRelation("rex", "dog", IsA)
+	rel.Confidence = 0.95
+	sn.AddRelation(rel)
+
+	engine := NewSemanticInferenceEngine(sn)
+	result, err := engine.InferMembership("rex", "dog")
+	if err != nil {
+		t.Fatalf("InferMembership failed: %v", err)
+	}
+
+	// The feedback loop says these answers are right only half the time
+	for i := 0; i < 40; i++ {
+		engine.RecordFeedback(result, i%2 == 0)
+	}
+
+	report := engine.GetCalibrationReport()
+	if report.Samples != 40 {
+		t.Errorf("Expected 40 samples, got %d", report.Samples)
+	}
+	if math.Abs(report.ExpectedCalibrationError-0.45) > 1e-9 {
+		t.Errorf("Expected ECE 0.45, got %f", report.ExpectedCalibrationError)
+	}
+
+	again, _ := engine.InferMembership("rex", "dog")
+	if again.CalibratedConfidence >= again.Confidence || again.CalibratedConfidence > 0.6 {
+		t.Errorf("Expected calibrated confidence near 0.5, got %f", again.CalibratedConfidence)
+	}
+}
+
+func TestConfidenceCalibrator_Table(t *testing.T) {
+	c := NewConfidenceCalibrator(5, 2)
+	for i := 0; i < 10; i++ {
+		c.Record(0.85, i%2 == 0)
+	}
+	table := c.Table()
+	if len(table.Bins) != 5 || table.Bins[4].Count != 10 || table.Bins[4].Correct != 5 {
+		t.Fatalf("unexpected table %+v", table)
+	}
+
+	restored := NewConfidenceCalibrator(10, 20)
+	if err := restored.SetTable(table); err != nil {
+		t.Fatalf("SetTable failed: %v", err)
+	}
+	if restored.Calibrate(0.85) != c.Calibrate(0.85) || restored.Report().BrierScore != c.Report().BrierScore {
+		t.Errorf("expected the restored calibrator to match, got %v", restored.Report())
+	}
+	if err := restored.SetTable(CalibrationTable{}); err == nil {
+		t.Error("expected a table without bins rejected")
+	}
+}
@@ -166,6 +166,18 @@ type MemoryConsolidator struct {
 	stats   *ConsolidationStats
 	statsMu sync.RWMutex
 
+	// Optional semantic relationship discovery run after each consolidation
+	discoveryLearner  *ConceptLearner
+	discoveryOptions  DiscoveryOptions
+	discoveryProgress DiscoveryProgress
+	// discoveryHypotheses stages low-confidence discoveries when set
+	discoveryHypotheses *HypothesisStore
+	discoveryMu         sync.RWMutex
+
+	// leader gates automatic consolidation to one replica when set
+	leader   *LeaderElector
+	leaderMu sync.RWMutex
+
 	// Control
 	stopChan chan struct{}
 	doneChan chan struct{}
@@ -180,6 +192,10 @@ type ConsolidationStats struct {
 	CompressionRatio      float64
 	LastConsolidationTime time.Time
 	AverageClusterSize    float64
+	// RelationshipsDiscovered counts semantic relations added by discovery
+	RelationshipsDiscovered int64
+	// HypothesesStaged counts discovered relations held for corroboration
+	HypothesesStaged int64
 }
 
 // NewMemoryConsolidator creates a new memory consolidator.
@@ -260,7 +276,7 @@ func (mc *MemoryConsolidator) Consolidate() (*ConsolidationResult, error) {
 			abstraction := mc.computeAbstractionLevel(cluster)
 
 			consolidated := &ConsolidatedMemory{
-				ID:               fmt.Sprintf("consolidated-%d", time.Now().UnixNano()),
+				ID:               NewID("consolidated"),
 				Schema:           schema,
 				Exemplars:        exemplars,
 				Frequency:        len(cluster),
@@ -283,7 +299,10 @@ func (mc *MemoryConsolidator) Consolidate() (*ConsolidationResult, error) {
 	}
 	mc.consolidatedMu.Unlock()
 
-	// 5. Update statistics
+	// 5. Discover semantic relationships, if enabled
+	discovery := mc.runDiscovery()
+
+	// 6. Update statistics
 	result := &ConsolidationResult{
 		ExperiencesProcessed: len(eligible),
 		ClustersFormed:       len(clusters),
@@ -291,6 +310,7 @@ func (mc *MemoryConsolidator) Consolidate() (*ConsolidationResult, error) {
 		ConsolidatedMemories: newConsolidated,
 		Duration:             time.Since(startTime),
 		CompressionRatio:     mc.calculateCompressionRatio(eligible, newConsolidated),
+		Discovery:            discovery,
 	}
 
 	mc.updateStats(result)
@@ -306,6 +326,8 @@ type ConsolidationResult struct {
 	ConsolidatedMemories []*ConsolidatedMemory
 	Duration             time.Duration
 	CompressionRatio     float64
+	// Discovery is the relationship discovery run, nil when disabled
+	Discovery *DiscoveryResult
 }
 
 // filterEligible filters experiences by access recency.
@@ -601,13 +623,30 @@ func (mc *MemoryConsolidator) runAutoConsolidation() {
 	for {
 		select {
 		case <-ticker.C:
-			mc.Consolidate()
+			if mc.isLeader() {
+				mc.Consolidate()
+			}
 		case <-mc.stopChan:
 			return
 		}
 	}
 }
 
+// SetLeaderElector restricts automatic consolidation to the elected leader
+// in multi-replica deployments. Manual Consolidate calls are not affected.
+func (mc *MemoryConsolidator) SetLeaderElector(elector *LeaderElector) {
+	mc.leaderMu.Lock()
+	defer mc.leaderMu.Unlock()
+	mc.leader = elector
+}
+
+// isLeader returns true if this replica should run automatic consolidation.
+func (mc *MemoryConsolidator) isLeader() bool {
+	mc.leaderMu.RLock()
+	defer mc.leaderMu.RUnlock()
+	return mc.leader == nil || mc.leader.IsLeader()
+}
+
 // GetConsolidated returns all consolidated memories.
 func (mc *MemoryConsolidator) GetConsolidated() map[string]*ConsolidatedMemory {
 	mc.consolidatedMu.RLock()
@@ -637,14 +676,95 @@ func (mc *MemoryConsolidator) GetStats() *ConsolidationStats {
 
 	// Return copy
 	return &ConsolidationStats{
-		TotalConsolidations:   mc.stats.TotalConsolidations,
-		ExperiencesProcessed:  mc.stats.ExperiencesProcessed,
-		ClustersFormed:        mc.stats.ClustersFormed,
-		SchemasExtracted:      mc.stats.SchemasExtracted,
-		CompressionRatio:      mc.stats.CompressionRatio,
-		LastConsolidationTime: mc.stats.LastConsolidationTime,
-		AverageClusterSize:    mc.stats.AverageClusterSize,
+		TotalConsolidations:     mc.stats.TotalConsolidations,
+		ExperiencesProcessed:    mc.stats.ExperiencesProcessed,
+		ClustersFormed:          mc.stats.ClustersFormed,
+		SchemasExtracted:        mc.stats.SchemasExtracted,
+		CompressionRatio:        mc.stats.CompressionRatio,
+		LastConsolidationTime:   mc.stats.LastConsolidationTime,
+		AverageClusterSize:      mc.stats.AverageClusterSize,
+		RelationshipsDiscovered: mc.stats.RelationshipsDiscovered,
+		HypothesesStaged:        mc.stats.HypothesesStaged,
+	}
+}
+
+// EnableRelationshipDiscovery runs budgeted semantic relationship discovery
+// after each consolidation and adds the discovered relations to the
+// learner's network.
+func (mc *MemoryConsolidator) EnableRelationshipDiscovery(learner *ConceptLearner, opts DiscoveryOptions) {
+	mc.discoveryMu.Lock()
+	defer mc.discoveryMu.Unlock()
+
+	mc.discoveryLearner = learner
+	mc.discoveryOptions = opts
+}
+
+// SetDiscoveryHypotheses routes discovered relations through a hypothesis
+// store, so only confident discoveries go straight into the network.
+func (mc *MemoryConsolidator) SetDiscoveryHypotheses(store *HypothesisStore) {
+	mc.discoveryMu.Lock()
+	defer mc.discoveryMu.Unlock()
+
+	mc.discoveryHypotheses = store
+}
+
+// GetDiscoveryProgress returns the progress of the current or last
+// relationship discovery run.
+func (mc *MemoryConsolidator) GetDiscoveryProgress() DiscoveryProgress {
+	mc.discoveryMu.RLock()
+	defer mc.discoveryMu.RUnlock()
+
+	return mc.discoveryProgress
+}
+
+// runDiscovery runs relationship discovery if enabled and commits results.
+func (mc *MemoryConsolidator) runDiscovery() *DiscoveryResult {
+	mc.discoveryMu.RLock()
+	learner := mc.discoveryLearner
+	opts := mc.discoveryOptions
+	hypotheses := mc.discoveryHypotheses
+	mc.discoveryMu.RUnlock()
+
+	if learner == nil {
+		return nil
 	}
+
+	callerProgress := opts.Progress
+	opts.Progress = func(p DiscoveryProgress) {
+		mc.discoveryMu.Lock()
+		mc.discoveryProgress = p
+		mc.discoveryMu.Unlock()
+		if callerProgress != nil {
+			callerProgress(p)
+		}
+	}
+
+	result := learner.DiscoverRelationshipsWithOptions(opts)
+
+	added, staged := 0, 0
+	for _, rel := range result.Relations {
+		if hypotheses == nil {
+			if err := learner.network.AddRelation(rel); err == nil {
+				added++
+			}
+			continue
+		}
+		h, err := hypotheses.ProposeRelation(rel)
+		switch {
+		case err != nil:
+		case h == nil || h.Status == HypothesisAccepted:
+			added++
+		case h.Status == HypothesisPending:
+			staged++
+		}
+	}
+
+	mc.statsMu.Lock()
+	mc.stats.RelationshipsDiscovered += int64(added)
+	mc.stats.HypothesesStaged += int64(staged)
+	mc.statsMu.Unlock()
+
+	return result
 }
 
 // GetBufferSize returns current short-term buffer size.
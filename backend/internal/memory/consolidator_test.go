@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -537,3 +538,17 @@ func BenchmarkConsolidator_Consolidate(b *testing.B) {
 		}
 	}
 }
+
+func TestMemoryConsolidator_SetLeaderElector(t *testing.T) {
+	mc := NewMemoryConsolidator(nil)
+	if !mc.isLeader() {
+		t.Error("Expected consolidation enabled without an elector")
+	}
+
+	lock := NewMemoryLeaseLock()
+	lock.TryAcquire(context.Background(), "other", time.Minute)
+	mc.SetLeaderElector(NewLeaderElector(lock, DefaultLeaderElectionConfig("self")))
+	if mc.isLeader() {
+		t.Error("Expected automatic consolidation disabled on a follower")
+	}
+}
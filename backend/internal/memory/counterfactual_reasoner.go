@@ -180,7 +180,7 @@ func (cr *CounterfactualReasoner) AnalyzeCounterfactuals(ctx context.Context, go
 	cr.requestCount++
 
 	analysis := &CounterfactualAnalysis{
-		ID:           fmt.Sprintf("analysis-%s-%d", goal.ID, time.Now().Unix()),
+		ID:           NewID("analysis-" + goal.ID),
 		OriginalGoal: goal,
 		Scenarios:    make([]*Scenario, 0),
 		Predictions:  make(map[string]OutcomePrediction),
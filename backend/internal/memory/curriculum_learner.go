@@ -3,13 +3,14 @@
 package memory
 
 import (
-	"container/heap"
 	"context"
 	"errors"
 	"math"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
 )
 
 // =============================================================================
@@ -760,74 +761,50 @@ func (c *CurriculumLearner) ApplyDecay() {
 
 // TaskPriorityQueue implements a priority queue for tasks
 type TaskPriorityQueue struct {
-	items []*priorityItem
+	items *pqueue.Queue[*priorityItem]
 }
 
 type priorityItem struct {
 	task     *CurriculumTask
 	priority float64
-	index    int
-}
-
-func (pq TaskPriorityQueue) Len() int { return len(pq.items) }
-
-func (pq TaskPriorityQueue) Less(i, j int) bool {
-	return pq.items[i].priority > pq.items[j].priority // Higher priority first
-}
-
-func (pq TaskPriorityQueue) Swap(i, j int) {
-	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
-	pq.items[i].index = i
-	pq.items[j].index = j
-}
-
-func (pq *TaskPriorityQueue) Push(x interface{}) {
-	n := len(pq.items)
-	item := x.(*priorityItem)
-	item.index = n
-	pq.items = append(pq.items, item)
-}
-
-func (pq *TaskPriorityQueue) Pop() interface{} {
-	old := pq.items
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	pq.items = old[0 : n-1]
-	return item
 }
 
 // NewTaskPriorityQueue creates a new priority queue
 func NewTaskPriorityQueue() *TaskPriorityQueue {
-	pq := &TaskPriorityQueue{items: make([]*priorityItem, 0)}
-	heap.Init(pq)
-	return pq
+	return &TaskPriorityQueue{items: pqueue.New(func(a, b *priorityItem) bool {
+		return a.priority > b.priority // Higher priority first
+	})}
+}
+
+// Len returns the number of queued tasks
+func (pq *TaskPriorityQueue) Len() int {
+	return pq.items.Len()
 }
 
 // Add adds a task with priority
 func (pq *TaskPriorityQueue) Add(task *CurriculumTask, priority float64) {
-	heap.Push(pq, &priorityItem{task: task, priority: priority})
+	pq.items.Push(&priorityItem{task: task, priority: priority})
 }
 
 // GetNext returns and removes the highest priority task
 func (pq *TaskPriorityQueue) GetNext() *CurriculumTask {
-	if len(pq.items) == 0 {
+	item, ok := pq.items.Pop()
+	if !ok {
 		return nil
 	}
-	item := heap.Pop(pq).(*priorityItem)
 	return item.task
 }
 
 // Peek returns the highest priority task without removing it
 func (pq *TaskPriorityQueue) Peek() *CurriculumTask {
-	if len(pq.items) == 0 {
+	item, ok := pq.items.Peek()
+	if !ok {
 		return nil
 	}
-	return pq.items[0].task
+	return item.task
 }
 
 // IsEmpty returns true if queue is empty
 func (pq *TaskPriorityQueue) IsEmpty() bool {
-	return len(pq.items) == 0
+	return pq.items.Len() == 0
 }
@@ -0,0 +1,129 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the automatic embedding of knowledge graph nodes and
+// experiences.
+//
+// A semantic network or retriever given an embedder embeds what is added to
+// it without an embedding: a node's label and text properties, an
+// experience's input. Similarity, concept discovery and the LSH and HNSW
+// indexes then have vectors to work with. Replayed log records are added as
+// logged, so restarts embed nothing again. Failures are logged and leave
+// the node or experience without an embedding; they never block the write.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// embedTimeout bounds embedding one node or experience.
+const embedTimeout = 10 * time.Second
+
+// NodeText returns the text of a node that is embedded: its label and its
+// string properties, sorted by key.
+func NodeText(node *SemanticNode) string {
+	parts := make([]string, 0, len(node.Properties)+1)
+	if node.Label != "" {
+		parts = append(parts, node.Label)
+	}
+	keys := make([]string, 0, len(node.Properties))
+	for key, value := range node.Properties {
+		if text, ok := value.(string); ok && strings.TrimSpace(text) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, node.Properties[key].(string))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// SetEmbedder sets the embedder nodes added without an embedding are
+// embedded with. It must be set before the network is used.
+func (sn *SemanticNetwork) SetEmbedder(embedder TextEmbedder) {
+	sn.embedder = embedder
+}
+
+// embedNode embeds a node about to be added, unless it has an embedding,
+// has no text or already exists.
+func (sn *SemanticNetwork) embedNode(node *SemanticNode) {
+	if sn.embedder == nil || node == nil || len(node.Embedding) > 0 {
+		return
+	}
+	sn.mu.RLock()
+	_, exists := sn.nodes[node.ID]
+	sn.mu.RUnlock()
+	if exists {
+		return
+	}
+	if vector, err := embedText(sn.embedder, NodeText(node)); err != nil {
+		log.Printf("Error embedding node %s: %v", node.ID, err)
+	} else {
+		node.Embedding = vector
+	}
+}
+
+// SetEmbedder sets the embedder experiences added without an embedding are
+// embedded with. Vectors of another dimension than the retriever's are kept
+// on the experience but not indexed. It must be set before the retriever is
+// used.
+func (r *SubLinearRetriever) SetEmbedder(embedder TextEmbedder) {
+	r.embedder = embedder
+}
+
+// embedExperience embeds the input of an experience about to be added,
+// unless it has an embedding or no input.
+func (r *SubLinearRetriever) embedExperience(exp *ExperienceTuple) {
+	if r.embedder == nil || len(exp.Embedding) > 0 {
+		return
+	}
+	if vector, err := embedText(r.embedder, exp.Input); err != nil {
+		log.Printf("Error embedding experience %s: %v", exp.ID, err)
+	} else {
+		exp.Embedding = vector
+	}
+}
+
+// embedText embeds one text, returning nil for text without words.
+func embedText(embedder TextEmbedder, text string) ([]float32, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+	defer cancel()
+	vectors, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("got %d vectors for one text", len(vectors))
+	}
+	return vectors[0], nil
+}
+
+// textEmbeddingService computes the ReMem loop's embeddings with a
+// TextEmbedder.
+type textEmbeddingService struct {
+	embedder TextEmbedder
+}
+
+// NewEmbeddingService adapts an embedder, such as the embedding cache, to
+// the EmbeddingService the ReMem loop embeds queries and experiences with.
+func NewEmbeddingService(embedder TextEmbedder) EmbeddingService {
+	return &textEmbeddingService{embedder: embedder}
+}
+
+// Embed embeds one text.
+func (s *textEmbeddingService) Embed(text string) ([]float32, error) {
+	vector, err := embedText(s.embedder, text)
+	if err == nil && vector == nil {
+		return nil, errors.New("no text to embed")
+	}
+	return vector, err
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingEmbedder counts the texts it embeds.
+type countingEmbedder struct {
+	wordEmbedder
+	texts []string
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.texts = append(e.texts, texts...)
+	return e.wordEmbedder.Embed(ctx, texts)
+}
+
+func newCountingEmbedder() *countingEmbedder {
+	return &countingEmbedder{wordEmbedder: wordEmbedder{vocabulary: []string{"sort", "quick", "merge", "stable"}}}
+}
+
+func TestNodeText(t *testing.T) {
+	node := NewSemanticNode("quicksort", "QuickSort", InstanceNode)
+	node.Properties["summary"] = "Divide and conquer"
+	node.Properties["author"] = "Hoare"
+	node.Properties["stable"] = false
+	if got := NodeText(node); got != "QuickSort. Hoare. Divide and conquer" {
+		t.Errorf("unexpected node text %q", got)
+	}
+}
+
+func TestSemanticNetwork_EmbedsNodes(t *testing.T) {
+	wal, path := openTestWAL(t)
+	embedder := newCountingEmbedder()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.SetEmbedder(embedder)
+	sn.AttachWAL(wal)
+
+	sn.AddNode(NewSemanticNode("quicksort", "Quick sort", InstanceNode))
+	sn.AddNode(NewSemanticNode("mergesort", "Merge sort, stable", InstanceNode))
+	preset := NewSemanticNode("preset", "Quick", InstanceNode)
+	preset.Embedding = []float32{1, 0, 0, 0}
+	sn.AddNode(preset)
+	if err := sn.AddNode(NewSemanticNode("quicksort", "Quick sort", InstanceNode)); !errors.Is(err, ErrNodeAlreadyExists) {
+		t.Fatalf("expected ErrNodeAlreadyExists, got %v", err)
+	}
+	if len(embedder.texts) != 2 {
+		t.Errorf("expected only the two new nodes without embeddings embedded, got %v", embedder.texts)
+	}
+
+	result, err := sn.ComputeSimilarity("quicksort", "mergesort")
+	if err != nil || result.Method != "embedding" || result.Similarity <= 0 || result.Similarity >= 1 {
+		t.Errorf("expected similarity by embedding, got %+v (%v)", result, err)
+	}
+	wal.Close()
+
+	// Replay restores the logged embeddings without embedding again
+	wal, err = OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	replayEmbedder := newCountingEmbedder()
+	recovered := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	recovered.SetEmbedder(replayEmbedder)
+	if _, err := recovered.ReplayWAL(wal, 0); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	node, _ := recovered.GetNode("mergesort")
+	if len(replayEmbedder.texts) != 0 || len(node.Embedding) != 4 {
+		t.Errorf("expected the logged embedding replayed, embedded %v and got %v", replayEmbedder.texts, node.Embedding)
+	}
+}
+
+func TestSemanticNetwork_EmbedFailureKeepsNode(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.SetEmbedder(&wordEmbedder{err: errors.New("model unavailable")})
+	if err := sn.AddNode(NewSemanticNode("quicksort", "Quick sort", InstanceNode)); err != nil {
+		t.Fatalf("expected the node added despite the embedder, got %v", err)
+	}
+	if node, _ := sn.GetNode("quicksort"); len(node.Embedding) != 0 {
+		t.Errorf("expected no embedding, got %v", node.Embedding)
+	}
+}
+
+func TestSubLinearRetriever_EmbedsExperiences(t *testing.T) {
+	embedder := newCountingEmbedder()
+	r := NewSubLinearRetriever(4)
+	r.SetEmbedder(embedder)
+
+	exp := NewExperienceTuple("APEX", 1, "quick sort a list", "done", "partition")
+	if err := r.Add(exp); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(exp.Embedding) != 4 || exp.Embedding[0] != 1 || exp.Embedding[1] != 1 {
+		t.Errorf("expected the input embedded, got %v", exp.Embedding)
+	}
+	if ids := r.hnsw.SearchIDs([]float32{1, 1, 0, 0}, 1); len(ids) != 1 || ids[0] != exp.ID {
+		t.Errorf("expected the experience indexed by its embedding, got %v", ids)
+	}
+
+	r.EnableDedup(DefaultDedupConfig())
+	merged := NewExperienceTuple("APEX", 1, "merge sort, stable", "done", "merge")
+	if _, _, err := r.AddOrMerge(merged); err != nil {
+		t.Fatalf("AddOrMerge failed: %v", err)
+	}
+	if len(merged.Embedding) != 4 || len(embedder.texts) != 2 {
+		t.Errorf("expected each input embedded once, got %v", embedder.texts)
+	}
+}
+
+func TestEmbeddingService(t *testing.T) {
+	service := NewEmbeddingService(newCountingEmbedder())
+	if vector, err := service.Embed("stable sort"); err != nil || len(vector) != 4 {
+		t.Errorf("expected a vector, got %v (%v)", vector, err)
+	}
+	if _, err := service.Embed("  "); err == nil {
+		t.Error("expected an error for blank text")
+	}
+}
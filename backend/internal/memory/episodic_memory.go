@@ -0,0 +1,256 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements episodic memory: experiences as they happened.
+//
+// Where the sub-linear retriever finds experiences by what they are about,
+// episodic memory keeps them in the order they happened, so the collective
+// can recall what an agent did last Tuesday, or what happened around an
+// incident. Episodes are kept for a retention period and up to a capacity;
+// those that age out are handed to the consolidation hook on their way out,
+// so their patterns can live on as schemas after the episodes are gone.
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EpisodicConfig configures an episodic memory.
+type EpisodicConfig struct {
+	// Retention is how long episodes are kept; zero keeps them until
+	// capacity pushes them out
+	Retention time.Duration
+	// Capacity bounds the episodes kept, the oldest leaving first; zero is
+	// unbounded
+	Capacity int
+	// Clock supplies the time (default: SystemClock)
+	Clock Clock
+}
+
+// DefaultEpisodicConfig returns defaults keeping a week of episodes, up
+// to 100,000 of them.
+func DefaultEpisodicConfig() EpisodicConfig {
+	return EpisodicConfig{
+		Retention: 7 * 24 * time.Hour,
+		Capacity:  100000,
+	}
+}
+
+// EpisodeQuery selects episodes. Zero fields match every episode.
+type EpisodeQuery struct {
+	// Since and Until bound when episodes happened, Since inclusive and
+	// Until exclusive
+	Since time.Time
+	Until time.Time
+	// AgentID, TaskSignature and TaskType match episodes exactly
+	AgentID       string
+	TaskSignature string
+	TaskType      string
+	// Success matches episodes by outcome
+	Success *bool
+	// Embedding orders episodes by similarity to it, most similar first,
+	// instead of newest first; episodes without embeddings don't match
+	Embedding []float32
+	// MinSimilarity drops episodes less similar than it to Embedding
+	MinSimilarity float64
+	// Limit bounds the episodes returned; zero returns all that match
+	Limit int
+}
+
+// EpisodeMatch is an episode a query matched.
+type EpisodeMatch struct {
+	Experience *ExperienceTuple
+	// Similarity is the episode's cosine similarity to the query's
+	// embedding, zero when the query has none
+	Similarity float64
+}
+
+// EpisodicMemory keeps experiences in the order they happened, queried by
+// time window, agent, task and similarity. It is safe for concurrent use.
+type EpisodicMemory struct {
+	mu     sync.RWMutex
+	config EpisodicConfig
+	clock  Clock
+	// episodes are ordered by timestamp, and those of each agent in
+	// byAgent too, so the oldest of all is the oldest of its agent
+	episodes []*ExperienceTuple
+	byAgent  map[string][]*ExperienceTuple
+
+	onConsolidate func([]*ExperienceTuple)
+}
+
+// NewEpisodicMemory creates an empty episodic memory.
+func NewEpisodicMemory(config EpisodicConfig) *EpisodicMemory {
+	return &EpisodicMemory{
+		config:  config,
+		clock:   clockOrSystem(config.Clock),
+		byAgent: make(map[string][]*ExperienceTuple),
+	}
+}
+
+// OnConsolidate sets a callback for the episodes leaving memory, past
+// their retention or pushed out by capacity, oldest first. The memory no
+// longer holds them, so the callback may keep them. It is called without
+// the memory locked, so it may feed them to a MemoryConsolidator or query
+// the memory. Set before the memory is shared between goroutines.
+func (m *EpisodicMemory) OnConsolidate(fn func(episodes []*ExperienceTuple)) {
+	m.onConsolidate = fn
+}
+
+// Record remembers a copy of an experience as an episode. Experiences
+// without a timestamp happened now. Episodes already past retention are
+// handed straight to consolidation.
+func (m *EpisodicMemory) Record(exp *ExperienceTuple) error {
+	if exp == nil || exp.AgentID == "" {
+		return ErrInvalidExperience
+	}
+	// Episodes are ordered by timestamp, so the caller mustn't hold one
+	exp = exp.Clone()
+	now := m.clock.Now()
+	if exp.Timestamp == 0 {
+		exp.Timestamp = now.UnixNano()
+	}
+
+	m.mu.Lock()
+	m.episodes = insertEpisode(m.episodes, exp)
+	m.byAgent[exp.AgentID] = insertEpisode(m.byAgent[exp.AgentID], exp)
+	expired := m.expireLocked(now)
+	m.mu.Unlock()
+
+	m.consolidate(expired)
+	return nil
+}
+
+// Query returns copies of the episodes matching q, newest first, or most
+// similar first when q has an embedding.
+func (m *EpisodicMemory) Query(q EpisodeQuery) []EpisodeMatch {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	candidates := m.episodes
+	if q.AgentID != "" {
+		candidates = m.byAgent[q.AgentID]
+	}
+	from, to := 0, len(candidates)
+	if !q.Since.IsZero() {
+		since := q.Since.UnixNano()
+		from = sort.Search(len(candidates), func(i int) bool { return candidates[i].Timestamp >= since })
+	}
+	if !q.Until.IsZero() {
+		until := q.Until.UnixNano()
+		to = sort.Search(len(candidates), func(i int) bool { return candidates[i].Timestamp >= until })
+	}
+
+	var matches []EpisodeMatch
+	for i := to - 1; i >= from; i-- {
+		exp := candidates[i]
+		if (q.TaskSignature != "" && exp.TaskSignature != q.TaskSignature) ||
+			(q.TaskType != "" && exp.TaskType != q.TaskType) ||
+			(q.Success != nil && exp.Success != *q.Success) {
+			continue
+		}
+		match := EpisodeMatch{Experience: exp.Clone()}
+		if len(q.Embedding) > 0 {
+			if len(exp.Embedding) != len(q.Embedding) {
+				continue
+			}
+			if match.Similarity = cosineSimilarity32(q.Embedding, exp.Embedding); match.Similarity < q.MinSimilarity {
+				continue
+			}
+		} else if q.Limit > 0 && len(matches) == q.Limit {
+			break
+		}
+		matches = append(matches, match)
+	}
+
+	if len(q.Embedding) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+		if q.Limit > 0 && len(matches) > q.Limit {
+			matches = matches[:q.Limit]
+		}
+	}
+	return matches
+}
+
+// Len returns how many episodes are kept.
+func (m *EpisodicMemory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.episodes)
+}
+
+// Expire removes the episodes past retention, handing them to
+// consolidation, and returns how many there were.
+func (m *EpisodicMemory) Expire() int {
+	m.mu.Lock()
+	expired := m.expireLocked(m.clock.Now())
+	m.mu.Unlock()
+
+	m.consolidate(expired)
+	return len(expired)
+}
+
+// Run expires episodes every interval until ctx is done.
+func (m *EpisodicMemory) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Expire()
+		}
+	}
+}
+
+// expireLocked removes the episodes past retention or over capacity and
+// returns them, oldest first. Must be called with the lock held.
+func (m *EpisodicMemory) expireLocked(now time.Time) []*ExperienceTuple {
+	n := 0
+	if m.config.Retention > 0 {
+		cutoff := now.Add(-m.config.Retention).UnixNano()
+		n = sort.Search(len(m.episodes), func(i int) bool { return m.episodes[i].Timestamp >= cutoff })
+	}
+	if m.config.Capacity > 0 && len(m.episodes)-n > m.config.Capacity {
+		n = len(m.episodes) - m.config.Capacity
+	}
+	if n == 0 {
+		return nil
+	}
+
+	expired := m.episodes[:n:n]
+	m.episodes = append([]*ExperienceTuple(nil), m.episodes[n:]...)
+	dropped := make(map[string]int)
+	for _, exp := range expired {
+		dropped[exp.AgentID]++
+	}
+	for agent, count := range dropped {
+		if rest := m.byAgent[agent][count:]; len(rest) > 0 {
+			m.byAgent[agent] = append([]*ExperienceTuple(nil), rest...)
+		} else {
+			delete(m.byAgent, agent)
+		}
+	}
+	return expired
+}
+
+// consolidate hands expired episodes to the consolidation hook.
+func (m *EpisodicMemory) consolidate(expired []*ExperienceTuple) {
+	if len(expired) > 0 && m.onConsolidate != nil {
+		m.onConsolidate(expired)
+	}
+}
+
+// insertEpisode inserts an experience into episodes ordered by timestamp,
+// after any with the same timestamp.
+func insertEpisode(episodes []*ExperienceTuple, exp *ExperienceTuple) []*ExperienceTuple {
+	i := sort.Search(len(episodes), func(i int) bool { return episodes[i].Timestamp > exp.Timestamp })
+	episodes = append(episodes, nil)
+	copy(episodes[i+1:], episodes[i:])
+	episodes[i] = exp
+	return episodes
+}
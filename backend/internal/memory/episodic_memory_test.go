@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// episode creates an experience by agent on taskType that happened at,
+// or without a timestamp when at is zero.
+func episode(agent, taskType string, success bool, at time.Time, embedding ...float32) *ExperienceTuple {
+	exp := NewExperienceTuple(agent, 1, taskType+" task", "done", "direct")
+	exp.TaskType = taskType
+	exp.Success = success
+	exp.Timestamp = 0
+	if !at.IsZero() {
+		exp.Timestamp = at.UnixNano()
+	}
+	exp.Embedding = embedding
+	return exp
+}
+
+// episodeAgents returns the agents of matched episodes, in order.
+func episodeAgents(matches []EpisodeMatch) []string {
+	agents := make([]string, len(matches))
+	for i, match := range matches {
+		agents[i] = match.Experience.AgentID + "/" + match.Experience.TaskType
+	}
+	return agents
+}
+
+func expectEpisodes(t *testing.T, name string, matches []EpisodeMatch, want ...string) {
+	t.Helper()
+	got := episodeAgents(matches)
+	if len(got) != len(want) {
+		t.Errorf("%s: expected %v, got %v", name, want, got)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+			return
+		}
+	}
+}
+
+func TestEpisodicMemory_Query(t *testing.T) {
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start.Add(4 * time.Hour))
+	m := NewEpisodicMemory(EpisodicConfig{Clock: clock})
+
+	// Recorded out of order, as imports may be
+	for _, exp := range []*ExperienceTuple{
+		episode("APEX", "refactor", true, start.Add(2*time.Hour), 1, 0),
+		episode("CIPHER", "audit", false, start, 0, 1),
+		episode("APEX", "review", false, start.Add(time.Hour), 0.8, 0.6),
+		episode("CIPHER", "audit", true, start.Add(3*time.Hour)),
+	} {
+		if err := m.Record(exp); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if err := m.Record(&ExperienceTuple{}); !errors.Is(err, ErrInvalidExperience) {
+		t.Errorf("expected an experience without an agent rejected, got %v", err)
+	}
+
+	failed := false
+	expectEpisodes(t, "all", m.Query(EpisodeQuery{}), "CIPHER/audit", "APEX/refactor", "APEX/review", "CIPHER/audit")
+	expectEpisodes(t, "window", m.Query(EpisodeQuery{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}), "APEX/refactor", "APEX/review")
+	expectEpisodes(t, "agent", m.Query(EpisodeQuery{AgentID: "APEX", Limit: 1}), "APEX/refactor")
+	expectEpisodes(t, "task and outcome", m.Query(EpisodeQuery{TaskType: "audit", Success: &failed}), "CIPHER/audit")
+	expectEpisodes(t, "unknown agent", m.Query(EpisodeQuery{AgentID: "AXIOM"}))
+
+	similar := m.Query(EpisodeQuery{Embedding: []float32{1, 0}, MinSimilarity: 0.5})
+	expectEpisodes(t, "similarity", similar, "APEX/refactor", "APEX/review")
+	if similar[0].Similarity < 0.99 || similar[1].Similarity < 0.79 || similar[1].Similarity > 0.81 {
+		t.Errorf("expected similarities of 1 and 0.8, got %v and %v", similar[0].Similarity, similar[1].Similarity)
+	}
+	expectEpisodes(t, "similarity limit", m.Query(EpisodeQuery{Embedding: []float32{0, 1}, Limit: 1}), "CIPHER/audit")
+
+	now := m.Query(EpisodeQuery{})
+	exp := episode("APEX", "now", true, time.Time{})
+	if err := m.Record(exp); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if m.Len() != len(now)+1 {
+		t.Errorf("expected %d episodes, got %d", len(now)+1, m.Len())
+	}
+	newest := m.Query(EpisodeQuery{Limit: 1})
+	if len(newest) != 1 || newest[0].Experience.Timestamp != clock.Now().UnixNano() {
+		t.Errorf("expected an experience without a timestamp to happen now, got %v", episodeAgents(newest))
+	}
+
+	// The memory keeps copies, so neither the recorded experience nor
+	// one returned changes the episodes or their order
+	exp.Timestamp = start.UnixNano()
+	exp.TaskType = "changed"
+	newest[0].Experience.Timestamp = 0
+	newest[0].Experience.Embedding = append(newest[0].Experience.Embedding, 1)
+	expectEpisodes(t, "after changing copies", m.Query(EpisodeQuery{AgentID: "APEX"}), "APEX/now", "APEX/refactor", "APEX/review")
+	if episodes := m.Query(EpisodeQuery{Limit: 1}); episodes[0].Experience.Timestamp != clock.Now().UnixNano() || len(episodes[0].Experience.Embedding) != 0 {
+		t.Errorf("expected the episode unchanged, got %+v", episodes[0].Experience)
+	}
+}
+
+func TestEpisodicMemory_Retention(t *testing.T) {
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	m := NewEpisodicMemory(EpisodicConfig{Retention: time.Hour, Capacity: 3, Clock: clock})
+	var consolidated []string
+	m.OnConsolidate(func(episodes []*ExperienceTuple) {
+		// Called unlocked, so the memory may be queried
+		m.Len()
+		for _, exp := range episodes {
+			consolidated = append(consolidated, exp.AgentID+"/"+exp.TaskType)
+		}
+	})
+
+	m.Record(episode("APEX", "a", true, start))
+	m.Record(episode("CIPHER", "b", true, start.Add(10*time.Minute)))
+	m.Record(episode("APEX", "c", true, start.Add(20*time.Minute)))
+	m.Record(episode("APEX", "d", true, start.Add(30*time.Minute)))
+	if len(consolidated) != 1 || consolidated[0] != "APEX/a" || m.Len() != 3 {
+		t.Fatalf("expected capacity to push out the oldest episode, got %v with %d kept", consolidated, m.Len())
+	}
+	expectEpisodes(t, "agent after capacity", m.Query(EpisodeQuery{AgentID: "APEX"}), "APEX/d", "APEX/c")
+
+	// Episodes older than retention are consolidated as they arrive
+	m.Record(episode("AXIOM", "old", true, start.Add(-2*time.Hour)))
+	if len(consolidated) != 2 || consolidated[1] != "AXIOM/old" {
+		t.Errorf("expected an episode past retention consolidated at once, got %v", consolidated)
+	}
+
+	clock.Advance(85 * time.Minute)
+	if n := m.Expire(); n != 2 {
+		t.Errorf("expected 2 episodes expired, got %d", n)
+	}
+	expectEpisodes(t, "after retention", m.Query(EpisodeQuery{}), "APEX/d")
+	expectEpisodes(t, "expired agent", m.Query(EpisodeQuery{AgentID: "CIPHER"}))
+	if len(consolidated) != 4 || consolidated[2] != "CIPHER/b" || consolidated[3] != "APEX/c" {
+		t.Errorf("expected expired episodes consolidated oldest first, got %v", consolidated)
+	}
+
+	// Expired episodes can feed the consolidator's buffer
+	config := DefaultConsolidatorConfig()
+	config.EnableAutoConsolidation = false
+	consolidator := NewMemoryConsolidator(config)
+	defer consolidator.Stop()
+	m.OnConsolidate(func(episodes []*ExperienceTuple) {
+		for _, exp := range episodes {
+			consolidator.AddToBuffer(exp)
+		}
+	})
+	clock.Advance(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run(ctx, time.Millisecond)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for m.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if m.Len() != 0 || consolidator.GetBufferSize() != 1 {
+		t.Errorf("expected Run to expire the last episode into the consolidator, got %d kept and %d buffered", m.Len(), consolidator.GetBufferSize())
+	}
+}
@@ -3,33 +3,33 @@
 
 package memory
 
-import "errors"
+import "github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 
 var (
 	// ErrInvalidExperience is returned when an experience is invalid or missing required fields.
-	ErrInvalidExperience = errors.New("invalid experience: missing required fields")
+	ErrInvalidExperience = errdefs.New(errdefs.ErrInvalidArgument, "invalid experience: missing required fields")
 
 	// ErrExperienceNotFound is returned when an experience is not found in the store.
-	ErrExperienceNotFound = errors.New("experience not found")
+	ErrExperienceNotFound = errdefs.New(errdefs.ErrNotFound, "experience not found")
 
 	// ErrInvalidQuery is returned when a query context is invalid.
-	ErrInvalidQuery = errors.New("invalid query context")
+	ErrInvalidQuery = errdefs.New(errdefs.ErrInvalidArgument, "invalid query context")
 
 	// ErrInvalidEmbedding is returned when an embedding has incorrect dimensions.
-	ErrInvalidEmbedding = errors.New("invalid embedding dimensions")
+	ErrInvalidEmbedding = errdefs.New(errdefs.ErrInvalidArgument, "invalid embedding dimensions")
 
 	// ErrAgentNotFound is returned when an agent is not found.
-	ErrAgentNotFound = errors.New("agent not found")
+	ErrAgentNotFound = errdefs.New(errdefs.ErrNotFound, "agent not found")
 
 	// ErrMemoryFull is returned when the memory system has reached capacity.
-	ErrMemoryFull = errors.New("memory system at capacity")
+	ErrMemoryFull = errdefs.New(errdefs.ErrCapacityExceeded, "memory system at capacity")
 
 	// ErrEvolutionInProgress is returned when an evolution cycle is already running.
-	ErrEvolutionInProgress = errors.New("evolution cycle already in progress")
+	ErrEvolutionInProgress = errdefs.New(errdefs.ErrConflict, "evolution cycle already in progress")
 
 	// ErrPersistenceFailed is returned when memory persistence fails.
-	ErrPersistenceFailed = errors.New("failed to persist memory")
+	ErrPersistenceFailed = errdefs.New(errdefs.ErrInternal, "failed to persist memory")
 
 	// ErrLoadFailed is returned when memory loading fails.
-	ErrLoadFailed = errors.New("failed to load memory")
+	ErrLoadFailed = errdefs.New(errdefs.ErrInternal, "failed to load memory")
 )
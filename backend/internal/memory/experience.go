@@ -67,6 +67,9 @@ type ExperienceTuple struct {
 
 	// LastAccessTime is when this experience was last retrieved
 	LastAccessTime int64 `json:"last_access_time"`
+
+	// Weight counts the near-duplicate experiences merged into this one
+	Weight int64 `json:"weight"`
 }
 
 // NewExperienceTuple creates a new experience tuple with default values.
@@ -88,7 +91,22 @@ func NewExperienceTuple(agentID string, tierID int, input, output, strategy stri
 		FitnessScore:   0.5, // Neutral starting fitness
 		UsageCount:     0,
 		LastAccessTime: now,
+		Weight:         1,
+	}
+}
+
+// Clone returns a copy of the experience sharing no embedding or metadata
+// with it.
+func (e *ExperienceTuple) Clone() *ExperienceTuple {
+	clone := *e
+	clone.Embedding = append([]float32(nil), e.Embedding...)
+	if e.Metadata != nil {
+		clone.Metadata = make(map[string]interface{}, len(e.Metadata))
+		for k, v := range e.Metadata {
+			clone.Metadata[k] = v
+		}
 	}
+	return &clone
 }
 
 // generateExperienceID creates a unique ID for an experience.
@@ -141,6 +159,9 @@ type QueryContext struct {
 	// TierID is the tier of the querying agent
 	TierID int `json:"tier_id"`
 
+	// Input is the task text, used by keyword and semantic retrieval
+	Input string `json:"input"`
+
 	// TaskSignature is the hash of the current task for exact matching
 	TaskSignature string `json:"task_signature"`
 
@@ -171,6 +192,7 @@ func NewQueryContext(agentID string, tierID int, input string) *QueryContext {
 	return &QueryContext{
 		AgentID:                      agentID,
 		TierID:                       tierID,
+		Input:                        input,
 		TaskSignature:                computeTaskSignature(input),
 		Embedding:                    nil, // To be computed by embedding service
 		TopK:                         10,
@@ -187,7 +209,7 @@ type RetrievalResult struct {
 	Experiences []*ExperienceTuple `json:"experiences"`
 
 	// RetrievalMethod indicates how the experiences were found
-	// Possible values: "exact", "lsh", "hnsw", "fallback"
+	// Possible values: "exact", "lsh", "hnsw", "keyword", "semantic", "fallback"
 	RetrievalMethod string `json:"retrieval_method"`
 
 	// RetrievalLatencyNs is the time taken for retrieval in nanoseconds
@@ -288,6 +310,9 @@ type MemoryStats struct {
 	// ExperiencesByTier maps tier ID to experience count
 	ExperiencesByTier map[int]int64 `json:"experiences_by_tier"`
 
+	// MergedExperiences counts near-duplicates merged instead of stored
+	MergedExperiences int64 `json:"merged_experiences"`
+
 	// TotalRetrievals counts all retrieval operations
 	TotalRetrievals int64 `json:"total_retrievals"`
 
@@ -324,6 +349,13 @@ func (s *MemoryStats) IncrementExperiences(agentID string, tierID int) {
 	s.ExperiencesByTier[tierID]++
 }
 
+// IncrementMerged safely counts a merged near-duplicate experience.
+func (s *MemoryStats) IncrementMerged() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MergedExperiences++
+}
+
 // UpdateRetrievalStats safely updates retrieval statistics.
 func (s *MemoryStats) UpdateRetrievalStats(latencyNs int64, cacheHit bool) {
 	s.mu.Lock()
@@ -369,6 +401,7 @@ func (s *MemoryStats) GetStats() *MemoryStats {
 		TotalExperiences:      s.TotalExperiences,
 		ExperiencesByAgent:    agentCopy,
 		ExperiencesByTier:     tierCopy,
+		MergedExperiences:     s.MergedExperiences,
 		TotalRetrievals:       s.TotalRetrievals,
 		AvgRetrievalLatencyNs: s.AvgRetrievalLatencyNs,
 		CacheHitRate:          s.CacheHitRate,
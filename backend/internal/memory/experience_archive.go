@@ -0,0 +1,556 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the cold-storage tier for old experiences.
+//
+// An archiver moves experiences older than the archival policy's age out
+// of the retriever into compressed segments in a cold store, keeping the
+// hot indexes small while preserving history. Each segment is a gzipped
+// JSON-lines file of experiences with an index beside it recording the
+// segment's experience IDs, agents and time range; the index is written
+// last, so a segment without one was never committed and is ignored.
+// Archived experiences are rehydrated on demand by ID, agent or time
+// range, and stay hot for a while before they can be archived again.
+
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrColdObjectNotFound is returned when a cold store has no object at a key.
+	ErrColdObjectNotFound = errdefs.New(errdefs.ErrNotFound, "cold storage object not found")
+
+	// ErrRehydrateAll is returned when a rehydration filter selects everything.
+	ErrRehydrateAll = errdefs.New(errdefs.ErrInvalidArgument, "rehydration needs IDs, an agent or a time range")
+)
+
+const (
+	// archiveSegmentPrefix is the key prefix segments are stored under
+	archiveSegmentPrefix = "experiences/"
+	// archiveIndexSuffix and archiveDataSuffix end segment index and data keys
+	archiveIndexSuffix = ".index.json"
+	archiveDataSuffix  = ".jsonl.gz"
+)
+
+// ColdStore holds archived objects by key. DirColdStore keeps them in a
+// directory and S3ColdStore in an S3-compatible bucket.
+type ColdStore interface {
+	// Put stores data at key, replacing any object there
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object at key, or ErrColdObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// DirColdStore is a ColdStore keeping objects as files under a directory.
+type DirColdStore struct {
+	dir string
+}
+
+// NewDirColdStore creates a store under dir, creating it if needed.
+func NewDirColdStore(dir string) (*DirColdStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cold storage directory: %w", err)
+	}
+	return &DirColdStore{dir: dir}, nil
+}
+
+// Put writes data to key's file atomically.
+func (s *DirColdStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get reads key's file.
+func (s *DirColdStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrColdObjectNotFound, key)
+	}
+	return data, err
+}
+
+// List walks the directory for the keys starting with prefix.
+func (s *DirColdStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) && !strings.Contains(filepath.Base(key), ".tmp-") {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cold storage: %w", err)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// ArchivePolicy decides which experiences are archived.
+type ArchivePolicy struct {
+	// MaxAge is how old an experience is when it is archived
+	MaxAge time.Duration
+	// SegmentSize bounds the experiences written to one segment
+	SegmentSize int
+	// RehydratedTTL is how long a rehydrated experience stays hot before
+	// it can be archived again
+	RehydratedTTL time.Duration
+	// Clock supplies the time ages are measured against (default: SystemClock)
+	Clock Clock
+}
+
+// DefaultArchivePolicy archives experiences after 30 days.
+func DefaultArchivePolicy() ArchivePolicy {
+	return ArchivePolicy{
+		MaxAge:        30 * 24 * time.Hour,
+		SegmentSize:   10000,
+		RehydratedTTL: 24 * time.Hour,
+	}
+}
+
+// ArchiveSegment is the index of one archived segment.
+type ArchiveSegment struct {
+	ID string `json:"id"`
+	// Key is where the segment's experiences are stored
+	Key       string    `json:"key"`
+	Count     int       `json:"count"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Agents    []string  `json:"agents"`
+	IDs       []string  `json:"ids"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArchiveResult reports an archival run.
+type ArchiveResult struct {
+	// Archived counts the experiences written to new segments
+	Archived int `json:"archived"`
+	// Evicted counts the experiences removed from the hot indexes,
+	// including rehydrated ones already in a segment
+	Evicted  int      `json:"evicted"`
+	Segments []string `json:"segments"`
+}
+
+// RehydrateFilter selects archived experiences to rehydrate. Set fields
+// must all match; at least one must be set.
+type RehydrateFilter struct {
+	IDs     []string
+	AgentID string
+	// From and To bound the experiences' timestamps; zero is unbounded
+	From time.Time
+	To   time.Time
+}
+
+// empty reports whether the filter selects everything.
+func (f RehydrateFilter) empty() bool {
+	return len(f.IDs) == 0 && f.AgentID == "" && f.From.IsZero() && f.To.IsZero()
+}
+
+// archivedExperience is one line of a segment. Metadata is wrapped as in
+// the write-ahead log, so its values keep their types.
+type archivedExperience struct {
+	Experience *ExperienceTuple    `json:"experience"`
+	Metadata   map[string]walValue `json:"metadata,omitempty"`
+}
+
+// ExperienceArchiver moves old experiences between a retriever and a cold
+// store. It is safe for concurrent use.
+type ExperienceArchiver struct {
+	retriever *SubLinearRetriever
+	store     ColdStore
+	policy    ArchivePolicy
+	clock     Clock
+
+	mu       sync.Mutex
+	segments []*ArchiveSegment
+	// archived maps archived experience IDs to their segment
+	archived map[string]*ArchiveSegment
+	// rehydrated records when experiences were rehydrated
+	rehydrated map[string]time.Time
+}
+
+// OpenExperienceArchiver creates an archiver and loads the indexes of the
+// segments already in the store.
+func OpenExperienceArchiver(ctx context.Context, retriever *SubLinearRetriever, store ColdStore, policy ArchivePolicy) (*ExperienceArchiver, error) {
+	defaults := DefaultArchivePolicy()
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = defaults.MaxAge
+	}
+	if policy.SegmentSize <= 0 {
+		policy.SegmentSize = defaults.SegmentSize
+	}
+	a := &ExperienceArchiver{
+		retriever:  retriever,
+		store:      store,
+		policy:     policy,
+		clock:      clockOrSystem(policy.Clock),
+		archived:   make(map[string]*ArchiveSegment),
+		rehydrated: make(map[string]time.Time),
+	}
+
+	keys, err := store.List(ctx, archiveSegmentPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, archiveIndexSuffix) {
+			continue
+		}
+		data, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var segment ArchiveSegment
+		if err := json.Unmarshal(data, &segment); err != nil || segment.Key == "" {
+			return nil, fmt.Errorf("%w: corrupt archive index %s", ErrLoadFailed, key)
+		}
+		a.addSegmentLocked(&segment)
+	}
+	return a, nil
+}
+
+// Segments returns the archived segments, oldest first.
+func (a *ExperienceArchiver) Segments() []ArchiveSegment {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	segments := make([]ArchiveSegment, 0, len(a.segments))
+	for _, segment := range a.segments {
+		segments = append(segments, *segment)
+	}
+	return segments
+}
+
+// Archive moves the experiences older than the policy's age into new
+// segments and removes them from the retriever. Experiences rehydrated
+// from a segment are only removed once their TTL passes.
+func (a *ExperienceArchiver) Archive(ctx context.Context) (*ArchiveResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	result := &ArchiveResult{Segments: []string{}}
+	var pending []*ExperienceTuple
+	for _, exp := range a.retriever.experiencesBefore(now.Add(-a.policy.MaxAge)) {
+		if at, ok := a.rehydrated[exp.ID]; ok {
+			if now.Sub(at) < a.policy.RehydratedTTL {
+				continue
+			}
+			delete(a.rehydrated, exp.ID)
+		}
+		if _, ok := a.archived[exp.ID]; ok {
+			if err := a.evict(exp.ID); err != nil {
+				return result, err
+			}
+			result.Evicted++
+			continue
+		}
+		pending = append(pending, exp)
+	}
+
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := pending
+		if len(batch) > a.policy.SegmentSize {
+			batch = batch[:a.policy.SegmentSize]
+		}
+		pending = pending[len(batch):]
+
+		segment, err := a.writeSegment(ctx, batch, now)
+		if err != nil {
+			return result, err
+		}
+		a.addSegmentLocked(segment)
+		result.Segments = append(result.Segments, segment.ID)
+		result.Archived += len(batch)
+		for _, exp := range batch {
+			if err := a.evict(exp.ID); err != nil {
+				return result, err
+			}
+			result.Evicted++
+		}
+	}
+	return result, nil
+}
+
+// Rehydrate adds the archived experiences matching filter back to the
+// retriever and returns how many were added. Experiences still hot are
+// left as they are.
+func (a *ExperienceArchiver) Rehydrate(ctx context.Context, filter RehydrateFilter) (int, error) {
+	if filter.empty() {
+		return 0, ErrRehydrateAll
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ids := make(map[string]bool, len(filter.IDs))
+	for _, id := range filter.IDs {
+		ids[id] = true
+	}
+	now := a.clock.Now()
+	added := 0
+	for _, segment := range a.segments {
+		if !segment.overlaps(filter, ids) {
+			continue
+		}
+		experiences, err := a.readSegment(ctx, segment)
+		if err != nil {
+			return added, err
+		}
+		for _, exp := range experiences {
+			if !filter.matches(exp, ids) || a.retriever.has(exp.ID) {
+				continue
+			}
+			if err := a.retriever.Add(exp); err != nil {
+				return added, err
+			}
+			a.rehydrated[exp.ID] = now
+			added++
+		}
+	}
+	return added, nil
+}
+
+// Run archives every interval until ctx is done. Failed runs are retried
+// at the next interval.
+func (a *ExperienceArchiver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Archive(ctx)
+		}
+	}
+}
+
+// evict removes an experience from the retriever; one already removed is
+// not an error.
+func (a *ExperienceArchiver) evict(id string) error {
+	if err := a.retriever.Remove(id); err != nil && !errors.Is(err, ErrExperienceNotFound) {
+		return fmt.Errorf("failed to evict archived experience %s: %w", id, err)
+	}
+	return nil
+}
+
+// writeSegment stores a batch of experiences as a segment, then its index.
+func (a *ExperienceArchiver) writeSegment(ctx context.Context, batch []*ExperienceTuple, now time.Time) (*ArchiveSegment, error) {
+	id := NewID("segment")
+	segment := &ArchiveSegment{
+		ID:        id,
+		Key:       archiveSegmentPrefix + id + archiveDataSuffix,
+		Count:     len(batch),
+		Agents:    []string{},
+		IDs:       make([]string, 0, len(batch)),
+		CreatedAt: now,
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for i, exp := range batch {
+		metadata, err := encodeWALValues(exp.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("%w: experience %s: %v", ErrPersistenceFailed, exp.ID, err)
+		}
+		expCopy := *exp
+		expCopy.Metadata = nil
+		if err := encoder.Encode(archivedExperience{Experience: &expCopy, Metadata: metadata}); err != nil {
+			return nil, fmt.Errorf("%w: experience %s: %v", ErrPersistenceFailed, exp.ID, err)
+		}
+
+		at := time.Unix(0, exp.Timestamp).UTC()
+		if i == 0 || at.Before(segment.From) {
+			segment.From = at
+		}
+		if at.After(segment.To) {
+			segment.To = at
+		}
+		segment.Agents = appendUnique(segment.Agents, exp.AgentID)
+		segment.IDs = append(segment.IDs, exp.ID)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	sort.Strings(segment.Agents)
+
+	if err := a.store.Put(ctx, segment.Key, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	index, err := json.Marshal(segment)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if err := a.store.Put(ctx, archiveSegmentPrefix+id+archiveIndexSuffix, index); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// readSegment loads a segment's experiences.
+func (a *ExperienceArchiver) readSegment(ctx context.Context, segment *ArchiveSegment) ([]*ExperienceTuple, error) {
+	data, err := a.store.Get(ctx, segment.Key)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: segment %s: %v", ErrLoadFailed, segment.ID, err)
+	}
+	defer zr.Close()
+
+	experiences := make([]*ExperienceTuple, 0, segment.Count)
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var line archivedExperience
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Experience == nil {
+			return nil, fmt.Errorf("%w: segment %s has a corrupt experience", ErrLoadFailed, segment.ID)
+		}
+		metadata, err := decodeWALValues(line.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		line.Experience.Metadata = metadata
+		experiences = append(experiences, line.Experience)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: segment %s: %v", ErrLoadFailed, segment.ID, err)
+	}
+	return experiences, nil
+}
+
+// addSegmentLocked indexes a segment. Must hold lock.
+func (a *ExperienceArchiver) addSegmentLocked(segment *ArchiveSegment) {
+	a.segments = append(a.segments, segment)
+	sort.SliceStable(a.segments, func(i, j int) bool {
+		return a.segments[i].From.Before(a.segments[j].From)
+	})
+	for _, id := range segment.IDs {
+		a.archived[id] = segment
+	}
+}
+
+// overlaps reports whether a segment may hold experiences matching filter.
+func (s *ArchiveSegment) overlaps(filter RehydrateFilter, ids map[string]bool) bool {
+	if !filter.From.IsZero() && s.To.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && s.From.After(filter.To) {
+		return false
+	}
+	if filter.AgentID != "" {
+		i := sort.SearchStrings(s.Agents, filter.AgentID)
+		if i == len(s.Agents) || s.Agents[i] != filter.AgentID {
+			return false
+		}
+	}
+	if len(ids) > 0 {
+		for _, id := range s.IDs {
+			if ids[id] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matches reports whether an experience matches the filter.
+func (f RehydrateFilter) matches(exp *ExperienceTuple, ids map[string]bool) bool {
+	if len(ids) > 0 && !ids[exp.ID] {
+		return false
+	}
+	if f.AgentID != "" && exp.AgentID != f.AgentID {
+		return false
+	}
+	at := time.Unix(0, exp.Timestamp)
+	if !f.From.IsZero() && at.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && at.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// experiencesBefore returns copies of the experiences created before
+// cutoff, oldest first.
+func (r *SubLinearRetriever) experiencesBefore(cutoff time.Time) []*ExperienceTuple {
+	r.expMu.RLock()
+	defer r.expMu.RUnlock()
+
+	limit := cutoff.UnixNano()
+	var old []*ExperienceTuple
+	for _, exp := range r.experiences {
+		if exp.Timestamp < limit {
+			expCopy := *exp
+			old = append(old, &expCopy)
+		}
+	}
+	sort.Slice(old, func(i, j int) bool {
+		if old[i].Timestamp != old[j].Timestamp {
+			return old[i].Timestamp < old[j].Timestamp
+		}
+		return old[i].ID < old[j].ID
+	})
+	return old
+}
+
+// has reports whether an experience is stored.
+func (r *SubLinearRetriever) has(id string) bool {
+	r.expMu.RLock()
+	defer r.expMu.RUnlock()
+	_, ok := r.experiences[id]
+	return ok
+}
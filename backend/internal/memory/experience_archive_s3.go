@@ -0,0 +1,281 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements an object-store cold storage tier.
+//
+// S3ColdStore keeps archive segments in an S3 bucket, or in any service
+// speaking the S3 API such as MinIO. Requests use path-style URLs, so the
+// endpoint may be any S3-compatible service, and are signed with AWS
+// Signature Version 4. Listing pages through ListObjectsV2.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrObjectStoreAPI is returned when an object store responds unexpectedly
+	ErrObjectStoreAPI = errdefs.New(errdefs.ErrProviderFailure, "object store API error")
+	// ErrInvalidObjectStore is returned when an object store is misconfigured
+	ErrInvalidObjectStore = errdefs.New(errdefs.ErrInvalidArgument, "invalid object store configuration")
+)
+
+// S3Config locates a bucket and the credentials to use it.
+type S3Config struct {
+	// Endpoint is the service URL, e.g. https://s3.eu-west-1.amazonaws.com
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to every key, so stores can share a bucket
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials
+	SessionToken string
+}
+
+// S3ColdStore is a ColdStore keeping objects in an S3-compatible bucket.
+type S3ColdStore struct {
+	config S3Config
+	client *http.Client
+
+	// now reads the clock; replaceable for tests
+	now func() time.Time
+}
+
+// NewS3ColdStore creates a store for a bucket. A nil client uses one with
+// a one-minute timeout.
+func NewS3ColdStore(config S3Config, client *http.Client) (*S3ColdStore, error) {
+	if config.Endpoint == "" || config.Region == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("%w: endpoint, region and bucket are required", ErrInvalidObjectStore)
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: access key ID and secret access key are required", ErrInvalidObjectStore)
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidObjectStore, err)
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	return &S3ColdStore{config: config, client: client, now: time.Now}, nil
+}
+
+// Put uploads data to key.
+func (s *S3ColdStore) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.objectPath(key), nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return objectStoreError(resp)
+	}
+	return nil
+}
+
+// Get downloads the object at key.
+func (s *S3ColdStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectPath(key), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading %s: %v", ErrObjectStoreAPI, key, err)
+		}
+		return data, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrColdObjectNotFound, key)
+	default:
+		return nil, objectStoreError(resp)
+	}
+}
+
+// s3ListResult is the subset of a ListObjectsV2 response used here.
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through the keys starting with prefix.
+func (s *S3ColdStore) List(ctx context.Context, prefix string) ([]string, error) {
+	keys := make([]string, 0)
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		page, err := s.listPage(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(object.Key, s.config.Prefix))
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// listPage requests one page of a listing.
+func (s *S3ColdStore) listPage(ctx context.Context, query url.Values) (*s3ListResult, error) {
+	resp, err := s.do(ctx, http.MethodGet, "/"+s3Escape(s.config.Bucket, false), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, objectStoreError(resp)
+	}
+	var page s3ListResult
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("%w: decoding listing: %v", ErrObjectStoreAPI, err)
+	}
+	return &page, nil
+}
+
+// objectPath is the escaped path-style path of key.
+func (s *S3ColdStore) objectPath(key string) string {
+	return "/" + s3Escape(s.config.Bucket, false) + "/" + s3Escape(s.config.Prefix+key, true)
+}
+
+// do sends a signed request for an escaped path and query.
+func (s *S3ColdStore) do(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrObjectStoreAPI, err)
+	}
+	req.URL.RawPath = strings.TrimSuffix(req.URL.EscapedPath(), "/") + path
+	req.URL.Path, _ = url.PathUnescape(req.URL.RawPath)
+	req.URL.RawQuery = s3CanonicalQuery(query)
+	req.ContentLength = int64(len(body))
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrObjectStoreAPI, err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers for body to req.
+func (s *S3ColdStore) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+		signed = append(signed, "x-amz-security-token")
+	}
+
+	var headers strings.Builder
+	for _, name := range signed {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	signedHeaders := strings.Join(signed, ";")
+	canonical := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, headers.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	key := []byte("AWS4" + s.config.SecretAccessKey)
+	for _, part := range []string{day, s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery encodes query sorted by key, as signing requires.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes all but unreserved characters, and slashes
+// too unless keepSlash is set.
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && keepSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// objectStoreError describes an unexpected object store response.
+func objectStoreError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%w: %s: %s", ErrObjectStoreAPI, resp.Status, bytes.TrimSpace(msg))
+}
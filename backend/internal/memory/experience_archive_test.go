@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// archivedFixture adds experiences to a retriever, one per day for the
+// given days before now, alternating between APEX and CIPHER.
+func archivedFixture(t *testing.T, now time.Time, days int) (*SubLinearRetriever, []*ExperienceTuple) {
+	t.Helper()
+	retriever := NewSubLinearRetriever(4)
+	var experiences []*ExperienceTuple
+	for i := 0; i < days; i++ {
+		agent := "APEX"
+		if i%2 == 1 {
+			agent = "CIPHER"
+		}
+		exp := NewExperienceTuple(agent, 1, "task "+strings.Repeat("x", i), "output", "direct")
+		exp.Timestamp = now.AddDate(0, 0, -i).UnixNano()
+		exp.Embedding = []float32{1, float32(i), 0, 0}
+		exp.Metadata = map[string]interface{}{"attempts": int64(i), "language": "go"}
+		if err := retriever.Add(exp); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		experiences = append(experiences, exp)
+	}
+	return retriever, experiences
+}
+
+func TestExperienceArchiver_ArchiveAndRehydrate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewManualClock(now)
+	retriever, experiences := archivedFixture(t, now, 10)
+	store, err := NewDirColdStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirColdStore failed: %v", err)
+	}
+	policy := ArchivePolicy{MaxAge: 84 * time.Hour, SegmentSize: 4, RehydratedTTL: time.Hour, Clock: clock}
+	archiver, err := OpenExperienceArchiver(ctx, retriever, store, policy)
+	if err != nil {
+		t.Fatalf("OpenExperienceArchiver failed: %v", err)
+	}
+
+	// Experiences 4 to 9 days old are archived, four to a segment
+	result, err := archiver.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if result.Archived != 6 || result.Evicted != 6 || len(result.Segments) != 2 {
+		t.Errorf("Expected 6 experiences archived in 2 segments, got %+v", result)
+	}
+	if retriever.Size() != 4 {
+		t.Errorf("Expected 4 hot experiences, got %d", retriever.Size())
+	}
+	segments := archiver.Segments()
+	if len(segments) != 2 || segments[0].Count != 4 || segments[1].Count != 2 {
+		t.Fatalf("Expected segments of 4 and 2 oldest first, got %+v", segments)
+	}
+
+	// A reopened archiver finds the segments, and rehydrates by agent and range
+	reopened, err := OpenExperienceArchiver(ctx, retriever, store, policy)
+	if err != nil {
+		t.Fatalf("Reopening failed: %v", err)
+	}
+	added, err := reopened.Rehydrate(ctx, RehydrateFilter{AgentID: "CIPHER", From: now.AddDate(0, 0, -7).Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Rehydrate failed: %v", err)
+	}
+	if added != 2 {
+		t.Errorf("Expected CIPHER's experiences 5 and 7 days old rehydrated, got %d", added)
+	}
+	restored := retriever.GetByAgent("CIPHER")
+	var found *ExperienceTuple
+	for _, exp := range restored {
+		if exp.ID == experiences[5].ID {
+			found = exp
+		}
+	}
+	if found == nil {
+		t.Fatal("Expected the 5 day old experience hot again")
+	}
+	if found.Metadata["attempts"] != int64(5) || found.Metadata["language"] != "go" || len(found.Embedding) != 4 {
+		t.Errorf("Expected the experience restored with typed metadata, got %+v", found)
+	}
+
+	// Rehydrated experiences stay hot until their TTL passes, and are not
+	// written to another segment when they go
+	if result, _ := reopened.Archive(ctx); result.Evicted != 0 {
+		t.Errorf("Expected rehydrated experiences kept within their TTL, got %+v", result)
+	}
+	clock.Advance(2 * time.Hour)
+	result, err = reopened.Archive(ctx)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if result.Evicted != 2 || result.Archived != 0 || len(reopened.Segments()) != 2 {
+		t.Errorf("Expected the rehydrated experiences evicted without a new segment, got %+v", result)
+	}
+
+	added, err = reopened.Rehydrate(ctx, RehydrateFilter{IDs: []string{experiences[9].ID, "missing"}})
+	if err != nil || added != 1 {
+		t.Errorf("Expected one experience rehydrated by ID, got %d, %v", added, err)
+	}
+	if _, err := reopened.Rehydrate(ctx, RehydrateFilter{}); !errors.Is(err, ErrRehydrateAll) {
+		t.Errorf("Expected ErrRehydrateAll for an empty filter, got %v", err)
+	}
+}
+
+func TestDirColdStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDirColdStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirColdStore failed: %v", err)
+	}
+	store.Put(ctx, "experiences/b.json", []byte("b"))
+	store.Put(ctx, "experiences/a.json", []byte("a"))
+	store.Put(ctx, "other/c.json", []byte("c"))
+
+	keys, err := store.List(ctx, "experiences/")
+	if err != nil || strings.Join(keys, ",") != "experiences/a.json,experiences/b.json" {
+		t.Errorf("Expected the prefixed keys sorted, got %v, %v", keys, err)
+	}
+	if data, err := store.Get(ctx, "experiences/a.json"); err != nil || string(data) != "a" {
+		t.Errorf("Expected the stored object, got %q, %v", data, err)
+	}
+	if _, err := store.Get(ctx, "experiences/missing.json"); !errors.Is(err, ErrColdObjectNotFound) {
+		t.Errorf("Expected ErrColdObjectNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements near-duplicate detection for experiences.
+//
+// Each experience's content (input, strategy, and output) is reduced to a
+// MinHash signature over word shingles and indexed with banded LSH. A new
+// experience whose estimated Jaccard similarity to a stored experience of
+// the same agent and outcome reaches the threshold is merged into it,
+// incrementing the stored experience's weight, so repeated tasks do not
+// crowd out the rest of an agent's memory.
+
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DedupConfig holds configuration for experience deduplication.
+type DedupConfig struct {
+	// Threshold is the similarity at which experiences are merged; zero
+	// disables deduplication
+	Threshold float64
+	// NumHashes is the MinHash signature length
+	NumHashes int
+}
+
+// DefaultDedupConfig returns the default deduplication configuration.
+func DefaultDedupConfig() DedupConfig {
+	return DedupConfig{
+		Threshold: 0.85,
+		NumHashes: 128,
+	}
+}
+
+// DedupIndex finds stored experiences that nearly duplicate a new one.
+type DedupIndex struct {
+	config     DedupConfig
+	minHash    *MinHash
+	lsh        *MinHashLSH
+	signatures map[string]MinHashSignature
+	mu         sync.RWMutex
+}
+
+// NewDedupIndex creates an empty deduplication index.
+func NewDedupIndex(config DedupConfig) *DedupIndex {
+	if config.NumHashes <= 0 {
+		config.NumHashes = DefaultDedupConfig().NumHashes
+	}
+	return &DedupIndex{
+		config:     config,
+		minHash:    NewMinHash(config.NumHashes),
+		lsh:        NewMinHashLSH(config.Threshold, config.NumHashes),
+		signatures: make(map[string]MinHashSignature),
+	}
+}
+
+// experienceShingles returns the words of an experience's content and
+// each pair of adjacent words, so word order counts toward similarity.
+func experienceShingles(exp *ExperienceTuple) []string {
+	shingles := make([]string, 0)
+	for _, text := range []string{exp.Input, exp.Strategy, exp.Output} {
+		words := tokenize(text)
+		for i, word := range words {
+			shingles = append(shingles, word)
+			if i > 0 {
+				shingles = append(shingles, words[i-1]+" "+word)
+			}
+		}
+	}
+	return shingles
+}
+
+// Signature computes the MinHash signature of an experience's content.
+func (d *DedupIndex) Signature(exp *ExperienceTuple) MinHashSignature {
+	return d.minHash.ComputeSignature(experienceShingles(exp))
+}
+
+// Add indexes an experience's signature.
+func (d *DedupIndex) Add(exp *ExperienceTuple) {
+	sig := d.Signature(exp)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if old, exists := d.signatures[exp.ID]; exists {
+		d.lsh.Remove(exp.ID, old)
+	}
+	d.signatures[exp.ID] = sig
+	d.lsh.Add(exp.ID, sig)
+}
+
+// Remove drops an experience from the index.
+func (d *DedupIndex) Remove(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if sig, exists := d.signatures[id]; exists {
+		d.lsh.Remove(id, sig)
+		delete(d.signatures, id)
+	}
+}
+
+// Candidates returns the indexed IDs whose similarity to exp reaches the
+// threshold, most similar first, with their estimated similarities.
+func (d *DedupIndex) Candidates(exp *ExperienceTuple) ([]string, []float64) {
+	sig := d.Signature(exp)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	type match struct {
+		id         string
+		similarity float64
+	}
+	matches := make([]match, 0)
+	for _, id := range d.lsh.Query(sig) {
+		if id == exp.ID {
+			continue
+		}
+		if similarity := d.minHash.EstimateSimilarity(sig, d.signatures[id]); similarity >= d.config.Threshold {
+			matches = append(matches, match{id: id, similarity: similarity})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].similarity != matches[j].similarity {
+			return matches[i].similarity > matches[j].similarity
+		}
+		return matches[i].id < matches[j].id
+	})
+
+	ids := make([]string, len(matches))
+	similarities := make([]float64, len(matches))
+	for i, m := range matches {
+		ids[i], similarities[i] = m.id, m.similarity
+	}
+	return ids, similarities
+}
+
+// Size returns the number of indexed experiences.
+func (d *DedupIndex) Size() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.signatures)
+}
+
+// experienceWeight returns exp's weight, counting unweighted experiences
+// (e.g. from logs written before weights existed) once.
+func experienceWeight(exp *ExperienceTuple) int64 {
+	if exp.Weight <= 0 {
+		return 1
+	}
+	return exp.Weight
+}
+
+// mergeExperience folds dup into exp: weights add, fitness becomes the
+// weighted mean, and exp counts as accessed now.
+func mergeExperience(exp, dup *ExperienceTuple) {
+	w, dw := experienceWeight(exp), experienceWeight(dup)
+	exp.FitnessScore = (exp.FitnessScore*float64(w) + dup.FitnessScore*float64(dw)) / float64(w+dw)
+	exp.Weight = w + dw
+	exp.LastAccessTime = time.Now().UnixNano()
+}
+
+// ============================================================================
+// Retriever Integration
+// ============================================================================
+
+// EnableDedup makes AddOrMerge merge near-duplicate experiences. Enable
+// before the retriever is shared between goroutines; experiences already
+// stored are indexed.
+func (r *SubLinearRetriever) EnableDedup(config DedupConfig) {
+	dedup := NewDedupIndex(config)
+	r.expMu.RLock()
+	for _, exp := range r.experiences {
+		dedup.Add(exp)
+	}
+	r.expMu.RUnlock()
+	r.dedup = dedup
+}
+
+// AddOrMerge stores exp, or, if deduplication is enabled and a stored
+// experience of the same agent and outcome nearly duplicates it, merges
+// exp into that experience instead. It returns the stored experience and
+// whether a merge happened.
+func (r *SubLinearRetriever) AddOrMerge(exp *ExperienceTuple) (*ExperienceTuple, bool, error) {
+	if r.dedup == nil || exp == nil {
+		return exp, false, r.Add(exp)
+	}
+	// Embedded before the lock, so one slow embedding holds up no one else
+	r.embedExperience(exp)
+
+	r.dedupMu.Lock()
+	defer r.dedupMu.Unlock()
+
+	ids, _ := r.dedup.Candidates(exp)
+	r.expMu.Lock()
+	for _, id := range ids {
+		stored, exists := r.experiences[id]
+		if !exists || stored.AgentID != exp.AgentID || stored.Success != exp.Success {
+			continue
+		}
+		merged := *stored
+		mergeExperience(&merged, exp)
+		if err := r.logExperience(&merged); err != nil {
+			r.expMu.Unlock()
+			return nil, false, err
+		}
+		stored.FitnessScore, stored.Weight, stored.LastAccessTime = merged.FitnessScore, merged.Weight, merged.LastAccessTime
+		r.expMu.Unlock()
+		r.stats.IncrementMerged()
+		return stored, true, nil
+	}
+	r.expMu.Unlock()
+
+	return exp, false, r.Add(exp)
+}
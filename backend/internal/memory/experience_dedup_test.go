@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"testing"
+)
+
+// ============================================================================
+// Dedup Index Tests
+// ============================================================================
+
+func TestDedupIndex_Candidates(t *testing.T) {
+	d := NewDedupIndex(DefaultDedupConfig())
+	original := NewExperienceTuple("APEX", 1, "sort a large list of customer records by signup date", "use a stable merge sort on the date key", "direct")
+	other := NewExperienceTuple("APEX", 1, "deploy the billing service to the staging cluster", "apply the helm chart", "direct")
+	d.Add(original)
+	d.Add(other)
+
+	repeat := NewExperienceTuple("APEX", 1, "sort a large list of customer records by signup date", "use a stable merge sort on the date key", "direct")
+	ids, similarities := d.Candidates(repeat)
+	if len(ids) != 1 || ids[0] != original.ID {
+		t.Fatalf("Expected only the original as candidate, got %v", ids)
+	}
+	if similarities[0] != 1 {
+		t.Errorf("Expected identical content to have similarity 1, got %f", similarities[0])
+	}
+
+	// Reordered words share terms but not shingles
+	reordered := NewExperienceTuple("APEX", 1, "date signup by records customer of list large a sort", "key date the on sort merge stable a use", "direct")
+	if ids, _ := d.Candidates(reordered); len(ids) != 0 {
+		t.Errorf("Expected no candidates for reordered content, got %v", ids)
+	}
+
+	d.Remove(original.ID)
+	if ids, _ := d.Candidates(repeat); len(ids) != 0 || d.Size() != 1 {
+		t.Errorf("Expected original removed, got %v with size %d", ids, d.Size())
+	}
+}
+
+// ============================================================================
+// Retriever Integration Tests
+// ============================================================================
+
+func TestSubLinearRetriever_AddOrMerge(t *testing.T) {
+	r := NewSubLinearRetriever(8)
+	r.EnableDedup(DefaultDedupConfig())
+	input, output := "optimize the cache eviction policy for the session store", "switch the session store to LRU eviction"
+
+	first := NewExperienceTuple("VELOCITY", 1, input, output, "direct")
+	first.FitnessScore = 0.4
+	if _, merged, err := r.AddOrMerge(first); err != nil || merged {
+		t.Fatalf("Expected first experience stored, got merged=%v err=%v", merged, err)
+	}
+
+	repeat := NewExperienceTuple("VELOCITY", 1, input, output, "direct")
+	repeat.FitnessScore = 0.8
+	stored, merged, err := r.AddOrMerge(repeat)
+	if err != nil || !merged || stored.ID != first.ID {
+		t.Fatalf("Expected repeat merged into first, got merged=%v err=%v", merged, err)
+	}
+	if first.Weight != 2 || first.FitnessScore < 0.599 || first.FitnessScore > 0.601 {
+		t.Errorf("Expected weight 2 and fitness 0.6, got %d and %f", first.Weight, first.FitnessScore)
+	}
+
+	// Other agents and outcomes are kept apart
+	otherAgent := NewExperienceTuple("APEX", 1, input, output, "direct")
+	failed := NewExperienceTuple("VELOCITY", 1, input, output, "direct")
+	failed.Success = false
+	for _, exp := range []*ExperienceTuple{otherAgent, failed} {
+		if _, merged, _ := r.AddOrMerge(exp); merged {
+			t.Errorf("Expected %s experience (success=%v) stored separately", exp.AgentID, exp.Success)
+		}
+	}
+
+	if r.Size() != 3 {
+		t.Errorf("Expected 3 stored experiences, got %d", r.Size())
+	}
+	if stats := r.GetStats(); stats.MergedExperiences != 1 || stats.TotalExperiences != 3 {
+		t.Errorf("Expected 1 merged and 3 stored, got %d and %d", stats.MergedExperiences, stats.TotalExperiences)
+	}
+}
+
+func TestSubLinearRetriever_AddOrMergeWithoutDedup(t *testing.T) {
+	r := NewSubLinearRetriever(8)
+	for i := 0; i < 2; i++ {
+		exp := NewExperienceTuple("APEX", 1, "sort a list", "sort.Ints", "direct")
+		exp.ID = exp.ID[:31] + string(rune('0'+i))
+		if _, merged, err := r.AddOrMerge(exp); err != nil || merged {
+			t.Fatalf("Expected plain add, got merged=%v err=%v", merged, err)
+		}
+	}
+	if r.Size() != 2 {
+		t.Errorf("Expected 2 stored experiences, got %d", r.Size())
+	}
+}
+
+func TestSubLinearRetriever_MergeWALReplay(t *testing.T) {
+	wal, path := openTestWAL(t)
+	r := NewSubLinearRetriever(8)
+	r.EnableDedup(DefaultDedupConfig())
+	r.AttachWAL(wal)
+
+	first := NewExperienceTuple("APEX", 1, "write a binary search over sorted ids", "use sort.Search", "direct")
+	r.AddOrMerge(first)
+	r.AddOrMerge(NewExperienceTuple("APEX", 1, "write a binary search over sorted ids", "use sort.Search", "direct"))
+	wal.Close()
+
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewSubLinearRetriever(8)
+	recovered.EnableDedup(DefaultDedupConfig())
+	if _, err := recovered.ReplayWAL(wal, 0); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+
+	exps := recovered.GetByAgent("APEX")
+	if len(exps) != 1 || exps[0].Weight != 2 {
+		t.Fatalf("Expected one experience with weight 2 after replay, got %d", len(exps))
+	}
+	if _, merged, _ := recovered.AddOrMerge(NewExperienceTuple("APEX", 1, "write a binary search over sorted ids", "use sort.Search", "direct")); !merged {
+		t.Error("Expected replayed experience indexed for deduplication")
+	}
+}
+
+func TestReMemController_DedupRepeatedTasks(t *testing.T) {
+	controller := NewReMemController(DefaultReMemConfig(), nil)
+	for i := 0; i < 3; i++ {
+		exp := NewExperienceTuple("APEX", 1, "sort a list of names", "use sort.Strings", "direct")
+		exp.ID = exp.ID[:31] + string(rune('0'+i))
+		if err := controller.updater.AddAndEvolve(exp, &Evaluation{Success: true, Score: 0.7}); err != nil {
+			t.Fatalf("AddAndEvolve failed: %v", err)
+		}
+	}
+
+	exps := controller.GetRetriever().GetByAgent("APEX")
+	if len(exps) != 1 || exps[0].Weight != 3 {
+		t.Errorf("Expected one experience with weight 3, got %d", len(exps))
+	}
+}
@@ -0,0 +1,339 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements batch ingestion of outcome feedback into the
+// agent-aware learning structures.
+//
+// A batch (e.g. from an offline evaluation run) is validated up front and
+// then applied in one pass: attention updates are published as a single
+// routing snapshot and the affinity routing tables are rebuilt once, rather
+// than once per record.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// MaxFeedbackBatch is the most records accepted in one batch.
+const MaxFeedbackBatch = 1000
+
+// maxFeedbackBody bounds the size of a batch request body.
+const maxFeedbackBody = 4 << 20
+
+// FeedbackRecord is the outcome of one agent invocation.
+type FeedbackRecord struct {
+	// Query is the request text the agent handled
+	Query string `json:"query"`
+	// Agent is the codename of the agent that handled it
+	Agent string `json:"agent"`
+	// Collaborators are other agents that took part
+	Collaborators []string `json:"collaborators,omitempty"`
+	Success       bool     `json:"success"`
+	// TaskType groups outcomes for insight detection; defaults to "general"
+	TaskType string `json:"task_type,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+	// User is the subject the query was made for, if known
+	User string `json:"user,omitempty"`
+	// Verbosity is the answer length the user asked for: concise or
+	// detailed
+	Verbosity string `json:"verbosity,omitempty"`
+	// Issue is what was wrong with the answer: factual_error, incoherent
+	// or repetitive
+	Issue string `json:"issue,omitempty"`
+}
+
+// Experience returns the record as an experience of its agent, which
+// episodic memory can keep.
+func (r FeedbackRecord) Experience() *ExperienceTuple {
+	exp := NewExperienceTuple(r.Agent, 0, r.Query, "", r.Strategy)
+	exp.Success = r.Success
+	exp.TaskType = r.TaskType
+	if len(r.Collaborators) > 0 {
+		exp.Metadata["collaborators"] = append([]string(nil), r.Collaborators...)
+	}
+	return exp
+}
+
+// FeedbackRejection explains why a record was not applied.
+type FeedbackRejection struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// AgentFeedback counts the outcomes applied for one agent.
+type AgentFeedback struct {
+	Successes int `json:"successes"`
+	Failures  int `json:"failures"`
+}
+
+// FeedbackSummary describes the updates made for a batch.
+type FeedbackSummary struct {
+	Received int                 `json:"received"`
+	Applied  int                 `json:"applied"`
+	Rejected []FeedbackRejection `json:"rejected"`
+	// AttentionUpdates counts category weights adjusted
+	AttentionUpdates int `json:"attention_updates"`
+	// AffinityUpdates counts agent pair outcomes recorded
+	AffinityUpdates int `json:"affinity_updates"`
+	// RoutingUpdates counts outcomes the routing blend learned from
+	RoutingUpdates int `json:"routing_updates"`
+	// Surprises counts successes the insight detector found unexpected
+	Surprises int `json:"surprises"`
+	// Pooled counts outcomes held for private release with other tenants'
+	// rather than applied directly; the updates above count what the batch
+	// released
+	Pooled int                       `json:"pooled,omitempty"`
+	Agents map[string]*AgentFeedback `json:"agents"`
+}
+
+// FeedbackIngester applies outcome feedback to the learning structures.
+// Any structure may be nil, in which case it is not updated.
+type FeedbackIngester struct {
+	attention *CollaborativeAttentionIndex
+	affinity  *AgentAffinityGraph
+	insights  *EmergentInsightDetector
+
+	// router learns its routing blend from the outcomes; nil skips it
+	router *HybridRouter
+	// privacy pools tenants' outcomes before they reach the structures;
+	// nil applies them directly
+	privacy *PrivateAggregator
+
+	// onIngested is called with the summary of each batch
+	onIngested func(*FeedbackSummary)
+	// onApplied is called with the records applied from each batch
+	onApplied func(context.Context, []FeedbackRecord)
+	// onBreakthrough is called with the breakthroughs each batch revealed
+	onBreakthrough func(context.Context, SurpriseEvent)
+}
+
+// NewFeedbackIngester creates an ingester over the learning structures.
+func NewFeedbackIngester(attention *CollaborativeAttentionIndex, affinity *AgentAffinityGraph, insights *EmergentInsightDetector) *FeedbackIngester {
+	return &FeedbackIngester{attention: attention, affinity: affinity, insights: insights}
+}
+
+// SetRouter has the router learn its routing blend from ingested
+// outcomes. It must be set before the ingester is used.
+func (f *FeedbackIngester) SetRouter(router *HybridRouter) {
+	f.router = router
+}
+
+// SetPrivacy has tenants' outcomes pooled by an aggregator and applied
+// only as it releases them. Outcomes without a tenant belong to no one and
+// are applied directly. It must be set before the ingester is used.
+func (f *FeedbackIngester) SetPrivacy(privacy *PrivateAggregator) {
+	f.privacy = privacy
+}
+
+// OnIngested sets a callback for applied batches. It must be set before
+// the ingester is used.
+func (f *FeedbackIngester) OnIngested(fn func(*FeedbackSummary)) {
+	f.onIngested = fn
+}
+
+// OnApplied sets a callback for the records applied from each batch,
+// called with the batch's context. It must be set before the ingester is
+// used.
+func (f *FeedbackIngester) OnApplied(fn func(context.Context, []FeedbackRecord)) {
+	f.onApplied = fn
+}
+
+// OnBreakthrough sets a callback for the surprising successes found in
+// each batch, called with the batch's context after the batch is applied,
+// so breakthroughs can be attributed to the tenant that sent it. It must
+// be set before the ingester is used.
+func (f *FeedbackIngester) OnBreakthrough(fn func(context.Context, SurpriseEvent)) {
+	f.onBreakthrough = fn
+}
+
+// normalize validates a record and canonicalizes its agent codenames.
+func (r *FeedbackRecord) normalize() error {
+	r.Agent = strings.ToUpper(strings.TrimSpace(r.Agent))
+	if r.Agent == "" {
+		return errors.New("agent is required")
+	}
+	if strings.TrimSpace(r.Query) == "" {
+		return errors.New("query is required")
+	}
+	for i, collaborator := range r.Collaborators {
+		collaborator = strings.ToUpper(strings.TrimSpace(collaborator))
+		if collaborator == "" || collaborator == r.Agent {
+			return fmt.Errorf("collaborator %d is empty or the agent itself", i)
+		}
+		r.Collaborators[i] = collaborator
+	}
+	if r.TaskType == "" {
+		r.TaskType = "general"
+	}
+	switch r.Verbosity {
+	case "", "concise", "detailed":
+	default:
+		return fmt.Errorf("verbosity must be concise or detailed, got %q", r.Verbosity)
+	}
+	if r.Issue != "" && !containsString(feedbackIssues, r.Issue) {
+		return fmt.Errorf("issue must be one of %s, got %q", strings.Join(feedbackIssues, ", "), r.Issue)
+	}
+	return nil
+}
+
+// Ingest applies a batch of records. Invalid records are skipped and
+// listed in the summary; the rest are applied.
+func (f *FeedbackIngester) Ingest(records []FeedbackRecord) *FeedbackSummary {
+	return f.ingest(context.Background(), records)
+}
+
+// ingest is Ingest with the context routing queries are embedded under.
+func (f *FeedbackIngester) ingest(ctx context.Context, records []FeedbackRecord) *FeedbackSummary {
+	summary := &FeedbackSummary{
+		Received: len(records),
+		Rejected: make([]FeedbackRejection, 0),
+		Agents:   make(map[string]*AgentFeedback),
+	}
+
+	attention := make([]AttentionFeedback, 0, len(records))
+	applied := make([]FeedbackRecord, 0, len(records))
+	collaborations := make([]CollaborationOutcome, 0)
+	var breakthroughs []SurpriseEvent
+	for i := range records {
+		record := &records[i]
+		if err := record.normalize(); err != nil {
+			summary.Rejected = append(summary.Rejected, FeedbackRejection{Index: i, Error: err.Error()})
+			continue
+		}
+		summary.Applied++
+		applied = append(applied, *record)
+
+		counts := summary.Agents[record.Agent]
+		if counts == nil {
+			counts = &AgentFeedback{}
+			summary.Agents[record.Agent] = counts
+		}
+		if record.Success {
+			counts.Successes++
+		} else {
+			counts.Failures++
+		}
+
+		attention = append(attention, AttentionFeedback{Query: record.Query, Agent: record.Agent, Success: record.Success})
+		for _, collaborator := range record.Collaborators {
+			collaborations = append(collaborations, CollaborationOutcome{Agent1: record.Agent, Agent2: collaborator, Success: record.Success})
+		}
+
+		if f.insights != nil {
+			agents := append([]string{record.Agent}, record.Collaborators...)
+			surprise := f.insights.RecordOutcome(agents, record.TaskType, record.Success, record.Strategy)
+			if record.Success && surprise > f.insights.surpriseThreshold {
+				summary.Surprises++
+				breakthroughs = append(breakthroughs, SurpriseEvent{
+					Agents:        agents,
+					TaskType:      record.TaskType,
+					SurpriseScore: surprise,
+					Timestamp:     time.Now(),
+					Strategy:      record.Strategy,
+				})
+			}
+		}
+	}
+
+	tenant := features.TenantFromContext(ctx)
+	if f.privacy != nil && tenant != "" {
+		summary.Pooled = len(attention)
+		f.applyRelease(ctx, summary, f.pool(tenant, attention, collaborations))
+	} else {
+		if f.attention != nil {
+			summary.AttentionUpdates = f.attention.UpdateAttentionBatch(attention)
+		}
+		if f.affinity != nil {
+			f.affinity.RecordCollaborations(collaborations)
+			summary.AffinityUpdates = len(collaborations)
+		}
+		f.learnBlend(ctx, summary, attention)
+	}
+	if f.onApplied != nil && len(applied) > 0 {
+		f.onApplied(ctx, applied)
+	}
+	if f.onBreakthrough != nil {
+		for _, event := range breakthroughs {
+			f.onBreakthrough(ctx, event)
+		}
+	}
+	if f.onIngested != nil {
+		f.onIngested(summary)
+	}
+	return summary
+}
+
+// pool hands a tenant's outcomes to the privacy aggregator and returns what
+// it released.
+func (f *FeedbackIngester) pool(tenant string, attention []AttentionFeedback, collaborations []CollaborationOutcome) privateRelease {
+	categories := make([][]string, len(attention))
+	if f.attention != nil {
+		for i, feedback := range attention {
+			categories[i] = f.attention.MatchCategories(feedback.Query)
+		}
+	}
+	return f.privacy.add(tenant, attention, categories, collaborations)
+}
+
+// applyRelease applies the aggregates the privacy aggregator released.
+func (f *FeedbackIngester) applyRelease(ctx context.Context, summary *FeedbackSummary, release privateRelease) {
+	if f.attention != nil {
+		summary.AttentionUpdates = f.attention.UpdateAttentionCounts(release.attention)
+	}
+	if f.affinity != nil {
+		f.affinity.RecordCollaborations(release.collaborations)
+		summary.AffinityUpdates = len(release.collaborations)
+	}
+	f.learnBlend(ctx, summary, release.blend)
+}
+
+// learnBlend has the router learn its routing blend from outcomes.
+func (f *FeedbackIngester) learnBlend(ctx context.Context, summary *FeedbackSummary, attention []AttentionFeedback) {
+	if f.router == nil || len(attention) == 0 {
+		return
+	}
+	used, err := f.router.Learn(ctx, attention)
+	if err != nil {
+		log.Printf("Routing blend learned from %d of %d outcomes: %v", used, len(attention), err)
+	}
+	summary.RoutingUpdates = used
+}
+
+// FeedbackBatchRequest is the body of POST /feedback/batch.
+type FeedbackBatchRequest struct {
+	Records []FeedbackRecord `json:"records"`
+}
+
+// ServeBatch handles POST /feedback/batch - applies a batch of outcome
+// records and returns a summary of the updates made.
+func (f *FeedbackIngester) ServeBatch(w http.ResponseWriter, r *http.Request) {
+	var req FeedbackBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFeedbackBody)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errdefs.WriteError(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if len(req.Records) == 0 {
+		errdefs.WriteError(w, "records are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Records) > MaxFeedbackBatch {
+		errdefs.WriteError(w, fmt.Sprintf("at most %d records per batch", MaxFeedbackBatch), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	errdefs.WriteJSON(w, http.StatusOK, f.ingest(r.Context(), req.Records))
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================================================
+// Feedback Ingestion Tests
+// ============================================================================
+
+func TestFeedbackIngester_Ingest(t *testing.T) {
+	attention := NewCollaborativeAttentionIndex()
+	affinity := NewAgentAffinityGraph()
+	ingester := NewFeedbackIngester(attention, affinity, NewEmergentInsightDetector())
+	before := attention.state.Load().weights["testing"]["ECLIPSE"]
+	affinityBefore := affinity.GetAffinityScore("ECLIPSE", "APEX")
+
+	records := []FeedbackRecord{
+		{Query: "write a unit test", Agent: "eclipse", Collaborators: []string{"APEX"}, Success: true},
+		{Query: "write a unit test", Agent: "ECLIPSE", Success: true},
+		{Query: "write a unit test", Agent: "CANVAS", Success: false},
+		{Query: "", Agent: "APEX", Success: true},
+		{Query: "deploy it", Agent: "FLUX", Collaborators: []string{"FLUX"}},
+	}
+	summary := ingester.Ingest(records)
+
+	if summary.Received != 5 || summary.Applied != 3 || len(summary.Rejected) != 2 {
+		t.Fatalf("Expected 3 of 5 applied, got %+v", summary)
+	}
+	if summary.Rejected[0].Index != 3 || summary.Rejected[1].Index != 4 {
+		t.Errorf("Expected records 3 and 4 rejected, got %+v", summary.Rejected)
+	}
+	if summary.Agents["ECLIPSE"].Successes != 2 || summary.Agents["CANVAS"].Failures != 1 {
+		t.Errorf("Expected per-agent counts, got %+v", summary.Agents)
+	}
+	// The query matches the testing and documentation categories
+	if summary.AttentionUpdates != 6 || summary.AffinityUpdates != 1 {
+		t.Errorf("Expected 6 attention and 1 affinity update, got %d and %d", summary.AttentionUpdates, summary.AffinityUpdates)
+	}
+
+	if after := attention.state.Load().weights["testing"]["ECLIPSE"]; after <= before {
+		t.Errorf("Expected ECLIPSE testing weight above %f, got %f", before, after)
+	}
+	if affinity.totalCount["ECLIPSE"]["APEX"] != 1 {
+		t.Errorf("Expected ECLIPSE-APEX collaboration recorded, got %d", affinity.totalCount["ECLIPSE"]["APEX"])
+	}
+	if after := affinity.GetAffinityScore("ECLIPSE", "APEX"); after <= affinityBefore {
+		t.Errorf("Expected ECLIPSE-APEX affinity above %f, got %f", affinityBefore, after)
+	}
+}
+
+func TestFeedbackIngester_MatchesSequentialUpdates(t *testing.T) {
+	batched := NewCollaborativeAttentionIndex()
+	sequential := NewCollaborativeAttentionIndex()
+	records := make([]FeedbackRecord, 0)
+	for i := 0; i < 50; i++ {
+		records = append(records, FeedbackRecord{Query: "optimize cache performance", Agent: "VELOCITY", Success: i%4 != 0})
+	}
+
+	NewFeedbackIngester(batched, nil, nil).Ingest(records)
+	for _, r := range records {
+		sequential.UpdateAttention(r.Query, r.Agent, r.Success)
+	}
+
+	for category, weights := range sequential.state.Load().weights {
+		for agent, w := range weights {
+			if got := batched.state.Load().weights[category][agent]; fmt.Sprintf("%.12f", got) != fmt.Sprintf("%.12f", w) {
+				t.Fatalf("Expected %s/%s weight %f, got %f", category, agent, w, got)
+			}
+		}
+	}
+}
+
+func TestFeedbackIngester_OnIngested(t *testing.T) {
+	ingester := NewFeedbackIngester(nil, nil, nil)
+	var summaries []*FeedbackSummary
+	ingester.OnIngested(func(summary *FeedbackSummary) {
+		summaries = append(summaries, summary)
+	})
+
+	summary := ingester.Ingest([]FeedbackRecord{{Query: "write a unit test", Agent: "ECLIPSE", Success: true}})
+	if len(summaries) != 1 || summaries[0] != summary {
+		t.Errorf("Expected the batch summary passed to the callback, got %v", summaries)
+	}
+}
+
+func TestFeedbackIngester_OnApplied(t *testing.T) {
+	ingester := NewFeedbackIngester(nil, nil, nil)
+	var applied []FeedbackRecord
+	ingester.OnApplied(func(ctx context.Context, records []FeedbackRecord) {
+		applied = append(applied, records...)
+	})
+
+	summary := ingester.Ingest([]FeedbackRecord{
+		{Query: "write a unit test", Agent: "eclipse", Success: true, User: "alice", Verbosity: "concise"},
+		{Query: "explain this", Agent: "APEX", Verbosity: "chatty"},
+		{Query: "explain that", Agent: "APEX", Issue: "too_long"},
+	})
+	if summary.Applied != 1 || len(summary.Rejected) != 2 {
+		t.Errorf("Expected the records with an unknown verbosity and issue rejected, got %+v", summary)
+	}
+	if len(applied) != 1 || applied[0].Agent != "ECLIPSE" || applied[0].User != "alice" {
+		t.Errorf("Expected the normalized applied record passed to the callback, got %+v", applied)
+	}
+}
+
+func TestFeedbackRecord_Experience(t *testing.T) {
+	record := FeedbackRecord{Query: "audit the login flow", Agent: "CIPHER", Collaborators: []string{"FORTRESS"}, TaskType: "security", Strategy: "threat model"}
+	exp := record.Experience()
+	if exp.AgentID != "CIPHER" || exp.Input != record.Query || exp.TaskType != "security" || exp.Strategy != "threat model" || exp.Success {
+		t.Errorf("Expected the record's agent, query, task, strategy and outcome, got %+v", exp)
+	}
+	if collaborators, _ := exp.Metadata["collaborators"].([]string); len(collaborators) != 1 || collaborators[0] != "FORTRESS" {
+		t.Errorf("Expected the collaborators in metadata, got %v", exp.Metadata)
+	}
+}
+
+func TestFeedbackIngester_OnBreakthrough(t *testing.T) {
+	type tenantKey struct{}
+	ingester := NewFeedbackIngester(nil, nil, NewEmergentInsightDetector())
+	var tenants []interface{}
+	var events []SurpriseEvent
+	ingester.OnBreakthrough(func(ctx context.Context, event SurpriseEvent) {
+		tenants = append(tenants, ctx.Value(tenantKey{}))
+		events = append(events, event)
+	})
+
+	records := make([]FeedbackRecord, 0)
+	for i := 0; i < 5; i++ {
+		records = append(records, FeedbackRecord{Query: "harden the API", Agent: "CIPHER", TaskType: "security"})
+	}
+	records = append(records, FeedbackRecord{Query: "harden the API", Agent: "CIPHER", TaskType: "security", Success: true, Strategy: "threat-model"})
+	summary := ingester.ingest(context.WithValue(context.Background(), tenantKey{}, "acme"), records)
+
+	if summary.Surprises != 1 || len(events) != 1 {
+		t.Fatalf("Expected one breakthrough, got %d surprises and %+v", summary.Surprises, events)
+	}
+	if tenants[0] != "acme" {
+		t.Errorf("Expected the batch's context passed to the callback, got %v", tenants[0])
+	}
+	if events[0].TaskType != "security" || events[0].Strategy != "threat-model" || events[0].Agents[0] != "CIPHER" {
+		t.Errorf("Expected the breakthrough's task, strategy and agents, got %+v", events[0])
+	}
+}
+
+func TestFeedbackIngester_ServeBatch(t *testing.T) {
+	ingester := NewFeedbackIngester(NewCollaborativeAttentionIndex(), NewAgentAffinityGraph(), nil)
+
+	body, _ := json.Marshal(FeedbackBatchRequest{Records: []FeedbackRecord{
+		{Query: "design a microservice architecture", Agent: "ARCHITECT", Success: true},
+	}})
+	rec := httptest.NewRecorder()
+	ingester.ServeBatch(rec, httptest.NewRequest(http.MethodPost, "/feedback/batch", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var summary FeedbackSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Applied != 1 || summary.AttentionUpdates == 0 {
+		t.Errorf("Expected one record applied, got %+v", summary)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", "{", http.StatusBadRequest},
+		{"no records", `{"records": []}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		ingester.ServeBatch(rec, httptest.NewRequest(http.MethodPost, "/feedback/batch", bytes.NewReader([]byte(tt.body))))
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.code, rec.Code)
+		}
+	}
+
+	tooMany := FeedbackBatchRequest{Records: make([]FeedbackRecord, MaxFeedbackBatch+1)}
+	body, _ = json.Marshal(tooMany)
+	rec = httptest.NewRecorder()
+	ingester.ServeBatch(rec, httptest.NewRequest(http.MethodPost, "/feedback/batch", bytes.NewReader(body)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for an oversized batch, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,113 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements follow-up suggestions: prompts a user might send
+// next, built from the concepts an exchange mentions and the concepts the
+// Semantic Network relates them to that the exchange did not touch.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// followUpTemplates phrase a follow-up about a relation between its source
+// and target labels, so that most can be answered by the knowledge graph.
+var followUpTemplates = map[RelationType]string{
+	IsA:        "How does %s differ from other kinds of %s?",
+	InstanceOf: "How does %s differ from other kinds of %s?",
+	HasA:       "What role does %[2]s play in %[1]s?",
+	PartOf:     "What role does %s play in %s?",
+	UsedFor:    "How is %s used for %s?",
+	Requires:   "Why does %s require %s?",
+	Produces:   "How does %s produce %s?",
+	SimilarTo:  "How does %s compare with %s?",
+	OppositeOf: "How does %s compare with %s?",
+}
+
+// defaultFollowUpTemplate phrases a follow-up about any other relation.
+const defaultFollowUpTemplate = "How is %s related to %s?"
+
+// FollowUpSuggester suggests follow-up prompts from a semantic network. It
+// is safe for concurrent use.
+type FollowUpSuggester struct {
+	network *SemanticNetwork
+	qa      *QuestionAnswerer
+}
+
+// NewFollowUpSuggester creates a suggester over a network.
+func NewFollowUpSuggester(network *SemanticNetwork) *FollowUpSuggester {
+	return &FollowUpSuggester{network: network, qa: NewQuestionAnswerer(network)}
+}
+
+// Suggest returns up to n follow-up prompts for a query and its answer.
+// Each asks about a concept the query or answer mentions and a concept
+// related to it that neither mentions. The mentioned concepts take turns,
+// the query's first, each offering its strongest remaining relation, so
+// the suggestions spread over the exchange. It returns nil when the
+// exchange mentions nothing the network relates to anything new.
+func (s *FollowUpSuggester) Suggest(ctx context.Context, query, answer string, n int) []string {
+	mentioned := s.qa.LinkEntities(query + "\n" + answer)
+	if len(mentioned) == 0 || n <= 0 {
+		return nil
+	}
+	covered := make(map[string]bool, len(mentioned))
+	for _, node := range mentioned {
+		covered[node.ID] = true
+	}
+
+	candidates := make([][]*SemanticRelation, len(mentioned))
+	for i, node := range mentioned {
+		relations := append(s.network.GetOutgoingRelations(node.ID), s.network.GetIncomingRelations(node.ID)...)
+		sort.SliceStable(relations, func(a, b int) bool {
+			return relations[a].Weight > relations[b].Weight
+		})
+		candidates[i] = relations
+	}
+
+	var suggestions []string
+	for offered := true; offered && len(suggestions) < n; {
+		offered = false
+		for i, node := range mentioned {
+			if len(suggestions) == n {
+				break
+			}
+			for len(candidates[i]) > 0 {
+				rel := candidates[i][0]
+				candidates[i] = candidates[i][1:]
+				if suggestion, ok := s.suggest(node, rel, covered); ok {
+					suggestions = append(suggestions, suggestion)
+					offered = true
+					break
+				}
+			}
+		}
+	}
+	return suggestions
+}
+
+// suggest phrases a follow-up about a relation of a mentioned node, unless
+// the node at its other end is already covered; it then covers that node.
+func (s *FollowUpSuggester) suggest(node *SemanticNode, rel *SemanticRelation, covered map[string]bool) (string, bool) {
+	other := rel.TargetID
+	if other == node.ID {
+		other = rel.SourceID
+	}
+	if covered[other] {
+		return "", false
+	}
+	source, err := s.network.GetNode(rel.SourceID)
+	if err != nil {
+		return "", false
+	}
+	target, err := s.network.GetNode(rel.TargetID)
+	if err != nil {
+		return "", false
+	}
+	covered[other] = true
+	template, ok := followUpTemplates[rel.Type]
+	if !ok {
+		template = defaultFollowUpTemplate
+	}
+	return fmt.Sprintf(template, source.Label, target.Label), true
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFollowUpSuggester_Suggest(t *testing.T) {
+	suggester := NewFollowUpSuggester(buildQANetwork())
+	ctx := context.Background()
+
+	got := suggester.Suggest(ctx, "Is QuickSort faster than MergeSort?", "MergeSort is stable; QuickSort sorts in place.", 3)
+	want := []string{
+		"How does QuickSort differ from other kinds of Sorting Algorithm?",
+		"Why does MergeSort require Recursion?",
+		"Why does MergeSort require Extra Memory?",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected suggestions spread over both sorts, got %q", got)
+	}
+
+	got = suggester.Suggest(ctx, "Explain MergeSort", "It uses Recursion to sort each half.", 3)
+	want = []string{
+		"How does MergeSort differ from other kinds of Sorting Algorithm?",
+		"Why does MergeSort require Extra Memory?",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected no suggestion about mentioned Recursion, got %q", got)
+	}
+
+	if got := suggester.Suggest(ctx, "Explain MergeSort", "", 1); len(got) != 1 {
+		t.Errorf("Expected suggestions limited to 1, got %q", got)
+	}
+	if got := suggester.Suggest(ctx, "What's for lunch?", "Soup.", 3); got != nil {
+		t.Errorf("Expected no suggestions without known concepts, got %q", got)
+	}
+}
@@ -0,0 +1,194 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements project glossaries generated from the Semantic
+// Network, for SCRIBE to embed in documentation.
+//
+// A project's glossary has an entry per concept in the project: a concept
+// whose project property, its own or inherited through IS-A, names the
+// project, or that belongs to or is part of a node named for it. Each
+// entry carries the concept's definition, the broader concepts it is a
+// kind of, the concepts related to it and its remaining properties.
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrGlossaryEmpty is returned when no concepts are in a project.
+var ErrGlossaryEmpty = errdefs.New(errdefs.ErrNotFound, "no concepts in project")
+
+const (
+	// GlossaryProjectProperty names the project a concept is in
+	GlossaryProjectProperty = "project"
+	// GlossaryDefinitionProperty holds a concept's definition; concepts
+	// without one fall back to their description
+	GlossaryDefinitionProperty = "definition"
+)
+
+// glossaryOmittedProperties are the properties not listed under an entry,
+// since the entry already shows them.
+var glossaryOmittedProperties = map[string]bool{
+	GlossaryProjectProperty:    true,
+	GlossaryDefinitionProperty: true,
+	"description":              true,
+}
+
+// GlossaryEntry is one term of a glossary.
+type GlossaryEntry struct {
+	ID   string `json:"id"`
+	Term string `json:"term"`
+	// Definition is the concept's definition, or one generated from the
+	// concepts it is a kind of when it has none
+	Definition string `json:"definition"`
+	// Generated is true when the definition was generated
+	Generated bool `json:"generated,omitempty"`
+	// Broader are the concepts this one is a kind of
+	Broader []string `json:"broader"`
+	// Related are the concepts related or similar to this one
+	Related    []string          `json:"related"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Confidence float64           `json:"confidence"`
+}
+
+// Glossary is a project's glossary, its entries sorted by term.
+type Glossary struct {
+	Project string          `json:"project"`
+	Entries []GlossaryEntry `json:"entries"`
+}
+
+// BuildGlossary assembles the glossary of a project's concepts. It returns
+// ErrGlossaryEmpty when no concepts are in the project.
+func (sn *SemanticNetwork) BuildGlossary(project string) (*Glossary, error) {
+	project = strings.TrimSpace(project)
+	glossary := &Glossary{Project: project, Entries: make([]GlossaryEntry, 0)}
+	for _, node := range sn.GetNodesByType(ConceptNode) {
+		if !sn.inProject(node, project) {
+			continue
+		}
+		glossary.Entries = append(glossary.Entries, sn.glossaryEntry(node))
+	}
+	if len(glossary.Entries) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrGlossaryEmpty, project)
+	}
+	sort.Slice(glossary.Entries, func(i, j int) bool {
+		a, b := strings.ToLower(glossary.Entries[i].Term), strings.ToLower(glossary.Entries[j].Term)
+		if a != b {
+			return a < b
+		}
+		return glossary.Entries[i].ID < glossary.Entries[j].ID
+	})
+	return glossary, nil
+}
+
+// inProject reports whether a concept is in a project.
+func (sn *SemanticNetwork) inProject(node *SemanticNode, project string) bool {
+	if props, err := sn.GetInheritedProperties(node.ID); err == nil {
+		if prop, ok := props[GlossaryProjectProperty]; ok {
+			if value, ok := AsPropertyValue(prop.Value); ok && strings.EqualFold(value.String(), project) {
+				return true
+			}
+		}
+	}
+	for _, rel := range sn.GetOutgoingRelations(node.ID) {
+		if rel.Type != BelongsTo && rel.Type != PartOf {
+			continue
+		}
+		if strings.EqualFold(rel.TargetID, project) {
+			return true
+		}
+		if target, err := sn.GetNode(rel.TargetID); err == nil && strings.EqualFold(target.Label, project) {
+			return true
+		}
+	}
+	return false
+}
+
+// glossaryEntry builds a concept's glossary entry.
+func (sn *SemanticNetwork) glossaryEntry(node *SemanticNode) GlossaryEntry {
+	entry := GlossaryEntry{
+		ID:         node.ID,
+		Term:       node.Label,
+		Broader:    make([]string, 0),
+		Related:    make([]string, 0),
+		Confidence: node.Confidence,
+	}
+
+	for _, parent := range sn.GetRelatedNodes(node.ID, IsA) {
+		entry.Broader = appendUnique(entry.Broader, parent.Label)
+	}
+	for _, rel := range append(sn.GetOutgoingRelations(node.ID), sn.GetIncomingRelations(node.ID)...) {
+		if rel.Type != RelatedTo && rel.Type != SimilarTo {
+			continue
+		}
+		otherID := rel.TargetID
+		if otherID == node.ID {
+			otherID = rel.SourceID
+		}
+		if other, err := sn.GetNode(otherID); err == nil {
+			entry.Related = appendUnique(entry.Related, other.Label)
+		}
+	}
+	sort.Strings(entry.Broader)
+	sort.Strings(entry.Related)
+
+	for _, key := range []string{GlossaryDefinitionProperty, "description"} {
+		if value, ok := node.GetTypedProperty(key); ok && value.String() != "" {
+			entry.Definition = value.String()
+			break
+		}
+	}
+	if entry.Definition == "" && len(entry.Broader) > 0 {
+		entry.Definition = "A kind of " + strings.Join(entry.Broader, " and ") + "."
+		entry.Generated = true
+	}
+
+	for key, raw := range node.Properties {
+		if glossaryOmittedProperties[key] {
+			continue
+		}
+		if value, ok := AsPropertyValue(raw); ok {
+			if entry.Properties == nil {
+				entry.Properties = make(map[string]string)
+			}
+			entry.Properties[key] = value.String()
+		}
+	}
+	return entry
+}
+
+// Markdown renders the glossary as a Markdown document: a heading per
+// term, its definition, and its broader and related terms and properties
+// as a list.
+func (g *Glossary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s Glossary\n", g.Project)
+	for _, entry := range g.Entries {
+		fmt.Fprintf(&b, "\n## %s\n", entry.Term)
+		if entry.Definition != "" {
+			fmt.Fprintf(&b, "\n%s\n", entry.Definition)
+		}
+		var items []string
+		if len(entry.Broader) > 0 {
+			items = append(items, "**Broader:** "+strings.Join(entry.Broader, ", "))
+		}
+		if len(entry.Related) > 0 {
+			items = append(items, "**Related:** "+strings.Join(entry.Related, ", "))
+		}
+		keys := make([]string, 0, len(entry.Properties))
+		for key := range entry.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			items = append(items, "**"+key+":** "+entry.Properties[key])
+		}
+		if len(items) > 0 {
+			fmt.Fprintf(&b, "\n- %s\n", strings.Join(items, "\n- "))
+		}
+	}
+	return b.String()
+}
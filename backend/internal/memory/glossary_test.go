@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func buildGlossaryNetwork(t *testing.T) *SemanticNetwork {
+	t.Helper()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+
+	retrieval := NewSemanticNode("retrieval", "Retrieval", ConceptNode)
+	retrieval.SetProperty("project", "mnemonic")
+	retrieval.SetProperty("definition", "Finding what memory holds about a query.")
+	spreading := NewSemanticNode("spreading", "Spreading Activation", ConceptNode)
+	spreading.SetProperty("description", "Activation flowing along weighted relations.")
+	spreading.SetProperty("max_depth", 3)
+	network := NewSemanticNode("network", "Semantic Network", ConceptNode)
+	network.SetProperty("project", "mnemonic")
+	project := NewSemanticNode("mnemonic", "MNEMONIC", DomainNode)
+	wal := NewSemanticNode("wal", "Write-Ahead Log", ConceptNode)
+	unrelated := NewSemanticNode("tls", "TLS", ConceptNode)
+	unrelated.SetProperty("project", "fortress")
+
+	for _, node := range []*SemanticNode{retrieval, spreading, network, project, wal, unrelated} {
+		if err := sn.AddNode(node); err != nil {
+			t.Fatalf("AddNode failed: %v", err)
+		}
+	}
+	for _, rel := range []*SemanticRelation{
+		NewSemanticRelation("spreading", "retrieval", IsA),
+		NewSemanticRelation("spreading", "network", RelatedTo),
+		NewSemanticRelation("wal", "mnemonic", PartOf),
+		NewSemanticRelation("wal", "network", IsA),
+	} {
+		if err := sn.AddRelation(rel); err != nil {
+			t.Fatalf("AddRelation failed: %v", err)
+		}
+	}
+	return sn
+}
+
+func TestBuildGlossary(t *testing.T) {
+	sn := buildGlossaryNetwork(t)
+
+	glossary, err := sn.BuildGlossary("MNEMONIC")
+	if err != nil {
+		t.Fatalf("BuildGlossary failed: %v", err)
+	}
+	var terms []string
+	for _, entry := range glossary.Entries {
+		terms = append(terms, entry.Term)
+	}
+	// Spreading Activation inherits the project; the log is part of it
+	if got := strings.Join(terms, ", "); got != "Retrieval, Semantic Network, Spreading Activation, Write-Ahead Log" {
+		t.Fatalf("Expected the project's concepts by term, got %s", got)
+	}
+
+	spreading := glossary.Entries[2]
+	if spreading.Definition != "Activation flowing along weighted relations." || spreading.Generated {
+		t.Errorf("Expected the description as the definition, got %q", spreading.Definition)
+	}
+	if len(spreading.Broader) != 1 || spreading.Broader[0] != "Retrieval" {
+		t.Errorf("Expected Retrieval broader, got %v", spreading.Broader)
+	}
+	if len(spreading.Related) != 1 || spreading.Related[0] != "Semantic Network" {
+		t.Errorf("Expected Semantic Network related, got %v", spreading.Related)
+	}
+	if spreading.Properties["max_depth"] != "3" || len(spreading.Properties) != 1 {
+		t.Errorf("Expected only max_depth listed, got %v", spreading.Properties)
+	}
+	if network := glossary.Entries[1]; len(network.Related) != 1 || network.Related[0] != "Spreading Activation" {
+		t.Errorf("Expected relations followed in both directions, got %v", network.Related)
+	}
+	if wal := glossary.Entries[3]; wal.Definition != "A kind of Semantic Network." || !wal.Generated {
+		t.Errorf("Expected a generated definition, got %q", wal.Definition)
+	}
+
+	if _, err := sn.BuildGlossary("apex"); !errors.Is(err, ErrGlossaryEmpty) {
+		t.Errorf("Expected ErrGlossaryEmpty, got %v", err)
+	}
+}
+
+func TestGlossary_Markdown(t *testing.T) {
+	glossary, err := buildGlossaryNetwork(t).BuildGlossary("mnemonic")
+	if err != nil {
+		t.Fatalf("BuildGlossary failed: %v", err)
+	}
+	md := glossary.Markdown()
+
+	for _, want := range []string{
+		"# mnemonic Glossary\n",
+		"\n## Retrieval\n\nFinding what memory holds about a query.\n\n## Semantic Network\n",
+		"\n## Spreading Activation\n\nActivation flowing along weighted relations.\n\n- **Broader:** Retrieval\n- **Related:** Semantic Network\n- **max_depth:** 3\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the Markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+}
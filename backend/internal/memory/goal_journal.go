@@ -0,0 +1,361 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements per-goal outcome journals for post-mortems.
+//
+// A goal's journal records what happened while it was worked on - its
+// status transitions, the productions fired and agents invoked for it, the
+// impasses it hit and what was spent on it - alongside a summary of each,
+// so why a request went wrong can be read back by goal ID after the goal
+// has finished. A journal store opened on a directory writes each goal's
+// journal to a file as it changes, so journals survive restarts.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrJournalNotFound is returned when no goal has a journal.
+var ErrJournalNotFound = errdefs.New(errdefs.ErrNotFound, "goal journal not found")
+
+const (
+	// goalJournalRetention is how long finished goals' journals are kept
+	goalJournalRetention = 7 * 24 * time.Hour
+	// maxJournalEntries bounds the entries kept per goal; the oldest are
+	// dropped first, while the summary keeps counting
+	maxJournalEntries = 500
+)
+
+// JournalEntryKind is what a journal entry records.
+type JournalEntryKind string
+
+// Journal entry kinds.
+const (
+	// JournalEntryStatus records a goal's status transition
+	JournalEntryStatus JournalEntryKind = "status"
+	// JournalEntryProduction records a production fired for a goal
+	JournalEntryProduction JournalEntryKind = "production"
+	// JournalEntryAgent records an agent invoked for a goal
+	JournalEntryAgent JournalEntryKind = "agent"
+	// JournalEntryImpasse records an impasse a goal hit
+	JournalEntryImpasse JournalEntryKind = "impasse"
+	// JournalEntrySpend records tokens and cost spent on a goal
+	JournalEntrySpend JournalEntryKind = "spend"
+)
+
+// JournalEntry is one thing that happened to a goal.
+type JournalEntry struct {
+	At   time.Time        `json:"at"`
+	Kind JournalEntryKind `json:"kind"`
+	// Status is the goal's status after a status entry
+	Status     string `json:"status,omitempty"`
+	Production string `json:"production,omitempty"`
+	Agent      string `json:"agent,omitempty"`
+	// Impasse is the ID of the impasse an impasse entry records
+	Impasse string  `json:"impasse,omitempty"`
+	Tokens  int64   `json:"tokens,omitempty"`
+	Cost    float64 `json:"cost,omitempty"`
+	Detail  string  `json:"detail,omitempty"`
+}
+
+// JournalImpasse summarizes an impasse a goal hit.
+type JournalImpasse struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// GoalJournal is the journal of one goal: a summary of how it went, and
+// the entries it was built from.
+type GoalJournal struct {
+	GoalID   string `json:"goal_id"`
+	Name     string `json:"name,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+	// Status is the goal's latest status, which is its final state once
+	// it is completed or failed
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	Exhausted     GoalLimit `json:"exhausted,omitempty"`
+	// Agents and Productions are those invoked and fired for the goal, in
+	// the order first seen
+	Agents      []string         `json:"agents"`
+	Productions []string         `json:"productions"`
+	Impasses    []JournalImpasse `json:"impasses"`
+	TokensSpent int64            `json:"tokens_spent"`
+	CostSpent   float64          `json:"cost_spent"`
+	StartedAt   time.Time        `json:"started_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+	Entries     []JournalEntry   `json:"entries"`
+	// DroppedEntries counts the oldest entries dropped past the limit
+	DroppedEntries int `json:"dropped_entries,omitempty"`
+}
+
+// Finished reports whether the journal's goal is completed or failed.
+func (j *GoalJournal) Finished() bool {
+	return j.FinishedAt != nil
+}
+
+// clone copies the journal; with entries false the copy has none, for
+// listings.
+func (j *GoalJournal) clone(entries bool) *GoalJournal {
+	c := *j
+	c.Agents = append([]string{}, j.Agents...)
+	c.Productions = append([]string{}, j.Productions...)
+	c.Impasses = append([]JournalImpasse{}, j.Impasses...)
+	if j.FinishedAt != nil {
+		finished := *j.FinishedAt
+		c.FinishedAt = &finished
+	}
+	c.Entries = []JournalEntry{}
+	if entries {
+		c.Entries = append(c.Entries, j.Entries...)
+	}
+	return &c
+}
+
+// GoalJournalStore keeps goals' journals. It is safe for concurrent use;
+// its recording methods log failures to save a journal rather than
+// return them, so journaling never fails the work it records.
+type GoalJournalStore struct {
+	// dir holds one file per goal; empty keeps journals in memory
+	dir string
+
+	mu       sync.Mutex
+	journals map[string]*GoalJournal
+}
+
+// NewGoalJournalStore creates a store that keeps journals in memory.
+func NewGoalJournalStore() *GoalJournalStore {
+	return &GoalJournalStore{journals: make(map[string]*GoalJournal)}
+}
+
+// OpenGoalJournalStore opens a store persisting journals in dir, creating
+// it if needed, and loads the journals saved there. Finished goals'
+// journals past retention are deleted.
+func OpenGoalJournalStore(dir string) (*GoalJournalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create goal journal directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewGoalJournalStore()
+	s.dir = dir
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read goal journal: %w", err)
+		}
+		var journal GoalJournal
+		if err := json.Unmarshal(data, &journal); err != nil || journal.GoalID == "" {
+			return nil, fmt.Errorf("corrupt goal journal %s", filepath.Base(path))
+		}
+		s.journals[journal.GoalID] = &journal
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	return s, nil
+}
+
+// Get returns a copy of a goal's journal.
+func (s *GoalJournalStore) Get(goalID string) (*GoalJournal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	journal, ok := s.journals[goalID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJournalNotFound, goalID)
+	}
+	return journal.clone(true), nil
+}
+
+// List returns copies of the journals, without their entries, most
+// recently updated first. A non-empty status keeps only the journals of
+// goals in it, such as FAILED; a positive limit bounds the journals
+// returned.
+func (s *GoalJournalStore) List(status string, limit int) []*GoalJournal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	journals := make([]*GoalJournal, 0, len(s.journals))
+	for _, journal := range s.journals {
+		if status == "" || strings.EqualFold(journal.Status, status) {
+			journals = append(journals, journal.clone(false))
+		}
+	}
+	sort.Slice(journals, func(i, j int) bool {
+		if !journals[i].UpdatedAt.Equal(journals[j].UpdatedAt) {
+			return journals[i].UpdatedAt.After(journals[j].UpdatedAt)
+		}
+		return journals[i].GoalID < journals[j].GoalID
+	})
+	if limit > 0 && len(journals) > limit {
+		journals = journals[:limit]
+	}
+	return journals
+}
+
+// RecordGoal records a goal's current status, with detail saying why it
+// changed. It starts the goal's journal if it has none.
+func (s *GoalJournalStore) RecordGoal(goal *Goal, detail string) {
+	s.record(goal.ID, func(journal *GoalJournal, entry *JournalEntry) {
+		journal.Name = goal.Name
+		journal.ParentID = goal.ParentID
+		journal.Status = goal.Status.String()
+		journal.FailureReason = goal.FailureReason
+		journal.Exhausted = goal.Exhausted
+		if goal.IsTerminal() && journal.FinishedAt == nil {
+			finished := entry.At
+			journal.FinishedAt = &finished
+		}
+		entry.Kind = JournalEntryStatus
+		entry.Status = journal.Status
+		entry.Detail = detail
+	})
+}
+
+// RecordProduction records a production fired for a goal.
+func (s *GoalJournalStore) RecordProduction(goalID string, prod *Production) {
+	s.record(goalID, func(journal *GoalJournal, entry *JournalEntry) {
+		journal.Productions = appendUnique(journal.Productions, prod.ID)
+		entry.Kind = JournalEntryProduction
+		entry.Production = prod.ID
+		entry.Detail = prod.Name
+	})
+}
+
+// RecordAgent records an agent invoked for a goal.
+func (s *GoalJournalStore) RecordAgent(goalID, agent string) {
+	s.record(goalID, func(journal *GoalJournal, entry *JournalEntry) {
+		journal.Agents = appendUnique(journal.Agents, agent)
+		entry.Kind = JournalEntryAgent
+		entry.Agent = agent
+	})
+}
+
+// RecordImpasse records an impasse against its goal. Impasses without a
+// goal are not journaled.
+func (s *GoalJournalStore) RecordImpasse(imp *Impasse) {
+	if imp.GoalID == "" {
+		return
+	}
+	s.record(imp.GoalID, func(journal *GoalJournal, entry *JournalEntry) {
+		journal.Impasses = append(journal.Impasses, JournalImpasse{
+			ID:          imp.ID,
+			Type:        imp.Type.String(),
+			Description: imp.Description,
+		})
+		entry.Kind = JournalEntryImpasse
+		entry.Impasse = imp.ID
+		entry.Detail = imp.Description
+	})
+}
+
+// RecordSpend records tokens and cost spent on a goal.
+func (s *GoalJournalStore) RecordSpend(goalID string, tokens int64, cost float64) {
+	s.record(goalID, func(journal *GoalJournal, entry *JournalEntry) {
+		journal.TokensSpent += tokens
+		journal.CostSpent += cost
+		entry.Kind = JournalEntrySpend
+		entry.Tokens = tokens
+		entry.Cost = cost
+	})
+}
+
+// record adds an entry to a goal's journal, starting it if needed, and
+// saves the journal.
+func (s *GoalJournalStore) record(goalID string, update func(*GoalJournal, *JournalEntry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	journal, ok := s.journals[goalID]
+	if !ok {
+		s.prune(now)
+		journal = &GoalJournal{
+			GoalID:      goalID,
+			Agents:      []string{},
+			Productions: []string{},
+			Impasses:    []JournalImpasse{},
+			StartedAt:   now,
+			Entries:     []JournalEntry{},
+		}
+		s.journals[goalID] = journal
+	}
+
+	entry := JournalEntry{At: now}
+	update(journal, &entry)
+	journal.UpdatedAt = now
+	journal.Entries = append(journal.Entries, entry)
+	if over := len(journal.Entries) - maxJournalEntries; over > 0 {
+		journal.Entries = append(journal.Entries[:0], journal.Entries[over:]...)
+		journal.DroppedEntries += over
+	}
+
+	if err := s.write(journal); err != nil {
+		log.Printf("Error saving journal of goal %s: %v", goalID, err)
+	}
+}
+
+// prune deletes finished goals' journals past retention. Callers hold mu.
+func (s *GoalJournalStore) prune(now time.Time) {
+	for id, journal := range s.journals {
+		if !journal.Finished() || now.Sub(journal.UpdatedAt) < goalJournalRetention {
+			continue
+		}
+		delete(s.journals, id)
+		if s.dir != "" {
+			os.Remove(s.path(id))
+		}
+	}
+}
+
+// write saves a journal to its file atomically. Callers hold mu.
+func (s *GoalJournalStore) write(journal *GoalJournal) error {
+	if s.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return fmt.Errorf("failed to encode goal journal: %w", err)
+	}
+	path := s.path(journal.GoalID)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save goal journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save goal journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save goal journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save goal journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save goal journal: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of a goal's journal. Goal IDs are escaped, since
+// callers choose them.
+func (s *GoalJournalStore) path(goalID string) string {
+	return filepath.Join(s.dir, url.PathEscape(goalID)+".json")
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGoalJournal_RecordsGoalOutcome(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	journal := NewGoalJournalStore()
+	gs.SetJournal(journal)
+	detector := NewImpasseDetector(nil, gs)
+	ps := NewProductionSystem(nil, nil, gs, detector)
+
+	gs.Push(&Goal{ID: "review", Name: "Review the design", Budget: GoalBudget{MaxTokens: 100}})
+
+	prod := &Production{
+		Name:    "ask-architect",
+		Actions: []*Action{{Type: ActionInvokeAgent, AgentID: "ARCHITECT"}, {Type: ActionInvokeAgent, AgentID: "APEX"}},
+	}
+	ps.AddProduction(prod)
+	if err := ps.Fire(&MatchResult{Production: prod}); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	ps.Fire(&MatchResult{Production: prod})
+
+	detector.DetectTie("review", []string{"ARCHITECT", "APEX"}, []float64{0.5, 0.5})
+	if err := gs.Spend("review", 150, 0.25); !errors.Is(err, ErrGoalExhausted) {
+		t.Errorf("Expected the goal exhausted by its spend, got %v", err)
+	}
+	gs.Fail("review", "agents could not agree")
+
+	j, err := journal.Get("review")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if j.Name != "Review the design" || j.Status != "FAILED" || j.FailureReason != "agents could not agree" || !j.Finished() {
+		t.Errorf("Expected the failed goal's final state, got %+v", j)
+	}
+	if len(j.Productions) != 1 || j.Productions[0] != prod.ID {
+		t.Errorf("Expected the fired production once, got %v", j.Productions)
+	}
+	if len(j.Agents) != 2 || j.Agents[0] != "ARCHITECT" || j.Agents[1] != "APEX" {
+		t.Errorf("Expected the invoked agents in order, got %v", j.Agents)
+	}
+	// The tie, and the capacity impasse the exhausted budget raised
+	if len(j.Impasses) != 2 || j.Impasses[0].Type != "TIE" || j.Impasses[1].Type != "CAPACITY" {
+		t.Errorf("Expected the tie and capacity impasses, got %+v", j.Impasses)
+	}
+	if j.TokensSpent != 150 || j.CostSpent != 0.25 || j.Exhausted != LimitTokens {
+		t.Errorf("Expected 150 tokens and 0.25 spent past the token budget, got %d, %.2f, %q", j.TokensSpent, j.CostSpent, j.Exhausted)
+	}
+
+	var kinds []JournalEntryKind
+	for _, entry := range j.Entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	want := []JournalEntryKind{
+		JournalEntryStatus, JournalEntryStatus, // pushed, activated
+		JournalEntryProduction, JournalEntryAgent, JournalEntryAgent,
+		JournalEntryProduction, JournalEntryAgent, JournalEntryAgent,
+		JournalEntryImpasse, JournalEntrySpend, JournalEntryStatus, JournalEntryImpasse,
+		JournalEntryStatus, // failed
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected %d entries, got %v", len(want), kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Expected entry %d to be %s, got %s", i, want[i], kinds[i])
+		}
+	}
+	if last := j.Entries[len(j.Entries)-1]; last.Status != "FAILED" || last.Detail != "agents could not agree" {
+		t.Errorf("Expected the failure last, got %+v", last)
+	}
+
+	if _, err := journal.Get("missing"); !errors.Is(err, ErrJournalNotFound) {
+		t.Errorf("Expected ErrJournalNotFound, got %v", err)
+	}
+}
+
+func TestGoalJournal_Subgoals(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	journal := NewGoalJournalStore()
+	gs.SetJournal(journal)
+
+	gs.Push(&Goal{ID: "parent", Name: "Parent"})
+	gs.Decompose("parent", []*Goal{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}})
+	gs.Fail("a", "timed out")
+	gs.Fail("b", "timed out")
+
+	parent, err := journal.Get("parent")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if parent.Status != "FAILED" || parent.FailureReason != "subgoal b failed" {
+		t.Errorf("Expected the parent failed by its last subgoal, got %s: %s", parent.Status, parent.FailureReason)
+	}
+	sub, _ := journal.Get("a")
+	if sub.ParentID != "parent" || sub.Entries[0].Detail != "subgoal of parent" {
+		t.Errorf("Expected a journaled as a subgoal of parent, got %+v", sub)
+	}
+
+	failed := journal.List("failed", 0)
+	if len(failed) != 3 {
+		t.Errorf("Expected 3 failed journals, got %d", len(failed))
+	}
+	if len(failed) > 0 && len(failed[0].Entries) != 0 {
+		t.Errorf("Expected listings without entries, got %d", len(failed[0].Entries))
+	}
+	if listed := journal.List("", 1); len(listed) != 1 {
+		t.Errorf("Expected the limit applied, got %d", len(listed))
+	}
+}
+
+func TestGoalJournal_Persists(t *testing.T) {
+	dir := t.TempDir()
+	journal, err := OpenGoalJournalStore(dir)
+	if err != nil {
+		t.Fatalf("OpenGoalJournalStore failed: %v", err)
+	}
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	gs.SetJournal(journal)
+	gs.Push(&Goal{ID: "tenant/request-1", Name: "Answer"})
+	gs.Complete("tenant/request-1")
+	gs.Push(&Goal{ID: "old", Name: "Old"})
+	gs.Complete("old")
+
+	if _, err := os.Stat(filepath.Join(dir, "tenant%2Frequest-1.json")); err != nil {
+		t.Errorf("Expected the journal saved under its escaped goal ID: %v", err)
+	}
+
+	// Age the finished goal's journal past retention
+	path := filepath.Join(dir, "old.json")
+	journal.mu.Lock()
+	old := journal.journals["old"]
+	old.UpdatedAt = time.Now().Add(-goalJournalRetention - time.Hour)
+	err = journal.write(old)
+	journal.mu.Unlock()
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	reopened, err := OpenGoalJournalStore(dir)
+	if err != nil {
+		t.Fatalf("OpenGoalJournalStore failed: %v", err)
+	}
+	j, err := reopened.Get("tenant/request-1")
+	if err != nil {
+		t.Fatalf("Expected the journal to survive reopening: %v", err)
+	}
+	if j.Status != "COMPLETED" || len(j.Entries) != 3 {
+		t.Errorf("Expected the completed goal's 3 entries, got %s with %d", j.Status, len(j.Entries))
+	}
+	if _, err := reopened.Get("old"); !errors.Is(err, ErrJournalNotFound) {
+		t.Errorf("Expected the journal past retention pruned, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the pruned journal's file deleted, got %v", err)
+	}
+}
+
+func TestGoalJournal_BoundsEntries(t *testing.T) {
+	journal := NewGoalJournalStore()
+	for i := 0; i < maxJournalEntries+10; i++ {
+		journal.RecordAgent("busy", "APEX")
+	}
+	j, _ := journal.Get("busy")
+	if len(j.Entries) != maxJournalEntries || j.DroppedEntries != 10 {
+		t.Errorf("Expected %d entries with 10 dropped, got %d with %d", maxJournalEntries, len(j.Entries), j.DroppedEntries)
+	}
+	if len(j.Agents) != 1 {
+		t.Errorf("Expected APEX summarized once, got %v", j.Agents)
+	}
+}
@@ -7,15 +7,26 @@
 // - Priority-based ordering
 // - Subgoal creation during impasses
 // - Goal satisfaction tracking
+// - Dependencies between goals, which form a DAG: goals wait for the goals
+//   they depend on, and goals with nothing left to wait for can be worked
+//   on in parallel
+// - Deadlines and token and cost budgets, past which a goal is exhausted:
+//   it raises an impasse and is no longer worked on
+// - An optional journal of each goal's transitions and spending, for
+//   post-mortems
 
 package memory
 
 import (
-	"container/heap"
-	"errors"
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
 )
 
 // ============================================================================
@@ -24,16 +35,26 @@ import (
 
 var (
 	// ErrGoalNotFound indicates a goal was not found
-	ErrGoalNotFound = errors.New("goal not found")
+	ErrGoalNotFound = errdefs.New(errdefs.ErrNotFound, "goal not found")
 
 	// ErrGoalStackEmpty indicates the goal stack is empty
-	ErrGoalStackEmpty = errors.New("goal stack is empty")
+	ErrGoalStackEmpty = errdefs.New(errdefs.ErrNotFound, "goal stack is empty")
 
 	// ErrMaxDepthExceeded indicates goal decomposition is too deep
-	ErrMaxDepthExceeded = errors.New("maximum goal stack depth exceeded")
+	ErrMaxDepthExceeded = errdefs.New(errdefs.ErrCapacityExceeded, "maximum goal stack depth exceeded")
 
 	// ErrCircularDependency indicates a circular goal dependency
-	ErrCircularDependency = errors.New("circular goal dependency detected")
+	ErrCircularDependency = errdefs.New(errdefs.ErrInvalidArgument, "circular goal dependency detected")
+
+	// ErrUnknownDependency indicates a goal depends on a goal that does not exist
+	ErrUnknownDependency = errdefs.New(errdefs.ErrInvalidArgument, "goal depends on an unknown goal")
+
+	// ErrGoalBlocked indicates a goal is waiting on goals it depends on
+	ErrGoalBlocked = errdefs.New(errdefs.ErrConflict, "goal is waiting on its dependencies")
+
+	// ErrGoalExhausted indicates a goal is past its deadline or budget, so
+	// nothing more should be spent on it
+	ErrGoalExhausted = errdefs.New(errdefs.ErrCapacityExceeded, "goal is past its deadline or budget")
 )
 
 // ============================================================================
@@ -97,6 +118,39 @@ const (
 	PriorityCritical GoalPriority = 10
 )
 
+// ============================================================================
+// Goal Limits
+// ============================================================================
+
+// GoalBudget limits what may be spent on a goal. Zero limits are unlimited.
+type GoalBudget struct {
+	// MaxTokens limits the LLM tokens spent
+	MaxTokens int64
+
+	// MaxCost limits the cost spent, in the caller's currency
+	MaxCost float64
+}
+
+// GoalSpend is what has been spent on a goal.
+type GoalSpend struct {
+	Tokens int64
+	Cost   float64
+}
+
+// GoalLimit names the limit an exhausted goal ran past.
+type GoalLimit string
+
+const (
+	// LimitDeadline is the goal's deadline
+	LimitDeadline GoalLimit = "deadline"
+
+	// LimitTokens is the goal's token budget
+	LimitTokens GoalLimit = "tokens"
+
+	// LimitCost is the goal's cost budget
+	LimitCost GoalLimit = "cost"
+)
+
 // ============================================================================
 // Goal
 // ============================================================================
@@ -124,7 +178,8 @@ type Goal struct {
 	// SubGoalIDs are child goals created from decomposition
 	SubGoalIDs []string
 
-	// Dependencies are goal IDs that must complete first
+	// Dependencies are goal IDs that must complete first; subgoals may
+	// depend on their siblings
 	Dependencies []string
 
 	// CreatedAt timestamp
@@ -139,6 +194,16 @@ type Goal struct {
 	// Deadline is an optional deadline
 	Deadline *time.Time
 
+	// Budget limits what may be spent on the goal
+	Budget GoalBudget
+
+	// Spent is what has been spent on the goal so far
+	Spent GoalSpend
+
+	// Exhausted is the limit the goal ran past, if any; an exhausted goal
+	// is not worked on until it is finished or its limits are raised
+	Exhausted GoalLimit
+
 	// Context holds goal-specific data
 	Context map[string]interface{}
 
@@ -174,49 +239,39 @@ func (g *Goal) IsTerminal() bool {
 
 // IsActionable returns true if the goal can be worked on.
 func (g *Goal) IsActionable() bool {
-	return g.Status == GoalPending || g.Status == GoalActive
+	return (g.Status == GoalPending || g.Status == GoalActive) && g.Exhausted == ""
+}
+
+// exceeded returns the first limit the goal is past at now, if any.
+func (g *Goal) exceeded(now time.Time) GoalLimit {
+	switch {
+	case g.Deadline != nil && now.After(*g.Deadline):
+		return LimitDeadline
+	case g.Budget.MaxTokens > 0 && g.Spent.Tokens > g.Budget.MaxTokens:
+		return LimitTokens
+	case g.Budget.MaxCost > 0 && g.Spent.Cost > g.Budget.MaxCost:
+		return LimitCost
+	}
+	return ""
 }
 
 // ============================================================================
 // Goal Priority Queue
 // ============================================================================
 
-// goalPriorityQueue implements heap.Interface for priority-based ordering.
-type goalPriorityQueue []*Goal
-
-func (pq goalPriorityQueue) Len() int { return len(pq) }
-
-// Less returns true if i has higher priority than j.
-// Higher priority value = higher priority.
-// Ties are broken by creation time (earlier = higher priority).
-func (pq goalPriorityQueue) Less(i, j int) bool {
-	if pq[i].Priority != pq[j].Priority {
-		return pq[i].Priority > pq[j].Priority
+// goalFirst orders goals highest priority first, with ties broken by
+// creation time (earlier first).
+func goalFirst(a, b *Goal) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
 	}
-	return pq[i].CreatedAt.Before(pq[j].CreatedAt)
-}
-
-func (pq goalPriorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
-}
-
-func (pq *goalPriorityQueue) Push(x interface{}) {
-	n := len(*pq)
-	goal := x.(*Goal)
-	goal.index = n
-	*pq = append(*pq, goal)
+	return a.CreatedAt.Before(b.CreatedAt)
 }
 
-func (pq *goalPriorityQueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	goal := old[n-1]
-	old[n-1] = nil
-	goal.index = -1
-	*pq = old[0 : n-1]
-	return goal
+// newGoalQueue creates the queue of pending and active goals in goalFirst
+// order.
+func newGoalQueue() *pqueue.Queue[*Goal] {
+	return pqueue.NewIndexed(goalFirst, func(goal *Goal, index int) { goal.index = index })
 }
 
 // ============================================================================
@@ -231,7 +286,7 @@ type GoalStack struct {
 	goals map[string]*Goal
 
 	// activeQueue holds pending/active goals in priority order
-	activeQueue goalPriorityQueue
+	activeQueue *pqueue.Queue[*Goal]
 
 	// suspendedGoals holds suspended goals
 	suspendedGoals map[string]*Goal
@@ -251,11 +306,15 @@ type GoalStack struct {
 	// stats tracks goal stack statistics
 	stats *GoalStackStats
 
+	// journal records what happens to each goal, if set
+	journal *GoalJournalStore
+
 	// callbacks
 	onGoalActivated func(*Goal)
 	onGoalCompleted func(*Goal)
 	onGoalFailed    func(*Goal)
 	onGoalSuspended func(*Goal)
+	onGoalExhausted func(*Goal)
 }
 
 // GoalStackStats tracks goal processing statistics.
@@ -286,15 +345,13 @@ func DefaultGoalStackConfig() GoalStackConfig {
 func NewGoalStack(config GoalStackConfig) *GoalStack {
 	gs := &GoalStack{
 		goals:          make(map[string]*Goal),
-		activeQueue:    make(goalPriorityQueue, 0),
+		activeQueue:    newGoalQueue(),
 		suspendedGoals: make(map[string]*Goal),
 		completedGoals: make(map[string]*Goal),
 		maxDepth:       config.MaxDepth,
 		maxGoals:       config.MaxGoals,
 		stats:          &GoalStackStats{},
 	}
-
-	heap.Init(&gs.activeQueue)
 	return gs
 }
 
@@ -317,6 +374,10 @@ func (gs *GoalStack) Push(goal *Goal) error {
 		return ErrMaxDepthExceeded
 	}
 
+	if err := gs.validateDependenciesLocked([]*Goal{goal}); err != nil {
+		return err
+	}
+
 	// Initialize goal
 	now := time.Now()
 	goal.CreatedAt = now
@@ -335,10 +396,11 @@ func (gs *GoalStack) Push(goal *Goal) error {
 
 	// Store goal
 	gs.goals[goal.ID] = goal
+	gs.journalGoalLocked(goal, "pushed")
 
 	// Add to active queue if pending
 	if goal.Status == GoalPending {
-		heap.Push(&gs.activeQueue, goal)
+		gs.activeQueue.Push(goal)
 	}
 
 	gs.stats.TotalGoalsCreated++
@@ -349,23 +411,25 @@ func (gs *GoalStack) Push(goal *Goal) error {
 	}
 
 	// Set as current if none active
-	if gs.currentGoal == nil && goal.Status == GoalPending {
+	if gs.currentGoal == nil && goal.Status == GoalPending && gs.dependenciesMetLocked(goal) {
 		gs.activateGoalLocked(goal)
 	}
 
 	return nil
 }
 
-// Pop removes and returns the highest-priority goal.
+// Pop removes and returns the highest-priority goal that can be worked on,
+// as Ready orders them. It returns ErrGoalBlocked if every goal is waiting
+// on others or exhausted.
 func (gs *GoalStack) Pop() (*Goal, error) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
-	if len(gs.activeQueue) == 0 {
-		return nil, ErrGoalStackEmpty
+	goal, err := gs.nextReadyLocked()
+	if err != nil {
+		return nil, err
 	}
-
-	goal := heap.Pop(&gs.activeQueue).(*Goal)
+	gs.activeQueue.Remove(goal.index)
 
 	// Clear current goal if it was popped
 	if gs.currentGoal != nil && gs.currentGoal.ID == goal.ID {
@@ -375,16 +439,13 @@ func (gs *GoalStack) Pop() (*Goal, error) {
 	return goal, nil
 }
 
-// Peek returns the highest-priority goal without removing it.
+// Peek returns the highest-priority goal that can be worked on without
+// removing it.
 func (gs *GoalStack) Peek() (*Goal, error) {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
 
-	if len(gs.activeQueue) == 0 {
-		return nil, ErrGoalStackEmpty
-	}
-
-	return gs.activeQueue[0], nil
+	return gs.nextReadyLocked()
 }
 
 // Get retrieves a goal by ID.
@@ -428,6 +489,12 @@ func (gs *GoalStack) Activate(id string) error {
 	if goal.IsTerminal() {
 		return fmt.Errorf("cannot activate terminal goal")
 	}
+	if blocked := gs.blockedByLocked(goal); len(blocked) > 0 {
+		return fmt.Errorf("%w: %s waits on %s", ErrGoalBlocked, id, strings.Join(blocked, ", "))
+	}
+	if err := goalExhaustedError(goal); err != nil {
+		return err
+	}
 
 	// Suspend current goal if different
 	if gs.currentGoal != nil && gs.currentGoal.ID != id {
@@ -448,6 +515,7 @@ func (gs *GoalStack) activateGoalLocked(goal *Goal) {
 	delete(gs.suspendedGoals, goal.ID)
 
 	gs.currentGoal = goal
+	gs.journalGoalLocked(goal, "activated")
 
 	if gs.onGoalActivated != nil {
 		gs.onGoalActivated(goal)
@@ -470,8 +538,8 @@ func (gs *GoalStack) Complete(id string) error {
 	goal.Progress = 1.0
 
 	// Remove from active queue
-	if goal.index >= 0 && goal.index < len(gs.activeQueue) {
-		heap.Remove(&gs.activeQueue, goal.index)
+	if goal.index >= 0 && goal.index < gs.activeQueue.Len() {
+		gs.activeQueue.Remove(goal.index)
 	}
 
 	// Move to completed
@@ -479,10 +547,14 @@ func (gs *GoalStack) Complete(id string) error {
 	gs.completedGoals[id] = goal
 
 	gs.stats.TotalGoalsCompleted++
+	gs.journalGoalLocked(goal, "completed")
 
-	// Clear current if this was it
+	// Clear current if this was it; goals waiting on this one may now be
+	// ready to take its place
 	if gs.currentGoal != nil && gs.currentGoal.ID == id {
 		gs.currentGoal = nil
+	}
+	if gs.currentGoal == nil {
 		gs.selectNextGoalLocked()
 	}
 
@@ -498,7 +570,8 @@ func (gs *GoalStack) Complete(id string) error {
 	return nil
 }
 
-// Fail marks a goal as failed.
+// Fail marks a goal as failed. Goals depending on it can no longer run,
+// so they fail too.
 func (gs *GoalStack) Fail(id string, reason string) error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
@@ -508,21 +581,30 @@ func (gs *GoalStack) Fail(id string, reason string) error {
 		return ErrGoalNotFound
 	}
 
+	gs.failGoalLocked(goal, reason)
+	return nil
+}
+
+// failGoalLocked fails a goal and the goals depending on it. Must hold lock.
+func (gs *GoalStack) failGoalLocked(goal *Goal, reason string) {
+	id := goal.ID
 	now := time.Now()
 	goal.Status = GoalFailed
 	goal.CompletedAt = &now
 	goal.FailureReason = reason
 
 	// Remove from active queue
-	if goal.index >= 0 && goal.index < len(gs.activeQueue) {
-		heap.Remove(&gs.activeQueue, goal.index)
+	if goal.index >= 0 && goal.index < gs.activeQueue.Len() {
+		gs.activeQueue.Remove(goal.index)
 	}
 
 	// Move to completed (as failed)
 	delete(gs.goals, id)
+	delete(gs.suspendedGoals, id)
 	gs.completedGoals[id] = goal
 
 	gs.stats.TotalGoalsFailed++
+	gs.journalGoalLocked(goal, reason)
 
 	// Clear current if this was it
 	if gs.currentGoal != nil && gs.currentGoal.ID == id {
@@ -539,7 +621,11 @@ func (gs *GoalStack) Fail(id string, reason string) error {
 		gs.onGoalFailed(goal)
 	}
 
-	return nil
+	for _, dependent := range gs.dependentsLocked(id) {
+		if _, ok := gs.goals[dependent.ID]; ok {
+			gs.failGoalLocked(dependent, fmt.Sprintf("dependency %s failed", id))
+		}
+	}
 }
 
 // Suspend pauses a goal.
@@ -562,13 +648,14 @@ func (gs *GoalStack) suspendGoalLocked(goal *Goal, reason string) {
 	goal.SuspensionReason = reason
 
 	// Remove from active queue
-	if goal.index >= 0 && goal.index < len(gs.activeQueue) {
-		heap.Remove(&gs.activeQueue, goal.index)
+	if goal.index >= 0 && goal.index < gs.activeQueue.Len() {
+		gs.activeQueue.Remove(goal.index)
 	}
 
 	gs.suspendedGoals[goal.ID] = goal
 
 	gs.stats.TotalGoalsSuspended++
+	gs.journalGoalLocked(goal, reason)
 
 	// Clear current if this was it
 	if gs.currentGoal != nil && gs.currentGoal.ID == goal.ID {
@@ -594,7 +681,8 @@ func (gs *GoalStack) Resume(id string) error {
 	goal.SuspensionReason = ""
 
 	delete(gs.suspendedGoals, id)
-	heap.Push(&gs.activeQueue, goal)
+	gs.activeQueue.Push(goal)
+	gs.journalGoalLocked(goal, "resumed")
 
 	return nil
 }
@@ -603,7 +691,9 @@ func (gs *GoalStack) Resume(id string) error {
 // Goal Decomposition
 // ============================================================================
 
-// Decompose breaks a goal into subgoals.
+// Decompose breaks a goal into subgoals. Subgoals may depend on each other
+// and on existing goals; subgoals with no dependencies between them can be
+// worked on in parallel.
 func (gs *GoalStack) Decompose(parentID string, subgoals []*Goal) error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
@@ -618,6 +708,10 @@ func (gs *GoalStack) Decompose(parentID string, subgoals []*Goal) error {
 		return ErrMaxDepthExceeded
 	}
 
+	if err := gs.validateDependenciesLocked(subgoals); err != nil {
+		return err
+	}
+
 	// Mark parent as decomposed
 	parent.Status = GoalDecomposed
 
@@ -639,7 +733,8 @@ func (gs *GoalStack) Decompose(parentID string, subgoals []*Goal) error {
 		}
 
 		gs.goals[sg.ID] = sg
-		heap.Push(&gs.activeQueue, sg)
+		gs.activeQueue.Push(sg)
+		gs.journalGoalLocked(sg, "subgoal of "+parentID)
 		subgoalIDs = append(subgoalIDs, sg.ID)
 
 		gs.stats.TotalGoalsCreated++
@@ -670,18 +765,21 @@ func (gs *GoalStack) CreateSubgoal(parentID string, subgoal *Goal) error {
 
 // selectNextGoalLocked selects the next goal to work on. Must hold lock.
 func (gs *GoalStack) selectNextGoalLocked() {
-	if len(gs.activeQueue) == 0 {
+	if gs.activeQueue.Len() == 0 {
 		gs.currentGoal = nil
 		return
 	}
 
-	// Get highest priority pending goal
-	for _, goal := range gs.activeQueue {
-		if goal.Status == GoalPending {
-			gs.activateGoalLocked(goal)
-			return
+	// Get highest priority pending goal whose dependencies are met
+	var next *Goal
+	for _, goal := range gs.activeQueue.Items() {
+		if goal.Status == GoalPending && goal.Exhausted == "" && gs.dependenciesMetLocked(goal) && (next == nil || goalFirst(goal, next)) {
+			next = goal
 		}
 	}
+	if next != nil {
+		gs.activateGoalLocked(next)
+	}
 }
 
 // checkParentCompletionLocked checks if all subgoals are complete. Must hold lock.
@@ -713,6 +811,7 @@ func (gs *GoalStack) checkParentCompletionLocked(parentID string) {
 		gs.completedGoals[parentID] = parent
 
 		gs.stats.TotalGoalsCompleted++
+		gs.journalGoalLocked(parent, "all subgoals completed")
 
 		if gs.onGoalCompleted != nil {
 			gs.onGoalCompleted(parent)
@@ -760,6 +859,7 @@ func (gs *GoalStack) handleSubgoalFailureLocked(parentID string, failedSubgoalID
 		gs.completedGoals[parentID] = parent
 
 		gs.stats.TotalGoalsFailed++
+		gs.journalGoalLocked(parent, parent.FailureReason)
 
 		if gs.onGoalFailed != nil {
 			gs.onGoalFailed(parent)
@@ -772,6 +872,299 @@ func (gs *GoalStack) handleSubgoalFailureLocked(parentID string, failedSubgoalID
 	}
 }
 
+// ============================================================================
+// Goal Dependencies
+// ============================================================================
+
+// validateDependenciesLocked checks that the goals being added depend only
+// on known goals, or each other, and that no dependency cycle results. Must
+// hold lock.
+func (gs *GoalStack) validateDependenciesLocked(added []*Goal) error {
+	batch := make(map[string]*Goal, len(added))
+	for _, goal := range added {
+		batch[goal.ID] = goal
+	}
+	for _, goal := range added {
+		for _, dep := range goal.Dependencies {
+			if _, ok := batch[dep]; ok {
+				continue
+			}
+			known := gs.lookupLocked(dep)
+			if known == nil {
+				return fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, goal.ID, dep)
+			}
+			if known.Status == GoalFailed {
+				return fmt.Errorf("%w: %s depends on %s, which failed", ErrGoalBlocked, goal.ID, dep)
+			}
+		}
+	}
+
+	// Depth-first search for a goal reached again while its own
+	// dependencies are being visited. Finished goals cannot wait on
+	// anything, so the search stops at them
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			for i, p := range path {
+				if p == id {
+					return append(append([]string(nil), path[i:]...), id)
+				}
+			}
+		case visited:
+			return nil
+		}
+		goal, ok := batch[id]
+		if !ok {
+			goal = gs.goals[id]
+		}
+		if goal == nil {
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range goal.Dependencies {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, goal := range added {
+		if cycle := visit(goal.ID); cycle != nil {
+			return fmt.Errorf("%w: %s", ErrCircularDependency, strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// lookupLocked returns a goal in any state, or nil. Must hold lock.
+func (gs *GoalStack) lookupLocked(id string) *Goal {
+	if goal, ok := gs.goals[id]; ok {
+		return goal
+	}
+	if goal, ok := gs.suspendedGoals[id]; ok {
+		return goal
+	}
+	return gs.completedGoals[id]
+}
+
+// blockedByLocked returns the dependencies of a goal that have not
+// completed. Must hold lock.
+func (gs *GoalStack) blockedByLocked(goal *Goal) []string {
+	var blocked []string
+	for _, dep := range goal.Dependencies {
+		if done, ok := gs.completedGoals[dep]; !ok || done.Status != GoalCompleted {
+			blocked = append(blocked, dep)
+		}
+	}
+	return blocked
+}
+
+// dependenciesMetLocked reports whether every dependency of a goal has
+// completed. Must hold lock.
+func (gs *GoalStack) dependenciesMetLocked(goal *Goal) bool {
+	return len(gs.blockedByLocked(goal)) == 0
+}
+
+// dependentsLocked returns the unfinished goals depending on a goal. Must
+// hold lock.
+func (gs *GoalStack) dependentsLocked(id string) []*Goal {
+	var dependents []*Goal
+	for _, goal := range gs.goals {
+		for _, dep := range goal.Dependencies {
+			if dep == id {
+				dependents = append(dependents, goal)
+				break
+			}
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return goalFirst(dependents[i], dependents[j]) })
+	return dependents
+}
+
+// readyLocked returns the queued goals whose dependencies are met, in
+// priority order. Must hold lock.
+func (gs *GoalStack) readyLocked() []*Goal {
+	ready := make([]*Goal, 0)
+	for _, goal := range gs.activeQueue.Items() {
+		if goal.IsActionable() && gs.dependenciesMetLocked(goal) {
+			ready = append(ready, goal)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return goalFirst(ready[i], ready[j]) })
+	return ready
+}
+
+// nextReadyLocked returns the highest-priority goal that can be worked on.
+// Must hold lock.
+func (gs *GoalStack) nextReadyLocked() (*Goal, error) {
+	if gs.activeQueue.Len() == 0 {
+		return nil, ErrGoalStackEmpty
+	}
+	if goal, _ := gs.activeQueue.Peek(); goal.IsActionable() && gs.dependenciesMetLocked(goal) {
+		return goal, nil
+	}
+	ready := gs.readyLocked()
+	if len(ready) == 0 {
+		return nil, fmt.Errorf("%w: all %d goals are waiting or exhausted", ErrGoalBlocked, gs.activeQueue.Len())
+	}
+	return ready[0], nil
+}
+
+// Ready returns the goals that can be worked on now, highest priority
+// first: those pending or active, within their limits, whose dependencies
+// have all completed. None of them waits on another, so they can run in
+// parallel.
+func (gs *GoalStack) Ready() []*Goal {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.readyLocked()
+}
+
+// BlockedBy returns the dependencies of a goal that have not completed.
+func (gs *GoalStack) BlockedBy(id string) ([]string, error) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	goal := gs.lookupLocked(id)
+	if goal == nil {
+		return nil, ErrGoalNotFound
+	}
+	return gs.blockedByLocked(goal), nil
+}
+
+// ============================================================================
+// Deadlines and Budgets
+// ============================================================================
+
+// Spend records tokens and cost spent on a goal. It returns
+// ErrGoalExhausted once the goal is past its deadline or budget, so the
+// caller stops spending on it; the spend is recorded either way.
+func (gs *GoalStack) Spend(id string, tokens int64, cost float64) error {
+	gs.mu.Lock()
+	goal, ok := gs.goals[id]
+	if !ok {
+		gs.mu.Unlock()
+		return ErrGoalNotFound
+	}
+	goal.Spent.Tokens += tokens
+	goal.Spent.Cost += cost
+	if gs.journal != nil {
+		gs.journal.RecordSpend(id, tokens, cost)
+	}
+	exhausted := gs.enforceLocked(goal, time.Now())
+	err := goalExhaustedError(goal)
+	gs.mu.Unlock()
+
+	gs.notifyExhausted(exhausted)
+	return err
+}
+
+// Allow reports whether more may be spent on a goal: it returns
+// ErrGoalExhausted for goals past their deadline or budget.
+func (gs *GoalStack) Allow(id string) error {
+	gs.mu.Lock()
+	goal, ok := gs.goals[id]
+	if !ok {
+		gs.mu.Unlock()
+		return ErrGoalNotFound
+	}
+	exhausted := gs.enforceLocked(goal, time.Now())
+	err := goalExhaustedError(goal)
+	gs.mu.Unlock()
+
+	gs.notifyExhausted(exhausted)
+	return err
+}
+
+// SweepDeadlines marks the unfinished goals past their deadline exhausted
+// and returns copies of them. Budgets are enforced as they are spent; deadlines pass
+// on their own, so they are swept.
+func (gs *GoalStack) SweepDeadlines() []*Goal {
+	gs.mu.Lock()
+	now := time.Now()
+	var exhausted []*Goal
+	for _, goal := range gs.goals {
+		exhausted = append(exhausted, gs.enforceLocked(goal, now)...)
+	}
+	gs.mu.Unlock()
+
+	sort.Slice(exhausted, func(i, j int) bool { return goalFirst(exhausted[i], exhausted[j]) })
+	gs.notifyExhausted(exhausted)
+	return exhausted
+}
+
+// WatchDeadlines sweeps deadlines every interval until ctx is done.
+func (gs *GoalStack) WatchDeadlines(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			gs.SweepDeadlines()
+		}
+	}
+}
+
+// enforceLocked marks a goal exhausted if it has just run past a limit,
+// taking it out of focus, and returns a copy of it to be notified, which
+// can be read without the lock. Must hold lock.
+func (gs *GoalStack) enforceLocked(goal *Goal, now time.Time) []*Goal {
+	if goal.Exhausted != "" || goal.IsTerminal() {
+		return nil
+	}
+	limit := goal.exceeded(now)
+	if limit == "" {
+		return nil
+	}
+	goal.Exhausted = limit
+	gs.journalGoalLocked(goal, "ran past its "+string(limit))
+	if gs.currentGoal != nil && gs.currentGoal.ID == goal.ID {
+		gs.currentGoal = nil
+		gs.selectNextGoalLocked()
+	}
+	exhausted := *goal
+	return []*Goal{&exhausted}
+}
+
+// notifyExhausted calls the exhausted callback for goals. Must not hold
+// lock.
+func (gs *GoalStack) notifyExhausted(exhausted []*Goal) {
+	if len(exhausted) == 0 {
+		return
+	}
+	gs.mu.RLock()
+	fn := gs.onGoalExhausted
+	gs.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	for _, goal := range exhausted {
+		fn(goal)
+	}
+}
+
+// goalExhaustedError returns ErrGoalExhausted naming the limit a goal ran
+// past, or nil. Must hold lock.
+func goalExhaustedError(goal *Goal) error {
+	if goal.Exhausted == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s ran past its %s", ErrGoalExhausted, goal.ID, goal.Exhausted)
+}
+
 // ============================================================================
 // Query Operations
 // ============================================================================
@@ -780,7 +1173,7 @@ func (gs *GoalStack) handleSubgoalFailureLocked(parentID string, failedSubgoalID
 func (gs *GoalStack) Size() int {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-	return len(gs.activeQueue)
+	return gs.activeQueue.Len()
 }
 
 // TotalSize returns the total number of goals (active + suspended).
@@ -794,7 +1187,7 @@ func (gs *GoalStack) TotalSize() int {
 func (gs *GoalStack) IsEmpty() bool {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-	return len(gs.activeQueue) == 0
+	return gs.activeQueue.Len() == 0
 }
 
 // GetByStatus returns goals with a specific status.
@@ -932,7 +1325,7 @@ func (gs *GoalStack) SetPriority(id string, priority GoalPriority) error {
 
 	// Reheap to fix ordering
 	if goal.index >= 0 {
-		heap.Fix(&gs.activeQueue, goal.index)
+		gs.activeQueue.Fix(goal.index)
 	}
 
 	return nil
@@ -970,6 +1363,44 @@ func (gs *GoalStack) OnGoalSuspended(fn func(*Goal)) {
 	gs.onGoalSuspended = fn
 }
 
+// OnGoalExhausted sets callback for a goal running past its deadline or
+// budget. Unlike the other callbacks it is called without the stack
+// locked, so it may act on the goal.
+func (gs *GoalStack) OnGoalExhausted(fn func(*Goal)) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.onGoalExhausted = fn
+}
+
+// ============================================================================
+// Journal
+// ============================================================================
+
+// SetJournal sets the store each goal's transitions and spending are
+// recorded in, along with the impasses and productions reported against
+// goals on the stack. Nil stops journaling.
+func (gs *GoalStack) SetJournal(journal *GoalJournalStore) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.journal = journal
+}
+
+// Journal returns the stack's journal store, or nil if goals are not
+// journaled.
+func (gs *GoalStack) Journal() *GoalJournalStore {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.journal
+}
+
+// journalGoalLocked records a goal's status in the journal, if any. Must
+// hold lock.
+func (gs *GoalStack) journalGoalLocked(goal *Goal, detail string) {
+	if gs.journal != nil {
+		gs.journal.RecordGoal(goal, detail)
+	}
+}
+
 // ============================================================================
 // Statistics
 // ============================================================================
@@ -997,6 +1428,11 @@ type GoalStackSnapshot struct {
 	CurrentGoalID   string
 	Goals           []*Goal
 	MaxDepthReached int
+	// ReadyGoalIDs are the goals that can be worked on in parallel now,
+	// highest priority first
+	ReadyGoalIDs []string
+	// BlockedBy holds each waiting goal's unfinished dependencies
+	BlockedBy map[string][]string
 }
 
 // Snapshot returns current state for debugging/monitoring.
@@ -1006,11 +1442,13 @@ func (gs *GoalStack) Snapshot() *GoalStackSnapshot {
 
 	snapshot := &GoalStackSnapshot{
 		Timestamp:       time.Now(),
-		ActiveCount:     len(gs.activeQueue),
+		ActiveCount:     gs.activeQueue.Len(),
 		SuspendedCount:  len(gs.suspendedGoals),
 		CompletedCount:  len(gs.completedGoals),
 		Goals:           make([]*Goal, 0, len(gs.goals)),
 		MaxDepthReached: gs.stats.MaxDepthReached,
+		ReadyGoalIDs:    make([]string, 0),
+		BlockedBy:       make(map[string][]string),
 	}
 
 	if gs.currentGoal != nil {
@@ -1019,6 +1457,12 @@ func (gs *GoalStack) Snapshot() *GoalStackSnapshot {
 
 	for _, goal := range gs.goals {
 		snapshot.Goals = append(snapshot.Goals, goal)
+		if blocked := gs.blockedByLocked(goal); len(blocked) > 0 {
+			snapshot.BlockedBy[goal.ID] = blocked
+		}
+	}
+	for _, goal := range gs.readyLocked() {
+		snapshot.ReadyGoalIDs = append(snapshot.ReadyGoalIDs, goal.ID)
 	}
 
 	return snapshot
@@ -1034,10 +1478,8 @@ func (gs *GoalStack) Clear() {
 	defer gs.mu.Unlock()
 
 	gs.goals = make(map[string]*Goal)
-	gs.activeQueue = make(goalPriorityQueue, 0)
+	gs.activeQueue.Reset()
 	gs.suspendedGoals = make(map[string]*Goal)
 	gs.completedGoals = make(map[string]*Goal)
 	gs.currentGoal = nil
-
-	heap.Init(&gs.activeQueue)
 }
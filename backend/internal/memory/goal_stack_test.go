@@ -1,7 +1,9 @@
 package memory
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 // ============================================================================
@@ -294,6 +296,222 @@ func TestGoalStack_MaxDepth(t *testing.T) {
 	}
 }
 
+func TestGoalStack_DependenciesOrderSiblings(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	gs.Push(&Goal{ID: "release", Name: "Release"})
+
+	// build and docs can run in parallel; ship waits on both, even though
+	// it has the highest priority
+	err := gs.Decompose("release", []*Goal{
+		{ID: "ship", Name: "Ship", Priority: PriorityCritical, Dependencies: []string{"build", "docs"}},
+		{ID: "build", Name: "Build", Priority: PriorityHigh},
+		{ID: "docs", Name: "Docs", Priority: PriorityNormal},
+	})
+	if err != nil {
+		t.Fatalf("Decompose failed: %v", err)
+	}
+
+	if ids := goalIDs(gs.Ready()); len(ids) != 2 || ids[0] != "build" || ids[1] != "docs" {
+		t.Errorf("Expected build and docs ready, got %v", ids)
+	}
+	if current := gs.Current(); current == nil || current.ID != "build" {
+		t.Errorf("Expected build activated, got %+v", current)
+	}
+	if next, _ := gs.Peek(); next.ID != "build" {
+		t.Errorf("Expected Peek to skip the blocked goal, got %s", next.ID)
+	}
+	if blocked, _ := gs.BlockedBy("ship"); len(blocked) != 2 {
+		t.Errorf("Expected ship blocked by 2 goals, got %v", blocked)
+	}
+	if err := gs.Activate("ship"); !errors.Is(err, ErrGoalBlocked) {
+		t.Errorf("Expected ErrGoalBlocked activating ship, got %v", err)
+	}
+
+	gs.Complete("build")
+	gs.Complete("docs")
+	if ids := goalIDs(gs.Ready()); len(ids) != 1 || ids[0] != "ship" {
+		t.Errorf("Expected ship ready once its dependencies completed, got %v", ids)
+	}
+	if current := gs.Current(); current == nil || current.ID != "ship" {
+		t.Errorf("Expected ship activated, got %+v", current)
+	}
+
+	gs.Complete("ship")
+	if parent, _ := gs.Get("release"); parent.Status != GoalCompleted {
+		t.Errorf("Expected release COMPLETED, got %s", parent.Status)
+	}
+}
+
+func TestGoalStack_PopBlocked(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	gs.Push(&Goal{ID: "first", Name: "First"})
+	gs.Push(&Goal{ID: "second", Name: "Second", Priority: PriorityHigh, Dependencies: []string{"first"}})
+
+	goal, err := gs.Pop()
+	if err != nil || goal.ID != "first" {
+		t.Fatalf("Expected first popped ahead of the higher-priority blocked goal, got %+v, %v", goal, err)
+	}
+	if _, err := gs.Pop(); !errors.Is(err, ErrGoalBlocked) {
+		t.Errorf("Expected ErrGoalBlocked with only blocked goals left, got %v", err)
+	}
+}
+
+func TestGoalStack_DependencyValidation(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+
+	if err := gs.Push(&Goal{ID: "orphan", Dependencies: []string{"missing"}}); !errors.Is(err, ErrUnknownDependency) {
+		t.Errorf("Expected ErrUnknownDependency, got %v", err)
+	}
+	if err := gs.Push(&Goal{ID: "self", Dependencies: []string{"self"}}); !errors.Is(err, ErrCircularDependency) {
+		t.Errorf("Expected a goal depending on itself rejected, got %v", err)
+	}
+
+	gs.Push(&Goal{ID: "parent"})
+	err := gs.Decompose("parent", []*Goal{
+		{ID: "a", Dependencies: []string{"c"}},
+		{ID: "b", Dependencies: []string{"a"}},
+		{ID: "c", Dependencies: []string{"b"}},
+	})
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("Expected ErrCircularDependency, got %v", err)
+	}
+	if err.Error() != "circular goal dependency detected: a -> c -> b -> a" {
+		t.Errorf("Expected the cycle in the error, got %q", err.Error())
+	}
+	if parent, _ := gs.Get("parent"); parent.Status == GoalSuspended || gs.TotalSize() != 1 {
+		t.Errorf("Expected a rejected decomposition to change nothing, got parent %s and %d goals", parent.Status, gs.TotalSize())
+	}
+
+	// Re-pushing a goal can close a cycle through the goals already there
+	gs.Push(&Goal{ID: "x"})
+	gs.Push(&Goal{ID: "y", Dependencies: []string{"x"}})
+	if err := gs.Push(&Goal{ID: "x", Dependencies: []string{"y"}}); !errors.Is(err, ErrCircularDependency) {
+		t.Errorf("Expected ErrCircularDependency re-pushing x, got %v", err)
+	}
+}
+
+func TestGoalStack_DependencyFailureCascades(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	gs.Push(&Goal{ID: "fetch"})
+	gs.Push(&Goal{ID: "parse", Dependencies: []string{"fetch"}})
+	gs.Push(&Goal{ID: "report", Dependencies: []string{"parse"}})
+	gs.Push(&Goal{ID: "unrelated"})
+
+	gs.Fail("fetch", "network down")
+
+	for _, id := range []string{"parse", "report"} {
+		goal, _ := gs.Get(id)
+		if goal.Status != GoalFailed {
+			t.Errorf("Expected %s FAILED with its dependency, got %s", id, goal.Status)
+		}
+	}
+	if parse, _ := gs.Get("parse"); parse.FailureReason != "dependency fetch failed" {
+		t.Errorf("Expected the failed dependency as the reason, got %q", parse.FailureReason)
+	}
+	if ids := goalIDs(gs.Ready()); len(ids) != 1 || ids[0] != "unrelated" {
+		t.Errorf("Expected only the unrelated goal left, got %v", ids)
+	}
+	if err := gs.Push(&Goal{ID: "late", Dependencies: []string{"fetch"}}); !errors.Is(err, ErrGoalBlocked) {
+		t.Errorf("Expected a dependency on a failed goal rejected, got %v", err)
+	}
+}
+
+func TestGoalStack_SnapshotDependencies(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	gs.Push(&Goal{ID: "a"})
+	gs.Push(&Goal{ID: "b"})
+	gs.Push(&Goal{ID: "c", Dependencies: []string{"a", "b"}})
+	gs.Complete("a")
+
+	snapshot := gs.Snapshot()
+	if len(snapshot.ReadyGoalIDs) != 1 || snapshot.ReadyGoalIDs[0] != "b" {
+		t.Errorf("Expected b ready, got %v", snapshot.ReadyGoalIDs)
+	}
+	if blocked := snapshot.BlockedBy["c"]; len(blocked) != 1 || blocked[0] != "b" {
+		t.Errorf("Expected c blocked by b, got %v", blocked)
+	}
+}
+
+func TestGoalStack_SpendPastBudget(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	var exhausted []*Goal
+	gs.OnGoalExhausted(func(goal *Goal) { exhausted = append(exhausted, goal) })
+
+	gs.Push(&Goal{ID: "costly", Priority: PriorityHigh, Budget: GoalBudget{MaxTokens: 1000, MaxCost: 0.5}})
+	gs.Push(&Goal{ID: "cheap"})
+
+	if err := gs.Spend("costly", 800, 0.1); err != nil {
+		t.Fatalf("Expected spending within budget allowed, got %v", err)
+	}
+	err := gs.Spend("costly", 300, 0.1)
+	if !errors.Is(err, ErrGoalExhausted) {
+		t.Fatalf("Expected ErrGoalExhausted past the token budget, got %v", err)
+	}
+	if err.Error() != "goal is past its deadline or budget: costly ran past its tokens" {
+		t.Errorf("Expected the limit in the error, got %q", err.Error())
+	}
+
+	costly, _ := gs.Get("costly")
+	if costly.Exhausted != LimitTokens || costly.Spent.Tokens != 1100 {
+		t.Errorf("Expected costly exhausted with 1100 tokens spent, got %q and %d", costly.Exhausted, costly.Spent.Tokens)
+	}
+	if len(exhausted) != 1 || exhausted[0].ID != "costly" {
+		t.Errorf("Expected the exhausted callback once for costly, got %d calls", len(exhausted))
+	}
+	if current := gs.Current(); current == nil || current.ID != "cheap" {
+		t.Errorf("Expected focus to move to cheap, got %+v", current)
+	}
+	if ids := goalIDs(gs.Ready()); len(ids) != 1 || ids[0] != "cheap" {
+		t.Errorf("Expected only cheap ready, got %v", ids)
+	}
+	if err := gs.Allow("costly"); !errors.Is(err, ErrGoalExhausted) {
+		t.Errorf("Expected Allow to refuse the exhausted goal, got %v", err)
+	}
+	if err := gs.Activate("costly"); !errors.Is(err, ErrGoalExhausted) {
+		t.Errorf("Expected Activate to refuse the exhausted goal, got %v", err)
+	}
+
+	// Spending more is still recorded, without notifying again
+	gs.Spend("costly", 100, 0)
+	if len(exhausted) != 1 || costly.Spent.Tokens != 1200 {
+		t.Errorf("Expected one notification and 1200 tokens, got %d and %d", len(exhausted), costly.Spent.Tokens)
+	}
+	if err := gs.Spend("cheap", 1_000_000, 100); err != nil {
+		t.Errorf("Expected a goal without a budget unlimited, got %v", err)
+	}
+}
+
+func TestGoalStack_SweepDeadlines(t *testing.T) {
+	gs := NewGoalStack(DefaultGoalStackConfig())
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	gs.Push(&Goal{ID: "late", Deadline: &past})
+	gs.Push(&Goal{ID: "on-time", Deadline: &future})
+
+	swept := gs.SweepDeadlines()
+	if len(swept) != 1 || swept[0].ID != "late" || swept[0].Exhausted != LimitDeadline {
+		t.Fatalf("Expected late swept past its deadline, got %v", goalIDs(swept))
+	}
+	if swept := gs.SweepDeadlines(); len(swept) != 0 {
+		t.Errorf("Expected an exhausted goal swept once, got %v", goalIDs(swept))
+	}
+	if err := gs.Allow("on-time"); err != nil {
+		t.Errorf("Expected on-time allowed, got %v", err)
+	}
+	if ids := goalIDs(gs.Ready()); len(ids) != 1 || ids[0] != "on-time" {
+		t.Errorf("Expected only on-time ready, got %v", ids)
+	}
+}
+
+// goalIDs returns the IDs of goals.
+func goalIDs(goals []*Goal) []string {
+	ids := make([]string, len(goals))
+	for i, goal := range goals {
+		ids[i] = goal.ID
+	}
+	return ids
+}
+
 func TestGoalStack_GetByStatus(t *testing.T) {
 	gs := NewGoalStack(DefaultGoalStackConfig())
 
@@ -0,0 +1,224 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements verification of agent answers against the Semantic
+// Network.
+//
+// An answer is split into claims the way answer fusion splits it. A claim
+// is checked when it can be read as a question the QuestionAnswerer
+// answers: two linked entities and a relation phrase ("APEX belongs to
+// Tier 1"), or one entity and the name of one of its properties ("the
+// max depth of the planner is 5"). Other claims are left alone, since the
+// graph says nothing about them either way. Checked claims the graph does
+// not support, or contradicts, are marked in the answer, and a summary is
+// appended, so a reader can tell grounded statements from the rest.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ClaimStatus is what the knowledge graph says about a claim.
+type ClaimStatus string
+
+const (
+	// ClaimSupported claims are backed by stored or inferred facts
+	ClaimSupported ClaimStatus = "supported"
+	// ClaimContradicted claims state a value other than the stored one, or
+	// deny a stored fact
+	ClaimContradicted ClaimStatus = "contradicted"
+	// ClaimUnsupported claims have no fact behind them
+	ClaimUnsupported ClaimStatus = "unsupported"
+)
+
+// GroundedClaim is a checked claim and the verdict on it.
+type GroundedClaim struct {
+	Claim  string      `json:"claim"`
+	Status ClaimStatus `json:"status"`
+	// Fact is what the graph holds, as one line
+	Fact       string  `json:"fact"`
+	Confidence float64 `json:"confidence"`
+	// Evidence are the stored facts the verdict rests on
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// GroundingReport is the verification of one answer.
+type GroundingReport struct {
+	// Claims are the checked claims, in answer order
+	Claims       []GroundedClaim `json:"claims"`
+	Supported    int             `json:"supported"`
+	Contradicted int             `json:"contradicted"`
+	Unsupported  int             `json:"unsupported"`
+	// Annotated is the answer with unsupported and contradicted claims
+	// marked and a summary appended; the answer unchanged if no claim
+	// could be checked
+	Annotated string `json:"annotated"`
+}
+
+// claimMarkers mark claims in the annotated answer.
+var claimMarkers = map[ClaimStatus]string{
+	ClaimContradicted: " *[contradicts memory]*",
+	ClaimUnsupported:  " *[unverified]*",
+}
+
+// GroundingVerifier checks the claims of answers against a semantic
+// network. It is safe for concurrent use.
+type GroundingVerifier struct {
+	qa *QuestionAnswerer
+}
+
+// NewGroundingVerifier creates a verifier for a network.
+func NewGroundingVerifier(network *SemanticNetwork) *GroundingVerifier {
+	return &GroundingVerifier{qa: NewQuestionAnswerer(network)}
+}
+
+// Verify checks an answer's claims and annotates it.
+func (v *GroundingVerifier) Verify(answer string) *GroundingReport {
+	report := &GroundingReport{Claims: make([]GroundedClaim, 0)}
+	lines := splitAnswer("", answer)
+	statuses := make(map[*answerClaim]ClaimStatus)
+	for _, line := range lines {
+		for _, claim := range line.claims {
+			checked, ok := v.check(claim)
+			if !ok {
+				continue
+			}
+			statuses[claim] = checked.Status
+			report.Claims = append(report.Claims, checked)
+			switch checked.Status {
+			case ClaimSupported:
+				report.Supported++
+			case ClaimContradicted:
+				report.Contradicted++
+			case ClaimUnsupported:
+				report.Unsupported++
+			}
+		}
+	}
+
+	if len(report.Claims) == 0 {
+		report.Annotated = answer
+		return report
+	}
+	report.Annotated = report.annotate(lines, statuses)
+	return report
+}
+
+// Ground verifies an answer, returning it annotated along with the claims
+// that are unsupported or contradicted.
+func (v *GroundingVerifier) Ground(ctx context.Context, answer string) (string, []string) {
+	report := v.Verify(answer)
+	var ungrounded []string
+	for _, claim := range report.Claims {
+		if claim.Status != ClaimSupported {
+			ungrounded = append(ungrounded, claim.Claim)
+		}
+	}
+	return report.Annotated, ungrounded
+}
+
+// check reads a claim as a question and compares the graph's answer with
+// it. It returns false for claims that cannot be checked.
+func (v *GroundingVerifier) check(claim *answerClaim) (GroundedClaim, bool) {
+	parsed := v.qa.linkEntities(claim.text)
+	if len(parsed.entities) == 0 {
+		return GroundedClaim{}, false
+	}
+	relType, _, hasRelation := detectRelation(parsed)
+
+	var answer *QAAnswer
+	var holds bool
+	switch {
+	case len(parsed.entities) >= 2 && hasRelation:
+		a, b := parsed.entities[0], parsed.entities[1]
+		if relType == IsA || relType == InstanceOf {
+			answer = v.qa.answerMembership(a, b)
+		} else {
+			answer = v.qa.answerRelationBetween(a, b, relType)
+		}
+		holds, _ = answer.Value.(bool)
+		// The graph not knowing a relation neither supports nor refutes
+		// a claim that it does not hold
+		if !holds && claim.negated {
+			return GroundedClaim{}, false
+		}
+	case len(parsed.entities) == 1:
+		answer = v.qa.answerProperty(parsed.entities[0], parsed)
+		if answer == nil {
+			return GroundedClaim{}, false
+		}
+		holds = valueStated(answer.Value, parsed.tokens)
+	default:
+		// Naming two entities without relating them asserts nothing
+		return GroundedClaim{}, false
+	}
+
+	// A stated property value is either the stored one or contradicts it;
+	// a relation the graph lacks is merely unsupported
+	status := ClaimSupported
+	switch {
+	case holds == claim.negated && answer.Kind == QuestionProperty:
+		status = ClaimContradicted
+	case holds && claim.negated:
+		status = ClaimContradicted
+	case !holds && !claim.negated:
+		status = ClaimUnsupported
+	}
+	return GroundedClaim{
+		Claim:      claim.text,
+		Status:     status,
+		Fact:       answer.Answer,
+		Confidence: answer.Confidence,
+		Evidence:   answer.Reasoning,
+	}, true
+}
+
+// valueStated reports whether every word of a property value appears in
+// a claim.
+func valueStated(value interface{}, tokens []string) bool {
+	words := tokenizeQuestion(fmt.Sprint(value))
+	if len(words) == 0 {
+		return false
+	}
+	present := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		present[token] = true
+	}
+	for _, word := range words {
+		if !present[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// annotate writes the answer with its ungrounded claims marked, followed
+// by a summary.
+func (r *GroundingReport) annotate(lines []answerLine, statuses map[*answerClaim]ClaimStatus) string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.claims == nil {
+			out = append(out, line.verbatim)
+			continue
+		}
+		texts := make([]string, len(line.claims))
+		for i, claim := range line.claims {
+			texts[i] = claim.text + claimMarkers[statuses[claim]]
+		}
+		out = append(out, line.prefix+strings.Join(texts, " "))
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(out, "\n"))
+	fmt.Fprintf(&b, "\n\n---\n**Grounding:** %d of %d checked claims are supported by the knowledge graph.\n", r.Supported, len(r.Claims))
+	for _, claim := range r.Claims {
+		switch claim.Status {
+		case ClaimContradicted:
+			fmt.Fprintf(&b, "- Contradicted: %q. %s\n", claim.Claim, claim.Fact)
+		case ClaimUnsupported:
+			fmt.Fprintf(&b, "- Unverified: %q. %s\n", claim.Claim, claim.Fact)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
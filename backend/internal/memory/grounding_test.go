@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Grounding Verifier Tests
+// ============================================================================
+
+func TestGroundingVerifier_Verify(t *testing.T) {
+	verifier := NewGroundingVerifier(buildQANetwork())
+
+	answer := strings.Join([]string{
+		"## Sorting",
+		"MergeSort requires Recursion. QuickSort requires Extra Memory.",
+		"- QuickSort is a Sorting Algorithm.",
+		"- The time complexity of QuickSort is O(n^2).",
+		"- MergeSort does not require Recursion.",
+		"Sorting is fun.",
+	}, "\n")
+	report := verifier.Verify(answer)
+
+	want := []struct {
+		claim  string
+		status ClaimStatus
+	}{
+		{"MergeSort requires Recursion.", ClaimSupported},
+		{"QuickSort requires Extra Memory.", ClaimUnsupported},
+		{"QuickSort is a Sorting Algorithm.", ClaimSupported},
+		{"The time complexity of QuickSort is O(n^2).", ClaimContradicted},
+		{"MergeSort does not require Recursion.", ClaimContradicted},
+	}
+	if len(report.Claims) != len(want) {
+		t.Fatalf("Expected %d checked claims, got %d: %+v", len(want), len(report.Claims), report.Claims)
+	}
+	for i, w := range want {
+		if report.Claims[i].Claim != w.claim || report.Claims[i].Status != w.status {
+			t.Errorf("Expected %q to be %s, got %q %s", w.claim, w.status, report.Claims[i].Claim, report.Claims[i].Status)
+		}
+	}
+	if report.Supported != 2 || report.Contradicted != 2 || report.Unsupported != 1 {
+		t.Errorf("Expected 2/2/1 supported/contradicted/unsupported, got %d/%d/%d",
+			report.Supported, report.Contradicted, report.Unsupported)
+	}
+
+	for _, expected := range []string{
+		"## Sorting\n",
+		"MergeSort requires Recursion. QuickSort requires Extra Memory. *[unverified]*\n",
+		"- QuickSort is a Sorting Algorithm.\n",
+		"- The time complexity of QuickSort is O(n^2). *[contradicts memory]*\n",
+		"Sorting is fun.\n",
+		"**Grounding:** 2 of 5 checked claims are supported",
+		"- Contradicted: \"The time complexity of QuickSort is O(n^2).\". The time_complexity of QuickSort is O(n log n).",
+	} {
+		if !strings.Contains(report.Annotated, expected) {
+			t.Errorf("Expected annotated answer to contain %q, got:\n%s", expected, report.Annotated)
+		}
+	}
+}
+
+func TestGroundingVerifier_NothingToCheck(t *testing.T) {
+	verifier := NewGroundingVerifier(buildQANetwork())
+
+	answer := "Sorting is fun.\n\n```go\nsort.Ints(xs)\n```"
+	report := verifier.Verify(answer)
+	if len(report.Claims) != 0 {
+		t.Errorf("Expected no checked claims, got %+v", report.Claims)
+	}
+	if report.Annotated != answer {
+		t.Errorf("Expected answer unchanged, got:\n%s", report.Annotated)
+	}
+}
+
+func TestGroundingVerifier_Ground(t *testing.T) {
+	verifier := NewGroundingVerifier(buildQANetwork())
+
+	annotated, ungrounded := verifier.Ground(context.Background(),
+		"MergeSort requires Recursion. QuickSort requires Extra Memory.")
+	if len(ungrounded) != 1 || ungrounded[0] != "QuickSort requires Extra Memory." {
+		t.Errorf("Expected the unsupported claim, got %v", ungrounded)
+	}
+	if !strings.Contains(annotated, "*[unverified]*") {
+		t.Errorf("Expected annotated answer, got:\n%s", annotated)
+	}
+}
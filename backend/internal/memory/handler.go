@@ -0,0 +1,409 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the HTTP handlers for the memory endpoints.
+
+package memory
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// Handler provides HTTP handlers for memory endpoints.
+type Handler struct {
+	network *SemanticNetwork
+	qa      *QuestionAnswerer
+}
+
+// NewHandler creates a new memory handler over a semantic network.
+func NewHandler(network *SemanticNetwork) *Handler {
+	return &Handler{
+		network: network,
+		qa:      NewQuestionAnswerer(network),
+	}
+}
+
+// Calibrator returns the calibrator that remaps the confidence of answers.
+func (h *Handler) Calibrator() *ConfidenceCalibrator {
+	return h.qa.engine.calibrator
+}
+
+// ============================================================================
+// Response Types
+// ============================================================================
+
+// NodeView is the JSON form of a semantic node.
+type NodeView struct {
+	ID         string                 `json:"id"`
+	Label      string                 `json:"label"`
+	Type       string                 `json:"type"`
+	Confidence float64                `json:"confidence"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// RelationView is the JSON form of a semantic relation.
+type RelationView struct {
+	ID         string  `json:"id"`
+	SourceID   string  `json:"source"`
+	TargetID   string  `json:"target"`
+	Type       string  `json:"type"`
+	Weight     float64 `json:"weight"`
+	Confidence float64 `json:"confidence"`
+}
+
+// SubgraphView is the JSON form of a subgraph.
+type SubgraphView struct {
+	Nodes     []NodeView     `json:"nodes"`
+	Relations []RelationView `json:"relations"`
+}
+
+// AskRequest is the body of POST /memory/ask.
+type AskRequest struct {
+	Question string `json:"question"`
+}
+
+// AskResponse is the body returned by POST /memory/ask.
+type AskResponse struct {
+	Question   string       `json:"question"`
+	Kind       string       `json:"kind"`
+	Answer     string       `json:"answer"`
+	Value      interface{}  `json:"value"`
+	Confidence float64      `json:"confidence"`
+	Entities   []string     `json:"entities"`
+	Relation   string       `json:"relation,omitempty"`
+	Inferred   bool         `json:"inferred"`
+	Reasoning  []string     `json:"reasoning"`
+	Subgraph   SubgraphView `json:"subgraph"`
+}
+
+// QueryRequest is the body of POST /memory/query.
+type QueryRequest struct {
+	Query string `json:"query"`
+}
+
+// PlanStepView is the JSON form of a query plan step.
+type PlanStepView struct {
+	Pattern  string   `json:"pattern"`
+	Access   string   `json:"access"`
+	Estimate int      `json:"estimate"`
+	Filters  []string `json:"filters,omitempty"`
+}
+
+// QueryResponse is the body returned by POST /memory/query.
+type QueryResponse struct {
+	Vars      []string                 `json:"vars"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Plan      []PlanStepView           `json:"plan"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// PathStepView is the JSON form of a relation followed on a path.
+type PathStepView struct {
+	Relation RelationView `json:"relation"`
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	// Reversed is true when the relation was followed target to source
+	Reversed bool `json:"reversed"`
+}
+
+// ExplainResponse is the body returned by GET /memory/explain.
+type ExplainResponse struct {
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Nodes []NodeView     `json:"nodes"`
+	Steps []PathStepView `json:"steps"`
+	Hops  int            `json:"hops"`
+	Cost  float64        `json:"cost"`
+}
+
+// newNodeView converts a node to its JSON form.
+func newNodeView(node *SemanticNode) NodeView {
+	return NodeView{
+		ID:         node.ID,
+		Label:      node.Label,
+		Type:       node.Type.String(),
+		Confidence: node.Confidence,
+		Properties: node.Properties,
+	}
+}
+
+// newRelationView converts a relation to its JSON form.
+func newRelationView(rel *SemanticRelation) RelationView {
+	return RelationView{
+		ID:         rel.ID,
+		SourceID:   rel.SourceID,
+		TargetID:   rel.TargetID,
+		Type:       rel.Type.String(),
+		Weight:     rel.Weight,
+		Confidence: rel.Confidence,
+	}
+}
+
+// newSubgraphView converts a subgraph to its JSON form.
+func newSubgraphView(sub *Subgraph) SubgraphView {
+	view := SubgraphView{
+		Nodes:     make([]NodeView, 0),
+		Relations: make([]RelationView, 0),
+	}
+	if sub == nil {
+		return view
+	}
+	for _, node := range sub.Nodes {
+		view.Nodes = append(view.Nodes, newNodeView(node))
+	}
+	for _, rel := range sub.Relations {
+		view.Relations = append(view.Relations, newRelationView(rel))
+	}
+	return view
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+// Ask handles POST /memory/ask - answers a question from the knowledge graph.
+func (h *Handler) Ask(w http.ResponseWriter, r *http.Request) {
+	var req AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		errdefs.WriteError(w, "question is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.Answer(req.Question)
+	if err != nil {
+		if status := errdefs.HTTPStatus(err); status != http.StatusInternalServerError {
+			errdefs.WriteError(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error answering question: %v", err)
+		errdefs.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Answer answers a question from the knowledge graph, as POST /memory/ask
+// does; the gRPC API shares it.
+func (h *Handler) Answer(question string) (*AskResponse, error) {
+	answer, err := h.qa.Ask(question)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AskResponse{
+		Question:   answer.Question,
+		Kind:       answer.Kind.String(),
+		Answer:     answer.Answer,
+		Value:      answer.Value,
+		Confidence: answer.Confidence,
+		Entities:   make([]string, 0, len(answer.Entities)),
+		Inferred:   answer.Inferred,
+		Reasoning:  answer.Reasoning,
+		Subgraph:   newSubgraphView(answer.Subgraph),
+	}
+	for _, entity := range answer.Entities {
+		resp.Entities = append(resp.Entities, entity.ID)
+	}
+	if answer.Relation != nil {
+		resp.Relation = answer.Relation.String()
+	}
+	if resp.Reasoning == nil {
+		resp.Reasoning = make([]string, 0)
+	}
+	return resp, nil
+}
+
+// Query handles POST /memory/query - runs a SPARQL-lite query.
+func (h *Handler) Query(w http.ResponseWriter, r *http.Request) {
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		errdefs.WriteError(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.RunQuery(req.Query)
+	if err != nil {
+		if status := errdefs.HTTPStatus(err); status != http.StatusInternalServerError {
+			errdefs.WriteError(w, err.Error(), status)
+			return
+		}
+		log.Printf("Error running query: %v", err)
+		errdefs.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// RunQuery runs a SPARQL-lite query, as POST /memory/query does; the gRPC
+// API shares it.
+func (h *Handler) RunQuery(query string) (*QueryResponse, error) {
+	result, err := h.network.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &QueryResponse{
+		Vars:      result.Vars,
+		Rows:      make([]map[string]interface{}, 0, len(result.Rows)),
+		Plan:      make([]PlanStepView, 0, len(result.Plan.Steps)),
+		Truncated: result.Truncated,
+	}
+	for _, row := range result.Rows {
+		view := make(map[string]interface{}, len(row))
+		for v, value := range row {
+			if typed, ok := value.(PropertyValue); ok {
+				value = typed.Interface()
+			}
+			view[v] = value
+		}
+		resp.Rows = append(resp.Rows, view)
+	}
+	for _, step := range result.Plan.Steps {
+		stepView := PlanStepView{
+			Pattern:  step.Pattern.String(),
+			Access:   step.Access,
+			Estimate: step.Estimate,
+		}
+		for _, filter := range step.Filters {
+			stepView.Filters = append(stepView.Filters, filter.String())
+		}
+		resp.Plan = append(resp.Plan, stepView)
+	}
+	return resp, nil
+}
+
+// Explain handles GET /memory/explain?from=&to=&types=&max_hops=&direction=&weighted=
+// - explains how two nodes are connected by the cheapest path between them.
+func (h *Handler) Explain(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := PathQuery{
+		FromID:    strings.TrimSpace(params.Get("from")),
+		ToID:      strings.TrimSpace(params.Get("to")),
+		Direction: PathBoth,
+	}
+	if query.FromID == "" || query.ToID == "" {
+		errdefs.WriteError(w, "from and to are required", http.StatusBadRequest)
+		return
+	}
+	if types := params.Get("types"); types != "" {
+		for _, name := range strings.Split(types, ",") {
+			relType, ok := parseRelationType(strings.TrimSpace(name))
+			if !ok {
+				errdefs.WriteError(w, "unknown relation type "+name, http.StatusBadRequest)
+				return
+			}
+			query.AllowedTypes = append(query.AllowedTypes, relType)
+		}
+	}
+	if hops := params.Get("max_hops"); hops != "" {
+		n, err := strconv.Atoi(hops)
+		if err != nil || n < 0 {
+			errdefs.WriteError(w, "max_hops must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		query.MaxHops = n
+	}
+	if direction := params.Get("direction"); direction != "" {
+		var ok bool
+		if query.Direction, ok = parsePathDirection(direction); !ok {
+			errdefs.WriteError(w, "direction must be forward, backward or both", http.StatusBadRequest)
+			return
+		}
+	}
+	if weighted := params.Get("weighted"); weighted != "" {
+		var err error
+		if query.UseWeights, err = strconv.ParseBool(weighted); err != nil {
+			errdefs.WriteError(w, "weighted must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	path, err := h.network.FindPath(query)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+
+	resp := ExplainResponse{
+		From:  query.FromID,
+		To:    query.ToID,
+		Nodes: make([]NodeView, 0, len(path.Nodes)),
+		Steps: make([]PathStepView, 0, len(path.Edges)),
+		Hops:  path.Hops(),
+		Cost:  path.Cost,
+	}
+	for _, node := range path.Nodes {
+		resp.Nodes = append(resp.Nodes, newNodeView(node))
+	}
+	for _, step := range path.Edges {
+		resp.Steps = append(resp.Steps, PathStepView{
+			Relation: newRelationView(step.Relation),
+			From:     step.FromID,
+			To:       step.ToID,
+			Reversed: step.Reversed,
+		})
+	}
+	errdefs.WriteJSON(w, http.StatusOK, resp)
+}
+
+// parsePathDirection converts a direction name back to its PathDirection.
+func parsePathDirection(name string) (PathDirection, bool) {
+	for d := PathForward; d <= PathBoth; d++ {
+		if d.String() == name {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// GlossaryResponse is the body returned by GET /glossary.
+type GlossaryResponse struct {
+	*Glossary
+	// Markdown is the glossary rendered for embedding in docs
+	Markdown string `json:"markdown"`
+}
+
+// Glossary handles GET /glossary?project=&format= - returns the glossary
+// of a project's concepts, as JSON or, with format=markdown, as the
+// Markdown document alone.
+func (h *Handler) Glossary(w http.ResponseWriter, r *http.Request) {
+	project := strings.TrimSpace(r.URL.Query().Get("project"))
+	if project == "" {
+		errdefs.WriteError(w, "project is required", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		errdefs.WriteError(w, "format must be json or markdown", http.StatusBadRequest)
+		return
+	}
+
+	glossary, err := h.network.BuildGlossary(project)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, glossary.Markdown())
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, GlossaryResponse{Glossary: glossary, Markdown: glossary.Markdown()})
+}
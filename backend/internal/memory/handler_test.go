@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Ask(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	if err := SeedAgentOntology(sn, testSeedAgents()); err != nil {
+		t.Fatalf("SeedAgentOntology failed: %v", err)
+	}
+	handler := NewHandler(sn)
+
+	body, _ := json.Marshal(AskRequest{Question: "What can APEX do?"})
+	req := httptest.NewRequest(http.MethodPost, "/memory/ask", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.Ask(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", rec.Header().Get("Content-Type"))
+	}
+
+	var resp AskResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Kind != "relation" || resp.Relation != "can-do" {
+		t.Errorf("Expected can-do relation answer, got %s %s", resp.Kind, resp.Relation)
+	}
+	if len(resp.Entities) != 1 || resp.Entities[0] != "APEX" {
+		t.Errorf("Expected APEX entity, got %v", resp.Entities)
+	}
+	if len(resp.Subgraph.Nodes) != 3 || len(resp.Subgraph.Relations) != 2 {
+		t.Errorf("Expected APEX and 2 keywords in subgraph, got %d nodes and %d relations",
+			len(resp.Subgraph.Nodes), len(resp.Subgraph.Relations))
+	}
+}
+
+func TestHandler_AskErrors(t *testing.T) {
+	handler := NewHandler(NewSemanticNetwork(DefaultSemanticNetworkConfig()))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", "{", http.StatusBadRequest},
+		{"empty question", `{"question": "  "}`, http.StatusBadRequest},
+		{"unknown entity", `{"question": "What is APEX?"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/memory/ask", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			handler.Ask(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+			var resp map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp["error"] == "" {
+				t.Errorf("Expected JSON error body, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Query(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	if err := SeedAgentOntology(sn, testSeedAgents()); err != nil {
+		t.Fatalf("SeedAgentOntology failed: %v", err)
+	}
+	handler := NewHandler(sn)
+
+	body, _ := json.Marshal(QueryRequest{
+		Query: `SELECT ?agent ?tier WHERE { ?agent type agent . ?agent @tier ?tier . FILTER(?tier = 1) }`,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/memory/query", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.Query(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp QueryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Rows) != 2 || resp.Rows[0]["agent"] != "APEX" || resp.Rows[0]["tier"] != float64(1) {
+		t.Errorf("Expected APEX and CIPHER in tier 1, got %v", resp.Rows)
+	}
+	if len(resp.Plan) != 2 || resp.Plan[0].Access != AccessTypeIndex {
+		t.Errorf("Expected plan starting from the type index, got %v", resp.Plan)
+	}
+}
+
+func TestHandler_QueryErrors(t *testing.T) {
+	handler := NewHandler(NewSemanticNetwork(DefaultSemanticNetworkConfig()))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "{"},
+		{"empty query", `{"query": ""}`},
+		{"syntax error", `{"query": "SELECT ?x WHERE { ?x frobnicates ?y }"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/memory/query", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			handler.Query(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Glossary(t *testing.T) {
+	handler := NewHandler(buildGlossaryNetwork(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/glossary?project=mnemonic", nil)
+	rec := httptest.NewRecorder()
+	handler.Glossary(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp GlossaryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Glossary == nil || len(resp.Entries) != 4 || resp.Markdown == "" {
+		t.Errorf("Expected 4 entries and their Markdown, got %+v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/glossary?project=mnemonic&format=markdown", nil)
+	rec = httptest.NewRecorder()
+	handler.Glossary(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/markdown; charset=utf-8" {
+		t.Errorf("Expected Markdown content type, got %s", ct)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("# mnemonic Glossary\n")) {
+		t.Errorf("Expected the Markdown document, got %s", rec.Body.String())
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"missing project", "", http.StatusBadRequest},
+		{"bad format", "?project=mnemonic&format=html", http.StatusBadRequest},
+		{"empty project", "?project=apex", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.Glossary(rec, httptest.NewRequest(http.MethodGet, "/glossary"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Explain(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	if err := SeedAgentOntology(sn, testSeedAgents()); err != nil {
+		t.Fatalf("SeedAgentOntology failed: %v", err)
+	}
+	handler := NewHandler(sn)
+
+	req := httptest.NewRequest(http.MethodGet, "/memory/explain?from=keyword-algorithms&to=CIPHER&types=can-do,related-to", nil)
+	rec := httptest.NewRecorder()
+	handler.Explain(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ExplainResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Hops != 2 || len(resp.Nodes) != 3 || resp.Nodes[1].ID != "APEX" {
+		t.Fatalf("Expected a 2 hop path through APEX, got %+v", resp)
+	}
+	if !resp.Steps[0].Reversed || resp.Steps[0].Relation.Type != "can-do" || resp.Steps[1].Reversed {
+		t.Errorf("Expected can-do followed backwards then related-to, got %+v", resp.Steps)
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"missing to", "?from=APEX", http.StatusBadRequest},
+		{"unknown type", "?from=APEX&to=CIPHER&types=knows", http.StatusBadRequest},
+		{"bad max hops", "?from=APEX&to=CIPHER&max_hops=-1", http.StatusBadRequest},
+		{"bad direction", "?from=APEX&to=CIPHER&direction=up", http.StatusBadRequest},
+		{"bad weighted", "?from=APEX&to=CIPHER&weighted=maybe", http.StatusBadRequest},
+		{"unknown node", "?from=APEX&to=NOBODY", http.StatusNotFound},
+		{"no path forward", "?from=keyword-algorithms&to=CIPHER&direction=forward", http.StatusNotFound},
+		{"no path within types", "?from=keyword-algorithms&to=CIPHER&types=can-do", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.Explain(rec, httptest.NewRequest(http.MethodGet, "/memory/explain"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
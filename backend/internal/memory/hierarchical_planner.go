@@ -332,7 +332,7 @@ func (p *HierarchicalPlanner) Plan(task *Task, state *PlannerState) (*Plan, erro
 	actions, depth, err := p.planTask(task, state, 0, deadline)
 
 	plan := &Plan{
-		ID:        fmt.Sprintf("plan-%s-%d", task.ID, time.Now().UnixNano()),
+		ID:        NewID("plan-" + task.ID),
 		GoalTask:  task,
 		Actions:   actions,
 		CreatedAt: startTime,
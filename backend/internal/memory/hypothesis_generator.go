@@ -251,7 +251,7 @@ func (shg *ScientificHypothesisGenerator) GenerateHypotheses(ctx context.Context
 	shg.requestCount++
 
 	hypothesisSet := &ScientificHypothesisSet{
-		ID:          fmt.Sprintf("hset-%s-%d", goal.ID, time.Now().Unix()),
+		ID:          NewID("hset-" + goal.ID),
 		Name:        fmt.Sprintf("Hypotheses for %s", goal.Name),
 		Description: fmt.Sprintf("Scientific hypotheses about %s", goal.Name),
 		Hypotheses:  make([]*ScientificHypothesis, 0),
@@ -386,7 +386,7 @@ func (shg *ScientificHypothesisGenerator) validateHypothesis(hypothesis *Scienti
 	}
 
 	validation := &ScientificValidation{
-		ID:             fmt.Sprintf("val-%s-%d", hypothesis.ID, time.Now().Unix()),
+		ID:             NewID("val-" + hypothesis.ID),
 		HypothesisID:   hypothesis.ID,
 		ValidationType: ValidationEmpirical,
 		Result:         result,
@@ -0,0 +1,200 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the ID generator used across memory components.
+//
+// Two kinds of ID are issued:
+//   - NewID returns a ULID: 48 bits of milliseconds and 80 random bits, so
+//     IDs sort by creation time and never repeat after a restart the way
+//     process-local counters did.
+//   - NameID returns a name-based UUID (version 5) for entities identified
+//     by their content, such as relations, so every replica derives the same
+//     ID. Parts are length-prefixed before hashing, so ("a-b", "c") and
+//     ("a", "b-c") no longer collide.
+
+package memory
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// IDGenerator issues unique IDs for memory entities.
+type IDGenerator interface {
+	// NewID returns a new ID of the form <prefix>-<unique suffix>
+	NewID(prefix string) string
+}
+
+// ============================================================================
+// ULID Generator
+// ============================================================================
+
+// crockford is the Crockford base32 alphabet ULIDs are written in.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator issues monotonic ULIDs. IDs issued within the same
+// millisecond, or after the clock steps back, increment the previous random
+// part, so IDs from one generator are strictly increasing. It is safe for
+// concurrent use.
+type ULIDGenerator struct {
+	clock   Clock
+	entropy io.Reader
+
+	mu sync.Mutex
+	// lastMillis and lastRandom are the parts of the previous ULID
+	lastMillis uint64
+	lastRandom [10]byte
+}
+
+// NewULIDGenerator creates a ULID generator reading time from clock; a nil
+// clock uses SystemClock.
+func NewULIDGenerator(clock Clock) *ULIDGenerator {
+	return &ULIDGenerator{clock: clockOrSystem(clock), entropy: rand.Reader}
+}
+
+// NewID returns <prefix>-<ULID>.
+func (g *ULIDGenerator) NewID(prefix string) string {
+	return prefix + "-" + g.ULID()
+}
+
+// ULID returns a new 26-character ULID.
+func (g *ULIDGenerator) ULID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	millis := uint64(g.clock.Now().UnixMilli()) & (1<<48 - 1)
+	if millis <= g.lastMillis {
+		millis = g.lastMillis
+		if !incrementBytes(g.lastRandom[:]) {
+			// The random part overflowed; borrow the next millisecond
+			millis++
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.lastRandom[:]); err != nil {
+		panic(fmt.Sprintf("memory: reading ULID entropy: %v", err))
+	}
+	g.lastMillis = millis
+
+	var id [16]byte
+	id[0] = byte(millis >> 40)
+	id[1] = byte(millis >> 32)
+	binary.BigEndian.PutUint32(id[2:], uint32(millis))
+	copy(id[6:], g.lastRandom[:])
+	return encodeULID(id)
+}
+
+// incrementBytes adds one to a big-endian number, reporting false if it
+// wrapped around to zero.
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID writes 128 bits as 26 Crockford base32 characters, the first
+// holding the top 3 bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ============================================================================
+// Package Generator
+// ============================================================================
+
+var (
+	idGeneratorMu sync.RWMutex
+	idGenerator   IDGenerator = NewULIDGenerator(SystemClock)
+)
+
+// SetIDGenerator replaces the generator memory components draw IDs from and
+// returns the previous one; nil restores the default ULID generator.
+func SetIDGenerator(g IDGenerator) IDGenerator {
+	if g == nil {
+		g = NewULIDGenerator(SystemClock)
+	}
+	idGeneratorMu.Lock()
+	defer idGeneratorMu.Unlock()
+	previous := idGenerator
+	idGenerator = g
+	return previous
+}
+
+// NewID returns a new unique ID of the form <prefix>-<suffix> from the
+// package generator.
+func NewID(prefix string) string {
+	idGeneratorMu.RLock()
+	g := idGenerator
+	idGeneratorMu.RUnlock()
+	return g.NewID(prefix)
+}
+
+// ============================================================================
+// Name-Based IDs
+// ============================================================================
+
+// nameIDNamespace is the UUID namespace of MNEMONIC name-based IDs.
+var nameIDNamespace = [16]byte{
+	0x6d, 0x6e, 0x65, 0x6d, 0x6f, 0x6e, 0x49, 0x43,
+	0x9a, 0x1d, 0x4e, 0x2b, 0x8c, 0x3f, 0x70, 0x51,
+}
+
+// NameID returns <prefix>-<UUIDv5> derived from parts, so the same parts
+// always give the same ID.
+func NameID(prefix string, parts ...string) string {
+	h := sha1.New()
+	h.Write(nameIDNamespace[:])
+	var length [binary.MaxVarintLen64]byte
+	for _, part := range parts {
+		h.Write(length[:binary.PutUvarint(length[:], uint64(len(part)))])
+		h.Write([]byte(part))
+	}
+	var uuid [16]byte
+	copy(uuid[:], h.Sum(nil))
+	uuid[6] = uuid[6]&0x0f | 0x50 // version 5
+	uuid[8] = uuid[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var b strings.Builder
+	b.Grow(len(prefix) + 37)
+	b.WriteString(prefix)
+	b.WriteByte('-')
+	for i, group := range [][]byte{uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]} {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(hex.EncodeToString(group))
+	}
+	return b.String()
+}
+
+// RelationID returns the ID of the relation of a type between two nodes.
+func RelationID(sourceID string, relType RelationType, targetID string) string {
+	return relationIDByName(sourceID, relType.String(), targetID)
+}
+
+// relationIDByName is RelationID with the type given by name, as snapshots
+// store it.
+func relationIDByName(sourceID, relType, targetID string) string {
+	return NameID("rel", sourceID, relType, targetID)
+}
+
+// legacyRelationID returns the ID releases before generated IDs gave a
+// relation. It is ambiguous when node IDs contain dashes, which is why it was
+// replaced.
+func legacyRelationID(sourceID, relType, targetID string) string {
+	return fmt.Sprintf("%s-%s-%s", sourceID, relType, targetID)
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// ID Generator Tests
+// ============================================================================
+
+func TestULIDGenerator_Format(t *testing.T) {
+	g := NewULIDGenerator(NewManualClock(time.UnixMilli(1469918176385)))
+
+	id := g.ULID()
+	if len(id) != 26 {
+		t.Fatalf("Expected 26 characters, got %d in %s", len(id), id)
+	}
+	// The timestamp of the ULID spec's example
+	if !strings.HasPrefix(id, "01ARYZ6S41") {
+		t.Errorf("Expected timestamp 01ARYZ6S41, got %s", id[:10])
+	}
+	if strings.Trim(id, crockford) != "" {
+		t.Errorf("Expected only Crockford base32, got %s", id)
+	}
+	if prefixed := g.NewID("focus"); !strings.HasPrefix(prefixed, "focus-") || len(prefixed) != len("focus-")+26 {
+		t.Errorf("Expected focus-<ULID>, got %s", prefixed)
+	}
+}
+
+func TestULIDGenerator_Monotonic(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	g := NewULIDGenerator(clock)
+
+	previous := g.ULID()
+	for i := 0; i < 1000; i++ {
+		switch i % 3 {
+		case 1:
+			clock.Advance(time.Millisecond)
+		case 2:
+			// Stepping back must not reorder IDs
+			clock.Advance(-time.Second)
+		}
+		id := g.ULID()
+		if id <= previous {
+			t.Fatalf("Expected %s after %s", id, previous)
+		}
+		previous = id
+	}
+}
+
+func TestULIDGenerator_ConcurrentUnique(t *testing.T) {
+	g := NewULIDGenerator(nil)
+	const workers, perWorker = 8, 500
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id := g.NewID("x")
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("Duplicate ID %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIncrementBytes(t *testing.T) {
+	b := []byte{0x00, 0xff}
+	if !incrementBytes(b) || b[0] != 0x01 || b[1] != 0x00 {
+		t.Errorf("Expected carry to 01 00, got % x", b)
+	}
+	b = []byte{0xff, 0xff}
+	if incrementBytes(b) || b[0] != 0 || b[1] != 0 {
+		t.Errorf("Expected overflow to 00 00, got % x", b)
+	}
+}
+
+func TestNameID(t *testing.T) {
+	id := NameID("rel", "dog", "is-a", "animal")
+	if id != NameID("rel", "dog", "is-a", "animal") {
+		t.Error("Expected the same parts to give the same ID")
+	}
+	if !strings.HasPrefix(id, "rel-") || len(id) != len("rel-")+36 {
+		t.Errorf("Expected rel-<UUID>, got %s", id)
+	}
+	if version := id[len("rel-")+14]; version != '5' {
+		t.Errorf("Expected UUID version 5, got %c in %s", version, id)
+	}
+
+	// Joining with dashes made these the same ID
+	if NameID("rel", "a-b", "c") == NameID("rel", "a", "b-c") {
+		t.Error("Expected part boundaries to change the ID")
+	}
+	if RelationID("a-is-a", IsA, "b") == RelationID("a", IsA, "is-a-b") {
+		t.Error("Expected relations with dashed node IDs not to collide")
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	g := NewULIDGenerator(NewManualClock(time.UnixMilli(0)))
+	previous := SetIDGenerator(g)
+	defer SetIDGenerator(previous)
+
+	if id := NewID("state"); !strings.HasPrefix(id, "state-0000000000") {
+		t.Errorf("Expected ID from the installed generator, got %s", id)
+	}
+	if item := NewFocusItem(FocusTask, nil, "Test", 0.5); !strings.HasPrefix(item.ID, "focus-0000000000") {
+		t.Errorf("Expected focus item ID from the installed generator, got %s", item.ID)
+	}
+}
@@ -16,14 +16,11 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
-// impasseIDCounter provides unique IDs for impasses
-var impasseIDCounter uint64
-
 // ============================================================================
 // Impasse Types
 // ============================================================================
@@ -244,6 +241,9 @@ type ResolutionResult struct {
 	// LearnedPattern is a pattern extracted (for Learn resolution)
 	LearnedPattern string
 
+	// RetryAt is when to retry (for Backoff resolution)
+	RetryAt time.Time
+
 	// Duration is how long resolution took
 	Duration time.Duration
 }
@@ -283,6 +283,9 @@ type ImpasseDetector struct {
 
 	// customResolvers allow custom resolution logic
 	customResolvers map[ImpasseType]func(*Impasse) (*ResolutionResult, error)
+
+	// clock stamps impasses and schedules backoff
+	clock Clock
 }
 
 // ImpasseDetectorConfig configures the detector.
@@ -307,6 +310,9 @@ type ImpasseDetectorConfig struct {
 
 	// MaxActiveImpasses before triggering capacity impasse
 	MaxActiveImpasses int
+
+	// Clock supplies detection, resolution and backoff times (default: SystemClock)
+	Clock Clock
 }
 
 // DefaultImpasseDetectorConfig returns sensible defaults.
@@ -350,6 +356,7 @@ func NewImpasseDetector(config *ImpasseDetectorConfig, goalStack *GoalStack) *Im
 		},
 		strategyHandlers: make(map[ImpasseType][]ResolutionStrategy),
 		customResolvers:  make(map[ImpasseType]func(*Impasse) (*ResolutionResult, error)),
+		clock:            clockOrSystem(config.Clock),
 	}
 
 	// Set default resolution strategies per impasse type
@@ -362,6 +369,11 @@ func NewImpasseDetector(config *ImpasseDetectorConfig, goalStack *GoalStack) *Im
 	detector.strategyHandlers[ImpasseConstraint] = []ResolutionStrategy{StrategyFallback, StrategyDecompose, StrategyAbort}
 	detector.strategyHandlers[ImpasseTimeout] = []ResolutionStrategy{StrategyBackoff, StrategyAbort}
 
+	// Goals running past their deadline or budget raise impasses
+	if goalStack != nil {
+		goalStack.OnGoalExhausted(func(goal *Goal) { detector.DetectExhausted(goal) })
+	}
+
 	return detector
 }
 
@@ -473,6 +485,35 @@ func (d *ImpasseDetector) DetectTimeout(goalID string, elapsed time.Duration) *I
 	})
 }
 
+// DetectTimeoutSince detects when processing started at start has exceeded
+// the time limit, measured on the detector's clock.
+func (d *ImpasseDetector) DetectTimeoutSince(goalID string, start time.Time) *Impasse {
+	return d.DetectTimeout(goalID, d.clock.Now().Sub(start))
+}
+
+// DetectExhausted raises the impasse for a goal that ran past a limit: a
+// timeout for its deadline, capacity for its token or cost budget.
+func (d *ImpasseDetector) DetectExhausted(goal *Goal) *Impasse {
+	switch goal.Exhausted {
+	case LimitDeadline:
+		return d.createImpasse(ImpasseTimeout, goal.ID, fmt.Sprintf("deadline %s passed", goal.Deadline.Format(time.RFC3339)), func(imp *Impasse) {
+			imp.Severity = 0.9
+			imp.Context["deadline"] = goal.Deadline.Format(time.RFC3339)
+		})
+	case LimitTokens:
+		return d.createImpasse(ImpasseCapacity, goal.ID, fmt.Sprintf("token budget exhausted: %d of %d", goal.Spent.Tokens, goal.Budget.MaxTokens), func(imp *Impasse) {
+			imp.Severity = 0.8
+			imp.Context["resource"] = string(LimitTokens)
+		})
+	case LimitCost:
+		return d.createImpasse(ImpasseCapacity, goal.ID, fmt.Sprintf("cost budget exhausted: %.2f of %.2f", goal.Spent.Cost, goal.Budget.MaxCost), func(imp *Impasse) {
+			imp.Severity = 0.8
+			imp.Context["resource"] = string(LimitCost)
+		})
+	}
+	return nil
+}
+
 // createImpasse creates and registers an impasse.
 func (d *ImpasseDetector) createImpasse(impasseType ImpasseType, goalID, description string, configure func(*Impasse)) *Impasse {
 	d.mu.Lock()
@@ -484,11 +525,11 @@ func (d *ImpasseDetector) createImpasse(impasseType ImpasseType, goalID, descrip
 		if impasseType != ImpasseCapacity {
 			// Avoid infinite recursion
 			cap := &Impasse{
-				ID:          fmt.Sprintf("imp-capacity-%d", atomic.AddUint64(&impasseIDCounter, 1)),
+				ID:          NewID("imp-capacity"),
 				Type:        ImpasseCapacity,
 				GoalID:      goalID,
 				Description: "too many active impasses",
-				DetectedAt:  time.Now(),
+				DetectedAt:  d.clock.Now(),
 				Severity:    0.9,
 				MaxRetries:  d.config.MaxRetries,
 				Context:     make(map[string]interface{}),
@@ -498,16 +539,17 @@ func (d *ImpasseDetector) createImpasse(impasseType ImpasseType, goalID, descrip
 			d.activeImpasses[cap.ID] = cap
 			d.stats.TotalDetected++
 			d.stats.ByType[ImpasseCapacity]++
+			d.journalImpasse(cap)
 			return cap
 		}
 	}
 
 	imp := &Impasse{
-		ID:          fmt.Sprintf("imp-%s-%d", impasseType.String(), atomic.AddUint64(&impasseIDCounter, 1)),
+		ID:          NewID("imp-" + impasseType.String()),
 		Type:        impasseType,
 		GoalID:      goalID,
 		Description: description,
-		DetectedAt:  time.Now(),
+		DetectedAt:  d.clock.Now(),
 		MaxRetries:  d.config.MaxRetries,
 		Context:     make(map[string]interface{}),
 		Metadata:    make(map[string]interface{}),
@@ -521,6 +563,7 @@ func (d *ImpasseDetector) createImpasse(impasseType ImpasseType, goalID, descrip
 	d.activeImpasses[imp.ID] = imp
 	d.stats.TotalDetected++
 	d.stats.ByType[impasseType]++
+	d.journalImpasse(imp)
 
 	if d.onImpasseDetected != nil {
 		d.onImpasseDetected(imp)
@@ -529,6 +572,17 @@ func (d *ImpasseDetector) createImpasse(impasseType ImpasseType, goalID, descrip
 	return imp
 }
 
+// journalImpasse records an impasse in its goal's journal, if the goal
+// stack keeps one.
+func (d *ImpasseDetector) journalImpasse(imp *Impasse) {
+	if d.goalStack == nil {
+		return
+	}
+	if journal := d.goalStack.Journal(); journal != nil {
+		journal.RecordImpasse(imp)
+	}
+}
+
 // ============================================================================
 // Impasse Resolution
 // ============================================================================
@@ -582,9 +636,30 @@ func (d *ImpasseDetector) Resolve(impasseID string) (*ResolutionResult, error) {
 	return nil, errors.New("resolution strategies failed, will retry")
 }
 
+// ResolveWith resolves an impasse with one strategy, rather than trying
+// the strategies configured for its type. The impasse stays active if the
+// strategy fails.
+func (d *ImpasseDetector) ResolveWith(impasseID string, strategy ResolutionStrategy) (*ResolutionResult, error) {
+	d.mu.RLock()
+	imp, ok := d.activeImpasses[impasseID]
+	d.mu.RUnlock()
+	if !ok {
+		return nil, errors.New("impasse not found or already resolved")
+	}
+
+	result, err := d.applyStrategy(imp, strategy)
+	if err != nil {
+		return nil, err
+	}
+	if result.Success {
+		d.markResolved(imp, result)
+	}
+	return result, nil
+}
+
 // applyStrategy applies a specific resolution strategy.
 func (d *ImpasseDetector) applyStrategy(imp *Impasse, strategy ResolutionStrategy) (*ResolutionResult, error) {
-	start := time.Now()
+	start := d.clock.Now()
 
 	var result *ResolutionResult
 
@@ -614,7 +689,7 @@ func (d *ImpasseDetector) applyStrategy(imp *Impasse, strategy ResolutionStrateg
 	}
 
 	result.Strategy = strategy
-	result.Duration = time.Since(start)
+	result.Duration = d.clock.Now().Sub(start)
 
 	return result, nil
 }
@@ -674,7 +749,7 @@ func (d *ImpasseDetector) resolveRandom(imp *Impasse) *ResolutionResult {
 	}
 
 	// Use time-based "random" selection
-	selected := imp.Candidates[time.Now().UnixNano()%int64(len(imp.Candidates))]
+	selected := imp.Candidates[uint64(d.clock.Now().UnixNano())%uint64(len(imp.Candidates))]
 
 	return &ResolutionResult{
 		Success:           true,
@@ -725,11 +800,11 @@ func (d *ImpasseDetector) resolveBackoff(imp *Impasse) *ResolutionResult {
 		backoff = d.config.BackoffMax
 	}
 
-	// In a real system, we'd schedule a delayed retry
-	// For now, just indicate the backoff duration
+	// Schedule the retry; callers wait until RetryAt before resolving again
 	return &ResolutionResult{
 		Success: true,
 		Message: fmt.Sprintf("backing off for %v", backoff),
+		RetryAt: d.clock.Now().Add(backoff),
 	}
 }
 
@@ -791,7 +866,7 @@ func (d *ImpasseDetector) markResolved(imp *Impasse, result *ResolutionResult) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	now := time.Now()
+	now := d.clock.Now()
 	imp.ResolvedAt = &now
 	imp.Resolution = result.Strategy
 	imp.ResolutionDetails = result.Message
@@ -837,6 +912,34 @@ func (d *ImpasseDetector) GetActive() []*Impasse {
 	return result
 }
 
+// Recent returns up to n impasses, active or resolved, most recently
+// detected first.
+func (d *ImpasseDetector) Recent(n int) []*Impasse {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := make([]*Impasse, 0, len(d.impasses)+len(d.resolvedImpasses))
+	seen := make(map[string]bool)
+	for _, impasses := range []map[string]*Impasse{d.impasses, d.resolvedImpasses} {
+		for id, imp := range impasses {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, imp)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].DetectedAt.Equal(result[j].DetectedAt) {
+			return result[i].DetectedAt.After(result[j].DetectedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
 // GetByType returns impasses of a specific type.
 func (d *ImpasseDetector) GetByType(impasseType ImpasseType) []*Impasse {
 	d.mu.RLock()
@@ -956,7 +1059,7 @@ func (d *ImpasseDetector) Snapshot() *ImpasseSnapshot {
 	defer d.mu.RUnlock()
 
 	snapshot := &ImpasseSnapshot{
-		Timestamp:      time.Now(),
+		Timestamp:      d.clock.Now(),
 		ActiveCount:    len(d.activeImpasses),
 		ResolvedCount:  len(d.resolvedImpasses),
 		ActiveImpasses: make([]*Impasse, 0, len(d.activeImpasses)),
@@ -196,6 +196,81 @@ func TestImpasseDetector_DetectTimeout(t *testing.T) {
 	}
 }
 
+func TestImpasseDetector_DetectTimeoutSince(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	config := DefaultImpasseDetectorConfig()
+	config.TimeoutThreshold = time.Minute
+	config.Clock = clock
+	detector := NewImpasseDetector(config, nil)
+
+	start := clock.Now()
+	clock.Advance(30 * time.Second)
+	if imp := detector.DetectTimeoutSince("goal-1", start); imp != nil {
+		t.Error("Should not detect timeout below threshold")
+	}
+
+	clock.Advance(time.Minute)
+	imp := detector.DetectTimeoutSince("goal-1", start)
+	if imp == nil {
+		t.Fatal("Should detect timeout above threshold")
+	}
+	if !imp.DetectedAt.Equal(clock.Now()) {
+		t.Errorf("Expected DetectedAt %v, got %v", clock.Now(), imp.DetectedAt)
+	}
+}
+
+func TestImpasseDetector_GoalLimits(t *testing.T) {
+	goals := NewGoalStack(DefaultGoalStackConfig())
+	detector := NewImpasseDetector(nil, goals)
+
+	past := time.Now().Add(-time.Minute)
+	goals.Push(&Goal{ID: "late", Deadline: &past})
+	goals.Push(&Goal{ID: "costly", Budget: GoalBudget{MaxCost: 1}})
+	goals.Push(&Goal{ID: "wordy", Budget: GoalBudget{MaxTokens: 10}})
+
+	goals.SweepDeadlines()
+	goals.Spend("costly", 0, 1.5)
+	goals.Spend("wordy", 20, 0)
+
+	if imps := detector.GetByGoal("late"); len(imps) != 1 || imps[0].Type != ImpasseTimeout {
+		t.Errorf("Expected a timeout impasse for the late goal, got %+v", imps)
+	}
+	costly := detector.GetByGoal("costly")
+	if len(costly) != 1 || costly[0].Type != ImpasseCapacity || costly[0].Context["resource"] != "cost" {
+		t.Errorf("Expected a cost capacity impasse, got %+v", costly)
+	}
+	wordy := detector.GetByGoal("wordy")
+	if len(wordy) != 1 || wordy[0].Description != "token budget exhausted: 20 of 10" {
+		t.Errorf("Expected a token capacity impasse, got %+v", wordy)
+	}
+
+	// Aborting resolves the impasse by failing the doomed goal
+	if _, err := detector.ResolveWith(costly[0].ID, StrategyAbort); err != nil {
+		t.Fatalf("ResolveWith failed: %v", err)
+	}
+	if goal, _ := goals.Get("costly"); goal.Status != GoalFailed {
+		t.Errorf("Expected the aborted goal FAILED, got %s", goal.Status)
+	}
+}
+
+func TestImpasseDetector_BackoffSchedule(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	config := DefaultImpasseDetectorConfig()
+	config.BackoffBase = time.Second
+	config.BackoffMax = 5 * time.Second
+	config.Clock = clock
+	detector := NewImpasseDetector(config, nil)
+
+	imp := detector.DetectTimeout("goal-1", time.Hour)
+	for retries, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		imp.RetryCount = retries
+		result := detector.resolveBackoff(imp)
+		if got := result.RetryAt.Sub(clock.Now()); got != want {
+			t.Errorf("Expected backoff %v after %d retries, got %v", want, retries, got)
+		}
+	}
+}
+
 func TestImpasseDetector_Resolution(t *testing.T) {
 	detector := NewImpasseDetector(nil, nil)
 
@@ -225,6 +300,36 @@ func TestImpasseDetector_Resolution(t *testing.T) {
 		result.Strategy, result.SelectedCandidate, result.Message)
 }
 
+func TestImpasseDetector_ResolveWith(t *testing.T) {
+	detector := NewImpasseDetector(nil, nil)
+	imp := detector.DetectConflict("goal-1", map[string]interface{}{"a": "yes", "b": "no"})
+
+	result, err := detector.ResolveWith(imp.ID, StrategyAsk)
+	if err != nil {
+		t.Fatalf("ResolveWith failed: %v", err)
+	}
+	if !result.Success || result.Strategy != StrategyAsk {
+		t.Errorf("Expected a successful ask resolution, got %+v", result)
+	}
+	if resolved, _ := detector.Get(imp.ID); !resolved.IsResolved() || resolved.Resolution != StrategyAsk {
+		t.Error("Impasse should be resolved by asking")
+	}
+
+	if _, err := detector.ResolveWith(imp.ID, StrategyAsk); err == nil {
+		t.Error("Expected error resolving an impasse twice")
+	}
+
+	// A failing strategy leaves the impasse active
+	failed := detector.DetectFailure("goal-2", "agent-1", "crashed")
+	result, err = detector.ResolveWith(failed.ID, StrategyDecompose)
+	if err != nil || result.Success {
+		t.Errorf("Expected decomposition without a goal stack to fail, got %+v and %v", result, err)
+	}
+	if detector.ActiveCount() != 1 {
+		t.Errorf("Expected 1 active impasse, got %d", detector.ActiveCount())
+	}
+}
+
 func TestImpasseDetector_ResolutionStrategies(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -223,7 +223,7 @@ func (ai *AdvancedIntegrator) ProcessRequest(ctx context.Context, request *Integ
 	startTime := time.Now()
 
 	result := &IntegrationResult{
-		ID:               fmt.Sprintf("result-%s-%d", request.ID, time.Now().Unix()),
+		ID:               NewID("result-" + request.ID),
 		RequestID:        request.ID,
 		ComponentResults: make(map[string]interface{}),
 		Timestamp:        time.Now(),
@@ -327,7 +327,7 @@ func (ai *AdvancedIntegrator) executeStrategyPlanning(ctx context.Context, goal
 // synthesizeDecision creates decision from all component results
 func (ai *AdvancedIntegrator) synthesizeDecision(result *IntegrationResult) *Decision {
 	decision := &Decision{
-		ID:           fmt.Sprintf("decision-%d", time.Now().Unix()),
+		ID:           NewID("decision"),
 		Reasoning:    make([]string, 0),
 		Alternatives: make([]Alternative, 0),
 		Timestamp:    time.Now(),
@@ -0,0 +1,521 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements leader election for singleton background jobs.
+//
+// Consolidation, scheduling and snapshotting must run on exactly one replica
+// of a multi-replica deployment. Replicas compete for a time-bounded lease in
+// a shared store; the holder is the leader and keeps renewing it. If the
+// leader stops renewing (crash, partition), the lease expires and another
+// replica takes over. A leader that can't renew within RenewDeadline steps
+// down before the lease expires, so two replicas never believe they lead.
+//
+// The lease store is pluggable. KubernetesLeaseLock uses a
+// coordination.k8s.io/v1 Lease object; MemoryLeaseLock serves single-process
+// deployments and tests.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrElectionRunning is returned when running an elector twice
+	ErrElectionRunning = errdefs.New(errdefs.ErrConflict, "leader election already running")
+	// ErrLeaseAPI is returned when the lease store responds unexpectedly
+	ErrLeaseAPI = errdefs.New(errdefs.ErrProviderFailure, "lease API error")
+)
+
+// LeaseLock is a shared store holding a single time-bounded lease.
+type LeaseLock interface {
+	// TryAcquire takes the lease for holder if it is free or expired, or
+	// renews it if holder already has it. It returns false if another
+	// holder's lease is still valid.
+	TryAcquire(ctx context.Context, holder string, duration time.Duration) (bool, error)
+	// Release gives the lease up early if holder has it.
+	Release(ctx context.Context, holder string) error
+}
+
+// ============================================================================
+// In-Memory Lease
+// ============================================================================
+
+// MemoryLeaseLock is a lease held in process memory. Electors sharing one
+// lock coordinate with each other; it suits single-replica deployments.
+type MemoryLeaseLock struct {
+	holder    string
+	expiresAt time.Time
+	// now reads the clock; replaceable for tests
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+// NewMemoryLeaseLock creates a free in-memory lease.
+func NewMemoryLeaseLock() *MemoryLeaseLock {
+	return &MemoryLeaseLock{now: time.Now}
+}
+
+// TryAcquire takes or renews the lease.
+func (l *MemoryLeaseLock) TryAcquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.holder != "" && l.holder != holder && now.Before(l.expiresAt) {
+		return false, nil
+	}
+	l.holder = holder
+	l.expiresAt = now.Add(duration)
+	return true, nil
+}
+
+// Release frees the lease if holder has it.
+func (l *MemoryLeaseLock) Release(ctx context.Context, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == holder {
+		l.holder = ""
+		l.expiresAt = time.Time{}
+	}
+	return nil
+}
+
+// ============================================================================
+// Kubernetes Lease
+// ============================================================================
+
+// Service account paths mounted into every pod
+const (
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// k8sMicroTime is the MicroTime format of the Kubernetes API.
+const k8sMicroTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// k8sLease is the subset of a coordination.k8s.io/v1 Lease used here.
+type k8sLease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   k8sObjectMeta `json:"metadata"`
+	Spec       k8sLeaseSpec  `json:"spec"`
+}
+
+type k8sObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type k8sLeaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int32  `json:"leaseTransitions"`
+}
+
+// KubernetesLeaseLock stores the lease in a Kubernetes Lease object.
+// Updates carry the object's resourceVersion, so the API server rejects
+// a write based on a stale read and only one contender wins.
+type KubernetesLeaseLock struct {
+	// Host is the API server URL, e.g. https://kubernetes.default.svc
+	Host string
+	// Token is the bearer token for the API server
+	Token     string
+	Name      string
+	Namespace string
+	Client    *http.Client
+
+	// now reads the clock; replaceable for tests
+	now func() time.Time
+}
+
+// NewKubernetesLeaseLock creates a lease lock against an API server.
+func NewKubernetesLeaseLock(host, token, namespace, name string, client *http.Client) *KubernetesLeaseLock {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KubernetesLeaseLock{
+		Host:      host,
+		Token:     token,
+		Name:      name,
+		Namespace: namespace,
+		Client:    client,
+		now:       time.Now,
+	}
+}
+
+// NewInClusterLeaseLock creates a lease lock using the pod's service account.
+func NewInClusterLeaseLock(namespace, name string) (*KubernetesLeaseLock, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("%w: not running in a Kubernetes cluster", ErrLeaseAPI)
+	}
+	token, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account token: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("%w: invalid service account CA", ErrLeaseAPI)
+	}
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	return NewKubernetesLeaseLock("https://"+host+":"+port, string(bytes.TrimSpace(token)), namespace, name, client), nil
+}
+
+// TryAcquire takes or renews the lease.
+func (l *KubernetesLeaseLock) TryAcquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	lease, found, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := l.now()
+	seconds := int32(duration.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	if !found {
+		lease = &k8sLease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   k8sObjectMeta{Name: l.Name, Namespace: l.Namespace},
+			Spec: k8sLeaseSpec{
+				HolderIdentity:       holder,
+				LeaseDurationSeconds: seconds,
+				AcquireTime:          now.UTC().Format(k8sMicroTime),
+				RenewTime:            now.UTC().Format(k8sMicroTime),
+			},
+		}
+		return l.write(ctx, http.MethodPost, l.collectionURL(), lease)
+	}
+
+	if lease.Spec.HolderIdentity != holder {
+		if lease.Spec.HolderIdentity != "" && !l.expired(lease, now) {
+			return false, nil
+		}
+		lease.Spec.HolderIdentity = holder
+		lease.Spec.AcquireTime = now.UTC().Format(k8sMicroTime)
+		lease.Spec.LeaseTransitions++
+	}
+	lease.Spec.LeaseDurationSeconds = seconds
+	lease.Spec.RenewTime = now.UTC().Format(k8sMicroTime)
+	return l.write(ctx, http.MethodPut, l.objectURL(), lease)
+}
+
+// Release clears the holder so another replica can take over immediately.
+func (l *KubernetesLeaseLock) Release(ctx context.Context, holder string) error {
+	lease, found, err := l.get(ctx)
+	if err != nil || !found || lease.Spec.HolderIdentity != holder {
+		return err
+	}
+	lease.Spec.HolderIdentity = ""
+	_, err = l.write(ctx, http.MethodPut, l.objectURL(), lease)
+	return err
+}
+
+// expired returns true if the lease's holder stopped renewing it.
+func (l *KubernetesLeaseLock) expired(lease *k8sLease, now time.Time) bool {
+	renewed, err := time.Parse(k8sMicroTime, lease.Spec.RenewTime)
+	if err != nil {
+		// An unreadable renew time can't prove the holder is alive
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+func (l *KubernetesLeaseLock) collectionURL() string {
+	return fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", l.Host, l.Namespace)
+}
+
+func (l *KubernetesLeaseLock) objectURL() string {
+	return l.collectionURL() + "/" + l.Name
+}
+
+// get reads the lease, reporting whether it exists.
+func (l *KubernetesLeaseLock) get(ctx context.Context) (*k8sLease, bool, error) {
+	resp, err := l.do(ctx, http.MethodGet, l.objectURL(), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var lease k8sLease
+		if err := json.NewDecoder(resp.Body).Decode(&lease); err != nil {
+			return nil, false, fmt.Errorf("%w: decoding lease: %v", ErrLeaseAPI, err)
+		}
+		return &lease, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, leaseAPIError(resp)
+	}
+}
+
+// write creates or updates the lease. A conflict means another replica
+// wrote first, which is a lost race rather than an error.
+func (l *KubernetesLeaseLock) write(ctx context.Context, method, url string, lease *k8sLease) (bool, error) {
+	body, err := json.Marshal(lease)
+	if err != nil {
+		return false, fmt.Errorf("encoding lease: %w", err)
+	}
+	resp, err := l.do(ctx, method, url, body)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, leaseAPIError(resp)
+	}
+}
+
+// do sends an authenticated request to the API server.
+func (l *KubernetesLeaseLock) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if l.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+l.Token)
+	}
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLeaseAPI, err)
+	}
+	return resp, nil
+}
+
+// leaseAPIError describes an unexpected API server response.
+func leaseAPIError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%w: %s: %s", ErrLeaseAPI, resp.Status, bytes.TrimSpace(msg))
+}
+
+// ============================================================================
+// Leader Elector
+// ============================================================================
+
+// LeaderElectionConfig configures a LeaderElector.
+type LeaderElectionConfig struct {
+	// Identity uniquely names this replica, e.g. the pod name
+	Identity string
+	// LeaseDuration is how long a lease stays valid without renewal
+	LeaseDuration time.Duration
+	// RenewDeadline is how long a leader keeps leading without a successful
+	// renewal; it must be shorter than LeaseDuration
+	RenewDeadline time.Duration
+	// RetryPeriod is the time between acquire and renew attempts
+	RetryPeriod time.Duration
+}
+
+// DefaultLeaderElectionConfig returns the timings Kubernetes controllers use.
+func DefaultLeaderElectionConfig(identity string) LeaderElectionConfig {
+	return LeaderElectionConfig{
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+// LeaderElectionStats contains leader election statistics.
+type LeaderElectionStats struct {
+	IsLeader bool
+	// LeaderSince is when this replica last became leader
+	LeaderSince time.Time
+	// Terms counts how often this replica became leader
+	Terms int64
+	// AcquireFailures counts lease store errors
+	AcquireFailures int64
+}
+
+// singletonJob is a background job run only while leading.
+type singletonJob struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// LeaderElector campaigns for a lease and runs singleton jobs while it holds it.
+type LeaderElector struct {
+	config LeaderElectionConfig
+	lock   LeaseLock
+	jobs   []singletonJob
+
+	leader      bool
+	leaderSince time.Time
+	stats       LeaderElectionStats
+	running     bool
+
+	// cancel stops the current term's jobs; wg waits for them to return
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.RWMutex
+}
+
+// NewLeaderElector creates an elector for a lease.
+func NewLeaderElector(lock LeaseLock, config LeaderElectionConfig) *LeaderElector {
+	if config.RetryPeriod <= 0 {
+		config.RetryPeriod = 2 * time.Second
+	}
+	if config.RenewDeadline <= 0 || config.RenewDeadline >= config.LeaseDuration {
+		config.RenewDeadline = config.LeaseDuration * 2 / 3
+	}
+	return &LeaderElector{
+		config: config,
+		lock:   lock,
+		jobs:   make([]singletonJob, 0),
+	}
+}
+
+// Register adds a job that runs while this replica leads. The job's
+// context is cancelled when leadership is lost; it is restarted on the
+// next term. Jobs registered mid-term start on the next term.
+func (e *LeaderElector) Register(name string, job func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.jobs = append(e.jobs, singletonJob{name: name, run: job})
+}
+
+// IsLeader returns true while this replica holds the lease.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Identity returns this replica's identity.
+func (e *LeaderElector) Identity() string {
+	return e.config.Identity
+}
+
+// GetStats returns leader election statistics.
+func (e *LeaderElector) GetStats() LeaderElectionStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stats := e.stats
+	stats.IsLeader = e.leader
+	stats.LeaderSince = e.leaderSince
+	return stats
+}
+
+// Run campaigns until ctx is done, then stops any jobs and releases the lease.
+func (e *LeaderElector) Run(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return ErrElectionRunning
+	}
+	e.running = true
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.running = false
+		e.mu.Unlock()
+	}()
+
+	var lastRenew time.Time
+	for {
+		acquired, err := e.lock.TryAcquire(ctx, e.config.Identity, e.config.LeaseDuration)
+		now := time.Now()
+		if err != nil {
+			e.mu.Lock()
+			e.stats.AcquireFailures++
+			e.mu.Unlock()
+		}
+
+		switch {
+		case acquired:
+			lastRenew = now
+			if !e.IsLeader() {
+				e.startLeading(ctx, now)
+			}
+		case e.IsLeader() && (err == nil || now.Sub(lastRenew) >= e.config.RenewDeadline):
+			// Another replica holds the lease, or ours is about to expire
+			e.stopLeading()
+		}
+
+		select {
+		case <-ctx.Done():
+			wasLeader := e.IsLeader()
+			e.stopLeading()
+			if wasLeader {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), e.config.RetryPeriod)
+				defer cancel()
+				return e.lock.Release(releaseCtx, e.config.Identity)
+			}
+			return nil
+		case <-time.After(e.config.RetryPeriod):
+		}
+	}
+}
+
+// startLeading begins a term and starts every registered job.
+func (e *LeaderElector) startLeading(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	termCtx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.leader = true
+	e.leaderSince = now
+	e.stats.Terms++
+
+	for _, job := range e.jobs {
+		e.wg.Add(1)
+		go func(job singletonJob) {
+			defer e.wg.Done()
+			job.run(termCtx)
+		}(job)
+	}
+}
+
+// stopLeading ends the term and waits for its jobs to return.
+func (e *LeaderElector) stopLeading() {
+	e.mu.Lock()
+	if !e.leader {
+		e.mu.Unlock()
+		return
+	}
+	e.leader = false
+	cancel := e.cancel
+	e.cancel = nil
+	e.mu.Unlock()
+
+	cancel()
+	e.wg.Wait()
+}
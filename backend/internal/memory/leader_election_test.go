@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Lease Lock Tests
+// ============================================================================
+
+func TestMemoryLeaseLock_TryAcquire(t *testing.T) {
+	lock := NewMemoryLeaseLock()
+	now := time.Unix(1000, 0)
+	lock.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if ok, _ := lock.TryAcquire(ctx, "a", 10*time.Second); !ok {
+		t.Fatal("Expected a to acquire free lease")
+	}
+	if ok, _ := lock.TryAcquire(ctx, "b", 10*time.Second); ok {
+		t.Error("Expected b to be refused while a's lease is valid")
+	}
+	if ok, _ := lock.TryAcquire(ctx, "a", 10*time.Second); !ok {
+		t.Error("Expected a to renew its own lease")
+	}
+
+	now = now.Add(11 * time.Second)
+	if ok, _ := lock.TryAcquire(ctx, "b", 10*time.Second); !ok {
+		t.Error("Expected b to take over expired lease")
+	}
+
+	lock.Release(ctx, "a")
+	if ok, _ := lock.TryAcquire(ctx, "a", 10*time.Second); ok {
+		t.Error("Expected release by non-holder to be ignored")
+	}
+	lock.Release(ctx, "b")
+	if ok, _ := lock.TryAcquire(ctx, "a", 10*time.Second); !ok {
+		t.Error("Expected a to acquire released lease")
+	}
+}
+
+// fakeLeaseServer serves a single Lease with resourceVersion checks.
+type fakeLeaseServer struct {
+	lease   *k8sLease
+	version int
+	writes  int
+	mu      sync.Mutex
+}
+
+func (s *fakeLeaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if s.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(s.lease)
+	case http.MethodPost, http.MethodPut:
+		var lease k8sLease
+		json.NewDecoder(r.Body).Decode(&lease)
+		if r.Method == http.MethodPost && s.lease != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if r.Method == http.MethodPut && lease.Metadata.ResourceVersion != strconv.Itoa(s.version) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.version++
+		s.writes++
+		lease.Metadata.ResourceVersion = strconv.Itoa(s.version)
+		s.lease = &lease
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(s.lease)
+	}
+}
+
+func TestKubernetesLeaseLock_TryAcquire(t *testing.T) {
+	fake := &fakeLeaseServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	now := time.Unix(1000, 0)
+	newLock := func() *KubernetesLeaseLock {
+		lock := NewKubernetesLeaseLock(server.URL, "secret", "default", "mnemonic", nil)
+		lock.now = func() time.Time { return now }
+		return lock
+	}
+	a, b := newLock(), newLock()
+	ctx := context.Background()
+
+	if ok, err := a.TryAcquire(ctx, "pod-a", 15*time.Second); !ok || err != nil {
+		t.Fatalf("Expected pod-a to create lease, got %v, %v", ok, err)
+	}
+	if fake.lease.Spec.HolderIdentity != "pod-a" || fake.lease.Spec.LeaseDurationSeconds != 15 {
+		t.Errorf("Expected lease held by pod-a for 15s, got %+v", fake.lease.Spec)
+	}
+	if ok, _ := b.TryAcquire(ctx, "pod-b", 15*time.Second); ok {
+		t.Error("Expected pod-b to be refused while lease is valid")
+	}
+
+	now = now.Add(16 * time.Second)
+	if ok, err := b.TryAcquire(ctx, "pod-b", 15*time.Second); !ok || err != nil {
+		t.Fatalf("Expected pod-b to take over expired lease, got %v, %v", ok, err)
+	}
+	if fake.lease.Spec.LeaseTransitions != 1 {
+		t.Errorf("Expected 1 lease transition, got %d", fake.lease.Spec.LeaseTransitions)
+	}
+
+	if err := b.Release(ctx, "pod-b"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if fake.lease.Spec.HolderIdentity != "" {
+		t.Errorf("Expected released lease to have no holder, got %s", fake.lease.Spec.HolderIdentity)
+	}
+	if ok, _ := a.TryAcquire(ctx, "pod-a", 15*time.Second); !ok {
+		t.Error("Expected pod-a to acquire released lease")
+	}
+}
+
+func TestKubernetesLeaseLock_Errors(t *testing.T) {
+	server := httptest.NewServer(&fakeLeaseServer{})
+	defer server.Close()
+
+	lock := NewKubernetesLeaseLock(server.URL, "wrong", "default", "mnemonic", nil)
+	if _, err := lock.TryAcquire(context.Background(), "pod-a", time.Second); !errors.Is(err, ErrLeaseAPI) {
+		t.Errorf("Expected ErrLeaseAPI, got %v", err)
+	}
+}
+
+// ============================================================================
+// Leader Elector Tests
+// ============================================================================
+
+// newTestElector creates an elector with short timings.
+func newTestElector(lock LeaseLock, identity string) *LeaderElector {
+	return NewLeaderElector(lock, LeaderElectionConfig{
+		Identity:      identity,
+		LeaseDuration: 200 * time.Millisecond,
+		RenewDeadline: 150 * time.Millisecond,
+		RetryPeriod:   10 * time.Millisecond,
+	})
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestLeaderElector_SingleLeader(t *testing.T) {
+	lock := NewMemoryLeaseLock()
+	var running int32
+	var maxRunning int32
+	job := func(ctx context.Context) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&maxRunning)
+			if n <= old || atomic.CompareAndSwapInt32(&maxRunning, old, n) {
+				break
+			}
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&running, -1)
+	}
+
+	a, b := newTestElector(lock, "a"), newTestElector(lock, "b")
+	a.Register("consolidation", job)
+	b.Register("consolidation", job)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	doneA := make(chan error, 1)
+	go func() { doneA <- a.Run(ctxA) }()
+	if !waitFor(a.IsLeader) {
+		t.Fatal("Expected a to become leader")
+	}
+	go b.Run(ctxB)
+
+	time.Sleep(50 * time.Millisecond)
+	if b.IsLeader() {
+		t.Error("Expected b to follow while a leads")
+	}
+	if atomic.LoadInt32(&running) != 1 {
+		t.Errorf("Expected 1 running job, got %d", atomic.LoadInt32(&running))
+	}
+
+	// a shuts down and releases; b takes over
+	cancelA()
+	if err := <-doneA; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+	if a.IsLeader() {
+		t.Error("Expected a to step down on shutdown")
+	}
+	if !waitFor(b.IsLeader) {
+		t.Fatal("Expected b to take over")
+	}
+	if !waitFor(func() bool { return atomic.LoadInt32(&running) == 1 }) {
+		t.Errorf("Expected job restarted on b, got %d running", atomic.LoadInt32(&running))
+	}
+	if atomic.LoadInt32(&maxRunning) != 1 {
+		t.Errorf("Expected job never to run twice at once, got %d", atomic.LoadInt32(&maxRunning))
+	}
+	if b.GetStats().Terms != 1 {
+		t.Errorf("Expected 1 term on b, got %d", b.GetStats().Terms)
+	}
+}
+
+// flakyLease fails every acquire once broken.
+type flakyLease struct {
+	LeaseLock
+	broken atomic.Bool
+}
+
+func (l *flakyLease) TryAcquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	if l.broken.Load() {
+		return false, errors.New("store unreachable")
+	}
+	return l.LeaseLock.TryAcquire(ctx, holder, duration)
+}
+
+func TestLeaderElector_RenewDeadline(t *testing.T) {
+	lock := &flakyLease{LeaseLock: NewMemoryLeaseLock()}
+	e := newTestElector(lock, "a")
+	stopped := make(chan struct{})
+	e.Register("snapshot", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+	if !waitFor(e.IsLeader) {
+		t.Fatal("Expected to become leader")
+	}
+
+	// Transient errors are tolerated until RenewDeadline passes
+	lock.broken.Store(true)
+	time.Sleep(50 * time.Millisecond)
+	if !e.IsLeader() {
+		t.Error("Expected to keep leading within renew deadline")
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected job cancelled after renew deadline")
+	}
+	if e.IsLeader() {
+		t.Error("Expected to step down after renew deadline")
+	}
+	if e.GetStats().AcquireFailures == 0 {
+		t.Error("Expected acquire failures recorded")
+	}
+	if err := e.Run(ctx); !errors.Is(err, ErrElectionRunning) {
+		t.Errorf("Expected ErrElectionRunning, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements versioned migrations for persisted memory formats.
+//
+// Each persisted format has a Migrator holding numbered migrations. A
+// format starts at version 1; migration N upgrades a document from N-1 to
+// N, and its Down reverts it. Migrations operate on the decoded JSON
+// document, so they keep working after the Go types have moved on.
+// Documents are migrated on load, and eacctl can rewrite files in place or
+// roll them back for a downgrade.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for format versions with no migration path
+	ErrUnsupportedVersion = errdefs.New(errdefs.ErrInvalidArgument, "unsupported format version")
+	// ErrIrreversibleMigration is returned when migrating down past a migration without Down
+	ErrIrreversibleMigration = errdefs.New(errdefs.ErrInvalidArgument, "migration cannot be reverted")
+)
+
+// Migration directions.
+const (
+	MigrationUp   = "up"
+	MigrationDown = "down"
+)
+
+// Migration converts a document between adjacent format versions.
+type Migration struct {
+	// Version is the format version Up produces
+	Version     int
+	Description string
+	// Up upgrades a document from Version-1 to Version in place
+	Up func(doc map[string]interface{}) error
+	// Down reverts a document from Version to Version-1; nil if irreversible
+	Down func(doc map[string]interface{}) error
+}
+
+// MigrationStep is one migration in a plan.
+type MigrationStep struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Direction   string `json:"direction"`
+	// From and To are the document versions before and after the step
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Migrator holds the migrations of one persisted format.
+type Migrator struct {
+	format     string
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator. Migrations must be numbered 2, 3, ...
+// without gaps.
+func NewMigrator(format string, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version != i+2 {
+			return nil, fmt.Errorf("%s migrations: expected version %d, got %d", format, i+2, m.Version)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("%s migration %d has no Up", format, m.Version)
+		}
+	}
+	return &Migrator{format: format, migrations: sorted}, nil
+}
+
+// mustMigrator is NewMigrator for statically defined migrations.
+func mustMigrator(format string, migrations []Migration) *Migrator {
+	m, err := NewMigrator(format, migrations)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Format returns the name of the migrated format.
+func (m *Migrator) Format() string {
+	return m.format
+}
+
+// Latest returns the current format version.
+func (m *Migrator) Latest() int {
+	return len(m.migrations) + 1
+}
+
+// Plan returns the steps that take a document from one version to another.
+func (m *Migrator) Plan(from, to int) ([]MigrationStep, error) {
+	latest := m.Latest()
+	if from < 1 || from > latest {
+		return nil, fmt.Errorf("%w: %s version %d (supported 1-%d)", ErrUnsupportedVersion, m.format, from, latest)
+	}
+	if to < 1 || to > latest {
+		return nil, fmt.Errorf("%w: %s target version %d (supported 1-%d)", ErrUnsupportedVersion, m.format, to, latest)
+	}
+
+	steps := make([]MigrationStep, 0)
+	for v := from + 1; v <= to; v++ {
+		mig := m.migrations[v-2]
+		steps = append(steps, MigrationStep{
+			Version: v, Description: mig.Description, Direction: MigrationUp, From: v - 1, To: v,
+		})
+	}
+	for v := from; v > to; v-- {
+		mig := m.migrations[v-2]
+		if mig.Down == nil {
+			return nil, fmt.Errorf("%w: %s migration %d (%s)", ErrIrreversibleMigration, m.format, v, mig.Description)
+		}
+		steps = append(steps, MigrationStep{
+			Version: v, Description: mig.Description, Direction: MigrationDown, From: v, To: v - 1,
+		})
+	}
+	return steps, nil
+}
+
+// Migrate applies the planned steps to doc in place. On error doc may be
+// partially migrated and should be discarded.
+func (m *Migrator) Migrate(doc map[string]interface{}, from, to int) ([]MigrationStep, error) {
+	steps, err := m.Plan(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		mig := m.migrations[step.Version-2]
+		apply := mig.Up
+		if step.Direction == MigrationDown {
+			apply = mig.Down
+		}
+		if err := apply(doc); err != nil {
+			return nil, fmt.Errorf("%s migration %d %s (%s): %w", m.format, step.Version, step.Direction, mig.Description, err)
+		}
+	}
+	return steps, nil
+}
+
+// MigrateJSON migrates an encoded JSON object and re-encodes it.
+func (m *Migrator) MigrateJSON(raw []byte, from, to int) ([]byte, []MigrationStep, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, fmt.Errorf("decoding %s: %w", m.format, err)
+	}
+	steps, err := m.Migrate(doc, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding %s: %w", m.format, err)
+	}
+	return out, steps, nil
+}
+
+// ============================================================================
+// Document Helpers
+// ============================================================================
+
+// migrateObjects applies fn to each object in the array doc[key].
+func migrateObjects(doc map[string]interface{}, key string, fn func(obj map[string]interface{}) error) error {
+	raw, ok := doc[key]
+	if !ok || raw == nil {
+		return nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not an array", key)
+	}
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s[%d] is not an object", key, i)
+		}
+		if err := fn(obj); err != nil {
+			return fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+	}
+	return nil
+}
+
+// renameFields moves fields of obj according to renames (old to new name).
+func renameFields(obj map[string]interface{}, renames map[string]string) {
+	for from, to := range renames {
+		if value, ok := obj[from]; ok {
+			delete(obj, from)
+			obj[to] = value
+		}
+	}
+}
+
+// invertRenames swaps the keys and values of a rename table.
+func invertRenames(renames map[string]string) map[string]string {
+	inverted := make(map[string]string, len(renames))
+	for from, to := range renames {
+		inverted[to] = from
+	}
+	return inverted
+}
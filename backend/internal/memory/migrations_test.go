@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// ============================================================================
+// Migrator Tests
+// ============================================================================
+
+// testMigrator renames "name" to "title" in v2 and adds "tags" in v3.
+func testMigrator(t *testing.T) *Migrator {
+	m, err := NewMigrator("test", []Migration{
+		{
+			Version:     3,
+			Description: "add tags",
+			Up: func(doc map[string]interface{}) error {
+				doc["tags"] = []interface{}{}
+				return nil
+			},
+		},
+		{
+			Version:     2,
+			Description: "rename name to title",
+			Up: func(doc map[string]interface{}) error {
+				renameFields(doc, map[string]string{"name": "title"})
+				return nil
+			},
+			Down: func(doc map[string]interface{}) error {
+				renameFields(doc, map[string]string{"title": "name"})
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %v", err)
+	}
+	return m
+}
+
+func TestNewMigrator(t *testing.T) {
+	if m := testMigrator(t); m.Latest() != 3 {
+		t.Errorf("Expected latest version 3, got %d", m.Latest())
+	}
+	if m, _ := NewMigrator("empty", nil); m.Latest() != 1 {
+		t.Errorf("Expected latest version 1 without migrations, got %d", m.Latest())
+	}
+
+	noop := func(doc map[string]interface{}) error { return nil }
+	if _, err := NewMigrator("gap", []Migration{{Version: 2, Up: noop}, {Version: 4, Up: noop}}); err == nil {
+		t.Error("Expected error for a gap in versions")
+	}
+	if _, err := NewMigrator("no-up", []Migration{{Version: 2}}); err == nil {
+		t.Error("Expected error for a migration without Up")
+	}
+}
+
+func TestMigrator_Plan(t *testing.T) {
+	m := testMigrator(t)
+
+	steps, err := m.Plan(1, 3)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(steps) != 2 || steps[0].Version != 2 || steps[1].Version != 3 || steps[0].Direction != MigrationUp {
+		t.Errorf("Expected up steps 2 and 3, got %+v", steps)
+	}
+
+	steps, _ = m.Plan(2, 2)
+	if len(steps) != 0 {
+		t.Errorf("Expected no steps at the same version, got %+v", steps)
+	}
+
+	if _, err := m.Plan(3, 1); !errors.Is(err, ErrIrreversibleMigration) {
+		t.Errorf("Expected ErrIrreversibleMigration, got %v", err)
+	}
+	if _, err := m.Plan(4, 3); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion for a newer format, got %v", err)
+	}
+	if _, err := m.Plan(1, 0); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion for target 0, got %v", err)
+	}
+}
+
+func TestMigrator_MigrateJSON(t *testing.T) {
+	m := testMigrator(t)
+
+	raw, steps, err := m.MigrateJSON([]byte(`{"name":"apex"}`), 1, 3)
+	if err != nil {
+		t.Fatalf("MigrateJSON failed: %v", err)
+	}
+	var doc map[string]interface{}
+	json.Unmarshal(raw, &doc)
+	if len(steps) != 2 || doc["title"] != "apex" || doc["name"] != nil || doc["tags"] == nil {
+		t.Errorf("Expected renamed title and tags, got %s", raw)
+	}
+
+	raw, _, err = m.MigrateJSON([]byte(`{"title":"apex"}`), 2, 1)
+	if err != nil {
+		t.Fatalf("MigrateJSON down failed: %v", err)
+	}
+	if string(raw) != `{"name":"apex"}` {
+		t.Errorf("Expected name restored, got %s", raw)
+	}
+
+	if _, _, err := m.MigrateJSON([]byte(`[1]`), 1, 2); err == nil {
+		t.Error("Expected error for a non-object document")
+	}
+}
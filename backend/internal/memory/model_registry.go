@@ -0,0 +1,696 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the model registry for learned artifacts.
+//
+// The parameters the collective learns - attention weights by category and
+// agent, the hybrid router's blend of routing signals, the calibration
+// table answers' confidences are remapped with, and each agent's tuned
+// sampling parameters - live in the structures
+// that use them. The registry keeps named, versioned copies of them. Each
+// model is bound to the structure it comes from: a snapshot records the
+// live parameters as a new version, an upload adds a candidate version
+// without applying it, and promoting a version applies it. Rolling back
+// re-promotes the version active before the last promotion. Any two
+// versions can be diffed parameter by parameter.
+//
+// A registry opened on a file saves itself there on every change, and a
+// model bound after a restart gets its promoted version applied again.
+
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrModelNotFound is returned for a model no structure is bound to
+	ErrModelNotFound = errdefs.New(errdefs.ErrNotFound, "model not found")
+	// ErrModelVersionNotFound is returned for a version a model lacks
+	ErrModelVersionNotFound = errdefs.New(errdefs.ErrNotFound, "model version not found")
+	// ErrInvalidModelArtifact is returned for parameters that don't match
+	// the model's kind or can't be applied
+	ErrInvalidModelArtifact = errdefs.New(errdefs.ErrInvalidArgument, "invalid model artifact")
+	// ErrNoRollback is returned when no earlier promotion can be rolled
+	// back to
+	ErrNoRollback = errdefs.New(errdefs.ErrConflict, "no earlier promoted version to roll back to")
+)
+
+// maxModelArtifactBody bounds uploaded model artifacts.
+const maxModelArtifactBody = 4 << 20
+
+// ModelKind is the kind of parameters a model holds.
+type ModelKind string
+
+const (
+	// ModelAttentionWeights are attention weights by category and agent
+	ModelAttentionWeights ModelKind = "attention-weights"
+	// ModelRoutingPriors are the hybrid router's blend of signals
+	ModelRoutingPriors ModelKind = "routing-priors"
+	// ModelCalibration is a confidence calibration table
+	ModelCalibration ModelKind = "calibration"
+	// ModelSampling is each agent's temperature and top-p
+	ModelSampling ModelKind = "sampling"
+)
+
+// ModelParameters are a version's learned parameters; only the field of
+// the model's kind is set.
+type ModelParameters struct {
+	Attention   map[string]map[string]float64 `json:"attention,omitempty"`
+	Routing     *RoutingWeights               `json:"routing,omitempty"`
+	Calibration *CalibrationTable             `json:"calibration,omitempty"`
+	Sampling    map[string]SamplingParams     `json:"sampling,omitempty"`
+}
+
+// ModelVersion is one version of a model.
+type ModelVersion struct {
+	Name        string    `json:"name"`
+	Kind        ModelKind `json:"kind"`
+	Version     int       `json:"version"`
+	Description string    `json:"description,omitempty"`
+	// Source is "snapshot" for live parameters or "upload"
+	Source     string          `json:"source"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Parameters ModelParameters `json:"parameters"`
+}
+
+// ModelSummary describes a model in the registry.
+type ModelSummary struct {
+	Name string    `json:"name"`
+	Kind ModelKind `json:"kind"`
+	// Active is the promoted version, or 0 before any
+	Active     int       `json:"active"`
+	Latest     int       `json:"latest"`
+	PromotedAt time.Time `json:"promoted_at,omitempty"`
+	// Rollbacks is how many earlier promotions can be rolled back to
+	Rollbacks int `json:"rollbacks"`
+}
+
+// ModelChange is one parameter that differs between two versions. From
+// or To is nil where the parameter is absent.
+type ModelChange struct {
+	Parameter string   `json:"parameter"`
+	From      *float64 `json:"from"`
+	To        *float64 `json:"to"`
+}
+
+// ModelDiff lists the parameters that differ between two versions,
+// sorted by parameter.
+type ModelDiff struct {
+	Name      string        `json:"name"`
+	From      int           `json:"from"`
+	To        int           `json:"to"`
+	Changes   []ModelChange `json:"changes"`
+	Unchanged int           `json:"unchanged"`
+}
+
+// modelBinding connects a model to the structure its parameters live in.
+type modelBinding struct {
+	capture func() ModelParameters
+	apply   func(ModelParameters) error
+}
+
+// registeredModel is a model's versions and promotion history.
+type registeredModel struct {
+	Kind       ModelKind       `json:"kind"`
+	Versions   []*ModelVersion `json:"versions"`
+	Active     int             `json:"active"`
+	PromotedAt time.Time       `json:"promoted_at,omitempty"`
+	// History holds the versions active before each promotion, latest last
+	History []int `json:"history,omitempty"`
+
+	binding *modelBinding
+}
+
+// version returns a version of the model, or nil.
+func (m *registeredModel) version(v int) *ModelVersion {
+	if v < 1 || v > len(m.Versions) {
+		return nil
+	}
+	return m.Versions[v-1]
+}
+
+// ModelRegistry stores versioned learned parameters.
+type ModelRegistry struct {
+	mu     sync.Mutex
+	clock  Clock
+	path   string
+	models map[string]*registeredModel
+}
+
+// NewModelRegistry creates an in-memory registry.
+func NewModelRegistry(clock Clock) *ModelRegistry {
+	return &ModelRegistry{clock: clockOrSystem(clock), models: make(map[string]*registeredModel)}
+}
+
+// OpenModelRegistry opens a registry saved in a file, creating it on the
+// first change if it does not exist.
+func OpenModelRegistry(path string, clock Clock) (*ModelRegistry, error) {
+	r := NewModelRegistry(clock)
+	r.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model registry: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.models); err != nil {
+		return nil, fmt.Errorf("corrupt model registry %s: %w", filepath.Base(path), err)
+	}
+	if r.models == nil {
+		r.models = make(map[string]*registeredModel)
+	}
+	return r, nil
+}
+
+// BindAttention binds a model to an attention index's weights.
+func (r *ModelRegistry) BindAttention(name string, idx *CollaborativeAttentionIndex) error {
+	return r.bind(name, ModelAttentionWeights, &modelBinding{
+		capture: func() ModelParameters { return ModelParameters{Attention: idx.Weights()} },
+		apply: func(p ModelParameters) error {
+			if _, ignored := idx.SetWeights(p.Attention); len(ignored) > 0 {
+				log.Printf("Model %s: ignored attention categories %s", name, strings.Join(ignored, ", "))
+			}
+			return nil
+		},
+	})
+}
+
+// BindRouting binds a model to a hybrid router's blend.
+func (r *ModelRegistry) BindRouting(name string, router *HybridRouter) error {
+	return r.bind(name, ModelRoutingPriors, &modelBinding{
+		capture: func() ModelParameters {
+			weights := router.Weights()
+			return ModelParameters{Routing: &weights}
+		},
+		apply: func(p ModelParameters) error {
+			router.SetWeights(*p.Routing)
+			return nil
+		},
+	})
+}
+
+// BindCalibration binds a model to a confidence calibrator's table.
+func (r *ModelRegistry) BindCalibration(name string, calibrator *ConfidenceCalibrator) error {
+	return r.bind(name, ModelCalibration, &modelBinding{
+		capture: func() ModelParameters {
+			table := calibrator.Table()
+			return ModelParameters{Calibration: &table}
+		},
+		apply: func(p ModelParameters) error { return calibrator.SetTable(*p.Calibration) },
+	})
+}
+
+// BindSampling binds a model to a sampling tuner's per-agent parameters.
+func (r *ModelRegistry) BindSampling(name string, tuner *SamplingTuner) error {
+	return r.bind(name, ModelSampling, &modelBinding{
+		capture: func() ModelParameters { return ModelParameters{Sampling: tuner.Params()} },
+		apply:   func(p ModelParameters) error { return tuner.SetParams(p.Sampling) },
+	})
+}
+
+// bind binds a model, applying its promoted version if it has one.
+func (r *ModelRegistry) bind(name string, kind ModelKind, binding *modelBinding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, ok := r.models[name]
+	if !ok {
+		model = &registeredModel{Kind: kind, Versions: make([]*ModelVersion, 0)}
+		r.models[name] = model
+	}
+	if model.Kind != kind {
+		return fmt.Errorf("model %s holds %s, not %s", name, model.Kind, kind)
+	}
+	model.binding = binding
+	if active := model.version(model.Active); active != nil {
+		if err := binding.apply(active.Parameters); err != nil {
+			return fmt.Errorf("failed to apply model %s version %d: %w", name, active.Version, err)
+		}
+	}
+	return nil
+}
+
+// model returns a bound model. Callers hold mu.
+func (r *ModelRegistry) model(name string) (*registeredModel, error) {
+	model, ok := r.models[name]
+	if !ok || model.binding == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, name)
+	}
+	return model, nil
+}
+
+// Models summarizes the bound models, sorted by name.
+func (r *ModelRegistry) Models() []ModelSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summaries := make([]ModelSummary, 0, len(r.models))
+	for name, model := range r.models {
+		if model.binding == nil {
+			continue
+		}
+		summaries = append(summaries, ModelSummary{
+			Name:       name,
+			Kind:       model.Kind,
+			Active:     model.Active,
+			Latest:     len(model.Versions),
+			PromotedAt: model.PromotedAt,
+			Rollbacks:  len(model.History),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries
+}
+
+// Versions returns a model's versions, oldest first.
+func (r *ModelRegistry) Versions(name string) ([]*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*ModelVersion(nil), model.Versions...), nil
+}
+
+// Version returns one version of a model.
+func (r *ModelRegistry) Version(name string, version int) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	v := model.version(version)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %s version %d", ErrModelVersionNotFound, name, version)
+	}
+	return v, nil
+}
+
+// Snapshot records a model's live parameters as a new version, which
+// becomes the active one since it is what is live.
+func (r *ModelRegistry) Snapshot(name, description string) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	version := r.add(name, model, "snapshot", description, model.binding.capture())
+	r.activate(model, version.Version)
+	r.save()
+	return version, nil
+}
+
+// Register adds uploaded parameters as a candidate version of a model,
+// without applying them.
+func (r *ModelRegistry) Register(name, description string, params ModelParameters) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateModelParameters(model.Kind, params); err != nil {
+		return nil, err
+	}
+	version := r.add(name, model, "upload", description, params)
+	r.save()
+	return version, nil
+}
+
+// Promote applies a version of a model and makes it the active one.
+func (r *ModelRegistry) Promote(name string, version int) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	v := model.version(version)
+	if v == nil {
+		return nil, fmt.Errorf("%w: %s version %d", ErrModelVersionNotFound, name, version)
+	}
+	if err := model.binding.apply(v.Parameters); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelArtifact, err)
+	}
+	r.activate(model, version)
+	r.save()
+	return v, nil
+}
+
+// Rollback re-promotes the version that was active before the last
+// promotion.
+func (r *ModelRegistry) Rollback(name string) (*ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(model.History) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoRollback, name)
+	}
+	previous := model.version(model.History[len(model.History)-1])
+	if err := model.binding.apply(previous.Parameters); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelArtifact, err)
+	}
+	model.History = model.History[:len(model.History)-1]
+	model.Active = previous.Version
+	model.PromotedAt = r.clock.Now()
+	r.save()
+	return previous, nil
+}
+
+// Diff compares two versions of a model.
+func (r *ModelRegistry) Diff(name string, from, to int) (*ModelDiff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, err := r.model(name)
+	if err != nil {
+		return nil, err
+	}
+	a, b := model.version(from), model.version(to)
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("%w: %s versions %d and %d", ErrModelVersionNotFound, name, from, to)
+	}
+
+	before, after := flattenModelParameters(a.Parameters), flattenModelParameters(b.Parameters)
+	diff := &ModelDiff{Name: name, From: from, To: to, Changes: make([]ModelChange, 0)}
+	for parameter, x := range before {
+		y, ok := after[parameter]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, ModelChange{Parameter: parameter, From: &x})
+		case x != y:
+			diff.Changes = append(diff.Changes, ModelChange{Parameter: parameter, From: &x, To: &y})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for parameter, y := range after {
+		if _, ok := before[parameter]; !ok {
+			diff.Changes = append(diff.Changes, ModelChange{Parameter: parameter, To: &y})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Parameter < diff.Changes[j].Parameter })
+	return diff, nil
+}
+
+// add appends a version to a model. Callers hold mu.
+func (r *ModelRegistry) add(name string, model *registeredModel, source, description string, params ModelParameters) *ModelVersion {
+	version := &ModelVersion{
+		Name:        name,
+		Kind:        model.Kind,
+		Version:     len(model.Versions) + 1,
+		Description: description,
+		Source:      source,
+		CreatedAt:   r.clock.Now(),
+		Parameters:  params,
+	}
+	model.Versions = append(model.Versions, version)
+	return version
+}
+
+// activate makes a version active, remembering the one it replaces for
+// rollback. Callers hold mu.
+func (r *ModelRegistry) activate(model *registeredModel, version int) {
+	if model.Active != 0 && model.Active != version {
+		model.History = append(model.History, model.Active)
+	}
+	model.Active = version
+	model.PromotedAt = r.clock.Now()
+}
+
+// save writes the registry to its file atomically. A failed save is
+// logged; the change it would have persisted is already live. Callers
+// hold mu.
+func (r *ModelRegistry) save() {
+	if r.path == "" {
+		return
+	}
+	if err := r.write(); err != nil {
+		log.Printf("Error saving model registry: %v", err)
+	}
+}
+
+// write encodes the registry to its file. Callers hold mu.
+func (r *ModelRegistry) write() error {
+	data, err := json.Marshal(r.models)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// validateModelParameters checks parameters hold exactly the kind of a
+// model and can be applied.
+func validateModelParameters(kind ModelKind, p ModelParameters) error {
+	set := 0
+	for _, present := range []bool{p.Attention != nil, p.Routing != nil, p.Calibration != nil, p.Sampling != nil} {
+		if present {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: exactly one of attention, routing, calibration or sampling must be set", ErrInvalidModelArtifact)
+	}
+
+	switch kind {
+	case ModelAttentionWeights:
+		if len(p.Attention) == 0 {
+			return fmt.Errorf("%w: %s models need attention weights", ErrInvalidModelArtifact, kind)
+		}
+		for category, weights := range p.Attention {
+			if !validWeights(weights) {
+				return fmt.Errorf("%w: attention weights of %s must be non-negative and not all zero", ErrInvalidModelArtifact, category)
+			}
+		}
+	case ModelRoutingPriors:
+		if p.Routing == nil {
+			return fmt.Errorf("%w: %s models need routing weights", ErrInvalidModelArtifact, kind)
+		}
+		if !validWeights(map[string]float64{"keyword": p.Routing.Keyword, "similarity": p.Routing.Similarity}) {
+			return fmt.Errorf("%w: routing weights must be non-negative and not all zero", ErrInvalidModelArtifact)
+		}
+	case ModelCalibration:
+		if p.Calibration == nil {
+			return fmt.Errorf("%w: %s models need a calibration table", ErrInvalidModelArtifact, kind)
+		}
+		if err := p.Calibration.validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModelArtifact, err)
+		}
+	case ModelSampling:
+		if p.Sampling == nil {
+			return fmt.Errorf("%w: %s models need sampling parameters", ErrInvalidModelArtifact, kind)
+		}
+		for agent, params := range p.Sampling {
+			if params.Temperature < 0 || params.TopP <= 0 || params.TopP > 1 {
+				return fmt.Errorf("%w: sampling of %s needs a non-negative temperature and a top-p above 0 and at most 1", ErrInvalidModelArtifact, agent)
+			}
+		}
+	}
+	return nil
+}
+
+// flattenModelParameters names each numeric parameter, for diffing:
+// attention.<category>.<agent>, routing.keyword and routing.similarity,
+// calibration.bin<i>.count and .accuracy, and sampling.<agent>.temperature
+// and .top_p.
+func flattenModelParameters(p ModelParameters) map[string]float64 {
+	flat := make(map[string]float64)
+	for category, agents := range p.Attention {
+		for agent, w := range agents {
+			flat["attention."+category+"."+agent] = w
+		}
+	}
+	if p.Routing != nil {
+		flat["routing.keyword"] = p.Routing.Keyword
+		flat["routing.similarity"] = p.Routing.Similarity
+	}
+	if p.Calibration != nil {
+		for i, bin := range p.Calibration.Bins {
+			key := "calibration.bin" + strconv.Itoa(i)
+			flat[key+".count"] = float64(bin.Count)
+			if bin.Count > 0 {
+				flat[key+".accuracy"] = math.Round(float64(bin.Correct)/float64(bin.Count)*1e6) / 1e6
+			}
+		}
+	}
+	for agent, params := range p.Sampling {
+		flat["sampling."+agent+".temperature"] = params.Temperature
+		flat["sampling."+agent+".top_p"] = params.TopP
+	}
+	return flat
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// modelArtifactRequest is the body of POST /admin/memory/registry/{name}.
+type modelArtifactRequest struct {
+	Description string           `json:"description"`
+	Parameters  *ModelParameters `json:"parameters"`
+}
+
+// ServeModels handles GET /admin/memory/registry - lists the models.
+func (r *ModelRegistry) ServeModels(w http.ResponseWriter, req *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, map[string]interface{}{"models": r.Models()})
+}
+
+// ServeVersions handles GET /admin/memory/registry/{name} - lists a
+// model's versions.
+func (r *ModelRegistry) ServeVersions(w http.ResponseWriter, req *http.Request) {
+	versions, err := r.Versions(req.PathValue("name"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, map[string]interface{}{"versions": versions})
+}
+
+// ServeVersion handles GET /admin/memory/registry/{name}/versions/{version}
+// - returns one version of a model.
+func (r *ModelRegistry) ServeVersion(w http.ResponseWriter, req *http.Request) {
+	version, ok := versionParam(w, req.PathValue("version"), "version")
+	if !ok {
+		return
+	}
+	v, err := r.Version(req.PathValue("name"), version)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, v)
+}
+
+// ServeCreate handles POST /admin/memory/registry/{name} - uploads a
+// candidate version when the body has parameters, and snapshots the live
+// parameters otherwise.
+func (r *ModelRegistry) ServeCreate(w http.ResponseWriter, req *http.Request) {
+	var body modelArtifactRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxModelArtifactBody)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	name := req.PathValue("name")
+	var version *ModelVersion
+	var err error
+	if body.Parameters != nil {
+		version, err = r.Register(name, body.Description, *body.Parameters)
+	} else {
+		version, err = r.Snapshot(name, body.Description)
+	}
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusCreated, version)
+}
+
+// ServePromote handles POST
+// /admin/memory/registry/{name}/versions/{version}/promote - applies a
+// version.
+func (r *ModelRegistry) ServePromote(w http.ResponseWriter, req *http.Request) {
+	version, ok := versionParam(w, req.PathValue("version"), "version")
+	if !ok {
+		return
+	}
+	v, err := r.Promote(req.PathValue("name"), version)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, v)
+}
+
+// ServeRollback handles POST /admin/memory/registry/{name}/rollback -
+// re-promotes the version active before the last promotion.
+func (r *ModelRegistry) ServeRollback(w http.ResponseWriter, req *http.Request) {
+	v, err := r.Rollback(req.PathValue("name"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, v)
+}
+
+// ServeDiff handles GET /admin/memory/registry/{name}/diff?from=&to= -
+// compares two versions; to defaults to the active version.
+func (r *ModelRegistry) ServeDiff(w http.ResponseWriter, req *http.Request) {
+	from, ok := versionParam(w, req.URL.Query().Get("from"), "from")
+	if !ok {
+		return
+	}
+	name := req.PathValue("name")
+	to := 0
+	if raw := req.URL.Query().Get("to"); raw != "" {
+		if to, ok = versionParam(w, raw, "to"); !ok {
+			return
+		}
+	} else {
+		for _, model := range r.Models() {
+			if model.Name == name {
+				to = model.Active
+			}
+		}
+	}
+
+	diff, err := r.Diff(name, from, to)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, diff)
+}
+
+// versionParam parses a version number, writing a 400 if it is not one.
+func versionParam(w http.ResponseWriter, raw, name string) (int, bool) {
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		errdefs.WriteError(w, name+" must be a version number", http.StatusBadRequest)
+		return 0, false
+	}
+	return version, true
+}
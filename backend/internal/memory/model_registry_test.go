@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newModelFixture binds the three kinds of model to fresh structures.
+func newModelFixture(t *testing.T, registry *ModelRegistry) (*HybridRouter, *CollaborativeAttentionIndex, *ConfidenceCalibrator) {
+	t.Helper()
+	attention := NewCollaborativeAttentionIndex()
+	router := NewHybridRouter(attention, DefaultHybridRouterConfig())
+	calibrator := NewConfidenceCalibrator(4, 1)
+	if err := registry.BindAttention("attention", attention); err != nil {
+		t.Fatalf("BindAttention failed: %v", err)
+	}
+	if err := registry.BindRouting("routing", router); err != nil {
+		t.Fatalf("BindRouting failed: %v", err)
+	}
+	if err := registry.BindCalibration("calibration", calibrator); err != nil {
+		t.Fatalf("BindCalibration failed: %v", err)
+	}
+	return router, attention, calibrator
+}
+
+func TestModelRegistry_PromoteAndRollback(t *testing.T) {
+	registry := NewModelRegistry(NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	router, _, _ := newModelFixture(t, registry)
+	live := router.Weights()
+
+	v1, err := registry.Snapshot("routing", "baseline")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if v1.Version != 1 || *v1.Parameters.Routing != live || v1.Source != "snapshot" {
+		t.Errorf("expected version 1 of the live blend, got %+v", v1)
+	}
+
+	v2, err := registry.Register("routing", "trained", ModelParameters{Routing: &RoutingWeights{Keyword: 1, Similarity: 3}})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if router.Weights() != live {
+		t.Error("expected an uploaded version not applied before promotion")
+	}
+	if _, err := registry.Promote("routing", v2.Version); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if weights := router.Weights(); weights.Keyword != 0.25 || weights.Similarity != 0.75 {
+		t.Errorf("expected the promoted blend live, got %+v", weights)
+	}
+	if models := registry.Models(); models[2].Name != "routing" || models[2].Active != 2 || models[2].Rollbacks != 1 {
+		t.Errorf("expected routing at version 2 with one rollback, got %+v", models)
+	}
+
+	previous, err := registry.Rollback("routing")
+	if err != nil || previous.Version != 1 || router.Weights() != live {
+		t.Errorf("expected the baseline back after rollback, got %+v (%v)", router.Weights(), err)
+	}
+	if _, err := registry.Rollback("routing"); !errors.Is(err, ErrNoRollback) {
+		t.Errorf("expected ErrNoRollback with nothing left, got %v", err)
+	}
+}
+
+func TestModelRegistry_Diff(t *testing.T) {
+	registry := NewModelRegistry(NewManualClock(time.Now()))
+	_, attention, _ := newModelFixture(t, registry)
+
+	if _, err := registry.Snapshot("attention", ""); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	performance := attention.Weights()["performance"]
+	trained := map[string]float64{"VELOCITY": performance["VELOCITY"], "NEWCOMER": 0.5}
+	if _, err := registry.Register("attention", "", ModelParameters{Attention: map[string]map[string]float64{"performance": trained}}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	diff, err := registry.Diff("attention", 1, 2)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	changes := make(map[string]ModelChange)
+	for _, change := range diff.Changes {
+		changes[change.Parameter] = change
+	}
+	if c := changes["attention.performance.NEWCOMER"]; c.From != nil || c.To == nil || *c.To != 0.5 {
+		t.Errorf("expected NEWCOMER added, got %+v", c)
+	}
+	if c, ok := changes["attention.performance.VELOCITY"]; ok {
+		t.Errorf("expected VELOCITY unchanged, got %+v", c)
+	}
+	if diff.Unchanged != 1 || len(diff.Changes) < 2 {
+		t.Errorf("expected the rest of version 1 removed, got %d changes, %d unchanged", len(diff.Changes), diff.Unchanged)
+	}
+	if _, err := registry.Diff("attention", 1, 9); !errors.Is(err, ErrModelVersionNotFound) {
+		t.Errorf("expected ErrModelVersionNotFound, got %v", err)
+	}
+}
+
+func TestModelRegistry_Invalid(t *testing.T) {
+	registry := NewModelRegistry(NewManualClock(time.Now()))
+	newModelFixture(t, registry)
+
+	for name, params := range map[string]ModelParameters{
+		"routing":     {Attention: map[string]map[string]float64{"coding": {"APEX": 1}}},
+		"attention":   {Attention: map[string]map[string]float64{"coding": {"APEX": -1}}},
+		"calibration": {Calibration: &CalibrationTable{Bins: []CalibrationTableBin{{Count: 1, Correct: 2}}}},
+	} {
+		if _, err := registry.Register(name, "", params); !errors.Is(err, ErrInvalidModelArtifact) {
+			t.Errorf("%s: expected ErrInvalidModelArtifact, got %v", name, err)
+		}
+	}
+	if _, err := registry.Snapshot("nobody", ""); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+	if err := registry.BindRouting("attention", NewHybridRouter(nil, DefaultHybridRouterConfig())); err == nil {
+		t.Error("expected binding a model to a structure of another kind to fail")
+	}
+}
+
+func TestModelRegistry_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	registry, err := OpenModelRegistry(path, nil)
+	if err != nil {
+		t.Fatalf("OpenModelRegistry failed: %v", err)
+	}
+	_, _, calibrator := newModelFixture(t, registry)
+	for i := 0; i < 4; i++ {
+		calibrator.Record(0.9, i != 0)
+	}
+	if _, err := registry.Snapshot("calibration", "after feedback"); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	reopened, err := OpenModelRegistry(path, nil)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	_, _, restored := newModelFixture(t, reopened)
+	if got, want := restored.Calibrate(0.9), calibrator.Calibrate(0.9); got != want {
+		t.Errorf("expected the promoted table reapplied on bind, got %v, want %v", got, want)
+	}
+	if versions, _ := reopened.Versions("calibration"); len(versions) != 1 || versions[0].Description != "after feedback" {
+		t.Errorf("expected the saved version, got %+v", versions)
+	}
+}
+
+func TestModelRegistry_HTTP(t *testing.T) {
+	registry := NewModelRegistry(NewManualClock(time.Now()))
+	router, _, _ := newModelFixture(t, registry)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/memory/registry", registry.ServeModels)
+	mux.HandleFunc("GET /admin/memory/registry/{name}", registry.ServeVersions)
+	mux.HandleFunc("POST /admin/memory/registry/{name}", registry.ServeCreate)
+	mux.HandleFunc("GET /admin/memory/registry/{name}/versions/{version}", registry.ServeVersion)
+	mux.HandleFunc("POST /admin/memory/registry/{name}/versions/{version}/promote", registry.ServePromote)
+	mux.HandleFunc("POST /admin/memory/registry/{name}/rollback", registry.ServeRollback)
+	mux.HandleFunc("GET /admin/memory/registry/{name}/diff", registry.ServeDiff)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/admin/memory/registry/routing", ""); rec.Code != http.StatusCreated {
+		t.Fatalf("expected a snapshot created, got %d %s", rec.Code, rec.Body)
+	}
+	rec := serve(http.MethodPost, "/admin/memory/registry/routing", `{"description":"trained","parameters":{"routing":{"keyword":0.4,"similarity":0.6}}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected an upload created, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPost, "/admin/memory/registry/routing/versions/2/promote", ""); rec.Code != http.StatusOK || router.Weights().Similarity != 0.6 {
+		t.Errorf("expected version 2 promoted, got %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodGet, "/admin/memory/registry/routing/diff?from=1", "")
+	var diff ModelDiff
+	if err := json.NewDecoder(rec.Body).Decode(&diff); err != nil || diff.To != 2 || len(diff.Changes) != 2 {
+		t.Errorf("expected the blend diffed against the active version, got %+v (%v)", diff, err)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/admin/memory/registry", "", http.StatusOK},
+		{http.MethodGet, "/admin/memory/registry/routing", "", http.StatusOK},
+		{http.MethodGet, "/admin/memory/registry/routing/versions/2", "", http.StatusOK},
+		{http.MethodGet, "/admin/memory/registry/routing/versions/7", "", http.StatusNotFound},
+		{http.MethodGet, "/admin/memory/registry/nobody", "", http.StatusNotFound},
+		{http.MethodPost, "/admin/memory/registry/routing", "{", http.StatusBadRequest},
+		{http.MethodPost, "/admin/memory/registry/routing/versions/x/promote", "", http.StatusBadRequest},
+		{http.MethodGet, "/admin/memory/registry/routing/diff", "", http.StatusBadRequest},
+		{http.MethodPost, "/admin/memory/registry/routing/rollback", "", http.StatusOK},
+		{http.MethodPost, "/admin/memory/registry/routing/rollback", "", http.StatusConflict},
+	} {
+		if rec := serve(tc.method, tc.path, tc.body); rec.Code != tc.status {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, rec.Code)
+		}
+	}
+}
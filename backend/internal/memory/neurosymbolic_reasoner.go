@@ -323,7 +323,7 @@ func (nr *NeuralReasoner) generateFromPatterns(query *Query) []*Hypothesis {
 	for _, pattern := range nr.patternMatcher.patterns {
 		if strings.Contains(query.Question, pattern.Trigger) {
 			hyp := &Hypothesis{
-				ID:         fmt.Sprintf("hyp-pattern-%s-%d", pattern.ID, time.Now().UnixNano()),
+				ID:         NewID("hyp-pattern-" + pattern.ID),
 				Statement:  fmt.Sprintf("Based on pattern '%s': %s", pattern.ID, pattern.Strategy),
 				Confidence: pattern.SuccessRate,
 				Provenance: "pattern_matching",
@@ -358,7 +358,7 @@ func (nr *NeuralReasoner) generateFromExperiences(query *Query) []*Hypothesis {
 	hypotheses := make([]*Hypothesis, 0)
 	for _, exp := range result.Experiences {
 		hyp := &Hypothesis{
-			ID:         fmt.Sprintf("hyp-exp-%s-%d", exp.ID, time.Now().UnixNano()),
+			ID:         NewID("hyp-exp-" + exp.ID),
 			Statement:  fmt.Sprintf("Based on similar experience: %s", exp.Strategy),
 			Confidence: exp.FitnessScore,
 			Provenance: "experience_retrieval",
@@ -383,7 +383,7 @@ func (nr *NeuralReasoner) generateFromAnalogies(query *Query) []*Hypothesis {
 	if len(concepts) >= 2 {
 		// Generate analogy-based hypothesis
 		hyp := &Hypothesis{
-			ID:         fmt.Sprintf("hyp-analogy-%d", time.Now().UnixNano()),
+			ID:         NewID("hyp-analogy"),
 			Statement:  fmt.Sprintf("Analogical reasoning: apply patterns from %s to %s", concepts[0], concepts[1]),
 			Confidence: 0.4, // Lower confidence for analogies
 			Provenance: "analogical_reasoning",
@@ -488,7 +488,7 @@ func (sv *SymbolicVerifier) Prove(hypothesis *Hypothesis, kb *LogicKnowledgeBase
 	proofSteps, success := sv.backwardChain(goal, kb, 0, make(map[string]bool))
 
 	proof := &Proof{
-		ID:           fmt.Sprintf("proof-%s-%d", hypothesis.ID, time.Now().UnixNano()),
+		ID:           NewID("proof-" + hypothesis.ID),
 		HypothesisID: hypothesis.ID,
 		Valid:        success,
 		Steps:        proofSteps,
@@ -724,7 +724,7 @@ func (r *NeurosymbolicReasoner) Reason(query *Query) (*Conclusion, *Proof) {
 				r.statsMu.Unlock()
 
 				conclusion := &Conclusion{
-					ID:           fmt.Sprintf("conclusion-%d", time.Now().UnixNano()),
+					ID:           NewID("conclusion"),
 					Statement:    hypothesis.Statement,
 					Confidence:   hypothesis.Confidence,
 					Verified:     true,
@@ -753,7 +753,7 @@ func (r *NeurosymbolicReasoner) Reason(query *Query) (*Conclusion, *Proof) {
 	if len(hypotheses) > 0 {
 		best := hypotheses[0]
 		return &Conclusion{
-			ID:           fmt.Sprintf("conclusion-unverified-%d", time.Now().UnixNano()),
+			ID:           NewID("conclusion-unverified"),
 			Statement:    best.Statement,
 			Confidence:   best.Confidence * 0.5, // Lower confidence for unverified
 			Verified:     false,
@@ -0,0 +1,314 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements a minimal Parquet writer for training data exports.
+//
+// The writer covers what the exports need and nothing more: flat schemas of
+// required columns, one row group, one PLAIN-encoded, uncompressed data
+// page per column. Such files are read by every Parquet implementation
+// (pyarrow, pandas, Spark, DuckDB), and they avoid a dependency for a
+// format the server only ever writes. The file footer is Thrift, written
+// with the compact protocol.
+
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// parquetMagic starts and ends every Parquet file.
+const parquetMagic = "PAR1"
+
+// parquetCreatedBy identifies the writer in the file footer.
+const parquetCreatedBy = "elite-agent-collective training exporter"
+
+// Parquet physical types, encodings and converted types used by the writer.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetPlain    = 0
+	parquetRLE      = 3
+	parquetDataPage = 0
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+)
+
+// Thrift compact protocol field types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// writeParquet writes rows as a Parquet file with one column per export
+// column. Each row holds a value per column, of the column's type.
+func writeParquet(w io.Writer, columns []ExportColumn, rows [][]any, metadata map[string]string) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(columns))
+	if len(rows) > 0 {
+		for i, column := range columns {
+			data, err := encodeParquetColumn(column, rows, i)
+			if err != nil {
+				return err
+			}
+			header := parquetPageHeader(len(rows), len(data))
+			chunks[i] = chunk{offset: int64(file.Len()), size: int64(len(header) + len(data))}
+			file.Write(header)
+			file.Write(data)
+		}
+	}
+
+	t := newThriftWriter()
+	t.begin()
+	t.i32(1, 1)
+	t.list(2, thriftStruct, len(columns)+1)
+	t.begin()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(columns)))
+	t.end()
+	for _, column := range columns {
+		t.begin()
+		t.i32(1, parquetPhysicalType(column.Type))
+		t.i32(3, parquetRequired)
+		t.binary(4, column.Name)
+		switch column.Type {
+		case ExportString:
+			t.i32(6, parquetUTF8)
+		case ExportTimestamp:
+			t.i32(6, parquetTimestampMillis)
+		}
+		t.end()
+	}
+	t.i64(3, int64(len(rows)))
+	if len(rows) == 0 {
+		t.list(4, thriftStruct, 0)
+	} else {
+		t.list(4, thriftStruct, 1)
+		t.begin()
+		t.list(1, thriftStruct, len(columns))
+		var total int64
+		for i, column := range columns {
+			total += chunks[i].size
+			t.begin()
+			t.i64(2, chunks[i].offset)
+			t.beginStruct(3)
+			t.i32(1, parquetPhysicalType(column.Type))
+			t.list(2, thriftI32, 1)
+			t.listI32(parquetPlain)
+			t.list(3, thriftBinary, 1)
+			t.listBinary(column.Name)
+			t.i32(4, 0)
+			t.i64(5, int64(len(rows)))
+			t.i64(6, chunks[i].size)
+			t.i64(7, chunks[i].size)
+			t.i64(9, chunks[i].offset)
+			t.end()
+			t.end()
+		}
+		t.i64(2, total)
+		t.i64(3, int64(len(rows)))
+		t.end()
+	}
+	if len(metadata) > 0 {
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		t.list(5, thriftStruct, len(keys))
+		for _, key := range keys {
+			t.begin()
+			t.binary(1, key)
+			t.binary(2, metadata[key])
+			t.end()
+		}
+	}
+	t.binary(6, parquetCreatedBy)
+	t.end()
+
+	footer := t.bytes()
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(parquetMagic)
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// parquetPhysicalType returns the physical type an export type is stored as.
+func parquetPhysicalType(t ExportType) int32 {
+	switch t {
+	case ExportBoolean:
+		return parquetBoolean
+	case ExportInt64, ExportTimestamp:
+		return parquetInt64
+	case ExportDouble:
+		return parquetDouble
+	default:
+		return parquetByteArray
+	}
+}
+
+// encodeParquetColumn PLAIN-encodes a column's values.
+func encodeParquetColumn(column ExportColumn, rows [][]any, index int) ([]byte, error) {
+	var buf bytes.Buffer
+	var bits byte
+	for n, row := range rows {
+		value := row[index]
+		ok := true
+		switch column.Type {
+		case ExportString:
+			var s string
+			if s, ok = value.(string); ok {
+				binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+				buf.WriteString(s)
+			}
+		case ExportInt64:
+			var v int64
+			if v, ok = value.(int64); ok {
+				binary.Write(&buf, binary.LittleEndian, v)
+			}
+		case ExportTimestamp:
+			var v time.Time
+			if v, ok = value.(time.Time); ok {
+				binary.Write(&buf, binary.LittleEndian, v.UnixMilli())
+			}
+		case ExportDouble:
+			var v float64
+			if v, ok = value.(float64); ok {
+				binary.Write(&buf, binary.LittleEndian, math.Float64bits(v))
+			}
+		case ExportBoolean:
+			var v bool
+			if v, ok = value.(bool); ok && v {
+				bits |= 1 << (n % 8)
+			}
+			if n%8 == 7 || n == len(rows)-1 {
+				buf.WriteByte(bits)
+				bits = 0
+			}
+		default:
+			return nil, fmt.Errorf("column %s: unsupported type %s", column.Name, column.Type)
+		}
+		if !ok {
+			return nil, fmt.Errorf("column %s: row %d holds %T, not %s", column.Name, n, value, column.Type)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// parquetPageHeader returns the header of a data page of values.
+func parquetPageHeader(values, size int) []byte {
+	t := newThriftWriter()
+	t.begin()
+	t.i32(1, parquetDataPage)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.beginStruct(5)
+	t.i32(1, int32(values))
+	t.i32(2, parquetPlain)
+	t.i32(3, parquetRLE)
+	t.i32(4, parquetRLE)
+	t.end()
+	t.end()
+	return t.bytes()
+}
+
+// thriftWriter writes Thrift structs with the compact protocol. Field IDs
+// are delta-encoded against the previous field of the enclosing struct.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{}
+}
+
+func (t *thriftWriter) bytes() []byte {
+	return t.buf.Bytes()
+}
+
+// begin starts a struct: the top-level struct, or an element of a list.
+func (t *thriftWriter) begin() {
+	t.last = append(t.last, 0)
+}
+
+// end finishes the current struct.
+func (t *thriftWriter) end() {
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	top := len(t.last) - 1
+	if delta := id - t.last[top]; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(uint64(uint16((id << 1) ^ (id >> 15))))
+	}
+	t.last[top] = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+// beginStruct starts a struct-valued field.
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// list starts a list-valued field of n elements; the elements follow.
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elem)
+	t.varint(uint64(n))
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes compact-protocol Thrift into generic values:
+// structs as maps by field ID, lists as slices, integers as int64 and
+// binaries as strings.
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.t.Fatalf("thrift: read past end at %d", r.pos)
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("thrift: bad varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		header := r.byte()
+		n, elem := int(header>>4), header&0x0F
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	r.t.Fatalf("thrift: unexpected type %d at %d", typ, r.pos)
+	return nil
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0F)
+		last = id
+	}
+}
+
+// readParquet decodes a file written by writeParquet, returning its footer
+// and its columns' values by name.
+func readParquet(t *testing.T, file []byte) (map[int16]any, map[string][]any) {
+	t.Helper()
+	if string(file[:4]) != parquetMagic || string(file[len(file)-4:]) != parquetMagic {
+		t.Fatal("file does not start and end with PAR1")
+	}
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerAt := len(file) - 8 - size
+	footer := (&thriftReader{t: t, data: file[footerAt : len(file)-8]}).readStruct()
+
+	columns := make(map[string][]any)
+	for _, group := range footer[4].([]any) {
+		for _, c := range group.(map[int16]any)[1].([]any) {
+			meta := c.(map[int16]any)[3].(map[int16]any)
+			name := meta[3].([]any)[0].(string)
+			typ := meta[1].(int64)
+			r := &thriftReader{t: t, data: file, pos: int(meta[9].(int64))}
+			header := r.readStruct()
+			values := int(header[5].(map[int16]any)[1].(int64))
+			data := file[r.pos : r.pos+int(header[2].(int64))]
+			if int64(r.pos+len(data))-meta[9].(int64) != meta[6].(int64) {
+				t.Errorf("column %s: chunk size %d does not cover header and page", name, meta[6])
+			}
+			for i := 0; i < values; i++ {
+				switch typ {
+				case parquetBoolean:
+					columns[name] = append(columns[name], data[i/8]&(1<<(i%8)) != 0)
+				case parquetInt64:
+					columns[name] = append(columns[name], int64(binary.LittleEndian.Uint64(data[8*i:])))
+				case parquetDouble:
+					columns[name] = append(columns[name], math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:])))
+				case parquetByteArray:
+					n := int(binary.LittleEndian.Uint32(data))
+					columns[name] = append(columns[name], string(data[4:4+n]))
+					data = data[4+n:]
+				}
+			}
+		}
+	}
+	return footer, columns
+}
+
+func TestWriteParquet(t *testing.T) {
+	columns := []ExportColumn{
+		{"name", ExportString, ""},
+		{"count", ExportInt64, ""},
+		{"score", ExportDouble, ""},
+		{"ok", ExportBoolean, ""},
+		{"at", ExportTimestamp, ""},
+	}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var rows [][]any
+	for i := 0; i < 20; i++ {
+		rows = append(rows, []any{string(rune('a' + i)), int64(i - 5), float64(i) / 4, i%3 == 0, at.Add(time.Duration(i) * time.Second)})
+	}
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, columns, rows, map[string]string{"eac.table": "test"}); err != nil {
+		t.Fatalf("writeParquet failed: %v", err)
+	}
+	footer, values := readParquet(t, buf.Bytes())
+
+	if footer[3].(int64) != 20 {
+		t.Errorf("expected 20 rows, got %v", footer[3])
+	}
+	schema := footer[2].([]any)
+	if len(schema) != 6 || schema[0].(map[int16]any)[5].(int64) != 5 {
+		t.Fatalf("expected a root with 5 children, got %v", schema)
+	}
+	for i, column := range columns {
+		element := schema[i+1].(map[int16]any)
+		if element[4] != column.Name || element[3].(int64) != parquetRequired {
+			t.Errorf("schema element %d: %v", i+1, element)
+		}
+	}
+	if schema[1].(map[int16]any)[6].(int64) != parquetUTF8 || schema[5].(map[int16]any)[6].(int64) != parquetTimestampMillis {
+		t.Error("expected UTF8 and TIMESTAMP_MILLIS converted types")
+	}
+	if kv := footer[5].([]any)[0].(map[int16]any); kv[1] != "eac.table" || kv[2] != "test" {
+		t.Errorf("expected table metadata, got %v", kv)
+	}
+
+	for i, row := range rows {
+		if values["name"][i] != row[0] || values["count"][i] != row[1] || values["score"][i] != row[2] || values["ok"][i] != row[3] {
+			t.Fatalf("row %d: got %v %v %v %v, want %v", i, values["name"][i], values["count"][i], values["score"][i], values["ok"][i], row)
+		}
+		if values["at"][i] != row[4].(time.Time).UnixMilli() {
+			t.Fatalf("row %d: got time %v", i, values["at"][i])
+		}
+	}
+}
+
+func TestWriteParquet_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, []ExportColumn{{"name", ExportString, ""}}, nil, nil); err != nil {
+		t.Fatalf("writeParquet failed: %v", err)
+	}
+	footer, values := readParquet(t, buf.Bytes())
+	if footer[3].(int64) != 0 || len(footer[4].([]any)) != 0 || len(values) != 0 {
+		t.Errorf("expected no rows or row groups, got %v", footer)
+	}
+}
+
+func TestWriteParquet_WrongType(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeParquet(&buf, []ExportColumn{{"count", ExportInt64, ""}}, [][]any{{"three"}}, nil)
+	if err == nil {
+		t.Fatal("expected an error for a string in an int64 column")
+	}
+}
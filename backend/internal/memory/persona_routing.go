@@ -0,0 +1,403 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements routing by similarity to agent personas.
+//
+// Each agent's persona (specialty, philosophy, directives and keywords) is
+// embedded once, up front. A query is then scored against every persona by
+// cosine similarity. This complements the keyword-based
+// CollaborativeAttentionIndex, which only sees queries that use its
+// category keywords. HybridRouter blends the two scores, learning the blend
+// from outcome feedback: a signal that favored agents which went on to
+// succeed gains weight (exponentiated gradient), one that favored agents
+// which failed loses it.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// TextEmbedder embeds texts in batches; embeddings.Embedder satisfies it.
+type TextEmbedder interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ============================================================================
+// Persona Index
+// ============================================================================
+
+// PersonaText returns the description of an agent that is embedded.
+func PersonaText(agent models.Agent) string {
+	parts := []string{agent.Codename}
+	if agent.Specialty != "" {
+		parts = append(parts, agent.Specialty)
+	}
+	if agent.Philosophy != "" {
+		parts = append(parts, agent.Philosophy)
+	}
+	parts = append(parts, agent.Directives...)
+	if len(agent.Keywords) > 0 {
+		parts = append(parts, strings.Join(agent.Keywords, ", "))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// PersonaIndex holds the persona embedding of every agent. It is immutable
+// once built and safe for concurrent use.
+type PersonaIndex struct {
+	embedder TextEmbedder
+	personas map[string][]float32
+}
+
+// NewPersonaIndex embeds the personas of agents in one batch.
+func NewPersonaIndex(ctx context.Context, embedder TextEmbedder, agents []models.Agent) (*PersonaIndex, error) {
+	texts := make([]string, len(agents))
+	for i, agent := range agents {
+		texts[i] = PersonaText(agent)
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding agent personas: %w", err)
+	}
+	if len(vectors) != len(agents) {
+		return nil, fmt.Errorf("embedding agent personas: got %d vectors for %d agents", len(vectors), len(agents))
+	}
+
+	idx := &PersonaIndex{embedder: embedder, personas: make(map[string][]float32, len(agents))}
+	for i, agent := range agents {
+		idx.personas[agent.Codename] = vectors[i]
+	}
+	return idx, nil
+}
+
+// Len returns the number of agents indexed.
+func (p *PersonaIndex) Len() int {
+	return len(p.personas)
+}
+
+// Similarities returns the cosine similarity of a query to every persona.
+func (p *PersonaIndex) Similarities(ctx context.Context, query string) (map[string]float64, error) {
+	vectors, err := p.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedding query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, errors.New("embedding query: no vector returned")
+	}
+	similarities := make(map[string]float64, len(p.personas))
+	for agent, persona := range p.personas {
+		similarities[agent] = cosineSimilarityFloat32(vectors[0], persona)
+	}
+	return similarities, nil
+}
+
+// ============================================================================
+// Hybrid Router
+// ============================================================================
+
+// HybridRoute is an agent ranked by the hybrid router.
+type HybridRoute struct {
+	AgentID string  `json:"agent"`
+	Score   float64 `json:"score"`
+	// Keyword and Similarity are the two signals, each rescaled to [0, 1]
+	// across agents
+	Keyword    float64 `json:"keyword"`
+	Similarity float64 `json:"similarity"`
+}
+
+// RoutingWeights are the blend of the two routing signals; they sum to one.
+type RoutingWeights struct {
+	Keyword    float64 `json:"keyword"`
+	Similarity float64 `json:"similarity"`
+}
+
+// HybridRouterConfig configures blend learning.
+type HybridRouterConfig struct {
+	// LearningRate scales each exponentiated-gradient step
+	LearningRate float64
+	// MinWeight keeps either signal from being switched off entirely
+	MinWeight float64
+}
+
+// DefaultHybridRouterConfig returns the default blend learning configuration.
+func DefaultHybridRouterConfig() HybridRouterConfig {
+	return HybridRouterConfig{LearningRate: 0.5, MinWeight: 0.05}
+}
+
+// HybridRouter ranks agents by a learned blend of keyword attention and
+// persona similarity. Until personas are set it routes by keywords alone.
+// It is safe for concurrent use.
+type HybridRouter struct {
+	attention *CollaborativeAttentionIndex
+	personas  atomic.Pointer[PersonaIndex]
+	config    HybridRouterConfig
+
+	mu      sync.Mutex
+	weights RoutingWeights
+
+	// onRoute is called with each ranking made
+	onRoute func(RoutingDecision)
+}
+
+// NewHybridRouter creates a router over an attention index, starting from
+// an even blend.
+func NewHybridRouter(attention *CollaborativeAttentionIndex, config HybridRouterConfig) *HybridRouter {
+	return &HybridRouter{
+		attention: attention,
+		config:    config,
+		weights:   RoutingWeights{Keyword: 0.5, Similarity: 0.5},
+	}
+}
+
+// SetPersonas sets the persona index similarity is computed against.
+func (r *HybridRouter) SetPersonas(personas *PersonaIndex) {
+	r.personas.Store(personas)
+}
+
+// OnRoute sets a callback for the rankings the router makes, e.g. to log
+// them for training. It must be set before the router is used.
+func (r *HybridRouter) OnRoute(fn func(RoutingDecision)) {
+	r.onRoute = fn
+}
+
+// SetWeights replaces the blend, e.g. with one trained offline. The
+// weights are normalized to sum to one, and neither drops below the
+// configured minimum.
+func (r *HybridRouter) SetWeights(weights RoutingWeights) {
+	total := weights.Keyword + weights.Similarity
+	if total <= 0 {
+		return
+	}
+	k, s := weights.Keyword/total, weights.Similarity/total
+	if k < r.config.MinWeight {
+		k, s = r.config.MinWeight, 1-r.config.MinWeight
+	} else if s < r.config.MinWeight {
+		k, s = 1-r.config.MinWeight, r.config.MinWeight
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights = RoutingWeights{Keyword: k, Similarity: s}
+}
+
+// Weights returns the current blend.
+func (r *HybridRouter) Weights() RoutingWeights {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.weights
+}
+
+// signals returns both routing signals for a query, each rescaled to
+// [0, 1] across agents. similarity is nil without personas.
+func (r *HybridRouter) signals(ctx context.Context, query string) (keyword, similarity map[string]float64, err error) {
+	keyword = keywordSignal(r.attention, query)
+	if personas := r.personas.Load(); personas != nil {
+		similarity, err = personas.Similarities(ctx, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		rescale(similarity)
+	}
+	return keyword, similarity, nil
+}
+
+// Route returns the top k agents for a query, best first.
+func (r *HybridRouter) Route(ctx context.Context, query string, topK int) ([]HybridRoute, error) {
+	keyword, similarity, err := r.signals(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	weights := r.Weights()
+	if similarity == nil {
+		weights = RoutingWeights{Keyword: 1}
+	}
+	routes := rankRoutes(keyword, similarity, weights, topK)
+	if r.onRoute != nil {
+		r.onRoute(RoutingDecision{Query: query, Routes: routes, Weights: weights})
+	}
+	return routes, nil
+}
+
+// keywordSignal returns an attention index's scores for a query, rescaled
+// to [0, 1] across agents.
+func keywordSignal(attention *CollaborativeAttentionIndex, query string) map[string]float64 {
+	keyword := make(map[string]float64)
+	for _, route := range attention.RouteQuery(query, math.MaxInt) {
+		keyword[route.AgentID] = route.Attention
+	}
+	rescale(keyword)
+	return keyword
+}
+
+// rankRoutes blends two signals by weights and returns the top k agents,
+// best first.
+func rankRoutes(keyword, similarity map[string]float64, weights RoutingWeights, topK int) []HybridRoute {
+	agents := make(map[string]bool, len(keyword)+len(similarity))
+	for agent := range keyword {
+		agents[agent] = true
+	}
+	for agent := range similarity {
+		agents[agent] = true
+	}
+	routes := make([]HybridRoute, 0, len(agents))
+	for agent := range agents {
+		routes = append(routes, HybridRoute{
+			AgentID:    agent,
+			Score:      weights.Keyword*keyword[agent] + weights.Similarity*similarity[agent],
+			Keyword:    keyword[agent],
+			Similarity: similarity[agent],
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Score != routes[j].Score {
+			return routes[i].Score > routes[j].Score
+		}
+		return routes[i].AgentID < routes[j].AgentID
+	})
+	if topK < len(routes) {
+		routes = routes[:topK]
+	}
+	return routes
+}
+
+// Learn adjusts the blend from routing outcomes and returns how many were
+// used. Outcomes are skipped without personas, since there is nothing to
+// blend.
+func (r *HybridRouter) Learn(ctx context.Context, feedback []AttentionFeedback) (int, error) {
+	if r.personas.Load() == nil {
+		return 0, nil
+	}
+	used := 0
+	for _, f := range feedback {
+		keyword, similarity, err := r.signals(ctx, f.Query)
+		if err != nil {
+			return used, err
+		}
+		r.learn(advantage(keyword, f.Agent), advantage(similarity, f.Agent), f.Success)
+		used++
+	}
+	return used, nil
+}
+
+// learn takes one exponentiated-gradient step: each signal is rewarded by
+// how far it favored the agent above its average, with the sign of the
+// outcome.
+func (r *HybridRouter) learn(keywordAdvantage, similarityAdvantage float64, success bool) {
+	reward := -1.0
+	if success {
+		reward = 1.0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := r.weights.Keyword * math.Exp(r.config.LearningRate*reward*keywordAdvantage)
+	s := r.weights.Similarity * math.Exp(r.config.LearningRate*reward*similarityAdvantage)
+	k, s = k/(k+s), s/(k+s)
+
+	// Keep both signals alive so the blend can recover
+	if k < r.config.MinWeight {
+		k, s = r.config.MinWeight, 1-r.config.MinWeight
+	} else if s < r.config.MinWeight {
+		k, s = 1-r.config.MinWeight, r.config.MinWeight
+	}
+	r.weights = RoutingWeights{Keyword: k, Similarity: s}
+}
+
+// advantage returns how far a signal favored an agent above its average.
+func advantage(signal map[string]float64, agent string) float64 {
+	if len(signal) == 0 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range signal {
+		mean += v
+	}
+	mean /= float64(len(signal))
+	return signal[agent] - mean
+}
+
+// rescale maps a signal's values linearly onto [0, 1]. A flat signal maps
+// to all zeros, since it favors no one.
+func rescale(signal map[string]float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range signal {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	for agent, v := range signal {
+		if hi > lo {
+			signal[agent] = (v - lo) / (hi - lo)
+		} else {
+			signal[agent] = 0
+		}
+	}
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// maxRouteTopK bounds the agents returned by one routing request.
+const maxRouteTopK = 40
+
+// RouteRequest is the body of POST /agents/route.
+type RouteRequest struct {
+	Query string `json:"query"`
+	// TopK is the number of agents returned; defaults to 5
+	TopK int `json:"top_k,omitempty"`
+}
+
+// RouteResponse is the response of POST /agents/route.
+type RouteResponse struct {
+	Agents  []HybridRoute  `json:"agents"`
+	Weights RoutingWeights `json:"weights"`
+	// PersonasReady is false while persona embeddings are being computed,
+	// when routing is by keywords alone
+	PersonasReady bool `json:"personas_ready"`
+}
+
+// ServeRoute handles POST /agents/route - ranks agents for a query.
+func (r *HybridRouter) ServeRoute(w http.ResponseWriter, req *http.Request) {
+	var body RouteRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Query) == "" {
+		errdefs.WriteError(w, "query is required", http.StatusBadRequest)
+		return
+	}
+	if body.TopK <= 0 {
+		body.TopK = 5
+	}
+	if body.TopK > maxRouteTopK {
+		body.TopK = maxRouteTopK
+	}
+
+	routes, err := r.Route(req.Context(), body.Query, body.TopK)
+	if err != nil {
+		log.Printf("Routing failed: %v", err)
+		errdefs.WriteError(w, "routing failed", http.StatusInternalServerError)
+		return
+	}
+	ready := r.personas.Load() != nil
+	weights := r.Weights()
+	if !ready {
+		weights = RoutingWeights{Keyword: 1}
+	}
+	errdefs.WriteJSON(w, http.StatusOK, &RouteResponse{Agents: routes, Weights: weights, PersonasReady: ready})
+}
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ============================================================================
+// Persona Routing Tests
+// ============================================================================
+
+// wordEmbedder embeds text as counts over a fixed vocabulary, so texts
+// sharing words are similar.
+type wordEmbedder struct {
+	vocabulary []string
+	err        error
+}
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(e.vocabulary))
+		for j, word := range e.vocabulary {
+			vectors[i][j] = float32(strings.Count(strings.ToLower(text), word))
+		}
+	}
+	return vectors, nil
+}
+
+// testPersonaAgents are the agents persona routing tests index.
+var testPersonaAgents = []models.Agent{
+	{Codename: "AXIOM", Specialty: "Pure Mathematics & Formal Proofs", Directives: []string{"Construct formal proofs"}, Keywords: []string{"theorem", "proof"}},
+	{Codename: "CIPHER", Specialty: "Advanced Cryptography & Security", Directives: []string{"Design cryptographic protocols"}},
+	{Codename: "SCRIBE", Specialty: "Technical Documentation", Directives: []string{"Write clear tutorials"}},
+}
+
+// newTestPersonaIndex indexes testPersonaAgents with a word embedder.
+func newTestPersonaIndex(t *testing.T) *PersonaIndex {
+	embedder := &wordEmbedder{vocabulary: []string{"theorem", "proof", "crypto", "protocol", "tutorial", "documentation"}}
+	personas, err := NewPersonaIndex(context.Background(), embedder, testPersonaAgents)
+	if err != nil {
+		t.Fatalf("NewPersonaIndex failed: %v", err)
+	}
+	return personas
+}
+
+func TestPersonaText(t *testing.T) {
+	text := PersonaText(testPersonaAgents[0])
+	for _, want := range []string{"AXIOM", "Formal Proofs", "Construct formal proofs", "theorem, proof"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected persona text to contain %q, got %q", want, text)
+		}
+	}
+}
+
+func TestPersonaIndex_Similarities(t *testing.T) {
+	personas := newTestPersonaIndex(t)
+	if personas.Len() != 3 {
+		t.Fatalf("Expected 3 personas, got %d", personas.Len())
+	}
+
+	similarities, err := personas.Similarities(context.Background(), "check this theorem and its proof")
+	if err != nil {
+		t.Fatalf("Similarities failed: %v", err)
+	}
+	if similarities["AXIOM"] <= similarities["CIPHER"] || similarities["AXIOM"] <= similarities["SCRIBE"] {
+		t.Errorf("Expected AXIOM most similar, got %v", similarities)
+	}
+}
+
+func TestPersonaIndex_EmbedderError(t *testing.T) {
+	failing := &wordEmbedder{err: errors.New("model unavailable")}
+	if _, err := NewPersonaIndex(context.Background(), failing, testPersonaAgents); err == nil {
+		t.Error("Expected error when personas cannot be embedded")
+	}
+}
+
+func TestHybridRouter_KeywordsOnlyWithoutPersonas(t *testing.T) {
+	router := NewHybridRouter(NewCollaborativeAttentionIndex(), DefaultHybridRouterConfig())
+
+	routes, err := router.Route(context.Background(), "optimize cache performance", 3)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if len(routes) != 3 || routes[0].AgentID != "VELOCITY" {
+		t.Fatalf("Expected VELOCITY first of 3, got %+v", routes)
+	}
+	if routes[0].Score != routes[0].Keyword || routes[0].Similarity != 0 {
+		t.Errorf("Expected score from keywords alone, got %+v", routes[0])
+	}
+
+	if used, err := router.Learn(context.Background(), []AttentionFeedback{{Query: "q", Agent: "APEX", Success: true}}); used != 0 || err != nil {
+		t.Errorf("Expected no blend learning without personas, got %d and %v", used, err)
+	}
+}
+
+func TestHybridRouter_SimilarityRoutesUnmatchedQueries(t *testing.T) {
+	router := NewHybridRouter(NewCollaborativeAttentionIndex(), DefaultHybridRouterConfig())
+	router.SetPersonas(newTestPersonaIndex(t))
+
+	// No category keyword matches, so only similarity ranks the agents
+	routes, err := router.Route(context.Background(), "is this theorem proof sound", 1)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if len(routes) != 1 || routes[0].AgentID != "AXIOM" || routes[0].Similarity != 1 {
+		t.Errorf("Expected AXIOM by similarity, got %+v", routes)
+	}
+}
+
+func TestHybridRouter_LearnsBlend(t *testing.T) {
+	router := NewHybridRouter(NewCollaborativeAttentionIndex(), DefaultHybridRouterConfig())
+	router.SetPersonas(newTestPersonaIndex(t))
+
+	// Keywords favor SCRIBE ("write", "tutorial"); similarity favors AXIOM
+	query := "write a tutorial on this theorem proof proof"
+	feedback := []AttentionFeedback{{Query: query, Agent: "AXIOM", Success: true}, {Query: query, Agent: "SCRIBE", Success: false}}
+	used, err := router.Learn(context.Background(), feedback)
+	if err != nil || used != 2 {
+		t.Fatalf("Expected 2 outcomes learned from, got %d and %v", used, err)
+	}
+	if w := router.Weights(); w.Similarity <= 0.5 || w.Keyword+w.Similarity < 0.999 || w.Keyword+w.Similarity > 1.001 {
+		t.Errorf("Expected similarity weight above 0.5 summing to 1, got %+v", w)
+	}
+
+	for i := 0; i < 100; i++ {
+		router.Learn(context.Background(), feedback)
+	}
+	if w := router.Weights(); w.Keyword < DefaultHybridRouterConfig().MinWeight-1e-9 {
+		t.Errorf("Expected keyword weight kept at or above the minimum, got %+v", w)
+	}
+}
+
+func TestHybridRouter_ServeRoute(t *testing.T) {
+	router := NewHybridRouter(NewCollaborativeAttentionIndex(), DefaultHybridRouterConfig())
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/agents/route", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		router.ServeRoute(w, req)
+		return w
+	}
+
+	w := post(`{"query": "deploy to kubernetes", "top_k": 2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp RouteResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Agents) != 2 || resp.Agents[0].AgentID != "FLUX" || resp.PersonasReady || resp.Weights.Keyword != 1 {
+		t.Errorf("Expected FLUX first by keywords alone, got %+v", resp)
+	}
+
+	if w := post(`{"query": " "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty query, got %d", w.Code)
+	}
+}
+
+func TestFeedbackIngester_LearnsRoutingBlend(t *testing.T) {
+	attention := NewCollaborativeAttentionIndex()
+	router := NewHybridRouter(attention, DefaultHybridRouterConfig())
+	router.SetPersonas(newTestPersonaIndex(t))
+	ingester := NewFeedbackIngester(attention, nil, nil)
+	ingester.SetRouter(router)
+
+	summary := ingester.Ingest([]FeedbackRecord{
+		{Query: "write a tutorial on this theorem proof", Agent: "AXIOM", Success: true},
+		{Query: "", Agent: "AXIOM", Success: true},
+	})
+	if summary.RoutingUpdates != 1 {
+		t.Errorf("Expected 1 routing update, got %d", summary.RoutingUpdates)
+	}
+}
@@ -203,8 +203,10 @@ type PhaseTransitionController struct {
 	// Whether the controller is running
 	running bool
 
-	// Shutdown channel
+	// Shutdown channel, and the channel closed once the monitor loop has
+	// returned
 	shutdownChan chan struct{}
+	done         chan struct{}
 }
 
 // PhaseTransitionConfig configures the controller.
@@ -278,7 +280,6 @@ func NewPhaseTransitionController(config PhaseTransitionConfig) *PhaseTransition
 		chaoticThreshold:  0.8,
 		minInnovationRate: 0.01,
 		minStability:      0.5,
-		shutdownChan:      make(chan struct{}),
 	}
 }
 
@@ -760,23 +761,30 @@ func (c *PhaseTransitionController) Start(ctx context.Context, interval time.Dur
 		return
 	}
 	c.running = true
+	c.shutdownChan = make(chan struct{})
+	c.done = make(chan struct{})
+	go c.monitorLoop(ctx, interval, c.shutdownChan, c.done)
 	c.mu.Unlock()
-
-	go c.monitorLoop(ctx, interval)
 }
 
-// Stop halts continuous monitoring.
+// Stop halts continuous monitoring, returning once the monitor loop has.
 func (c *PhaseTransitionController) Stop() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.running {
-		close(c.shutdownChan)
-		c.running = false
+	if !c.running {
+		c.mu.Unlock()
+		return
 	}
+	close(c.shutdownChan)
+	c.running = false
+	done := c.done
+	c.mu.Unlock()
+	<-done
 }
 
-// monitorLoop runs the continuous monitoring.
-func (c *PhaseTransitionController) monitorLoop(ctx context.Context, interval time.Duration) {
+// monitorLoop runs the continuous monitoring until shutdown is closed or
+// ctx is done, then closes done.
+func (c *PhaseTransitionController) monitorLoop(ctx context.Context, interval time.Duration, shutdown, done chan struct{}) {
+	defer close(done)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -784,7 +792,7 @@ func (c *PhaseTransitionController) monitorLoop(ctx context.Context, interval ti
 		select {
 		case <-ctx.Done():
 			return
-		case <-c.shutdownChan:
+		case <-shutdown:
 			return
 		case <-ticker.C:
 			c.Update()
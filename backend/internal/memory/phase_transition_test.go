@@ -239,6 +239,15 @@ func TestContinuousMonitoring(t *testing.T) {
 		t.Error("Expected some history from continuous monitoring")
 	}
 
+	// Stop returns once the loop has, and the controller can start again
+	done := controller.done
+	controller.Stop()
+	select {
+	case <-done:
+	default:
+		t.Error("Expected the monitor loop to have returned")
+	}
+	controller.Start(ctx, 50*time.Millisecond)
 	controller.Stop()
 }
 
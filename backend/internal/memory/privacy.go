@@ -0,0 +1,271 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements differentially private aggregation of the learning
+// signals tenants share.
+//
+// Feedback from every tenant updates the same routing attention, affinity
+// graph and routing blend, so those updates can reveal how one tenant uses
+// the collective. A PrivateAggregator holds each tenant's outcomes back per
+// signal - an agent in an attention category, or a pair of agents - until
+// enough distinct tenants have contributed to it, then releases the signal
+// as one aggregate. Each tenant's share of a release is capped, and Laplace
+// noise scaled to the cap is added to its success and failure counts, so a
+// release is epsilon-differentially private with respect to any one
+// tenant's outcomes in it.
+
+package memory
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PrivacyConfig configures private aggregation of learning signals.
+type PrivacyConfig struct {
+	// Epsilon is the privacy budget spent on each release; smaller adds
+	// more noise. Zero adds none
+	Epsilon float64
+	// MinTenants is the fewest distinct tenants a signal is released with;
+	// below two, signals are released with the batch that records them
+	MinTenants int
+	// MaxContribution caps the outcomes one tenant adds to a signal in a
+	// release, which bounds the noise needed to hide that tenant
+	MaxContribution int
+}
+
+// DefaultPrivacyConfig returns a configuration with privacy disabled.
+func DefaultPrivacyConfig() PrivacyConfig {
+	return PrivacyConfig{MaxContribution: 10}
+}
+
+// Enabled reports whether the configuration adds noise or requires a
+// cohort of tenants.
+func (c PrivacyConfig) Enabled() bool {
+	return c.Epsilon > 0 || c.MinTenants > 1
+}
+
+// outcomeCounts are the outcomes a tenant contributed to a signal.
+type outcomeCounts struct {
+	successes int
+	failures  int
+}
+
+// add counts one outcome.
+func (c *outcomeCounts) add(success bool) {
+	if success {
+		c.successes++
+	} else {
+		c.failures++
+	}
+}
+
+// capped scales the counts down to at most max outcomes, keeping their
+// success rate.
+func (c outcomeCounts) capped(max int) outcomeCounts {
+	total := c.successes + c.failures
+	if total <= max {
+		return c
+	}
+	successes := int(math.Round(float64(c.successes) * float64(max) / float64(total)))
+	return outcomeCounts{successes: successes, failures: max - successes}
+}
+
+// attentionSignal is an agent's outcomes in an attention category.
+type attentionSignal struct {
+	category string
+	agent    string
+}
+
+// affinitySignal is the outcomes of a pair of agents working together,
+// with the codenames in order.
+type affinitySignal struct {
+	agent1 string
+	agent2 string
+}
+
+// privateRelease is the learning released by one call to add.
+type privateRelease struct {
+	attention      []AttentionCount
+	collaborations []CollaborationOutcome
+	// blend are the outcomes the routing blend learns from, interleaved
+	// across tenants
+	blend []AttentionFeedback
+}
+
+// PrivateAggregator pools tenants' outcomes and releases them to the
+// shared learning structures only in noisy aggregates of enough tenants.
+// It is safe for concurrent use.
+type PrivateAggregator struct {
+	config PrivacyConfig
+
+	mu        sync.Mutex
+	attention map[attentionSignal]map[string]*outcomeCounts
+	affinity  map[affinitySignal]map[string]*outcomeCounts
+	// blend holds each tenant's latest outcomes for the routing blend,
+	// which learns from queries rather than counts and so is pooled
+	// without noise
+	blend map[string][]AttentionFeedback
+	rng   *rand.Rand
+}
+
+// NewPrivateAggregator creates an aggregator. A MaxContribution below one
+// is taken as one.
+func NewPrivateAggregator(config PrivacyConfig) *PrivateAggregator {
+	if config.MaxContribution < 1 {
+		config.MaxContribution = 1
+	}
+	return &PrivateAggregator{
+		config:    config,
+		attention: make(map[attentionSignal]map[string]*outcomeCounts),
+		affinity:  make(map[affinitySignal]map[string]*outcomeCounts),
+		blend:     make(map[string][]AttentionFeedback),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Config returns the aggregator's configuration.
+func (p *PrivateAggregator) Config() PrivacyConfig {
+	return p.config
+}
+
+// Pending returns the number of signals waiting for enough tenants.
+func (p *PrivateAggregator) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.attention) + len(p.affinity)
+}
+
+// add pools a tenant's outcomes and returns the signals that now have
+// enough tenants. categories holds the attention categories each
+// attention outcome's query matches.
+func (p *PrivateAggregator) add(tenant string, attention []AttentionFeedback, categories [][]string, collaborations []CollaborationOutcome) privateRelease {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, f := range attention {
+		for _, category := range categories[i] {
+			countOutcome(p.attention, attentionSignal{category: category, agent: f.Agent}, tenant, f.Success)
+		}
+	}
+	for _, c := range collaborations {
+		signal := affinitySignal{agent1: c.Agent1, agent2: c.Agent2}
+		if signal.agent2 < signal.agent1 {
+			signal.agent1, signal.agent2 = signal.agent2, signal.agent1
+		}
+		countOutcome(p.affinity, signal, tenant, c.Success)
+	}
+	if len(attention) > 0 {
+		pooled := append(p.blend[tenant], attention...)
+		if len(pooled) > p.config.MaxContribution {
+			pooled = pooled[len(pooled)-p.config.MaxContribution:]
+		}
+		p.blend[tenant] = pooled
+	}
+
+	var release privateRelease
+	for signal, tenants := range p.attention {
+		if successes, failures, ok := p.release(tenants); ok {
+			delete(p.attention, signal)
+			release.attention = append(release.attention, AttentionCount{
+				Category:  signal.category,
+				Agent:     signal.agent,
+				Successes: successes,
+				Failures:  failures,
+			})
+		}
+	}
+	sort.Slice(release.attention, func(i, j int) bool {
+		a, b := release.attention[i], release.attention[j]
+		return a.Category < b.Category || a.Category == b.Category && a.Agent < b.Agent
+	})
+	for signal, tenants := range p.affinity {
+		if successes, failures, ok := p.release(tenants); ok {
+			delete(p.affinity, signal)
+			release.collaborations = appendCollaborations(release.collaborations, signal, successes, failures)
+		}
+	}
+	if len(p.blend) >= p.config.MinTenants {
+		release.blend = interleave(p.blend)
+		p.blend = make(map[string][]AttentionFeedback)
+	}
+	return release
+}
+
+// countOutcome adds a tenant's outcome to a signal.
+func countOutcome[K comparable](signals map[K]map[string]*outcomeCounts, signal K, tenant string, success bool) {
+	tenants := signals[signal]
+	if tenants == nil {
+		tenants = make(map[string]*outcomeCounts)
+		signals[signal] = tenants
+	}
+	if tenants[tenant] == nil {
+		tenants[tenant] = &outcomeCounts{}
+	}
+	tenants[tenant].add(success)
+}
+
+// release returns a signal's noisy totals if enough tenants contributed
+// to it. The caller must hold p.mu.
+func (p *PrivateAggregator) release(tenants map[string]*outcomeCounts) (int, int, bool) {
+	if len(tenants) < p.config.MinTenants {
+		return 0, 0, false
+	}
+	var total outcomeCounts
+	for _, counts := range tenants {
+		capped := counts.capped(p.config.MaxContribution)
+		total.successes += capped.successes
+		total.failures += capped.failures
+	}
+	return p.noisy(total.successes), p.noisy(total.failures), true
+}
+
+// noisy adds Laplace noise to a count, scaled so that one tenant's capped
+// contribution is hidden within the privacy budget, and rounds the result
+// to a count. Without a budget the count is returned unchanged.
+func (p *PrivateAggregator) noisy(count int) int {
+	if p.config.Epsilon <= 0 {
+		return count
+	}
+	scale := float64(p.config.MaxContribution) / p.config.Epsilon
+	u := p.rng.Float64() - 0.5
+	noise := -scale * math.Copysign(math.Log(1-2*math.Abs(u)), u)
+	noised := int(math.Round(float64(count) + noise))
+	if noised < 0 {
+		return 0
+	}
+	return noised
+}
+
+// appendCollaborations expands a pair's released totals into outcomes.
+func appendCollaborations(outcomes []CollaborationOutcome, signal affinitySignal, successes, failures int) []CollaborationOutcome {
+	for i := 0; i < successes+failures; i++ {
+		outcomes = append(outcomes, CollaborationOutcome{Agent1: signal.agent1, Agent2: signal.agent2, Success: i < successes})
+	}
+	return outcomes
+}
+
+// interleave returns the tenants' pooled outcomes taking turns, so no
+// tenant's outcomes are learned from as one run.
+func interleave(pooled map[string][]AttentionFeedback) []AttentionFeedback {
+	tenants := make([]string, 0, len(pooled))
+	for tenant := range pooled {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	var out []AttentionFeedback
+	for i := 0; ; i++ {
+		added := false
+		for _, tenant := range tenants {
+			if i < len(pooled[tenant]) {
+				out = append(out, pooled[tenant][i])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// ============================================================================
+// Private Aggregation Tests
+// ============================================================================
+
+func TestFeedbackIngester_PrivacyHoldsSignalsUntilCohort(t *testing.T) {
+	attention := NewCollaborativeAttentionIndex()
+	affinity := NewAgentAffinityGraph()
+	ingester := NewFeedbackIngester(attention, affinity, nil)
+	privacy := NewPrivateAggregator(PrivacyConfig{MinTenants: 2, MaxContribution: 10})
+	ingester.SetPrivacy(privacy)
+	before := attention.state.Load().weights["testing"]["ECLIPSE"]
+
+	records := func(n int) []FeedbackRecord {
+		batch := make([]FeedbackRecord, n)
+		for i := range batch {
+			batch[i] = FeedbackRecord{Query: "write a unit test", Agent: "ECLIPSE", Collaborators: []string{"APEX"}, Success: true}
+		}
+		return batch
+	}
+
+	summary := ingester.ingest(features.WithTenant(context.Background(), "acme"), records(3))
+	if summary.Applied != 3 || summary.Pooled != 3 || summary.AttentionUpdates != 0 || summary.AffinityUpdates != 0 {
+		t.Fatalf("Expected 3 outcomes pooled and none applied, got %+v", summary)
+	}
+	if after := attention.state.Load().weights["testing"]["ECLIPSE"]; after != before {
+		t.Errorf("Expected ECLIPSE testing weight %f while one tenant contributed, got %f", before, after)
+	}
+	if affinity.totalCount["ECLIPSE"]["APEX"] != 0 {
+		t.Errorf("Expected no collaboration recorded while one tenant contributed, got %d", affinity.totalCount["ECLIPSE"]["APEX"])
+	}
+	// testing/ECLIPSE, documentation/ECLIPSE and ECLIPSE-APEX
+	if privacy.Pending() != 3 {
+		t.Errorf("Expected 3 pending signals, got %d", privacy.Pending())
+	}
+
+	summary = ingester.ingest(features.WithTenant(context.Background(), "globex"), records(1))
+	// Four outcomes in each of two categories
+	if summary.AttentionUpdates != 8 || summary.AffinityUpdates != 4 {
+		t.Errorf("Expected 8 attention and 4 affinity updates released, got %d and %d", summary.AttentionUpdates, summary.AffinityUpdates)
+	}
+	if after := attention.state.Load().weights["testing"]["ECLIPSE"]; after <= before {
+		t.Errorf("Expected ECLIPSE testing weight above %f once two tenants contributed, got %f", before, after)
+	}
+	if affinity.totalCount["ECLIPSE"]["APEX"] != 4 {
+		t.Errorf("Expected 4 ECLIPSE-APEX collaborations released, got %d", affinity.totalCount["ECLIPSE"]["APEX"])
+	}
+	if privacy.Pending() != 0 {
+		t.Errorf("Expected no pending signals after release, got %d", privacy.Pending())
+	}
+}
+
+func TestFeedbackIngester_PrivacyAppliesUntenantedOutcomes(t *testing.T) {
+	affinity := NewAgentAffinityGraph()
+	ingester := NewFeedbackIngester(nil, affinity, nil)
+	ingester.SetPrivacy(NewPrivateAggregator(PrivacyConfig{Epsilon: 1, MinTenants: 5, MaxContribution: 10}))
+
+	summary := ingester.Ingest([]FeedbackRecord{{Query: "review the design", Agent: "ARCHITECT", Collaborators: []string{"APEX"}, Success: true}})
+	if summary.Pooled != 0 || summary.AffinityUpdates != 1 || affinity.totalCount["ARCHITECT"]["APEX"] != 1 {
+		t.Errorf("Expected an outcome without a tenant applied directly, got %+v", summary)
+	}
+}
+
+func TestPrivateAggregator_CapsEachTenant(t *testing.T) {
+	privacy := NewPrivateAggregator(PrivacyConfig{MinTenants: 2, MaxContribution: 2})
+	heavy := make([]CollaborationOutcome, 10)
+	for i := range heavy {
+		heavy[i] = CollaborationOutcome{Agent1: "APEX", Agent2: "CIPHER", Success: true}
+	}
+
+	if release := privacy.add("acme", nil, nil, heavy); len(release.collaborations) != 0 {
+		t.Fatalf("Expected nothing released for one tenant, got %+v", release)
+	}
+	release := privacy.add("globex", nil, nil, []CollaborationOutcome{{Agent1: "CIPHER", Agent2: "APEX", Success: false}})
+	successes, failures := 0, 0
+	for _, outcome := range release.collaborations {
+		if outcome.Agent1 != "APEX" || outcome.Agent2 != "CIPHER" {
+			t.Errorf("Expected the pair in order, got %+v", outcome)
+		}
+		if outcome.Success {
+			successes++
+		} else {
+			failures++
+		}
+	}
+	if successes != 2 || failures != 1 {
+		t.Errorf("Expected 2 successes and 1 failure after capping, got %d and %d", successes, failures)
+	}
+
+	if capped := (outcomeCounts{successes: 8, failures: 2}).capped(5); capped.successes != 4 || capped.failures != 1 {
+		t.Errorf("Expected capping to keep the success rate, got %+v", capped)
+	}
+}
+
+func TestPrivateAggregator_PoolsRoutingBlend(t *testing.T) {
+	privacy := NewPrivateAggregator(PrivacyConfig{MinTenants: 2, MaxContribution: 2})
+	feedback := func(agent string, n int) []AttentionFeedback {
+		out := make([]AttentionFeedback, n)
+		for i := range out {
+			out[i] = AttentionFeedback{Query: "audit the login flow", Agent: agent, Success: true}
+		}
+		return out
+	}
+
+	if release := privacy.add("acme", feedback("CIPHER", 3), make([][]string, 3), nil); release.blend != nil {
+		t.Fatalf("Expected the blend held for one tenant, got %+v", release.blend)
+	}
+	release := privacy.add("globex", feedback("FORTRESS", 1), make([][]string, 1), nil)
+	if len(release.blend) != 3 {
+		t.Fatalf("Expected 3 blend outcomes with acme capped at 2, got %+v", release.blend)
+	}
+	if release.blend[0].Agent != "CIPHER" || release.blend[1].Agent != "FORTRESS" || release.blend[2].Agent != "CIPHER" {
+		t.Errorf("Expected the tenants' outcomes interleaved, got %+v", release.blend)
+	}
+}
+
+func TestPrivateAggregator_LaplaceNoise(t *testing.T) {
+	privacy := NewPrivateAggregator(PrivacyConfig{Epsilon: 1, MaxContribution: 1})
+	privacy.rng = rand.New(rand.NewSource(1))
+
+	const samples = 5000
+	sum, changed := 0, 0
+	for i := 0; i < samples; i++ {
+		n := privacy.noisy(50)
+		sum += n
+		if n != 50 {
+			changed++
+		}
+		if zero := privacy.noisy(0); zero < 0 {
+			t.Fatalf("Expected noisy counts to be non-negative, got %d", zero)
+		}
+	}
+	if mean := float64(sum) / samples; math.Abs(mean-50) > 0.2 {
+		t.Errorf("Expected noise centered on the count, got mean %f", mean)
+	}
+	if changed < samples/2 {
+		t.Errorf("Expected most counts changed by noise, got %d of %d", changed, samples)
+	}
+
+	exact := NewPrivateAggregator(PrivacyConfig{MinTenants: 2})
+	if n := exact.noisy(50); n != 50 {
+		t.Errorf("Expected no noise without a budget, got %d", n)
+	}
+}
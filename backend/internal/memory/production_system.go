@@ -17,25 +17,20 @@ import (
 	"fmt"
 	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
-)
-
-// productionIDCounter provides unique IDs for productions
-var productionIDCounter uint64
 
-// chunkIDCounter provides unique IDs for chunks
-var chunkIDCounter uint64
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
 
 // ============================================================================
 // Error Definitions
 // ============================================================================
 
 var (
-	ErrProductionNotFound    = errors.New("production not found")
-	ErrInvalidCondition      = errors.New("invalid condition")
-	ErrNoMatchingProductions = errors.New("no matching productions")
-	ErrProductionDisabled    = errors.New("production is disabled")
+	ErrProductionNotFound    = errdefs.New(errdefs.ErrNotFound, "production not found")
+	ErrInvalidCondition      = errdefs.New(errdefs.ErrInvalidArgument, "invalid condition")
+	ErrNoMatchingProductions = errdefs.New(errdefs.ErrNotFound, "no matching productions")
+	ErrProductionDisabled    = errdefs.New(errdefs.ErrConflict, "production is disabled")
 )
 
 // ============================================================================
@@ -124,7 +119,8 @@ type Condition struct {
 // Match tests if a working memory item matches this condition.
 func (c *Condition) Match(item *WorkingMemoryItem) bool {
 	if item == nil {
-		return c.Type == ConditionNotExists
+		// Nothing exists on a missing item, so only NOT_EXISTS holds
+		return (c.Type == ConditionNotExists) != c.Negated
 	}
 
 	var attrValue interface{}
@@ -182,16 +178,18 @@ func (c *Condition) matchValue(attrValue interface{}) bool {
 					findSubstring(strVal, pattern)))
 
 	case ConditionGreaterThan:
-		return compareNumeric(attrValue, c.Value) > 0
+		cmp, ok := compareNumeric(attrValue, c.Value)
+		return ok && cmp > 0
 
 	case ConditionLessThan:
-		return compareNumeric(attrValue, c.Value) < 0
+		cmp, ok := compareNumeric(attrValue, c.Value)
+		return ok && cmp < 0
 
 	case ConditionInRange:
-		val := toFloat64(attrValue)
-		min := toFloat64(c.Value)
-		max := toFloat64(c.SecondValue)
-		return val >= min && val <= max
+		val, valOK := toFloat64(attrValue)
+		min, minOK := toFloat64(c.Value)
+		max, maxOK := toFloat64(c.SecondValue)
+		return valOK && minOK && maxOK && val >= min && val <= max
 
 	case ConditionTypeMatch:
 		return fmt.Sprintf("%v", attrValue) == fmt.Sprintf("%v", c.Value)
@@ -211,33 +209,37 @@ func findSubstring(str, pattern string) bool {
 	return false
 }
 
-// compareNumeric compares two values numerically.
-func compareNumeric(a, b interface{}) int {
-	aVal := toFloat64(a)
-	bVal := toFloat64(b)
+// compareNumeric compares two values numerically. It reports false unless
+// both are numbers, so strings and other values never pass a threshold.
+func compareNumeric(a, b interface{}) (int, bool) {
+	aVal, aOK := toFloat64(a)
+	bVal, bOK := toFloat64(b)
+	if !aOK || !bOK {
+		return 0, false
+	}
 	if aVal > bVal {
-		return 1
+		return 1, true
 	} else if aVal < bVal {
-		return -1
+		return -1, true
 	}
-	return 0
+	return 0, true
 }
 
-// toFloat64 converts interface to float64.
-func toFloat64(v interface{}) float64 {
+// toFloat64 converts interface to float64, reporting whether it is a number.
+func toFloat64(v interface{}) (float64, bool) {
 	switch val := v.(type) {
 	case float64:
-		return val
+		return val, true
 	case float32:
-		return float64(val)
+		return float64(val), true
 	case int:
-		return float64(val)
+		return float64(val), true
 	case int64:
-		return float64(val)
+		return float64(val), true
 	case int32:
-		return float64(val)
+		return float64(val), true
 	default:
-		return 0
+		return 0, false
 	}
 }
 
@@ -575,7 +577,7 @@ func (ps *ProductionSystem) AddProduction(prod *Production) error {
 	}
 
 	if prod.ID == "" {
-		prod.ID = fmt.Sprintf("prod-%d", atomic.AddUint64(&productionIDCounter, 1))
+		prod.ID = NewID("prod")
 	}
 
 	prod.Specificity = len(prod.Conditions)
@@ -691,7 +693,9 @@ func (ps *ProductionSystem) Match() []*MatchResult {
 		return nil
 	}
 
-	items := ps.workingMemory.GetAll()
+	// Match against copies; working memory decays and rehearses items
+	// under its own lock while conditions read them
+	items := ps.workingMemory.SnapshotItems()
 	matches := make([]*MatchResult, 0)
 
 	for _, prod := range ps.productions {
@@ -909,10 +913,12 @@ func (ps *ProductionSystem) Fire(result *MatchResult) error {
 		Bindings:     result.Bindings,
 	}
 
+	var journal *GoalJournalStore
 	if ps.goalStack != nil {
 		current := ps.goalStack.Current()
 		if current != nil {
 			record.GoalID = current.ID
+			journal = ps.goalStack.Journal()
 		}
 	}
 
@@ -930,19 +936,27 @@ func (ps *ProductionSystem) Fire(result *MatchResult) error {
 	}
 
 	ps.stats.TotalFirings++
+	onFired := ps.onProductionFired
 
 	ps.mu.Unlock()
 
+	if journal != nil {
+		journal.RecordProduction(record.GoalID, prod)
+	}
+
 	// Execute actions (without lock to allow callbacks to access system)
 	for _, action := range prod.Actions {
 		if err := ps.executeAction(action, result.Bindings); err != nil {
 			return err
 		}
+		if action.Type == ActionInvokeAgent && journal != nil {
+			journal.RecordAgent(record.GoalID, action.AgentID)
+		}
 	}
 
 	// Notify callback
-	if ps.onProductionFired != nil {
-		ps.onProductionFired(prod, result)
+	if onFired != nil {
+		onFired(prod, result)
 	}
 
 	return nil
@@ -956,7 +970,14 @@ func (ps *ProductionSystem) executeAction(action *Action, bindings map[string]in
 			item := &WorkingMemoryItem{
 				ContentType: WorkingMemoryContentType(fmt.Sprintf("%v", action.Value)),
 				Content:     action.Metadata,
-				Metadata:    action.Metadata,
+			}
+			// Each item gets its own metadata, so modifying one never
+			// changes the rule or the items it added before
+			if action.Metadata != nil {
+				item.Metadata = make(map[string]interface{}, len(action.Metadata))
+				for k, v := range action.Metadata {
+					item.Metadata[k] = v
+				}
 			}
 			ps.workingMemory.Add(item)
 		}
@@ -968,14 +989,12 @@ func (ps *ProductionSystem) executeAction(action *Action, bindings map[string]in
 
 	case ActionModify:
 		if ps.workingMemory != nil {
-			item, exists := ps.workingMemory.Get(action.TargetID)
-			if !exists {
+			// Get rehearses the item; the write itself goes through working
+			// memory's lock
+			if _, exists := ps.workingMemory.Get(action.TargetID); !exists {
 				return errors.New("item not found")
 			}
-			if item.Metadata == nil {
-				item.Metadata = make(map[string]interface{})
-			}
-			item.Metadata[action.Attribute] = action.Value
+			ps.workingMemory.SetMetadata(action.TargetID, action.Attribute, action.Value)
 		}
 
 	case ActionPushGoal:
@@ -1110,7 +1129,7 @@ func (ps *ProductionSystem) LearnChunk(name string, sequence []*FiringRecord) (*
 
 	// Create chunk production
 	chunk := &Production{
-		ID:          fmt.Sprintf("chunk-%d", atomic.AddUint64(&chunkIDCounter, 1)),
+		ID:          NewID("chunk"),
 		Name:        name,
 		Description: fmt.Sprintf("Learned chunk from %d-step sequence", len(sequence)),
 		Conditions:  conditions,
@@ -1129,10 +1148,11 @@ func (ps *ProductionSystem) LearnChunk(name string, sequence []*FiringRecord) (*
 
 	ps.mu.Lock()
 	ps.stats.ProductionsLearned++
+	onLearned := ps.onLearned
 	ps.mu.Unlock()
 
-	if ps.onLearned != nil {
-		ps.onLearned(chunk)
+	if onLearned != nil {
+		onLearned(chunk)
 	}
 
 	return chunk, nil
@@ -1,6 +1,10 @@
 package memory
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -891,3 +895,202 @@ func BenchmarkCondition_Match(b *testing.B) {
 		cond.Match(item)
 	}
 }
+
+// ============================================================================
+// Concurrency Tests
+// ============================================================================
+
+func TestProductionSystem_ConcurrentCycleAndAdd(t *testing.T) {
+	wm := NewCognitiveWorkingMemory(DefaultWorkingMemoryConfig())
+	wm.Add(&WorkingMemoryItem{ID: "target", ContentType: ContentTypeGoal, Content: "task", Activation: 0.9})
+	wm.Add(&WorkingMemoryItem{ID: "context", ContentType: ContentTypeContext, Content: "ctx", Activation: 0.5})
+
+	psConfig := DefaultProductionSystemConfig()
+	psConfig.EnableRefraction = false
+	ps := NewProductionSystem(psConfig, wm, nil, nil)
+	var fired int64
+	ps.OnProductionFired(func(*Production, *MatchResult) { atomic.AddInt64(&fired, 1) })
+
+	const workers = 8
+	const iterations = 200
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				switch w % 4 {
+				case 0:
+					// Rules are added, toggled and removed while cycles run
+					prod := &Production{
+						Name: fmt.Sprintf("w%d-p%d", w, i),
+						Conditions: []*Condition{
+							{Type: ConditionEquals, Attribute: "type", Value: "goal"},
+							{Type: ConditionGreaterThan, Attribute: "activation", Value: 0.1},
+							{Type: ConditionNotExists, Attribute: "halted"},
+						},
+						Actions: []*Action{
+							{Type: ActionModify, TargetID: "target", Attribute: "step", Value: i},
+							{Type: ActionAdd, Value: "context", Metadata: map[string]interface{}{"step": i}},
+							{Type: ActionRemove, TargetID: "missing"},
+						},
+						Priority: float64(i%10) / 10,
+						Tags:     []string{"stress"},
+					}
+					if ps.AddProduction(prod) != nil {
+						continue
+					}
+					if i%3 == 0 {
+						ps.DisableProduction(prod.ID)
+						ps.EnableProduction(prod.ID)
+					}
+					if i%5 == 0 {
+						ps.RemoveProduction(prod.ID)
+					}
+				case 1, 2:
+					ps.Cycle()
+					ps.MarkSuccess()
+				default:
+					// Working memory keeps changing under the matcher
+					wm.Get("target")
+					wm.TriggerDecay()
+					ps.GetStats()
+					ps.GetConflictSet()
+					ps.GetRecentHistory(5)
+					ps.GetByTag("stress")
+					ps.Snapshot()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := ps.GetStats()
+	if stats.TotalProductions != ps.Count() {
+		t.Errorf("Expected %d productions in stats, got %d", ps.Count(), stats.TotalProductions)
+	}
+	if stats.TotalFirings != atomic.LoadInt64(&fired) {
+		t.Errorf("Expected %d firings, got %d", atomic.LoadInt64(&fired), stats.TotalFirings)
+	}
+}
+
+// ============================================================================
+// Fuzz Tests
+// ============================================================================
+
+// fuzzValue builds a loosely typed value of one of the kinds conditions and
+// predicates meet in working memory, state features and rule definitions.
+func fuzzValue(kind uint8, s string, f float64, i int64) interface{} {
+	switch kind % 10 {
+	case 0:
+		return nil
+	case 1:
+		return s
+	case 2:
+		return f
+	case 3:
+		return float32(f)
+	case 4:
+		return int(i)
+	case 5:
+		return i
+	case 6:
+		return int32(i)
+	case 7:
+		return i%2 == 0
+	case 8:
+		return []string{s}
+	default:
+		return map[string]interface{}{s: f}
+	}
+}
+
+// isNumber reports whether a fuzz value is one of the numeric kinds.
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case float64, float32, int, int64, int32:
+		return true
+	}
+	return false
+}
+
+// itemFields are the attributes Condition.Match reads from item fields
+// rather than metadata.
+var itemFields = map[string]bool{
+	"id": true, "type": true, "activation": true, "source": true, "chunk_id": true, "salience": true,
+}
+
+func FuzzCondition_Match(f *testing.F) {
+	f.Add(uint8(ConditionEquals), "content", uint8(1), uint8(1), "goal", 0.5, int64(1), false)
+	f.Add(uint8(ConditionContains), "content", uint8(1), uint8(1), "test message", 0.0, int64(0), true)
+	f.Add(uint8(ConditionGreaterThan), "score", uint8(1), uint8(4), "abc", -1.0, int64(-1), false)
+	f.Add(uint8(ConditionLessThan), "activation", uint8(4), uint8(2), "", 0.75, int64(2), false)
+	f.Add(uint8(ConditionInRange), "priority", uint8(2), uint8(5), "high", 1e308, int64(-1<<63), false)
+	f.Add(uint8(ConditionExists), "missing", uint8(0), uint8(0), "", 0.0, int64(0), true)
+	f.Add(uint8(ConditionTypeMatch), "content", uint8(8), uint8(9), "[x]", 0.0, int64(0), false)
+
+	f.Fuzz(func(t *testing.T, typ uint8, attribute string, attrKind, valueKind uint8, s string, x float64, i int64, negated bool) {
+		attrValue := fuzzValue(attrKind, s, x, i)
+		item := &WorkingMemoryItem{
+			ID:         "item-1",
+			Content:    attrValue,
+			Activation: x,
+			Metadata:   map[string]interface{}{attribute: attrValue},
+		}
+		cond := &Condition{
+			Type:        ConditionType(typ % 10),
+			Attribute:   attribute,
+			Value:       fuzzValue(valueKind, s, x, i),
+			SecondValue: fuzzValue(valueKind, s, x+1, i+1),
+		}
+
+		// Neither call may panic, and negation must invert every result
+		matched := cond.Match(item)
+		cond.Negated = true
+		if cond.Match(item) == matched {
+			t.Fatalf("Expected negated %v to invert %v", cond.Type, matched)
+		}
+		cond.Negated = negated
+		nilMatched := cond.Match(nil)
+		cond.Negated = !negated
+		if cond.Match(nil) == nilMatched {
+			t.Fatalf("Expected negated %v to invert %v for a nil item", cond.Type, nilMatched)
+		}
+		cond.Negated = false
+
+		// Opposite conditions never both match
+		opposite := *cond
+		switch cond.Type {
+		case ConditionEquals:
+			opposite.Type = ConditionNotEquals
+		case ConditionGreaterThan:
+			opposite.Type = ConditionLessThan
+		case ConditionExists:
+			opposite.Type = ConditionNotExists
+		}
+		if opposite.Type != cond.Type && matched && opposite.Match(item) {
+			t.Fatalf("Expected %v and %v not to both match", cond.Type, opposite.Type)
+		}
+
+		// Numeric comparisons only match numbers
+		numeric := cond.Type == ConditionGreaterThan || cond.Type == ConditionLessThan || cond.Type == ConditionInRange
+		if numeric && matched && !isNumber(cond.Value) {
+			t.Fatalf("Expected %v not to match a %T threshold", cond.Type, cond.Value)
+		}
+		if itemFields[attribute] {
+			return
+		}
+		if numeric && matched && !isNumber(attrValue) {
+			t.Fatalf("Expected %v not to match a %T attribute", cond.Type, attrValue)
+		}
+
+		// Contains is a substring test on strings
+		if cond.Type == ConditionContains {
+			text, textOK := attrValue.(string)
+			pattern, patternOK := cond.Value.(string)
+			if textOK && patternOK && pattern != "" && matched != strings.Contains(text, pattern) {
+				t.Fatalf("Expected contains(%q, %q) = %v, got %v", text, pattern, !matched, matched)
+			}
+		}
+	})
+}
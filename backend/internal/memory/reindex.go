@@ -0,0 +1,567 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements rebuilding of derived indexes.
+//
+// Every index the memory system answers from is derived from a primary
+// store: the Semantic Network's adjacency lists and secondary indexes from
+// its node and relation tables; the retriever's HNSW graph, LSH tables,
+// Bloom filter and agent, tier and signature indexes from its experiences;
+// the skill filters from each agent's skill list; and the affinity graph's
+// routing tables from its scores. A Reindexer rebuilds them from those
+// stores, to repair index drift or drop stale entries (the Bloom filter
+// never forgets) without a restart.
+//
+// Offline, an index is rebuilt in place under its structure's write lock,
+// so requests that need it wait. Online, a shadow index is built from a
+// frozen view of the store while the old one keeps serving; writes that
+// land during the build are applied to the shadow under the write lock,
+// and the shadow is swapped in atomically.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrReindexRunning is returned when a reindex is started while one runs
+var ErrReindexRunning = errdefs.New(errdefs.ErrConflict, "reindex already running")
+
+// reindexProgressSteps is how many progress events an index reports at
+// most.
+const reindexProgressSteps = 10
+
+// ReindexOptions configures a reindex run.
+type ReindexOptions struct {
+	// Online builds shadow indexes and swaps them in, so reads are served
+	// from the old indexes throughout
+	Online bool
+}
+
+// ReindexTarget is one derived index a Reindexer rebuilds.
+type ReindexTarget struct {
+	Name    string
+	Rebuild func(ctx context.Context, online bool, progress WarmupProgress) error
+}
+
+// ReindexProgress reports how far the rebuild of one index has got.
+type ReindexProgress struct {
+	Index string `json:"index"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// ReindexResult is the outcome of rebuilding one index.
+type ReindexResult struct {
+	Index string `json:"index"`
+	// Items is the number of primary records indexed
+	Items    int    `json:"items"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReindexReport summarizes a reindex run.
+type ReindexReport struct {
+	Online bool `json:"online"`
+	// Indexes are the rebuilt indexes in order, ending with a failed one
+	Indexes []ReindexResult `json:"indexes"`
+	Elapsed string          `json:"elapsed"`
+}
+
+// Reindexer rebuilds derived indexes from their primary stores. Add
+// targets before the reindexer is shared; one run is allowed at a time.
+type Reindexer struct {
+	targets []ReindexTarget
+
+	// running is set while a run is in progress
+	running atomic.Bool
+}
+
+// NewReindexer creates a reindexer with no targets.
+func NewReindexer() *Reindexer {
+	return &Reindexer{targets: make([]ReindexTarget, 0)}
+}
+
+// AddTarget appends an index to rebuild.
+func (r *Reindexer) AddTarget(target ReindexTarget) {
+	r.targets = append(r.targets, target)
+}
+
+// AddSemanticNetwork rebuilds a network's adjacency lists and secondary
+// indexes.
+func (r *Reindexer) AddSemanticNetwork(sn *SemanticNetwork) {
+	r.AddTarget(ReindexTarget{
+		Name: "semantic network indexes",
+		Rebuild: func(ctx context.Context, online bool, progress WarmupProgress) error {
+			if online {
+				return sn.RebuildIndexesOnline(ctx, progress)
+			}
+			sn.RebuildIndexes(progress)
+			return nil
+		},
+	})
+}
+
+// AddRetriever rebuilds a retriever's HNSW graph, LSH tables, Bloom filter
+// and secondary indexes.
+func (r *Reindexer) AddRetriever(retriever *SubLinearRetriever) {
+	r.AddTarget(ReindexTarget{
+		Name: "experience indexes",
+		Rebuild: func(ctx context.Context, online bool, progress WarmupProgress) error {
+			return retriever.RebuildIndexes(ctx, online, progress)
+		},
+	})
+}
+
+// AddSkillCascade rebuilds a cascade's skill filters.
+func (r *Reindexer) AddSkillCascade(cascade *SkillBloomCascade) {
+	r.AddTarget(ReindexTarget{
+		Name: "skill filters",
+		Rebuild: func(ctx context.Context, online bool, progress WarmupProgress) error {
+			cascade.Rebuild(progress)
+			return nil
+		},
+	})
+}
+
+// AddAffinityGraph rebuilds a graph's routing tables.
+func (r *Reindexer) AddAffinityGraph(graph *AgentAffinityGraph) {
+	r.AddTarget(ReindexTarget{
+		Name: "routing tables",
+		Rebuild: func(ctx context.Context, online bool, progress WarmupProgress) error {
+			graph.RebuildRoutingTables(progress)
+			return nil
+		},
+	})
+}
+
+// Run rebuilds every target in order, stopping at the first failure.
+// progress, if set, is called as indexes advance; offline it may run under
+// an index's write lock and must be quick.
+func (r *Reindexer) Run(ctx context.Context, opts ReindexOptions, progress func(ReindexProgress)) (*ReindexReport, error) {
+	if !r.running.CompareAndSwap(false, true) {
+		return nil, ErrReindexRunning
+	}
+	defer r.running.Store(false)
+	return r.run(ctx, opts, progress)
+}
+
+// run rebuilds the targets; the caller has set running.
+func (r *Reindexer) run(ctx context.Context, opts ReindexOptions, progress func(ReindexProgress)) (*ReindexReport, error) {
+	startedAt := time.Now()
+	report := &ReindexReport{Online: opts.Online, Indexes: make([]ReindexResult, 0, len(r.targets))}
+	defer func() {
+		report.Elapsed = time.Since(startedAt).Round(time.Millisecond).String()
+	}()
+
+	for _, target := range r.targets {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result := ReindexResult{Index: target.Name}
+		targetStart := time.Now()
+		reported := 0
+		err := target.Rebuild(ctx, opts.Online, func(done, total int) {
+			result.Items = done
+			// Report each time another tenth of the index is done
+			if progress == nil || total == 0 || done*reindexProgressSteps/total <= reported {
+				return
+			}
+			reported = done * reindexProgressSteps / total
+			progress(ReindexProgress{Index: target.Name, Done: done, Total: total})
+		})
+		result.Duration = time.Since(targetStart).Round(time.Millisecond).String()
+		if err != nil {
+			result.Error = err.Error()
+			report.Indexes = append(report.Indexes, result)
+			return report, fmt.Errorf("rebuilding %s: %w", target.Name, err)
+		}
+		report.Indexes = append(report.Indexes, result)
+		log.Printf("Reindex: rebuilt %s from %d items in %s", target.Name, result.Items, result.Duration)
+	}
+	return report, nil
+}
+
+// ============================================================================
+// Semantic Network
+// ============================================================================
+
+// RebuildIndexesOnline rebuilds the adjacency lists and secondary indexes
+// into shadow copies while the network keeps serving, then swaps them in.
+// Mutations made during the build are caught up under the write lock,
+// which is the only time readers and writers wait.
+func (sn *SemanticNetwork) RebuildIndexesOnline(ctx context.Context, progress func(done, total int)) error {
+	// Freezing starts a snapshot epoch: writers copy frozen nodes before
+	// changing them, so a changed node has a new pointer
+	nodes, relations, _, _ := sn.freeze()
+	defer atomic.AddInt64(&sn.activeSnapshots, -1)
+
+	shadow := &SemanticNetwork{
+		outgoing:          make(map[string][]*SemanticRelation, len(nodes)),
+		incoming:          make(map[string][]*SemanticRelation, len(nodes)),
+		typeIndex:         make(map[NodeType]map[string]*SemanticNode),
+		relationTypeIndex: make(map[RelationType]map[string]*SemanticRelation),
+		propertyIndex:     newPropertyIndex(sn.config.IndexedProperties),
+		propertyIndexed:   make(map[string]map[string]string),
+		nameIndex:         make(map[string]map[string]*SemanticNode),
+		nameIndexed:       make(map[string][]string),
+	}
+
+	total := len(nodes) + len(relations)
+	done := 0
+	frozenNodes := make(map[string]*SemanticNode, len(nodes))
+	for _, node := range nodes {
+		if done%256 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		frozenNodes[node.ID] = node
+		shadow.outgoing[node.ID] = make([]*SemanticRelation, 0)
+		shadow.incoming[node.ID] = make([]*SemanticRelation, 0)
+		shadow.indexNode(node)
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	// Sorted so adjacency order doesn't depend on map iteration
+	sort.Slice(relations, func(i, j int) bool { return relations[i].ID < relations[j].ID })
+	frozenRelations := make(map[string]*SemanticRelation, len(relations))
+	for _, rel := range relations {
+		if done%256 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		frozenRelations[rel.ID] = rel
+		shadow.outgoing[rel.SourceID] = append(shadow.outgoing[rel.SourceID], rel)
+		shadow.incoming[rel.TargetID] = append(shadow.incoming[rel.TargetID], rel)
+		shadow.indexRelation(rel)
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	// Relations are never changed in place, so a different pointer is a
+	// removal and re-add
+	for id, old := range frozenRelations {
+		if rel, ok := sn.relations[id]; !ok || rel != old {
+			shadow.removeFromOutgoing(old.SourceID, id)
+			shadow.removeFromIncoming(old.TargetID, id)
+			shadow.unindexRelation(old)
+		}
+	}
+	for id, old := range frozenNodes {
+		if _, ok := sn.nodes[id]; !ok {
+			shadow.unindexNode(old)
+			delete(shadow.outgoing, id)
+			delete(shadow.incoming, id)
+		}
+	}
+	for id, node := range sn.nodes {
+		old, ok := frozenNodes[id]
+		switch {
+		case !ok:
+			shadow.outgoing[id] = make([]*SemanticRelation, 0)
+			shadow.incoming[id] = make([]*SemanticRelation, 0)
+		case old == node:
+			continue
+		default:
+			shadow.unindexNode(old)
+		}
+		shadow.indexNode(node)
+	}
+	for id, rel := range sn.relations {
+		if frozenRelations[id] != rel {
+			shadow.outgoing[rel.SourceID] = append(shadow.outgoing[rel.SourceID], rel)
+			shadow.incoming[rel.TargetID] = append(shadow.incoming[rel.TargetID], rel)
+			shadow.indexRelation(rel)
+		}
+	}
+
+	sn.outgoing, sn.incoming = shadow.outgoing, shadow.incoming
+	sn.typeIndex, sn.relationTypeIndex = shadow.typeIndex, shadow.relationTypeIndex
+	sn.propertyIndex, sn.propertyIndexed = shadow.propertyIndex, shadow.propertyIndexed
+	sn.nameIndex, sn.nameIndexed = shadow.nameIndex, shadow.nameIndexed
+	sn.invalidateDepthCache()
+	return nil
+}
+
+// ============================================================================
+// Experience Retriever
+// ============================================================================
+
+// retrieverIndexes are the indexes a retriever derives from its
+// experiences, built off to the side by RebuildIndexes.
+type retrieverIndexes struct {
+	lsh          *LSHIndex
+	hnsw         *HNSWGraph
+	bloom        *BloomFilter
+	agentIndex   map[string][]string
+	tierIndex    map[int][]string
+	taskSigIndex map[string]string
+	dimension    int
+}
+
+// add indexes an experience.
+func (x *retrieverIndexes) add(exp *ExperienceTuple) {
+	x.bloom.Add(exp.TaskSignature)
+	x.taskSigIndex[exp.TaskSignature] = exp.ID
+	x.agentIndex[exp.AgentID] = append(x.agentIndex[exp.AgentID], exp.ID)
+	x.tierIndex[exp.TierID] = append(x.tierIndex[exp.TierID], exp.ID)
+	if len(exp.Embedding) == x.dimension {
+		x.lsh.Add(exp.ID, exp.Embedding)
+		x.hnsw.Add(exp.ID, exp.Embedding)
+	}
+}
+
+// remove unindexes an experience. The Bloom filter keeps its signature.
+func (x *retrieverIndexes) remove(exp *ExperienceTuple) {
+	if x.taskSigIndex[exp.TaskSignature] == exp.ID {
+		delete(x.taskSigIndex, exp.TaskSignature)
+	}
+	x.agentIndex[exp.AgentID] = removeID(x.agentIndex[exp.AgentID], exp.ID)
+	x.tierIndex[exp.TierID] = removeID(x.tierIndex[exp.TierID], exp.ID)
+	if len(exp.Embedding) == x.dimension {
+		x.lsh.Remove(exp.ID, exp.Embedding)
+		x.hnsw.Remove(exp.ID)
+	}
+}
+
+// removeID removes the first occurrence of id from ids.
+func removeID(ids []string, id string) []string {
+	for i, existing := range ids {
+		if existing == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// RebuildIndexes rebuilds the HNSW graph, LSH tables, Bloom filter and
+// secondary indexes from the stored experiences. Offline, retrievals,
+// adds and removes wait for the whole rebuild; online, they wait only
+// while experiences changed during the build are caught up and the new
+// indexes are swapped in. Run one rebuild at a time.
+func (r *SubLinearRetriever) RebuildIndexes(ctx context.Context, online bool, progress func(done, total int)) error {
+	if !online {
+		r.indexMu.Lock()
+		defer r.indexMu.Unlock()
+	}
+
+	r.expMu.RLock()
+	frozen := make(map[string]*ExperienceTuple, len(r.experiences))
+	experiences := make([]*ExperienceTuple, 0, len(r.experiences))
+	for id, exp := range r.experiences {
+		frozen[id] = exp
+		experiences = append(experiences, exp)
+	}
+	r.expMu.RUnlock()
+
+	// Insertion order keeps the agent and tier index order of Add
+	sort.Slice(experiences, func(i, j int) bool {
+		if experiences[i].Timestamp != experiences[j].Timestamp {
+			return experiences[i].Timestamp < experiences[j].Timestamp
+		}
+		return experiences[i].ID < experiences[j].ID
+	})
+
+	if online {
+		r.indexMu.RLock()
+	}
+	shadow := r.emptyIndexes()
+	if online {
+		r.indexMu.RUnlock()
+	}
+	for i, exp := range experiences {
+		if i%256 == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		shadow.add(exp)
+		if progress != nil {
+			progress(i+1, len(experiences))
+		}
+	}
+
+	if online {
+		// Add and Remove hold indexMu for reading, so none is in flight
+		r.indexMu.Lock()
+		defer r.indexMu.Unlock()
+
+		r.expMu.RLock()
+		for id, old := range frozen {
+			if exp, ok := r.experiences[id]; !ok || exp != old {
+				shadow.remove(old)
+			}
+		}
+		for id, exp := range r.experiences {
+			if frozen[id] != exp {
+				shadow.add(exp)
+			}
+		}
+		r.expMu.RUnlock()
+	}
+
+	r.lsh, r.hnsw, r.bloom = shadow.lsh, shadow.hnsw, shadow.bloom
+	r.taskSigMu.Lock()
+	r.taskSigIndex = shadow.taskSigIndex
+	r.taskSigMu.Unlock()
+	r.agentMu.Lock()
+	r.agentIndex = shadow.agentIndex
+	r.agentMu.Unlock()
+	r.tierMu.Lock()
+	r.tierIndex = shadow.tierIndex
+	r.tierMu.Unlock()
+	return nil
+}
+
+// emptyIndexes creates empty indexes configured like the retriever's own.
+func (r *SubLinearRetriever) emptyIndexes() *retrieverIndexes {
+	r.hnsw.mu.RLock()
+	hnsw := NewHNSWGraph(r.dimension, r.hnsw.mMax, r.hnsw.efConstruction)
+	hnsw.efSearch = r.hnsw.efSearch
+	r.hnsw.mu.RUnlock()
+
+	return &retrieverIndexes{
+		lsh:          r.lsh.emptyCopy(),
+		hnsw:         hnsw,
+		bloom:        NewBloomFilter(r.bloom.size, r.bloom.numHash),
+		agentIndex:   make(map[string][]string),
+		tierIndex:    make(map[int][]string),
+		taskSigIndex: make(map[string]string),
+		dimension:    r.dimension,
+	}
+}
+
+// ============================================================================
+// Skill Filters and Routing Tables
+// ============================================================================
+
+// Rebuild rebuilds every agent's skill filter and the skill index from the
+// agents' skill lists. Lookups keep reading the old snapshot until the new
+// one is swapped in.
+func (c *SkillBloomCascade) Rebuild(progress func(done, total int)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.state.Load()
+	agents := make([]string, 0, len(current.agentFilters))
+	for agentID := range current.agentFilters {
+		agents = append(agents, agentID)
+	}
+	sort.Strings(agents)
+
+	next := &skillCascadeState{
+		agentFilters: make(map[string]*SkillFilter, len(agents)),
+		skillIndex:   make(map[string][]string),
+	}
+	for i, agentID := range agents {
+		next.addAgent(agentID, current.agentFilters[agentID].skills)
+		if progress != nil {
+			progress(i+1, len(agents))
+		}
+	}
+	c.state.Store(next)
+}
+
+// RebuildRoutingTables recomputes every agent's routing table from the
+// affinity scores. The tables are small, so they are rebuilt under the
+// lock in either mode.
+func (g *AgentAffinityGraph) RebuildRoutingTables(progress func(done, total int)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.routingTable = make(map[string][]string, len(g.affinity))
+	g.rebuildRoutingTables()
+	if progress != nil {
+		progress(len(g.affinity), len(g.affinity))
+	}
+}
+
+// ============================================================================
+// HTTP Handler
+// ============================================================================
+
+// ReindexEventType identifies a streamed reindex event.
+type ReindexEventType string
+
+const (
+	// ReindexProgressEvent reports how far an index has got
+	ReindexProgressEvent ReindexEventType = "progress"
+	// ReindexErrorEvent reports a failed run and ends the stream
+	ReindexErrorEvent ReindexEventType = "error"
+	// ReindexSummaryEvent reports the finished run and ends the stream
+	ReindexSummaryEvent ReindexEventType = "summary"
+)
+
+// ReindexEvent is one line of the reindex progress stream.
+type ReindexEvent struct {
+	Type ReindexEventType `json:"type"`
+	*ReindexProgress
+	Report *ReindexReport `json:"report,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// ServeReindex handles POST /admin/memory/reindex - rebuilds every index,
+// online if ?online=true, and streams NDJSON progress events ending with
+// a summary or error event.
+func (r *Reindexer) ServeReindex(w http.ResponseWriter, req *http.Request) {
+	opts := ReindexOptions{}
+	if value := req.URL.Query().Get("online"); value != "" {
+		online, err := strconv.ParseBool(value)
+		if err != nil {
+			errdefs.WriteError(w, "online must be true or false", http.StatusBadRequest)
+			return
+		}
+		opts.Online = online
+	}
+	if !r.running.CompareAndSwap(false, true) {
+		errdefs.WriteHTTP(w, ErrReindexRunning)
+		return
+	}
+
+	// Progress may be reported under an index's write lock, so it is
+	// handed off without waiting for the client; events are dropped when
+	// it falls behind
+	events := make(chan ReindexEvent, 64)
+	go func() {
+		defer close(events)
+		defer r.running.Store(false)
+		report, err := r.run(req.Context(), opts, func(p ReindexProgress) {
+			select {
+			case events <- ReindexEvent{Type: ReindexProgressEvent, ReindexProgress: &p}:
+			default:
+			}
+		})
+		if err != nil {
+			log.Printf("Reindex failed: %v", err)
+			events <- ReindexEvent{Type: ReindexErrorEvent, Report: report, Error: err.Error()}
+			return
+		}
+		events <- ReindexEvent{Type: ReindexSummaryEvent, Report: report}
+	}()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for event := range events {
+		// Unsupported by some writers (e.g. in tests); a rebuild of a
+		// large store may outlast the server's write timeout otherwise
+		_ = rc.SetWriteDeadline(time.Now().Add(time.Minute))
+		if err := encoder.Encode(event); err == nil {
+			_ = rc.Flush()
+		}
+	}
+}
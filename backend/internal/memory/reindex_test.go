@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Semantic Network Tests
+// ============================================================================
+
+func TestSemanticNetwork_RebuildIndexesOnline(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"tier"}
+	sn := NewSemanticNetwork(config)
+	apex := NewSemanticNode("apex", "APEX", AgentNode)
+	apex.SetProperty("tier", NumberValue(1, ""))
+	sn.AddNode(apex)
+	sn.AddNode(NewSemanticNode("tier-1", "Tier 1", DomainNode))
+	sn.AddNode(NewSemanticNode("cipher", "CIPHER", AgentNode))
+	sn.AddRelation(NewSemanticRelation("apex", "tier-1", BelongsTo))
+	removed := NewSemanticRelation("cipher", "tier-1", BelongsTo)
+	sn.AddRelation(removed)
+
+	// Damage the indexes, then change the network while the shadow builds
+	sn.typeIndex = make(map[NodeType]map[string]*SemanticNode)
+	changed := false
+	err := sn.RebuildIndexesOnline(context.Background(), func(done, total int) {
+		if changed {
+			return
+		}
+		changed = true
+		sn.AddNode(NewSemanticNode("velocity", "VELOCITY", AgentNode))
+		sn.AddRelation(NewSemanticRelation("velocity", "tier-1", BelongsTo))
+		sn.RemoveRelation(removed.ID)
+		sn.SetNodeProperty("apex", "tier", NumberValue(2, ""))
+	})
+	if err != nil {
+		t.Fatalf("Expected online rebuild to succeed, got %v", err)
+	}
+
+	if err := sn.VerifyIntegrity(); err != nil {
+		t.Errorf("Expected integrity after rebuild, got %v", err)
+	}
+	if got := len(sn.GetNodesByType(AgentNode)); got != 3 {
+		t.Errorf("Expected 3 agents in the type index, got %d", got)
+	}
+	if got := len(sn.GetIncomingRelations("tier-1")); got != 2 {
+		t.Errorf("Expected 2 incoming relations after catch-up, got %d", got)
+	}
+	if len(sn.FindNodesByProperty("tier", NumberValue(1, ""))) != 0 || len(sn.FindNodesByProperty("tier", NumberValue(2, ""))) != 1 {
+		t.Error("Expected property index to follow the change made during the build")
+	}
+}
+
+func TestSemanticNetwork_RebuildIndexesOnlineCancelled(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("apex", "APEX", AgentNode))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sn.RebuildIndexesOnline(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if sn.activeSnapshots != 0 {
+		t.Errorf("Expected the snapshot epoch ended, got %d active", sn.activeSnapshots)
+	}
+	if len(sn.GetNodesByType(AgentNode)) != 1 {
+		t.Error("Expected old indexes kept")
+	}
+}
+
+// ============================================================================
+// Experience Retriever Tests
+// ============================================================================
+
+func TestSubLinearRetriever_RebuildIndexes(t *testing.T) {
+	for _, online := range []bool{false, true} {
+		r := NewSubLinearRetriever(4)
+		first := NewExperienceTuple("APEX", 1, "sort records", "merge sort", "direct")
+		first.Embedding = []float32{1, 0, 0, 0}
+		second := NewExperienceTuple("APEX", 1, "cache results", "add an LRU cache", "direct")
+		second.Embedding = []float32{0, 1, 0, 0}
+		r.Add(first)
+		r.Add(second)
+		r.Remove(second.ID)
+
+		// Adds and removes during an online build wait for the catch-up,
+		// so they can't run from the progress callback
+		calls := 0
+		if err := r.RebuildIndexes(context.Background(), online, func(done, total int) { calls++ }); err != nil {
+			t.Fatalf("online=%v: Expected rebuild to succeed, got %v", online, err)
+		}
+		if calls != 1 {
+			t.Errorf("online=%v: Expected 1 progress call, got %d", online, calls)
+		}
+		if r.bloom.MayContain(second.TaskSignature) {
+			t.Errorf("online=%v: Expected the removed signature dropped from the Bloom filter", online)
+		}
+		if got := r.hnsw.Size(); got != 1 {
+			t.Errorf("online=%v: Expected 1 vector in the HNSW graph, got %d", online, got)
+		}
+
+		third := NewExperienceTuple("APEX", 1, "profile the service", "use pprof", "direct")
+		third.Embedding = []float32{0, 0, 1, 0}
+		if err := r.Add(third); err != nil {
+			t.Fatalf("online=%v: Expected add after rebuild, got %v", online, err)
+		}
+		if got := len(r.GetByAgent("APEX")); got != 2 {
+			t.Errorf("online=%v: Expected 2 experiences in the agent index, got %d", online, got)
+		}
+		result, _ := r.Retrieve(&QueryContext{AgentID: "APEX", Embedding: third.Embedding, TopK: 1})
+		if len(result.Experiences) != 1 || result.Experiences[0].ID != third.ID {
+			t.Errorf("online=%v: Expected vector search to find the new experience, got %+v", online, result.Experiences)
+		}
+	}
+}
+
+// ============================================================================
+// Skill Filter and Routing Table Tests
+// ============================================================================
+
+func TestSkillBloomCascade_Rebuild(t *testing.T) {
+	c := NewSkillBloomCascade()
+	before := c.FindAgentsWithSkills([]string{"cryptography"})
+
+	calls := 0
+	c.Rebuild(func(done, total int) { calls++ })
+	if calls != len(c.state.Load().agentFilters) {
+		t.Errorf("Expected a progress call per agent, got %d", calls)
+	}
+	after := c.FindAgentsWithSkills([]string{"cryptography"})
+	if len(after) != len(before) || len(after) == 0 {
+		t.Errorf("Expected the same matches after rebuild, got %v and %v", before, after)
+	}
+}
+
+func TestAgentAffinityGraph_RebuildRoutingTables(t *testing.T) {
+	g := NewAgentAffinityGraph()
+	g.mu.Lock()
+	g.affinity["APEX"]["ORACLE"] = 5
+	delete(g.routingTable, "APEX")
+	g.mu.Unlock()
+
+	g.RebuildRoutingTables(nil)
+	if top := g.GetTopCollaborators("APEX", 1); len(top) != 1 || top[0] != "ORACLE" {
+		t.Errorf("Expected ORACLE as APEX's top collaborator, got %v", top)
+	}
+}
+
+// ============================================================================
+// Reindexer Tests
+// ============================================================================
+
+func TestReindexer_Run(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		sn.AddNode(NewSemanticNode(id, strings.ToUpper(id), ConceptNode))
+	}
+	reindexer := NewReindexer()
+	reindexer.AddSemanticNetwork(sn)
+	reindexer.AddAffinityGraph(NewAgentAffinityGraph())
+
+	events := make([]ReindexProgress, 0)
+	report, err := reindexer.Run(context.Background(), ReindexOptions{Online: true}, func(p ReindexProgress) {
+		events = append(events, p)
+	})
+	if err != nil {
+		t.Fatalf("Expected reindex to succeed, got %v", err)
+	}
+	if len(report.Indexes) != 2 || report.Indexes[0].Items != 12 || !report.Online {
+		t.Errorf("Expected 2 indexes with 12 nodes in the first, got %+v", report)
+	}
+	// 12 nodes report at most once a tenth, plus once for the routing tables
+	if len(events) > reindexProgressSteps+1 {
+		t.Errorf("Expected progress to be throttled, got %d events", len(events))
+	}
+	if last := events[len(events)-1]; last.Index != "routing tables" || last.Done != last.Total {
+		t.Errorf("Expected final routing table progress, got %+v", last)
+	}
+}
+
+func TestReindexer_RunFailure(t *testing.T) {
+	reindexer := NewReindexer()
+	reindexer.AddTarget(ReindexTarget{
+		Name: "broken",
+		Rebuild: func(ctx context.Context, online bool, progress WarmupProgress) error {
+			return errors.New("disk on fire")
+		},
+	})
+	reindexer.AddAffinityGraph(NewAgentAffinityGraph())
+
+	report, err := reindexer.Run(context.Background(), ReindexOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "rebuilding broken") {
+		t.Fatalf("Expected failure naming the index, got %v", err)
+	}
+	if len(report.Indexes) != 1 || report.Indexes[0].Error == "" {
+		t.Errorf("Expected the run to stop at the failed index, got %+v", report.Indexes)
+	}
+}
+
+func TestReindexer_ServeReindex(t *testing.T) {
+	reindexer := NewReindexer()
+	reindexer.AddAffinityGraph(NewAgentAffinityGraph())
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/memory/reindex?online=true", nil)
+	w := httptest.NewRecorder()
+	reindexer.ServeReindex(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	var last ReindexEvent
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("Failed to decode final event: %v", err)
+	}
+	if last.Type != ReindexSummaryEvent || last.Report == nil || !last.Report.Online {
+		t.Errorf("Expected an online summary event, got %+v", last)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/memory/reindex?online=maybe", nil)
+	w = httptest.NewRecorder()
+	reindexer.ServeReindex(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a malformed flag, got %d", w.Code)
+	}
+
+	reindexer.running.Store(true)
+	w = httptest.NewRecorder()
+	reindexer.ServeReindex(w, httptest.NewRequest(http.MethodPost, "/admin/memory/reindex", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 while a reindex runs, got %d", w.Code)
+	}
+}
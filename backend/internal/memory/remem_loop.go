@@ -4,8 +4,10 @@
 package memory
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"sync"
 	"time"
@@ -195,8 +197,8 @@ func (u *MemoryUpdater) AddAndEvolve(exp *ExperienceTuple, eval *Evaluation) err
 	exp.Metadata["evaluation_score"] = eval.Score
 	exp.Metadata["evaluation_feedback"] = eval.Feedback
 
-	// Add to retriever
-	if err := u.retriever.Add(exp); err != nil {
+	// Add to retriever, merging into a near-duplicate if dedup is enabled
+	if _, _, err := u.retriever.AddOrMerge(exp); err != nil {
 		return fmt.Errorf("failed to add experience: %w", err)
 	}
 
@@ -206,6 +208,18 @@ func (u *MemoryUpdater) AddAndEvolve(exp *ExperienceTuple, eval *Evaluation) err
 	return nil
 }
 
+// Add stores an experience that was not evaluated, keeping its neutral
+// fitness and leaving the experiences it retrieved as they are.
+func (u *MemoryUpdater) Add(exp *ExperienceTuple) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, _, err := u.retriever.AddOrMerge(exp); err != nil {
+		return fmt.Errorf("failed to add experience: %w", err)
+	}
+	return nil
+}
+
 // computeFitness calculates the fitness score for an experience.
 func (u *MemoryUpdater) computeFitness(exp *ExperienceTuple, eval *Evaluation) float64 {
 	baseFitness := eval.Score
@@ -249,14 +263,8 @@ func (u *MemoryUpdater) updateRetrievedFitness(newExp *ExperienceTuple, eval *Ev
 }
 
 // clamp restricts a value to a range.
-func clamp(value, min, max float64) float64 {
-	if value < min {
-		return min
-	}
-	if value > max {
-		return max
-	}
-	return value
+func clamp[T cmp.Ordered](value, lo, hi T) T {
+	return min(max(value, lo), hi)
 }
 
 // ============================================================================
@@ -423,12 +431,14 @@ type EmbeddingService interface {
 // ReMemController implements the Think-Act-Refine loop for Elite Agents.
 type ReMemController struct {
 	retriever        *SubLinearRetriever
+	retrieval        *SpeculativeRetriever
 	contextBuilder   *ContextConstructor
 	updater          *MemoryUpdater
 	evaluator        *OutcomeEvaluator
 	impasseDetector  *ImpasseDetector
 	consolidator     *MemoryConsolidator
 	embeddingService EmbeddingService
+	budget           *BudgetManager
 
 	// Agent registry for tier lookups
 	agentTiers map[string]int // agent_id -> tier_id
@@ -458,6 +468,15 @@ type ReMemConfig struct {
 
 	// EmbeddingDimension is the size of embedding vectors
 	EmbeddingDimension int
+
+	// Budget splits each request's deadline across the loop phases
+	Budget BudgetConfig
+
+	// Retrieval configures the race between retrieval sources
+	Retrieval SpeculativeConfig
+
+	// Dedup configures merging of near-duplicate experiences
+	Dedup DedupConfig
 }
 
 // DefaultReMemConfig returns the default configuration.
@@ -467,6 +486,9 @@ func DefaultReMemConfig() *ReMemConfig {
 		MinFitnessThreshold:    0.3,
 		BreakthroughThreshold:  0.9,
 		EmbeddingDimension:     384, // Common small embedding dimension
+		Budget:                 DefaultBudgetConfig(),
+		Retrieval:              DefaultSpeculativeConfig(),
+		Dedup:                  DefaultDedupConfig(),
 	}
 }
 
@@ -477,18 +499,23 @@ func NewReMemController(config *ReMemConfig, embeddingService EmbeddingService)
 	}
 
 	retriever := NewSubLinearRetriever(config.EmbeddingDimension)
+	if config.Dedup.Threshold > 0 {
+		retriever.EnableDedup(config.Dedup)
+	}
 	goalStack := NewGoalStack(DefaultGoalStackConfig()) // Initialize goal stack for impasse resolution
 	impasseDetector := NewImpasseDetector(DefaultImpasseDetectorConfig(), goalStack)
 	consolidator := NewMemoryConsolidator(DefaultConsolidatorConfig())
 
 	return &ReMemController{
 		retriever:         retriever,
+		retrieval:         NewSpeculativeRetriever(config.Retrieval, EpisodicSource(retriever), KeywordSource(retriever)),
 		contextBuilder:    NewContextConstructor(),
 		updater:           NewMemoryUpdater(retriever),
 		evaluator:         NewOutcomeEvaluator(),
 		impasseDetector:   impasseDetector,
 		consolidator:      consolidator,
 		embeddingService:  embeddingService,
+		budget:            NewBudgetManager(config.Budget),
 		agentTiers:        initializeAgentTiers(),
 		breakthroughs:     make([]*Breakthrough, 0),
 		generationCounter: make(map[string]int),
@@ -521,6 +548,12 @@ func initializeAgentTiers() map[string]int {
 }
 
 // ExecuteWithMemory runs the full ReMem loop for an agent invocation.
+//
+// The deadline of ctx is split across the phases (see BudgetManager).
+// Tie-breaking among retrieved experiences and reflection are optional and
+// skipped when the remaining budget is low; the other phases, the agent
+// included, run until the deadline of ctx itself. Without reflection the
+// experience is stored unevaluated.
 func (c *ReMemController) ExecuteWithMemory(
 	ctx context.Context,
 	agentID string,
@@ -528,16 +561,15 @@ func (c *ReMemController) ExecuteWithMemory(
 	executor AgentExecutor,
 ) (*models.CopilotResponse, error) {
 	tierID := c.getAgentTier(agentID)
+	budget := c.budget.Begin(ctx)
 
 	// =========================================================================
 	// Phase 1: RETRIEVE - Sub-linear experience retrieval
 	// =========================================================================
+	retrievalCtx, done, _ := budget.Stage(ctx, StageRetrieval)
 	queryCtx := c.buildQueryContext(agentID, tierID, request)
-	retrievalResult, err := c.retriever.Retrieve(queryCtx)
-	if err != nil {
-		// Non-fatal: continue without memory augmentation
-		retrievalResult = &RetrievalResult{Experiences: []*ExperienceTuple{}}
-	}
+	// Sources race; without a useful result this continues unaugmented
+	retrievalResult := c.retrieval.Retrieve(retrievalCtx, queryCtx)
 
 	// Detect retrieval-based impasses
 	goalID := fmt.Sprintf("goal-%s-%d", agentID, time.Now().UnixNano())
@@ -550,30 +582,27 @@ func (c *ReMemController) ExecuteWithMemory(
 				// Resolution successful - could modify retrieval strategy
 			}
 		}
-	} else if len(retrievalResult.Experiences) >= 2 {
-		// Check for tie
-		scores := make([]float64, len(retrievalResult.Experiences))
-		candidates := make([]string, len(retrievalResult.Experiences))
-		for i, exp := range retrievalResult.Experiences {
-			scores[i] = exp.FitnessScore
-			candidates[i] = exp.AgentID
-		}
-		// Tie detection is handled internally by DetectTie
-		if impasse := c.impasseDetector.DetectTie(goalID, candidates, scores); impasse != nil {
-			// Resolution will select among candidates
-			c.impasseDetector.Resolve(impasse.ID)
+	}
+	done()
+
+	if len(retrievalResult.Experiences) >= 2 {
+		if _, done, ok := budget.Stage(ctx, StageReRank); ok {
+			c.resolveRetrievalTie(goalID, retrievalResult.Experiences)
+			done()
 		}
 	}
 
+	// =========================================================================
+	// Phase 2: THINK - Build augmented context with experiences
+	// =========================================================================
+	_, done, _ = budget.Stage(ctx, StagePlanning)
+
 	// Get tier-shared experiences
 	tierExperiences := c.getTierExperiences(agentID, tierID, queryCtx)
 
 	// Get collective breakthroughs
 	breakthroughs := c.getApplicableBreakthroughs(tierID)
 
-	// =========================================================================
-	// Phase 2: THINK - Build augmented context with experiences
-	// =========================================================================
 	augmentedCtx := c.contextBuilder.Build(
 		request,
 		retrievalResult.Experiences,
@@ -581,11 +610,14 @@ func (c *ReMemController) ExecuteWithMemory(
 		breakthroughs,
 	)
 	augmentedCtx.RetrievalResult = retrievalResult
+	done()
 
 	// =========================================================================
 	// Phase 3: ACT - Execute agent with memory-augmented context
 	// =========================================================================
-	response, trace, err := executor.Execute(ctx, augmentedCtx)
+	execCtx, done, _ := budget.Stage(ctx, StageLLM)
+	response, trace, err := executor.Execute(execCtx, augmentedCtx)
+	done()
 	if err != nil {
 		// Detect execution failure impasse
 		goalID := fmt.Sprintf("goal-%s-%d", agentID, time.Now().UnixNano())
@@ -602,6 +634,21 @@ func (c *ReMemController) ExecuteWithMemory(
 		return nil, fmt.Errorf("agent execution failed: %w", err)
 	}
 
+	newExperience := c.createExperience(agentID, tierID, request, response, trace, retrievalResult)
+
+	// Add to consolidation buffer for offline processing
+	c.consolidator.AddToBuffer(newExperience)
+
+	// Without budget for reflection the experience is stored as it is
+	_, done, ok := budget.Stage(ctx, StageReflection)
+	if !ok {
+		if err := c.updater.Add(newExperience); err != nil {
+			log.Printf("Could not store experience %s: %v", newExperience.ID, err)
+		}
+		return response, nil
+	}
+	defer done()
+
 	// =========================================================================
 	// Phase 4: REFLECT - Evaluate outcome
 	// =========================================================================
@@ -610,11 +657,6 @@ func (c *ReMemController) ExecuteWithMemory(
 	// =========================================================================
 	// Phase 5: EVOLVE - Update memory based on outcome
 	// =========================================================================
-	newExperience := c.createExperience(agentID, tierID, request, response, trace, retrievalResult)
-
-	// Add to consolidation buffer for offline processing
-	c.consolidator.AddToBuffer(newExperience)
-
 	// Store in primary memory
 	if err := c.updater.AddAndEvolve(newExperience, evaluation); err != nil {
 		// Log but don't fail the request
@@ -629,6 +671,21 @@ func (c *ReMemController) ExecuteWithMemory(
 	return response, nil
 }
 
+// resolveRetrievalTie breaks ties among equally fit retrieved experiences.
+func (c *ReMemController) resolveRetrievalTie(goalID string, experiences []*ExperienceTuple) {
+	scores := make([]float64, len(experiences))
+	candidates := make([]string, len(experiences))
+	for i, exp := range experiences {
+		scores[i] = exp.FitnessScore
+		candidates[i] = exp.AgentID
+	}
+	// Tie detection is handled internally by DetectTie
+	if impasse := c.impasseDetector.DetectTie(goalID, candidates, scores); impasse != nil {
+		// Resolution will select among candidates
+		c.impasseDetector.Resolve(impasse.ID)
+	}
+}
+
 // buildQueryContext creates a query context for retrieval.
 func (c *ReMemController) buildQueryContext(agentID string, tierID int, request *models.CopilotRequest) *QueryContext {
 	input := ""
@@ -833,6 +890,23 @@ func (c *ReMemController) GetConsolidationStats() *ConsolidationStats {
 	return c.consolidator.GetStats()
 }
 
+// SetSemanticNetwork adds the knowledge graph as a retrieval source. Call
+// it before serving requests; it resets the retrieval statistics.
+func (c *ReMemController) SetSemanticNetwork(sn *SemanticNetwork) {
+	c.retrieval = NewSpeculativeRetriever(c.config.Retrieval,
+		EpisodicSource(c.retriever), KeywordSource(c.retriever), SemanticSource(sn, c.retriever))
+}
+
+// GetRetrievalStats returns speculative retrieval statistics.
+func (c *ReMemController) GetRetrievalStats() SpeculativeStats {
+	return c.retrieval.GetStats()
+}
+
+// GetBudgetStats returns deadline budgeting statistics.
+func (c *ReMemController) GetBudgetStats() BudgetStats {
+	return c.budget.GetStats()
+}
+
 // GetConsolidatedMemories returns all consolidated memories/schemas.
 func (c *ReMemController) GetConsolidatedMemories() map[string]*ConsolidatedMemory {
 	return c.consolidator.GetConsolidated()
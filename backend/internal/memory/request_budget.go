@@ -0,0 +1,272 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements per-request deadline budgeting across pipeline stages.
+//
+// A request's deadline is split across its stages by weight. Each stage is
+// allotted its share of the time still remaining when it starts, so time a
+// fast stage leaves unused rolls forward to later ones, and a slow stage
+// eats into the rest. Optional stages (re-ranking, reflection) are skipped
+// when their allotment drops below what they need, so a late request
+// degrades instead of timing out in the LLM call. Allotments only decide
+// what is skipped: required stages run until the request's own deadline,
+// however much of it they were allotted.
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BudgetStage names a pipeline stage that receives a share of the deadline.
+type BudgetStage string
+
+const (
+	StageRetrieval  BudgetStage = "retrieval"  // Experience retrieval
+	StageReRank     BudgetStage = "rerank"     // Tie-breaking among retrieved experiences
+	StagePlanning   BudgetStage = "planning"   // Building the augmented context
+	StageLLM        BudgetStage = "llm"        // Agent execution
+	StageReflection BudgetStage = "reflection" // Outcome evaluation and memory update
+)
+
+// StageBudget configures one stage's share of the deadline.
+type StageBudget struct {
+	Stage BudgetStage
+	// Weight is the stage's share relative to the stages after it
+	Weight float64
+	// Optional stages are skipped when their allotment is below MinBudget
+	Optional  bool
+	MinBudget time.Duration
+}
+
+// BudgetConfig holds configuration for deadline budgeting.
+type BudgetConfig struct {
+	// Stages lists the pipeline stages in execution order
+	Stages []StageBudget
+	// DefaultDeadline sizes the allotments of requests whose context has
+	// no deadline; it doesn't bound them
+	DefaultDeadline time.Duration
+	// Reserve is held back from every allotment for writing the response
+	Reserve time.Duration
+}
+
+// DefaultBudgetConfig returns the default budget configuration.
+func DefaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		Stages: []StageBudget{
+			{Stage: StageRetrieval, Weight: 0.15},
+			{Stage: StageReRank, Weight: 0.05, Optional: true, MinBudget: 20 * time.Millisecond},
+			{Stage: StagePlanning, Weight: 0.10},
+			{Stage: StageLLM, Weight: 0.60},
+			{Stage: StageReflection, Weight: 0.10, Optional: true, MinBudget: 50 * time.Millisecond},
+		},
+		DefaultDeadline: 30 * time.Second,
+		Reserve:         250 * time.Millisecond,
+	}
+}
+
+// StageUsage records how a stage spent its allotment.
+type StageUsage struct {
+	Stage    BudgetStage   `json:"stage"`
+	Allotted time.Duration `json:"allotted"`
+	Used     time.Duration `json:"used"`
+	Skipped  bool          `json:"skipped"`
+	// Exceeded is set when the stage ran past its allotment
+	Exceeded bool `json:"exceeded"`
+}
+
+// BudgetStats tracks budgeting across requests.
+type BudgetStats struct {
+	Requests int64 `json:"requests"`
+	// NoDeadline counts requests that fell back to DefaultDeadline
+	NoDeadline int64                 `json:"no_deadline"`
+	Skipped    map[BudgetStage]int64 `json:"skipped"`
+	Exceeded   map[BudgetStage]int64 `json:"exceeded"`
+}
+
+// BudgetManager splits request deadlines across pipeline stages.
+type BudgetManager struct {
+	config BudgetConfig
+	// now is the clock, replaceable in tests
+	now func() time.Time
+
+	mu    sync.Mutex
+	stats BudgetStats
+}
+
+// NewBudgetManager creates a budget manager. Zero fields take defaults.
+func NewBudgetManager(config BudgetConfig) *BudgetManager {
+	defaults := DefaultBudgetConfig()
+	if len(config.Stages) == 0 {
+		config.Stages = defaults.Stages
+	}
+	if config.DefaultDeadline <= 0 {
+		config.DefaultDeadline = defaults.DefaultDeadline
+	}
+	return &BudgetManager{
+		config: config,
+		now:    time.Now,
+		stats: BudgetStats{
+			Skipped:  make(map[BudgetStage]int64),
+			Exceeded: make(map[BudgetStage]int64),
+		},
+	}
+}
+
+// Begin starts budgeting a request against the deadline of ctx.
+func (m *BudgetManager) Begin(ctx context.Context) *RequestBudget {
+	deadline, ok := ctx.Deadline()
+
+	m.mu.Lock()
+	m.stats.Requests++
+	if !ok {
+		m.stats.NoDeadline++
+		deadline = m.now().Add(m.config.DefaultDeadline)
+	}
+	m.mu.Unlock()
+
+	return &RequestBudget{manager: m, deadline: deadline}
+}
+
+// GetStats returns a copy of the budgeting statistics.
+func (m *BudgetManager) GetStats() BudgetStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Skipped = make(map[BudgetStage]int64, len(m.stats.Skipped))
+	for stage, n := range m.stats.Skipped {
+		stats.Skipped[stage] = n
+	}
+	stats.Exceeded = make(map[BudgetStage]int64, len(m.stats.Exceeded))
+	for stage, n := range m.stats.Exceeded {
+		stats.Exceeded[stage] = n
+	}
+	return stats
+}
+
+// ============================================================================
+// Request Budget
+// ============================================================================
+
+// RequestBudget is the deadline budget of one request.
+type RequestBudget struct {
+	manager  *BudgetManager
+	deadline time.Time
+
+	mu    sync.Mutex
+	usage []StageUsage
+}
+
+// Deadline returns the request deadline the budget splits.
+func (b *RequestBudget) Deadline() time.Time {
+	return b.deadline
+}
+
+// Remaining returns the time left before the deadline, less the reserve.
+func (b *RequestBudget) Remaining() time.Duration {
+	remaining := b.deadline.Sub(b.manager.now()) - b.manager.config.Reserve
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Allotment returns the time stage would be given if it started now: its
+// weight's share of the remaining time among it and the stages after it.
+// Stages not in the configuration get all the remaining time.
+func (b *RequestBudget) Allotment(stage BudgetStage) time.Duration {
+	remaining := b.Remaining()
+	stages := b.manager.config.Stages
+	for i, s := range stages {
+		if s.Stage != stage {
+			continue
+		}
+		total := 0.0
+		for _, later := range stages[i:] {
+			total += later.Weight
+		}
+		if total <= 0 {
+			return remaining
+		}
+		return time.Duration(float64(remaining) * s.Weight / total)
+	}
+	return remaining
+}
+
+// Stage starts a stage. It returns the context the stage runs with and a
+// func to call when the stage finishes. Optional stages are bounded by
+// their allotment, and for one whose allotment is too small Stage returns
+// ok false: the caller should skip the stage, though the returned context
+// and func are still usable. Required stages run with ctx itself; their
+// allotment only records whether they overran it.
+func (b *RequestBudget) Stage(ctx context.Context, stage BudgetStage) (stageCtx context.Context, done func(), ok bool) {
+	allotted := b.Allotment(stage)
+	config, found := b.stageConfig(stage)
+	optional := found && config.Optional
+	if optional && allotted < config.MinBudget {
+		b.record(StageUsage{Stage: stage, Allotted: allotted, Skipped: true})
+		return ctx, func() {}, false
+	}
+
+	start := b.manager.now()
+	stageCtx, cancel := ctx, context.CancelFunc(func() {})
+	if optional {
+		stageCtx, cancel = context.WithDeadline(ctx, start.Add(allotted))
+	}
+	var once sync.Once
+	done = func() {
+		once.Do(func() {
+			cancel()
+			used := b.manager.now().Sub(start)
+			b.record(StageUsage{Stage: stage, Allotted: allotted, Used: used, Exceeded: used > allotted})
+		})
+	}
+	return stageCtx, done, true
+}
+
+// Report returns the usage of the stages run or skipped so far.
+func (b *RequestBudget) Report() []StageUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]StageUsage(nil), b.usage...)
+}
+
+// Skipped reports whether stage was skipped for lack of budget.
+func (b *RequestBudget) Skipped(stage BudgetStage) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, u := range b.usage {
+		if u.Stage == stage && u.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// stageConfig looks up the configuration of stage.
+func (b *RequestBudget) stageConfig(stage BudgetStage) (StageBudget, bool) {
+	for _, s := range b.manager.config.Stages {
+		if s.Stage == stage {
+			return s, true
+		}
+	}
+	return StageBudget{}, false
+}
+
+// record appends a stage's usage and updates the manager's statistics.
+func (b *RequestBudget) record(usage StageUsage) {
+	b.mu.Lock()
+	b.usage = append(b.usage, usage)
+	b.mu.Unlock()
+
+	m := b.manager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if usage.Skipped {
+		m.stats.Skipped[usage.Stage]++
+	}
+	if usage.Exceeded {
+		m.stats.Exceeded[usage.Stage]++
+	}
+}
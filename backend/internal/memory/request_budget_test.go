@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ============================================================================
+// Budget Manager Tests
+// ============================================================================
+
+// testBudget starts a budget with a fake clock and the given time to deadline.
+func testBudget(t *testing.T, config BudgetConfig, timeout time.Duration) (*BudgetManager, *RequestBudget, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewBudgetManager(config)
+	m.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(timeout))
+	t.Cleanup(cancel)
+	return m, m.Begin(ctx), &now
+}
+
+func testBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		Stages: []StageBudget{
+			{Stage: StageRetrieval, Weight: 1},
+			{Stage: StageReRank, Weight: 1, Optional: true, MinBudget: 50 * time.Millisecond},
+			{Stage: StageLLM, Weight: 2},
+		},
+		DefaultDeadline: time.Second,
+	}
+}
+
+func TestRequestBudget_Allotment(t *testing.T) {
+	_, budget, now := testBudget(t, testBudgetConfig(), 400*time.Millisecond)
+
+	if got := budget.Allotment(StageRetrieval); got != 100*time.Millisecond {
+		t.Errorf("Expected retrieval allotted 100ms, got %v", got)
+	}
+
+	// Retrieval finishes early and the unused time rolls forward
+	_, done, _ := budget.Stage(context.Background(), StageRetrieval)
+	*now = now.Add(40 * time.Millisecond)
+	done()
+	if got := budget.Allotment(StageReRank); got != 120*time.Millisecond {
+		t.Errorf("Expected rerank allotted 120ms, got %v", got)
+	}
+	if got := budget.Allotment(StageLLM); got != 360*time.Millisecond {
+		t.Errorf("Expected llm allotted all 360ms remaining, got %v", got)
+	}
+	if got := budget.Allotment("unknown"); got != 360*time.Millisecond {
+		t.Errorf("Expected unknown stage allotted all remaining, got %v", got)
+	}
+}
+
+func TestRequestBudget_StageDeadline(t *testing.T) {
+	_, budget, now := testBudget(t, testBudgetConfig(), 400*time.Millisecond)
+	requestCtx, cancel := context.WithDeadline(context.Background(), now.Add(400*time.Millisecond))
+	defer cancel()
+
+	// Required stages run until the request's deadline, not their allotment
+	ctx, done, ok := budget.Stage(requestCtx, StageRetrieval)
+	if !ok {
+		t.Fatal("Expected required stage to run")
+	}
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(now.Add(400 * time.Millisecond)) {
+		t.Errorf("Expected required stage bounded by the request deadline, got %v", deadline)
+	}
+	done()
+
+	ctx, done, ok = budget.Stage(requestCtx, StageReRank)
+	defer done()
+	if !ok {
+		t.Fatal("Expected optional stage to run with 133ms allotted")
+	}
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(now.Add(133333333 * time.Nanosecond)) {
+		t.Errorf("Expected optional stage deadline at its allotment, got %v", deadline)
+	}
+}
+
+func TestRequestBudget_SkipsOptionalStages(t *testing.T) {
+	m, budget, now := testBudget(t, testBudgetConfig(), 400*time.Millisecond)
+
+	// Retrieval overruns, leaving too little for re-ranking
+	_, done, _ := budget.Stage(context.Background(), StageRetrieval)
+	*now = now.Add(300 * time.Millisecond)
+	done()
+
+	if _, _, ok := budget.Stage(context.Background(), StageReRank); ok {
+		t.Error("Expected rerank skipped with 33ms allotted")
+	}
+	if _, done, ok := budget.Stage(context.Background(), StageLLM); !ok {
+		t.Error("Expected required stage to run however little time is left")
+	} else {
+		done()
+	}
+
+	if !budget.Skipped(StageReRank) || budget.Skipped(StageLLM) {
+		t.Errorf("Expected only rerank skipped, got %+v", budget.Report())
+	}
+	report := budget.Report()
+	if len(report) != 3 || !report[0].Exceeded || report[0].Used != 300*time.Millisecond {
+		t.Errorf("Expected retrieval overrun recorded, got %+v", report)
+	}
+
+	stats := m.GetStats()
+	if stats.Skipped[StageReRank] != 1 || stats.Exceeded[StageRetrieval] != 1 {
+		t.Errorf("Expected one skip and one overrun, got %+v", stats)
+	}
+}
+
+func TestBudgetManager_DefaultDeadline(t *testing.T) {
+	m := NewBudgetManager(BudgetConfig{})
+	budget := m.Begin(context.Background())
+
+	if remaining := budget.Remaining(); remaining > 30*time.Second || remaining < 29*time.Second {
+		t.Errorf("Expected about 30s minus reserve remaining, got %v", remaining)
+	}
+	if stats := m.GetStats(); stats.Requests != 1 || stats.NoDeadline != 1 {
+		t.Errorf("Expected one request without deadline, got %+v", stats)
+	}
+	ctx, done, _ := budget.Stage(context.Background(), StageLLM)
+	defer done()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected the default deadline not to bound required stages")
+	}
+}
+
+// ============================================================================
+// ReMem Integration Tests
+// ============================================================================
+
+// deadlineExecutor runs until its context expires and records the deadline.
+type deadlineExecutor struct {
+	deadline time.Time
+	block    bool
+}
+
+func (e *deadlineExecutor) Execute(ctx context.Context, augmentedCtx *AugmentedContext) (*models.CopilotResponse, *ExecutionTrace, error) {
+	e.deadline, _ = ctx.Deadline()
+	if e.block {
+		<-ctx.Done()
+	}
+	return &models.CopilotResponse{
+		Choices: []models.Choice{{Message: models.Message{Role: "assistant", Content: "done"}}},
+	}, &ExecutionTrace{Strategy: "direct"}, nil
+}
+
+func TestReMemController_ExecuteWithBudget(t *testing.T) {
+	config := DefaultReMemConfig()
+	config.Budget.Reserve = 0
+	controller := NewReMemController(config, nil)
+	request := &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "sort a list"}}}
+
+	// The agent runs until the request deadline, leaving no time for reflection
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	requestDeadline, _ := ctx.Deadline()
+	executor := &deadlineExecutor{block: true}
+	if _, err := controller.ExecuteWithMemory(ctx, "APEX", request, executor); err != nil {
+		t.Fatalf("ExecuteWithMemory failed: %v", err)
+	}
+	if !executor.deadline.Equal(requestDeadline) {
+		t.Errorf("Expected agent deadline at request deadline %v, got %v", requestDeadline, executor.deadline)
+	}
+	if controller.GetBudgetStats().Skipped[StageReflection] != 1 {
+		t.Errorf("Expected reflection skipped, got %+v", controller.GetBudgetStats())
+	}
+	if controller.GetStats().TotalExperiences != 1 {
+		t.Error("Expected the experience stored without reflection")
+	}
+
+	// With time to spare every stage runs, and without a deadline the
+	// agent isn't given one
+	executor = &deadlineExecutor{}
+	if _, err := controller.ExecuteWithMemory(context.Background(), "APEX", request, executor); err != nil {
+		t.Fatalf("ExecuteWithMemory failed: %v", err)
+	}
+	if !executor.deadline.IsZero() {
+		t.Errorf("Expected no agent deadline without a request deadline, got %v", executor.deadline)
+	}
+	if controller.GetBudgetStats().Skipped[StageReflection] != 1 {
+		t.Errorf("Expected reflection to run, got %+v", controller.GetBudgetStats())
+	}
+	if controller.GetStats().TotalExperiences != 2 {
+		t.Errorf("Expected experience stored after reflection, got %d", controller.GetStats().TotalExperiences)
+	}
+}
@@ -0,0 +1,354 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the offline evaluation of routing changes by replay.
+//
+// Before a routing model is promoted, the queries the hybrid router
+// recorded are routed again under the current configuration and the
+// candidate, on private copies of the attention weights and blend so live
+// routing is untouched. For each query the two chosen agents are compared,
+// and each choice's chance of success is predicted from the outcomes
+// recorded as feedback: the agent's smoothed success rate in the query's
+// attention categories, or overall when it has none there. The report
+// says how often the candidate routes differently and whether it is
+// predicted to do better.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// DefaultReplayLimit is the number of latest recorded queries replayed by
+// default.
+const DefaultReplayLimit = 1000
+
+// maxReplayChanges bounds the differently routed queries a report lists.
+const maxReplayChanges = 50
+
+// ReplayResult is how one configuration routed the replayed queries.
+type ReplayResult struct {
+	Weights RoutingWeights `json:"weights"`
+	// PredictedSuccess is the mean predicted success of the agents chosen
+	PredictedSuccess float64 `json:"predicted_success"`
+	// Agents counts the queries routed to each agent
+	Agents map[string]int `json:"agents"`
+}
+
+// ReplayChange is a query the candidate routes to a different agent.
+type ReplayChange struct {
+	Query            string  `json:"query"`
+	Current          string  `json:"current"`
+	Candidate        string  `json:"candidate"`
+	CurrentSuccess   float64 `json:"current_success"`
+	CandidateSuccess float64 `json:"candidate_success"`
+}
+
+// RoutingReplayReport compares the current routing configuration with a
+// candidate over recorded queries.
+type RoutingReplayReport struct {
+	Queries int `json:"queries"`
+	// Outcomes is the number of recorded outcomes success is predicted
+	// from
+	Outcomes  int          `json:"outcomes"`
+	Current   ReplayResult `json:"current"`
+	Candidate ReplayResult `json:"candidate"`
+	// Changed is the number of queries routed to a different agent, and
+	// Agreement the share routed to the same one
+	Changed   int     `json:"changed"`
+	Agreement float64 `json:"agreement"`
+	// Improvement is the candidate's predicted success less the current's
+	Improvement float64 `json:"improvement"`
+	// Regressions is the number of changed queries predicted to do worse
+	Regressions int `json:"regressions"`
+	// Changes are the changed queries, largest predicted difference first,
+	// up to 50
+	Changes []ReplayChange `json:"changes"`
+}
+
+// RoutingReplayer replays recorded queries against routing configurations.
+type RoutingReplayer struct {
+	log    *TrainingLog
+	router *HybridRouter
+
+	// registry resolves candidates given as model versions; nil accepts
+	// only model artifacts
+	registry *ModelRegistry
+}
+
+// NewRoutingReplayer creates a replayer of the queries in a training log,
+// comparing candidates with the router's current configuration.
+func NewRoutingReplayer(log *TrainingLog, router *HybridRouter) *RoutingReplayer {
+	return &RoutingReplayer{log: log, router: router}
+}
+
+// SetRegistry sets the model registry candidate versions are read from. It
+// must be set before the replayer is used.
+func (p *RoutingReplayer) SetRegistry(registry *ModelRegistry) {
+	p.registry = registry
+}
+
+// Candidate builds a routing model from registry versions, by model name.
+// Attention weights and routing priors can be combined; calibration
+// models do not route.
+func (p *RoutingReplayer) Candidate(versions map[string]int) (*RoutingModel, error) {
+	if p.registry == nil {
+		return nil, fmt.Errorf("%w: no model registry", ErrInvalidRoutingModel)
+	}
+	candidate := &RoutingModel{Name: "registry"}
+	for name, v := range versions {
+		version, err := p.registry.Version(name, v)
+		if err != nil {
+			return nil, err
+		}
+		switch version.Kind {
+		case ModelAttentionWeights:
+			candidate.Attention = version.Parameters.Attention
+		case ModelRoutingPriors:
+			candidate.Blend = version.Parameters.Routing
+		default:
+			return nil, fmt.Errorf("%w: %s holds %s, which does not route", ErrInvalidRoutingModel, name, version.Kind)
+		}
+	}
+	return candidate, nil
+}
+
+// Replay routes the latest limit queries recorded within a window under
+// the current configuration and a candidate, or DefaultReplayLimit if
+// limit is not positive. The candidate is validated as if imported; parts
+// it omits are the current ones.
+func (p *RoutingReplayer) Replay(ctx context.Context, candidate *RoutingModel, window ExportWindow, limit int) (*RoutingReplayReport, error) {
+	if err := candidate.validate(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultReplayLimit
+	}
+	decisions := p.log.Decisions(window)
+	if len(decisions) > limit {
+		decisions = decisions[len(decisions)-limit:]
+	}
+
+	// Both configurations route on copies, so neither feedback arriving
+	// meanwhile nor the candidate changes live routing
+	liveWeights := p.router.attention.Weights()
+	current := newAttentionCopy(liveWeights, nil)
+	next := newAttentionCopy(liveWeights, candidate.Attention)
+	blend := NewHybridRouter(next, p.router.config)
+	blend.SetWeights(p.router.Weights())
+	if candidate.Blend != nil {
+		blend.SetWeights(*candidate.Blend)
+	}
+	currentWeights, nextWeights := p.router.Weights(), blend.Weights()
+	personas := p.router.personas.Load()
+	if personas == nil {
+		currentWeights, nextWeights = RoutingWeights{Keyword: 1}, RoutingWeights{Keyword: 1}
+	}
+
+	outcomes := p.log.Outcomes(ExportWindow{})
+	predictor := newSuccessPredictor(current, outcomes)
+	report := &RoutingReplayReport{
+		Outcomes:  len(outcomes),
+		Current:   ReplayResult{Weights: currentWeights, Agents: make(map[string]int)},
+		Candidate: ReplayResult{Weights: nextWeights, Agents: make(map[string]int)},
+		Changes:   make([]ReplayChange, 0),
+	}
+	for _, decision := range decisions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var similarity map[string]float64
+		if personas != nil {
+			var err error
+			similarity, err = personas.Similarities(ctx, decision.Query)
+			if err != nil {
+				return nil, err
+			}
+			rescale(similarity)
+		}
+		was := topAgent(rankRoutes(keywordSignal(current, decision.Query), similarity, currentWeights, 1))
+		now := topAgent(rankRoutes(keywordSignal(next, decision.Query), similarity, nextWeights, 1))
+		if was == "" && now == "" {
+			continue
+		}
+
+		categories := current.MatchCategories(decision.Query)
+		wasSuccess, nowSuccess := predictor.predict(categories, was), predictor.predict(categories, now)
+		report.Queries++
+		report.Current.PredictedSuccess += wasSuccess
+		report.Candidate.PredictedSuccess += nowSuccess
+		if was != "" {
+			report.Current.Agents[was]++
+		}
+		if now != "" {
+			report.Candidate.Agents[now]++
+		}
+		if was == now {
+			continue
+		}
+		report.Changed++
+		if nowSuccess < wasSuccess {
+			report.Regressions++
+		}
+		report.Changes = append(report.Changes, ReplayChange{
+			Query:            decision.Query,
+			Current:          was,
+			Candidate:        now,
+			CurrentSuccess:   wasSuccess,
+			CandidateSuccess: nowSuccess,
+		})
+	}
+
+	if report.Queries > 0 {
+		n := float64(report.Queries)
+		report.Current.PredictedSuccess /= n
+		report.Candidate.PredictedSuccess /= n
+		report.Agreement = float64(report.Queries-report.Changed) / n
+		report.Improvement = report.Candidate.PredictedSuccess - report.Current.PredictedSuccess
+	}
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		return math.Abs(report.Changes[i].CandidateSuccess-report.Changes[i].CurrentSuccess) >
+			math.Abs(report.Changes[j].CandidateSuccess-report.Changes[j].CurrentSuccess)
+	})
+	if len(report.Changes) > maxReplayChanges {
+		report.Changes = report.Changes[:maxReplayChanges]
+	}
+	return report, nil
+}
+
+// newAttentionCopy creates an attention index with weights, then
+// overrides replacing some of them.
+func newAttentionCopy(weights, overrides map[string]map[string]float64) *CollaborativeAttentionIndex {
+	idx := NewCollaborativeAttentionIndex()
+	idx.SetWeights(weights)
+	if len(overrides) > 0 {
+		idx.SetWeights(overrides)
+	}
+	return idx
+}
+
+// topAgent returns the agent ranked first, or "" if none was.
+func topAgent(routes []HybridRoute) string {
+	if len(routes) == 0 {
+		return ""
+	}
+	return routes[0].AgentID
+}
+
+// successCount is an agent's recorded outcomes.
+type successCount struct {
+	successes int
+	trials    int
+}
+
+// successPredictor predicts whether an agent will succeed with a query
+// from recorded outcomes.
+type successPredictor struct {
+	byCategory map[string]map[string]*successCount
+	byAgent    map[string]*successCount
+}
+
+// newSuccessPredictor counts outcomes by the attention categories of their
+// queries and agent.
+func newSuccessPredictor(attention *CollaborativeAttentionIndex, outcomes []RoutingOutcome) *successPredictor {
+	p := &successPredictor{
+		byCategory: make(map[string]map[string]*successCount),
+		byAgent:    make(map[string]*successCount),
+	}
+	count := func(counts map[string]*successCount, agent string, success bool) {
+		c, ok := counts[agent]
+		if !ok {
+			c = &successCount{}
+			counts[agent] = c
+		}
+		c.trials++
+		if success {
+			c.successes++
+		}
+	}
+	for _, o := range outcomes {
+		count(p.byAgent, o.Agent, o.Success)
+		for _, category := range attention.MatchCategories(o.Query) {
+			if p.byCategory[category] == nil {
+				p.byCategory[category] = make(map[string]*successCount)
+			}
+			count(p.byCategory[category], o.Agent, o.Success)
+		}
+	}
+	return p
+}
+
+// predict returns the chance an agent succeeds with a query in categories:
+// its success rate there, or overall without outcomes there, smoothed
+// toward one half (Laplace's rule of succession). An empty agent never
+// succeeds.
+func (p *successPredictor) predict(categories []string, agent string) float64 {
+	if agent == "" {
+		return 0
+	}
+	var c successCount
+	for _, category := range categories {
+		if counted := p.byCategory[category][agent]; counted != nil {
+			c.successes += counted.successes
+			c.trials += counted.trials
+		}
+	}
+	if c.trials == 0 && p.byAgent[agent] != nil {
+		c = *p.byAgent[agent]
+	}
+	return float64(c.successes+1) / float64(c.trials+2)
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// RoutingReplayRequest is the body of POST /admin/memory/routing/replay.
+// Exactly one of Candidate and Versions is set.
+type RoutingReplayRequest struct {
+	// Candidate is a routing model artifact
+	Candidate *RoutingModel `json:"candidate,omitempty"`
+	// Versions are model registry versions, by model name
+	Versions map[string]int `json:"versions,omitempty"`
+	// From and To limit the replay to queries recorded in [From, To)
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+	// Limit replays only the latest queries; defaults to 1000
+	Limit int `json:"limit,omitempty"`
+}
+
+// ServeReplay handles POST /admin/memory/routing/replay - compares a
+// candidate routing configuration with the current one over recorded
+// queries.
+func (p *RoutingReplayer) ServeReplay(w http.ResponseWriter, r *http.Request) {
+	var req RoutingReplayRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRoutingModelBody)).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if (req.Candidate == nil) == (len(req.Versions) == 0) {
+		errdefs.WriteError(w, "Exactly one of candidate and versions is required", http.StatusBadRequest)
+		return
+	}
+
+	candidate := req.Candidate
+	if candidate == nil {
+		var err error
+		if candidate, err = p.Candidate(req.Versions); err != nil {
+			errdefs.WriteHTTP(w, err)
+			return
+		}
+	}
+	report, err := p.Replay(r.Context(), candidate, ExportWindow{From: req.From, To: req.To}, req.Limit)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newReplayFixture records three routed queries, two about performance,
+// and outcomes in which VELOCITY fails at performance and APEX succeeds.
+func newReplayFixture(t *testing.T) (*RoutingReplayer, *CollaborativeAttentionIndex) {
+	t.Helper()
+	attention := NewCollaborativeAttentionIndex()
+	router := NewHybridRouter(attention, DefaultHybridRouterConfig())
+	log := NewTrainingLog(0, nil)
+	router.OnRoute(log.RecordDecision)
+
+	for _, query := range []string{"optimize the cache", "optimize the cache", "design the system architecture"} {
+		if _, err := router.Route(context.Background(), query, 3); err != nil {
+			t.Fatalf("Route failed: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		log.RecordOutcomes([]FeedbackRecord{
+			{Query: "optimize the cache", Agent: "VELOCITY", Success: false},
+			{Query: "make it fast", Agent: "APEX", Success: true},
+		})
+	}
+	return NewRoutingReplayer(log, router), attention
+}
+
+func TestRoutingReplayer_Replay(t *testing.T) {
+	replayer, attention := newReplayFixture(t)
+	candidate := &RoutingModel{Name: "apex-first", Attention: map[string]map[string]float64{"performance": {"APEX": 1}}}
+
+	report, err := replayer.Replay(context.Background(), candidate, ExportWindow{}, 0)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if report.Queries != 3 || report.Outcomes != 6 || report.Changed != 2 || report.Regressions != 0 {
+		t.Fatalf("expected both performance queries rerouted, got %+v", report)
+	}
+	if report.Current.Agents["VELOCITY"] != 2 || report.Candidate.Agents["APEX"] != 2 || report.Candidate.Agents["ARCHITECT"] != 1 {
+		t.Errorf("unexpected agents %v -> %v", report.Current.Agents, report.Candidate.Agents)
+	}
+	// VELOCITY is predicted (0+1)/(3+2), APEX (3+1)/(3+2) and ARCHITECT,
+	// without outcomes, one half
+	approx := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+	if !approx(report.Current.PredictedSuccess, 0.3) || !approx(report.Candidate.PredictedSuccess, 0.7) ||
+		!approx(report.Improvement, 0.4) || !approx(report.Agreement, 1.0/3) {
+		t.Errorf("unexpected predictions %+v", report)
+	}
+	if len(report.Changes) != 2 || report.Changes[0].Current != "VELOCITY" || report.Changes[0].Candidate != "APEX" {
+		t.Errorf("unexpected changes %+v", report.Changes)
+	}
+
+	if attention.Weights()["performance"]["APEX"] >= 1 {
+		t.Error("expected replay to leave live attention weights alone")
+	}
+
+	limited, err := replayer.Replay(context.Background(), candidate, ExportWindow{}, 1)
+	if err != nil || limited.Queries != 1 || limited.Changed != 0 {
+		t.Errorf("expected only the latest query replayed, got %+v (%v)", limited, err)
+	}
+	if _, err := replayer.Replay(context.Background(), &RoutingModel{Name: "empty"}, ExportWindow{}, 0); !errors.Is(err, ErrInvalidRoutingModel) {
+		t.Errorf("expected ErrInvalidRoutingModel, got %v", err)
+	}
+}
+
+func TestRoutingReplayer_Versions(t *testing.T) {
+	replayer, attention := newReplayFixture(t)
+	if _, err := replayer.Candidate(map[string]int{"attention-weights": 1}); !errors.Is(err, ErrInvalidRoutingModel) {
+		t.Errorf("expected ErrInvalidRoutingModel without a registry, got %v", err)
+	}
+
+	registry := NewModelRegistry(nil)
+	if err := registry.BindAttention("attention-weights", attention); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.BindCalibration("calibration", NewConfidenceCalibrator(10, 5)); err != nil {
+		t.Fatal(err)
+	}
+	weights := attention.Weights()
+	weights["performance"] = map[string]float64{"APEX": 1}
+	if _, err := registry.Register("attention-weights", "APEX first", ModelParameters{Attention: weights}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Snapshot("calibration", ""); err != nil {
+		t.Fatal(err)
+	}
+	replayer.SetRegistry(registry)
+
+	candidate, err := replayer.Candidate(map[string]int{"attention-weights": 1})
+	if err != nil {
+		t.Fatalf("Candidate failed: %v", err)
+	}
+	report, err := replayer.Replay(context.Background(), candidate, ExportWindow{}, 0)
+	if err != nil || report.Changed != 2 {
+		t.Errorf("expected the registered version to reroute, got %+v (%v)", report, err)
+	}
+
+	if _, err := replayer.Candidate(map[string]int{"calibration": 1}); !errors.Is(err, ErrInvalidRoutingModel) {
+		t.Errorf("expected calibration rejected, got %v", err)
+	}
+	if _, err := replayer.Candidate(map[string]int{"attention-weights": 9}); !errors.Is(err, ErrModelVersionNotFound) {
+		t.Errorf("expected ErrModelVersionNotFound, got %v", err)
+	}
+}
+
+func TestRoutingReplayer_ServeReplay(t *testing.T) {
+	replayer, _ := newReplayFixture(t)
+
+	for body, want := range map[string]int{
+		`{"candidate":{"name":"blend","blend":{"keyword":1,"similarity":0}}}`: http.StatusOK,
+		`{}`: http.StatusBadRequest,
+		`{"candidate":{"name":"blend","blend":{"keyword":1}},"versions":{"routing-priors":1}}`: http.StatusBadRequest,
+		`{"versions":{"routing-priors":1}}`:                                                    http.StatusBadRequest,
+		`{"candidate":{"name":"negative","blend":{"keyword":-1}}}`:                             http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		replayer.ServeReplay(w, httptest.NewRequest(http.MethodPost, "/admin/memory/routing/replay", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", body, want, w.Code, w.Body.String())
+			continue
+		}
+		if want == http.StatusOK {
+			var report RoutingReplayReport
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || report.Queries != 3 || report.Changed != 0 {
+				t.Errorf("expected an unchanged keyword-only replay, got %s", w.Body.String())
+			}
+		}
+	}
+}
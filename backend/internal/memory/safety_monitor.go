@@ -87,6 +87,8 @@ type SafetyMonitor struct {
 	// State
 	running bool
 	stopCh  chan struct{}
+	// done is closed once the monitoring loop has returned
+	done chan struct{}
 }
 
 // SafetyMonitorConfig configures the safety monitor
@@ -143,7 +145,6 @@ func NewSafetyMonitor(config *SafetyMonitorConfig, guardrails *ConstitutionalGua
 			AlertsByType:      make(map[AlertType]int64),
 			SystemHealthScore: 1.0,
 		},
-		stopCh: make(chan struct{}),
 	}
 }
 
@@ -155,25 +156,32 @@ func (m *SafetyMonitor) Start(ctx context.Context) error {
 		return fmt.Errorf("safety monitor already running")
 	}
 	m.running = true
+	m.stopCh = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.monitoringLoop(ctx, m.stopCh, m.done)
 	m.mu.Unlock()
-
-	go m.monitoringLoop(ctx)
 	return nil
 }
 
-// Stop stops the monitoring
+// Stop stops the monitoring, returning once a cycle in progress has
+// finished
 func (m *SafetyMonitor) Stop() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if m.running {
-		close(m.stopCh)
-		m.running = false
+	if !m.running {
+		m.mu.Unlock()
+		return
 	}
+	close(m.stopCh)
+	m.running = false
+	done := m.done
+	m.mu.Unlock()
+	<-done
 }
 
-// monitoringLoop runs the continuous monitoring
-func (m *SafetyMonitor) monitoringLoop(ctx context.Context) {
+// monitoringLoop runs the continuous monitoring until stopCh is closed or
+// ctx is done, then closes done
+func (m *SafetyMonitor) monitoringLoop(ctx context.Context, stopCh, done chan struct{}) {
+	defer close(done)
 	ticker := time.NewTicker(m.config.MonitoringInterval)
 	defer ticker.Stop()
 
@@ -181,7 +189,7 @@ func (m *SafetyMonitor) monitoringLoop(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-m.stopCh:
+		case <-stopCh:
 			return
 		case <-ticker.C:
 			m.runMonitoringCycle(ctx)
@@ -712,13 +720,7 @@ func (c *CapabilityController) GetPendingApprovals() []string {
 	return result
 }
 
-// Helper to generate alert IDs
-var alertIDCounter int64
-var alertIDMu sync.Mutex
-
+// generateAlertID returns a unique alert ID.
 func generateAlertID() string {
-	alertIDMu.Lock()
-	defer alertIDMu.Unlock()
-	alertIDCounter++
-	return fmt.Sprintf("alert-%d-%d", time.Now().UnixNano(), alertIDCounter)
+	return NewID("alert")
 }
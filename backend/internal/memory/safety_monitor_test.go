@@ -66,8 +66,21 @@ func TestSafetyMonitor_StartStop(t *testing.T) {
 	})
 
 	t.Run("stop succeeds", func(t *testing.T) {
+		done := m.done
+		m.Stop()
+		select {
+		case <-done:
+		default:
+			t.Error("Expected the monitoring loop to have returned")
+		}
+	})
+
+	t.Run("restart succeeds", func(t *testing.T) {
+		if err := m.Start(ctx); err != nil {
+			t.Errorf("Start after Stop failed: %v", err)
+		}
+		m.Stop()
 		m.Stop()
-		// Should not panic
 	})
 }
 
@@ -0,0 +1,445 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the tuning of each agent's sampling parameters from
+// feedback.
+//
+// Agents that answer through a language model sample with a temperature
+// and a top-p of their own. The tuner counts each agent's outcomes in
+// windows: the share that succeeded is the window's quality, and feedback
+// can name what was wrong with an answer. Factual errors lower the
+// temperature, incoherent answers lower the top-p and repetitive ones
+// raise the temperature, one step at a time and never past the bounds. A
+// change the tuner made is judged by the next window: if quality fell by
+// more than the tolerance, the change is reverted. Every change is logged
+// with its reason and can be reverted by an administrator, who can also
+// set an agent's parameters outright.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidSampling is returned for sampling parameters outside the
+	// tuner's bounds
+	ErrInvalidSampling = errdefs.New(errdefs.ErrInvalidArgument, "invalid sampling parameters")
+	// ErrNoSamplingChange is returned for reverting an agent whose
+	// parameters were never changed
+	ErrNoSamplingChange = errdefs.New(errdefs.ErrConflict, "no sampling change to revert")
+)
+
+// Issues feedback can name about an answer.
+const (
+	// IssueFactualError is an answer that stated something false
+	IssueFactualError = "factual_error"
+	// IssueIncoherent is an answer that rambled or contradicted itself
+	IssueIncoherent = "incoherent"
+	// IssueRepetitive is an answer that repeated itself or earlier answers
+	IssueRepetitive = "repetitive"
+)
+
+// feedbackIssues are the issues feedback records may name.
+var feedbackIssues = []string{IssueFactualError, IssueIncoherent, IssueRepetitive}
+
+// Sources of sampling changes.
+const (
+	SamplingSourceTuner    = "tuner"
+	SamplingSourceAdmin    = "admin"
+	SamplingSourceRegistry = "registry"
+)
+
+// maxSamplingChanges bounds the changes kept for the admin API.
+const maxSamplingChanges = 100
+
+// SamplingParams are the parameters an agent samples with.
+type SamplingParams struct {
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p"`
+}
+
+// SamplingTunerConfig configures a sampling tuner.
+type SamplingTunerConfig struct {
+	// Default is what agents sample with until they are tuned
+	Default SamplingParams
+	// The bounds the tuner keeps the parameters within
+	MinTemperature float64
+	MaxTemperature float64
+	MinTopP        float64
+	MaxTopP        float64
+	// Step is how far one adjustment moves a parameter
+	Step float64
+	// Window is how many outcomes of an agent are judged together
+	Window int
+	// IssueShare is the share of a window's outcomes with one issue that
+	// adjusts the parameters for it
+	IssueShare float64
+	// Tolerance is how far quality may fall after a tuning change before
+	// the change is reverted
+	Tolerance float64
+	// Clock supplies the time (default: SystemClock)
+	Clock Clock
+}
+
+// DefaultSamplingTunerConfig returns the vendors' default sampling, tuned
+// in steps of 0.1 over windows of 20 outcomes.
+func DefaultSamplingTunerConfig() SamplingTunerConfig {
+	return SamplingTunerConfig{
+		Default:        SamplingParams{Temperature: 1, TopP: 1},
+		MinTemperature: 0.2,
+		MaxTemperature: 1.2,
+		MinTopP:        0.7,
+		MaxTopP:        1,
+		Step:           0.1,
+		Window:         20,
+		IssueShare:     0.2,
+		Tolerance:      0.1,
+	}
+}
+
+// SamplingChange is one change of an agent's sampling parameters.
+type SamplingChange struct {
+	Agent string         `json:"agent"`
+	From  SamplingParams `json:"from"`
+	To    SamplingParams `json:"to"`
+	// Source is tuner, admin or registry
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+	// Reverted is true for a change that undid an earlier one
+	Reverted bool      `json:"reverted,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// AgentSampling is an agent's sampling parameters and tuning state.
+type AgentSampling struct {
+	Agent string `json:"agent"`
+	SamplingParams
+	// Quality is the success rate of the agent's last full window; nil
+	// before one
+	Quality *float64 `json:"quality,omitempty"`
+	// Outcomes counts the outcomes in the current window
+	Outcomes int `json:"outcomes"`
+	// Changes is how many earlier changes can be reverted
+	Changes int `json:"changes"`
+}
+
+// samplingState is one agent's parameters and current window.
+type samplingState struct {
+	params SamplingParams
+	// history holds the parameters before each change, latest last
+	history []SamplingParams
+
+	outcomes  int
+	successes int
+	issues    map[string]int
+
+	// quality is the success rate of the last full window, if hasQuality
+	quality    float64
+	hasQuality bool
+	// tuned marks a tuner change the next window judges
+	tuned bool
+}
+
+// SamplingTuner tunes each agent's temperature and top-p from feedback.
+type SamplingTuner struct {
+	config SamplingTunerConfig
+	clock  Clock
+
+	mu      sync.RWMutex
+	agents  map[string]*samplingState
+	changes []SamplingChange
+}
+
+// NewSamplingTuner creates a tuner.
+func NewSamplingTuner(config SamplingTunerConfig) *SamplingTuner {
+	if config.Window < 1 {
+		config.Window = DefaultSamplingTunerConfig().Window
+	}
+	return &SamplingTuner{config: config, clock: clockOrSystem(config.Clock), agents: make(map[string]*samplingState)}
+}
+
+// Sampling returns the temperature and top-p an agent samples with.
+func (t *SamplingTuner) Sampling(agent string) (temperature, topP float64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	params := t.config.Default
+	if st, ok := t.agents[agent]; ok {
+		params = st.params
+	}
+	return params.Temperature, params.TopP
+}
+
+// state returns an agent's state, creating it. Callers hold mu.
+func (t *SamplingTuner) state(agent string) *samplingState {
+	st, ok := t.agents[agent]
+	if !ok {
+		st = &samplingState{params: t.config.Default, issues: make(map[string]int)}
+		t.agents[agent] = st
+	}
+	return st
+}
+
+// Observe counts applied feedback records, tuning each agent whose window
+// fills.
+func (t *SamplingTuner) Observe(records []FeedbackRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, record := range records {
+		st := t.state(record.Agent)
+		st.outcomes++
+		if record.Success {
+			st.successes++
+		}
+		if record.Issue != "" {
+			st.issues[record.Issue]++
+		}
+		if st.outcomes >= t.config.Window {
+			t.evaluate(record.Agent, st)
+		}
+	}
+}
+
+// evaluate judges an agent's full window and adjusts its parameters.
+// Callers hold mu.
+func (t *SamplingTuner) evaluate(agent string, st *samplingState) {
+	quality := float64(st.successes) / float64(st.outcomes)
+	share := func(issue string) float64 { return float64(st.issues[issue]) / float64(st.outcomes) }
+
+	next, reason := st.params, ""
+	switch {
+	case st.tuned && st.hasQuality && quality < st.quality-t.config.Tolerance && len(st.history) > 0:
+		t.revert(agent, st, SamplingSourceTuner, fmt.Sprintf("quality fell from %.2f to %.2f after tuning", st.quality, quality))
+	case share(IssueFactualError) >= t.config.IssueShare:
+		next.Temperature -= t.config.Step
+		reason = fmt.Sprintf("factual errors in %.0f%% of outcomes", 100*share(IssueFactualError))
+	case share(IssueIncoherent) >= t.config.IssueShare:
+		next.TopP -= t.config.Step
+		reason = fmt.Sprintf("incoherent answers in %.0f%% of outcomes", 100*share(IssueIncoherent))
+	case share(IssueRepetitive) >= t.config.IssueShare:
+		next.Temperature += t.config.Step
+		reason = fmt.Sprintf("repetitive answers in %.0f%% of outcomes", 100*share(IssueRepetitive))
+	}
+	st.tuned = false
+	if reason != "" {
+		if next = t.clamp(next); next != st.params {
+			t.change(agent, st, next, SamplingSourceTuner, fmt.Sprintf("%s, quality %.2f", reason, quality))
+			st.tuned = true
+		}
+	}
+
+	st.quality, st.hasQuality = quality, true
+	st.outcomes, st.successes = 0, 0
+	st.issues = make(map[string]int)
+}
+
+// clamp keeps parameters within the bounds, rounded to two decimals so
+// steps don't accumulate rounding errors.
+func (t *SamplingTuner) clamp(p SamplingParams) SamplingParams {
+	round := func(x, lo, hi float64) float64 {
+		return math.Round(math.Max(lo, math.Min(hi, x))*100) / 100
+	}
+	return SamplingParams{
+		Temperature: round(p.Temperature, t.config.MinTemperature, t.config.MaxTemperature),
+		TopP:        round(p.TopP, t.config.MinTopP, t.config.MaxTopP),
+	}
+}
+
+// validate checks parameters are within the bounds.
+func (t *SamplingTuner) validate(p SamplingParams) error {
+	if p.Temperature < t.config.MinTemperature || p.Temperature > t.config.MaxTemperature {
+		return fmt.Errorf("%w: temperature %v is not between %v and %v", ErrInvalidSampling, p.Temperature, t.config.MinTemperature, t.config.MaxTemperature)
+	}
+	if p.TopP < t.config.MinTopP || p.TopP > t.config.MaxTopP {
+		return fmt.Errorf("%w: top-p %v is not between %v and %v", ErrInvalidSampling, p.TopP, t.config.MinTopP, t.config.MaxTopP)
+	}
+	return nil
+}
+
+// change sets an agent's parameters, remembering the previous ones so the
+// change can be reverted. Callers hold mu.
+func (t *SamplingTuner) change(agent string, st *samplingState, to SamplingParams, source, reason string) {
+	st.history = append(st.history, st.params)
+	t.record(SamplingChange{Agent: agent, From: st.params, To: to, Source: source, Reason: reason})
+	st.params = to
+}
+
+// revert restores an agent's parameters before its last change. Callers
+// hold mu and check there is one.
+func (t *SamplingTuner) revert(agent string, st *samplingState, source, reason string) {
+	previous := st.history[len(st.history)-1]
+	st.history = st.history[:len(st.history)-1]
+	t.record(SamplingChange{Agent: agent, From: st.params, To: previous, Source: source, Reason: reason, Reverted: true})
+	st.params = previous
+}
+
+// record logs a change and keeps it for the admin API. Callers hold mu.
+func (t *SamplingTuner) record(c SamplingChange) {
+	c.Time = t.clock.Now()
+	log.Printf("Sampling of %s changed by %s from temperature %.2f, top-p %.2f to %.2f, %.2f: %s",
+		c.Agent, c.Source, c.From.Temperature, c.From.TopP, c.To.Temperature, c.To.TopP, c.Reason)
+	t.changes = append(t.changes, c)
+	if len(t.changes) > maxSamplingChanges {
+		t.changes = t.changes[len(t.changes)-maxSamplingChanges:]
+	}
+}
+
+// Set sets an agent's parameters, which must be within the bounds.
+func (t *SamplingTuner) Set(agent string, params SamplingParams, reason string) error {
+	if err := t.validate(params); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := t.state(agent)
+	if params != st.params {
+		t.change(agent, st, params, SamplingSourceAdmin, reason)
+	}
+	st.tuned = false
+	return nil
+}
+
+// Revert restores an agent's parameters before its last change.
+func (t *SamplingTuner) Revert(agent string) (SamplingParams, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st, ok := t.agents[agent]
+	if !ok || len(st.history) == 0 {
+		return SamplingParams{}, fmt.Errorf("%w: %s", ErrNoSamplingChange, agent)
+	}
+	t.revert(agent, st, SamplingSourceAdmin, "reverted by an administrator")
+	st.tuned = false
+	return st.params, nil
+}
+
+// Params returns the parameters of the agents that differ from the
+// default, by codename.
+func (t *SamplingTuner) Params() map[string]SamplingParams {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	params := make(map[string]SamplingParams)
+	for agent, st := range t.agents {
+		if st.params != t.config.Default {
+			params[agent] = st.params
+		}
+	}
+	return params
+}
+
+// SetParams replaces every agent's parameters: agents in params get
+// theirs, the others the default. Nothing changes if any are out of
+// bounds.
+func (t *SamplingTuner) SetParams(params map[string]SamplingParams) error {
+	for agent, p := range params {
+		if err := t.validate(p); err != nil {
+			return fmt.Errorf("%s: %w", agent, err)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for agent := range params {
+		t.state(agent)
+	}
+	for agent, st := range t.agents {
+		next, ok := params[agent]
+		if !ok {
+			next = t.config.Default
+		}
+		if next != st.params {
+			t.change(agent, st, next, SamplingSourceRegistry, "model version applied")
+		}
+		st.tuned = false
+	}
+	return nil
+}
+
+// Agents returns the sampling state of every agent with feedback or
+// changes, sorted by codename.
+func (t *SamplingTuner) Agents() []AgentSampling {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	agents := make([]AgentSampling, 0, len(t.agents))
+	for agent, st := range t.agents {
+		a := AgentSampling{Agent: agent, SamplingParams: st.params, Outcomes: st.outcomes, Changes: len(st.history)}
+		if st.hasQuality {
+			quality := st.quality
+			a.Quality = &quality
+		}
+		agents = append(agents, a)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Agent < agents[j].Agent })
+	return agents
+}
+
+// Changes returns the latest changes, newest first.
+func (t *SamplingTuner) Changes() []SamplingChange {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	changes := make([]SamplingChange, len(t.changes))
+	for i, c := range t.changes {
+		changes[len(changes)-1-i] = c
+	}
+	return changes
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// SamplingResponse is the body of GET /admin/memory/sampling.
+type SamplingResponse struct {
+	Default SamplingParams   `json:"default"`
+	Agents  []AgentSampling  `json:"agents"`
+	Changes []SamplingChange `json:"changes"`
+}
+
+// SamplingRequest is the body of PUT /admin/memory/sampling/{agent}.
+type SamplingRequest struct {
+	SamplingParams
+	Reason string `json:"reason,omitempty"`
+}
+
+// ServeSampling handles GET /admin/memory/sampling - lists each agent's
+// sampling parameters and the latest changes.
+func (t *SamplingTuner) ServeSampling(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, SamplingResponse{Default: t.config.Default, Agents: t.Agents(), Changes: t.Changes()})
+}
+
+// ServeSet handles PUT /admin/memory/sampling/{agent} - sets an agent's
+// sampling parameters.
+func (t *SamplingTuner) ServeSet(w http.ResponseWriter, r *http.Request) {
+	var req SamplingRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "set by an administrator"
+	}
+	agent := strings.ToUpper(r.PathValue("agent"))
+	if err := t.Set(agent, req.SamplingParams, req.Reason); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	temperature, topP := t.Sampling(agent)
+	errdefs.WriteJSON(w, http.StatusOK, SamplingParams{Temperature: temperature, TopP: topP})
+}
+
+// ServeRevert handles POST /admin/memory/sampling/{agent}/revert -
+// restores an agent's sampling parameters before its last change.
+func (t *SamplingTuner) ServeRevert(w http.ResponseWriter, r *http.Request) {
+	params, err := t.Revert(strings.ToUpper(r.PathValue("agent")))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, params)
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSamplingTuner creates a tuner judging windows of 10 outcomes.
+func newTestSamplingTuner() *SamplingTuner {
+	config := DefaultSamplingTunerConfig()
+	config.Window = 10
+	config.Clock = NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	return NewSamplingTuner(config)
+}
+
+// samplingWindow returns a window of outcomes for an agent: successes of
+// them succeed and the first issued name issue.
+func samplingWindow(agent string, successes, issued int, issue string) []FeedbackRecord {
+	records := make([]FeedbackRecord, 10)
+	for i := range records {
+		records[i] = FeedbackRecord{Agent: agent, Success: i < successes}
+		if i < issued {
+			records[i].Issue = issue
+		}
+	}
+	return records
+}
+
+func TestSamplingTuner_FactualErrorsLowerTemperature(t *testing.T) {
+	tuner := newTestSamplingTuner()
+	tuner.Observe(samplingWindow("APEX", 7, 3, IssueFactualError))
+	if temperature, topP := tuner.Sampling("APEX"); temperature != 0.9 || topP != 1 {
+		t.Errorf("expected the temperature lowered a step, got %v, %v", temperature, topP)
+	}
+	if temperature, _ := tuner.Sampling("CIPHER"); temperature != 1 {
+		t.Errorf("expected other agents at the default, got %v", temperature)
+	}
+
+	tuner.Observe(samplingWindow("APEX", 7, 3, IssueIncoherent))
+	if temperature, topP := tuner.Sampling("APEX"); temperature != 0.9 || topP != 0.9 {
+		t.Errorf("expected top-p lowered for incoherent answers, got %v, %v", temperature, topP)
+	}
+
+	// A single issue below the share changes nothing
+	tuner.Observe(samplingWindow("APEX", 7, 1, IssueRepetitive))
+	if got := tuner.Changes(); len(got) != 2 || got[0].Source != SamplingSourceTuner || !strings.Contains(got[0].Reason, "incoherent") {
+		t.Errorf("expected two tuner changes, newest first, got %+v", got)
+	}
+}
+
+func TestSamplingTuner_Bounds(t *testing.T) {
+	tuner := newTestSamplingTuner()
+	for i := 0; i < 20; i++ {
+		tuner.Observe(samplingWindow("APEX", 8, 5, IssueFactualError))
+	}
+	if temperature, _ := tuner.Sampling("APEX"); temperature != 0.2 {
+		t.Errorf("expected the temperature held at its lower bound, got %v", temperature)
+	}
+	if got := len(tuner.Changes()); got != 8 {
+		t.Errorf("expected 8 steps from 1 to 0.2, got %d", got)
+	}
+}
+
+func TestSamplingTuner_RevertsWhenQualityFalls(t *testing.T) {
+	tuner := newTestSamplingTuner()
+	tuner.Observe(samplingWindow("APEX", 8, 5, IssueRepetitive))
+	if temperature, _ := tuner.Sampling("APEX"); temperature != 1.1 {
+		t.Fatalf("expected the temperature raised, got %v", temperature)
+	}
+
+	tuner.Observe(samplingWindow("APEX", 5, 0, ""))
+	if temperature, _ := tuner.Sampling("APEX"); temperature != 1 {
+		t.Errorf("expected the change reverted after quality fell, got %v", temperature)
+	}
+	latest := tuner.Changes()[0]
+	if !latest.Reverted || !strings.Contains(latest.Reason, "quality fell from 0.80 to 0.50") {
+		t.Errorf("expected the revert logged, got %+v", latest)
+	}
+	agents := tuner.Agents()
+	if len(agents) != 1 || agents[0].Quality == nil || *agents[0].Quality != 0.5 || agents[0].Changes != 0 {
+		t.Errorf("unexpected state %+v", agents)
+	}
+}
+
+func TestSamplingTuner_SetAndRevert(t *testing.T) {
+	tuner := newTestSamplingTuner()
+	if _, err := tuner.Revert("APEX"); !errors.Is(err, ErrNoSamplingChange) {
+		t.Errorf("expected ErrNoSamplingChange, got %v", err)
+	}
+	if err := tuner.Set("APEX", SamplingParams{Temperature: 2, TopP: 1}, "hot"); !errors.Is(err, ErrInvalidSampling) {
+		t.Errorf("expected ErrInvalidSampling, got %v", err)
+	}
+	if err := tuner.Set("APEX", SamplingParams{Temperature: 0.5, TopP: 0.8}, "precise"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if temperature, topP := tuner.Sampling("APEX"); temperature != 0.5 || topP != 0.8 {
+		t.Errorf("expected the set parameters, got %v, %v", temperature, topP)
+	}
+	params, err := tuner.Revert("APEX")
+	if err != nil || params != (SamplingParams{Temperature: 1, TopP: 1}) {
+		t.Errorf("expected the default back, got %+v (%v)", params, err)
+	}
+	if got := tuner.Changes(); len(got) != 2 || got[1].Source != SamplingSourceAdmin || got[1].Reason != "precise" {
+		t.Errorf("unexpected changes %+v", got)
+	}
+}
+
+func TestSamplingTuner_SetParams(t *testing.T) {
+	tuner := newTestSamplingTuner()
+	tuner.Observe(samplingWindow("APEX", 8, 5, IssueFactualError))
+	if err := tuner.SetParams(map[string]SamplingParams{"CIPHER": {Temperature: 0.1, TopP: 1}}); !errors.Is(err, ErrInvalidSampling) {
+		t.Errorf("expected ErrInvalidSampling, got %v", err)
+	}
+	if temperature, _ := tuner.Sampling("APEX"); temperature != 0.9 {
+		t.Errorf("expected nothing changed by invalid parameters, got %v", temperature)
+	}
+
+	if err := tuner.SetParams(map[string]SamplingParams{"CIPHER": {Temperature: 0.4, TopP: 0.9}}); err != nil {
+		t.Fatalf("SetParams failed: %v", err)
+	}
+	params := tuner.Params()
+	if len(params) != 1 || params["CIPHER"] != (SamplingParams{Temperature: 0.4, TopP: 0.9}) {
+		t.Errorf("expected only CIPHER off the default, got %+v", params)
+	}
+}
+
+func TestModelRegistry_BindSampling(t *testing.T) {
+	registry := NewModelRegistry(NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	tuner := newTestSamplingTuner()
+	if err := registry.BindSampling("sampling", tuner); err != nil {
+		t.Fatalf("BindSampling failed: %v", err)
+	}
+	if _, err := registry.Snapshot("sampling", "defaults"); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	tuner.Observe(samplingWindow("APEX", 8, 5, IssueFactualError))
+	tuned, err := registry.Snapshot("sampling", "tuned")
+	if err != nil || tuned.Parameters.Sampling["APEX"].Temperature != 0.9 {
+		t.Fatalf("expected the tuned temperature captured, got %+v (%v)", tuned, err)
+	}
+	if _, err := registry.Promote("sampling", 1); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if temperature, _ := tuner.Sampling("APEX"); temperature != 1 {
+		t.Errorf("expected the defaults promoted back, got %v", temperature)
+	}
+	if tuner.Changes()[0].Source != SamplingSourceRegistry {
+		t.Errorf("expected the promotion logged, got %+v", tuner.Changes()[0])
+	}
+}
+
+func TestSamplingTuner_HTTP(t *testing.T) {
+	tuner := newTestSamplingTuner()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/memory/sampling/apex", strings.NewReader(`{"temperature":0.6,"top_p":0.9,"reason":"too creative"}`))
+	req.SetPathValue("agent", "apex")
+	w := httptest.NewRecorder()
+	tuner.ServeSet(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/admin/memory/sampling/apex", strings.NewReader(`{"temperature":0.6,"top_p":0.1}`))
+	req.SetPathValue("agent", "apex")
+	w = httptest.NewRecorder()
+	tuner.ServeSet(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an out of bounds top-p, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	tuner.ServeSampling(w, httptest.NewRequest(http.MethodGet, "/admin/memory/sampling", nil))
+	var resp SamplingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(resp.Agents) != 1 || resp.Agents[0].Agent != "APEX" || resp.Agents[0].Temperature != 0.6 || len(resp.Changes) != 1 {
+		t.Errorf("unexpected response %s", w.Body.String())
+	}
+
+	for _, want := range []int{http.StatusOK, http.StatusConflict} {
+		req = httptest.NewRequest(http.MethodPost, "/admin/memory/sampling/apex/revert", nil)
+		req.SetPathValue("agent", "apex")
+		w = httptest.NewRecorder()
+		tuner.ServeRevert(w, req)
+		if w.Code != want {
+			t.Errorf("expected %d, got %d: %s", want, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements invalidation of spread activation when the Semantic
+// Network's relations change.
+//
+// Activation is left on the nodes a query spread to, and read back by
+// GetMostActivated. When a relation is added from or removed from an
+// active node, that activation no longer matches the graph: it carried
+// activation that no longer has a path, or missed activation it would now
+// pass on. The relation's target, and the active nodes spreading could
+// have reached beyond it, are flagged stale. Stale nodes are left out of
+// GetMostActivated until spreading reaches them again or activation is
+// reset, and an OnActivationInvalidated hook lets caches of activation,
+// such as an attention focus built from it, drop them too.
+
+package memory
+
+import "sort"
+
+// OnActivationInvalidated sets a callback for nodes whose activation goes
+// stale. It runs under the network's write lock, so it must be quick and
+// must not call back into the network.
+func (sn *SemanticNetwork) OnActivationInvalidated(fn func(nodeIDs []string)) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.onActivationInvalidated = fn
+}
+
+// StaleActivations returns the nodes whose activation is stale, sorted.
+func (sn *SemanticNetwork) StaleActivations() []string {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	ids := make([]string, 0, len(sn.staleActivation))
+	for id := range sn.staleActivation {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// IsActivationStale reports whether a node's activation is stale.
+func (sn *SemanticNetwork) IsActivationStale(id string) bool {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+	return sn.staleActivation[id]
+}
+
+// invalidateActivation flags the activation spread over a relation, or
+// that would now spread over it, as stale: the target's, and that of
+// active nodes within spreading depth beyond it. A relation from a node at
+// rest, or too weak to pass on more than the threshold, carries nothing.
+// Callers hold the write lock.
+func (sn *SemanticNetwork) invalidateActivation(rel *SemanticRelation) {
+	source := sn.nodes[rel.SourceID]
+	if source == nil || source.Activation <= source.BaseActivation {
+		return
+	}
+	if source.Activation*sn.config.SpreadingFactor*rel.Weight <= sn.config.ActivationThreshold {
+		return
+	}
+	if _, exists := sn.nodes[rel.TargetID]; !exists {
+		return
+	}
+
+	if sn.staleActivation == nil {
+		sn.staleActivation = make(map[string]bool)
+	}
+	marked := make([]string, 0)
+	mark := func(id string) {
+		if !sn.staleActivation[id] {
+			sn.staleActivation[id] = true
+			marked = append(marked, id)
+		}
+	}
+
+	mark(rel.TargetID)
+	seen := map[string]bool{rel.SourceID: true, rel.TargetID: true}
+	frontier := []string{rel.TargetID}
+	for hop := 1; hop < sn.config.MaxSpreadingDepth && len(frontier) > 0; hop++ {
+		next := make([]string, 0)
+		for _, id := range frontier {
+			for _, out := range sn.outgoing[id] {
+				node := sn.nodes[out.TargetID]
+				if seen[out.TargetID] || node == nil || node.Activation <= node.BaseActivation {
+					continue
+				}
+				seen[out.TargetID] = true
+				mark(out.TargetID)
+				next = append(next, out.TargetID)
+			}
+		}
+		frontier = next
+	}
+
+	if len(marked) > 0 && sn.onActivationInvalidated != nil {
+		sn.onActivationInvalidated(marked)
+	}
+}
+
+// refreshActivation clears a node's stale flag once spreading has set its
+// activation. Callers hold the write lock.
+func (sn *SemanticNetwork) refreshActivation(id string) {
+	delete(sn.staleActivation, id)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+// activatedChain builds dog -> animal -> living, plus a resting cat, and
+// spreads activation from dog.
+func activatedChain(t *testing.T) (*SemanticNetwork, *SemanticRelation) {
+	t.Helper()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"dog", "animal", "living", "cat"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	dogAnimal := NewSemanticRelation("dog", "animal", IsA)
+	sn.AddRelation(dogAnimal)
+	sn.AddRelation(NewSemanticRelation("animal", "living", IsA))
+
+	result := sn.SpreadActivation([]string{"dog"}, 1.0)
+	if _, ok := result.ActivatedNodes["living"]; !ok {
+		t.Fatalf("Expected activation to reach living, got %v", result.ActivatedNodes)
+	}
+	return sn, dogAnimal
+}
+
+func TestSemanticNetwork_RemoveRelationInvalidatesActivation(t *testing.T) {
+	sn, dogAnimal := activatedChain(t)
+	var invalidated []string
+	sn.OnActivationInvalidated(func(ids []string) { invalidated = append(invalidated, ids...) })
+
+	if err := sn.RemoveRelation(dogAnimal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(invalidated, []string{"animal", "living"}) {
+		t.Errorf("Expected animal then living invalidated, got %v", invalidated)
+	}
+	if got := sn.StaleActivations(); !reflect.DeepEqual(got, []string{"animal", "living"}) {
+		t.Errorf("Expected animal and living stale, got %v", got)
+	}
+	for _, node := range sn.GetMostActivated(4) {
+		if node.ID == "animal" || node.ID == "living" {
+			t.Errorf("Expected stale %s left out of the most activated", node.ID)
+		}
+	}
+
+	// Spreading again refreshes what it reaches; animal has no path left
+	sn.SpreadActivation([]string{"dog"}, 1.0)
+	if !sn.IsActivationStale("animal") || sn.IsActivationStale("dog") {
+		t.Errorf("Expected only unreached nodes still stale, got %v", sn.StaleActivations())
+	}
+	sn.ResetActivation()
+	if got := sn.StaleActivations(); len(got) != 0 {
+		t.Errorf("Expected a reset to clear stale activation, got %v", got)
+	}
+}
+
+func TestSemanticNetwork_AddRelationInvalidatesActivation(t *testing.T) {
+	sn, _ := activatedChain(t)
+
+	// From a resting node nothing would spread
+	sn.AddRelation(NewSemanticRelation("cat", "living", RelatedTo))
+	if got := sn.StaleActivations(); len(got) != 0 {
+		t.Errorf("Expected no stale activation from a resting source, got %v", got)
+	}
+
+	// Too weak to pass on more than the threshold
+	weak := NewSemanticRelation("dog", "cat", RelatedTo)
+	weak.Weight = 0.1
+	sn.AddRelation(weak)
+	if sn.IsActivationStale("cat") {
+		t.Error("Expected a relation below the threshold ignored")
+	}
+	sn.RemoveRelation(weak.ID)
+
+	sn.AddRelation(NewSemanticRelation("dog", "cat", RelatedTo))
+	if !sn.IsActivationStale("cat") {
+		t.Fatal("Expected the new target of an active node stale")
+	}
+	sn.SpreadActivation([]string{"dog"}, 1.0)
+	if sn.IsActivationStale("cat") {
+		t.Error("Expected spreading over the new relation to refresh cat")
+	}
+}
+
+func TestSemanticNetwork_RemoveNodeInvalidatesActivation(t *testing.T) {
+	sn, _ := activatedChain(t)
+
+	if err := sn.RemoveNode("dog"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sn.StaleActivations(); !reflect.DeepEqual(got, []string{"animal", "living"}) {
+		t.Errorf("Expected the removed node's reach stale, got %v", got)
+	}
+	if err := sn.RemoveNode("animal"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sn.StaleActivations(); !reflect.DeepEqual(got, []string{"living"}) {
+		t.Errorf("Expected removed nodes dropped from the stale set, got %v", got)
+	}
+}
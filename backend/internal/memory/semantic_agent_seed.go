@@ -0,0 +1,111 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file seeds the Semantic Network with what the collective knows about
+// its own agents: tiers, categories, keywords and collaborators.
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// agentSeedSource marks nodes and relations created from the agent registry.
+const agentSeedSource = "registry"
+
+// SeedAgentOntology adds agents and their metadata to the network:
+// - each agent BELONGS-TO its tier and category
+// - each agent CAN-DO its keywords
+// - each agent is RELATED-TO its collaborators
+// Existing nodes and relations are left untouched, so seeding is idempotent.
+func SeedAgentOntology(sn *SemanticNetwork, agents []models.Agent) error {
+	sorted := append([]models.Agent(nil), agents...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Codename < sorted[j].Codename })
+
+	for _, agent := range sorted {
+		node := NewSemanticNode(agent.Codename, agent.Codename, AgentNode)
+		node.SetProperty("tier", agent.Tier)
+		if agent.Name != "" {
+			node.SetProperty("name", agent.Name)
+		}
+		if agent.Specialty != "" {
+			node.SetProperty("specialty", agent.Specialty)
+		}
+		if agent.Philosophy != "" {
+			node.SetProperty("philosophy", agent.Philosophy)
+		}
+		if err := seedNode(sn, node); err != nil {
+			return err
+		}
+
+		if agent.Tier > 0 {
+			tier := NewSemanticNode(fmt.Sprintf("tier-%d", agent.Tier), fmt.Sprintf("Tier %d", agent.Tier), DomainNode)
+			if err := seedLink(sn, agent.Codename, tier, BelongsTo); err != nil {
+				return err
+			}
+		}
+		if agent.Category != "" {
+			category := NewSemanticNode("category-"+seedSlug(agent.Category), agent.Category, DomainNode)
+			if err := seedLink(sn, agent.Codename, category, BelongsTo); err != nil {
+				return err
+			}
+		}
+		for _, keyword := range agent.Keywords {
+			concept := NewSemanticNode("keyword-"+seedSlug(keyword), keyword, ConceptNode)
+			if err := seedLink(sn, agent.Codename, concept, CanDo); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Collaborators last, once every agent node exists
+	for _, agent := range sorted {
+		for _, collaborator := range agent.Collaborators {
+			if _, err := sn.GetNode(collaborator); err != nil {
+				continue
+			}
+			rel := NewSemanticRelation(agent.Codename, collaborator, RelatedTo)
+			rel.Source = agentSeedSource
+			if err := seedRelation(sn, rel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// seedNode adds a node unless it already exists.
+func seedNode(sn *SemanticNetwork, node *SemanticNode) error {
+	node.Source = agentSeedSource
+	if err := sn.AddNode(node); err != nil && !errors.Is(err, ErrNodeAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// seedLink adds target if needed and relates sourceID to it.
+func seedLink(sn *SemanticNetwork, sourceID string, target *SemanticNode, relType RelationType) error {
+	if err := seedNode(sn, target); err != nil {
+		return err
+	}
+	rel := NewSemanticRelation(sourceID, target.ID, relType)
+	rel.Source = agentSeedSource
+	return seedRelation(sn, rel)
+}
+
+// seedRelation adds a relation unless it already exists.
+func seedRelation(sn *SemanticNetwork, rel *SemanticRelation) error {
+	if err := sn.AddRelation(rel); err != nil && !errors.Is(err, ErrRelationAlreadyExists) &&
+		!errors.Is(err, ErrSelfRelation) {
+		return err
+	}
+	return nil
+}
+
+// seedSlug lowercases a name and joins its words with hyphens.
+func seedSlug(name string) string {
+	return strings.Join(tokenizeQuestion(name), "-")
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func testSeedAgents() []models.Agent {
+	return []models.Agent{
+		{
+			Codename:      "APEX",
+			Tier:          1,
+			Specialty:     "Elite Computer Science Engineering",
+			Category:      "Foundational",
+			Keywords:      []string{"algorithms", "system design"},
+			Collaborators: []string{"CIPHER", "UNKNOWN"},
+		},
+		{
+			Codename:  "CIPHER",
+			Tier:      1,
+			Specialty: "Cryptography",
+			Category:  "Foundational",
+			Keywords:  []string{"encryption"},
+		},
+	}
+}
+
+func TestSeedAgentOntology(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	if err := SeedAgentOntology(sn, testSeedAgents()); err != nil {
+		t.Fatalf("SeedAgentOntology failed: %v", err)
+	}
+
+	// 2 agents, 1 tier, 1 category, 3 keywords
+	if sn.NodeCount() != 7 {
+		t.Errorf("Expected 7 nodes, got %d", sn.NodeCount())
+	}
+	if related := sn.GetRelatedNodes("APEX", BelongsTo); len(related) != 2 {
+		t.Errorf("Expected APEX to belong to tier and category, got %d", len(related))
+	}
+	if related := sn.GetRelatedNodes("APEX", CanDo); len(related) != 2 {
+		t.Errorf("Expected 2 APEX keywords, got %d", len(related))
+	}
+	if _, err := sn.GetNode("keyword-system-design"); err != nil {
+		t.Errorf("Expected slugged keyword node: %v", err)
+	}
+	// Unknown collaborators are skipped
+	if related := sn.GetRelatedNodes("APEX", RelatedTo); len(related) != 1 || related[0].ID != "CIPHER" {
+		t.Errorf("Expected APEX related to CIPHER only, got %v", related)
+	}
+
+	node, _ := sn.GetNode("APEX")
+	if value, _ := node.GetProperty("specialty"); value != "Elite Computer Science Engineering" {
+		t.Errorf("Expected specialty property, got %v", value)
+	}
+
+	// Seeding again changes nothing
+	relations := sn.RelationCount()
+	if err := SeedAgentOntology(sn, testSeedAgents()); err != nil {
+		t.Fatalf("Second SeedAgentOntology failed: %v", err)
+	}
+	if sn.NodeCount() != 7 || sn.RelationCount() != relations {
+		t.Error("Expected seeding to be idempotent")
+	}
+}
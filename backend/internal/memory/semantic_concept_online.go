@@ -0,0 +1,309 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements online (incremental) concept learning.
+//
+// ExtractPrototype builds a concept from a batch of instances. Once a concept
+// is committed, the ConceptLearner keeps running statistics for it so new
+// matching instances can refine it one at a time:
+// - Centroid: running mean of instance embeddings
+// - Common properties: shrink as instances disagree with the prototype
+// - Confidence: shared properties relative to the average instance
+// - Drift: an EWMA of match quality compared with the batch baseline
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrConceptNotTracked indicates the concept is not being learned online
+	ErrConceptNotTracked = errdefs.New(errdefs.ErrNotFound, "concept not tracked for online learning")
+	// ErrNoMatchingConcept indicates no tracked concept matches an instance
+	ErrNoMatchingConcept = errdefs.New(errdefs.ErrNotFound, "no matching concept")
+)
+
+// conceptTracker holds running statistics for a concept learned online.
+type conceptTracker struct {
+	concept *LearnedConcept
+	// count is the number of instances absorbed, including the batch
+	count int
+	// centroid is the running mean embedding, kept in float64 for precision
+	centroid []float64
+	// embeddingCount is how many instances contributed to the centroid
+	embeddingCount int
+	// propertyTotal sums instance property counts for confidence
+	propertyTotal int
+	// baseline is the mean match quality of the batch instances
+	baseline float64
+	// quality is the EWMA of match quality for online observations
+	quality float64
+}
+
+// ConceptUpdate describes how an observation changed a concept.
+type ConceptUpdate struct {
+	ConceptID  string
+	InstanceID string
+	// Match is how well the instance fit the concept before the update
+	Match float64
+	// RemovedProperties lists common properties the instance contradicted
+	RemovedProperties []string
+	Confidence        float64
+	DriftScore        float64
+	Drifted           bool
+	// DriftDetected is true only on the observation that crossed the threshold
+	DriftDetected bool
+}
+
+// TrackConcept starts online learning for a concept, seeding running
+// statistics from its batch instances. CommitLearnedConcept calls this.
+func (cl *ConceptLearner) TrackConcept(concept *LearnedConcept) {
+	tracker := &conceptTracker{concept: concept}
+	if proto := concept.PrototypeNode; proto != nil && len(proto.Embedding) > 0 {
+		tracker.centroid = make([]float64, len(proto.Embedding))
+		for i, v := range proto.Embedding {
+			tracker.centroid[i] = float64(v)
+		}
+	}
+
+	cl.network.mu.RLock()
+	qualitySum := 0.0
+	for _, id := range concept.Instances {
+		inst, exists := cl.network.nodes[id]
+		if !exists {
+			continue
+		}
+		tracker.count++
+		tracker.propertyTotal += len(inst.Properties)
+		if tracker.centroid != nil && len(inst.Embedding) == len(tracker.centroid) {
+			tracker.embeddingCount++
+		}
+		qualitySum += cl.matchQuality(tracker, inst)
+	}
+	cl.network.mu.RUnlock()
+
+	tracker.baseline = 1.0
+	if tracker.count > 0 {
+		tracker.baseline = qualitySum / float64(tracker.count)
+	}
+	tracker.quality = tracker.baseline
+
+	cl.mu.Lock()
+	cl.tracked[concept.ID] = tracker
+	cl.mu.Unlock()
+}
+
+// UntrackConcept stops online learning for a concept.
+func (cl *ConceptLearner) UntrackConcept(conceptID string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	delete(cl.tracked, conceptID)
+}
+
+// TrackedConcepts returns the concepts being learned online.
+func (cl *ConceptLearner) TrackedConcepts() []*LearnedConcept {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	concepts := make([]*LearnedConcept, 0, len(cl.tracked))
+	for _, tracker := range cl.tracked {
+		concepts = append(concepts, tracker.concept)
+	}
+	return concepts
+}
+
+// ObserveInstance absorbs a new instance into the best-matching tracked
+// concept. Returns ErrNoMatchingConcept if no concept matches at or above
+// the learner's similarity threshold.
+func (cl *ConceptLearner) ObserveInstance(instanceID string) (*ConceptUpdate, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.network.mu.Lock()
+	inst, exists := cl.network.nodes[instanceID]
+	if !exists {
+		cl.network.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, instanceID)
+	}
+
+	var best *conceptTracker
+	bestMatch := cl.similarityThreshold
+	for _, tracker := range cl.tracked {
+		if tracker.concept.ID == instanceID || containsString(tracker.concept.Instances, instanceID) {
+			continue
+		}
+		match := cl.matchQuality(tracker, inst)
+		if match >= bestMatch && (best == nil || match > bestMatch || tracker.concept.ID < best.concept.ID) {
+			best = tracker
+			bestMatch = match
+		}
+	}
+	if best == nil {
+		cl.network.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoMatchingConcept, instanceID)
+	}
+
+	update := cl.absorb(best, inst)
+	cl.network.mu.Unlock()
+
+	cl.linkInstance(best.concept, instanceID)
+	return update, nil
+}
+
+// ObserveInstanceFor absorbs an instance into a specific tracked concept,
+// regardless of how well it matches. Poor matches push the concept
+// towards drift.
+func (cl *ConceptLearner) ObserveInstanceFor(conceptID, instanceID string) (*ConceptUpdate, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	tracker, ok := cl.tracked[conceptID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConceptNotTracked, conceptID)
+	}
+
+	cl.network.mu.Lock()
+	inst, exists := cl.network.nodes[instanceID]
+	if !exists {
+		cl.network.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, instanceID)
+	}
+	update := cl.absorb(tracker, inst)
+	cl.network.mu.Unlock()
+
+	cl.linkInstance(tracker.concept, instanceID)
+	return update, nil
+}
+
+// absorb folds one instance into a concept's running statistics and
+// prototype. Caller must hold cl.mu and cl.network.mu for writing.
+func (cl *ConceptLearner) absorb(tracker *conceptTracker, inst *SemanticNode) *ConceptUpdate {
+	concept := tracker.concept
+	proto := concept.PrototypeNode
+	// A committed prototype is the network's node: copy it on write, as a
+	// snapshot may be cloning it, follow any copy another writer made, and
+	// reindex the properties removed from it
+	if proto != nil {
+		if current, ok := cl.network.nodes[proto.ID]; ok {
+			proto = cl.network.mutableNode(current)
+			concept.PrototypeNode = proto
+			cl.network.unindexNode(proto)
+			defer cl.network.indexNode(proto)
+		}
+	}
+
+	update := &ConceptUpdate{
+		ConceptID:  concept.ID,
+		InstanceID: inst.ID,
+		Match:      cl.matchQuality(tracker, inst),
+	}
+
+	tracker.count++
+	tracker.propertyTotal += len(inst.Properties)
+	concept.Instances = append(concept.Instances, inst.ID)
+
+	// Running mean of embeddings
+	if len(inst.Embedding) > 0 {
+		if tracker.centroid == nil {
+			tracker.centroid = make([]float64, len(inst.Embedding))
+		}
+		if len(inst.Embedding) == len(tracker.centroid) {
+			tracker.embeddingCount++
+			n := float64(tracker.embeddingCount)
+			for i, v := range inst.Embedding {
+				tracker.centroid[i] += (float64(v) - tracker.centroid[i]) / n
+			}
+			if proto != nil {
+				proto.Embedding = make([]float32, len(tracker.centroid))
+				for i, v := range tracker.centroid {
+					proto.Embedding[i] = float32(v)
+				}
+			}
+		}
+	}
+
+	// Common properties can only shrink
+	for key, value := range concept.CommonProperties {
+		if instVal, ok := inst.Properties[key]; !ok || !valuesEqual(value, instVal) {
+			delete(concept.CommonProperties, key)
+			if proto != nil {
+				delete(proto.Properties, key)
+			}
+			update.RemovedProperties = append(update.RemovedProperties, key)
+		}
+	}
+	sort.Strings(update.RemovedProperties)
+
+	// Confidence: shared properties relative to the average instance
+	confidence := 0.0
+	if tracker.propertyTotal > 0 {
+		avgProps := float64(tracker.propertyTotal) / float64(tracker.count)
+		confidence = float64(len(concept.CommonProperties)) / avgProps
+		if confidence > 1.0 {
+			confidence = 1.0
+		}
+	}
+	concept.Confidence = confidence
+
+	// Drift: smoothed match quality falling below the batch baseline
+	tracker.quality = cl.driftSmoothing*update.Match + (1-cl.driftSmoothing)*tracker.quality
+	drift := tracker.baseline - tracker.quality
+	if drift < 0 {
+		drift = 0
+	}
+	concept.DriftScore = drift
+	if !concept.Drifted && drift > cl.driftThreshold {
+		concept.Drifted = true
+		update.DriftDetected = true
+	}
+
+	concept.UpdatedAt = time.Now()
+	if proto != nil {
+		proto.Confidence = confidence
+	}
+	cl.network.stats.LastUpdated = concept.UpdatedAt
+
+	update.Confidence = concept.Confidence
+	update.DriftScore = concept.DriftScore
+	update.Drifted = concept.Drifted
+	return update
+}
+
+// linkInstance records INSTANCE-OF from the instance to a committed concept.
+func (cl *ConceptLearner) linkInstance(concept *LearnedConcept, instanceID string) {
+	rel := NewSemanticRelation(instanceID, concept.ID, InstanceOf)
+	rel.Confidence = concept.Confidence
+	rel.Source = "learned"
+	// The prototype may not be committed, or the link may already exist
+	_ = cl.network.AddRelation(rel)
+}
+
+// matchQuality scores how well an instance fits a concept: cosine similarity
+// to the centroid when both have embeddings, otherwise the fraction of the
+// concept's common properties the instance shares.
+// Caller must hold cl.network.mu.
+func (cl *ConceptLearner) matchQuality(tracker *conceptTracker, inst *SemanticNode) float64 {
+	if len(tracker.centroid) > 0 && len(inst.Embedding) == len(tracker.centroid) {
+		centroid := make([]float32, len(tracker.centroid))
+		for i, v := range tracker.centroid {
+			centroid[i] = float32(v)
+		}
+		return cosineSimilarityFloat32(inst.Embedding, centroid)
+	}
+
+	common := tracker.concept.CommonProperties
+	if len(common) == 0 {
+		return 0
+	}
+	shared := 0
+	for key, value := range common {
+		if instVal, ok := inst.Properties[key]; ok && valuesEqual(value, instVal) {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(common))
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+// ============================================================================
+// Online Concept Learning Tests
+// ============================================================================
+
+// commitEmbeddedConcept adds three instances near (1, 0) and commits a concept.
+func commitEmbeddedConcept(t *testing.T, sn *SemanticNetwork, learner *ConceptLearner) *LearnedConcept {
+	t.Helper()
+	embeddings := [][]float32{{1, 0}, {0.9, 0.1}, {0.95, 0.05}}
+	ids := make([]string, 0, len(embeddings))
+	for i, emb := range embeddings {
+		id := fmt.Sprintf("inst%d", i)
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.Embedding = emb
+		node.SetProperty("domain", "sorting")
+		node.SetProperty("stable", true)
+		if err := sn.AddNode(node); err != nil {
+			t.Fatalf("AddNode failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	concept, err := learner.ExtractPrototype(ids)
+	if err != nil {
+		t.Fatalf("ExtractPrototype failed: %v", err)
+	}
+	if err := learner.CommitLearnedConcept(concept); err != nil {
+		t.Fatalf("CommitLearnedConcept failed: %v", err)
+	}
+	return concept
+}
+
+func TestConceptLearner_ObserveInstanceUpdatesCentroid(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	learner := NewConceptLearner(sn)
+	concept := commitEmbeddedConcept(t, sn, learner)
+
+	if len(learner.TrackedConcepts()) != 1 {
+		t.Fatalf("Expected committed concept to be tracked, got %d", len(learner.TrackedConcepts()))
+	}
+
+	node := NewSemanticNode("new", "new", InstanceNode)
+	node.Embedding = []float32{0.85, 0.15}
+	node.SetProperty("domain", "sorting")
+	node.SetProperty("stable", false)
+	sn.AddNode(node)
+
+	update, err := learner.ObserveInstance("new")
+	if err != nil {
+		t.Fatalf("ObserveInstance failed: %v", err)
+	}
+	if update.ConceptID != concept.ID {
+		t.Errorf("Expected update for %s, got %s", concept.ID, update.ConceptID)
+	}
+
+	// Centroid of the four embeddings
+	wantX := (1 + 0.9 + 0.95 + 0.85) / 4
+	if math.Abs(float64(concept.PrototypeNode.Embedding[0])-wantX) > 1e-5 {
+		t.Errorf("Expected centroid x=%f, got %f", wantX, concept.PrototypeNode.Embedding[0])
+	}
+
+	if len(update.RemovedProperties) != 1 || update.RemovedProperties[0] != "stable" {
+		t.Errorf("Expected 'stable' to be removed, got %v", update.RemovedProperties)
+	}
+	if _, ok := concept.PrototypeNode.Properties["stable"]; ok {
+		t.Error("Prototype should no longer carry 'stable'")
+	}
+	if concept.Confidence != 0.5 {
+		t.Errorf("Expected confidence 0.5 (1 of 2 properties), got %f", concept.Confidence)
+	}
+	if len(concept.Instances) != 4 {
+		t.Errorf("Expected 4 instances, got %d", len(concept.Instances))
+	}
+
+	// The instance is linked into the committed concept
+	if related := sn.GetRelatedNodes("new", InstanceOf); len(related) != 1 || related[0].ID != concept.ID {
+		t.Error("Expected INSTANCE-OF link from new instance to concept")
+	}
+
+	// Observing again is not a new match
+	if _, err := learner.ObserveInstance("new"); !errors.Is(err, ErrNoMatchingConcept) {
+		t.Errorf("Expected ErrNoMatchingConcept for already absorbed instance, got %v", err)
+	}
+}
+
+func TestConceptLearner_ObserveInstanceNoMatch(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	learner := NewConceptLearner(sn)
+	commitEmbeddedConcept(t, sn, learner)
+
+	far := NewSemanticNode("far", "far", InstanceNode)
+	far.Embedding = []float32{0, 1}
+	sn.AddNode(far)
+
+	if _, err := learner.ObserveInstance("far"); !errors.Is(err, ErrNoMatchingConcept) {
+		t.Errorf("Expected ErrNoMatchingConcept, got %v", err)
+	}
+	if _, err := learner.ObserveInstance("missing"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("Expected ErrNodeNotFound, got %v", err)
+	}
+	if _, err := learner.ObserveInstanceFor("nope", "far"); !errors.Is(err, ErrConceptNotTracked) {
+		t.Errorf("Expected ErrConceptNotTracked, got %v", err)
+	}
+}
+
+func TestConceptLearner_DriftDetection(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	learner := NewConceptLearner(sn)
+	concept := commitEmbeddedConcept(t, sn, learner)
+
+	detections := 0
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("drift%d", i)
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.Embedding = []float32{0.2, 1}
+		sn.AddNode(node)
+
+		update, err := learner.ObserveInstanceFor(concept.ID, id)
+		if err != nil {
+			t.Fatalf("ObserveInstanceFor failed: %v", err)
+		}
+		if update.DriftDetected {
+			detections++
+		}
+	}
+
+	if !concept.Drifted {
+		t.Errorf("Expected concept to drift, drift score %f", concept.DriftScore)
+	}
+	if detections != 1 {
+		t.Errorf("Expected drift to be reported once, got %d", detections)
+	}
+}
+
+func TestConceptLearner_PropertyOnlyMatching(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	learner := NewConceptLearner(sn)
+
+	for i := 0; i < 3; i++ {
+		node := NewSemanticNode(fmt.Sprintf("p%d", i), "P", InstanceNode)
+		node.SetProperty("kind", "cache")
+		node.SetProperty("ttl", NumberValue(60, "s"))
+		sn.AddNode(node)
+	}
+	concept, err := learner.ExtractPrototype([]string{"p0", "p1", "p2"})
+	if err != nil {
+		t.Fatalf("ExtractPrototype failed: %v", err)
+	}
+	learner.TrackConcept(concept)
+
+	match := NewSemanticNode("p3", "P", InstanceNode)
+	match.SetProperty("kind", "cache")
+	match.SetProperty("ttl", NumberValue(1, "min"))
+	sn.AddNode(match)
+
+	update, err := learner.ObserveInstance("p3")
+	if err != nil {
+		t.Fatalf("ObserveInstance failed: %v", err)
+	}
+	if update.Match != 1.0 {
+		t.Errorf("Expected full property match, got %f", update.Match)
+	}
+	if len(concept.CommonProperties) != 2 {
+		t.Errorf("Expected common properties to be kept, got %v", concept.CommonProperties)
+	}
+
+	learner.UntrackConcept(concept.ID)
+	if len(learner.TrackedConcepts()) != 0 {
+		t.Error("Expected no tracked concepts after UntrackConcept")
+	}
+}
+
+func TestConceptLearner_ObserveDuringSnapshot(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"stable"}
+	sn := NewSemanticNetwork(config)
+	learner := NewConceptLearner(sn)
+	concept := commitEmbeddedConcept(t, sn, learner)
+
+	const observations = 50
+	for i := 0; i < observations; i++ {
+		id := fmt.Sprintf("late%d", i)
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.Embedding = []float32{0.8, 0.2}
+		node.SetProperty("domain", "sorting")
+		if err := sn.AddNode(node); err != nil {
+			t.Fatalf("AddNode failed: %v", err)
+		}
+	}
+
+	// A snapshot in progress keeps reading the prototype it froze
+	frozen, _, _, _ := sn.freeze()
+	var frozenProto *SemanticNode
+	for _, node := range frozen {
+		if node.ID == concept.ID {
+			frozenProto = node
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < observations; i++ {
+			frozenProto.Clone()
+			sn.Snapshot()
+		}
+	}()
+	for i := 0; i < observations; i++ {
+		if _, err := learner.ObserveInstanceFor(concept.ID, fmt.Sprintf("late%d", i)); err != nil {
+			t.Fatalf("ObserveInstanceFor failed: %v", err)
+		}
+	}
+	<-done
+	atomic.AddInt64(&sn.activeSnapshots, -1)
+
+	if _, ok := frozenProto.Properties["stable"]; !ok || frozenProto == concept.PrototypeNode {
+		t.Error("Expected the frozen prototype left as it was")
+	}
+	stored, err := sn.GetNode(concept.ID)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if stored != concept.PrototypeNode {
+		t.Error("Expected the learner to update the network's copy of the prototype")
+	}
+	if _, ok := stored.Properties["stable"]; ok {
+		t.Error("Expected the network's prototype to no longer carry 'stable'")
+	}
+	if found := sn.FindNodesByProperty("stable", true); len(found) != 3 {
+		t.Errorf("Expected only the instances indexed by 'stable', got %d nodes", len(found))
+	}
+}
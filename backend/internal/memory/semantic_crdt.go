@@ -0,0 +1,802 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements CRDT replication of the Semantic Network for
+// active-active, multi-region deployments.
+//
+// Each region owns a SemanticReplica that wraps its network. Local writes
+// go through the replica, which applies them to the network and records
+// them in conflict-free replicated data types:
+//
+//   - node and relation membership: OR-sets (add wins over a concurrent remove)
+//   - node attributes, properties and relation weights: LWW registers
+//   - statistics: PN-counters
+//
+// Replicas reconcile by exchanging their state and merging it; merges are
+// commutative, associative and idempotent, so any gossip order converges
+// without a central coordinator. After a merge the network is rebuilt from
+// the merged state. Relations that together violate a network invariant
+// (e.g. concurrent IS-A edges forming a cycle) are added in ID order and
+// the losers reported as conflicts, so every replica keeps the same ones.
+//
+// Replication is a library: the server doesn't replicate its network, and
+// a deployment embedding replicas carries State between regions itself,
+// e.g. over its own RPC, and Merges what it receives.
+
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrReplicaMismatch is returned when merging a replica's state into itself
+var ErrReplicaMismatch = errdefs.New(errdefs.ErrInvalidArgument, "cannot merge replica state into itself")
+
+// Replica counter names maintained by SemanticReplica.
+const (
+	CounterNodesCreated     = "nodes_created"
+	CounterNodesRemoved     = "nodes_removed"
+	CounterRelationsCreated = "relations_created"
+	CounterRelationsRemoved = "relations_removed"
+)
+
+// ============================================================================
+// CRDT Primitives
+// ============================================================================
+
+// CRDTTimestamp is a hybrid logical clock reading. Ties between replicas
+// are broken by replica ID so every replica picks the same winner.
+type CRDTTimestamp struct {
+	Time    int64  `json:"t"`
+	Replica string `json:"r"`
+}
+
+// After returns true if t is later than other.
+func (t CRDTTimestamp) After(other CRDTTimestamp) bool {
+	if t.Time != other.Time {
+		return t.Time > other.Time
+	}
+	return t.Replica > other.Replica
+}
+
+// tag returns a unique OR-set tag for the timestamp.
+func (t CRDTTimestamp) tag() string {
+	return fmt.Sprintf("%s@%d", t.Replica, t.Time)
+}
+
+// LWWRegister is a last-writer-wins register. Deleted marks a tombstone.
+type LWWRegister struct {
+	Value     json.RawMessage `json:"value,omitempty"`
+	Timestamp CRDTTimestamp   `json:"ts"`
+	Deleted   bool            `json:"deleted,omitempty"`
+}
+
+// Merge keeps the later of the two writes, returning true if r changed.
+func (r *LWWRegister) Merge(other LWWRegister) bool {
+	if !other.Timestamp.After(r.Timestamp) {
+		return false
+	}
+	*r = other
+	return true
+}
+
+// ORSet is an observed-remove set. Each add carries a unique tag; a remove
+// tombstones only the tags it has observed, so a concurrent add survives.
+type ORSet struct {
+	// Adds maps each element to its add tags
+	Adds map[string]map[string]bool `json:"adds"`
+	// Removes maps each element to its tombstoned tags
+	Removes map[string]map[string]bool `json:"removes"`
+}
+
+// NewORSet creates an empty OR-set.
+func NewORSet() *ORSet {
+	return &ORSet{
+		Adds:    make(map[string]map[string]bool),
+		Removes: make(map[string]map[string]bool),
+	}
+}
+
+// Add inserts an element with a unique tag.
+func (s *ORSet) Add(element, tag string) {
+	addTag(s.Adds, element, tag)
+}
+
+// Remove tombstones every observed tag of an element.
+func (s *ORSet) Remove(element string) {
+	for tag := range s.Adds[element] {
+		addTag(s.Removes, element, tag)
+	}
+}
+
+// Contains returns true if the element has a tag that was not removed.
+func (s *ORSet) Contains(element string) bool {
+	for tag := range s.Adds[element] {
+		if !s.Removes[element][tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// Elements returns the present elements in sorted order.
+func (s *ORSet) Elements() []string {
+	elements := make([]string, 0, len(s.Adds))
+	for element := range s.Adds {
+		if s.Contains(element) {
+			elements = append(elements, element)
+		}
+	}
+	sort.Strings(elements)
+	return elements
+}
+
+// Merge unions adds and tombstones, returning true if s changed.
+func (s *ORSet) Merge(other *ORSet) bool {
+	changed := false
+	for element, tags := range other.Adds {
+		for tag := range tags {
+			changed = addTag(s.Adds, element, tag) || changed
+		}
+	}
+	for element, tags := range other.Removes {
+		for tag := range tags {
+			changed = addTag(s.Removes, element, tag) || changed
+		}
+	}
+	return changed
+}
+
+// addTag inserts a tag, returning true if it was new.
+func addTag(sets map[string]map[string]bool, element, tag string) bool {
+	tags, ok := sets[element]
+	if !ok {
+		tags = make(map[string]bool)
+		sets[element] = tags
+	}
+	if tags[tag] {
+		return false
+	}
+	tags[tag] = true
+	return true
+}
+
+// PNCounter is a counter that supports increments and decrements. Each
+// replica only updates its own entries, and merge takes the maximum.
+type PNCounter struct {
+	P map[string]int64 `json:"p"`
+	N map[string]int64 `json:"n"`
+}
+
+// NewPNCounter creates a zero counter.
+func NewPNCounter() *PNCounter {
+	return &PNCounter{
+		P: make(map[string]int64),
+		N: make(map[string]int64),
+	}
+}
+
+// Increment adds delta (which may be negative) on behalf of a replica.
+func (c *PNCounter) Increment(replica string, delta int64) {
+	if delta >= 0 {
+		c.P[replica] += delta
+	} else {
+		c.N[replica] -= delta
+	}
+}
+
+// Value returns the counter value across all replicas.
+func (c *PNCounter) Value() int64 {
+	var value int64
+	for _, p := range c.P {
+		value += p
+	}
+	for _, n := range c.N {
+		value -= n
+	}
+	return value
+}
+
+// Merge takes the per-replica maximum, returning true if c changed.
+func (c *PNCounter) Merge(other *PNCounter) bool {
+	changed := false
+	for replica, p := range other.P {
+		if p > c.P[replica] {
+			c.P[replica] = p
+			changed = true
+		}
+	}
+	for replica, n := range other.N {
+		if n > c.N[replica] {
+			c.N[replica] = n
+			changed = true
+		}
+	}
+	return changed
+}
+
+// ============================================================================
+// Replica State
+// ============================================================================
+
+// nodeAttributes are the LWW-replicated scalar fields of a node.
+type nodeAttributes struct {
+	Label      string   `json:"label"`
+	Type       NodeType `json:"type"`
+	Confidence float64  `json:"confidence"`
+	Source     string   `json:"source"`
+}
+
+// relationAttributes are the LWW-replicated fields of a relation.
+type relationAttributes struct {
+	SourceID   string       `json:"source_id"`
+	TargetID   string       `json:"target_id"`
+	Type       RelationType `json:"type"`
+	Weight     float64      `json:"weight"`
+	Confidence float64      `json:"confidence"`
+	Source     string       `json:"source"`
+}
+
+// ReplicaState is the mergeable state a replica sends to its peers.
+type ReplicaState struct {
+	ReplicaID string `json:"replica_id"`
+	// Clock is the highest hybrid logical clock value seen
+	Clock int64 `json:"clock"`
+
+	Nodes          *ORSet                            `json:"nodes"`
+	NodeAttributes map[string]LWWRegister            `json:"node_attributes"`
+	Properties     map[string]map[string]LWWRegister `json:"properties"`
+
+	Relations          *ORSet                 `json:"relations"`
+	RelationAttributes map[string]LWWRegister `json:"relation_attributes"`
+
+	Counters map[string]*PNCounter `json:"counters"`
+}
+
+// newReplicaState creates empty state for a replica.
+func newReplicaState(replicaID string) *ReplicaState {
+	return &ReplicaState{
+		ReplicaID:          replicaID,
+		Nodes:              NewORSet(),
+		NodeAttributes:     make(map[string]LWWRegister),
+		Properties:         make(map[string]map[string]LWWRegister),
+		Relations:          NewORSet(),
+		RelationAttributes: make(map[string]LWWRegister),
+		Counters:           make(map[string]*PNCounter),
+	}
+}
+
+// MergeReport summarizes how a merge changed the local network.
+type MergeReport struct {
+	NodesAdded       int
+	NodesUpdated     int
+	NodesRemoved     int
+	RelationsAdded   int
+	RelationsRemoved int
+	// Conflicts lists relations dropped because they violate an invariant
+	Conflicts []string
+	// Rebuilt is set when relations were re-added in ID order to resolve conflicts
+	Rebuilt bool
+}
+
+// ============================================================================
+// Semantic Replica
+// ============================================================================
+
+// SemanticReplica replicates a SemanticNetwork with CRDT semantics. Writes
+// made directly on the network bypass replication.
+type SemanticReplica struct {
+	mu sync.Mutex
+
+	network *SemanticNetwork
+	state   *ReplicaState
+	// now reads the physical clock; replaceable for tests
+	now func() time.Time
+}
+
+// NewSemanticReplica creates a replica over a network. Nodes and relations
+// already in the network are registered as local adds.
+func NewSemanticReplica(replicaID string, network *SemanticNetwork) *SemanticReplica {
+	r := &SemanticReplica{
+		network: network,
+		state:   newReplicaState(replicaID),
+		now:     time.Now,
+	}
+
+	for _, node := range network.GetAllNodes() {
+		r.recordNode(node, r.tick())
+	}
+	for _, rel := range network.GetAllRelations() {
+		r.recordRelation(rel, r.tick())
+	}
+	return r
+}
+
+// ReplicaID returns the replica's identifier.
+func (r *SemanticReplica) ReplicaID() string {
+	return r.state.ReplicaID
+}
+
+// Network returns the materialized network.
+func (r *SemanticReplica) Network() *SemanticNetwork {
+	return r.network
+}
+
+// tick advances the hybrid logical clock. Caller must hold r.mu or be
+// constructing the replica.
+func (r *SemanticReplica) tick() CRDTTimestamp {
+	now := r.now().UnixNano()
+	if now <= r.state.Clock {
+		now = r.state.Clock + 1
+	}
+	r.state.Clock = now
+	return CRDTTimestamp{Time: now, Replica: r.state.ReplicaID}
+}
+
+// recordNode registers a node add with its attributes and properties.
+func (r *SemanticReplica) recordNode(node *SemanticNode, ts CRDTTimestamp) {
+	r.state.Nodes.Add(node.ID, ts.tag())
+	r.setRegister(r.state.NodeAttributes, node.ID, nodeAttributes{
+		Label:      node.Label,
+		Type:       node.Type,
+		Confidence: node.Confidence,
+		Source:     node.Source,
+	}, ts)
+
+	props := make(map[string]LWWRegister, len(node.Properties))
+	// Properties of an earlier incarnation are tombstoned, not inherited
+	for key, reg := range r.state.Properties[node.ID] {
+		if _, ok := node.Properties[key]; !ok {
+			props[key] = LWWRegister{Timestamp: ts, Deleted: true}
+		} else {
+			props[key] = reg
+		}
+	}
+	r.state.Properties[node.ID] = props
+	for key, value := range node.Properties {
+		r.setProperty(node.ID, key, value, ts)
+	}
+	r.counter(CounterNodesCreated).Increment(r.state.ReplicaID, 1)
+}
+
+// recordRelation registers a relation add with its attributes.
+func (r *SemanticReplica) recordRelation(rel *SemanticRelation, ts CRDTTimestamp) {
+	r.state.Relations.Add(rel.ID, ts.tag())
+	r.setRegister(r.state.RelationAttributes, rel.ID, relationAttributes{
+		SourceID:   rel.SourceID,
+		TargetID:   rel.TargetID,
+		Type:       rel.Type,
+		Weight:     rel.Weight,
+		Confidence: rel.Confidence,
+		Source:     rel.Source,
+	}, ts)
+	r.counter(CounterRelationsCreated).Increment(r.state.ReplicaID, 1)
+}
+
+// setRegister writes a JSON value into an LWW register map.
+func (r *SemanticReplica) setRegister(registers map[string]LWWRegister, id string, value interface{}, ts CRDTTimestamp) {
+	raw, _ := json.Marshal(value)
+	reg := registers[id]
+	reg.Merge(LWWRegister{Value: raw, Timestamp: ts})
+	registers[id] = reg
+}
+
+// setProperty writes a property register, preserving the value's Go type.
+func (r *SemanticReplica) setProperty(nodeID, key string, value interface{}, ts CRDTTimestamp) error {
+	encoded, err := encodeWALValue(value)
+	if err != nil {
+		return fmt.Errorf("property %s on %s: %w", key, nodeID, err)
+	}
+	props, ok := r.state.Properties[nodeID]
+	if !ok {
+		props = make(map[string]LWWRegister)
+		r.state.Properties[nodeID] = props
+	}
+	r.setRegister(props, key, encoded, ts)
+	return nil
+}
+
+// counter returns a named counter, creating it if needed.
+func (r *SemanticReplica) counter(name string) *PNCounter {
+	c, ok := r.state.Counters[name]
+	if !ok {
+		c = NewPNCounter()
+		r.state.Counters[name] = c
+	}
+	return c
+}
+
+// ============================================================================
+// Local Operations
+// ============================================================================
+
+// AddNode adds a node locally and records it for replication.
+func (r *SemanticReplica) AddNode(node *SemanticNode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.network.AddNode(node); err != nil {
+		return err
+	}
+	r.recordNode(node, r.tick())
+	return nil
+}
+
+// SetNodeProperty sets a property locally and records it for replication.
+func (r *SemanticReplica) SetNodeProperty(nodeID, key string, value interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.network.SetNodeProperty(nodeID, key, value); err != nil {
+		return err
+	}
+	node, err := r.network.GetNode(nodeID)
+	if err != nil {
+		return err
+	}
+	// Record the stored form, which a property schema may have typed
+	return r.setProperty(nodeID, key, node.Properties[key], r.tick())
+}
+
+// DeleteNodeProperty removes a property locally and records a tombstone.
+func (r *SemanticReplica) DeleteNodeProperty(nodeID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	node, err := r.network.GetNode(nodeID)
+	if err != nil {
+		return err
+	}
+	updated := node.Clone()
+	delete(updated.Properties, key)
+	if err := r.network.UpdateNode(updated); err != nil {
+		return err
+	}
+
+	ts := r.tick()
+	props, ok := r.state.Properties[nodeID]
+	if !ok {
+		props = make(map[string]LWWRegister)
+		r.state.Properties[nodeID] = props
+	}
+	reg := props[key]
+	reg.Merge(LWWRegister{Timestamp: ts, Deleted: true})
+	props[key] = reg
+	return nil
+}
+
+// RemoveNode removes a node and its relations locally and records the removal.
+func (r *SemanticReplica) RemoveNode(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	relations := append(r.network.GetOutgoingRelations(id), r.network.GetIncomingRelations(id)...)
+	if err := r.network.RemoveNode(id); err != nil {
+		return err
+	}
+	r.state.Nodes.Remove(id)
+	for _, rel := range relations {
+		r.state.Relations.Remove(rel.ID)
+		r.counter(CounterRelationsRemoved).Increment(r.state.ReplicaID, 1)
+	}
+	r.counter(CounterNodesRemoved).Increment(r.state.ReplicaID, 1)
+	return nil
+}
+
+// AddRelation adds a relation locally and records it for replication.
+func (r *SemanticReplica) AddRelation(rel *SemanticRelation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.network.AddRelation(rel); err != nil {
+		return err
+	}
+	r.recordRelation(rel, r.tick())
+	return nil
+}
+
+// RemoveRelation removes a relation locally and records the removal.
+func (r *SemanticReplica) RemoveRelation(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.network.RemoveRelation(id); err != nil {
+		return err
+	}
+	r.state.Relations.Remove(id)
+	r.counter(CounterRelationsRemoved).Increment(r.state.ReplicaID, 1)
+	return nil
+}
+
+// IncrementCounter adds delta to a replicated counter.
+func (r *SemanticReplica) IncrementCounter(name string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counter(name).Increment(r.state.ReplicaID, delta)
+}
+
+// Counter returns the value of a replicated counter across all replicas.
+func (r *SemanticReplica) Counter(name string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.state.Counters[name]; ok {
+		return c.Value()
+	}
+	return 0
+}
+
+// ============================================================================
+// Merge Protocol
+// ============================================================================
+
+// State returns a deep copy of the replica's state to send to peers.
+func (r *SemanticReplica) State() (*ReplicaState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	raw, err := json.Marshal(r.state)
+	if err != nil {
+		return nil, fmt.Errorf("encoding replica state: %w", err)
+	}
+	var state ReplicaState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("decoding replica state: %w", err)
+	}
+	return &state, nil
+}
+
+// Merge folds a peer's state into this replica and updates the network.
+func (r *SemanticReplica) Merge(remote *ReplicaState) (*MergeReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if remote.ReplicaID == r.state.ReplicaID {
+		return nil, fmt.Errorf("%w: %s", ErrReplicaMismatch, remote.ReplicaID)
+	}
+
+	if remote.Clock > r.state.Clock {
+		r.state.Clock = remote.Clock
+	}
+	if remote.Nodes != nil {
+		r.state.Nodes.Merge(remote.Nodes)
+	}
+	mergeRegisters(r.state.NodeAttributes, remote.NodeAttributes)
+	for nodeID, props := range remote.Properties {
+		if len(props) == 0 {
+			continue
+		}
+		local, ok := r.state.Properties[nodeID]
+		if !ok {
+			local = make(map[string]LWWRegister)
+			r.state.Properties[nodeID] = local
+		}
+		mergeRegisters(local, props)
+	}
+	if remote.Relations != nil {
+		r.state.Relations.Merge(remote.Relations)
+	}
+	mergeRegisters(r.state.RelationAttributes, remote.RelationAttributes)
+	for name, c := range remote.Counters {
+		// State decoded from a peer may hold null counters
+		if c != nil {
+			r.counter(name).Merge(c)
+		}
+	}
+
+	return r.materialize()
+}
+
+// mergeRegisters merges each remote register into the local map. Registers
+// never written, such as nulls in decoded state, are skipped rather than
+// added empty.
+func mergeRegisters(local, remote map[string]LWWRegister) {
+	for id, reg := range remote {
+		if reg.Timestamp == (CRDTTimestamp{}) {
+			continue
+		}
+		merged := local[id]
+		merged.Merge(reg)
+		local[id] = merged
+	}
+}
+
+// SyncReplicas exchanges state between two replicas in both directions.
+// Afterwards both networks hold the same nodes and relations.
+func SyncReplicas(a, b *SemanticReplica) error {
+	stateA, err := a.State()
+	if err != nil {
+		return err
+	}
+	stateB, err := b.State()
+	if err != nil {
+		return err
+	}
+	if _, err := a.Merge(stateB); err != nil {
+		return err
+	}
+	if _, err := b.Merge(stateA); err != nil {
+		return err
+	}
+	return nil
+}
+
+// materialize brings the network in line with the merged state. Caller
+// must hold r.mu.
+func (r *SemanticReplica) materialize() (*MergeReport, error) {
+	report := &MergeReport{Conflicts: make([]string, 0)}
+
+	desiredNodes := make(map[string]*SemanticNode)
+	for _, id := range r.state.Nodes.Elements() {
+		node, err := r.desiredNode(id)
+		if err != nil {
+			return nil, err
+		}
+		desiredNodes[id] = node
+	}
+
+	// Relations first, so removed nodes don't leave dangling edges behind
+	desiredRelations := make(map[string]*SemanticRelation)
+	for _, id := range r.state.Relations.Elements() {
+		rel, err := r.desiredRelation(id)
+		if err != nil {
+			return nil, err
+		}
+		if desiredNodes[rel.SourceID] != nil && desiredNodes[rel.TargetID] != nil {
+			desiredRelations[id] = rel
+		}
+	}
+	for _, rel := range r.network.GetAllRelations() {
+		want, ok := desiredRelations[rel.ID]
+		if !ok || !sameRelation(rel, want) {
+			if err := r.network.RemoveRelation(rel.ID); err == nil {
+				report.RelationsRemoved++
+			}
+		}
+	}
+
+	for _, node := range r.network.GetAllNodes() {
+		if desiredNodes[node.ID] == nil {
+			if err := r.network.RemoveNode(node.ID); err == nil {
+				report.NodesRemoved++
+			}
+		}
+	}
+	ids := make([]string, 0, len(desiredNodes))
+	for id := range desiredNodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		want := desiredNodes[id]
+		current, err := r.network.GetNode(id)
+		if err != nil {
+			if err := r.network.AddNode(want); err != nil {
+				return nil, fmt.Errorf("materializing node %s: %w", id, err)
+			}
+			report.NodesAdded++
+			continue
+		}
+		if !sameNode(current, want) {
+			// Keep local runtime state that is not replicated
+			want.Activation = current.Activation
+			want.BaseActivation = current.BaseActivation
+			want.AccessCount = current.AccessCount
+			want.LastAccessed = current.LastAccessed
+			want.CreatedAt = current.CreatedAt
+			want.Embedding = current.Embedding
+			if err := r.network.UpdateNode(want); err != nil {
+				return nil, fmt.Errorf("materializing node %s: %w", id, err)
+			}
+			report.NodesUpdated++
+		}
+	}
+
+	relIDs := make([]string, 0, len(desiredRelations))
+	for id := range desiredRelations {
+		relIDs = append(relIDs, id)
+	}
+	sort.Strings(relIDs)
+	failed := false
+	for _, id := range relIDs {
+		if _, err := r.network.GetRelation(id); err == nil {
+			continue
+		}
+		if err := r.network.AddRelation(desiredRelations[id]); err != nil {
+			failed = true
+			continue
+		}
+		report.RelationsAdded++
+	}
+
+	// The full set is inconsistent: add everything in ID order so every
+	// replica keeps the same subset regardless of its local history
+	if failed {
+		report.Rebuilt = true
+		for _, rel := range r.network.GetAllRelations() {
+			r.network.RemoveRelation(rel.ID)
+		}
+		report.RelationsAdded = 0
+		for _, id := range relIDs {
+			if err := r.network.AddRelation(desiredRelations[id]); err != nil {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s: %v", id, err))
+				continue
+			}
+			report.RelationsAdded++
+		}
+	}
+	return report, nil
+}
+
+// desiredNode builds a node from its replicated registers.
+func (r *SemanticReplica) desiredNode(id string) (*SemanticNode, error) {
+	var attrs nodeAttributes
+	if reg, ok := r.state.NodeAttributes[id]; ok && reg.Value != nil {
+		if err := json.Unmarshal(reg.Value, &attrs); err != nil {
+			return nil, fmt.Errorf("decoding node %s: %w", id, err)
+		}
+	}
+	node := NewSemanticNode(id, attrs.Label, attrs.Type)
+	node.Confidence = attrs.Confidence
+	node.Source = attrs.Source
+
+	for key, reg := range r.state.Properties[id] {
+		if reg.Deleted || reg.Value == nil {
+			continue
+		}
+		var encoded walValue
+		if err := json.Unmarshal(reg.Value, &encoded); err != nil {
+			return nil, fmt.Errorf("decoding property %s on %s: %w", key, id, err)
+		}
+		value, err := encoded.decode()
+		if err != nil {
+			return nil, fmt.Errorf("decoding property %s on %s: %w", key, id, err)
+		}
+		node.Properties[key] = value
+	}
+	return node, nil
+}
+
+// desiredRelation builds a relation from its replicated register.
+func (r *SemanticReplica) desiredRelation(id string) (*SemanticRelation, error) {
+	var attrs relationAttributes
+	if reg, ok := r.state.RelationAttributes[id]; ok && reg.Value != nil {
+		if err := json.Unmarshal(reg.Value, &attrs); err != nil {
+			return nil, fmt.Errorf("decoding relation %s: %w", id, err)
+		}
+	}
+	rel := NewSemanticRelation(attrs.SourceID, attrs.TargetID, attrs.Type)
+	rel.ID = id
+	rel.Weight = attrs.Weight
+	rel.Confidence = attrs.Confidence
+	rel.Source = attrs.Source
+	return rel, nil
+}
+
+// sameNode compares the replicated fields of two nodes.
+func sameNode(a, b *SemanticNode) bool {
+	if a.Label != b.Label || a.Type != b.Type || a.Confidence != b.Confidence || a.Source != b.Source {
+		return false
+	}
+	if len(a.Properties) != len(b.Properties) {
+		return false
+	}
+	for key, value := range a.Properties {
+		other, ok := b.Properties[key]
+		if !ok || !valuesEqual(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRelation compares the replicated fields of two relations.
+func sameRelation(a, b *SemanticRelation) bool {
+	return a.SourceID == b.SourceID && a.TargetID == b.TargetID && a.Type == b.Type &&
+		a.Weight == b.Weight && a.Confidence == b.Confidence && a.Source == b.Source
+}
@@ -0,0 +1,293 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// CRDT Primitive Tests
+// ============================================================================
+
+func TestLWWRegister_Merge(t *testing.T) {
+	early := LWWRegister{Value: []byte(`1`), Timestamp: CRDTTimestamp{Time: 1, Replica: "eu"}}
+	late := LWWRegister{Value: []byte(`2`), Timestamp: CRDTTimestamp{Time: 2, Replica: "us"}}
+
+	a, b := early, late
+	a.Merge(late)
+	b.Merge(early)
+	if string(a.Value) != "2" || string(b.Value) != "2" {
+		t.Errorf("Expected later write to win on both sides, got %s and %s", a.Value, b.Value)
+	}
+
+	// Equal clocks break ties on replica ID
+	tieA := LWWRegister{Value: []byte(`"a"`), Timestamp: CRDTTimestamp{Time: 5, Replica: "a"}}
+	tieB := LWWRegister{Value: []byte(`"b"`), Timestamp: CRDTTimestamp{Time: 5, Replica: "b"}}
+	tieA.Merge(tieB)
+	if string(tieA.Value) != `"b"` {
+		t.Errorf("Expected higher replica ID to win tie, got %s", tieA.Value)
+	}
+	if tieA.Merge(tieA) {
+		t.Error("Expected merging a register with itself to be a no-op")
+	}
+}
+
+func TestORSet_AddWins(t *testing.T) {
+	a := NewORSet()
+	a.Add("x", "a@1")
+
+	b := NewORSet()
+	b.Merge(a)
+
+	// Concurrently: a removes x, b re-adds it with a fresh tag
+	a.Remove("x")
+	b.Add("x", "b@2")
+
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Contains("x") || !b.Contains("x") {
+		t.Error("Expected concurrent add to win over remove")
+	}
+
+	a.Remove("x")
+	b.Merge(a)
+	if a.Contains("x") || b.Contains("x") {
+		t.Error("Expected remove of all observed tags to delete element")
+	}
+	if len(b.Elements()) != 0 {
+		t.Errorf("Expected no elements, got %v", b.Elements())
+	}
+}
+
+func TestPNCounter_Merge(t *testing.T) {
+	a := NewPNCounter()
+	b := NewPNCounter()
+	a.Increment("a", 5)
+	a.Increment("a", -2)
+	b.Increment("b", 4)
+
+	a.Merge(b)
+	b.Merge(a)
+	if a.Value() != 7 || b.Value() != 7 {
+		t.Errorf("Expected 7 on both replicas, got %d and %d", a.Value(), b.Value())
+	}
+
+	// Idempotent
+	a.Merge(b)
+	if a.Value() != 7 {
+		t.Errorf("Expected repeated merge to keep 7, got %d", a.Value())
+	}
+}
+
+// ============================================================================
+// Semantic Replica Tests
+// ============================================================================
+
+// newTestReplica creates a replica whose clock is driven by the test.
+func newTestReplica(id string, clock *int64) *SemanticReplica {
+	r := NewSemanticReplica(id, NewSemanticNetwork(DefaultSemanticNetworkConfig()))
+	r.now = func() time.Time { return time.Unix(0, *clock) }
+	return r
+}
+
+func TestSemanticReplica_PropertyConvergence(t *testing.T) {
+	var clock int64 = 100
+	eu := newTestReplica("eu", &clock)
+	us := newTestReplica("us", &clock)
+
+	eu.AddNode(NewSemanticNode("apex", "APEX", AgentNode))
+	if err := SyncReplicas(eu, us); err != nil {
+		t.Fatalf("SyncReplicas failed: %v", err)
+	}
+	if _, err := us.Network().GetNode("apex"); err != nil {
+		t.Fatal("Expected node to replicate")
+	}
+
+	// Concurrent writes to the same property; the later one wins everywhere
+	clock = 200
+	eu.SetNodeProperty("apex", "tier", NumberValue(1, ""))
+	clock = 300
+	us.SetNodeProperty("apex", "tier", NumberValue(2, ""))
+	SyncReplicas(eu, us)
+
+	for _, r := range []*SemanticReplica{eu, us} {
+		node, _ := r.Network().GetNode("apex")
+		if !valuesEqual(node.Properties["tier"], NumberValue(2, "")) {
+			t.Errorf("Expected tier 2 on %s, got %v", r.ReplicaID(), node.Properties["tier"])
+		}
+	}
+
+	// A delete after the write removes the property everywhere
+	clock = 400
+	if err := eu.DeleteNodeProperty("apex", "tier"); err != nil {
+		t.Fatalf("DeleteNodeProperty failed: %v", err)
+	}
+	SyncReplicas(eu, us)
+	node, _ := us.Network().GetNode("apex")
+	if _, ok := node.Properties["tier"]; ok {
+		t.Error("Expected deleted property to be removed on peer")
+	}
+}
+
+func TestSemanticReplica_ConcurrentAddRemove(t *testing.T) {
+	var clock int64 = 100
+	eu := newTestReplica("eu", &clock)
+	us := newTestReplica("us", &clock)
+
+	eu.AddNode(NewSemanticNode("sorting", "Sorting", ConceptNode))
+	eu.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	eu.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+	SyncReplicas(eu, us)
+
+	if us.Network().RelationCount() != 1 {
+		t.Fatalf("Expected 1 relation on peer, got %d", us.Network().RelationCount())
+	}
+
+	// eu removes the relation while us keeps it untouched
+	clock = 200
+	if err := eu.RemoveRelation(RelationID("quicksort", IsA, "sorting")); err != nil {
+		t.Fatalf("RemoveRelation failed: %v", err)
+	}
+	report, err := us.Merge(mustState(t, eu))
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if report.RelationsRemoved != 1 {
+		t.Errorf("Expected 1 relation removed, got %d", report.RelationsRemoved)
+	}
+
+	// Concurrently: eu removes the node, us re-adds the relation to it
+	clock = 300
+	eu.RemoveNode("quicksort")
+	clock = 301
+	us.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+	SyncReplicas(eu, us)
+
+	// The node was not re-added, so the relation has nothing to attach to
+	for _, r := range []*SemanticReplica{eu, us} {
+		if _, err := r.Network().GetNode("quicksort"); err == nil {
+			t.Errorf("Expected quicksort removed on %s", r.ReplicaID())
+		}
+		if r.Network().RelationCount() != 0 {
+			t.Errorf("Expected 0 relations on %s, got %d", r.ReplicaID(), r.Network().RelationCount())
+		}
+	}
+}
+
+func TestSemanticReplica_CycleConflict(t *testing.T) {
+	var clock int64 = 100
+	eu := newTestReplica("eu", &clock)
+	us := newTestReplica("us", &clock)
+
+	eu.AddNode(NewSemanticNode("a", "A", ConceptNode))
+	eu.AddNode(NewSemanticNode("b", "B", ConceptNode))
+	SyncReplicas(eu, us)
+
+	// Each region adds one half of an IS-A cycle
+	clock = 200
+	eu.AddRelation(NewSemanticRelation("a", "b", IsA))
+	us.AddRelation(NewSemanticRelation("b", "a", IsA))
+
+	report, err := eu.Merge(mustState(t, us))
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !report.Rebuilt || len(report.Conflicts) != 1 {
+		t.Errorf("Expected rebuild with 1 conflict, got %+v", report)
+	}
+	us.Merge(mustState(t, eu))
+
+	// Both keep the relation that sorts first
+	for _, r := range []*SemanticReplica{eu, us} {
+		if r.Network().RelationCount() != 1 {
+			t.Errorf("Expected 1 relation on %s, got %d", r.ReplicaID(), r.Network().RelationCount())
+		}
+		if _, err := r.Network().GetRelation(RelationID("a", IsA, "b")); err != nil {
+			t.Errorf("Expected a-is-a-b on %s, got %v", r.ReplicaID(), err)
+		}
+	}
+}
+
+func TestSemanticReplica_MergeIdempotent(t *testing.T) {
+	var clock int64 = 100
+	eu := newTestReplica("eu", &clock)
+	us := newTestReplica("us", &clock)
+
+	eu.AddNode(NewSemanticNode("x", "X", ConceptNode))
+	eu.IncrementCounter("queries", 3)
+	us.IncrementCounter("queries", 2)
+
+	state := mustState(t, eu)
+	us.Merge(state)
+	report, err := us.Merge(state)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if report.NodesAdded != 0 || report.NodesUpdated != 0 || report.RelationsAdded != 0 {
+		t.Errorf("Expected repeated merge to change nothing, got %+v", report)
+	}
+	if us.Counter("queries") != 5 {
+		t.Errorf("Expected counter 5, got %d", us.Counter("queries"))
+	}
+	if us.Counter(CounterNodesCreated) != 1 {
+		t.Errorf("Expected 1 node created, got %d", us.Counter(CounterNodesCreated))
+	}
+
+	if _, err := eu.Merge(state); !errors.Is(err, ErrReplicaMismatch) {
+		t.Errorf("Expected ErrReplicaMismatch, got %v", err)
+	}
+}
+
+func TestSemanticReplica_MergeNullState(t *testing.T) {
+	var clock int64 = 100
+	us := newTestReplica("us", &clock)
+	us.IncrementCounter("queries", 2)
+
+	var state ReplicaState
+	data := `{"replica_id":"eu","clock":200,"nodes":null,` +
+		`"node_attributes":{"x":null},"properties":{"x":null,"y":{"p":null}},` +
+		`"counters":{"queries":null,"other":null}}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if _, err := us.Merge(&state); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if us.Counter("queries") != 2 {
+		t.Errorf("Expected counter 2, got %d", us.Counter("queries"))
+	}
+	if _, ok := us.state.NodeAttributes["x"]; ok {
+		t.Error("Expected a null register skipped")
+	}
+	if _, ok := us.state.Properties["y"]["p"]; ok {
+		t.Error("Expected a null property skipped")
+	}
+}
+
+func TestSemanticReplica_ImportsExistingNetwork(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("x", "X", ConceptNode))
+	sn.AddNode(NewSemanticNode("y", "Y", ConceptNode))
+	sn.AddRelation(NewSemanticRelation("x", "y", RelatedTo))
+
+	eu := NewSemanticReplica("eu", sn)
+	us := NewSemanticReplica("us", NewSemanticNetwork(DefaultSemanticNetworkConfig()))
+	SyncReplicas(eu, us)
+
+	if us.Network().NodeCount() != 2 || us.Network().RelationCount() != 1 {
+		t.Errorf("Expected 2 nodes and 1 relation, got %d and %d",
+			us.Network().NodeCount(), us.Network().RelationCount())
+	}
+}
+
+// mustState returns a replica's state or fails the test.
+func mustState(t *testing.T, r *SemanticReplica) *ReplicaState {
+	t.Helper()
+	state, err := r.State()
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	return state
+}
@@ -0,0 +1,264 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements scalable relationship discovery for the Semantic Network.
+//
+// Comparing every pair of nodes is O(n²) and stops being practical long
+// before the network reaches its 100k node limit. Discovery instead
+// generates candidate pairs by blocking:
+// - Embedded nodes: random-hyperplane LSH buckets per embedding dimension
+// - Other nodes: shared IS-A ancestors within a few levels, since Wu-Palmer
+//   similarity is zero without a common ancestor
+//
+// Only candidate pairs are scored, a comparison budget bounds the work per
+// run, and a progress callback lets the consolidation worker report status.
+
+package memory
+
+import (
+	"sort"
+	"time"
+)
+
+// ============================================================================
+// Discovery Options
+// ============================================================================
+
+// DiscoveryOptions configures a relationship discovery run.
+type DiscoveryOptions struct {
+	// MinConfidence scales the confidence of discovered relations
+	MinConfidence float64
+	// Budget caps similarity comparisons per run (0 = unlimited)
+	Budget int
+	// MaxCandidatesPerNode caps LSH candidates considered per node
+	MaxCandidatesPerNode int
+	// AncestorLevels is how many IS-A levels define a structural block
+	AncestorLevels int
+	// MaxBlockSize skips structural blocks larger than this (0 = unlimited);
+	// very broad ancestors such as a root concept carry little signal
+	MaxBlockSize int
+	// SameTypeOnly restricts candidates to pairs of the same NodeType
+	SameTypeOnly bool
+	// Progress is called every ProgressInterval comparisons and once at the end
+	Progress func(DiscoveryProgress)
+	// ProgressInterval is how many comparisons between progress callbacks
+	ProgressInterval int
+}
+
+// DefaultDiscoveryOptions returns sensible defaults.
+func DefaultDiscoveryOptions() DiscoveryOptions {
+	return DiscoveryOptions{
+		MinConfidence:        0.5,
+		Budget:               0,
+		MaxCandidatesPerNode: 50,
+		AncestorLevels:       2,
+		MaxBlockSize:         1000,
+		ProgressInterval:     1000,
+	}
+}
+
+// DiscoveryProgress reports how far a discovery run has got.
+type DiscoveryProgress struct {
+	// Compared is the number of candidate pairs scored so far
+	Compared int
+	// Candidates is the total number of candidate pairs generated
+	Candidates int
+	// Discovered is the number of relations found so far
+	Discovered int
+	// Done is set on the final callback
+	Done bool
+}
+
+// DiscoveryResult holds the outcome of a discovery run.
+type DiscoveryResult struct {
+	Relations []*SemanticRelation
+	// Candidates is the number of candidate pairs generated
+	Candidates int
+	// Compared is the number of pairs actually scored
+	Compared int
+	// Truncated is set when the budget stopped the run early
+	Truncated bool
+	Duration  time.Duration
+}
+
+// ============================================================================
+// Candidate Generation
+// ============================================================================
+
+// discoveryPair is an unordered candidate pair with a < b.
+type discoveryPair struct {
+	a, b string
+}
+
+// newDiscoveryPair orders the IDs so each pair has one key.
+func newDiscoveryPair(x, y string) discoveryPair {
+	if x < y {
+		return discoveryPair{a: x, b: y}
+	}
+	return discoveryPair{a: y, b: x}
+}
+
+// discoveryLSHTables and discoveryLSHFuncs trade recall for bucket size.
+const (
+	discoveryLSHTables = 6
+	discoveryLSHFuncs  = 8
+)
+
+// generateCandidates blocks nodes into buckets and returns the candidate
+// pairs in a deterministic order. Caller must hold cl.network.mu.
+func (cl *ConceptLearner) generateCandidates(opts DiscoveryOptions) []discoveryPair {
+	ids := make([]string, 0, len(cl.network.nodes))
+	for id := range cl.network.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	seen := make(map[discoveryPair]bool)
+	pairs := make([]discoveryPair, 0)
+	addPair := func(x, y string) {
+		if x == y {
+			return
+		}
+		if opts.SameTypeOnly && cl.network.nodes[x].Type != cl.network.nodes[y].Type {
+			return
+		}
+		pair := newDiscoveryPair(x, y)
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	// LSH blocking for embedded nodes, one index per dimension
+	indexes := make(map[int]*LSHIndex)
+	for _, id := range ids {
+		emb := cl.network.nodes[id].Embedding
+		if len(emb) == 0 {
+			continue
+		}
+		index, ok := indexes[len(emb)]
+		if !ok {
+			index = NewLSHIndex(discoveryLSHTables, discoveryLSHFuncs, len(emb))
+			indexes[len(emb)] = index
+		}
+		index.Add(id, emb)
+	}
+	maxCandidates := opts.MaxCandidatesPerNode
+	if maxCandidates <= 0 {
+		maxCandidates = len(ids)
+	}
+	for _, id := range ids {
+		emb := cl.network.nodes[id].Embedding
+		if len(emb) == 0 {
+			continue
+		}
+		for _, other := range indexes[len(emb)].Query(emb, maxCandidates+1) {
+			addPair(id, other)
+		}
+	}
+
+	// Structural blocking on shared ancestors for the remaining nodes
+	blocks := make(map[string][]string)
+	for _, id := range ids {
+		if len(cl.network.nodes[id].Embedding) > 0 {
+			continue
+		}
+		for ancestor, depth := range cl.network.getAncestors(id) {
+			if depth <= opts.AncestorLevels {
+				blocks[ancestor] = append(blocks[ancestor], id)
+			}
+		}
+	}
+	blockKeys := make([]string, 0, len(blocks))
+	for key := range blocks {
+		blockKeys = append(blockKeys, key)
+	}
+	sort.Strings(blockKeys)
+	for _, key := range blockKeys {
+		members := blocks[key]
+		if opts.MaxBlockSize > 0 && len(members) > opts.MaxBlockSize {
+			continue
+		}
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				addPair(members[i], members[j])
+			}
+		}
+	}
+
+	return pairs
+}
+
+// ============================================================================
+// Discovery
+// ============================================================================
+
+// DiscoverRelationshipsWithOptions discovers potential relationships between
+// unconnected nodes using blocked candidate generation.
+func (cl *ConceptLearner) DiscoverRelationshipsWithOptions(opts DiscoveryOptions) *DiscoveryResult {
+	start := time.Now()
+
+	cl.network.mu.RLock()
+	defer cl.network.mu.RUnlock()
+
+	pairs := cl.generateCandidates(opts)
+	result := &DiscoveryResult{
+		Relations:  make([]*SemanticRelation, 0),
+		Candidates: len(pairs),
+	}
+
+	report := func(done bool) {
+		if opts.Progress != nil {
+			opts.Progress(DiscoveryProgress{
+				Compared:   result.Compared,
+				Candidates: result.Candidates,
+				Discovered: len(result.Relations),
+				Done:       done,
+			})
+		}
+	}
+
+	for _, pair := range pairs {
+		if opts.Budget > 0 && result.Compared >= opts.Budget {
+			result.Truncated = true
+			break
+		}
+
+		nodeA := cl.network.nodes[pair.a]
+		nodeB := cl.network.nodes[pair.b]
+		if cl.areConnected(nodeA.ID, nodeB.ID) {
+			continue
+		}
+
+		result.Compared++
+		if opts.ProgressInterval > 0 && result.Compared%opts.ProgressInterval == 0 {
+			report(false)
+		}
+
+		sim := cl.network.computeSimilarity(nodeA, nodeB)
+		if sim.Similarity < cl.similarityThreshold {
+			continue
+		}
+
+		// Orient the pair so the more specific relation type wins
+		relType := cl.inferRelationType(nodeA, nodeB)
+		if relType == RelatedTo {
+			if reversed := cl.inferRelationType(nodeB, nodeA); reversed != RelatedTo {
+				nodeA, nodeB = nodeB, nodeA
+				relType = reversed
+			}
+		}
+		if relType == RelatedTo && sim.Similarity < 0.8 {
+			continue // Only create generic relations for very similar nodes
+		}
+
+		rel := NewSemanticRelation(nodeA.ID, nodeB.ID, relType)
+		rel.Weight = sim.Similarity
+		rel.Confidence = sim.Similarity * opts.MinConfidence
+		rel.Source = "discovered"
+
+		result.Relations = append(result.Relations, rel)
+	}
+
+	result.Duration = time.Since(start)
+	report(true)
+	return result
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Relationship Discovery Tests
+// ============================================================================
+
+// buildClusteredNetwork creates clusters of near-identical embedded concepts.
+func buildClusteredNetwork(clusters, perCluster int) *SemanticNetwork {
+	config := DefaultSemanticNetworkConfig()
+	sn := NewSemanticNetwork(config)
+	for c := 0; c < clusters; c++ {
+		for i := 0; i < perCluster; i++ {
+			id := fmt.Sprintf("c%d_n%d", c, i)
+			node := NewSemanticNode(id, id, ConceptNode)
+			emb := make([]float32, clusters)
+			emb[c] = 1
+			// Small per-node jitter keeps the cluster tight
+			emb[(c+1)%clusters] = float32(i) * 0.01
+			node.Embedding = emb
+			sn.AddNode(node)
+		}
+	}
+	return sn
+}
+
+func TestDiscoverRelationships_FindsSimilarEmbeddedNodes(t *testing.T) {
+	sn := buildClusteredNetwork(4, 3)
+	learner := NewConceptLearner(sn)
+
+	result := learner.DiscoverRelationshipsWithOptions(DefaultDiscoveryOptions())
+
+	if len(result.Relations) == 0 {
+		t.Fatal("Expected relations within clusters")
+	}
+	for _, rel := range result.Relations {
+		if rel.SourceID[:2] != rel.TargetID[:2] {
+			t.Errorf("Unexpected cross-cluster relation %s", rel.ID)
+		}
+		if rel.Type != SimilarTo {
+			t.Errorf("Expected similar-to between concepts, got %s", rel.Type)
+		}
+	}
+
+	// Full pairwise would be 66 pairs; blocking should consider far fewer
+	total := 12 * 11 / 2
+	if result.Candidates >= total {
+		t.Errorf("Expected blocking to prune candidates, got %d of %d", result.Candidates, total)
+	}
+}
+
+func TestDiscoverRelationships_StructuralBlocking(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"root", "algo", "sort", "quick", "merge", "unrelated"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	sn.AddRelation(NewSemanticRelation("algo", "root", IsA))
+	sn.AddRelation(NewSemanticRelation("sort", "algo", IsA))
+	sn.AddRelation(NewSemanticRelation("quick", "sort", IsA))
+	sn.AddRelation(NewSemanticRelation("merge", "sort", IsA))
+
+	learner := NewConceptLearner(sn)
+	result := learner.DiscoverRelationshipsWithOptions(DefaultDiscoveryOptions())
+
+	found := false
+	for _, rel := range result.Relations {
+		if (rel.SourceID == "quick" && rel.TargetID == "merge") ||
+			(rel.SourceID == "merge" && rel.TargetID == "quick") {
+			found = true
+		}
+		if rel.SourceID == "unrelated" || rel.TargetID == "unrelated" {
+			t.Errorf("Unrelated node should not be a candidate: %s", rel.ID)
+		}
+	}
+	if !found {
+		t.Errorf("Expected quick/merge siblings to be related, got %d relations", len(result.Relations))
+	}
+}
+
+func TestDiscoverRelationships_BudgetAndProgress(t *testing.T) {
+	sn := buildClusteredNetwork(2, 20)
+	learner := NewConceptLearner(sn)
+
+	var updates []DiscoveryProgress
+	opts := DefaultDiscoveryOptions()
+	opts.Budget = 10
+	opts.ProgressInterval = 5
+	opts.Progress = func(p DiscoveryProgress) {
+		updates = append(updates, p)
+	}
+
+	result := learner.DiscoverRelationshipsWithOptions(opts)
+
+	if result.Compared != 10 {
+		t.Errorf("Expected budget to cap comparisons at 10, got %d", result.Compared)
+	}
+	if !result.Truncated {
+		t.Error("Expected result to be marked truncated")
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Expected 2 interval updates and 1 final update, got %d", len(updates))
+	}
+	final := updates[len(updates)-1]
+	if !final.Done || final.Compared != 10 || final.Discovered != len(result.Relations) {
+		t.Errorf("Unexpected final progress: %+v", final)
+	}
+}
+
+func TestMemoryConsolidator_RelationshipDiscovery(t *testing.T) {
+	sn := buildClusteredNetwork(2, 3)
+	learner := NewConceptLearner(sn)
+
+	config := DefaultConsolidatorConfig()
+	config.MinClusterSize = 2
+	config.MinTimeSinceLastAccess = time.Minute
+	mc := NewMemoryConsolidator(config)
+	mc.EnableRelationshipDiscovery(learner, DefaultDiscoveryOptions())
+
+	past := time.Now().Add(-2 * time.Minute).UnixNano()
+	for i := 0; i < 3; i++ {
+		mc.AddToBuffer(&ExperienceTuple{
+			Input:          "task",
+			AgentID:        "APEX",
+			Timestamp:      past,
+			LastAccessTime: past,
+			Embedding:      []float32{0.1, 0.2},
+		})
+	}
+
+	before := sn.RelationCount()
+	result, err := mc.Consolidate()
+	if err != nil {
+		t.Fatalf("Consolidate failed: %v", err)
+	}
+	if result.Discovery == nil {
+		t.Fatal("Expected discovery result")
+	}
+
+	added := sn.RelationCount() - before
+	if added == 0 {
+		t.Error("Expected discovered relations to be added to the network")
+	}
+	if mc.GetStats().RelationshipsDiscovered != int64(added) {
+		t.Errorf("Expected stats to count %d relations, got %d", added, mc.GetStats().RelationshipsDiscovered)
+	}
+	if !mc.GetDiscoveryProgress().Done {
+		t.Error("Expected final discovery progress to be recorded")
+	}
+}
+
+func BenchmarkDiscoverRelationships_Blocked(b *testing.B) {
+	sn := buildClusteredNetwork(50, 40)
+	learner := NewConceptLearner(sn)
+	opts := DefaultDiscoveryOptions()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		learner.DiscoverRelationshipsWithOptions(opts)
+	}
+}
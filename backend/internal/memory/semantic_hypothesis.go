@@ -0,0 +1,396 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements hypothesis staging for uncertain learned facts.
+//
+// Relations found by discovery or inference, and concepts extracted by the
+// ConceptLearner, are not always trustworthy. Facts below the confidence
+// threshold are held as hypotheses outside the main graph until they are
+// corroborated, either by being observed again often enough or by an agent
+// confirming them. Hypotheses can be listed, accepted and rejected.
+// Resolved hypotheses are kept up to a limit, the earliest resolved going
+// first; once one goes, its fact can be proposed afresh.
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrHypothesisNotFound indicates the hypothesis doesn't exist
+	ErrHypothesisNotFound = errdefs.New(errdefs.ErrNotFound, "hypothesis not found")
+	// ErrHypothesisResolved indicates the hypothesis was already accepted or rejected
+	ErrHypothesisResolved = errdefs.New(errdefs.ErrConflict, "hypothesis already resolved")
+)
+
+// ============================================================================
+// Hypothesis Types
+// ============================================================================
+
+// HypothesisStatus tracks where a hypothesis is in its lifecycle.
+type HypothesisStatus int
+
+const (
+	// HypothesisPending awaits corroboration
+	HypothesisPending HypothesisStatus = iota
+	// HypothesisAccepted has been committed to the main graph
+	HypothesisAccepted
+	// HypothesisRejected was refuted and will not be committed
+	HypothesisRejected
+)
+
+// String returns the string representation of a HypothesisStatus.
+func (s HypothesisStatus) String() string {
+	switch s {
+	case HypothesisPending:
+		return "pending"
+	case HypothesisAccepted:
+		return "accepted"
+	case HypothesisRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// HypothesisKind distinguishes what a hypothesis would add to the graph.
+type HypothesisKind int
+
+const (
+	// RelationHypothesis proposes a new relation
+	RelationHypothesis HypothesisKind = iota
+	// ConceptHypothesis proposes a learned concept and its instance links
+	ConceptHypothesis
+)
+
+// String returns the string representation of a HypothesisKind.
+func (k HypothesisKind) String() string {
+	switch k {
+	case RelationHypothesis:
+		return "relation"
+	case ConceptHypothesis:
+		return "concept"
+	default:
+		return "unknown"
+	}
+}
+
+// FactHypothesis is an uncertain fact held outside the main graph.
+type FactHypothesis struct {
+	ID   string
+	Kind HypothesisKind
+	// Relation is set for relation hypotheses
+	Relation *SemanticRelation
+	// Concept is set for concept hypotheses
+	Concept *LearnedConcept
+	// Source is where the fact came from (discovered, learned, inferred)
+	Source string
+	// Confidence pools the confidence of every observation
+	Confidence float64
+	// Observations is how many times the fact has been proposed
+	Observations int
+	// Evidence holds the confidence of each observation
+	Evidence []float64
+	Status   HypothesisStatus
+	// ResolvedBy is the agent that accepted or rejected the hypothesis, or
+	// "corroboration" when repeated observations committed it
+	ResolvedBy string
+	// Reason is the rejection reason, if any
+	Reason    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// key identifies the fact in the store's byKey index
+	key string
+}
+
+// HypothesisConfig configures hypothesis staging.
+type HypothesisConfig struct {
+	// ConfidenceThreshold is the confidence at which facts skip staging
+	ConfidenceThreshold float64
+	// RequiredObservations commits a hypothesis once observed this many times
+	RequiredObservations int
+	// MaxResolved bounds the accepted and rejected hypotheses kept; zero
+	// keeps them all
+	MaxResolved int
+}
+
+// DefaultHypothesisConfig returns sensible defaults.
+func DefaultHypothesisConfig() HypothesisConfig {
+	return HypothesisConfig{
+		ConfidenceThreshold:  0.7,
+		RequiredObservations: 3,
+		MaxResolved:          1000,
+	}
+}
+
+// ============================================================================
+// Hypothesis Store
+// ============================================================================
+
+// HypothesisStore stages uncertain facts for a ConceptLearner's network.
+type HypothesisStore struct {
+	mu sync.Mutex
+
+	learner *ConceptLearner
+	config  HypothesisConfig
+	// hypotheses by ID
+	hypotheses map[string]*FactHypothesis
+	// byKey maps a fact's identity to its hypothesis so repeat
+	// observations corroborate instead of duplicating
+	byKey map[string]*FactHypothesis
+	// resolved holds resolved hypothesis IDs, earliest resolved first
+	resolved []string
+}
+
+// NewHypothesisStore creates a hypothesis store that commits accepted facts
+// through the given learner and its network.
+func NewHypothesisStore(learner *ConceptLearner, config HypothesisConfig) *HypothesisStore {
+	return &HypothesisStore{
+		learner:    learner,
+		config:     config,
+		hypotheses: make(map[string]*FactHypothesis),
+		byKey:      make(map[string]*FactHypothesis),
+	}
+}
+
+// ProposeRelation commits a relation directly if it is confident enough,
+// otherwise stages or corroborates a hypothesis for it. Returns nil for
+// direct commits. Proposals of a rejected relation are ignored and return
+// the rejected hypothesis.
+func (hs *HypothesisStore) ProposeRelation(rel *SemanticRelation) (*FactHypothesis, error) {
+	if rel.Confidence >= hs.config.ConfidenceThreshold {
+		return nil, hs.learner.network.AddRelation(rel)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return hs.observe("relation:"+rel.ID, rel.Confidence, func(h *FactHypothesis) {
+		h.Kind = RelationHypothesis
+		h.Relation = rel
+		h.Source = rel.Source
+	})
+}
+
+// ProposeConcept commits a learned concept directly if it is confident
+// enough, otherwise stages or corroborates a hypothesis for it. Concepts
+// are identified by their instances, so re-extracting the same group
+// corroborates the existing hypothesis.
+func (hs *HypothesisStore) ProposeConcept(concept *LearnedConcept) (*FactHypothesis, error) {
+	if concept.Confidence >= hs.config.ConfidenceThreshold {
+		return nil, hs.learner.CommitLearnedConcept(concept)
+	}
+
+	instances := append([]string(nil), concept.Instances...)
+	sort.Strings(instances)
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return hs.observe("concept:"+strings.Join(instances, ","), concept.Confidence, func(h *FactHypothesis) {
+		h.Kind = ConceptHypothesis
+		h.Concept = concept
+		h.Source = "learned"
+	})
+}
+
+// ProposeCompletions stages the predicted relations of an InferCompletion
+// result for nodeID. Returns the hypotheses that were staged or corroborated.
+func (hs *HypothesisStore) ProposeCompletions(nodeID string, result *InferenceResult) ([]*FactHypothesis, error) {
+	predictions, ok := result.Answer.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected completion predictions, got %T", result.Answer)
+	}
+
+	staged := make([]*FactHypothesis, 0, len(predictions))
+	for _, prediction := range predictions {
+		typeName, _ := prediction["type"].(string)
+		relType, ok := parseRelationType(typeName)
+		if !ok {
+			return staged, fmt.Errorf("%w: %s", ErrInvalidRelationType, typeName)
+		}
+		target, _ := prediction["target"].(string)
+		confidence, _ := prediction["confidence"].(float64)
+
+		rel := NewSemanticRelation(nodeID, target, relType)
+		rel.Confidence = confidence
+		rel.Source = "inferred"
+
+		h, err := hs.ProposeRelation(rel)
+		if err != nil {
+			return staged, err
+		}
+		if h != nil {
+			staged = append(staged, h)
+		}
+	}
+	return staged, nil
+}
+
+// observe records one observation of the fact with the given key, creating
+// the hypothesis with init if needed. Caller must hold hs.mu.
+func (hs *HypothesisStore) observe(key string, confidence float64, init func(*FactHypothesis)) (*FactHypothesis, error) {
+	now := time.Now()
+	h, exists := hs.byKey[key]
+	if !exists {
+		h = &FactHypothesis{
+			ID:        NewID("hyp"),
+			Status:    HypothesisPending,
+			CreatedAt: now,
+			key:       key,
+		}
+		init(h)
+		hs.hypotheses[h.ID] = h
+		hs.byKey[key] = h
+	}
+	if h.Status != HypothesisPending {
+		return h, nil
+	}
+
+	h.Observations++
+	h.Evidence = append(h.Evidence, confidence)
+	h.Confidence = hs.learner.network.config.Confidence.Combine(h.Evidence...)
+	h.UpdatedAt = now
+
+	corroborated := hs.config.RequiredObservations > 0 && h.Observations >= hs.config.RequiredObservations
+	if corroborated || h.Confidence >= hs.config.ConfidenceThreshold {
+		if err := hs.commit(h, "corroboration"); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// Accept commits a pending hypothesis on an agent's confirmation.
+func (hs *HypothesisStore) Accept(id, agentID string) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	h, err := hs.pending(id)
+	if err != nil {
+		return err
+	}
+	return hs.commit(h, agentID)
+}
+
+// Reject marks a pending hypothesis as refuted. Later proposals of the same
+// fact are ignored.
+func (hs *HypothesisStore) Reject(id, agentID, reason string) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	h, err := hs.pending(id)
+	if err != nil {
+		return err
+	}
+	h.Status = HypothesisRejected
+	h.ResolvedBy = agentID
+	h.Reason = reason
+	h.UpdatedAt = time.Now()
+	hs.resolve(h)
+	return nil
+}
+
+// pending returns a hypothesis that is still awaiting corroboration.
+// Caller must hold hs.mu.
+func (hs *HypothesisStore) pending(id string) (*FactHypothesis, error) {
+	h, exists := hs.hypotheses[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrHypothesisNotFound, id)
+	}
+	if h.Status != HypothesisPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrHypothesisResolved, id, h.Status)
+	}
+	return h, nil
+}
+
+// commit adds the hypothesis to the main graph with its pooled confidence.
+// A relation that already exists counts as committed. Caller must hold hs.mu.
+func (hs *HypothesisStore) commit(h *FactHypothesis, resolvedBy string) error {
+	switch h.Kind {
+	case RelationHypothesis:
+		h.Relation.Confidence = h.Confidence
+		if err := hs.learner.network.AddRelation(h.Relation); err != nil && !errors.Is(err, ErrRelationAlreadyExists) {
+			return err
+		}
+	case ConceptHypothesis:
+		h.Concept.Confidence = h.Confidence
+		if h.Concept.PrototypeNode != nil {
+			h.Concept.PrototypeNode.Confidence = h.Confidence
+		}
+		if err := hs.learner.CommitLearnedConcept(h.Concept); err != nil {
+			return err
+		}
+	}
+
+	h.Status = HypothesisAccepted
+	h.ResolvedBy = resolvedBy
+	h.UpdatedAt = time.Now()
+	hs.resolve(h)
+	return nil
+}
+
+// resolve records a hypothesis as resolved, dropping the earliest resolved
+// ones over MaxResolved. Caller must hold hs.mu.
+func (hs *HypothesisStore) resolve(h *FactHypothesis) {
+	hs.resolved = append(hs.resolved, h.ID)
+	if hs.config.MaxResolved <= 0 {
+		return
+	}
+	for len(hs.resolved) > hs.config.MaxResolved {
+		if old, ok := hs.hypotheses[hs.resolved[0]]; ok {
+			delete(hs.hypotheses, old.ID)
+			delete(hs.byKey, old.key)
+		}
+		hs.resolved = hs.resolved[1:]
+	}
+}
+
+// Get returns a hypothesis by ID.
+func (hs *HypothesisStore) Get(id string) (*FactHypothesis, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	h, exists := hs.hypotheses[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrHypothesisNotFound, id)
+	}
+	return h, nil
+}
+
+// List returns hypotheses with the given status, oldest first.
+func (hs *HypothesisStore) List(status HypothesisStatus) []*FactHypothesis {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	result := make([]*FactHypothesis, 0)
+	for _, h := range hs.hypotheses {
+		if h.Status == status {
+			result = append(result, h)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// parseRelationType converts a relation type name back to its RelationType.
+func parseRelationType(name string) (RelationType, bool) {
+	for rt := IsA; rt <= BelongsTo; rt++ {
+		if rt.String() == name {
+			return rt, true
+		}
+	}
+	return 0, false
+}
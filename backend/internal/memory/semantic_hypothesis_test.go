@@ -0,0 +1,301 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Hypothesis Staging Tests
+// ============================================================================
+
+// newHypothesisFixture creates a store over a network with nodes a, b, c.
+func newHypothesisFixture() (*SemanticNetwork, *HypothesisStore) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"a", "b", "c"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	store := NewHypothesisStore(NewConceptLearner(sn), DefaultHypothesisConfig())
+	return sn, store
+}
+
+func newUncertainRelation(source, target string, confidence float64) *SemanticRelation {
+	rel := NewSemanticRelation(source, target, RelatedTo)
+	rel.Confidence = confidence
+	rel.Source = "discovered"
+	return rel
+}
+
+func TestHypothesisStore_ConfidentRelationCommitsDirectly(t *testing.T) {
+	sn, store := newHypothesisFixture()
+
+	h, err := store.ProposeRelation(newUncertainRelation("a", "b", 0.9))
+	if err != nil {
+		t.Fatalf("ProposeRelation failed: %v", err)
+	}
+	if h != nil {
+		t.Errorf("Expected no hypothesis for a confident relation, got %s", h.ID)
+	}
+	if sn.RelationCount() != 1 {
+		t.Errorf("Expected relation in graph, got %d relations", sn.RelationCount())
+	}
+}
+
+func TestHypothesisStore_RepeatObservationCorroborates(t *testing.T) {
+	sn, store := newHypothesisFixture()
+
+	var h *FactHypothesis
+	for i := 0; i < 2; i++ {
+		var err error
+		h, err = store.ProposeRelation(newUncertainRelation("a", "b", 0.3))
+		if err != nil {
+			t.Fatalf("ProposeRelation failed: %v", err)
+		}
+	}
+
+	if h.Status != HypothesisPending {
+		t.Fatalf("Expected pending after 2 observations, got %s", h.Status)
+	}
+	if h.Observations != 2 {
+		t.Errorf("Expected 2 observations, got %d", h.Observations)
+	}
+	// Noisy-OR of 0.3, 0.3 = 0.51
+	if h.Confidence < 0.509 || h.Confidence > 0.511 {
+		t.Errorf("Expected pooled confidence 0.51, got %f", h.Confidence)
+	}
+	if sn.RelationCount() != 0 {
+		t.Error("Expected staged relation to stay out of the graph")
+	}
+	if len(store.List(HypothesisPending)) != 1 {
+		t.Errorf("Expected a single pending hypothesis, got %d", len(store.List(HypothesisPending)))
+	}
+
+	// The third observation reaches RequiredObservations
+	store.ProposeRelation(newUncertainRelation("a", "b", 0.1))
+	if h.Status != HypothesisAccepted || h.ResolvedBy != "corroboration" {
+		t.Errorf("Expected accepted by corroboration, got %s by %q", h.Status, h.ResolvedBy)
+	}
+	rel, err := sn.GetRelation(h.Relation.ID)
+	if err != nil {
+		t.Fatalf("Expected committed relation: %v", err)
+	}
+	if rel.Confidence != h.Confidence {
+		t.Errorf("Expected committed confidence %f, got %f", h.Confidence, rel.Confidence)
+	}
+}
+
+func TestHypothesisStore_PooledConfidenceCommits(t *testing.T) {
+	sn, store := newHypothesisFixture()
+
+	store.ProposeRelation(newUncertainRelation("a", "c", 0.5))
+	h, _ := store.ProposeRelation(newUncertainRelation("a", "c", 0.5))
+
+	// Noisy-OR of 0.5, 0.5 = 0.75 crosses the 0.7 threshold
+	if h.Status != HypothesisAccepted {
+		t.Errorf("Expected accepted once pooled confidence crossed the threshold, got %s", h.Status)
+	}
+	if sn.RelationCount() != 1 {
+		t.Errorf("Expected relation in graph, got %d", sn.RelationCount())
+	}
+}
+
+func TestHypothesisStore_AcceptAndReject(t *testing.T) {
+	sn, store := newHypothesisFixture()
+
+	ab, _ := store.ProposeRelation(newUncertainRelation("a", "b", 0.2))
+	bc, _ := store.ProposeRelation(newUncertainRelation("b", "c", 0.2))
+
+	if err := store.Accept(ab.ID, "APEX"); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if ab.Status != HypothesisAccepted || ab.ResolvedBy != "APEX" {
+		t.Errorf("Expected accepted by APEX, got %s by %q", ab.Status, ab.ResolvedBy)
+	}
+	if sn.RelationCount() != 1 {
+		t.Errorf("Expected accepted relation in graph, got %d", sn.RelationCount())
+	}
+
+	if err := store.Reject(bc.ID, "CIPHER", "contradicts spec"); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	if bc.Reason != "contradicts spec" {
+		t.Errorf("Expected rejection reason, got %q", bc.Reason)
+	}
+
+	// Rejected facts are not revived by later observations
+	again, _ := store.ProposeRelation(newUncertainRelation("b", "c", 0.6))
+	if again != bc || again.Status != HypothesisRejected || again.Observations != 1 {
+		t.Errorf("Expected rejected hypothesis to be unchanged, got %+v", again)
+	}
+	if sn.RelationCount() != 1 {
+		t.Error("Expected rejected relation to stay out of the graph")
+	}
+
+	if err := store.Accept(bc.ID, "APEX"); !errors.Is(err, ErrHypothesisResolved) {
+		t.Errorf("Expected ErrHypothesisResolved, got %v", err)
+	}
+	if err := store.Reject("missing", "APEX", ""); !errors.Is(err, ErrHypothesisNotFound) {
+		t.Errorf("Expected ErrHypothesisNotFound, got %v", err)
+	}
+
+	if len(store.List(HypothesisAccepted)) != 1 || len(store.List(HypothesisRejected)) != 1 {
+		t.Error("Expected one accepted and one rejected hypothesis")
+	}
+}
+
+func TestHypothesisStore_MaxResolved(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"a", "b", "c", "d"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	config := DefaultHypothesisConfig()
+	config.MaxResolved = 2
+	store := NewHypothesisStore(NewConceptLearner(sn), config)
+
+	ab, _ := store.ProposeRelation(newUncertainRelation("a", "b", 0.2))
+	bc, _ := store.ProposeRelation(newUncertainRelation("b", "c", 0.2))
+	cd, _ := store.ProposeRelation(newUncertainRelation("c", "d", 0.2))
+	pending, _ := store.ProposeRelation(newUncertainRelation("a", "d", 0.2))
+	store.Reject(ab.ID, "CIPHER", "wrong")
+	store.Accept(bc.ID, "APEX")
+	store.Accept(cd.ID, "APEX")
+
+	if _, err := store.Get(ab.ID); !errors.Is(err, ErrHypothesisNotFound) {
+		t.Errorf("Expected the earliest resolved hypothesis dropped, got %v", err)
+	}
+	for _, h := range []*FactHypothesis{bc, cd, pending} {
+		if _, err := store.Get(h.ID); err != nil {
+			t.Errorf("Expected %s kept, got %v", h.ID, err)
+		}
+	}
+
+	// A dropped rejection no longer keeps its fact out
+	again, _ := store.ProposeRelation(newUncertainRelation("a", "b", 0.2))
+	if again == ab || again.Status != HypothesisPending {
+		t.Errorf("Expected a fresh pending hypothesis, got %+v", again)
+	}
+}
+
+func TestHypothesisStore_ProposeConcept(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	learner := NewConceptLearner(sn)
+	store := NewHypothesisStore(learner, DefaultHypothesisConfig())
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("inst%d", i)
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.SetProperty("kind", "queue")
+		node.SetProperty("size", i)
+		sn.AddNode(node)
+		ids = append(ids, id)
+	}
+	concept, err := learner.ExtractPrototype(ids)
+	if err != nil {
+		t.Fatalf("ExtractPrototype failed: %v", err)
+	}
+
+	h, err := store.ProposeConcept(concept)
+	if err != nil {
+		t.Fatalf("ProposeConcept failed: %v", err)
+	}
+	if h == nil || h.Kind != ConceptHypothesis {
+		t.Fatalf("Expected concept hypothesis for confidence %f", concept.Confidence)
+	}
+	if _, err := sn.GetNode(concept.ID); err == nil {
+		t.Error("Expected staged concept to stay out of the graph")
+	}
+
+	if err := store.Accept(h.ID, "APEX"); err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	if _, err := sn.GetNode(concept.ID); err != nil {
+		t.Errorf("Expected accepted concept in graph: %v", err)
+	}
+	if len(learner.TrackedConcepts()) != 1 {
+		t.Error("Expected accepted concept to be tracked for online learning")
+	}
+}
+
+func TestHypothesisStore_ProposeCompletions(t *testing.T) {
+	_, store := newHypothesisFixture()
+	result := &InferenceResult{
+		Type: InferenceCompletion,
+		Answer: []map[string]interface{}{
+			{"type": "requires", "target": "b", "confidence": 0.4},
+			{"type": "similar-to", "target": "c", "confidence": 0.3},
+		},
+	}
+
+	staged, err := store.ProposeCompletions("a", result)
+	if err != nil {
+		t.Fatalf("ProposeCompletions failed: %v", err)
+	}
+	if len(staged) != 2 {
+		t.Fatalf("Expected 2 staged hypotheses, got %d", len(staged))
+	}
+	if staged[0].Relation.Type != Requires || staged[0].Source != "inferred" {
+		t.Errorf("Unexpected staged relation %+v", staged[0].Relation)
+	}
+
+	result.Answer = []map[string]interface{}{{"type": "bogus", "target": "b"}}
+	if _, err := store.ProposeCompletions("a", result); !errors.Is(err, ErrInvalidRelationType) {
+		t.Errorf("Expected ErrInvalidRelationType, got %v", err)
+	}
+}
+
+func TestMemoryConsolidator_DiscoveryHypotheses(t *testing.T) {
+	sn := buildClusteredNetwork(2, 3)
+	learner := NewConceptLearner(sn)
+
+	config := DefaultConsolidatorConfig()
+	config.MinClusterSize = 2
+	config.MinTimeSinceLastAccess = time.Minute
+	mc := NewMemoryConsolidator(config)
+	mc.EnableRelationshipDiscovery(learner, DefaultDiscoveryOptions())
+	store := NewHypothesisStore(learner, DefaultHypothesisConfig())
+	mc.SetDiscoveryHypotheses(store)
+
+	past := time.Now().Add(-2 * time.Minute).UnixNano()
+	for i := 0; i < 3; i++ {
+		mc.AddToBuffer(&ExperienceTuple{
+			Input:          "task",
+			AgentID:        "APEX",
+			Timestamp:      past,
+			LastAccessTime: past,
+			Embedding:      []float32{0.1, 0.2},
+		})
+	}
+
+	if _, err := mc.Consolidate(); err != nil {
+		t.Fatalf("Consolidate failed: %v", err)
+	}
+
+	// Discovered confidence is similarity * 0.5, below the 0.7 threshold
+	pending := store.List(HypothesisPending)
+	if len(pending) == 0 {
+		t.Fatal("Expected discoveries to be staged")
+	}
+	if sn.RelationCount() != 0 {
+		t.Errorf("Expected no relations committed, got %d", sn.RelationCount())
+	}
+	if mc.GetStats().HypothesesStaged != int64(len(pending)) {
+		t.Errorf("Expected %d staged in stats, got %d", len(pending), mc.GetStats().HypothesesStaged)
+	}
+}
+
+func TestHypothesisStatus_String(t *testing.T) {
+	tests := map[HypothesisStatus]string{
+		HypothesisPending:   "pending",
+		HypothesisAccepted:  "accepted",
+		HypothesisRejected:  "rejected",
+		HypothesisStatus(9): "unknown",
+	}
+	for status, expected := range tests {
+		if status.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, status.String())
+		}
+	}
+}
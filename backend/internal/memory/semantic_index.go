@@ -0,0 +1,252 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the secondary indexes of the Semantic Network.
+//
+// Besides the adjacency lists, the network keeps nodes indexed by NodeType
+// and relations indexed by RelationType. Queries use them to start from the
+// smallest candidate set instead of scanning every node or relation.
+//
+// Property keys listed in SemanticNetworkConfig.IndexedProperties also get a
+// hash index from value to nodes, so equality lookups such as language=go
+// are O(1). The index follows AddNode, UpdateNode, SetNodeProperty and
+// RemoveNode; properties changed directly on a node already in the network
+// are not seen until the node is updated through the network.
+//
+// Node labels and IDs are indexed by their tokenized form, so question
+// answering links entities without scanning every node. Like properties,
+// a label changed in place is seen once the node is updated.
+
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// indexNode adds a node to the type, name and property indexes.
+// Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) indexNode(node *SemanticNode) {
+	bucket, ok := sn.typeIndex[node.Type]
+	if !ok {
+		bucket = make(map[string]*SemanticNode)
+		sn.typeIndex[node.Type] = bucket
+	}
+	bucket[node.ID] = node
+
+	for _, key := range nodeNameKeys(node) {
+		nodes, ok := sn.nameIndex[key]
+		if !ok {
+			nodes = make(map[string]*SemanticNode)
+			sn.nameIndex[key] = nodes
+		}
+		nodes[node.ID] = node
+		sn.nameIndexed[node.ID] = append(sn.nameIndexed[node.ID], key)
+	}
+
+	for key, values := range sn.propertyIndex {
+		raw, ok := node.Properties[key]
+		if !ok {
+			continue
+		}
+		valueKey, ok := propertyIndexKey(raw)
+		if !ok {
+			continue
+		}
+		nodes, ok := values[valueKey]
+		if !ok {
+			nodes = make(map[string]*SemanticNode)
+			values[valueKey] = nodes
+		}
+		nodes[node.ID] = node
+
+		indexed, ok := sn.propertyIndexed[node.ID]
+		if !ok {
+			indexed = make(map[string]string)
+			sn.propertyIndexed[node.ID] = indexed
+		}
+		indexed[key] = valueKey
+	}
+}
+
+// unindexNode removes a node from the type, name and property indexes.
+// Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) unindexNode(node *SemanticNode) {
+	if bucket, ok := sn.typeIndex[node.Type]; ok {
+		delete(bucket, node.ID)
+		if len(bucket) == 0 {
+			delete(sn.typeIndex, node.Type)
+		}
+	}
+
+	for _, key := range sn.nameIndexed[node.ID] {
+		if nodes, ok := sn.nameIndex[key]; ok {
+			delete(nodes, node.ID)
+			if len(nodes) == 0 {
+				delete(sn.nameIndex, key)
+			}
+		}
+	}
+	delete(sn.nameIndexed, node.ID)
+
+	// Use the recorded value keys: the node may have been mutated in place
+	for key, valueKey := range sn.propertyIndexed[node.ID] {
+		values := sn.propertyIndex[key]
+		if nodes, ok := values[valueKey]; ok {
+			delete(nodes, node.ID)
+			if len(nodes) == 0 {
+				delete(values, valueKey)
+			}
+		}
+	}
+	delete(sn.propertyIndexed, node.ID)
+}
+
+// nodeNameKeys returns the distinct tokenized forms of a node's label and ID.
+func nodeNameKeys(node *SemanticNode) []string {
+	keys := make([]string, 0, 2)
+	for _, name := range []string{node.Label, node.ID} {
+		key := strings.Join(tokenizeQuestion(name), " ")
+		if key != "" && (len(keys) == 0 || keys[0] != key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// indexRelation adds a relation to the relation type index.
+// Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) indexRelation(rel *SemanticRelation) {
+	bucket, ok := sn.relationTypeIndex[rel.Type]
+	if !ok {
+		bucket = make(map[string]*SemanticRelation)
+		sn.relationTypeIndex[rel.Type] = bucket
+	}
+	bucket[rel.ID] = rel
+}
+
+// unindexRelation removes a relation from the relation type index.
+// Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) unindexRelation(rel *SemanticRelation) {
+	if bucket, ok := sn.relationTypeIndex[rel.Type]; ok {
+		delete(bucket, rel.ID)
+		if len(bucket) == 0 {
+			delete(sn.relationTypeIndex, rel.Type)
+		}
+	}
+}
+
+// GetRelationsByType returns all relations of a specific type.
+func (sn *SemanticNetwork) GetRelationsByType(relType RelationType) []*SemanticRelation {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	relations := make([]*SemanticRelation, 0, len(sn.relationTypeIndex[relType]))
+	for _, rel := range sn.relationTypeIndex[relType] {
+		relations = append(relations, rel)
+	}
+	return relations
+}
+
+// CountNodesByType returns the number of nodes of a type without copying them.
+func (sn *SemanticNetwork) CountNodesByType(nodeType NodeType) int {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	return len(sn.typeIndex[nodeType])
+}
+
+// CountRelationsByType returns the number of relations of a type without
+// copying them.
+func (sn *SemanticNetwork) CountRelationsByType(relType RelationType) int {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	return len(sn.relationTypeIndex[relType])
+}
+
+// ============================================================================
+// Property Indexes
+// ============================================================================
+
+// newPropertyIndex creates empty buckets for the indexed property keys.
+func newPropertyIndex(keys []string) map[string]map[string]map[string]*SemanticNode {
+	index := make(map[string]map[string]map[string]*SemanticNode, len(keys))
+	for _, key := range keys {
+		index[key] = make(map[string]map[string]*SemanticNode)
+	}
+	return index
+}
+
+// propertyIndexKey returns the hash key for a property value. Values that
+// are Equal share a key: numbers are normalized to their base unit.
+func propertyIndexKey(raw interface{}) (string, bool) {
+	value, ok := AsPropertyValue(raw)
+	if !ok {
+		return "", false
+	}
+	switch value.Kind {
+	case PropertyNumber:
+		base, dimension := normalizeUnit(value.Num, value.Unit)
+		return fmt.Sprintf("%s:%s:%s", value.Kind, strconv.FormatFloat(base, 'g', 12, 64), dimension), true
+	case PropertyDuration:
+		return fmt.Sprintf("%s:%d", value.Kind, value.Duration), true
+	default:
+		return fmt.Sprintf("%s:%s", value.Kind, value.String()), true
+	}
+}
+
+// IsPropertyIndexed returns true if the property key has a hash index.
+func (sn *SemanticNetwork) IsPropertyIndexed(key string) bool {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	_, ok := sn.propertyIndex[key]
+	return ok
+}
+
+// FindNodesByProperty returns the nodes whose direct property equals value.
+// Indexed keys are answered from the hash index; other keys fall back to a
+// scan of all nodes.
+func (sn *SemanticNetwork) FindNodesByProperty(key string, value interface{}) []*SemanticNode {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	return sn.findNodesByProperty(key, value)
+}
+
+// findNodesByProperty looks up nodes by property value. Caller must hold sn.mu.
+func (sn *SemanticNetwork) findNodesByProperty(key string, value interface{}) []*SemanticNode {
+	if values, ok := sn.propertyIndex[key]; ok {
+		valueKey, ok := propertyIndexKey(value)
+		if !ok {
+			return make([]*SemanticNode, 0)
+		}
+		nodes := make([]*SemanticNode, 0, len(values[valueKey]))
+		for _, node := range values[valueKey] {
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	nodes := make([]*SemanticNode, 0)
+	for _, node := range sn.nodes {
+		if raw, ok := node.Properties[key]; ok && valuesEqual(raw, value) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// countNodesByProperty estimates how many nodes have a property value.
+// Returns false if the key is not indexed. Caller must hold sn.mu.
+func (sn *SemanticNetwork) countNodesByProperty(key string, value interface{}) (int, bool) {
+	values, ok := sn.propertyIndex[key]
+	if !ok {
+		return 0, false
+	}
+	valueKey, ok := propertyIndexKey(value)
+	if !ok {
+		return 0, true
+	}
+	return len(values[valueKey]), true
+}
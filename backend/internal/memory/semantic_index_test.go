@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"testing"
+)
+
+// ============================================================================
+// Secondary Index Tests
+// ============================================================================
+
+func TestSemanticNetwork_TypeIndex(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("a", "A", ConceptNode))
+	sn.AddNode(NewSemanticNode("b", "B", ConceptNode))
+	sn.AddNode(NewSemanticNode("c", "C", InstanceNode))
+
+	if sn.CountNodesByType(ConceptNode) != 2 {
+		t.Errorf("Expected 2 concepts, got %d", sn.CountNodesByType(ConceptNode))
+	}
+
+	// Changing a node's type moves it between buckets
+	updated := NewSemanticNode("b", "B", InstanceNode)
+	if err := sn.UpdateNode(updated); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if sn.CountNodesByType(ConceptNode) != 1 || sn.CountNodesByType(InstanceNode) != 2 {
+		t.Errorf("Expected 1 concept and 2 instances, got %d and %d",
+			sn.CountNodesByType(ConceptNode), sn.CountNodesByType(InstanceNode))
+	}
+
+	sn.RemoveNode("c")
+	if nodes := sn.GetNodesByType(InstanceNode); len(nodes) != 1 || nodes[0].ID != "b" {
+		t.Errorf("Expected only b as instance, got %v", nodes)
+	}
+}
+
+func TestSemanticNetwork_RelationTypeIndex(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"a", "b", "c"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	sn.AddRelation(NewSemanticRelation("a", "b", IsA))
+	sn.AddRelation(NewSemanticRelation("b", "c", IsA))
+	sn.AddRelation(NewSemanticRelation("a", "c", Requires))
+
+	if sn.CountRelationsByType(IsA) != 2 {
+		t.Errorf("Expected 2 IS-A relations, got %d", sn.CountRelationsByType(IsA))
+	}
+	if rels := sn.GetRelationsByType(Requires); len(rels) != 1 || rels[0].TargetID != "c" {
+		t.Errorf("Expected a requires c, got %v", rels)
+	}
+
+	// Removing a node drops its relations from the index
+	sn.RemoveNode("b")
+	if sn.CountRelationsByType(IsA) != 0 {
+		t.Errorf("Expected no IS-A relations, got %d", sn.CountRelationsByType(IsA))
+	}
+	if sn.CountRelationsByType(Requires) != 1 {
+		t.Errorf("Expected 1 requires relation, got %d", sn.CountRelationsByType(Requires))
+	}
+}
+
+func TestSemanticNetwork_PropertyIndex(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"language", "latency"}
+	sn := NewSemanticNetwork(config)
+
+	for id, language := range map[string]string{"gin": "go", "chi": "go", "flask": "python"} {
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.SetProperty("language", language)
+		sn.AddNode(node)
+	}
+
+	if !sn.IsPropertyIndexed("language") || sn.IsPropertyIndexed("name") {
+		t.Error("Expected only configured keys to be indexed")
+	}
+	if nodes := sn.FindNodesByProperty("language", "go"); len(nodes) != 2 {
+		t.Errorf("Expected 2 go nodes, got %d", len(nodes))
+	}
+
+	// SetNodeProperty moves the node to its new value
+	if err := sn.SetNodeProperty("flask", "language", "go"); err != nil {
+		t.Fatalf("SetNodeProperty failed: %v", err)
+	}
+	if nodes := sn.FindNodesByProperty("language", "python"); len(nodes) != 0 {
+		t.Errorf("Expected no python nodes, got %d", len(nodes))
+	}
+
+	// UpdateNode with the same node mutated in place still drops the old value
+	node, _ := sn.GetNode("gin")
+	node.SetProperty("language", "rust")
+	if err := sn.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if nodes := sn.FindNodesByProperty("language", "go"); len(nodes) != 2 {
+		t.Errorf("Expected 2 go nodes after update, got %d", len(nodes))
+	}
+	if nodes := sn.FindNodesByProperty("language", "rust"); len(nodes) != 1 || nodes[0].ID != "gin" {
+		t.Errorf("Expected gin as rust node, got %v", nodes)
+	}
+
+	sn.RemoveNode("chi")
+	if nodes := sn.FindNodesByProperty("language", "go"); len(nodes) != 1 {
+		t.Errorf("Expected 1 go node after removal, got %d", len(nodes))
+	}
+}
+
+func TestSemanticNetwork_PropertyIndexUnits(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"latency"}
+	sn := NewSemanticNetwork(config)
+
+	node := NewSemanticNode("api", "API", InstanceNode)
+	node.SetProperty("latency", NumberValue(1, "s"))
+	sn.AddNode(node)
+
+	// Equal values in convertible units share an index key
+	if nodes := sn.FindNodesByProperty("latency", NumberValue(1000, "ms")); len(nodes) != 1 {
+		t.Errorf("Expected 1s to match 1000ms, got %d nodes", len(nodes))
+	}
+	// Unindexed keys fall back to a scan
+	if nodes := sn.FindNodesByProperty("missing", "x"); len(nodes) != 0 {
+		t.Errorf("Expected no nodes, got %d", len(nodes))
+	}
+}
+
+func TestSemanticNetwork_PropertyIndexRestore(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"language"}
+	sn := NewSemanticNetwork(config)
+
+	node := NewSemanticNode("gin", "Gin", InstanceNode)
+	node.SetProperty("language", "go")
+	sn.AddNode(node)
+
+	restored := NewSemanticNetwork(config)
+	if err := restored.Restore(sn.Snapshot()); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if nodes := restored.FindNodesByProperty("language", "go"); len(nodes) != 1 {
+		t.Errorf("Expected restored index to find gin, got %d nodes", len(nodes))
+	}
+}
@@ -0,0 +1,1019 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements exporting and importing the Semantic Network as
+// GraphML, Graphviz DOT and JSON-LD.
+//
+// The formats let the knowledge graph be viewed in tools such as yEd,
+// Gephi and Graphviz, and exchanged with other knowledge-graph systems.
+// Each carries the nodes' labels, types, confidences, sources and creation
+// times, and the relations' types, weights, confidences, sources and
+// creation times. Properties are written as the typed JSON the write-ahead
+// log uses, so they survive a round trip. Embeddings and activation are
+// not exported. Attributes an importer doesn't know, such as a DOT file's
+// colors, are ignored. An import adds the nodes and relations the network
+// doesn't have yet and reports the ones it skipped.
+
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrUnknownGraphFormat is returned for a format other than graphml,
+	// dot or jsonld
+	ErrUnknownGraphFormat = errdefs.New(errdefs.ErrInvalidArgument, "unknown graph format")
+	// ErrInvalidGraph is returned for a graph document that cannot be
+	// imported
+	ErrInvalidGraph = errdefs.New(errdefs.ErrInvalidArgument, "invalid graph document")
+)
+
+// GraphFormat is a graph interchange format.
+type GraphFormat string
+
+const (
+	// GraphFormatGraphML is the XML format of yEd, Gephi and NetworkX
+	GraphFormatGraphML GraphFormat = "graphml"
+	// GraphFormatDOT is the Graphviz language
+	GraphFormatDOT GraphFormat = "dot"
+	// GraphFormatJSONLD is linked data in JSON
+	GraphFormatJSONLD GraphFormat = "jsonld"
+)
+
+// ParseGraphFormat returns the format with a name, ignoring case.
+func ParseGraphFormat(name string) (GraphFormat, error) {
+	switch format := GraphFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case GraphFormatGraphML, GraphFormatDOT, GraphFormatJSONLD:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q is not graphml, dot or jsonld", ErrUnknownGraphFormat, name)
+	}
+}
+
+// ContentType returns the media type of documents in the format.
+func (f GraphFormat) ContentType() string {
+	switch f {
+	case GraphFormatGraphML:
+		return "application/graphml+xml"
+	case GraphFormatDOT:
+		return "text/vnd.graphviz"
+	default:
+		return "application/ld+json"
+	}
+}
+
+// Extension returns the file extension of documents in the format.
+func (f GraphFormat) Extension() string {
+	switch f {
+	case GraphFormatGraphML:
+		return ".graphml"
+	case GraphFormatDOT:
+		return ".dot"
+	default:
+		return ".jsonld"
+	}
+}
+
+// GraphImportSummary reports what an import added.
+type GraphImportSummary struct {
+	Nodes     int `json:"nodes"`
+	Relations int `json:"relations"`
+	// Skipped counts the nodes and relations not added, such as ones the
+	// network already has
+	Skipped int `json:"skipped"`
+	// Reasons explains the first skips
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// maxImportReasons bounds the skip reasons an import reports.
+const maxImportReasons = 20
+
+// skip records a node or relation that was not added.
+func (s *GraphImportSummary) skip(what string, err error) {
+	s.Skipped++
+	if len(s.Reasons) < maxImportReasons {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%s: %v", what, err))
+	}
+}
+
+// Export writes the network in a format. It works on a snapshot, so
+// writers are not held up while a large graph is written.
+func (sn *SemanticNetwork) Export(w io.Writer, format GraphFormat) error {
+	snapshot := sn.Snapshot()
+	sort.Slice(snapshot.Nodes, func(i, j int) bool { return snapshot.Nodes[i].ID < snapshot.Nodes[j].ID })
+	sort.Slice(snapshot.Relations, func(i, j int) bool { return snapshot.Relations[i].ID < snapshot.Relations[j].ID })
+
+	switch format {
+	case GraphFormatGraphML:
+		return writeGraphML(w, snapshot.Nodes, snapshot.Relations)
+	case GraphFormatDOT:
+		return writeDOT(w, snapshot.Nodes, snapshot.Relations)
+	case GraphFormatJSONLD:
+		return writeJSONLD(w, snapshot.Nodes, snapshot.Relations)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownGraphFormat, format)
+	}
+}
+
+// Import reads a document in a format and adds its nodes, then its
+// relations. A document that fails to parse adds nothing. Nodes and
+// relations the network refuses, such as ones it already has or relations
+// that would make a hierarchy cyclic, are skipped.
+func (sn *SemanticNetwork) Import(r io.Reader, format GraphFormat) (*GraphImportSummary, error) {
+	var (
+		nodes     []*SemanticNode
+		relations []*SemanticRelation
+		err       error
+	)
+	switch format {
+	case GraphFormatGraphML:
+		nodes, relations, err = readGraphML(r)
+	case GraphFormatDOT:
+		nodes, relations, err = readDOT(r)
+	case GraphFormatJSONLD:
+		nodes, relations, err = readJSONLD(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGraphFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &GraphImportSummary{}
+	for _, node := range nodes {
+		if err := sn.AddNode(node); err != nil {
+			summary.skip("node "+node.ID, err)
+			continue
+		}
+		summary.Nodes++
+	}
+	for _, rel := range relations {
+		if err := sn.AddRelation(rel); err != nil {
+			summary.skip(fmt.Sprintf("relation %s %s %s", rel.SourceID, rel.Type, rel.TargetID), err)
+			continue
+		}
+		summary.Relations++
+	}
+	return summary, nil
+}
+
+// ============================================================================
+// Attributes
+// ============================================================================
+
+// graphAttr is a named attribute of a node or relation, for the formats
+// that store everything as text.
+type graphAttr struct {
+	Name  string
+	Value string
+}
+
+// formatGraphFloat formats a number as briefly as it round-trips.
+func formatGraphFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeGraphProperties returns properties as typed JSON, or "" for none.
+func encodeGraphProperties(props map[string]interface{}) (string, error) {
+	encoded, err := encodeWALValues(props)
+	if err != nil || encoded == nil {
+		return "", err
+	}
+	raw, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// decodeGraphProperties restores properties written by
+// encodeGraphProperties.
+func decodeGraphProperties(raw string) (map[string]interface{}, error) {
+	if strings.TrimSpace(raw) == "" {
+		return make(map[string]interface{}), nil
+	}
+	var encoded map[string]walValue
+	if err := json.Unmarshal([]byte(raw), &encoded); err != nil {
+		return nil, err
+	}
+	return decodeWALValues(encoded)
+}
+
+// nodeGraphAttrs returns the attributes of a node.
+func nodeGraphAttrs(node *SemanticNode) ([]graphAttr, error) {
+	attrs := []graphAttr{
+		{"label", node.Label},
+		{"type", node.Type.String()},
+		{"confidence", formatGraphFloat(node.Confidence)},
+		{"source", node.Source},
+		{"created_at", node.CreatedAt.UTC().Format(time.RFC3339Nano)},
+	}
+	props, err := encodeGraphProperties(node.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("node %s: %w", node.ID, err)
+	}
+	if props != "" {
+		attrs = append(attrs, graphAttr{"properties", props})
+	}
+	return attrs, nil
+}
+
+// relationGraphAttrs returns the attributes of a relation.
+func relationGraphAttrs(rel *SemanticRelation) ([]graphAttr, error) {
+	attrs := []graphAttr{
+		{"type", rel.Type.String()},
+		{"weight", formatGraphFloat(rel.Weight)},
+		{"confidence", formatGraphFloat(rel.Confidence)},
+		{"source", rel.Source},
+		{"created_at", rel.CreatedAt.UTC().Format(time.RFC3339Nano)},
+	}
+	props, err := encodeGraphProperties(rel.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("relation %s: %w", rel.ID, err)
+	}
+	if props != "" {
+		attrs = append(attrs, graphAttr{"properties", props})
+	}
+	return attrs, nil
+}
+
+// nodeFromGraphAttrs builds a node from its attributes. A missing label is
+// the ID, a missing type a concept and a missing confidence 1.
+func nodeFromGraphAttrs(id string, attrs map[string]string) (*SemanticNode, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: node without an ID", ErrInvalidGraph)
+	}
+	nodeType := ConceptNode
+	if name, ok := attrs["type"]; ok {
+		var known bool
+		if nodeType, known = parseNodeType(name); !known {
+			return nil, fmt.Errorf("%w: node %s has unknown type %q", ErrInvalidGraph, id, name)
+		}
+	}
+	label := attrs["label"]
+	if label == "" {
+		label = id
+	}
+	node := NewSemanticNode(id, label, nodeType)
+	node.Source = "import"
+	if err := applyGraphAttrs(attrs, &node.Confidence, &node.Source, &node.CreatedAt, &node.Properties); err != nil {
+		return nil, fmt.Errorf("%w: node %s: %v", ErrInvalidGraph, id, err)
+	}
+	node.LastAccessed = node.CreatedAt
+	return node, nil
+}
+
+// relationFromGraphAttrs builds a relation from its attributes. The type
+// is taken from the label when missing, as DOT files often only label
+// their edges.
+func relationFromGraphAttrs(source, target string, attrs map[string]string) (*SemanticRelation, error) {
+	name, ok := attrs["type"]
+	if !ok {
+		name = attrs["label"]
+	}
+	relType, known := parseRelationType(name)
+	if !known {
+		return nil, fmt.Errorf("%w: relation %s -> %s has unknown type %q", ErrInvalidGraph, source, target, name)
+	}
+	rel := NewSemanticRelation(source, target, relType)
+	rel.Source = "import"
+	if raw, ok := attrs["weight"]; ok {
+		weight, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: relation %s: weight %q is not a number", ErrInvalidGraph, rel.ID, raw)
+		}
+		rel.Weight = weight
+	}
+	if err := applyGraphAttrs(attrs, &rel.Confidence, &rel.Source, &rel.CreatedAt, &rel.Properties); err != nil {
+		return nil, fmt.Errorf("%w: relation %s: %v", ErrInvalidGraph, rel.ID, err)
+	}
+	return rel, nil
+}
+
+// applyGraphAttrs sets the attributes nodes and relations share.
+func applyGraphAttrs(attrs map[string]string, confidence *float64, source *string, createdAt *time.Time, props *map[string]interface{}) error {
+	if raw, ok := attrs["confidence"]; ok {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("confidence %q is not a number", raw)
+		}
+		*confidence = value
+	}
+	if value := attrs["source"]; value != "" {
+		*source = value
+	}
+	if raw := attrs["created_at"]; raw != "" {
+		value, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return fmt.Errorf("created_at %q is not an RFC 3339 time", raw)
+		}
+		*createdAt = value
+	}
+	if raw, ok := attrs["properties"]; ok {
+		value, err := decodeGraphProperties(raw)
+		if err != nil {
+			return fmt.Errorf("properties: %v", err)
+		}
+		*props = value
+	}
+	return nil
+}
+
+// ============================================================================
+// GraphML
+// ============================================================================
+
+// graphMLNamespace is the namespace of GraphML documents.
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// graphMLDocument is a GraphML file with one graph.
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares an attribute nodes or edges carry.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr,omitempty"`
+	Name string `xml:"attr.name,attr,omitempty"`
+	Type string `xml:"attr.type,attr,omitempty"`
+}
+
+// graphMLGraph holds the nodes and edges.
+type graphMLGraph struct {
+	ID          string           `xml:"id,attr,omitempty"`
+	EdgeDefault string           `xml:"edgedefault,attr,omitempty"`
+	Nodes       []graphMLElement `xml:"node"`
+	Edges       []graphMLElement `xml:"edge"`
+}
+
+// graphMLElement is a node or an edge.
+type graphMLElement struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLData is the value of one attribute.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys are the attributes exported nodes and edges carry; key IDs
+// are the attribute names.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "type", For: "all", Name: "type", Type: "string"},
+	{ID: "weight", For: "edge", Name: "weight", Type: "double"},
+	{ID: "confidence", For: "all", Name: "confidence", Type: "double"},
+	{ID: "source", For: "all", Name: "source", Type: "string"},
+	{ID: "created_at", For: "all", Name: "created_at", Type: "string"},
+	{ID: "properties", For: "all", Name: "properties", Type: "string"},
+}
+
+// graphMLDataOf converts attributes to GraphML data.
+func graphMLDataOf(attrs []graphAttr) []graphMLData {
+	data := make([]graphMLData, 0, len(attrs))
+	for _, attr := range attrs {
+		data = append(data, graphMLData{Key: attr.Name, Value: attr.Value})
+	}
+	return data
+}
+
+// writeGraphML writes nodes and relations as GraphML.
+func writeGraphML(w io.Writer, nodes []*SemanticNode, relations []*SemanticRelation) error {
+	doc := graphMLDocument{
+		XMLNS: graphMLNamespace,
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{
+			ID:          "semantic_network",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLElement, 0, len(nodes)),
+			Edges:       make([]graphMLElement, 0, len(relations)),
+		},
+	}
+	for _, node := range nodes {
+		attrs, err := nodeGraphAttrs(node)
+		if err != nil {
+			return err
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLElement{ID: node.ID, Data: graphMLDataOf(attrs)})
+	}
+	for _, rel := range relations {
+		attrs, err := relationGraphAttrs(rel)
+		if err != nil {
+			return err
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLElement{ID: rel.ID, Source: rel.SourceID, Target: rel.TargetID, Data: graphMLDataOf(attrs)})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// readGraphML reads a GraphML document. Data is matched to attributes by
+// the attr.name its key declares, so files from other tools import too.
+func readGraphML(r io.Reader) ([]*SemanticNode, []*SemanticRelation, error) {
+	var doc graphMLDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGraph, err)
+	}
+	names := make(map[string]string, len(doc.Keys))
+	for _, key := range doc.Keys {
+		names[key.ID] = key.ID
+		if key.Name != "" {
+			names[key.ID] = key.Name
+		}
+	}
+	attrsOf := func(data []graphMLData) map[string]string {
+		attrs := make(map[string]string, len(data))
+		for _, d := range data {
+			if name, ok := names[d.Key]; ok {
+				attrs[name] = strings.TrimSpace(d.Value)
+			}
+		}
+		return attrs
+	}
+
+	nodes := make([]*SemanticNode, 0, len(doc.Graph.Nodes))
+	seen := make(map[string]bool, len(doc.Graph.Nodes))
+	for _, element := range doc.Graph.Nodes {
+		if seen[element.ID] {
+			return nil, nil, fmt.Errorf("%w: node %s is defined twice", ErrInvalidGraph, element.ID)
+		}
+		seen[element.ID] = true
+		node, err := nodeFromGraphAttrs(element.ID, attrsOf(element.Data))
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	relations := make([]*SemanticRelation, 0, len(doc.Graph.Edges))
+	for _, element := range doc.Graph.Edges {
+		rel, err := relationFromGraphAttrs(element.Source, element.Target, attrsOf(element.Data))
+		if err != nil {
+			return nil, nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return nodes, relations, nil
+}
+
+// ============================================================================
+// DOT
+// ============================================================================
+
+// writeDOT writes nodes and relations as a Graphviz digraph. Edges are
+// labelled with their relation type so the rendered graph shows it.
+func writeDOT(w io.Writer, nodes []*SemanticNode, relations []*SemanticRelation) error {
+	var b bytes.Buffer
+	b.WriteString("digraph semantic_network {\n")
+	for _, node := range nodes {
+		attrs, err := nodeGraphAttrs(node)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), dotAttrList(attrs))
+	}
+	for _, rel := range relations {
+		attrs, err := relationGraphAttrs(rel)
+		if err != nil {
+			return err
+		}
+		attrs = append([]graphAttr{{"label", rel.Type.String()}}, attrs...)
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(rel.SourceID), dotQuote(rel.TargetID), dotAttrList(attrs))
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// dotAttrList formats an attribute list without its brackets.
+func dotAttrList(attrs []graphAttr) string {
+	parts := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		parts = append(parts, attr.Name+"="+dotQuote(attr.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dotQuote quotes a DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// dotToken is a token of the DOT language. Quoted and HTML strings are
+// IDs, so they never match a keyword or symbol.
+type dotToken struct {
+	text string
+	id   bool
+}
+
+// tokenizeDOT splits a DOT document into tokens, dropping comments.
+func tokenizeDOT(src string) ([]dotToken, error) {
+	var tokens []dotToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case strings.HasPrefix(src[i:], "->") || strings.HasPrefix(src[i:], "--"):
+			tokens = append(tokens, dotToken{text: src[i : i+2]})
+			i += 2
+		case strings.ContainsRune("{}[]=;,:", rune(c)):
+			tokens = append(tokens, dotToken{text: string(c)})
+			i++
+		case c == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(src) && src[i] != '"'; i++ {
+				if src[i] != '\\' || i+1 == len(src) {
+					b.WriteByte(src[i])
+					continue
+				}
+				i++
+				switch src[i] {
+				case '"', '\\':
+					b.WriteByte(src[i])
+				case 'n':
+					b.WriteByte('\n')
+				case '\n':
+					// A line continuation
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(src[i])
+				}
+			}
+			if i == len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, dotToken{text: b.String(), id: true})
+		case c == '<':
+			depth, start := 0, i
+			for ; i < len(src); i++ {
+				if src[i] == '<' {
+					depth++
+				} else if src[i] == '>' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if i == len(src) {
+				return nil, fmt.Errorf("unterminated HTML string")
+			}
+			i++
+			tokens = append(tokens, dotToken{text: src[start+1 : i-1], id: true})
+		default:
+			start := i
+			for i < len(src) && !unicode.IsSpace(rune(src[i])) && !strings.ContainsRune("{}[]=;,:\"<#", rune(src[i])) &&
+				!strings.HasPrefix(src[i:], "->") && !strings.HasPrefix(src[i:], "--") && !strings.HasPrefix(src[i:], "/*") && !strings.HasPrefix(src[i:], "//") {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			tokens = append(tokens, dotToken{text: src[start:i], id: true})
+		}
+	}
+	return tokens, nil
+}
+
+// dotParser parses the statements of one graph. Subgraphs are not
+// supported.
+type dotParser struct {
+	tokens []dotToken
+	pos    int
+}
+
+// peek returns the next token's text, or "" at the end.
+func (p *dotParser) peek() string {
+	if p.pos == len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+// isKeyword reports whether the next token is an unquoted keyword.
+func (p *dotParser) isKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].id && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+// symbol consumes a symbol if it is next.
+func (p *dotParser) symbol(s string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].id && p.tokens[p.pos].text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// id consumes an ID.
+func (p *dotParser) id() (string, error) {
+	if p.pos == len(p.tokens) || !p.tokens[p.pos].id {
+		return "", fmt.Errorf("expected an ID, got %q", p.peek())
+	}
+	p.pos++
+	return p.tokens[p.pos-1].text, nil
+}
+
+// attrLists consumes any attribute lists, merging them into attrs.
+func (p *dotParser) attrLists(attrs map[string]string) error {
+	for p.symbol("[") {
+		for !p.symbol("]") {
+			name, err := p.id()
+			if err != nil {
+				return err
+			}
+			value := "true"
+			if p.symbol("=") {
+				if value, err = p.id(); err != nil {
+					return err
+				}
+			}
+			attrs[name] = value
+			if !p.symbol(",") {
+				p.symbol(";")
+			}
+		}
+	}
+	return nil
+}
+
+// nodeID consumes a node ID and any port.
+func (p *dotParser) nodeID() (string, error) {
+	id, err := p.id()
+	if err != nil {
+		return "", err
+	}
+	for p.symbol(":") {
+		if _, err := p.id(); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// readDOT reads a DOT graph. Nodes that only appear in edges are imported
+// as concepts labelled with their ID. Undirected edges are imported in the
+// order they are written.
+func readDOT(r io.Reader) ([]*SemanticNode, []*SemanticRelation, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := tokenizeDOT(string(src))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGraph, err)
+	}
+	nodeAttrs, order, edges, err := parseDOT(&dotParser{tokens: tokens})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGraph, err)
+	}
+
+	nodes := make([]*SemanticNode, 0, len(order))
+	for _, id := range order {
+		node, err := nodeFromGraphAttrs(id, nodeAttrs[id])
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	relations := make([]*SemanticRelation, 0, len(edges))
+	for _, edge := range edges {
+		rel, err := relationFromGraphAttrs(edge.source, edge.target, edge.attrs)
+		if err != nil {
+			return nil, nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return nodes, relations, nil
+}
+
+// dotEdge is an edge statement's edge.
+type dotEdge struct {
+	source, target string
+	attrs          map[string]string
+}
+
+// parseDOT parses a graph into its nodes' attributes, the order nodes
+// first appear in, and its edges.
+func parseDOT(p *dotParser) (map[string]map[string]string, []string, []dotEdge, error) {
+	if p.isKeyword("strict") {
+		p.pos++
+	}
+	if !p.isKeyword("digraph") && !p.isKeyword("graph") {
+		return nil, nil, nil, fmt.Errorf("expected digraph or graph, got %q", p.peek())
+	}
+	p.pos++
+	if p.peek() != "{" {
+		if _, err := p.id(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if !p.symbol("{") {
+		return nil, nil, nil, fmt.Errorf("expected {, got %q", p.peek())
+	}
+
+	nodes := make(map[string]map[string]string)
+	var order []string
+	var edges []dotEdge
+	declare := func(id string) map[string]string {
+		if _, ok := nodes[id]; !ok {
+			nodes[id] = make(map[string]string)
+			order = append(order, id)
+		}
+		return nodes[id]
+	}
+
+	for !p.symbol("}") {
+		switch {
+		case p.pos == len(p.tokens):
+			return nil, nil, nil, fmt.Errorf("expected }")
+		case p.symbol(";"):
+			continue
+		case p.isKeyword("subgraph") || p.peek() == "{":
+			return nil, nil, nil, fmt.Errorf("subgraphs are not supported")
+		case (p.isKeyword("graph") || p.isKeyword("node") || p.isKeyword("edge")) && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "[":
+			// Defaults only style the rendering
+			p.pos++
+			if err := p.attrLists(make(map[string]string)); err != nil {
+				return nil, nil, nil, err
+			}
+			continue
+		}
+
+		id, err := p.nodeID()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if p.symbol("=") {
+			// A graph attribute
+			if _, err := p.id(); err != nil {
+				return nil, nil, nil, err
+			}
+			continue
+		}
+		chain := []string{id}
+		for p.symbol("->") || p.symbol("--") {
+			next, err := p.nodeID()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			chain = append(chain, next)
+		}
+		attrs := make(map[string]string)
+		if err := p.attrLists(attrs); err != nil {
+			return nil, nil, nil, err
+		}
+		if len(chain) == 1 {
+			node := declare(id)
+			for name, value := range attrs {
+				node[name] = value
+			}
+			continue
+		}
+		for i := 1; i < len(chain); i++ {
+			declare(chain[i-1])
+			declare(chain[i])
+			edges = append(edges, dotEdge{source: chain[i-1], target: chain[i], attrs: attrs})
+		}
+	}
+	if p.pos != len(p.tokens) {
+		return nil, nil, nil, fmt.Errorf("unexpected %q after the graph", p.peek())
+	}
+	return nodes, order, edges, nil
+}
+
+// ============================================================================
+// JSON-LD
+// ============================================================================
+
+// IRIs of exported JSON-LD.
+const (
+	// jsonLDVocabulary is the vocabulary of types and terms
+	jsonLDVocabulary = "urn:eac:memory:"
+	// jsonLDNodePrefix prefixes node IDs to make them IRIs
+	jsonLDNodePrefix = "urn:eac:node:"
+	// jsonLDRelationType is the type of relation entries
+	jsonLDRelationType = "Relation"
+)
+
+// jsonLDContext maps the exported terms to IRIs. Labels are RDF Schema
+// labels, relations are reified as statements with a subject and an
+// object, and properties are JSON literals.
+var jsonLDContext = map[string]interface{}{
+	"@vocab":     jsonLDVocabulary,
+	"xsd":        "http://www.w3.org/2001/XMLSchema#",
+	"label":      "http://www.w3.org/2000/01/rdf-schema#label",
+	"subject":    map[string]string{"@id": "http://www.w3.org/1999/02/22-rdf-syntax-ns#subject", "@type": "@id"},
+	"object":     map[string]string{"@id": "http://www.w3.org/1999/02/22-rdf-syntax-ns#object", "@type": "@id"},
+	"weight":     map[string]string{"@type": "xsd:double"},
+	"confidence": map[string]string{"@type": "xsd:double"},
+	"created_at": map[string]string{"@type": "xsd:dateTime"},
+	"properties": map[string]string{"@type": "@json"},
+}
+
+// jsonLDDocument is a JSON-LD document with a default graph.
+type jsonLDDocument struct {
+	Context interface{}   `json:"@context,omitempty"`
+	Graph   []jsonLDEntry `json:"@graph"`
+}
+
+// jsonLDEntry is a node, or a relation with @type Relation.
+type jsonLDEntry struct {
+	ID         string              `json:"@id,omitempty"`
+	Type       string              `json:"@type"`
+	Label      string              `json:"label,omitempty"`
+	Relation   string              `json:"relation,omitempty"`
+	Subject    string              `json:"subject,omitempty"`
+	Object     string              `json:"object,omitempty"`
+	Weight     *float64            `json:"weight,omitempty"`
+	Confidence *float64            `json:"confidence,omitempty"`
+	Source     string              `json:"source,omitempty"`
+	CreatedAt  *time.Time          `json:"created_at,omitempty"`
+	Properties map[string]walValue `json:"properties,omitempty"`
+}
+
+// jsonLDNodeIRI returns the IRI of a node.
+func jsonLDNodeIRI(id string) string {
+	return jsonLDNodePrefix + url.PathEscape(id)
+}
+
+// jsonLDNodeID returns the node ID of an IRI; IRIs from other systems are
+// used as they are.
+func jsonLDNodeID(iri string) string {
+	if rest, ok := strings.CutPrefix(iri, jsonLDNodePrefix); ok {
+		if id, err := url.PathUnescape(rest); err == nil {
+			return id
+		}
+	}
+	return iri
+}
+
+// writeJSONLD writes nodes and relations as JSON-LD.
+func writeJSONLD(w io.Writer, nodes []*SemanticNode, relations []*SemanticRelation) error {
+	doc := jsonLDDocument{Context: jsonLDContext, Graph: make([]jsonLDEntry, 0, len(nodes)+len(relations))}
+	for _, node := range nodes {
+		props, err := encodeWALValues(node.Properties)
+		if err != nil {
+			return fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		confidence, createdAt := node.Confidence, node.CreatedAt.UTC()
+		doc.Graph = append(doc.Graph, jsonLDEntry{
+			ID:         jsonLDNodeIRI(node.ID),
+			Type:       node.Type.String(),
+			Label:      node.Label,
+			Confidence: &confidence,
+			Source:     node.Source,
+			CreatedAt:  &createdAt,
+			Properties: props,
+		})
+	}
+	for _, rel := range relations {
+		props, err := encodeWALValues(rel.Properties)
+		if err != nil {
+			return fmt.Errorf("relation %s: %w", rel.ID, err)
+		}
+		weight, confidence, createdAt := rel.Weight, rel.Confidence, rel.CreatedAt.UTC()
+		doc.Graph = append(doc.Graph, jsonLDEntry{
+			Type:       jsonLDRelationType,
+			Relation:   rel.Type.String(),
+			Subject:    jsonLDNodeIRI(rel.SourceID),
+			Object:     jsonLDNodeIRI(rel.TargetID),
+			Weight:     &weight,
+			Confidence: &confidence,
+			Source:     rel.Source,
+			CreatedAt:  &createdAt,
+			Properties: props,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// readJSONLD reads a JSON-LD document shaped like the ones Export writes:
+// compacted with its terms, with types either as terms or as IRIs in its
+// vocabulary.
+func readJSONLD(r io.Reader) ([]*SemanticNode, []*SemanticRelation, error) {
+	var doc jsonLDDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGraph, err)
+	}
+
+	var nodes []*SemanticNode
+	var relations []*SemanticRelation
+	seen := make(map[string]bool)
+	for _, entry := range doc.Graph {
+		attrs := make(map[string]string)
+		if entry.Confidence != nil {
+			attrs["confidence"] = formatGraphFloat(*entry.Confidence)
+		}
+		if entry.Weight != nil {
+			attrs["weight"] = formatGraphFloat(*entry.Weight)
+		}
+		attrs["source"] = entry.Source
+		if entry.CreatedAt != nil {
+			attrs["created_at"] = entry.CreatedAt.Format(time.RFC3339Nano)
+		}
+
+		entryType := strings.TrimPrefix(entry.Type, jsonLDVocabulary)
+		if entryType == jsonLDRelationType {
+			attrs["type"] = entry.Relation
+			rel, err := relationFromGraphAttrs(jsonLDNodeID(entry.Subject), jsonLDNodeID(entry.Object), attrs)
+			if err != nil {
+				return nil, nil, err
+			}
+			if rel.Properties, err = decodeWALValues(entry.Properties); err != nil {
+				return nil, nil, fmt.Errorf("%w: relation %s: %v", ErrInvalidGraph, rel.ID, err)
+			}
+			relations = append(relations, rel)
+			continue
+		}
+
+		id := jsonLDNodeID(entry.ID)
+		if seen[id] {
+			return nil, nil, fmt.Errorf("%w: node %s is defined twice", ErrInvalidGraph, id)
+		}
+		seen[id] = true
+		attrs["type"] = entryType
+		attrs["label"] = entry.Label
+		node, err := nodeFromGraphAttrs(id, attrs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if node.Properties, err = decodeWALValues(entry.Properties); err != nil {
+			return nil, nil, fmt.Errorf("%w: node %s: %v", ErrInvalidGraph, id, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, relations, nil
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// ExportGraph handles GET /memory/semantic/export?format= - downloads the
+// semantic network as GraphML, DOT or JSON-LD (the default).
+func (h *Handler) ExportGraph(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = string(GraphFormatJSONLD)
+	}
+	format, err := ParseGraphFormat(name)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := h.network.Export(&b, format); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="semantic-network`+format.Extension()+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.Bytes())
+}
@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newInterchangeNetwork returns a small network with typed properties and
+// characters the formats must escape.
+func newInterchangeNetwork(t *testing.T) *SemanticNetwork {
+	t.Helper()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sorting := NewSemanticNode("concept:sorting", `Sorting "algorithms" <& co>`, ConceptNode)
+	sorting.SetProperty("complexity", NumberValue(2, ""))
+	sorting.SetProperty("stable", false)
+	sorting.SetProperty("runs", 3)
+	sorting.CreatedAt = created
+	sorting.Confidence = 0.75
+	quicksort := NewSemanticNode("quick sort", "QuickSort\nin place", InstanceNode)
+	quicksort.Source = "seed"
+	quicksort.CreatedAt = created
+	for _, node := range []*SemanticNode{sorting, quicksort} {
+		if err := sn.AddNode(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rel := NewSemanticRelation("quick sort", "concept:sorting", IsA)
+	rel.Weight = 0.5
+	rel.CreatedAt = created
+	rel.Properties["evidence"] = "textbook"
+	if err := sn.AddRelation(rel); err != nil {
+		t.Fatal(err)
+	}
+	return sn
+}
+
+func TestSemanticNetwork_ExportImportRoundTrip(t *testing.T) {
+	for _, format := range []GraphFormat{GraphFormatGraphML, GraphFormatDOT, GraphFormatJSONLD} {
+		var b bytes.Buffer
+		if err := newInterchangeNetwork(t).Export(&b, format); err != nil {
+			t.Fatalf("%s: Export failed: %v", format, err)
+		}
+
+		imported := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+		summary, err := imported.Import(bytes.NewReader(b.Bytes()), format)
+		if err != nil {
+			t.Fatalf("%s: Import failed: %v\n%s", format, err, b.String())
+		}
+		if summary.Nodes != 2 || summary.Relations != 1 || summary.Skipped != 0 {
+			t.Errorf("%s: unexpected summary %+v", format, summary)
+		}
+
+		sorting, err := imported.GetNode("concept:sorting")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if sorting.Label != `Sorting "algorithms" <& co>` || sorting.Type != ConceptNode || sorting.Confidence != 0.75 ||
+			!sorting.CreatedAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("%s: unexpected node %+v", format, sorting)
+		}
+		if !valuesEqual(sorting.Properties["complexity"], NumberValue(2, "")) || sorting.Properties["stable"] != false || sorting.Properties["runs"] != 3 {
+			t.Errorf("%s: expected typed properties, got %#v", format, sorting.Properties)
+		}
+		quicksort, _ := imported.GetNode("quick sort")
+		if quicksort == nil || quicksort.Label != "QuickSort\nin place" || quicksort.Type != InstanceNode || quicksort.Source != "seed" {
+			t.Errorf("%s: unexpected node %+v", format, quicksort)
+		}
+		rel, err := imported.GetRelation(RelationID("quick sort", IsA, "concept:sorting"))
+		if err != nil || rel.Weight != 0.5 || rel.Properties["evidence"] != "textbook" {
+			t.Errorf("%s: unexpected relation %+v (%v)", format, rel, err)
+		}
+	}
+}
+
+func TestSemanticNetwork_ExportFormats(t *testing.T) {
+	sn := newInterchangeNetwork(t)
+	for format, want := range map[GraphFormat]string{
+		GraphFormatGraphML: `<edge id="` + RelationID("quick sort", IsA, "concept:sorting") + `" source="quick sort" target="concept:sorting">`,
+		GraphFormatDOT:     `"quick sort" -> "concept:sorting" [label="is-a", type="is-a", weight="0.5"`,
+		GraphFormatJSONLD:  `"subject": "urn:eac:node:quick%20sort"`,
+	} {
+		var b bytes.Buffer
+		if err := sn.Export(&b, format); err != nil {
+			t.Fatalf("%s: Export failed: %v", format, err)
+		}
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("%s: expected %q in\n%s", format, want, b.String())
+		}
+	}
+	if err := sn.Export(&bytes.Buffer{}, "csv"); !errors.Is(err, ErrUnknownGraphFormat) {
+		t.Errorf("expected ErrUnknownGraphFormat, got %v", err)
+	}
+}
+
+func TestSemanticNetwork_ImportDOT(t *testing.T) {
+	dot := `
+// Drawn by hand
+strict digraph "algorithms" {
+  rankdir=LR
+  node [shape=box, color="grey"];
+  Algorithm [type=concept];
+  /* Nodes only named in edges become concepts */
+  MergeSort -> Sorting -> Algorithm [label="is-a" color=red];
+  MergeSort:n -> Recursion [label=requires];
+  "Divide and conquer";
+}`
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	summary, err := sn.Import(strings.NewReader(dot), GraphFormatDOT)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if summary.Nodes != 5 || summary.Relations != 3 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if !sn.IsA("MergeSort", "Algorithm") {
+		t.Error("expected the is-a chain imported")
+	}
+	node, _ := sn.GetNode("Divide and conquer")
+	if node == nil || node.Label != "Divide and conquer" || node.Type != ConceptNode || node.Source != "import" {
+		t.Errorf("unexpected node %+v", node)
+	}
+	if len(sn.GetRelatedNodes("MergeSort", Requires)) != 1 {
+		t.Error("expected the relation from the port imported")
+	}
+}
+
+func TestSemanticNetwork_ImportGraphML(t *testing.T) {
+	// Keys from another tool, matched by attr.name
+	graphml := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="type" attr.type="string"/>
+  <key id="d2" for="node" attr.name="color" attr.type="string"/>
+  <graph edgedefault="directed">
+    <node id="n0"><data key="d0">Cipher</data><data key="d2">red</data></node>
+    <node id="n1"><data key="d0">Security</data></node>
+    <edge source="n0" target="n1"><data key="d1">belongs-to</data></edge>
+  </graph>
+</graphml>`
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("n1", "Existing", DomainNode))
+	summary, err := sn.Import(strings.NewReader(graphml), GraphFormatGraphML)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if summary.Nodes != 1 || summary.Relations != 1 || summary.Skipped != 1 || !strings.Contains(summary.Reasons[0], "node n1") {
+		t.Errorf("expected the existing node skipped, got %+v", summary)
+	}
+	if node, _ := sn.GetNode("n0"); node == nil || node.Label != "Cipher" || len(node.Properties) != 0 {
+		t.Errorf("expected the unknown color ignored, got %+v", node)
+	}
+	if node, _ := sn.GetNode("n1"); node.Label != "Existing" {
+		t.Errorf("expected the existing node kept, got %+v", node)
+	}
+}
+
+func TestSemanticNetwork_ImportInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		format GraphFormat
+		doc    string
+	}{
+		"bad xml":           {GraphFormatGraphML, "<graphml><graph>"},
+		"unknown node type": {GraphFormatGraphML, `<graphml><key id="t" attr.name="type"/><graph><node id="a"><data key="t">planet</data></node></graph></graphml>`},
+		"unknown relation":  {GraphFormatDOT, `digraph { a -> b [label="orbits"] }`},
+		"subgraph":          {GraphFormatDOT, `digraph { subgraph cluster { a } }`},
+		"unterminated":      {GraphFormatDOT, `digraph { a [label="open] }`},
+		"not a graph":       {GraphFormatDOT, `{ a -> b }`},
+		"bad weight":        {GraphFormatDOT, `digraph { a -> b [type=is-a, weight=heavy] }`},
+		"duplicate node":    {GraphFormatJSONLD, `{"@graph": [{"@id": "a", "@type": "concept"}, {"@id": "a", "@type": "concept"}]}`},
+		"bad json":          {GraphFormatJSONLD, `{"@graph": [`},
+	} {
+		sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+		if _, err := sn.Import(strings.NewReader(tc.doc), tc.format); !errors.Is(err, ErrInvalidGraph) {
+			t.Errorf("%s: expected ErrInvalidGraph, got %v", name, err)
+		}
+		if sn.NodeCount() != 0 {
+			t.Errorf("%s: expected nothing imported", name)
+		}
+	}
+}
+
+func TestSemanticNetwork_ImportJSONLDExpandedTypes(t *testing.T) {
+	doc := `{"@graph": [
+		{"@id": "https://example.com/kg/tls", "@type": "urn:eac:memory:instance", "label": "TLS 1.3"},
+		{"@id": "urn:eac:node:encryption", "@type": "concept"},
+		{"@type": "Relation", "relation": "instance-of", "subject": "https://example.com/kg/tls", "object": "urn:eac:node:encryption"}
+	]}`
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	if _, err := sn.Import(strings.NewReader(doc), GraphFormatJSONLD); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if related := sn.GetRelatedNodes("https://example.com/kg/tls", InstanceOf); len(related) != 1 || related[0].ID != "encryption" {
+		t.Error("expected foreign IRIs kept as IDs and node IRIs unprefixed")
+	}
+}
+
+func TestHandler_ExportGraph(t *testing.T) {
+	h := NewHandler(newInterchangeNetwork(t))
+
+	w := httptest.NewRecorder()
+	h.ExportGraph(w, httptest.NewRequest(http.MethodGet, "/memory/semantic/export", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/ld+json" ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "semantic-network.jsonld") {
+		t.Fatalf("expected JSON-LD by default, got %d %v", w.Code, w.Header())
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || len(doc["@graph"].([]interface{})) != 3 {
+		t.Errorf("expected two nodes and a relation, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ExportGraph(w, httptest.NewRequest(http.MethodGet, "/memory/semantic/export?format=DOT", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/vnd.graphviz" || !strings.HasPrefix(w.Body.String(), "digraph") {
+		t.Errorf("expected a DOT graph, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ExportGraph(w, httptest.NewRequest(http.MethodGet, "/memory/semantic/export?format=gexf", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown format, got %d", w.Code)
+	}
+}
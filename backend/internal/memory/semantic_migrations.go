@@ -0,0 +1,222 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file holds the migrations of the Semantic Network snapshot format.
+//
+// Format history:
+//
+//	1  nodes and relations as Go structs with numeric type codes
+//	2  explicit snake_case records with types stored by name
+//	3  relation IDs derived by RelationID instead of joining source, type
+//	   and target with dashes
+//
+// To change the format, bump it by appending a migration here and updating
+// snapshotPayload; never edit a released migration.
+
+package memory
+
+import (
+	"fmt"
+	"os"
+)
+
+// snapshotMigrations migrates snapshot payloads between format versions.
+var snapshotMigrations = mustMigrator("semantic snapshot", []Migration{
+	{
+		Version:     2,
+		Description: "store node and relation types by name",
+		Up:          snapshotTypesByName,
+		Down:        snapshotTypesByCode,
+	},
+	{
+		Version:     3,
+		Description: "derive relation IDs with collision-safe name-based UUIDs",
+		Up:          snapshotRelationIDsByName,
+		Down:        snapshotRelationIDsJoined,
+	},
+})
+
+// Field names of version 1 records and their version 2 replacements.
+var (
+	snapshotV2NodeFields = map[string]string{
+		"ID": "id", "Label": "label", "Activation": "activation",
+		"BaseActivation": "base_activation", "Embedding": "embedding",
+		"CreatedAt": "created_at", "LastAccessed": "last_accessed",
+		"AccessCount": "access_count", "Confidence": "confidence", "Source": "source",
+	}
+	snapshotV2RelationFields = map[string]string{
+		"ID": "id", "SourceID": "source_id", "TargetID": "target_id",
+		"Weight": "weight", "CreatedAt": "created_at",
+		"Confidence": "confidence", "Source": "source",
+	}
+)
+
+// snapshotTypesByName flattens version 1 entries of the form
+// {"node": {...}, "properties": {...}} into version 2 records.
+func snapshotTypesByName(doc map[string]interface{}) error {
+	flatten := func(wrapper string, fields map[string]string, typeName func(int) string) func(map[string]interface{}) error {
+		return func(entry map[string]interface{}) error {
+			record, ok := entry[wrapper].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("missing %s", wrapper)
+			}
+			code, ok := record["Type"].(float64)
+			name := typeName(int(code))
+			if !ok || name == "unknown" {
+				return fmt.Errorf("unknown %s type %v", wrapper, record["Type"])
+			}
+
+			props := entry["properties"]
+			for key := range entry {
+				delete(entry, key)
+			}
+			for key, value := range record {
+				entry[key] = value
+			}
+			delete(entry, "Type")
+			delete(entry, "Properties")
+			renameFields(entry, fields)
+			entry["type"] = name
+			if props != nil {
+				entry["properties"] = props
+			}
+			return nil
+		}
+	}
+
+	if err := migrateObjects(doc, "nodes", flatten("node", snapshotV2NodeFields,
+		func(code int) string { return NodeType(code).String() })); err != nil {
+		return err
+	}
+	return migrateObjects(doc, "relations", flatten("relation", snapshotV2RelationFields,
+		func(code int) string { return RelationType(code).String() }))
+}
+
+// snapshotTypesByCode reverts version 2 records to version 1 entries.
+func snapshotTypesByCode(doc map[string]interface{}) error {
+	wrap := func(wrapper string, fields map[string]string, typeCode func(string) (int, bool)) func(map[string]interface{}) error {
+		return func(entry map[string]interface{}) error {
+			name, _ := entry["type"].(string)
+			code, ok := typeCode(name)
+			if !ok {
+				return fmt.Errorf("unknown %s type %q", wrapper, name)
+			}
+
+			record := make(map[string]interface{}, len(entry))
+			for key, value := range entry {
+				record[key] = value
+			}
+			props := record["properties"]
+			delete(record, "properties")
+			delete(record, "type")
+			renameFields(record, invertRenames(fields))
+			record["Type"] = code
+			record["Properties"] = nil
+
+			for key := range entry {
+				delete(entry, key)
+			}
+			entry[wrapper] = record
+			if props != nil {
+				entry["properties"] = props
+			}
+			return nil
+		}
+	}
+
+	if err := migrateObjects(doc, "nodes", wrap("node", snapshotV2NodeFields, func(name string) (int, bool) {
+		t, ok := parseNodeType(name)
+		return int(t), ok
+	})); err != nil {
+		return err
+	}
+	return migrateObjects(doc, "relations", wrap("relation", snapshotV2RelationFields, func(name string) (int, bool) {
+		t, ok := parseRelationType(name)
+		return int(t), ok
+	}))
+}
+
+// snapshotRelationIDsByName renames relations whose IDs were derived by
+// joining source, type and target to their RelationID. Relations with other
+// IDs, such as those set by replicas or ingest, keep them.
+func snapshotRelationIDsByName(doc map[string]interface{}) error {
+	return migrateObjects(doc, "relations", func(entry map[string]interface{}) error {
+		source, target, relType := relationRecordParts(entry)
+		if entry["id"] == legacyRelationID(source, relType, target) {
+			entry["id"] = relationIDByName(source, relType, target)
+		}
+		return nil
+	})
+}
+
+// snapshotRelationIDsJoined reverts RelationIDs to the joined form.
+func snapshotRelationIDsJoined(doc map[string]interface{}) error {
+	return migrateObjects(doc, "relations", func(entry map[string]interface{}) error {
+		source, target, relType := relationRecordParts(entry)
+		if entry["id"] == relationIDByName(source, relType, target) {
+			entry["id"] = legacyRelationID(source, relType, target)
+		}
+		return nil
+	})
+}
+
+// relationRecordParts returns the source, target and type name of a
+// version 2 or later relation record.
+func relationRecordParts(entry map[string]interface{}) (source, target, relType string) {
+	source, _ = entry["source_id"].(string)
+	target, _ = entry["target_id"].(string)
+	relType, _ = entry["type"].(string)
+	return source, target, relType
+}
+
+// ============================================================================
+// Snapshot File Migration
+// ============================================================================
+
+// SnapshotMigrationResult describes a snapshot file migration.
+type SnapshotMigrationResult struct {
+	Path   string          `json:"path"`
+	From   int             `json:"from"`
+	To     int             `json:"to"`
+	Steps  []MigrationStep `json:"steps"`
+	DryRun bool            `json:"dry_run"`
+	// Backup is where the original file was copied, if anywhere
+	Backup string `json:"backup,omitempty"`
+}
+
+// LatestSnapshotVersion returns the snapshot format version this build writes.
+func LatestSnapshotVersion() int {
+	return snapshotMigrations.Latest()
+}
+
+// MigrateSnapshotFile rewrites a snapshot file in format version to. A dry
+// run applies the migrations in memory to validate them but writes nothing.
+// With backup set, the original file is kept at <path>.v<from>.bak.
+func MigrateSnapshotFile(path string, to int, dryRun, backup bool) (*SnapshotMigrationResult, error) {
+	file, err := readSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, steps, err := snapshotMigrations.MigrateJSON(file.Payload, file.Version, to)
+	if err != nil {
+		return nil, err
+	}
+	result := &SnapshotMigrationResult{Path: path, From: file.Version, To: to, Steps: steps, DryRun: dryRun}
+	if dryRun || len(steps) == 0 {
+		return result, nil
+	}
+
+	if backup {
+		result.Backup = fmt.Sprintf("%s.v%d.bak", path, file.Version)
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(result.Backup, original, 0o644); err != nil {
+			return nil, fmt.Errorf("%w: backup: %v", ErrPersistenceFailed, err)
+		}
+	}
+	if err := writeSnapshotFile(path, to, raw); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ============================================================================
+// Snapshot Migration Tests
+// ============================================================================
+
+// snapshotV1Fixture is a snapshot payload as written by format version 1.
+const snapshotV1Fixture = `{
+	"nodes": [
+		{"node": {"ID": "sorting", "Label": "Sorting", "Type": 0, "Activation": 0, "BaseActivation": 0.1,
+			"Properties": null, "Embedding": null, "CreatedAt": "2025-01-01T00:00:00Z",
+			"LastAccessed": "2025-01-01T00:00:00Z", "AccessCount": 3, "Confidence": 1, "Source": "seed"},
+		 "properties": {"stable": {"t": "bool", "v": false}}},
+		{"node": {"ID": "quicksort", "Label": "QuickSort", "Type": 1, "Activation": 0, "BaseActivation": 0.1,
+			"Properties": null, "Embedding": null, "CreatedAt": "2025-01-01T00:00:00Z",
+			"LastAccessed": "2025-01-01T00:00:00Z", "AccessCount": 0, "Confidence": 0.9, "Source": ""}}
+	],
+	"relations": [
+		{"relation": {"ID": "quicksort-is-a-sorting", "SourceID": "quicksort", "TargetID": "sorting",
+			"Type": 0, "Weight": 1, "Properties": null, "CreatedAt": "2025-01-01T00:00:00Z",
+			"Confidence": 1, "Source": "seed"}}
+	],
+	"timestamp": "2025-01-01T00:00:00Z",
+	"lsn": 7
+}`
+
+// writeV1Snapshot writes the version 1 fixture to a snapshot file.
+func writeV1Snapshot(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "semantic.snapshot")
+	var compact map[string]interface{}
+	if err := json.Unmarshal([]byte(snapshotV1Fixture), &compact); err != nil {
+		t.Fatalf("Invalid fixture: %v", err)
+	}
+	raw, _ := json.Marshal(compact)
+	if err := writeSnapshotFile(path, 1, raw); err != nil {
+		t.Fatalf("writeSnapshotFile failed: %v", err)
+	}
+	return path
+}
+
+func TestLoadSnapshotFile_MigratesV1(t *testing.T) {
+	snapshot, err := LoadSnapshotFile(writeV1Snapshot(t))
+	if err != nil {
+		t.Fatalf("LoadSnapshotFile failed: %v", err)
+	}
+
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.Restore(snapshot)
+	if sn.NodeCount() != 2 || sn.RelationCount() != 1 {
+		t.Fatalf("Expected 2 nodes and 1 relation, got %d and %d", sn.NodeCount(), sn.RelationCount())
+	}
+	node, _ := sn.GetNode("quicksort")
+	if node.Type != InstanceNode || node.Confidence != 0.9 {
+		t.Errorf("Expected instance node with confidence 0.9, got %v and %v", node.Type, node.Confidence)
+	}
+	// Checked on the snapshot, since GetNode counts an access
+	node = snapshot.Nodes[0]
+	if node.Properties["stable"] != false || node.AccessCount != 3 || node.Source != "seed" {
+		t.Errorf("Expected fields and properties carried over, got %+v", node)
+	}
+	rel, _ := sn.GetRelation(RelationID("quicksort", IsA, "sorting"))
+	if rel == nil || rel.Type != IsA {
+		t.Errorf("Expected is-a relation, got %+v", rel)
+	}
+	if snapshot.LSN != 7 {
+		t.Errorf("Expected LSN 7, got %d", snapshot.LSN)
+	}
+}
+
+func TestMigrateSnapshotFile(t *testing.T) {
+	path := writeV1Snapshot(t)
+	latest := LatestSnapshotVersion()
+
+	// A dry run validates without touching the file
+	result, err := MigrateSnapshotFile(path, latest, true, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Steps) != latest-1 || result.Backup != "" {
+		t.Errorf("Expected %d planned steps and no backup, got %+v", latest-1, result)
+	}
+	if file, _ := readSnapshotFile(path); file.Version != 1 {
+		t.Errorf("Expected file left at version 1, got %d", file.Version)
+	}
+
+	result, err = MigrateSnapshotFile(path, latest, false, true)
+	if err != nil {
+		t.Fatalf("MigrateSnapshotFile failed: %v", err)
+	}
+	if file, _ := readSnapshotFile(path); file.Version != latest {
+		t.Errorf("Expected file at version %d, got %d", latest, file.Version)
+	}
+	if backup, err := readSnapshotFile(result.Backup); err != nil || backup.Version != 1 {
+		t.Errorf("Expected version 1 backup, got %v", err)
+	}
+
+	// Downgrading and upgrading again round-trips the graph
+	if _, err := MigrateSnapshotFile(path, 1, false, false); err != nil {
+		t.Fatalf("Downgrade failed: %v", err)
+	}
+	snapshot, err := LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFile after downgrade failed: %v", err)
+	}
+	if len(snapshot.Nodes) != 2 || len(snapshot.Relations) != 1 || snapshot.Relations[0].Type != IsA {
+		t.Errorf("Expected graph preserved through downgrade, got %d nodes and %d relations", len(snapshot.Nodes), len(snapshot.Relations))
+	}
+
+	if result, _ := MigrateSnapshotFile(path, 1, false, true); len(result.Steps) != 0 || result.Backup != "" {
+		t.Errorf("Expected no-op at the same version, got %+v", result)
+	}
+}
+
+func TestMigrateSnapshotFile_NewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "semantic.snapshot")
+	writeSnapshotFile(path, LatestSnapshotVersion()+1, []byte(`{}`))
+
+	if _, err := LoadSnapshotFile(path); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion loading a newer format, got %v", err)
+	}
+	if _, err := MigrateSnapshotFile(path, 1, false, true); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion migrating a newer format, got %v", err)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.v%d.bak", path, LatestSnapshotVersion()+1)); !os.IsNotExist(err) {
+		t.Error("Expected no backup for a failed migration")
+	}
+}
+
+func TestSnapshotMigration_UnknownType(t *testing.T) {
+	doc := map[string]interface{}{
+		"nodes": []interface{}{map[string]interface{}{"node": map[string]interface{}{"ID": "x", "Type": float64(99)}}},
+	}
+	if err := snapshotTypesByName(doc); err == nil {
+		t.Error("Expected error for an unknown node type code")
+	}
+}
+
+func TestSnapshotMigration_RelationIDs(t *testing.T) {
+	relations := func() []interface{} {
+		return []interface{}{
+			map[string]interface{}{"id": "quick-sort-is-a-sorting", "source_id": "quick-sort", "target_id": "sorting", "type": "is-a"},
+			map[string]interface{}{"id": "imported-1", "source_id": "heap-sort", "target_id": "sorting", "type": "is-a"},
+		}
+	}
+	doc := map[string]interface{}{"relations": relations()}
+
+	if err := snapshotRelationIDsByName(doc); err != nil {
+		t.Fatalf("snapshotRelationIDsByName failed: %v", err)
+	}
+	migrated := doc["relations"].([]interface{})
+	if id := migrated[0].(map[string]interface{})["id"]; id != RelationID("quick-sort", IsA, "sorting") {
+		t.Errorf("Expected joined ID replaced by RelationID, got %v", id)
+	}
+	if id := migrated[1].(map[string]interface{})["id"]; id != "imported-1" {
+		t.Errorf("Expected custom ID kept, got %v", id)
+	}
+
+	if err := snapshotRelationIDsJoined(doc); err != nil {
+		t.Fatalf("snapshotRelationIDsJoined failed: %v", err)
+	}
+	for i, want := range relations() {
+		if got := doc["relations"].([]interface{})[i].(map[string]interface{})["id"]; got != want.(map[string]interface{})["id"] {
+			t.Errorf("Expected %v after downgrade, got %v", want.(map[string]interface{})["id"], got)
+		}
+	}
+}
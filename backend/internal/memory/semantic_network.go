@@ -14,12 +14,15 @@
 package memory
 
 import (
-	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
 // ============================================================================
@@ -28,19 +31,19 @@ import (
 
 var (
 	// ErrNodeNotFound indicates the semantic node doesn't exist
-	ErrNodeNotFound = errors.New("semantic node not found")
+	ErrNodeNotFound = errdefs.New(errdefs.ErrNotFound, "semantic node not found")
 	// ErrNodeAlreadyExists indicates a node with this ID already exists
-	ErrNodeAlreadyExists = errors.New("semantic node already exists")
+	ErrNodeAlreadyExists = errdefs.New(errdefs.ErrConflict, "semantic node already exists")
 	// ErrRelationNotFound indicates the relation doesn't exist
-	ErrRelationNotFound = errors.New("semantic relation not found")
+	ErrRelationNotFound = errdefs.New(errdefs.ErrNotFound, "semantic relation not found")
 	// ErrRelationAlreadyExists indicates this relation already exists
-	ErrRelationAlreadyExists = errors.New("semantic relation already exists")
+	ErrRelationAlreadyExists = errdefs.New(errdefs.ErrConflict, "semantic relation already exists")
 	// ErrInvalidRelationType indicates an unknown relation type
-	ErrInvalidRelationType = errors.New("invalid relation type")
+	ErrInvalidRelationType = errdefs.New(errdefs.ErrInvalidArgument, "invalid relation type")
 	// ErrCyclicHierarchy indicates creating this relation would cause a cycle
-	ErrCyclicHierarchy = errors.New("cyclic hierarchy detected")
+	ErrCyclicHierarchy = errdefs.New(errdefs.ErrConflict, "cyclic hierarchy detected")
 	// ErrSelfRelation indicates a node cannot relate to itself
-	ErrSelfRelation = errors.New("node cannot relate to itself")
+	ErrSelfRelation = errdefs.New(errdefs.ErrInvalidArgument, "node cannot relate to itself")
 )
 
 // ============================================================================
@@ -191,6 +194,10 @@ type SemanticNode struct {
 	Confidence float64
 	// Source indicates where this knowledge came from
 	Source string
+
+	// epoch is the snapshot epoch of the last in-place write; nodes from an
+	// older epoch may be shared with a snapshot and are copied before writing
+	epoch uint64
 }
 
 // NewSemanticNode creates a new semantic node.
@@ -276,7 +283,7 @@ type SemanticRelation struct {
 // NewSemanticRelation creates a new semantic relation.
 func NewSemanticRelation(sourceID, targetID string, relType RelationType) *SemanticRelation {
 	return &SemanticRelation{
-		ID:         fmt.Sprintf("%s-%s-%s", sourceID, relType.String(), targetID),
+		ID:         RelationID(sourceID, relType, targetID),
 		SourceID:   sourceID,
 		TargetID:   targetID,
 		Type:       relType,
@@ -310,6 +317,10 @@ type SemanticNetworkConfig struct {
 	InheritanceDepth int
 	// MinConfidenceThreshold below which facts are considered unreliable
 	MinConfidenceThreshold float64
+	// Confidence defines how confidences chain and combine during inference
+	Confidence ConfidenceCalculus
+	// IndexedProperties are property keys with a hash index for equality lookups
+	IndexedProperties []string
 }
 
 // DefaultSemanticNetworkConfig returns sensible defaults.
@@ -323,6 +334,7 @@ func DefaultSemanticNetworkConfig() SemanticNetworkConfig {
 		MaxSpreadingDepth:      3,
 		InheritanceDepth:       5,
 		MinConfidenceThreshold: 0.3,
+		Confidence:             DefaultConfidenceCalculus(),
 	}
 }
 
@@ -344,11 +356,50 @@ type SemanticNetwork struct {
 	// incoming maps target node ID to its incoming relations
 	incoming map[string][]*SemanticRelation
 
+	// typeIndex maps each node type to its nodes
+	typeIndex map[NodeType]map[string]*SemanticNode
+	// relationTypeIndex maps each relation type to its relations
+	relationTypeIndex map[RelationType]map[string]*SemanticRelation
+	// propertyIndex maps indexed property keys to value keys to nodes
+	propertyIndex map[string]map[string]map[string]*SemanticNode
+	// propertyIndexed records the value key each node was indexed under
+	propertyIndexed map[string]map[string]string
+	// nameIndex maps each tokenized node label and ID to its nodes
+	nameIndex map[string]map[string]*SemanticNode
+	// nameIndexed records the name keys each node was indexed under
+	nameIndexed map[string][]string
+
 	// config holds network configuration
 	config SemanticNetworkConfig
 
 	// stats tracks network statistics
 	stats *SemanticNetworkStats
+
+	// depthMu guards depthCache, which is filled in under the read lock
+	depthMu sync.Mutex
+	// depthCache memoizes IS-A hierarchy depths until the hierarchy changes
+	depthCache map[string]int
+
+	// propertySchemas validates typed properties by key
+	propertySchemas map[string]PropertySchema
+
+	// snapshotEpoch is incremented by each Snapshot; accessed atomically
+	snapshotEpoch uint64
+	// activeSnapshots counts snapshots still copying; accessed atomically
+	activeSnapshots int64
+
+	// wal records mutations before they are applied; nil disables logging
+	wal *WriteAheadLog
+
+	// staleActivation holds nodes whose activation predates a change to
+	// the relations it spread over (see semantic_activation.go)
+	staleActivation map[string]bool
+	// onActivationInvalidated is called with nodes as they go stale
+	onActivationInvalidated func(nodeIDs []string)
+
+	// embedder embeds nodes added without an embedding; nil leaves them
+	// without one (see embedding_service.go)
+	embedder TextEmbedder
 }
 
 // SemanticNetworkStats tracks network performance.
@@ -359,17 +410,27 @@ type SemanticNetworkStats struct {
 	InheritanceQueries int64
 	SpreadingCycles    int64
 	ConceptsLearned    int64
+	NodesCopiedOnWrite int64
 	LastUpdated        time.Time
 }
 
 // NewSemanticNetwork creates a new semantic network.
 func NewSemanticNetwork(config SemanticNetworkConfig) *SemanticNetwork {
 	return &SemanticNetwork{
-		nodes:     make(map[string]*SemanticNode),
-		relations: make(map[string]*SemanticRelation),
-		outgoing:  make(map[string][]*SemanticRelation),
-		incoming:  make(map[string][]*SemanticRelation),
-		config:    config,
+		nodes:             make(map[string]*SemanticNode),
+		relations:         make(map[string]*SemanticRelation),
+		outgoing:          make(map[string][]*SemanticRelation),
+		incoming:          make(map[string][]*SemanticRelation),
+		typeIndex:         make(map[NodeType]map[string]*SemanticNode),
+		relationTypeIndex: make(map[RelationType]map[string]*SemanticRelation),
+		propertyIndex:     newPropertyIndex(config.IndexedProperties),
+		propertyIndexed:   make(map[string]map[string]string),
+		nameIndex:         make(map[string]map[string]*SemanticNode),
+		nameIndexed:       make(map[string][]string),
+		config:            config,
+		depthCache:        make(map[string]int),
+		propertySchemas:   make(map[string]PropertySchema),
+		staleActivation:   make(map[string]bool),
 		stats: &SemanticNetworkStats{
 			LastUpdated: time.Now(),
 		},
@@ -380,23 +441,41 @@ func NewSemanticNetwork(config SemanticNetworkConfig) *SemanticNetwork {
 // Node Management
 // ============================================================================
 
-// AddNode adds a new node to the network.
+// AddNode adds a new node to the network, embedding it first if the
+// network has an embedder and the node has no embedding.
 func (sn *SemanticNetwork) AddNode(node *SemanticNode) error {
+	sn.embedNode(node)
+	return sn.addNode(node)
+}
+
+// addNode adds a node as it is.
+func (sn *SemanticNetwork) addNode(node *SemanticNode) error {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
 
 	if _, exists := sn.nodes[node.ID]; exists {
 		return ErrNodeAlreadyExists
 	}
+	if err := sn.validateProperties(node); err != nil {
+		return err
+	}
 
 	if len(sn.nodes) >= sn.config.MaxNodes {
-		// Evict least recently accessed node
-		sn.evictLRUNode()
+		// Evict least recently accessed node; logged first so replay
+		// removes the same node instead of evicting by replay-time access
+		if err := sn.evictLRUNode(); err != nil {
+			return err
+		}
+	}
+	if err := sn.logNode(WALNodeAdd, node); err != nil {
+		return err
 	}
 
+	node.epoch = atomic.LoadUint64(&sn.snapshotEpoch)
 	sn.nodes[node.ID] = node
 	sn.outgoing[node.ID] = make([]*SemanticRelation, 0)
 	sn.incoming[node.ID] = make([]*SemanticRelation, 0)
+	sn.indexNode(node)
 	sn.stats.NodesCreated++
 	sn.stats.LastUpdated = time.Now()
 
@@ -413,6 +492,7 @@ func (sn *SemanticNetwork) GetNode(id string) (*SemanticNode, error) {
 		return nil, ErrNodeNotFound
 	}
 
+	node = sn.mutableNode(node)
 	node.LastAccessed = time.Now()
 	node.AccessCount++
 
@@ -424,23 +504,35 @@ func (sn *SemanticNetwork) RemoveNode(id string) error {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
 
-	if _, exists := sn.nodes[id]; !exists {
+	node, exists := sn.nodes[id]
+	if !exists {
 		return ErrNodeNotFound
 	}
+	if err := sn.logMutation(&WALRecord{Op: WALNodeRemove, ID: id}); err != nil {
+		return err
+	}
 
 	// Remove all relations involving this node
+	for _, rel := range sn.outgoing[id] {
+		sn.invalidateActivation(rel)
+	}
 	for _, rel := range sn.outgoing[id] {
 		delete(sn.relations, rel.ID)
+		sn.unindexRelation(rel)
 		sn.removeFromIncoming(rel.TargetID, rel.ID)
 	}
 	for _, rel := range sn.incoming[id] {
 		delete(sn.relations, rel.ID)
+		sn.unindexRelation(rel)
 		sn.removeFromOutgoing(rel.SourceID, rel.ID)
 	}
 
+	sn.unindexNode(node)
 	delete(sn.nodes, id)
 	delete(sn.outgoing, id)
 	delete(sn.incoming, id)
+	delete(sn.staleActivation, id)
+	sn.invalidateDepthCache()
 
 	return nil
 }
@@ -450,11 +542,21 @@ func (sn *SemanticNetwork) UpdateNode(node *SemanticNode) error {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
 
-	if _, exists := sn.nodes[node.ID]; !exists {
+	old, exists := sn.nodes[node.ID]
+	if !exists {
 		return ErrNodeNotFound
 	}
+	if err := sn.validateProperties(node); err != nil {
+		return err
+	}
+	if err := sn.logNode(WALNodeUpdate, node); err != nil {
+		return err
+	}
 
+	sn.unindexNode(old)
+	node.epoch = atomic.LoadUint64(&sn.snapshotEpoch)
 	sn.nodes[node.ID] = node
+	sn.indexNode(node)
 	sn.stats.LastUpdated = time.Now()
 
 	return nil
@@ -477,11 +579,9 @@ func (sn *SemanticNetwork) GetNodesByType(nodeType NodeType) []*SemanticNode {
 	sn.mu.RLock()
 	defer sn.mu.RUnlock()
 
-	nodes := make([]*SemanticNode, 0)
-	for _, node := range sn.nodes {
-		if node.Type == nodeType {
-			nodes = append(nodes, node)
-		}
+	nodes := make([]*SemanticNode, 0, len(sn.typeIndex[nodeType]))
+	for _, node := range sn.typeIndex[nodeType] {
+		nodes = append(nodes, node)
 	}
 	return nodes
 }
@@ -501,7 +601,7 @@ func (sn *SemanticNetwork) FindNodesByLabel(query string) []*SemanticNode {
 }
 
 // evictLRUNode removes the least recently used node.
-func (sn *SemanticNetwork) evictLRUNode() {
+func (sn *SemanticNetwork) evictLRUNode() error {
 	var oldest *SemanticNode
 	for _, node := range sn.nodes {
 		if oldest == nil || node.LastAccessed.Before(oldest.LastAccessed) {
@@ -509,17 +609,25 @@ func (sn *SemanticNetwork) evictLRUNode() {
 		}
 	}
 	if oldest != nil {
+		if err := sn.logMutation(&WALRecord{Op: WALNodeRemove, ID: oldest.ID}); err != nil {
+			return err
+		}
 		// Remove relations first
 		for _, rel := range sn.outgoing[oldest.ID] {
 			delete(sn.relations, rel.ID)
+			sn.unindexRelation(rel)
 		}
 		for _, rel := range sn.incoming[oldest.ID] {
 			delete(sn.relations, rel.ID)
+			sn.unindexRelation(rel)
 		}
+		sn.unindexNode(oldest)
 		delete(sn.nodes, oldest.ID)
 		delete(sn.outgoing, oldest.ID)
 		delete(sn.incoming, oldest.ID)
+		sn.invalidateDepthCache()
 	}
+	return nil
 }
 
 // ============================================================================
@@ -560,10 +668,18 @@ func (sn *SemanticNetwork) AddRelation(rel *SemanticRelation) error {
 	if len(sn.outgoing[rel.SourceID]) >= sn.config.MaxRelationsPerNode {
 		return fmt.Errorf("max relations exceeded for node %s", rel.SourceID)
 	}
+	if err := sn.logRelation(WALRelationAdd, rel); err != nil {
+		return err
+	}
 
 	sn.relations[rel.ID] = rel
 	sn.outgoing[rel.SourceID] = append(sn.outgoing[rel.SourceID], rel)
 	sn.incoming[rel.TargetID] = append(sn.incoming[rel.TargetID], rel)
+	sn.indexRelation(rel)
+	if rel.Type.IsInheritable() {
+		sn.invalidateDepthCache()
+	}
+	sn.invalidateActivation(rel)
 	sn.stats.RelationsCreated++
 	sn.stats.LastUpdated = time.Now()
 
@@ -582,6 +698,18 @@ func (sn *SemanticNetwork) GetRelation(id string) (*SemanticRelation, error) {
 	return rel, nil
 }
 
+// GetAllRelations returns all relations in the network.
+func (sn *SemanticNetwork) GetAllRelations() []*SemanticRelation {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	relations := make([]*SemanticRelation, 0, len(sn.relations))
+	for _, rel := range sn.relations {
+		relations = append(relations, rel)
+	}
+	return relations
+}
+
 // RemoveRelation removes a relation.
 func (sn *SemanticNetwork) RemoveRelation(id string) error {
 	sn.mu.Lock()
@@ -591,28 +719,38 @@ func (sn *SemanticNetwork) RemoveRelation(id string) error {
 	if !exists {
 		return ErrRelationNotFound
 	}
+	if err := sn.logMutation(&WALRecord{Op: WALRelationRemove, ID: id}); err != nil {
+		return err
+	}
 
+	sn.invalidateActivation(rel)
 	sn.removeFromOutgoing(rel.SourceID, id)
 	sn.removeFromIncoming(rel.TargetID, id)
 	delete(sn.relations, id)
+	sn.unindexRelation(rel)
+	if rel.Type.IsInheritable() {
+		sn.invalidateDepthCache()
+	}
 
 	return nil
 }
 
-// GetOutgoingRelations returns all relations from a node.
+// GetOutgoingRelations returns all relations from a node. The slice is a
+// copy, since removals shift the network's own list in place.
 func (sn *SemanticNetwork) GetOutgoingRelations(nodeID string) []*SemanticRelation {
 	sn.mu.RLock()
 	defer sn.mu.RUnlock()
 
-	return sn.outgoing[nodeID]
+	return append([]*SemanticRelation(nil), sn.outgoing[nodeID]...)
 }
 
-// GetIncomingRelations returns all relations to a node.
+// GetIncomingRelations returns all relations to a node. The slice is a
+// copy, since removals shift the network's own list in place.
 func (sn *SemanticNetwork) GetIncomingRelations(nodeID string) []*SemanticRelation {
 	sn.mu.RLock()
 	defer sn.mu.RUnlock()
 
-	return sn.incoming[nodeID]
+	return append([]*SemanticRelation(nil), sn.incoming[nodeID]...)
 }
 
 // GetRelatedNodes returns nodes related to a source node by a specific relation type.
@@ -708,31 +846,105 @@ type ActivationResult struct {
 	SpreadPath []string
 	// Iterations is how many spreading cycles occurred
 	Iterations int
+	// Depth and Threshold are the spreading parameters the query ran with
+	Depth     int
+	Threshold float64
+	// Duration is how long spreading took, not counting waiting for the lock
+	Duration time.Duration
+	// Truncated reports that spreading stopped at its time budget before
+	// reaching Depth
+	Truncated bool
+	// Contributions are the amounts each edge passed on, in spreading
+	// order; nil unless requested with SpreadOptions.Contributions
+	Contributions []ActivationContribution
+}
+
+// ActivationContribution is the activation one relation passed from its
+// source to its target in one spreading cycle. A target reached over
+// several relations in a cycle keeps the activation of one of them, so
+// its contributions can sum to more than it gained.
+type ActivationContribution struct {
+	SourceID   string `json:"source"`
+	TargetID   string `json:"target"`
+	RelationID string `json:"relation_id"`
+	// Relation is the relation type's name
+	Relation string  `json:"relation"`
+	Amount   float64 `json:"amount"`
+	// Cycle is the spreading cycle, from 1
+	Cycle int `json:"cycle"`
+}
+
+// SpreadOptions adjusts a spreading activation query.
+type SpreadOptions struct {
+	// Contributions records what each relation passed on, to explain why
+	// nodes became active. It is off by default, since it allocates a
+	// record for every relation that fires in every cycle
+	Contributions bool
+}
+
+// ContributionsTo returns the contributions a node received, largest
+// first.
+func (r *ActivationResult) ContributionsTo(nodeID string) []ActivationContribution {
+	contributions := make([]ActivationContribution, 0)
+	for _, c := range r.Contributions {
+		if c.TargetID == nodeID {
+			contributions = append(contributions, c)
+		}
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Amount > contributions[j].Amount
+	})
+	return contributions
 }
 
 // SpreadActivation performs spreading activation from source nodes.
 func (sn *SemanticNetwork) SpreadActivation(sourceIDs []string, initialActivation float64) *ActivationResult {
+	return sn.SpreadActivationWith(sourceIDs, initialActivation, SpreadOptions{})
+}
+
+// SpreadActivationWith performs spreading activation from source nodes
+// with options.
+func (sn *SemanticNetwork) SpreadActivationWith(sourceIDs []string, initialActivation float64, opts SpreadOptions) *ActivationResult {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
+	return sn.spreadActivation(sourceIDs, initialActivation, sn.config.MaxSpreadingDepth, sn.config.ActivationThreshold, 0, opts)
+}
 
+// spreadActivation spreads activation up to depth cycles, passing on only
+// amounts above threshold. A positive budget stops spreading after the
+// first cycle that ends past it. Callers hold the write lock.
+func (sn *SemanticNetwork) spreadActivation(sourceIDs []string, initialActivation float64, depth int, threshold float64, budget time.Duration, opts SpreadOptions) *ActivationResult {
+	start := time.Now()
 	sn.stats.SpreadingCycles++
 
 	result := &ActivationResult{
 		ActivatedNodes: make(map[string]float64),
 		SpreadPath:     make([]string, 0),
+		Depth:          depth,
+		Threshold:      threshold,
+	}
+	if opts.Contributions {
+		result.Contributions = make([]ActivationContribution, 0)
 	}
+	defer func() { result.Duration = time.Since(start) }()
 
 	// Initialize source nodes
 	for _, id := range sourceIDs {
 		if node, exists := sn.nodes[id]; exists {
+			node = sn.mutableNode(node)
 			node.Activation = initialActivation
+			sn.refreshActivation(id)
 			result.ActivatedNodes[id] = initialActivation
 			result.SpreadPath = append(result.SpreadPath, id)
 		}
 	}
 
 	// Spreading activation loop
-	for depth := 0; depth < sn.config.MaxSpreadingDepth; depth++ {
+	for level := 0; level < depth; level++ {
+		if level > 0 && budget > 0 && time.Since(start) > budget {
+			result.Truncated = true
+			break
+		}
 		result.Iterations++
 		newActivations := make(map[string]float64)
 
@@ -740,9 +952,19 @@ func (sn *SemanticNetwork) SpreadActivation(sourceIDs []string, initialActivatio
 			// Spread to connected nodes
 			for _, rel := range sn.outgoing[nodeID] {
 				spreadAmount := activation * sn.config.SpreadingFactor * rel.Weight
-				if spreadAmount > sn.config.ActivationThreshold {
+				if spreadAmount > threshold {
 					targetNode := sn.nodes[rel.TargetID]
 					if targetNode != nil {
+						if opts.Contributions {
+							result.Contributions = append(result.Contributions, ActivationContribution{
+								SourceID:   nodeID,
+								TargetID:   rel.TargetID,
+								RelationID: rel.ID,
+								Relation:   rel.Type.String(),
+								Amount:     spreadAmount,
+								Cycle:      level + 1,
+							})
+						}
 						newAct := targetNode.Activation + spreadAmount
 						if newAct > 1.0 {
 							newAct = 1.0
@@ -756,7 +978,9 @@ func (sn *SemanticNetwork) SpreadActivation(sourceIDs []string, initialActivatio
 		// Apply new activations
 		for nodeID, newAct := range newActivations {
 			if node, exists := sn.nodes[nodeID]; exists {
+				node = sn.mutableNode(node)
 				node.Activation = newAct
+				sn.refreshActivation(nodeID)
 				if _, already := result.ActivatedNodes[nodeID]; !already {
 					result.SpreadPath = append(result.SpreadPath, nodeID)
 				}
@@ -781,6 +1005,7 @@ func (sn *SemanticNetwork) DecayActivation(elapsed time.Duration) {
 	decayFactor := math.Exp(-sn.config.ActivationDecayRate * elapsed.Seconds())
 
 	for _, node := range sn.nodes {
+		node = sn.mutableNode(node)
 		// Decay towards base activation
 		node.Activation = node.BaseActivation + (node.Activation-node.BaseActivation)*decayFactor
 	}
@@ -792,18 +1017,23 @@ func (sn *SemanticNetwork) ResetActivation() {
 	defer sn.mu.Unlock()
 
 	for _, node := range sn.nodes {
+		node = sn.mutableNode(node)
 		node.Activation = node.BaseActivation
 	}
+	sn.staleActivation = make(map[string]bool)
 }
 
-// GetMostActivated returns the N most activated nodes.
+// GetMostActivated returns the N most activated nodes. Nodes whose
+// activation is stale are left out.
 func (sn *SemanticNetwork) GetMostActivated(n int) []*SemanticNode {
 	sn.mu.RLock()
 	defer sn.mu.RUnlock()
 
 	nodes := make([]*SemanticNode, 0, len(sn.nodes))
-	for _, node := range sn.nodes {
-		nodes = append(nodes, node)
+	for id, node := range sn.nodes {
+		if !sn.staleActivation[id] {
+			nodes = append(nodes, node)
+		}
 	}
 
 	sort.Slice(nodes, func(i, j int) bool {
@@ -834,7 +1064,8 @@ func (sn *SemanticNetwork) GetInheritedProperties(nodeID string) (map[string]*In
 	sn.mu.RLock()
 	defer sn.mu.RUnlock()
 
-	sn.stats.InheritanceQueries++
+	// Concurrent readers share the read lock, so count atomically
+	atomic.AddInt64(&sn.stats.InheritanceQueries, 1)
 
 	node, exists := sn.nodes[nodeID]
 	if !exists {
@@ -856,17 +1087,20 @@ func (sn *SemanticNetwork) GetInheritedProperties(nodeID string) (map[string]*In
 
 	// Traverse inheritance hierarchy
 	visited := make(map[string]bool)
-	sn.collectInheritedProperties(nodeID, properties, visited, 0)
+	sn.collectInheritedProperties(nodeID, properties, visited, 0, 1.0)
 
 	return properties, nil
 }
 
 // collectInheritedProperties recursively collects inherited properties.
+// linkConfidence is the chained confidence of the links walked so far; an
+// inherited property's confidence chains those links with its source node.
 func (sn *SemanticNetwork) collectInheritedProperties(
 	nodeID string,
 	properties map[string]*InheritedProperty,
 	visited map[string]bool,
 	depth int,
+	linkConfidence float64,
 ) {
 	if depth >= sn.config.InheritanceDepth {
 		return
@@ -883,6 +1117,7 @@ func (sn *SemanticNetwork) collectInheritedProperties(
 			if parent == nil {
 				continue
 			}
+			pathConfidence := sn.config.Confidence.Chain(linkConfidence, rel.Confidence)
 
 			// Inherit properties from parent
 			for k, v := range parent.Properties {
@@ -893,13 +1128,13 @@ func (sn *SemanticNetwork) collectInheritedProperties(
 						Value:        v,
 						SourceNodeID: parent.ID,
 						Distance:     depth + 1,
-						Confidence:   parent.Confidence * rel.Confidence,
+						Confidence:   sn.config.Confidence.Chain(pathConfidence, parent.Confidence),
 					}
 				}
 			}
 
 			// Recurse to parent's parents
-			sn.collectInheritedProperties(parent.ID, properties, visited, depth+1)
+			sn.collectInheritedProperties(parent.ID, properties, visited, depth+1, pathConfidence)
 		}
 	}
 }
@@ -981,63 +1216,19 @@ func (sn *SemanticNetwork) collectAncestors(nodeID string, ancestors map[string]
 	}
 }
 
-// FindShortestPath finds the shortest path between two nodes.
+// FindShortestPath finds the shortest path between two nodes, following
+// relations of any type in either direction. Use FindPath for constrained
+// queries.
 func (sn *SemanticNetwork) FindShortestPath(fromID, toID string) ([]*SemanticNode, error) {
-	sn.mu.RLock()
-	defer sn.mu.RUnlock()
-
-	if _, exists := sn.nodes[fromID]; !exists {
-		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, fromID)
-	}
-	if _, exists := sn.nodes[toID]; !exists {
-		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, toID)
-	}
-
-	// BFS for shortest path
-	type pathNode struct {
-		id   string
-		path []string
-	}
-
-	queue := []pathNode{{id: fromID, path: []string{fromID}}}
-	visited := make(map[string]bool)
-	visited[fromID] = true
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		if current.id == toID {
-			// Convert IDs to nodes
-			result := make([]*SemanticNode, len(current.path))
-			for i, id := range current.path {
-				result[i] = sn.nodes[id]
-			}
-			return result, nil
-		}
-
-		// Explore neighbors (both directions)
-		for _, rel := range sn.outgoing[current.id] {
-			if !visited[rel.TargetID] {
-				visited[rel.TargetID] = true
-				newPath := make([]string, len(current.path)+1)
-				copy(newPath, current.path)
-				newPath[len(current.path)] = rel.TargetID
-				queue = append(queue, pathNode{id: rel.TargetID, path: newPath})
-			}
-		}
-		for _, rel := range sn.incoming[current.id] {
-			if !visited[rel.SourceID] {
-				visited[rel.SourceID] = true
-				newPath := make([]string, len(current.path)+1)
-				copy(newPath, current.path)
-				newPath[len(current.path)] = rel.SourceID
-				queue = append(queue, pathNode{id: rel.SourceID, path: newPath})
-			}
-		}
+	path, err := sn.FindPath(PathQuery{
+		FromID:    fromID,
+		ToID:      toID,
+		Direction: PathBoth,
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("no path found between %s and %s", fromID, toID)
+	return path.Nodes, nil
 }
 
 // ============================================================================
@@ -1050,6 +1241,8 @@ type SimilarityResult struct {
 	NodeB      string
 	Similarity float64
 	Method     string
+	// LCA is the lowest common ancestor used by the wu-palmer method
+	LCA string
 }
 
 // ComputeSimilarity computes semantic similarity between two nodes.
@@ -1067,59 +1260,111 @@ func (sn *SemanticNetwork) ComputeSimilarity(nodeA, nodeB string) (*SimilarityRe
 		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeB)
 	}
 
+	return sn.computeSimilarity(a, b), nil
+}
+
+// computeSimilarity scores two nodes. Caller must hold sn.mu.
+func (sn *SemanticNetwork) computeSimilarity(a, b *SemanticNode) *SimilarityResult {
 	result := &SimilarityResult{
-		NodeA: nodeA,
-		NodeB: nodeB,
+		NodeA: a.ID,
+		NodeB: b.ID,
 	}
 
 	// Use embedding similarity if available
 	if len(a.Embedding) > 0 && len(b.Embedding) > 0 {
 		result.Similarity = cosineSimilarityFloat32(a.Embedding, b.Embedding)
 		result.Method = "embedding"
-		return result, nil
+		return result
 	}
 
 	// Fall back to structure-based similarity
-	// Wu-Palmer similarity based on common ancestors
+	// Wu-Palmer: 2*depth(LCA) / (depth(A) + depth(B))
+	lca, lcaDepth := sn.lowestCommonAncestor(a.ID, b.ID)
+	if lca != "" {
+		depthA := sn.getNodeDepth(a.ID)
+		depthB := sn.getNodeDepth(b.ID)
+		result.Similarity = float64(2*lcaDepth) / float64(depthA+depthB)
+		result.LCA = lca
+	}
+	result.Method = "wu-palmer"
+
+	return result
+}
+
+// lowestCommonAncestor returns the deepest node that both nodes reach through
+// IS-A / INSTANCE-OF links, counting each node as its own ancestor.
+// Returns an empty ID when the nodes share no ancestor.
+func (sn *SemanticNetwork) lowestCommonAncestor(nodeA, nodeB string) (string, int) {
 	ancestorsA := sn.getAncestors(nodeA)
+	ancestorsA[nodeA] = 0
 	ancestorsB := sn.getAncestors(nodeB)
+	ancestorsB[nodeB] = 0
 
-	// Find lowest common ancestor (LCA)
-	var lcaDepth int
-	for id, depthA := range ancestorsA {
-		if depthB, ok := ancestorsB[id]; ok {
-			combinedDepth := depthA + depthB
-			if combinedDepth > lcaDepth {
-				lcaDepth = combinedDepth
-			}
+	sn.depthMu.Lock()
+	defer sn.depthMu.Unlock()
+
+	lca := ""
+	lcaDepth := 0
+	for id := range ancestorsA {
+		if _, ok := ancestorsB[id]; !ok {
+			continue
+		}
+		depth := sn.computeNodeDepth(id, make(map[string]bool))
+		// Break ties on ID so the result is deterministic
+		if depth > lcaDepth || (depth == lcaDepth && id < lca) {
+			lca = id
+			lcaDepth = depth
 		}
 	}
+	return lca, lcaDepth
+}
 
-	depthA := sn.getNodeDepth(nodeA)
-	depthB := sn.getNodeDepth(nodeB)
-
-	if depthA+depthB > 0 {
-		result.Similarity = float64(2*lcaDepth) / float64(depthA+depthB+2*lcaDepth)
-	}
-	result.Method = "wu-palmer"
+// getNodeDepth returns the depth of a node in the IS-A hierarchy, counting
+// the node itself so that roots have depth 1. Depths are memoized until the
+// hierarchy changes. Caller must hold sn.mu (read or write).
+func (sn *SemanticNetwork) getNodeDepth(nodeID string) int {
+	sn.depthMu.Lock()
+	defer sn.depthMu.Unlock()
 
-	return result, nil
+	return sn.computeNodeDepth(nodeID, make(map[string]bool))
 }
 
-// getNodeDepth returns the depth of a node in the IS-A hierarchy.
-func (sn *SemanticNetwork) getNodeDepth(nodeID string) int {
-	maxDepth := 0
+// computeNodeDepth fills depthCache for nodeID and its ancestors.
+// Caller must hold sn.depthMu.
+func (sn *SemanticNetwork) computeNodeDepth(nodeID string, visiting map[string]bool) int {
+	if depth, ok := sn.depthCache[nodeID]; ok {
+		return depth
+	}
+	// IS-A and INSTANCE-OF are cycle-checked separately, so a mixed cycle
+	// is still possible; treat the back edge as a root.
+	if visiting[nodeID] {
+		return 1
+	}
+	visiting[nodeID] = true
+
+	maxDepth := 1
 	for _, rel := range sn.outgoing[nodeID] {
-		if rel.Type == IsA || rel.Type == InstanceOf {
-			depth := 1 + sn.getNodeDepth(rel.TargetID)
+		if rel.Type.IsInheritable() {
+			depth := 1 + sn.computeNodeDepth(rel.TargetID, visiting)
 			if depth > maxDepth {
 				maxDepth = depth
 			}
 		}
 	}
+
+	delete(visiting, nodeID)
+	sn.depthCache[nodeID] = maxDepth
 	return maxDepth
 }
 
+// invalidateDepthCache drops memoized depths after a hierarchy change.
+// Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) invalidateDepthCache() {
+	sn.depthMu.Lock()
+	sn.depthCache = make(map[string]int)
+	sn.depthMu.Unlock()
+}
+
 // cosineSimilarityFloat32 computes cosine similarity between two vectors.
 func cosineSimilarityFloat32(a, b []float32) float64 {
 	if len(a) != len(b) {
@@ -1150,13 +1395,19 @@ func (sn *SemanticNetwork) GetStats() *SemanticNetworkStats {
 	defer sn.mu.RUnlock()
 
 	// Return a copy to avoid data races
+	return sn.copyStats()
+}
+
+// copyStats copies the statistics. Caller must hold sn.mu.
+func (sn *SemanticNetwork) copyStats() *SemanticNetworkStats {
 	return &SemanticNetworkStats{
 		NodesCreated:       sn.stats.NodesCreated,
 		RelationsCreated:   sn.stats.RelationsCreated,
 		ActivationQueries:  sn.stats.ActivationQueries,
-		InheritanceQueries: sn.stats.InheritanceQueries,
+		InheritanceQueries: atomic.LoadInt64(&sn.stats.InheritanceQueries),
 		SpreadingCycles:    sn.stats.SpreadingCycles,
 		ConceptsLearned:    sn.stats.ConceptsLearned,
+		NodesCopiedOnWrite: sn.stats.NodesCopiedOnWrite,
 		LastUpdated:        sn.stats.LastUpdated,
 	}
 }
@@ -1185,25 +1436,34 @@ type SemanticNetworkSnapshot struct {
 	Relations []*SemanticRelation
 	Stats     *SemanticNetworkStats
 	Timestamp time.Time
+	// LSN is the last write-ahead log record the snapshot includes
+	LSN uint64
 }
 
 // Snapshot creates a snapshot of the current network state.
+//
+// Snapshots are copy-on-write: the read lock is held only while node and
+// relation pointers are collected, and the deep copies are made after it is
+// released. Writers that touch a node captured by a running snapshot copy
+// it first (see mutableNode), so backups of large graphs don't stall the
+// serving path. Relations are never modified in place by the network.
 func (sn *SemanticNetwork) Snapshot() *SemanticNetworkSnapshot {
-	sn.mu.RLock()
-	defer sn.mu.RUnlock()
+	nodes, relations, stats, lsn := sn.freeze()
+	defer atomic.AddInt64(&sn.activeSnapshots, -1)
 
 	snapshot := &SemanticNetworkSnapshot{
-		Nodes:     make([]*SemanticNode, 0, len(sn.nodes)),
-		Relations: make([]*SemanticRelation, 0, len(sn.relations)),
-		Stats:     sn.GetStats(),
+		Nodes:     make([]*SemanticNode, 0, len(nodes)),
+		Relations: make([]*SemanticRelation, 0, len(relations)),
+		Stats:     stats,
 		Timestamp: time.Now(),
+		LSN:       lsn,
 	}
 
-	for _, node := range sn.nodes {
+	for _, node := range nodes {
 		snapshot.Nodes = append(snapshot.Nodes, node.Clone())
 	}
 
-	for _, rel := range sn.relations {
+	for _, rel := range relations {
 		relCopy := *rel
 		relCopy.Properties = make(map[string]interface{})
 		for k, v := range rel.Properties {
@@ -1215,7 +1475,57 @@ func (sn *SemanticNetwork) Snapshot() *SemanticNetworkSnapshot {
 	return snapshot
 }
 
-// Restore restores the network from a snapshot.
+// freeze starts a snapshot epoch and collects the current node and relation
+// pointers with the last logged LSN. The caller must decrement
+// activeSnapshots when done copying.
+func (sn *SemanticNetwork) freeze() ([]*SemanticNode, []*SemanticRelation, *SemanticNetworkStats, uint64) {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	// Writers hold the write lock, so none can observe the old epoch while
+	// the new snapshot reads nodes from it
+	atomic.AddInt64(&sn.activeSnapshots, 1)
+	atomic.AddUint64(&sn.snapshotEpoch, 1)
+
+	nodes := make([]*SemanticNode, 0, len(sn.nodes))
+	for _, node := range sn.nodes {
+		nodes = append(nodes, node)
+	}
+	relations := make([]*SemanticRelation, 0, len(sn.relations))
+	for _, rel := range sn.relations {
+		relations = append(relations, rel)
+	}
+	stats := sn.copyStats()
+	var lsn uint64
+	if sn.wal != nil {
+		// Mutations are logged under the write lock, so none is in flight
+		lsn = sn.wal.LastLSN()
+	}
+	return nodes, relations, stats, lsn
+}
+
+// mutableNode returns a node that is safe to modify in place. A node from
+// an earlier epoch may be read by a running snapshot, so it is replaced by
+// a copy first. Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) mutableNode(node *SemanticNode) *SemanticNode {
+	epoch := atomic.LoadUint64(&sn.snapshotEpoch)
+	if node.epoch >= epoch || atomic.LoadInt64(&sn.activeSnapshots) == 0 {
+		node.epoch = epoch
+		return node
+	}
+
+	clone := node.Clone()
+	clone.epoch = epoch
+	sn.unindexNode(node)
+	sn.nodes[node.ID] = clone
+	sn.indexNode(clone)
+	sn.stats.NodesCopiedOnWrite++
+	return clone
+}
+
+// Restore restores the network from a snapshot. Restores are not written
+// to the write-ahead log: replay the records after snapshot.LSN and
+// checkpoint the log instead.
 func (sn *SemanticNetwork) Restore(snapshot *SemanticNetworkSnapshot) error {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
@@ -1225,12 +1535,23 @@ func (sn *SemanticNetwork) Restore(snapshot *SemanticNetworkSnapshot) error {
 	sn.relations = make(map[string]*SemanticRelation)
 	sn.outgoing = make(map[string][]*SemanticRelation)
 	sn.incoming = make(map[string][]*SemanticRelation)
+	sn.typeIndex = make(map[NodeType]map[string]*SemanticNode)
+	sn.relationTypeIndex = make(map[RelationType]map[string]*SemanticRelation)
+	sn.propertyIndex = newPropertyIndex(sn.config.IndexedProperties)
+	sn.propertyIndexed = make(map[string]map[string]string)
+	sn.nameIndex = make(map[string]map[string]*SemanticNode)
+	sn.nameIndexed = make(map[string][]string)
+	sn.staleActivation = make(map[string]bool)
+	sn.invalidateDepthCache()
 
 	// Restore nodes
 	for _, node := range snapshot.Nodes {
-		sn.nodes[node.ID] = node.Clone()
+		clone := node.Clone()
+		clone.epoch = atomic.LoadUint64(&sn.snapshotEpoch)
+		sn.nodes[node.ID] = clone
 		sn.outgoing[node.ID] = make([]*SemanticRelation, 0)
 		sn.incoming[node.ID] = make([]*SemanticRelation, 0)
+		sn.indexNode(clone)
 	}
 
 	// Restore relations
@@ -1243,6 +1564,7 @@ func (sn *SemanticNetwork) Restore(snapshot *SemanticNetworkSnapshot) error {
 		sn.relations[rel.ID] = &relCopy
 		sn.outgoing[rel.SourceID] = append(sn.outgoing[rel.SourceID], &relCopy)
 		sn.incoming[rel.TargetID] = append(sn.incoming[rel.TargetID], &relCopy)
+		sn.indexRelation(&relCopy)
 	}
 
 	// Restore stats
@@ -1262,14 +1584,27 @@ func (sn *SemanticNetwork) Restore(snapshot *SemanticNetworkSnapshot) error {
 }
 
 // Clear removes all nodes and relations.
-func (sn *SemanticNetwork) Clear() {
+func (sn *SemanticNetwork) Clear() error {
 	sn.mu.Lock()
 	defer sn.mu.Unlock()
 
+	if err := sn.logMutation(&WALRecord{Op: WALNetworkClear}); err != nil {
+		return err
+	}
+
 	sn.nodes = make(map[string]*SemanticNode)
 	sn.relations = make(map[string]*SemanticRelation)
 	sn.outgoing = make(map[string][]*SemanticRelation)
 	sn.incoming = make(map[string][]*SemanticRelation)
+	sn.typeIndex = make(map[NodeType]map[string]*SemanticNode)
+	sn.relationTypeIndex = make(map[RelationType]map[string]*SemanticRelation)
+	sn.propertyIndex = newPropertyIndex(sn.config.IndexedProperties)
+	sn.propertyIndexed = make(map[string]map[string]string)
+	sn.nameIndex = make(map[string]map[string]*SemanticNode)
+	sn.nameIndexed = make(map[string][]string)
+	sn.staleActivation = make(map[string]bool)
+	sn.invalidateDepthCache()
+	return nil
 }
 
 // ============================================================================
@@ -1332,18 +1667,42 @@ type InferenceResult struct {
 	Query      string
 	Answer     interface{}
 	Confidence float64
-	Reasoning  []string
-	SourceIDs  []string
+	// CalibratedConfidence is Confidence remapped by feedback so far
+	CalibratedConfidence float64
+	Reasoning            []string
+	SourceIDs            []string
 }
 
 // SemanticInferenceEngine performs reasoning over the semantic network.
 type SemanticInferenceEngine struct {
 	network *SemanticNetwork
+	// calibrator compares predicted confidence with feedback
+	calibrator *ConfidenceCalibrator
 }
 
 // NewSemanticInferenceEngine creates a new inference engine.
 func NewSemanticInferenceEngine(network *SemanticNetwork) *SemanticInferenceEngine {
-	return &SemanticInferenceEngine{network: network}
+	return &SemanticInferenceEngine{
+		network:    network,
+		calibrator: NewConfidenceCalibrator(10, 20),
+	}
+}
+
+// RecordFeedback records whether an inference turned out to be correct,
+// so future confidences can be calibrated against observed accuracy.
+func (e *SemanticInferenceEngine) RecordFeedback(result *InferenceResult, correct bool) {
+	e.calibrator.Record(result.Confidence, correct)
+}
+
+// GetCalibrationReport returns predicted vs observed correctness so far.
+func (e *SemanticInferenceEngine) GetCalibrationReport() *CalibrationReport {
+	return e.calibrator.Report()
+}
+
+// calibrate fills in the calibrated confidence of a result.
+func (e *SemanticInferenceEngine) calibrate(result *InferenceResult) *InferenceResult {
+	result.CalibratedConfidence = e.calibrator.Calibrate(result.Confidence)
+	return result
 }
 
 // InferProperty uses inheritance to determine a property value.
@@ -1373,7 +1732,7 @@ func (e *SemanticInferenceEngine) InferProperty(nodeID, propertyKey string) (*In
 				fmt.Sprintf("%s inherits %s = %v from %s (distance: %d)",
 					nodeID, propertyKey, prop.Value, prop.SourceNodeID, prop.Distance))
 		}
-		return result, nil
+		return e.calibrate(result), nil
 	}
 
 	return nil, fmt.Errorf("property %s not found for node %s", propertyKey, nodeID)
@@ -1393,24 +1752,33 @@ func (e *SemanticInferenceEngine) InferMembership(instanceID, categoryID string)
 		result.Answer = true
 		result.Confidence = 1.0
 
-		// Build reasoning chain
-		path, err := e.network.FindShortestPath(instanceID, categoryID)
+		// Build reasoning chain from the IS-A links only
+		path, err := e.network.FindPath(PathQuery{
+			FromID:       instanceID,
+			ToID:         categoryID,
+			AllowedTypes: []RelationType{IsA},
+			Direction:    PathForward,
+		})
 		if err == nil {
-			for i := 0; i < len(path)-1; i++ {
+			links := make([]float64, 0, len(path.Edges))
+			for i, step := range path.Edges {
 				result.Reasoning = append(result.Reasoning,
-					fmt.Sprintf("%s is-a %s", path[i].Label, path[i+1].Label))
-				result.SourceIDs = append(result.SourceIDs, path[i].ID)
+					fmt.Sprintf("%s %s %s", path.Nodes[i].Label, step.Relation.Type, path.Nodes[i+1].Label))
+				result.SourceIDs = append(result.SourceIDs, path.Nodes[i].ID)
+				links = append(links, step.Relation.Confidence)
 			}
-			result.SourceIDs = append(result.SourceIDs, path[len(path)-1].ID)
+			result.SourceIDs = append(result.SourceIDs, path.Nodes[len(path.Nodes)-1].ID)
+			// Membership holds only if every link on the chain holds
+			result.Confidence = e.network.config.Confidence.Chain(links...)
 		}
-		return result, nil
+		return e.calibrate(result), nil
 	}
 
 	result.Answer = false
 	result.Confidence = 1.0
 	result.Reasoning = append(result.Reasoning,
 		fmt.Sprintf("No IS-A path found from %s to %s", instanceID, categoryID))
-	return result, nil
+	return e.calibrate(result), nil
 }
 
 // InferAnalogy finds analogous relationships between concepts.
@@ -1462,7 +1830,7 @@ func (e *SemanticInferenceEngine) InferAnalogy(nodeA, nodeB, nodeC string) (*Inf
 	result.Answer = candidates[0].ID
 	result.Confidence = 1.0 / float64(len(candidates)) // Lower confidence if multiple candidates
 
-	return result, nil
+	return e.calibrate(result), nil
 }
 
 // InferCompletion predicts missing relationships for a node.
@@ -1491,23 +1859,40 @@ func (e *SemanticInferenceEngine) InferCompletion(nodeID string) (*InferenceResu
 		existingRels[rel.Type.String()+":"+rel.TargetID] = true
 	}
 
+	// Each similar node is independent evidence for a prediction, so
+	// support from several nodes is pooled rather than kept separately
+	calculus := e.network.config.Confidence
 	predictions := make([]map[string]interface{}, 0)
+	evidence := make(map[string][]float64)
+	index := make(map[string]int)
 	for _, similar := range similarNodes {
 		for _, rel := range e.network.outgoing[similar.ID] {
 			key := rel.Type.String() + ":" + rel.TargetID
-			if !existingRels[key] {
+			if existingRels[key] {
+				continue
+			}
+			support := calculus.Chain(similar.Activation, rel.Weight, rel.Confidence)
+			evidence[key] = append(evidence[key], support)
+			if _, seen := index[key]; !seen {
+				index[key] = len(predictions)
 				predictions = append(predictions, map[string]interface{}{
-					"type":       rel.Type.String(),
-					"target":     rel.TargetID,
-					"confidence": similar.Activation * rel.Weight,
-					"source":     similar.ID,
+					"type":   rel.Type.String(),
+					"target": rel.TargetID,
+					"source": similar.ID,
 				})
-				result.Reasoning = append(result.Reasoning,
-					fmt.Sprintf("Similar node %s has %s relation to %s",
-						similar.Label, rel.Type, rel.TargetID))
 			}
+			result.Reasoning = append(result.Reasoning,
+				fmt.Sprintf("Similar node %s has %s relation to %s",
+					similar.Label, rel.Type, rel.TargetID))
 		}
 	}
+	for key, i := range index {
+		predictions[i]["confidence"] = calculus.Combine(evidence[key]...)
+		predictions[i]["support"] = len(evidence[key])
+	}
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i]["confidence"].(float64) > predictions[j]["confidence"].(float64)
+	})
 
 	result.Answer = predictions
 	if len(predictions) > 0 {
@@ -1515,7 +1900,7 @@ func (e *SemanticInferenceEngine) InferCompletion(nodeID string) (*InferenceResu
 	}
 
 	_ = node // Use node variable
-	return result, nil
+	return e.calibrate(result), nil
 }
 
 // findSimilarNodes finds nodes similar to the given node.
@@ -1580,6 +1965,15 @@ type ConceptLearner struct {
 	network               *SemanticNetwork
 	minExamplesForConcept int
 	similarityThreshold   float64
+
+	// mu guards tracked, the concepts updated online by ObserveInstance
+	mu      sync.Mutex
+	tracked map[string]*conceptTracker
+	// driftThreshold is how far the smoothed match quality may fall below
+	// its baseline before a concept is flagged as drifting
+	driftThreshold float64
+	// driftSmoothing is the EWMA factor applied to match quality
+	driftSmoothing float64
 }
 
 // NewConceptLearner creates a new concept learner.
@@ -1588,6 +1982,9 @@ func NewConceptLearner(network *SemanticNetwork) *ConceptLearner {
 		network:               network,
 		minExamplesForConcept: 3,
 		similarityThreshold:   0.7,
+		tracked:               make(map[string]*conceptTracker),
+		driftThreshold:        0.15,
+		driftSmoothing:        0.2,
 	}
 }
 
@@ -1600,6 +1997,12 @@ type LearnedConcept struct {
 	CommonProperties map[string]interface{}
 	Confidence       float64
 	LearnedAt        time.Time
+	// UpdatedAt is when the concept last absorbed an online observation
+	UpdatedAt time.Time
+	// DriftScore is how far recent match quality has fallen below baseline
+	DriftScore float64
+	// Drifted is set once DriftScore exceeds the learner's drift threshold
+	Drifted bool
 }
 
 // ExtractPrototype creates a prototype from a set of instances.
@@ -1650,6 +2053,7 @@ func (cl *ConceptLearner) ExtractPrototype(instanceIDs []string) (*LearnedConcep
 		CommonProperties: commonProps,
 		Confidence:       prototype.Confidence,
 		LearnedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 
 	return learned, nil
@@ -1728,51 +2132,12 @@ func (cl *ConceptLearner) averageEmbeddings(instances []*SemanticNode) []float32
 	return avg
 }
 
-// DiscoverRelationships discovers potential relationships between unconnected nodes.
+// DiscoverRelationships discovers potential relationships between unconnected
+// nodes using default blocking and no comparison budget.
 func (cl *ConceptLearner) DiscoverRelationships(minConfidence float64) []*SemanticRelation {
-	cl.network.mu.RLock()
-	defer cl.network.mu.RUnlock()
-
-	discovered := make([]*SemanticRelation, 0)
-
-	// Find pairs of similar unconnected nodes
-	nodes := make([]*SemanticNode, 0, len(cl.network.nodes))
-	for _, node := range cl.network.nodes {
-		nodes = append(nodes, node)
-	}
-
-	for i := 0; i < len(nodes); i++ {
-		for j := i + 1; j < len(nodes); j++ {
-			nodeA := nodes[i]
-			nodeB := nodes[j]
-
-			// Skip if already connected
-			if cl.areConnected(nodeA.ID, nodeB.ID) {
-				continue
-			}
-
-			// Compute similarity
-			sim, err := cl.network.ComputeSimilarity(nodeA.ID, nodeB.ID)
-			if err != nil || sim.Similarity < cl.similarityThreshold {
-				continue
-			}
-
-			// Infer relationship type from node types
-			relType := cl.inferRelationType(nodeA, nodeB)
-			if relType == RelatedTo && sim.Similarity < 0.8 {
-				continue // Only create generic relations for very similar nodes
-			}
-
-			rel := NewSemanticRelation(nodeA.ID, nodeB.ID, relType)
-			rel.Weight = sim.Similarity
-			rel.Confidence = sim.Similarity * minConfidence
-			rel.Source = "discovered"
-
-			discovered = append(discovered, rel)
-		}
-	}
-
-	return discovered
+	opts := DefaultDiscoveryOptions()
+	opts.MinConfidence = minConfidence
+	return cl.DiscoverRelationshipsWithOptions(opts).Relations
 }
 
 // areConnected checks if two nodes are directly connected.
@@ -1834,6 +2199,9 @@ func (cl *ConceptLearner) CommitLearnedConcept(concept *LearnedConcept) error {
 	cl.network.stats.ConceptsLearned++
 	cl.network.mu.Unlock()
 
+	// Keep refining the concept as new matching instances arrive
+	cl.TrackConcept(concept)
+
 	return nil
 }
 
@@ -1892,8 +2260,16 @@ func (cl *ConceptLearner) LearnFromExperience(experiences []*ExperienceTuple) ([
 	return learned, nil
 }
 
-// valuesEqual compares two interface values for equality.
+// valuesEqual compares two property values for equality, using typed
+// comparison when both sides have a property kind.
 func valuesEqual(a, b interface{}) bool {
-	// Simple comparison - could be extended for deep equality
-	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	typedA, okA := AsPropertyValue(a)
+	typedB, okB := AsPropertyValue(b)
+	if okA && okB {
+		return typedA.Equal(typedB)
+	}
+	if okA != okB {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
 }
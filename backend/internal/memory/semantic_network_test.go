@@ -2,6 +2,9 @@ package memory
 
 import (
 	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -204,6 +207,50 @@ func TestSemanticNetwork_SpreadActivation(t *testing.T) {
 	}
 }
 
+func TestSemanticNetwork_SpreadActivationContributions(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"dog", "cat", "animal", "living"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	sn.AddRelation(NewSemanticRelation("dog", "animal", IsA))
+	weak := NewSemanticRelation("cat", "animal", IsA)
+	weak.Weight = 0.5
+	sn.AddRelation(weak)
+	sn.AddRelation(NewSemanticRelation("animal", "living", IsA))
+
+	if result := sn.SpreadActivation([]string{"dog", "cat"}, 1.0); result.Contributions != nil {
+		t.Errorf("Expected no contributions by default, got %d", len(result.Contributions))
+	}
+
+	sn.ResetActivation()
+	result := sn.SpreadActivationWith([]string{"dog", "cat"}, 1.0, SpreadOptions{Contributions: true})
+	// Sources keep spreading every cycle; the first shows both edges
+	toAnimal := make([]ActivationContribution, 0)
+	for _, c := range result.ContributionsTo("animal") {
+		if c.Cycle == 1 {
+			toAnimal = append(toAnimal, c)
+		}
+	}
+	if len(toAnimal) != 2 {
+		t.Fatalf("Expected contributions from dog and cat in the first cycle, got %+v", toAnimal)
+	}
+	first := toAnimal[0]
+	if first.SourceID != "dog" || first.Relation != "is-a" || first.Amount != 0.5 {
+		t.Errorf("Expected dog's full-weight IS-A edge first, got %+v", first)
+	}
+	if toAnimal[1].SourceID != "cat" || toAnimal[1].Amount != 0.25 {
+		t.Errorf("Expected cat's half-weight edge second, got %+v", toAnimal[1])
+	}
+
+	toLiving := result.ContributionsTo("living")
+	if len(toLiving) == 0 || toLiving[0].SourceID != "animal" || toLiving[0].Cycle < 2 {
+		t.Errorf("Expected living reached through animal in a later cycle, got %+v", toLiving)
+	}
+	if got := result.ContributionsTo("dog"); len(got) != 0 {
+		t.Errorf("Expected no contributions to a source without incoming edges, got %+v", got)
+	}
+}
+
 func TestSemanticNetwork_DecayActivation(t *testing.T) {
 	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
 
@@ -393,6 +440,89 @@ func TestSemanticNetwork_ComputeSimilarity(t *testing.T) {
 	}
 }
 
+func TestSemanticNetwork_ComputeSimilarity_WuPalmer(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+
+	for _, id := range []string{"animal", "mammal", "bird", "dog", "cat", "sparrow", "rock"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	sn.AddRelation(NewSemanticRelation("mammal", "animal", IsA))
+	sn.AddRelation(NewSemanticRelation("bird", "animal", IsA))
+	sn.AddRelation(NewSemanticRelation("dog", "mammal", IsA))
+	sn.AddRelation(NewSemanticRelation("cat", "mammal", IsA))
+	sn.AddRelation(NewSemanticRelation("sparrow", "bird", IsA))
+
+	tests := []struct {
+		a, b     string
+		expected float64
+		lca      string
+	}{
+		// depth(dog)=3, depth(cat)=3, LCA mammal depth 2 -> 4/6
+		{"dog", "cat", 4.0 / 6.0, "mammal"},
+		// LCA animal depth 1 -> 2/6
+		{"dog", "sparrow", 2.0 / 6.0, "animal"},
+		// ancestor is its own LCA -> 2*2/(3+2)
+		{"dog", "mammal", 4.0 / 5.0, "mammal"},
+		{"dog", "dog", 1.0, "dog"},
+		// disconnected
+		{"dog", "rock", 0.0, ""},
+	}
+
+	for _, tc := range tests {
+		sim, err := sn.ComputeSimilarity(tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("ComputeSimilarity(%s, %s) failed: %v", tc.a, tc.b, err)
+		}
+		if sim.Method != "wu-palmer" {
+			t.Errorf("Expected wu-palmer method, got %s", sim.Method)
+		}
+		if math.Abs(sim.Similarity-tc.expected) > 1e-9 {
+			t.Errorf("Similarity(%s, %s) = %f, want %f", tc.a, tc.b, sim.Similarity, tc.expected)
+		}
+		if sim.LCA != tc.lca {
+			t.Errorf("LCA(%s, %s) = %q, want %q", tc.a, tc.b, sim.LCA, tc.lca)
+		}
+	}
+
+	// Similarity must be symmetric
+	ab, _ := sn.ComputeSimilarity("dog", "sparrow")
+	ba, _ := sn.ComputeSimilarity("sparrow", "dog")
+	if ab.Similarity != ba.Similarity {
+		t.Errorf("Similarity not symmetric: %f vs %f", ab.Similarity, ba.Similarity)
+	}
+}
+
+func TestSemanticNetwork_DepthCacheInvalidation(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+
+	for _, id := range []string{"root", "mid", "leaf", "other"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	sn.AddRelation(NewSemanticRelation("leaf", "root", IsA))
+	sn.AddRelation(NewSemanticRelation("other", "root", IsA))
+
+	before, _ := sn.ComputeSimilarity("leaf", "other")
+	if math.Abs(before.Similarity-0.5) > 1e-9 {
+		t.Fatalf("Expected 0.5 before change, got %f", before.Similarity)
+	}
+
+	// Deepen the leaf: leaf -> mid -> root
+	sn.RemoveRelation(NewSemanticRelation("leaf", "root", IsA).ID)
+	sn.AddRelation(NewSemanticRelation("mid", "root", IsA))
+	sn.AddRelation(NewSemanticRelation("leaf", "mid", IsA))
+
+	after, _ := sn.ComputeSimilarity("leaf", "other")
+	if math.Abs(after.Similarity-2.0/5.0) > 1e-9 {
+		t.Errorf("Expected 0.4 after deepening, got %f (stale depth cache?)", after.Similarity)
+	}
+
+	sn.RemoveNode("mid")
+	removed, _ := sn.ComputeSimilarity("leaf", "other")
+	if removed.Similarity != 0 {
+		t.Errorf("Expected 0 after removing link to root, got %f", removed.Similarity)
+	}
+}
+
 func TestSemanticNetwork_SnapshotRestore(t *testing.T) {
 	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
 
@@ -430,6 +560,150 @@ func TestSemanticNetwork_SnapshotRestore(t *testing.T) {
 	}
 }
 
+func TestSemanticNetwork_SnapshotCopyOnWrite(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	node := NewSemanticNode("test", "Test", ConceptNode)
+	node.SetProperty("key", "before")
+	sn.AddNode(node)
+
+	// Simulate a snapshot that is still copying
+	frozen, _, _, _ := sn.freeze()
+	if err := sn.SetNodeProperty("test", "key", "after"); err != nil {
+		t.Fatalf("SetNodeProperty failed: %v", err)
+	}
+	sn.SpreadActivation([]string{"test"}, 1.0)
+
+	if frozen[0].Properties["key"] != "before" || frozen[0].Activation != 0 {
+		t.Errorf("Expected frozen node to be unchanged, got %v", frozen[0].Properties["key"])
+	}
+	current, _ := sn.GetNode("test")
+	if current == frozen[0] || current.Properties["key"] != "after" || current.Activation != 1.0 {
+		t.Error("Expected writes to go to a copy of the node")
+	}
+	if copies := sn.GetStats().NodesCopiedOnWrite; copies != 1 {
+		t.Errorf("Expected 1 copy, got %d", copies)
+	}
+	if nodes := sn.GetNodesByType(ConceptNode); len(nodes) != 1 || nodes[0] != current {
+		t.Error("Expected type index to point at the copy")
+	}
+
+	// Once the snapshot finishes, nodes are written in place again
+	atomic.AddInt64(&sn.activeSnapshots, -1)
+	sn.SetNodeProperty("test", "key", "again")
+	if again, _ := sn.GetNode("test"); again != current {
+		t.Error("Expected in-place write without an active snapshot")
+	}
+}
+
+func TestSemanticNetwork_SnapshotConcurrentWrites(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for i := 0; i < 200; i++ {
+		sn.AddNode(NewSemanticNode(fmt.Sprintf("n%d", i), "Node", ConceptNode))
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			id := fmt.Sprintf("n%d", i%200)
+			sn.SetNodeProperty(id, "counter", i)
+			sn.SpreadActivation([]string{id}, 0.8)
+			sn.DecayActivation(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		snapshot := sn.Snapshot()
+		if len(snapshot.Nodes) != 200 {
+			t.Errorf("Expected 200 nodes in snapshot, got %d", len(snapshot.Nodes))
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestSemanticNetwork_ConcurrentAddRemoveQuery(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for i := 0; i < 50; i++ {
+		node := NewSemanticNode(fmt.Sprintf("seed%d", i), fmt.Sprintf("Seed %d", i), ConceptNode)
+		node.SetProperty("domain", "seed")
+		sn.AddNode(node)
+	}
+
+	const workers = 8
+	const iterations = 300
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				seed := fmt.Sprintf("seed%d", (w*31+i)%50)
+				id := fmt.Sprintf("w%d-n%d", w, i)
+				switch w % 4 {
+				case 0:
+					// Writers add nodes and link them into the seed set
+					sn.AddNode(NewSemanticNode(id, "Node "+id, InstanceNode))
+					sn.AddRelation(NewSemanticRelation(id, seed, InstanceOf))
+					sn.AddRelation(NewSemanticRelation(seed, id, RelatedTo))
+				case 1:
+					// Removers add nodes and relations, then drop the
+					// previous node, shifting it out of relation lists
+					sn.AddNode(NewSemanticNode(id, "Node "+id, InstanceNode))
+					rel := NewSemanticRelation(id, seed, PartOf)
+					sn.AddRelation(rel)
+					sn.AddRelation(NewSemanticRelation("seed0", id, RelatedTo))
+					if i%2 == 0 {
+						sn.RemoveRelation(rel.ID)
+					}
+					sn.RemoveNode(fmt.Sprintf("w%d-n%d", w, i-1))
+				case 2:
+					// Readers walk relation lists while they change; seed0
+					// is shared by every worker
+					for _, rel := range sn.GetOutgoingRelations("seed0") {
+						_ = rel.TargetID
+					}
+					for _, rel := range sn.GetIncomingRelations("seed0") {
+						_ = rel.SourceID
+					}
+					sn.GetRelatedNodes(seed, RelatedTo)
+					sn.GetReverseRelatedNodes(seed, InstanceOf)
+					sn.GetInheritedProperties(seed)
+					sn.FindNodesByLabel("Node")
+					sn.FindNodesByProperty("domain", "seed")
+					sn.FindShortestPath(seed, fmt.Sprintf("seed%d", i%50))
+					sn.CountNodesByType(InstanceNode)
+				default:
+					sn.SpreadActivation([]string{seed}, 0.9)
+					sn.DecayActivation(time.Millisecond)
+					sn.GetMostActivated(5)
+					sn.GetNode(seed)
+					if i%50 == 0 {
+						sn.Snapshot()
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Every surviving relation joins surviving nodes
+	for _, rel := range sn.GetAllRelations() {
+		for _, id := range []string{rel.SourceID, rel.TargetID} {
+			if _, err := sn.GetNode(id); err != nil {
+				t.Fatalf("Relation %s references removed node %s", rel.ID, id)
+			}
+		}
+	}
+}
+
 // ============================================================================
 // Semantic Inference Engine Tests
 // ============================================================================
@@ -836,6 +1110,63 @@ func TestSemanticNetwork_GetNodesByType(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Semantic Network Benchmarks
+// ============================================================================
+
+// buildDeepHierarchy creates a binary IS-A tree of the given depth.
+func buildDeepHierarchy(depth int) (*SemanticNetwork, []string) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("n0", "n0", ConceptNode))
+
+	level := []string{"n0"}
+	next := 1
+	for d := 1; d < depth; d++ {
+		children := make([]string, 0, 2*len(level))
+		for _, parent := range level {
+			for k := 0; k < 2; k++ {
+				id := fmt.Sprintf("n%d", next)
+				next++
+				sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+				sn.AddRelation(NewSemanticRelation(id, parent, IsA))
+				children = append(children, id)
+			}
+		}
+		level = children
+	}
+	return sn, level
+}
+
+func BenchmarkSemanticNetwork_ComputeSimilarity_DeepHierarchy(b *testing.B) {
+	sn, leaves := buildDeepHierarchy(14)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a := leaves[i%len(leaves)]
+		c := leaves[(i*7919)%len(leaves)]
+		sn.ComputeSimilarity(a, c)
+	}
+}
+
+func BenchmarkSemanticNetwork_ComputeSimilarity_LongChain(b *testing.B) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	const chain = 2000
+	for i := 0; i < chain; i++ {
+		id := fmt.Sprintf("c%d", i)
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+		if i > 0 {
+			sn.AddRelation(NewSemanticRelation(id, fmt.Sprintf("c%d", i-1), IsA))
+		}
+	}
+	sn.AddNode(NewSemanticNode("side", "side", ConceptNode))
+	sn.AddRelation(NewSemanticRelation("side", "c0", IsA))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sn.ComputeSimilarity(fmt.Sprintf("c%d", chain-1), "side")
+	}
+}
+
 // Helper for tests
 func fmt_Sprintf(format string, args ...interface{}) string {
 	return fmt.Sprintf(format, args...)
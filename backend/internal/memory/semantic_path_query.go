@@ -0,0 +1,236 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements constrained path queries over the Semantic Network.
+//
+// Unlike FindShortestPath, which treats every relation as an undirected edge,
+// a PathQuery can restrict traversal to specific relation types, bound the
+// number of hops, choose which way relations may be followed, and rank paths
+// by relation weight. Results carry the traversed relations alongside the
+// nodes so callers can explain *why* two concepts are connected.
+
+package memory
+
+import (
+	"fmt"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
+)
+
+// ErrNoPathFound indicates no path satisfies the query constraints
+var ErrNoPathFound = errdefs.New(errdefs.ErrNotFound, "no path found")
+
+// ============================================================================
+// Path Query Types
+// ============================================================================
+
+// PathDirection controls which way relations may be traversed.
+type PathDirection int
+
+const (
+	// PathForward follows relations from source to target only
+	PathForward PathDirection = iota
+	// PathBackward follows relations from target to source only
+	PathBackward
+	// PathBoth follows relations in either direction
+	PathBoth
+)
+
+// String returns the string representation of a PathDirection.
+func (d PathDirection) String() string {
+	switch d {
+	case PathForward:
+		return "forward"
+	case PathBackward:
+		return "backward"
+	case PathBoth:
+		return "both"
+	default:
+		return "unknown"
+	}
+}
+
+// PathQuery describes a constrained path search between two nodes.
+type PathQuery struct {
+	// FromID is the start node
+	FromID string
+	// ToID is the goal node
+	ToID string
+	// AllowedTypes restricts traversal to these relation types (empty = all)
+	AllowedTypes []RelationType
+	// MaxHops bounds the path length in edges (0 = unbounded)
+	MaxHops int
+	// Direction controls which way relations may be followed
+	Direction PathDirection
+	// UseWeights ranks paths by 1/weight per edge instead of hop count;
+	// relations with non-positive weight are not traversable
+	UseWeights bool
+}
+
+// PathStep is a single traversed relation in a path.
+type PathStep struct {
+	// Relation is the traversed relation
+	Relation *SemanticRelation
+	// FromID is the node the step leaves
+	FromID string
+	// ToID is the node the step arrives at
+	ToID string
+	// Reversed is true when the relation was followed target-to-source
+	Reversed bool
+}
+
+// PathResult holds the outcome of a path query.
+type PathResult struct {
+	// Nodes are the nodes on the path, including both endpoints
+	Nodes []*SemanticNode
+	// Edges are the traversed relations; len(Edges) == len(Nodes)-1
+	Edges []*PathStep
+	// Cost is the total path cost (hops, or summed 1/weight)
+	Cost float64
+}
+
+// Hops returns the number of edges in the path.
+func (r *PathResult) Hops() int {
+	return len(r.Edges)
+}
+
+// ============================================================================
+// Path Search
+// ============================================================================
+
+// pathStateKey identifies a search state; hops is only tracked when the
+// query bounds path length, since a cheaper path with more hops may then
+// be unusable.
+type pathStateKey struct {
+	nodeID string
+	hops   int
+}
+
+// pathState is a partial path in the search frontier.
+type pathState struct {
+	nodeID string
+	hops   int
+	cost   float64
+	prev   *pathState
+	step   *PathStep
+}
+
+// cheaperPath orders search states by cost, then hops.
+func cheaperPath(a, b *pathState) bool {
+	if a.cost != b.cost {
+		return a.cost < b.cost
+	}
+	return a.hops < b.hops
+}
+
+// FindPath finds the cheapest path satisfying the query constraints.
+func (sn *SemanticNetwork) FindPath(query PathQuery) (*PathResult, error) {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	return sn.findPath(query)
+}
+
+// findPath runs a uniform-cost search. Caller must hold sn.mu.
+func (sn *SemanticNetwork) findPath(query PathQuery) (*PathResult, error) {
+	if _, exists := sn.nodes[query.FromID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, query.FromID)
+	}
+	if _, exists := sn.nodes[query.ToID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, query.ToID)
+	}
+
+	var allowed map[RelationType]bool
+	if len(query.AllowedTypes) > 0 {
+		allowed = make(map[RelationType]bool, len(query.AllowedTypes))
+		for _, t := range query.AllowedTypes {
+			allowed[t] = true
+		}
+	}
+
+	keyFor := func(nodeID string, hops int) pathStateKey {
+		if query.MaxHops > 0 {
+			return pathStateKey{nodeID: nodeID, hops: hops}
+		}
+		return pathStateKey{nodeID: nodeID}
+	}
+
+	frontier := pqueue.New(cheaperPath)
+	frontier.Push(&pathState{nodeID: query.FromID})
+	settled := make(map[pathStateKey]bool)
+
+	for frontier.Len() > 0 {
+		current, _ := frontier.Pop()
+		key := keyFor(current.nodeID, current.hops)
+		if settled[key] {
+			continue
+		}
+		settled[key] = true
+
+		if current.nodeID == query.ToID {
+			return sn.buildPathResult(current), nil
+		}
+		if query.MaxHops > 0 && current.hops >= query.MaxHops {
+			continue
+		}
+
+		expand := func(rel *SemanticRelation, nextID string, reversed bool) {
+			if allowed != nil && !allowed[rel.Type] {
+				return
+			}
+			if _, exists := sn.nodes[nextID]; !exists {
+				return
+			}
+			if settled[keyFor(nextID, current.hops+1)] {
+				return
+			}
+			cost := 1.0
+			if query.UseWeights {
+				if rel.Weight <= 0 {
+					return
+				}
+				cost = 1.0 / rel.Weight
+			}
+			frontier.Push(&pathState{
+				nodeID: nextID,
+				hops:   current.hops + 1,
+				cost:   current.cost + cost,
+				prev:   current,
+				step: &PathStep{
+					Relation: rel,
+					FromID:   current.nodeID,
+					ToID:     nextID,
+					Reversed: reversed,
+				},
+			})
+		}
+
+		if query.Direction == PathForward || query.Direction == PathBoth {
+			for _, rel := range sn.outgoing[current.nodeID] {
+				expand(rel, rel.TargetID, false)
+			}
+		}
+		if query.Direction == PathBackward || query.Direction == PathBoth {
+			for _, rel := range sn.incoming[current.nodeID] {
+				expand(rel, rel.SourceID, true)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w between %s and %s", ErrNoPathFound, query.FromID, query.ToID)
+}
+
+// buildPathResult walks the predecessor chain back to the start node.
+func (sn *SemanticNetwork) buildPathResult(end *pathState) *PathResult {
+	result := &PathResult{
+		Nodes: make([]*SemanticNode, end.hops+1),
+		Edges: make([]*PathStep, end.hops),
+		Cost:  end.cost,
+	}
+	for state := end; state != nil; state = state.prev {
+		result.Nodes[state.hops] = sn.nodes[state.nodeID]
+		if state.step != nil {
+			result.Edges[state.hops-1] = state.step
+		}
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+)
+
+// ============================================================================
+// Path Query Tests
+// ============================================================================
+
+// buildPathTestNetwork creates:
+//
+//	dog -is-a-> mammal -is-a-> animal
+//	dog -related-to-> animal          (weight 0.2)
+//	cat -is-a-> mammal
+func buildPathTestNetwork(t *testing.T) *SemanticNetwork {
+	t.Helper()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"dog", "cat", "mammal", "animal"} {
+		if err := sn.AddNode(NewSemanticNode(id, id, ConceptNode)); err != nil {
+			t.Fatalf("AddNode(%s) failed: %v", id, err)
+		}
+	}
+
+	shortcut := NewSemanticRelation("dog", "animal", RelatedTo)
+	shortcut.Weight = 0.2
+	for _, rel := range []*SemanticRelation{
+		NewSemanticRelation("dog", "mammal", IsA),
+		NewSemanticRelation("mammal", "animal", IsA),
+		NewSemanticRelation("cat", "mammal", IsA),
+		shortcut,
+	} {
+		if err := sn.AddRelation(rel); err != nil {
+			t.Fatalf("AddRelation(%s) failed: %v", rel.ID, err)
+		}
+	}
+	return sn
+}
+
+func TestFindPath_Unconstrained(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	path, err := sn.FindPath(PathQuery{FromID: "dog", ToID: "animal", Direction: PathForward})
+	if err != nil {
+		t.Fatalf("FindPath failed: %v", err)
+	}
+	if path.Hops() != 1 {
+		t.Fatalf("Expected 1 hop via shortcut, got %d", path.Hops())
+	}
+	if path.Edges[0].Relation.Type != RelatedTo {
+		t.Errorf("Expected related-to edge, got %s", path.Edges[0].Relation.Type)
+	}
+	if len(path.Nodes) != len(path.Edges)+1 {
+		t.Errorf("Expected %d nodes, got %d", len(path.Edges)+1, len(path.Nodes))
+	}
+}
+
+func TestFindPath_AllowedTypes(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	path, err := sn.FindPath(PathQuery{
+		FromID:       "dog",
+		ToID:         "animal",
+		AllowedTypes: []RelationType{IsA},
+		Direction:    PathForward,
+	})
+	if err != nil {
+		t.Fatalf("FindPath failed: %v", err)
+	}
+	if path.Hops() != 2 {
+		t.Fatalf("Expected 2 IS-A hops, got %d", path.Hops())
+	}
+	for _, step := range path.Edges {
+		if step.Relation.Type != IsA {
+			t.Errorf("Expected only is-a edges, got %s", step.Relation.Type)
+		}
+	}
+	if path.Nodes[1].ID != "mammal" {
+		t.Errorf("Expected path through mammal, got %s", path.Nodes[1].ID)
+	}
+}
+
+func TestFindPath_Direction(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	// dog -> cat requires going up to mammal then back down
+	_, err := sn.FindPath(PathQuery{FromID: "dog", ToID: "cat", Direction: PathForward})
+	if !errors.Is(err, ErrNoPathFound) {
+		t.Errorf("Expected ErrNoPathFound going forward only, got %v", err)
+	}
+
+	path, err := sn.FindPath(PathQuery{FromID: "dog", ToID: "cat", Direction: PathBoth})
+	if err != nil {
+		t.Fatalf("FindPath failed: %v", err)
+	}
+	if path.Hops() != 2 {
+		t.Fatalf("Expected 2 hops, got %d", path.Hops())
+	}
+	if path.Edges[0].Reversed || !path.Edges[1].Reversed {
+		t.Error("Expected second step to traverse cat->mammal in reverse")
+	}
+	if path.Edges[1].FromID != "mammal" || path.Edges[1].ToID != "cat" {
+		t.Errorf("Unexpected step endpoints: %s -> %s", path.Edges[1].FromID, path.Edges[1].ToID)
+	}
+
+	// animal -> dog only works backward
+	path, err = sn.FindPath(PathQuery{FromID: "animal", ToID: "dog", Direction: PathBackward})
+	if err != nil {
+		t.Fatalf("Backward FindPath failed: %v", err)
+	}
+	if path.Nodes[len(path.Nodes)-1].ID != "dog" {
+		t.Error("Backward path should end at dog")
+	}
+}
+
+func TestFindPath_MaxHops(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	query := PathQuery{
+		FromID:       "dog",
+		ToID:         "animal",
+		AllowedTypes: []RelationType{IsA},
+		Direction:    PathForward,
+		MaxHops:      1,
+	}
+	if _, err := sn.FindPath(query); !errors.Is(err, ErrNoPathFound) {
+		t.Errorf("Expected ErrNoPathFound with MaxHops=1, got %v", err)
+	}
+
+	query.MaxHops = 2
+	if _, err := sn.FindPath(query); err != nil {
+		t.Errorf("Expected path with MaxHops=2, got %v", err)
+	}
+}
+
+func TestFindPath_MaxHopsPrefersFeasiblePath(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	for _, id := range []string{"a", "b", "c", "d"} {
+		sn.AddNode(NewSemanticNode(id, id, ConceptNode))
+	}
+	// Cheap three-hop route and an expensive direct edge
+	sn.AddRelation(NewSemanticRelation("a", "b", RelatedTo))
+	sn.AddRelation(NewSemanticRelation("b", "c", RelatedTo))
+	sn.AddRelation(NewSemanticRelation("c", "d", RelatedTo))
+	direct := NewSemanticRelation("a", "d", Requires)
+	direct.Weight = 0.1
+	sn.AddRelation(direct)
+
+	path, err := sn.FindPath(PathQuery{FromID: "a", ToID: "d", Direction: PathForward, UseWeights: true})
+	if err != nil {
+		t.Fatalf("FindPath failed: %v", err)
+	}
+	if path.Hops() != 3 {
+		t.Errorf("Expected cheap 3-hop path, got %d hops (cost %.1f)", path.Hops(), path.Cost)
+	}
+
+	path, err = sn.FindPath(PathQuery{FromID: "a", ToID: "d", Direction: PathForward, UseWeights: true, MaxHops: 2})
+	if err != nil {
+		t.Fatalf("FindPath with MaxHops failed: %v", err)
+	}
+	if path.Hops() != 1 {
+		t.Errorf("Expected direct edge under hop limit, got %d hops", path.Hops())
+	}
+	if path.Cost != 10 {
+		t.Errorf("Expected cost 10 for weight 0.1 edge, got %f", path.Cost)
+	}
+}
+
+func TestFindPath_UseWeights(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	// The weak shortcut costs 5, the IS-A chain costs 2
+	path, err := sn.FindPath(PathQuery{FromID: "dog", ToID: "animal", Direction: PathForward, UseWeights: true})
+	if err != nil {
+		t.Fatalf("FindPath failed: %v", err)
+	}
+	if path.Hops() != 2 || path.Cost != 2 {
+		t.Errorf("Expected 2-hop path with cost 2, got %d hops cost %f", path.Hops(), path.Cost)
+	}
+}
+
+func TestFindPath_NodeNotFound(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+
+	if _, err := sn.FindPath(PathQuery{FromID: "dog", ToID: "unicorn"}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("Expected ErrNodeNotFound, got %v", err)
+	}
+}
+
+func TestInferMembership_ReasoningUsesIsAPath(t *testing.T) {
+	sn := buildPathTestNetwork(t)
+	engine := NewSemanticInferenceEngine(sn)
+
+	result, err := engine.InferMembership("dog", "animal")
+	if err != nil {
+		t.Fatalf("InferMembership failed: %v", err)
+	}
+	if len(result.Reasoning) != 2 {
+		t.Fatalf("Expected 2 reasoning steps, got %v", result.Reasoning)
+	}
+	if result.Reasoning[0] != "dog is-a mammal" || result.Reasoning[1] != "mammal is-a animal" {
+		t.Errorf("Unexpected reasoning chain: %v", result.Reasoning)
+	}
+}
+
+func TestPathDirection_String(t *testing.T) {
+	tests := []struct {
+		direction PathDirection
+		expected  string
+	}{
+		{PathForward, "forward"},
+		{PathBackward, "backward"},
+		{PathBoth, "both"},
+		{PathDirection(99), "unknown"},
+	}
+
+	for _, tc := range tests {
+		if got := tc.direction.String(); got != tc.expected {
+			t.Errorf("PathDirection(%d).String() = %s, want %s", tc.direction, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements persisted Semantic Network snapshots and the index
+// rebuild and integrity checks run when loading them.
+//
+// A snapshot file holds a format version, the JSON snapshot with typed
+// property values, and a SHA-256 checksum of it, so a truncated or edited
+// file is rejected instead of silently restoring a partial graph. Older
+// format versions are migrated on load (see semantic_migrations.go). Files are written to a temporary
+// path and renamed, so a crash mid-save leaves the previous snapshot intact.
+
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrSnapshotCorrupt is returned when a snapshot file fails verification
+	ErrSnapshotCorrupt = errdefs.New(errdefs.ErrInternal, "snapshot file is corrupt")
+	// ErrIntegrityViolation is returned when the network's indexes disagree
+	ErrIntegrityViolation = errdefs.New(errdefs.ErrInternal, "semantic network integrity violation")
+)
+
+// maxIntegrityProblems bounds the problems listed in an integrity error.
+const maxIntegrityProblems = 5
+
+// ============================================================================
+// Snapshot Files
+// ============================================================================
+
+// snapshotFile is the on-disk envelope of a snapshot.
+type snapshotFile struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// snapshotPayload is the snapshot content of the current format version.
+type snapshotPayload struct {
+	Nodes     []snapshotNode        `json:"nodes"`
+	Relations []snapshotRelation    `json:"relations"`
+	Stats     *SemanticNetworkStats `json:"stats,omitempty"`
+	Timestamp time.Time             `json:"timestamp"`
+	LSN       uint64                `json:"lsn"`
+}
+
+// snapshotNode is the persisted form of a node. Types are stored by name
+// so adding a NodeType doesn't renumber existing files.
+type snapshotNode struct {
+	ID             string              `json:"id"`
+	Label          string              `json:"label"`
+	Type           string              `json:"type"`
+	Activation     float64             `json:"activation"`
+	BaseActivation float64             `json:"base_activation"`
+	Properties     map[string]walValue `json:"properties,omitempty"`
+	Embedding      []float32           `json:"embedding,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	LastAccessed   time.Time           `json:"last_accessed"`
+	AccessCount    int64               `json:"access_count"`
+	Confidence     float64             `json:"confidence"`
+	Source         string              `json:"source"`
+}
+
+// snapshotRelation is the persisted form of a relation.
+type snapshotRelation struct {
+	ID         string              `json:"id"`
+	SourceID   string              `json:"source_id"`
+	TargetID   string              `json:"target_id"`
+	Type       string              `json:"type"`
+	Weight     float64             `json:"weight"`
+	Properties map[string]walValue `json:"properties,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Confidence float64             `json:"confidence"`
+	Source     string              `json:"source"`
+}
+
+// SaveSnapshotFile writes a snapshot to path atomically in the current
+// format version.
+func SaveSnapshotFile(path string, snapshot *SemanticNetworkSnapshot) error {
+	payload := snapshotPayload{
+		Nodes:     make([]snapshotNode, 0, len(snapshot.Nodes)),
+		Relations: make([]snapshotRelation, 0, len(snapshot.Relations)),
+		Stats:     snapshot.Stats,
+		Timestamp: snapshot.Timestamp,
+		LSN:       snapshot.LSN,
+	}
+	for _, node := range snapshot.Nodes {
+		props, err := encodeWALValues(node.Properties)
+		if err != nil {
+			return fmt.Errorf("%w: node %s: %v", ErrPersistenceFailed, node.ID, err)
+		}
+		payload.Nodes = append(payload.Nodes, snapshotNode{
+			ID:             node.ID,
+			Label:          node.Label,
+			Type:           node.Type.String(),
+			Activation:     node.Activation,
+			BaseActivation: node.BaseActivation,
+			Properties:     props,
+			Embedding:      node.Embedding,
+			CreatedAt:      node.CreatedAt,
+			LastAccessed:   node.LastAccessed,
+			AccessCount:    node.AccessCount,
+			Confidence:     node.Confidence,
+			Source:         node.Source,
+		})
+	}
+	for _, rel := range snapshot.Relations {
+		props, err := encodeWALValues(rel.Properties)
+		if err != nil {
+			return fmt.Errorf("%w: relation %s: %v", ErrPersistenceFailed, rel.ID, err)
+		}
+		payload.Relations = append(payload.Relations, snapshotRelation{
+			ID:         rel.ID,
+			SourceID:   rel.SourceID,
+			TargetID:   rel.TargetID,
+			Type:       rel.Type.String(),
+			Weight:     rel.Weight,
+			Properties: props,
+			CreatedAt:  rel.CreatedAt,
+			Confidence: rel.Confidence,
+			Source:     rel.Source,
+		})
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	return writeSnapshotFile(path, snapshotMigrations.Latest(), raw)
+}
+
+// writeSnapshotFile checksums a payload and writes it to path atomically.
+func writeSnapshotFile(path string, version int, payload []byte) error {
+	sum := sha256.Sum256(payload)
+	data, err := json.Marshal(snapshotFile{
+		Version:  version,
+		Checksum: hex.EncodeToString(sum[:]),
+		Payload:  payload,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	return nil
+}
+
+// readSnapshotFile reads a snapshot envelope and verifies its checksum.
+func readSnapshotFile(path string) (*snapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	sum := sha256.Sum256(file.Payload)
+	if hex.EncodeToString(sum[:]) != file.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupt)
+	}
+	return &file, nil
+}
+
+// LoadSnapshotFile reads and verifies a snapshot written by SaveSnapshotFile.
+// Files from older releases are migrated to the current format in memory;
+// the file itself is left unchanged.
+func LoadSnapshotFile(path string) (*SemanticNetworkSnapshot, error) {
+	file, err := readSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := []byte(file.Payload)
+	if file.Version != snapshotMigrations.Latest() {
+		raw, _, err = snapshotMigrations.MigrateJSON(raw, file.Version, snapshotMigrations.Latest())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var payload snapshotPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+
+	snapshot := &SemanticNetworkSnapshot{
+		Nodes:     make([]*SemanticNode, 0, len(payload.Nodes)),
+		Relations: make([]*SemanticRelation, 0, len(payload.Relations)),
+		Stats:     payload.Stats,
+		Timestamp: payload.Timestamp,
+		LSN:       payload.LSN,
+	}
+	for _, entry := range payload.Nodes {
+		nodeType, ok := parseNodeType(entry.Type)
+		if !ok {
+			return nil, fmt.Errorf("%w: node %s has unknown type %q", ErrSnapshotCorrupt, entry.ID, entry.Type)
+		}
+		props, err := decodeWALValues(entry.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %s: %v", ErrSnapshotCorrupt, entry.ID, err)
+		}
+		snapshot.Nodes = append(snapshot.Nodes, &SemanticNode{
+			ID:             entry.ID,
+			Label:          entry.Label,
+			Type:           nodeType,
+			Activation:     entry.Activation,
+			BaseActivation: entry.BaseActivation,
+			Properties:     props,
+			Embedding:      entry.Embedding,
+			CreatedAt:      entry.CreatedAt,
+			LastAccessed:   entry.LastAccessed,
+			AccessCount:    entry.AccessCount,
+			Confidence:     entry.Confidence,
+			Source:         entry.Source,
+		})
+	}
+	for _, entry := range payload.Relations {
+		relType, ok := parseRelationType(entry.Type)
+		if !ok {
+			return nil, fmt.Errorf("%w: relation %s has unknown type %q", ErrSnapshotCorrupt, entry.ID, entry.Type)
+		}
+		props, err := decodeWALValues(entry.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("%w: relation %s: %v", ErrSnapshotCorrupt, entry.ID, err)
+		}
+		snapshot.Relations = append(snapshot.Relations, &SemanticRelation{
+			ID:         entry.ID,
+			SourceID:   entry.SourceID,
+			TargetID:   entry.TargetID,
+			Type:       relType,
+			Weight:     entry.Weight,
+			Properties: props,
+			CreatedAt:  entry.CreatedAt,
+			Confidence: entry.Confidence,
+			Source:     entry.Source,
+		})
+	}
+	return snapshot, nil
+}
+
+// ============================================================================
+// Index Rebuild and Integrity
+// ============================================================================
+
+// RebuildIndexes rebuilds the adjacency lists and secondary indexes from
+// the node and relation tables. progress, if set, is called as nodes and
+// relations are processed; it runs under the write lock and must be quick.
+func (sn *SemanticNetwork) RebuildIndexes(progress func(done, total int)) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	sn.outgoing = make(map[string][]*SemanticRelation, len(sn.nodes))
+	sn.incoming = make(map[string][]*SemanticRelation, len(sn.nodes))
+	sn.typeIndex = make(map[NodeType]map[string]*SemanticNode)
+	sn.relationTypeIndex = make(map[RelationType]map[string]*SemanticRelation)
+	sn.propertyIndex = newPropertyIndex(sn.config.IndexedProperties)
+	sn.propertyIndexed = make(map[string]map[string]string)
+	sn.nameIndex = make(map[string]map[string]*SemanticNode)
+	sn.nameIndexed = make(map[string][]string)
+	sn.invalidateDepthCache()
+
+	total := len(sn.nodes) + len(sn.relations)
+	done := 0
+	for id, node := range sn.nodes {
+		sn.outgoing[id] = make([]*SemanticRelation, 0)
+		sn.incoming[id] = make([]*SemanticRelation, 0)
+		sn.indexNode(node)
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	// Sorted so adjacency order doesn't depend on map iteration
+	ids := make([]string, 0, len(sn.relations))
+	for id := range sn.relations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rel := sn.relations[id]
+		sn.outgoing[rel.SourceID] = append(sn.outgoing[rel.SourceID], rel)
+		sn.incoming[rel.TargetID] = append(sn.incoming[rel.TargetID], rel)
+		sn.indexRelation(rel)
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+}
+
+// VerifyIntegrity checks that relations connect existing nodes and that the
+// adjacency lists and indexes agree with the node and relation tables.
+func (sn *SemanticNetwork) VerifyIntegrity() error {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	problems := make([]string, 0)
+	for id, rel := range sn.relations {
+		if _, ok := sn.nodes[rel.SourceID]; !ok {
+			problems = append(problems, fmt.Sprintf("relation %s has missing source %s", id, rel.SourceID))
+		}
+		if _, ok := sn.nodes[rel.TargetID]; !ok {
+			problems = append(problems, fmt.Sprintf("relation %s has missing target %s", id, rel.TargetID))
+		}
+		if !containsRelation(sn.outgoing[rel.SourceID], rel) || !containsRelation(sn.incoming[rel.TargetID], rel) {
+			problems = append(problems, fmt.Sprintf("relation %s missing from adjacency lists", id))
+		}
+		if sn.relationTypeIndex[rel.Type][id] != rel {
+			problems = append(problems, fmt.Sprintf("relation %s missing from type index", id))
+		}
+	}
+	for id, rels := range sn.outgoing {
+		for _, rel := range rels {
+			if sn.relations[rel.ID] != rel {
+				problems = append(problems, fmt.Sprintf("node %s lists unknown relation %s", id, rel.ID))
+			}
+		}
+	}
+
+	indexedNodes := 0
+	for _, bucket := range sn.typeIndex {
+		indexedNodes += len(bucket)
+	}
+	for id, node := range sn.nodes {
+		if sn.typeIndex[node.Type][id] != node {
+			problems = append(problems, fmt.Sprintf("node %s missing from type index", id))
+		}
+		for key, values := range sn.propertyIndex {
+			valueKey, ok := propertyIndexKey(node.Properties[key])
+			if _, has := node.Properties[key]; has && ok && values[valueKey][id] != node {
+				problems = append(problems, fmt.Sprintf("node %s missing from %s index", id, key))
+			}
+		}
+	}
+	if indexedNodes != len(sn.nodes) {
+		problems = append(problems, fmt.Sprintf("type index holds %d nodes, network has %d", indexedNodes, len(sn.nodes)))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	shown := problems[:min(len(problems), maxIntegrityProblems)]
+	return fmt.Errorf("%w: %d problems: %s", ErrIntegrityViolation, len(problems), strings.Join(shown, "; "))
+}
+
+// containsRelation returns true if rels holds rel.
+func containsRelation(rels []*SemanticRelation, rel *SemanticRelation) bool {
+	for _, r := range rels {
+		if r == rel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Snapshot File Tests
+// ============================================================================
+
+func TestSaveSnapshotFile_RoundTrip(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sorting := NewSemanticNode("sorting", "Sorting", ConceptNode)
+	sorting.SetProperty("complexity", NumberValue(2, ""))
+	sorting.SetProperty("stable", false)
+	sn.AddNode(sorting)
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	sn.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+
+	path := filepath.Join(t.TempDir(), "semantic.snapshot")
+	if err := SaveSnapshotFile(path, sn.Snapshot()); err != nil {
+		t.Fatalf("SaveSnapshotFile failed: %v", err)
+	}
+	snapshot, err := LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFile failed: %v", err)
+	}
+
+	restored := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	restored.Restore(snapshot)
+	if restored.NodeCount() != 2 || restored.RelationCount() != 1 {
+		t.Errorf("Expected 2 nodes and 1 relation, got %d and %d", restored.NodeCount(), restored.RelationCount())
+	}
+	node, _ := restored.GetNode("sorting")
+	if !valuesEqual(node.Properties["complexity"], NumberValue(2, "")) {
+		t.Errorf("Expected typed complexity 2, got %#v", node.Properties["complexity"])
+	}
+	if node.Properties["stable"] != false {
+		t.Errorf("Expected stable false, got %#v", node.Properties["stable"])
+	}
+}
+
+func TestLoadSnapshotFile_Corrupt(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("sorting", "Sorting", ConceptNode))
+	path := filepath.Join(t.TempDir(), "semantic.snapshot")
+	SaveSnapshotFile(path, sn.Snapshot())
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "Sorting", "Sortinq", 1)), 0o644)
+	if _, err := LoadSnapshotFile(path); !errors.Is(err, ErrSnapshotCorrupt) {
+		t.Errorf("Expected ErrSnapshotCorrupt for edited payload, got %v", err)
+	}
+
+	os.WriteFile(path, data[:len(data)/2], 0o644)
+	if _, err := LoadSnapshotFile(path); !errors.Is(err, ErrSnapshotCorrupt) {
+		t.Errorf("Expected ErrSnapshotCorrupt for truncated file, got %v", err)
+	}
+
+	if _, err := LoadSnapshotFile(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
+
+// ============================================================================
+// Index Rebuild and Integrity Tests
+// ============================================================================
+
+func TestSemanticNetwork_RebuildIndexes(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"tier"}
+	sn := NewSemanticNetwork(config)
+	apex := NewSemanticNode("apex", "APEX", AgentNode)
+	apex.SetProperty("tier", NumberValue(1, ""))
+	sn.AddNode(apex)
+	sn.AddNode(NewSemanticNode("tier-1", "Tier 1", DomainNode))
+	sn.AddRelation(NewSemanticRelation("apex", "tier-1", BelongsTo))
+
+	// Damage the indexes, then rebuild them from the tables
+	sn.typeIndex = make(map[NodeType]map[string]*SemanticNode)
+	sn.outgoing["apex"] = nil
+	if err := sn.VerifyIntegrity(); !errors.Is(err, ErrIntegrityViolation) {
+		t.Fatalf("Expected ErrIntegrityViolation, got %v", err)
+	}
+
+	calls, lastDone, lastTotal := 0, 0, 0
+	sn.RebuildIndexes(func(done, total int) {
+		calls++
+		lastDone, lastTotal = done, total
+	})
+	if calls != 3 || lastDone != 3 || lastTotal != 3 {
+		t.Errorf("Expected progress 3/3 over 3 calls, got %d/%d over %d", lastDone, lastTotal, calls)
+	}
+	if err := sn.VerifyIntegrity(); err != nil {
+		t.Errorf("Expected integrity after rebuild, got %v", err)
+	}
+	if len(sn.FindNodesByProperty("tier", NumberValue(1, ""))) != 1 {
+		t.Error("Expected property index rebuilt")
+	}
+	if len(sn.GetOutgoingRelations("apex")) != 1 {
+		t.Error("Expected adjacency rebuilt")
+	}
+}
+
+func TestSemanticNetwork_VerifyIntegrity(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("a", "A", ConceptNode))
+	sn.AddNode(NewSemanticNode("b", "B", ConceptNode))
+	sn.AddRelation(NewSemanticRelation("a", "b", RelatedTo))
+	if err := sn.VerifyIntegrity(); err != nil {
+		t.Fatalf("Expected healthy network, got %v", err)
+	}
+
+	// A relation whose target vanished without going through RemoveNode
+	delete(sn.nodes, "b")
+	err := sn.VerifyIntegrity()
+	if !errors.Is(err, ErrIntegrityViolation) || !strings.Contains(err.Error(), "missing target b") {
+		t.Errorf("Expected missing target violation, got %v", err)
+	}
+}
@@ -0,0 +1,469 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements typed property values for the Semantic Network.
+//
+// Node properties are stored as interface{} for flexibility, but comparing
+// them by their formatted string made 1 and 1.0 unequal, "1s" and
+// time.Second unequal, and allowed any value anywhere. PropertyValue gives
+// properties a kind (string/number/bool/duration/enum), an optional unit for
+// numbers, and proper equality and ordering. PropertySchema adds per-key
+// validation (kind, unit, range, enum membership) enforced by the network.
+
+package memory
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidPropertyValue indicates a property violates its schema
+	ErrInvalidPropertyValue = errdefs.New(errdefs.ErrInvalidArgument, "invalid property value")
+	// ErrIncomparableProperties indicates two property values cannot be ordered
+	ErrIncomparableProperties = errdefs.New(errdefs.ErrInvalidArgument, "incomparable property values")
+)
+
+// ============================================================================
+// Property Kinds
+// ============================================================================
+
+// PropertyKind identifies the type of a property value.
+type PropertyKind int
+
+const (
+	// PropertyString is free-form text
+	PropertyString PropertyKind = iota
+	// PropertyNumber is a float64 with an optional unit
+	PropertyNumber
+	// PropertyBool is true/false
+	PropertyBool
+	// PropertyDuration is a time.Duration
+	PropertyDuration
+	// PropertyEnum is one of a fixed set of symbols
+	PropertyEnum
+)
+
+// String returns the string representation of a PropertyKind.
+func (k PropertyKind) String() string {
+	switch k {
+	case PropertyString:
+		return "string"
+	case PropertyNumber:
+		return "number"
+	case PropertyBool:
+		return "bool"
+	case PropertyDuration:
+		return "duration"
+	case PropertyEnum:
+		return "enum"
+	default:
+		return "unknown"
+	}
+}
+
+// ============================================================================
+// Units
+// ============================================================================
+
+// unitScale maps a unit to its dimension and its factor to the base unit.
+type unitScale struct {
+	dimension string
+	factor    float64
+}
+
+// knownUnits lists units that can be converted within their dimension.
+// Unknown units are still allowed but only equal themselves.
+var knownUnits = map[string]unitScale{
+	"ns":  {"time", 1e-9},
+	"us":  {"time", 1e-6},
+	"ms":  {"time", 1e-3},
+	"s":   {"time", 1},
+	"min": {"time", 60},
+	"h":   {"time", 3600},
+	"B":   {"bytes", 1},
+	"KB":  {"bytes", 1 << 10},
+	"MB":  {"bytes", 1 << 20},
+	"GB":  {"bytes", 1 << 30},
+	"%":   {"ratio", 0.01},
+}
+
+// normalizeUnit converts a number to the base unit of its dimension.
+func normalizeUnit(value float64, unit string) (float64, string) {
+	if scale, ok := knownUnits[unit]; ok {
+		return value * scale.factor, scale.dimension
+	}
+	return value, unit
+}
+
+// ============================================================================
+// Property Value
+// ============================================================================
+
+// PropertyValue is a typed semantic property.
+type PropertyValue struct {
+	// Kind is the value type
+	Kind PropertyKind
+	// Str holds string and enum values
+	Str string
+	// Num holds number values
+	Num float64
+	// Unit qualifies number values (e.g. "ms", "MB"); empty for unitless
+	Unit string
+	// Bool holds bool values
+	Bool bool
+	// Duration holds duration values
+	Duration time.Duration
+}
+
+// StringValue creates a string property value.
+func StringValue(s string) PropertyValue {
+	return PropertyValue{Kind: PropertyString, Str: s}
+}
+
+// NumberValue creates a number property value with an optional unit.
+func NumberValue(n float64, unit string) PropertyValue {
+	return PropertyValue{Kind: PropertyNumber, Num: n, Unit: unit}
+}
+
+// BoolValue creates a bool property value.
+func BoolValue(b bool) PropertyValue {
+	return PropertyValue{Kind: PropertyBool, Bool: b}
+}
+
+// DurationValue creates a duration property value.
+func DurationValue(d time.Duration) PropertyValue {
+	return PropertyValue{Kind: PropertyDuration, Duration: d}
+}
+
+// EnumValue creates an enum property value.
+func EnumValue(symbol string) PropertyValue {
+	return PropertyValue{Kind: PropertyEnum, Str: symbol}
+}
+
+// AsPropertyValue converts a raw property into a typed value.
+// Returns false for types that have no property kind.
+func AsPropertyValue(raw interface{}) (PropertyValue, bool) {
+	switch v := raw.(type) {
+	case PropertyValue:
+		return v, true
+	case *PropertyValue:
+		if v == nil {
+			return PropertyValue{}, false
+		}
+		return *v, true
+	case string:
+		return StringValue(v), true
+	case bool:
+		return BoolValue(v), true
+	case time.Duration:
+		return DurationValue(v), true
+	case float64:
+		return NumberValue(v, ""), true
+	case float32:
+		return NumberValue(float64(v), ""), true
+	case int:
+		return NumberValue(float64(v), ""), true
+	case int8:
+		return NumberValue(float64(v), ""), true
+	case int16:
+		return NumberValue(float64(v), ""), true
+	case int32:
+		return NumberValue(float64(v), ""), true
+	case int64:
+		return NumberValue(float64(v), ""), true
+	case uint:
+		return NumberValue(float64(v), ""), true
+	case uint8:
+		return NumberValue(float64(v), ""), true
+	case uint16:
+		return NumberValue(float64(v), ""), true
+	case uint32:
+		return NumberValue(float64(v), ""), true
+	case uint64:
+		return NumberValue(float64(v), ""), true
+	default:
+		return PropertyValue{}, false
+	}
+}
+
+// String returns a human-readable form of the value.
+func (v PropertyValue) String() string {
+	switch v.Kind {
+	case PropertyString, PropertyEnum:
+		return v.Str
+	case PropertyNumber:
+		s := strconv.FormatFloat(v.Num, 'g', -1, 64)
+		if v.Unit != "" {
+			return s + " " + v.Unit
+		}
+		return s
+	case PropertyBool:
+		return strconv.FormatBool(v.Bool)
+	case PropertyDuration:
+		return v.Duration.String()
+	default:
+		return ""
+	}
+}
+
+// Interface returns the value as a plain Go value.
+func (v PropertyValue) Interface() interface{} {
+	switch v.Kind {
+	case PropertyString, PropertyEnum:
+		return v.Str
+	case PropertyNumber:
+		return v.Num
+	case PropertyBool:
+		return v.Bool
+	case PropertyDuration:
+		return v.Duration
+	default:
+		return nil
+	}
+}
+
+// Equal reports whether two values are the same. Numbers in convertible
+// units (e.g. 1 s and 1000 ms) are equal.
+func (v PropertyValue) Equal(other PropertyValue) bool {
+	if v.Kind != other.Kind {
+		return false
+	}
+	switch v.Kind {
+	case PropertyString, PropertyEnum:
+		return v.Str == other.Str
+	case PropertyBool:
+		return v.Bool == other.Bool
+	case PropertyDuration:
+		return v.Duration == other.Duration
+	case PropertyNumber:
+		a, dimA := normalizeUnit(v.Num, v.Unit)
+		b, dimB := normalizeUnit(other.Num, other.Unit)
+		if dimA != dimB {
+			return false
+		}
+		return floatsEqual(a, b)
+	default:
+		return false
+	}
+}
+
+// Compare orders two values, returning -1, 0 or 1. Numbers must share a
+// dimension; enums are unordered and only compare equal or not.
+func (v PropertyValue) Compare(other PropertyValue) (int, error) {
+	if v.Kind != other.Kind {
+		return 0, fmt.Errorf("%w: %s vs %s", ErrIncomparableProperties, v.Kind, other.Kind)
+	}
+	switch v.Kind {
+	case PropertyString:
+		return compareOrdered(v.Str, other.Str), nil
+	case PropertyBool:
+		if v.Bool == other.Bool {
+			return 0, nil
+		}
+		if !v.Bool {
+			return -1, nil
+		}
+		return 1, nil
+	case PropertyDuration:
+		return compareOrdered(v.Duration, other.Duration), nil
+	case PropertyNumber:
+		a, dimA := normalizeUnit(v.Num, v.Unit)
+		b, dimB := normalizeUnit(other.Num, other.Unit)
+		if dimA != dimB {
+			return 0, fmt.Errorf("%w: units %q and %q", ErrIncomparableProperties, v.Unit, other.Unit)
+		}
+		if floatsEqual(a, b) {
+			return 0, nil
+		}
+		return compareOrdered(a, b), nil
+	case PropertyEnum:
+		if v.Str == other.Str {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: enum values are unordered", ErrIncomparableProperties)
+	default:
+		return 0, fmt.Errorf("%w: unknown kind", ErrIncomparableProperties)
+	}
+}
+
+// compareOrdered returns -1, 0 or 1.
+func compareOrdered[T string | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// floatsEqual compares floats with a relative tolerance so unit conversion
+// rounding doesn't break equality.
+func floatsEqual(a, b float64) bool {
+	scale := math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+	return math.Abs(a-b) <= 1e-9*scale
+}
+
+// ============================================================================
+// Property Schema
+// ============================================================================
+
+// PropertySchema constrains the values a property key may take.
+type PropertySchema struct {
+	// Kind is the required value kind
+	Kind PropertyKind
+	// Unit is the expected unit for numbers; values in a convertible unit
+	// are accepted. Empty accepts unitless numbers only.
+	Unit string
+	// Min is the inclusive lower bound for numbers and durations
+	Min *PropertyValue
+	// Max is the inclusive upper bound for numbers and durations
+	Max *PropertyValue
+	// EnumValues lists the allowed symbols for enums
+	EnumValues []string
+}
+
+// Validate checks a raw or typed value against the schema and returns it
+// as a PropertyValue.
+func (s PropertySchema) Validate(raw interface{}) (PropertyValue, error) {
+	value, ok := AsPropertyValue(raw)
+	if !ok {
+		return PropertyValue{}, fmt.Errorf("%w: unsupported type %T", ErrInvalidPropertyValue, raw)
+	}
+
+	// Plain strings are accepted for enum-typed keys
+	if s.Kind == PropertyEnum && value.Kind == PropertyString {
+		value = EnumValue(value.Str)
+	}
+	if value.Kind != s.Kind {
+		return PropertyValue{}, fmt.Errorf("%w: expected %s, got %s", ErrInvalidPropertyValue, s.Kind, value.Kind)
+	}
+
+	if s.Kind == PropertyNumber {
+		_, want := normalizeUnit(0, s.Unit)
+		_, got := normalizeUnit(0, value.Unit)
+		if want != got {
+			return PropertyValue{}, fmt.Errorf("%w: unit %q not convertible to %q", ErrInvalidPropertyValue, value.Unit, s.Unit)
+		}
+	}
+
+	if s.Kind == PropertyEnum {
+		allowed := false
+		for _, symbol := range s.EnumValues {
+			if symbol == value.Str {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return PropertyValue{}, fmt.Errorf("%w: %q not in %v", ErrInvalidPropertyValue, value.Str, s.EnumValues)
+		}
+	}
+
+	if s.Min != nil {
+		if cmp, err := value.Compare(*s.Min); err != nil || cmp < 0 {
+			return PropertyValue{}, fmt.Errorf("%w: %s below minimum %s", ErrInvalidPropertyValue, value, s.Min)
+		}
+	}
+	if s.Max != nil {
+		if cmp, err := value.Compare(*s.Max); err != nil || cmp > 0 {
+			return PropertyValue{}, fmt.Errorf("%w: %s above maximum %s", ErrInvalidPropertyValue, value, s.Max)
+		}
+	}
+
+	return value, nil
+}
+
+// ============================================================================
+// Network Integration
+// ============================================================================
+
+// RegisterPropertySchema sets the schema for a property key. Nodes added or
+// updated afterwards must satisfy it; existing nodes are not re-checked.
+func (sn *SemanticNetwork) RegisterPropertySchema(key string, schema PropertySchema) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	sn.propertySchemas[key] = schema
+}
+
+// GetPropertySchema returns the schema registered for a property key.
+func (sn *SemanticNetwork) GetPropertySchema(key string) (PropertySchema, bool) {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	schema, ok := sn.propertySchemas[key]
+	return schema, ok
+}
+
+// SetNodeProperty validates and sets a property on a node in the network.
+func (sn *SemanticNetwork) SetNodeProperty(nodeID, key string, value interface{}) error {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	node, exists := sn.nodes[nodeID]
+	if !exists {
+		return ErrNodeNotFound
+	}
+
+	if schema, ok := sn.propertySchemas[key]; ok {
+		typed, err := schema.Validate(value)
+		if err != nil {
+			return fmt.Errorf("property %s on %s: %w", key, nodeID, err)
+		}
+		value = typed
+	}
+	if err := sn.logProperty(nodeID, key, value); err != nil {
+		return err
+	}
+
+	node = sn.mutableNode(node)
+	sn.unindexNode(node)
+	node.Properties[key] = value
+	sn.indexNode(node)
+	sn.stats.LastUpdated = time.Now()
+	return nil
+}
+
+// validateProperties checks every property with a registered schema and
+// replaces raw values with their typed form, leaving the node as it was
+// unless every property passes. Caller must hold sn.mu.
+func (sn *SemanticNetwork) validateProperties(node *SemanticNode) error {
+	if len(sn.propertySchemas) == 0 {
+		return nil
+	}
+	typedValues := make(map[string]interface{})
+	for key, raw := range node.Properties {
+		schema, ok := sn.propertySchemas[key]
+		if !ok {
+			continue
+		}
+		typed, err := schema.Validate(raw)
+		if err != nil {
+			return fmt.Errorf("property %s on %s: %w", key, node.ID, err)
+		}
+		typedValues[key] = typed
+	}
+	for key, typed := range typedValues {
+		node.Properties[key] = typed
+	}
+	return nil
+}
+
+// GetTypedProperty returns a node property as a typed value.
+func (n *SemanticNode) GetTypedProperty(key string) (PropertyValue, bool) {
+	raw, ok := n.Properties[key]
+	if !ok {
+		return PropertyValue{}, false
+	}
+	return AsPropertyValue(raw)
+}
+
+// TypedValue returns the inherited property as a typed value.
+func (p *InheritedProperty) TypedValue() (PropertyValue, bool) {
+	return AsPropertyValue(p.Value)
+}
@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Property Value Tests
+// ============================================================================
+
+func TestAsPropertyValue(t *testing.T) {
+	tests := []struct {
+		raw  interface{}
+		kind PropertyKind
+		ok   bool
+	}{
+		{"text", PropertyString, true},
+		{42, PropertyNumber, true},
+		{int64(42), PropertyNumber, true},
+		{float32(1.5), PropertyNumber, true},
+		{true, PropertyBool, true},
+		{time.Second, PropertyDuration, true},
+		{EnumValue("high"), PropertyEnum, true},
+		{[]string{"x"}, 0, false},
+		{nil, 0, false},
+	}
+
+	for _, tc := range tests {
+		value, ok := AsPropertyValue(tc.raw)
+		if ok != tc.ok {
+			t.Errorf("AsPropertyValue(%v) ok = %v, want %v", tc.raw, ok, tc.ok)
+			continue
+		}
+		if ok && value.Kind != tc.kind {
+			t.Errorf("AsPropertyValue(%v) kind = %s, want %s", tc.raw, value.Kind, tc.kind)
+		}
+	}
+}
+
+func TestPropertyValue_Equal(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     PropertyValue
+		expected bool
+	}{
+		{"same string", StringValue("x"), StringValue("x"), true},
+		{"different string", StringValue("x"), StringValue("y"), false},
+		{"int and float", NumberValue(1, ""), NumberValue(1.0, ""), true},
+		{"convertible units", NumberValue(1, "s"), NumberValue(1000, "ms"), true},
+		{"bytes", NumberValue(2, "KB"), NumberValue(2048, "B"), true},
+		{"incompatible units", NumberValue(1, "s"), NumberValue(1, "MB"), false},
+		{"unit vs unitless", NumberValue(1, "s"), NumberValue(1, ""), false},
+		{"string vs enum", StringValue("high"), EnumValue("high"), false},
+		{"durations", DurationValue(time.Minute), DurationValue(60 * time.Second), true},
+	}
+
+	for _, tc := range tests {
+		if got := tc.a.Equal(tc.b); got != tc.expected {
+			t.Errorf("%s: %s.Equal(%s) = %v, want %v", tc.name, tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestPropertyValue_Compare(t *testing.T) {
+	cmp, err := NumberValue(500, "ms").Compare(NumberValue(1, "s"))
+	if err != nil || cmp != -1 {
+		t.Errorf("Expected 500ms < 1s, got %d (%v)", cmp, err)
+	}
+
+	cmp, err = DurationValue(2 * time.Second).Compare(DurationValue(time.Second))
+	if err != nil || cmp != 1 {
+		t.Errorf("Expected 2s > 1s, got %d (%v)", cmp, err)
+	}
+
+	cmp, err = BoolValue(false).Compare(BoolValue(true))
+	if err != nil || cmp != -1 {
+		t.Errorf("Expected false < true, got %d (%v)", cmp, err)
+	}
+
+	if _, err := NumberValue(1, "s").Compare(NumberValue(1, "MB")); !errors.Is(err, ErrIncomparableProperties) {
+		t.Errorf("Expected ErrIncomparableProperties for mixed units, got %v", err)
+	}
+	if _, err := EnumValue("a").Compare(EnumValue("b")); !errors.Is(err, ErrIncomparableProperties) {
+		t.Errorf("Expected enums to be unordered, got %v", err)
+	}
+	if _, err := StringValue("1").Compare(NumberValue(1, "")); !errors.Is(err, ErrIncomparableProperties) {
+		t.Errorf("Expected ErrIncomparableProperties for mixed kinds, got %v", err)
+	}
+}
+
+func TestPropertyValue_String(t *testing.T) {
+	tests := []struct {
+		value    PropertyValue
+		expected string
+	}{
+		{NumberValue(250, "ms"), "250 ms"},
+		{NumberValue(0.5, ""), "0.5"},
+		{BoolValue(true), "true"},
+		{DurationValue(90 * time.Second), "1m30s"},
+		{EnumValue("high"), "high"},
+	}
+
+	for _, tc := range tests {
+		if got := tc.value.String(); got != tc.expected {
+			t.Errorf("String() = %q, want %q", got, tc.expected)
+		}
+	}
+}
+
+func TestPropertySchema_Validate(t *testing.T) {
+	minLatency := NumberValue(0, "ms")
+	maxLatency := NumberValue(10, "s")
+	latency := PropertySchema{Kind: PropertyNumber, Unit: "ms", Min: &minLatency, Max: &maxLatency}
+
+	if _, err := latency.Validate(NumberValue(250, "ms")); err != nil {
+		t.Errorf("Expected 250ms to be valid, got %v", err)
+	}
+	if _, err := latency.Validate(NumberValue(2, "s")); err != nil {
+		t.Errorf("Expected 2s to be valid via unit conversion, got %v", err)
+	}
+	if _, err := latency.Validate(NumberValue(11, "s")); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected 11s to exceed max, got %v", err)
+	}
+	if _, err := latency.Validate(NumberValue(-1, "ms")); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected -1ms to be below min, got %v", err)
+	}
+	if _, err := latency.Validate(NumberValue(5, "MB")); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected MB to be rejected, got %v", err)
+	}
+	if _, err := latency.Validate("fast"); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected string to be rejected, got %v", err)
+	}
+
+	priority := PropertySchema{Kind: PropertyEnum, EnumValues: []string{"low", "high"}}
+	value, err := priority.Validate("high")
+	if err != nil {
+		t.Fatalf("Expected plain string enum to be accepted, got %v", err)
+	}
+	if value.Kind != PropertyEnum {
+		t.Errorf("Expected enum kind, got %s", value.Kind)
+	}
+	if _, err := priority.Validate("urgent"); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected unknown enum symbol to be rejected, got %v", err)
+	}
+}
+
+func TestSemanticNetwork_PropertySchemaEnforcement(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.RegisterPropertySchema("priority", PropertySchema{Kind: PropertyEnum, EnumValues: []string{"low", "high"}})
+
+	bad := NewSemanticNode("bad", "Bad", ConceptNode)
+	bad.SetProperty("priority", "urgent")
+	if err := sn.AddNode(bad); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected ErrInvalidPropertyValue from AddNode, got %v", err)
+	}
+
+	// A rejected node keeps its raw values, whichever key failed first
+	sn.RegisterPropertySchema("severity", PropertySchema{Kind: PropertyEnum, EnumValues: []string{"minor", "major"}})
+	for i := 0; i < 20; i++ {
+		mixed := NewSemanticNode("mixed", "Mixed", ConceptNode)
+		mixed.SetProperty("priority", "high")
+		mixed.SetProperty("severity", "catastrophic")
+		if err := sn.AddNode(mixed); !errors.Is(err, ErrInvalidPropertyValue) {
+			t.Fatalf("Expected ErrInvalidPropertyValue from AddNode, got %v", err)
+		}
+		if raw, ok := mixed.Properties["priority"].(string); !ok || raw != "high" {
+			t.Fatalf("Expected priority left raw on a rejected node, got %#v", mixed.Properties["priority"])
+		}
+	}
+
+	good := NewSemanticNode("good", "Good", ConceptNode)
+	good.SetProperty("priority", "high")
+	if err := sn.AddNode(good); err != nil {
+		t.Fatalf("AddNode failed: %v", err)
+	}
+	value, ok := good.GetTypedProperty("priority")
+	if !ok || value.Kind != PropertyEnum {
+		t.Errorf("Expected priority to be stored as enum, got %+v", value)
+	}
+
+	if err := sn.SetNodeProperty("good", "priority", "urgent"); !errors.Is(err, ErrInvalidPropertyValue) {
+		t.Errorf("Expected ErrInvalidPropertyValue from SetNodeProperty, got %v", err)
+	}
+	if err := sn.SetNodeProperty("missing", "priority", "low"); err != ErrNodeNotFound {
+		t.Errorf("Expected ErrNodeNotFound, got %v", err)
+	}
+	// Keys without a schema are unconstrained
+	if err := sn.SetNodeProperty("good", "notes", []string{"free-form"}); err != nil {
+		t.Errorf("Expected unconstrained key to be accepted, got %v", err)
+	}
+}
+
+func TestConceptLearner_CommonPropertiesTyped(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+
+	// Same values expressed with different Go types and units
+	values := []interface{}{1000, 1000.0, NumberValue(1, "s")}
+	latencies := []interface{}{NumberValue(1, "s"), NumberValue(1000, "ms"), NumberValue(1, "s")}
+	for i := range values {
+		node := NewSemanticNode(fmt_Sprintf("n%d", i), "N", InstanceNode)
+		node.SetProperty("count", values[i])
+		node.SetProperty("latency", latencies[i])
+		node.SetProperty("ok", true)
+		sn.AddNode(node)
+	}
+
+	learner := NewConceptLearner(sn)
+	concept, err := learner.ExtractPrototype([]string{"n0", "n1", "n2"})
+	if err != nil {
+		t.Fatalf("ExtractPrototype failed: %v", err)
+	}
+
+	if _, ok := concept.CommonProperties["latency"]; !ok {
+		t.Error("Expected latency to be common across unit-equivalent values")
+	}
+	if _, ok := concept.CommonProperties["ok"]; !ok {
+		t.Error("Expected ok to be common")
+	}
+	// 1 s is not a unitless 1000
+	if _, ok := concept.CommonProperties["count"]; ok {
+		t.Error("Expected count not to be common across unitless and timed values")
+	}
+}
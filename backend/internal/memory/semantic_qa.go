@@ -0,0 +1,675 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements question answering over the Semantic Network.
+//
+// Questions are answered without an LLM, in three stages:
+// - Entity linking: the longest phrases matching node labels or IDs
+// - Relation lookup: relation phrases ("part of", "requires") select edges
+// - Inference: membership and inherited properties are derived through the
+//   IS-A hierarchy, and missing relations fall back to completion inference
+//
+// Every answer carries the supporting subgraph, so callers can show exactly
+// which stored facts it rests on.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrNoEntityFound indicates no node could be linked from the question
+	ErrNoEntityFound = errdefs.New(errdefs.ErrNotFound, "no known entity in question")
+	// ErrUnanswerable indicates the graph holds no facts that answer the question
+	ErrUnanswerable = errdefs.New(errdefs.ErrNotFound, "question cannot be answered from the knowledge graph")
+)
+
+// ============================================================================
+// Question Answering Types
+// ============================================================================
+
+// QuestionKind classifies how a question was answered.
+type QuestionKind int
+
+const (
+	// QuestionMembership asks whether one entity is a kind of another
+	QuestionMembership QuestionKind = iota
+	// QuestionRelation asks about a specific relation of an entity
+	QuestionRelation
+	// QuestionProperty asks for a property value
+	QuestionProperty
+	// QuestionConnection asks how two entities are connected
+	QuestionConnection
+	// QuestionDescription asks what an entity is
+	QuestionDescription
+)
+
+// String returns the string representation of a QuestionKind.
+func (k QuestionKind) String() string {
+	switch k {
+	case QuestionMembership:
+		return "membership"
+	case QuestionRelation:
+		return "relation"
+	case QuestionProperty:
+		return "property"
+	case QuestionConnection:
+		return "connection"
+	case QuestionDescription:
+		return "description"
+	default:
+		return "unknown"
+	}
+}
+
+// Subgraph is the set of nodes and relations supporting an answer.
+type Subgraph struct {
+	Nodes     []*SemanticNode
+	Relations []*SemanticRelation
+}
+
+// QAAnswer is the answer to a natural-language question.
+type QAAnswer struct {
+	Question string
+	Kind     QuestionKind
+	// Answer is a one-line natural-language answer
+	Answer string
+	// Value is the structured answer: a bool, a property value, or node IDs
+	Value      interface{}
+	Confidence float64
+	// Entities are the nodes linked from the question, in question order
+	Entities []*SemanticNode
+	// Relation is the relation type detected in the question, if any
+	Relation *RelationType
+	// Inferred is true when the answer was predicted rather than stored
+	Inferred  bool
+	Subgraph  *Subgraph
+	Reasoning []string
+}
+
+// QuestionAnswerer answers questions from the facts in a semantic network.
+type QuestionAnswerer struct {
+	network *SemanticNetwork
+	engine  *SemanticInferenceEngine
+	// MaxConnectionHops bounds the path search between two entities
+	MaxConnectionHops int
+}
+
+// NewQuestionAnswerer creates a question answerer for a network.
+func NewQuestionAnswerer(network *SemanticNetwork) *QuestionAnswerer {
+	return &QuestionAnswerer{
+		network:           network,
+		engine:            NewSemanticInferenceEngine(network),
+		MaxConnectionHops: 4,
+	}
+}
+
+// ============================================================================
+// Question Parsing
+// ============================================================================
+
+// relationPhrases map question wording to relation types. Longer phrases
+// are listed first so "part of" wins over shorter matches.
+var relationPhrases = []struct {
+	words   []string
+	relType RelationType
+}{
+	{[]string{"instance", "of"}, InstanceOf},
+	{[]string{"kind", "of"}, IsA},
+	{[]string{"type", "of"}, IsA},
+	{[]string{"subclass", "of"}, IsA},
+	{[]string{"is", "a"}, IsA},
+	{[]string{"is", "an"}, IsA},
+	{[]string{"are", "a"}, IsA},
+	{[]string{"part", "of"}, PartOf},
+	{[]string{"used", "for"}, UsedFor},
+	{[]string{"use", "for"}, UsedFor},
+	{[]string{"depend", "on"}, Requires},
+	{[]string{"depends", "on"}, Requires},
+	{[]string{"member", "of"}, BelongsTo},
+	{[]string{"opposite"}, OppositeOf},
+	{[]string{"similar"}, SimilarTo},
+	{[]string{"require"}, Requires},
+	{[]string{"requires"}, Requires},
+	{[]string{"need"}, Requires},
+	{[]string{"needs"}, Requires},
+	{[]string{"produce"}, Produces},
+	{[]string{"produces"}, Produces},
+	{[]string{"belong"}, BelongsTo},
+	{[]string{"belongs"}, BelongsTo},
+	{[]string{"can"}, CanDo},
+	{[]string{"has"}, HasA},
+	{[]string{"have"}, HasA},
+	{[]string{"related"}, RelatedTo},
+}
+
+// qaStopwords are never linked as single-word entities.
+var qaStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "is": true, "are": true, "of": true,
+	"what": true, "which": true, "who": true, "how": true, "does": true,
+	"do": true, "to": true, "in": true, "and": true, "or": true, "for": true,
+	"can": true, "has": true, "have": true, "it": true, "on": true,
+}
+
+// qaMaxEntityWords bounds the length of linked entity phrases.
+const qaMaxEntityWords = 6
+
+// parsedQuestion is a tokenized question with linked entities.
+type parsedQuestion struct {
+	tokens []string
+	// covered marks tokens consumed by entity links
+	covered  []bool
+	entities []*SemanticNode
+	// entityPos is the first token index of each entity
+	entityPos []int
+}
+
+// tokenizeQuestion lowercases and splits text into words, keeping hyphens
+// and underscores inside words and dropping possessive "'s".
+func tokenizeQuestion(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '\''
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSuffix(field, "'s")
+		field = strings.Trim(field, "'-_")
+		if field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// LinkEntities returns the nodes a text mentions, in text order.
+func (qa *QuestionAnswerer) LinkEntities(text string) []*SemanticNode {
+	return qa.linkEntities(text).entities
+}
+
+// linkEntities matches the longest token spans against node labels and IDs,
+// looked up in the network's name index.
+func (qa *QuestionAnswerer) linkEntities(question string) *parsedQuestion {
+	qa.network.mu.RLock()
+	defer qa.network.mu.RUnlock()
+
+	parsed, _ := qa.network.linkEntities(context.Background(), question)
+	return parsed
+}
+
+// linkEntities links the entities of text, giving up with ctx's error once
+// ctx is done. Caller must hold sn.mu.
+func (sn *SemanticNetwork) linkEntities(ctx context.Context, text string) (*parsedQuestion, error) {
+	parsed := &parsedQuestion{tokens: tokenizeQuestion(text)}
+	parsed.covered = make([]bool, len(parsed.tokens))
+
+	linked := make(map[string]bool)
+	for i := 0; i < len(parsed.tokens); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		matched := 0
+		for n := min(qaMaxEntityWords, len(parsed.tokens)-i); n >= 1; n-- {
+			if n == 1 && qaStopwords[parsed.tokens[i]] {
+				break
+			}
+			candidates := sn.nameIndex[strings.Join(parsed.tokens[i:i+n], " ")]
+			if len(candidates) == 0 {
+				continue
+			}
+			// Prefer the best-connected node when labels are ambiguous
+			var best *SemanticNode
+			for _, node := range candidates {
+				if best == nil || sn.degree(node.ID) > sn.degree(best.ID) ||
+					(sn.degree(node.ID) == sn.degree(best.ID) && node.ID < best.ID) {
+					best = node
+				}
+			}
+			if !linked[best.ID] {
+				linked[best.ID] = true
+				parsed.entities = append(parsed.entities, best)
+				parsed.entityPos = append(parsed.entityPos, i)
+			}
+			matched = n
+			break
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		for j := i; j < i+matched; j++ {
+			parsed.covered[j] = true
+		}
+		i += matched
+	}
+	return parsed, nil
+}
+
+// degree returns the number of relations touching a node.
+// Caller must hold sn.mu.
+func (sn *SemanticNetwork) degree(nodeID string) int {
+	return len(sn.outgoing[nodeID]) + len(sn.incoming[nodeID])
+}
+
+// detectRelation finds the first relation phrase outside entity spans.
+// Returns the relation type and the token index where it starts.
+func detectRelation(parsed *parsedQuestion) (RelationType, int, bool) {
+	for _, phrase := range relationPhrases {
+		n := len(phrase.words)
+		for i := 0; i+n <= len(parsed.tokens); i++ {
+			match := true
+			for j, word := range phrase.words {
+				if parsed.covered[i+j] || parsed.tokens[i+j] != word {
+					match = false
+					break
+				}
+			}
+			if match {
+				return phrase.relType, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// ============================================================================
+// Answering
+// ============================================================================
+
+// Ask answers a natural-language question. Returns ErrNoEntityFound if no
+// entity can be linked and ErrUnanswerable if the graph has no supporting facts.
+func (qa *QuestionAnswerer) Ask(question string) (*QAAnswer, error) {
+	parsed := qa.linkEntities(question)
+	if len(parsed.entities) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNoEntityFound, question)
+	}
+
+	relType, relPos, hasRelation := detectRelation(parsed)
+	// "Is X a Y?" splits "is a" around the first entity
+	if !hasRelation && (parsed.tokens[0] == "is" || parsed.tokens[0] == "are") {
+		relType, hasRelation = IsA, true
+	}
+
+	var answer *QAAnswer
+	var err error
+	switch {
+	case len(parsed.entities) >= 2:
+		a, b := parsed.entities[0], parsed.entities[1]
+		switch {
+		case hasRelation && (relType == IsA || relType == InstanceOf):
+			answer = qa.answerMembership(a, b)
+		case hasRelation:
+			answer = qa.answerRelationBetween(a, b, relType)
+		default:
+			answer = qa.answerConnection(a, b)
+		}
+	default:
+		entity := parsed.entities[0]
+		if answer = qa.answerProperty(entity, parsed); answer != nil {
+			break
+		}
+		if hasRelation && relType != IsA {
+			// "What does X require?" asks about X's outgoing relations,
+			// "What requires X?" about incoming ones
+			outgoing := parsed.entityPos[0] < relPos
+			answer, err = qa.answerRelationLookup(entity, relType, outgoing)
+			break
+		}
+		answer = qa.answerDescription(entity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	answer.Question = question
+	answer.Entities = parsed.entities
+	if hasRelation {
+		answer.Relation = &relType
+	}
+	return answer, nil
+}
+
+// answerMembership answers "Is A a kind of B?" from the IS-A hierarchy.
+func (qa *QuestionAnswerer) answerMembership(a, b *SemanticNode) *QAAnswer {
+	answer := &QAAnswer{Kind: QuestionMembership}
+	sub := newSubgraphBuilder()
+	sub.addNode(a)
+	sub.addNode(b)
+
+	path, err := qa.network.FindPath(PathQuery{
+		FromID:       a.ID,
+		ToID:         b.ID,
+		AllowedTypes: []RelationType{IsA, InstanceOf},
+		Direction:    PathForward,
+	})
+	if err != nil {
+		answer.Value = false
+		answer.Confidence = 1.0
+		answer.Answer = fmt.Sprintf("No, %s is not known to be a %s.", a.Label, b.Label)
+		answer.Reasoning = []string{fmt.Sprintf("No IS-A path found from %s to %s", a.ID, b.ID)}
+		answer.Subgraph = sub.build()
+		return answer
+	}
+
+	answer.Value = true
+	answer.Confidence = qa.addPath(sub, path, &answer.Reasoning)
+	answer.Answer = fmt.Sprintf("Yes, %s is a %s.", a.Label, b.Label)
+	answer.Subgraph = sub.build()
+	return answer
+}
+
+// answerRelationBetween answers "Does A require B?" from stored relations.
+func (qa *QuestionAnswerer) answerRelationBetween(a, b *SemanticNode, relType RelationType) *QAAnswer {
+	answer := &QAAnswer{Kind: QuestionRelation}
+	sub := newSubgraphBuilder()
+	sub.addNode(a)
+	sub.addNode(b)
+
+	for _, pair := range [][2]*SemanticNode{{a, b}, {b, a}} {
+		for _, rel := range qa.network.GetOutgoingRelations(pair[0].ID) {
+			if rel.Type == relType && rel.TargetID == pair[1].ID {
+				sub.addRelation(rel)
+				answer.Value = true
+				answer.Confidence = rel.Confidence
+				answer.Answer = fmt.Sprintf("Yes, %s %s %s.", pair[0].Label, relType, pair[1].Label)
+				answer.Reasoning = []string{fmt.Sprintf("%s %s %s", pair[0].Label, relType, pair[1].Label)}
+				answer.Subgraph = sub.build()
+				return answer
+			}
+		}
+	}
+
+	answer.Value = false
+	answer.Confidence = 1.0
+	answer.Answer = fmt.Sprintf("No %s relation is known between %s and %s.", relType, a.Label, b.Label)
+	answer.Reasoning = []string{fmt.Sprintf("No %s relation found between %s and %s", relType, a.ID, b.ID)}
+	answer.Subgraph = sub.build()
+	return answer
+}
+
+// answerConnection answers "How is A related to B?" with the shortest path.
+func (qa *QuestionAnswerer) answerConnection(a, b *SemanticNode) *QAAnswer {
+	answer := &QAAnswer{Kind: QuestionConnection}
+	sub := newSubgraphBuilder()
+	sub.addNode(a)
+	sub.addNode(b)
+
+	path, err := qa.network.FindPath(PathQuery{
+		FromID:    a.ID,
+		ToID:      b.ID,
+		MaxHops:   qa.MaxConnectionHops,
+		Direction: PathBoth,
+	})
+	if err != nil {
+		answer.Value = false
+		answer.Confidence = 1.0
+		answer.Answer = fmt.Sprintf("No connection between %s and %s within %d hops.", a.Label, b.Label, qa.MaxConnectionHops)
+		answer.Subgraph = sub.build()
+		return answer
+	}
+
+	answer.Value = true
+	answer.Confidence = qa.addPath(sub, path, &answer.Reasoning)
+	answer.Answer = fmt.Sprintf("%s is connected to %s: %s.", a.Label, b.Label, strings.Join(answer.Reasoning, ", "))
+	answer.Subgraph = sub.build()
+	return answer
+}
+
+// answerProperty answers "What is the P of X?" when the question names a
+// property X has or inherits. Returns nil if no property is named.
+func (qa *QuestionAnswerer) answerProperty(entity *SemanticNode, parsed *parsedQuestion) *QAAnswer {
+	props, err := qa.network.GetInheritedProperties(entity.ID)
+	if err != nil || len(props) == 0 {
+		return nil
+	}
+
+	uncovered := make([]string, 0, len(parsed.tokens))
+	for i, token := range parsed.tokens {
+		if !parsed.covered[i] {
+			uncovered = append(uncovered, token)
+		}
+	}
+	text := " " + strings.Join(uncovered, " ") + " "
+
+	// Longest key first so "max depth" beats "depth"
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		phrase := strings.Join(tokenizeQuestion(strings.ReplaceAll(key, "_", " ")), " ")
+		if phrase == "" || !strings.Contains(text, " "+phrase+" ") {
+			continue
+		}
+		result, err := qa.engine.InferProperty(entity.ID, key)
+		if err != nil {
+			return nil
+		}
+
+		answer := &QAAnswer{
+			Kind:       QuestionProperty,
+			Value:      result.Answer,
+			Confidence: result.Confidence,
+			Answer:     fmt.Sprintf("The %s of %s is %v.", key, entity.Label, result.Answer),
+			Reasoning:  result.Reasoning,
+		}
+		sub := newSubgraphBuilder()
+		sub.addNode(entity)
+		if source := props[key].SourceNodeID; source != entity.ID {
+			if path, err := qa.network.FindPath(PathQuery{
+				FromID:       entity.ID,
+				ToID:         source,
+				AllowedTypes: []RelationType{IsA, InstanceOf},
+				Direction:    PathForward,
+			}); err == nil {
+				var ignored []string
+				qa.addPath(sub, path, &ignored)
+			}
+		}
+		answer.Subgraph = sub.build()
+		return answer
+	}
+	return nil
+}
+
+// answerRelationLookup lists the nodes related to entity by relType,
+// falling back to completion inference when nothing is stored.
+func (qa *QuestionAnswerer) answerRelationLookup(entity *SemanticNode, relType RelationType, outgoing bool) (*QAAnswer, error) {
+	answer := &QAAnswer{Kind: QuestionRelation}
+	sub := newSubgraphBuilder()
+	sub.addNode(entity)
+
+	// Try the direction the question implies, then the other one
+	for _, forward := range []bool{outgoing, !outgoing} {
+		relations := qa.network.GetIncomingRelations(entity.ID)
+		if forward {
+			relations = qa.network.GetOutgoingRelations(entity.ID)
+		}
+
+		ids := make([]string, 0)
+		labels := make([]string, 0)
+		for _, rel := range relations {
+			if rel.Type != relType {
+				continue
+			}
+			otherID := rel.SourceID
+			if forward {
+				otherID = rel.TargetID
+			}
+			other, err := qa.network.GetNode(otherID)
+			if err != nil {
+				continue
+			}
+			sub.addNode(other)
+			sub.addRelation(rel)
+			ids = append(ids, other.ID)
+			labels = append(labels, other.Label)
+			answer.Confidence = math.Max(answer.Confidence, rel.Confidence)
+			answer.Reasoning = append(answer.Reasoning, fmt.Sprintf("%s %s %s", rel.SourceID, relType, rel.TargetID))
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		answer.Value = ids
+		if forward {
+			answer.Answer = fmt.Sprintf("%s %s %s.", entity.Label, relType, strings.Join(labels, ", "))
+		} else {
+			answer.Answer = fmt.Sprintf("%s %s %s.", strings.Join(labels, ", "), relType, entity.Label)
+		}
+		answer.Subgraph = sub.build()
+		return answer, nil
+	}
+
+	return qa.inferRelation(entity, relType, sub)
+}
+
+// inferRelation predicts relType relations of entity from similar nodes.
+func (qa *QuestionAnswerer) inferRelation(entity *SemanticNode, relType RelationType, sub *subgraphBuilder) (*QAAnswer, error) {
+	result, err := qa.engine.InferCompletion(entity.ID)
+	if err != nil {
+		return nil, err
+	}
+	predictions, _ := result.Answer.([]map[string]interface{})
+
+	answer := &QAAnswer{Kind: QuestionRelation, Inferred: true}
+	ids := make([]string, 0)
+	labels := make([]string, 0)
+	for _, prediction := range predictions {
+		if prediction["type"] != relType.String() {
+			continue
+		}
+		target, err := qa.network.GetNode(prediction["target"].(string))
+		if err != nil {
+			continue
+		}
+		if source, err := qa.network.GetNode(prediction["source"].(string)); err == nil {
+			sub.addNode(source)
+			if rel, err := qa.network.GetRelation(RelationID(source.ID, relType, target.ID)); err == nil {
+				sub.addRelation(rel)
+			}
+			answer.Reasoning = append(answer.Reasoning,
+				fmt.Sprintf("Similar node %s %s %s", source.Label, relType, target.Label))
+		}
+		sub.addNode(target)
+		ids = append(ids, target.ID)
+		labels = append(labels, target.Label)
+		answer.Confidence = math.Max(answer.Confidence, prediction["confidence"].(float64))
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no %s relations for %s", ErrUnanswerable, relType, entity.ID)
+	}
+
+	answer.Value = ids
+	answer.Answer = fmt.Sprintf("%s probably %s %s.", entity.Label, relType, strings.Join(labels, ", "))
+	answer.Subgraph = sub.build()
+	return answer, nil
+}
+
+// answerDescription answers "What is X?" with its categories and relations.
+func (qa *QuestionAnswerer) answerDescription(entity *SemanticNode) *QAAnswer {
+	answer := &QAAnswer{
+		Kind:       QuestionDescription,
+		Value:      entity.ID,
+		Confidence: entity.Confidence,
+	}
+	sub := newSubgraphBuilder()
+	sub.addNode(entity)
+
+	parents := make([]string, 0)
+	for _, rel := range qa.network.GetOutgoingRelations(entity.ID) {
+		if other, err := qa.network.GetNode(rel.TargetID); err == nil {
+			sub.addNode(other)
+			sub.addRelation(rel)
+			answer.Reasoning = append(answer.Reasoning, fmt.Sprintf("%s %s %s", entity.Label, rel.Type, other.Label))
+			if rel.Type == IsA || rel.Type == InstanceOf {
+				parents = append(parents, other.Label)
+			}
+		}
+	}
+	for _, rel := range qa.network.GetIncomingRelations(entity.ID) {
+		if other, err := qa.network.GetNode(rel.SourceID); err == nil {
+			sub.addNode(other)
+			sub.addRelation(rel)
+			answer.Reasoning = append(answer.Reasoning, fmt.Sprintf("%s %s %s", other.Label, rel.Type, entity.Label))
+		}
+	}
+
+	answer.Answer = fmt.Sprintf("%s is a %s", entity.Label, entity.Type)
+	if len(parents) > 0 {
+		answer.Answer += " (" + strings.Join(parents, ", ") + ")"
+	}
+	answer.Answer += fmt.Sprintf(" with %d known relations.", len(answer.Reasoning))
+	answer.Subgraph = sub.build()
+	return answer
+}
+
+// addPath adds a path to the subgraph and reasoning, returning the chained
+// confidence of its relations.
+func (qa *QuestionAnswerer) addPath(sub *subgraphBuilder, path *PathResult, reasoning *[]string) float64 {
+	for _, node := range path.Nodes {
+		sub.addNode(node)
+	}
+	links := make([]float64, 0, len(path.Edges))
+	for _, step := range path.Edges {
+		sub.addRelation(step.Relation)
+		links = append(links, step.Relation.Confidence)
+		*reasoning = append(*reasoning,
+			fmt.Sprintf("%s %s %s", step.Relation.SourceID, step.Relation.Type, step.Relation.TargetID))
+	}
+	return qa.network.config.Confidence.Chain(links...)
+}
+
+// ============================================================================
+// Subgraph Builder
+// ============================================================================
+
+// subgraphBuilder collects nodes and relations without duplicates.
+type subgraphBuilder struct {
+	nodes     map[string]*SemanticNode
+	relations map[string]*SemanticRelation
+}
+
+func newSubgraphBuilder() *subgraphBuilder {
+	return &subgraphBuilder{
+		nodes:     make(map[string]*SemanticNode),
+		relations: make(map[string]*SemanticRelation),
+	}
+}
+
+func (b *subgraphBuilder) addNode(node *SemanticNode) {
+	b.nodes[node.ID] = node
+}
+
+func (b *subgraphBuilder) addRelation(rel *SemanticRelation) {
+	b.relations[rel.ID] = rel
+}
+
+// build returns the subgraph sorted by ID.
+func (b *subgraphBuilder) build() *Subgraph {
+	sub := &Subgraph{
+		Nodes:     make([]*SemanticNode, 0, len(b.nodes)),
+		Relations: make([]*SemanticRelation, 0, len(b.relations)),
+	}
+	for _, node := range b.nodes {
+		sub.Nodes = append(sub.Nodes, node)
+	}
+	for _, rel := range b.relations {
+		sub.Relations = append(sub.Relations, rel)
+	}
+	sort.Slice(sub.Nodes, func(i, j int) bool { return sub.Nodes[i].ID < sub.Nodes[j].ID })
+	sort.Slice(sub.Relations, func(i, j int) bool { return sub.Relations[i].ID < sub.Relations[j].ID })
+	return sub
+}
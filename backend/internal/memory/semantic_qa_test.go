@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+)
+
+// ============================================================================
+// Question Answering Tests
+// ============================================================================
+
+// buildQANetwork creates a small algorithms ontology.
+func buildQANetwork() *SemanticNetwork {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+
+	algorithm := NewSemanticNode("algorithm", "Algorithm", ConceptNode)
+	algorithm.SetProperty("deterministic", true)
+	sn.AddNode(algorithm)
+
+	sorting := NewSemanticNode("sorting-algorithm", "Sorting Algorithm", ConceptNode)
+	sorting.SetProperty("time_complexity", "O(n log n)")
+	sn.AddNode(sorting)
+
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("mergesort", "MergeSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("recursion", "Recursion", ConceptNode))
+	sn.AddNode(NewSemanticNode("memory", "Extra Memory", ConceptNode))
+
+	sn.AddRelation(NewSemanticRelation("sorting-algorithm", "algorithm", IsA))
+	quick := NewSemanticRelation("quicksort", "sorting-algorithm", IsA)
+	quick.Confidence = 0.9
+	sn.AddRelation(quick)
+	sn.AddRelation(NewSemanticRelation("mergesort", "sorting-algorithm", IsA))
+	sn.AddRelation(NewSemanticRelation("mergesort", "recursion", Requires))
+	sn.AddRelation(NewSemanticRelation("mergesort", "memory", Requires))
+	return sn
+}
+
+func TestQuestionAnswerer_Membership(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	answer, err := qa.Ask("Is QuickSort a kind of algorithm?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.Kind != QuestionMembership {
+		t.Errorf("Expected membership question, got %s", answer.Kind)
+	}
+	if answer.Value != true {
+		t.Errorf("Expected yes, got %v", answer.Value)
+	}
+	if answer.Confidence != 0.9 {
+		t.Errorf("Expected chained confidence 0.9, got %f", answer.Confidence)
+	}
+	if len(answer.Subgraph.Nodes) != 3 || len(answer.Subgraph.Relations) != 2 {
+		t.Errorf("Expected 3-node IS-A chain, got %d nodes and %d relations",
+			len(answer.Subgraph.Nodes), len(answer.Subgraph.Relations))
+	}
+
+	answer, err = qa.Ask("Is Algorithm a QuickSort?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.Value != false {
+		t.Errorf("Expected no, got %v", answer.Value)
+	}
+}
+
+func TestQuestionAnswerer_RelationLookup(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	answer, err := qa.Ask("What does MergeSort require?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	ids, ok := answer.Value.([]string)
+	if !ok || len(ids) != 2 {
+		t.Fatalf("Expected 2 required nodes, got %v", answer.Value)
+	}
+	if answer.Relation == nil || *answer.Relation != Requires {
+		t.Errorf("Expected requires relation, got %v", answer.Relation)
+	}
+	if len(answer.Subgraph.Relations) != 2 {
+		t.Errorf("Expected supporting relations, got %d", len(answer.Subgraph.Relations))
+	}
+
+	// Reverse direction: the entity follows the relation phrase
+	answer, err = qa.Ask("What requires recursion?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if ids := answer.Value.([]string); len(ids) != 1 || ids[0] != "mergesort" {
+		t.Errorf("Expected mergesort, got %v", ids)
+	}
+}
+
+func TestQuestionAnswerer_InferredRelation(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	// QuickSort has no stored requirements but is similar to MergeSort
+	answer, err := qa.Ask("What does QuickSort need?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if !answer.Inferred {
+		t.Error("Expected answer to be marked as inferred")
+	}
+	if len(answer.Value.([]string)) == 0 {
+		t.Error("Expected inferred requirements")
+	}
+}
+
+func TestQuestionAnswerer_Property(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	answer, err := qa.Ask("What is the time complexity of QuickSort?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.Kind != QuestionProperty {
+		t.Fatalf("Expected property question, got %s", answer.Kind)
+	}
+	if answer.Value != "O(n log n)" {
+		t.Errorf("Expected inherited value, got %v", answer.Value)
+	}
+	// The subgraph shows where the value was inherited from
+	if len(answer.Subgraph.Relations) != 1 || answer.Subgraph.Relations[0].TargetID != "sorting-algorithm" {
+		t.Errorf("Expected IS-A link to the property source, got %v", answer.Subgraph.Relations)
+	}
+}
+
+func TestQuestionAnswerer_ConnectionAndDescription(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	answer, err := qa.Ask("How are QuickSort and MergeSort connected?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.Kind != QuestionConnection || answer.Value != true {
+		t.Errorf("Expected a connection, got %s %v", answer.Kind, answer.Value)
+	}
+	if len(answer.Reasoning) != 2 {
+		t.Errorf("Expected a 2-hop path, got %v", answer.Reasoning)
+	}
+
+	answer, err = qa.Ask("What is a sorting algorithm?")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if answer.Kind != QuestionDescription {
+		t.Errorf("Expected description, got %s", answer.Kind)
+	}
+	if len(answer.Subgraph.Nodes) != 4 {
+		t.Errorf("Expected node with its neighbours, got %d nodes", len(answer.Subgraph.Nodes))
+	}
+}
+
+func TestQuestionAnswerer_Errors(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	if _, err := qa.Ask("What is the weather today?"); !errors.Is(err, ErrNoEntityFound) {
+		t.Errorf("Expected ErrNoEntityFound, got %v", err)
+	}
+	if _, err := qa.Ask("What does Recursion produce?"); !errors.Is(err, ErrUnanswerable) {
+		t.Errorf("Expected ErrUnanswerable, got %v", err)
+	}
+}
+
+func TestQuestionAnswerer_LinkEntities(t *testing.T) {
+	qa := NewQuestionAnswerer(buildQANetwork())
+
+	nodes := qa.LinkEntities("Compare MergeSort with the sorting-algorithm family and mergesort again")
+	if len(nodes) != 2 || nodes[0].ID != "mergesort" || nodes[1].ID != "sorting-algorithm" {
+		t.Errorf("Expected mergesort and sorting-algorithm once each, got %v", nodes)
+	}
+}
+
+func TestQuestionAnswerer_LinkEntitiesFollowsChanges(t *testing.T) {
+	sn := buildQANetwork()
+	qa := NewQuestionAnswerer(sn)
+
+	renamed := NewSemanticNode("quicksort", "Hoare Sort", InstanceNode)
+	if err := sn.UpdateNode(renamed); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if nodes := qa.LinkEntities("Is Hoare Sort fast?"); len(nodes) != 1 || nodes[0] != renamed {
+		t.Errorf("Expected the renamed node linked, got %v", nodes)
+	}
+	if err := sn.RemoveNode("mergesort"); err != nil {
+		t.Fatalf("RemoveNode failed: %v", err)
+	}
+	if nodes := qa.LinkEntities("Is MergeSort stable?"); len(nodes) != 0 {
+		t.Errorf("Expected the removed node not linked, got %v", nodes)
+	}
+}
+
+func TestTokenizeQuestion(t *testing.T) {
+	tokens := tokenizeQuestion("What is APEX's tier-1 role_name?")
+	expected := []string{"what", "is", "apex", "tier-1", "role_name"}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, tokens)
+	}
+	for i := range expected {
+		if tokens[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], tokens[i])
+		}
+	}
+}
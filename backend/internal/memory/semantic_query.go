@@ -0,0 +1,1011 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements a SPARQL-lite query language for the Semantic Network.
+//
+// Queries select variable bindings that satisfy a set of triple patterns:
+//
+//	SELECT ?agent ?tier WHERE {
+//	    ?agent type agent .
+//	    ?agent belongs-to ?tier .
+//	    ?agent @specialty ?s .
+//	    FILTER(?s contains "security")
+//	} LIMIT 10
+//
+// Predicates are relation types (is-a, requires, ...), "type" for the node
+// type, or @key for a node property. Subjects and relation objects are
+// variables or node IDs; property objects may also be literals (strings,
+// numbers with optional units, true/false). FILTER compares a variable with
+// =, !=, <, <=, >, >= or contains.
+//
+// A greedy planner orders patterns so each step starts from the smallest
+// candidate set: bound endpoints use the adjacency lists, unbound ones the
+// type, relation type and property indexes. Filters run as soon as their
+// variables are bound.
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrQuerySyntax indicates the query text could not be parsed
+	ErrQuerySyntax = errdefs.New(errdefs.ErrInvalidArgument, "query syntax error")
+	// ErrQueryTooLarge indicates intermediate results exceeded the bindings limit
+	ErrQueryTooLarge = errdefs.New(errdefs.ErrInvalidArgument, "query result too large")
+)
+
+// maxQueryBindings bounds intermediate results to protect the server.
+const maxQueryBindings = 100000
+
+// ============================================================================
+// Query Types
+// ============================================================================
+
+// QueryTerm is a variable or a constant in a pattern or filter.
+type QueryTerm struct {
+	// Var is the variable name without '?', empty for constants
+	Var string
+	// Value is the constant: a node ID or type name, or a PropertyValue literal
+	Value interface{}
+}
+
+// IsVar returns true if the term is a variable.
+func (t QueryTerm) IsVar() bool {
+	return t.Var != ""
+}
+
+// String returns the term as written in a query.
+func (t QueryTerm) String() string {
+	if t.IsVar() {
+		return "?" + t.Var
+	}
+	if v, ok := t.Value.(PropertyValue); ok && v.Kind == PropertyString {
+		return strconv.Quote(v.Str)
+	}
+	return fmt.Sprint(t.Value)
+}
+
+// PatternKind distinguishes what a triple pattern matches.
+type PatternKind int
+
+const (
+	// PatternRelation matches a relation between two nodes
+	PatternRelation PatternKind = iota
+	// PatternType matches a node's type
+	PatternType
+	// PatternProperty matches a node's property value
+	PatternProperty
+)
+
+// TriplePattern is a single subject-predicate-object pattern.
+type TriplePattern struct {
+	Subject QueryTerm
+	Kind    PatternKind
+	// Relation is the relation type of PatternRelation
+	Relation RelationType
+	// Property is the property key of PatternProperty
+	Property string
+	Object   QueryTerm
+}
+
+// String returns the pattern as written in a query.
+func (p TriplePattern) String() string {
+	predicate := "type"
+	switch p.Kind {
+	case PatternRelation:
+		predicate = p.Relation.String()
+	case PatternProperty:
+		predicate = "@" + p.Property
+	}
+	return fmt.Sprintf("%s %s %s", p.Subject, predicate, p.Object)
+}
+
+// vars returns the variables a pattern binds.
+func (p TriplePattern) vars() []string {
+	vars := make([]string, 0, 2)
+	if p.Subject.IsVar() {
+		vars = append(vars, p.Subject.Var)
+	}
+	if p.Object.IsVar() && p.Object.Var != p.Subject.Var {
+		vars = append(vars, p.Object.Var)
+	}
+	return vars
+}
+
+// FilterOp is a comparison operator in a FILTER.
+type FilterOp string
+
+const (
+	FilterEq       FilterOp = "="
+	FilterNe       FilterOp = "!="
+	FilterLt       FilterOp = "<"
+	FilterLe       FilterOp = "<="
+	FilterGt       FilterOp = ">"
+	FilterGe       FilterOp = ">="
+	FilterContains FilterOp = "contains"
+)
+
+// QueryFilter compares a variable with a constant or another variable.
+type QueryFilter struct {
+	Var   string
+	Op    FilterOp
+	Value QueryTerm
+}
+
+// String returns the filter as written in a query.
+func (f QueryFilter) String() string {
+	return fmt.Sprintf("FILTER(?%s %s %s)", f.Var, f.Op, f.Value)
+}
+
+// vars returns the variables a filter needs.
+func (f QueryFilter) vars() []string {
+	if f.Value.IsVar() {
+		return []string{f.Var, f.Value.Var}
+	}
+	return []string{f.Var}
+}
+
+// GraphQuery is a parsed SPARQL-lite query.
+type GraphQuery struct {
+	// Vars are the projected variables; empty selects all
+	Vars     []string
+	Distinct bool
+	Patterns []TriplePattern
+	Filters  []QueryFilter
+	// Limit caps the rows returned (0 = unlimited)
+	Limit  int
+	Offset int
+}
+
+// ============================================================================
+// Parser
+// ============================================================================
+
+// queryTokenKind classifies lexical tokens.
+type queryTokenKind int
+
+const (
+	tokenIdent queryTokenKind = iota
+	tokenVar
+	tokenString
+	tokenNumber
+	tokenPunct
+	tokenEOF
+)
+
+// queryToken is a lexical token with its offset for error messages.
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+}
+
+// tokenizeQuery splits query text into tokens.
+func tokenizeQuery(text string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0)
+	runes := []rune(text)
+	isIdent := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':' || r == '@'
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '?':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("%w: empty variable name at %d", ErrQuerySyntax, start)
+			}
+			tokens = append(tokens, queryToken{kind: tokenVar, text: string(runes[start+1 : i]), pos: start})
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", ErrQuerySyntax, start)
+			}
+			i++
+			tokens = append(tokens, queryToken{kind: tokenString, text: sb.String(), pos: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])) {
+				i++
+			}
+			// An optional unit directly follows the number, e.g. 100ms
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case strings.ContainsRune("{}().,*", r):
+			tokens = append(tokens, queryToken{kind: tokenPunct, text: string(r), pos: i})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return nil, fmt.Errorf("%w: unexpected '!' at %d", ErrQuerySyntax, start)
+			}
+			tokens = append(tokens, queryToken{kind: tokenPunct, text: op, pos: start})
+		case isIdent(r):
+			start := i
+			for i < len(runes) && isIdent(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d", ErrQuerySyntax, r, i)
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// queryParser is a recursive-descent parser over query tokens.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword consumes the token if it is the given case-insensitive keyword.
+func (p *queryParser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// punct consumes the token if it is the given punctuation.
+func (p *queryParser) punct(text string) bool {
+	tok := p.peek()
+	if tok.kind == tokenPunct && tok.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at %d", ErrQuerySyntax, fmt.Sprintf(format, args...), p.peek().pos)
+}
+
+// ParseQuery parses SPARQL-lite query text.
+func ParseQuery(text string) (*GraphQuery, error) {
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	query := &GraphQuery{}
+
+	if !p.keyword("SELECT") {
+		return nil, p.errorf("expected SELECT")
+	}
+	query.Distinct = p.keyword("DISTINCT")
+	if !p.punct("*") {
+		for p.peek().kind == tokenVar {
+			query.Vars = append(query.Vars, p.next().text)
+		}
+		if len(query.Vars) == 0 {
+			return nil, p.errorf("expected variables or *")
+		}
+	}
+
+	if !p.keyword("WHERE") {
+		return nil, p.errorf("expected WHERE")
+	}
+	if !p.punct("{") {
+		return nil, p.errorf("expected {")
+	}
+	for !p.punct("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf("expected }")
+		}
+		if p.keyword("FILTER") {
+			filter, err := p.parseFilter()
+			if err != nil {
+				return nil, err
+			}
+			query.Filters = append(query.Filters, filter)
+		} else {
+			pattern, err := p.parsePattern()
+			if err != nil {
+				return nil, err
+			}
+			query.Patterns = append(query.Patterns, pattern)
+		}
+		p.punct(".")
+	}
+	if len(query.Patterns) == 0 {
+		return nil, p.errorf("expected at least one triple pattern")
+	}
+
+	for {
+		switch {
+		case p.keyword("LIMIT"):
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+			query.Limit = n
+		case p.keyword("OFFSET"):
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+			query.Offset = n
+		default:
+			if p.peek().kind != tokenEOF {
+				return nil, p.errorf("unexpected %q", p.peek().text)
+			}
+			if err := validateQuery(query); err != nil {
+				return nil, err
+			}
+			return query, nil
+		}
+	}
+}
+
+// parseCount parses a non-negative integer for LIMIT or OFFSET.
+func (p *queryParser) parseCount() (int, error) {
+	tok := p.next()
+	n, err := strconv.Atoi(tok.text)
+	if tok.kind != tokenNumber || err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: expected a count at %d", ErrQuerySyntax, tok.pos)
+	}
+	return n, nil
+}
+
+// parsePattern parses "subject predicate object".
+func (p *queryParser) parsePattern() (TriplePattern, error) {
+	var pattern TriplePattern
+
+	subject, err := p.parseNodeTerm()
+	if err != nil {
+		return pattern, err
+	}
+	pattern.Subject = subject
+
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return pattern, fmt.Errorf("%w: expected predicate at %d", ErrQuerySyntax, tok.pos)
+	}
+	switch {
+	case tok.text == "type" || tok.text == "a":
+		pattern.Kind = PatternType
+		object := p.next()
+		switch object.kind {
+		case tokenVar:
+			pattern.Object = QueryTerm{Var: object.text}
+		case tokenIdent:
+			nodeType, ok := parseNodeType(object.text)
+			if !ok {
+				return pattern, fmt.Errorf("%w: unknown node type %q at %d", ErrQuerySyntax, object.text, object.pos)
+			}
+			pattern.Object = QueryTerm{Value: nodeType}
+		default:
+			return pattern, fmt.Errorf("%w: expected node type at %d", ErrQuerySyntax, object.pos)
+		}
+		return pattern, nil
+	case strings.HasPrefix(tok.text, "@") && len(tok.text) > 1:
+		pattern.Kind = PatternProperty
+		pattern.Property = tok.text[1:]
+		object, err := p.parseValueTerm()
+		if err != nil {
+			return pattern, err
+		}
+		pattern.Object = object
+		return pattern, nil
+	default:
+		relType, ok := parseRelationType(tok.text)
+		if !ok {
+			return pattern, fmt.Errorf("%w: unknown predicate %q at %d", ErrQuerySyntax, tok.text, tok.pos)
+		}
+		pattern.Kind = PatternRelation
+		pattern.Relation = relType
+		object, err := p.parseNodeTerm()
+		if err != nil {
+			return pattern, err
+		}
+		pattern.Object = object
+		return pattern, nil
+	}
+}
+
+// parseNodeTerm parses a variable or a node ID.
+func (p *queryParser) parseNodeTerm() (QueryTerm, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenVar:
+		return QueryTerm{Var: tok.text}, nil
+	case tokenIdent, tokenString:
+		return QueryTerm{Value: tok.text}, nil
+	default:
+		return QueryTerm{}, fmt.Errorf("%w: expected variable or node ID at %d", ErrQuerySyntax, tok.pos)
+	}
+}
+
+// parseValueTerm parses a variable or a literal.
+func (p *queryParser) parseValueTerm() (QueryTerm, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenVar:
+		return QueryTerm{Var: tok.text}, nil
+	case tokenString:
+		return QueryTerm{Value: StringValue(tok.text)}, nil
+	case tokenNumber:
+		value, err := parseNumberLiteral(tok.text)
+		if err != nil {
+			return QueryTerm{}, fmt.Errorf("%w: %v at %d", ErrQuerySyntax, err, tok.pos)
+		}
+		return QueryTerm{Value: value}, nil
+	case tokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return QueryTerm{Value: BoolValue(true)}, nil
+		case "false":
+			return QueryTerm{Value: BoolValue(false)}, nil
+		}
+		return QueryTerm{Value: StringValue(tok.text)}, nil
+	default:
+		return QueryTerm{}, fmt.Errorf("%w: expected variable or literal at %d", ErrQuerySyntax, tok.pos)
+	}
+}
+
+// parseNumberLiteral parses a number with an optional unit suffix.
+func parseNumberLiteral(text string) (PropertyValue, error) {
+	split := len(text)
+	for split > 0 && unicode.IsLetter(rune(text[split-1])) {
+		split--
+	}
+	n, err := strconv.ParseFloat(text[:split], 64)
+	if err != nil {
+		return PropertyValue{}, fmt.Errorf("invalid number %q", text)
+	}
+	return NumberValue(n, text[split:]), nil
+}
+
+// parseFilter parses "( ?var op term )".
+func (p *queryParser) parseFilter() (QueryFilter, error) {
+	var filter QueryFilter
+	if !p.punct("(") {
+		return filter, p.errorf("expected ( after FILTER")
+	}
+	tok := p.next()
+	if tok.kind != tokenVar {
+		return filter, fmt.Errorf("%w: expected variable in FILTER at %d", ErrQuerySyntax, tok.pos)
+	}
+	filter.Var = tok.text
+
+	op := p.next()
+	switch {
+	case op.kind == tokenPunct && strings.ContainsAny(op.text, "=<>"):
+		filter.Op = FilterOp(op.text)
+	case op.kind == tokenIdent && strings.EqualFold(op.text, "contains"):
+		filter.Op = FilterContains
+	default:
+		return filter, fmt.Errorf("%w: expected operator in FILTER at %d", ErrQuerySyntax, op.pos)
+	}
+
+	value, err := p.parseValueTerm()
+	if err != nil {
+		return filter, err
+	}
+	filter.Value = value
+	if !p.punct(")") {
+		return filter, p.errorf("expected ) to close FILTER")
+	}
+	return filter, nil
+}
+
+// validateQuery checks that projected and filtered variables are bound.
+func validateQuery(query *GraphQuery) error {
+	bound := make(map[string]bool)
+	for _, pattern := range query.Patterns {
+		for _, v := range pattern.vars() {
+			bound[v] = true
+		}
+	}
+	for _, v := range query.Vars {
+		if !bound[v] {
+			return fmt.Errorf("%w: selected variable ?%s is not bound by any pattern", ErrQuerySyntax, v)
+		}
+	}
+	for _, filter := range query.Filters {
+		for _, v := range filter.vars() {
+			if !bound[v] {
+				return fmt.Errorf("%w: filtered variable ?%s is not bound by any pattern", ErrQuerySyntax, v)
+			}
+		}
+	}
+	return nil
+}
+
+// parseNodeType converts a node type name back to its NodeType.
+func parseNodeType(name string) (NodeType, bool) {
+	for nt := ConceptNode; nt <= DomainNode; nt++ {
+		if nt.String() == strings.ToLower(name) {
+			return nt, true
+		}
+	}
+	return 0, false
+}
+
+// ============================================================================
+// Planner
+// ============================================================================
+
+// Access paths chosen by the planner.
+const (
+	AccessCheck         = "check"
+	AccessOutgoing      = "outgoing"
+	AccessIncoming      = "incoming"
+	AccessRelationIndex = "relation-index"
+	AccessTypeIndex     = "type-index"
+	AccessNodeLookup    = "node-lookup"
+	AccessNodeScan      = "node-scan"
+	AccessPropertyIndex = "property-index"
+)
+
+// PlanStep is one pattern in execution order.
+type PlanStep struct {
+	Pattern TriplePattern
+	// Access is how candidates for the pattern are found
+	Access string
+	// Estimate is the expected number of matches per input binding
+	Estimate int
+	// Filters are applied right after this step
+	Filters []QueryFilter
+}
+
+// QueryPlan is the execution order chosen for a query.
+type QueryPlan struct {
+	Steps []PlanStep
+}
+
+// PlanQuery returns the execution plan for a query without running it.
+func (sn *SemanticNetwork) PlanQuery(query *GraphQuery) *QueryPlan {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	return sn.planQuery(query)
+}
+
+// planQuery orders patterns greedily by estimated fan-out, preferring
+// patterns connected to already bound variables. Caller must hold sn.mu.
+func (sn *SemanticNetwork) planQuery(query *GraphQuery) *QueryPlan {
+	plan := &QueryPlan{Steps: make([]PlanStep, 0, len(query.Patterns))}
+	bound := make(map[string]bool)
+	remaining := append([]TriplePattern(nil), query.Patterns...)
+	filtersApplied := make([]bool, len(query.Filters))
+
+	for len(remaining) > 0 {
+		best := -1
+		var bestStep PlanStep
+		bestConnected := false
+		for i, pattern := range remaining {
+			access, estimate := sn.estimatePattern(pattern, bound)
+			connected := len(bound) == 0
+			for _, v := range pattern.vars() {
+				if bound[v] {
+					connected = true
+				}
+			}
+			if !pattern.Subject.IsVar() || (pattern.Kind == PatternRelation && !pattern.Object.IsVar()) {
+				connected = true
+			}
+			better := best < 0 ||
+				(connected && !bestConnected) ||
+				(connected == bestConnected && estimate < bestStep.Estimate)
+			if better {
+				best = i
+				bestConnected = connected
+				bestStep = PlanStep{Pattern: pattern, Access: access, Estimate: estimate}
+			}
+		}
+
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		for _, v := range bestStep.Pattern.vars() {
+			bound[v] = true
+		}
+		// Push filters down to the first step that binds all their variables
+		for i, filter := range query.Filters {
+			if filtersApplied[i] {
+				continue
+			}
+			ready := true
+			for _, v := range filter.vars() {
+				if !bound[v] {
+					ready = false
+				}
+			}
+			if ready {
+				filtersApplied[i] = true
+				bestStep.Filters = append(bestStep.Filters, filter)
+			}
+		}
+		plan.Steps = append(plan.Steps, bestStep)
+	}
+	return plan
+}
+
+// estimatePattern chooses the access path for a pattern given the bound
+// variables and estimates its fan-out. Caller must hold sn.mu.
+func (sn *SemanticNetwork) estimatePattern(pattern TriplePattern, bound map[string]bool) (string, int) {
+	isBound := func(term QueryTerm) bool {
+		return !term.IsVar() || bound[term.Var]
+	}
+	nodeCount := max(1, len(sn.nodes))
+
+	switch pattern.Kind {
+	case PatternRelation:
+		relCount := len(sn.relationTypeIndex[pattern.Relation])
+		avgDegree := max(1, relCount/nodeCount)
+		switch {
+		case isBound(pattern.Subject) && isBound(pattern.Object):
+			return AccessCheck, 1
+		case isBound(pattern.Subject):
+			if id, ok := pattern.Subject.Value.(string); ok {
+				return AccessOutgoing, max(1, len(sn.outgoing[id]))
+			}
+			return AccessOutgoing, avgDegree
+		case isBound(pattern.Object):
+			if id, ok := pattern.Object.Value.(string); ok {
+				return AccessIncoming, max(1, len(sn.incoming[id]))
+			}
+			return AccessIncoming, avgDegree
+		default:
+			return AccessRelationIndex, relCount
+		}
+	case PatternType:
+		if isBound(pattern.Subject) {
+			return AccessNodeLookup, 1
+		}
+		if nodeType, ok := pattern.Object.Value.(NodeType); ok {
+			return AccessTypeIndex, len(sn.typeIndex[nodeType])
+		}
+		return AccessNodeScan, len(sn.nodes)
+	default:
+		if isBound(pattern.Subject) {
+			return AccessNodeLookup, 1
+		}
+		if !pattern.Object.IsVar() {
+			if count, ok := sn.countNodesByProperty(pattern.Property, pattern.Object.Value); ok {
+				return AccessPropertyIndex, count
+			}
+		}
+		return AccessNodeScan, len(sn.nodes)
+	}
+}
+
+// ============================================================================
+// Execution
+// ============================================================================
+
+// GraphQueryResult holds the rows matched by a query.
+type GraphQueryResult struct {
+	// Vars are the columns of each row
+	Vars []string
+	// Rows map each variable to a node ID or property value
+	Rows []map[string]interface{}
+	Plan *QueryPlan
+	// Truncated is set when LIMIT dropped matching rows
+	Truncated bool
+}
+
+// queryBinding maps variables to node IDs or property values.
+type queryBinding map[string]interface{}
+
+// bind extends a binding with term = value, returning false on conflict.
+func (b queryBinding) bind(term QueryTerm, value interface{}) (queryBinding, bool) {
+	if !term.IsVar() {
+		return b, queryValuesEqual(term.Value, value)
+	}
+	if existing, ok := b[term.Var]; ok {
+		return b, queryValuesEqual(existing, value)
+	}
+	extended := make(queryBinding, len(b)+1)
+	for k, v := range b {
+		extended[k] = v
+	}
+	extended[term.Var] = value
+	return extended, true
+}
+
+// resolve returns the value of a term under a binding.
+func (b queryBinding) resolve(term QueryTerm) (interface{}, bool) {
+	if !term.IsVar() {
+		return term.Value, true
+	}
+	value, ok := b[term.Var]
+	return value, ok
+}
+
+// Query parses and executes SPARQL-lite query text.
+func (sn *SemanticNetwork) Query(text string) (*GraphQueryResult, error) {
+	query, err := ParseQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	return sn.ExecuteQuery(query)
+}
+
+// ExecuteQuery plans and runs a parsed query. Rows are ordered by their
+// projected values so results are deterministic.
+func (sn *SemanticNetwork) ExecuteQuery(query *GraphQuery) (*GraphQueryResult, error) {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	plan := sn.planQuery(query)
+	bindings := []queryBinding{{}}
+	for _, step := range plan.Steps {
+		next := make([]queryBinding, 0)
+		for _, binding := range bindings {
+			for _, extended := range sn.matchPattern(step, binding) {
+				if !applyQueryFilters(step.Filters, extended) {
+					continue
+				}
+				next = append(next, extended)
+				if len(next) > maxQueryBindings {
+					return nil, fmt.Errorf("%w: more than %d bindings at %s", ErrQueryTooLarge, maxQueryBindings, step.Pattern)
+				}
+			}
+		}
+		bindings = next
+		if len(bindings) == 0 {
+			break
+		}
+	}
+
+	vars := query.Vars
+	if len(vars) == 0 {
+		seen := make(map[string]bool)
+		for _, pattern := range query.Patterns {
+			for _, v := range pattern.vars() {
+				if !seen[v] {
+					seen[v] = true
+					vars = append(vars, v)
+				}
+			}
+		}
+	}
+
+	result := &GraphQueryResult{Vars: vars, Rows: make([]map[string]interface{}, 0), Plan: plan}
+	seen := make(map[string]bool)
+	keys := make(map[int]string)
+	for _, binding := range bindings {
+		row := make(map[string]interface{}, len(vars))
+		parts := make([]string, len(vars))
+		for i, v := range vars {
+			row[v] = binding[v]
+			parts[i] = fmt.Sprint(binding[v])
+		}
+		key := strings.Join(parts, "\x00")
+		if query.Distinct {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		keys[len(result.Rows)] = key
+		result.Rows = append(result.Rows, row)
+	}
+
+	order := make([]int, len(result.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+	sorted := make([]map[string]interface{}, len(order))
+	for i, idx := range order {
+		sorted[i] = result.Rows[idx]
+	}
+	result.Rows = sorted
+
+	if query.Offset > 0 {
+		result.Rows = result.Rows[min(query.Offset, len(result.Rows)):]
+	}
+	if query.Limit > 0 && len(result.Rows) > query.Limit {
+		result.Rows = result.Rows[:query.Limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// matchPattern returns the extensions of a binding that satisfy a plan
+// step. Caller must hold sn.mu.
+func (sn *SemanticNetwork) matchPattern(step PlanStep, binding queryBinding) []queryBinding {
+	pattern := step.Pattern
+	matches := make([]queryBinding, 0)
+	add := func(pairs ...interface{}) {
+		extended := binding
+		for i := 0; i < len(pairs); i += 2 {
+			var ok bool
+			extended, ok = extended.bind(pairs[i].(QueryTerm), pairs[i+1])
+			if !ok {
+				return
+			}
+		}
+		matches = append(matches, extended)
+	}
+
+	subject, subjectBound := binding.resolve(pattern.Subject)
+	switch pattern.Kind {
+	case PatternRelation:
+		object, objectBound := binding.resolve(pattern.Object)
+		switch {
+		case subjectBound:
+			id, _ := subject.(string)
+			for _, rel := range sn.outgoing[id] {
+				if rel.Type == pattern.Relation && (!objectBound || queryValuesEqual(object, rel.TargetID)) {
+					add(pattern.Object, rel.TargetID)
+				}
+			}
+		case objectBound:
+			id, _ := object.(string)
+			for _, rel := range sn.incoming[id] {
+				if rel.Type == pattern.Relation {
+					add(pattern.Subject, rel.SourceID)
+				}
+			}
+		default:
+			for _, rel := range sn.relationTypeIndex[pattern.Relation] {
+				add(pattern.Subject, rel.SourceID, pattern.Object, rel.TargetID)
+			}
+		}
+
+	case PatternType:
+		if subjectBound {
+			id, _ := subject.(string)
+			if node, ok := sn.nodes[id]; ok {
+				add(pattern.Object, typeTermValue(pattern.Object, node.Type))
+			}
+			break
+		}
+		if nodeType, ok := pattern.Object.Value.(NodeType); ok {
+			for id := range sn.typeIndex[nodeType] {
+				add(pattern.Subject, id)
+			}
+			break
+		}
+		for id, node := range sn.nodes {
+			add(pattern.Subject, id, pattern.Object, typeTermValue(pattern.Object, node.Type))
+		}
+
+	case PatternProperty:
+		if subjectBound {
+			id, _ := subject.(string)
+			if node, ok := sn.nodes[id]; ok {
+				if value, ok := node.Properties[pattern.Property]; ok {
+					add(pattern.Object, value)
+				}
+			}
+			break
+		}
+		if step.Access == AccessPropertyIndex {
+			for _, node := range sn.findNodesByProperty(pattern.Property, pattern.Object.Value) {
+				add(pattern.Subject, node.ID)
+			}
+			break
+		}
+		for id, node := range sn.nodes {
+			if value, ok := node.Properties[pattern.Property]; ok {
+				add(pattern.Subject, id, pattern.Object, value)
+			}
+		}
+	}
+	return matches
+}
+
+// typeTermValue returns what a type pattern's object binds to: the NodeType
+// for constants, so they compare equal, or the type name for variables.
+func typeTermValue(term QueryTerm, nodeType NodeType) interface{} {
+	if term.IsVar() {
+		return nodeType.String()
+	}
+	return nodeType
+}
+
+// applyQueryFilters returns true if the binding passes every filter.
+func applyQueryFilters(filters []QueryFilter, binding queryBinding) bool {
+	for _, filter := range filters {
+		left := binding[filter.Var]
+		right, ok := binding.resolve(filter.Value)
+		if !ok || !evalQueryFilter(filter.Op, left, right) {
+			return false
+		}
+	}
+	return true
+}
+
+// evalQueryFilter compares two values with a filter operator. Values that
+// cannot be ordered fail every comparison except !=.
+func evalQueryFilter(op FilterOp, left, right interface{}) bool {
+	switch op {
+	case FilterEq:
+		return queryValuesEqual(left, right)
+	case FilterNe:
+		return !queryValuesEqual(left, right)
+	case FilterContains:
+		return containsIgnoreCase(queryValueString(left), queryValueString(right))
+	}
+
+	a, okA := AsPropertyValue(left)
+	b, okB := AsPropertyValue(right)
+	if !okA || !okB {
+		return false
+	}
+	cmp, err := a.Compare(b)
+	if err != nil {
+		return false
+	}
+	switch op {
+	case FilterLt:
+		return cmp < 0
+	case FilterLe:
+		return cmp <= 0
+	case FilterGt:
+		return cmp > 0
+	case FilterGe:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// queryValuesEqual compares node IDs, type names and property values.
+func queryValuesEqual(a, b interface{}) bool {
+	if ta, ok := a.(NodeType); ok {
+		tb, ok := b.(NodeType)
+		return ok && ta == tb
+	}
+	return valuesEqual(a, b)
+}
+
+// queryValueString returns the text form of a value for contains.
+func queryValueString(value interface{}) string {
+	if typed, ok := AsPropertyValue(value); ok {
+		return typed.String()
+	}
+	return fmt.Sprint(value)
+}
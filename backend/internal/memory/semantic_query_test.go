@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+)
+
+// ============================================================================
+// Query Language Tests
+// ============================================================================
+
+func TestParseQuery(t *testing.T) {
+	query, err := ParseQuery(`
+		SELECT DISTINCT ?x ?t WHERE {
+			?x is-a sorting-algorithm .
+			?x @time_complexity ?t .
+			?x type instance
+			FILTER(?t contains "log")
+		} LIMIT 5 OFFSET 1`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	if !query.Distinct || len(query.Vars) != 2 {
+		t.Errorf("Expected DISTINCT with 2 vars, got %v %v", query.Distinct, query.Vars)
+	}
+	if len(query.Patterns) != 3 || len(query.Filters) != 1 {
+		t.Fatalf("Expected 3 patterns and 1 filter, got %d and %d", len(query.Patterns), len(query.Filters))
+	}
+	if query.Patterns[0].Kind != PatternRelation || query.Patterns[0].Relation != IsA {
+		t.Errorf("Expected IS-A pattern, got %s", query.Patterns[0])
+	}
+	if query.Patterns[1].Kind != PatternProperty || query.Patterns[1].Property != "time_complexity" {
+		t.Errorf("Expected property pattern, got %s", query.Patterns[1])
+	}
+	if query.Patterns[2].Object.Value != InstanceNode {
+		t.Errorf("Expected instance type, got %v", query.Patterns[2].Object.Value)
+	}
+	if query.Filters[0].Op != FilterContains {
+		t.Errorf("Expected contains filter, got %s", query.Filters[0].Op)
+	}
+	if query.Limit != 5 || query.Offset != 1 {
+		t.Errorf("Expected LIMIT 5 OFFSET 1, got %d %d", query.Limit, query.Offset)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"SELECT WHERE { ?x is-a ?y }",
+		"SELECT ?x WHERE { }",
+		"SELECT ?x WHERE { ?x unknown-rel ?y }",
+		"SELECT ?x WHERE { ?x type widget }",
+		"SELECT ?z WHERE { ?x is-a ?y }",
+		"SELECT ?x WHERE { ?x is-a ?y FILTER(?z = 1) }",
+		`SELECT ?x WHERE { ?x @name "open }`,
+		"SELECT ?x WHERE { ?x is-a ?y } LIMIT many",
+	}
+	for _, text := range tests {
+		if _, err := ParseQuery(text); !errors.Is(err, ErrQuerySyntax) {
+			t.Errorf("Expected ErrQuerySyntax for %q, got %v", text, err)
+		}
+	}
+}
+
+func TestSemanticNetwork_Query(t *testing.T) {
+	sn := buildQANetwork()
+
+	result, err := sn.Query(`SELECT ?x WHERE { ?x is-a sorting-algorithm }`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["x"] != "mergesort" || result.Rows[1]["x"] != "quicksort" {
+		t.Errorf("Expected mergesort and quicksort, got %v", result.Rows)
+	}
+
+	// Join across relations: what do sorting algorithms require?
+	result, err = sn.Query(`SELECT ?x ?r WHERE { ?x is-a sorting-algorithm . ?x requires ?r }`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["r"] != "memory" {
+		t.Errorf("Expected 2 requirements of mergesort, got %v", result.Rows)
+	}
+}
+
+func TestSemanticNetwork_QueryFilters(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	latencies := map[string]PropertyValue{
+		"fast":   NumberValue(20, "ms"),
+		"medium": NumberValue(150, "ms"),
+		"slow":   NumberValue(2, "s"),
+	}
+	for id, latency := range latencies {
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.SetProperty("latency", latency)
+		sn.AddNode(node)
+	}
+
+	result, err := sn.Query(`SELECT ?x WHERE { ?x @latency ?l . FILTER(?l < 200ms) }`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["x"] != "fast" || result.Rows[1]["x"] != "medium" {
+		t.Errorf("Expected fast and medium, got %v", result.Rows)
+	}
+
+	result, err = sn.Query(`SELECT ?x WHERE { ?x type instance . FILTER(?x != "slow") } LIMIT 1`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 1 || !result.Truncated {
+		t.Errorf("Expected 1 truncated row, got %d rows (truncated=%v)", len(result.Rows), result.Truncated)
+	}
+}
+
+func TestSemanticNetwork_QueryDistinct(t *testing.T) {
+	sn := buildQANetwork()
+
+	result, err := sn.Query(`SELECT DISTINCT ?c WHERE { ?x is-a ?c . ?c type concept }`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("Expected algorithm and sorting-algorithm, got %v", result.Rows)
+	}
+}
+
+func TestSemanticNetwork_PlanQuery(t *testing.T) {
+	sn := buildQANetwork()
+
+	query, err := ParseQuery(`SELECT * WHERE {
+		?x type concept .
+		?x requires ?r .
+		?y is-a ?x .
+		?y @time_complexity ?t .
+		FILTER(?t contains "n")
+	}`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	plan := sn.PlanQuery(query)
+	if len(plan.Steps) != 4 {
+		t.Fatalf("Expected 4 steps, got %d", len(plan.Steps))
+	}
+
+	// Requires has 2 relations, fewer than the 4 concepts
+	if plan.Steps[0].Access != AccessRelationIndex || plan.Steps[0].Pattern.Relation != Requires {
+		t.Errorf("Expected plan to start from the requires index, got %s via %s",
+			plan.Steps[0].Pattern, plan.Steps[0].Access)
+	}
+	for _, step := range plan.Steps[1:] {
+		if step.Access == AccessRelationIndex || step.Access == AccessTypeIndex || step.Access == AccessNodeScan {
+			t.Errorf("Expected later steps to follow bound variables, got %s via %s", step.Pattern, step.Access)
+		}
+	}
+	last := plan.Steps[len(plan.Steps)-1]
+	if len(last.Filters) != 1 {
+		t.Errorf("Expected filter pushed to the step binding ?t, got %v", last.Filters)
+	}
+}
+
+func TestSemanticNetwork_QueryPropertyIndex(t *testing.T) {
+	config := DefaultSemanticNetworkConfig()
+	config.IndexedProperties = []string{"language"}
+	sn := NewSemanticNetwork(config)
+	for id, language := range map[string]string{"gin": "go", "chi": "go", "flask": "python"} {
+		node := NewSemanticNode(id, id, InstanceNode)
+		node.SetProperty("language", language)
+		sn.AddNode(node)
+	}
+
+	result, err := sn.Query(`SELECT ?x WHERE { ?x type instance . ?x @language go }`)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Plan.Steps[0].Access != AccessPropertyIndex {
+		t.Errorf("Expected plan to start from the property index, got %s", result.Plan.Steps[0].Access)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["x"] != "chi" {
+		t.Errorf("Expected chi and gin, got %v", result.Rows)
+	}
+}
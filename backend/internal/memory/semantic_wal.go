@@ -0,0 +1,184 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file connects the Semantic Network to the write-ahead log.
+//
+// With a log attached, every node, relation and property mutation is
+// appended under the network's write lock before it is applied, so the log
+// order matches the order mutations took effect. Transient state such as
+// activation and access counts is not logged.
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AttachWAL logs every subsequent mutation to wal before applying it.
+// Pass nil to stop logging.
+func (sn *SemanticNetwork) AttachWAL(wal *WriteAheadLog) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	sn.wal = wal
+}
+
+// logMutation appends a record if a log is attached. Caller must hold sn.mu
+// for writing.
+func (sn *SemanticNetwork) logMutation(rec *WALRecord) error {
+	if sn.wal == nil {
+		return nil
+	}
+	if _, err := sn.wal.Append(rec); err != nil {
+		return fmt.Errorf("logging %s: %w", rec.Op, err)
+	}
+	return nil
+}
+
+// logNode logs a node write. Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) logNode(op WALOp, node *SemanticNode) error {
+	if sn.wal == nil {
+		return nil
+	}
+	props, err := encodeWALValues(node.Properties)
+	if err != nil {
+		return fmt.Errorf("%w: node %s: %v", ErrPersistenceFailed, node.ID, err)
+	}
+	clone := node.Clone()
+	clone.Properties = nil
+	return sn.logMutation(&WALRecord{Op: op, Node: clone, Properties: props})
+}
+
+// logRelation logs a relation write. Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) logRelation(op WALOp, rel *SemanticRelation) error {
+	if sn.wal == nil {
+		return nil
+	}
+	props, err := encodeWALValues(rel.Properties)
+	if err != nil {
+		return fmt.Errorf("%w: relation %s: %v", ErrPersistenceFailed, rel.ID, err)
+	}
+	relCopy := *rel
+	relCopy.Properties = nil
+	return sn.logMutation(&WALRecord{Op: op, Relation: &relCopy, Properties: props})
+}
+
+// logProperty logs a single property write. Caller must hold sn.mu for writing.
+func (sn *SemanticNetwork) logProperty(nodeID, key string, value interface{}) error {
+	if sn.wal == nil {
+		return nil
+	}
+	encoded, err := encodeWALValue(value)
+	if err != nil {
+		return fmt.Errorf("%w: property %s on %s: %v", ErrPersistenceFailed, key, nodeID, err)
+	}
+	return sn.logMutation(&WALRecord{Op: WALNodeProperty, ID: nodeID, Key: key, Value: &encoded})
+}
+
+// ReplayWAL applies the records after afterLSN, typically the LSN of the
+// snapshot just restored. Replay is idempotent: re-adding an existing node
+// updates it, and removing a missing one is ignored. Logging is suspended
+// while replaying so records are not written twice.
+func (sn *SemanticNetwork) ReplayWAL(wal *WriteAheadLog, afterLSN uint64) (int, error) {
+	sn.mu.Lock()
+	attached := sn.wal
+	sn.wal = nil
+	sn.mu.Unlock()
+
+	defer func() {
+		sn.mu.Lock()
+		sn.wal = attached
+		sn.mu.Unlock()
+	}()
+
+	return wal.Replay(afterLSN, sn.applyWALRecord)
+}
+
+// migratedRelationID finds the relation a legacy relation ID, logged before
+// a snapshot migration renamed it, now goes by.
+func (sn *SemanticNetwork) migratedRelationID(legacyID string) (string, bool) {
+	if strings.HasPrefix(legacyID, "rel-") {
+		return "", false
+	}
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+	for id, rel := range sn.relations {
+		if legacyRelationID(rel.SourceID, rel.Type.String(), rel.TargetID) == legacyID {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// applyWALRecord re-applies one logged mutation.
+func (sn *SemanticNetwork) applyWALRecord(rec *WALRecord) error {
+	switch rec.Op {
+	case WALNodeAdd, WALNodeUpdate:
+		if rec.Node == nil {
+			return fmt.Errorf("%w: %s without node", ErrWALCorrupt, rec.Op)
+		}
+		props, err := decodeWALValues(rec.Properties)
+		if err != nil {
+			return err
+		}
+		node := rec.Node
+		node.Properties = props
+		if err := sn.addNode(node); errors.Is(err, ErrNodeAlreadyExists) {
+			return sn.UpdateNode(node)
+		} else if err != nil {
+			return err
+		}
+
+	case WALNodeRemove:
+		if err := sn.RemoveNode(rec.ID); err != nil && !errors.Is(err, ErrNodeNotFound) {
+			return err
+		}
+
+	case WALNodeProperty:
+		if rec.Value == nil {
+			return fmt.Errorf("%w: %s without value", ErrWALCorrupt, rec.Op)
+		}
+		value, err := rec.Value.decode()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrWALCorrupt, err)
+		}
+		if err := sn.SetNodeProperty(rec.ID, rec.Key, value); err != nil && !errors.Is(err, ErrNodeNotFound) {
+			return err
+		}
+
+	case WALRelationAdd:
+		if rec.Relation == nil {
+			return fmt.Errorf("%w: %s without relation", ErrWALCorrupt, rec.Op)
+		}
+		props, err := decodeWALValues(rec.Properties)
+		if err != nil {
+			return err
+		}
+		rel := rec.Relation
+		rel.Properties = props
+		if rel.ID == legacyRelationID(rel.SourceID, rel.Type.String(), rel.TargetID) {
+			rel.ID = RelationID(rel.SourceID, rel.Type, rel.TargetID)
+		}
+		if err := sn.AddRelation(rel); err != nil && !errors.Is(err, ErrRelationAlreadyExists) {
+			return err
+		}
+
+	case WALRelationRemove:
+		err := sn.RemoveRelation(rec.ID)
+		if errors.Is(err, ErrRelationNotFound) {
+			if id, ok := sn.migratedRelationID(rec.ID); ok {
+				err = sn.RemoveRelation(id)
+			}
+		}
+		if err != nil && !errors.Is(err, ErrRelationNotFound) {
+			return err
+		}
+
+	case WALNetworkClear:
+		return sn.Clear()
+
+	default:
+		return fmt.Errorf("%w: unexpected op %s in semantic log", ErrWALCorrupt, rec.Op)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// ============================================================================
+// Semantic Network WAL Tests
+// ============================================================================
+
+func TestSemanticNetwork_WALReplay(t *testing.T) {
+	wal, path := openTestWAL(t)
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AttachWAL(wal)
+
+	sorting := NewSemanticNode("sorting", "Sorting", ConceptNode)
+	sorting.SetProperty("complexity", NumberValue(2, ""))
+	sn.AddNode(sorting)
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("temp", "Temp", InstanceNode))
+	sn.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+	sn.SetNodeProperty("quicksort", "stable", false)
+	sn.RemoveNode("temp")
+	wal.Close()
+
+	// Crash: rebuild from the log alone
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	applied, err := recovered.ReplayWAL(wal, 0)
+	if err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if applied != 6 {
+		t.Errorf("Expected 6 records, got %d", applied)
+	}
+
+	if recovered.NodeCount() != 2 || recovered.RelationCount() != 1 {
+		t.Errorf("Expected 2 nodes and 1 relation, got %d and %d", recovered.NodeCount(), recovered.RelationCount())
+	}
+	if !recovered.IsA("quicksort", "sorting") {
+		t.Error("Expected quicksort IS-A sorting after replay")
+	}
+	node, _ := recovered.GetNode("quicksort")
+	if node.Properties["stable"] != false {
+		t.Errorf("Expected stable=false, got %v", node.Properties["stable"])
+	}
+	concept, _ := recovered.GetNode("sorting")
+	if _, ok := concept.Properties["complexity"].(PropertyValue); !ok {
+		t.Errorf("Expected typed property, got %T", concept.Properties["complexity"])
+	}
+}
+
+func TestSemanticNetwork_WALReplayLegacyRelationIDs(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("heapsort", "HeapSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("sorting", "Sorting", ConceptNode))
+	sn.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+
+	// Records logged before the snapshot migration use joined IDs
+	legacy := NewSemanticRelation("heapsort", "sorting", IsA)
+	legacy.ID = "heapsort-is-a-sorting"
+	if err := sn.applyWALRecord(&WALRecord{Op: WALRelationAdd, Relation: legacy}); err != nil {
+		t.Fatalf("Replaying legacy add failed: %v", err)
+	}
+	if _, err := sn.GetRelation(RelationID("heapsort", IsA, "sorting")); err != nil {
+		t.Errorf("Expected legacy add stored under RelationID, got %v", err)
+	}
+
+	if err := sn.applyWALRecord(&WALRecord{Op: WALRelationRemove, ID: "quicksort-is-a-sorting"}); err != nil {
+		t.Fatalf("Replaying legacy remove failed: %v", err)
+	}
+	if sn.IsA("quicksort", "sorting") {
+		t.Error("Expected legacy remove to find the migrated relation")
+	}
+	if err := sn.applyWALRecord(&WALRecord{Op: WALRelationRemove, ID: "quicksort-is-a-sorting"}); err != nil {
+		t.Errorf("Expected repeated remove to be ignored, got %v", err)
+	}
+}
+
+func TestSemanticNetwork_WALSnapshotTail(t *testing.T) {
+	wal, _ := openTestWAL(t)
+	defer wal.Close()
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AttachWAL(wal)
+
+	sn.AddNode(NewSemanticNode("a", "A", ConceptNode))
+	snapshot := sn.Snapshot()
+	if snapshot.LSN != 1 {
+		t.Errorf("Expected snapshot at LSN 1, got %d", snapshot.LSN)
+	}
+	sn.AddNode(NewSemanticNode("b", "B", ConceptNode))
+
+	// Restore the snapshot and replay only what came after it
+	recovered := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	recovered.Restore(snapshot)
+	applied, err := recovered.ReplayWAL(wal, snapshot.LSN)
+	if err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if applied != 1 || recovered.NodeCount() != 2 {
+		t.Errorf("Expected 1 replayed record and 2 nodes, got %d and %d", applied, recovered.NodeCount())
+	}
+
+	// Replay is idempotent
+	if _, err := recovered.ReplayWAL(wal, 0); err != nil {
+		t.Fatalf("Second ReplayWAL failed: %v", err)
+	}
+	if recovered.NodeCount() != 2 {
+		t.Errorf("Expected 2 nodes after second replay, got %d", recovered.NodeCount())
+	}
+}
+
+func TestSemanticNetwork_WALEviction(t *testing.T) {
+	wal, _ := openTestWAL(t)
+	defer wal.Close()
+	config := DefaultSemanticNetworkConfig()
+	config.MaxNodes = 2
+	sn := NewSemanticNetwork(config)
+	sn.AttachWAL(wal)
+
+	sn.AddNode(NewSemanticNode("a", "A", ConceptNode))
+	sn.AddNode(NewSemanticNode("b", "B", ConceptNode))
+	sn.GetNode("a") // b is now least recently used
+	sn.AddNode(NewSemanticNode("c", "C", ConceptNode))
+
+	recovered := NewSemanticNetwork(config)
+	if _, err := recovered.ReplayWAL(wal, 0); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if _, err := recovered.GetNode("a"); err != nil {
+		t.Error("Expected the logged eviction of b to be replayed, not a replay-time eviction of a")
+	}
+}
+
+func TestSemanticNetwork_WALClosedRejectsWrites(t *testing.T) {
+	wal, err := OpenWAL(filepath.Join(t.TempDir(), "closed.wal"), WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AttachWAL(wal)
+	wal.Close()
+
+	if err := sn.AddNode(NewSemanticNode("a", "A", ConceptNode)); err == nil {
+		t.Error("Expected write to fail when the log is closed")
+	}
+	if sn.NodeCount() != 0 {
+		t.Errorf("Expected unlogged write not to be applied, got %d nodes", sn.NodeCount())
+	}
+}
@@ -0,0 +1,335 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements speculative parallel retrieval.
+//
+// Episodic retrieval, keyword search, and semantic retrieval are started
+// together for each query. The first to return a useful result (at least
+// MinUseful experiences) wins and the others are cancelled; if none does
+// before the sub-deadline, the largest partial result is used. Since the
+// slowest source no longer sets the latency, the tail of the invoke path
+// shrinks to that of the fastest source able to answer.
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RetrievalSource is one way of retrieving experiences for a query.
+// Sources should return promptly once ctx is done; a result that arrives
+// after the race is decided is discarded.
+type RetrievalSource interface {
+	// Name identifies the source in results and statistics
+	Name() string
+	Retrieve(ctx context.Context, query *QueryContext) (*RetrievalResult, error)
+}
+
+// retrievalSourceFunc adapts a function to RetrievalSource.
+type retrievalSourceFunc struct {
+	name string
+	fn   func(ctx context.Context, query *QueryContext) (*RetrievalResult, error)
+}
+
+func (s retrievalSourceFunc) Name() string { return s.name }
+
+func (s retrievalSourceFunc) Retrieve(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+	return s.fn(ctx, query)
+}
+
+// NewRetrievalSource creates a retrieval source from a function.
+func NewRetrievalSource(name string, fn func(ctx context.Context, query *QueryContext) (*RetrievalResult, error)) RetrievalSource {
+	return retrievalSourceFunc{name: name, fn: fn}
+}
+
+// EpisodicSource retrieves through the retriever's exact, LSH, and HNSW tiers.
+func EpisodicSource(r *SubLinearRetriever) RetrievalSource {
+	return NewRetrievalSource("episodic", r.RetrieveContext)
+}
+
+// KeywordSource retrieves experiences sharing words with the query input.
+func KeywordSource(r *SubLinearRetriever) RetrievalSource {
+	return NewRetrievalSource("keyword", func(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+		terms := make(map[string]float64)
+		for _, term := range retrievalTerms(query.Input) {
+			terms[term] = 1
+		}
+		return r.SearchTerms(ctx, query, terms, "keyword")
+	})
+}
+
+// SemanticSource retrieves experiences mentioning the concepts the query
+// input links to in the semantic network, or their direct neighbours.
+// Neighbour terms count for the weight of the connecting relation.
+func SemanticSource(sn *SemanticNetwork, r *SubLinearRetriever) RetrievalSource {
+	return NewRetrievalSource("semantic", func(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+		terms, err := sn.conceptTerms(ctx, query.Input)
+		if err != nil {
+			return nil, err
+		}
+		return r.SearchTerms(ctx, query, terms, "semantic")
+	})
+}
+
+// conceptTerms weighs the terms of the concepts text links to and of their
+// neighbours. Unlike GetNode it reads under the read lock and leaves access
+// statistics alone, so a speculative lookup neither waits for other readers
+// nor counts as a use of the nodes it reads.
+func (sn *SemanticNetwork) conceptTerms(ctx context.Context, text string) (map[string]float64, error) {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+
+	parsed, err := sn.linkEntities(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	terms := make(map[string]float64)
+	addTerms := func(label string, weight float64) {
+		for _, term := range retrievalTerms(label) {
+			if weight > terms[term] {
+				terms[term] = weight
+			}
+		}
+	}
+	for _, entity := range parsed.entities {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		addTerms(entity.Label, 1)
+		for _, rel := range sn.outgoing[entity.ID] {
+			if node, ok := sn.nodes[rel.TargetID]; ok {
+				addTerms(node.Label, rel.Weight)
+			}
+		}
+		for _, rel := range sn.incoming[entity.ID] {
+			if node, ok := sn.nodes[rel.SourceID]; ok {
+				addTerms(node.Label, rel.Weight)
+			}
+		}
+	}
+	return terms, nil
+}
+
+// retrievalTerms splits text into lowercase words without stopwords.
+func retrievalTerms(text string) []string {
+	terms := make([]string, 0)
+	for _, token := range tokenizeQuestion(text) {
+		if !qaStopwords[token] {
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// SearchTerms scores experiences by the summed weights of terms in their
+// input and returns the best that pass the query filters. It scans every
+// experience and gives up when ctx is done.
+func (r *SubLinearRetriever) SearchTerms(ctx context.Context, query *QueryContext, terms map[string]float64, method string) (*RetrievalResult, error) {
+	startTime := time.Now()
+	result := &RetrievalResult{Experiences: make([]*ExperienceTuple, 0), RetrievalMethod: method}
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	type scored struct {
+		exp   *ExperienceTuple
+		score float64
+	}
+	matches := make([]scored, 0)
+	now := time.Now().UnixNano()
+
+	r.expMu.RLock()
+	checked := 0
+	for _, exp := range r.experiences {
+		if checked++; checked%256 == 0 && ctx.Err() != nil {
+			r.expMu.RUnlock()
+			return nil, ctx.Err()
+		}
+		if !acceptsExperience(query, exp, now) {
+			continue
+		}
+		score := 0.0
+		seen := make(map[string]bool)
+		for _, token := range tokenizeQuestion(exp.Input) {
+			if weight, ok := terms[token]; ok && !seen[token] {
+				seen[token] = true
+				score += weight
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{exp: exp, score: score})
+		}
+	}
+	r.expMu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].exp.ID < matches[j].exp.ID
+	})
+	result.TotalCandidates = len(matches)
+	for _, m := range matches[:min(len(matches), query.TopK)] {
+		result.Experiences = append(result.Experiences, m.exp)
+	}
+	result.RetrievalLatencyNs = time.Since(startTime).Nanoseconds()
+	return result, nil
+}
+
+// ============================================================================
+// Speculative Retriever
+// ============================================================================
+
+// SpeculativeConfig holds configuration for speculative retrieval.
+type SpeculativeConfig struct {
+	// SubDeadline bounds the race; the caller's deadline applies if sooner
+	SubDeadline time.Duration
+	// MinUseful is the number of experiences that makes a result useful
+	MinUseful int
+}
+
+// DefaultSpeculativeConfig returns the default speculative configuration.
+func DefaultSpeculativeConfig() SpeculativeConfig {
+	return SpeculativeConfig{
+		SubDeadline: 150 * time.Millisecond,
+		MinUseful:   1,
+	}
+}
+
+// SpeculativeStats tracks the outcomes of retrieval races.
+type SpeculativeStats struct {
+	Races int64 `json:"races"`
+	// Wins counts the races each source won with a useful result
+	Wins map[string]int64 `json:"wins"`
+	// Partial counts races decided by the best result at the sub-deadline
+	// or once every source had answered without a useful result
+	Partial int64 `json:"partial"`
+	// Errors counts source failures by source
+	Errors map[string]int64 `json:"errors"`
+}
+
+// SpeculativeRetriever races retrieval sources against each other.
+type SpeculativeRetriever struct {
+	config  SpeculativeConfig
+	sources []RetrievalSource
+
+	mu    sync.Mutex
+	stats SpeculativeStats
+}
+
+// NewSpeculativeRetriever creates a retriever racing the given sources.
+func NewSpeculativeRetriever(config SpeculativeConfig, sources ...RetrievalSource) *SpeculativeRetriever {
+	if config.MinUseful <= 0 {
+		config.MinUseful = 1
+	}
+	return &SpeculativeRetriever{
+		config:  config,
+		sources: sources,
+		stats: SpeculativeStats{
+			Wins:   make(map[string]int64),
+			Errors: make(map[string]int64),
+		},
+	}
+}
+
+// sourceOutcome is a source's answer in a race.
+type sourceOutcome struct {
+	source string
+	result *RetrievalResult
+	err    error
+}
+
+// Retrieve runs every source in parallel and returns the first useful
+// result, cancelling the rest. It never fails: without any result it
+// returns an empty fallback result.
+func (s *SpeculativeRetriever) Retrieve(ctx context.Context, query *QueryContext) *RetrievalResult {
+	startTime := time.Now()
+	raceCtx, cancel := ctx, context.CancelFunc(func() {})
+	if s.config.SubDeadline > 0 {
+		raceCtx, cancel = context.WithTimeout(ctx, s.config.SubDeadline)
+	}
+	defer cancel()
+
+	// Buffered so sources that finish after the race don't block
+	outcomes := make(chan sourceOutcome, len(s.sources))
+	for _, source := range s.sources {
+		go func(source RetrievalSource) {
+			result, err := source.Retrieve(raceCtx, query)
+			outcomes <- sourceOutcome{source: source.Name(), result: result, err: err}
+		}(source)
+	}
+
+	s.mu.Lock()
+	s.stats.Races++
+	s.mu.Unlock()
+
+	var best *RetrievalResult
+race:
+	for pending := len(s.sources); pending > 0; pending-- {
+		select {
+		case outcome := <-outcomes:
+			if outcome.err != nil || outcome.result == nil {
+				s.recordError(outcome)
+				continue
+			}
+			if len(outcome.result.Experiences) >= s.config.MinUseful {
+				s.mu.Lock()
+				s.stats.Wins[outcome.source]++
+				s.mu.Unlock()
+				outcome.result.RetrievalLatencyNs = time.Since(startTime).Nanoseconds()
+				return outcome.result
+			}
+			if best == nil || len(outcome.result.Experiences) > len(best.Experiences) {
+				best = outcome.result
+			}
+		case <-raceCtx.Done():
+			break race
+		}
+	}
+
+	s.mu.Lock()
+	s.stats.Partial++
+	s.mu.Unlock()
+	if best == nil || len(best.Experiences) == 0 {
+		best = &RetrievalResult{Experiences: []*ExperienceTuple{}, RetrievalMethod: "fallback"}
+	}
+	best.RetrievalLatencyNs = time.Since(startTime).Nanoseconds()
+	return best
+}
+
+// recordError counts a failed source, ignoring cancellation by the race.
+func (s *SpeculativeRetriever) recordError(outcome sourceOutcome) {
+	if outcome.err == context.Canceled || outcome.err == context.DeadlineExceeded {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Errors[outcome.source]++
+}
+
+// Sources returns the names of the raced sources.
+func (s *SpeculativeRetriever) Sources() []string {
+	names := make([]string, len(s.sources))
+	for i, source := range s.sources {
+		names[i] = source.Name()
+	}
+	return names
+}
+
+// GetStats returns a copy of the race statistics.
+func (s *SpeculativeRetriever) GetStats() SpeculativeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Wins = make(map[string]int64, len(s.stats.Wins))
+	for name, n := range s.stats.Wins {
+		stats.Wins[name] = n
+	}
+	stats.Errors = make(map[string]int64, len(s.stats.Errors))
+	for name, n := range s.stats.Errors {
+		stats.Errors[name] = n
+	}
+	return stats
+}
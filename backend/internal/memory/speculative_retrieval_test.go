@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// ============================================================================
+// Speculative Retriever Tests
+// ============================================================================
+
+// staticSource returns n experiences after delay, or ctx's error if sooner.
+func staticSource(name string, n int, delay time.Duration, cancelled chan<- string) RetrievalSource {
+	return NewRetrievalSource(name, func(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			if cancelled != nil {
+				cancelled <- name
+			}
+			return nil, ctx.Err()
+		}
+		result := &RetrievalResult{RetrievalMethod: name}
+		for i := 0; i < n; i++ {
+			result.Experiences = append(result.Experiences, NewExperienceTuple("APEX", 1, name, "", ""))
+		}
+		return result, nil
+	})
+}
+
+func TestSpeculativeRetriever_FirstUseful(t *testing.T) {
+	cancelled := make(chan string, 1)
+	s := NewSpeculativeRetriever(SpeculativeConfig{SubDeadline: time.Second, MinUseful: 2},
+		staticSource("empty", 0, 0, nil),
+		staticSource("fast", 2, 10*time.Millisecond, nil),
+		staticSource("slow", 5, time.Hour, cancelled),
+	)
+
+	start := time.Now()
+	result := s.Retrieve(context.Background(), NewQueryContext("APEX", 1, "sort"))
+	if result.RetrievalMethod != "fast" || len(result.Experiences) != 2 {
+		t.Errorf("Expected fast source to win with 2 experiences, got %s with %d", result.RetrievalMethod, len(result.Experiences))
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected race decided by the fast source, took %v", elapsed)
+	}
+	select {
+	case name := <-cancelled:
+		if name != "slow" {
+			t.Errorf("Expected slow source cancelled, got %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected slow source cancelled after the race")
+	}
+
+	stats := s.GetStats()
+	if stats.Races != 1 || stats.Wins["fast"] != 1 || stats.Partial != 0 {
+		t.Errorf("Expected one race won by fast, got %+v", stats)
+	}
+}
+
+func TestSpeculativeRetriever_SubDeadline(t *testing.T) {
+	s := NewSpeculativeRetriever(SpeculativeConfig{SubDeadline: 30 * time.Millisecond, MinUseful: 3},
+		staticSource("partial", 1, 0, nil),
+		staticSource("slow", 5, time.Hour, nil),
+	)
+
+	start := time.Now()
+	result := s.Retrieve(context.Background(), NewQueryContext("APEX", 1, "sort"))
+	if result.RetrievalMethod != "partial" || len(result.Experiences) != 1 {
+		t.Errorf("Expected partial result at the sub-deadline, got %s with %d", result.RetrievalMethod, len(result.Experiences))
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected race bounded by the sub-deadline, took %v", elapsed)
+	}
+	if s.GetStats().Partial != 1 {
+		t.Errorf("Expected partial race counted, got %+v", s.GetStats())
+	}
+}
+
+func TestSpeculativeRetriever_Fallback(t *testing.T) {
+	failing := NewRetrievalSource("failing", func(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+		return nil, errors.New("index unavailable")
+	})
+	s := NewSpeculativeRetriever(DefaultSpeculativeConfig(), failing, staticSource("empty", 0, 0, nil))
+
+	result := s.Retrieve(context.Background(), NewQueryContext("APEX", 1, "sort"))
+	if result.RetrievalMethod != "fallback" || len(result.Experiences) != 0 {
+		t.Errorf("Expected empty fallback, got %s with %d", result.RetrievalMethod, len(result.Experiences))
+	}
+	if s.GetStats().Errors["failing"] != 1 {
+		t.Errorf("Expected source error counted, got %+v", s.GetStats())
+	}
+}
+
+// ============================================================================
+// Retrieval Source Tests
+// ============================================================================
+
+// testRetrieverWith stores experiences with the given inputs for APEX.
+func testRetrieverWith(t *testing.T, inputs ...string) *SubLinearRetriever {
+	r := NewSubLinearRetriever(8)
+	for _, input := range inputs {
+		if err := r.Add(NewExperienceTuple("APEX", 1, input, "", "direct")); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	return r
+}
+
+func TestKeywordSource(t *testing.T) {
+	r := testRetrieverWith(t, "sort a large list quickly", "sort names", "deploy the service")
+	other := NewExperienceTuple("CIPHER", 2, "sort a list", "", "direct")
+	r.Add(other)
+
+	result, err := KeywordSource(r).Retrieve(context.Background(), NewQueryContext("APEX", 1, "How do I sort a list?"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(result.Experiences) != 2 || result.RetrievalMethod != "keyword" {
+		t.Fatalf("Expected 2 keyword matches, got %d via %s", len(result.Experiences), result.RetrievalMethod)
+	}
+	if result.Experiences[0].Input != "sort a large list quickly" {
+		t.Errorf("Expected best overlap first, got %q", result.Experiences[0].Input)
+	}
+	for _, exp := range result.Experiences {
+		if exp.ID == other.ID {
+			t.Error("Expected other-tier experience filtered out")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := KeywordSource(testRetrieverWith(t, make([]string, 300)...)).Retrieve(ctx, NewQueryContext("APEX", 1, "sort")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSemanticSource(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	sn.AddNode(NewSemanticNode("sorting", "Sorting", ConceptNode))
+	sn.AddRelation(NewSemanticRelation("quicksort", "sorting", IsA))
+	r := testRetrieverWith(t, "sorting records by date", "deploy the service")
+
+	// The query never says "sorting"; the graph links it through QuickSort
+	result, err := SemanticSource(sn, r).Retrieve(context.Background(), NewQueryContext("APEX", 1, "tune quicksort pivots"))
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(result.Experiences) != 1 || result.Experiences[0].Input != "sorting records by date" {
+		t.Errorf("Expected the sorting experience via the graph, got %d experiences", len(result.Experiences))
+	}
+
+	result, _ = KeywordSource(r).Retrieve(context.Background(), NewQueryContext("APEX", 1, "tune quicksort pivots"))
+	if len(result.Experiences) != 0 {
+		t.Errorf("Expected no keyword match without the graph, got %d", len(result.Experiences))
+	}
+}
+
+func TestSemanticSource_Cancelled(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	sn.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := SemanticSource(sn, testRetrieverWith(t, "quicksort")).Retrieve(ctx, NewQueryContext("APEX", 1, "tune quicksort"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if node, _ := sn.GetNode("quicksort"); node.AccessCount != 1 {
+		t.Errorf("Expected the lookup not to count as an access, got %d", node.AccessCount)
+	}
+}
+
+func TestSpeculativeRetriever_SlowEpisodicStops(t *testing.T) {
+	r := testRetrieverWith(t, "sort a list")
+	episodic := EpisodicSource(r)
+	stopped := make(chan error, 1)
+	slow := NewRetrievalSource("episodic", func(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
+		result, err := episodic.Retrieve(ctx, query)
+		stopped <- err
+		return result, err
+	})
+
+	// A rehash holding the index makes episodic retrieval slow
+	r.indexMu.Lock()
+	s := NewSpeculativeRetriever(SpeculativeConfig{SubDeadline: time.Second, MinUseful: 1},
+		slow, staticSource("fast", 1, 0, nil))
+	if result := s.Retrieve(context.Background(), NewQueryContext("APEX", 1, "sort a list")); result.RetrievalMethod != "fast" {
+		t.Fatalf("Expected the fast source to win, got %s", result.RetrievalMethod)
+	}
+	r.indexMu.Unlock()
+
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the episodic source to stop with context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the episodic source to stop")
+	}
+}
+
+func TestReMemController_SpeculativeRetrieval(t *testing.T) {
+	controller := NewReMemController(DefaultReMemConfig(), nil)
+	controller.GetRetriever().Add(NewExperienceTuple("APEX", 1, "sort a list of names", "use sort.Strings", "direct"))
+
+	request := &models.CopilotRequest{Messages: []models.Message{{Role: "user", Content: "how should I sort names"}}}
+	if _, err := controller.ExecuteWithMemory(context.Background(), "APEX", request, &deadlineExecutor{}); err != nil {
+		t.Fatalf("ExecuteWithMemory failed: %v", err)
+	}
+	if stats := controller.GetRetrievalStats(); stats.Wins["keyword"] != 1 {
+		t.Errorf("Expected keyword source to win, got %+v", stats)
+	}
+}
@@ -107,7 +107,7 @@ func (sp *StrategicPlanner) CreatePlan(ctx context.Context, goal *Goal) (*Plan,
 	}
 
 	plan := &Plan{
-		ID:          fmt.Sprintf("plan-%s-%d", goal.ID, time.Now().Unix()),
+		ID:          NewID("plan-" + goal.ID),
 		GoalTask:    nil,
 		Actions:     make([]*PlannerAction, 0),
 		TotalCost:   0.0,
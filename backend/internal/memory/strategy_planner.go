@@ -212,7 +212,7 @@ func (msp *MultiStrategyPlanner) GenerateStrategies(ctx context.Context, goal *G
 	msp.requestCount++
 
 	strategySet := &StrategySet{
-		ID:                fmt.Sprintf("strat-set-%s-%d", goal.ID, time.Now().Unix()),
+		ID:                NewID("strat-set-" + goal.ID),
 		GoalID:            goal.ID,
 		Name:              fmt.Sprintf("Strategies for %s", goal.Name),
 		Strategies:        make([]*Strategy, 0),
@@ -0,0 +1,493 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements streaming ingestion of knowledge and experiences.
+//
+// Imports are sent as newline-delimited JSON, one node, relation, or
+// experience per line, and applied as each line arrives rather than after
+// the whole body is buffered. Each stream is rate limited with a token
+// bucket; while a stream waits for tokens its body is not read, so TCP flow
+// control pushes back on the sender. Progress is streamed back as NDJSON
+// events, so a client importing millions of records can follow along and
+// see rejected lines without waiting for the end.
+
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrIngestLineTooLong is returned when a stream line exceeds MaxLineBytes
+	ErrIngestLineTooLong = errdefs.New(errdefs.ErrInvalidArgument, "ingest line too long")
+	// ErrNoExperienceStore is returned for experience records when the
+	// ingester has no retriever
+	ErrNoExperienceStore = errdefs.New(errdefs.ErrUnavailable, "no experience store configured")
+	// ErrNoIngestRecords is returned for text that holds no records
+	ErrNoIngestRecords = errdefs.New(errdefs.ErrInvalidArgument, "no ingest records")
+)
+
+// IngestEventType identifies a streamed ingestion event.
+type IngestEventType string
+
+const (
+	// IngestProgressEvent reports counts so far
+	IngestProgressEvent IngestEventType = "progress"
+	// IngestErrorEvent reports a rejected line
+	IngestErrorEvent IngestEventType = "error"
+	// IngestSummaryEvent reports the final counts and ends the stream
+	IngestSummaryEvent IngestEventType = "summary"
+)
+
+// StreamIngestConfig holds configuration for streaming ingestion.
+type StreamIngestConfig struct {
+	// RecordsPerSecond is each stream's sustained rate; zero is unlimited
+	RecordsPerSecond float64
+	// Burst is how many records a stream may apply at once
+	Burst int
+	// ProgressEvery is the number of records between progress events
+	ProgressEvery int
+	// MaxLineBytes bounds a single record
+	MaxLineBytes int
+	// MaxReportedErrors bounds the error events per stream; further
+	// rejections are only counted
+	MaxReportedErrors int
+	// IdleTimeout is how long a stream may go without reading or writing
+	IdleTimeout time.Duration
+}
+
+// DefaultStreamIngestConfig returns the default ingestion configuration.
+func DefaultStreamIngestConfig() StreamIngestConfig {
+	return StreamIngestConfig{
+		RecordsPerSecond:  5000,
+		Burst:             500,
+		ProgressEvery:     1000,
+		MaxLineBytes:      1 << 20,
+		MaxReportedErrors: 100,
+		IdleTimeout:       30 * time.Second,
+	}
+}
+
+// IngestRecord is one line of an ingestion stream. Exactly one field is set.
+type IngestRecord struct {
+	Node       *NodeView       `json:"node,omitempty"`
+	Relation   *RelationView   `json:"relation,omitempty"`
+	Experience json.RawMessage `json:"experience,omitempty"`
+}
+
+// IngestProgress counts the records handled by a stream.
+type IngestProgress struct {
+	Received    int `json:"received"`
+	Nodes       int `json:"nodes"`
+	Relations   int `json:"relations"`
+	Experiences int `json:"experiences"`
+	// Merged counts experiences merged into near-duplicates
+	Merged    int   `json:"merged"`
+	Rejected  int   `json:"rejected"`
+	ElapsedMs int64 `json:"elapsed_ms"`
+}
+
+// IngestEvent is one line of the response stream.
+type IngestEvent struct {
+	Type IngestEventType `json:"type"`
+	// Line is the 1-based input line an error event refers to
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error,omitempty"`
+	*IngestProgress
+}
+
+// StreamIngester applies ingestion streams to a semantic network and an
+// experience store. Either may be nil, in which case its records are
+// rejected.
+type StreamIngester struct {
+	network   *SemanticNetwork
+	retriever *SubLinearRetriever
+	config    StreamIngestConfig
+}
+
+// NewStreamIngester creates an ingester over the given stores.
+func NewStreamIngester(network *SemanticNetwork, retriever *SubLinearRetriever, config StreamIngestConfig) *StreamIngester {
+	defaults := DefaultStreamIngestConfig()
+	if config.Burst <= 0 {
+		config.Burst = defaults.Burst
+	}
+	if config.ProgressEvery <= 0 {
+		config.ProgressEvery = defaults.ProgressEvery
+	}
+	if config.MaxLineBytes <= 0 {
+		config.MaxLineBytes = defaults.MaxLineBytes
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaults.IdleTimeout
+	}
+	return &StreamIngester{network: network, retriever: retriever, config: config}
+}
+
+// Ingest reads records from r until EOF, applying each as it arrives.
+// Events are passed to emit as they happen; the final counts are returned
+// and also emitted as a summary event. Invalid lines are rejected without
+// stopping the stream; an error is returned only if reading fails or ctx
+// is done.
+func (s *StreamIngester) Ingest(ctx context.Context, r io.Reader, emit func(IngestEvent)) (*IngestProgress, error) {
+	start := time.Now()
+	progress := &IngestProgress{}
+	limiter := newRateLimiter(s.config.RecordsPerSecond, s.config.Burst)
+	snapshot := func() *IngestProgress {
+		p := *progress
+		p.ElapsedMs = time.Since(start).Milliseconds()
+		return &p
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(64*1024, s.config.MaxLineBytes)), s.config.MaxLineBytes)
+	line := 0
+	var err error
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if err = limiter.wait(ctx); err != nil {
+			break
+		}
+
+		progress.Received++
+		if applyErr := s.apply([]byte(text), progress); applyErr != nil {
+			progress.Rejected++
+			if progress.Rejected <= s.config.MaxReportedErrors {
+				emit(IngestEvent{Type: IngestErrorEvent, Line: line, Error: applyErr.Error()})
+			}
+		}
+		if progress.Received%s.config.ProgressEvery == 0 {
+			emit(IngestEvent{Type: IngestProgressEvent, IngestProgress: snapshot()})
+		}
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	if errors.Is(err, bufio.ErrTooLong) {
+		err = fmt.Errorf("%w: line %d exceeds %d bytes", ErrIngestLineTooLong, line+1, s.config.MaxLineBytes)
+	}
+
+	final := snapshot()
+	summary := IngestEvent{Type: IngestSummaryEvent, IngestProgress: final}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	emit(summary)
+	return final, err
+}
+
+// IngestText applies the records among the lines of a text, such as an
+// agent's answer, skipping lines that are not JSON objects: prose and the
+// code fences around the records. It returns the part of the network the
+// records name, with the relations between those nodes whether or not
+// the text added them, so it can be read back by an agent.
+func (s *StreamIngester) IngestText(ctx context.Context, text string) (string, error) {
+	var records []string
+	var named []string
+	seen := make(map[string]bool)
+	name := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			named = append(named, id)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		records = append(records, line)
+		var record IngestRecord
+		if json.Unmarshal([]byte(line), &record) != nil {
+			continue
+		}
+		if record.Node != nil {
+			name(record.Node.ID)
+		}
+		if record.Relation != nil {
+			name(record.Relation.SourceID)
+			name(record.Relation.TargetID)
+		}
+	}
+	if len(records) == 0 {
+		return "", ErrNoIngestRecords
+	}
+
+	var rejected []string
+	progress, err := s.Ingest(ctx, strings.NewReader(strings.Join(records, "\n")), func(event IngestEvent) {
+		if event.Type == IngestErrorEvent {
+			rejected = append(rejected, event.Error)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Knowledge graph: %d nodes and %d relations added", progress.Nodes, progress.Relations)
+	if progress.Rejected > 0 {
+		fmt.Fprintf(&b, ", %d records rejected (%s)", progress.Rejected, strings.Join(rejected, "; "))
+	}
+	b.WriteString(".")
+	if s.network == nil {
+		return b.String(), nil
+	}
+	var nodes []*SemanticNode
+	for _, id := range named {
+		if node, err := s.network.GetNode(id); err == nil {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return b.String(), nil
+	}
+	b.WriteString("\nNodes:")
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "\n- %s (%s, %s)", node.Label, node.ID, node.Type)
+	}
+	b.WriteString("\nRelations:")
+	for _, node := range nodes {
+		for _, rel := range s.network.GetOutgoingRelations(node.ID) {
+			if !seen[rel.TargetID] {
+				continue
+			}
+			if target, err := s.network.GetNode(rel.TargetID); err == nil {
+				fmt.Fprintf(&b, "\n- %s %s %s", node.Label, rel.Type, target.Label)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// apply decodes one record and adds it to the matching store.
+func (s *StreamIngester) apply(raw []byte, progress *IngestProgress) error {
+	var record IngestRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	switch {
+	case record.Node != nil && record.Relation == nil && record.Experience == nil:
+		if s.network == nil {
+			return errors.New("no semantic network configured")
+		}
+		node, err := nodeFromView(record.Node)
+		if err != nil {
+			return err
+		}
+		if err := s.network.AddNode(node); err != nil {
+			return fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		progress.Nodes++
+	case record.Relation != nil && record.Node == nil && record.Experience == nil:
+		if s.network == nil {
+			return errors.New("no semantic network configured")
+		}
+		rel, err := relationFromView(record.Relation)
+		if err != nil {
+			return err
+		}
+		if err := s.network.AddRelation(rel); err != nil {
+			return fmt.Errorf("relation %s: %w", rel.ID, err)
+		}
+		progress.Relations++
+	case record.Experience != nil && record.Node == nil && record.Relation == nil:
+		if s.retriever == nil {
+			return ErrNoExperienceStore
+		}
+		exp, err := decodeIngestExperience(record.Experience)
+		if err != nil {
+			return err
+		}
+		_, merged, err := s.retriever.AddOrMerge(exp)
+		if err != nil {
+			return fmt.Errorf("experience %s: %w", exp.ID, err)
+		}
+		if merged {
+			progress.Merged++
+		}
+		progress.Experiences++
+	default:
+		return errors.New("record must have exactly one of node, relation, or experience")
+	}
+	return nil
+}
+
+// nodeFromView converts an imported node to a semantic node.
+func nodeFromView(view *NodeView) (*SemanticNode, error) {
+	if view.ID == "" {
+		return nil, errors.New("node id is required")
+	}
+	nodeType, ok := parseNodeType(view.Type)
+	if !ok {
+		return nil, fmt.Errorf("node %s has unknown type %q", view.ID, view.Type)
+	}
+	label := view.Label
+	if label == "" {
+		label = view.ID
+	}
+	node := NewSemanticNode(view.ID, label, nodeType)
+	node.Source = "import"
+	if view.Confidence > 0 {
+		node.Confidence = clamp(view.Confidence, 0, 1)
+	}
+	for key, value := range view.Properties {
+		node.SetProperty(key, value)
+	}
+	return node, nil
+}
+
+// relationFromView converts an imported relation to a semantic relation.
+func relationFromView(view *RelationView) (*SemanticRelation, error) {
+	if view.SourceID == "" || view.TargetID == "" {
+		return nil, errors.New("relation source and target are required")
+	}
+	relType, ok := parseRelationType(view.Type)
+	if !ok {
+		return nil, fmt.Errorf("relation has unknown type %q", view.Type)
+	}
+	rel := NewSemanticRelation(view.SourceID, view.TargetID, relType)
+	if view.ID != "" {
+		rel.ID = view.ID
+	}
+	rel.Source = "import"
+	if view.Weight > 0 {
+		rel.Weight = clamp(view.Weight, 0, 1)
+	}
+	if view.Confidence > 0 {
+		rel.Confidence = clamp(view.Confidence, 0, 1)
+	}
+	return rel, nil
+}
+
+// decodeIngestExperience decodes an imported experience. Fields the
+// record omits take the defaults of NewExperienceTuple.
+func decodeIngestExperience(raw json.RawMessage) (*ExperienceTuple, error) {
+	exp := &ExperienceTuple{Success: true, FitnessScore: 0.5, Weight: 1}
+	if err := json.Unmarshal(raw, exp); err != nil {
+		return nil, fmt.Errorf("invalid experience: %v", err)
+	}
+	if exp.AgentID == "" || exp.Input == "" {
+		return nil, fmt.Errorf("%w: agent_id and input", ErrInvalidExperience)
+	}
+	now := time.Now().UnixNano()
+	if exp.Timestamp == 0 {
+		exp.Timestamp = now
+	}
+	if exp.LastAccessTime == 0 {
+		exp.LastAccessTime = exp.Timestamp
+	}
+	if exp.ID == "" {
+		exp.ID = generateExperienceID(exp.AgentID, exp.Input, exp.Timestamp)
+	}
+	if exp.TaskSignature == "" {
+		exp.TaskSignature = computeTaskSignature(exp.Input)
+	}
+	if exp.Metadata == nil {
+		exp.Metadata = make(map[string]interface{})
+	}
+	return exp, nil
+}
+
+// ============================================================================
+// Rate Limiting
+// ============================================================================
+
+// rateLimiter is a token bucket limiting one stream.
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a full bucket; a non-positive rate never waits.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, sleeping until one is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil || l.rate <= 0 {
+		return err
+	}
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return nil
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.tokens = 0
+		l.last = time.Now()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ============================================================================
+// HTTP Handler
+// ============================================================================
+
+// deadlineReader extends the connection deadlines before every read, so a
+// stream may run as long as it keeps making progress.
+type deadlineReader struct {
+	r      io.Reader
+	extend func()
+}
+
+func (d deadlineReader) Read(p []byte) (int, error) {
+	d.extend()
+	return d.r.Read(p)
+}
+
+// ServeIngest handles POST /memory/ingest - applies an NDJSON stream of
+// records and streams NDJSON progress events back. The status is sent
+// before the body is read, so failures after that are reported in the
+// summary event.
+func (s *StreamIngester) ServeIngest(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	extend := func() {
+		// Unsupported by some writers (e.g. in tests); the server's
+		// timeouts then apply
+		deadline := time.Now().Add(s.config.IdleTimeout)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+	}
+	extend()
+
+	// Read the body while writing the response (HTTP/1.x otherwise
+	// closes the request body at the first flush)
+	_ = rc.EnableFullDuplex()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	emit := func(event IngestEvent) {
+		extend()
+		if err := encoder.Encode(event); err == nil {
+			_ = rc.Flush()
+		}
+	}
+
+	s.Ingest(r.Context(), deadlineReader{r: r.Body, extend: extend}, emit)
+}
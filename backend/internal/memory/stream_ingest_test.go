@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Stream Ingester Tests
+// ============================================================================
+
+func TestStreamIngester_Ingest(t *testing.T) {
+	network := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	retriever := NewSubLinearRetriever(8)
+	retriever.EnableDedup(DefaultDedupConfig())
+	s := NewStreamIngester(network, retriever, StreamIngestConfig{ProgressEvery: 3, MaxReportedErrors: 2})
+
+	stream := strings.Join([]string{
+		`{"node": {"id": "sorting", "label": "Sorting", "type": "concept", "properties": {"complexity": 2}}}`,
+		`{"node": {"id": "quicksort", "type": "instance"}}`,
+		`{"relation": {"source": "quicksort", "target": "sorting", "type": "is-a", "weight": 0.8}}`,
+		``,
+		`{"experience": {"agent_id": "APEX", "tier_id": 1, "input": "sort a list of names", "output": "use sort.Strings"}}`,
+		`{"experience": {"agent_id": "APEX", "tier_id": 1, "input": "sort a list of names", "output": "use sort.Strings"}}`,
+		`{"node": `,
+		`{"node": {"id": "heap", "type": "widget"}}`,
+		`{"relation": {"source": "quicksort", "target": "missing", "type": "is-a"}}`,
+	}, "\n")
+
+	events := make([]IngestEvent, 0)
+	progress, err := s.Ingest(context.Background(), strings.NewReader(stream), func(e IngestEvent) {
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+
+	if progress.Received != 8 || progress.Nodes != 2 || progress.Relations != 1 || progress.Experiences != 2 || progress.Merged != 1 || progress.Rejected != 3 {
+		t.Errorf("Expected 8 received with 3 rejected, got %+v", progress)
+	}
+	if network.NodeCount() != 2 || network.RelationCount() != 1 || retriever.Size() != 1 {
+		t.Errorf("Expected 2 nodes, 1 relation and 1 experience stored, got %d, %d and %d", network.NodeCount(), network.RelationCount(), retriever.Size())
+	}
+	rel, err := network.GetRelation(RelationID("quicksort", IsA, "sorting"))
+	if err != nil || rel.Weight != 0.8 || rel.Source != "import" {
+		t.Errorf("Expected imported relation with weight 0.8, got %+v (%v)", rel, err)
+	}
+
+	// Progress after records 3 and 6 around two error events (the third
+	// rejection is only counted), then the summary
+	types := make([]IngestEventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	expected := []IngestEventType{IngestProgressEvent, IngestErrorEvent, IngestProgressEvent, IngestErrorEvent, IngestSummaryEvent}
+	if len(types) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("Expected events %v, got %v", expected, types)
+		}
+	}
+	if events[1].Line != 7 || events[3].Line != 8 {
+		t.Errorf("Expected errors on lines 7 and 8, got %d and %d", events[1].Line, events[3].Line)
+	}
+	if events[4].Rejected != 3 || events[4].Error != "" {
+		t.Errorf("Expected clean summary with 3 rejected, got %+v", events[4])
+	}
+}
+
+func TestStreamIngester_NoExperienceStore(t *testing.T) {
+	s := NewStreamIngester(NewSemanticNetwork(DefaultSemanticNetworkConfig()), nil, StreamIngestConfig{MaxReportedErrors: 10})
+
+	var reported string
+	s.Ingest(context.Background(), strings.NewReader(`{"experience": {"agent_id": "APEX", "input": "sort"}}`), func(e IngestEvent) {
+		if e.Type == IngestErrorEvent {
+			reported = e.Error
+		}
+	})
+	if reported != ErrNoExperienceStore.Error() {
+		t.Errorf("Expected %q, got %q", ErrNoExperienceStore.Error(), reported)
+	}
+}
+
+func TestStreamIngester_IngestText(t *testing.T) {
+	network := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	network.AddNode(NewSemanticNode("orders-db", "Orders DB", InstanceNode))
+	s := NewStreamIngester(network, nil, StreamIngestConfig{MaxReportedErrors: 10})
+
+	text := strings.Join([]string{
+		"Here is the component graph:",
+		"```json",
+		`{"node": {"id": "api-gateway", "label": "API Gateway", "type": "instance", "properties": {"kind": "component"}}}`,
+		`{"relation": {"source": "api-gateway", "target": "orders-db", "type": "requires"}}`,
+		`{"relation": {"source": "api-gateway", "target": "billing", "type": "requires"}}`,
+		"```",
+	}, "\n")
+	described, err := s.IngestText(context.Background(), text)
+	if err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+	for _, want := range []string{
+		"1 nodes and 1 relations added, 1 records rejected (relation ",
+		"- API Gateway (api-gateway, instance)",
+		"- Orders DB (orders-db, instance)",
+		"- API Gateway requires Orders DB",
+	} {
+		if !strings.Contains(described, want) {
+			t.Errorf("Expected %q in the description, got %q", want, described)
+		}
+	}
+
+	if _, err := s.IngestText(context.Background(), "No records here."); !errors.Is(err, ErrNoIngestRecords) {
+		t.Errorf("Expected ErrNoIngestRecords, got %v", err)
+	}
+}
+
+func TestStreamIngester_RateLimit(t *testing.T) {
+	s := NewStreamIngester(NewSemanticNetwork(DefaultSemanticNetworkConfig()), nil, StreamIngestConfig{RecordsPerSecond: 100, Burst: 1})
+	lines := make([]string, 6)
+	for i := range lines {
+		lines[i] = `{"node": {"id": "n` + string(rune('0'+i)) + `", "type": "concept"}}`
+	}
+
+	start := time.Now()
+	progress, err := s.Ingest(context.Background(), strings.NewReader(strings.Join(lines, "\n")), func(IngestEvent) {})
+	if err != nil || progress.Nodes != 6 {
+		t.Fatalf("Expected 6 nodes ingested, got %+v (%v)", progress, err)
+	}
+	// One record from the burst, then five at 10ms apart
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("Expected ingestion throttled to at least 50ms, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Ingest(ctx, strings.NewReader(lines[0]), func(IngestEvent) {}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamIngester_LineTooLong(t *testing.T) {
+	s := NewStreamIngester(NewSemanticNetwork(DefaultSemanticNetworkConfig()), nil, StreamIngestConfig{MaxLineBytes: 64})
+	stream := `{"node": {"id": "a", "type": "concept"}}` + "\n" + `{"node": {"id": "b", "label": "` + strings.Repeat("x", 100) + `", "type": "concept"}}`
+
+	var summary IngestEvent
+	progress, err := s.Ingest(context.Background(), strings.NewReader(stream), func(e IngestEvent) { summary = e })
+	if !errors.Is(err, ErrIngestLineTooLong) {
+		t.Fatalf("Expected ErrIngestLineTooLong, got %v", err)
+	}
+	if progress.Nodes != 1 || summary.Type != IngestSummaryEvent || !strings.Contains(summary.Error, "line 2") {
+		t.Errorf("Expected first line applied and the long line reported, got %+v", summary)
+	}
+}
+
+func TestStreamIngester_ServeIngest(t *testing.T) {
+	network := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	s := NewStreamIngester(network, nil, StreamIngestConfig{ProgressEvery: 1})
+	server := httptest.NewServer(http.HandlerFunc(s.ServeIngest))
+	defer server.Close()
+
+	body, writer := io.Pipe()
+	go writer.Write([]byte(`{"node": {"id": "sorting", "type": "concept"}}` + "\n"))
+	resp, err := http.Post(server.URL, "application/x-ndjson", body)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Expected 200 NDJSON response, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// Progress arrives while the request body is still open
+	reader := bufio.NewReader(resp.Body)
+	var event IngestEvent
+	line, err := reader.ReadBytes('\n')
+	if err != nil || json.Unmarshal(line, &event) != nil {
+		t.Fatalf("Failed to read progress event: %v", err)
+	}
+	if event.Type != IngestProgressEvent || event.Nodes != 1 || network.NodeCount() != 1 {
+		t.Errorf("Expected progress with 1 node before the stream ends, got %+v", event)
+	}
+
+	writer.Write([]byte(`{"node": {"id": "quicksort", "type": "instance"}}` + "\n"))
+	writer.Close()
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("Stream ended without a summary: %v", err)
+		}
+		if json.Unmarshal(line, &event); event.Type == IngestSummaryEvent {
+			break
+		}
+	}
+	if event.Nodes != 2 || event.Error != "" {
+		t.Errorf("Expected summary with 2 nodes, got %+v", event)
+	}
+}
@@ -4,13 +4,19 @@
 package memory
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"hash/fnv"
+	"log"
 	"math"
 	"math/rand"
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/pqueue"
 )
 
 // ============================================================================
@@ -96,41 +102,175 @@ func (b *BloomFilter) Clear() {
 // LSH Index - O(1) expected approximate nearest neighbor lookup
 // ============================================================================
 
+// LSH growth steps: each rehash adds hash functions, quartering the
+// expected bucket size, and adds tables to win back the recall that
+// narrower buckets lose.
+const (
+	lshGrowthFuncs  = 2
+	lshGrowthTables = 2
+)
+
+// LSHResizeConfig configures automatic rehashing of an LSH index as it
+// grows.
+type LSHResizeConfig struct {
+	// MaxAvgBucketSize is the average number of entries per non-empty
+	// bucket above which the index is rehashed; zero disables resizing
+	MaxAvgBucketSize float64
+	// MaxHashTables and MaxHashFuncs bound how far the index grows
+	MaxHashTables int
+	MaxHashFuncs  int
+	// BatchSize is how many entries a rehash copies each time it takes
+	// the index's lock
+	BatchSize int
+}
+
+// DefaultLSHResizeConfig returns the resizing used by the retriever.
+func DefaultLSHResizeConfig() LSHResizeConfig {
+	return LSHResizeConfig{
+		MaxAvgBucketSize: 64,
+		MaxHashTables:    20,
+		MaxHashFuncs:     24,
+		BatchSize:        256,
+	}
+}
+
+// LSHStats describes an LSH index's parameters and load.
+type LSHStats struct {
+	HashTables    int     `json:"hash_tables"`
+	HashFuncs     int     `json:"hash_funcs"`
+	Entries       int     `json:"entries"`
+	AvgBucketSize float64 `json:"avg_bucket_size"`
+	// Resizing is set while a rehash is running
+	Resizing bool `json:"resizing"`
+	// Resizes counts the rehashes completed
+	Resizes int `json:"resizes"`
+}
+
+// lshTables is one generation of an LSH index's hash tables, with the
+// hyperplanes their keys are computed from.
+type lshTables struct {
+	hashTables  []map[uint64][]string // hash -> experience IDs
+	hyperplanes [][][]float32         // [table][hash_func][dimension]
+	// buckets counts the non-empty buckets across the tables
+	buckets int
+	// migrated holds the IDs copied into a generation a rehash is
+	// filling; nil once the generation is in service
+	migrated map[string]bool
+}
+
+// hash computes a vector's key in one table.
+func (g *lshTables) hash(vector []float32, table int) uint64 {
+	var hash uint64
+	for h, hyperplane := range g.hyperplanes[table] {
+		// Compute dot product
+		var dot float32
+		for i, v := range vector {
+			dot += v * hyperplane[i]
+		}
+		// Set bit based on sign of dot product
+		if dot >= 0 {
+			hash |= (1 << uint(h))
+		}
+	}
+	return hash
+}
+
+// add puts an ID in its bucket in every table.
+func (g *lshTables) add(id string, vector []float32) {
+	for t := range g.hashTables {
+		hash := g.hash(vector, t)
+		if len(g.hashTables[t][hash]) == 0 {
+			g.buckets++
+		}
+		g.hashTables[t][hash] = append(g.hashTables[t][hash], id)
+	}
+}
+
+// remove takes an ID out of its bucket in every table.
+func (g *lshTables) remove(id string, vector []float32) {
+	for t := range g.hashTables {
+		hash := g.hash(vector, t)
+		bucket := g.hashTables[t][hash]
+		for i, existingID := range bucket {
+			if existingID == id {
+				bucket = append(bucket[:i], bucket[i+1:]...)
+				break
+			}
+		}
+		if len(bucket) == 0 {
+			if _, ok := g.hashTables[t][hash]; ok {
+				delete(g.hashTables[t], hash)
+				g.buckets--
+			}
+			continue
+		}
+		g.hashTables[t][hash] = bucket
+	}
+}
+
+// avgBucketSize returns the average entries per non-empty bucket.
+func (g *lshTables) avgBucketSize(entries int) float64 {
+	if g.buckets == 0 {
+		return 0
+	}
+	return float64(entries*len(g.hashTables)) / float64(g.buckets)
+}
+
 // LSHIndex implements Locality Sensitive Hashing for O(1) approximate nearest neighbor queries.
 // Uses random hyperplane hashing for cosine similarity.
+//
+// Buckets fill as the corpus grows, so queries return more candidates
+// and rank them worse. With resizing enabled, once the average bucket
+// grows past its limit the index is rehashed into more tables with more
+// hash functions. The rehash runs in the background, copying entries a
+// batch at a time; queries keep using the old tables until it finishes,
+// and adds and removes go to both.
 type LSHIndex struct {
-	numHashTables int
-	numHashFuncs  int
-	dimension     int
-	hashTables    []map[uint64][]string // hash -> experience IDs
-	hyperplanes   [][][]float32         // [table][hash_func][dimension]
-	mu            sync.RWMutex
-	rng           *rand.Rand
+	dimension int
+	tables    *lshTables
+	// next is the generation a rehash is filling; nil when none is running
+	next *lshTables
+	// vectors are the vectors indexed under each ID, kept so they can be
+	// rehashed
+	vectors map[string][][]float32
+	// entries counts the vectors across the IDs
+	entries int
+	resize  LSHResizeConfig
+	resizes int
+	// resizeDone is closed when the running or last rehash finishes
+	resizeDone chan struct{}
+	mu         sync.RWMutex
+	rng        *rand.Rand
 }
 
 // NewLSHIndex creates a new LSH index with the specified parameters.
 // numHashTables: more tables = higher recall, lower precision
 // numHashFuncs: more functions = higher precision, lower recall
+// Resizing is disabled until SetAutoResize enables it.
 func NewLSHIndex(numHashTables, numHashFuncs, dimension int) *LSHIndex {
 	lsh := &LSHIndex{
-		numHashTables: numHashTables,
-		numHashFuncs:  numHashFuncs,
-		dimension:     dimension,
-		hashTables:    make([]map[uint64][]string, numHashTables),
-		hyperplanes:   make([][][]float32, numHashTables),
-		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		dimension: dimension,
+		vectors:   make(map[string][][]float32),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	lsh.tables = lsh.newTables(numHashTables, numHashFuncs)
+	return lsh
+}
 
-	// Initialize hash tables and random hyperplanes
+// newTables creates empty tables with fresh random hyperplanes.
+func (l *LSHIndex) newTables(numHashTables, numHashFuncs int) *lshTables {
+	g := &lshTables{
+		hashTables:  make([]map[uint64][]string, numHashTables),
+		hyperplanes: make([][][]float32, numHashTables),
+	}
 	for t := 0; t < numHashTables; t++ {
-		lsh.hashTables[t] = make(map[uint64][]string)
-		lsh.hyperplanes[t] = make([][]float32, numHashFuncs)
+		g.hashTables[t] = make(map[uint64][]string)
+		g.hyperplanes[t] = make([][]float32, numHashFuncs)
 		for h := 0; h < numHashFuncs; h++ {
-			lsh.hyperplanes[t][h] = lsh.randomHyperplane()
+			g.hyperplanes[t][h] = l.randomHyperplane()
 		}
 	}
-
-	return lsh
+	return g
 }
 
 // randomHyperplane generates a random unit vector for hyperplane hashing.
@@ -148,25 +288,33 @@ func (l *LSHIndex) randomHyperplane() []float32 {
 	return plane
 }
 
-// computeHash computes the LSH hash for a vector in a specific table.
-func (l *LSHIndex) computeHash(vector []float32, tableIdx int) uint64 {
-	if len(vector) != l.dimension {
-		return 0
+// SetAutoResize enables rehashing as the index grows, or disables it for
+// a zero MaxAvgBucketSize. A rehash already running finishes.
+func (l *LSHIndex) SetAutoResize(config LSHResizeConfig) {
+	if config.BatchSize < 1 {
+		config.BatchSize = DefaultLSHResizeConfig().BatchSize
 	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resize = config
+}
 
-	var hash uint64
-	for h, hyperplane := range l.hyperplanes[tableIdx] {
-		// Compute dot product
-		var dot float32
-		for i, v := range vector {
-			dot += v * hyperplane[i]
-		}
-		// Set bit based on sign of dot product
-		if dot >= 0 {
-			hash |= (1 << uint(h))
-		}
-	}
-	return hash
+// Params returns the index's number of hash tables and hash functions per
+// table.
+func (l *LSHIndex) Params() (numHashTables, numHashFuncs int) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.tables.hashTables), len(l.tables.hyperplanes[0])
+}
+
+// emptyCopy creates an empty index with the same parameters and resizing.
+func (l *LSHIndex) emptyCopy() *LSHIndex {
+	numHashTables, numHashFuncs := l.Params()
+	copied := NewLSHIndex(numHashTables, numHashFuncs, l.dimension)
+	l.mu.RLock()
+	copied.resize = l.resize
+	l.mu.RUnlock()
+	return copied
 }
 
 // Add inserts a vector with its ID into the LSH index.
@@ -178,10 +326,17 @@ func (l *LSHIndex) Add(id string, vector []float32) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	for t := 0; t < l.numHashTables; t++ {
-		hash := l.computeHash(vector, t)
-		l.hashTables[t][hash] = append(l.hashTables[t][hash], id)
+	l.vectors[id] = append(l.vectors[id], vector)
+	l.entries++
+	l.tables.add(id, vector)
+	if l.next != nil {
+		if l.next.migrated[id] {
+			l.next.add(id, vector)
+		} else {
+			l.migrate(id)
+		}
 	}
+	l.maybeResize()
 }
 
 // Remove removes a vector from the LSH index by its ID.
@@ -193,15 +348,123 @@ func (l *LSHIndex) Remove(id string, vector []float32) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	for t := 0; t < l.numHashTables; t++ {
-		hash := l.computeHash(vector, t)
-		bucket := l.hashTables[t][hash]
-		for i, existingID := range bucket {
-			if existingID == id {
-				l.hashTables[t][hash] = append(bucket[:i], bucket[i+1:]...)
-				break
+	vectors := l.vectors[id]
+	if len(vectors) == 0 {
+		return
+	}
+	// The ID's vector equal to the one given is removed, or its first
+	// vector if none is
+	i := 0
+	for j, added := range vectors {
+		if equalVectors(added, vector) {
+			i = j
+			break
+		}
+	}
+	removed := vectors[i]
+	l.entries--
+	if len(vectors) == 1 {
+		delete(l.vectors, id)
+	} else {
+		l.vectors[id] = append(vectors[:i:i], vectors[i+1:]...)
+	}
+	l.tables.remove(id, removed)
+	if l.next != nil && l.next.migrated[id] {
+		l.next.remove(id, removed)
+	}
+}
+
+// equalVectors reports whether two vectors are the same.
+func equalVectors(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// migrate copies an ID's vectors into the generation a rehash is filling.
+// The caller must hold l.mu.
+func (l *LSHIndex) migrate(id string) {
+	for _, vector := range l.vectors[id] {
+		l.next.add(id, vector)
+	}
+	l.next.migrated[id] = true
+}
+
+// maybeResize starts a rehash if buckets have grown past the limit and
+// the index may still grow. The caller must hold l.mu.
+func (l *LSHIndex) maybeResize() {
+	if l.resize.MaxAvgBucketSize <= 0 || l.next != nil {
+		return
+	}
+	if l.tables.avgBucketSize(l.entries) <= l.resize.MaxAvgBucketSize {
+		return
+	}
+	numHashTables := len(l.tables.hashTables)
+	numHashFuncs := len(l.tables.hyperplanes[0])
+	grownTables := min(numHashTables+lshGrowthTables, max(l.resize.MaxHashTables, numHashTables))
+	// Keys are 64-bit, one bit per hash function
+	grownFuncs := min(min(numHashFuncs+lshGrowthFuncs, max(l.resize.MaxHashFuncs, numHashFuncs)), 64)
+	if grownTables == numHashTables && grownFuncs == numHashFuncs {
+		return
+	}
+
+	next := l.newTables(grownTables, grownFuncs)
+	next.migrated = make(map[string]bool, len(l.vectors))
+	l.next = next
+	done := make(chan struct{})
+	l.resizeDone = done
+	go func() {
+		defer close(done)
+		l.rehash(next)
+	}()
+}
+
+// rehash copies every entry into next, a batch at a time, then puts next
+// in service. Entries added meanwhile are already in next, and entries
+// removed meanwhile are skipped.
+func (l *LSHIndex) rehash(next *lshTables) {
+	start := time.Now()
+	l.mu.RLock()
+	ids := make([]string, 0, len(l.vectors))
+	for id := range l.vectors {
+		ids = append(ids, id)
+	}
+	batchSize := l.resize.BatchSize
+	l.mu.RUnlock()
+
+	for i := 0; i < len(ids); i += batchSize {
+		l.mu.Lock()
+		for _, id := range ids[i:min(i+batchSize, len(ids))] {
+			if !next.migrated[id] {
+				l.migrate(id)
 			}
 		}
+		l.mu.Unlock()
+	}
+
+	l.mu.Lock()
+	next.migrated = nil
+	l.tables, l.next = next, nil
+	l.resizes++
+	entries := l.entries
+	l.mu.Unlock()
+	log.Printf("LSH index rehashed %d entries into %d tables of %d hash functions in %s",
+		entries, len(next.hashTables), len(next.hyperplanes[0]), time.Since(start))
+}
+
+// WaitResize waits for a running rehash to finish.
+func (l *LSHIndex) WaitResize() {
+	l.mu.RLock()
+	done := l.resizeDone
+	l.mu.RUnlock()
+	if done != nil {
+		<-done
 	}
 }
 
@@ -218,9 +481,9 @@ func (l *LSHIndex) Query(vector []float32, maxCandidates int) []string {
 	// Use a map to deduplicate candidates across tables
 	candidateSet := make(map[string]int) // ID -> count of appearances
 
-	for t := 0; t < l.numHashTables; t++ {
-		hash := l.computeHash(vector, t)
-		for _, id := range l.hashTables[t][hash] {
+	for t := range l.tables.hashTables {
+		hash := l.tables.hash(vector, t)
+		for _, id := range l.tables.hashTables[t][hash] {
 			candidateSet[id]++
 		}
 	}
@@ -246,24 +509,25 @@ func (l *LSHIndex) Query(vector []float32, maxCandidates int) []string {
 	return result
 }
 
-// Size returns the total number of entries across all hash tables.
+// Size returns the number of distinct IDs in the index.
 func (l *LSHIndex) Size() int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
+	return len(l.vectors)
+}
 
-	total := 0
-	seen := make(map[string]bool)
-	for t := 0; t < l.numHashTables; t++ {
-		for _, bucket := range l.hashTables[t] {
-			for _, id := range bucket {
-				if !seen[id] {
-					seen[id] = true
-					total++
-				}
-			}
-		}
+// Stats returns the index's parameters and load.
+func (l *LSHIndex) Stats() LSHStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return LSHStats{
+		HashTables:    len(l.tables.hashTables),
+		HashFuncs:     len(l.tables.hyperplanes[0]),
+		Entries:       l.entries,
+		AvgBucketSize: l.tables.avgBucketSize(l.entries),
+		Resizing:      l.next != nil,
+		Resizes:       l.resizes,
 	}
-	return total
 }
 
 // ============================================================================
@@ -353,89 +617,17 @@ func (h *HNSWGraph) cosineSimilarity(a, b []float32) float32 {
 	return dotProduct / (float32(math.Sqrt(float64(normA))) * float32(math.Sqrt(float64(normB))))
 }
 
-// priorityQueue implements a min-heap for nearest neighbor search.
-type priorityQueue struct {
-	items []pqItem
-}
-
+// pqItem is a node found by a layer search and its distance to the query.
 type pqItem struct {
 	id       string
 	distance float32
 }
 
-func (pq *priorityQueue) Len() int { return len(pq.items) }
-
-func (pq *priorityQueue) Push(id string, dist float32) {
-	pq.items = append(pq.items, pqItem{id, dist})
-	// Bubble up
-	i := len(pq.items) - 1
-	for i > 0 {
-		parent := (i - 1) / 2
-		if pq.items[parent].distance <= pq.items[i].distance {
-			break
-		}
-		pq.items[parent], pq.items[i] = pq.items[i], pq.items[parent]
-		i = parent
-	}
-}
-
-func (pq *priorityQueue) Pop() (string, float32) {
-	if len(pq.items) == 0 {
-		return "", 0
-	}
-	item := pq.items[0]
-	last := len(pq.items) - 1
-	pq.items[0] = pq.items[last]
-	pq.items = pq.items[:last]
-	// Bubble down
-	i := 0
-	for {
-		left := 2*i + 1
-		right := 2*i + 2
-		smallest := i
-		if left < len(pq.items) && pq.items[left].distance < pq.items[smallest].distance {
-			smallest = left
-		}
-		if right < len(pq.items) && pq.items[right].distance < pq.items[smallest].distance {
-			smallest = right
-		}
-		if smallest == i {
-			break
-		}
-		pq.items[i], pq.items[smallest] = pq.items[smallest], pq.items[i]
-		i = smallest
-	}
-	return item.id, item.distance
-}
-
-func (pq *priorityQueue) Peek() (string, float32) {
-	if len(pq.items) == 0 {
-		return "", 0
-	}
-	return pq.items[0].id, pq.items[0].distance
-}
-
-// maxHeap wraps priorityQueue to act as a max-heap (for keeping k nearest).
-type maxHeap struct {
-	pq priorityQueue
-}
-
-func (mh *maxHeap) Push(id string, dist float32) {
-	// Store negative distance to simulate max-heap with min-heap
-	mh.pq.Push(id, -dist)
-}
-
-func (mh *maxHeap) Pop() (string, float32) {
-	id, negDist := mh.pq.Pop()
-	return id, -negDist
-}
+// nearer orders search items nearest first.
+func nearer(a, b pqItem) bool { return a.distance < b.distance }
 
-func (mh *maxHeap) Peek() (string, float32) {
-	id, negDist := mh.pq.Peek()
-	return id, -negDist
-}
-
-func (mh *maxHeap) Len() int { return mh.pq.Len() }
+// farther orders search items furthest first.
+func farther(a, b pqItem) bool { return a.distance > b.distance }
 
 // Add inserts a new node into the HNSW graph.
 func (h *HNSWGraph) Add(id string, vector []float32) {
@@ -521,8 +713,10 @@ func (h *HNSWGraph) Add(id string, vector []float32) {
 // searchLayer performs greedy search within a single layer.
 func (h *HNSWGraph) searchLayer(query []float32, entryID string, ef int, level int) []string {
 	visited := make(map[string]bool)
-	candidates := &priorityQueue{}
-	result := &maxHeap{}
+	// candidates are the nodes to expand, nearest first; result keeps the
+	// ef nearest found, furthest first so it is the one dropped
+	candidates := pqueue.New(nearer)
+	result := pqueue.New(farther)
 
 	entryNode := h.nodes[entryID]
 	if entryNode == nil {
@@ -530,19 +724,19 @@ func (h *HNSWGraph) searchLayer(query []float32, entryID string, ef int, level i
 	}
 
 	dist := h.distance(query, entryNode.Vector)
-	candidates.Push(entryID, dist)
-	result.Push(entryID, dist)
+	candidates.Push(pqItem{entryID, dist})
+	result.Push(pqItem{entryID, dist})
 	visited[entryID] = true
 
 	for candidates.Len() > 0 {
-		currentID, currentDist := candidates.Pop()
-		_, furthestDist := result.Peek()
+		current, _ := candidates.Pop()
+		furthest, _ := result.Peek()
 
-		if currentDist > furthestDist {
+		if current.distance > furthest.distance {
 			break
 		}
 
-		currentNode := h.nodes[currentID]
+		currentNode := h.nodes[current.id]
 		if currentNode == nil || level >= len(currentNode.Neighbors) {
 			continue
 		}
@@ -559,11 +753,11 @@ func (h *HNSWGraph) searchLayer(query []float32, entryID string, ef int, level i
 			}
 
 			dist := h.distance(query, neighborNode.Vector)
-			_, furthestDist := result.Peek()
+			furthest, _ := result.Peek()
 
-			if dist < furthestDist || result.Len() < ef {
-				candidates.Push(neighborID, dist)
-				result.Push(neighborID, dist)
+			if dist < furthest.distance || result.Len() < ef {
+				candidates.Push(pqItem{neighborID, dist})
+				result.Push(pqItem{neighborID, dist})
 				if result.Len() > ef {
 					result.Pop()
 				}
@@ -571,15 +765,12 @@ func (h *HNSWGraph) searchLayer(query []float32, entryID string, ef int, level i
 		}
 	}
 
-	// Extract results
-	results := make([]string, 0, result.Len())
-	for result.Len() > 0 {
-		id, _ := result.Pop()
-		results = append(results, id)
-	}
-	// Reverse to get nearest first
-	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
-		results[i], results[j] = results[j], results[i]
+	// Extract results, furthest first, filling from the back so the nearest
+	// comes first
+	results := make([]string, result.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		item, _ := result.Pop()
+		results[i] = item.id
 	}
 	return results
 }
@@ -735,6 +926,9 @@ type SubLinearRetriever struct {
 	lsh   *LSHIndex
 	hnsw  *HNSWGraph
 	bloom *BloomFilter
+	// indexMu is held for reading while indices are read or updated, and
+	// for writing while RebuildIndexes swaps new ones in
+	indexMu sync.RWMutex
 
 	// Experience storage
 	experiences map[string]*ExperienceTuple
@@ -753,12 +947,25 @@ type SubLinearRetriever struct {
 
 	// Statistics
 	stats *MemoryStats
+
+	// wal records added and removed experiences; nil disables logging
+	wal *WriteAheadLog
+
+	// dedup finds near-duplicates for AddOrMerge; nil disables merging
+	dedup   *DedupIndex
+	dedupMu sync.Mutex
+
+	// embedder embeds experiences added without an embedding; nil leaves
+	// them without one (see embedding_service.go)
+	embedder TextEmbedder
 }
 
 // NewSubLinearRetriever creates a new sub-linear retriever with the specified embedding dimension.
 func NewSubLinearRetriever(dimension int) *SubLinearRetriever {
+	lsh := NewLSHIndex(10, 12, dimension) // 10 tables, 12 hash functions
+	lsh.SetAutoResize(DefaultLSHResizeConfig())
 	return &SubLinearRetriever{
-		lsh:          NewLSHIndex(10, 12, dimension),       // 10 tables, 12 hash functions
+		lsh:          lsh,
 		hnsw:         NewHNSWGraph(dimension, 16, 200),     // M=16, efConstruction=200
 		bloom:        NewBloomFilterOptimal(1000000, 0.01), // 1M items, 1% FP rate
 		experiences:  make(map[string]*ExperienceTuple),
@@ -770,11 +977,23 @@ func NewSubLinearRetriever(dimension int) *SubLinearRetriever {
 	}
 }
 
-// Add inserts an experience into all indices.
+// Add inserts an experience into all indices, embedding its input first
+// if the retriever has an embedder and the experience has no embedding.
 func (r *SubLinearRetriever) Add(exp *ExperienceTuple) error {
 	if exp == nil || exp.ID == "" {
 		return ErrInvalidExperience
 	}
+	r.embedExperience(exp)
+	return r.add(exp)
+}
+
+// add inserts an experience as it is.
+func (r *SubLinearRetriever) add(exp *ExperienceTuple) error {
+	r.indexMu.RLock()
+	defer r.indexMu.RUnlock()
+	if err := r.logExperience(exp); err != nil {
+		return err
+	}
 
 	// Store experience
 	r.expMu.Lock()
@@ -805,6 +1024,10 @@ func (r *SubLinearRetriever) Add(exp *ExperienceTuple) error {
 		r.hnsw.Add(exp.ID, exp.Embedding)
 	}
 
+	if r.dedup != nil {
+		r.dedup.Add(exp)
+	}
+
 	// Update statistics
 	r.stats.IncrementExperiences(exp.AgentID, exp.TierID)
 
@@ -813,12 +1036,21 @@ func (r *SubLinearRetriever) Add(exp *ExperienceTuple) error {
 
 // Remove removes an experience from all indices.
 func (r *SubLinearRetriever) Remove(id string) error {
+	r.indexMu.RLock()
+	defer r.indexMu.RUnlock()
+
 	r.expMu.Lock()
 	exp, exists := r.experiences[id]
 	if !exists {
 		r.expMu.Unlock()
 		return ErrExperienceNotFound
 	}
+	if r.wal != nil {
+		if _, err := r.wal.Append(&WALRecord{Op: WALExperienceRemove, ID: id}); err != nil {
+			r.expMu.Unlock()
+			return fmt.Errorf("logging %s: %w", WALExperienceRemove, err)
+		}
+	}
 	delete(r.experiences, id)
 	r.expMu.Unlock()
 
@@ -857,18 +1089,92 @@ func (r *SubLinearRetriever) Remove(id string) error {
 		r.hnsw.Remove(id)
 	}
 
+	if r.dedup != nil {
+		r.dedup.Remove(id)
+	}
+
+	return nil
+}
+
+// AttachWAL logs every subsequently added or removed experience to wal.
+// Attach before the retriever is shared between goroutines.
+func (r *SubLinearRetriever) AttachWAL(wal *WriteAheadLog) {
+	r.wal = wal
+}
+
+// logExperience logs an added experience if a log is attached.
+func (r *SubLinearRetriever) logExperience(exp *ExperienceTuple) error {
+	if r.wal == nil {
+		return nil
+	}
+	metadata, err := encodeWALValues(exp.Metadata)
+	if err != nil {
+		return fmt.Errorf("%w: experience %s: %v", ErrPersistenceFailed, exp.ID, err)
+	}
+	expCopy := *exp
+	expCopy.Metadata = nil
+	if _, err := r.wal.Append(&WALRecord{Op: WALExperienceAdd, Experience: &expCopy, Properties: metadata}); err != nil {
+		return fmt.Errorf("logging %s: %w", WALExperienceAdd, err)
+	}
 	return nil
 }
 
+// ReplayWAL re-adds and removes the experiences logged after afterLSN.
+// Call before AttachWAL so replayed records are not logged again.
+func (r *SubLinearRetriever) ReplayWAL(wal *WriteAheadLog, afterLSN uint64) (int, error) {
+	return wal.Replay(afterLSN, func(rec *WALRecord) error {
+		switch rec.Op {
+		case WALExperienceAdd:
+			if rec.Experience == nil {
+				return fmt.Errorf("%w: %s without experience", ErrWALCorrupt, rec.Op)
+			}
+			metadata, err := decodeWALValues(rec.Properties)
+			if err != nil {
+				return err
+			}
+			exp := rec.Experience
+			exp.Metadata = metadata
+			r.expMu.RLock()
+			_, exists := r.experiences[exp.ID]
+			r.expMu.RUnlock()
+			if exists {
+				if err := r.Remove(exp.ID); err != nil {
+					return err
+				}
+			}
+			return r.add(exp)
+		case WALExperienceRemove:
+			if err := r.Remove(rec.ID); err != nil && !errors.Is(err, ErrExperienceNotFound) {
+				return err
+			}
+			return nil
+		default:
+			return fmt.Errorf("%w: unexpected op %s in experience log", ErrWALCorrupt, rec.Op)
+		}
+	})
+}
+
 // Retrieve performs sub-linear experience retrieval using a tiered approach.
 // 1. Bloom filter check for exact task signature (O(1))
 // 2. LSH for approximate matching (O(1) expected)
 // 3. HNSW for semantic search (O(log n))
 func (r *SubLinearRetriever) Retrieve(query *QueryContext) (*RetrievalResult, error) {
+	return r.RetrieveContext(context.Background(), query)
+}
+
+// RetrieveContext is Retrieve giving up with ctx's error once ctx is done,
+// checked before each tier.
+func (r *SubLinearRetriever) RetrieveContext(ctx context.Context, query *QueryContext) (*RetrievalResult, error) {
 	if query == nil {
 		return nil, ErrInvalidQuery
 	}
 
+	r.indexMu.RLock()
+	defer r.indexMu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
 	result := &RetrievalResult{
 		Experiences: make([]*ExperienceTuple, 0, query.TopK),
@@ -901,6 +1207,9 @@ func (r *SubLinearRetriever) Retrieve(query *QueryContext) (*RetrievalResult, er
 	}
 
 	// Step 2: LSH for approximate matching (O(1) expected)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if len(query.Embedding) == r.dimension {
 		candidates := r.lsh.Query(query.Embedding, query.TopK*3) // Get more candidates for filtering
 		result.TotalCandidates = len(candidates)
@@ -918,6 +1227,9 @@ func (r *SubLinearRetriever) Retrieve(query *QueryContext) (*RetrievalResult, er
 	}
 
 	// Step 3: HNSW for semantic search (O(log n))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if len(query.Embedding) == r.dimension {
 		ids := r.hnsw.SearchIDs(query.Embedding, query.TopK*3)
 		result.TotalCandidates += len(ids)
@@ -959,21 +1271,7 @@ func (r *SubLinearRetriever) lookupAndFilter(ids []string, query *QueryContext)
 			continue
 		}
 
-		// Apply filters
-		if exp.FitnessScore < query.MinFitnessScore {
-			continue
-		}
-
-		if query.MaxAge > 0 && (now-exp.Timestamp) > query.MaxAge {
-			continue
-		}
-
-		// Include experiences from the same agent, same tier (if enabled), or collective
-		sameAgent := exp.AgentID == query.AgentID
-		sameTier := query.IncludeTierExperiences && exp.TierID == query.TierID
-		isCollective := query.IncludeCollectiveExperiences && exp.AgentID == "COLLECTIVE"
-
-		if sameAgent || sameTier || isCollective {
+		if acceptsExperience(query, exp, now) {
 			results = append(results, exp)
 			// Update access statistics
 			exp.UsageCount++
@@ -984,6 +1282,24 @@ func (r *SubLinearRetriever) lookupAndFilter(ids []string, query *QueryContext)
 	return results
 }
 
+// acceptsExperience applies the query filters to an experience.
+func acceptsExperience(query *QueryContext, exp *ExperienceTuple, now int64) bool {
+	if exp.FitnessScore < query.MinFitnessScore {
+		return false
+	}
+
+	if query.MaxAge > 0 && (now-exp.Timestamp) > query.MaxAge {
+		return false
+	}
+
+	// Include experiences from the same agent, same tier (if enabled), or collective
+	sameAgent := exp.AgentID == query.AgentID
+	sameTier := query.IncludeTierExperiences && exp.TierID == query.TierID
+	isCollective := query.IncludeCollectiveExperiences && exp.AgentID == "COLLECTIVE"
+
+	return sameAgent || sameTier || isCollective
+}
+
 // GetByAgent returns all experiences for a specific agent.
 func (r *SubLinearRetriever) GetByAgent(agentID string) []*ExperienceTuple {
 	r.agentMu.RLock()
@@ -1036,20 +1352,6 @@ func (r *SubLinearRetriever) Size() int {
 // Utility functions
 // ============================================================================
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 // hashString computes a hash for a string.
 func hashString(s string) uint64 {
 	h := fnv.New64a()
@@ -1,9 +1,13 @@
 package memory
 
 import (
+	"fmt"
 	"math/rand"
+	"sort"
 	"testing"
 	"time"
+
+	"pgregory.net/rapid"
 )
 
 // ============================================================================
@@ -154,6 +158,73 @@ func TestLSHIndex_Remove(t *testing.T) {
 	// The important thing is that queries shouldn't return the removed item
 }
 
+func TestLSHIndex_AutoResize(t *testing.T) {
+	dimension := 16
+	lsh := NewLSHIndex(2, 2, dimension)
+	lsh.SetAutoResize(LSHResizeConfig{MaxAvgBucketSize: 8, MaxHashTables: 4, MaxHashFuncs: 6, BatchSize: 16})
+	rng := rand.New(rand.NewSource(42))
+
+	vectors := make(map[string][]float32)
+	for i := 0; i < 400; i++ {
+		id := fmt.Sprintf("exp_%d", i)
+		vectors[id] = randomVector(rng, dimension)
+		lsh.Add(id, vectors[id])
+		// Half the entries are removed, some while a rehash is copying
+		if i%2 == 1 {
+			prev := fmt.Sprintf("exp_%d", i-1)
+			lsh.Remove(prev, vectors[prev])
+			delete(vectors, prev)
+		}
+	}
+	lsh.WaitResize()
+
+	stats := lsh.Stats()
+	if stats.Resizes == 0 || stats.Resizing {
+		t.Fatalf("Expected a completed rehash, got %+v", stats)
+	}
+	if stats.HashTables <= 2 || stats.HashTables > 4 || stats.HashFuncs <= 2 || stats.HashFuncs > 6 {
+		t.Errorf("Expected the index grown within its bounds, got %d tables of %d functions", stats.HashTables, stats.HashFuncs)
+	}
+	if stats.Entries != 200 || lsh.Size() != 200 {
+		t.Errorf("Expected 200 entries after rehashing, got %d (size %d)", stats.Entries, lsh.Size())
+	}
+	for id, vector := range vectors {
+		found := false
+		for _, candidate := range lsh.Query(vector, 400) {
+			if candidate == id {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Expected %s found by its own vector after rehashing", id)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		for _, candidate := range lsh.Query(randomVector(rng, dimension), 400) {
+			if _, ok := vectors[candidate]; !ok {
+				t.Fatalf("Expected removed %s gone after rehashing", candidate)
+			}
+		}
+	}
+}
+
+func TestLSHIndex_ResizeDisabledByDefault(t *testing.T) {
+	dimension := 16
+	lsh := NewLSHIndex(2, 2, dimension)
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		lsh.Add(fmt.Sprintf("exp_%d", i), randomVector(rng, dimension))
+	}
+	lsh.WaitResize()
+
+	if tables, funcs := lsh.Params(); tables != 2 || funcs != 2 {
+		t.Errorf("Expected fixed parameters without resizing, got %d tables of %d functions", tables, funcs)
+	}
+	if stats := lsh.Stats(); stats.AvgBucketSize <= 8 || stats.Resizes != 0 {
+		t.Errorf("Expected overfull buckets left alone, got %+v", stats)
+	}
+}
+
 // ============================================================================
 // HNSW Graph Tests
 // ============================================================================
@@ -464,6 +535,113 @@ func TestSubLinearRetriever_Remove(t *testing.T) {
 	}
 }
 
+func TestSubLinearRetriever_WALReplay(t *testing.T) {
+	dimension := 32
+	wal, _ := openTestWAL(t)
+	defer wal.Close()
+
+	retriever := NewSubLinearRetriever(dimension)
+	retriever.AttachWAL(wal)
+	for _, id := range []string{"exp_1", "exp_2"} {
+		retriever.Add(&ExperienceTuple{
+			ID:            id,
+			AgentID:       "APEX",
+			TierID:        1,
+			TaskSignature: "sig_" + id,
+			Embedding:     make([]float32, dimension),
+			Metadata:      map[string]interface{}{"attempts": 2},
+		})
+	}
+	retriever.Remove("exp_1")
+
+	recovered := NewSubLinearRetriever(dimension)
+	applied, err := recovered.ReplayWAL(wal, 0)
+	if err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if applied != 3 {
+		t.Errorf("Expected 3 records, got %d", applied)
+	}
+	if recovered.Size() != 1 {
+		t.Errorf("Expected 1 experience after replay, got %d", recovered.Size())
+	}
+	exps := recovered.GetByAgent("APEX")
+	if len(exps) != 1 || exps[0].Metadata["attempts"] != 2 {
+		t.Errorf("Expected exp_2 with typed metadata, got %v", exps)
+	}
+}
+
+// ============================================================================
+// Property Tests
+// ============================================================================
+
+func TestProperty_BloomFilterNoFalseNegatives(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		var bf *BloomFilter
+		if rapid.Bool().Draw(t, "optimal") {
+			bf = NewBloomFilterOptimal(
+				rapid.IntRange(1, 1000).Draw(t, "expected"),
+				rapid.Float64Range(0.0001, 0.5).Draw(t, "falsePositiveRate"))
+		} else {
+			bf = NewBloomFilter(rapid.IntRange(1, 4096).Draw(t, "size"), rapid.IntRange(1, 16).Draw(t, "numHash"))
+		}
+		keys := rapid.SliceOf(rapid.String()).Draw(t, "keys")
+
+		for _, key := range keys {
+			bf.Add(key)
+		}
+		for _, key := range keys {
+			if !bf.MayContain(key) {
+				t.Fatalf("Expected added key %q to be reported present", key)
+			}
+		}
+	})
+}
+
+func TestProperty_HNSWRecall(t *testing.T) {
+	const k = 10
+	const minRecall = 0.9
+
+	rapid.Check(t, func(t *rapid.T) {
+		dimension := rapid.IntRange(2, 32).Draw(t, "dimension")
+		count := rapid.IntRange(k, 200).Draw(t, "count")
+		rng := rand.New(rand.NewSource(rapid.Int64().Draw(t, "seed")))
+
+		graph := NewHNSWGraph(dimension, 16, 100)
+		graph.rng = rand.New(rand.NewSource(rng.Int63()))
+		graph.SetEfSearch(64)
+		vectors := make(map[string][]float32, count)
+		for i := 0; i < count; i++ {
+			id := fmt.Sprintf("v%d", i)
+			vectors[id] = randomVector(rng, dimension)
+			graph.Add(id, vectors[id])
+		}
+
+		// Average recall@k over several queries, counting ties with the
+		// k-th true neighbor as hits
+		const queries = 10
+		hits := 0
+		for q := 0; q < queries; q++ {
+			query := randomVector(rng, dimension)
+			distances := make([]float32, 0, count)
+			for _, vector := range vectors {
+				distances = append(distances, graph.distance(query, vector))
+			}
+			sort.Slice(distances, func(i, j int) bool { return distances[i] < distances[j] })
+			kth := distances[k-1]
+
+			for _, id := range graph.SearchIDs(query, k) {
+				if graph.distance(query, vectors[id]) <= kth {
+					hits++
+				}
+			}
+		}
+		if recall := float64(hits) / float64(queries*k); recall < minRecall {
+			t.Fatalf("Expected recall@%d of at least %.2f against brute force, got %.2f", k, minRecall, recall)
+		}
+	})
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
@@ -0,0 +1,509 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the export of training data for offline learning,
+// and the import of the routing models trained on it.
+//
+// Three tables are exported, each with a fixed, documented schema (see
+// TrainingSchema): the experiences in the retriever, the routing decisions
+// the hybrid router made, one row per ranked agent, and the outcomes
+// reported as feedback. Decisions and outcomes are kept in a bounded
+// TrainingLog as they happen. Tables are written as CSV or Parquet.
+//
+// A model trained offline comes back as a RoutingModel artifact: a routing
+// blend and attention weights by category and agent. Importing it replaces
+// the live weights, so routing follows the trained model from the next
+// query on; feedback keeps adjusting it from there.
+
+package memory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrUnknownExportTable is returned for a table that is not exported.
+	ErrUnknownExportTable = errdefs.New(errdefs.ErrInvalidArgument, "unknown export table")
+	// ErrUnknownExportFormat is returned for a format tables are not
+	// written in.
+	ErrUnknownExportFormat = errdefs.New(errdefs.ErrInvalidArgument, "unknown export format")
+	// ErrInvalidRoutingModel is returned when a routing model artifact
+	// cannot be imported.
+	ErrInvalidRoutingModel = errdefs.New(errdefs.ErrInvalidArgument, "invalid routing model")
+)
+
+// Exported tables.
+const (
+	TableExperiences = "experiences"
+	TableDecisions   = "decisions"
+	TableOutcomes    = "outcomes"
+)
+
+// Export formats.
+const (
+	ExportCSV     = "csv"
+	ExportParquet = "parquet"
+)
+
+// DefaultTrainingLogCapacity is the number of decisions and of outcomes a
+// training log keeps by default.
+const DefaultTrainingLogCapacity = 100000
+
+// maxRoutingModelBody bounds the size of a routing model artifact.
+const maxRoutingModelBody = 4 << 20
+
+// ExportType is the type of an exported column.
+type ExportType string
+
+// Column types. Timestamps are UTC; in CSV they are RFC 3339, in Parquet
+// milliseconds since the epoch.
+const (
+	ExportString    ExportType = "string"
+	ExportInt64     ExportType = "int64"
+	ExportDouble    ExportType = "double"
+	ExportBoolean   ExportType = "boolean"
+	ExportTimestamp ExportType = "timestamp"
+)
+
+// ExportColumn describes a column of an exported table.
+type ExportColumn struct {
+	Name        string     `json:"name"`
+	Type        ExportType `json:"type"`
+	Description string     `json:"description"`
+}
+
+// TrainingSchema is the schema of each exported table, its columns in the
+// order they are written.
+var TrainingSchema = map[string][]ExportColumn{
+	TableExperiences: {
+		{"id", ExportString, "Experience ID"},
+		{"timestamp", ExportTimestamp, "When the experience was recorded"},
+		{"agent_id", ExportString, "Codename of the agent that handled the task"},
+		{"tier_id", ExportInt64, "The agent's tier, 1-8"},
+		{"task_type", ExportString, "Task category, e.g. code_generation"},
+		{"task_signature", ExportString, "Hash of the task, equal for identical inputs"},
+		{"strategy", ExportString, "Approach the agent took"},
+		{"input", ExportString, "The request"},
+		{"output", ExportString, "The agent's response"},
+		{"success", ExportBoolean, "Whether the task was completed successfully"},
+		{"fitness_score", ExportDouble, "How useful the experience has been in retrieval"},
+		{"usage_count", ExportInt64, "Times the experience was retrieved"},
+		{"weight", ExportInt64, "Near-duplicate experiences merged into this one"},
+		{"evolution_generation", ExportInt64, "Evolution generation the experience belongs to"},
+		{"embedding", ExportString, "Embedding as a JSON array of floats; empty without one"},
+	},
+	TableDecisions: {
+		{"decision_id", ExportString, "Routing decision ID, shared by the decision's rows"},
+		{"timestamp", ExportTimestamp, "When the query was routed"},
+		{"query", ExportString, "The routed query"},
+		{"rank", ExportInt64, "The agent's rank, 1 for the agent routed to"},
+		{"agent", ExportString, "Codename of the ranked agent"},
+		{"score", ExportDouble, "Blended score the agents were ranked by"},
+		{"keyword", ExportDouble, "Keyword attention signal, rescaled to [0, 1] across agents"},
+		{"similarity", ExportDouble, "Persona similarity signal, rescaled to [0, 1] across agents; 0 before personas are embedded"},
+		{"keyword_weight", ExportDouble, "Weight of the keyword signal in the blend"},
+		{"similarity_weight", ExportDouble, "Weight of the similarity signal in the blend"},
+	},
+	TableOutcomes: {
+		{"timestamp", ExportTimestamp, "When the outcome was applied"},
+		{"query", ExportString, "The query the agent handled; joins decisions on query and agent"},
+		{"agent", ExportString, "Codename of the agent that handled it"},
+		{"collaborators", ExportString, "Other agents that took part, separated by semicolons"},
+		{"success", ExportBoolean, "Whether the agent succeeded"},
+		{"task_type", ExportString, "Task category"},
+		{"strategy", ExportString, "Approach the agent took"},
+		{"verbosity", ExportString, "Answer length the user asked for: concise, detailed or empty"},
+	},
+}
+
+// ============================================================================
+// Training Log
+// ============================================================================
+
+// RoutingDecision is a ranking the hybrid router made for a query.
+type RoutingDecision struct {
+	ID      string         `json:"id"`
+	Time    time.Time      `json:"time"`
+	Query   string         `json:"query"`
+	Routes  []HybridRoute  `json:"routes"`
+	Weights RoutingWeights `json:"weights"`
+}
+
+// RoutingOutcome is an outcome applied from feedback.
+type RoutingOutcome struct {
+	Time time.Time `json:"time"`
+	FeedbackRecord
+}
+
+// TrainingLog keeps the latest routing decisions and outcomes for export,
+// dropping the oldest beyond its capacity. It is safe for concurrent use.
+type TrainingLog struct {
+	capacity int
+	clock    Clock
+
+	mu        sync.Mutex
+	decisions []RoutingDecision
+	outcomes  []RoutingOutcome
+}
+
+// NewTrainingLog creates a log keeping up to capacity decisions and as
+// many outcomes, or DefaultTrainingLogCapacity of each if capacity is not
+// positive. A nil clock uses the system clock.
+func NewTrainingLog(capacity int, clock Clock) *TrainingLog {
+	if capacity <= 0 {
+		capacity = DefaultTrainingLogCapacity
+	}
+	return &TrainingLog{capacity: capacity, clock: clockOrSystem(clock)}
+}
+
+// RecordDecision logs a routing decision, stamping its ID and time.
+func (l *TrainingLog) RecordDecision(decision RoutingDecision) {
+	decision.ID = NewID("route")
+	decision.Time = l.clock.Now().UTC()
+	decision.Routes = append([]HybridRoute(nil), decision.Routes...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decisions = append(l.decisions, decision)
+	if len(l.decisions) > l.capacity {
+		l.decisions = l.decisions[len(l.decisions)-l.capacity:]
+	}
+}
+
+// RecordOutcomes logs the outcomes applied from a feedback batch.
+func (l *TrainingLog) RecordOutcomes(records []FeedbackRecord) {
+	now := l.clock.Now().UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, record := range records {
+		record.Collaborators = append([]string(nil), record.Collaborators...)
+		l.outcomes = append(l.outcomes, RoutingOutcome{Time: now, FeedbackRecord: record})
+	}
+	if len(l.outcomes) > l.capacity {
+		l.outcomes = l.outcomes[len(l.outcomes)-l.capacity:]
+	}
+}
+
+// Decisions returns the logged decisions within a window, oldest first.
+func (l *TrainingLog) Decisions(window ExportWindow) []RoutingDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	decisions := make([]RoutingDecision, 0)
+	for _, d := range l.decisions {
+		if window.contains(d.Time) {
+			decisions = append(decisions, d)
+		}
+	}
+	return decisions
+}
+
+// Outcomes returns the logged outcomes within a window, oldest first.
+func (l *TrainingLog) Outcomes(window ExportWindow) []RoutingOutcome {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	outcomes := make([]RoutingOutcome, 0)
+	for _, o := range l.outcomes {
+		if window.contains(o.Time) {
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes
+}
+
+// ExportWindow limits an export to rows timestamped in [From, To). A zero
+// bound is open.
+type ExportWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+func (w ExportWindow) contains(t time.Time) bool {
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || t.Before(w.To))
+}
+
+// ============================================================================
+// Exporter
+// ============================================================================
+
+// TrainingExporter writes training data and imports the routing models
+// trained on it.
+type TrainingExporter struct {
+	log       *TrainingLog
+	router    *HybridRouter
+	attention *CollaborativeAttentionIndex
+
+	// experiences is the retriever experiences are exported from; nil
+	// exports none
+	experiences *SubLinearRetriever
+}
+
+// NewTrainingExporter creates an exporter of a training log. Routing models
+// are imported into the router's blend and the attention index's weights.
+func NewTrainingExporter(log *TrainingLog, router *HybridRouter, attention *CollaborativeAttentionIndex) *TrainingExporter {
+	return &TrainingExporter{log: log, router: router, attention: attention}
+}
+
+// SetExperiences sets the retriever experiences are exported from. It must
+// be set before the exporter is used.
+func (e *TrainingExporter) SetExperiences(retriever *SubLinearRetriever) {
+	e.experiences = retriever
+}
+
+// Export writes a table in a format and returns the number of rows
+// written.
+func (e *TrainingExporter) Export(w io.Writer, table, format string, window ExportWindow) (int, error) {
+	columns, ok := TrainingSchema[table]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownExportTable, table)
+	}
+	if format != ExportCSV && format != ExportParquet {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownExportFormat, format)
+	}
+
+	rows := e.rows(table, window)
+	if format == ExportParquet {
+		metadata := map[string]string{"eac.table": table}
+		for _, column := range columns {
+			metadata["eac.column."+column.Name] = column.Description
+		}
+		return len(rows), writeParquet(w, columns, rows, metadata)
+	}
+	return len(rows), writeCSV(w, columns, rows)
+}
+
+// rows returns a table's rows, a value per column in schema order.
+func (e *TrainingExporter) rows(table string, window ExportWindow) [][]any {
+	rows := make([][]any, 0)
+	switch table {
+	case TableExperiences:
+		if e.experiences == nil {
+			return rows
+		}
+		for _, exp := range e.experiences.experiencesBefore(time.Unix(0, math.MaxInt64)) {
+			at := time.Unix(0, exp.Timestamp).UTC()
+			if !window.contains(at) {
+				continue
+			}
+			embedding := ""
+			if len(exp.Embedding) > 0 {
+				raw, _ := json.Marshal(exp.Embedding)
+				embedding = string(raw)
+			}
+			rows = append(rows, []any{
+				exp.ID, at, exp.AgentID, int64(exp.TierID), exp.TaskType, exp.TaskSignature,
+				exp.Strategy, exp.Input, exp.Output, exp.Success, exp.FitnessScore,
+				exp.UsageCount, exp.Weight, int64(exp.EvolutionGen), embedding,
+			})
+		}
+	case TableDecisions:
+		for _, d := range e.log.Decisions(window) {
+			for rank, route := range d.Routes {
+				rows = append(rows, []any{
+					d.ID, d.Time, d.Query, int64(rank + 1), route.AgentID, route.Score,
+					route.Keyword, route.Similarity, d.Weights.Keyword, d.Weights.Similarity,
+				})
+			}
+		}
+	case TableOutcomes:
+		for _, o := range e.log.Outcomes(window) {
+			rows = append(rows, []any{
+				o.Time, o.Query, o.Agent, strings.Join(o.Collaborators, ";"), o.Success,
+				o.TaskType, o.Strategy, o.Verbosity,
+			})
+		}
+	}
+	return rows
+}
+
+// writeCSV writes rows as CSV under a header of the column names.
+func writeCSV(w io.Writer, columns []ExportColumn, rows [][]any) error {
+	out := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.Name
+	}
+	if err := out.Write(record); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			switch v := value.(type) {
+			case string:
+				record[i] = v
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			case time.Time:
+				record[i] = v.UTC().Format(time.RFC3339Nano)
+			default:
+				return fmt.Errorf("column %s holds %T", columns[i].Name, value)
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ============================================================================
+// Model Import
+// ============================================================================
+
+// RoutingModel is a routing model trained offline. Either part may be
+// omitted to leave the live one as it is.
+type RoutingModel struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	TrainedAt time.Time `json:"trained_at,omitempty"`
+	// Blend is the weight of each routing signal; it is normalized to sum
+	// to one
+	Blend *RoutingWeights `json:"blend,omitempty"`
+	// Attention holds attention weights by category and agent; each
+	// category's weights are normalized to sum to one
+	Attention map[string]map[string]float64 `json:"attention,omitempty"`
+}
+
+// RoutingModelImport describes the import of a routing model.
+type RoutingModelImport struct {
+	Name    string         `json:"name"`
+	Version string         `json:"version"`
+	Blend   RoutingWeights `json:"blend"`
+	// Categories are the attention categories replaced
+	Categories []string `json:"categories"`
+	// Ignored are the model's categories the index does not route by
+	Ignored []string `json:"ignored,omitempty"`
+}
+
+// validate checks a model can be imported.
+func (m *RoutingModel) validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRoutingModel)
+	}
+	if m.Blend == nil && len(m.Attention) == 0 {
+		return fmt.Errorf("%w: neither a blend nor attention weights", ErrInvalidRoutingModel)
+	}
+	if m.Blend != nil && !validWeights(map[string]float64{"keyword": m.Blend.Keyword, "similarity": m.Blend.Similarity}) {
+		return fmt.Errorf("%w: blend weights must be non-negative and not all zero", ErrInvalidRoutingModel)
+	}
+	for category, weights := range m.Attention {
+		if !validWeights(weights) {
+			return fmt.Errorf("%w: attention weights of %s must be non-negative and not all zero", ErrInvalidRoutingModel, category)
+		}
+	}
+	return nil
+}
+
+// validWeights reports whether weights are finite, non-negative and not
+// all zero.
+func validWeights(weights map[string]float64) bool {
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return false
+		}
+		total += w
+	}
+	return total > 0
+}
+
+// Import applies a routing model to the router and attention index. The
+// model is validated first and nothing is changed if it is invalid.
+func (e *TrainingExporter) Import(model *RoutingModel) (*RoutingModelImport, error) {
+	if err := model.validate(); err != nil {
+		return nil, err
+	}
+	result := &RoutingModelImport{Name: model.Name, Version: model.Version, Categories: make([]string, 0)}
+	if model.Blend != nil {
+		e.router.SetWeights(*model.Blend)
+	}
+	result.Blend = e.router.Weights()
+	if len(model.Attention) > 0 {
+		result.Categories, result.Ignored = e.attention.SetWeights(model.Attention)
+	}
+	return result, nil
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// ServeSchema handles GET /admin/memory/export/schema - returns the schema
+// of every exported table.
+func (e *TrainingExporter) ServeSchema(w http.ResponseWriter, r *http.Request) {
+	errdefs.WriteJSON(w, http.StatusOK, TrainingSchema)
+}
+
+// ServeExport handles GET /admin/memory/export/{table}?format=&from=&to= -
+// downloads a table as CSV (the default) or Parquet, optionally only the
+// rows timestamped from and before RFC 3339 times.
+func (e *TrainingExporter) ServeExport(w http.ResponseWriter, r *http.Request) {
+	table := r.PathValue("table")
+	if _, ok := TrainingSchema[table]; !ok {
+		errdefs.WriteError(w, fmt.Errorf("%w: %s", ErrUnknownExportTable, table).Error(), http.StatusNotFound)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportCSV
+	}
+	var window ExportWindow
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &window.From}, {"to", &window.To}} {
+		if raw := r.URL.Query().Get(bound.name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				errdefs.WriteError(w, bound.name+" must be an RFC 3339 time", http.StatusBadRequest)
+				return
+			}
+			*bound.t = t
+		}
+	}
+
+	var body bytes.Buffer
+	if _, err := e.Export(&body, table, format, window); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == ExportParquet {
+		contentType = "application/vnd.apache.parquet"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", table+"."+format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// ServeImportModel handles POST /admin/memory/models/routing - imports a
+// routing model artifact.
+func (e *TrainingExporter) ServeImportModel(w http.ResponseWriter, r *http.Request) {
+	var model RoutingModel
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRoutingModelBody)).Decode(&model); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	result, err := e.Import(&model)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, result)
+}
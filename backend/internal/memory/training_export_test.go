@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTrainingFixture routes a query and applies feedback on it, logging
+// both, and adds an experience to export.
+func newTrainingFixture(t *testing.T, clock *ManualClock) (*TrainingExporter, *HybridRouter, *CollaborativeAttentionIndex) {
+	t.Helper()
+	attention := NewCollaborativeAttentionIndex()
+	router := NewHybridRouter(attention, DefaultHybridRouterConfig())
+	log := NewTrainingLog(0, clock)
+	router.OnRoute(log.RecordDecision)
+
+	if _, err := router.Route(context.Background(), "optimize the cache", 3); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	clock.Advance(time.Minute)
+	log.RecordOutcomes([]FeedbackRecord{
+		{Query: "optimize the cache", Agent: "VELOCITY", Collaborators: []string{"APEX", "CORE"}, Success: true, TaskType: "performance"},
+	})
+
+	retriever := NewSubLinearRetriever(2)
+	exp := NewExperienceTuple("VELOCITY", 2, "optimize, then \"measure\"", "done", "profile")
+	exp.Timestamp = clock.Now().UnixNano()
+	exp.Embedding = []float32{0.5, 1}
+	exp.Success = true
+	if err := retriever.Add(exp); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	exporter := NewTrainingExporter(log, router, attention)
+	exporter.SetExperiences(retriever)
+	return exporter, router, attention
+}
+
+func TestTrainingExporter_CSV(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	exporter, _, _ := newTrainingFixture(t, NewManualClock(start))
+
+	for _, tc := range []struct {
+		table string
+		rows  int
+		check func(t *testing.T, header, row []string)
+	}{
+		{TableDecisions, 3, func(t *testing.T, header, row []string) {
+			if row[2] != "optimize the cache" || row[3] != "1" || row[4] != "VELOCITY" {
+				t.Errorf("expected VELOCITY ranked first, got %v", row)
+			}
+			if row[1] != "2026-03-01T12:00:00Z" {
+				t.Errorf("expected the routing time, got %s", row[1])
+			}
+		}},
+		{TableOutcomes, 1, func(t *testing.T, header, row []string) {
+			if row[2] != "VELOCITY" || row[3] != "APEX;CORE" || row[4] != "true" {
+				t.Errorf("unexpected outcome row %v", row)
+			}
+		}},
+		{TableExperiences, 1, func(t *testing.T, header, row []string) {
+			if row[7] != "optimize, then \"measure\"" || row[9] != "true" || row[14] != "[0.5,1]" {
+				t.Errorf("unexpected experience row %v", row)
+			}
+		}},
+	} {
+		t.Run(tc.table, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := exporter.Export(&buf, tc.table, ExportCSV, ExportWindow{})
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("exported CSV does not parse: %v", err)
+			}
+			if n != tc.rows || len(records) != tc.rows+1 {
+				t.Fatalf("expected %d rows, got %d (%d records)", tc.rows, n, len(records))
+			}
+			for i, column := range TrainingSchema[tc.table] {
+				if records[0][i] != column.Name {
+					t.Errorf("header %d: expected %s, got %s", i, column.Name, records[0][i])
+				}
+			}
+			tc.check(t, records[0], records[1])
+		})
+	}
+}
+
+func TestTrainingExporter_Parquet(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	exporter, _, _ := newTrainingFixture(t, NewManualClock(start))
+
+	for table := range TrainingSchema {
+		var buf bytes.Buffer
+		n, err := exporter.Export(&buf, table, ExportParquet, ExportWindow{})
+		if err != nil {
+			t.Fatalf("Export %s failed: %v", table, err)
+		}
+		footer, values := readParquet(t, buf.Bytes())
+		if footer[3].(int64) != int64(n) || len(values) != len(TrainingSchema[table]) {
+			t.Errorf("%s: expected %d rows of %d columns, got %v rows of %d", table, n, len(TrainingSchema[table]), footer[3], len(values))
+		}
+	}
+}
+
+func TestTrainingExporter_Window(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	exporter, _, _ := newTrainingFixture(t, NewManualClock(start))
+
+	window := ExportWindow{From: start.Add(30 * time.Second)}
+	if n, _ := exporter.Export(&bytes.Buffer{}, TableDecisions, ExportCSV, window); n != 0 {
+		t.Errorf("expected the decision before the window left out, got %d rows", n)
+	}
+	if n, _ := exporter.Export(&bytes.Buffer{}, TableOutcomes, ExportCSV, window); n != 1 {
+		t.Errorf("expected the outcome in the window, got %d rows", n)
+	}
+}
+
+func TestTrainingExporter_Errors(t *testing.T) {
+	exporter, _, _ := newTrainingFixture(t, NewManualClock(time.Now()))
+	if _, err := exporter.Export(&bytes.Buffer{}, "users", ExportCSV, ExportWindow{}); !errors.Is(err, ErrUnknownExportTable) {
+		t.Errorf("expected ErrUnknownExportTable, got %v", err)
+	}
+	if _, err := exporter.Export(&bytes.Buffer{}, TableOutcomes, "xlsx", ExportWindow{}); !errors.Is(err, ErrUnknownExportFormat) {
+		t.Errorf("expected ErrUnknownExportFormat, got %v", err)
+	}
+}
+
+func TestTrainingLog_Capacity(t *testing.T) {
+	log := NewTrainingLog(2, NewManualClock(time.Now()))
+	for _, query := range []string{"a", "b", "c"} {
+		log.RecordDecision(RoutingDecision{Query: query})
+		log.RecordOutcomes([]FeedbackRecord{{Query: query, Agent: "APEX"}})
+	}
+	decisions, outcomes := log.Decisions(ExportWindow{}), log.Outcomes(ExportWindow{})
+	if len(decisions) != 2 || decisions[0].Query != "b" || len(outcomes) != 2 || outcomes[1].Query != "c" {
+		t.Errorf("expected the two latest kept, got %v and %v", decisions, outcomes)
+	}
+}
+
+func TestTrainingExporter_Import(t *testing.T) {
+	exporter, router, attention := newTrainingFixture(t, NewManualClock(time.Now()))
+
+	result, err := exporter.Import(&RoutingModel{
+		Name:    "routing",
+		Version: "2026-03-01",
+		Blend:   &RoutingWeights{Keyword: 1, Similarity: 3},
+		Attention: map[string]map[string]float64{
+			"performance": {"CORE": 3, "VELOCITY": 1},
+			"astrology":   {"ORACLE": 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if weights := router.Weights(); weights.Keyword != 0.25 || weights.Similarity != 0.75 || result.Blend != weights {
+		t.Errorf("expected a 0.25/0.75 blend, got %+v", weights)
+	}
+	if len(result.Categories) != 1 || result.Categories[0] != "performance" || len(result.Ignored) != 1 || result.Ignored[0] != "astrology" {
+		t.Errorf("unexpected categories %v, ignored %v", result.Categories, result.Ignored)
+	}
+	performance := attention.Weights()["performance"]
+	if performance["CORE"] != 0.75 || performance["VELOCITY"] != 0.25 || len(performance) != 2 {
+		t.Errorf("expected the trained performance weights, got %v", performance)
+	}
+	if routes := attention.RouteQuery("optimize the cache", 1); routes[0].AgentID != "CORE" {
+		t.Errorf("expected routing to follow the trained weights, got %v", routes)
+	}
+
+	for _, model := range []*RoutingModel{
+		{Blend: &RoutingWeights{Keyword: 1}},
+		{Name: "empty"},
+		{Name: "negative", Blend: &RoutingWeights{Keyword: -1, Similarity: 2}},
+		{Name: "zero", Attention: map[string]map[string]float64{"coding": {"APEX": 0}}},
+	} {
+		if _, err := exporter.Import(model); !errors.Is(err, ErrInvalidRoutingModel) {
+			t.Errorf("%+v: expected ErrInvalidRoutingModel, got %v", model, err)
+		}
+	}
+}
+
+func TestTrainingExporter_HTTP(t *testing.T) {
+	exporter, router, _ := newTrainingFixture(t, NewManualClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/memory/export/schema", exporter.ServeSchema)
+	mux.HandleFunc("GET /admin/memory/export/{table}", exporter.ServeExport)
+	mux.HandleFunc("POST /admin/memory/models/routing", exporter.ServeImportModel)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/memory/export/schema", nil))
+	var schema map[string][]ExportColumn
+	if err := json.NewDecoder(rec.Body).Decode(&schema); err != nil || len(schema[TableDecisions]) != len(TrainingSchema[TableDecisions]) {
+		t.Errorf("expected the schema, got %v (%v)", schema, err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/memory/export/outcomes?format=parquet", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/vnd.apache.parquet" {
+		t.Fatalf("expected a Parquet download, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.Contains(disposition, "outcomes.parquet") {
+		t.Errorf("expected an outcomes.parquet attachment, got %s", disposition)
+	}
+	readParquet(t, rec.Body.Bytes())
+
+	for path, status := range map[string]int{
+		"/admin/memory/export/users":                             http.StatusNotFound,
+		"/admin/memory/export/outcomes?format=xlsx":              http.StatusBadRequest,
+		"/admin/memory/export/outcomes?from=yesterday":           http.StatusBadRequest,
+		"/admin/memory/export/decisions?to=2026-03-02T00:00:00Z": http.StatusOK,
+	} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != status {
+			t.Errorf("%s: expected %d, got %d", path, status, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	body := `{"name":"routing","version":"v2","blend":{"keyword":0.4,"similarity":0.6}}`
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/memory/models/routing", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || router.Weights().Similarity != 0.6 {
+		t.Errorf("expected the blend imported, got %d %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/memory/models/routing", strings.NewReader(`{"version":"v3"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid model, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,365 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements the startup warmup phase.
+//
+// On boot the server loads persisted snapshots, replays logs, rebuilds
+// indexes and verifies integrity before it is ready. Warmup runs these as
+// ordered steps in the background while the server already answers
+// liveness checks; the readiness probe stays false until every step has
+// finished. In degraded mode the server reports ready immediately and
+// serves from whatever has loaded so far, trading completeness for
+// availability. Progress is logged as steps advance.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrWarmupIncomplete is returned for requests gated behind an unfinished warmup
+var ErrWarmupIncomplete = errdefs.New(errdefs.ErrUnavailable, "memory warmup in progress")
+
+// WarmupProgress reports how far a step has got. Steps that can't measure
+// progress may never call it.
+type WarmupProgress func(done, total int)
+
+// WarmupStep is one phase of the warmup.
+type WarmupStep struct {
+	Name string
+	Run  func(ctx context.Context, progress WarmupProgress) error
+	// Optional steps log failures and let the warmup continue
+	Optional bool
+}
+
+// WarmupConfig configures a Warmup.
+type WarmupConfig struct {
+	// ServeDegraded reports ready and serves reads while warming; writes
+	// still wait for the warmup to complete
+	ServeDegraded bool
+	// ProgressInterval is the minimum time between progress log lines
+	ProgressInterval time.Duration
+	// Logf receives progress lines; defaults to log.Printf
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultWarmupConfig returns the default warmup configuration.
+func DefaultWarmupConfig() WarmupConfig {
+	return WarmupConfig{
+		ServeDegraded:    false,
+		ProgressInterval: 2 * time.Second,
+		Logf:             log.Printf,
+	}
+}
+
+// WarmupState is the phase a warmup is in.
+type WarmupState string
+
+const (
+	WarmupPending  WarmupState = "pending"
+	WarmupRunning  WarmupState = "running"
+	WarmupComplete WarmupState = "complete"
+	WarmupFailed   WarmupState = "failed"
+)
+
+// WarmupStatus describes warmup progress for readiness probes.
+type WarmupStatus struct {
+	State WarmupState `json:"state"`
+	Ready bool        `json:"ready"`
+	// Degraded is set while serving before warmup has completed
+	Degraded bool   `json:"degraded"`
+	Step     string `json:"step,omitempty"`
+	// StepIndex is the 1-based position of Step among Steps
+	StepIndex int      `json:"step_index"`
+	Steps     int      `json:"steps"`
+	Done      int      `json:"done"`
+	Total     int      `json:"total"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	// Elapsed is the warmup duration so far, or in total once finished
+	Elapsed string `json:"elapsed"`
+}
+
+// Warmup runs startup steps and gates readiness on their completion.
+type Warmup struct {
+	config WarmupConfig
+	steps  []WarmupStep
+
+	state       WarmupState
+	current     int
+	done, total int
+	warnings    []string
+	err         error
+	startedAt   time.Time
+	finishedAt  time.Time
+	lastLogged  time.Time
+
+	mu sync.RWMutex
+}
+
+// NewWarmup creates a warmup with no steps.
+func NewWarmup(config WarmupConfig) *Warmup {
+	if config.Logf == nil {
+		config.Logf = log.Printf
+	}
+	return &Warmup{
+		config:   config,
+		steps:    make([]WarmupStep, 0),
+		state:    WarmupPending,
+		warnings: make([]string, 0),
+	}
+}
+
+// AddStep appends a step. Steps must be added before Run.
+func (w *Warmup) AddStep(step WarmupStep) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.steps = append(w.steps, step)
+}
+
+// Run executes the steps in order. A failing required step stops the warmup
+// and leaves the server unready; in degraded mode it keeps serving.
+func (w *Warmup) Run(ctx context.Context) error {
+	w.mu.Lock()
+	if w.state != WarmupPending {
+		w.mu.Unlock()
+		return fmt.Errorf("warmup already %s", w.state)
+	}
+	w.state = WarmupRunning
+	w.startedAt = time.Now()
+	steps := w.steps
+	w.mu.Unlock()
+
+	w.config.Logf("Warmup: starting %d steps", len(steps))
+	for i, step := range steps {
+		w.mu.Lock()
+		w.current = i
+		w.done, w.total = 0, 0
+		w.mu.Unlock()
+
+		start := time.Now()
+		w.config.Logf("Warmup: [%d/%d] %s", i+1, len(steps), step.Name)
+		err := step.Run(ctx, w.progressFor(i, step.Name))
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil && step.Optional && ctx.Err() == nil {
+			w.mu.Lock()
+			w.warnings = append(w.warnings, fmt.Sprintf("%s: %v", step.Name, err))
+			w.mu.Unlock()
+			w.config.Logf("Warmup: [%d/%d] %s failed, continuing: %v", i+1, len(steps), step.Name, err)
+			continue
+		}
+		if err != nil {
+			w.finish(WarmupFailed, fmt.Errorf("warmup step %s: %w", step.Name, err))
+			w.config.Logf("Warmup: [%d/%d] %s failed: %v", i+1, len(steps), step.Name, err)
+			return w.err
+		}
+		w.config.Logf("Warmup: [%d/%d] %s done in %s", i+1, len(steps), step.Name, time.Since(start).Round(time.Millisecond))
+	}
+
+	w.finish(WarmupComplete, nil)
+	w.config.Logf("Warmup: complete in %s", w.finishedAt.Sub(w.startedAt).Round(time.Millisecond))
+	return nil
+}
+
+// finish records the final state.
+func (w *Warmup) finish(state WarmupState, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = state
+	w.err = err
+	w.finishedAt = time.Now()
+}
+
+// progressFor returns the progress callback of step i, logging at most
+// once per ProgressInterval.
+func (w *Warmup) progressFor(i int, name string) WarmupProgress {
+	return func(done, total int) {
+		w.mu.Lock()
+		w.done, w.total = done, total
+		now := time.Now()
+		shouldLog := done == total || now.Sub(w.lastLogged) >= w.config.ProgressInterval
+		if shouldLog {
+			w.lastLogged = now
+		}
+		steps := len(w.steps)
+		w.mu.Unlock()
+
+		if shouldLog && total > 0 {
+			w.config.Logf("Warmup: [%d/%d] %s %d/%d (%.0f%%)", i+1, steps, name, done, total,
+				100*float64(done)/float64(total))
+		}
+	}
+}
+
+// Ready returns true once warmup completed, or always in degraded mode.
+func (w *Warmup) Ready() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state == WarmupComplete || w.config.ServeDegraded
+}
+
+// Status returns a snapshot of warmup progress.
+func (w *Warmup) Status() WarmupStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	status := WarmupStatus{
+		State:    w.state,
+		Ready:    w.state == WarmupComplete || w.config.ServeDegraded,
+		Degraded: w.config.ServeDegraded && w.state != WarmupComplete,
+		Steps:    len(w.steps),
+		Done:     w.done,
+		Total:    w.total,
+		Warnings: append([]string(nil), w.warnings...),
+	}
+	if w.state == WarmupRunning {
+		status.Step = w.steps[w.current].Name
+		status.StepIndex = w.current + 1
+	}
+	if w.err != nil {
+		status.Error = w.err.Error()
+	}
+	switch {
+	case w.startedAt.IsZero():
+		status.Elapsed = "0s"
+	case w.finishedAt.IsZero():
+		status.Elapsed = time.Since(w.startedAt).Round(time.Millisecond).String()
+	default:
+		status.Elapsed = w.finishedAt.Sub(w.startedAt).Round(time.Millisecond).String()
+	}
+	return status
+}
+
+// ServeReady is a readiness probe: 200 when ready, 503 while warming.
+func (w *Warmup) ServeReady(rw http.ResponseWriter, r *http.Request) {
+	status := w.Status()
+	rw.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(rw).Encode(status)
+}
+
+// Gate rejects requests with 503 until the warmup makes the server ready.
+// Requests that may change memory - any method but GET, HEAD and OPTIONS -
+// wait for the warmup to complete even when serving degraded: a write
+// landing before the snapshot is restored would be overwritten by it, and
+// one landing before the log is attached would never be logged.
+func (w *Warmup) Gate(next http.Handler) http.Handler {
+	return w.gate(next, func(r *http.Request) bool {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return false
+		}
+		return true
+	})
+}
+
+// GateReads is Gate for handlers that only read memory whatever their
+// method, such as queries posted as JSON, so degraded mode serves them.
+func (w *Warmup) GateReads(next http.Handler) http.Handler {
+	return w.gate(next, func(*http.Request) bool { return false })
+}
+
+// gate rejects requests until the server is ready, or until the warmup
+// is complete for requests that write.
+func (w *Warmup) gate(next http.Handler, writes func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !w.Ready() || (writes(r) && !w.complete()) {
+			rw.Header().Set("Retry-After", "5")
+			http.Error(rw, ErrWarmupIncomplete.Error(), errdefs.HTTPStatus(ErrWarmupIncomplete))
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// complete returns true once warmup completed.
+func (w *Warmup) complete() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state == WarmupComplete
+}
+
+// ============================================================================
+// Semantic Network Warmup
+// ============================================================================
+
+// SemanticWarmupOptions names the persisted artifacts of a network.
+type SemanticWarmupOptions struct {
+	// SnapshotPath is the snapshot file to restore; empty or missing skips it
+	SnapshotPath string
+	// WAL is replayed after the snapshot's LSN when set
+	WAL *WriteAheadLog
+	// Seed populates a network that had no snapshot
+	Seed func(sn *SemanticNetwork) error
+}
+
+// AddSemanticNetworkSteps adds the steps that warm a Semantic Network:
+// restore the snapshot (or seed), replay the log, rebuild indexes and
+// verify integrity. The log is attached once replay has finished.
+func (w *Warmup) AddSemanticNetworkSteps(sn *SemanticNetwork, opts SemanticWarmupOptions) {
+	var afterLSN uint64
+
+	w.AddStep(WarmupStep{
+		Name: "load snapshot",
+		Run: func(ctx context.Context, progress WarmupProgress) error {
+			if opts.SnapshotPath != "" {
+				snapshot, err := LoadSnapshotFile(opts.SnapshotPath)
+				if err == nil {
+					afterLSN = snapshot.LSN
+					w.config.Logf("Warmup: restoring %d nodes and %d relations from %s",
+						len(snapshot.Nodes), len(snapshot.Relations), opts.SnapshotPath)
+					return sn.Restore(snapshot)
+				}
+				if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+			if opts.Seed != nil {
+				return opts.Seed(sn)
+			}
+			return nil
+		},
+	})
+
+	if opts.WAL != nil {
+		w.AddStep(WarmupStep{
+			Name: "replay write-ahead log",
+			Run: func(ctx context.Context, progress WarmupProgress) error {
+				replayed, err := sn.ReplayWAL(opts.WAL, afterLSN)
+				w.config.Logf("Warmup: replayed %d mutations after LSN %d", replayed, afterLSN)
+				if err != nil {
+					return err
+				}
+				sn.AttachWAL(opts.WAL)
+				return nil
+			},
+		})
+	}
+
+	w.AddStep(WarmupStep{
+		Name: "rebuild indexes",
+		Run: func(ctx context.Context, progress WarmupProgress) error {
+			sn.RebuildIndexes(progress)
+			return nil
+		},
+	})
+
+	w.AddStep(WarmupStep{
+		Name: "verify integrity",
+		Run: func(ctx context.Context, progress WarmupProgress) error {
+			return sn.VerifyIntegrity()
+		},
+	})
+}
@@ -0,0 +1,270 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ============================================================================
+// Warmup Tests
+// ============================================================================
+
+// testWarmupConfig captures log lines instead of printing them.
+func testWarmupConfig(lines *[]string, mu *sync.Mutex) WarmupConfig {
+	config := DefaultWarmupConfig()
+	config.ProgressInterval = 0
+	config.Logf = func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		*lines = append(*lines, fmt.Sprintf(format, args...))
+	}
+	return config
+}
+
+func TestWarmup_Run(t *testing.T) {
+	var lines []string
+	var mu sync.Mutex
+	w := NewWarmup(testWarmupConfig(&lines, &mu))
+
+	release := make(chan struct{})
+	w.AddStep(WarmupStep{Name: "load", Run: func(ctx context.Context, progress WarmupProgress) error {
+		progress(1, 2)
+		<-release
+		progress(2, 2)
+		return nil
+	}})
+	w.AddStep(WarmupStep{Name: "optional", Optional: true, Run: func(ctx context.Context, progress WarmupProgress) error {
+		return errors.New("cache unavailable")
+	}})
+
+	done := make(chan error)
+	go func() { done <- w.Run(context.Background()) }()
+
+	// Gated while the first step is blocked
+	if !waitFor(func() bool { return w.Status().Done == 1 }) {
+		t.Fatal("Expected progress from first step")
+	}
+	status := w.Status()
+	if w.Ready() || status.State != WarmupRunning || status.Step != "load" || status.StepIndex != 1 {
+		t.Errorf("Expected running step 1 and not ready, got %+v", status)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	status = w.Status()
+	if !w.Ready() || status.State != WarmupComplete {
+		t.Errorf("Expected complete and ready, got %+v", status)
+	}
+	if len(status.Warnings) != 1 || !strings.Contains(status.Warnings[0], "cache unavailable") {
+		t.Errorf("Expected optional step warning, got %v", status.Warnings)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	joined := strings.Join(lines, "\n")
+	if !strings.Contains(joined, "load 2/2 (100%)") || !strings.Contains(joined, "complete in") {
+		t.Errorf("Expected progress and completion logged, got:\n%s", joined)
+	}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("Expected error running warmup twice")
+	}
+}
+
+func TestWarmup_FailedStep(t *testing.T) {
+	var lines []string
+	var mu sync.Mutex
+	w := NewWarmup(testWarmupConfig(&lines, &mu))
+	w.AddStep(WarmupStep{Name: "verify", Run: func(ctx context.Context, progress WarmupProgress) error {
+		return ErrIntegrityViolation
+	}})
+	ran := false
+	w.AddStep(WarmupStep{Name: "after", Run: func(ctx context.Context, progress WarmupProgress) error {
+		ran = true
+		return nil
+	}})
+
+	if err := w.Run(context.Background()); !errors.Is(err, ErrIntegrityViolation) {
+		t.Errorf("Expected ErrIntegrityViolation, got %v", err)
+	}
+	if ran {
+		t.Error("Expected later steps skipped after a failure")
+	}
+	if w.Ready() || w.Status().State != WarmupFailed {
+		t.Errorf("Expected failed and not ready, got %+v", w.Status())
+	}
+}
+
+func TestWarmup_ServeDegraded(t *testing.T) {
+	config := DefaultWarmupConfig()
+	config.ServeDegraded = true
+	w := NewWarmup(config)
+
+	status := w.Status()
+	if !w.Ready() || !status.Degraded {
+		t.Errorf("Expected ready and degraded before warmup, got %+v", status)
+	}
+	w.Run(context.Background())
+	if w.Status().Degraded {
+		t.Error("Expected degraded cleared after warmup")
+	}
+}
+
+func TestWarmup_ServeReadyAndGate(t *testing.T) {
+	w := NewWarmup(DefaultWarmupConfig())
+	gated := w.Gate(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	w.ServeReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while warming, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	gated.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/memory/query", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected gated 503 with Retry-After, got %d", rec.Code)
+	}
+
+	w.Run(context.Background())
+	rec = httptest.NewRecorder()
+	w.ServeReady(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var status WarmupStatus
+	json.NewDecoder(rec.Body).Decode(&status)
+	if rec.Code != http.StatusOK || !status.Ready {
+		t.Errorf("Expected 200 and ready, got %d and %+v", rec.Code, status)
+	}
+	rec = httptest.NewRecorder()
+	gated.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/memory/query", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected request passed through after warmup, got %d", rec.Code)
+	}
+}
+
+func TestWarmup_DegradedGateRejectsWrites(t *testing.T) {
+	config := DefaultWarmupConfig()
+	config.ServeDegraded = true
+	w := NewWarmup(config)
+	release := make(chan struct{})
+	w.AddStep(WarmupStep{Name: "load snapshot", Run: func(ctx context.Context, progress WarmupProgress) error {
+		progress(0, 1)
+		<-release
+		return nil
+	}})
+	done := make(chan error)
+	go func() { done <- w.Run(context.Background()) }()
+	if !waitFor(func() bool { return w.Status().State == WarmupRunning }) {
+		t.Fatal("Expected the warmup to be running")
+	}
+
+	network := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	ingest := w.Gate(http.HandlerFunc(NewStreamIngester(network, nil, StreamIngestConfig{}).ServeIngest))
+	query := w.GateReads(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	post := func(handler http.Handler, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	// Degraded mode serves reads but not writes while a step is blocked
+	rec := post(ingest, "/memory/ingest", `{"node": {"id": "sorting", "type": "concept"}}`+"\n")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" || network.NodeCount() != 0 {
+		t.Errorf("Expected the ingest rejected with 503 while warming, got %d and %d nodes", rec.Code, network.NodeCount())
+	}
+	if rec := post(query, "/memory/query", `{}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected a query served while degraded, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	w.Gate(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memory/semantic/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected a GET served while degraded, got %d", rec.Code)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if rec := post(ingest, "/memory/ingest", `{"node": {"id": "sorting", "type": "concept"}}`+"\n"); rec.Code != http.StatusOK || network.NodeCount() != 1 {
+		t.Errorf("Expected the ingest applied after warmup, got %d and %d nodes", rec.Code, network.NodeCount())
+	}
+}
+
+func TestWarmup_SemanticNetworkSteps(t *testing.T) {
+	dir := t.TempDir()
+	snapshotPath := filepath.Join(dir, "semantic.snapshot")
+
+	// A previous run left a snapshot and one logged mutation after it
+	wal, err := OpenWAL(filepath.Join(dir, "semantic.wal"), WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	previous := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	previous.AttachWAL(wal)
+	previous.AddNode(NewSemanticNode("sorting", "Sorting", ConceptNode))
+	SaveSnapshotFile(snapshotPath, previous.Snapshot())
+	previous.AddNode(NewSemanticNode("quicksort", "QuickSort", InstanceNode))
+	previous.AttachWAL(nil)
+
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	seeded := false
+	w := NewWarmup(DefaultWarmupConfig())
+	w.AddSemanticNetworkSteps(sn, SemanticWarmupOptions{
+		SnapshotPath: snapshotPath,
+		WAL:          wal,
+		Seed: func(sn *SemanticNetwork) error {
+			seeded = true
+			return nil
+		},
+	})
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if seeded {
+		t.Error("Expected seed skipped when a snapshot exists")
+	}
+	if sn.NodeCount() != 2 {
+		t.Errorf("Expected snapshot plus replayed node, got %d nodes", sn.NodeCount())
+	}
+	if w.Status().Steps != 4 {
+		t.Errorf("Expected 4 steps, got %d", w.Status().Steps)
+	}
+
+	// The log is attached once warm
+	sn.AddNode(NewSemanticNode("mergesort", "MergeSort", InstanceNode))
+	if wal.LastLSN() != 3 {
+		t.Errorf("Expected new mutation logged at LSN 3, got %d", wal.LastLSN())
+	}
+}
+
+func TestWarmup_SemanticNetworkSeed(t *testing.T) {
+	sn := NewSemanticNetwork(DefaultSemanticNetworkConfig())
+	w := NewWarmup(DefaultWarmupConfig())
+	w.AddSemanticNetworkSteps(sn, SemanticWarmupOptions{
+		SnapshotPath: filepath.Join(t.TempDir(), "missing.snapshot"),
+		Seed: func(sn *SemanticNetwork) error {
+			return sn.AddNode(NewSemanticNode("apex", "APEX", AgentNode))
+		},
+	})
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if sn.NodeCount() != 1 {
+		t.Errorf("Expected seeded node, got %d nodes", sn.NodeCount())
+	}
+}
@@ -15,13 +15,14 @@
 package memory
 
 import (
-	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
 )
 
 // ============================================================================
@@ -29,11 +30,11 @@ import (
 // ============================================================================
 
 var (
-	ErrInvalidState            = errors.New("invalid state")
-	ErrInvalidAction           = errors.New("invalid action")
-	ErrSimulationFailed        = errors.New("simulation failed")
-	ErrSimulationDepthExceeded = errors.New("maximum simulation depth exceeded")
-	ErrNoTransitionsDefined    = errors.New("no transitions defined for state")
+	ErrInvalidState            = errdefs.New(errdefs.ErrInvalidArgument, "invalid state")
+	ErrInvalidAction           = errdefs.New(errdefs.ErrInvalidArgument, "invalid action")
+	ErrSimulationFailed        = errdefs.New(errdefs.ErrInternal, "simulation failed")
+	ErrSimulationDepthExceeded = errdefs.New(errdefs.ErrCapacityExceeded, "maximum simulation depth exceeded")
+	ErrNoTransitionsDefined    = errdefs.New(errdefs.ErrNotFound, "no transitions defined for state")
 )
 
 // ============================================================================
@@ -87,9 +88,6 @@ func (st StateType) IsTerminal() bool {
 // State
 // ============================================================================
 
-// stateIDCounter provides unique IDs
-var stateIDCounter uint64
-
 // State represents a point in the simulation state space.
 type State struct {
 	// ID uniquely identifies this state
@@ -126,7 +124,7 @@ type State struct {
 // NewState creates a new state.
 func NewState(stateType StateType, description string) *State {
 	return &State{
-		ID:          fmt.Sprintf("state-%d", atomic.AddUint64(&stateIDCounter, 1)),
+		ID:          NewID("state"),
 		Type:        stateType,
 		Description: description,
 		Features:    make(map[string]interface{}),
@@ -226,9 +224,6 @@ func (s *State) Similarity(other *State) float64 {
 // Action
 // ============================================================================
 
-// actionIDCounter provides unique IDs
-var actionIDCounter uint64
-
 // SimActionType classifies simulation actions.
 type SimActionType int
 
@@ -306,7 +301,7 @@ type SimAction struct {
 // NewSimAction creates a new simulation action.
 func NewSimAction(actionType SimActionType, name string) *SimAction {
 	return &SimAction{
-		ID:                 fmt.Sprintf("action-%d", atomic.AddUint64(&actionIDCounter, 1)),
+		ID:                 NewID("action"),
 		Type:               actionType,
 		Name:               name,
 		Parameters:         make(map[string]interface{}),
@@ -376,9 +371,9 @@ func (p *Predicate) Evaluate(state *State) bool {
 	case "not_exists":
 		return !exists
 	case "eq":
-		return exists && val == p.Value
+		return exists && simEqual(val, p.Value)
 	case "ne":
-		return !exists || val != p.Value
+		return !exists || !simEqual(val, p.Value)
 	case "gt", "lt", "gte", "lte":
 		if !exists {
 			return false
@@ -389,6 +384,26 @@ func (p *Predicate) Evaluate(state *State) bool {
 	}
 }
 
+// simEqual reports whether two feature values are equal. Numbers are
+// compared by value whatever their types, so an int feature equals a
+// float64 threshold; values that can't be compared, such as slices and
+// maps, are never equal.
+func simEqual(a, b interface{}) bool {
+	af, aok := simToFloat64(a)
+	bf, bok := simToFloat64(b)
+	if aok && bok {
+		return af == bf
+	}
+	if a == nil || b == nil {
+		return a == b
+	}
+	typ := reflect.TypeOf(a)
+	if typ != reflect.TypeOf(b) || !typ.Comparable() {
+		return false
+	}
+	return a == b
+}
+
 // simCompareValues compares two values with the given operator.
 func simCompareValues(a, b interface{}, op string) bool {
 	af, aok := simToFloat64(a)
@@ -470,9 +485,6 @@ func (e *StateEffect) Apply(state *State) {
 // Trajectory
 // ============================================================================
 
-// trajectoryIDCounter provides unique IDs
-var trajectoryIDCounter uint64
-
 // Trajectory represents a sequence of states and actions.
 type Trajectory struct {
 	// ID uniquely identifies this trajectory
@@ -506,7 +518,7 @@ type Trajectory struct {
 // NewTrajectory creates a new trajectory starting from a state.
 func NewTrajectory(initialState *State) *Trajectory {
 	return &Trajectory{
-		ID:               fmt.Sprintf("traj-%d", atomic.AddUint64(&trajectoryIDCounter, 1)),
+		ID:               NewID("traj"),
 		States:           []*State{initialState.Clone()},
 		Actions:          make([]*SimAction, 0),
 		EstimatedSuccess: 1.0,
@@ -657,7 +669,7 @@ func (sp *StatePredictor) Predict(currentState *State, action *SimAction) *State
 
 	// Create new state as copy of current
 	nextState := currentState.Clone()
-	nextState.ID = fmt.Sprintf("state-%d", atomic.AddUint64(&stateIDCounter, 1))
+	nextState.ID = NewID("state")
 	nextState.ParentID = currentState.ID
 	nextState.ActionID = action.ID
 	nextState.CreatedAt = time.Now()
@@ -914,3 +914,51 @@ func BenchmarkWorldModel_SimulateBestPath(b *testing.B) {
 		wm.SimulateBestPath(state, 5)
 	}
 }
+
+// ============================================================================
+// Fuzz Tests
+// ============================================================================
+
+func FuzzPredicate_Evaluate(f *testing.F) {
+	f.Add("count", uint8(4), uint8(4), "", 10.0, int64(10), true)
+	f.Add("count", uint8(4), uint8(2), "", 10.0, int64(10), true)
+	f.Add("name", uint8(1), uint8(5), "test", 0.0, int64(0), true)
+	f.Add("tags", uint8(8), uint8(8), "a", 0.0, int64(0), true)
+	f.Add("ratio", uint8(2), uint8(3), "", 0.1, int64(0), true)
+	f.Add("missing", uint8(0), uint8(0), "", 0.0, int64(0), false)
+
+	operators := []string{"eq", "ne", "gt", "lt", "gte", "lte", "exists", "not_exists"}
+
+	f.Fuzz(func(t *testing.T, feature string, featureKind, valueKind uint8, s string, x float64, i int64, present bool) {
+		state := NewState(StateInitial, "Fuzz")
+		if present {
+			state.SetFeature(feature, fuzzValue(featureKind, s, x, i))
+		}
+		value := fuzzValue(valueKind, s, x, i)
+
+		// No operator may panic, whatever the types
+		results := make(map[string]bool, len(operators))
+		for _, op := range operators {
+			p := &Predicate{Feature: feature, Operator: op, Value: value}
+			results[op] = p.Evaluate(state)
+		}
+
+		if results["exists"] == results["not_exists"] {
+			t.Fatalf("Expected exists and not_exists to differ, got %v", results["exists"])
+		}
+		if results["eq"] == results["ne"] {
+			t.Fatalf("Expected eq and ne to differ, got %v", results["eq"])
+		}
+		if results["gt"] && results["lt"] {
+			t.Fatal("Expected gt and lt not to both hold")
+		}
+		if results["gt"] && !results["gte"] || results["lt"] && !results["lte"] {
+			t.Fatalf("Expected strict comparisons to imply loose ones, got %v", results)
+		}
+		// Numbers that are neither less nor greater are equal, whatever
+		// their types
+		if results["gte"] && results["lte"] && !results["eq"] {
+			t.Fatalf("Expected gte and lte to imply eq for %T and %T", state.Features[feature], value)
+		}
+	})
+}
@@ -0,0 +1,625 @@
+// Package memory provides the MNEMONIC system for the Elite Agent Collective.
+// This file implements a write-ahead log for memory mutations.
+//
+// Stores append a record before applying each mutation. Records are framed
+// as [length uint32][crc32 uint32][JSON payload] so a record torn by a crash
+// is detected and dropped on open. Appends are buffered and a background
+// flusher fsyncs every SyncInterval (group commit), bounding what a crash
+// can lose to that interval; a zero interval fsyncs every append.
+//
+// Stores check a mutation can be applied before appending its record, so
+// every record in the log took effect. When Append fails after the record
+// was written, it follows it with an abort record and replay skips both.
+//
+// On startup, restore the latest snapshot and replay the records after its
+// LSN. Once a snapshot is safely stored, Checkpoint drops the records it
+// covers so the log stays short.
+
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrWALClosed is returned when appending to a closed log
+	ErrWALClosed = errdefs.New(errdefs.ErrUnavailable, "write-ahead log is closed")
+	// ErrWALCorrupt indicates a record failed its checksum or could not be decoded
+	ErrWALCorrupt = errdefs.New(errdefs.ErrInternal, "write-ahead log record is corrupt")
+)
+
+// walHeaderSize is the length and checksum prefix of each record.
+const walHeaderSize = 8
+
+// maxWALRecordSize guards against reading garbage lengths after a torn write.
+const maxWALRecordSize = 64 << 20
+
+// ============================================================================
+// Records
+// ============================================================================
+
+// WALOp identifies the mutation a record describes.
+type WALOp string
+
+const (
+	WALNodeAdd          WALOp = "node.add"
+	WALNodeUpdate       WALOp = "node.update"
+	WALNodeRemove       WALOp = "node.remove"
+	WALNodeProperty     WALOp = "node.property"
+	WALRelationAdd      WALOp = "relation.add"
+	WALRelationRemove   WALOp = "relation.remove"
+	WALNetworkClear     WALOp = "network.clear"
+	WALExperienceAdd    WALOp = "experience.add"
+	WALExperienceRemove WALOp = "experience.remove"
+	// WALCheckpoint carries the LSN covered by a snapshot
+	WALCheckpoint WALOp = "checkpoint"
+	// WALAbort marks the record at Aborts as never applied
+	WALAbort WALOp = "abort"
+)
+
+// WALRecord is a single logged mutation.
+type WALRecord struct {
+	// LSN is the log sequence number, increasing by one per record
+	LSN  uint64    `json:"lsn"`
+	Op   WALOp     `json:"op"`
+	Time time.Time `json:"time"`
+	// ID is the node, relation or experience ID for removals
+	ID string `json:"id,omitempty"`
+	// Key and Value describe a single property write
+	Key   string    `json:"key,omitempty"`
+	Value *walValue `json:"value,omitempty"`
+	// Aborts is the LSN of the record an abort record cancels
+	Aborts uint64 `json:"aborts,omitempty"`
+
+	Node       *SemanticNode       `json:"node,omitempty"`
+	Relation   *SemanticRelation   `json:"relation,omitempty"`
+	Experience *ExperienceTuple    `json:"experience,omitempty"`
+	Properties map[string]walValue `json:"properties,omitempty"`
+}
+
+// walValue preserves the Go type of a property through JSON, which would
+// otherwise turn ints into float64 and PropertyValues into maps.
+type walValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v"`
+}
+
+// encodeWALValue wraps a property value with its type.
+func encodeWALValue(value interface{}) (walValue, error) {
+	var typeName string
+	switch value.(type) {
+	case nil:
+		typeName = "nil"
+	case string:
+		typeName = "string"
+	case bool:
+		typeName = "bool"
+	case int:
+		typeName = "int"
+	case int64:
+		typeName = "int64"
+	case float64:
+		typeName = "float64"
+	case float32:
+		typeName = "float32"
+	case time.Duration:
+		typeName = "duration"
+	case time.Time:
+		typeName = "time"
+	case PropertyValue:
+		typeName = "property"
+	case []string:
+		typeName = "strings"
+	default:
+		typeName = "json"
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return walValue{}, err
+	}
+	return walValue{Type: typeName, Value: raw}, nil
+}
+
+// decode restores the original Go value.
+func (v walValue) decode() (interface{}, error) {
+	var err error
+	switch v.Type {
+	case "nil":
+		return nil, nil
+	case "string":
+		var s string
+		err = json.Unmarshal(v.Value, &s)
+		return s, err
+	case "bool":
+		var b bool
+		err = json.Unmarshal(v.Value, &b)
+		return b, err
+	case "int":
+		var n int
+		err = json.Unmarshal(v.Value, &n)
+		return n, err
+	case "int64":
+		var n int64
+		err = json.Unmarshal(v.Value, &n)
+		return n, err
+	case "float64":
+		var f float64
+		err = json.Unmarshal(v.Value, &f)
+		return f, err
+	case "float32":
+		var f float32
+		err = json.Unmarshal(v.Value, &f)
+		return f, err
+	case "duration":
+		var d time.Duration
+		err = json.Unmarshal(v.Value, &d)
+		return d, err
+	case "time":
+		var t time.Time
+		err = json.Unmarshal(v.Value, &t)
+		return t, err
+	case "property":
+		var p PropertyValue
+		err = json.Unmarshal(v.Value, &p)
+		return p, err
+	case "strings":
+		var s []string
+		err = json.Unmarshal(v.Value, &s)
+		return s, err
+	default:
+		var raw interface{}
+		err = json.Unmarshal(v.Value, &raw)
+		return raw, err
+	}
+}
+
+// encodeWALValues wraps every value of a property map.
+func encodeWALValues(values map[string]interface{}) (map[string]walValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	encoded := make(map[string]walValue, len(values))
+	for k, v := range values {
+		ev, err := encodeWALValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", k, err)
+		}
+		encoded[k] = ev
+	}
+	return encoded, nil
+}
+
+// decodeWALValues restores a property map.
+func decodeWALValues(encoded map[string]walValue) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(encoded))
+	for k, ev := range encoded {
+		v, err := ev.decode()
+		if err != nil {
+			return nil, fmt.Errorf("%w: property %s: %v", ErrWALCorrupt, k, err)
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// ============================================================================
+// Write-Ahead Log
+// ============================================================================
+
+// WALConfig configures a write-ahead log.
+type WALConfig struct {
+	// SyncInterval is how often buffered records are fsynced; 0 syncs every append
+	SyncInterval time.Duration
+}
+
+// DefaultWALConfig returns sensible defaults.
+func DefaultWALConfig() WALConfig {
+	return WALConfig{
+		SyncInterval: 5 * time.Millisecond,
+	}
+}
+
+// WALStats tracks log activity.
+type WALStats struct {
+	Appends      int64
+	Syncs        int64
+	BytesWritten int64
+	// TornRecords counts incomplete records dropped when the log was opened
+	TornRecords int64
+}
+
+// WriteAheadLog is an append-only, checksummed log of memory mutations.
+type WriteAheadLog struct {
+	mu sync.Mutex
+
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+	config WALConfig
+
+	// lastLSN is the sequence number of the last appended record
+	lastLSN uint64
+	// dirty is set when buffered records await an fsync
+	dirty  bool
+	closed bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	stats WALStats
+}
+
+// OpenWAL opens or creates the log at path. An incomplete record at the
+// end, left by a crash mid-write, is truncated away.
+func OpenWAL(path string, config WALConfig) (*WriteAheadLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+	}
+
+	wal := &WriteAheadLog{
+		path:   path,
+		file:   file,
+		config: config,
+		stopCh: make(chan struct{}),
+	}
+
+	good, err := scanWAL(file, func(rec *WALRecord) error {
+		if rec.LSN > wal.lastLSN {
+			wal.lastLSN = rec.LSN
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrWALCorrupt) {
+		file.Close()
+		return nil, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+	}
+	if info, statErr := file.Stat(); statErr == nil && info.Size() > good {
+		wal.stats.TornRecords++
+		if err := file.Truncate(good); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+		}
+	}
+	if _, err := file.Seek(good, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+	}
+	wal.writer = bufio.NewWriter(file)
+
+	if config.SyncInterval > 0 {
+		wal.wg.Add(1)
+		go wal.flushLoop()
+	}
+	return wal, nil
+}
+
+// scanWAL reads records until the end of the log or the first invalid
+// record, returning the offset just past the last valid one.
+func scanWAL(r io.Reader, fn func(*WALRecord) error) (int64, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, walHeaderSize)
+	var offset int64
+
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return offset, nil
+			}
+			return offset, fmt.Errorf("%w: truncated header at %d", ErrWALCorrupt, offset)
+		}
+		length := binary.LittleEndian.Uint32(header[0:4])
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+		if length > maxWALRecordSize {
+			return offset, fmt.Errorf("%w: record length %d at %d", ErrWALCorrupt, length, offset)
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return offset, fmt.Errorf("%w: truncated record at %d", ErrWALCorrupt, offset)
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			return offset, fmt.Errorf("%w: checksum mismatch at %d", ErrWALCorrupt, offset)
+		}
+
+		var rec WALRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return offset, fmt.Errorf("%w: %v at %d", ErrWALCorrupt, err, offset)
+		}
+		if err := fn(&rec); err != nil {
+			return offset, err
+		}
+		offset += int64(walHeaderSize) + int64(length)
+	}
+}
+
+// Append assigns the next LSN to a record and writes it. The record is
+// durable once the next sync completes. On error the mutation must not be
+// applied: a record written before the error is aborted.
+func (w *WriteAheadLog) Append(rec *WALRecord) (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrWALClosed
+	}
+
+	rec.LSN = w.lastLSN + 1
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if err := w.writeRecord(rec); err != nil {
+		return 0, err
+	}
+	w.lastLSN = rec.LSN
+	w.stats.Appends++
+
+	if w.config.SyncInterval <= 0 {
+		if err := w.syncLocked(); err != nil {
+			w.abortLocked(rec.LSN)
+			return 0, err
+		}
+	}
+	return rec.LSN, nil
+}
+
+// abortLocked appends an abort record for lsn, logging rather than
+// returning a failure to write it. Caller must hold w.mu.
+func (w *WriteAheadLog) abortLocked(lsn uint64) {
+	abort := &WALRecord{LSN: w.lastLSN + 1, Op: WALAbort, Time: time.Now(), Aborts: lsn}
+	if err := w.writeRecord(abort); err != nil {
+		log.Printf("Write-ahead log abort of LSN %d failed: %v", lsn, err)
+		return
+	}
+	w.lastLSN = abort.LSN
+}
+
+// abortedLSNs returns the LSNs cancelled by abort records in r.
+func abortedLSNs(r io.Reader) (map[uint64]bool, error) {
+	aborted := make(map[uint64]bool)
+	_, err := scanWAL(r, func(rec *WALRecord) error {
+		if rec.Op == WALAbort {
+			aborted[rec.Aborts] = true
+		}
+		return nil
+	})
+	return aborted, err
+}
+
+// writeRecord frames and buffers one record. Caller must hold w.mu.
+func (w *WriteAheadLog) writeRecord(rec *WALRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+
+	header := make([]byte, walHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	if _, err := w.writer.Write(header); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if _, err := w.writer.Write(payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	w.dirty = true
+	w.stats.BytesWritten += int64(walHeaderSize + len(payload))
+	return nil
+}
+
+// Sync flushes buffered records and fsyncs the file.
+func (w *WriteAheadLog) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrWALClosed
+	}
+	return w.syncLocked()
+}
+
+// syncLocked flushes and fsyncs if records are pending. Caller must hold w.mu.
+func (w *WriteAheadLog) syncLocked() error {
+	if !w.dirty {
+		return nil
+	}
+	if err := w.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	w.dirty = false
+	w.stats.Syncs++
+	return nil
+}
+
+// flushLoop syncs pending records every SyncInterval until Close.
+func (w *WriteAheadLog) flushLoop() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if !w.closed {
+				if err := w.syncLocked(); err != nil {
+					log.Printf("Write-ahead log sync failed: %v", err)
+				}
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Replay calls apply for every record with an LSN above afterLSN, in order.
+// Checkpoint markers and aborted records are skipped.
+func (w *WriteAheadLog) Replay(afterLSN uint64, apply func(*WALRecord) error) (int, error) {
+	if err := w.Sync(); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(w.path)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+	}
+	defer file.Close()
+
+	aborted, err := abortedLSNs(file)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrLoadFailed, err)
+	}
+
+	applied := 0
+	_, err = scanWAL(file, func(rec *WALRecord) error {
+		if rec.LSN <= afterLSN || rec.Op == WALCheckpoint || rec.Op == WALAbort || aborted[rec.LSN] {
+			return nil
+		}
+		if err := apply(rec); err != nil {
+			return fmt.Errorf("replaying %s at LSN %d: %w", rec.Op, rec.LSN, err)
+		}
+		applied++
+		return nil
+	})
+	return applied, err
+}
+
+// Checkpoint drops records up to and including lsn, which must be covered
+// by a stored snapshot. The log is rewritten to a temporary file and
+// renamed over the original, so a crash leaves either the old or new log.
+// Aborted records are dropped too.
+func (w *WriteAheadLog) Checkpoint(lsn uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrWALClosed
+	}
+	if err := w.syncLocked(); err != nil {
+		return err
+	}
+
+	source, err := os.Open(w.path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	defer source.Close()
+
+	aborted, err := abortedLSNs(source)
+	if err != nil {
+		return err
+	}
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+
+	tmpPath := w.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+
+	// Swap the writer to the new file while copying the retained tail
+	oldFile, oldWriter := w.file, w.writer
+	w.file, w.writer = tmp, bufio.NewWriter(tmp)
+	restore := func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+		w.file, w.writer = oldFile, oldWriter
+	}
+
+	// The marker keeps the LSN sequence going when every record is dropped
+	if err := w.writeRecord(&WALRecord{LSN: lsn, Op: WALCheckpoint, Time: time.Now()}); err != nil {
+		restore()
+		return err
+	}
+	_, err = scanWAL(source, func(rec *WALRecord) error {
+		// Abort records are kept as they may hold the last LSN
+		if rec.LSN <= lsn || rec.Op == WALCheckpoint || aborted[rec.LSN] {
+			return nil
+		}
+		return w.writeRecord(rec)
+	})
+	if err != nil {
+		restore()
+		return err
+	}
+	if err := w.syncLocked(); err != nil {
+		restore()
+		return err
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		restore()
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	oldFile.Close()
+
+	// The rename is durable once the directory entry is
+	if err := syncDir(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("%w: %v", ErrPersistenceFailed, err)
+	}
+	return nil
+}
+
+// syncDir fsyncs a directory, persisting the entries renamed into it.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// LastLSN returns the sequence number of the last appended record.
+func (w *WriteAheadLog) LastLSN() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastLSN
+}
+
+// GetStats returns a copy of the log statistics.
+func (w *WriteAheadLog) GetStats() WALStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Close syncs pending records and closes the log.
+func (w *WriteAheadLog) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	err := w.syncLocked()
+	w.closed = true
+	close(w.stopCh)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	if closeErr := w.file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("%w: %v", ErrPersistenceFailed, closeErr)
+	}
+	return err
+}
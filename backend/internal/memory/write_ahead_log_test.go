@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Write-Ahead Log Tests
+// ============================================================================
+
+// openTestWAL opens a log that fsyncs every append in a temp directory.
+func openTestWAL(t *testing.T) (*WriteAheadLog, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wal")
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	return wal, path
+}
+
+func TestWriteAheadLog_AppendReplay(t *testing.T) {
+	wal, path := openTestWAL(t)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := wal.Append(&WALRecord{Op: WALNodeRemove, ID: id}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	if wal.LastLSN() != 3 {
+		t.Errorf("Expected LSN 3, got %d", wal.LastLSN())
+	}
+	wal.Close()
+
+	// Reopening continues the sequence
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	if wal.LastLSN() != 3 {
+		t.Errorf("Expected LSN 3 after reopen, got %d", wal.LastLSN())
+	}
+
+	ids := make([]string, 0)
+	applied, err := wal.Replay(1, func(rec *WALRecord) error {
+		ids = append(ids, rec.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if applied != 2 || len(ids) != 2 || ids[0] != "b" || ids[1] != "c" {
+		t.Errorf("Expected b and c after LSN 1, got %v", ids)
+	}
+}
+
+func TestWriteAheadLog_TornRecord(t *testing.T) {
+	wal, path := openTestWAL(t)
+	wal.Append(&WALRecord{Op: WALNodeRemove, ID: "a"})
+	wal.Append(&WALRecord{Op: WALNodeRemove, ID: "b"})
+	wal.Close()
+
+	// Simulate a crash in the middle of writing the last record
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	if wal.LastLSN() != 1 {
+		t.Errorf("Expected torn record to be dropped, got LSN %d", wal.LastLSN())
+	}
+	if wal.GetStats().TornRecords != 1 {
+		t.Errorf("Expected 1 torn record, got %d", wal.GetStats().TornRecords)
+	}
+
+	// New records follow the last intact one
+	lsn, err := wal.Append(&WALRecord{Op: WALNodeRemove, ID: "c"})
+	if err != nil || lsn != 2 {
+		t.Errorf("Expected LSN 2, got %d (%v)", lsn, err)
+	}
+	applied, err := wal.Replay(0, func(*WALRecord) error { return nil })
+	if err != nil || applied != 2 {
+		t.Errorf("Expected 2 records, got %d (%v)", applied, err)
+	}
+}
+
+func TestWriteAheadLog_Checkpoint(t *testing.T) {
+	wal, path := openTestWAL(t)
+	for _, id := range []string{"a", "b", "c"} {
+		wal.Append(&WALRecord{Op: WALNodeRemove, ID: id})
+	}
+
+	if err := wal.Checkpoint(2); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	wal.Append(&WALRecord{Op: WALNodeRemove, ID: "d"})
+	wal.Close()
+
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+
+	ids := make([]string, 0)
+	wal.Replay(0, func(rec *WALRecord) error {
+		ids = append(ids, rec.ID)
+		return nil
+	})
+	if len(ids) != 2 || ids[0] != "c" || ids[1] != "d" {
+		t.Errorf("Expected c and d after checkpoint, got %v", ids)
+	}
+
+	// A checkpoint covering everything keeps the LSN sequence
+	if err := wal.Checkpoint(wal.LastLSN()); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	wal.Close()
+	wal, _ = OpenWAL(path, WALConfig{})
+	defer wal.Close()
+	if wal.LastLSN() != 4 {
+		t.Errorf("Expected LSN 4 after full checkpoint, got %d", wal.LastLSN())
+	}
+}
+
+func TestWriteAheadLog_Abort(t *testing.T) {
+	wal, path := openTestWAL(t)
+	for _, id := range []string{"a", "b", "c"} {
+		wal.Append(&WALRecord{Op: WALNodeRemove, ID: id})
+	}
+
+	// As Append does when the sync after writing a record fails
+	wal.mu.Lock()
+	wal.abortLocked(2)
+	wal.mu.Unlock()
+	if wal.LastLSN() != 4 {
+		t.Errorf("Expected the abort record at LSN 4, got %d", wal.LastLSN())
+	}
+
+	replayed := func() []string {
+		ids := make([]string, 0)
+		if _, err := wal.Replay(0, func(rec *WALRecord) error {
+			ids = append(ids, rec.ID)
+			return nil
+		}); err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		return ids
+	}
+	if ids := replayed(); len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Errorf("Expected a and c without the aborted b, got %v", ids)
+	}
+
+	// Checkpoints drop the aborted record but keep the sequence
+	if err := wal.Checkpoint(1); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	wal.Close()
+	wal, err := OpenWAL(path, WALConfig{})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+	defer wal.Close()
+	if wal.LastLSN() != 4 {
+		t.Errorf("Expected LSN 4 after checkpoint, got %d", wal.LastLSN())
+	}
+	if ids := replayed(); len(ids) != 1 || ids[0] != "c" {
+		t.Errorf("Expected only c after checkpoint, got %v", ids)
+	}
+}
+
+func TestWriteAheadLog_GroupCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "group.wal")
+	wal, err := OpenWAL(path, WALConfig{SyncInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("OpenWAL failed: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		wal.Append(&WALRecord{Op: WALNodeRemove, ID: "x"})
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	stats := wal.GetStats()
+	if stats.Appends != 100 {
+		t.Errorf("Expected 100 appends, got %d", stats.Appends)
+	}
+	if stats.Syncs == 0 || stats.Syncs >= 100 {
+		t.Errorf("Expected batched syncs, got %d", stats.Syncs)
+	}
+
+	wal.Close()
+	if _, err := wal.Append(&WALRecord{Op: WALNodeRemove}); !errors.Is(err, ErrWALClosed) {
+		t.Errorf("Expected ErrWALClosed, got %v", err)
+	}
+}
+
+func TestWALValue_RoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"name":     "go",
+		"tier":     3,
+		"score":    0.75,
+		"enabled":  true,
+		"latency":  NumberValue(120, "ms"),
+		"timeout":  2 * time.Second,
+		"keywords": []string{"a", "b"},
+	}
+	encoded, err := encodeWALValues(values)
+	if err != nil {
+		t.Fatalf("encodeWALValues failed: %v", err)
+	}
+	decoded, err := decodeWALValues(encoded)
+	if err != nil {
+		t.Fatalf("decodeWALValues failed: %v", err)
+	}
+
+	if decoded["tier"] != 3 {
+		t.Errorf("Expected int 3, got %T %v", decoded["tier"], decoded["tier"])
+	}
+	if decoded["timeout"] != 2*time.Second {
+		t.Errorf("Expected 2s duration, got %v", decoded["timeout"])
+	}
+	if latency, ok := decoded["latency"].(PropertyValue); !ok || !latency.Equal(NumberValue(120, "ms")) {
+		t.Errorf("Expected typed 120ms, got %v", decoded["latency"])
+	}
+	if !valuesEqual(decoded["keywords"], values["keywords"]) {
+		t.Errorf("Expected keywords preserved, got %v", decoded["keywords"])
+	}
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Memory subsystem metrics, one per field of the subsystem's stats.
+var (
+	semanticNodesCreated       = newDesc("semantic", "nodes_created_total", "Nodes added to the semantic network.")
+	semanticRelationsCreated   = newDesc("semantic", "relations_created_total", "Relations added to the semantic network.")
+	semanticActivationQueries  = newDesc("semantic", "activation_queries_total", "Spreading activation queries run.")
+	semanticInheritanceQueries = newDesc("semantic", "inheritance_queries_total", "Inheritance queries run.")
+	semanticSpreadingCycles    = newDesc("semantic", "spreading_cycles_total", "Spreading activation cycles run.")
+	semanticConceptsLearned    = newDesc("semantic", "concepts_learned_total", "Concepts learned from experience.")
+	semanticNodesCopiedOnWrite = newDesc("semantic", "nodes_copied_on_write_total", "Nodes copied because a snapshot shared them.")
+
+	productionProductions       = newDesc("production", "productions", "Productions in the production system.")
+	productionFirings           = newDesc("production", "firings_total", "Productions fired.")
+	productionSuccessfulFirings = newDesc("production", "successful_firings_total", "Productions fired whose actions succeeded.")
+	productionConflictsResolved = newDesc("production", "conflicts_resolved_total", "Conflicts between matching productions resolved.")
+	productionLearned           = newDesc("production", "productions_learned_total", "Productions learned by chunking.")
+	productionCycleSeconds      = newDesc("production", "average_cycle_seconds", "Average duration of a match-fire cycle.")
+
+	attentionItemsFocused = newDesc("attention", "items_focused_total", "Items brought into focus.")
+	attentionItemsEvicted = newDesc("attention", "items_evicted_total", "Items evicted from focus.")
+	attentionInterrupts   = newDesc("attention", "interrupts_total", "Interrupts focused.")
+	attentionOverloads    = newDesc("attention", "overloads_total", "Times attention was overloaded.")
+	attentionAverageLoad  = newDesc("attention", "average_load_percent", "Average attention load, in percent of capacity.")
+	attentionPeakLoad     = newDesc("attention", "peak_load", "Highest attention load reached.")
+	attentionFocusGained  = newDesc("attention", "focus_gained_total", "Times an item gained focus.")
+	attentionFocusLost    = newDesc("attention", "focus_lost_total", "Times an item lost focus.")
+
+	impasseDetected     = newDesc("impasse", "detected_total", "Impasses detected.")
+	impasseResolved     = newDesc("impasse", "resolved_total", "Impasses resolved.")
+	impasseFailed       = newDesc("impasse", "failed_total", "Impasses that could not be resolved.")
+	impasseByType       = newDesc("impasse", "detected_by_type_total", "Impasses detected, by type.", "type")
+	impasseByResolution = newDesc("impasse", "resolved_by_strategy_total", "Impasses resolved, by strategy.", "strategy")
+
+	worldModelSimulations            = newDesc("world_model", "simulations_total", "Simulations run.")
+	worldModelStepsSimulated         = newDesc("world_model", "steps_simulated_total", "Steps simulated.")
+	worldModelTrajectories           = newDesc("world_model", "trajectories_total", "Trajectories simulated.")
+	worldModelSuccessfulTrajectories = newDesc("world_model", "successful_trajectories_total", "Trajectories that reached their goal.")
+	worldModelAverageDepth           = newDesc("world_model", "average_depth", "Average depth of a simulation.")
+	worldModelAverageSuccess         = newDesc("world_model", "average_success_ratio", "Share of trajectories that reached their goal.")
+)
+
+// memoryCollector reports the stats of the memory subsystems, read when
+// scraped so the subsystems need not know about metrics.
+type memoryCollector struct {
+	sources Sources
+}
+
+func newMemoryCollector(sources Sources) *memoryCollector {
+	return &memoryCollector{sources: sources}
+}
+
+// Describe sends the descriptors of the metrics Collect sends, which
+// depend on the sources set.
+func (c *memoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+// Collect reads the stats of each subsystem and sends them.
+func (c *memoryCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.sources.Semantic != nil {
+		stats := c.sources.Semantic()
+		counter(ch, semanticNodesCreated, stats.NodesCreated)
+		counter(ch, semanticRelationsCreated, stats.RelationsCreated)
+		counter(ch, semanticActivationQueries, stats.ActivationQueries)
+		counter(ch, semanticInheritanceQueries, stats.InheritanceQueries)
+		counter(ch, semanticSpreadingCycles, stats.SpreadingCycles)
+		counter(ch, semanticConceptsLearned, stats.ConceptsLearned)
+		counter(ch, semanticNodesCopiedOnWrite, stats.NodesCopiedOnWrite)
+	}
+	if c.sources.Production != nil {
+		stats := c.sources.Production()
+		gauge(ch, productionProductions, float64(stats.TotalProductions))
+		counter(ch, productionFirings, stats.TotalFirings)
+		counter(ch, productionSuccessfulFirings, stats.SuccessfulFirings)
+		counter(ch, productionConflictsResolved, stats.ConflictsResolved)
+		counter(ch, productionLearned, stats.ProductionsLearned)
+		gauge(ch, productionCycleSeconds, stats.AverageCycleTime.Seconds())
+	}
+	if c.sources.Attention != nil {
+		stats := c.sources.Attention()
+		counter(ch, attentionItemsFocused, stats.TotalItemsFocused)
+		counter(ch, attentionItemsEvicted, stats.TotalItemsEvicted)
+		counter(ch, attentionInterrupts, stats.TotalInterrupts)
+		counter(ch, attentionOverloads, stats.TotalOverloads)
+		gauge(ch, attentionAverageLoad, stats.AverageLoadPercent)
+		gauge(ch, attentionPeakLoad, stats.PeakLoad)
+		counter(ch, attentionFocusGained, stats.FocusGainedCount)
+		counter(ch, attentionFocusLost, stats.FocusLostCount)
+	}
+	if c.sources.Impasse != nil {
+		stats := c.sources.Impasse()
+		counter(ch, impasseDetected, stats.TotalDetected)
+		counter(ch, impasseResolved, stats.TotalResolved)
+		counter(ch, impasseFailed, stats.TotalFailed)
+		for impasseType, n := range stats.ByType {
+			counter(ch, impasseByType, n, impasseType.String())
+		}
+		for strategy, n := range stats.ByResolution {
+			counter(ch, impasseByResolution, n, strategy.String())
+		}
+	}
+	if c.sources.WorldModel != nil {
+		stats := c.sources.WorldModel()
+		counter(ch, worldModelSimulations, stats.TotalSimulations)
+		counter(ch, worldModelStepsSimulated, stats.TotalStepsSimulated)
+		counter(ch, worldModelTrajectories, stats.TotalTrajectories)
+		counter(ch, worldModelSuccessfulTrajectories, stats.SuccessfulTrajectories)
+		gauge(ch, worldModelAverageDepth, stats.AverageDepth)
+		gauge(ch, worldModelAverageSuccess, stats.AverageSuccess)
+	}
+}
+
+// newDesc describes a metric of a memory subsystem.
+func newDesc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+}
+
+// counter sends the value of a counter.
+func counter(ch chan<- prometheus.Metric, desc *prometheus.Desc, value int64, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labels...)
+}
+
+// gauge sends the value of a gauge.
+func gauge(ch chan<- prometheus.Metric, desc *prometheus.Desc, value float64, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+}
@@ -0,0 +1,124 @@
+// Package metrics exposes the server's metrics in the Prometheus text
+// format: the counters and gauges of the memory subsystems, read from their
+// stats when scraped, the latency of agent invocations and of assembling
+// their context, the hedging of language model calls, and the Go
+// runtime's and process's own metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// namespace prefixes every metric the server exports.
+const namespace = "eac"
+
+// InvocationBuckets are the bounds, in seconds, of the invocation latency
+// histogram. Agents answering through a language model take seconds, so
+// the buckets reach a minute.
+var InvocationBuckets = []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// ContextBuckets are the bounds, in seconds, of the histogram of the time
+// invocations take to get their agent's context. A pooled context takes
+// microseconds and one assembled from the knowledge graph milliseconds.
+var ContextBuckets = []float64{0.00001, 0.0001, 0.001, 0.005, 0.025, 0.1, 0.5}
+
+// Sources read the stats of the memory subsystems the server runs. A nil
+// source is not reported.
+type Sources struct {
+	Semantic   func() *memory.SemanticNetworkStats
+	Production func() *memory.ProductionStats
+	Attention  func() memory.AttentionStats
+	Impasse    func() *memory.ImpasseStats
+	WorldModel func() memory.WorldModelStats
+}
+
+// Metrics is the server's metrics registry.
+type Metrics struct {
+	registry    *prometheus.Registry
+	invocations *prometheus.HistogramVec
+	contexts    *prometheus.HistogramVec
+	hedges      *prometheus.CounterVec
+	handler     http.Handler
+}
+
+// New creates the metrics of a server. The memory subsystems' metrics are
+// added with RegisterMemory once they are created.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		invocations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "agent",
+			Name:      "request_duration_seconds",
+			Help:      "Time agents took to answer requests, by agent codename, route and outcome.",
+			Buckets:   InvocationBuckets,
+		}, []string{"agent", "route", "outcome"}),
+		contexts: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "agent",
+			Name:      "context_duration_seconds",
+			Help:      "Time invocations took to get their agent's context, by whether the warm pool held it.",
+			Buckets:   ContextBuckets,
+		}, []string{"pool"}),
+		hedges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "llm",
+			Name:      "hedges_total",
+			Help:      "Language model calls due a hedge, by outcome: primary, fallback, failed or capped.",
+		}, []string{"outcome"}),
+	}
+	m.registry.MustRegister(
+		m.invocations,
+		m.contexts,
+		m.hedges,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	m.handler = promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	return m
+}
+
+// RegisterMemory adds the metrics of the memory subsystems read through
+// sources. It must be called once.
+func (m *Metrics) RegisterMemory(sources Sources) {
+	m.registry.MustRegister(newMemoryCollector(sources))
+}
+
+// ObserveInvocation records how long an agent took to answer a request on
+// a route, and whether it succeeded.
+func (m *Metrics) ObserveInvocation(agent, route string, success bool, duration time.Duration) {
+	outcome := "success"
+	if !success {
+		outcome = "error"
+	}
+	m.invocations.WithLabelValues(agent, route, outcome).Observe(duration.Seconds())
+}
+
+// ObserveContext records how long an invocation took to get its agent's
+// context, and whether it was warm in the pool.
+func (m *Metrics) ObserveContext(warm bool, duration time.Duration) {
+	pool := "cold"
+	if warm {
+		pool = "warm"
+	}
+	m.contexts.WithLabelValues(pool).Observe(duration.Seconds())
+}
+
+// ObserveHedge counts a language model call that was due a hedge by its
+// outcome.
+func (m *Metrics) ObserveHedge(outcome string) {
+	m.hedges.WithLabelValues(outcome).Inc()
+}
+
+// ServeHTTP handles GET /metrics - returns the metrics in the Prometheus
+// text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
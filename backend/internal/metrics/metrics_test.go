@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// scrape returns the metrics as Prometheus would read them.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func TestMetrics_Memory(t *testing.T) {
+	network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	network.AddNode(memory.NewSemanticNode("sorting", "Sorting", memory.ConceptNode))
+	impasses := memory.NewImpasseDetector(nil, nil)
+	impasses.DetectFailure("goal-1", "APEX", "timed out")
+
+	m := New()
+	m.RegisterMemory(Sources{
+		Semantic: network.GetStats,
+		Impasse:  impasses.GetStats,
+		WorldModel: func() memory.WorldModelStats {
+			return memory.WorldModelStats{TotalSimulations: 4, AverageSuccess: 0.25}
+		},
+	})
+	body := scrape(t, m)
+	for _, want := range []string{
+		"# TYPE eac_semantic_nodes_created_total counter",
+		"eac_semantic_nodes_created_total 1",
+		"eac_impasse_detected_total 1",
+		`eac_impasse_detected_by_type_total{type="FAILURE"} 1`,
+		"# TYPE eac_world_model_average_success_ratio gauge",
+		"eac_world_model_simulations_total 4",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "eac_production_") || strings.Contains(body, "eac_attention_") {
+		t.Error("expected subsystems without a source left out")
+	}
+}
+
+func TestMetrics_ObserveInvocation(t *testing.T) {
+	m := New()
+	m.ObserveInvocation("APEX", "mention", true, 300*time.Millisecond)
+	m.ObserveInvocation("APEX", "mention", false, 2*time.Second)
+	body := scrape(t, m)
+	for _, want := range []string{
+		`eac_agent_request_duration_seconds_bucket{agent="APEX",outcome="success",route="mention",le="0.5"} 1`,
+		`eac_agent_request_duration_seconds_count{agent="APEX",outcome="error",route="mention"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_ObserveContext(t *testing.T) {
+	m := New()
+	m.ObserveContext(true, 5*time.Microsecond)
+	m.ObserveContext(false, 3*time.Millisecond)
+	body := scrape(t, m)
+	for _, want := range []string{
+		`eac_agent_context_duration_seconds_bucket{pool="warm",le="1e-05"} 1`,
+		`eac_agent_context_duration_seconds_bucket{pool="cold",le="0.001"} 0`,
+		`eac_agent_context_duration_seconds_count{pool="cold"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_ObserveHedge(t *testing.T) {
+	m := New()
+	m.ObserveHedge("fallback")
+	m.ObserveHedge("fallback")
+	m.ObserveHedge("capped")
+	body := scrape(t, m)
+	for _, want := range []string{
+		`eac_llm_hedges_total{outcome="fallback"} 2`,
+		`eac_llm_hedges_total{outcome="capped"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+}
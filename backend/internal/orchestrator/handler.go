@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// maxRequestBytes bounds an orchestration request.
+const maxRequestBytes = 1 << 20
+
+// ServeOrchestrate handles POST /orchestrate - picks a team for the task
+// in the request body, runs it and returns the team's answer with every
+// step's result. A run still going when the request times out is answered
+// with 202 Accepted and goes on in the background. Retries with the same
+// Idempotency-Key header get the run the first request started.
+func (o *Orchestrator) ServeOrchestrate(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errdefs.WriteError(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	result, err := o.Run(r.Context(), &req, r.Header.Get("Idempotency-Key"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	if result.Run.Status == agents.StepRunning {
+		w.Header().Set("Location", "/orchestrate/runs/"+result.Run.ID)
+		errdefs.WriteJSON(w, http.StatusAccepted, result)
+		return
+	}
+	log.Printf("Team of %d led by %s run %s %s in %dms", len(result.Team), result.Lead, result.Run.ID, result.Run.Status, result.Run.DurationMs)
+	errdefs.WriteJSON(w, http.StatusOK, result)
+}
+
+// ServeRun handles GET /orchestrate/runs/{id} - returns a team's run.
+func (o *Orchestrator) ServeRun(w http.ResponseWriter, r *http.Request) {
+	run, err := o.GetRun(chi.URLParam(r, "id"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+)
+
+func TestServeOrchestrate(t *testing.T) {
+	o, _ := newTestOrchestrator("ARCHITECT", "APEX", "OMNISCIENT")
+	router := chi.NewRouter()
+	router.Post("/orchestrate", o.ServeOrchestrate)
+	router.Get("/orchestrate/runs/{id}", o.ServeRun)
+
+	req := httptest.NewRequest(http.MethodPost, "/orchestrate", strings.NewReader(`{"task": "Design the system", "team_size": 2}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result Result
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Lead != "ARCHITECT" || len(result.Team) != 2 || result.Run.Status != agents.StepSucceeded {
+		t.Errorf("expected a team of two led by ARCHITECT, got %+v", result)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orchestrate/runs/"+result.Run.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for the run, got %d", w.Code)
+	}
+
+	for body, status := range map[string]int{
+		`{"task": ""}`: http.StatusBadRequest,
+		`not json`:     http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orchestrate", strings.NewReader(body)))
+		if w.Code != status {
+			t.Errorf("expected %d for %s, got %d", status, body, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orchestrate/runs/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown run, got %d", w.Code)
+	}
+}
@@ -0,0 +1,298 @@
+// Package orchestrator answers a task with a team of agents. The task is
+// routed to the agents paying it the most attention, a team is grown
+// around the lead from their collaboration affinity, and the team runs as
+// a workflow: the lead frames the task, the others contribute in
+// parallel, and the lead combines their contributions into one answer.
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// ErrInvalidTask is returned for tasks that can't be orchestrated.
+var ErrInvalidTask = errdefs.New(errdefs.ErrInvalidArgument, "invalid task")
+
+const (
+	// DefaultTeamSize is the size of a team when none is requested
+	DefaultTeamSize = 3
+	// MaxTeamSize bounds the size of a team, lead included
+	MaxTeamSize = 6
+	// workflowName names the runs of orchestrated teams
+	workflowName = "orchestrate"
+	// poolFactor is how many more agents than needed the affinity walk
+	// suggests, so the team can be picked by attention from among them
+	poolFactor = 2
+)
+
+// Roles of team members.
+const (
+	RoleLead        = "lead"
+	RoleContributor = "contributor"
+)
+
+// Step IDs of an orchestrated run; contributions are the members'
+// lowercase codenames.
+const (
+	StepFrame         = "frame"
+	StepContributions = "contributions"
+	StepSynthesis     = "synthesis"
+	StepAnswer        = "answer"
+)
+
+// Config tunes team selection.
+type Config struct {
+	// TeamSize is the size of a team when a request names none
+	TeamSize int
+	// FallbackLead leads tasks no agent pays attention to
+	FallbackLead string
+}
+
+// DefaultConfig returns teams of three, led by OMNISCIENT when the task
+// matches no agent's attention.
+func DefaultConfig() Config {
+	return Config{TeamSize: DefaultTeamSize, FallbackLead: "OMNISCIENT"}
+}
+
+// Request is a task for a team.
+type Request struct {
+	Task string `json:"task"`
+	// TeamSize is how many agents answer, lead included
+	TeamSize int `json:"team_size,omitempty"`
+	// Lead is the agent the team forms around; empty routes the task
+	Lead string `json:"lead,omitempty"`
+}
+
+// Member is an agent on a team.
+type Member struct {
+	Agent string `json:"agent"`
+	Role  string `json:"role"`
+	// Attention is what the task's routing paid the agent
+	Attention float64 `json:"attention"`
+	// Affinity is how well the agent has worked with the lead
+	Affinity float64 `json:"affinity"`
+}
+
+// Plan is the team picked for a task and the workflow it runs.
+type Plan struct {
+	Lead     string                     `json:"lead"`
+	Team     []Member                   `json:"team"`
+	Workflow *agents.WorkflowDefinition `json:"workflow"`
+}
+
+// Result is a team's answer to a task.
+type Result struct {
+	Lead string   `json:"lead"`
+	Team []Member `json:"team"`
+	// Output is the team's answer: the lead's synthesis of the
+	// contributions
+	Output string              `json:"output"`
+	Run    *agents.WorkflowRun `json:"run"`
+}
+
+// Orchestrator picks teams for tasks and runs them.
+type Orchestrator struct {
+	registry  *agents.Registry
+	engine    *agents.WorkflowEngine
+	attention *memory.CollaborativeAttentionIndex
+	affinity  *memory.AgentAffinityGraph
+	config    Config
+}
+
+// New creates an orchestrator running a registry's agents, keeping run
+// state in memory.
+func New(registry *agents.Registry, attention *memory.CollaborativeAttentionIndex, affinity *memory.AgentAffinityGraph, config Config) *Orchestrator {
+	if config.TeamSize <= 0 {
+		config.TeamSize = DefaultConfig().TeamSize
+	}
+	if config.FallbackLead == "" {
+		config.FallbackLead = DefaultConfig().FallbackLead
+	}
+	return &Orchestrator{
+		registry:  registry,
+		engine:    agents.NewWorkflowEngine(registry),
+		attention: attention,
+		affinity:  affinity,
+		config:    config,
+	}
+}
+
+// Plan picks a team for a task and builds the workflow it runs. The lead
+// is the request's, or the registered agent the task's routing pays the
+// most attention; the rest are drawn from a random walk over the lead's
+// collaboration affinity, those paid the most attention first, and then
+// from the other routed agents.
+func (o *Orchestrator) Plan(req *Request) (*Plan, error) {
+	task := strings.TrimSpace(req.Task)
+	if task == "" {
+		return nil, fmt.Errorf("%w: task is required", ErrInvalidTask)
+	}
+	size := req.TeamSize
+	if size == 0 {
+		size = o.config.TeamSize
+	}
+	if size < 1 || size > MaxTeamSize {
+		return nil, fmt.Errorf("%w: team_size must be from 1 to %d", ErrInvalidTask, MaxTeamSize)
+	}
+
+	attention := make(map[string]float64)
+	var routed []string
+	for _, candidate := range o.attention.RouteQuery(task, MaxTeamSize*poolFactor) {
+		attention[candidate.AgentID] = candidate.Attention
+		routed = append(routed, candidate.AgentID)
+	}
+	lead, err := o.lead(req.Lead, routed)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{lead: true}
+	var pool []string
+	for _, agent := range o.affinity.SuggestCollaborationTeam(lead, size*poolFactor) {
+		if !seen[agent] && o.registered(agent) {
+			seen[agent] = true
+			pool = append(pool, agent)
+		}
+	}
+	sort.SliceStable(pool, func(i, j int) bool {
+		if attention[pool[i]] != attention[pool[j]] {
+			return attention[pool[i]] > attention[pool[j]]
+		}
+		return o.affinity.GetAffinityScore(lead, pool[i]) > o.affinity.GetAffinityScore(lead, pool[j])
+	})
+	// The walk can land on agents that are not registered; routed agents
+	// fill the places left
+	for _, agent := range routed {
+		if !seen[agent] && o.registered(agent) {
+			seen[agent] = true
+			pool = append(pool, agent)
+		}
+	}
+	if len(pool) > size-1 {
+		pool = pool[:size-1]
+	}
+
+	plan := &Plan{Lead: lead, Team: []Member{{Agent: lead, Role: RoleLead, Attention: attention[lead]}}}
+	for _, agent := range pool {
+		plan.Team = append(plan.Team, Member{
+			Agent:     agent,
+			Role:      RoleContributor,
+			Attention: attention[agent],
+			Affinity:  o.affinity.GetAffinityScore(lead, agent),
+		})
+	}
+	plan.Workflow = teamWorkflow(lead, pool)
+	return plan, nil
+}
+
+// Run plans a task and runs the team, waiting until it finishes or ctx is
+// done. A run still going when ctx is done goes on in the background; the
+// result then has status running. A non-empty key makes the call
+// idempotent, as with workflow runs. A retry gets the run the first
+// request started; the team it reports is planned again, so the run's
+// steps are what name the agents that ran.
+func (o *Orchestrator) Run(ctx context.Context, req *Request, key string) (*Result, error) {
+	plan, err := o.Plan(req)
+	if err != nil {
+		return nil, err
+	}
+	run, err := o.engine.RunDefinition(ctx, plan.Workflow, map[string]string{"task": strings.TrimSpace(req.Task)}, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Lead: plan.Lead, Team: plan.Team, Output: run.Output, Run: run}, nil
+}
+
+// GetRun returns the state of a team's run.
+func (o *Orchestrator) GetRun(id string) (*agents.WorkflowRun, error) {
+	return o.engine.GetRun(id)
+}
+
+// lead returns the requested lead's codename, or the first registered
+// routed agent, or the fallback lead.
+func (o *Orchestrator) lead(requested string, routed []string) (string, error) {
+	if requested != "" {
+		_, res, err := o.registry.Resolve(strings.ToUpper(requested))
+		if err != nil {
+			return "", fmt.Errorf("%w: lead: %w", ErrInvalidTask, err)
+		}
+		return res.Codename, nil
+	}
+	for _, agent := range routed {
+		if o.registered(agent) {
+			return agent, nil
+		}
+	}
+	if !o.registered(o.config.FallbackLead) {
+		return "", fmt.Errorf("%w: no agent can lead the task; name a lead", ErrInvalidTask)
+	}
+	return o.config.FallbackLead, nil
+}
+
+// registered reports whether an agent can be called.
+func (o *Orchestrator) registered(agent string) bool {
+	_, _, err := o.registry.Resolve(agent)
+	return err == nil
+}
+
+// teamWorkflow builds the workflow of a team: a lead alone answers the
+// task; otherwise the lead frames it, the contributors answer in parallel,
+// and the lead combines their answers. A contributor failing leaves the
+// others' contributions to combine.
+func teamWorkflow(lead string, contributors []string) *agents.WorkflowDefinition {
+	def := &agents.WorkflowDefinition{
+		Name:        workflowName,
+		Description: "A team led by " + lead,
+		Inputs:      []agents.WorkflowInput{{Name: "task", Required: true}},
+	}
+	if len(contributors) == 0 {
+		def.Steps = []agents.WorkflowStep{{ID: StepAnswer, Agent: lead, Prompt: "{{inputs.task}}"}}
+		return def
+	}
+
+	names := strings.Join(contributors, ", ")
+	frame := agents.WorkflowStep{
+		ID:    StepFrame,
+		Agent: lead,
+		Prompt: fmt.Sprintf(`You are leading %s on this task. Break it down into the parts each of them should answer from their expertise, and say what a complete answer has to cover.
+
+Task:
+{{inputs.task}}`, names),
+	}
+	group := agents.WorkflowStep{ID: StepContributions}
+	var contributions strings.Builder
+	for _, agent := range contributors {
+		id := strings.ToLower(agent)
+		group.Parallel = append(group.Parallel, agents.WorkflowStep{
+			ID:    id,
+			Agent: agent,
+			Prompt: fmt.Sprintf(`You are contributing to a team answer led by %s. Answer the part of this task that falls to your expertise.
+
+Task:
+{{inputs.task}}
+
+%s's breakdown:
+{{steps.frame.output}}`, lead, lead),
+			ContinueOnError: true,
+		})
+		fmt.Fprintf(&contributions, "\n\n%s:\n{{steps.%s.output}}", agent, id)
+	}
+	synthesis := agents.WorkflowStep{
+		ID:    StepSynthesis,
+		Agent: lead,
+		Prompt: `Combine your team's contributions into one answer to the task. Keep what each got right, resolve where they disagree and say how, and fill any part a contribution is missing.
+
+Task:
+{{inputs.task}}
+
+Contributions:` + contributions.String(),
+	}
+	def.Steps = []agents.WorkflowStep{frame, group, synthesis}
+	return def
+}
@@ -0,0 +1,139 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/copilot"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// teamAgent answers every prompt with its codename, or fails.
+type teamAgent struct {
+	codename string
+	fail     bool
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (a *teamAgent) Handle(ctx context.Context, req *models.CopilotRequest) (*models.CopilotResponse, error) {
+	a.mu.Lock()
+	a.prompts = append(a.prompts, copilot.GetLastUserMessage(req))
+	a.mu.Unlock()
+	if a.fail {
+		return nil, errors.New(a.codename + " is unavailable")
+	}
+	return copilot.NewResponse(a.codename + " answers"), nil
+}
+
+func (a *teamAgent) GetInfo() models.Agent {
+	return models.Agent{Codename: a.codename, Tier: 1}
+}
+
+// newTestOrchestrator registers agents with the given codenames.
+func newTestOrchestrator(codenames ...string) (*Orchestrator, map[string]*teamAgent) {
+	registry := agents.NewRegistry()
+	team := make(map[string]*teamAgent)
+	for _, codename := range codenames {
+		team[codename] = &teamAgent{codename: codename}
+		registry.Register(team[codename])
+	}
+	return New(registry, memory.NewCollaborativeAttentionIndex(), memory.NewAgentAffinityGraph(), DefaultConfig()), team
+}
+
+func TestPlan(t *testing.T) {
+	o, _ := newTestOrchestrator("ARCHITECT", "APEX", "ATLAS", "FORTRESS", "OMNISCIENT")
+
+	plan, err := o.Plan(&Request{Task: "Design the system architecture for a billing service"})
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if plan.Lead != "ARCHITECT" || plan.Team[0].Agent != "ARCHITECT" || plan.Team[0].Role != RoleLead {
+		t.Errorf("expected ARCHITECT to lead an architecture task, got %+v", plan.Team)
+	}
+	if len(plan.Team) != DefaultTeamSize {
+		t.Fatalf("expected a team of %d, got %+v", DefaultTeamSize, plan.Team)
+	}
+	seen := make(map[string]bool)
+	for _, member := range plan.Team[1:] {
+		if member.Role != RoleContributor || seen[member.Agent] || member.Agent == plan.Lead {
+			t.Errorf("expected distinct contributors, got %+v", plan.Team)
+		}
+		seen[member.Agent] = true
+	}
+
+	steps := plan.Workflow.Steps
+	if len(steps) != 3 || steps[0].ID != StepFrame || steps[1].ID != StepContributions || steps[2].ID != StepSynthesis {
+		t.Fatalf("expected frame, contributions and synthesis, got %+v", steps)
+	}
+	if len(steps[1].Parallel) != DefaultTeamSize-1 || !steps[1].Parallel[0].ContinueOnError {
+		t.Errorf("expected contributors in parallel, tolerating failures, got %+v", steps[1].Parallel)
+	}
+	for _, member := range plan.Team[1:] {
+		if !strings.Contains(steps[2].Prompt, "{{steps."+strings.ToLower(member.Agent)+".output}}") {
+			t.Errorf("expected the synthesis to combine %s, got %q", member.Agent, steps[2].Prompt)
+		}
+	}
+}
+
+func TestPlanLead(t *testing.T) {
+	o, _ := newTestOrchestrator("ARCHITECT", "APEX", "OMNISCIENT")
+
+	plan, err := o.Plan(&Request{Task: "Design the system", Lead: "apex", TeamSize: 1})
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if plan.Lead != "APEX" || len(plan.Team) != 1 || len(plan.Workflow.Steps) != 1 || plan.Workflow.Steps[0].ID != StepAnswer {
+		t.Errorf("expected APEX alone to answer, got %+v", plan)
+	}
+
+	// Nothing in the task draws attention
+	plan, err = o.Plan(&Request{Task: "hello there", TeamSize: 1})
+	if err != nil || plan.Lead != "OMNISCIENT" {
+		t.Errorf("expected the fallback lead, got %+v, %v", plan, err)
+	}
+
+	for _, req := range []*Request{
+		{Task: " "},
+		{Task: "Design the system", TeamSize: MaxTeamSize + 1},
+		{Task: "Design the system", Lead: "NOBODY"},
+	} {
+		if _, err := o.Plan(req); !errors.Is(err, ErrInvalidTask) {
+			t.Errorf("expected %+v rejected, got %v", req, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	o, team := newTestOrchestrator("ARCHITECT", "APEX", "ATLAS", "OMNISCIENT")
+	team["ATLAS"].fail = true
+
+	result, err := o.Run(context.Background(), &Request{Task: "Design the system architecture", TeamSize: 4}, "")
+	if err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	if result.Run.Status != agents.StepSucceeded || result.Output != "ARCHITECT answers" {
+		t.Fatalf("expected the lead's synthesis despite a failed contributor, got %+v", result.Run)
+	}
+	prompts := team["ARCHITECT"].prompts
+	if len(prompts) != 2 || !strings.Contains(prompts[1], "APEX:\nAPEX answers") {
+		t.Errorf("expected the lead to frame and then combine the contributions, got %q", prompts)
+	}
+	if !strings.Contains(team["APEX"].prompts[0], "ARCHITECT answers") {
+		t.Errorf("expected contributors given the lead's breakdown, got %q", team["APEX"].prompts)
+	}
+
+	run, err := o.GetRun(result.Run.ID)
+	if err != nil || run.ID != result.Run.ID {
+		t.Errorf("expected the run kept, got %+v, %v", run, err)
+	}
+	if _, err := o.GetRun("missing"); !errors.Is(err, agents.ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package pqueue provides a generic priority queue, so each ordering the
+// server keeps - nearest neighbors, focus items, goals, evictions - is a
+// less function rather than another heap.Interface implementation.
+//
+// A Queue pops the item its less function orders first:
+//
+//	nearest := pqueue.New(func(a, b Candidate) bool { return a.Distance < b.Distance })
+//
+// Queues built with NewIndexed also report each item's position as it
+// moves, so callers holding items can Fix or Remove them in place.
+package pqueue
+
+import "container/heap"
+
+// Queue is a priority queue of items ordered by a less function. It is not
+// safe for concurrent use.
+type Queue[T any] struct {
+	h *items[T]
+}
+
+// New creates a queue popping the item less orders first.
+func New[T any](less func(a, b T) bool) *Queue[T] {
+	return NewIndexed(less, nil)
+}
+
+// NewIndexed creates a queue popping the item less orders first and
+// calling moved with an item's index whenever it changes, and with -1 when
+// the item leaves the queue. The index is what Fix and Remove take.
+func NewIndexed[T any](less func(a, b T) bool, moved func(item T, index int)) *Queue[T] {
+	return &Queue[T]{h: &items[T]{less: less, moved: moved}}
+}
+
+// Len returns the number of items queued.
+func (q *Queue[T]) Len() int {
+	return len(q.h.list)
+}
+
+// Push adds an item.
+func (q *Queue[T]) Push(item T) {
+	heap.Push(q.h, item)
+}
+
+// Pop removes and returns the first item. It returns false if the queue is
+// empty.
+func (q *Queue[T]) Pop() (T, bool) {
+	if len(q.h.list) == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(q.h).(T), true
+}
+
+// Peek returns the first item without removing it. It returns false if the
+// queue is empty.
+func (q *Queue[T]) Peek() (T, bool) {
+	if len(q.h.list) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.h.list[0], true
+}
+
+// Fix restores the order after the item at index changed priority.
+func (q *Queue[T]) Fix(index int) {
+	heap.Fix(q.h, index)
+}
+
+// Remove removes and returns the item at index.
+func (q *Queue[T]) Remove(index int) T {
+	return heap.Remove(q.h, index).(T)
+}
+
+// Reset replaces the queued items with a copy of list. Call it with no
+// items to empty the queue.
+func (q *Queue[T]) Reset(list ...T) {
+	for _, item := range q.h.list {
+		q.h.move(item, -1)
+	}
+	q.h.list = append([]T(nil), list...)
+	for i, item := range q.h.list {
+		q.h.move(item, i)
+	}
+	heap.Init(q.h)
+}
+
+// Reorder restores the order after any number of items changed priority.
+func (q *Queue[T]) Reorder() {
+	heap.Init(q.h)
+}
+
+// Items returns the queued items in heap order, which is not sorted
+// beyond the first item. The slice is the queue's own and must not be
+// modified.
+func (q *Queue[T]) Items() []T {
+	return q.h.list
+}
+
+// items adapts a list and its less function to heap.Interface.
+type items[T any] struct {
+	list  []T
+	less  func(a, b T) bool
+	moved func(item T, index int)
+}
+
+func (h *items[T]) Len() int           { return len(h.list) }
+func (h *items[T]) Less(i, j int) bool { return h.less(h.list[i], h.list[j]) }
+
+func (h *items[T]) Swap(i, j int) {
+	h.list[i], h.list[j] = h.list[j], h.list[i]
+	h.move(h.list[i], i)
+	h.move(h.list[j], j)
+}
+
+func (h *items[T]) Push(x interface{}) {
+	item := x.(T)
+	h.move(item, len(h.list))
+	h.list = append(h.list, item)
+}
+
+func (h *items[T]) Pop() interface{} {
+	n := len(h.list)
+	item := h.list[n-1]
+	var zero T
+	h.list[n-1] = zero
+	h.list = h.list[:n-1]
+	h.move(item, -1)
+	return item
+}
+
+// move reports an item's new index, if the queue tracks them.
+func (h *items[T]) move(item T, index int) {
+	if h.moved != nil {
+		h.moved(item, index)
+	}
+}
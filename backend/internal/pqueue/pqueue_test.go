@@ -0,0 +1,102 @@
+package pqueue
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestQueue_PopsInOrder(t *testing.T) {
+	q := New(func(a, b int) bool { return a < b })
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		q.Push(v)
+	}
+	if first, ok := q.Peek(); !ok || first != 1 {
+		t.Errorf("Expected to peek 1, got %d (%v)", first, ok)
+	}
+
+	var popped []int
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		popped = append(popped, v)
+	}
+	if !sort.IntsAreSorted(popped) || len(popped) != 5 {
+		t.Errorf("Expected 1 through 5 in order, got %v", popped)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected Pop on an empty queue to report false")
+	}
+	if _, ok := q.Peek(); ok {
+		t.Error("Expected Peek on an empty queue to report false")
+	}
+}
+
+type task struct {
+	name     string
+	priority int
+	index    int
+}
+
+func newTaskQueue() *Queue[*task] {
+	return NewIndexed(
+		func(a, b *task) bool { return a.priority > b.priority },
+		func(t *task, index int) { t.index = index },
+	)
+}
+
+func TestQueue_TracksIndexes(t *testing.T) {
+	q := newTaskQueue()
+	tasks := []*task{{name: "a", priority: 1}, {name: "b", priority: 3}, {name: "c", priority: 2}}
+	for _, task := range tasks {
+		q.Push(task)
+	}
+	for _, task := range tasks {
+		if q.Items()[task.index] != task {
+			t.Errorf("Expected %s at index %d", task.name, task.index)
+		}
+	}
+
+	tasks[0].priority = 10
+	q.Fix(tasks[0].index)
+	if first, _ := q.Peek(); first != tasks[0] {
+		t.Errorf("Expected a first after raising its priority, got %s", first.name)
+	}
+
+	removed := q.Remove(tasks[1].index)
+	if removed != tasks[1] || removed.index != -1 {
+		t.Errorf("Expected b removed with index -1, got %s at %d", removed.name, removed.index)
+	}
+	if q.Len() != 2 {
+		t.Errorf("Expected 2 tasks left, got %d", q.Len())
+	}
+
+	popped, _ := q.Pop()
+	if popped != tasks[0] || popped.index != -1 {
+		t.Errorf("Expected a popped with index -1, got %s at %d", popped.name, popped.index)
+	}
+}
+
+func TestQueue_ResetAndReorder(t *testing.T) {
+	q := newTaskQueue()
+	old := &task{name: "old", priority: 1}
+	q.Push(old)
+
+	tasks := []*task{{name: "a", priority: 1}, {name: "b", priority: 2}}
+	q.Reset(tasks...)
+	if old.index != -1 {
+		t.Errorf("Expected the replaced task's index to be -1, got %d", old.index)
+	}
+	if first, _ := q.Peek(); first.name != "b" {
+		t.Errorf("Expected b first after Reset, got %s", first.name)
+	}
+
+	tasks[0].priority = 5
+	q.Reorder()
+	if first, _ := q.Peek(); first.name != "a" {
+		t.Errorf("Expected a first after Reorder, got %s", first.name)
+	}
+
+	q.Reset()
+	if q.Len() != 0 || tasks[0].index != -1 || tasks[1].index != -1 {
+		t.Errorf("Expected an empty queue with untracked tasks, got %d queued", q.Len())
+	}
+}
@@ -0,0 +1,78 @@
+package preferences
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// ProfileResponse is the body of GET and PUT /preferences.
+type ProfileResponse struct {
+	*Profile
+	// Effective are the preferences agents are given
+	Effective Settings `json:"effective"`
+}
+
+// ServeGet handles GET /preferences - returns the caller's profile.
+func (s *Store) ServeGet(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	profile, err := s.Get(tenant, user)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, &ProfileResponse{Profile: profile, Effective: profile.Effective()})
+}
+
+// ServeSet handles PUT /preferences - replaces the caller's explicit
+// settings and returns the updated profile.
+func (s *Store) ServeSet(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	var settings Settings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	profile, err := s.Set(tenant, user, settings)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, &ProfileResponse{Profile: profile, Effective: profile.Effective()})
+}
+
+// ServeDelete handles DELETE /preferences - deletes the caller's profile,
+// learned preferences included.
+func (s *Store) ServeDelete(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	if err := s.Delete(tenant, user); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	log.Printf("Deleted preference profile of %s in tenant %q", user, tenant)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// caller returns the tenant and authenticated user a request is made for,
+// writing a 401 response for anonymous requests.
+func caller(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	claims := auth.GetClaims(r.Context())
+	if claims == nil || claims.Subject == "" {
+		errdefs.WriteError(w, "an authenticated user is required", http.StatusUnauthorized)
+		return "", "", false
+	}
+	return features.TenantFromContext(r.Context()), claims.Subject, true
+}
@@ -0,0 +1,84 @@
+package preferences
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// newTestRouter serves a store's API, authenticating requests as the
+// subject in the X-Test-Subject header.
+func newTestRouter(store *Store) http.Handler {
+	r := chi.NewRouter()
+	r.Use(features.Tenant)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subject := r.Header.Get("X-Test-Subject"); subject != "" {
+				r = r.WithContext(context.WithValue(r.Context(), auth.ClaimsContextKey, &auth.Claims{Subject: subject}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/preferences", store.ServeGet)
+	r.Put("/preferences", store.ServeSet)
+	r.Delete("/preferences", store.ServeDelete)
+	return r
+}
+
+func serve(router http.Handler, method, subject, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/preferences", strings.NewReader(body))
+	req.Header.Set(features.TenantHeader, "acme")
+	if subject != "" {
+		req.Header.Set("X-Test-Subject", subject)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPreferencesAPI(t *testing.T) {
+	store := NewStore()
+	store.Learn("acme", []Feedback{{User: "alice", Query: "a django view", Success: true}})
+	router := newTestRouter(store)
+
+	if w := serve(router, http.MethodGet, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an anonymous request, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "bob", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a profile, got %d", w.Code)
+	}
+
+	w := serve(router, http.MethodPut, "alice", `{"languages": ["Python"], "verbosity": "concise"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		User      string   `json:"user"`
+		Tenant    string   `json:"tenant"`
+		Effective Settings `json:"effective"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.User != "alice" || resp.Tenant != "acme" || resp.Effective.Verbosity != Concise ||
+		len(resp.Effective.Frameworks) != 1 || resp.Effective.Frameworks[0] != "Django" {
+		t.Errorf("expected alice's explicit and learned preferences, got %+v", resp)
+	}
+
+	if w := serve(router, http.MethodPut, "alice", `{"verbosity": "chatty"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid settings, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "alice", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "alice", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
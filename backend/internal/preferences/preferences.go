@@ -0,0 +1,283 @@
+// Package preferences keeps long-term preference profiles of users: the
+// languages and frameworks they work in and how detailed they like
+// answers. Preferences are learned from outcome feedback and set
+// explicitly, explicit settings winning, and are added to the prompts of
+// the agents that answer the user.
+package preferences
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Verbosity is how detailed a user likes answers.
+type Verbosity string
+
+// Verbosities.
+const (
+	Concise  Verbosity = "concise"
+	Detailed Verbosity = "detailed"
+)
+
+// Learning parameters.
+const (
+	// learnDecay scales learned weights down with each feedback, so
+	// profiles follow users as their work changes
+	learnDecay = 0.95
+	// failureWeight is what a mention counts in a failed outcome; the user
+	// still works in the language, but the answer did not help
+	failureWeight = 0.5
+	// minWeight is the least weight a learned preference is used with
+	minWeight = 1.0
+	// forgetWeight is the weight below which a preference is dropped
+	forgetWeight = 0.05
+	// maxLearned bounds the learned languages or frameworks used
+	maxLearned = 3
+	// maxSettings bounds the languages or frameworks set explicitly
+	maxSettings = 10
+)
+
+// Settings are preferences a user set explicitly. Empty fields are left to
+// what is learned.
+type Settings struct {
+	Languages  []string  `json:"languages,omitempty"`
+	Frameworks []string  `json:"frameworks,omitempty"`
+	Verbosity  Verbosity `json:"verbosity,omitempty"`
+}
+
+// normalize validates settings, trimming names and dropping duplicates.
+func (s *Settings) normalize() error {
+	var problems []string
+	for _, list := range []*[]string{&s.Languages, &s.Frameworks} {
+		seen := make(map[string]bool, len(*list))
+		names := make([]string, 0, len(*list))
+		for _, name := range *list {
+			name = strings.TrimSpace(name)
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+		*list = names
+	}
+	if len(s.Languages) > maxSettings || len(s.Frameworks) > maxSettings {
+		problems = append(problems, fmt.Sprintf("at most %d languages and %d frameworks", maxSettings, maxSettings))
+	}
+	switch s.Verbosity {
+	case "", Concise, Detailed:
+	default:
+		problems = append(problems, fmt.Sprintf("verbosity must be %s or %s", Concise, Detailed))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSettings, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Feedback is the outcome of a query a user made.
+type Feedback struct {
+	User    string
+	Query   string
+	Success bool
+	// Verbosity is the answer length the user asked for, if they did
+	Verbosity Verbosity
+}
+
+// Learned are preference weights learned from feedback.
+type Learned struct {
+	Languages  map[string]float64    `json:"languages"`
+	Frameworks map[string]float64    `json:"frameworks"`
+	Verbosity  map[Verbosity]float64 `json:"verbosity"`
+	// Observations counts the feedback learned from
+	Observations int `json:"observations"`
+}
+
+// newLearned creates empty weights.
+func newLearned() Learned {
+	return Learned{
+		Languages:  make(map[string]float64),
+		Frameworks: make(map[string]float64),
+		Verbosity:  make(map[Verbosity]float64),
+	}
+}
+
+// learn updates the weights from one outcome. Every language and framework
+// the query mentions gains weight, more if the answer succeeded.
+func (l *Learned) learn(fb Feedback) {
+	decay(l.Languages)
+	decay(l.Frameworks)
+	decay(l.Verbosity)
+
+	weight := 1.0
+	if !fb.Success {
+		weight = failureWeight
+	}
+	text := " " + strings.Join(tokenize(fb.Query), " ") + " "
+	for name := range mentioned(text, languageNames) {
+		l.Languages[name] += weight
+	}
+	for name := range mentioned(text, frameworkNames) {
+		l.Frameworks[name] += weight
+	}
+	if fb.Verbosity != "" {
+		l.Verbosity[fb.Verbosity]++
+	}
+	l.Observations++
+}
+
+// decay scales weights down, dropping those that fade out.
+func decay[K comparable](weights map[K]float64) {
+	for key, weight := range weights {
+		weight *= learnDecay
+		if weight < forgetWeight {
+			delete(weights, key)
+			continue
+		}
+		weights[key] = weight
+	}
+}
+
+// top returns up to maxLearned names weighing at least minWeight, heaviest
+// first.
+func top(weights map[string]float64) []string {
+	names := make([]string, 0, len(weights))
+	for name, weight := range weights {
+		if weight >= minWeight {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if weights[names[i]] != weights[names[j]] {
+			return weights[names[i]] > weights[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxLearned {
+		names = names[:maxLearned]
+	}
+	return names
+}
+
+// Profile is a user's preferences in one tenant.
+type Profile struct {
+	Tenant   string   `json:"tenant"`
+	User     string   `json:"user"`
+	Settings Settings `json:"settings"`
+	Learned  Learned  `json:"learned"`
+	// UpdatedAt is when the profile last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// clone returns a deep copy of the profile.
+func (p *Profile) clone() *Profile {
+	c := *p
+	c.Settings.Languages = append([]string(nil), p.Settings.Languages...)
+	c.Settings.Frameworks = append([]string(nil), p.Settings.Frameworks...)
+	c.Learned = newLearned()
+	c.Learned.Observations = p.Learned.Observations
+	for name, weight := range p.Learned.Languages {
+		c.Learned.Languages[name] = weight
+	}
+	for name, weight := range p.Learned.Frameworks {
+		c.Learned.Frameworks[name] = weight
+	}
+	for verbosity, weight := range p.Learned.Verbosity {
+		c.Learned.Verbosity[verbosity] = weight
+	}
+	return &c
+}
+
+// Effective returns the preferences in force: each explicit setting, or
+// what was learned where nothing was set.
+func (p *Profile) Effective() Settings {
+	effective := p.Settings
+	if len(effective.Languages) == 0 {
+		effective.Languages = top(p.Learned.Languages)
+	}
+	if len(effective.Frameworks) == 0 {
+		effective.Frameworks = top(p.Learned.Frameworks)
+	}
+	if effective.Verbosity == "" {
+		best := 0.0
+		for _, verbosity := range []Verbosity{Concise, Detailed} {
+			if weight := p.Learned.Verbosity[verbosity]; weight >= minWeight && weight > best {
+				effective.Verbosity, best = verbosity, weight
+			}
+		}
+	}
+	return effective
+}
+
+// Prompt renders the settings as an instruction to agents, or "" when
+// nothing is known.
+func (s Settings) Prompt() string {
+	var parts []string
+	if len(s.Languages) > 0 {
+		parts = append(parts, "works in "+strings.Join(s.Languages, ", "))
+	}
+	if len(s.Frameworks) > 0 {
+		parts = append(parts, "uses "+strings.Join(s.Frameworks, ", "))
+	}
+	switch s.Verbosity {
+	case Concise:
+		parts = append(parts, "prefers concise answers")
+	case Detailed:
+		parts = append(parts, "prefers detailed answers with explanations")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "The user " + strings.Join(parts, "; ") + ". Tailor examples and the level of detail accordingly."
+}
+
+// languageNames maps phrases in lower-cased, tokenized queries to the
+// languages they mention. A bare "go" is too common a word, so Go is
+// recognized only in phrases.
+var languageNames = map[string]string{
+	"golang": "Go", "in go": "Go", "go code": "Go", "go module": "Go", "go service": "Go", "go program": "Go", "go function": "Go",
+	"python": "Python", "rust": "Rust", "typescript": "TypeScript", "javascript": "JavaScript",
+	"java": "Java", "kotlin": "Kotlin", "swift": "Swift", "c#": "C#", "csharp": "C#",
+	"c++": "C++", "cpp": "C++", "ruby": "Ruby", "php": "PHP", "scala": "Scala",
+	"haskell": "Haskell", "elixir": "Elixir", "sql": "SQL",
+}
+
+// frameworkNames maps phrases in lower-cased, tokenized queries to the
+// frameworks they mention.
+var frameworkNames = map[string]string{
+	"react": "React", "vue": "Vue", "angular": "Angular", "svelte": "Svelte",
+	"next.js": "Next.js", "nextjs": "Next.js", "django": "Django", "flask": "Flask",
+	"fastapi": "FastAPI", "rails": "Rails", "spring": "Spring", "express": "Express",
+	"gin": "Gin", "chi": "chi", "laravel": "Laravel", "pytorch": "PyTorch",
+	"tensorflow": "TensorFlow", ".net": ".NET", "dotnet": ".NET",
+}
+
+// tokenize lower-cases text and splits it into words, keeping the
+// characters of names like C++, C# and Next.js.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.", r)
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		// Sentence punctuation, not part of a name
+		if field = strings.TrimRight(field, "."); field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// mentioned returns the names whose phrases occur in space-padded text.
+func mentioned(text string, names map[string]string) map[string]bool {
+	found := make(map[string]bool)
+	for phrase, name := range names {
+		if strings.Contains(text, " "+phrase+" ") {
+			found[name] = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,81 @@
+package preferences
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLearnedLearn(t *testing.T) {
+	learned := newLearned()
+	learned.learn(Feedback{Query: "Write a Go service with chi and golang generics", Success: true})
+	learned.learn(Feedback{Query: "Port this Python script to Rust.", Success: false, Verbosity: Concise})
+	learned.learn(Feedback{Query: "let's go", Success: true})
+
+	if got := learned.Languages["Go"]; got != learnDecay*learnDecay {
+		t.Errorf("expected Go counted once and a bare go not at all, got %f", got)
+	}
+	if got := learned.Languages["Python"]; got != failureWeight*learnDecay {
+		t.Errorf("expected Python at half weight decayed once, got %f", got)
+	}
+	if learned.Frameworks["chi"] == 0 || learned.Verbosity[Concise] == 0 {
+		t.Errorf("expected chi and concise learned, got %+v", learned)
+	}
+	if learned.Observations != 3 {
+		t.Errorf("expected 3 observations, got %d", learned.Observations)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Use C++ or C# with Next.js.")
+	want := []string{"use", "c++", "or", "c#", "with", "next.js"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestProfileEffective(t *testing.T) {
+	profile := &Profile{Learned: newLearned()}
+	for i := 0; i < 3; i++ {
+		profile.Learned.learn(Feedback{Query: "build it in go with react", Success: true})
+	}
+	profile.Learned.learn(Feedback{Query: "explain rust lifetimes", Success: false, Verbosity: Detailed})
+
+	effective := profile.Effective()
+	if !reflect.DeepEqual(effective.Languages, []string{"Go"}) || !reflect.DeepEqual(effective.Frameworks, []string{"React"}) {
+		t.Errorf("expected learned Go and React above the threshold, got %+v", effective)
+	}
+	if effective.Verbosity != Detailed {
+		t.Errorf("expected the reported verbosity, got %q", effective.Verbosity)
+	}
+
+	profile.Settings = Settings{Languages: []string{"Kotlin"}, Verbosity: Concise}
+	effective = profile.Effective()
+	if !reflect.DeepEqual(effective.Languages, []string{"Kotlin"}) || effective.Verbosity != Concise ||
+		!reflect.DeepEqual(effective.Frameworks, []string{"React"}) {
+		t.Errorf("expected explicit settings to win field by field, got %+v", effective)
+	}
+
+	want := "The user works in Kotlin; uses React; prefers concise answers. Tailor examples and the level of detail accordingly."
+	if got := effective.Prompt(); got != want {
+		t.Errorf("expected prompt %q, got %q", want, got)
+	}
+	if got := (Settings{}).Prompt(); got != "" {
+		t.Errorf("expected no prompt without preferences, got %q", got)
+	}
+}
+
+func TestSettingsNormalize(t *testing.T) {
+	settings := Settings{Languages: []string{" Go ", "go", ""}, Verbosity: Concise}
+	if err := settings.normalize(); err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	if !reflect.DeepEqual(settings.Languages, []string{"Go"}) {
+		t.Errorf("expected duplicates and blanks dropped, got %v", settings.Languages)
+	}
+
+	settings = Settings{Verbosity: "chatty"}
+	if err := settings.normalize(); !errors.Is(err, ErrInvalidSettings) {
+		t.Errorf("expected ErrInvalidSettings, got %v", err)
+	}
+}
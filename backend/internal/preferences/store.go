@@ -0,0 +1,226 @@
+package preferences
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+var (
+	// ErrProfileNotFound is returned when a user has no profile.
+	ErrProfileNotFound = errdefs.New(errdefs.ErrNotFound, "preference profile not found")
+	// ErrInvalidSettings is returned for explicit settings that fail
+	// validation.
+	ErrInvalidSettings = errdefs.New(errdefs.ErrInvalidArgument, "invalid preference settings")
+)
+
+// tenantFile is the persisted form of a tenant's profiles.
+type tenantFile struct {
+	Tenant   string              `json:"tenant"`
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Store keeps preference profiles by tenant and user. A store opened on a
+// directory writes each tenant's profiles to a file on every change;
+// otherwise profiles are kept in memory only. It is safe for concurrent
+// use.
+type Store struct {
+	// dir holds one file per tenant; empty keeps profiles in memory
+	dir string
+
+	mu sync.Mutex
+	// tenants holds each tenant's profiles by user
+	tenants map[string]map[string]*Profile
+	now     func() time.Time
+}
+
+// NewStore creates a store that keeps profiles in memory.
+func NewStore() *Store {
+	return &Store{
+		tenants: make(map[string]map[string]*Profile),
+		now:     time.Now,
+	}
+}
+
+// OpenStore opens a store persisting profiles in dir, creating it if
+// needed, and loads the profiles saved there.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create preferences directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "tenant-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewStore()
+	s.dir = dir
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read preferences: %w", err)
+		}
+		var file tenantFile
+		if err := json.Unmarshal(data, &file); err != nil || file.Profiles == nil {
+			return nil, fmt.Errorf("corrupt preferences %s", filepath.Base(path))
+		}
+		for _, profile := range file.Profiles {
+			if profile.Learned.Languages == nil || profile.Learned.Frameworks == nil || profile.Learned.Verbosity == nil {
+				return nil, fmt.Errorf("corrupt preferences %s", filepath.Base(path))
+			}
+		}
+		s.tenants[file.Tenant] = file.Profiles
+	}
+	return s, nil
+}
+
+// Get returns a copy of a user's profile.
+func (s *Store) Get(tenant, user string) (*Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.tenants[tenant][user]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, user)
+	}
+	return profile.clone(), nil
+}
+
+// Set replaces a user's explicit settings, creating the profile if
+// needed, and returns the updated profile.
+func (s *Store) Set(tenant, user string, settings Settings) (*Profile, error) {
+	if err := settings.normalize(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile := s.profile(tenant, user)
+	profile.Settings = settings
+	profile.UpdatedAt = s.now()
+	if err := s.write(tenant); err != nil {
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// Learn updates the profiles of the users of a tenant from their
+// feedback. Feedback without a user is ignored.
+func (s *Store) Learn(tenant string, feedback []Feedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	learned := false
+	for _, fb := range feedback {
+		if fb.User == "" {
+			continue
+		}
+		profile := s.profile(tenant, fb.User)
+		profile.Learned.learn(fb)
+		profile.UpdatedAt = s.now()
+		learned = true
+	}
+	if !learned {
+		return nil
+	}
+	return s.write(tenant)
+}
+
+// Delete removes a user's profile.
+func (s *Store) Delete(tenant, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tenants[tenant][user]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, user)
+	}
+	delete(s.tenants[tenant], user)
+	if len(s.tenants[tenant]) == 0 {
+		delete(s.tenants, tenant)
+	}
+	return s.write(tenant)
+}
+
+// Prompt returns the preferences of the user a request is made for, as
+// an instruction to agents: the authenticated user in the tenant named by
+// the request. It returns "" for anonymous requests and users without
+// preferences.
+func (s *Store) Prompt(ctx context.Context) string {
+	claims := auth.GetClaims(ctx)
+	if claims == nil || claims.Subject == "" {
+		return ""
+	}
+	profile, err := s.Get(features.TenantFromContext(ctx), claims.Subject)
+	if err != nil {
+		return ""
+	}
+	return profile.Effective().Prompt()
+}
+
+// profile returns a user's profile, creating it if needed. Callers hold
+// mu.
+func (s *Store) profile(tenant, user string) *Profile {
+	profiles := s.tenants[tenant]
+	if profiles == nil {
+		profiles = make(map[string]*Profile)
+		s.tenants[tenant] = profiles
+	}
+	profile := profiles[user]
+	if profile == nil {
+		profile = &Profile{Tenant: tenant, User: user, Learned: newLearned()}
+		profiles[user] = profile
+	}
+	return profile
+}
+
+// write saves a tenant's profiles to its file atomically, removing the
+// file when the tenant has none left. Callers hold mu.
+func (s *Store) write(tenant string) error {
+	if s.dir == "" {
+		return nil
+	}
+	path := s.path(tenant)
+	profiles := s.tenants[tenant]
+	if len(profiles) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete preferences: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(tenantFile{Tenant: tenant, Profiles: profiles})
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of a tenant. Tenant names are hex-encoded, since
+// they come from a request header.
+func (s *Store) path(tenant string) string {
+	return filepath.Join(s.dir, "tenant-"+hex.EncodeToString([]byte(tenant))+".json")
+}
@@ -0,0 +1,98 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore()
+	if _, err := store.Get("acme", "alice"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	err := store.Learn("acme", []Feedback{
+		{User: "alice", Query: "a python flask app", Success: true},
+		{Query: "anonymous rust question", Success: true},
+	})
+	if err != nil {
+		t.Fatalf("Learn failed: %v", err)
+	}
+	profile, err := store.Get("acme", "alice")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if profile.Learned.Languages["Python"] != 1 || profile.Learned.Observations != 1 {
+		t.Errorf("expected Python learned once, got %+v", profile.Learned)
+	}
+	if _, err := store.Get("globex", "alice"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected profiles kept per tenant, got %v", err)
+	}
+
+	profile.Learned.Languages["Python"] = 100
+	if again, _ := store.Get("acme", "alice"); again.Learned.Languages["Python"] != 1 {
+		t.Error("expected Get to return a copy")
+	}
+
+	if _, err := store.Set("acme", "alice", Settings{Verbosity: Detailed}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := store.Set("acme", "alice", Settings{Verbosity: "chatty"}); !errors.Is(err, ErrInvalidSettings) {
+		t.Errorf("expected ErrInvalidSettings, got %v", err)
+	}
+
+	if err := store.Delete("acme", "alice"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete("acme", "alice"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound on second delete, got %v", err)
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	store.Set("acme/../x", "alice", Settings{Languages: []string{"Go"}})
+	store.Set("", "bob", Settings{Verbosity: Concise})
+	store.Learn("", []Feedback{{User: "bob", Query: "react hooks", Success: true}})
+
+	reopened, err := OpenStore(dir)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if profile, err := reopened.Get("acme/../x", "alice"); err != nil || profile.Settings.Languages[0] != "Go" {
+		t.Errorf("expected alice's settings reloaded, got %+v, %v", profile, err)
+	}
+	if profile, err := reopened.Get("", "bob"); err != nil || profile.Learned.Frameworks["React"] != 1 {
+		t.Errorf("expected bob's learned preferences reloaded, got %+v, %v", profile, err)
+	}
+
+	reopened.Delete("", "bob")
+	reopened, err = OpenStore(dir)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	if _, err := reopened.Get("", "bob"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected the deleted profile to stay deleted, got %v", err)
+	}
+}
+
+func TestStorePrompt(t *testing.T) {
+	store := NewStore()
+	store.Set("acme", "alice", Settings{Languages: []string{"Go"}})
+
+	ctx := context.WithValue(features.WithTenant(context.Background(), "acme"), auth.ClaimsContextKey, &auth.Claims{Subject: "alice"})
+	if got := store.Prompt(ctx); got != "The user works in Go. Tailor examples and the level of detail accordingly." {
+		t.Errorf("expected alice's preferences, got %q", got)
+	}
+	if got := store.Prompt(features.WithTenant(context.Background(), "acme")); got != "" {
+		t.Errorf("expected no preferences for an anonymous request, got %q", got)
+	}
+}
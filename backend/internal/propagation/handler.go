@@ -0,0 +1,60 @@
+package propagation
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ListResponse is the body of GET /admin/insights.
+type ListResponse struct {
+	Insights []Insight `json:"insights"`
+	// Counts are the retained insights by status
+	Counts map[Status]int `json:"counts"`
+}
+
+// ServeList handles GET /admin/insights?status= - lists the retained
+// insights, newest first, optionally only those with a status.
+func (e *Engine) ServeList(w http.ResponseWriter, r *http.Request) {
+	status := Status(r.URL.Query().Get("status"))
+	if status != "" && !validStatus(status) {
+		errdefs.WriteError(w, "status must be pending, promoted, private or rejected", http.StatusBadRequest)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, ListResponse{Insights: e.List(status), Counts: e.Counts()})
+}
+
+// ServeApprove handles POST /admin/insights/{id}/approve - promotes an
+// insight awaiting review to every tenant.
+func (e *Engine) ServeApprove(w http.ResponseWriter, r *http.Request) {
+	insight, err := e.Approve(chi.URLParam(r, "id"), reviewer(r))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	log.Printf("Insight %s from tenant %s promoted by %s", insight.ID, insight.Tenant, insight.DecidedBy)
+	errdefs.WriteJSON(w, http.StatusOK, insight)
+}
+
+// ServeReject handles POST /admin/insights/{id}/reject - keeps an insight
+// awaiting review private to its tenant.
+func (e *Engine) ServeReject(w http.ResponseWriter, r *http.Request) {
+	insight, err := e.Reject(chi.URLParam(r, "id"), reviewer(r))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	log.Printf("Insight %s from tenant %s rejected by %s", insight.ID, insight.Tenant, insight.DecidedBy)
+	errdefs.WriteJSON(w, http.StatusOK, insight)
+}
+
+// reviewer returns the subject deciding a review, if authenticated.
+func reviewer(r *http.Request) string {
+	if claims := auth.GetClaims(r.Context()); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
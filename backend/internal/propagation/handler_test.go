@@ -0,0 +1,58 @@
+package propagation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestRouter(engine *Engine) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/admin/insights", engine.ServeList)
+	r.Post("/admin/insights/{id}/approve", engine.ServeApprove)
+	r.Post("/admin/insights/{id}/reject", engine.ServeReject)
+	return r
+}
+
+func TestServeReview(t *testing.T) {
+	engine := NewEngine(nil)
+	router := newTestRouter(engine)
+	first := engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough, Subject: "security"})
+	second := engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough, Subject: "testing"})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do(http.MethodPost, "/admin/insights/"+first.ID+"/approve")
+	var insight Insight
+	if err := json.NewDecoder(w.Body).Decode(&insight); err != nil || w.Code != http.StatusOK || insight.Status != StatusPromoted {
+		t.Fatalf("expected the insight promoted, got %d %+v", w.Code, insight)
+	}
+	if w := do(http.MethodPost, "/admin/insights/"+second.ID+"/reject"); w.Code != http.StatusOK {
+		t.Errorf("expected the insight rejected, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/admin/insights/"+second.ID+"/approve"); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a decided insight, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/admin/insights/insight-404/reject"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown insight, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, "/admin/insights?status=rejected")
+	var list ListResponse
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected the insight list, got %d %v", w.Code, err)
+	}
+	if len(list.Insights) != 1 || list.Insights[0].ID != second.ID || list.Counts[StatusPromoted] != 1 {
+		t.Errorf("expected the rejected insight and counts, got %+v", list)
+	}
+	if w := do(http.MethodGet, "/admin/insights?status=lost"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", w.Code)
+	}
+}
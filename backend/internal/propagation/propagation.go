@@ -0,0 +1,373 @@
+// Package propagation decides how far an insight learned from one
+// tenant's traffic may travel. A policy keeps each insight private to its
+// tenant, promotes it to the global knowledge base, or queues it for an
+// administrator to approve or reject; the first rule matching the insight
+// decides. Promoted insights are handed to a callback that publishes them,
+// so nothing a tenant's sessions teach the collective reaches the other
+// tenants until the policy or an administrator allows it.
+package propagation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidPolicy is returned for policy files that fail validation
+	ErrInvalidPolicy = errdefs.New(errdefs.ErrInvalidArgument, "invalid insight policy")
+	// ErrInsightNotFound is returned for insights that are not retained
+	ErrInsightNotFound = errdefs.New(errdefs.ErrNotFound, "insight not found")
+	// ErrAlreadyDecided is returned for approving or rejecting an insight
+	// that is not awaiting review
+	ErrAlreadyDecided = errdefs.New(errdefs.ErrConflict, "insight is not awaiting review")
+)
+
+// KindBreakthrough is an agent combination succeeding far more than
+// expected at a task type.
+const KindBreakthrough = "breakthrough"
+
+// maxInsights bounds the insights retained; the oldest are dropped first.
+const maxInsights = 1000
+
+// Decision is what the policy does with an insight.
+type Decision string
+
+const (
+	// Private keeps an insight to its tenant
+	Private Decision = "private"
+	// Promote publishes an insight to every tenant
+	Promote Decision = "promote"
+	// Review queues an insight for an administrator
+	Review Decision = "review"
+)
+
+// Status is where an insight is in review.
+type Status string
+
+const (
+	// StatusPending insights await an administrator
+	StatusPending Status = "pending"
+	// StatusPromoted insights were published
+	StatusPromoted Status = "promoted"
+	// StatusPrivate insights were kept private by the policy
+	StatusPrivate Status = "private"
+	// StatusRejected insights were kept private by an administrator
+	StatusRejected Status = "rejected"
+)
+
+// Insight is something the collective learned from a tenant's traffic.
+type Insight struct {
+	ID string `json:"id"`
+	// Tenant is where the insight was learned; empty for traffic without
+	// a tenant
+	Tenant string `json:"tenant,omitempty"`
+	Kind   string `json:"kind"`
+	// Subject is what the insight is about, such as a task type
+	Subject string `json:"subject"`
+	// Detail qualifies the subject, such as the strategy that succeeded
+	Detail string   `json:"detail,omitempty"`
+	Agents []string `json:"agents,omitempty"`
+	// Score is how notable the insight is; for breakthroughs, the surprise
+	// in standard deviations
+	Score      float64   `json:"score"`
+	DetectedAt time.Time `json:"detected_at"`
+
+	Status Status `json:"status"`
+	// Reason is what decided the insight: a policy rule, the default, or
+	// the administrator
+	Reason    string     `json:"reason"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+}
+
+// ============================================================================
+// Policy
+// ============================================================================
+
+// Policy decides insights. Insights without a tenant belong to no one, so
+// they are promoted without consulting the rules.
+type Policy struct {
+	// Default decides insights no rule matches; empty is review
+	Default Decision `yaml:"default"`
+	Rules   []Rule   `yaml:"rules"`
+}
+
+// Rule decides the insights it matches. Empty lists match everything.
+type Rule struct {
+	Tenants []string `yaml:"tenants"`
+	Kinds   []string `yaml:"kinds"`
+	// MinScore is the lowest score matched
+	MinScore float64  `yaml:"min_score"`
+	Decision Decision `yaml:"decision"`
+}
+
+// DefaultPolicy queues every tenant's insights for review.
+func DefaultPolicy() *Policy {
+	return &Policy{Default: Review}
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read insight policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy decodes and validates a policy. Unknown fields are errors,
+// so misspelled names are caught at load time.
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse insight policy YAML: %w", err)
+	}
+	if policy.Default == "" {
+		policy.Default = Review
+	}
+
+	var problems []error
+	if !policy.Default.valid() {
+		problems = append(problems, fmt.Errorf("default: unknown decision %q", policy.Default))
+	}
+	for i, rule := range policy.Rules {
+		if !rule.Decision.valid() {
+			problems = append(problems, fmt.Errorf("rule %d: unknown decision %q", i+1, rule.Decision))
+		}
+		for _, tenant := range rule.Tenants {
+			if tenant == "" {
+				problems = append(problems, fmt.Errorf("rule %d: tenant id is required", i+1))
+			}
+		}
+		if rule.MinScore < 0 {
+			problems = append(problems, fmt.Errorf("rule %d: min_score must not be negative", i+1))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPolicy, errors.Join(problems...))
+	}
+	return &policy, nil
+}
+
+// valid reports whether a decision is known.
+func (d Decision) valid() bool {
+	return d == Private || d == Promote || d == Review
+}
+
+// Decide returns the decision for an insight and why it was made.
+func (p *Policy) Decide(insight *Insight) (Decision, string) {
+	if insight.Tenant == "" {
+		return Promote, "no tenant"
+	}
+	for i, rule := range p.Rules {
+		if rule.matches(insight) {
+			return rule.Decision, "rule " + strconv.Itoa(i+1)
+		}
+	}
+	return p.Default, "default"
+}
+
+// matches reports whether a rule applies to an insight.
+func (r *Rule) matches(insight *Insight) bool {
+	return matchesAny(r.Tenants, insight.Tenant) &&
+		matchesAny(r.Kinds, insight.Kind) &&
+		insight.Score >= r.MinScore
+}
+
+// matchesAny reports whether value is listed, or the list is empty.
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// Engine
+// ============================================================================
+
+// Engine applies a policy to insights as they are learned and keeps the
+// queue of insights awaiting review. It is safe for concurrent use.
+type Engine struct {
+	policy *Policy
+
+	mu       sync.Mutex
+	insights map[string]*Insight
+	// order holds insight IDs, oldest first
+	order     []string
+	seq       int
+	onPromote func(Insight)
+	now       func() time.Time
+}
+
+// NewEngine creates an engine applying policy, or DefaultPolicy if policy
+// is nil.
+func NewEngine(policy *Policy) *Engine {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	return &Engine{
+		policy:   policy,
+		insights: make(map[string]*Insight),
+		now:      time.Now,
+	}
+}
+
+// OnPromote sets the callback that publishes promoted insights. It is
+// called on the goroutine that submitted or approved the insight, without
+// the engine locked.
+func (e *Engine) OnPromote(fn func(Insight)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onPromote = fn
+}
+
+// Submit decides a newly learned insight and returns it with its ID and
+// status. Promoted insights are published before Submit returns.
+func (e *Engine) Submit(insight Insight) Insight {
+	decision, reason := e.policy.Decide(&insight)
+
+	e.mu.Lock()
+	e.seq++
+	insight.ID = "insight-" + strconv.Itoa(e.seq)
+	now := e.now()
+	if insight.DetectedAt.IsZero() {
+		insight.DetectedAt = now
+	}
+	insight.Reason = reason
+	switch decision {
+	case Promote:
+		insight.Status = StatusPromoted
+		insight.DecidedAt = &now
+	case Private:
+		insight.Status = StatusPrivate
+		insight.DecidedAt = &now
+	default:
+		insight.Status = StatusPending
+	}
+	e.insights[insight.ID] = &insight
+	e.order = append(e.order, insight.ID)
+	e.evict()
+	onPromote := e.onPromote
+	e.mu.Unlock()
+
+	if insight.Status == StatusPromoted && onPromote != nil {
+		onPromote(insight)
+	}
+	return insight
+}
+
+// evict drops the oldest insights beyond maxInsights. Dropped pending
+// insights stay private. It must be called with the engine locked.
+func (e *Engine) evict() {
+	for len(e.order) > maxInsights {
+		oldest := e.insights[e.order[0]]
+		if oldest.Status == StatusPending {
+			log.Printf("Review queue full: dropping %s %s from tenant %s unreviewed", oldest.Kind, oldest.ID, oldest.Tenant)
+		}
+		delete(e.insights, oldest.ID)
+		e.order = e.order[1:]
+	}
+}
+
+// Approve promotes an insight awaiting review and publishes it.
+func (e *Engine) Approve(id, by string) (Insight, error) {
+	insight, err := e.decide(id, by, StatusPromoted)
+	if err != nil {
+		return Insight{}, err
+	}
+	e.mu.Lock()
+	onPromote := e.onPromote
+	e.mu.Unlock()
+	if onPromote != nil {
+		onPromote(insight)
+	}
+	return insight, nil
+}
+
+// Reject keeps an insight awaiting review private to its tenant.
+func (e *Engine) Reject(id, by string) (Insight, error) {
+	return e.decide(id, by, StatusRejected)
+}
+
+// decide records an administrator's decision on a pending insight.
+func (e *Engine) decide(id, by string, status Status) (Insight, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	insight, ok := e.insights[id]
+	if !ok {
+		return Insight{}, ErrInsightNotFound
+	}
+	if insight.Status != StatusPending {
+		return Insight{}, fmt.Errorf("%w: %s is %s", ErrAlreadyDecided, id, insight.Status)
+	}
+	now := e.now()
+	insight.Status = status
+	insight.Reason = "administrator"
+	insight.DecidedAt = &now
+	insight.DecidedBy = by
+	return *insight, nil
+}
+
+// Get returns an insight.
+func (e *Engine) Get(id string) (Insight, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	insight, ok := e.insights[id]
+	if !ok {
+		return Insight{}, ErrInsightNotFound
+	}
+	return *insight, nil
+}
+
+// List returns the retained insights with a status, or all of them for an
+// empty status, newest first.
+func (e *Engine) List(status Status) []Insight {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	list := make([]Insight, 0)
+	for i := len(e.order) - 1; i >= 0; i-- {
+		insight := e.insights[e.order[i]]
+		if status == "" || insight.Status == status {
+			list = append(list, *insight)
+		}
+	}
+	return list
+}
+
+// Counts returns the number of retained insights by status.
+func (e *Engine) Counts() map[Status]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	counts := make(map[Status]int)
+	for _, insight := range e.insights {
+		counts[insight.Status]++
+	}
+	return counts
+}
+
+// validStatus reports whether a status is known.
+func validStatus(status Status) bool {
+	switch status {
+	case StatusPending, StatusPromoted, StatusPrivate, StatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package propagation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+const testPolicyYAML = `
+default: review
+rules:
+  - tenants: [globex]
+    decision: private
+  - kinds: [breakthrough]
+    min_score: 5
+    decision: promote
+`
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("expected valid policy, got %v", err)
+	}
+
+	tests := []struct {
+		insight Insight
+		want    Decision
+		reason  string
+	}{
+		{Insight{Tenant: "globex", Kind: KindBreakthrough, Score: 9}, Private, "rule 1"},
+		{Insight{Tenant: "acme", Kind: KindBreakthrough, Score: 9}, Promote, "rule 2"},
+		{Insight{Tenant: "acme", Kind: KindBreakthrough, Score: 3}, Review, "default"},
+		{Insight{Tenant: "acme", Kind: "concept", Score: 9}, Review, "default"},
+		{Insight{Kind: KindBreakthrough, Score: 1}, Promote, "no tenant"},
+	}
+	for _, tt := range tests {
+		got, reason := policy.Decide(&tt.insight)
+		if got != tt.want || reason != tt.reason {
+			t.Errorf("expected %s by %s for %+v, got %s by %s", tt.want, tt.reason, tt.insight, got, reason)
+		}
+	}
+
+	if policy, err := ParsePolicy([]byte("rules: []\n")); err != nil || policy.Default != Review {
+		t.Errorf("expected review by default, got %+v, %v", policy, err)
+	}
+}
+
+func TestParsePolicyReportsEveryProblem(t *testing.T) {
+	_, err := ParsePolicy([]byte(`
+default: share
+rules:
+  - tenants: [""]
+    min_score: -1
+    decision: promote
+  - decision: maybe
+`))
+	if !errors.Is(err, ErrInvalidPolicy) || !errors.Is(err, errdefs.ErrInvalidArgument) {
+		t.Fatalf("expected an invalid policy, got %v", err)
+	}
+	for _, want := range []string{`default: unknown decision "share"`, "rule 1: tenant id is required", "rule 1: min_score", `rule 2: unknown decision "maybe"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	if _, err := ParsePolicy([]byte("rules:\n  - decison: promote\n")); err == nil {
+		t.Error("expected unknown fields rejected")
+	}
+}
+
+func TestEngineReview(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(policy)
+	var promoted []Insight
+	engine.OnPromote(func(insight Insight) {
+		promoted = append(promoted, insight)
+	})
+
+	private := engine.Submit(Insight{Tenant: "globex", Kind: KindBreakthrough, Subject: "security", Score: 9})
+	pending := engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough, Subject: "testing", Score: 3})
+	automatic := engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough, Subject: "design", Score: 7})
+
+	if private.Status != StatusPrivate || pending.Status != StatusPending || automatic.Status != StatusPromoted {
+		t.Fatalf("expected private, pending and promoted, got %s, %s and %s", private.Status, pending.Status, automatic.Status)
+	}
+	if pending.ID == "" || pending.ID == private.ID || pending.DetectedAt.IsZero() || pending.DecidedAt != nil {
+		t.Errorf("expected a pending insight with its own ID and detection time, got %+v", pending)
+	}
+	if len(promoted) != 1 || promoted[0].Subject != "design" {
+		t.Fatalf("expected only the automatic promotion published, got %+v", promoted)
+	}
+
+	approved, err := engine.Approve(pending.ID, "admin@example.com")
+	if err != nil {
+		t.Fatalf("expected the pending insight approved, got %v", err)
+	}
+	if approved.Status != StatusPromoted || approved.DecidedBy != "admin@example.com" || approved.Reason != "administrator" {
+		t.Errorf("expected the approval recorded, got %+v", approved)
+	}
+	if len(promoted) != 2 || promoted[1].ID != pending.ID {
+		t.Errorf("expected the approved insight published, got %+v", promoted)
+	}
+
+	if _, err := engine.Approve(pending.ID, "admin@example.com"); !errors.Is(err, ErrAlreadyDecided) || errdefs.HTTPStatus(err) != 409 {
+		t.Errorf("expected a decided insight not to be approved twice, got %v", err)
+	}
+	if _, err := engine.Reject(private.ID, ""); !errors.Is(err, ErrAlreadyDecided) {
+		t.Errorf("expected a private insight not to be reviewed, got %v", err)
+	}
+	if _, err := engine.Reject("insight-404", ""); !errors.Is(err, ErrInsightNotFound) {
+		t.Errorf("expected an unknown insight not found, got %v", err)
+	}
+
+	list := engine.List("")
+	if len(list) != 3 || list[0].ID != automatic.ID {
+		t.Errorf("expected every insight newest first, got %+v", list)
+	}
+	if counts := engine.Counts(); counts[StatusPromoted] != 2 || counts[StatusPrivate] != 1 || counts[StatusPending] != 0 {
+		t.Errorf("expected counts by status, got %v", counts)
+	}
+}
+
+func TestEngineEvictsOldest(t *testing.T) {
+	engine := NewEngine(nil)
+	first := engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough})
+	for i := 0; i < maxInsights; i++ {
+		engine.Submit(Insight{Tenant: "acme", Kind: KindBreakthrough})
+	}
+	if _, err := engine.Get(first.ID); !errors.Is(err, ErrInsightNotFound) {
+		t.Errorf("expected the oldest insight dropped, got %v", err)
+	}
+	if pending := engine.List(StatusPending); len(pending) != maxInsights {
+		t.Errorf("expected %d insights retained, got %d", maxInsights, len(pending))
+	}
+}
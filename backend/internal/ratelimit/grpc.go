@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+// UnaryInterceptor is the gRPC counterpart of LimitClients: it fails calls
+// from clients over their limit with ResourceExhausted and a retry-after
+// header giving the seconds to wait. It goes after authentication.
+func (l *Limiter) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := l.allowCall(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor limits streaming calls as UnaryInterceptor limits
+// unary ones, taking one token when the stream opens.
+func (l *Limiter) StreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.allowCall(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// allowCall takes a token from the bucket of a call's client, returning
+// the status error answering a call over the limit.
+func (l *Limiter) allowCall(ctx context.Context) error {
+	err := l.AllowClient(ctx, callerOf(ctx))
+	var limited *LimitedError
+	if !errors.As(err, &limited) {
+		return nil
+	}
+	log.Printf("Call not admitted: %v", err)
+	retryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(limited.Wait.Seconds()))))
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+	return status.Error(codes.ResourceExhausted, err.Error())
+}
+
+// callerOf names the client a call is from, as clientOf names the client
+// of a request.
+func callerOf(ctx context.Context) string {
+	if claims := auth.GetClaims(ctx); claims != nil && claims.Subject != "" {
+		return claims.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+	return "ip:unknown"
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+// testStream is a server stream with a context of its own.
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context { return s.ctx }
+
+func TestInterceptors(t *testing.T) {
+	limiter := New(NewMemoryStore(), Config{Client: Limit{QPS: 0.25, Burst: 1}})
+	unary := func(ctx context.Context) error {
+		_, err := limiter.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Call"}, func(ctx context.Context, req any) (any, error) {
+			return "ok", nil
+		})
+		return err
+	}
+	stream := func(ctx context.Context) error {
+		return limiter.StreamInterceptor(nil, testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}, func(srv any, stream grpc.ServerStream) error {
+			return nil
+		})
+	}
+	from := func(subject, addr string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 5000}})
+		if subject != "" {
+			ctx = context.WithValue(ctx, auth.ClaimsContextKey, &auth.Claims{Subject: subject})
+		}
+		return ctx
+	}
+
+	if err := unary(from("alice", "10.0.0.1")); err != nil {
+		t.Fatalf("expected the first call admitted, got %v", err)
+	}
+	err := stream(from("alice", "10.0.0.2"))
+	if s, _ := status.FromError(err); s.Code() != codes.ResourceExhausted || !strings.Contains(s.Message(), "client:alice") {
+		t.Errorf("expected alice's stream limited with ResourceExhausted, got %v", err)
+	}
+
+	// Clients without a token are limited by address
+	if err := stream(from("", "10.0.0.1")); err != nil {
+		t.Errorf("expected an address of its own, got %v", err)
+	}
+	if err := unary(from("", "10.0.0.1")); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the address limited, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets, which are the same as no
+// bucket, are dropped from a memory store.
+const sweepInterval = time.Minute
+
+// bucket is a token bucket as of a time.
+type bucket struct {
+	tokens float64
+	at     time.Time
+	limit  Limit
+}
+
+// fill adds the tokens earned since the bucket was last taken from.
+func (b *bucket) fill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.burst()), b.tokens+now.Sub(b.at).Seconds()*b.limit.QPS)
+	b.at = now
+}
+
+// MemoryStore keeps token buckets in memory, limiting the requests to one
+// server.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take takes a token from the bucket named key.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.swept) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), at: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.fill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+	return time.Duration((1 - b.tokens) / limit.QPS * float64(time.Second)), nil
+}
+
+// sweep drops the buckets that have filled up.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		b.fill(now)
+		if b.tokens >= float64(b.limit.burst()) {
+			delete(s.buckets, key)
+		}
+	}
+	s.swept = now
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{QPS: 2}
+
+	for i := 0; i < 2; i++ {
+		if wait, _ := store.Take(context.Background(), "k", limit); wait != 0 {
+			t.Fatalf("expected one second's worth of burst, got a wait of %s", wait)
+		}
+	}
+	if wait, _ := store.Take(context.Background(), "k", limit); wait != 500*time.Millisecond {
+		t.Errorf("expected a token in half a second, got %s", wait)
+	}
+
+	now = now.Add(250 * time.Millisecond)
+	if wait, _ := store.Take(context.Background(), "k", limit); wait != 250*time.Millisecond {
+		t.Errorf("expected half a token earned, got a wait of %s", wait)
+	}
+
+	// Buckets that fill up are swept
+	now = now.Add(sweepInterval)
+	if _, err := store.Take(context.Background(), "other", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.buckets["k"]; ok || len(store.buckets) != 1 {
+		t.Errorf("expected the full bucket swept, got %v", store.buckets)
+	}
+}
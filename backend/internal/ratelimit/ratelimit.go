@@ -0,0 +1,234 @@
+// Package ratelimit limits the requests each client sends and each agent
+// is sent with token buckets. Buckets are kept in memory, per server, or in
+// Redis, shared by every server using it.
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrRateLimited is wrapped by the errors of requests over a rate limit.
+var ErrRateLimited = errdefs.New(errdefs.ErrCapacityExceeded, "rate limit exceeded")
+
+// LimitedError is a request over a rate limit.
+type LimitedError struct {
+	// Key names the bucket: client:<subject>, client:ip:<address> or
+	// agent:<codename>
+	Key string
+	// Wait is how long until the bucket has a token again
+	Wait time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s; retry in %s", e.Key, e.Wait.Round(time.Millisecond))
+}
+
+// Unwrap returns ErrRateLimited.
+func (e *LimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RetryAfter is how long until the request can be retried.
+func (e *LimitedError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// Limit is a token bucket: QPS tokens are added each second, up to Burst.
+// A zero QPS is unlimited.
+type Limit struct {
+	QPS float64 `yaml:"qps" json:"qps"`
+	// Burst is how many requests may be sent at once; zero is one
+	// second's worth
+	Burst int `yaml:"burst" json:"burst"`
+}
+
+// burst returns the bucket's size.
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.QPS)))
+}
+
+// validate checks a limit's values.
+func (l Limit) validate() error {
+	if l.QPS < 0 || math.IsNaN(l.QPS) || math.IsInf(l.QPS, 0) || l.Burst < 0 {
+		return errors.New("qps and burst must be non-negative numbers")
+	}
+	return nil
+}
+
+// Config sets the limits of clients and agents.
+type Config struct {
+	// Client limits each client not listed in Clients
+	Client Limit `yaml:"client"`
+	// Agent limits each agent not listed in Agents
+	Agent Limit `yaml:"agent"`
+	// Clients limits particular clients, by OIDC subject
+	Clients map[string]Limit `yaml:"clients"`
+	// Agents limits particular agents, by codename
+	Agents map[string]Limit `yaml:"agents"`
+}
+
+// Enabled reports whether any client or agent is limited.
+func (c *Config) Enabled() bool {
+	if c.Client.QPS > 0 || c.Agent.QPS > 0 {
+		return true
+	}
+	for _, limits := range []map[string]Limit{c.Clients, c.Agents} {
+		for _, limit := range limits {
+			if limit.QPS > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadConfig reads a YAML file of limits over defaults; the limits the
+// file sets replace them.
+func LoadConfig(path string, defaults Config) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limits: %w", err)
+	}
+	return ParseConfig(data, defaults)
+}
+
+// ParseConfig decodes and validates YAML limits over defaults. Unknown
+// fields are errors, so misspelled keys are caught at load time.
+func ParseConfig(data []byte, defaults Config) (*Config, error) {
+	cfg := defaults
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limits YAML: %w", err)
+	}
+
+	var problems []error
+	if err := cfg.Client.validate(); err != nil {
+		problems = append(problems, fmt.Errorf("client: %w", err))
+	}
+	if err := cfg.Agent.validate(); err != nil {
+		problems = append(problems, fmt.Errorf("agent: %w", err))
+	}
+	for subject, limit := range cfg.Clients {
+		if err := limit.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("client %s: %w", subject, err))
+		}
+	}
+	agents := make(map[string]Limit, len(cfg.Agents))
+	for codename, limit := range cfg.Agents {
+		if err := limit.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("agent %s: %w", codename, err))
+		}
+		agents[strings.ToUpper(codename)] = limit
+	}
+	cfg.Agents = agents
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return &cfg, nil
+}
+
+// Store keeps token buckets.
+type Store interface {
+	// Take takes a token from the bucket named key, filled as limit says.
+	// It returns zero when a token was taken, or how long until one will
+	// be
+	Take(ctx context.Context, key string, limit Limit) (time.Duration, error)
+}
+
+// Limiter limits clients and agents to their configured rates.
+type Limiter struct {
+	store  Store
+	config Config
+}
+
+// New creates a limiter keeping its buckets in store.
+func New(store Store, config Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// AllowClient takes a token from a client's bucket. The error is a
+// *LimitedError when the client is over its limit.
+func (l *Limiter) AllowClient(ctx context.Context, client string) error {
+	limit, ok := l.config.Clients[client]
+	if !ok {
+		limit = l.config.Client
+	}
+	return l.take(ctx, "client:"+client, limit)
+}
+
+// AllowAgent takes a token from an agent's bucket. The error is a
+// *LimitedError when the agent is over its limit.
+func (l *Limiter) AllowAgent(ctx context.Context, codename string) error {
+	limit, ok := l.config.Agents[codename]
+	if !ok {
+		limit = l.config.Agent
+	}
+	return l.take(ctx, "agent:"+codename, limit)
+}
+
+// take takes a token from a bucket. A store that fails admits the
+// request, so an unreachable Redis does not take the server down with it.
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) error {
+	if limit.QPS <= 0 {
+		return nil
+	}
+	wait, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		log.Printf("Rate limit store failed, admitting %s: %v", key, err)
+		return nil
+	}
+	if wait > 0 {
+		return &LimitedError{Key: key, Wait: wait}
+	}
+	return nil
+}
+
+// LimitClients is HTTP middleware that limits each client, answering
+// requests over the limit with 429 Too Many Requests and a Retry-After
+// header. Clients are the OIDC subjects of authenticated requests, and
+// the addresses of the others, so it goes after authentication.
+func (l *Limiter) LimitClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := l.AllowClient(r.Context(), clientOf(r))
+		var limited *LimitedError
+		if errors.As(err, &limited) {
+			log.Printf("Request not admitted: %v", err)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(limited.Wait.Seconds())))))
+			errdefs.WriteError(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientOf names the client a request is from.
+func clientOf(r *http.Request) string {
+	if claims := auth.GetClaims(r.Context()); claims != nil && claims.Subject != "" {
+		return claims.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// failingStore fails every take.
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+clients:
+  ci-bot: {qps: 20, burst: 40}
+agents:
+  omniscient: {qps: 0.5}
+`), Config{Client: Limit{QPS: 2}})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if cfg.Client.QPS != 2 || cfg.Clients["ci-bot"].Burst != 40 || cfg.Agents["OMNISCIENT"].QPS != 0.5 {
+		t.Errorf("expected the file's limits over the defaults, got %+v", cfg)
+	}
+	if !cfg.Enabled() || (&Config{}).Enabled() {
+		t.Error("expected only limits with a rate enabled")
+	}
+
+	for _, data := range []string{
+		"agents:\n  APEX: {qps: -1}\n",
+		"client: {qps: 1, burts: 2}\n",
+	} {
+		if _, err := ParseConfig([]byte(data), Config{}); err == nil {
+			t.Errorf("expected %q rejected", data)
+		}
+	}
+}
+
+func TestLimiterAllowAgent(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limiter := New(store, Config{Agent: Limit{QPS: 1, Burst: 2}, Agents: map[string]Limit{"ORACLE": {}}})
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.AllowAgent(context.Background(), "APEX"); err != nil {
+			t.Fatalf("expected the burst admitted, got %v", err)
+		}
+	}
+	err := limiter.AllowAgent(context.Background(), "APEX")
+	var limited *LimitedError
+	if !errors.As(err, &limited) || limited.Key != "agent:APEX" || limited.RetryAfter() != time.Second {
+		t.Fatalf("expected APEX limited for a second, got %v", err)
+	}
+	if !errors.Is(err, ErrRateLimited) || errdefs.HTTPStatus(err) != http.StatusTooManyRequests {
+		t.Errorf("expected a capacity error, got %v", err)
+	}
+
+	// Other agents have buckets of their own, and a zero rate is unlimited
+	if err := limiter.AllowAgent(context.Background(), "ATLAS"); err != nil {
+		t.Errorf("expected ATLAS admitted, got %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := limiter.AllowAgent(context.Background(), "ORACLE"); err != nil {
+			t.Errorf("expected ORACLE unlimited, got %v", err)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if err := limiter.AllowAgent(context.Background(), "APEX"); err != nil {
+		t.Errorf("expected a token after a second, got %v", err)
+	}
+
+	// A store that fails admits requests
+	if err := New(failingStore{}, Config{Agent: Limit{QPS: 1}}).AllowAgent(context.Background(), "APEX"); err != nil {
+		t.Errorf("expected the request admitted, got %v", err)
+	}
+}
+
+func TestLimitClients(t *testing.T) {
+	limiter := New(NewMemoryStore(), Config{Client: Limit{QPS: 0.25, Burst: 1}, Clients: map[string]Limit{"ci-bot": {QPS: 100}}})
+	handler := limiter.LimitClients(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	send := func(subject, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/agent", nil)
+		req.RemoteAddr = addr
+		if subject != "" {
+			req = req.WithContext(context.WithValue(req.Context(), auth.ClaimsContextKey, &auth.Claims{Subject: subject}))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("alice", "10.0.0.1:5000"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected the first request admitted, got %d", w.Code)
+	}
+	w := send("alice", "10.0.0.2:5000")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "4" || !strings.Contains(w.Body.String(), "client:alice") {
+		t.Errorf("expected alice limited for 4s, got %d %q %s", w.Code, w.Header().Get("Retry-After"), w.Body.String())
+	}
+
+	// Clients without a token are limited by address
+	if w := send("", "10.0.0.1:5000"); w.Code != http.StatusNoContent {
+		t.Errorf("expected an address of its own, got %d", w.Code)
+	}
+	if w := send("", "10.0.0.1:6000"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the address limited, got %d", w.Code)
+	}
+	for i := 0; i < 10; i++ {
+		if w := send("ci-bot", "10.0.0.3:5000"); w.Code != http.StatusNoContent {
+			t.Fatalf("expected ci-bot's own limit, got %d", w.Code)
+		}
+	}
+}
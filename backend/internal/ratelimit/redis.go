@@ -0,0 +1,232 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redisTimeout bounds a Redis call whose context has no deadline.
+const redisTimeout = time.Second
+
+// maxBulkString is the longest string Redis replies with.
+const maxBulkString = 512 << 20
+
+// redisKeyPrefix namespaces the buckets in Redis.
+const redisKeyPrefix = "ratelimit:"
+
+// takeScript takes a token from the bucket hash KEYS[1], filled at
+// ARGV[1] tokens a second up to ARGV[2], and returns the milliseconds
+// until a token will be, or 0 when one was taken. Redis' own clock keeps
+// servers with skewed clocks to the same rate, and buckets expire once
+// they would be full again.
+const takeScript = `
+local qps = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(bucket[1]) or burst
+local at = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - at) * qps)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / qps * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / qps * 1000) + 1000)
+return wait
+`
+
+// redisError is an error reply from Redis.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisStore keeps token buckets in Redis, limiting the requests to every
+// server sharing it. It speaks RESP over one connection, redialed after a
+// failure.
+type RedisStore struct {
+	addr     string
+	password string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisStore creates a store in the Redis at addr (host:port),
+// authenticating with password unless it is empty. It connects on first
+// use.
+func NewRedisStore(addr, password string) *RedisStore {
+	return &RedisStore{addr: addr, password: password}
+}
+
+// Take takes a token from the bucket named key.
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	reply, err := s.do(ctx, "EVAL", takeScript, "1", redisKeyPrefix+key,
+		strconv.FormatFloat(limit.QPS, 'g', -1, 64), strconv.Itoa(limit.burst()))
+	if err != nil {
+		return 0, err
+	}
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply %v", reply)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Close closes the connection to Redis.
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.reader = nil, nil
+	return err
+}
+
+// do sends a command and reads its reply. A failed connection is closed,
+// and one that failed after being reused, which Redis may have closed
+// while it was idle, is redialed for a second try; an error reply leaves
+// it open.
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	for retried := false; ; retried = true {
+		reused := s.conn != nil
+		if !reused {
+			if err := s.dial(ctx, deadline); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.conn.SetDeadline(deadline); err != nil {
+			s.drop()
+			return nil, err
+		}
+		reply, err := s.roundTrip(args...)
+		var replyErr redisError
+		if err == nil || errors.As(err, &replyErr) {
+			return reply, err
+		}
+		s.drop()
+		if !reused || retried {
+			return nil, err
+		}
+	}
+}
+
+// dial connects to Redis and authenticates.
+func (s *RedisStore) dial(ctx context.Context, deadline time.Time) error {
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	s.conn, s.reader = conn, bufio.NewReader(conn)
+	if s.password == "" {
+		return nil
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		s.drop()
+		return err
+	}
+	if _, err := s.roundTrip("AUTH", s.password); err != nil {
+		s.drop()
+		return err
+	}
+	return nil
+}
+
+// drop closes a failed connection.
+func (s *RedisStore) drop() {
+	s.conn.Close()
+	s.conn, s.reader = nil, nil
+}
+
+// roundTrip writes a command as an array of bulk strings and reads the
+// reply.
+func (s *RedisStore) roundTrip(args ...string) (interface{}, error) {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := s.conn.Write(buf); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return readReply(s.reader)
+}
+
+// readReply reads one RESP reply: a string, an int64, nil, an error
+// reply as a redisError, or a []interface{} of replies.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed integer %q", body)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 || n > maxBulkString {
+			return nil, fmt.Errorf("redis: malformed bulk string length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("redis: malformed array length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
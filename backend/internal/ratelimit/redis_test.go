@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis answers AUTH and EVAL like Redis, replying to each EVAL with
+// the next of its waits, and hangs up after each EVAL when hangUp is set.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+	hangUp   bool
+
+	mu       sync.Mutex
+	waits    []string
+	commands [][]interface{}
+	dials    int
+}
+
+func newFakeRedis(t *testing.T, password string, waits ...string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	f := &fakeRedis{listener: listener, password: password, waits: waits}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.dials++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		command := reply.([]interface{})
+		f.mu.Lock()
+		f.commands = append(f.commands, command)
+		var out string
+		switch {
+		case command[0] == "AUTH" && command[1] == f.password:
+			out = "+OK\r\n"
+		case command[0] == "AUTH":
+			out = "-WRONGPASS invalid password\r\n"
+		case command[0] == "EVAL" && len(f.waits) > 0:
+			out = ":" + f.waits[0] + "\r\n"
+			f.waits = f.waits[1:]
+		default:
+			out = fmt.Sprintf("-ERR unexpected %v\r\n", command[0])
+		}
+		f.mu.Unlock()
+		if _, err := conn.Write([]byte(out)); err != nil || (f.hangUp && command[0] == "EVAL") {
+			return
+		}
+	}
+}
+
+func TestRedisStoreTake(t *testing.T) {
+	redis := newFakeRedis(t, "secret", "0", "1500")
+	store := NewRedisStore(redis.listener.Addr().String(), "secret")
+	defer store.Close()
+
+	if wait, err := store.Take(context.Background(), "agent:APEX", Limit{QPS: 0.5}); err != nil || wait != 0 {
+		t.Fatalf("expected a token taken, got %s, %v", wait, err)
+	}
+	if wait, err := store.Take(context.Background(), "agent:APEX", Limit{QPS: 0.5}); err != nil || wait != 1500*time.Millisecond {
+		t.Fatalf("expected a wait of 1.5s, got %s, %v", wait, err)
+	}
+
+	redis.mu.Lock()
+	defer redis.mu.Unlock()
+	if redis.dials != 1 || len(redis.commands) != 3 || redis.commands[0][0] != "AUTH" {
+		t.Fatalf("expected one authenticated connection, got %d dials and %v", redis.dials, redis.commands)
+	}
+	eval := redis.commands[1]
+	if eval[0] != "EVAL" || !strings.Contains(eval[1].(string), "redis.call('TIME')") || eval[3] != "ratelimit:agent:APEX" || eval[4] != "0.5" || eval[5] != "1" {
+		t.Errorf("expected the take script for the bucket, got %v", eval)
+	}
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	redis := newFakeRedis(t, "secret", "0", "0")
+	redis.hangUp = true
+	store := NewRedisStore(redis.listener.Addr().String(), "secret")
+	defer store.Close()
+
+	// A connection Redis dropped is redialed
+	for i := 0; i < 2; i++ {
+		if _, err := store.Take(context.Background(), "client:alice", Limit{QPS: 1}); err != nil {
+			t.Fatalf("take %d failed: %v", i, err)
+		}
+	}
+	redis.mu.Lock()
+	dials := redis.dials
+	redis.mu.Unlock()
+	if dials != 2 {
+		t.Errorf("expected a redial, got %d dials", dials)
+	}
+
+	if _, err := NewRedisStore(redis.listener.Addr().String(), "wrong").Take(context.Background(), "client:alice", Limit{QPS: 1}); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("expected the AUTH error, got %v", err)
+	}
+	redis.listener.Close()
+	if _, err := NewRedisStore(redis.listener.Addr().String(), "").Take(context.Background(), "client:alice", Limit{QPS: 1}); err == nil {
+		t.Error("expected an unreachable Redis to fail")
+	}
+}
@@ -0,0 +1,151 @@
+// Package rungroup runs the server's long-lived workers - the HTTP and
+// gRPC servers, warmup, and the loops that sweep goals, sessions and
+// anomalies - as one group, so none of them is a goroutine left to itself.
+// The first worker to fail stops the others and its error is what the
+// group returns; stopping the group waits for every worker to return, up
+// to a timeout that names the ones that didn't:
+//
+//	workers := rungroup.New(ctx, 30*time.Second)
+//	workers.Go("sessions", func(ctx context.Context) error {
+//		sessions.WatchExpiry(ctx, time.Minute)
+//		return nil
+//	})
+//	workers.Serve("http", server.ListenAndServe, server.Shutdown)
+//	<-workers.Context().Done()
+//	err := workers.Wait()
+package rungroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrStopTimeout is returned by Wait when workers are still running once
+// the stop timeout has passed.
+var ErrStopTimeout = errdefs.New(errdefs.ErrUnavailable, "workers did not stop in time")
+
+// Group runs workers until the context it was created with is done or one
+// of them fails. It is safe for concurrent use.
+type Group struct {
+	group       *errgroup.Group
+	ctx         context.Context
+	stopTimeout time.Duration
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// New creates a group stopped when ctx is done. Once stopped, its workers
+// have stopTimeout to return.
+func New(ctx context.Context, stopTimeout time.Duration) *Group {
+	group, ctx := errgroup.WithContext(ctx)
+	return &Group{group: group, ctx: ctx, stopTimeout: stopTimeout, running: make(map[string]int)}
+}
+
+// Context returns the context workers run with, done once the group is
+// stopped or a worker has failed.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go runs a worker named name. The worker returns once ctx is done; it
+// returns nil, or ctx's error, when stopped, and any other error stops the
+// group, returned by Wait prefixed with the worker's name.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	g.running[name]++
+	g.mu.Unlock()
+	g.group.Go(func() error {
+		defer func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if g.running[name]--; g.running[name] == 0 {
+				delete(g.running, name)
+			}
+		}()
+		err := fn(g.ctx)
+		if err == nil || (g.ctx.Err() != nil && errors.Is(err, g.ctx.Err())) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	})
+}
+
+// Serve runs a server as a worker named name: serve blocks while the
+// server runs, and shutdown, called once the group is stopped, stops it
+// within the context it is given, which ends at the stop timeout. The
+// worker returns when both have. A server that stops on its own with an
+// error stops the group; http.ErrServerClosed, returned after shutdown,
+// is not an error.
+func (g *Group) Serve(name string, serve func() error, shutdown func(ctx context.Context) error) {
+	g.Go(name, func(ctx context.Context) error {
+		served := make(chan error, 1)
+		go func() {
+			served <- serve()
+		}()
+		select {
+		case err := <-served:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		case <-ctx.Done():
+		}
+		stopCtx, cancel := context.WithTimeout(context.Background(), g.stopTimeout)
+		defer cancel()
+		shutdownErr := shutdown(stopCtx)
+		if err := <-served; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		if shutdownErr != nil {
+			return fmt.Errorf("shutdown: %w", shutdownErr)
+		}
+		return nil
+	})
+}
+
+// Running returns the names of the workers still running, sorted.
+func (g *Group) Running() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	names := make([]string, 0, len(g.running))
+	for name := range g.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wait waits for every worker to return and returns the first error one
+// failed with. Once the group is stopped, it waits at most the stop
+// timeout, then returns ErrStopTimeout naming the workers still running;
+// they are left to finish on their own.
+func (g *Group) Wait() error {
+	done := make(chan error, 1)
+	go func() {
+		done <- g.group.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-g.ctx.Done():
+	}
+	timer := time.NewTimer(g.stopTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w: %s", ErrStopTimeout, strings.Join(g.Running(), ", "))
+	}
+}
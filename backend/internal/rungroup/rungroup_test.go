@@ -0,0 +1,136 @@
+package rungroup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkNoLeaks fails the test if more goroutines are running than before
+// it, once they have had a moment to return.
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := runtime.NumGoroutine(); n > before {
+			buf := make([]byte, 1<<16)
+			t.Errorf("expected %d goroutines after teardown, got %d:\n%s", before, n, buf[:runtime.Stack(buf, true)])
+		}
+	})
+}
+
+// loop is a worker that runs until its context is done.
+func loop(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestGroupStop(t *testing.T) {
+	checkNoLeaks(t)
+	ctx, stop := context.WithCancel(context.Background())
+	workers := New(ctx, time.Second)
+	workers.Go("goals", loop)
+	workers.Go("sessions", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	if running := workers.Running(); len(running) != 2 || running[0] != "goals" || running[1] != "sessions" {
+		t.Errorf("expected both workers running, got %v", running)
+	}
+
+	stop()
+	if err := workers.Wait(); err != nil {
+		t.Errorf("expected a clean stop, got %v", err)
+	}
+	if running := workers.Running(); len(running) != 0 {
+		t.Errorf("expected no workers running, got %v", running)
+	}
+}
+
+func TestGroupFailure(t *testing.T) {
+	checkNoLeaks(t)
+	failure := errors.New("disk full")
+	workers := New(context.Background(), time.Second)
+	workers.Go("sessions", loop)
+	workers.Go("snapshot", func(ctx context.Context) error {
+		return failure
+	})
+
+	select {
+	case <-workers.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected a failing worker to stop the group")
+	}
+	err := workers.Wait()
+	if !errors.Is(err, failure) || !strings.HasPrefix(err.Error(), "snapshot: ") {
+		t.Errorf("expected the failure named by its worker, got %v", err)
+	}
+}
+
+func TestGroupServe(t *testing.T) {
+	checkNoLeaks(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})}
+	ctx, stop := context.WithCancel(context.Background())
+	workers := New(ctx, time.Second)
+	workers.Serve("http", func() error { return server.Serve(listener) }, server.Shutdown)
+
+	client := &http.Client{Transport: &http.Transport{}}
+	resp, err := client.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	client.CloseIdleConnections()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected the server to answer, got %d", resp.StatusCode)
+	}
+
+	stop()
+	if err := workers.Wait(); err != nil {
+		t.Errorf("expected the server to shut down cleanly, got %v", err)
+	}
+	if _, err := net.Dial("tcp", listener.Addr().String()); err == nil {
+		t.Error("expected the listener closed")
+	}
+
+	// A server that can't serve stops the group
+	failed := New(context.Background(), time.Second)
+	failed.Go("sessions", loop)
+	failed.Serve("grpc", func() error { return errors.New("address in use") }, func(context.Context) error { return nil })
+	if err := failed.Wait(); err == nil || err.Error() != "grpc: address in use" {
+		t.Errorf("expected the server's failure, got %v", err)
+	}
+}
+
+func TestGroupStopTimeout(t *testing.T) {
+	workers := New(context.Background(), 20*time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	workers.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	workers.Go("failing", func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+
+	err := workers.Wait()
+	if !errors.Is(err, ErrStopTimeout) || !strings.HasSuffix(err.Error(), ": stuck") {
+		t.Errorf("expected a timeout naming the stuck worker, got %v", err)
+	}
+}
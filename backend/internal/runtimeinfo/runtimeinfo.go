@@ -0,0 +1,154 @@
+// Package runtimeinfo describes the running server in machine-readable
+// form: the build and its git revision, the configuration profile and
+// effective settings, feature flags, and the versions of the formats its
+// subsystems read and write. Deployment tooling compares it with what it
+// meant to roll out.
+package runtimeinfo
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// Version and Revision identify the build. Builds from a git checkout
+// record the revision themselves; container builds, which have no .git,
+// set both with
+//
+//	-ldflags "-X .../internal/runtimeinfo.Version=v2.1.0 -X .../internal/runtimeinfo.Revision=$GIT_SHA"
+var (
+	Version  = ""
+	Revision = ""
+)
+
+// Build describes the binary.
+type Build struct {
+	// Version is the release, or the module version, or "devel"
+	Version string `json:"version"`
+	// Revision is the git commit the binary was built from, if known
+	Revision string `json:"revision,omitempty"`
+	// RevisionTime is the commit time, if known
+	RevisionTime string `json:"revision_time,omitempty"`
+	// Modified reports uncommitted changes in the build's checkout
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Module    string `json:"module,omitempty"`
+}
+
+// Setting is one effective configuration value; secrets are masked.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Info is the body of GET /admin/runtime.
+type Info struct {
+	Build   Build  `json:"build"`
+	Profile string `json:"profile"`
+	// Features are the feature flags' current values and tenant overrides
+	Features []*features.FlagStatus `json:"features"`
+	// Subsystems maps each subsystem to the version of its format or API
+	Subsystems map[string]string `json:"subsystems"`
+	Config     []Setting         `json:"config"`
+	StartedAt  time.Time         `json:"started_at"`
+	// UptimeSeconds is the time since StartedAt
+	UptimeSeconds int64 `json:"uptime_seconds"`
+}
+
+// Reporter reports the runtime of this process.
+type Reporter struct {
+	build      Build
+	cfg        *config.Config
+	flags      *features.Flags
+	subsystems map[string]string
+	started    time.Time
+	now        func() time.Time
+}
+
+// New creates a reporter for a server started now with cfg and flags;
+// flags may be nil.
+func New(cfg *config.Config, flags *features.Flags) *Reporter {
+	return &Reporter{
+		build:      readBuild(),
+		cfg:        cfg,
+		flags:      flags,
+		subsystems: make(map[string]string),
+		started:    time.Now(),
+		now:        time.Now,
+	}
+}
+
+// SetSubsystem records the version of a subsystem's format or API. Set
+// before the reporter is shared between goroutines.
+func (rep *Reporter) SetSubsystem(name, version string) {
+	rep.subsystems[name] = version
+}
+
+// Info returns the current runtime description.
+func (rep *Reporter) Info() *Info {
+	info := &Info{
+		Build:         rep.build,
+		Profile:       rep.cfg.Profile,
+		Features:      make([]*features.FlagStatus, 0),
+		Subsystems:    make(map[string]string, len(rep.subsystems)),
+		Config:        make([]Setting, 0),
+		StartedAt:     rep.started.UTC(),
+		UptimeSeconds: int64(rep.now().Sub(rep.started) / time.Second),
+	}
+	if rep.flags != nil {
+		info.Features = rep.flags.List()
+	}
+	for name, version := range rep.subsystems {
+		info.Subsystems[name] = version
+	}
+	for _, s := range rep.cfg.Settings() {
+		info.Config = append(info.Config, Setting{Key: s.Key, Value: s.Value, Source: s.Source})
+	}
+	return info
+}
+
+// ServeHTTP handles GET /admin/runtime.
+func (rep *Reporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	errdefs.WriteJSON(w, http.StatusOK, rep.Info())
+}
+
+// readBuild describes the running binary from the build information Go
+// embeds, overridden by Version and Revision where set.
+func readBuild() Build {
+	build := Build{
+		Version:   "devel",
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		build.Module = bi.Main.Path
+		if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			build.Version = bi.Main.Version
+		}
+		settings := make(map[string]string, len(bi.Settings))
+		for _, s := range bi.Settings {
+			settings[s.Key] = s.Value
+		}
+		build.Revision = settings["vcs.revision"]
+		build.RevisionTime = settings["vcs.time"]
+		build.Modified = settings["vcs.modified"] == "true"
+	}
+	if Version != "" {
+		build.Version = Version
+	}
+	if Revision != "" && Revision != build.Revision {
+		// A revision set at link time describes a checkout Go could not see
+		build.Revision = Revision
+		build.RevisionTime = ""
+		build.Modified = false
+	}
+	return build
+}
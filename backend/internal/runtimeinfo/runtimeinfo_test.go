@@ -0,0 +1,90 @@
+package runtimeinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+func TestServeRuntime(t *testing.T) {
+	cfg, err := config.Load([]string{"-profile", "dev", "-session-signing-key", "s3cret"})
+	if err != nil {
+		t.Fatalf("expected configuration to load, got %v", err)
+	}
+	flags := features.New(nil)
+	if err := flags.Set(features.AutoLearning, "acme", false); err != nil {
+		t.Fatal(err)
+	}
+
+	rep := New(cfg, flags)
+	rep.SetSubsystem("session_bundle", "elite-agent-collective/session/v1")
+	rep.SetSubsystem("semantic_snapshot", "3")
+	rep.now = func() time.Time { return rep.started.Add(90 * time.Second) }
+
+	rec := httptest.NewRecorder()
+	rep.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/runtime", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("expected runtime info not to be cached, got %q", cc)
+	}
+	if strings.Contains(rec.Body.String(), "s3cret") {
+		t.Errorf("expected secrets masked, got %s", rec.Body.String())
+	}
+
+	var info Info
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("expected JSON, got %v", err)
+	}
+	if info.Profile != "dev" {
+		t.Errorf("expected the dev profile, got %q", info.Profile)
+	}
+	if info.Build.GoVersion != runtime.Version() || info.Build.Version == "" {
+		t.Errorf("expected build info, got %+v", info.Build)
+	}
+	if info.Subsystems["session_bundle"] != "elite-agent-collective/session/v1" || info.Subsystems["semantic_snapshot"] != "3" {
+		t.Errorf("expected subsystem versions, got %v", info.Subsystems)
+	}
+	if info.UptimeSeconds != 90 {
+		t.Errorf("expected 90s uptime, got %d", info.UptimeSeconds)
+	}
+
+	overridden := false
+	for _, flag := range info.Features {
+		if flag.Name == features.AutoLearning {
+			overridden = !flag.Tenants["acme"] && len(flag.Tenants) == 1
+		}
+	}
+	if len(info.Features) == 0 || !overridden {
+		t.Errorf("expected feature flags with tenant overrides, got %+v", info.Features)
+	}
+
+	sources := make(map[string]string)
+	for _, s := range info.Config {
+		sources[s.Key] = s.Source
+	}
+	if sources["profile"] != "flag -profile" || sources["session_signing_key"] == "" {
+		t.Errorf("expected settings with their sources, got %v", sources)
+	}
+}
+
+func TestReadBuildLinkerOverrides(t *testing.T) {
+	defer func(version, revision string) { Version, Revision = version, revision }(Version, Revision)
+	Version, Revision = "v2.1.0", "0123456789abcdef"
+
+	build := readBuild()
+	if build.Version != "v2.1.0" || build.Revision != "0123456789abcdef" {
+		t.Errorf("expected the linker's version and revision, got %+v", build)
+	}
+	if build.Modified || build.RevisionTime != "" {
+		t.Errorf("expected no VCS details for a linker revision, got %+v", build)
+	}
+}
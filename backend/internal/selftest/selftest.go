@@ -0,0 +1,348 @@
+// Package selftest checks the server's pipeline end to end before it takes
+// traffic: the agent registry, one invocation of a stubbed agent, a
+// knowledge graph round trip through a snapshot file, the configured
+// persistence and the authentication configuration. The server runs it
+// with -selftest and exits nonzero when a check fails, so a deployment can
+// be gated on it.
+package selftest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// DefaultTimeout bounds a whole self-test run.
+const DefaultTimeout = 30 * time.Second
+
+// invocationAgent is the agent the invocation check sends its prompt to.
+const invocationAgent = "APEX"
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass checks found nothing wrong
+	StatusPass Status = "pass"
+	// StatusFail checks found a problem that should stop a deployment
+	StatusFail Status = "fail"
+	// StatusSkip checks did not apply to this configuration
+	StatusSkip Status = "skip"
+)
+
+// errSkipped marks a check that did not apply.
+var errSkipped = errors.New("skipped")
+
+// Skip returns the error a check reports when it does not apply.
+func Skip(reason string) error {
+	return fmt.Errorf("%w: %s", errSkipped, reason)
+}
+
+// Check is one step of the self-test. Run returns a short description of
+// what it verified, or the error that failed it.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Detail   string        `json:"detail"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a self-test run.
+type Report struct {
+	Results []Result `json:"results"`
+	Passed  bool     `json:"passed"`
+}
+
+// Run runs the checks in order. Every check runs, so the report shows all
+// failures at once; a check that panics fails.
+func Run(ctx context.Context, checks []Check) *Report {
+	report := &Report{Results: make([]Result, 0, len(checks)), Passed: true}
+	for _, check := range checks {
+		result := run(ctx, check)
+		if result.Status == StatusFail {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// run runs one check.
+func run(ctx context.Context, check Check) (result Result) {
+	start := time.Now()
+	result = Result{Name: check.Name, Status: StatusPass}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status, result.Detail = StatusFail, fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	if err := ctx.Err(); err != nil {
+		result.Status, result.Detail = StatusFail, err.Error()
+		return result
+	}
+	detail, err := check.Run(ctx)
+	switch {
+	case errors.Is(err, errSkipped):
+		result.Status, result.Detail = StatusSkip, strings.TrimPrefix(err.Error(), errSkipped.Error()+": ")
+	case err != nil:
+		result.Status, result.Detail = StatusFail, err.Error()
+	default:
+		result.Detail = detail
+	}
+	return result
+}
+
+// Write prints the report as a table followed by a verdict line.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tTIME\tDETAIL")
+	failed := 0
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Name, strings.ToUpper(string(result.Status)), result.Duration.Round(time.Millisecond), result.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if r.Passed {
+		_, err := fmt.Fprintf(w, "\nSelf-test passed: %d checks\n", len(r.Results))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nSelf-test failed: %d of %d checks\n", failed, len(r.Results))
+	return err
+}
+
+// ============================================================================
+// Checks
+// ============================================================================
+
+// DefaultChecks are the checks the server runs with -selftest.
+func DefaultChecks(cfg *config.Config, registry *agents.Registry) []Check {
+	return []Check{
+		RegistryCheck(registry),
+		InvocationCheck(registry),
+		MemoryCheck(),
+		PersistenceCheck(cfg.Memory),
+		AuthCheck(cfg),
+	}
+}
+
+// RegistryCheck verifies that agents are registered and every listed agent
+// resolves to a handler.
+func RegistryCheck(registry *agents.Registry) Check {
+	return Check{
+		Name: "registry",
+		Run: func(ctx context.Context) (string, error) {
+			listed := registry.List()
+			if len(listed) == 0 {
+				return "", errors.New("no agents registered")
+			}
+			var missing []string
+			for _, agent := range listed {
+				if _, err := registry.Get(agent.Codename); err != nil {
+					missing = append(missing, agent.Codename)
+				}
+			}
+			if len(missing) > 0 {
+				return "", fmt.Errorf("listed agents without a handler: %s", strings.Join(missing, ", "))
+			}
+			return fmt.Sprintf("%d agents registered", len(listed)), nil
+		},
+	}
+}
+
+// InvocationCheck sends a prompt to a stubbed agent through the registry,
+// under its tier's quota, and verifies the reply addresses it.
+func InvocationCheck(registry *agents.Registry) Check {
+	return Check{
+		Name: "invocation",
+		Run: func(ctx context.Context) (string, error) {
+			const prompt = "self-test: confirm the invocation pipeline"
+			codename, reply, err := registry.Invoke(ctx, invocationAgent, prompt)
+			if err != nil {
+				return "", fmt.Errorf("invoking %s: %w", invocationAgent, err)
+			}
+			if !strings.Contains(reply, prompt) {
+				return "", fmt.Errorf("%s replied without addressing the prompt", codename)
+			}
+			return fmt.Sprintf("%s replied with %d bytes", codename, len(reply)), nil
+		},
+	}
+}
+
+// MemoryCheck adds a node to an empty knowledge graph, retrieves it, saves
+// the graph to a snapshot file in a temporary directory and restores it
+// into a second graph.
+func MemoryCheck() Check {
+	return Check{
+		Name: "memory round trip",
+		Run: func(ctx context.Context) (string, error) {
+			network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+			node := memory.NewSemanticNode("selftest", "Self-test", memory.ConceptNode)
+			node.SetProperty("purpose", memory.StringValue("deployment gate"))
+			if err := network.AddNode(node); err != nil {
+				return "", fmt.Errorf("adding node: %w", err)
+			}
+			if _, err := network.GetNode(node.ID); err != nil {
+				return "", fmt.Errorf("retrieving node: %w", err)
+			}
+
+			dir, err := os.MkdirTemp("", "eac-selftest-")
+			if err != nil {
+				return "", err
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "semantic.snapshot")
+			if err := memory.SaveSnapshotFile(path, network.Snapshot()); err != nil {
+				return "", fmt.Errorf("saving snapshot: %w", err)
+			}
+			snapshot, err := memory.LoadSnapshotFile(path)
+			if err != nil {
+				return "", fmt.Errorf("loading snapshot: %w", err)
+			}
+
+			restored := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+			if err := restored.Restore(snapshot); err != nil {
+				return "", fmt.Errorf("restoring snapshot: %w", err)
+			}
+			got, err := restored.GetNode(node.ID)
+			if err != nil {
+				return "", fmt.Errorf("retrieving restored node: %w", err)
+			}
+			if value, ok := got.GetProperty("purpose"); !ok || fmt.Sprint(value) != "deployment gate" {
+				return "", errors.New("restored node lost its properties")
+			}
+			if err := restored.VerifyIntegrity(); err != nil {
+				return "", fmt.Errorf("restored graph: %w", err)
+			}
+			return "add, retrieve, snapshot and restore", nil
+		},
+	}
+}
+
+// PersistenceCheck verifies that the configured snapshot loads and that
+// the directories of the snapshot and write-ahead log are writable.
+func PersistenceCheck(cfg config.MemoryConfig) Check {
+	return Check{
+		Name: "memory persistence",
+		Run: func(ctx context.Context) (string, error) {
+			if cfg.WALPath == "" && cfg.SnapshotPath == "" {
+				return "", Skip("no write-ahead log or snapshot configured")
+			}
+			var verified []string
+			for _, path := range []string{cfg.WALPath, cfg.SnapshotPath} {
+				if path == "" {
+					continue
+				}
+				if err := checkWritable(filepath.Dir(path)); err != nil {
+					return "", err
+				}
+			}
+			if cfg.SnapshotPath != "" {
+				snapshot, err := memory.LoadSnapshotFile(cfg.SnapshotPath)
+				switch {
+				case errors.Is(err, os.ErrNotExist):
+					verified = append(verified, "no snapshot yet")
+				case err != nil:
+					return "", fmt.Errorf("loading snapshot %s: %w", cfg.SnapshotPath, err)
+				default:
+					verified = append(verified, fmt.Sprintf("snapshot has %d nodes", len(snapshot.Nodes)))
+				}
+			}
+			verified = append(verified, "directories writable")
+			return strings.Join(verified, ", "), nil
+		},
+	}
+}
+
+// checkWritable creates and removes a file in a directory, creating the
+// directory first as the server does.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	file, err := os.CreateTemp(dir, ".selftest-")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// AuthCheck verifies the authentication configuration: that the API keys
+// load, that the OIDC provider serves signing keys, that webhook
+// signatures round-trip, and that the GitHub App key parses. The provider is not contacted in offline
+// mode.
+func AuthCheck(cfg *config.Config) Check {
+	return Check{
+		Name: "auth",
+		Run: func(ctx context.Context) (string, error) {
+			var verified []string
+			if cfg.APIKeysConfig != "" {
+				keys, err := auth.LoadAPIKeys(cfg.APIKeysConfig)
+				if err != nil {
+					return "", err
+				}
+				verified = append(verified, fmt.Sprintf("%d API keys load", keys.Len()))
+			}
+			switch {
+			case cfg.OIDC.ClientID == "" && cfg.APIKeysConfig != "":
+				verified = append(verified, "OIDC disabled")
+			case cfg.OIDC.ClientID == "":
+				verified = append(verified, "authentication disabled")
+				if len(cfg.AdminSubjects) > 0 {
+					verified = append(verified, "admin API open to any caller")
+				}
+			case cfg.Offline:
+				verified = append(verified, "OIDC provider not contacted offline")
+			default:
+				keys, err := auth.NewOIDCValidator(&cfg.OIDC).CheckProvider()
+				if err != nil {
+					return "", fmt.Errorf("OIDC provider %s: %w", cfg.OIDC.Issuer, err)
+				}
+				verified = append(verified, fmt.Sprintf("OIDC provider serves %d signing keys", keys))
+				if len(cfg.AdminSubjects) == 0 {
+					verified = append(verified, "no admin subjects")
+				}
+			}
+
+			if secret := cfg.GitHub.WebhookSecret; secret != "" {
+				body := []byte(`{"selftest":true}`)
+				if err := auth.ValidateSignature(secret, auth.ComputeSignature(secret, body), body); err != nil {
+					return "", fmt.Errorf("webhook signature: %w", err)
+				}
+				verified = append(verified, "webhook signatures verify")
+			}
+			if key := cfg.GitHub.PrivateKey; key != "" {
+				if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key)); err != nil {
+					return "", fmt.Errorf("GitHub App private key: %w", err)
+				}
+				verified = append(verified, "GitHub App key parses")
+			}
+			return strings.Join(verified, ", "), nil
+		},
+	}
+}
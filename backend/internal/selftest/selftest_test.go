@@ -0,0 +1,160 @@
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents/handlers"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/config"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+func testRegistry() *agents.Registry {
+	registry := agents.NewRegistry()
+	registry.Register(handlers.NewBaseAgent(models.Agent{ID: "01", Codename: "APEX", Tier: 1, Specialty: "Computer Science"}))
+	return registry
+}
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		{Name: "ok", Run: func(ctx context.Context) (string, error) { return "fine", nil }},
+		{Name: "skipped", Run: func(ctx context.Context) (string, error) { return "", Skip("not configured") }},
+		{Name: "broken", Run: func(ctx context.Context) (string, error) { return "", errors.New("disk on fire") }},
+		{Name: "panics", Run: func(ctx context.Context) (string, error) { panic("boom") }},
+	})
+
+	if report.Passed {
+		t.Error("expected the report to fail")
+	}
+	want := []Status{StatusPass, StatusSkip, StatusFail, StatusFail}
+	for i, result := range report.Results {
+		if result.Status != want[i] {
+			t.Errorf("expected %s to %s, got %s", result.Name, want[i], result.Status)
+		}
+	}
+	if report.Results[1].Detail != "not configured" || report.Results[3].Detail != "panic: boom" {
+		t.Errorf("expected skip and panic details, got %+v", report.Results)
+	}
+
+	var out bytes.Buffer
+	report.Write(&out)
+	if !strings.Contains(out.String(), "disk on fire") || !strings.Contains(out.String(), "Self-test failed: 2 of 4 checks") {
+		t.Errorf("expected a diagnostic report, got:\n%s", out.String())
+	}
+}
+
+func TestDefaultChecks(t *testing.T) {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	cfg.Memory.WALPath = filepath.Join(dir, "wal", "semantic.wal")
+	cfg.Memory.SnapshotPath = filepath.Join(dir, "semantic.snapshot")
+
+	network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	network.AddNode(memory.NewSemanticNode("apex", "APEX", memory.AgentNode))
+	if err := memory.SaveSnapshotFile(cfg.Memory.SnapshotPath, network.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(context.Background(), DefaultChecks(cfg, testRegistry()))
+	if !report.Passed {
+		var out bytes.Buffer
+		report.Write(&out)
+		t.Fatalf("expected the self-test to pass, got:\n%s", out.String())
+	}
+	if detail := report.Results[3].Detail; !strings.Contains(detail, "snapshot has 1 nodes") {
+		t.Errorf("expected the configured snapshot loaded, got %q", detail)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wal")); err != nil {
+		t.Errorf("expected the log directory created, got %v", err)
+	}
+}
+
+func TestRegistryCheckEmpty(t *testing.T) {
+	report := Run(context.Background(), []Check{RegistryCheck(agents.NewRegistry()), InvocationCheck(agents.NewRegistry())})
+	for _, result := range report.Results {
+		if result.Status != StatusFail {
+			t.Errorf("expected %s to fail without agents, got %s", result.Name, result.Status)
+		}
+	}
+}
+
+func TestPersistenceCheckCorruptSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "semantic.snapshot")
+	os.WriteFile(path, []byte("not a snapshot"), 0o644)
+
+	_, err := PersistenceCheck(config.MemoryConfig{SnapshotPath: path}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "loading snapshot") {
+		t.Errorf("expected a corrupt snapshot to fail, got %v", err)
+	}
+}
+
+func TestAuthCheck(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwks" {
+			json.NewEncoder(w).Encode(auth.JWKS{Keys: []auth.JWK{{
+				Kty: "RSA",
+				Kid: "k1",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+			return
+		}
+		json.NewEncoder(w).Encode(auth.OIDCDiscovery{Issuer: server.URL, JWKSURI: server.URL + "/jwks"})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.OIDC = config.OIDCConfig{Issuer: server.URL, ClientID: "eac"}
+	cfg.GitHub.WebhookSecret = "shh"
+	cfg.GitHub.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	detail, err := AuthCheck(cfg).Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected the auth check to pass, got %v", err)
+	}
+	for _, want := range []string{"serves 1 signing keys", "webhook signatures verify", "GitHub App key parses", "no admin subjects"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("expected %q in %q", want, detail)
+		}
+	}
+
+	cfg.GitHub.PrivateKey = "not a key"
+	if _, err := AuthCheck(cfg).Run(context.Background()); err == nil || !strings.Contains(err.Error(), "GitHub App private key") {
+		t.Errorf("expected a malformed key to fail, got %v", err)
+	}
+
+	server.Close()
+	cfg.GitHub.PrivateKey = ""
+	if _, err := AuthCheck(cfg).Run(context.Background()); err == nil || !strings.Contains(err.Error(), "OIDC provider") {
+		t.Errorf("expected an unreachable provider to fail, got %v", err)
+	}
+	cfg.Offline = true
+	if _, err := AuthCheck(cfg).Run(context.Background()); err != nil {
+		t.Errorf("expected the provider skipped offline, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+var (
+	// ErrInvalidBundle is returned for bundles that cannot be imported.
+	ErrInvalidBundle = errdefs.New(errdefs.ErrInvalidArgument, "invalid session bundle")
+	// ErrSigningDisabled is returned for exports and imports without a
+	// signing key.
+	ErrSigningDisabled = errdefs.New(errdefs.ErrUnavailable, "session export is disabled")
+)
+
+// BundleFormat identifies the bundle layout; bundles of other formats are
+// rejected.
+const BundleFormat = "elite-agent-collective/session/v1"
+
+// Bundle is an exported session. The signature is the HMAC-SHA256 of the
+// compacted payload, in the form used for webhook signatures.
+type Bundle struct {
+	Format    string          `json:"format"`
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+}
+
+// BundlePayload is what a bundle carries.
+type BundlePayload struct {
+	Session    *Session  `json:"session"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Export returns a signed bundle of a user's session.
+func (s *Store) Export(tenant, user, id string) (*Bundle, error) {
+	if s.config.SigningKey == "" {
+		return nil, ErrSigningDisabled
+	}
+	session, err := s.Get(tenant, user, id)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(&BundlePayload{Session: session, ExportedAt: s.now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	return &Bundle{
+		Format:    BundleFormat,
+		Payload:   payload,
+		Signature: auth.ComputeSignature(s.config.SigningKey, payload),
+	}, nil
+}
+
+// Import verifies a bundle and stores its session as the user's, keeping
+// its ID. The error wraps auth.ErrInvalidSignature for bundles not signed
+// with this store's key and ErrSessionExists if the user already has a
+// session with the ID.
+func (s *Store) Import(tenant, user string, bundle *Bundle) (*Session, error) {
+	if s.config.SigningKey == "" {
+		return nil, ErrSigningDisabled
+	}
+	if bundle.Format != BundleFormat {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidBundle, bundle.Format)
+	}
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, bundle.Payload); err != nil {
+		return nil, fmt.Errorf("%w: payload is not JSON", ErrInvalidBundle)
+	}
+	if err := auth.ValidateSignature(s.config.SigningKey, bundle.Signature, payload.Bytes()); err != nil {
+		return nil, err
+	}
+	var decoded BundlePayload
+	if err := json.Unmarshal(payload.Bytes(), &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if err := validate(decoded.Session); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	k := key{tenant: tenant, user: user, id: decoded.Session.ID}
+	if _, ok := s.sessions[k]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, k.id)
+	}
+	stored := restore(k, decoded.Session, s.now())
+	// An imported session is fresh, however long ago it was exported
+	stored.UpdatedAt = s.now()
+	s.add(k, stored)
+	return stored.snapshot(), nil
+}
+
+// validate checks an imported session against the limits of this store.
+func validate(session *Session) error {
+	switch {
+	case session == nil:
+		return fmt.Errorf("%w: no session", ErrInvalidBundle)
+	case !idPattern.MatchString(session.ID):
+		return fmt.Errorf("%w: malformed session ID %q", ErrInvalidBundle, session.ID)
+	case len(session.Turns) > maxTurns:
+		return fmt.Errorf("%w: more than %d turns", ErrInvalidBundle, maxTurns)
+	case len(session.Context) > maxContext:
+		return fmt.Errorf("%w: more than %d context references", ErrInvalidBundle, maxContext)
+	}
+	return nil
+}
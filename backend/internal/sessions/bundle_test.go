@@ -0,0 +1,81 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+func TestExportImport(t *testing.T) {
+	config := Config{SigningKey: "shared-secret"}
+	source := NewStore(config, newTestLinker())
+	source.Record(sessionContext("alice", "s1"), "APEX", "Explain QuickSort", "It partitions.")
+
+	bundle, err := source.Export("acme", "alice", "s1")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if bundle.Format != BundleFormat || bundle.Signature == "" {
+		t.Errorf("expected a signed bundle, got %+v", bundle)
+	}
+
+	// Bundles survive being pretty-printed on the way
+	var indented bytes.Buffer
+	json.Indent(&indented, bundle.Payload, "", "  ")
+	copied := *bundle
+	copied.Payload = indented.Bytes()
+
+	target := NewStore(config, nil)
+	session, err := target.Import("globex", "alice@laptop", &copied)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if session.ID != "s1" || session.Tenant != "globex" || session.User != "alice@laptop" || session.ImportedAt == nil {
+		t.Errorf("expected the session stored as the importer's, got %+v", session)
+	}
+	if len(session.Turns) != 1 || session.Turns[0].Answer != "It partitions." {
+		t.Errorf("expected the conversation imported, got %+v", session.Turns)
+	}
+	if len(session.Context) != 1 || len(session.WorkingMemory) != 2 {
+		t.Errorf("expected context and working memory imported, got %+v and %+v", session.Context, session.WorkingMemory)
+	}
+	if history := target.History(sessionContext("alice@laptop", "s1")); history != nil {
+		t.Error("expected no history in another tenant")
+	}
+
+	if _, err := target.Import("globex", "alice@laptop", bundle); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("expected ErrSessionExists, got %v", err)
+	}
+}
+
+func TestImportRejects(t *testing.T) {
+	source := NewStore(Config{SigningKey: "shared-secret"}, nil)
+	source.Record(sessionContext("alice", "s1"), "APEX", "hello", "hi")
+	bundle, _ := source.Export("acme", "alice", "s1")
+
+	if _, err := NewStore(Config{SigningKey: "other-secret"}, nil).Import("acme", "alice", bundle); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for another key, got %v", err)
+	}
+
+	tampered := *bundle
+	tampered.Payload = bytes.Replace(bundle.Payload, []byte(`"hi"`), []byte(`"bye"`), 1)
+	if _, err := NewStore(Config{SigningKey: "shared-secret"}, nil).Import("acme", "alice", &tampered); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a modified payload, got %v", err)
+	}
+
+	wrongFormat := *bundle
+	wrongFormat.Format = "v0"
+	if _, err := NewStore(Config{SigningKey: "shared-secret"}, nil).Import("acme", "alice", &wrongFormat); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("expected ErrInvalidBundle, got %v", err)
+	}
+
+	if _, err := NewStore(DefaultConfig(), nil).Import("acme", "alice", bundle); !errors.Is(err, ErrSigningDisabled) {
+		t.Errorf("expected ErrSigningDisabled without a key, got %v", err)
+	}
+	if _, err := NewStore(DefaultConfig(), nil).Export("acme", "alice", "s1"); !errors.Is(err, ErrSigningDisabled) {
+		t.Errorf("expected ErrSigningDisabled without a key, got %v", err)
+	}
+}
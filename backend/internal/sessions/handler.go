@@ -0,0 +1,128 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// maxBundleBody bounds the size of an imported bundle.
+const maxBundleBody = 4 << 20
+
+// ListResponse is the body of GET /sessions.
+type ListResponse struct {
+	Sessions []Summary `json:"sessions"`
+}
+
+// ServeList handles GET /sessions - lists the caller's sessions.
+func (s *Store) ServeList(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, &ListResponse{Sessions: s.List(tenant, user)})
+}
+
+// ServeGet handles GET /sessions/{id} - returns one of the caller's
+// sessions.
+func (s *Store) ServeGet(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	session, err := s.Get(tenant, user, chi.URLParam(r, "id"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, session)
+}
+
+// ServeState handles GET /sessions/{id}/state - returns what one of the
+// caller's sessions is attending to and pursuing.
+func (s *Store) ServeState(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	state, err := s.State(tenant, user, chi.URLParam(r, "id"))
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusOK, state)
+}
+
+// ServeDelete handles DELETE /sessions/{id} - deletes one of the caller's
+// sessions.
+func (s *Store) ServeDelete(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	if err := s.Delete(tenant, user, chi.URLParam(r, "id")); err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeExport handles GET /sessions/{id}/export - returns a signed bundle
+// of one of the caller's sessions.
+func (s *Store) ServeExport(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	bundle, err := s.Export(tenant, user, id)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="session-`+id+`.json"`)
+	errdefs.WriteJSON(w, http.StatusOK, bundle)
+}
+
+// ServeImport handles POST /sessions/import - verifies a bundle and
+// stores its session as the caller's.
+func (s *Store) ServeImport(w http.ResponseWriter, r *http.Request) {
+	tenant, user, ok := caller(w, r)
+	if !ok {
+		return
+	}
+	var bundle Bundle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleBody)).Decode(&bundle); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			errdefs.WriteError(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	session, err := s.Import(tenant, user, &bundle)
+	if err != nil {
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	log.Printf("Imported session %s for %s in tenant %q", session.ID, user, tenant)
+	errdefs.WriteJSON(w, http.StatusCreated, session)
+}
+
+// caller returns the tenant and authenticated user a request is made for,
+// writing a 401 response for anonymous requests.
+func caller(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	claims := auth.GetClaims(r.Context())
+	if claims == nil || claims.Subject == "" {
+		errdefs.WriteError(w, "an authenticated user is required", http.StatusUnauthorized)
+		return "", "", false
+	}
+	return features.TenantFromContext(r.Context()), claims.Subject, true
+}
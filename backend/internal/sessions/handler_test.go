@@ -0,0 +1,90 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+)
+
+// newTestRouter serves a store's API, authenticating requests as the
+// subject in the X-Test-Subject header.
+func newTestRouter(store *Store) http.Handler {
+	r := chi.NewRouter()
+	r.Use(features.Tenant)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subject := r.Header.Get("X-Test-Subject"); subject != "" {
+				r = r.WithContext(context.WithValue(r.Context(), auth.ClaimsContextKey, &auth.Claims{Subject: subject}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/sessions", store.ServeList)
+	r.Post("/sessions/import", store.ServeImport)
+	r.Get("/sessions/{id}", store.ServeGet)
+	r.Delete("/sessions/{id}", store.ServeDelete)
+	r.Get("/sessions/{id}/export", store.ServeExport)
+	r.Get("/sessions/{id}/state", store.ServeState)
+	return r
+}
+
+func serve(router http.Handler, method, path, subject, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(features.TenantHeader, "acme")
+	if subject != "" {
+		req.Header.Set("X-Test-Subject", subject)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSessionsAPI(t *testing.T) {
+	store := NewStore(Config{SigningKey: "shared-secret"}, nil)
+	store.Record(sessionContext("alice", "s1"), "APEX", "hello", "hi")
+	router := newTestRouter(store)
+
+	if w := serve(router, http.MethodGet, "/sessions", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an anonymous request, got %d", w.Code)
+	}
+	w := serve(router, http.MethodGet, "/sessions", "alice", "")
+	var list ListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Sessions) != 1 || list.Sessions[0].Turns != 1 {
+		t.Errorf("expected alice's session listed, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(router, http.MethodGet, "/sessions/s1", "bob", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another user's session, got %d", w.Code)
+	}
+
+	w = serve(router, http.MethodGet, "/sessions/s1/export", "alice", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "session-s1.json") {
+		t.Fatalf("expected a bundle download, got %d: %s", w.Code, w.Body.String())
+	}
+	bundle := w.Body.String()
+
+	if w := serve(router, http.MethodPost, "/sessions/import", "alice", bundle); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 importing over an existing session, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodPost, "/sessions/import", "bob", bundle); w.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	tampered := strings.Replace(bundle, `"hi"`, `"bye"`, 1)
+	if w := serve(router, http.MethodPost, "/sessions/import", "carol", tampered); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a tampered bundle, got %d", w.Code)
+	}
+
+	if w := serve(router, http.MethodDelete, "/sessions/s1", "alice", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", w.Code)
+	}
+	if w := serve(router, http.MethodGet, "/sessions/s1", "alice", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,294 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// PrefetchConfig configures the working set prefetched when a session
+// starts.
+type PrefetchConfig struct {
+	// Episodes bounds the user's recent turns from their other sessions
+	Episodes int
+	// Nodes bounds the knowledge graph nodes around them
+	Nodes int
+	// Wait is how long a request waits for an unfinished prefetch before
+	// going without it
+	Wait time.Duration
+}
+
+// DefaultPrefetchConfig returns the default prefetch configuration.
+func DefaultPrefetchConfig() PrefetchConfig {
+	return PrefetchConfig{Episodes: 5, Nodes: 20, Wait: 250 * time.Millisecond}
+}
+
+// Graph expands the nodes recent sessions drew on into the subgraph
+// around them.
+type Graph interface {
+	GetNode(id string) (*memory.SemanticNode, error)
+	GetOutgoingRelations(nodeID string) []*memory.SemanticRelation
+}
+
+// Profiles supplies the preferences of the user a request is made for, as
+// an instruction to agents, or "" when none are known.
+type Profiles interface {
+	Prompt(ctx context.Context) string
+}
+
+// WorkingSet is the context prefetched for a session when it starts, so
+// its first turn does not wait on retrieval.
+type WorkingSet struct {
+	// Episodes are the user's most recent turns in other sessions, newest
+	// first
+	Episodes []Turn `json:"episodes"`
+	// Nodes are the nodes those sessions drew on, then the nodes they
+	// relate to
+	Nodes []ContextRef `json:"nodes"`
+	// Preferences is the user's preference prompt when the session started
+	Preferences string    `json:"preferences,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// message renders the episodes and nodes as a system message.
+func (ws *WorkingSet) message() (models.Message, bool) {
+	if len(ws.Episodes) == 0 && len(ws.Nodes) == 0 {
+		return models.Message{}, false
+	}
+	var b strings.Builder
+	b.WriteString("Context from the user's recent sessions.")
+	if len(ws.Episodes) > 0 {
+		b.WriteString("\nRecent questions:")
+		for _, turn := range ws.Episodes {
+			b.WriteString("\n- ")
+			b.WriteString(turn.Query)
+			if turn.Agent != "" {
+				b.WriteString(" (" + turn.Agent + ")")
+			}
+		}
+	}
+	if len(ws.Nodes) > 0 {
+		labels := make([]string, 0, len(ws.Nodes))
+		for _, node := range ws.Nodes {
+			labels = append(labels, node.Label)
+		}
+		b.WriteString("\nRelated concepts: ")
+		b.WriteString(strings.Join(labels, ", "))
+	}
+	return models.Message{Role: "system", Content: b.String()}, true
+}
+
+// prefetch is a working set being fetched; set is ready once done is
+// closed.
+type prefetch struct {
+	done chan struct{}
+	set  *WorkingSet
+}
+
+// SetPrefetch sets where prefetched subgraphs and preferences come from;
+// either may be nil. Set before the store is shared between goroutines.
+func (s *Store) SetPrefetch(graph Graph, profiles Profiles) {
+	s.graph = graph
+	s.profiles = profiles
+}
+
+// Start starts the session a request continues if the user has no session
+// with its ID, and prefetches its working set in the background. It
+// reports whether a session was started.
+func (s *Store) Start(ctx context.Context) bool {
+	k, ok := sessionKey(ctx)
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	if _, exists := s.sessions[k]; exists {
+		s.mu.Unlock()
+		return false
+	}
+	s.prune()
+	stored := newSession(k, s.now())
+	stored.prefetch = &prefetch{done: make(chan struct{})}
+	s.add(k, stored)
+	s.mu.Unlock()
+
+	go s.fetch(context.WithoutCancel(ctx), k, stored)
+	return true
+}
+
+// Prefetch is HTTP middleware that starts the session a request continues,
+// prefetching its working set while the request is routed. It goes after
+// authentication, since sessions are per user.
+func (s *Store) Prefetch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Start(r.Context())
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Prompt returns the preferences of the user a request is made for. The
+// first turn of a session uses those prefetched when it started; later
+// turns, and requests outside a prefetched session, read the profile, which
+// feedback may have changed since.
+func (s *Store) Prompt(ctx context.Context) string {
+	if k, ok := sessionKey(ctx); ok {
+		if set, first := s.workingSet(ctx, k); set != nil && first {
+			return set.Preferences
+		}
+	}
+	if s.profiles == nil {
+		return ""
+	}
+	return s.profiles.Prompt(ctx)
+}
+
+// WorkingSet returns a copy of the working set prefetched for a user's
+// session, or nil if none was or it is still being fetched.
+func (s *Store) WorkingSet(tenant, user, id string) (*WorkingSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.sessions[key{tenant, user, id}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	if stored.prefetch == nil {
+		return nil, nil
+	}
+	select {
+	case <-stored.prefetch.done:
+		c := *stored.prefetch.set
+		return &c, nil
+	default:
+		return nil, nil
+	}
+}
+
+// workingSet waits up to the configured wait for the working set of a
+// session, and reports whether the session has yet to record a turn. It
+// returns nil for sessions without one or if the wait runs out.
+func (s *Store) workingSet(ctx context.Context, k key) (*WorkingSet, bool) {
+	s.mu.Lock()
+	stored, ok := s.sessions[k]
+	s.mu.Unlock()
+	if !ok || stored.prefetch == nil {
+		return nil, false
+	}
+
+	timer := time.NewTimer(s.config.Prefetch.Wait)
+	defer timer.Stop()
+	select {
+	case <-stored.prefetch.done:
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return stored.prefetch.set, len(stored.Turns) == 0
+}
+
+// fetch gathers a session's working set: the user's latest turns in their
+// other sessions, the nodes those sessions drew on and the nodes related
+// to them, and their preferences. The nodes are also added to the
+// session's working memory.
+func (s *Store) fetch(ctx context.Context, k key, stored *session) {
+	config := s.config.Prefetch
+	set := &WorkingSet{Episodes: make([]Turn, 0), Nodes: make([]ContextRef, 0)}
+
+	// Recent sessions first, so their turns and context come first
+	s.mu.Lock()
+	recent := make([]*session, 0)
+	for other, session := range s.sessions {
+		if other.tenant == k.tenant && other.user == k.user && other.id != k.id {
+			recent = append(recent, session)
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].UpdatedAt.After(recent[j].UpdatedAt)
+	})
+	var episodes []Turn
+	var seeds []ContextRef
+	for _, session := range recent {
+		episodes = append(episodes, session.Turns...)
+		seeds = append(seeds, session.Context...)
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Time.After(episodes[j].Time)
+	})
+	if len(episodes) > config.Episodes {
+		episodes = episodes[:config.Episodes]
+	}
+	set.Episodes = append(set.Episodes, episodes...)
+	set.Nodes = s.subgraph(seeds, config.Nodes)
+	if s.profiles != nil {
+		set.Preferences = s.profiles.Prompt(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set.FetchedAt = s.now()
+	// Least relevant first, so the most relevant survive the capacity
+	for i := len(set.Nodes) - 1; i >= 0; i-- {
+		node := set.Nodes[i]
+		stored.working.Add(&memory.WorkingMemoryItem{
+			ID:          "node-" + node.NodeID,
+			Content:     node.Label,
+			ContentType: memory.ContentTypeContext,
+			Source:      memory.SourceRetrieval,
+		})
+	}
+	stored.prefetch.set = set
+	close(stored.prefetch.done)
+}
+
+// subgraph returns up to limit nodes: the seeds, then the nodes they
+// relate to, strongest relations first. Without a graph it returns the
+// seeds alone.
+func (s *Store) subgraph(seeds []ContextRef, limit int) []ContextRef {
+	nodes := make([]ContextRef, 0)
+	seen := make(map[string]bool)
+	for _, seed := range seeds {
+		if len(nodes) >= limit {
+			return nodes
+		}
+		if !seen[seed.NodeID] {
+			seen[seed.NodeID] = true
+			nodes = append(nodes, ContextRef{NodeID: seed.NodeID, Label: seed.Label})
+		}
+	}
+	if s.graph == nil {
+		return nodes
+	}
+
+	frontier := len(nodes)
+	for i := 0; i < frontier && len(nodes) < limit; i++ {
+		relations := s.graph.GetOutgoingRelations(nodes[i].NodeID)
+		sort.SliceStable(relations, func(a, b int) bool {
+			return relations[a].Weight > relations[b].Weight
+		})
+		for _, rel := range relations {
+			if len(nodes) >= limit {
+				break
+			}
+			if seen[rel.TargetID] {
+				continue
+			}
+			node, err := s.graph.GetNode(rel.TargetID)
+			if err != nil {
+				continue
+			}
+			seen[node.ID] = true
+			nodes = append(nodes, ContextRef{NodeID: node.ID, Label: node.Label})
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,119 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// staticProfiles returns the same preferences for every request.
+type staticProfiles struct{ prompt string }
+
+func (p *staticProfiles) Prompt(ctx context.Context) string { return p.prompt }
+
+// waitWorkingSet waits for a session's working set to be fetched.
+func waitWorkingSet(t *testing.T, store *Store, user, id string) *WorkingSet {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		set, err := store.WorkingSet("acme", user, id)
+		if err != nil {
+			t.Fatalf("WorkingSet failed: %v", err)
+		}
+		if set != nil {
+			return set
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected the working set of %s fetched", id)
+	return nil
+}
+
+func TestStorePrefetch(t *testing.T) {
+	network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	network.AddNode(memory.NewSemanticNode("quicksort", "QuickSort", memory.InstanceNode))
+	network.AddNode(memory.NewSemanticNode("recursion", "Recursion", memory.ConceptNode))
+	network.AddNode(memory.NewSemanticNode("divide", "Divide and Conquer", memory.ConceptNode))
+	network.AddRelation(memory.NewSemanticRelation("recursion", "divide", memory.RelatedTo))
+
+	store := NewStore(DefaultConfig(), memory.NewQuestionAnswerer(network))
+	profiles := &staticProfiles{prompt: "Answer in Go."}
+	store.SetPrefetch(network, profiles)
+
+	earlier := sessionContext("alice", "s1")
+	store.Record(earlier, "APEX", "Is QuickSort built on recursion?", "Yes.")
+	store.Record(earlier, "AXIOM", "What is the complexity of QuickSort?", "O(n log n) on average.")
+	store.Record(sessionContext("bob", "s1"), "APEX", "Not alice's question", "ok")
+
+	ctx := sessionContext("alice", "s2")
+	if !store.Start(ctx) {
+		t.Fatal("expected a new session started")
+	}
+	if store.Start(ctx) || store.Start(earlier) {
+		t.Error("expected existing sessions not started again")
+	}
+
+	set := waitWorkingSet(t, store, "alice", "s2")
+	if len(set.Episodes) != 2 || set.Episodes[0].Agent != "AXIOM" {
+		t.Errorf("expected alice's turns newest first, got %+v", set.Episodes)
+	}
+	var labels []string
+	for _, node := range set.Nodes {
+		labels = append(labels, node.Label)
+	}
+	if strings.Join(labels, ",") != "QuickSort,Recursion,Divide and Conquer" {
+		t.Errorf("expected the referenced nodes then their neighbors, got %v", labels)
+	}
+	if set.Preferences != "Answer in Go." {
+		t.Errorf("expected the preferences prefetched, got %q", set.Preferences)
+	}
+
+	session, _ := store.Get("acme", "alice", "s2")
+	if len(session.Turns) != 0 || len(session.WorkingMemory) != 3 {
+		t.Errorf("expected an empty session attending to the prefetched nodes, got %+v", session)
+	}
+	history := store.History(ctx)
+	if len(history) != 1 || history[0].Role != "system" || !strings.Contains(history[0].Content, "Is QuickSort built on recursion? (APEX)") {
+		t.Errorf("expected the working set as a system message, got %+v", history)
+	}
+
+	// The first turn uses the prefetched preferences, later turns the
+	// current profile
+	profiles.prompt = "Answer in Rust."
+	if got := store.Prompt(ctx); got != "Answer in Go." {
+		t.Errorf("expected the prefetched preferences on the first turn, got %q", got)
+	}
+	store.Record(ctx, "APEX", "And merge sort?", "Also O(n log n).")
+	if got := store.Prompt(ctx); got != "Answer in Rust." {
+		t.Errorf("expected the current preferences after the first turn, got %q", got)
+	}
+	if history := store.History(ctx); len(history) != 3 || history[0].Role != "system" {
+		t.Errorf("expected the working set before the session's turns, got %+v", history)
+	}
+}
+
+func TestStorePrefetchMiddleware(t *testing.T) {
+	store := NewStore(DefaultConfig(), nil)
+	handler := store.Prefetch(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(sessionContext("alice", "s1"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if set := waitWorkingSet(t, store, "alice", "s1"); len(set.Episodes) != 0 || len(set.Nodes) != 0 {
+		t.Errorf("expected an empty working set for a first session, got %+v", set)
+	}
+	if history := store.History(sessionContext("alice", "s1")); len(history) != 0 {
+		t.Errorf("expected no context message for an empty working set, got %+v", history)
+	}
+
+	// Anonymous requests are outside any session
+	req = httptest.NewRequest(http.MethodPost, "/", nil).WithContext(WithID(context.Background(), "s2"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got := store.List("acme", "alice"); len(got) != 1 {
+		t.Errorf("expected only the authenticated session started, got %+v", got)
+	}
+}
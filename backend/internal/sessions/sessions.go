@@ -0,0 +1,469 @@
+// Package sessions keeps the conversations users hold with the collective
+// so they can be continued: the turns of a session, its working memory,
+// and references to the knowledge graph context it drew on. Sessions are
+// exported as signed bundles and imported later, or into another
+// deployment, for continuity across devices and reproducible support
+// cases.
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+var (
+	// ErrSessionNotFound is returned when a user has no session with an ID.
+	ErrSessionNotFound = errdefs.New(errdefs.ErrNotFound, "session not found")
+	// ErrSessionExists is returned when an imported session's ID is taken.
+	ErrSessionExists = errdefs.New(errdefs.ErrConflict, "session already exists")
+)
+
+// SessionHeader names the session a request continues.
+const SessionHeader = "X-Session-ID"
+
+// Session limits.
+const (
+	// maxTurns bounds the turns kept per session; older turns are dropped
+	maxTurns = 200
+	// maxContext bounds the context references kept per session
+	maxContext = 100
+	// maxHistoryTurns bounds the turns replayed into a request
+	maxHistoryTurns = 10
+	// maxSessionsPerUser bounds a user's sessions; the least recently
+	// updated is dropped
+	maxSessionsPerUser = 50
+)
+
+// idPattern is what session IDs are made of.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Turn is one exchange with an agent.
+type Turn struct {
+	Agent  string    `json:"agent"`
+	Query  string    `json:"query"`
+	Answer string    `json:"answer"`
+	Time   time.Time `json:"time"`
+}
+
+// WorkingItem is an item in a session's working memory.
+type WorkingItem struct {
+	ID         string                          `json:"id"`
+	Content    string                          `json:"content"`
+	Type       memory.WorkingMemoryContentType `json:"type"`
+	Activation float64                         `json:"activation"`
+}
+
+// ContextRef refers to a knowledge graph node a session drew on.
+type ContextRef struct {
+	NodeID string `json:"node_id"`
+	Label  string `json:"label"`
+	// Turn is the index of the turn that first mentioned the node
+	Turn int `json:"turn"`
+}
+
+// Session is a conversation and the state it built up.
+type Session struct {
+	ID     string `json:"id"`
+	Tenant string `json:"tenant"`
+	User   string `json:"user"`
+	Turns  []Turn `json:"turns"`
+	// WorkingMemory are the items the session attends to, most active
+	// first
+	WorkingMemory []WorkingItem `json:"working_memory"`
+	Context       []ContextRef  `json:"context"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	// ImportedAt is set on sessions imported from a bundle
+	ImportedAt *time.Time `json:"imported_at,omitempty"`
+}
+
+// Summary describes a session without its content.
+type Summary struct {
+	ID        string    `json:"id"`
+	Turns     int       `json:"turns"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Linker finds the knowledge graph nodes a text mentions.
+type Linker interface {
+	LinkEntities(text string) []*memory.SemanticNode
+}
+
+// Config configures the sessions.
+type Config struct {
+	// SigningKey signs exported bundles and verifies imported ones; empty
+	// disables export and import
+	SigningKey string
+	// Retention is how long an idle session is kept
+	Retention time.Duration
+	// Prefetch configures the working set fetched when a session starts
+	Prefetch PrefetchConfig
+}
+
+// DefaultConfig returns the default session configuration.
+func DefaultConfig() Config {
+	return Config{Retention: 7 * 24 * time.Hour, Prefetch: DefaultPrefetchConfig()}
+}
+
+// workingMemoryConfig has session working memory fade by capacity rather
+// than time, since turns can be hours apart.
+func workingMemoryConfig() memory.WorkingMemoryConfig {
+	config := memory.DefaultWorkingMemoryConfig()
+	config.DecayRate = 0
+	return config
+}
+
+// key identifies a session: IDs are chosen by clients, so each user has
+// their own.
+type key struct {
+	tenant, user, id string
+}
+
+// session is a stored session with its live working memory.
+type session struct {
+	Session
+	working *memory.CognitiveWorkingMemory
+	// attention holds the turns and nodes the session is focused on, and
+	// goals what it is pursuing
+	attention *memory.AttentionController
+	goals     *memory.GoalStack
+	// referenced holds the IDs of the nodes in Context
+	referenced map[string]bool
+	// prefetch is the working set fetched when the session started; nil
+	// for sessions started by recording a turn or by import
+	prefetch *prefetch
+}
+
+// newSession creates an empty session.
+func newSession(k key, now time.Time) *session {
+	return &session{
+		Session: Session{
+			ID:        k.id,
+			Tenant:    k.tenant,
+			User:      k.user,
+			Turns:     make([]Turn, 0),
+			Context:   make([]ContextRef, 0),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		working:    memory.NewCognitiveWorkingMemory(workingMemoryConfig()),
+		attention:  memory.NewAttentionController(memory.DefaultAttentionConfig()),
+		goals:      memory.NewGoalStack(memory.DefaultGoalStackConfig()),
+		referenced: make(map[string]bool),
+	}
+}
+
+// record adds a turn, attending to the query and the nodes it mentions.
+func (s *session) record(turn Turn, nodes []*memory.SemanticNode) {
+	index := len(s.Turns)
+	s.Turns = append(s.Turns, turn)
+	if len(s.Turns) > maxTurns {
+		s.Turns = append([]Turn(nil), s.Turns[len(s.Turns)-maxTurns:]...)
+	}
+	s.UpdatedAt = turn.Time
+
+	turnID := fmt.Sprintf("turn-%d", turn.Time.UnixNano())
+	associations := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		itemID := "node-" + node.ID
+		s.working.Add(&memory.WorkingMemoryItem{
+			ID:          itemID,
+			Content:     node.Label,
+			ContentType: memory.ContentTypeContext,
+			Source:      memory.SourceRetrieval,
+		})
+		associations = append(associations, itemID)
+		if !s.referenced[node.ID] && len(s.Context) < maxContext {
+			s.referenced[node.ID] = true
+			s.Context = append(s.Context, ContextRef{NodeID: node.ID, Label: node.Label, Turn: index})
+		}
+	}
+	s.working.Add(&memory.WorkingMemoryItem{
+		ID:           turnID,
+		Content:      turn.Query,
+		ContentType:  memory.ContentTypeTask,
+		Source:       memory.SourcePerception,
+		Associations: associations,
+	})
+	s.attend(turnID, turn.Query, nodes)
+}
+
+// snapshot returns a copy of the session with its working memory.
+func (s *session) snapshot() *Session {
+	c := s.Session
+	c.Turns = append([]Turn(nil), s.Turns...)
+	c.Context = append([]ContextRef(nil), s.Context...)
+	items := s.working.SnapshotItems()
+	c.WorkingMemory = make([]WorkingItem, 0, len(items))
+	for _, item := range items {
+		content, _ := item.Content.(string)
+		c.WorkingMemory = append(c.WorkingMemory, WorkingItem{
+			ID:         item.ID,
+			Content:    content,
+			Type:       item.ContentType,
+			Activation: item.Activation,
+		})
+	}
+	sort.Slice(c.WorkingMemory, func(i, j int) bool {
+		if c.WorkingMemory[i].Activation != c.WorkingMemory[j].Activation {
+			return c.WorkingMemory[i].Activation > c.WorkingMemory[j].Activation
+		}
+		return c.WorkingMemory[i].ID < c.WorkingMemory[j].ID
+	})
+	return &c
+}
+
+// restore creates a stored session from an exported one.
+func restore(k key, exported *Session, now time.Time) *session {
+	s := newSession(k, exported.CreatedAt)
+	s.Turns = append(s.Turns, exported.Turns...)
+	s.UpdatedAt = exported.UpdatedAt
+	s.ImportedAt = &now
+	for _, ref := range exported.Context {
+		if !s.referenced[ref.NodeID] {
+			s.referenced[ref.NodeID] = true
+			s.Context = append(s.Context, ref)
+		}
+	}
+	// Least active first, so the most active survive if the exporting
+	// deployment allowed more items
+	for i := len(exported.WorkingMemory) - 1; i >= 0; i-- {
+		item := exported.WorkingMemory[i]
+		s.working.Add(&memory.WorkingMemoryItem{
+			ID:          item.ID,
+			Content:     item.Content,
+			ContentType: item.Type,
+			Activation:  item.Activation,
+			Source:      memory.SourceRetrieval,
+		})
+	}
+	if len(s.Turns) > 0 {
+		last := s.Turns[len(s.Turns)-1]
+		s.pursue(s.Turns[0].Query)
+		s.attend(fmt.Sprintf("turn-%d", last.Time.UnixNano()), last.Query, nil)
+	}
+	return s
+}
+
+// Store keeps sessions in memory, by tenant and user. It is safe for
+// concurrent use.
+type Store struct {
+	config Config
+	// linker finds the context of queries; nil records none
+	linker Linker
+	// graph and profiles supply prefetched working sets; either may be nil
+	graph    Graph
+	profiles Profiles
+
+	mu       sync.Mutex
+	sessions map[key]*session
+	now      func() time.Time
+}
+
+// NewStore creates an empty store.
+func NewStore(config Config, linker Linker) *Store {
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig().Retention
+	}
+	if config.Prefetch == (PrefetchConfig{}) {
+		config.Prefetch = DefaultPrefetchConfig()
+	}
+	return &Store{
+		config:   config,
+		linker:   linker,
+		sessions: make(map[key]*session),
+		now:      time.Now,
+	}
+}
+
+// Get returns a copy of a user's session.
+func (s *Store) Get(tenant, user, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	stored, ok := s.sessions[key{tenant, user, id}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return stored.snapshot(), nil
+}
+
+// List returns a user's sessions, most recently updated first.
+func (s *Store) List(tenant, user string) []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	summaries := make([]Summary, 0)
+	for k, stored := range s.sessions {
+		if k.tenant == tenant && k.user == user {
+			summaries = append(summaries, Summary{
+				ID:        stored.ID,
+				Turns:     len(stored.Turns),
+				CreatedAt: stored.CreatedAt,
+				UpdatedAt: stored.UpdatedAt,
+			})
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].UpdatedAt.Equal(summaries[j].UpdatedAt) {
+			return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+// Delete removes a user's session.
+func (s *Store) Delete(tenant, user, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key{tenant, user, id}
+	if _, ok := s.sessions[k]; !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	delete(s.sessions, k)
+	return nil
+}
+
+// History returns the earlier turns of the session a request continues as
+// messages, oldest first, after the context prefetched when it started.
+// It returns nil for requests outside a session.
+func (s *Store) History(ctx context.Context) []models.Message {
+	k, ok := sessionKey(ctx)
+	if !ok {
+		return nil
+	}
+	set, _ := s.workingSet(ctx, k)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.sessions[k]
+	if !ok {
+		return nil
+	}
+	turns := stored.Turns
+	if len(turns) > maxHistoryTurns {
+		turns = turns[len(turns)-maxHistoryTurns:]
+	}
+	messages := make([]models.Message, 0, 2*len(turns)+1)
+	if set != nil {
+		if message, ok := set.message(); ok {
+			messages = append(messages, message)
+		}
+	}
+	for _, turn := range turns {
+		messages = append(messages,
+			models.Message{Role: "user", Content: turn.Query},
+			models.Message{Role: "assistant", Content: turn.Answer})
+	}
+	return messages
+}
+
+// Record adds an exchange to the session a request continues, creating
+// the session if needed. Requests outside a session are not recorded.
+func (s *Store) Record(ctx context.Context, agent, query, answer string) {
+	k, ok := sessionKey(ctx)
+	if !ok {
+		return
+	}
+	var nodes []*memory.SemanticNode
+	if s.linker != nil {
+		nodes = s.linker.LinkEntities(query)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	stored, ok := s.sessions[k]
+	if !ok {
+		s.prune()
+		stored = newSession(k, now)
+		s.add(k, stored)
+	}
+	stored.record(Turn{Agent: agent, Query: query, Answer: answer, Time: now}, nodes)
+}
+
+// add stores a session, dropping the user's least recently updated
+// session if they have too many. Callers hold mu.
+func (s *Store) add(k key, stored *session) {
+	var oldest key
+	count := 0
+	for other, session := range s.sessions {
+		if other.tenant != k.tenant || other.user != k.user {
+			continue
+		}
+		if count == 0 || session.UpdatedAt.Before(s.sessions[oldest].UpdatedAt) {
+			oldest = other
+		}
+		count++
+	}
+	if count >= maxSessionsPerUser {
+		delete(s.sessions, oldest)
+	}
+	s.sessions[k] = stored
+}
+
+// prune drops sessions idle past retention. Callers hold mu.
+func (s *Store) prune() {
+	cutoff := s.now().Add(-s.config.Retention)
+	for k, stored := range s.sessions {
+		if stored.UpdatedAt.Before(cutoff) {
+			delete(s.sessions, k)
+		}
+	}
+}
+
+// idKey is the context key of the session a request continues.
+type idKey struct{}
+
+// WithID returns a context carrying the session a request continues.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// IDFromContext returns the session set by WithID or the Track
+// middleware, or "" for requests outside a session.
+func IDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// sessionKey returns the session a request continues: the session ID in
+// the context, for the authenticated user in the request's tenant.
+// Anonymous requests are outside any session.
+func sessionKey(ctx context.Context) (key, bool) {
+	id := IDFromContext(ctx)
+	claims := auth.GetClaims(ctx)
+	if id == "" || claims == nil || claims.Subject == "" {
+		return key{}, false
+	}
+	return key{tenant: features.TenantFromContext(ctx), user: claims.Subject, id: id}, true
+}
+
+// Track is HTTP middleware that records the session a request continues,
+// named in the X-Session-ID header, in its context. Malformed session IDs
+// are rejected with 400.
+func Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(SessionHeader); id != "" {
+			if !idPattern.MatchString(id) {
+				errdefs.WriteError(w, "session IDs are 1 to 64 letters, digits, dots, dashes and underscores", http.StatusBadRequest)
+				return
+			}
+			r = r.WithContext(WithID(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
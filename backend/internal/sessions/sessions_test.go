@@ -0,0 +1,117 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/features"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// newTestLinker links the nodes of a small knowledge graph.
+func newTestLinker() Linker {
+	network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	network.AddNode(memory.NewSemanticNode("quicksort", "QuickSort", memory.InstanceNode))
+	network.AddNode(memory.NewSemanticNode("recursion", "Recursion", memory.ConceptNode))
+	return memory.NewQuestionAnswerer(network)
+}
+
+// sessionContext returns the context of a request by user in tenant acme
+// continuing session id.
+func sessionContext(user, id string) context.Context {
+	ctx := features.WithTenant(context.Background(), "acme")
+	ctx = context.WithValue(ctx, auth.ClaimsContextKey, &auth.Claims{Subject: user})
+	return WithID(ctx, id)
+}
+
+func TestStoreRecord(t *testing.T) {
+	store := NewStore(DefaultConfig(), newTestLinker())
+	ctx := sessionContext("alice", "s1")
+
+	store.Record(ctx, "APEX", "Is QuickSort built on recursion?", "Yes.")
+	store.Record(ctx, "AXIOM", "What is the complexity of QuickSort?", "O(n log n) on average.")
+	store.Record(context.Background(), "APEX", "outside any session", "ignored")
+
+	session, err := store.Get("acme", "alice", "s1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(session.Turns) != 2 || session.Turns[1].Agent != "AXIOM" {
+		t.Errorf("expected 2 turns, got %+v", session.Turns)
+	}
+	if len(session.Context) != 2 || session.Context[0].NodeID != "quicksort" || session.Context[1].NodeID != "recursion" {
+		t.Errorf("expected QuickSort and Recursion referenced once each, got %+v", session.Context)
+	}
+	if len(session.WorkingMemory) != 4 || session.WorkingMemory[0].ID != "node-quicksort" {
+		t.Errorf("expected the twice-mentioned QuickSort most active of 4 items, got %+v", session.WorkingMemory)
+	}
+
+	history := store.History(ctx)
+	if len(history) != 4 || history[0].Role != "user" || history[3].Content != "O(n log n) on average." {
+		t.Errorf("expected both turns as messages, got %+v", history)
+	}
+	if history := store.History(sessionContext("bob", "s1")); history != nil {
+		t.Errorf("expected sessions kept per user, got %+v", history)
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	store := NewStore(Config{Retention: time.Hour}, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < maxSessionsPerUser+1; i++ {
+		now = now.Add(time.Second)
+		store.Record(sessionContext("alice", fmt.Sprintf("s%d", i)), "APEX", "hello", "hi")
+	}
+	if got := len(store.List("acme", "alice")); got != maxSessionsPerUser {
+		t.Errorf("expected %d sessions, got %d", maxSessionsPerUser, got)
+	}
+	if _, err := store.Get("acme", "alice", "s0"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected the least recently updated session dropped, got %v", err)
+	}
+
+	for i := 0; i < maxTurns+5; i++ {
+		store.Record(sessionContext("alice", "long"), "APEX", fmt.Sprintf("query %d", i), "ok")
+	}
+	session, _ := store.Get("acme", "alice", "long")
+	if len(session.Turns) != maxTurns || session.Turns[0].Query != "query 5" {
+		t.Errorf("expected the oldest turns dropped, got %d turns from %q", len(session.Turns), session.Turns[0].Query)
+	}
+	if history := store.History(sessionContext("alice", "long")); len(history) != 2*maxHistoryTurns {
+		t.Errorf("expected %d history messages, got %d", 2*maxHistoryTurns, len(history))
+	}
+
+	now = now.Add(2 * time.Hour)
+	if got := store.List("acme", "alice"); len(got) != 0 {
+		t.Errorf("expected idle sessions pruned, got %+v", got)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	var got string
+	handler := Track(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = IDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(SessionHeader, "support-42")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "support-42" {
+		t.Errorf("expected session support-42 in context, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(SessionHeader, "../etc")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed session ID, got %d", w.Code)
+	}
+}
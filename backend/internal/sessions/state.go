@@ -0,0 +1,235 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+const (
+	// topicGoal is the ID of a session's goal: what its first turn asked
+	topicGoal = "topic"
+	// maxLabel bounds the labels of focus items and goals, in runes
+	maxLabel = 80
+	// turnSalience and nodeSalience are how strongly a turn's query and
+	// the nodes it mentions draw attention
+	turnSalience = 0.8
+	nodeSalience = 0.5
+	// maxCopilotBody bounds the Copilot request read for its thread
+	maxCopilotBody = 4 << 20
+)
+
+// State is what a session is attending to and pursuing.
+type State struct {
+	ID            string         `json:"id"`
+	WorkingMemory []WorkingItem  `json:"working_memory"`
+	Attention     AttentionState `json:"attention"`
+	Goals         []GoalState    `json:"goals"`
+	// CurrentGoal is the ID of the goal being pursued, if any
+	CurrentGoal string    `json:"current_goal,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// ExpiresAt is when the session expires unless another turn is
+	// recorded
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttentionState is a session's focus.
+type AttentionState struct {
+	Load     float64 `json:"load"`
+	Capacity float64 `json:"capacity"`
+	// Focus are the items attended to, highest priority first
+	Focus []FocusState `json:"focus"`
+}
+
+// FocusState is an item a session attends to.
+type FocusState struct {
+	ID       string  `json:"id"`
+	Type     string  `json:"type"`
+	Label    string  `json:"label"`
+	Salience float64 `json:"salience"`
+	Priority float64 `json:"priority"`
+}
+
+// GoalState is a goal a session pursues.
+type GoalState struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	ParentID string  `json:"parent_id,omitempty"`
+	Progress float64 `json:"progress"`
+	// CreatedAt is when the goal was pushed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// attend focuses a session on a turn's query and the nodes it mentions,
+// and has it pursue the query if it has no goal yet. Items the session is
+// already focused on are refreshed instead. Items that do not fit beside
+// more salient ones are left out of focus; they stay in working memory.
+func (s *session) attend(turnID, query string, nodes []*memory.SemanticNode) {
+	if _, err := s.goals.Get(topicGoal); err != nil {
+		s.pursue(query)
+	}
+	item := s.attention.NewFocusItem(memory.FocusTask, query, label(query), turnSalience)
+	item.ID = turnID
+	s.attention.Focus(item)
+	for _, node := range nodes {
+		item := s.attention.NewFocusItem(memory.FocusContext, node.ID, node.Label, nodeSalience)
+		item.ID = "node-" + node.ID
+		s.attention.Focus(item)
+	}
+}
+
+// pursue sets a session's goal, keeping it in focus.
+func (s *session) pursue(query string) {
+	goal := &memory.Goal{ID: topicGoal, Name: label(query), Description: query}
+	if err := s.goals.Push(goal); err != nil {
+		return
+	}
+	item := s.attention.NewFocusItem(memory.FocusGoal, goal.ID, goal.Name, 1)
+	item.ID = "goal-" + goal.ID
+	item.Sticky = true
+	s.attention.Focus(item)
+}
+
+// state returns what a session is attending to and pursuing.
+func (s *session) state(retention time.Duration) *State {
+	state := &State{
+		ID:            s.ID,
+		WorkingMemory: s.snapshot().WorkingMemory,
+		Goals:         make([]GoalState, 0),
+		UpdatedAt:     s.UpdatedAt,
+		ExpiresAt:     s.UpdatedAt.Add(retention),
+	}
+
+	focus := s.attention.Snapshot()
+	state.Attention = AttentionState{Load: focus.CurrentLoad, Capacity: s.attention.Capacity(), Focus: make([]FocusState, 0, len(focus.Items))}
+	for _, item := range focus.Items {
+		state.Attention.Focus = append(state.Attention.Focus, FocusState{
+			ID:       item.ID,
+			Type:     item.Type.String(),
+			Label:    item.Label,
+			Salience: item.Salience,
+			Priority: item.Priority,
+		})
+	}
+	sort.Slice(state.Attention.Focus, func(i, j int) bool {
+		if state.Attention.Focus[i].Priority != state.Attention.Focus[j].Priority {
+			return state.Attention.Focus[i].Priority > state.Attention.Focus[j].Priority
+		}
+		return state.Attention.Focus[i].ID < state.Attention.Focus[j].ID
+	})
+
+	goals := s.goals.Snapshot()
+	state.CurrentGoal = goals.CurrentGoalID
+	for _, goal := range goals.Goals {
+		state.Goals = append(state.Goals, GoalState{
+			ID:        goal.ID,
+			Name:      goal.Name,
+			Status:    strings.ToLower(goal.Status.String()),
+			ParentID:  goal.ParentID,
+			Progress:  goal.Progress,
+			CreatedAt: goal.CreatedAt,
+		})
+	}
+	sort.Slice(state.Goals, func(i, j int) bool {
+		if !state.Goals[i].CreatedAt.Equal(state.Goals[j].CreatedAt) {
+			return state.Goals[i].CreatedAt.Before(state.Goals[j].CreatedAt)
+		}
+		return state.Goals[i].ID < state.Goals[j].ID
+	})
+	return state
+}
+
+// State returns what one of a user's sessions is attending to and
+// pursuing.
+func (s *Store) State(tenant, user, id string) (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	stored, ok := s.sessions[key{tenant, user, id}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return stored.state(s.config.Retention), nil
+}
+
+// Expire drops the sessions idle past retention and returns how many it
+// dropped.
+func (s *Store) Expire() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := len(s.sessions)
+	s.prune()
+	return before - len(s.sessions)
+}
+
+// WatchExpiry expires idle sessions every interval until ctx is done, so
+// their working memory is released without waiting for the next request.
+func (s *Store) WatchExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.Expire(); n > 0 {
+				log.Printf("Expired %d idle sessions", n)
+			}
+		}
+	}
+}
+
+// CopilotThread is HTTP middleware that continues the session named by a
+// Copilot request's copilot_thread_id, so each Copilot conversation is a
+// session. A session named in the X-Session-ID header is kept. It goes
+// after signature verification and before Prefetch.
+func CopilotThread(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IDFromContext(r.Context()) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCopilotBody))
+		r.Body.Close()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				errdefs.WriteError(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			errdefs.WriteError(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var thread struct {
+			ID string `json:"copilot_thread_id"`
+		}
+		if json.Unmarshal(body, &thread) == nil && idPattern.MatchString(thread.ID) {
+			r = r.WithContext(WithID(r.Context(), thread.ID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// label shortens a text to a label.
+func label(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxLabel {
+		return string(runes[:maxLabel-3]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,109 @@
+package sessions
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreState(t *testing.T) {
+	store := NewStore(Config{Retention: time.Hour}, newTestLinker())
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := sessionContext("alice", "s1")
+	store.Record(ctx, "APEX", "Is QuickSort built on recursion?", "Yes.")
+	now = now.Add(time.Minute)
+	store.Record(ctx, "AXIOM", "What is the complexity of QuickSort?", "O(n log n) on average.")
+
+	state, err := store.State("acme", "alice", "s1")
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	if len(state.Goals) != 1 || state.Goals[0].Name != "Is QuickSort built on recursion?" || state.Goals[0].Status != "active" || state.CurrentGoal != topicGoal {
+		t.Errorf("expected the first query pursued, got %+v (current %q)", state.Goals, state.CurrentGoal)
+	}
+	focused := make(map[string]string)
+	for _, item := range state.Attention.Focus {
+		focused[item.ID] = item.Type
+	}
+	if len(focused) != 5 || focused["goal-topic"] != "goal" || focused["node-quicksort"] != "context" {
+		t.Errorf("expected the goal, 2 turns and 2 nodes in focus, got %+v", state.Attention.Focus)
+	}
+	if state.Attention.Focus[0].ID != "goal-topic" || state.Attention.Load <= 0 {
+		t.Errorf("expected the goal first with some load, got %+v", state.Attention)
+	}
+	if len(state.WorkingMemory) != 4 || !state.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected 4 working memory items expiring in an hour, got %d, %v", len(state.WorkingMemory), state.ExpiresAt)
+	}
+
+	if _, err := store.State("acme", "bob", "s1"); err == nil {
+		t.Error("expected another user's session not found")
+	}
+}
+
+func TestStoreExpire(t *testing.T) {
+	store := NewStore(Config{Retention: time.Hour}, nil)
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.Record(sessionContext("alice", "old"), "APEX", "hello", "hi")
+	now = now.Add(45 * time.Minute)
+	store.Record(sessionContext("alice", "new"), "APEX", "hello", "hi")
+
+	if n := store.Expire(); n != 0 {
+		t.Errorf("expected no sessions expired yet, got %d", n)
+	}
+	now = now.Add(30 * time.Minute)
+	if n := store.Expire(); n != 1 {
+		t.Errorf("expected the idle session expired, got %d", n)
+	}
+	if list := store.List("acme", "alice"); len(list) != 1 || list[0].ID != "new" {
+		t.Errorf("expected the recent session kept, got %+v", list)
+	}
+}
+
+func TestCopilotThread(t *testing.T) {
+	var id, body string
+	handler := CopilotThread(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = IDFromContext(r.Context())
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+
+	payload := `{"copilot_thread_id":"4f2c9a1e-7b1d-4c2e-9a55-2d0c1b7e8f10","messages":[]}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/copilot", strings.NewReader(payload)))
+	if id != "4f2c9a1e-7b1d-4c2e-9a55-2d0c1b7e8f10" || body != payload {
+		t.Errorf("expected the thread continued with the body intact, got %q, %q", id, body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/copilot", strings.NewReader(payload))
+	req = req.WithContext(WithID(req.Context(), "named"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if id != "named" {
+		t.Errorf("expected the X-Session-ID session kept, got %q", id)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/copilot", strings.NewReader(`{"copilot_thread_id":"bad id!"}`)))
+	if id != "" {
+		t.Errorf("expected a malformed thread ignored, got %q", id)
+	}
+}
+
+func TestStateAPI(t *testing.T) {
+	store := NewStore(DefaultConfig(), nil)
+	store.Record(sessionContext("alice", "s1"), "APEX", "hello", "hi")
+	router := newTestRouter(store)
+
+	w := serve(router, http.MethodGet, "/sessions/s1/state", "alice", "")
+	var state State
+	json.Unmarshal(w.Body.Bytes(), &state)
+	if w.Code != http.StatusOK || state.ID != "s1" || len(state.Goals) != 1 {
+		t.Errorf("expected the session's state, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve(router, http.MethodGet, "/sessions/s1/state", "bob", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another user's session, got %d", w.Code)
+	}
+}
@@ -0,0 +1,244 @@
+package testgap
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"strconv"
+	"strings"
+)
+
+// reservedFields are the table fields a skeleton declares itself; inputs
+// named the same are renamed.
+var reservedFields = map[string]bool{"name": true, "recv": true, "want": true, "wantErr": true, "tt": true, "tests": true}
+
+// Skeleton writes a test file with a table-driven test for each function
+// with gaps, one case per gap, for the cases' inputs and expected results
+// to be filled in. Generic functions are left out, and their names
+// returned.
+func (a *Analysis) Skeleton(gaps []Branch) (string, []string, error) {
+	byFunction := make(map[string][]Branch)
+	for _, gap := range gaps {
+		byFunction[gap.Function] = append(byFunction[gap.Function], gap)
+	}
+
+	imports := map[string]bool{`"testing"`: true}
+	var tests bytes.Buffer
+	var skipped []string
+	named := make(map[string]bool)
+	for _, fn := range a.Functions {
+		cases := byFunction[fn.Name]
+		if len(cases) == 0 {
+			continue
+		}
+		if generic(fn.decl) {
+			skipped = append(skipped, fn.Name)
+			continue
+		}
+		name := testName(fn.decl)
+		if a.tests[name] || named[name] {
+			name += "_gaps"
+		}
+		named[name] = true
+		a.writeTest(&tests, fn, name, cases, imports)
+	}
+	if tests.Len() == 0 {
+		return "", skipped, nil
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Table-driven test skeletons for the branches test-gap analysis found\n")
+	b.WriteString("// untested. Fill in each case's inputs and expected results.\n\n")
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", a.Package)
+	for _, imp := range sortedKeys(imports) {
+		b.WriteString("\t" + imp + "\n")
+	}
+	b.WriteString(")\n")
+	b.Write(tests.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", nil, fmt.Errorf("formatting skeleton: %w", err)
+	}
+	return string(src), skipped, nil
+}
+
+// writeTest writes the test of a function, adding the imports its table
+// needs.
+func (a *Analysis) writeTest(b *bytes.Buffer, fn *Function, name string, cases []Branch, imports map[string]bool) {
+	decl := fn.decl
+	var fields, args, got []string
+	var wants []string
+	hasErr := false
+	addType := func(expr ast.Expr) string {
+		a.addImports(expr, fn.file, imports)
+		return a.typeSource(expr)
+	}
+
+	if decl.Recv != nil {
+		fields = append(fields, "recv "+addType(decl.Recv.List[0].Type))
+	}
+	for i, param := range decl.Type.Params.List {
+		typ := param.Type
+		variadic := false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ellipsis.Elt, true
+		}
+		typeSource := addType(typ)
+		if variadic {
+			typeSource = "[]" + typeSource
+		}
+		names := param.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("_")}
+		}
+		for j, ident := range names {
+			field := ident.Name
+			if field == "_" {
+				field = fmt.Sprintf("arg%d", i+j)
+			}
+			if reservedFields[field] {
+				field += "Arg"
+			}
+			fields = append(fields, field+" "+typeSource)
+			if variadic {
+				args = append(args, "tt."+field+"...")
+			} else {
+				args = append(args, "tt."+field)
+			}
+		}
+	}
+	if decl.Type.Results != nil {
+		var results []ast.Expr
+		for _, result := range decl.Type.Results.List {
+			for n := 0; n < max(1, len(result.Names)); n++ {
+				results = append(results, result.Type)
+			}
+		}
+		for i, result := range results {
+			if ident, ok := result.(*ast.Ident); ok && ident.Name == "error" && i == len(results)-1 {
+				hasErr = true
+				got = append(got, "err")
+				continue
+			}
+			suffix := ""
+			if len(wants) > 0 {
+				suffix = strconv.Itoa(len(wants))
+			}
+			wants = append(wants, suffix)
+			fields = append(fields, "want"+suffix+" "+addType(result))
+			got = append(got, "got"+suffix)
+		}
+	}
+	if hasErr {
+		fields = append(fields, "wantErr bool")
+	}
+	if len(wants) > 0 {
+		imports[`"reflect"`] = true
+	}
+
+	callee := decl.Name.Name
+	if decl.Recv != nil {
+		callee = "tt.recv." + callee
+	}
+	call := callee + "(" + strings.Join(args, ", ") + ")"
+	label := strings.TrimPrefix(callee, "tt.recv.")
+
+	fmt.Fprintf(b, "\nfunc %s(t *testing.T) {\n\ttests := []struct {\n\t\tname string\n", name)
+	for _, field := range fields {
+		b.WriteString("\t\t" + field + "\n")
+	}
+	b.WriteString("\t}{\n")
+	for _, gap := range cases {
+		fmt.Fprintf(b, "\t\t// %s:%d\n\t\t{name: %s},\n", gap.File, gap.Line, strconv.Quote(caseName(gap)))
+	}
+	b.WriteString("\t}\n\tfor _, tt := range tests {\n\t\tt.Run(tt.name, func(t *testing.T) {\n")
+	if len(got) > 0 {
+		fmt.Fprintf(b, "\t\t\t%s := %s\n", strings.Join(got, ", "), call)
+	} else {
+		fmt.Fprintf(b, "\t\t\t%s\n", call)
+	}
+	if hasErr {
+		fmt.Fprintf(b, "\t\t\tif (err != nil) != tt.wantErr {\n\t\t\t\tt.Fatalf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n\t\t\t}\n", label)
+	}
+	for _, suffix := range wants {
+		fmt.Fprintf(b, "\t\t\tif !reflect.DeepEqual(got%[1]s, tt.want%[1]s) {\n\t\t\t\tt.Errorf(\"%[2]s() got%[1]s = %%v, want %%v\", got%[1]s, tt.want%[1]s)\n\t\t\t}\n", suffix, label)
+	}
+	b.WriteString("\t\t})\n\t}\n}\n")
+}
+
+// addImports adds the imports of the packages a type refers to.
+func (a *Analysis) addImports(expr ast.Expr, file *ast.File, imports map[string]bool) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := selector.X.(*ast.Ident); ok {
+			for _, spec := range file.Imports {
+				if importName(spec) != pkg.Name {
+					continue
+				}
+				if spec.Name != nil {
+					imports[spec.Name.Name+" "+spec.Path.Value] = true
+				} else {
+					imports[spec.Path.Value] = true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// typeSource returns a type as written.
+func (a *Analysis) typeSource(expr ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, a.fset, expr)
+	return b.String()
+}
+
+// generic reports whether a function or its receiver has type parameters.
+func generic(decl *ast.FuncDecl) bool {
+	if decl.Type.TypeParams != nil {
+		return true
+	}
+	if decl.Recv == nil {
+		return false
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch recv.(type) {
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// testName names a function's test as go test conventions do: TestParse,
+// Test_parse, TestLexer_Next.
+func testName(decl *ast.FuncDecl) string {
+	name := decl.Name.Name
+	if decl.Recv != nil {
+		name = strings.TrimPrefix(receiverName(decl.Recv.List[0].Type), "*") + "_" + name
+	}
+	if ast.IsExported(name) {
+		return "Test" + name
+	}
+	return "Test_" + name
+}
+
+// caseName names a test case after the branch it takes.
+func caseName(gap Branch) string {
+	switch gap.Kind {
+	case KindIf, KindCase:
+		return gap.Kind + " " + gap.Condition
+	case KindElse:
+		return "else: " + gap.Condition
+	case KindDefault:
+		return "default"
+	}
+	return "call"
+}
@@ -0,0 +1,324 @@
+// Package testgap finds the code of a Go package its tests do not reach
+// and writes table-driven test skeletons for it. Analysis is static: the
+// package is parsed, never built or run. A function is reached when a test
+// refers to it, directly or through functions it calls; its branches are
+// the if, else, case and default clauses in its body.
+package testgap
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrInvalidSource is returned for files that are not Go source of one
+// package.
+var ErrInvalidSource = errdefs.New(errdefs.ErrInvalidArgument, "invalid Go source")
+
+// maxCondition bounds the length of a branch's condition as reported.
+const maxCondition = 80
+
+// Branch kinds.
+const (
+	KindIf      = "if"
+	KindElse    = "else"
+	KindCase    = "case"
+	KindDefault = "default"
+	// KindBody is a function without branches, reached or not as a whole
+	KindBody = "body"
+)
+
+// versionSuffix matches the major version element of a module path.
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// File is a Go source file.
+type File struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Branch is a path through a function that a test can take.
+type Branch struct {
+	// Function is the function's name, such as "Parse" or "(*Lexer).Next"
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	// Kind is if, else, case, default, or body
+	Kind string `json:"kind"`
+	// Condition is the branch's condition or case expressions as written;
+	// an else branch's is the negated condition of its if
+	Condition string `json:"condition,omitempty"`
+}
+
+// Function is a function or method of the package.
+type Function struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+	// Reached is set when a test refers to the function or to one that
+	// refers to it
+	Reached  bool     `json:"reached"`
+	Branches []Branch `json:"branches"`
+
+	decl *ast.FuncDecl
+	file *ast.File
+	// refs are the names the function's body refers to
+	refs map[string]bool
+}
+
+// Analysis is what static analysis found in a package.
+type Analysis struct {
+	Package   string      `json:"package"`
+	Functions []*Function `json:"functions"`
+
+	fset *token.FileSet
+	// tests are the names of the package's existing test functions
+	tests map[string]bool
+}
+
+// Analyze parses a package's sources and tests and finds the functions its
+// tests reach. Test files are those named *_test.go.
+func Analyze(files []File) (*Analysis, error) {
+	a := &Analysis{fset: token.NewFileSet(), tests: make(map[string]bool)}
+	roots := map[string]bool{"init": true}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name, ".go") {
+			return nil, fmt.Errorf("%w: %s is not a .go file", ErrInvalidSource, f.Name)
+		}
+		file, err := parser.ParseFile(a.fset, f.Name, f.Content, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+		}
+
+		if strings.HasSuffix(f.Name, "_test.go") {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") {
+					a.tests[fn.Name.Name] = true
+				}
+			}
+			for name := range references(file) {
+				roots[name] = true
+			}
+			continue
+		}
+
+		if a.Package == "" {
+			a.Package = file.Name.Name
+		} else if file.Name.Name != a.Package {
+			return nil, fmt.Errorf("%w: %s is in package %s, not %s", ErrInvalidSource, f.Name, file.Name.Name, a.Package)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				a.Functions = append(a.Functions, a.function(fn, file))
+			}
+		}
+	}
+	if a.Package == "" {
+		return nil, fmt.Errorf("%w: no source files besides tests", ErrInvalidSource)
+	}
+	a.reach(roots)
+	return a, nil
+}
+
+// function describes a function declaration and lists its branches.
+func (a *Analysis) function(decl *ast.FuncDecl, file *ast.File) *Function {
+	fn := &Function{
+		Name:    funcName(decl),
+		File:    a.fset.Position(decl.Pos()).Filename,
+		Line:    a.fset.Position(decl.Pos()).Line,
+		EndLine: a.fset.Position(decl.End()).Line,
+		decl:    decl,
+		file:    file,
+		refs:    references(decl.Body),
+	}
+	branch := func(node ast.Node, kind, condition string) {
+		if len(condition) > maxCondition {
+			condition = condition[:maxCondition-3] + "..."
+		}
+		fn.Branches = append(fn.Branches, Branch{Function: fn.Name, File: fn.File, Line: a.fset.Position(node.Pos()).Line, Kind: kind, Condition: condition})
+	}
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.IfStmt:
+			condition := a.source(node.Cond)
+			branch(node, KindIf, condition)
+			if block, ok := node.Else.(*ast.BlockStmt); ok {
+				branch(block, KindElse, "!("+condition+")")
+			}
+		case *ast.CaseClause:
+			if node.List == nil {
+				branch(node, KindDefault, "")
+			} else {
+				branch(node, KindCase, a.sourceList(node.List))
+			}
+		case *ast.CommClause:
+			if node.Comm == nil {
+				branch(node, KindDefault, "")
+			} else {
+				branch(node, KindCase, a.source(node.Comm))
+			}
+		}
+		return true
+	})
+	if len(fn.Branches) == 0 {
+		branch(decl, KindBody, "")
+	}
+	return fn
+}
+
+// reach marks the functions the roots refer to, and those they refer to.
+func (a *Analysis) reach(roots map[string]bool) {
+	byName := make(map[string][]*Function)
+	for _, fn := range a.Functions {
+		byName[fn.decl.Name.Name] = append(byName[fn.decl.Name.Name], fn)
+	}
+	var queue []string
+	for name := range roots {
+		queue = append(queue, name)
+	}
+	seen := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		// Methods are matched by name alone, so a call through any type
+		// reaches every method of that name
+		for _, fn := range byName[name] {
+			fn.Reached = true
+			for ref := range fn.refs {
+				queue = append(queue, ref)
+			}
+		}
+	}
+}
+
+// Unreached returns the branches of the functions no test reaches, in
+// source order.
+func (a *Analysis) Unreached() []Branch {
+	var gaps []Branch
+	for _, fn := range a.Functions {
+		if !fn.Reached {
+			gaps = append(gaps, fn.Branches...)
+		}
+	}
+	return gaps
+}
+
+// Reached returns the functions tests reach.
+func (a *Analysis) Reached() []*Function {
+	var reached []*Function
+	for _, fn := range a.Functions {
+		if fn.Reached {
+			reached = append(reached, fn)
+		}
+	}
+	return reached
+}
+
+// BranchAt returns the branch of a function on a line of a file, or the
+// function's body if no branch starts there.
+func (a *Analysis) BranchAt(file string, line int) (Branch, bool) {
+	for _, fn := range a.Functions {
+		if fn.File != file || line < fn.Line || line > fn.EndLine {
+			continue
+		}
+		for _, branch := range fn.Branches {
+			if branch.Line == line {
+				return branch, true
+			}
+		}
+		return Branch{Function: fn.Name, File: fn.File, Line: line, Kind: KindBody}, true
+	}
+	return Branch{}, false
+}
+
+// source returns a node as written.
+func (a *Analysis) source(node ast.Node) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, a.fset, node)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// sourceList returns expressions as written, separated by commas.
+func (a *Analysis) sourceList(exprs []ast.Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = a.source(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// references returns the names a node refers to, qualified or not.
+func references(node ast.Node) map[string]bool {
+	refs := make(map[string]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			refs[ident.Name] = true
+		}
+		return true
+	})
+	return refs
+}
+
+// funcName names a function as "Parse", or a method as "(*Lexer).Next".
+func funcName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	return "(" + receiverName(decl.Recv.List[0].Type) + ")." + decl.Name.Name
+}
+
+// receiverName returns a receiver's type without type parameters, such
+// as "*Lexer".
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return "?"
+}
+
+// importName returns the name a package is imported by: its given name, or
+// a guess from its path, the last element that is not a major version,
+// without a "go-" prefix or a ".v3" suffix.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p := strings.Trim(spec.Path.Value, `"`)
+	name := path.Base(p)
+	if versionSuffix.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return strings.TrimPrefix(name, "go-")
+}
+
+// sortedKeys returns a set's members in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
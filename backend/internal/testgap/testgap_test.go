@@ -0,0 +1,150 @@
+package testgap
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const parserSource = `package calc
+
+import (
+	"io"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Parse reads an expression.
+func Parse(r io.Reader) (*Node, error) {
+	tok, err := next(r)
+	if err != nil {
+		return nil, err
+	} else {
+		tok = strings.TrimSpace(tok)
+	}
+	return &Node{Op: tok}, nil
+}
+
+func next(r io.Reader) (string, error) {
+	return "", nil
+}
+
+// Eval evaluates a node.
+func (n *Node) Eval(vars map[string]int, name string, opts ...yaml.Node) int {
+	switch n.Op {
+	case "+", "-":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func Max[T int | float64](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func reset() {}
+`
+
+const parserTest = `package calc
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	Parse(nil)
+}
+`
+
+func TestAnalyze(t *testing.T) {
+	a, err := Analyze([]File{{Name: "calc.go", Content: parserSource}, {Name: "calc_test.go", Content: parserTest}})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if a.Package != "calc" || len(a.Functions) != 5 {
+		t.Fatalf("unexpected analysis %+v", a)
+	}
+	reached := make(map[string]bool)
+	for _, fn := range a.Functions {
+		reached[fn.Name] = fn.Reached
+	}
+	if !reached["Parse"] || !reached["next"] || reached["(*Node).Eval"] || reached["Max"] || reached["reset"] {
+		t.Errorf("expected Parse and what it calls reached, got %v", reached)
+	}
+
+	var got []string
+	for _, gap := range a.Unreached() {
+		got = append(got, gap.Function+" "+gap.Kind+" "+gap.Condition)
+	}
+	want := []string{`(*Node).Eval case "+", "-"`, "(*Node).Eval default ", "Max if a > b", "reset body "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected gaps %q, got %q", want, got)
+	}
+
+	if branch, ok := a.BranchAt("calc.go", 13); !ok || branch.Kind != KindElse || branch.Condition != "!(err != nil)" {
+		t.Errorf("expected the else branch on line 13, got %+v", branch)
+	}
+	if branch, ok := a.BranchAt("calc.go", 16); !ok || branch.Kind != KindBody || branch.Function != "Parse" {
+		t.Errorf("expected Parse's body on line 16, got %+v", branch)
+	}
+	if _, ok := a.BranchAt("calc.go", 3); ok {
+		t.Error("expected no branch outside functions")
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	a, err := Analyze([]File{{Name: "calc.go", Content: parserSource}, {Name: "calc_test.go", Content: parserTest}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	else13, _ := a.BranchAt("calc.go", 13)
+	src, skipped, err := a.Skeleton(append(a.Unreached(), else13))
+	if err != nil {
+		t.Fatalf("Skeleton failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != "Max" {
+		t.Errorf("expected the generic function skipped, got %v", skipped)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "calc_gaps_test.go", src, 0); err != nil {
+		t.Fatalf("expected valid Go, got %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"package calc",
+		`"io"`,
+		`yaml "gopkg.in/yaml.v3"`,
+		`"reflect"`,
+		"func TestParse_gaps(t *testing.T) {",
+		`{name: "else: !(err != nil)"},`,
+		"got, err := Parse(tt.r)",
+		"func TestNode_Eval(t *testing.T) {",
+		"recv    *Node",
+		"nameArg string",
+		"opts    []yaml.Node",
+		"got := tt.recv.Eval(tt.vars, tt.nameArg, tt.opts...)",
+		"func Test_reset(t *testing.T) {",
+		"\t\t\treset()\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("expected %q in\n%s", want, src)
+		}
+	}
+	if src, _, _ := a.Skeleton(nil); src != "" {
+		t.Errorf("expected no skeleton without gaps, got %s", src)
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	for name, files := range map[string][]File{
+		"syntax":     {{Name: "a.go", Content: "package a\nfunc {"}},
+		"not go":     {{Name: "a.py", Content: "print(1)"}},
+		"two pkgs":   {{Name: "a.go", Content: "package a"}, {Name: "b.go", Content: "package b"}},
+		"tests only": {{Name: "a_test.go", Content: "package a"}},
+	} {
+		if _, err := Analyze(files); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("%s: expected ErrInvalidSource, got %v", name, err)
+		}
+	}
+}
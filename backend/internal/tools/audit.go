@@ -0,0 +1,131 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxRecentAudit bounds the entries an audit log keeps in memory for
+// Entries.
+const maxRecentAudit = 10000
+
+// Audit outcomes.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeDenied    = "denied"
+	OutcomeFailed    = "failed"
+)
+
+// AuditEntry records one tool call.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Tenant string    `json:"tenant"`
+	Agent  string    `json:"agent"`
+	// Subject is the authenticated caller, if any
+	Subject string `json:"subject,omitempty"`
+	Tool    string `json:"tool"`
+	Action  string `json:"action"`
+	// Target is what the call acted on, such as a project key
+	Target  string `json:"target,omitempty"`
+	Outcome string `json:"outcome"`
+	// Reference identifies what the call created, such as an issue key
+	Reference string `json:"reference,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AuditLog is an append-only trail of tool calls, written as one JSON
+// object per line. The latest entries are also kept in memory so they can
+// be read back.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+	// closer closes the file the log owns, if any
+	closer io.Closer
+	// recent are the latest entries, oldest first
+	recent []AuditEntry
+}
+
+// NewAuditLog creates an audit log writing to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog opens an audit log file for appending, creating it if
+// needed. The entries already in the file are read back, so Entries
+// covers calls made before a restart; lines that do not decode are
+// skipped.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	audit := &AuditLog{w: file, closer: file}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			audit.remember(entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return audit, nil
+}
+
+// Record appends an entry, stamping its time if unset.
+func (a *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	a.remember(entry)
+	return nil
+}
+
+// Entries returns the entries recorded since a time, oldest first. Only
+// the latest entries are kept in memory; older ones are in the log alone.
+func (a *AuditLog) Entries(since time.Time) []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entries := make([]AuditEntry, 0)
+	for _, entry := range a.recent {
+		if !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// remember keeps an entry in memory, dropping the oldest past the bound.
+func (a *AuditLog) remember(entry AuditEntry) {
+	if len(a.recent) == maxRecentAudit {
+		copy(a.recent, a.recent[1:])
+		a.recent = a.recent[:len(a.recent)-1]
+	}
+	a.recent = append(a.recent, entry)
+}
+
+// Close closes the log's file, if it owns one.
+func (a *AuditLog) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readAudit decodes the entries of an audit trail.
+func readAudit(t *testing.T, data []byte) []AuditEntry {
+	t.Helper()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("expected one JSON entry per line, got %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLogRecord(t *testing.T) {
+	var buf bytes.Buffer
+	audit := NewAuditLog(&buf)
+	if err := audit.Record(AuditEntry{Tenant: "acme", Agent: "FORTRESS", Tool: "issue_tracker", Outcome: OutcomeSucceeded, Reference: "SEC-1"}); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+	if err := audit.Record(AuditEntry{Tenant: "acme", Agent: "FORTRESS", Tool: "issue_tracker", Outcome: OutcomeDenied}); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	entries := readAudit(t, buf.Bytes())
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Reference != "SEC-1" || entries[0].Time.IsZero() {
+		t.Errorf("expected reference and stamped time, got %+v", entries[0])
+	}
+	if audit.Close() != nil {
+		t.Error("expected closing a writer-backed log to be a no-op")
+	}
+}
+
+func TestOpenAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		audit, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatalf("failed to open audit log: %v", err)
+		}
+		if err := audit.Record(AuditEntry{Tenant: "acme", Outcome: OutcomeSucceeded}); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+		if err := audit.Close(); err != nil {
+			t.Fatalf("failed to close: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if entries := readAudit(t, data); len(entries) != 2 {
+		t.Errorf("expected entries appended across opens, got %d", len(entries))
+	}
+}
+
+func TestAuditLogEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit.Record(AuditEntry{Time: old, Tenant: "acme", Outcome: OutcomeDenied})
+	audit.Record(AuditEntry{Tenant: "acme", Outcome: OutcomeSucceeded})
+	audit.Close()
+
+	reopened, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("failed to reopen audit log: %v", err)
+	}
+	defer reopened.Close()
+	if entries := reopened.Entries(time.Time{}); len(entries) != 2 || !entries[0].Time.Equal(old) {
+		t.Fatalf("expected the file's entries read back oldest first, got %+v", entries)
+	}
+	if entries := reopened.Entries(old.Add(time.Hour)); len(entries) != 1 || entries[0].Outcome != OutcomeSucceeded {
+		t.Errorf("expected only entries since the time, got %+v", entries)
+	}
+}
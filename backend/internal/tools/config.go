@@ -0,0 +1,205 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrInvalidConfig is returned for tool files that fail validation.
+var ErrInvalidConfig = errdefs.New(errdefs.ErrInvalidArgument, "invalid tools config")
+
+// Issue tracker kinds.
+const (
+	TrackerJira   = "jira"
+	TrackerLinear = "linear"
+)
+
+// defaultLinearURL is Linear's GraphQL API.
+const defaultLinearURL = "https://api.linear.app/graphql"
+
+// Config lists the tenants whose credentials tools act with.
+type Config struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// Tenant is one customer's tool credentials and the agents allowed to use
+// them.
+type Tenant struct {
+	ID string `yaml:"id"`
+	// IssueTracker is where the tenant's issues are created; nil disables
+	// issue creation for the tenant
+	IssueTracker *IssueTrackerConfig `yaml:"issue_tracker"`
+	// Grants are the tool calls agents may make for the tenant; calls no
+	// grant allows are denied
+	Grants []Grant `yaml:"grants"`
+}
+
+// Grant allows agents to make calls with tools for a tenant. "*" in
+// Agents or Tools matches every agent or tool.
+type Grant struct {
+	Agents []string `yaml:"agents"`
+	Tools  []string `yaml:"tools"`
+	// Actions narrows the grant to some of the tools' actions; empty
+	// allows them all
+	Actions []string `yaml:"actions"`
+}
+
+// IssueTrackerConfig holds a tenant's issue tracker credentials.
+type IssueTrackerConfig struct {
+	// Kind is jira or linear
+	Kind string `yaml:"kind"`
+	// URL is the Jira site, such as https://acme.atlassian.net, or the
+	// Linear API endpoint, which defaults to Linear's
+	URL string `yaml:"url"`
+	// Email is the Jira account the API token belongs to
+	Email string `yaml:"email"`
+	// Token is the Jira API token or Linear API key
+	Token string `yaml:"token"`
+	// Projects are the Jira project keys or Linear team keys issues may be
+	// created in; nothing else is allowed
+	Projects []string `yaml:"projects"`
+}
+
+// LoadConfig reads and validates a tools file. Tokens and URLs may refer
+// to environment variables as $NAME or ${NAME}, so they need not be
+// stored in the file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tools config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes and validates a tools config. Unknown fields are
+// errors, so misspelled keys are caught at load time.
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse tools YAML: %w", err)
+	}
+
+	var problems []error
+	seen := make(map[string]bool)
+	for i := range cfg.Tenants {
+		tenant := &cfg.Tenants[i]
+		if tenant.ID == "" {
+			problems = append(problems, fmt.Errorf("tenant %d: id is required", i))
+		} else if seen[tenant.ID] {
+			problems = append(problems, fmt.Errorf("tenant %d: %s is duplicated", i, tenant.ID))
+		}
+		seen[tenant.ID] = true
+
+		if tracker := tenant.IssueTracker; tracker != nil {
+			tracker.URL = os.ExpandEnv(tracker.URL)
+			tracker.Email = os.ExpandEnv(tracker.Email)
+			tracker.Token = os.ExpandEnv(tracker.Token)
+			if tracker.Kind == TrackerLinear && tracker.URL == "" {
+				tracker.URL = defaultLinearURL
+			}
+			tracker.URL = strings.TrimSuffix(tracker.URL, "/")
+			for j, project := range tracker.Projects {
+				tracker.Projects[j] = strings.ToUpper(project)
+			}
+			for _, err := range tracker.validate() {
+				problems = append(problems, fmt.Errorf("tenant %s issue tracker: %w", tenant.ID, err))
+			}
+		}
+		for j := range tenant.Grants {
+			grant := &tenant.Grants[j]
+			for k, agent := range grant.Agents {
+				grant.Agents[k] = strings.ToUpper(strings.TrimSpace(agent))
+			}
+			for _, err := range grant.validate() {
+				problems = append(problems, fmt.Errorf("tenant %s grant %d: %w", tenant.ID, j, err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(problems...))
+	}
+	return &cfg, nil
+}
+
+// validate returns an issue tracker's problems.
+func (c *IssueTrackerConfig) validate() []error {
+	var problems []error
+	switch c.Kind {
+	case TrackerJira:
+		if c.Email == "" {
+			problems = append(problems, errors.New("email is required for Jira"))
+		}
+	case TrackerLinear:
+	default:
+		problems = append(problems, fmt.Errorf("unknown kind %q", c.Kind))
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		problems = append(problems, fmt.Errorf("url %q is not an HTTP URL", c.URL))
+	}
+	if c.Token == "" {
+		problems = append(problems, errors.New("token is required"))
+	}
+	if len(c.Projects) == 0 {
+		problems = append(problems, errors.New("at least one project is required"))
+	}
+	return problems
+}
+
+// allows reports whether issues may be created in a project.
+func (c *IssueTrackerConfig) allows(project string) bool {
+	for _, p := range c.Projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns a grant's problems. Tools and actions must be known, so
+// that a misspelled name is caught rather than silently granting nothing.
+func (g *Grant) validate() []error {
+	var problems []error
+	if len(g.Agents) == 0 {
+		problems = append(problems, errors.New("at least one agent is required"))
+	}
+	for _, agent := range g.Agents {
+		if agent == "" {
+			problems = append(problems, errors.New("agent codename is required"))
+		}
+	}
+	if len(g.Tools) == 0 {
+		problems = append(problems, errors.New("at least one tool is required"))
+	}
+	for _, tool := range g.Tools {
+		if _, ok := capabilities[tool]; !ok && tool != Wildcard {
+			problems = append(problems, fmt.Errorf("unknown tool %q", tool))
+		}
+	}
+	for _, action := range g.Actions {
+		if !g.offers(action) {
+			problems = append(problems, fmt.Errorf("action %q is not offered by the grant's tools", action))
+		}
+	}
+	return problems
+}
+
+// offers reports whether one of the grant's tools has an action.
+func (g *Grant) offers(action string) bool {
+	for tool, actions := range capabilities {
+		if matches(g.Tools, tool) && contains(actions, action) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testToolsYAML = `
+tenants:
+  - id: acme
+    issue_tracker:
+      kind: jira
+      url: https://acme.atlassian.example/
+      email: bot@acme.example
+      token: ${TEST_JIRA_TOKEN}
+      projects: [sec, OPS]
+    grants:
+      - agents: [fortress, CIPHER]
+        tools: [issue_tracker]
+  - id: globex
+    issue_tracker:
+      kind: linear
+      token: lin_api_test
+      projects: [ENG]
+  - id: initech
+`
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv("TEST_JIRA_TOKEN", "jira-token")
+	cfg, err := ParseConfig([]byte(testToolsYAML))
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	if len(cfg.Tenants) != 3 {
+		t.Fatalf("expected 3 tenants, got %d", len(cfg.Tenants))
+	}
+
+	jira := cfg.Tenants[0].IssueTracker
+	if jira.Token != "jira-token" || jira.URL != "https://acme.atlassian.example" {
+		t.Errorf("expected expanded token and trimmed URL, got %q and %q", jira.Token, jira.URL)
+	}
+	if !jira.allows("SEC") || jira.allows("HR") {
+		t.Errorf("expected upper-cased project allowlist, got %v", jira.Projects)
+	}
+	if linear := cfg.Tenants[1].IssueTracker; linear.URL != defaultLinearURL {
+		t.Errorf("expected default Linear URL, got %q", linear.URL)
+	}
+	if cfg.Tenants[2].IssueTracker != nil {
+		t.Error("expected no issue tracker for initech")
+	}
+	if grants := cfg.Tenants[0].Grants; len(grants) != 1 || grants[0].Agents[0] != "FORTRESS" {
+		t.Errorf("expected upper-cased grant codenames, got %+v", grants)
+	}
+	if len(cfg.Tenants[1].Grants) != 0 {
+		t.Errorf("expected no grants for globex, got %+v", cfg.Tenants[1].Grants)
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"no id", "tenants: [{}]", "id is required"},
+		{"duplicate", "tenants: [{id: a}, {id: a}]", "duplicated"},
+		{"unknown kind", "tenants: [{id: a, issue_tracker: {kind: trello, url: https://x.example, token: t, projects: [P]}}]", "unknown kind"},
+		{"jira without email", "tenants: [{id: a, issue_tracker: {kind: jira, url: https://x.example, token: t, projects: [P]}}]", "email is required"},
+		{"jira without url", "tenants: [{id: a, issue_tracker: {kind: jira, email: e, token: t, projects: [P]}}]", "not an HTTP URL"},
+		{"no token", "tenants: [{id: a, issue_tracker: {kind: linear, projects: [P]}}]", "token is required"},
+		{"no projects", "tenants: [{id: a, issue_tracker: {kind: linear, token: t}}]", "at least one project"},
+		{"grant without agents", "tenants: [{id: a, grants: [{tools: [issue_tracker]}]}]", "at least one agent"},
+		{"grant without tools", "tenants: [{id: a, grants: [{agents: [APEX]}]}]", "at least one tool"},
+		{"unknown tool", "tenants: [{id: a, grants: [{agents: [APEX], tools: [issue_traker]}]}]", `unknown tool "issue_traker"`},
+		{"unknown action", "tenants: [{id: a, grants: [{agents: ['*'], tools: [issue_tracker], actions: [delete_issue]}]}]", `action "delete_issue" is not offered`},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.yaml))
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error to mention %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := ParseConfig([]byte("tenants: [{id: a, tracker: {}}]")); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	if err := os.WriteFile(path, []byte("tenants: [{id: acme}]"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected config to load, got %v", err)
+	}
+	if len(cfg.Tenants) != 1 {
+		t.Errorf("expected 1 tenant, got %d", len(cfg.Tenants))
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
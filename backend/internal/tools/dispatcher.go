@@ -0,0 +1,133 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// ErrToolNotAllowed is returned for tool calls no grant allows.
+var ErrToolNotAllowed = errdefs.New(errdefs.ErrForbidden, "tool call is not allowed")
+
+// Tools and their actions.
+const (
+	ToolIssueTracker  = "issue_tracker"
+	ActionCreateIssue = "create_issue"
+)
+
+// Wildcard in a grant's agents or tools matches every agent or tool.
+const Wildcard = "*"
+
+// capabilities lists each tool's actions. Grants may only name these, and
+// a new tool is added here with the actions it dispatches.
+var capabilities = map[string][]string{
+	ToolIssueTracker: {ActionCreateIssue},
+}
+
+// Matrix is the authorization matrix of agents, tools and tenants: which
+// agents may make which tool calls for each tenant. It denies by default;
+// only calls a tenant's grants allow are authorized.
+type Matrix struct {
+	grants map[string][]Grant
+}
+
+// NewMatrix creates the matrix of the grants in cfg.
+func NewMatrix(cfg *Config) *Matrix {
+	m := &Matrix{grants: make(map[string][]Grant)}
+	for _, tenant := range cfg.Tenants {
+		m.grants[tenant.ID] = tenant.Grants
+	}
+	return m
+}
+
+// Allows reports whether an agent may use a tool's action for a tenant.
+func (m *Matrix) Allows(tenant, agent, tool, action string) bool {
+	for _, grant := range m.grants[tenant] {
+		if matches(grant.Agents, agent) && matches(grant.Tools, tool) &&
+			(len(grant.Actions) == 0 || contains(grant.Actions, action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether value is listed, or the list holds the wildcard.
+func matches(list []string, value string) bool {
+	return contains(list, value) || contains(list, Wildcard)
+}
+
+// contains reports whether value is listed.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Call is one tool call an agent makes for a tenant.
+type Call struct {
+	Tenant string
+	Agent  string
+	// Subject is the authenticated caller, if any
+	Subject string
+	Tool    string
+	Action  string
+	// Target is what the call acts on, such as a project key
+	Target string
+}
+
+// Dispatcher is the one way tools act: it authorizes each call against
+// the matrix, runs the allowed ones, and records every call, allowed or
+// not, in the audit trail.
+type Dispatcher struct {
+	matrix *Matrix
+	audit  *AuditLog
+}
+
+// NewDispatcher creates a dispatcher authorizing calls with matrix and
+// recording them in audit.
+func NewDispatcher(matrix *Matrix, audit *AuditLog) *Dispatcher {
+	return &Dispatcher{matrix: matrix, audit: audit}
+}
+
+// Dispatch runs a call if the matrix allows it. run performs the call and
+// returns a reference to what it created, such as an issue key. Calls the
+// matrix denies, and calls run refuses, are audited as denied; calls that
+// fail upstream are audited as failed.
+func (d *Dispatcher) Dispatch(ctx context.Context, call Call, run func(context.Context) (string, error)) error {
+	entry := AuditEntry{
+		Tenant:  call.Tenant,
+		Agent:   call.Agent,
+		Subject: call.Subject,
+		Tool:    call.Tool,
+		Action:  call.Action,
+		Target:  call.Target,
+	}
+
+	var err error
+	if d.matrix.Allows(call.Tenant, call.Agent, call.Tool, call.Action) {
+		entry.Reference, err = run(ctx)
+	} else {
+		err = fmt.Errorf("%w: %s may not %s with %s for tenant %s", ErrToolNotAllowed, call.Agent, call.Action, call.Tool, call.Tenant)
+	}
+	switch {
+	case err == nil:
+		entry.Outcome = OutcomeSucceeded
+	case errors.Is(err, errdefs.ErrProviderFailure):
+		entry.Outcome, entry.Reference, entry.Error = OutcomeFailed, "", err.Error()
+	default:
+		entry.Outcome, entry.Reference, entry.Error = OutcomeDenied, "", err.Error()
+	}
+	if auditErr := d.audit.Record(entry); auditErr != nil {
+		// The call has happened either way; the entry is logged so the
+		// trail can be reconciled
+		log.Printf("Error recording %s tool call %+v: %v", call.Tool, entry, auditErr)
+	}
+	return err
+}
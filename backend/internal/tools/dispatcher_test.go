@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMatrixAllows(t *testing.T) {
+	matrix := NewMatrix(&Config{Tenants: []Tenant{
+		{ID: "acme", Grants: []Grant{
+			{Agents: []string{"FORTRESS", "CIPHER"}, Tools: []string{ToolIssueTracker}},
+		}},
+		{ID: "globex", Grants: []Grant{
+			{Agents: []string{Wildcard}, Tools: []string{Wildcard}, Actions: []string{ActionCreateIssue}},
+		}},
+		{ID: "initech"},
+	}})
+
+	tests := []struct {
+		tenant, agent, tool, action string
+		want                        bool
+	}{
+		{"acme", "FORTRESS", ToolIssueTracker, ActionCreateIssue, true},
+		{"acme", "CIPHER", ToolIssueTracker, ActionCreateIssue, true},
+		{"acme", "CANVAS", ToolIssueTracker, ActionCreateIssue, false},
+		{"acme", "FORTRESS", "code_exec", "run", false},
+		{"globex", "CANVAS", ToolIssueTracker, ActionCreateIssue, true},
+		{"globex", "CANVAS", ToolIssueTracker, "close_issue", false},
+		{"initech", "FORTRESS", ToolIssueTracker, ActionCreateIssue, false},
+		{"umbrella", "FORTRESS", ToolIssueTracker, ActionCreateIssue, false},
+	}
+	for _, tt := range tests {
+		if got := matrix.Allows(tt.tenant, tt.agent, tt.tool, tt.action); got != tt.want {
+			t.Errorf("%s %s %s/%s: expected %v, got %v", tt.tenant, tt.agent, tt.tool, tt.action, tt.want, got)
+		}
+	}
+}
+
+func TestDispatcherAuditsEveryCall(t *testing.T) {
+	var trail bytes.Buffer
+	cfg := &Config{Tenants: []Tenant{
+		{ID: "acme", Grants: []Grant{{Agents: []string{"FORTRESS"}, Tools: []string{ToolIssueTracker}}}},
+	}}
+	dispatcher := NewDispatcher(NewMatrix(cfg), NewAuditLog(&trail))
+	call := Call{Tenant: "acme", Agent: "FORTRESS", Subject: "user-1", Tool: ToolIssueTracker, Action: ActionCreateIssue, Target: "SEC"}
+
+	if err := dispatcher.Dispatch(context.Background(), call, func(context.Context) (string, error) {
+		return "SEC-1", nil
+	}); err != nil {
+		t.Fatalf("expected the call allowed, got %v", err)
+	}
+
+	denied := call
+	denied.Agent = "CANVAS"
+	ran := false
+	err := dispatcher.Dispatch(context.Background(), denied, func(context.Context) (string, error) {
+		ran = true
+		return "SEC-2", nil
+	})
+	if !errors.Is(err, ErrToolNotAllowed) || ran {
+		t.Errorf("expected the call denied without running, got %v (ran %v)", err, ran)
+	}
+
+	upstream := fmt.Errorf("%w: 503", ErrTracker)
+	if err := dispatcher.Dispatch(context.Background(), call, func(context.Context) (string, error) {
+		return "", upstream
+	}); !errors.Is(err, ErrTracker) {
+		t.Errorf("expected the tool's error returned, got %v", err)
+	}
+
+	entries := readAudit(t, trail.Bytes())
+	if len(entries) != 3 {
+		t.Fatalf("expected every call audited, got %d entries", len(entries))
+	}
+	if e := entries[0]; e.Outcome != OutcomeSucceeded || e.Reference != "SEC-1" || e.Subject != "user-1" || e.Action != ActionCreateIssue || e.Target != "SEC" {
+		t.Errorf("expected the allowed call audited with its reference, got %+v", e)
+	}
+	if e := entries[1]; e.Outcome != OutcomeDenied || e.Agent != "CANVAS" || e.Reference != "" || e.Error == "" {
+		t.Errorf("expected the ungranted call audited as denied, got %+v", e)
+	}
+	if e := entries[2]; e.Outcome != OutcomeFailed || e.Error != upstream.Error() {
+		t.Errorf("expected the upstream failure audited as failed, got %+v", e)
+	}
+}
@@ -0,0 +1,207 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/errdefs"
+)
+
+// maxIssueBodyBytes bounds an issue creation request.
+const maxIssueBodyBytes = 256 << 10
+
+var (
+	// ErrUnknownTenant is returned for tenants without an issue tracker
+	ErrUnknownTenant = errdefs.New(errdefs.ErrNotFound, "tenant has no issue tracker")
+	// ErrProjectNotAllowed is returned for projects outside the tenant's
+	// allowlist
+	ErrProjectNotAllowed = errdefs.New(errdefs.ErrForbidden, "project is not allowed")
+	// ErrInvalidIssue is returned for issues missing required fields
+	ErrInvalidIssue = errdefs.New(errdefs.ErrInvalidArgument, "invalid issue")
+	// ErrTracker is returned when the issue tracker rejects a request
+	ErrTracker = errdefs.New(errdefs.ErrProviderFailure, "issue tracker error")
+)
+
+// Issue priorities, mapped onto each tracker's own scale.
+const (
+	PriorityUrgent = "urgent"
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// Issue is an issue an agent asks to create.
+type Issue struct {
+	// Project is the Jira project key or Linear team key
+	Project     string `json:"project"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Type is the Jira issue type, Task by default; Linear has no types
+	// and applies the team label of that name instead
+	Type string `json:"type,omitempty"`
+	// Priority is urgent, high, medium or low; empty leaves the tracker's
+	// default
+	Priority string   `json:"priority,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+}
+
+// CreatedIssue identifies an issue in its tracker.
+type CreatedIssue struct {
+	// ID is the tracker's internal ID
+	ID string `json:"id"`
+	// Key is the human-readable key, such as SEC-42
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// issueTracker creates issues in one tenant's tracker.
+type issueTracker interface {
+	createIssue(ctx context.Context, issue *Issue) (*CreatedIssue, error)
+}
+
+// IssueTool creates issues in tenants' trackers, within each tenant's
+// project allowlist, through a dispatcher that authorizes and audits
+// every attempt.
+type IssueTool struct {
+	configs    map[string]*IssueTrackerConfig
+	trackers   map[string]issueTracker
+	dispatcher *Dispatcher
+}
+
+// NewIssueTool creates the issue tool for the tenants in cfg that have an
+// issue tracker.
+func NewIssueTool(cfg *Config, dispatcher *Dispatcher) *IssueTool {
+	client := &http.Client{
+		Timeout: 15 * time.Second,
+	}
+	return newIssueTool(cfg, dispatcher, func(config *IssueTrackerConfig) issueTracker {
+		if config.Kind == TrackerLinear {
+			return &linearTracker{config: config, client: client}
+		}
+		return &jiraTracker{config: config, client: client}
+	})
+}
+
+// newIssueTool creates an issue tool with the trackers tracker returns for
+// each tenant's config.
+func newIssueTool(cfg *Config, dispatcher *Dispatcher, tracker func(*IssueTrackerConfig) issueTracker) *IssueTool {
+	t := &IssueTool{
+		configs:    make(map[string]*IssueTrackerConfig),
+		trackers:   make(map[string]issueTracker),
+		dispatcher: dispatcher,
+	}
+	for _, tenant := range cfg.Tenants {
+		if tenant.IssueTracker == nil {
+			continue
+		}
+		t.configs[tenant.ID] = tenant.IssueTracker
+		t.trackers[tenant.ID] = tracker(tenant.IssueTracker)
+	}
+	return t
+}
+
+// CreateIssue creates an issue in a tenant's tracker on behalf of an
+// agent, if the agent is granted the issue tracker for the tenant.
+// subject is the authenticated caller, recorded in the audit trail.
+func (t *IssueTool) CreateIssue(ctx context.Context, tenant, agent, subject string, issue Issue) (*CreatedIssue, error) {
+	issue.Project = strings.ToUpper(strings.TrimSpace(issue.Project))
+	call := Call{
+		Tenant:  tenant,
+		Agent:   agent,
+		Subject: subject,
+		Tool:    ToolIssueTracker,
+		Action:  ActionCreateIssue,
+		Target:  issue.Project,
+	}
+
+	var created *CreatedIssue
+	err := t.dispatcher.Dispatch(ctx, call, func(ctx context.Context) (string, error) {
+		var err error
+		created, err = t.createIssue(ctx, tenant, &issue)
+		if err != nil {
+			return "", err
+		}
+		return created.Key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// createIssue checks an issue against the tenant's tracker and creates it.
+func (t *IssueTool) createIssue(ctx context.Context, tenant string, issue *Issue) (*CreatedIssue, error) {
+	config, ok := t.configs[tenant]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+	if err := issue.validate(); err != nil {
+		return nil, err
+	}
+	if !config.allows(issue.Project) {
+		return nil, fmt.Errorf("%w: %s", ErrProjectNotAllowed, issue.Project)
+	}
+	return t.trackers[tenant].createIssue(ctx, issue)
+}
+
+// validate checks an issue's required fields.
+func (issue *Issue) validate() error {
+	switch {
+	case issue.Project == "":
+		return fmt.Errorf("%w: project is required", ErrInvalidIssue)
+	case strings.TrimSpace(issue.Title) == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidIssue)
+	}
+	switch issue.Priority {
+	case "", PriorityUrgent, PriorityHigh, PriorityMedium, PriorityLow:
+	default:
+		return fmt.Errorf("%w: unknown priority %q", ErrInvalidIssue, issue.Priority)
+	}
+	return nil
+}
+
+// ============================================================================
+// HTTP
+// ============================================================================
+
+// CreateIssueRequest is the payload of POST /tools/issues.
+type CreateIssueRequest struct {
+	Tenant string `json:"tenant"`
+	// Agent is the codename of the agent filing the issue
+	Agent string `json:"agent"`
+	Issue
+}
+
+// ServeCreateIssue handles POST /tools/issues - creates an issue for an
+// agent and returns 201 with the created issue's ID, key and URL.
+func (t *IssueTool) ServeCreateIssue(w http.ResponseWriter, r *http.Request) {
+	var req CreateIssueRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxIssueBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errdefs.WriteError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	if req.Tenant == "" || req.Agent == "" {
+		errdefs.WriteError(w, "tenant and agent are required", http.StatusBadRequest)
+		return
+	}
+
+	var subject string
+	if claims := auth.GetClaims(r.Context()); claims != nil {
+		subject = claims.Subject
+	}
+	created, err := t.CreateIssue(r.Context(), req.Tenant, strings.ToUpper(req.Agent), subject, req.Issue)
+	if err != nil {
+		log.Printf("Issue creation for %s failed: %v", req.Tenant, err)
+		errdefs.WriteHTTP(w, err)
+		return
+	}
+	errdefs.WriteJSON(w, http.StatusCreated, created)
+}
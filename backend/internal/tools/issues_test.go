@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/auth"
+)
+
+// setupIssueTool creates an issue tool for tenant acme, whose Jira allows
+// project SEC and FORTRESS to file issues, recording its audit trail in
+// the returned buffer.
+func setupIssueTool(t *testing.T) (*IssueTool, *bytes.Buffer) {
+	t.Helper()
+	server := newJiraServer(t, nil)
+	var trail bytes.Buffer
+	fortress := []Grant{{Agents: []string{"FORTRESS"}, Tools: []string{ToolIssueTracker}}}
+	cfg := &Config{Tenants: []Tenant{
+		{ID: "acme", IssueTracker: &IssueTrackerConfig{Kind: TrackerJira, URL: server.URL, Email: "bot@acme.example", Token: "jira-token", Projects: []string{"SEC"}}, Grants: fortress},
+		{ID: "broken", IssueTracker: &IssueTrackerConfig{Kind: TrackerJira, URL: server.URL, Email: "bot@acme.example", Token: "revoked", Projects: []string{"SEC"}}, Grants: fortress},
+		{ID: "initech", Grants: fortress},
+	}}
+	return NewIssueTool(cfg, NewDispatcher(NewMatrix(cfg), NewAuditLog(&trail))), &trail
+}
+
+func TestIssueToolCreateIssue(t *testing.T) {
+	tool, trail := setupIssueTool(t)
+
+	created, err := tool.CreateIssue(context.Background(), "acme", "FORTRESS", "user-1", Issue{Project: "sec", Title: "Weak TLS config"})
+	if err != nil {
+		t.Fatalf("expected issue created, got %v", err)
+	}
+	if created.Key != "SEC-42" {
+		t.Errorf("expected SEC-42, got %+v", created)
+	}
+
+	tests := []struct {
+		tenant string
+		agent  string
+		issue  Issue
+		err    error
+	}{
+		{"acme", "FORTRESS", Issue{Project: "HR", Title: "t"}, ErrProjectNotAllowed},
+		{"acme", "FORTRESS", Issue{Project: "SEC"}, ErrInvalidIssue},
+		{"acme", "FORTRESS", Issue{Project: "SEC", Title: "t", Priority: "critical"}, ErrInvalidIssue},
+		{"acme", "CANVAS", Issue{Project: "SEC", Title: "t"}, ErrToolNotAllowed},
+		{"umbrella", "FORTRESS", Issue{Project: "SEC", Title: "t"}, ErrToolNotAllowed},
+		{"initech", "FORTRESS", Issue{Project: "SEC", Title: "t"}, ErrUnknownTenant},
+		{"broken", "FORTRESS", Issue{Project: "SEC", Title: "t"}, ErrTracker},
+	}
+	for _, tt := range tests {
+		if _, err := tool.CreateIssue(context.Background(), tt.tenant, tt.agent, "", tt.issue); !errors.Is(err, tt.err) {
+			t.Errorf("%s %s %+v: expected %v, got %v", tt.tenant, tt.agent, tt.issue, tt.err, err)
+		}
+	}
+
+	entries := readAudit(t, trail.Bytes())
+	if len(entries) != 1+len(tests) {
+		t.Fatalf("expected every call audited, got %d entries", len(entries))
+	}
+	first := entries[0]
+	if first.Outcome != OutcomeSucceeded || first.Reference != "SEC-42" || first.Target != "SEC" || first.Agent != "FORTRESS" || first.Subject != "user-1" {
+		t.Errorf("expected created issue key in the audit trail, got %+v", first)
+	}
+	if entries[1].Outcome != OutcomeDenied || entries[1].Error == "" {
+		t.Errorf("expected disallowed project audited as denied, got %+v", entries[1])
+	}
+	if denied := entries[4]; denied.Outcome != OutcomeDenied || denied.Agent != "CANVAS" || denied.Action != ActionCreateIssue || denied.Target != "SEC" {
+		t.Errorf("expected ungranted agent audited as denied, got %+v", denied)
+	}
+	if last := entries[len(entries)-1]; last.Outcome != OutcomeFailed || last.Reference != "" {
+		t.Errorf("expected tracker error audited as failed, got %+v", last)
+	}
+}
+
+func TestServeCreateIssue(t *testing.T) {
+	tool, trail := setupIssueTool(t)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"created", `{"tenant": "acme", "agent": "fortress", "project": "SEC", "title": "Weak TLS config"}`, http.StatusCreated},
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"no agent", `{"tenant": "acme", "project": "SEC", "title": "t"}`, http.StatusBadRequest},
+		{"no title", `{"tenant": "acme", "agent": "FORTRESS", "project": "SEC"}`, http.StatusBadRequest},
+		{"ungranted tenant", `{"tenant": "umbrella", "agent": "FORTRESS", "project": "SEC", "title": "t"}`, http.StatusForbidden},
+		{"ungranted agent", `{"tenant": "acme", "agent": "CANVAS", "project": "SEC", "title": "t"}`, http.StatusForbidden},
+		{"no tracker", `{"tenant": "initech", "agent": "FORTRESS", "project": "SEC", "title": "t"}`, http.StatusNotFound},
+		{"disallowed project", `{"tenant": "acme", "agent": "FORTRESS", "project": "HR", "title": "t"}`, http.StatusForbidden},
+		{"tracker error", `{"tenant": "broken", "agent": "FORTRESS", "project": "SEC", "title": "t"}`, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/tools/issues", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		tool.ServeCreateIssue(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, w.Code, w.Body.String())
+		}
+	}
+
+	// The authenticated caller is recorded with the agent
+	body := `{"tenant": "acme", "agent": "FORTRESS", "project": "SEC", "title": "t"}`
+	ctx := context.WithValue(context.Background(), auth.ClaimsContextKey, &auth.Claims{Subject: "user-1"})
+	req := httptest.NewRequest("POST", "/tools/issues", strings.NewReader(body)).WithContext(ctx)
+	tool.ServeCreateIssue(httptest.NewRecorder(), req)
+	entries := readAudit(t, trail.Bytes())
+	if last := entries[len(entries)-1]; last.Subject != "user-1" || last.Agent != "FORTRESS" {
+		t.Errorf("expected caller and agent audited, got %+v", last)
+	}
+	if entries[0].Agent != "FORTRESS" {
+		t.Errorf("expected agent codename upper-cased, got %s", entries[0].Agent)
+	}
+}
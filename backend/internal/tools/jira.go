@@ -0,0 +1,100 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// jiraPriorities maps issue priorities onto Jira's default scheme.
+var jiraPriorities = map[string]string{
+	PriorityUrgent: "Highest",
+	PriorityHigh:   "High",
+	PriorityMedium: "Medium",
+	PriorityLow:    "Low",
+}
+
+// jiraTracker creates issues with the Jira Cloud REST API.
+type jiraTracker struct {
+	config *IssueTrackerConfig
+	client *http.Client
+}
+
+// createIssue creates an issue with POST /rest/api/3/issue.
+func (j *jiraTracker) createIssue(ctx context.Context, issue *Issue) (*CreatedIssue, error) {
+	issueType := issue.Type
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]interface{}{
+		"project":   map[string]string{"key": issue.Project},
+		"summary":   issue.Title,
+		"issuetype": map[string]string{"name": issueType},
+	}
+	if issue.Description != "" {
+		fields["description"] = jiraDocument(issue.Description)
+	}
+	if priority, ok := jiraPriorities[issue.Priority]; ok {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+	if len(issue.Labels) > 0 {
+		// Jira labels can't contain spaces
+		labels := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			labels[i] = strings.ReplaceAll(label, " ", "-")
+		}
+		fields["labels"] = labels
+	}
+	body, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.config.URL+"/rest/api/3/issue", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(j.config.Email, j.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTracker, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%w: Jira returned status %d: %s", ErrTracker, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode Jira response: %v", ErrTracker, err)
+	}
+	return &CreatedIssue{ID: created.ID, Key: created.Key, URL: j.config.URL + "/browse/" + created.Key}, nil
+}
+
+// jiraDocument wraps text in an Atlassian Document, one paragraph per
+// blank-line-separated block.
+func jiraDocument(text string) map[string]interface{} {
+	var content []map[string]interface{}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		content = append(content, map[string]interface{}{
+			"type":    "paragraph",
+			"content": []map[string]string{{"type": "text", "text": paragraph}},
+		})
+	}
+	return map[string]interface{}{"type": "doc", "version": 1, "content": content}
+}
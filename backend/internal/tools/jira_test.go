@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newJiraServer mocks POST /rest/api/3/issue, recording the fields sent and
+// answering with key SEC-42.
+func newJiraServer(t *testing.T, fields *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, token, ok := r.BasicAuth()
+		if r.URL.Path != "/rest/api/3/issue" || !ok || email != "bot@acme.example" || token != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if fields != nil {
+			*fields = body.Fields
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": "10042", "key": "SEC-42"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJiraCreateIssue(t *testing.T) {
+	var fields map[string]interface{}
+	server := newJiraServer(t, &fields)
+	tracker := &jiraTracker{
+		config: &IssueTrackerConfig{Kind: TrackerJira, URL: server.URL, Email: "bot@acme.example", Token: "jira-token"},
+		client: http.DefaultClient,
+	}
+
+	created, err := tracker.createIssue(context.Background(), &Issue{
+		Project:     "SEC",
+		Title:       "SQL injection in search",
+		Description: "The q parameter is concatenated.\n\nUse a prepared statement.",
+		Type:        "Bug",
+		Priority:    PriorityUrgent,
+		Labels:      []string{"security review"},
+	})
+	if err != nil {
+		t.Fatalf("expected issue created, got %v", err)
+	}
+	if created.ID != "10042" || created.Key != "SEC-42" || created.URL != server.URL+"/browse/SEC-42" {
+		t.Errorf("expected created issue SEC-42, got %+v", created)
+	}
+
+	encoded, _ := json.Marshal(fields)
+	want := `{"description":{"content":[{"content":[{"text":"The q parameter is concatenated.","type":"text"}],"type":"paragraph"},{"content":[{"text":"Use a prepared statement.","type":"text"}],"type":"paragraph"}],"type":"doc","version":1},"issuetype":{"name":"Bug"},"labels":["security-review"],"priority":{"name":"Highest"},"project":{"key":"SEC"},"summary":"SQL injection in search"}`
+	if string(encoded) != want {
+		t.Errorf("expected Jira fields\n%s\ngot\n%s", want, encoded)
+	}
+
+	tracker.config.Token = "wrong"
+	if _, err := tracker.createIssue(context.Background(), &Issue{Project: "SEC", Title: "t"}); err == nil {
+		t.Error("expected error when Jira rejects the request")
+	}
+}
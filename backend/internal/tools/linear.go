@@ -0,0 +1,148 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// linearPriorities maps issue priorities onto Linear's numeric scale.
+var linearPriorities = map[string]int{
+	PriorityUrgent: 1,
+	PriorityHigh:   2,
+	PriorityMedium: 3,
+	PriorityLow:    4,
+}
+
+const linearTeamQuery = `query Team($key: String!) {
+  teams(filter: {key: {eq: $key}}) {
+    nodes { id labels { nodes { id name } } }
+  }
+}`
+
+const linearCreateMutation = `mutation CreateIssue($input: IssueCreateInput!) {
+  issueCreate(input: $input) {
+    success
+    issue { id identifier url }
+  }
+}`
+
+// linearTracker creates issues with the Linear GraphQL API.
+type linearTracker struct {
+	config *IssueTrackerConfig
+	client *http.Client
+}
+
+// createIssue looks up the team and its labels by key, then creates the
+// issue. Labels, and the type, that match no team label are left out.
+func (l *linearTracker) createIssue(ctx context.Context, issue *Issue) (*CreatedIssue, error) {
+	var team struct {
+		Teams struct {
+			Nodes []struct {
+				ID     string `json:"id"`
+				Labels struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"labels"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	if err := l.query(ctx, linearTeamQuery, map[string]interface{}{"key": issue.Project}, &team); err != nil {
+		return nil, err
+	}
+	if len(team.Teams.Nodes) == 0 {
+		return nil, fmt.Errorf("%w: Linear has no team %s", ErrTracker, issue.Project)
+	}
+
+	input := map[string]interface{}{
+		"teamId": team.Teams.Nodes[0].ID,
+		"title":  issue.Title,
+	}
+	if issue.Description != "" {
+		input["description"] = issue.Description
+	}
+	if priority, ok := linearPriorities[issue.Priority]; ok {
+		input["priority"] = priority
+	}
+	wanted := issue.Labels
+	if issue.Type != "" {
+		wanted = append([]string{issue.Type}, wanted...)
+	}
+	var labelIDs []string
+	for _, name := range wanted {
+		for _, label := range team.Teams.Nodes[0].Labels.Nodes {
+			if strings.EqualFold(label.Name, name) {
+				labelIDs = append(labelIDs, label.ID)
+				break
+			}
+		}
+	}
+	if len(labelIDs) > 0 {
+		input["labelIds"] = labelIDs
+	}
+
+	var created struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID         string `json:"id"`
+				Identifier string `json:"identifier"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	if err := l.query(ctx, linearCreateMutation, map[string]interface{}{"input": input}, &created); err != nil {
+		return nil, err
+	}
+	if !created.IssueCreate.Success {
+		return nil, fmt.Errorf("%w: Linear did not create the issue", ErrTracker)
+	}
+	result := created.IssueCreate.Issue
+	return &CreatedIssue{ID: result.ID, Key: result.Identifier, URL: result.URL}, nil
+}
+
+// query runs a GraphQL request and decodes its data into v.
+func (l *linearTracker) query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	// Linear API keys are sent without a scheme
+	req.Header.Set("Authorization", l.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTracker, err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: Linear returned status %d", ErrTracker, resp.StatusCode)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%w: Linear: %s", ErrTracker, result.Errors[0].Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: Linear returned status %d", ErrTracker, resp.StatusCode)
+	}
+	if err := json.Unmarshal(result.Data, v); err != nil {
+		return fmt.Errorf("%w: failed to decode Linear response: %v", ErrTracker, err)
+	}
+	return nil
+}
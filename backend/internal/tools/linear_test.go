@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLinearServer mocks the Linear GraphQL API for team ENG with labels
+// Bug and Security, recording the issue input sent.
+func newLinearServer(t *testing.T, input *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]string{{"message": "Authentication required"}}})
+			return
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if strings.Contains(req.Query, "teams(") {
+			nodes := []interface{}{}
+			if req.Variables["key"] == "ENG" {
+				nodes = append(nodes, map[string]interface{}{
+					"id": "team-eng",
+					"labels": map[string]interface{}{"nodes": []map[string]string{
+						{"id": "label-bug", "name": "Bug"},
+						{"id": "label-security", "name": "Security"},
+					}},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"teams": map[string]interface{}{"nodes": nodes}}})
+			return
+		}
+		if input != nil {
+			*input, _ = req.Variables["input"].(map[string]interface{})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"issueCreate": map[string]interface{}{
+			"success": true,
+			"issue":   map[string]string{"id": "issue-uuid", "identifier": "ENG-7", "url": "https://linear.example/acme/issue/ENG-7"},
+		}}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLinearCreateIssue(t *testing.T) {
+	var input map[string]interface{}
+	server := newLinearServer(t, &input)
+	tracker := &linearTracker{
+		config: &IssueTrackerConfig{Kind: TrackerLinear, URL: server.URL, Token: "lin_api_test"},
+		client: http.DefaultClient,
+	}
+
+	created, err := tracker.createIssue(context.Background(), &Issue{
+		Project:     "ENG",
+		Title:       "Rotate leaked key",
+		Description: "Found during review",
+		Type:        "bug",
+		Priority:    PriorityHigh,
+		Labels:      []string{"security", "unknown"},
+	})
+	if err != nil {
+		t.Fatalf("expected issue created, got %v", err)
+	}
+	if created.ID != "issue-uuid" || created.Key != "ENG-7" || created.URL != "https://linear.example/acme/issue/ENG-7" {
+		t.Errorf("expected created issue ENG-7, got %+v", created)
+	}
+
+	if input["teamId"] != "team-eng" || input["priority"] != float64(2) {
+		t.Errorf("expected team ID and numeric priority, got %v", input)
+	}
+	labels, _ := json.Marshal(input["labelIds"])
+	if string(labels) != `["label-bug","label-security"]` {
+		t.Errorf("expected type and known labels matched to label IDs, got %s", labels)
+	}
+
+	if _, err := tracker.createIssue(context.Background(), &Issue{Project: "OPS", Title: "t"}); err == nil || !strings.Contains(err.Error(), "no team OPS") {
+		t.Errorf("expected error for an unknown team, got %v", err)
+	}
+	tracker.config.Token = "wrong"
+	if _, err := tracker.createIssue(context.Background(), &Issue{Project: "ENG", Title: "t"}); err == nil || !strings.Contains(err.Error(), "Authentication required") {
+		t.Errorf("expected Linear's error message, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package tools provides the tools agents use to act outside the collective.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// NewStubIssueTool creates an issue tool for offline mode. It checks and
+// dispatches calls exactly as NewIssueTool's does, but issues are numbered
+// locally instead of being sent to the trackers, so nothing leaves the
+// process.
+func NewStubIssueTool(cfg *Config, dispatcher *Dispatcher) *IssueTool {
+	return newIssueTool(cfg, dispatcher, func(*IssueTrackerConfig) issueTracker {
+		return &stubTracker{next: make(map[string]int)}
+	})
+}
+
+// stubTracker numbers issues per project from 1, so a run of calls always
+// yields the same keys.
+type stubTracker struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+// createIssue returns the project's next key.
+func (s *stubTracker) createIssue(ctx context.Context, issue *Issue) (*CreatedIssue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[issue.Project]++
+	key := fmt.Sprintf("%s-%d", issue.Project, s.next[issue.Project])
+	return &CreatedIssue{ID: "stub-" + key, Key: key, URL: "offline://issues/" + key}, nil
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStubIssueToolNumbersIssues(t *testing.T) {
+	var trail bytes.Buffer
+	cfg := &Config{Tenants: []Tenant{
+		// The URL is never called
+		{ID: "acme", IssueTracker: &IssueTrackerConfig{Kind: TrackerJira, URL: "http://127.0.0.1:1", Projects: []string{"SEC", "OPS"}},
+			Grants: []Grant{{Agents: []string{Wildcard}, Tools: []string{ToolIssueTracker}}}},
+	}}
+	tool := NewStubIssueTool(cfg, NewDispatcher(NewMatrix(cfg), NewAuditLog(&trail)))
+
+	var keys []string
+	for _, project := range []string{"sec", "SEC", "OPS"} {
+		created, err := tool.CreateIssue(context.Background(), "acme", "FORTRESS", "user-1", Issue{Project: project, Title: "Weak TLS config"})
+		if err != nil {
+			t.Fatalf("expected issue created, got %v", err)
+		}
+		keys = append(keys, created.Key)
+	}
+	if got := strings.Join(keys, ","); got != "SEC-1,SEC-2,OPS-1" {
+		t.Errorf("expected keys numbered per project, got %s", got)
+	}
+
+	if _, err := tool.CreateIssue(context.Background(), "acme", "FORTRESS", "user-1", Issue{Project: "HR", Title: "t"}); !errors.Is(err, ErrProjectNotAllowed) {
+		t.Errorf("expected ErrProjectNotAllowed, got %v", err)
+	}
+	if lines := strings.Count(trail.String(), "\n"); lines != 4 {
+		t.Errorf("expected 4 audit entries, got %d", lines)
+	}
+	if !strings.Contains(trail.String(), `"reference":"OPS-1"`) {
+		t.Errorf("expected created keys in the audit trail, got %s", trail.String())
+	}
+}
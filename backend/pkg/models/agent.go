@@ -17,6 +17,35 @@ type Agent struct {
 	Collaborators []string `json:"collaborators"`
 	Category      string   `json:"category"`
 	MarkdownPath  string   `json:"-"` // Internal: path to .agent.md file
+
+	// Lifecycle is set when the agent is deprecated or removed
+	Lifecycle *AgentLifecycle `json:"lifecycle,omitempty"`
+	// Aliases are former codenames that still route to the agent
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// LifecycleState is the lifecycle state of an agent or codename alias.
+type LifecycleState string
+
+const (
+	// LifecycleActive agents are served normally
+	LifecycleActive LifecycleState = "active"
+	// LifecycleDeprecated agents are served with deprecation headers
+	LifecycleDeprecated LifecycleState = "deprecated"
+	// LifecycleRemoved agents are no longer served
+	LifecycleRemoved LifecycleState = "removed"
+)
+
+// AgentLifecycle describes where an agent or alias is in its lifecycle.
+// Dates are in YYYY-MM-DD form.
+type AgentLifecycle struct {
+	State LifecycleState `json:"state" yaml:"state"`
+	// DeprecatedOn is when the agent was deprecated
+	DeprecatedOn string `json:"deprecated_on,omitempty" yaml:"deprecated_on"`
+	// RemovalDate is when the agent stops being served
+	RemovalDate string `json:"removal_date,omitempty" yaml:"removal_date"`
+	// ReplacedBy is the codename of the agent to use instead
+	ReplacedBy string `json:"replaced_by,omitempty" yaml:"replaced_by"`
 }
 
 // CopilotRequest represents a request from GitHub Copilot.
@@ -24,23 +53,41 @@ type CopilotRequest struct {
 	Messages []Message `json:"messages"`
 	Model    string    `json:"model"`
 	Stream   bool      `json:"stream"`
+	// Draft asks for a fast draft streamed ahead of the answer; it applies
+	// only to streamed requests
+	Draft bool `json:"draft,omitempty"`
 }
 
 // Message represents a single message in a conversation.
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// CopilotReferences are the repository, files and selections Copilot
+	// sends as context with a user message
+	CopilotReferences []Reference `json:"copilot_references,omitempty"`
+}
+
+// AgentAnswer is one agent's answer to a request several agents handled.
+type AgentAnswer struct {
+	Agent   string `json:"agent"`
+	Content string `json:"content"`
 }
 
 // CopilotResponse represents a response to GitHub Copilot.
 type CopilotResponse struct {
 	Choices []Choice `json:"choices"`
+	// CopilotReferences are the repository lines the answer cites, for
+	// Copilot to link to
+	CopilotReferences []Reference `json:"copilot_references,omitempty"`
 }
 
 // Choice represents a single response choice.
 type Choice struct {
 	Message      Message `json:"message"`
 	FinishReason string  `json:"finish_reason"`
+	// Structured is the answer the message renders, when the agent gave
+	// one in structured form
+	Structured *AgentResponse `json:"structured,omitempty"`
 }
 
 // AgentHandler defines the interface for agent handlers.
@@ -50,3 +97,19 @@ type AgentHandler interface {
 	// GetInfo returns the agent's metadata.
 	GetInfo() Agent
 }
+
+// idempotencyKey is the context key of a request's idempotency key.
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context carrying key. A request retried
+// with the same key must not repeat side effects; agents that have side
+// effects use it to deduplicate.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by ctx.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
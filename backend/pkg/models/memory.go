@@ -20,6 +20,10 @@ type MemoryConfig struct {
 	// BreakthroughThreshold for promoting to collective
 	BreakthroughThreshold float64 `json:"breakthrough_threshold" yaml:"breakthrough_threshold"`
 
+	// DedupThreshold is the content similarity at which a new experience is
+	// merged into a stored one; zero disables deduplication
+	DedupThreshold float64 `json:"dedup_threshold" yaml:"dedup_threshold"`
+
 	// EvolutionIntervalSeconds between evolution cycles
 	EvolutionIntervalSeconds int `json:"evolution_interval_seconds" yaml:"evolution_interval_seconds"`
 
@@ -29,6 +33,9 @@ type MemoryConfig struct {
 	// LSH configuration
 	LSHNumTables    int `json:"lsh_num_tables" yaml:"lsh_num_tables"`
 	LSHNumHashFuncs int `json:"lsh_num_hash_funcs" yaml:"lsh_num_hash_funcs"`
+	// LSHMaxAvgBucketSize is the average bucket size above which the LSH
+	// index is rehashed into more tables and hash functions; 0 disables it
+	LSHMaxAvgBucketSize float64 `json:"lsh_max_avg_bucket_size" yaml:"lsh_max_avg_bucket_size"`
 
 	// HNSW configuration
 	HNSWMaxConnections int `json:"hnsw_max_connections" yaml:"hnsw_max_connections"`
@@ -44,10 +51,12 @@ func DefaultMemoryConfig() *MemoryConfig {
 		MaxExperiencesPerAgent:   1000,
 		MinFitnessThreshold:      0.3,
 		BreakthroughThreshold:    0.9,
+		DedupThreshold:           0.85,
 		EvolutionIntervalSeconds: 3600, // 1 hour
 		PersistencePath:          "./data/memory",
 		LSHNumTables:             10,
 		LSHNumHashFuncs:          12,
+		LSHMaxAvgBucketSize:      64,
 		HNSWMaxConnections:       16,
 		HNSWEfConstruction:       200,
 		HNSWEfSearch:             100,
@@ -0,0 +1,61 @@
+// Package models contains data models for the Elite Agent Collective backend.
+// This file defines the references Copilot attaches to messages and renders
+// with answers.
+
+package models
+
+// Reference types.
+const (
+	// ReferenceRepository is the repository the user is working in
+	ReferenceRepository = "github.repository"
+	// ReferenceFile is a file open in the user's editor
+	ReferenceFile = "client.file"
+	// ReferenceSelection is code selected in the user's editor
+	ReferenceSelection = "client.selection"
+	// ReferenceCitation is repository lines an answer cites
+	ReferenceCitation = "code.citation"
+)
+
+// Reference is context Copilot sends with a message, such as the
+// repository or a selection, or a source sent with an answer for Copilot
+// to link to. ID is the path of files, selections and citations.
+type Reference struct {
+	Type       string             `json:"type"`
+	ID         string             `json:"id"`
+	Data       ReferenceData      `json:"data"`
+	IsImplicit bool               `json:"is_implicit"`
+	Metadata   *ReferenceMetadata `json:"metadata,omitempty"`
+}
+
+// ReferenceData holds the fields of every reference type; each type sets
+// some of them.
+type ReferenceData struct {
+	// Content is the text of a file or selection
+	Content  string `json:"content,omitempty"`
+	Language string `json:"language,omitempty"`
+	// Start and End bound a selection or citation. Selection lines count
+	// from 0, as editors send them; citation lines count from 1
+	Start *ReferencePosition `json:"start,omitempty"`
+	End   *ReferencePosition `json:"end,omitempty"`
+	// Name and OwnerLogin name a repository, or a cited file's repository
+	Name       string `json:"name,omitempty"`
+	OwnerLogin string `json:"ownerLogin,omitempty"`
+	// CommitOID is the commit a repository is checked out at, or a
+	// citation refers to
+	CommitOID string `json:"commitOID,omitempty"`
+	// Ref is the branch or tag a repository is checked out at
+	Ref string `json:"ref,omitempty"`
+}
+
+// ReferencePosition is a position in a file.
+type ReferencePosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// ReferenceMetadata is how Copilot shows a reference.
+type ReferenceMetadata struct {
+	DisplayName string `json:"display_name"`
+	DisplayIcon string `json:"display_icon,omitempty"`
+	DisplayURL  string `json:"display_url,omitempty"`
+}
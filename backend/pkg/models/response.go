@@ -0,0 +1,53 @@
+// Package models contains data models for the Elite Agent Collective backend.
+// This file defines the structured form of an agent's answer.
+
+package models
+
+// AgentResponse is an agent's answer in structured form. Agents build one
+// instead of formatting text; it is rendered as Markdown for Copilot and
+// sent alongside the text for clients that render it themselves.
+type AgentResponse struct {
+	// Summary answers the request in a sentence or a short paragraph
+	Summary string `json:"summary"`
+	// Reasoning explains how the agent reached the answer
+	Reasoning string `json:"reasoning,omitempty"`
+	// Sections are further titled parts of the answer, in order
+	Sections []ResponseSection `json:"sections,omitempty"`
+	// Artifacts are code and other files the answer produced
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Citations are the sources the answer relies on
+	Citations []Citation `json:"citations,omitempty"`
+	// FollowUps are requests the user might make next
+	FollowUps []string `json:"follow_ups,omitempty"`
+}
+
+// ResponseSection is a titled part of an answer. The body is Markdown.
+type ResponseSection struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Artifact is a piece of code or a file in an answer.
+type Artifact struct {
+	// Title names the artifact, such as a file name; it may be empty
+	Title string `json:"title,omitempty"`
+	// Language tags the content for syntax highlighting, such as "go"
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content"`
+}
+
+// Citation is a source an answer relies on. URL is empty for sources
+// without one, such as the knowledge graph. Citations of repository code
+// also locate the lines cited.
+type Citation struct {
+	Title string `json:"title"`
+	URL   string `json:"url,omitempty"`
+	// Path is the cited file, relative to the repository root
+	Path string `json:"path,omitempty"`
+	// StartLine and EndLine are the cited lines, counting from 1
+	StartLine int `json:"start_line,omitempty"`
+	EndLine   int `json:"end_line,omitempty"`
+	// Commit is the SHA the lines are cited at; empty if Copilot didn't
+	// send one
+	Commit string `json:"commit,omitempty"`
+}
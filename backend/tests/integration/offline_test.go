@@ -0,0 +1,155 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/agents"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/embeddings"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/pkg/models"
+)
+
+// offlineWorkflowYAML hands a task from one agent to the next.
+const offlineWorkflowYAML = `
+name: "offline-review"
+inputs:
+  - name: "proposal"
+    required: true
+steps:
+  - id: "design"
+    agent: "ARCHITECT"
+    prompt: "Design {{inputs.proposal}}"
+  - id: "security"
+    agent: "CIPHER"
+    prompt: "Secure {{steps.design.output}}"
+`
+
+// newOfflineServer wires routing, memory, orchestration and embeddings as
+// the server does in offline mode, with no network-backed provider.
+func newOfflineServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	registry := agents.DefaultRegistry()
+	agentHandler := agents.NewHandler(registry)
+	workflows := agents.NewWorkflowEngine(registry)
+	def, err := agents.ParseWorkflow([]byte(offlineWorkflowYAML))
+	if err != nil {
+		t.Fatalf("failed to parse workflow: %v", err)
+	}
+	if err := workflows.Register(def); err != nil {
+		t.Fatalf("failed to register workflow: %v", err)
+	}
+	agentHandler.SetWorkflows(workflows)
+
+	network := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	if err := memory.SeedAgentOntology(network, registry.List()); err != nil {
+		t.Fatalf("failed to seed ontology: %v", err)
+	}
+	cache, err := embeddings.NewCache(embeddings.NewStubEmbedder(0), embeddings.DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("failed to create embedding cache: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/copilot", agentHandler.CopilotWebhook)
+	r.Post("/workflows/{name}/run", agentHandler.RunWorkflow)
+	r.Get("/workflows/runs/{id}", agentHandler.GetWorkflowRun)
+	r.Post("/memory/ask", memory.NewHandler(network).Ask)
+	r.Post("/embeddings", embeddings.NewHandler(cache).ServeEmbed)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postOffline posts a JSON body and decodes the JSON response into v.
+func postOffline(t *testing.T, url string, body, v interface{}) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to post to %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 200 or 202 from %s, got %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response from %s: %v", url, err)
+	}
+}
+
+// offlineTranscript runs the whole pipeline once and returns every
+// response that should be the same on every run.
+func offlineTranscript(t *testing.T, server *httptest.Server) []interface{} {
+	t.Helper()
+
+	// Routing: the mention picks the agent
+	var routed models.CopilotResponse
+	postOffline(t, server.URL+"/copilot", models.CopilotRequest{
+		Messages: []models.Message{{Role: "user", Content: "@CIPHER how should we rotate API keys?"}},
+	}, &routed)
+	if len(routed.Choices) == 0 {
+		t.Fatal("expected a routed response")
+	}
+
+	// Memory: questions are answered from the seeded knowledge graph
+	var answer memory.AskResponse
+	postOffline(t, server.URL+"/memory/ask", memory.AskRequest{Question: "What is the tier of APEX?"}, &answer)
+	if answer.Answer == "" {
+		t.Fatal("expected an answer from the knowledge graph")
+	}
+
+	// Orchestration: wait for the run if it went to the background
+	var run agents.WorkflowRun
+	postOffline(t, server.URL+"/workflows/offline-review/run", map[string]interface{}{
+		"inputs": map[string]string{"proposal": "a billing API"},
+	}, &run)
+	for deadline := time.Now().Add(5 * time.Second); run.Status == agents.StepRunning; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected workflow run to finish, still %s", run.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+		resp, err := http.Get(server.URL + "/workflows/runs/" + run.ID)
+		if err != nil {
+			t.Fatalf("failed to get workflow run: %v", err)
+		}
+		json.NewDecoder(resp.Body).Decode(&run)
+		resp.Body.Close()
+	}
+	if run.Status != agents.StepSucceeded {
+		t.Fatalf("expected workflow run to succeed, got %+v", run)
+	}
+
+	// Embeddings: the stub stands in for the model
+	var embedded embeddings.EmbedResponse
+	postOffline(t, server.URL+"/embeddings", embeddings.EmbedRequest{Texts: []string{"rotate API keys"}}, &embedded)
+	if embedded.Dimensions != embeddings.DefaultStubDimensions {
+		t.Errorf("expected %d stub dimensions, got %d", embeddings.DefaultStubDimensions, embedded.Dimensions)
+	}
+
+	return []interface{}{routed.Choices[0].Message.Content, answer.Answer, run.Output, embedded.Embeddings}
+}
+
+// TestOfflinePipelineIsDeterministic runs the pipeline on two offline
+// servers and expects identical results.
+func TestOfflinePipelineIsDeterministic(t *testing.T) {
+	first := offlineTranscript(t, newOfflineServer(t))
+	second := offlineTranscript(t, newOfflineServer(t))
+
+	names := []string{"routed response", "memory answer", "workflow output", "embeddings"}
+	for i := range first {
+		if !reflect.DeepEqual(first[i], second[i]) {
+			t.Errorf("expected the same %s on both servers, got %v and %v", names[i], first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Package soak drives the memory subsystems for hours and fails when heap
+// use or structure sizes keep growing. Decay and eviction leaks only show
+// as a slow upward trend, which short tests can't tell from a structure
+// filling up to its bounds.
+package soak
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HeapMetric names the live heap trend, in bytes.
+const HeapMetric = "heap_bytes"
+
+var (
+	// ErrGrowth is returned when a trend exceeds its slope limit
+	ErrGrowth = errors.New("unbounded growth")
+	// ErrTooFewSamples is returned when too few samples follow the warmup
+	// to fit a trend
+	ErrTooFewSamples = errors.New("too few samples after warmup")
+)
+
+// Subsystem is a memory structure under soak.
+type Subsystem struct {
+	Name string
+	// Step does one unit of work; step counts the subsystem's steps from 0
+	Step func(rng *rand.Rand, step int) error
+	// Counts reports the sizes of the subsystem's structures. It is called
+	// from the sampler while Step runs.
+	Counts func() map[string]int
+}
+
+// Config configures a soak run.
+type Config struct {
+	Duration       time.Duration
+	SampleInterval time.Duration
+	// Warmup is left out of the trends, so structures can fill up to their
+	// bounds first
+	Warmup time.Duration
+	// MaxHeapSlope bounds live heap growth, in bytes per hour
+	MaxHeapSlope float64
+	// MaxCountSlope bounds every structure count's growth, in entries per
+	// hour
+	MaxCountSlope float64
+	// CountSlopes overrides MaxCountSlope for counts named
+	// "subsystem/count"
+	CountSlopes map[string]float64
+	// Seed seeds each subsystem's random source, so runs are repeatable
+	Seed int64
+	// Logf receives a line per sample; nil is silent
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultConfig returns a two-hour soak that tolerates 8 MiB of heap and
+// 100 entries per structure of growth an hour.
+func DefaultConfig() Config {
+	return Config{
+		Duration:       2 * time.Hour,
+		SampleInterval: 30 * time.Second,
+		Warmup:         10 * time.Minute,
+		MaxHeapSlope:   8 << 20,
+		MaxCountSlope:  100,
+		Seed:           1,
+	}
+}
+
+// Sample is the state of the process at one point in the run.
+type Sample struct {
+	Elapsed time.Duration
+	// HeapAlloc is the live heap after a collection
+	HeapAlloc   uint64
+	HeapObjects uint64
+	// TotalAlloc is the cumulative allocation, whose slope is the
+	// allocation rate
+	TotalAlloc uint64
+	// Counts holds each structure's size by "subsystem/count"
+	Counts map[string]int
+	// Steps is the steps done so far by all subsystems
+	Steps int64
+}
+
+// Trend is a metric's least-squares growth after the warmup.
+type Trend struct {
+	Metric string
+	// Slope is the growth per hour
+	Slope float64
+	Limit float64
+	// First and Last are the metric's values at the ends of the fit
+	First, Last float64
+}
+
+// Exceeded reports whether the trend grows faster than its limit.
+func (t Trend) Exceeded() bool {
+	return t.Slope > t.Limit
+}
+
+// Result is the outcome of a soak run.
+type Result struct {
+	Samples []Sample
+	Trends  []Trend
+}
+
+// Violations returns the trends that exceeded their limits.
+func (r *Result) Violations() []Trend {
+	var violations []Trend
+	for _, trend := range r.Trends {
+		if trend.Exceeded() {
+			violations = append(violations, trend)
+		}
+	}
+	return violations
+}
+
+// Err returns an ErrGrowth error listing the violations, or nil.
+func (r *Result) Err() error {
+	violations := r.Violations()
+	if len(violations) == 0 {
+		return nil
+	}
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = fmt.Sprintf("%s grew %.1f/h (limit %.1f/h, %.0f -> %.0f)", v.Metric, v.Slope, v.Limit, v.First, v.Last)
+	}
+	return fmt.Errorf("%w: %s", ErrGrowth, strings.Join(lines, "; "))
+}
+
+// Run drives the subsystems concurrently for cfg.Duration, or until ctx is
+// done, sampling the heap and structure counts every cfg.SampleInterval,
+// then fits a trend to each metric. A failing step ends the run with its
+// error.
+func Run(ctx context.Context, cfg Config, subsystems ...Subsystem) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var steps atomic.Int64
+	var wg sync.WaitGroup
+	var stepErr error
+	var errOnce sync.Once
+	for i, sub := range subsystems {
+		wg.Add(1)
+		go func(seed int64, sub Subsystem) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for step := 0; ctx.Err() == nil; step++ {
+				if err := sub.Step(rng, step); err != nil {
+					errOnce.Do(func() { stepErr = fmt.Errorf("%s step %d: %w", sub.Name, step, err) })
+					cancel()
+					return
+				}
+				steps.Add(1)
+			}
+		}(cfg.Seed+int64(i), sub)
+	}
+
+	started := time.Now()
+	result := &Result{}
+	ticker := time.NewTicker(cfg.SampleInterval)
+	defer ticker.Stop()
+	for sampling := true; sampling; {
+		select {
+		case <-ctx.Done():
+			sampling = false
+		case <-ticker.C:
+			if ctx.Err() != nil {
+				// The subsystems are stopping; their heap no longer counts
+				sampling = false
+				break
+			}
+			sample := takeSample(time.Since(started), steps.Load(), subsystems)
+			result.Samples = append(result.Samples, sample)
+			if cfg.Logf != nil {
+				cfg.Logf("%s", sample)
+			}
+		}
+	}
+	wg.Wait()
+	if stepErr != nil {
+		return result, stepErr
+	}
+
+	trends, err := fitTrends(result.Samples, cfg)
+	result.Trends = trends
+	return result, err
+}
+
+// takeSample collects garbage, so the heap is what is still reachable,
+// then reads the heap and the structure counts.
+func takeSample(elapsed time.Duration, steps int64, subsystems []Subsystem) Sample {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	sample := Sample{
+		Elapsed:     elapsed,
+		HeapAlloc:   stats.HeapAlloc,
+		HeapObjects: stats.HeapObjects,
+		TotalAlloc:  stats.TotalAlloc,
+		Counts:      make(map[string]int),
+		Steps:       steps,
+	}
+	for _, sub := range subsystems {
+		for name, n := range sub.Counts() {
+			sample.Counts[sub.Name+"/"+name] = n
+		}
+	}
+	return sample
+}
+
+// String formats a sample as a progress line.
+func (s Sample) String() string {
+	names := sortedCounts(s.Counts)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, s.Counts[name])
+	}
+	return fmt.Sprintf("%s heap=%.1fMiB objects=%d steps=%d %s",
+		s.Elapsed.Round(time.Second), float64(s.HeapAlloc)/(1<<20), s.HeapObjects, s.Steps, strings.Join(parts, " "))
+}
+
+// fitTrends fits the heap and every count over the samples after the
+// warmup.
+func fitTrends(samples []Sample, cfg Config) ([]Trend, error) {
+	var fit []Sample
+	for _, sample := range samples {
+		if sample.Elapsed >= cfg.Warmup {
+			fit = append(fit, sample)
+		}
+	}
+	if len(fit) < 3 {
+		return nil, fmt.Errorf("%w: %d of %d", ErrTooFewSamples, len(fit), len(samples))
+	}
+
+	hours := make([]float64, len(fit))
+	for i, sample := range fit {
+		hours[i] = sample.Elapsed.Hours()
+	}
+	values := make([]float64, len(fit))
+	for i, sample := range fit {
+		values[i] = float64(sample.HeapAlloc)
+	}
+	trends := []Trend{newTrend(HeapMetric, hours, values, cfg.MaxHeapSlope)}
+	for _, name := range sortedCounts(fit[len(fit)-1].Counts) {
+		for i, sample := range fit {
+			values[i] = float64(sample.Counts[name])
+		}
+		limit, ok := cfg.CountSlopes[name]
+		if !ok {
+			limit = cfg.MaxCountSlope
+		}
+		trends = append(trends, newTrend(name, hours, values, limit))
+	}
+	return trends, nil
+}
+
+// newTrend fits a least-squares line to values over x.
+func newTrend(metric string, x, values []float64, limit float64) Trend {
+	return Trend{
+		Metric: metric,
+		Slope:  slope(x, values),
+		Limit:  limit,
+		First:  values[0],
+		Last:   values[len(values)-1],
+	}
+}
+
+// slope returns the least-squares slope of y over x; 0 when x doesn't
+// vary.
+func slope(x, y []float64) float64 {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var covariance, variance float64
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		variance += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+// sortedCounts returns the count names in order.
+func sortedCounts(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteCSV writes the samples for plotting: elapsed seconds, heap, objects,
+// cumulative allocation, steps, then one column per structure count.
+func (r *Result) WriteCSV(w io.Writer) error {
+	var names []string
+	if len(r.Samples) > 0 {
+		names = sortedCounts(r.Samples[len(r.Samples)-1].Counts)
+	}
+	out := csv.NewWriter(w)
+	header := append([]string{"elapsed_s", HeapMetric, "heap_objects", "total_alloc_bytes", "steps"}, names...)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, sample := range r.Samples {
+		row := []string{
+			strconv.FormatFloat(sample.Elapsed.Seconds(), 'f', 1, 64),
+			strconv.FormatUint(sample.HeapAlloc, 10),
+			strconv.FormatUint(sample.HeapObjects, 10),
+			strconv.FormatUint(sample.TotalAlloc, 10),
+			strconv.FormatInt(sample.Steps, 10),
+		}
+		for _, name := range names {
+			row = append(row, strconv.Itoa(sample.Counts[name]))
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,143 @@
+package soak
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// quickConfig samples fast enough to fit trends in a fraction of a second,
+// with the heap limit out of the way.
+func quickConfig() Config {
+	return Config{
+		Duration:       300 * time.Millisecond,
+		SampleInterval: 20 * time.Millisecond,
+		Warmup:         50 * time.Millisecond,
+		MaxHeapSlope:   math.Inf(1),
+		MaxCountSlope:  1000,
+		Seed:           1,
+	}
+}
+
+// sliceSubsystem appends to a slice every step, trimming it to bound
+// entries when bound is positive.
+func sliceSubsystem(name string, bound int) Subsystem {
+	var mu sync.Mutex
+	var entries []int
+	return Subsystem{
+		Name: name,
+		Step: func(rng *rand.Rand, step int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			entries = append(entries, rng.Int())
+			if bound > 0 && len(entries) > bound {
+				entries = entries[len(entries)-bound:]
+			}
+			time.Sleep(100 * time.Microsecond)
+			return nil
+		},
+		Counts: func() map[string]int {
+			mu.Lock()
+			defer mu.Unlock()
+			return map[string]int{"entries": len(entries)}
+		},
+	}
+}
+
+func TestSlope(t *testing.T) {
+	if got := slope([]float64{0, 1, 2, 3}, []float64{1, 3, 5, 7}); got != 2 {
+		t.Errorf("expected slope 2, got %v", got)
+	}
+	if got := slope([]float64{0, 1, 2, 3}, []float64{5, 5, 5, 5}); got != 0 {
+		t.Errorf("expected flat slope, got %v", got)
+	}
+	if got := slope([]float64{1, 1, 1}, []float64{1, 2, 3}); got != 0 {
+		t.Errorf("expected 0 when x doesn't vary, got %v", got)
+	}
+}
+
+func TestRunDetectsGrowth(t *testing.T) {
+	result, err := Run(context.Background(), quickConfig(), sliceSubsystem("leaky", 0), sliceSubsystem("bounded", 10))
+	if err != nil {
+		t.Fatalf("expected trends, got %v", err)
+	}
+	if len(result.Samples) < 5 {
+		t.Errorf("expected a sample every interval, got %d", len(result.Samples))
+	}
+
+	violations := result.Violations()
+	if len(violations) != 1 || violations[0].Metric != "leaky/entries" {
+		t.Fatalf("expected only leaky/entries to exceed its limit, got %+v", violations)
+	}
+	if err := result.Err(); !errors.Is(err, ErrGrowth) || !strings.Contains(err.Error(), "leaky/entries") {
+		t.Errorf("expected ErrGrowth naming the leak, got %v", err)
+	}
+	for _, trend := range result.Trends {
+		if trend.Metric == "bounded/entries" && (trend.Last != 10 || trend.Exceeded()) {
+			t.Errorf("expected bounded entries to stay at 10, got %+v", trend)
+		}
+	}
+}
+
+func TestRunCountSlopeOverride(t *testing.T) {
+	cfg := quickConfig()
+	cfg.CountSlopes = map[string]float64{"leaky/entries": math.Inf(1)}
+	result, err := Run(context.Background(), cfg, sliceSubsystem("leaky", 0))
+	if err != nil {
+		t.Fatalf("expected trends, got %v", err)
+	}
+	if err := result.Err(); err != nil {
+		t.Errorf("expected the override to allow the growth, got %v", err)
+	}
+}
+
+func TestRunStepError(t *testing.T) {
+	failing := Subsystem{
+		Name: "failing",
+		Step: func(rng *rand.Rand, step int) error {
+			if step == 3 {
+				return errors.New("corrupt index")
+			}
+			return nil
+		},
+		Counts: func() map[string]int { return nil },
+	}
+	cfg := quickConfig()
+	cfg.Duration = 10 * time.Second
+	start := time.Now()
+	_, err := Run(context.Background(), cfg, failing, sliceSubsystem("bounded", 10))
+	if err == nil || !strings.Contains(err.Error(), "failing step 3: corrupt index") {
+		t.Errorf("expected the step error, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected a failing step to end the run early")
+	}
+}
+
+func TestRunTooFewSamples(t *testing.T) {
+	cfg := quickConfig()
+	cfg.Warmup = time.Hour
+	if _, err := Run(context.Background(), cfg, sliceSubsystem("bounded", 10)); !errors.Is(err, ErrTooFewSamples) {
+		t.Errorf("expected ErrTooFewSamples, got %v", err)
+	}
+}
+
+func TestResultWriteCSV(t *testing.T) {
+	result := &Result{Samples: []Sample{
+		{Elapsed: time.Second, HeapAlloc: 100, HeapObjects: 3, TotalAlloc: 500, Steps: 7, Counts: map[string]int{"b/n": 2, "a/n": 1}},
+	}}
+	var buf bytes.Buffer
+	if err := result.WriteCSV(&buf); err != nil {
+		t.Fatalf("expected CSV, got %v", err)
+	}
+	want := "elapsed_s,heap_bytes,heap_objects,total_alloc_bytes,steps,a/n,b/n\n1.0,100,3,500,7,1,2\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
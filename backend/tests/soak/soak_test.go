@@ -0,0 +1,274 @@
+//go:build soak
+// +build soak
+
+package soak
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/embeddings"
+	"github.com/iamthegreatdestroyer/elite-agent-collective/backend/internal/memory"
+)
+
+// Each subsystem keeps a sliding window of live entries, so a structure
+// that keeps growing past its window leaks what it was told to drop.
+const (
+	networkWindow    = 5000
+	retrieverWindow  = 2000
+	retrieverDims    = 64
+	embeddingEntries = 1000
+)
+
+// soakAgents is a fixed set of codenames, so per-agent state is bounded.
+var soakAgents = []string{"APEX", "CIPHER", "ARCHITECT", "FORTRESS", "TENSOR", "FLUX", "VELOCITY", "ECLIPSE", "NEXUS", "ORACLE"}
+
+var soakTaskTypes = []string{"code_review", "design", "debugging", "security_audit", "optimization"}
+
+// TestMemorySoak drives the memory subsystems and fails on growth beyond
+// the configured slopes. It runs for two hours by default:
+//
+//	SOAK_DURATION=2h SOAK_REPORT=soak.csv go test -tags soak -timeout 0 -v ./tests/soak/
+func TestMemorySoak(t *testing.T) {
+	cfg := configFromEnv(t)
+	t.Logf("soaking for %s, sampling every %s after %s of warmup", cfg.Duration, cfg.SampleInterval, cfg.Warmup)
+
+	result, err := Run(context.Background(), cfg,
+		workingMemorySubsystem(),
+		semanticNetworkSubsystem(),
+		retrieverSubsystem(),
+		feedbackSubsystem(),
+		embeddingCacheSubsystem(t),
+	)
+	if path := os.Getenv("SOAK_REPORT"); path != "" && result != nil {
+		writeReport(t, path, result)
+	}
+	if err != nil {
+		t.Fatalf("soak failed: %v", err)
+	}
+	for _, trend := range result.Trends {
+		t.Logf("%-40s %12.1f/h (limit %.1f/h) %.0f -> %.0f", trend.Metric, trend.Slope, trend.Limit, trend.First, trend.Last)
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// configFromEnv reads the soak's duration and limits from SOAK_*
+// variables over DefaultConfig.
+func configFromEnv(t *testing.T) Config {
+	cfg := DefaultConfig()
+	cfg.Logf = t.Logf
+	durations := map[string]*time.Duration{
+		"SOAK_DURATION":        &cfg.Duration,
+		"SOAK_SAMPLE_INTERVAL": &cfg.SampleInterval,
+		"SOAK_WARMUP":          &cfg.Warmup,
+	}
+	for key, field := range durations {
+		if value := os.Getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				t.Fatalf("invalid %s: %v", key, err)
+			}
+			*field = d
+		}
+	}
+	if value := os.Getenv("SOAK_MAX_HEAP_SLOPE_MB"); value != "" {
+		mb, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("invalid SOAK_MAX_HEAP_SLOPE_MB: %v", err)
+		}
+		cfg.MaxHeapSlope = mb * (1 << 20)
+	}
+	if value := os.Getenv("SOAK_MAX_COUNT_SLOPE"); value != "" {
+		slope, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("invalid SOAK_MAX_COUNT_SLOPE: %v", err)
+		}
+		cfg.MaxCountSlope = slope
+	}
+	return cfg
+}
+
+// writeReport saves the samples as CSV.
+func writeReport(t *testing.T, path string, result *Result) {
+	file, err := os.Create(path)
+	if err != nil {
+		t.Errorf("failed to create report: %v", err)
+		return
+	}
+	defer file.Close()
+	if err := result.WriteCSV(file); err != nil {
+		t.Errorf("failed to write report: %v", err)
+	}
+}
+
+// workingMemorySubsystem adds, rehearses, associates and chunks items in
+// a capacity-bound working memory, decaying it as it goes.
+func workingMemorySubsystem() Subsystem {
+	wm := memory.NewCognitiveWorkingMemory(memory.DefaultWorkingMemoryConfig())
+	return Subsystem{
+		Name: "working_memory",
+		Step: func(rng *rand.Rand, step int) error {
+			id := fmt.Sprintf("item-%d", step)
+			wm.Add(&memory.WorkingMemoryItem{ID: id, Content: step, ContentType: memory.ContentTypeContext})
+			if step > 0 {
+				wm.AddAssociation(id, fmt.Sprintf("item-%d", step-1))
+			}
+			if rng.Intn(4) == 0 {
+				wm.Get(fmt.Sprintf("item-%d", step-rng.Intn(5)))
+			}
+			if step%50 == 0 && step > 0 {
+				chunk := fmt.Sprintf("chunk-%d", step)
+				if _, err := wm.CreateChunk(chunk, chunk, []string{id, fmt.Sprintf("item-%d", step-1)}, "pair"); err == nil && rng.Intn(2) == 0 {
+					wm.DisbandChunk(chunk)
+				}
+			}
+			if step%10 == 0 {
+				wm.TriggerDecay()
+			}
+			return nil
+		},
+		Counts: func() map[string]int {
+			snapshot := wm.Snapshot()
+			return map[string]int{"items": snapshot.ItemCount, "chunks": snapshot.ChunkCount}
+		},
+	}
+}
+
+// semanticNetworkSubsystem keeps a chain of networkWindow nodes, adding
+// one and removing the oldest each step, with spreading activation and
+// decay along the way.
+func semanticNetworkSubsystem() Subsystem {
+	sn := memory.NewSemanticNetwork(memory.DefaultSemanticNetworkConfig())
+	return Subsystem{
+		Name: "semantic_network",
+		Step: func(rng *rand.Rand, step int) error {
+			id := fmt.Sprintf("node-%d", step)
+			node := memory.NewSemanticNode(id, fmt.Sprintf("Concept %d", step), memory.ConceptNode)
+			node.SetProperty("weight", rng.Float64())
+			if err := sn.AddNode(node); err != nil {
+				return err
+			}
+			if step > 0 {
+				if err := sn.AddRelation(memory.NewSemanticRelation(id, fmt.Sprintf("node-%d", step-1), memory.RelatedTo)); err != nil {
+					return err
+				}
+			}
+			if step >= networkWindow {
+				if err := sn.RemoveNode(fmt.Sprintf("node-%d", step-networkWindow)); err != nil {
+					return err
+				}
+			}
+			if step%100 == 0 {
+				sn.SpreadActivation([]string{id}, 1.0)
+				sn.DecayActivation(time.Second)
+			}
+			return nil
+		},
+		Counts: func() map[string]int {
+			return map[string]int{"nodes": sn.NodeCount(), "relations": sn.RelationCount()}
+		},
+	}
+}
+
+// retrieverSubsystem keeps retrieverWindow experiences with embeddings in
+// the sub-linear retriever, adding one, removing the oldest and
+// retrieving each step.
+func retrieverSubsystem() Subsystem {
+	retriever := memory.NewSubLinearRetriever(retrieverDims)
+	vector := func(rng *rand.Rand) []float32 {
+		v := make([]float32, retrieverDims)
+		for i := range v {
+			v[i] = rng.Float32()*2 - 1
+		}
+		return v
+	}
+	return Subsystem{
+		Name: "retriever",
+		Step: func(rng *rand.Rand, step int) error {
+			agent := soakAgents[rng.Intn(len(soakAgents))]
+			input := fmt.Sprintf("task %d for %s", step, agent)
+			exp := memory.NewExperienceTuple(agent, 1+rng.Intn(8), input, "done", "direct")
+			exp.ID = fmt.Sprintf("exp-%d", step)
+			exp.TaskType = soakTaskTypes[rng.Intn(len(soakTaskTypes))]
+			exp.Embedding = vector(rng)
+			if err := retriever.Add(exp); err != nil {
+				return err
+			}
+			if step >= retrieverWindow {
+				if err := retriever.Remove(fmt.Sprintf("exp-%d", step-retrieverWindow)); err != nil {
+					return err
+				}
+			}
+			if step%10 == 0 {
+				_, err := retriever.Retrieve(&memory.QueryContext{AgentID: agent, Input: input, Embedding: vector(rng), TopK: 5})
+				return err
+			}
+			return nil
+		},
+		Counts: func() map[string]int {
+			return map[string]int{"experiences": retriever.Size()}
+		},
+	}
+}
+
+// feedbackSubsystem applies feedback batches over a fixed set of agents
+// and task types, so routing and insight state should level off.
+func feedbackSubsystem() Subsystem {
+	insights := memory.NewEmergentInsightDetector()
+	ingester := memory.NewFeedbackIngester(memory.NewCollaborativeAttentionIndex(), memory.NewAgentAffinityGraph(), insights)
+	return Subsystem{
+		Name: "feedback",
+		Step: func(rng *rand.Rand, step int) error {
+			records := make([]memory.FeedbackRecord, 1+rng.Intn(10))
+			for i := range records {
+				records[i] = memory.FeedbackRecord{
+					Query:         fmt.Sprintf("query %d", rng.Intn(1000)),
+					Agent:         soakAgents[rng.Intn(len(soakAgents))],
+					Collaborators: []string{soakAgents[rng.Intn(len(soakAgents))]},
+					Success:       rng.Float64() < 0.8,
+					TaskType:      soakTaskTypes[rng.Intn(len(soakTaskTypes))],
+				}
+			}
+			ingester.Ingest(records)
+			return nil
+		},
+		Counts: func() map[string]int {
+			return map[string]int{
+				"breakthroughs":    len(insights.GetRecentBreakthroughs(1 << 20)),
+				"unexpected_pairs": len(insights.GetUnexpectedPairs(1)),
+			}
+		},
+	}
+}
+
+// embeddingCacheSubsystem embeds texts from a space far larger than the
+// cache, so it evicts constantly.
+func embeddingCacheSubsystem(t *testing.T) Subsystem {
+	cfg := embeddings.DefaultCacheConfig()
+	cfg.MaxEntries = embeddingEntries
+	cache, err := embeddings.NewCache(embeddings.NewStubEmbedder(0), cfg)
+	if err != nil {
+		t.Fatalf("failed to create embedding cache: %v", err)
+	}
+	return Subsystem{
+		Name: "embedding_cache",
+		Step: func(rng *rand.Rand, step int) error {
+			texts := make([]string, 1+rng.Intn(8))
+			for i := range texts {
+				texts[i] = fmt.Sprintf("text %d", rng.Intn(100*embeddingEntries))
+			}
+			_, err := cache.Embed(context.Background(), texts)
+			return err
+		},
+		Counts: func() map[string]int {
+			return map[string]int{"entries": cache.Stats().Entries}
+		},
+	}
+}